@@ -1,48 +1,81 @@
 package handlers
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
 	"easy-orders-backend/internal/api/middleware"
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/documents"
 	"easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AdminHandler handles admin-related HTTP requests
 type AdminHandler struct {
-	orderService  services.OrderService
-	reportService services.ReportService
-	logger        *logger.Logger
+	orderService      services.OrderService
+	adminOrderService services.AdminOrderService
+	reportService     services.ReportService
+	reportQueue       services.ReportQueueService
+	queryCache        *cache.QueryCache
+	stages            *metrics.StageRecorder
+	duplicates        *services.DuplicateOrderGuard
+	limits            *services.OrderLimiter
+	snapshots         services.SandboxSnapshotService
+	logger            *logger.Logger
 }
 
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler(
 	orderService services.OrderService,
+	adminOrderService services.AdminOrderService,
 	reportService services.ReportService,
+	reportQueue services.ReportQueueService,
+	queryCache *cache.QueryCache,
+	stages *metrics.StageRecorder,
+	duplicates *services.DuplicateOrderGuard,
+	limits *services.OrderLimiter,
+	snapshots services.SandboxSnapshotService,
 	logger *logger.Logger,
 ) *AdminHandler {
 	return &AdminHandler{
-		orderService:  orderService,
-		reportService: reportService,
-		logger:        logger,
+		orderService:      orderService,
+		adminOrderService: adminOrderService,
+		reportService:     reportService,
+		reportQueue:       reportQueue,
+		queryCache:        queryCache,
+		stages:            stages,
+		duplicates:        duplicates,
+		limits:            limits,
+		snapshots:         snapshots,
+		logger:            logger,
 	}
 }
 
 // GetAllOrders godoc
 // @Summary Get all orders (Admin)
-// @Description Get a paginated list of all orders (Admin only)
+// @Description Get a paginated list of all orders with their fraud risk score and signals, or stream every matching order as NDJSON with format=ndjson. Filtering by risk band lists the highest scores first (Admin only)
 // @Tags admin
 // @Accept json
-// @Produce json
+// @Produce json,application/x-ndjson
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of items per page" default(10)
 // @Param status query string false "Filter by order status"
+// @Param metadata_key query string false "Filter by metadata key"
+// @Param metadata_value query string false "Metadata value to match for metadata_key"
+// @Param risk_band query string false "Filter by risk score band: low (0-29), medium (30-59) or high (60-100)" Enums(low, medium, high)
+// @Param format query string false "json for a paginated page, ndjson to stream every matching order" Enums(json, ndjson)
 // @Success 200 {object} object{data=services.ListOrdersResponse} "List of all orders"
+// @Failure 400 {object} map[string]interface{} "Invalid metadata filter"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
 // @Router /admin/orders [get]
@@ -59,11 +92,25 @@ func (h *AdminHandler) GetAllOrders(c *gin.Context) {
 
 	// Type asserts to the expected request type
 	req := *validatedQuery.(*services.ListOrdersRequest)
+	req.IncludeRisk = true
+
+	if req.Format == "ndjson" || c.GetHeader("Accept") == ndjsonContentType {
+		h.streamOrders(c, req)
+		return
+	}
 
 	// Call service
 	response, err := h.orderService.ListOrders(c.Request.Context(), req)
 	if err != nil {
 		h.logger.Error("Failed to get all orders", "error", err)
+
+		if strings.Contains(err.Error(), "invalid metadata") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get orders",
 		})
@@ -76,6 +123,348 @@ func (h *AdminHandler) GetAllOrders(c *gin.Context) {
 	})
 }
 
+const (
+	ndjsonContentType = "application/x-ndjson"
+	// ndjsonFlushEvery bounds how many rows are buffered before being sent to the client
+	ndjsonFlushEvery = 100
+)
+
+// streamOrders writes every matching order as one JSON object per line,
+// gzip-compressed when the client accepts it, flushing as rows are fetched
+func (h *AdminHandler) streamOrders(c *gin.Context, req services.ListOrdersRequest) {
+	h.logger.Info("Streaming orders export via admin API", "status", req.Status, "metadata_key", req.MetadataKey)
+
+	// Reject bad filters while a JSON error response is still possible
+	if req.MetadataKey != "" && !models.IsValidMetadataKey(req.MetadataKey) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid metadata key",
+		})
+		return
+	}
+
+	stream := startNDJSONStream(c)
+	defer stream.Close()
+
+	err := h.orderService.StreamOrders(c.Request.Context(), req, func(order *services.OrderResponse) error {
+		return stream.Write(order)
+	})
+	if err != nil {
+		// Headers are already sent, so the failure is reported as a final line
+		h.logger.Error("Failed to stream orders export", "error", err, "rows", stream.rows)
+		_ = stream.encoder.Encode(gin.H{"error": "Failed to stream orders"})
+	}
+
+	h.logger.Info("Orders export streamed via admin API", "rows", stream.rows)
+}
+
+// ndjsonStream writes rows to the response one JSON object per line, gzip-compressed
+// when the client accepts it, flushing every ndjsonFlushEvery rows
+type ndjsonStream struct {
+	c       *gin.Context
+	gz      *gzip.Writer
+	encoder *json.Encoder
+	rows    int
+}
+
+// startNDJSONStream sends the headers of an NDJSON response and returns its stream
+func startNDJSONStream(c *gin.Context) *ndjsonStream {
+	c.Header("Content-Type", ndjsonContentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	stream := &ndjsonStream{c: c}
+	var writer io.Writer = c.Writer
+	if strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+		c.Header("Content-Encoding", "gzip")
+		c.Header("Vary", "Accept-Encoding")
+
+		stream.gz = gzip.NewWriter(c.Writer)
+		writer = stream.gz
+	}
+	c.Status(http.StatusOK)
+
+	stream.encoder = json.NewEncoder(writer)
+	return stream
+}
+
+// Write encodes one row
+func (s *ndjsonStream) Write(row interface{}) error {
+	if err := s.encoder.Encode(row); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%ndjsonFlushEvery == 0 {
+		return s.flush()
+	}
+	return nil
+}
+
+// Close flushes the remaining rows and ends the compressed stream
+func (s *ndjsonStream) Close() {
+	_ = s.flush()
+	if s.gz != nil {
+		_ = s.gz.Close()
+	}
+}
+
+func (s *ndjsonStream) flush() error {
+	if s.gz != nil {
+		if err := s.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// ExportSandboxSnapshot godoc
+// @Summary Export an anonymized sandbox snapshot (Admin)
+// @Description Stream an anonymized copy of organizations, users, products with their inventory, and orders with their items and payments as NDJSON, one object per line naming its table and record, in an order that can be loaded as is. Emails are hashed, names shuffled, addresses reduced to their city and amounts jittered, keeping IDs so references still resolve. Passwords are not exported. (Admin only)
+// @Tags admin
+// @Produce application/x-ndjson
+// @Param orders_since query string false "Export orders placed from this date (YYYY-MM-DD); all orders when empty"
+// @Param jitter_percent query number false "Most an amount is jittered by, in percent" default(10)
+// @Param seed query string false "Seed making snapshots anonymize alike; random when empty"
+// @Success 200 {object} services.SandboxSnapshotRecord "One record per line"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Security BearerAuth
+// @Router /admin/sandbox-snapshot [get]
+func (h *AdminHandler) ExportSandboxSnapshot(c *gin.Context) {
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.SandboxSnapshotRequest)
+	h.logger.Info("Streaming sandbox snapshot via admin API", "orders_since", req.OrdersSince)
+
+	stream := startNDJSONStream(c)
+	defer stream.Close()
+
+	err := h.snapshots.Export(c.Request.Context(), req, func(record *services.SandboxSnapshotRecord) error {
+		return stream.Write(record)
+	})
+	if err != nil {
+		// Headers are already sent, so the failure is reported as a final line
+		h.logger.Error("Failed to stream sandbox snapshot", "error", err, "rows", stream.rows)
+		_ = stream.encoder.Encode(gin.H{"error": "Failed to export sandbox snapshot"})
+	}
+
+	h.logger.Info("Sandbox snapshot streamed via admin API", "rows", stream.rows)
+}
+
+// GetOrderFullView godoc
+// @Summary Get full order view (Admin)
+// @Description Get an order with its customer, items and product snapshots, payments with attempts, refunds, notifications and status history in one call (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{data=services.OrderFullViewResponse} "Full order view"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/full [get]
+func (h *AdminHandler) GetOrderFullView(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Getting order full view via admin API", "id", orderID)
+
+	// Call service
+	view, err := h.adminOrderService.GetOrderFullView(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to get order full view", "error", err, "id", orderID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Order not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get order",
+		})
+		return
+	}
+
+	h.logger.Debug("Order full view retrieved via admin API", "id", orderID)
+	c.JSON(http.StatusOK, gin.H{
+		"data": view,
+	})
+}
+
+// RescoreOrderRisk godoc
+// @Summary Rescore order risk (Admin)
+// @Description Score an order's fraud risk again from its current data, such as after its address was updated or a payment failed, and return the new score with the signals that raised it (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{data=services.OrderRisk} "New risk score"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/risk [post]
+func (h *AdminHandler) RescoreOrderRisk(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Info("Rescoring order risk via admin API", "id", orderID)
+
+	// Call service
+	risk, err := h.adminOrderService.RescoreOrder(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to rescore order risk", "error", err, "id", orderID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Order not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to rescore order risk",
+		})
+		return
+	}
+
+	h.logger.Info("Order risk rescored via admin API", "id", orderID, "score", risk.Score, "band", risk.Band)
+	c.JSON(http.StatusOK, gin.H{
+		"data": risk,
+	})
+}
+
+// GetPackingSlip godoc
+// @Summary Get order packing slip (Admin)
+// @Description Get the packing slip to pack with an order: what ships, where to, and the order prices. Gift orders carry the gift message, and leave out every price when the buyer asked to hide them. With format html or pdf the slip is returned ready to print (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json,html,application/pdf
+// @Param id path string true "Order ID"
+// @Param format query string false "json (default), html or pdf"
+// @Success 200 {object} object{data=services.PackingSlipResponse} "Packing slip"
+// @Failure 400 {object} map[string]interface{} "Invalid format"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/packing-slip [get]
+func (h *AdminHandler) GetPackingSlip(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Getting packing slip via admin API", "id", orderID)
+
+	// Call service
+	slip, err := h.adminOrderService.GetPackingSlip(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to get packing slip", "error", err, "id", orderID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Order not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get packing slip",
+		})
+		return
+	}
+
+	respondWithDocument(c, h.logger, slip, slip.Document(), "packing-slip-"+slip.OrderID)
+}
+
+// GetPickList godoc
+// @Summary Generate a pick list (Admin)
+// @Description Aggregate the items of a batch of orders into a pick list, one line per product with the quantity to pick and how it splits across the orders. Lines follow the bin locations of the products' inventory so the warehouse is walked once; products without a location come last. Every order must be confirmed or paid and not on hold. With format html or pdf the list is returned ready to print (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json,html,application/pdf
+// @Param orders body services.PickListRequest true "Orders to pick"
+// @Param format query string false "json (default), html or pdf"
+// @Success 200 {object} object{data=services.PickListResponse} "Pick list"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order not ready to pick"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/pick-list [post]
+func (h *AdminHandler) GetPickList(c *gin.Context) {
+	h.logger.Debug("Generating pick list via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PickListRequest)
+
+	// Call service
+	pickList, err := h.adminOrderService.GetPickList(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate pick list", "error", err, "orders", len(req.OrderIDs))
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate pick list"})
+		}
+		return
+	}
+
+	h.logger.Info("Pick list generated via admin API", "orders", len(pickList.OrderIDs), "lines", len(pickList.Lines))
+	respondWithDocument(c, h.logger, pickList, pickList.Document(), "pick-list-"+pickList.GeneratedAt.Format("20060102-150405"))
+}
+
+// documentQuery is a validated query selecting the format of a printable document
+type documentQuery interface {
+	DocumentFormat() documents.Format
+}
+
+// respondWithDocument responds with the data as JSON, or with the document rendered
+// in the format of the validated query to print
+func respondWithDocument(c *gin.Context, log *logger.Logger, data interface{}, doc *documents.Document, filename string) {
+	format := documents.FormatJSON
+	if validatedQuery, exists := middleware.GetValidatedQuery(c); exists {
+		if query, ok := validatedQuery.(documentQuery); ok {
+			format = query.DocumentFormat()
+		}
+	}
+
+	if format == documents.FormatJSON {
+		c.JSON(http.StatusOK, gin.H{
+			"data": data,
+		})
+		return
+	}
+
+	body, err := documents.Render(doc, format)
+	if err != nil {
+		log.Error("Failed to render document", "error", err, "format", format)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to render document",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+"."+string(format)))
+	c.Data(http.StatusOK, format.ContentType(), body)
+}
+
 // UpdateOrderStatus godoc
 // @Summary Update order status (Admin)
 // @Description Update order status as admin
@@ -87,7 +476,7 @@ func (h *AdminHandler) GetAllOrders(c *gin.Context) {
 // @Success 200 {object} object{message=string,data=services.OrderResponse} "Order status updated"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 404 {object} map[string]interface{} "Order not found"
-// @Failure 409 {object} map[string]interface{} "Invalid status transition"
+// @Failure 409 {object} map[string]interface{} "Invalid status transition, or order on hold"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
 // @Router /admin/orders/{id}/status [patch]
@@ -120,7 +509,7 @@ func (h *AdminHandler) UpdateOrderStatus(c *gin.Context) {
 			return
 		}
 
-		if strings.Contains(err.Error(), "cannot transition") {
+		if strings.Contains(err.Error(), "cannot transition") || strings.Contains(err.Error(), "CONFLICT") {
 			c.JSON(http.StatusConflict, gin.H{
 				"error": err.Error(),
 			})
@@ -140,52 +529,787 @@ func (h *AdminHandler) UpdateOrderStatus(c *gin.Context) {
 	})
 }
 
-// GenerateDailySalesReport godoc
-// @Summary Generate daily sales report (Admin)
-// @Description Generate sales report for a specific date
+// UpdateOrderMetadata godoc
+// @Summary Update order metadata (Admin)
+// @Description Merge metadata entries into an order; empty values remove keys
 // @Tags admin
 // @Accept json
 // @Produce json
-// @Param date query string false "Date in YYYY-MM-DD format"
-// @Success 200 {object} object{data=services.SalesReportResponse} "Daily sales report"
-// @Failure 400 {object} map[string]interface{} "Invalid date format"
+// @Param id path string true "Order ID"
+// @Param metadata body services.UpdateMetadataRequest true "Metadata entries"
+// @Success 200 {object} object{message=string,data=services.OrderResponse} "Order metadata updated"
+// @Failure 400 {object} map[string]interface{} "Invalid metadata"
+// @Failure 404 {object} map[string]interface{} "Order not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
-// @Router /admin/reports/daily [get]
-func (h *AdminHandler) GenerateDailySalesReport(c *gin.Context) {
-	h.logger.Debug("Generating daily sales report via admin API")
+// @Router /admin/orders/{id}/metadata [patch]
+func (h *AdminHandler) UpdateOrderMetadata(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Updating order metadata via admin API", "id", orderID)
 
-	// Get validated query from context
-	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
 	if !exists {
-		h.logger.Error("Validated query not found in context")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
 		return
 	}
 
 	// Type asserts to the expected request type
-	req := *validatedQuery.(*services.DailySalesReportQuery)
+	req := *validatedReq.(*services.UpdateMetadataRequest)
 
 	// Call service
-	report, err := h.reportService.GenerateDailySalesReport(c.Request.Context(), req.Date)
+	order, err := h.orderService.UpdateOrderMetadata(c.Request.Context(), orderID, req)
 	if err != nil {
-		h.logger.Error("Failed to generate daily sales report", "error", err, "date", req.Date)
+		h.logger.Error("Failed to update order metadata via admin", "error", err, "id", orderID)
 
-		if strings.Contains(err.Error(), "invalid date format") {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Order not found",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "invalid metadata") {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Invalid date format, use YYYY-MM-DD",
+				"error": err.Error(),
 			})
 			return
 		}
 
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to generate sales report",
+			"error": "Failed to update order metadata",
 		})
 		return
 	}
 
-	h.logger.Info("Daily sales report generated successfully via admin API", "date", report.Date, "total_sales", report.TotalSales)
+	h.logger.Info("Order metadata updated successfully via admin API", "id", orderID)
 	c.JSON(http.StatusOK, gin.H{
-		"data": report,
+		"message": "Order metadata updated successfully",
+		"data":    order,
+	})
+}
+
+// OverrideOrderItemPrice godoc
+// @Summary Override an order item price (Admin)
+// @Description Charge an item of a pending order a unit price below its list price, such as a negotiated discount, and update the order total. A reason code is required and the discount may not exceed the configured maximum. The list price is kept alongside the charged price for margin reporting.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param item_id path string true "Order item ID"
+// @Param override body services.OverrideItemPriceRequest true "Charged unit price and reason"
+// @Success 200 {object} object{message=string,data=services.OrderItemPriceOverrideResponse} "Order item price overridden"
+// @Failure 400 {object} map[string]interface{} "Invalid price or reason code"
+// @Failure 403 {object} map[string]interface{} "Discount exceeds the maximum"
+// @Failure 404 {object} map[string]interface{} "Order or item not found"
+// @Failure 409 {object} map[string]interface{} "Order is not pending"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/items/{item_id}/price [patch]
+func (h *AdminHandler) OverrideOrderItemPrice(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	itemID := c.Param("item_id")
+	h.logger.Debug("Overriding order item price via admin API", "id", orderID, "item_id", itemID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.OverrideItemPriceRequest)
+
+	// Extract the overriding admin's ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	response, err := h.adminOrderService.OverrideItemPrice(c.Request.Context(), orderID, itemID, userID, req)
+	if err != nil {
+		h.logger.Error("Failed to override order item price via admin", "error", err, "id", orderID, "item_id", itemID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "FORBIDDEN"):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to override order item price"})
+		}
+		return
+	}
+
+	h.logger.Info("Order item price overridden via admin API", "id", orderID, "item_id", itemID,
+		"user_id", userID, "reason_code", req.ReasonCode)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order item price overridden",
+		"data":    response,
+	})
+}
+
+// ImportOrders godoc
+// @Summary Import historical orders (Admin)
+// @Description Import orders with items and payments from a previous platform. The CSV has one row per order item, grouped by order_ref, with a header row. Stock is not reserved or deducted; orders are marked with the migration channel and re-importing skips orders already migrated.
+// @Tags admin
+// @Accept mpfd,text/csv
+// @Produce json
+// @Param file formData file false "Order CSV (order_ref,customer_email,status,ordered_at,sku,quantity,unit_price and optional customer_name,currency,order_total,payment_method,payment_status,payment_amount,payment_reference)"
+// @Success 200 {object} object{message=string,data=services.OrderImportResponse} "Orders imported"
+// @Failure 400 {object} map[string]interface{} "Invalid CSV file"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/import [post]
+func (h *AdminHandler) ImportOrders(c *gin.Context) {
+	h.logger.Debug("Importing historical orders via admin API")
+
+	// Accept either a multipart upload or a raw CSV body
+	var source io.Reader = c.Request.Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.logger.Error("Failed to open uploaded order import file", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read uploaded file",
+			})
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	// Call service
+	response, err := h.orderService.ImportOrders(c.Request.Context(), source)
+	if err != nil {
+		h.logger.Error("Failed to import orders", "error", err)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to import orders",
+		})
+		return
+	}
+
+	h.logger.Info("Historical orders imported via admin API",
+		"total_orders", response.TotalOrders, "imported", response.Imported,
+		"skipped", response.Skipped, "failed", response.Failed)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Orders imported",
+		"data":    response,
+	})
+}
+
+// GenerateDailySalesReport godoc
+// @Summary Generate daily sales report (Admin)
+// @Description Generate sales report for a specific date. With format html or pdf the report is returned ready to print
+// @Tags admin
+// @Accept json
+// @Produce json,html,application/pdf
+// @Param date query string false "Date in YYYY-MM-DD format"
+// @Param low_margin_threshold query number false "Margin percentage below which products are flagged (default 20)"
+// @Param format query string false "json (default), html or pdf"
+// @Success 200 {object} object{data=services.SalesReportResponse} "Daily sales report"
+// @Failure 400 {object} map[string]interface{} "Invalid date format"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/daily [get]
+func (h *AdminHandler) GenerateDailySalesReport(c *gin.Context) {
+	h.logger.Debug("Generating daily sales report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.DailySalesReportQuery)
+
+	// Call service
+	report, err := h.reportService.GenerateDailySalesReport(c.Request.Context(), req.Date, req.LowMarginThreshold)
+	if err != nil {
+		h.logger.Error("Failed to generate daily sales report", "error", err, "date", req.Date)
+
+		if strings.Contains(err.Error(), "invalid date format") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid date format, use YYYY-MM-DD",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate sales report",
+		})
+		return
+	}
+
+	h.logger.Info("Daily sales report generated successfully via admin API", "date", report.Date, "total_sales", report.TotalSales)
+	respondWithDocument(c, h.logger, report, report.Document(), "sales-report-"+report.Date)
+}
+
+// GenerateUserActivityReport godoc
+// @Summary Generate user activity report (Admin)
+// @Description Active and new users with engagement by activity type for an inclusive range of UTC days (default: last 7 days). With format html or pdf the report is returned ready to print
+// @Tags admin
+// @Accept json
+// @Produce json,html,application/pdf
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Param format query string false "json (default), html or pdf"
+// @Success 200 {object} object{data=services.UserActivityReportResponse} "User activity report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/user-activity [get]
+func (h *AdminHandler) GenerateUserActivityReport(c *gin.Context) {
+	h.logger.Debug("Generating user activity report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.UserActivityReportRequest)
+
+	// Call service
+	report, err := h.reportService.GenerateUserActivityReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate user activity report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+
+		if strings.Contains(err.Error(), "invalid date") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate user activity report",
+		})
+		return
+	}
+
+	h.logger.Info("User activity report generated successfully via admin API", "active_users", report.ActiveUsers, "new_users", report.NewUsers)
+	respondWithDocument(c, h.logger, report, report.Document(), "user-activity-"+report.StartDate+"-"+report.EndDate)
+}
+
+// GenerateSettlementReport godoc
+// @Summary Generate payment settlement report (Admin)
+// @Description Reconcile payment method surcharges and discounts against gateway processing fees for an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.SettlementReportResponse} "Settlement report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/settlement [get]
+func (h *AdminHandler) GenerateSettlementReport(c *gin.Context) {
+	h.logger.Debug("Generating settlement report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.SettlementReportRequest)
+
+	// Call service
+	report, err := h.reportService.GenerateSettlementReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate settlement report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+
+		if strings.Contains(err.Error(), "invalid date") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate settlement report",
+		})
+		return
+	}
+
+	h.logger.Info("Settlement report generated successfully via admin API", "payments", report.Totals.Payments, "fee_variance", report.Totals.FeeVariance)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// GeneratePaymentFailureReport godoc
+// @Summary Generate payment failure report (Admin)
+// @Description Aggregate payment attempt failures by decline reason, gateway, payment method and UTC hour of day, with retry success rates, for an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.PaymentFailureReportResponse} "Payment failure report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/payment-failures [get]
+func (h *AdminHandler) GeneratePaymentFailureReport(c *gin.Context) {
+	h.logger.Debug("Generating payment failure report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.PaymentFailureReportRequest)
+
+	// Call service
+	report, err := h.reportService.GeneratePaymentFailureReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate payment failure report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+
+		if strings.Contains(err.Error(), "invalid date") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate payment failure report",
+		})
+		return
+	}
+
+	h.logger.Info("Payment failure report generated successfully via admin API", "attempts", report.Totals.Attempts, "failures", report.Totals.Failures)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// GenerateNotificationDeliveryReport godoc
+// @Summary Generate notification delivery report (Admin)
+// @Description Count notifications sent, delivered and read, with delivery and read rates and average times, by notification type, by delivering channel and by both, for notifications created in an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.NotificationDeliveryReportResponse} "Notification delivery report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/notification-delivery [get]
+func (h *AdminHandler) GenerateNotificationDeliveryReport(c *gin.Context) {
+	h.logger.Debug("Generating notification delivery report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.NotificationDeliveryReportRequest)
+
+	// Call service
+	report, err := h.reportService.GenerateNotificationDeliveryReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate notification delivery report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+
+		if strings.Contains(err.Error(), "invalid date") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate notification delivery report",
+		})
+		return
+	}
+
+	h.logger.Info("Notification delivery report generated successfully via admin API", "notifications", report.Totals.Notifications, "read_rate", report.Totals.ReadRate)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// GenerateARAgingReport godoc
+// @Summary Generate accounts receivable aging report (Admin)
+// @Description Age the unpaid on-account invoices of each organization by days past due, in current, 1-30, 31-60, 61-90 and over 90 day buckets, as of today (UTC)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=services.ARAgingReportResponse} "AR aging report"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/ar-aging [get]
+func (h *AdminHandler) GenerateARAgingReport(c *gin.Context) {
+	h.logger.Debug("Generating AR aging report via admin API")
+
+	// Call service
+	report, err := h.reportService.GenerateARAgingReport(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to generate AR aging report", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate AR aging report",
+		})
+		return
+	}
+
+	h.logger.Info("AR aging report generated successfully via admin API", "organizations", len(report.Organizations), "total", report.Totals.Total)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// GenerateCreditExposureReport godoc
+// @Summary Generate credit exposure report (Admin)
+// @Description Compare the outstanding on-account balance of each organization with its credit limit, with the orders held for review for exceeding it. Organizations over their limit come first, then the most utilized.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=services.CreditExposureReportResponse} "Credit exposure report"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/credit-exposure [get]
+func (h *AdminHandler) GenerateCreditExposureReport(c *gin.Context) {
+	h.logger.Debug("Generating credit exposure report via admin API")
+
+	// Call service
+	report, err := h.reportService.GenerateCreditExposureReport(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to generate credit exposure report", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate credit exposure report",
+		})
+		return
+	}
+
+	h.logger.Info("Credit exposure report generated successfully via admin API",
+		"organizations", len(report.Organizations), "over_limit_accounts", report.Totals.OverLimitAccounts)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// GenerateShippingSLAReport godoc
+// @Summary Generate shipping SLA report (Admin)
+// @Description Track unshipped orders against the ship date their store promised from its hours and same-day shipping cutoff: orders past it, by hours overdue, and orders due within the next day. Stores with the most overdue orders come first.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=services.ShippingSLAReportResponse} "Shipping SLA report"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/shipping-sla [get]
+func (h *AdminHandler) GenerateShippingSLAReport(c *gin.Context) {
+	h.logger.Debug("Generating shipping SLA report via admin API")
+
+	// Call service
+	report, err := h.reportService.GenerateShippingSLAReport(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to generate shipping SLA report", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate shipping SLA report",
+		})
+		return
+	}
+
+	h.logger.Info("Shipping SLA report generated successfully via admin API",
+		"overdue", report.Totals.Overdue, "due_soon", report.Totals.DueSoon)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// QueueReport godoc
+// @Summary Queue a report (Admin)
+// @Description Queue a report for generation in the background and return its pending result right away. Poll the result until its status is completed, failed or cancelled; results are stored, so they can be polled after a restart (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param report body services.QueueReportRequest true "Report to generate"
+// @Success 202 {object} object{data=services.ReportResultResponse} "Report queued"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/results [post]
+func (h *AdminHandler) QueueReport(c *gin.Context) {
+	h.logger.Debug("Queueing report via admin API")
+
+	// Get user ID from context (set by auth middleware)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.QueueReportRequest)
+
+	// Call service
+	result, err := h.reportQueue.QueueReport(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to queue report", "error", err, "type", req.Type)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to queue report",
+		})
+		return
+	}
+
+	h.logger.Info("Report queued via admin API", "id", result.ID, "type", result.Type, "status", result.Status)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Report queued",
+		"data":    result,
+	})
+}
+
+// GetReportResult godoc
+// @Summary Get a report result (Admin)
+// @Description Poll a queued report: its status and, once completed, its data. Completed PDF reports are downloaded with download=true, large files in storage by a redirect to a signed URL (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json,application/pdf
+// @Param id path string true "Report result ID"
+// @Param download query bool false "Download the rendered file of a completed PDF report"
+// @Success 200 {object} object{data=services.ReportResultResponse} "Report result"
+// @Success 302 "Redirect to a signed URL of the report file"
+// @Failure 404 {object} map[string]interface{} "Report result not found"
+// @Failure 409 {object} map[string]interface{} "Report not ready to download"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/results/{id} [get]
+func (h *AdminHandler) GetReportResult(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	id := c.Param("id")
+
+	if c.Query("download") == "true" {
+		h.downloadReport(c, id)
+		return
+	}
+
+	h.logger.Debug("Getting report result via admin API", "id", id)
+
+	// Call service
+	result, err := h.reportQueue.GetReportResult(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get report result", "error", err, "id", id)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Report result not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get report result",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+	})
+}
+
+// downloadReport responds with the rendered file of a report, or redirects to a
+// signed URL of it
+func (h *AdminHandler) downloadReport(c *gin.Context, id string) {
+	h.logger.Debug("Downloading report via admin API", "id", id)
+
+	download, err := h.reportQueue.DownloadReport(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to download report", "error", err, "id", id)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Report result not found",
+			})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Report has no file to download",
+				"details": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to download report",
+			})
+		}
+		return
+	}
+
+	if download.URL != "" {
+		c.Redirect(http.StatusFound, download.URL)
+		return
+	}
+	defer download.Content.Close()
+
+	c.DataFromReader(http.StatusOK, download.Size, download.ContentType, download.Content, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", download.Filename),
+	})
+}
+
+// GetCacheStats godoc
+// @Summary Get repository cache stats (Admin)
+// @Description Get hit/miss/invalidation counts per query cache namespace
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=map[string]cache.Stats} "Cache stats by namespace"
+// @Security BearerAuth
+// @Router /admin/cache/stats [get]
+func (h *AdminHandler) GetCacheStats(c *gin.Context) {
+	h.logger.Debug("Getting repository cache stats via admin API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.queryCache.Stats(),
+	})
+}
+
+// GetPipelineMetrics godoc
+// @Summary Get order pipeline stage metrics (Admin)
+// @Description Get the latency and outcome of each order placement and payment stage since startup, slowest first by recent p95 latency. Percentiles cover the most recent executions of each stage; a rollback is counted for the stage that failed an order placement.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=[]metrics.StageSummary} "Stage metrics, slowest first"
+// @Security BearerAuth
+// @Router /admin/pipeline/metrics [get]
+func (h *AdminHandler) GetPipelineMetrics(c *gin.Context) {
+	h.logger.Debug("Getting pipeline stage metrics via admin API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.stages.Summary(),
+	})
+}
+
+// GetDuplicateOrderMetrics godoc
+// @Summary Get duplicate order metrics (Admin)
+// @Description Get how many orders were checked since startup for repeating a recent order of the same user with the same items, and how many of those likely duplicates were placed with a warning, prevented pending confirmation, or confirmed
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=services.DuplicateOrderMetrics} "Duplicate order metrics"
+// @Security BearerAuth
+// @Router /admin/orders/duplicates/metrics [get]
+func (h *AdminHandler) GetDuplicateOrderMetrics(c *gin.Context) {
+	h.logger.Debug("Getting duplicate order metrics via admin API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.duplicates.Metrics(),
+	})
+}
+
+// GetOrderLimits godoc
+// @Summary Get order limits (Admin)
+// @Description Get the order limits in force, where zero is no limit: different products per order, units of one product per order and orders per user per hour, with how many orders each limit has refused since startup
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=services.OrderLimitsResponse} "Order limits"
+// @Security BearerAuth
+// @Router /admin/orders/limits [get]
+func (h *AdminHandler) GetOrderLimits(c *gin.Context) {
+	h.logger.Debug("Getting order limits via admin API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.limits.Limits(),
+	})
+}
+
+// UpdateOrderLimits godoc
+// @Summary Update order limits (Admin)
+// @Description Change the order limits in force; zero removes a limit and a limit left out keeps its value. Changes last until the next restart, when the configured limits apply again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param limits body services.UpdateOrderLimitsRequest true "Order limits to change"
+// @Success 200 {object} object{message=string,data=services.OrderLimitsResponse} "Order limits updated"
+// @Failure 400 {object} map[string]interface{} "Invalid limits"
+// @Security BearerAuth
+// @Router /admin/orders/limits [put]
+func (h *AdminHandler) UpdateOrderLimits(c *gin.Context) {
+	h.logger.Debug("Updating order limits via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdateOrderLimitsRequest)
+
+	response, err := h.limits.Update(req)
+	if err != nil {
+		h.logger.Error("Failed to update order limits", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Order limits updated via admin API", "max_items_per_order", response.MaxItemsPerOrder,
+		"max_quantity_per_sku", response.MaxQuantityPerSKU, "max_orders_per_hour", response.MaxOrdersPerHour)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order limits updated",
+		"data":    response,
 	})
 }
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIUsageHandler handles API key and API usage HTTP requests
+type APIUsageHandler struct {
+	apiUsageService services.APIUsageService
+	logger          *logger.Logger
+}
+
+// NewAPIUsageHandler creates a new API usage handler
+func NewAPIUsageHandler(apiUsageService services.APIUsageService, logger *logger.Logger) *APIUsageHandler {
+	return &APIUsageHandler{
+		apiUsageService: apiUsageService,
+		logger:          logger,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key the current user's integrations can call the API with in the X-API-Key header instead of a token. The key is returned only in this response. New keys get the default monthly quota, if one is configured.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body services.CreateAPIKeyRequest true "API key name"
+// @Success 201 {object} object{message=string,data=services.CreateAPIKeyResponse} "API key created"
+// @Failure 400 {object} map[string]interface{} "Invalid name"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (h *APIUsageHandler) CreateAPIKey(c *gin.Context) {
+	h.logger.Debug("Creating API key via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateAPIKeyRequest)
+
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	key, err := h.apiUsageService.CreateAPIKey(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to create API key", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to create API key")
+		return
+	}
+
+	h.logger.Info("API key created via API", "api_key_id", key.ID, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Store it now, it will not be shown again.",
+		"data":    key,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the current user's API keys, newest first, with the requests made with each this month and what remains of its quota
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} object{data=[]services.APIKeyResponse} "API keys"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (h *APIUsageHandler) ListAPIKeys(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Listing API keys via API", "user_id", userID)
+
+	keys, err := h.apiUsageService.ListAPIKeys(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list API keys", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to list API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": keys,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke one of the current user's API keys. Requests made with it are refused from then on.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} object{message=string} "API key revoked"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Failure 409 {object} map[string]interface{} "API key already revoked"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (h *APIUsageHandler) RevokeAPIKey(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	keyID := c.Param("id")
+
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Revoking API key via API", "api_key_id", keyID, "user_id", userID)
+
+	if err := h.apiUsageService.RevokeAPIKey(c.Request.Context(), userID, keyID); err != nil {
+		h.logger.Error("Failed to revoke API key", "error", err, "api_key_id", keyID, "user_id", userID)
+		h.respondWithError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	h.logger.Info("API key revoked via API", "api_key_id", keyID, "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked",
+	})
+}
+
+// GetMyAPIUsage godoc
+// @Summary Get my API usage
+// @Description Get the current user's API usage by endpoint and by API key, for an inclusive range of UTC days (default: last 7 days). Usage is written periodically, so the latest requests may not be included yet.
+// @Tags api-keys
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Param api_key_id query string false "Only the usage of this API key"
+// @Success 200 {object} object{data=services.APIUsageReportResponse} "API usage"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /usage [get]
+func (h *APIUsageHandler) GetMyAPIUsage(c *gin.Context) {
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.APIUsageRequest)
+
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Getting API usage via API", "user_id", userID)
+
+	// Users only see their own usage
+	req.UserID = userID
+
+	usage, err := h.apiUsageService.GetAPIUsage(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get API usage", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to get API usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": usage,
+	})
+}
+
+// GetAPIUsage godoc
+// @Summary Get API usage (Admin)
+// @Description Get API usage by endpoint and by caller (API key, or user signed in with a token), with request, server error and quota rejection counts and latencies, for an inclusive range of UTC days (default: last 7 days), optionally of one API key or user
+// @Tags admin
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Param api_key_id query string false "Only the usage of this API key"
+// @Param user_id query string false "Only the usage of this user"
+// @Success 200 {object} object{data=services.APIUsageReportResponse} "API usage"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/usage [get]
+func (h *APIUsageHandler) GetAPIUsage(c *gin.Context) {
+	h.logger.Debug("Getting API usage via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.APIUsageRequest)
+
+	usage, err := h.apiUsageService.GetAPIUsage(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get API usage", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+		h.respondWithError(c, err, "Failed to get API usage")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": usage,
+	})
+}
+
+// SetAPIKeyQuota godoc
+// @Summary Set an API key's monthly quota (Admin)
+// @Description Set the number of requests an API key may make per calendar month (UTC), or remove its quota with a null monthly_quota. Requests over the quota are refused with 429 Too Many Requests.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param quota body services.SetAPIKeyQuotaRequest true "Monthly quota"
+// @Success 200 {object} object{message=string,data=services.APIKeyResponse} "Quota set"
+// @Failure 400 {object} map[string]interface{} "Invalid quota"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/api-keys/{id}/quota [put]
+func (h *APIUsageHandler) SetAPIKeyQuota(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	keyID := c.Param("id")
+	h.logger.Debug("Setting API key quota via admin API", "api_key_id", keyID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.SetAPIKeyQuotaRequest)
+
+	key, err := h.apiUsageService.SetAPIKeyQuota(c.Request.Context(), keyID, req)
+	if err != nil {
+		h.logger.Error("Failed to set API key quota", "error", err, "api_key_id", keyID)
+		h.respondWithError(c, err, "Failed to set API key quota")
+		return
+	}
+
+	h.logger.Info("API key quota set via admin API", "api_key_id", keyID, "monthly_quota", req.MonthlyQuota)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key quota set",
+		"data":    key,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *APIUsageHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
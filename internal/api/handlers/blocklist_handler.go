@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlocklistHandler handles fraud blocklist HTTP requests
+type BlocklistHandler struct {
+	blocklistService services.BlocklistService
+	logger           *logger.Logger
+}
+
+// NewBlocklistHandler creates a new blocklist handler
+func NewBlocklistHandler(blocklistService services.BlocklistService, logger *logger.Logger) *BlocklistHandler {
+	return &BlocklistHandler{
+		blocklistService: blocklistService,
+		logger:           logger,
+	}
+}
+
+// AddBlocklistEntry godoc
+// @Summary Add a blocklist entry (Admin)
+// @Description Block orders and payments of an email address, an email domain and its subdomains, a card by its gateway fingerprint, or an IP address or CIDR range, optionally until expires_at. Values are normalized, e.g. email addresses and domains to lower case. The change is recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param entry body services.CreateBlocklistEntryRequest true "Blocklist entry"
+// @Success 201 {object} object{message=string,data=models.BlocklistEntry} "Entry added"
+// @Failure 400 {object} map[string]interface{} "Invalid type, value or expiry"
+// @Failure 409 {object} map[string]interface{} "Value already blocklisted"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/blocklist [post]
+func (h *BlocklistHandler) AddBlocklistEntry(c *gin.Context) {
+	h.logger.Debug("Adding blocklist entry via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateBlocklistEntryRequest)
+
+	// Extract the admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	entry, err := h.blocklistService.AddEntry(c.Request.Context(), actor, req)
+	if err != nil {
+		h.logger.Error("Failed to add blocklist entry", "error", err, "type", req.Type)
+		h.respondWithError(c, err, "Failed to add blocklist entry")
+		return
+	}
+
+	h.logger.Info("Blocklist entry added via admin API", "id", entry.ID, "type", entry.Type)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Blocklist entry added",
+		"data":    entry,
+	})
+}
+
+// ListBlocklistEntries godoc
+// @Summary List blocklist entries (Admin)
+// @Description List blocklist entries, newest first, optionally of one type or with values containing search, with the attempts each blocked
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param type query string false "email, domain, card_fingerprint or ip_range"
+// @Param search query string false "Part of the value"
+// @Success 200 {object} object{data=services.ListBlocklistEntriesResponse} "Blocklist entries"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/blocklist [get]
+func (h *BlocklistHandler) ListBlocklistEntries(c *gin.Context) {
+	h.logger.Debug("Listing blocklist entries via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListBlocklistEntriesRequest)
+
+	response, err := h.blocklistService.ListEntries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list blocklist entries", "error", err)
+		h.respondWithError(c, err, "Failed to list blocklist entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// RemoveBlocklistEntry godoc
+// @Summary Remove a blocklist entry (Admin)
+// @Description Remove an entry from the blocklist, so that it no longer blocks orders and payments. The change is recorded in the audit log.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Blocklist entry ID"
+// @Success 200 {object} object{message=string} "Entry removed"
+// @Failure 404 {object} map[string]interface{} "Entry not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/blocklist/{id} [delete]
+func (h *BlocklistHandler) RemoveBlocklistEntry(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	entryID := c.Param("id")
+	h.logger.Debug("Removing blocklist entry via admin API", "id", entryID)
+
+	// Extract the admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	if err := h.blocklistService.RemoveEntry(c.Request.Context(), actor, entryID); err != nil {
+		h.logger.Error("Failed to remove blocklist entry", "error", err, "id", entryID)
+		h.respondWithError(c, err, "Failed to remove blocklist entry")
+		return
+	}
+
+	h.logger.Info("Blocklist entry removed via admin API", "id", entryID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Blocklist entry removed",
+	})
+}
+
+// GetBlocklistMetrics godoc
+// @Summary Get blocked attempt metrics (Admin)
+// @Description Get the order and payment attempts screened and blocked since startup, by checkpoint and by the type of entry that matched, with the entries that blocked the most attempts. Screening latency is reported with the order pipeline stage metrics as fraud_screening.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=services.BlocklistMetricsResponse} "Blocked attempt metrics"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/blocklist/metrics [get]
+func (h *BlocklistHandler) GetBlocklistMetrics(c *gin.Context) {
+	h.logger.Debug("Getting blocklist metrics via admin API")
+
+	metrics, err := h.blocklistService.GetMetrics(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get blocklist metrics", "error", err)
+		h.respondWithError(c, err, "Failed to get blocklist metrics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": metrics,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *BlocklistHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
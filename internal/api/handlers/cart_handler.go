@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CartHandler handles cart stock hold HTTP requests
+type CartHandler struct {
+	cartHoldService services.CartHoldService
+	logger          *logger.Logger
+}
+
+// NewCartHandler creates a new cart handler
+func NewCartHandler(cartHoldService services.CartHoldService, logger *logger.Logger) *CartHandler {
+	return &CartHandler{
+		cartHoldService: cartHoldService,
+		logger:          logger,
+	}
+}
+
+// HoldItem godoc
+// @Summary Hold stock for a cart item
+// @Description Reserve stock for a cart item until the hold window expires. Holding a product again replaces its quantity and restarts the window. Holds are converted when the user places an order.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param item body services.HoldCartItemRequest true "Cart item to hold"
+// @Success 200 {object} object{data=services.CartHoldResponse} "Stock held"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Cart holds are disabled"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /cart/holds [put]
+func (h *CartHandler) HoldItem(c *gin.Context) {
+	h.logger.Debug("Holding cart item via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.HoldCartItemRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	hold, err := h.cartHoldService.HoldItem(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to hold cart item", "error", err, "user_id", userID, "product_id", req.ProductID)
+
+		if strings.Contains(err.Error(), "disabled") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Cart holds are disabled",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Product not found",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "INSUFFICIENT_STOCK") || strings.Contains(err.Error(), "not available") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "must be") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to hold cart item",
+		})
+		return
+	}
+
+	h.logger.Info("Cart item held successfully via API", "id", hold.ID, "user_id", userID, "product_id", hold.ProductID)
+	c.JSON(http.StatusOK, gin.H{
+		"data": hold,
+	})
+}
+
+// GetCartHolds godoc
+// @Summary List cart stock holds
+// @Description List the authenticated user's active cart holds
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.CartHoldResponse} "Active cart holds"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /cart/holds [get]
+func (h *CartHandler) GetCartHolds(c *gin.Context) {
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Getting cart holds via API", "user_id", userID)
+
+	// Call service
+	holds, err := h.cartHoldService.GetCartHolds(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get cart holds", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get cart holds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": holds,
+	})
+}
+
+// ReleaseItem godoc
+// @Summary Release a cart stock hold
+// @Description Release the authenticated user's hold on a product, returning the stock to available inventory
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} object{message=string} "Hold released"
+// @Failure 404 {object} map[string]interface{} "Cart hold not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /cart/holds/{product_id} [delete]
+func (h *CartHandler) ReleaseItem(c *gin.Context) {
+	// Middleware does path parameter validation
+	productID := c.Param("product_id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Releasing cart hold via API", "user_id", userID, "product_id", productID)
+
+	// Call service
+	if err := h.cartHoldService.ReleaseItem(c.Request.Context(), userID, productID); err != nil {
+		h.logger.Error("Failed to release cart hold", "error", err, "user_id", userID, "product_id", productID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Cart hold not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to release cart hold",
+		})
+		return
+	}
+
+	h.logger.Info("Cart hold released successfully via API", "user_id", userID, "product_id", productID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart hold released successfully",
+	})
+}
+
+// GetHoldStats godoc
+// @Summary Get cart hold conversion stats (Admin)
+// @Description How cart holds created in an inclusive range of UTC days ended, and the hold-to-order conversion rate (default: last 7 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.CartHoldStatsResponse} "Cart hold stats"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/cart-holds/stats [get]
+func (h *CartHandler) GetHoldStats(c *gin.Context) {
+	h.logger.Debug("Getting cart hold stats via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.CartHoldStatsRequest)
+
+	// Call service
+	stats, err := h.cartHoldService.GetConversionStats(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get cart hold stats", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+
+		if strings.Contains(err.Error(), "invalid date") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get cart hold stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": stats,
+	})
+}
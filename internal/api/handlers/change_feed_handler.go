@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeFeedHandler handles change feed HTTP requests of integrators
+type ChangeFeedHandler struct {
+	changeFeedService services.ChangeFeedService
+	logger            *logger.Logger
+}
+
+// NewChangeFeedHandler creates a new change feed handler
+func NewChangeFeedHandler(changeFeedService services.ChangeFeedService, logger *logger.Logger) *ChangeFeedHandler {
+	return &ChangeFeedHandler{
+		changeFeedService: changeFeedService,
+		logger:            logger,
+	}
+}
+
+// ListInventoryChanges godoc
+// @Summary List inventory changes (Admin)
+// @Description Page through inventory changes (reservations, releases, adjustments and fulfillments) in the order they were recorded. Pass the returned next_cursor as since to resume; events from the last few seconds are held back until they settle.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned with the previous page; empty starts from the oldest event"
+// @Param limit query int false "Maximum number of events (1-1000)" default(100)
+// @Success 200 {object} object{data=services.InventoryChangesResponse} "Inventory changes"
+// @Failure 400 {object} map[string]interface{} "Invalid cursor"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /inventory/changes [get]
+func (h *ChangeFeedHandler) ListInventoryChanges(c *gin.Context) {
+	h.logger.Debug("Listing inventory changes via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ChangeFeedRequest)
+
+	// Call service
+	response, err := h.changeFeedService.InventoryChanges(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list inventory changes", "error", err, "since", req.Since)
+		h.writeError(c, err, "Failed to list inventory changes")
+		return
+	}
+
+	h.logger.Debug("Inventory changes listed via API", "since", req.Since, "count", len(response.Events))
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListOrderChanges godoc
+// @Summary List order changes (Admin)
+// @Description Page through order lifecycle events (created, status changed, credit reviewed, updated) in the order they were recorded, as a pull alternative to webhooks. Each payload carries its payload_version. Pass the returned next_cursor as since to resume; events from the last few seconds are held back until they settle.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned with the previous page; empty starts from the oldest event"
+// @Param limit query int false "Maximum number of events (1-1000)" default(100)
+// @Success 200 {object} object{data=services.OrderChangesResponse} "Order changes"
+// @Failure 400 {object} map[string]interface{} "Invalid cursor"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /orders/changes [get]
+func (h *ChangeFeedHandler) ListOrderChanges(c *gin.Context) {
+	h.logger.Debug("Listing order changes via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ChangeFeedRequest)
+
+	// Call service
+	response, err := h.changeFeedService.OrderChanges(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list order changes", "error", err, "since", req.Since)
+		h.writeError(c, err, "Failed to list order changes")
+		return
+	}
+
+	h.logger.Debug("Order changes listed via API", "since", req.Since, "count", len(response.Events))
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListCatalogChanges godoc
+// @Summary List catalog changes
+// @Description Page through changes to products' storefront pages (created, price changed, content changed, availability changed, deleted) in the order they were recorded, for frontends and CDNs to invalidate cached pages. Events only name the product; availability changes are recorded when a product goes in or out of stock or is activated or deactivated. Pass the returned next_cursor as since to resume; events from the last few seconds are held back until they settle.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned with the previous page; empty starts from the oldest event"
+// @Param limit query int false "Maximum number of events (1-1000)" default(100)
+// @Success 200 {object} object{data=services.CatalogChangesResponse} "Catalog changes"
+// @Failure 400 {object} map[string]interface{} "Invalid cursor"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /catalog/changes [get]
+func (h *ChangeFeedHandler) ListCatalogChanges(c *gin.Context) {
+	h.logger.Debug("Listing catalog changes via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ChangeFeedRequest)
+
+	// Call service
+	response, err := h.changeFeedService.CatalogChanges(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list catalog changes", "error", err, "since", req.Since)
+		h.writeError(c, err, "Failed to list catalog changes")
+		return
+	}
+
+	h.logger.Debug("Catalog changes listed via API", "since", req.Since, "count", len(response.Events))
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// writeError responds with the status matching a change feed service error
+func (h *ChangeFeedHandler) writeError(c *gin.Context, err error, fallback string) {
+	if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxChannelOrderPayloadSize caps the size of an imported channel order payload
+const maxChannelOrderPayloadSize = 1 << 20
+
+// ChannelHandler handles marketplace channel HTTP requests
+type ChannelHandler struct {
+	channelService services.ChannelService
+	logger         *logger.Logger
+}
+
+// NewChannelHandler creates a new channel handler
+func NewChannelHandler(channelService services.ChannelService, logger *logger.Logger) *ChannelHandler {
+	return &ChannelHandler{
+		channelService: channelService,
+		logger:         logger,
+	}
+}
+
+// ListChannels godoc
+// @Summary List sales channels (Admin)
+// @Description Get the registered marketplace channels and their health (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.ChannelResponse} "List of channels"
+// @Security BearerAuth
+// @Router /admin/channels [get]
+func (h *ChannelHandler) ListChannels(c *gin.Context) {
+	h.logger.Debug("Listing channels via admin API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.channelService.ListChannels(c.Request.Context()),
+	})
+}
+
+// ImportChannelOrder godoc
+// @Summary Import a channel order (Admin)
+// @Description Create an order from a channel-specific order payload, mapping channel SKUs to products (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel name"
+// @Param order body object true "Channel order payload"
+// @Success 201 {object} object{message=string,data=services.ChannelOrderImportResponse} "Order imported"
+// @Success 200 {object} object{message=string,data=services.ChannelOrderImportResponse} "Order already imported"
+// @Failure 400 {object} map[string]interface{} "Invalid channel order"
+// @Failure 404 {object} map[string]interface{} "Channel or customer not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/channels/{channel}/orders [post]
+func (h *ChannelHandler) ImportChannelOrder(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	channel := c.Param("channel")
+	h.logger.Debug("Importing channel order via admin API", "channel", channel)
+
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxChannelOrderPayloadSize))
+	if err != nil {
+		h.logger.Error("Failed to read channel order payload", "error", err, "channel", channel)
+		appErr := errors.NewValidationError("Failed to read request body")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	response, err := h.channelService.ImportOrder(c.Request.Context(), channel, payload)
+	if err != nil {
+		h.logger.Error("Failed to import channel order", "error", err, "channel", channel)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "not available") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to import channel order",
+		})
+		return
+	}
+
+	if response.Duplicate {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Channel order already imported",
+			"data":    response,
+		})
+		return
+	}
+
+	h.logger.Info("Channel order imported via admin API", "channel", channel, "order_id", response.Order.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Channel order imported",
+		"data":    response,
+	})
+}
+
+// CreateChannelListing godoc
+// @Summary Map a product to a channel SKU (Admin)
+// @Description Register the SKU a product is listed under on a channel (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel name"
+// @Param listing body services.CreateChannelListingRequest true "Listing details"
+// @Success 201 {object} object{message=string,data=services.ChannelListingResponse} "Listing created"
+// @Failure 400 {object} map[string]interface{} "Invalid listing"
+// @Failure 404 {object} map[string]interface{} "Channel or product not found"
+// @Failure 409 {object} map[string]interface{} "Channel SKU already mapped"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/channels/{channel}/listings [post]
+func (h *ChannelHandler) CreateChannelListing(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	channel := c.Param("channel")
+	h.logger.Debug("Creating channel listing via admin API", "channel", channel)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateChannelListingRequest)
+
+	// Call service
+	listing, err := h.channelService.CreateListing(c.Request.Context(), channel, req)
+	if err != nil {
+		h.logger.Error("Failed to create channel listing", "error", err, "channel", channel, "channel_sku", req.ChannelSKU)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "already exists") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create channel listing",
+		})
+		return
+	}
+
+	h.logger.Info("Channel listing created via admin API", "channel", channel, "id", listing.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Channel listing created",
+		"data":    listing,
+	})
+}
+
+// ListChannelListings godoc
+// @Summary List channel SKU mappings (Admin)
+// @Description Get the products listed on a channel and their channel SKUs (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel name"
+// @Success 200 {object} object{data=[]services.ChannelListingResponse} "List of listings"
+// @Failure 404 {object} map[string]interface{} "Channel not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/channels/{channel}/listings [get]
+func (h *ChannelHandler) ListChannelListings(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	channel := c.Param("channel")
+	h.logger.Debug("Listing channel listings via admin API", "channel", channel)
+
+	listings, err := h.channelService.ListListings(c.Request.Context(), channel)
+	if err != nil {
+		h.logger.Error("Failed to list channel listings", "error", err, "channel", channel)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list channel listings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": listings,
+	})
+}
+
+// SyncChannel godoc
+// @Summary Push inventory and prices to a channel (Admin)
+// @Description Publish current availability and prices for every listing on the channel (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param channel path string true "Channel name"
+// @Success 200 {object} object{message=string,data=services.ChannelSyncResponse} "Channel synced"
+// @Failure 404 {object} map[string]interface{} "Channel not found"
+// @Failure 502 {object} map[string]interface{} "Channel rejected the update"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/channels/{channel}/sync [post]
+func (h *ChannelHandler) SyncChannel(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	channel := c.Param("channel")
+	h.logger.Debug("Syncing channel via admin API", "channel", channel)
+
+	response, err := h.channelService.SyncChannel(c.Request.Context(), channel)
+	if err != nil {
+		h.logger.Error("Failed to sync channel", "error", err, "channel", channel)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "EXTERNAL_SERVICE_ERROR") {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to sync channel",
+		})
+		return
+	}
+
+	h.logger.Info("Channel synced via admin API", "channel", channel,
+		"inventory_updates", response.InventoryUpdates, "price_updates", response.PriceUpdates)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Channel synced",
+		"data":    response,
+	})
+}
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader carries the client-chosen key of an express checkout
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses that replay an earlier express checkout or
+// order submission
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// CheckoutHandler handles saved address, saved payment method and express checkout HTTP requests
+type CheckoutHandler struct {
+	checkoutService services.CheckoutService
+	logger          *logger.Logger
+}
+
+// NewCheckoutHandler creates a new checkout handler
+func NewCheckoutHandler(checkoutService services.CheckoutService, logger *logger.Logger) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutService: checkoutService,
+		logger:          logger,
+	}
+}
+
+// AddAddress godoc
+// @Summary Save a shipping address
+// @Description Save a shipping address for the authenticated user. The first address saved becomes the default; saving another one as default replaces the previous default.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param address body services.CreateAddressRequest true "Address"
+// @Success 201 {object} object{message=string,data=services.AddressResponse} "Address saved"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /addresses [post]
+func (h *CheckoutHandler) AddAddress(c *gin.Context) {
+	h.logger.Debug("Saving address via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateAddressRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	address, err := h.checkoutService.AddAddress(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to save address", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to save address")
+		return
+	}
+
+	h.logger.Info("Address saved successfully via API", "id", address.ID, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Address saved successfully",
+		"data":    address,
+	})
+}
+
+// ListAddresses godoc
+// @Summary List saved shipping addresses
+// @Description List the authenticated user's saved shipping addresses, default first
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.AddressResponse} "Saved addresses"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /addresses [get]
+func (h *CheckoutHandler) ListAddresses(c *gin.Context) {
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Listing addresses via API", "user_id", userID)
+
+	// Call service
+	addresses, err := h.checkoutService.ListAddresses(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list addresses", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to list addresses")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": addresses,
+	})
+}
+
+// SetDefaultAddress godoc
+// @Summary Set the default shipping address
+// @Description Make one of the authenticated user's saved addresses the default, which express checkout ships to
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} object{message=string,data=services.AddressResponse} "Default address set"
+// @Failure 404 {object} map[string]interface{} "Address not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /addresses/{id}/default [put]
+func (h *CheckoutHandler) SetDefaultAddress(c *gin.Context) {
+	// Middleware does path parameter validation
+	id := c.Param("id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Setting default address via API", "user_id", userID, "id", id)
+
+	// Call service
+	address, err := h.checkoutService.SetDefaultAddress(c.Request.Context(), userID, id)
+	if err != nil {
+		h.logger.Error("Failed to set default address", "error", err, "user_id", userID, "id", id)
+		h.writeError(c, err, "Failed to set default address")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default address set successfully",
+		"data":    address,
+	})
+}
+
+// DeleteAddress godoc
+// @Summary Delete a saved shipping address
+// @Description Delete one of the authenticated user's saved addresses. Orders keep the address they were placed with.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param id path string true "Address ID"
+// @Success 200 {object} object{message=string} "Address deleted"
+// @Failure 404 {object} map[string]interface{} "Address not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /addresses/{id} [delete]
+func (h *CheckoutHandler) DeleteAddress(c *gin.Context) {
+	// Middleware does path parameter validation
+	id := c.Param("id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Deleting address via API", "user_id", userID, "id", id)
+
+	// Call service
+	if err := h.checkoutService.DeleteAddress(c.Request.Context(), userID, id); err != nil {
+		h.logger.Error("Failed to delete address", "error", err, "user_id", userID, "id", id)
+		h.writeError(c, err, "Failed to delete address")
+		return
+	}
+
+	h.logger.Info("Address deleted successfully via API", "user_id", userID, "id", id)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address deleted successfully",
+	})
+}
+
+// AddPaymentMethod godoc
+// @Summary Save a payment method
+// @Description Save a payment method tokenized by the payment gateway for the authenticated user. The token is never returned. The first method saved becomes the default; saving another one as default replaces the previous default.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param method body services.CreateSavedPaymentMethodRequest true "Payment method"
+// @Success 201 {object} object{message=string,data=services.SavedPaymentMethodResponse} "Payment method saved"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-methods [post]
+func (h *CheckoutHandler) AddPaymentMethod(c *gin.Context) {
+	h.logger.Debug("Saving payment method via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateSavedPaymentMethodRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	method, err := h.checkoutService.AddPaymentMethod(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to save payment method", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to save payment method")
+		return
+	}
+
+	h.logger.Info("Payment method saved successfully via API", "id", method.ID, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment method saved successfully",
+		"data":    method,
+	})
+}
+
+// ListPaymentMethods godoc
+// @Summary List saved payment methods
+// @Description List the authenticated user's saved payment methods, default first
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.SavedPaymentMethodResponse} "Saved payment methods"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-methods [get]
+func (h *CheckoutHandler) ListPaymentMethods(c *gin.Context) {
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Listing payment methods via API", "user_id", userID)
+
+	// Call service
+	methods, err := h.checkoutService.ListPaymentMethods(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list payment methods", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to list payment methods")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": methods,
+	})
+}
+
+// SetDefaultPaymentMethod godoc
+// @Summary Set the default payment method
+// @Description Make one of the authenticated user's saved payment methods the default, which express checkout pays with
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment method ID"
+// @Success 200 {object} object{message=string,data=services.SavedPaymentMethodResponse} "Default payment method set"
+// @Failure 404 {object} map[string]interface{} "Payment method not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-methods/{id}/default [put]
+func (h *CheckoutHandler) SetDefaultPaymentMethod(c *gin.Context) {
+	// Middleware does path parameter validation
+	id := c.Param("id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Setting default payment method via API", "user_id", userID, "id", id)
+
+	// Call service
+	method, err := h.checkoutService.SetDefaultPaymentMethod(c.Request.Context(), userID, id)
+	if err != nil {
+		h.logger.Error("Failed to set default payment method", "error", err, "user_id", userID, "id", id)
+		h.writeError(c, err, "Failed to set default payment method")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Default payment method set successfully",
+		"data":    method,
+	})
+}
+
+// DeletePaymentMethod godoc
+// @Summary Delete a saved payment method
+// @Description Delete one of the authenticated user's saved payment methods
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment method ID"
+// @Success 200 {object} object{message=string} "Payment method deleted"
+// @Failure 404 {object} map[string]interface{} "Payment method not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-methods/{id} [delete]
+func (h *CheckoutHandler) DeletePaymentMethod(c *gin.Context) {
+	// Middleware does path parameter validation
+	id := c.Param("id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Deleting payment method via API", "user_id", userID, "id", id)
+
+	// Call service
+	if err := h.checkoutService.DeletePaymentMethod(c.Request.Context(), userID, id); err != nil {
+		h.logger.Error("Failed to delete payment method", "error", err, "user_id", userID, "id", id)
+		h.writeError(c, err, "Failed to delete payment method")
+		return
+	}
+
+	h.logger.Info("Payment method deleted successfully via API", "user_id", userID, "id", id)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment method deleted successfully",
+	})
+}
+
+// ExpressCheckout godoc
+// @Summary One-click checkout
+// @Description Place an order for one product, shipped to the default address and paid with the default payment method, in one call. Requires an Idempotency-Key header: retrying with the same key returns the order placed by the first request, as it is now, without placing or charging anything, and sets the Idempotent-Replayed header.
+// @Description Once the order is placed, a payment that does not complete does not undo it. The order stays pending with its stock reserved and the outcome field says what happened: paid (201), payment_held (202, retried automatically), payment_pending (202) or payment_failed (402, pay it through the payments API or cancel it).
+// @Description While the flash sale allocation queue is on, a checkout whose turn for stock does not come quickly is answered with 202 and a queue ticket instead; retry with the same key and queue_ticket set to keep its place.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string true "Client-chosen key identifying this checkout"
+// @Param checkout body services.ExpressCheckoutRequest true "Product and quantity"
+// @Success 200 {object} object{message=string,data=services.ExpressCheckoutResponse} "Replay of a paid checkout"
+// @Success 201 {object} object{message=string,data=services.ExpressCheckoutResponse} "Order placed and paid"
+// @Success 202 {object} object{message=string,data=services.ExpressCheckoutResponse} "Order placed, payment not settled yet, or checkout queued for stock with data=services.AllocationQueuePosition"
+// @Failure 400 {object} map[string]interface{} "Invalid request, no default address or payment method, order over the size limits, or gift options the store does not offer"
+// @Failure 402 {object} object{error=string,data=services.ExpressCheckoutResponse} "Order placed but payment failed"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock, store closed, or idempotency key used for a different checkout"
+// @Failure 429 {object} map[string]interface{} "Hourly order limit reached"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /checkout/express [post]
+func (h *CheckoutHandler) ExpressCheckout(c *gin.Context) {
+	h.logger.Debug("Express checkout via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ExpressCheckoutRequest)
+	req.IdempotencyKey = strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+	req.ClientIP = c.ClientIP()
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	checkout, err := h.checkoutService.ExpressCheckout(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Express checkout failed", "error", err, "user_id", userID, "product_id", req.ProductID)
+		h.writeError(c, err, "Failed to check out")
+		return
+	}
+
+	if checkout.Replayed {
+		c.Header(IdempotentReplayedHeader, "true")
+	}
+
+	h.logger.Info("Express checkout handled via API", "order_id", checkout.Order.ID, "user_id", userID,
+		"outcome", checkout.Outcome, "replayed", checkout.Replayed)
+
+	switch checkout.Outcome {
+	case services.ExpressCheckoutPaid:
+		status := http.StatusCreated
+		if checkout.Replayed {
+			status = http.StatusOK
+		}
+		c.JSON(status, gin.H{
+			"message": "Order placed and paid",
+			"data":    checkout,
+		})
+	case services.ExpressCheckoutPaymentFailed:
+		c.JSON(http.StatusPaymentRequired, gin.H{
+			"error": "Order placed but payment failed: " + checkout.PaymentError,
+			"data":  checkout,
+		})
+	case services.ExpressCheckoutPaymentHeld:
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Order placed, payment held for retry",
+			"data":    checkout,
+		})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Order placed, payment pending",
+			"data":    checkout,
+		})
+	}
+}
+
+// writeError maps a checkout service error to a response
+func (h *CheckoutHandler) writeError(c *gin.Context, err error, fallback string) {
+	if position, queued := services.QueuePositionFromError(err); queued {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Checkout queued for stock; retry with the queue ticket",
+			"data":    position,
+		})
+		return
+	}
+
+	switch {
+	case strings.Contains(err.Error(), "ORDER_RATE_LIMIT_EXCEEDED"):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "ORDER_ITEM_LIMIT_EXCEEDED"), strings.Contains(err.Error(), "ORDER_QUANTITY_LIMIT_EXCEEDED"),
+		strings.Contains(err.Error(), "PURCHASE_LIMIT_EXCEEDED"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"), strings.Contains(err.Error(), "INSUFFICIENT_STOCK"),
+		strings.Contains(err.Error(), "not available"), strings.Contains(err.Error(), "is closed"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "FORBIDDEN"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"), strings.Contains(err.Error(), "BUSINESS_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeadLetterHandler handles dead-letter queue HTTP requests for background jobs that ran
+// out of retries
+type DeadLetterHandler struct {
+	deadLetterService services.DeadLetterService
+	logger            *logger.Logger
+}
+
+// NewDeadLetterHandler creates a new dead-letter handler
+func NewDeadLetterHandler(deadLetterService services.DeadLetterService, logger *logger.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		deadLetterService: deadLetterService,
+		logger:            logger,
+	}
+}
+
+// ListDeadJobs godoc
+// @Summary List dead jobs (Admin)
+// @Description List background jobs that ran out of retries, most recently failed first, without their payload
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param type query string false "Job type, such as payment_retry"
+// @Param pool query string false "Worker pool"
+// @Success 200 {object} object{data=services.ListDeadJobsResponse} "Dead jobs"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/dead [get]
+func (h *DeadLetterHandler) ListDeadJobs(c *gin.Context) {
+	h.logger.Debug("Listing dead jobs via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListDeadJobsRequest)
+
+	// Call service
+	response, err := h.deadLetterService.ListDeadJobs(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list dead jobs", "error", err)
+		h.writeError(c, err, "Failed to list dead jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// GetDeadJob godoc
+// @Summary Get a dead job (Admin)
+// @Description Get a background job that ran out of retries, with its payload and last error
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{data=models.DeadJob} "Dead job"
+// @Failure 404 {object} map[string]interface{} "Dead job not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/dead/{id} [get]
+func (h *DeadLetterHandler) GetDeadJob(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	jobID := c.Param("id")
+	h.logger.Debug("Getting dead job via admin API", "job_id", jobID)
+
+	// Call service
+	job, err := h.deadLetterService.GetDeadJob(c.Request.Context(), jobID)
+	if err != nil {
+		h.logger.Error("Failed to get dead job", "error", err, "job_id", jobID)
+		h.writeError(c, err, "Failed to get dead job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": job,
+	})
+}
+
+// RequeueDeadJob godoc
+// @Summary Requeue a dead job (Admin)
+// @Description Move a dead job back to its pool's queue to run right away, with all of its retries
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{message=string,data=services.RequeueDeadJobResponse} "Dead job requeued"
+// @Failure 404 {object} map[string]interface{} "Dead job not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/dead/{id}/requeue [post]
+func (h *DeadLetterHandler) RequeueDeadJob(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	jobID := c.Param("id")
+	h.logger.Debug("Requeueing dead job via admin API", "job_id", jobID)
+
+	// Call service
+	response, err := h.deadLetterService.RequeueDeadJob(c.Request.Context(), jobID)
+	if err != nil {
+		h.logger.Error("Failed to requeue dead job", "error", err, "job_id", jobID)
+		h.writeError(c, err, "Failed to requeue dead job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead job requeued",
+		"data":    response,
+	})
+}
+
+// DeleteDeadJob godoc
+// @Summary Delete a dead job (Admin)
+// @Description Delete a dead job for good
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} object{message=string} "Dead job deleted"
+// @Failure 404 {object} map[string]interface{} "Dead job not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/dead/{id} [delete]
+func (h *DeadLetterHandler) DeleteDeadJob(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	jobID := c.Param("id")
+	h.logger.Debug("Deleting dead job via admin API", "job_id", jobID)
+
+	// Call service
+	if err := h.deadLetterService.DeleteDeadJob(c.Request.Context(), jobID); err != nil {
+		h.logger.Error("Failed to delete dead job", "error", err, "job_id", jobID)
+		h.writeError(c, err, "Failed to delete dead job")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead job deleted",
+	})
+}
+
+// PurgeDeadJobs godoc
+// @Summary Purge dead jobs (Admin)
+// @Description Delete the dead jobs of a type or pool, or that failed before a time; all=true purges the whole queue
+// @Tags admin
+// @Produce json
+// @Param type query string false "Job type"
+// @Param pool query string false "Worker pool"
+// @Param died_before query string false "RFC 3339 time the jobs failed before"
+// @Param all query bool false "Purge every dead job"
+// @Success 200 {object} object{message=string,data=services.PurgeDeadJobsResponse} "Dead jobs purged"
+// @Failure 400 {object} map[string]interface{} "No criteria, or invalid died_before"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/jobs/dead [delete]
+func (h *DeadLetterHandler) PurgeDeadJobs(c *gin.Context) {
+	h.logger.Debug("Purging dead jobs via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.PurgeDeadJobsRequest)
+
+	// Call service
+	response, err := h.deadLetterService.PurgeDeadJobs(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to purge dead jobs", "error", err)
+		h.writeError(c, err, "Failed to purge dead jobs")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dead jobs purged",
+		"data":    response,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *DeadLetterHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
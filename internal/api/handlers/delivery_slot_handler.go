@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeliverySlotHandler handles delivery slot HTTP requests
+type DeliverySlotHandler struct {
+	slotService services.DeliverySlotService
+	logger      *logger.Logger
+}
+
+// NewDeliverySlotHandler creates a new delivery slot handler
+func NewDeliverySlotHandler(slotService services.DeliverySlotService, logger *logger.Logger) *DeliverySlotHandler {
+	return &DeliverySlotHandler{
+		slotService: slotService,
+		logger:      logger,
+	}
+}
+
+// PlanSlots godoc
+// @Summary Plan delivery slots (Admin)
+// @Description Plan the same delivery windows (HH:MM, not overlapping) with their capacity for every day of an inclusive range of days in a region. Windows already planned on a day are updated and reactivated; their capacity never goes below the orders already booked into them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param plan body services.PlanDeliverySlotsRequest true "Delivery windows to plan"
+// @Success 201 {object} object{message=string,data=services.ListDeliverySlotsResponse} "Delivery slots planned"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/delivery-slots [post]
+func (h *DeliverySlotHandler) PlanSlots(c *gin.Context) {
+	h.logger.Debug("Planning delivery slots via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PlanDeliverySlotsRequest)
+
+	// Call service
+	slots, err := h.slotService.PlanSlots(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to plan delivery slots", "error", err, "region", req.Region)
+		h.writeError(c, err, "Failed to plan delivery slots")
+		return
+	}
+
+	h.logger.Info("Delivery slots planned successfully via admin API", "region", req.Region, "slots", len(slots.Slots))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Delivery slots planned successfully",
+		"data":    slots,
+	})
+}
+
+// ListSlots godoc
+// @Summary List delivery slots (Admin)
+// @Description Get the delivery slots of an inclusive range of days (default: the next 14 days) with their capacity and bookings, for capacity planning
+// @Tags admin
+// @Produce json
+// @Param region query string false "Region"
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.ListDeliverySlotsResponse} "Delivery slots"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/delivery-slots [get]
+func (h *DeliverySlotHandler) ListSlots(c *gin.Context) {
+	h.logger.Debug("Listing delivery slots via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListDeliverySlotsRequest)
+
+	slots, err := h.slotService.ListSlots(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list delivery slots", "error", err, "region", req.Region)
+		h.writeError(c, err, "Failed to list delivery slots")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": slots,
+	})
+}
+
+// UpdateSlot godoc
+// @Summary Update a delivery slot (Admin)
+// @Description Change the capacity of a delivery slot or deactivate it. The capacity cannot go below the orders already booked into the slot; deactivating it keeps their bookings.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery slot ID"
+// @Param slot body services.UpdateDeliverySlotRequest true "Fields to update"
+// @Success 200 {object} object{message=string,data=services.DeliverySlotResponse} "Delivery slot updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Delivery slot not found"
+// @Failure 409 {object} map[string]interface{} "More orders booked than the capacity"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/delivery-slots/{id} [patch]
+func (h *DeliverySlotHandler) UpdateSlot(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	slotID := c.Param("id")
+	h.logger.Debug("Updating delivery slot via admin API", "id", slotID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdateDeliverySlotRequest)
+
+	// Call service
+	slot, err := h.slotService.UpdateSlot(c.Request.Context(), slotID, req)
+	if err != nil {
+		h.logger.Error("Failed to update delivery slot", "error", err, "id", slotID)
+		h.writeError(c, err, "Failed to update delivery slot")
+		return
+	}
+
+	h.logger.Info("Delivery slot updated successfully via admin API", "id", slotID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Delivery slot updated successfully",
+		"data":    slot,
+	})
+}
+
+// ListAvailableSlots godoc
+// @Summary List available delivery slots
+// @Description Get the delivery slots that can be chosen at checkout with delivery_slot_id: active, not fully booked, from today on, in an inclusive range of days (default: the next 14 days). Set region to the region of the shipping address.
+// @Tags delivery-slots
+// @Produce json
+// @Param region query string false "Region"
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.ListDeliverySlotsResponse} "Available delivery slots"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /delivery-slots [get]
+func (h *DeliverySlotHandler) ListAvailableSlots(c *gin.Context) {
+	h.logger.Debug("Listing available delivery slots via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListDeliverySlotsRequest)
+
+	slots, err := h.slotService.ListAvailableSlots(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list available delivery slots", "error", err, "region", req.Region)
+		h.writeError(c, err, "Failed to list delivery slots")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": slots,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *DeliverySlotHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
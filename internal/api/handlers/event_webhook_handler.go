@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventWebhookHandler handles order and payment event webhook endpoints and their delivery log
+type EventWebhookHandler struct {
+	eventWebhookService services.EventWebhookService
+	logger              *logger.Logger
+}
+
+// NewEventWebhookHandler creates a new event webhook handler
+func NewEventWebhookHandler(eventWebhookService services.EventWebhookService, logger *logger.Logger) *EventWebhookHandler {
+	return &EventWebhookHandler{
+		eventWebhookService: eventWebhookService,
+		logger:              logger,
+	}
+}
+
+// CreateWebhookEndpoint godoc
+// @Summary Register a webhook endpoint (Admin)
+// @Description Register a URL that receives order and payment lifecycle events of the given topics (order.created, order.paid, order.shipped, order.delivered, order.cancelled, payment.failed). Each delivery is POSTed with an X-Webhook-Signature header, "sha256=" and the hex HMAC-SHA256 of the X-Webhook-Timestamp header, a dot and the body, keyed by the secret. Endpoints receive events that occur after they are registered.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param endpoint body services.CreateWebhookEndpointRequest true "Endpoint details"
+// @Success 201 {object} object{message=string,data=services.WebhookEndpointResponse} "Endpoint registered"
+// @Failure 400 {object} map[string]interface{} "Invalid endpoint"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/endpoints [post]
+func (h *EventWebhookHandler) CreateWebhookEndpoint(c *gin.Context) {
+	h.logger.Debug("Creating webhook endpoint via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateWebhookEndpointRequest)
+
+	// Call service
+	endpoint, err := h.eventWebhookService.CreateEndpoint(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create webhook endpoint", "error", err, "name", req.Name)
+		h.respondWithError(c, err, "Failed to create webhook endpoint")
+		return
+	}
+
+	h.logger.Info("Webhook endpoint created via admin API", "id", endpoint.ID, "name", endpoint.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook endpoint registered",
+		"data":    endpoint,
+	})
+}
+
+// ListWebhookEndpoints godoc
+// @Summary List webhook endpoints (Admin)
+// @Description Get all registered order and payment event webhook endpoints
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=[]services.WebhookEndpointResponse} "List of endpoints"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/endpoints [get]
+func (h *EventWebhookHandler) ListWebhookEndpoints(c *gin.Context) {
+	h.logger.Debug("Listing webhook endpoints via admin API")
+
+	endpoints, err := h.eventWebhookService.ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list webhook endpoints", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list webhook endpoints",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": endpoints,
+	})
+}
+
+// UpdateWebhookEndpoint godoc
+// @Summary Update a webhook endpoint (Admin)
+// @Description Change an endpoint's name, URL, secret or topics, or pause it. Deliveries to a paused endpoint are kept and sent once it is active again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Param endpoint body services.UpdateWebhookEndpointRequest true "Fields to change"
+// @Success 200 {object} object{message=string,data=services.WebhookEndpointResponse} "Endpoint updated"
+// @Failure 400 {object} map[string]interface{} "Invalid endpoint"
+// @Failure 404 {object} map[string]interface{} "Endpoint not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/endpoints/{id} [put]
+func (h *EventWebhookHandler) UpdateWebhookEndpoint(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	endpointID := c.Param("id")
+	h.logger.Debug("Updating webhook endpoint via admin API", "id", endpointID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdateWebhookEndpointRequest)
+
+	// Call service
+	endpoint, err := h.eventWebhookService.UpdateEndpoint(c.Request.Context(), endpointID, req)
+	if err != nil {
+		h.logger.Error("Failed to update webhook endpoint", "error", err, "id", endpointID)
+		h.respondWithError(c, err, "Failed to update webhook endpoint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook endpoint updated",
+		"data":    endpoint,
+	})
+}
+
+// DeleteWebhookEndpoint godoc
+// @Summary Delete a webhook endpoint (Admin)
+// @Description Stop delivering events to an endpoint. Its pending deliveries are marked failed; its delivery log is kept.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Endpoint ID"
+// @Success 200 {object} object{message=string} "Endpoint deleted"
+// @Failure 404 {object} map[string]interface{} "Endpoint not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/endpoints/{id} [delete]
+func (h *EventWebhookHandler) DeleteWebhookEndpoint(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	endpointID := c.Param("id")
+	h.logger.Debug("Deleting webhook endpoint via admin API", "id", endpointID)
+
+	if err := h.eventWebhookService.DeleteEndpoint(c.Request.Context(), endpointID); err != nil {
+		h.logger.Error("Failed to delete webhook endpoint", "error", err, "id", endpointID)
+		h.respondWithError(c, err, "Failed to delete webhook endpoint")
+		return
+	}
+
+	h.logger.Info("Webhook endpoint deleted via admin API", "id", endpointID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook endpoint deleted",
+	})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook deliveries (Admin)
+// @Description Get the webhook delivery log, newest first, with the outcome of each delivery's latest attempt. Filter by endpoint, topic or status, e.g. failed to audit deliveries that were given up.
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param endpoint_id query string false "Endpoint ID"
+// @Param topic query string false "Event topic"
+// @Param status query string false "pending, delivered or failed"
+// @Success 200 {object} object{data=services.ListWebhookDeliveriesResponse} "Webhook deliveries"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/deliveries [get]
+func (h *EventWebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	h.logger.Debug("Listing webhook deliveries via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListWebhookDeliveriesRequest)
+
+	// Call service
+	response, err := h.eventWebhookService.ListDeliveries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list webhook deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// RedeliverWebhookDelivery godoc
+// @Summary Redeliver a failed webhook delivery (Admin)
+// @Description Queue a failed delivery to be sent again right away, with a fresh set of retries. The event keeps its ID.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} object{message=string,data=services.WebhookDeliveryResponse} "Delivery queued"
+// @Failure 404 {object} map[string]interface{} "Delivery not found"
+// @Failure 409 {object} map[string]interface{} "Delivery not failed, or endpoint deleted"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/deliveries/{id}/redeliver [post]
+func (h *EventWebhookHandler) RedeliverWebhookDelivery(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	deliveryID := c.Param("id")
+	h.logger.Debug("Redelivering webhook delivery via admin API", "id", deliveryID)
+
+	delivery, err := h.eventWebhookService.Redeliver(c.Request.Context(), deliveryID)
+	if err != nil {
+		h.logger.Error("Failed to redeliver webhook delivery", "error", err, "id", deliveryID)
+		h.respondWithError(c, err, "Failed to redeliver webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Webhook delivery queued",
+		"data":    delivery,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *EventWebhookHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FulfillmentHandler handles confirmation of paid orders and fulfillment queue HTTP requests
+type FulfillmentHandler struct {
+	confirmationService services.OrderConfirmationService
+	logger              *logger.Logger
+}
+
+// NewFulfillmentHandler creates a new fulfillment handler
+func NewFulfillmentHandler(confirmationService services.OrderConfirmationService, logger *logger.Logger) *FulfillmentHandler {
+	return &FulfillmentHandler{
+		confirmationService: confirmationService,
+		logger:              logger,
+	}
+}
+
+// ConfirmOrder godoc
+// @Summary Confirm a paid order (Admin)
+// @Description Confirm a paid order of a store that requires manual confirmation, queue it for fulfillment and notify the customer
+// @Tags admin
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{message=string,data=services.OrderConfirmationResponse} "Order confirmed"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order not paid, or already confirmed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/confirm [post]
+func (h *FulfillmentHandler) ConfirmOrder(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Confirming order via admin API", "order_id", orderID)
+
+	// Call service
+	confirmation, err := h.confirmationService.ConfirmOrder(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to confirm order", "error", err, "order_id", orderID)
+		h.writeError(c, err, "Failed to confirm order")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order confirmed",
+		"data":    confirmation,
+	})
+}
+
+// ListAwaitingConfirmation godoc
+// @Summary List orders awaiting confirmation (Admin)
+// @Description List paid orders not confirmed yet, oldest first, such as those of stores that require manual confirmation
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} object{data=services.ListAwaitingConfirmationResponse} "Orders awaiting confirmation"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/awaiting-confirmation [get]
+func (h *FulfillmentHandler) ListAwaitingConfirmation(c *gin.Context) {
+	h.logger.Debug("Listing orders awaiting confirmation via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListAwaitingConfirmationRequest)
+
+	// Call service
+	response, err := h.confirmationService.ListAwaitingConfirmation(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list orders awaiting confirmation", "error", err)
+		h.writeError(c, err, "Failed to list orders awaiting confirmation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListFulfillmentQueue godoc
+// @Summary List the fulfillment queue (Admin)
+// @Description List the fulfillment tasks of confirmed orders in a status, oldest queued first. Tasks are completed when their order ships and cancelled when it is cancelled.
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "queued, completed or cancelled" default(queued)
+// @Success 200 {object} object{data=services.ListFulfillmentQueueResponse} "Fulfillment tasks"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/fulfillment/queue [get]
+func (h *FulfillmentHandler) ListFulfillmentQueue(c *gin.Context) {
+	h.logger.Debug("Listing fulfillment queue via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListFulfillmentQueueRequest)
+
+	// Call service
+	response, err := h.confirmationService.ListFulfillmentQueue(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list fulfillment queue", "error", err)
+		h.writeError(c, err, "Failed to list fulfillment queue")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *FulfillmentHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
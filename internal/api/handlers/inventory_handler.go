@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"easy-orders-backend/internal/api/middleware"
 	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -13,15 +17,19 @@ import (
 
 // InventoryHandler handles inventory-related HTTP requests
 type InventoryHandler struct {
-	inventoryService services.InventoryService
-	logger           *logger.Logger
+	inventoryService   services.InventoryService
+	shardService       services.InventoryShardService
+	stockImportService services.StockImportService
+	logger             *logger.Logger
 }
 
 // NewInventoryHandler creates a new inventory handler
-func NewInventoryHandler(inventoryService services.InventoryService, logger *logger.Logger) *InventoryHandler {
+func NewInventoryHandler(inventoryService services.InventoryService, shardService services.InventoryShardService, stockImportService services.StockImportService, logger *logger.Logger) *InventoryHandler {
 	return &InventoryHandler{
-		inventoryService: inventoryService,
-		logger:           logger,
+		inventoryService:   inventoryService,
+		shardService:       shardService,
+		stockImportService: stockImportService,
+		logger:             logger,
 	}
 }
 
@@ -95,11 +103,12 @@ func (h *InventoryHandler) CheckAvailability(c *gin.Context) {
 
 // GetLowStockAlert godoc
 // @Summary Get low stock alerts (Admin)
-// @Description Get products with low stock levels (Admin only)
+// @Description Get products with low stock levels (Admin only). Without a threshold each product is compared against its own: its override, its sales velocity over the supplier lead time when dynamic thresholds are enabled, or its min stock. With format html or pdf the report is returned ready to print.
 // @Tags admin
 // @Accept json
-// @Produce json
-// @Param threshold query int false "Stock threshold" default(10)
+// @Produce json,html,application/pdf
+// @Param threshold query int false "Stock threshold applied to every product"
+// @Param format query string false "json (default), html or pdf"
 // @Success 200 {object} object{data=services.LowStockResponse} "Low stock products"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
@@ -107,8 +116,8 @@ func (h *InventoryHandler) CheckAvailability(c *gin.Context) {
 func (h *InventoryHandler) GetLowStockAlert(c *gin.Context) {
 	h.logger.Debug("Getting low stock alert via API")
 
-	// Parse threshold parameter
-	threshold := 10 // Default threshold
+	// Parse threshold parameter; without one, use per-product thresholds
+	threshold := -1
 	if thresholdStr := c.Query("threshold"); thresholdStr != "" {
 		if t, err := strconv.Atoi(thresholdStr); err == nil && t >= 0 {
 			threshold = t
@@ -116,7 +125,13 @@ func (h *InventoryHandler) GetLowStockAlert(c *gin.Context) {
 	}
 
 	// Call service
-	response, err := h.inventoryService.GetLowStockAlert(c.Request.Context(), threshold)
+	var response *services.LowStockResponse
+	var err error
+	if threshold >= 0 {
+		response, err = h.inventoryService.GetLowStockAlert(c.Request.Context(), threshold)
+	} else {
+		response, err = h.inventoryService.GetProductLowStockAlert(c.Request.Context())
+	}
 	if err != nil {
 		h.logger.Error("Failed to get low stock alert", "error", err, "threshold", threshold)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -126,7 +141,535 @@ func (h *InventoryHandler) GetLowStockAlert(c *gin.Context) {
 	}
 
 	h.logger.Debug("Low stock alert retrieved successfully via API", "threshold", threshold, "alert_count", response.Count)
+	respondWithDocument(c, h.logger, response, response.Document(), "low-stock-"+time.Now().Format("20060102"))
+}
+
+// ImportInventory godoc
+// @Summary Import inventory recount or stock adjustments (Admin)
+// @Description With mode=recount (the default), apply counted quantities from a CSV of sku,quantity rows and return the variance report. An optional third column holds the bin location the stock was counted at; stock found at another bin is relocated there and the move is audit-logged. With mode=adjust, queue a CSV of sku,quantity rows whose signed quantities are added to the products' on hand stock, such as supplier deliveries; the rows are validated and applied in chunks on the worker pool, and the returned import is fetched with its accepted and rejected rows from /inventory/imports/{id}.
+// @Tags admin
+// @Accept mpfd,text/csv
+// @Produce json
+// @Param mode query string false "Import mode" Enums(recount, adjust)
+// @Param file formData file false "Recount CSV (sku,quantity[,location]) or adjustment CSV (sku,quantity)"
+// @Success 200 {object} object{message=string,data=services.InventoryRecountResponse} "Recount applied"
+// @Success 202 {object} object{message=string,data=models.StockImport} "Stock adjustments queued"
+// @Failure 400 {object} map[string]interface{} "Invalid CSV file"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /inventory/import [post]
+func (h *InventoryHandler) ImportInventory(c *gin.Context) {
+	mode := c.DefaultQuery("mode", "recount")
+	h.logger.Debug("Importing inventory via API", "mode", mode)
+
+	if mode != "recount" && mode != "adjust" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Mode must be recount or adjust",
+		})
+		return
+	}
+
+	// Extract the importing admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Accept either a multipart upload or a raw CSV body
+	var source io.Reader = c.Request.Body
+	var filename string
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.logger.Error("Failed to open uploaded import file", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read uploaded file",
+			})
+			return
+		}
+		defer file.Close()
+		source = file
+		filename = fileHeader.Filename
+	}
+
+	if mode == "adjust" {
+		h.importStock(c, source, filename, actor)
+		return
+	}
+	h.importRecount(c, source, actor)
+}
+
+// importRecount applies a recount and responds with its variance report
+func (h *InventoryHandler) importRecount(c *gin.Context, source io.Reader, actor services.AuditActor) {
+	// Call service
+	response, err := h.inventoryService.ImportRecount(c.Request.Context(), source, actor)
+	if err != nil {
+		h.logger.Error("Failed to import inventory recount", "error", err)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to import inventory recount",
+		})
+		return
+	}
+
+	h.logger.Info("Inventory recount imported via API",
+		"total_rows", response.TotalRows, "adjusted", response.Adjusted, "relocated", response.Relocated, "failed", response.Failed)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventory recount imported",
+		"data":    response,
+	})
+}
+
+// importStock queues stock adjustments and responds with their import
+func (h *InventoryHandler) importStock(c *gin.Context, source io.Reader, filename string, actor services.AuditActor) {
+	// Call service
+	stockImport, err := h.stockImportService.ImportStock(c.Request.Context(), source, filename, actor)
+	if err != nil {
+		h.logger.Error("Failed to import stock adjustments", "error", err)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to import stock adjustments",
+		})
+		return
+	}
+
+	h.logger.Info("Stock adjustments queued via API",
+		"import_id", stockImport.ID, "total_rows", stockImport.TotalRows, "chunks", stockImport.Chunks)
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Stock adjustments queued",
+		"data":    stockImport,
+	})
+}
+
+// GetStockImport godoc
+// @Summary Get a stock import (Admin)
+// @Description Get a stock adjustment import with a page of its rows in file order. The import is processing until every row was accepted or rejected; accepted rows carry the product's on hand stock after they were applied, rejected rows their error.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Import ID"
+// @Param status query string false "Row status" Enums(pending, accepted, rejected)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Rows per page (max 500)" default(100)
+// @Success 200 {object} object{data=services.StockImportResponse} "Stock import"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Import not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /inventory/imports/{id} [get]
+func (h *InventoryHandler) GetStockImport(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	importID := c.Param("id")
+	h.logger.Debug("Getting stock import via API", "import_id", importID)
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.StockImportRowsRequest)
+
+	// Call service
+	response, err := h.stockImportService.GetImport(c.Request.Context(), importID, req)
+	if err != nil {
+		h.logger.Error("Failed to get stock import", "error", err, "import_id", importID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get stock import",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"data": response,
 	})
 }
+
+// RelocateInventory godoc
+// @Summary Relocate inventory (Admin)
+// @Description Move a product's stock to another bin location in the warehouse, e.g. A-3-2. Pick lists walk bins in location order, with numbers compared by value, so A-2 comes before A-10. An empty location clears it. Each move is audit-logged with the admin and the reason.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param location body services.RelocateInventoryRequest true "New bin location"
+// @Success 200 {object} object{message=string,data=services.InventoryLocationResponse} "Inventory relocated"
+// @Failure 400 {object} map[string]interface{} "Invalid location"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 409 {object} map[string]interface{} "Inventory relocated concurrently"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/location [put]
+func (h *InventoryHandler) RelocateInventory(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Relocating inventory via admin API", "product_id", productID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.RelocateInventoryRequest)
+
+	// Extract the relocating admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	location, err := h.inventoryService.Relocate(c.Request.Context(), productID, actor, req)
+	if err != nil {
+		h.logger.Error("Failed to relocate inventory", "error", err, "product_id", productID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to relocate inventory"})
+		}
+		return
+	}
+
+	h.logger.Info("Inventory relocated via admin API", "product_id", productID,
+		"from", location.PreviousLocation, "to", location.Location, "relocated", location.Relocated)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventory relocated",
+		"data":    location,
+	})
+}
+
+// GetInventoryRelocations godoc
+// @Summary Get inventory relocations (Admin)
+// @Description Get the audit-logged moves of a product's stock between bin locations, newest first, with who made each move and why
+// @Tags admin
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} object{data=[]services.InventoryRelocationEntry} "Relocations"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/relocations [get]
+func (h *InventoryHandler) GetInventoryRelocations(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Getting inventory relocations via admin API", "product_id", productID)
+
+	// Call service
+	relocations, err := h.inventoryService.GetRelocations(c.Request.Context(), productID)
+	if err != nil {
+		h.logger.Error("Failed to get inventory relocations", "error", err, "product_id", productID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inventory relocations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": relocations,
+	})
+}
+
+// GetStockLevels godoc
+// @Summary Get stock levels (Admin)
+// @Description Get a product's physical stock on hand, with the units reserved for orders and carts, the safety stock held back from online sales, and the rest sellable online
+// @Tags admin
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} object{data=services.InventoryStockLevelsResponse} "Stock levels"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/stock [get]
+func (h *InventoryHandler) GetStockLevels(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Getting stock levels via admin API", "product_id", productID)
+
+	// Call service
+	levels, err := h.inventoryService.GetStockLevels(c.Request.Context(), productID)
+	if err != nil {
+		h.logger.Error("Failed to get stock levels", "error", err, "product_id", productID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get stock levels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": levels,
+	})
+}
+
+// SetSafetyStock godoc
+// @Summary Set safety stock (Admin)
+// @Description Hold back units of a product from online sales to buffer against count errors. The stock on hand is unchanged; the sellable stock drops by the safety stock, down to zero. Each change is audit-logged with the admin and the reason, and recorded in the inventory history.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param safety_stock body services.SetSafetyStockRequest true "Safety stock"
+// @Success 200 {object} object{message=string,data=services.InventoryStockLevelsResponse} "Safety stock set"
+// @Failure 400 {object} map[string]interface{} "Invalid safety stock"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/safety-stock [put]
+func (h *InventoryHandler) SetSafetyStock(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Setting safety stock via admin API", "product_id", productID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.SetSafetyStockRequest)
+
+	// Extract the admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	levels, err := h.inventoryService.SetSafetyStock(c.Request.Context(), productID, actor, req)
+	if err != nil {
+		h.logger.Error("Failed to set safety stock", "error", err, "product_id", productID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set safety stock"})
+		}
+		return
+	}
+
+	h.logger.Info("Safety stock set via admin API", "product_id", productID,
+		"safety_stock", levels.SafetyStock, "sellable", levels.Sellable)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Safety stock set",
+		"data":    levels,
+	})
+}
+
+// SetInventoryShards godoc
+// @Summary Shard a product's stock (Admin)
+// @Description Split the sellable stock of a hot product evenly into shards, rows that concurrent orders reserve from round-robin instead of queueing on one inventory row, or gather it back onto one row with 0 shards. The sellable stock is unchanged, and stock released or restocked later is spread over the shards by the periodic rebalancer. Each change is audit-logged with the admin and the reason.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param shards body services.SetInventoryShardsRequest true "Shard count"
+// @Success 200 {object} object{message=string,data=services.InventoryShardsResponse} "Inventory shards set"
+// @Failure 400 {object} map[string]interface{} "Invalid shard count"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/shards [put]
+func (h *InventoryHandler) SetInventoryShards(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Setting inventory shards via admin API", "product_id", productID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.SetInventoryShardsRequest)
+
+	// Extract the admin's ID from JWT context
+	actor, ok := auditActor(c)
+	if !ok {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	shards, err := h.shardService.SetShards(c.Request.Context(), productID, actor, req)
+	if err != nil {
+		h.logger.Error("Failed to set inventory shards", "error", err, "product_id", productID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set inventory shards"})
+		}
+		return
+	}
+
+	h.logger.Info("Inventory shards set via admin API", "product_id", productID,
+		"shards", len(shards.Shards), "sellable", shards.Sellable)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inventory shards set",
+		"data":    shards,
+	})
+}
+
+// GetInventoryShards godoc
+// @Summary Get a product's stock shards (Admin)
+// @Description Get a product's sellable stock with the units each of its shards can still reserve. Units released or restocked since the last rebalance are on no shard yet. A product that is not sharded has no shards.
+// @Tags admin
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} object{data=services.InventoryShardsResponse} "Inventory shards"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/inventory/{product_id}/shards [get]
+func (h *InventoryHandler) GetInventoryShards(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Getting inventory shards via admin API", "product_id", productID)
+
+	// Call service
+	shards, err := h.shardService.GetShards(c.Request.Context(), productID)
+	if err != nil {
+		h.logger.Error("Failed to get inventory shards", "error", err, "product_id", productID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inventory shards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": shards,
+	})
+}
+
+// GetInventoryHistory godoc
+// @Summary Get inventory history (Admin)
+// @Description Get a page of a product's inventory audit trail, newest first: reservations, releases, fulfillments, adjustments and recount counts with the stock levels after each, merged with transfers of its stock between bin locations. Each entry has the user who made the change and what it was made for (an order, a cart stock hold or a recount) when known.
+// @Tags admin
+// @Produce json
+// @Param product_id path string true "Product ID"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of entries per page" default(20)
+// @Success 200 {object} object{data=services.InventoryHistoryResponse} "Inventory history"
+// @Failure 404 {object} map[string]interface{} "Product has no inventory"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /inventory/{product_id}/history [get]
+func (h *InventoryHandler) GetInventoryHistory(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("product_id")
+	h.logger.Debug("Getting inventory history via API", "product_id", productID)
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.InventoryHistoryRequest)
+
+	// Call service
+	history, err := h.inventoryService.GetHistory(c.Request.Context(), productID, req)
+	if err != nil {
+		h.logger.Error("Failed to get inventory history", "error", err, "product_id", productID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get inventory history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": history,
+	})
+}
+
+// auditActor returns the authenticated user making an audited change and the
+// client they made it from
+func auditActor(c *gin.Context) (services.AuditActor, bool) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		return services.AuditActor{}, false
+	}
+	return services.AuditActor{
+		UserID:    userID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}, true
+}
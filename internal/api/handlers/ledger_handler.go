@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerHandler handles payments ledger HTTP requests
+type LedgerHandler struct {
+	ledgerService services.LedgerService
+	logger        *logger.Logger
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(ledgerService services.LedgerService, logger *logger.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+		logger:        logger,
+	}
+}
+
+// GetLedgerBalances godoc
+// @Summary Get ledger account balances (Admin)
+// @Description Get the balance of a payments ledger account, or of every account of a kind. Accounts are revenue, processing_fees, gift_cards, gateway_clearing:<gateway> and customer:<user ID>; pass gateway_clearing or customer for all accounts of the kind. Balances are on each account's normal side.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param account query string true "Account, or kind of account"
+// @Success 200 {object} object{data=services.LedgerBalancesResponse} "Account balances"
+// @Failure 400 {object} map[string]interface{} "Bad request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/ledger/balances [get]
+func (h *LedgerHandler) GetLedgerBalances(c *gin.Context) {
+	h.logger.Debug("Getting ledger balances via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.LedgerBalanceRequest)
+
+	// Call service
+	response, err := h.ledgerService.GetBalances(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get ledger balances", "error", err, "account", req.Account)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to get ledger balances",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListLedgerEntries godoc
+// @Summary List ledger entries (Admin)
+// @Description List payments ledger entries in posting order, optionally for one account, order or payment
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param account query string false "Filter by account"
+// @Param order_id query string false "Filter by order"
+// @Param payment_id query string false "Filter by payment"
+// @Success 200 {object} object{data=services.ListLedgerEntriesResponse} "List of ledger entries"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/ledger/entries [get]
+func (h *LedgerHandler) ListLedgerEntries(c *gin.Context) {
+	h.logger.Debug("Listing ledger entries via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListLedgerEntriesRequest)
+
+	// Call service
+	response, err := h.ledgerService.ListEntries(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list ledger entries", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list ledger entries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// CheckLedgerConsistency godoc
+// @Summary Check ledger consistency (Admin)
+// @Description Check that every ledger transaction balances, and that every settled payment and refund is posted to the ledger for its amount
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=services.LedgerConsistencyReport} "Consistency report"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/ledger/consistency [get]
+func (h *LedgerHandler) CheckLedgerConsistency(c *gin.Context) {
+	h.logger.Debug("Checking ledger consistency via API")
+
+	report, err := h.ledgerService.CheckConsistency(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to check ledger consistency", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check ledger consistency",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ManualPaymentHandler handles HTTP requests for offline payments recorded by admins
+type ManualPaymentHandler struct {
+	manualPaymentService services.ManualPaymentService
+	logger               *logger.Logger
+}
+
+// NewManualPaymentHandler creates a new manual payment handler
+func NewManualPaymentHandler(manualPaymentService services.ManualPaymentService, logger *logger.Logger) *ManualPaymentHandler {
+	return &ManualPaymentHandler{
+		manualPaymentService: manualPaymentService,
+		logger:               logger,
+	}
+}
+
+// RecordManualPayment godoc
+// @Summary Record an offline payment (Admin)
+// @Description Record a bank transfer or cash-on-delivery payment received for an order, with its reference number and an optional proof (a base64-encoded PDF, PNG or JPEG of at most 5 MB). The amount may be part of what is left to pay of the order total but not more, and a reference can only be recorded once per method. A pending or confirmed order becomes paid once its payments cover the total, and the payment is audit-logged against the order with the recording admin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param payment body services.RecordManualPaymentRequest true "Offline payment"
+// @Success 201 {object} object{message=string,data=services.ManualPaymentResponse} "Payment recorded"
+// @Failure 400 {object} map[string]interface{} "Invalid method, amount, reference or proof"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order cannot be paid, or reference already recorded"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/payments/manual [post]
+func (h *ManualPaymentHandler) RecordManualPayment(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Recording manual payment via admin API", "order_id", orderID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.RecordManualPaymentRequest)
+
+	// Extract the recording admin's ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	actor := services.AuditActor{
+		UserID:    userID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	// Call service
+	response, err := h.manualPaymentService.RecordPayment(c.Request.Context(), orderID, actor, req)
+	if err != nil {
+		h.logger.Error("Failed to record manual payment via admin", "error", err, "order_id", orderID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record manual payment"})
+		}
+		return
+	}
+
+	h.logger.Info("Manual payment recorded via admin API", "order_id", orderID, "payment_id", response.Payment.ID,
+		"user_id", userID, "method", req.Method)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment recorded",
+		"data":    response,
+	})
+}
+
+// GetPaymentProof godoc
+// @Summary Download an offline payment's proof (Admin)
+// @Description Download the proof attached to an offline payment when it was recorded
+// @Tags admin
+// @Produce application/pdf,image/png,image/jpeg
+// @Param id path string true "Payment ID"
+// @Success 200 {file} file "Payment proof"
+// @Failure 404 {object} map[string]interface{} "Payment has no proof"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/{id}/proof [get]
+func (h *ManualPaymentHandler) GetPaymentProof(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	paymentID := c.Param("id")
+	h.logger.Debug("Getting payment proof via admin API", "payment_id", paymentID)
+
+	proof, err := h.manualPaymentService.GetProof(c.Request.Context(), paymentID)
+	if err != nil {
+		h.logger.Error("Failed to get payment proof via admin", "error", err, "payment_id", paymentID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get payment proof"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", proof.FileName))
+	c.Data(http.StatusOK, proof.ContentType, proof.Content)
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationExperimentHandler handles A/B testing of notification wording
+type NotificationExperimentHandler struct {
+	experimentService services.NotificationExperimentService
+	logger            *logger.Logger
+}
+
+// NewNotificationExperimentHandler creates a new notification experiment handler
+func NewNotificationExperimentHandler(experimentService services.NotificationExperimentService, logger *logger.Logger) *NotificationExperimentHandler {
+	return &NotificationExperimentHandler{
+		experimentService: experimentService,
+		logger:            logger,
+	}
+}
+
+// CreateNotificationExperiment godoc
+// @Summary Start a notification wording experiment (Admin)
+// @Description Start an A/B test of two wordings of a customer notification type in one locale. Each user is assigned a variant for the whole experiment, split_percent of them getting variant B; templates take the notification data's values as {name} placeholders. A type and locale run one experiment at a time.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param experiment body services.CreateNotificationExperimentRequest true "Variants, traffic split and conversion goal"
+// @Success 201 {object} object{message=string,data=models.NotificationExperiment} "Experiment started"
+// @Failure 400 {object} map[string]interface{} "Invalid experiment"
+// @Failure 409 {object} map[string]interface{} "An experiment of the type is already running"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/notification-experiments [post]
+func (h *NotificationExperimentHandler) CreateNotificationExperiment(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Creating notification experiment via admin API", "user_id", userID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateNotificationExperimentRequest)
+
+	// Call service
+	experiment, err := h.experimentService.CreateExperiment(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to create notification experiment", "error", err, "type", req.Type)
+		h.respondWithError(c, err, "Failed to create notification experiment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Notification experiment started",
+		"data":    experiment,
+	})
+}
+
+// ListNotificationExperiments godoc
+// @Summary List notification wording experiments (Admin)
+// @Description List notification wording experiments, newest first, optionally by notification type or status
+// @Tags admin
+// @Produce json
+// @Param type query string false "Notification type"
+// @Param status query string false "Experiment status" Enums(running, concluded, stopped)
+// @Success 200 {object} object{data=[]models.NotificationExperiment} "Experiments"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/notification-experiments [get]
+func (h *NotificationExperimentHandler) ListNotificationExperiments(c *gin.Context) {
+	h.logger.Debug("Listing notification experiments via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListNotificationExperimentsRequest)
+
+	// Call service
+	experiments, err := h.experimentService.ListExperiments(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list notification experiments", "error", err)
+		h.respondWithError(c, err, "Failed to list notification experiments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": experiments,
+	})
+}
+
+// GetNotificationExperimentResults godoc
+// @Summary Notification experiment results (Admin)
+// @Description Per variant, the notifications sent, delivered and read (opened), and the share that converted within the experiment's conversion window: for the payment goal, a completed payment of the notification's order, or of any order when it has none; for the order goal, a new order. Reports the leading variant, variant B's lift over variant A, and whether the difference is significant at 95% confidence.
+// @Tags admin
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Success 200 {object} object{data=services.NotificationExperimentResultsResponse} "Experiment results"
+// @Failure 404 {object} map[string]interface{} "Experiment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/notification-experiments/{id}/results [get]
+func (h *NotificationExperimentHandler) GetNotificationExperimentResults(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	experimentID := c.Param("id")
+	h.logger.Debug("Getting notification experiment results via admin API", "experiment_id", experimentID)
+
+	// Call service
+	results, err := h.experimentService.GetResults(c.Request.Context(), experimentID)
+	if err != nil {
+		h.logger.Error("Failed to get notification experiment results", "error", err, "experiment_id", experimentID)
+		h.respondWithError(c, err, "Failed to get notification experiment results")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": results,
+	})
+}
+
+// StopNotificationExperiment godoc
+// @Summary Stop a notification wording experiment (Admin)
+// @Description End an experiment. Naming a winner concludes a running experiment, its winner's wording then being sent to everyone until a newer experiment of the type starts; without one, the catalog wording is sent again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Experiment ID"
+// @Param stop body services.StopNotificationExperimentRequest true "Winning variant, or {} to stop without one"
+// @Success 200 {object} object{message=string,data=models.NotificationExperiment} "Experiment stopped"
+// @Failure 400 {object} map[string]interface{} "Invalid winner"
+// @Failure 404 {object} map[string]interface{} "Experiment not found"
+// @Failure 409 {object} map[string]interface{} "Experiment already ended"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/notification-experiments/{id}/stop [post]
+func (h *NotificationExperimentHandler) StopNotificationExperiment(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	experimentID := c.Param("id")
+	h.logger.Debug("Stopping notification experiment via admin API", "experiment_id", experimentID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.StopNotificationExperimentRequest)
+
+	// Call service
+	experiment, err := h.experimentService.StopExperiment(c.Request.Context(), experimentID, req)
+	if err != nil {
+		h.logger.Error("Failed to stop notification experiment", "error", err, "experiment_id", experimentID)
+		h.respondWithError(c, err, "Failed to stop notification experiment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification experiment stopped",
+		"data":    experiment,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *NotificationExperimentHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler handles notification HTTP requests
+type NotificationHandler struct {
+	notificationService services.NotificationService
+	logger              *logger.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService services.NotificationService, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// SendBatch godoc
+// @Summary Send a notification batch (Admin)
+// @Description Enqueue up to 1000 notifications in one call; each recipient gets its own result (Admin only)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param batch body services.SendBatchNotificationsRequest true "Notifications to send"
+// @Success 200 {object} object{data=services.BatchNotificationResponse} "Per-recipient results"
+// @Failure 400 {object} map[string]interface{} "Invalid batch"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /notifications/batch [post]
+func (h *NotificationHandler) SendBatch(c *gin.Context) {
+	h.logger.Debug("Sending notification batch via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.SendBatchNotificationsRequest)
+
+	// Call service
+	response, err := h.notificationService.SendBatch(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to send notification batch", "error", err, "count", len(req.Notifications))
+
+		if strings.Contains(err.Error(), "batch must contain") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to send notifications",
+		})
+		return
+	}
+
+	h.logger.Info("Notification batch processed via API", "total", response.Total, "sent", response.Sent, "failed", response.Failed)
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListNotifications godoc
+// @Summary List my notifications
+// @Description Get the authenticated user's notifications, newest first, with when each was sent, delivered and read
+// @Tags notifications
+// @Produce json
+// @Param offset query int false "Number of notifications to skip" default(0)
+// @Param limit query int false "Number of notifications to return (max 100)" default(20)
+// @Param unread_only query bool false "Only list unread notifications"
+// @Success 200 {object} object{data=services.ListNotificationsResponse} "Notifications"
+// @Failure 400 {object} map[string]interface{} "Invalid query parameters"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	h.logger.Debug("Listing notifications via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListNotificationsRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	response, err := h.notificationService.GetUserNotifications(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to list notifications", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list notifications",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// MarkRead godoc
+// @Summary Mark a notification as read
+// @Description Mark one of the authenticated user's notifications as read. Marking a notification read again keeps the time it was first read.
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} object{data=services.NotificationResponse} "Notification read"
+// @Failure 404 {object} map[string]interface{} "Notification not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /notifications/{id}/read [put]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	notificationID := c.Param("id")
+	h.logger.Debug("Marking notification as read via API", "notification_id", notificationID)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	notification, err := h.notificationService.MarkRead(c.Request.Context(), userID, notificationID)
+	if err != nil {
+		h.logger.Error("Failed to mark notification as read", "error", err, "notification_id", notificationID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to mark notification as read",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": notification,
+	})
+}
+
+// RecordReceipt godoc
+// @Summary Record a notification receipt (Admin)
+// @Description Record that a notification was delivered to, or read by, its recipient, as reported by the channel provider. Only the first receipt of each kind counts, so retried receipts are accepted and ignored. A read receipt also marks the notification delivered. (Admin only)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Param receipt body services.NotificationReceiptRequest true "Receipt"
+// @Success 200 {object} object{data=services.NotificationResponse} "Receipt recorded"
+// @Failure 400 {object} map[string]interface{} "Invalid receipt"
+// @Failure 404 {object} map[string]interface{} "Notification not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /notifications/{id}/receipts [post]
+func (h *NotificationHandler) RecordReceipt(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	notificationID := c.Param("id")
+	h.logger.Debug("Recording notification receipt via API", "notification_id", notificationID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.NotificationReceiptRequest)
+
+	// Call service
+	notification, err := h.notificationService.RecordReceipt(c.Request.Context(), notificationID, req)
+	if err != nil {
+		h.logger.Error("Failed to record notification receipt", "error", err, "notification_id", notificationID, "status", req.Status)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record notification receipt"})
+		}
+		return
+	}
+
+	h.logger.Info("Notification receipt recorded via API", "notification_id", notificationID, "status", req.Status)
+	c.JSON(http.StatusOK, gin.H{
+		"data": notification,
+	})
+}
@@ -15,29 +15,35 @@ import (
 // OrderHandler handles order-related HTTP requests
 type OrderHandler struct {
 	orderService services.OrderService
+	queue        *services.AllocationQueue
 	logger       *logger.Logger
 }
 
 // NewOrderHandler creates a new order handler
-func NewOrderHandler(orderService services.OrderService, logger *logger.Logger) *OrderHandler {
+func NewOrderHandler(orderService services.OrderService, queue *services.AllocationQueue, logger *logger.Logger) *OrderHandler {
 	return &OrderHandler{
 		orderService: orderService,
+		queue:        queue,
 		logger:       logger,
 	}
 }
 
 // CreateOrder godoc
 // @Summary Create a new order
-// @Description Create a new order with items. User ID is automatically extracted from the JWT token.
+// @Description Create a new order with items. User ID is automatically extracted from the JWT token. Members of an organization with payment terms may set on_account to be invoiced instead of paying at checkout. On-account orders over the organization's credit limit are rejected, or created with credit_hold set when the organization holds them for review. An order with the same items as one the user placed minutes ago gets a duplicate_warning, or is refused until resent with confirm_duplicate when confirmation is required. While the flash sale allocation queue is on, an order whose turn for stock does not come quickly is answered with 202 and a queue ticket; resend it with queue_ticket set to keep its place. Limited releases cap the units of a product each customer may buy, ever or per period, counting their earlier orders that were not cancelled or failed. Stores offering gift options take a gift with an optional message for the recipient and hide_prices to leave prices off the packing slip. Orders by blocklisted customers, email domains or IP addresses are refused. A client may set request_id to make resubmitting safe: a submission with a request ID the user already placed an order with is answered with 200, the Idempotent-Replayed header and that order as it is now, instead of placing another. Setting delivery_slot_id books the order into one of the delivery slots listed by GET /delivery-slots, which must be in the region of the shipping address and have capacity left.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param order body services.CreateOrderRequest true "Order details (user_id is extracted from JWT, not request body)"
+// @Success 200 {object} object{message=string,data=services.OrderResponse} "Order already placed for this request ID"
 // @Success 201 {object} object{message=string,data=services.OrderResponse} "Order created successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Success 202 {object} object{message=string,data=services.AllocationQueuePosition} "Order queued for stock"
+// @Failure 400 {object} map[string]interface{} "Invalid request, order over the size limits, a product purchase limit reached (PURCHASE_LIMIT_EXCEEDED), or gift options the store does not offer"
 // @Failure 401 {object} map[string]interface{} "User authentication failed"
-// @Failure 404 {object} map[string]interface{} "User or product not found"
-// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Failure 403 {object} map[string]interface{} "Ordering on account is not allowed, or order blocked by fraud screening"
+// @Failure 404 {object} map[string]interface{} "User, product or delivery slot not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock, credit limit exceeded, store closed, delivery slot unavailable or fully booked, unconfirmed duplicate order, or request ID already used for a different order"
+// @Failure 429 {object} map[string]interface{} "Hourly order limit reached"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
 // @Router /orders [post]
@@ -67,13 +73,33 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 
 	// Override UserID with an authenticated user's ID for security
 	req.UserID = userID
+	req.ClientIP = c.ClientIP()
 	h.logger.Debug("Using authenticated user ID for order", "user_id", userID)
 
 	// Call service
 	order, err := h.orderService.CreateOrder(c.Request.Context(), req)
 	if err != nil {
+		// A queued order is not a failure: the client retries with its ticket
+		if position, queued := services.QueuePositionFromError(err); queued {
+			c.JSON(http.StatusAccepted, gin.H{
+				"message": "Order queued for stock; retry with the queue ticket",
+				"data":    position,
+			})
+			return
+		}
+
 		h.logger.Error("Failed to create order", "error", err, "user_id", req.UserID)
 
+		// Order limits carry their own status: 429 for the hourly order limit, 400 for
+		// the size of the order and for product purchase limits
+		if errors.IsErrorType(err, errors.ErrorTypeOrderItemLimit) || errors.IsErrorType(err, errors.ErrorTypeOrderQuantityLimit) ||
+			errors.IsErrorType(err, errors.ErrorTypeOrderRateLimit) || errors.IsErrorType(err, errors.ErrorTypePurchaseLimit) {
+			c.JSON(errors.GetStatusCode(err), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		// Handle specific error types
 		if strings.Contains(err.Error(), "not found") {
 			if strings.Contains(err.Error(), "user") {
@@ -92,14 +118,25 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 			return
 		}
 
-		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "not available") {
+		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "not available") ||
+			strings.Contains(err.Error(), "credit limit exceeded") || strings.Contains(err.Error(), "is closed") ||
+			strings.Contains(err.Error(), "fully booked") ||
+			strings.Contains(err.Error(), "possible duplicate order") || strings.Contains(err.Error(), "already used for a different order") {
 			c.JSON(http.StatusConflict, gin.H{
 				"error": err.Error(),
 			})
 			return
 		}
 
-		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid") {
+		if strings.Contains(err.Error(), "FORBIDDEN") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid") ||
+			strings.Contains(err.Error(), "VALIDATION_ERROR") || strings.Contains(err.Error(), "does not offer gift options") {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
 			})
@@ -112,13 +149,65 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	if order.Replayed {
+		h.logger.Info("Order submission replayed via API", "id", order.ID, "user_id", req.UserID)
+		c.Header(IdempotentReplayedHeader, "true")
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Order already placed for this request ID",
+			"data":    order,
+		})
+		return
+	}
+
 	h.logger.Info("Order created successfully via API", "id", order.ID, "user_id", req.UserID, "total", order.Total)
+	message := "Order created successfully"
+	if order.CreditHold {
+		message = "Order created and held for credit review"
+	} else if order.DuplicateWarning != nil {
+		message = "Order created; it looks like a duplicate of a recent order"
+	}
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "Order created successfully",
+		"message": message,
 		"data":    order,
 	})
 }
 
+// GetQueuePosition godoc
+// @Summary Get stock allocation queue position
+// @Description Get the place in the flash sale stock allocation queue of an order that was queued. Polling keeps the ticket from expiring; position 0 means the order is being placed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param ticket path string true "Queue ticket"
+// @Success 200 {object} object{data=services.AllocationQueuePosition} "Queue position"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "Queue ticket not found or expired"
+// @Security BearerAuth
+// @Router /orders/queue/{ticket} [get]
+func (h *OrderHandler) GetQueuePosition(c *gin.Context) {
+	ticketID := c.Param("ticket")
+
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		middleware.AbortWithError(c, errors.NewUnauthorizedError("User authentication failed"))
+		return
+	}
+
+	position, err := h.queue.Position(ticketID, userID)
+	if err != nil {
+		h.logger.Debug("Queue ticket not found", "ticket_id", ticketID, "user_id", userID)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Queue ticket not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": position,
+	})
+}
+
 // GetOrder godoc
 // @Summary Get order by ID
 // @Description Retrieve order details by order ID
@@ -205,16 +294,18 @@ func (h *OrderHandler) GetOrderStatus(c *gin.Context) {
 
 // CancelOrder godoc
 // @Summary Cancel order
-// @Description Cancel an existing order
+// @Description Cancel an order that has not shipped. Customers can only cancel their own orders; admins can cancel anyone's. Its reserved stock is returned to inventory and what was paid for it is refunded; if the refund fails the order stays cancelled, the error is returned and the payments can be refunded from the payment API. The customer is notified of the cancellation and the refund.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
 // @Success 200 {object} map[string]interface{} "Order cancelled successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid order ID"
+// @Failure 403 {object} map[string]interface{} "Not the user's own order"
 // @Failure 404 {object} map[string]interface{} "Order not found"
 // @Failure 409 {object} map[string]interface{} "Order cannot be cancelled"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 502 {object} map[string]interface{} "Refund failed"
 // @Security BearerAuth
 // @Router /orders/{id}/cancel [patch]
 func (h *OrderHandler) CancelOrder(c *gin.Context) {
@@ -222,6 +313,11 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	orderID := c.Param("id")
 	h.logger.Debug("Cancelling order via API", "id", orderID)
 
+	// Cancelling refunds the order, so customers may only cancel their own
+	if !h.authorizeOrderOwner(c, orderID) {
+		return
+	}
+
 	// Call service
 	err := h.orderService.CancelOrder(c.Request.Context(), orderID)
 	if err != nil {
@@ -241,6 +337,13 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 			return
 		}
 
+		if isRefundError(err) {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to cancel order",
 		})
@@ -253,6 +356,176 @@ func (h *OrderHandler) CancelOrder(c *gin.Context) {
 	})
 }
 
+// CancelOrderItems godoc
+// @Summary Cancel order items
+// @Description Cancel some of the items of an order that has not shipped. Customers can only cancel items of their own orders; admins can cancel anyone's. The order total is reduced by the cancelled items and their share of the payment method adjustment, which is refunded if the order was paid. Their reserved stock is returned to inventory and the customer is notified. To cancel every item, cancel the order.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param items body services.CancelOrderItemsRequest true "Quantities of products to cancel"
+// @Success 200 {object} object{message=string,data=services.OrderResponse} "Order items cancelled"
+// @Failure 400 {object} map[string]interface{} "Invalid quantities"
+// @Failure 403 {object} map[string]interface{} "Not the user's own order"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order cannot be cancelled"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 502 {object} map[string]interface{} "Refund failed"
+// @Security BearerAuth
+// @Router /orders/{id}/items/cancel [patch]
+func (h *OrderHandler) CancelOrderItems(c *gin.Context) {
+	// Middleware does path parameter validation
+	orderID := c.Param("id")
+	h.logger.Debug("Cancelling order items via API", "id", orderID)
+
+	// Cancelling refunds the order, so customers may only cancel items of their own
+	if !h.authorizeOrderOwner(c, orderID) {
+		return
+	}
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CancelOrderItemsRequest)
+
+	// Call service
+	order, err := h.orderService.CancelOrderItems(c.Request.Context(), orderID, req)
+	if err != nil {
+		h.logger.Error("Failed to cancel order items", "error", err, "id", orderID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "cannot be cancelled"), strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case isRefundError(err):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order items"})
+		}
+		return
+	}
+
+	h.logger.Info("Order items cancelled via API", "id", orderID, "total", order.Total, "refunded", order.Refunded)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order items cancelled",
+		"data":    order,
+	})
+}
+
+// authorizeOrderOwner responds and returns false unless the order exists and belongs
+// to the current user, or the current user is an admin
+func (h *OrderHandler) authorizeOrderOwner(c *gin.Context, orderID string) bool {
+	currentUserID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return false
+	}
+	if middleware.IsCurrentUserAdmin(c) {
+		return true
+	}
+
+	order, err := h.orderService.GetOrder(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to get order", "error", err, "id", orderID)
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order"})
+		return false
+	}
+	if order.UserID != currentUserID {
+		appErr := errors.NewForbiddenError("cannot change another user's order")
+		middleware.AbortWithError(c, appErr)
+		return false
+	}
+	return true
+}
+
+// GetUserOrders godoc
+// @Summary List a user's orders
+// @Description Get a paginated list of a user's orders, newest first, optionally only those in a status. Customers can only list their own orders; admins can list anyone's.
+// @Tags orders
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Filter by order status"
+// @Success 200 {object} object{data=services.ListOrdersResponse} "List of the user's orders"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 403 {object} map[string]interface{} "Not the user's own orders"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /users/{id}/orders [get]
+func (h *OrderHandler) GetUserOrders(c *gin.Context) {
+	// Middleware does path parameter validation
+	userID := c.Param("id")
+	h.logger.Debug("Listing user orders via API", "user_id", userID)
+
+	currentUserID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	if currentUserID != userID && !middleware.IsCurrentUserAdmin(c) {
+		appErr := errors.NewForbiddenError("cannot list another user's orders")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListUserOrdersRequest)
+
+	// Call service
+	response, err := h.orderService.GetUserOrders(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to list user orders", "error", err, "user_id", userID)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list user orders",
+		})
+		return
+	}
+
+	h.logger.Debug("User orders listed successfully via API", "user_id", userID, "count", len(response.Orders))
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// isRefundError reports whether a cancellation failed because its refund did not go through
+func isRefundError(err error) bool {
+	return errors.IsErrorType(err, errors.ErrorTypeExternal) || strings.Contains(err.Error(), "refund declined")
+}
+
 // ListOrders godoc
 // @Summary List orders
 // @Description Get a paginated list of orders with optional status filter
@@ -279,6 +552,7 @@ func (h *OrderHandler) ListOrders(c *gin.Context) {
 
 	// Type asserts to the expected request type
 	req := *validatedQuery.(*services.ListOrdersRequest)
+	req.RiskBand = "" // Risk scores are for admins
 
 	// Call service
 	response, err := h.orderService.ListOrders(c.Request.Context(), req)
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderHoldHandler handles order compliance hold HTTP requests
+type OrderHoldHandler struct {
+	orderHoldService services.OrderHoldService
+	logger           *logger.Logger
+}
+
+// NewOrderHoldHandler creates a new order hold handler
+func NewOrderHoldHandler(orderHoldService services.OrderHoldService, logger *logger.Logger) *OrderHoldHandler {
+	return &OrderHoldHandler{
+		orderHoldService: orderHoldService,
+		logger:           logger,
+	}
+}
+
+// PlaceOrderHold godoc
+// @Summary Put an order on hold (Admin)
+// @Description Put an order that has not shipped on a compliance hold for fraud, export control or an address issue, optionally assigning an admin to review it. The order cannot ship until the hold is released, and the hold is overdue once it has been active longer than the hold SLA.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param hold body services.PlaceOrderHoldRequest true "Hold reason and reviewer"
+// @Success 201 {object} object{message=string,data=services.OrderHoldResponse} "Order put on hold"
+// @Failure 400 {object} map[string]interface{} "Invalid reason or reviewer"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order already on hold, or shipped"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/holds [post]
+func (h *OrderHoldHandler) PlaceOrderHold(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Placing order hold via admin API", "order_id", orderID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PlaceOrderHoldRequest)
+
+	// Extract the admin's ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	hold, err := h.orderHoldService.PlaceHold(c.Request.Context(), orderID, userID, req)
+	if err != nil {
+		h.logger.Error("Failed to place order hold", "error", err, "order_id", orderID)
+		h.respondWithError(c, err, "Failed to place order hold")
+		return
+	}
+
+	h.logger.Info("Order put on hold via admin API", "order_id", orderID, "hold_id", hold.ID,
+		"user_id", userID, "reason", req.Reason)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Order put on hold",
+		"data":    hold,
+	})
+}
+
+// GetOrderHolds godoc
+// @Summary Get an order's holds (Admin)
+// @Description Get the compliance holds of an order, active and released, newest first
+// @Tags admin
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} object{data=[]services.OrderHoldResponse} "Order holds"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/holds [get]
+func (h *OrderHoldHandler) GetOrderHolds(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Getting order holds via admin API", "order_id", orderID)
+
+	holds, err := h.orderHoldService.GetOrderHolds(c.Request.Context(), orderID)
+	if err != nil {
+		h.logger.Error("Failed to get order holds", "error", err, "order_id", orderID)
+		h.respondWithError(c, err, "Failed to get order holds")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": holds,
+	})
+}
+
+// ListOrderHolds godoc
+// @Summary List order holds (Admin)
+// @Description List compliance holds, oldest due first, optionally only active or released ones, of one reason, assigned to one reviewer, or overdue
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "active or released"
+// @Param reason query string false "fraud, export_control or address_issue"
+// @Param assigned_to query string false "Reviewer user ID"
+// @Param overdue query bool false "Only active holds past their SLA"
+// @Success 200 {object} object{data=services.ListOrderHoldsResponse} "List of order holds"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/order-holds [get]
+func (h *OrderHoldHandler) ListOrderHolds(c *gin.Context) {
+	h.logger.Debug("Listing order holds via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListOrderHoldsRequest)
+
+	// Call service
+	response, err := h.orderHoldService.ListHolds(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list order holds", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list order holds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// AssignOrderHold godoc
+// @Summary Assign an order hold (Admin)
+// @Description Assign an active compliance hold to an admin for review
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param assignment body services.AssignOrderHoldRequest true "Reviewer"
+// @Success 200 {object} object{message=string,data=services.OrderHoldResponse} "Order hold assigned"
+// @Failure 400 {object} map[string]interface{} "Reviewer is not an active admin"
+// @Failure 404 {object} map[string]interface{} "Hold not found"
+// @Failure 409 {object} map[string]interface{} "Hold already released"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/order-holds/{id}/assignee [put]
+func (h *OrderHoldHandler) AssignOrderHold(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	holdID := c.Param("id")
+	h.logger.Debug("Assigning order hold via admin API", "hold_id", holdID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.AssignOrderHoldRequest)
+
+	// Call service
+	hold, err := h.orderHoldService.AssignHold(c.Request.Context(), holdID, req)
+	if err != nil {
+		h.logger.Error("Failed to assign order hold", "error", err, "hold_id", holdID)
+		h.respondWithError(c, err, "Failed to assign order hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order hold assigned",
+		"data":    hold,
+	})
+}
+
+// ReleaseOrderHold godoc
+// @Summary Release an order hold (Admin)
+// @Description Release an active compliance hold, after which the order can ship
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param release body services.ReleaseOrderHoldRequest true "Release note"
+// @Success 200 {object} object{message=string,data=services.OrderHoldResponse} "Order hold released"
+// @Failure 404 {object} map[string]interface{} "Hold not found"
+// @Failure 409 {object} map[string]interface{} "Hold already released"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/order-holds/{id}/release [post]
+func (h *OrderHoldHandler) ReleaseOrderHold(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	holdID := c.Param("id")
+	h.logger.Debug("Releasing order hold via admin API", "hold_id", holdID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ReleaseOrderHoldRequest)
+
+	// Extract the admin's ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	hold, err := h.orderHoldService.ReleaseHold(c.Request.Context(), holdID, userID, req)
+	if err != nil {
+		h.logger.Error("Failed to release order hold", "error", err, "hold_id", holdID)
+		h.respondWithError(c, err, "Failed to release order hold")
+		return
+	}
+
+	h.logger.Info("Order hold released via admin API", "hold_id", holdID, "order_id", hold.OrderID, "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order hold released",
+		"data":    hold,
+	})
+}
+
+// GetOrderHoldMetrics godoc
+// @Summary Get order hold metrics (Admin)
+// @Description Get the share of orders placed in a date range that were put on hold and, per hold reason, how many holds placed in the range were released, are active or overdue, how long released holds lasted, and how many were released within the hold SLA. Defaults to the last 7 days.
+// @Tags admin
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD), inclusive"
+// @Success 200 {object} object{data=services.OrderHoldMetricsResponse} "Order hold metrics"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/order-holds/metrics [get]
+func (h *OrderHoldHandler) GetOrderHoldMetrics(c *gin.Context) {
+	h.logger.Debug("Getting order hold metrics via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.OrderHoldMetricsRequest)
+
+	// Call service
+	metrics, err := h.orderHoldService.GetHoldMetrics(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to get order hold metrics", "error", err)
+		h.respondWithError(c, err, "Failed to get order hold metrics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": metrics,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *OrderHoldHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
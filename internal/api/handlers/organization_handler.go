@@ -0,0 +1,495 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationHandler handles B2B organization account HTTP requests
+type OrganizationHandler struct {
+	organizationService services.OrganizationService
+	logger              *logger.Logger
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService services.OrganizationService, logger *logger.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		logger:              logger,
+	}
+}
+
+// GetMyOrganization godoc
+// @Summary Get my organization
+// @Description Get the organization account the authenticated user belongs to, with its members and credit terms
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=services.OrganizationResponse} "Organization"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "User does not belong to an organization"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /organizations/me [get]
+func (h *OrganizationHandler) GetMyOrganization(c *gin.Context) {
+	h.logger.Debug("Getting user organization via API")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	organization, err := h.organizationService.GetUserOrganization(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get user organization", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to get organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": organization,
+	})
+}
+
+// ListMyOrganizationOrders godoc
+// @Summary List my organization's orders
+// @Description List the orders placed by every member of the authenticated user's organization
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} object{data=services.ListOrdersResponse} "Organization orders"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "User does not belong to an organization"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /organizations/me/orders [get]
+func (h *OrganizationHandler) ListMyOrganizationOrders(c *gin.Context) {
+	h.logger.Debug("Listing organization orders via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListOrdersRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	orders, err := h.organizationService.ListOrganizationOrders(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to list organization orders", "error", err, "user_id", userID)
+		h.writeError(c, err, "Failed to list organization orders")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": orders,
+	})
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization (Admin)
+// @Description Create a B2B customer account. Members may order on account when payment_terms_days is greater than zero.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param organization body services.CreateOrganizationRequest true "Organization to create"
+// @Success 201 {object} object{message=string,data=services.OrganizationResponse} "Organization created"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 409 {object} map[string]interface{} "Organization name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	h.logger.Debug("Creating organization via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateOrganizationRequest)
+
+	// Call service
+	organization, err := h.organizationService.CreateOrganization(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create organization", "error", err, "name", req.Name)
+		h.writeError(c, err, "Failed to create organization")
+		return
+	}
+
+	h.logger.Info("Organization created successfully via admin API", "id", organization.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Organization created successfully",
+		"data":    organization,
+	})
+}
+
+// ListOrganizations godoc
+// @Summary List organizations (Admin)
+// @Description Get a paginated list of B2B customer accounts, by name
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} object{data=services.ListOrganizationsResponse} "List of organizations"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	h.logger.Debug("Listing organizations via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListOrganizationsRequest)
+
+	// Call service
+	organizations, err := h.organizationService.ListOrganizations(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list organizations", "error", err)
+		h.writeError(c, err, "Failed to list organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": organizations,
+	})
+}
+
+// GetOrganization godoc
+// @Summary Get an organization (Admin)
+// @Description Get a B2B customer account with its members and credit terms
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} object{data=services.OrganizationResponse} "Organization"
+// @Failure 404 {object} map[string]interface{} "Organization not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	organizationID := c.Param("id")
+	h.logger.Debug("Getting organization via admin API", "id", organizationID)
+
+	// Call service
+	organization, err := h.organizationService.GetOrganization(c.Request.Context(), organizationID)
+	if err != nil {
+		h.logger.Error("Failed to get organization", "error", err, "id", organizationID)
+		h.writeError(c, err, "Failed to get organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": organization,
+	})
+}
+
+// UpdateOrganization godoc
+// @Summary Update an organization (Admin)
+// @Description Update a B2B customer account's name, credit limit, payment terms or active flag. Deactivated organizations can't order on account.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param organization body services.UpdateOrganizationRequest true "Fields to update"
+// @Success 200 {object} object{message=string,data=services.OrganizationResponse} "Organization updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Organization not found"
+// @Failure 409 {object} map[string]interface{} "Organization name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations/{id} [patch]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	organizationID := c.Param("id")
+	h.logger.Debug("Updating organization via admin API", "id", organizationID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdateOrganizationRequest)
+
+	// Call service
+	organization, err := h.organizationService.UpdateOrganization(c.Request.Context(), organizationID, req)
+	if err != nil {
+		h.logger.Error("Failed to update organization", "error", err, "id", organizationID)
+		h.writeError(c, err, "Failed to update organization")
+		return
+	}
+
+	h.logger.Info("Organization updated successfully via admin API", "id", organizationID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Organization updated successfully",
+		"data":    organization,
+	})
+}
+
+// AddOrganizationMember godoc
+// @Summary Add an organization member (Admin)
+// @Description Add a user to a B2B customer account. A user belongs to at most one organization.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param member body services.AddOrganizationMemberRequest true "User to add"
+// @Success 200 {object} object{message=string,data=services.OrganizationResponse} "Member added"
+// @Failure 404 {object} map[string]interface{} "Organization or user not found"
+// @Failure 409 {object} map[string]interface{} "User belongs to another organization"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations/{id}/members [post]
+func (h *OrganizationHandler) AddOrganizationMember(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	organizationID := c.Param("id")
+	h.logger.Debug("Adding organization member via admin API", "id", organizationID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.AddOrganizationMemberRequest)
+
+	// Call service
+	organization, err := h.organizationService.AddMember(c.Request.Context(), organizationID, req.UserID)
+	if err != nil {
+		h.logger.Error("Failed to add organization member", "error", err, "id", organizationID, "user_id", req.UserID)
+		h.writeError(c, err, "Failed to add organization member")
+		return
+	}
+
+	h.logger.Info("Organization member added via admin API", "id", organizationID, "user_id", req.UserID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member added successfully",
+		"data":    organization,
+	})
+}
+
+// RemoveOrganizationMember godoc
+// @Summary Remove an organization member (Admin)
+// @Description Remove a user from a B2B customer account. Orders the user already placed stay with the organization.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} map[string]interface{} "Member removed"
+// @Failure 404 {object} map[string]interface{} "User is not a member of the organization"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveOrganizationMember(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	organizationID := c.Param("id")
+	userID := c.Param("user_id")
+	h.logger.Debug("Removing organization member via admin API", "id", organizationID, "user_id", userID)
+
+	// Call service
+	if err := h.organizationService.RemoveMember(c.Request.Context(), organizationID, userID); err != nil {
+		h.logger.Error("Failed to remove organization member", "error", err, "id", organizationID, "user_id", userID)
+		h.writeError(c, err, "Failed to remove organization member")
+		return
+	}
+
+	h.logger.Info("Organization member removed via admin API", "id", organizationID, "user_id", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// ImportUsers godoc
+// @Summary Import users (Admin)
+// @Description Create customer accounts in bulk from a CSV with a header row, assigning them to organizations by name. Missing organizations are created without credit terms. Existing users are only added to the row's organization. Users imported without a password get a random one and must reset it.
+// @Tags admin
+// @Accept mpfd,text/csv
+// @Produce json
+// @Param file formData file false "User CSV (email,name and optional organization,password)"
+// @Success 200 {object} object{message=string,data=services.UserImportResponse} "Users imported"
+// @Failure 400 {object} map[string]interface{} "Invalid CSV file"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/import [post]
+func (h *OrganizationHandler) ImportUsers(c *gin.Context) {
+	h.logger.Debug("Importing users via admin API")
+
+	// Accept either a multipart upload or a raw CSV body
+	var source io.Reader = c.Request.Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.logger.Error("Failed to open uploaded user import file", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read uploaded file",
+			})
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	// Call service
+	response, err := h.organizationService.ImportUsers(c.Request.Context(), source)
+	if err != nil {
+		h.logger.Error("Failed to import users", "error", err)
+		h.writeError(c, err, "Failed to import users")
+		return
+	}
+
+	h.logger.Info("Users imported via admin API",
+		"total_rows", response.TotalRows, "created", response.Created,
+		"updated", response.Updated, "failed", response.Failed)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Users imported",
+		"data":    response,
+	})
+}
+
+// ListCreditHolds godoc
+// @Summary List orders on credit hold (Admin)
+// @Description List on-account orders held for exceeding their organization's credit limit, oldest first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.OrderResponse} "Orders on credit hold"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/credit-holds [get]
+func (h *OrganizationHandler) ListCreditHolds(c *gin.Context) {
+	h.logger.Debug("Listing orders on credit hold via admin API")
+
+	// Call service
+	orders, err := h.organizationService.ListCreditHolds(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list orders on credit hold", "error", err)
+		h.writeError(c, err, "Failed to list orders on credit hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": orders,
+	})
+}
+
+// ReviewCreditHold godoc
+// @Summary Review an order on credit hold (Admin)
+// @Description Approve an on-account order held for exceeding its organization's credit limit, overriding the limit, or reject it, which cancels the order. The reviewing admin is recorded on the order.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param review body services.ReviewCreditHoldRequest true "Review decision"
+// @Success 200 {object} object{message=string,data=services.OrderResponse} "Credit hold reviewed"
+// @Failure 400 {object} map[string]interface{} "Invalid decision"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order is not on credit hold"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/credit-review [post]
+func (h *OrganizationHandler) ReviewCreditHold(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	h.logger.Debug("Reviewing order credit hold via admin API", "id", orderID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ReviewCreditHoldRequest)
+
+	// Extract reviewer ID from JWT context (authenticated admin)
+	reviewerID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	order, err := h.organizationService.ReviewCreditHold(c.Request.Context(), orderID, reviewerID, req)
+	if err != nil {
+		h.logger.Error("Failed to review order credit hold", "error", err, "id", orderID)
+		h.writeError(c, err, "Failed to review order credit hold")
+		return
+	}
+
+	h.logger.Info("Order credit hold reviewed via admin API", "id", orderID, "reviewer_id", reviewerID, "decision", req.Decision)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Credit hold reviewed",
+		"data":    order,
+	})
+}
+
+// writeError maps an organization service error to a response
+func (h *OrganizationHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/services"
 	"easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
@@ -15,29 +16,33 @@ import (
 // PaymentHandler handles payment-related HTTP requests
 type PaymentHandler struct {
 	paymentService services.PaymentService
+	orderService   services.OrderService
 	logger         *logger.Logger
 }
 
 // NewPaymentHandler creates a new payment handler
-func NewPaymentHandler(paymentService services.PaymentService, logger *logger.Logger) *PaymentHandler {
+func NewPaymentHandler(paymentService services.PaymentService, orderService services.OrderService, logger *logger.Logger) *PaymentHandler {
 	return &PaymentHandler{
 		paymentService: paymentService,
+		orderService:   orderService,
 		logger:         logger,
 	}
 }
 
 // ProcessPayment godoc
 // @Summary Process a payment
-// @Description Process payment for an order
+// @Description Process payment for an order. An order can be paid in several payments up to its total, and becomes paid once they cover it. Payments by blocklisted customers, cards or IP addresses are refused, as are payments a routing rule settles outside the gateways, which are recorded as manual payments. Card payments are taken through payment intents instead.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Param payment body services.ProcessPaymentRequest true "Payment details"
 // @Success 201 {object} object{message=string,data=services.PaymentResponse} "Payment processed successfully"
-// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Success 202 {object} object{message=string,data=services.PaymentResponse} "Payment held for retry"
+// @Failure 400 {object} map[string]interface{} "Invalid request, card payment or payment settled outside the gateways"
 // @Failure 404 {object} map[string]interface{} "Order not found"
-// @Failure 409 {object} map[string]interface{} "Order already paid"
+// @Failure 409 {object} map[string]interface{} "Order already paid or payment held for retry"
 // @Failure 402 {object} map[string]interface{} "Payment processing failed"
+// @Failure 403 {object} map[string]interface{} "Payment blocked by fraud screening"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
 // @Router /payments [post]
@@ -55,6 +60,15 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 
 	// Type assert to the expected request type
 	req := *validatedReq.(*services.ProcessPaymentRequest)
+	req.ClientIP = c.ClientIP()
+
+	// Card payments are confirmed once the gateway's client SDK has collected the card
+	if models.PaymentMethod(req.PaymentType).IsCard() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Card payments are taken through payment intents: create one with POST /payment-intents and confirm it",
+		})
+		return
+	}
 
 	// Call service
 	payment, err := h.paymentService.ProcessPayment(c.Request.Context(), req)
@@ -76,7 +90,22 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 			return
 		}
 
-		if strings.Contains(err.Error(), "does not match") || strings.Contains(err.Error(), "cannot be paid") {
+		if strings.Contains(err.Error(), "FORBIDDEN") {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "held for retry") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "does not match") || strings.Contains(err.Error(), "left to pay") ||
+			strings.Contains(err.Error(), "cannot be paid") {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
 			})
@@ -96,6 +125,15 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
+	if payment.Status == models.PaymentStatusHeld {
+		h.logger.Info("Payment held for retry via API", "id", payment.ID, "order_id", req.OrderID, "retry_at", payment.RetryAt)
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Payment held for retry",
+			"data":    payment,
+		})
+		return
+	}
+
 	h.logger.Info("Payment processed successfully via API", "id", payment.ID, "order_id", req.OrderID, "amount", req.Amount)
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Payment processed successfully",
@@ -103,6 +141,88 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 	})
 }
 
+// ResumePayment godoc
+// @Summary Resume a held payment
+// @Description Retry a payment held after a transient failure now instead of waiting for the scheduled retry. The payment fails once its retry window has expired. Customers can only resume payments of their own orders.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} object{message=string,data=services.PaymentResponse} "Payment processed successfully"
+// @Success 202 {object} object{message=string,data=services.PaymentResponse} "Payment held for retry"
+// @Failure 400 {object} map[string]interface{} "Payment not held or order cannot be paid"
+// @Failure 403 {object} map[string]interface{} "Not a payment of the user's own order"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "Payment already being retried"
+// @Failure 402 {object} map[string]interface{} "Payment processing failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payments/{id}/resume [post]
+func (h *PaymentHandler) ResumePayment(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	paymentID := c.Param("id")
+	h.logger.Debug("Resuming payment via API", "id", paymentID)
+
+	// Held payments are resumed on schedule, so customers may only resume their own
+	if !h.authorizePaymentOwner(c, paymentID) {
+		return
+	}
+
+	// Call service
+	payment, err := h.paymentService.ResumePayment(c.Request.Context(), paymentID)
+	if err != nil {
+		h.logger.Error("Failed to resume payment", "error", err, "id", paymentID)
+
+		if strings.Contains(err.Error(), "processing failed") {
+			c.JSON(http.StatusPaymentRequired, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "payment not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Payment not found",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "already being retried") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "not held") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to resume payment",
+		})
+		return
+	}
+
+	if payment.Status == models.PaymentStatusHeld {
+		h.logger.Info("Payment held for retry again via API", "id", paymentID, "retry_at", payment.RetryAt)
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Payment held for retry",
+			"data":    payment,
+		})
+		return
+	}
+
+	h.logger.Info("Held payment processed successfully via API", "id", paymentID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment processed successfully",
+		"data":    payment,
+	})
+}
+
 // GetPayment godoc
 // @Summary Get payment by ID
 // @Description Retrieve payment details by payment ID
@@ -177,3 +297,157 @@ func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
 		"data": payments,
 	})
 }
+
+// RefundPayment godoc
+// @Summary Refund a payment (Admin)
+// @Description Return part or all of a completed payment to the customer, through the gateway that took it. A payment can be refunded several times, but never by more than was captured; it becomes refunded once all of it has been returned. Each refund is added to the payment's refund history.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param refund body services.RefundRequest true "Refund"
+// @Success 201 {object} object{message=string,data=services.PaymentResponse} "Payment refunded"
+// @Failure 400 {object} map[string]interface{} "Invalid request, refund over what is left to refund, or refund declined by the gateway"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 409 {object} map[string]interface{} "Payment not completed, or refunded concurrently"
+// @Failure 502 {object} map[string]interface{} "Gateway refund failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payments/{id}/refunds [post]
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	paymentID := c.Param("id")
+	h.logger.Debug("Refunding payment via API", "id", paymentID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type assert to the expected request type
+	req := *validatedReq.(*services.RefundRequest)
+
+	// Extract the refunding admin's ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	req.RequestedBy = userID
+
+	// Call service
+	payment, err := h.paymentService.RefundPayment(c.Request.Context(), paymentID, req)
+	if err != nil {
+		h.logger.Error("Failed to refund payment", "error", err, "id", paymentID)
+
+		switch {
+		case strings.Contains(err.Error(), "NOT_FOUND"):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "CONFLICT"):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "VALIDATION_ERROR"), strings.Contains(err.Error(), "BUSINESS_ERROR"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case strings.Contains(err.Error(), "EXTERNAL_SERVICE_ERROR"):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Gateway refund failed"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refund payment"})
+		}
+		return
+	}
+
+	h.logger.Info("Payment refunded via API", "id", paymentID, "amount", req.Amount, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment refunded successfully",
+		"data":    payment,
+	})
+}
+
+// ListRefunds godoc
+// @Summary List payment refunds
+// @Description Get the refunds of a payment, oldest first, with how much of it has been refunded and how much is left to refund. Customers can only list refunds of payments of their own orders.
+// @Tags payments
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} object{data=services.PaymentRefundsResponse} "Payment refunds"
+// @Failure 403 {object} map[string]interface{} "Not a payment of the user's own order"
+// @Failure 404 {object} map[string]interface{} "Payment not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payments/{id}/refunds [get]
+func (h *PaymentHandler) ListRefunds(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	paymentID := c.Param("id")
+	h.logger.Debug("Listing payment refunds via API", "id", paymentID)
+
+	// Refund amounts and reasons are only shown to the payment's owner
+	if !h.authorizePaymentOwner(c, paymentID) {
+		return
+	}
+
+	// Call service
+	refunds, err := h.paymentService.ListRefunds(c.Request.Context(), paymentID)
+	if err != nil {
+		h.logger.Error("Failed to list payment refunds", "error", err, "id", paymentID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Payment not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list payment refunds",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": refunds,
+	})
+}
+
+// authorizePaymentOwner responds and returns false unless the payment exists and is
+// for an order of the current user, or the current user is an admin
+func (h *PaymentHandler) authorizePaymentOwner(c *gin.Context, paymentID string) bool {
+	currentUserID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return false
+	}
+	if middleware.IsCurrentUserAdmin(c) {
+		return true
+	}
+
+	payment, err := h.paymentService.GetPayment(c.Request.Context(), paymentID)
+	if err != nil {
+		h.logger.Error("Failed to get payment", "error", err, "id", paymentID)
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get payment"})
+		return false
+	}
+
+	order, err := h.orderService.GetOrder(c.Request.Context(), payment.OrderID)
+	if err != nil {
+		h.logger.Error("Failed to get payment order", "error", err, "id", paymentID, "order_id", payment.OrderID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get payment"})
+		return false
+	}
+	if order.UserID != currentUserID {
+		appErr := errors.NewForbiddenError("cannot access another user's payment")
+		middleware.AbortWithError(c, appErr)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentIntentHandler handles payment intent HTTP requests
+type PaymentIntentHandler struct {
+	intentService services.PaymentIntentService
+	logger        *logger.Logger
+}
+
+// NewPaymentIntentHandler creates a new payment intent handler
+func NewPaymentIntentHandler(intentService services.PaymentIntentService, logger *logger.Logger) *PaymentIntentHandler {
+	return &PaymentIntentHandler{
+		intentService: intentService,
+		logger:        logger,
+	}
+}
+
+// CreateIntent godoc
+// @Summary Create a payment intent
+// @Description Open a card payment for one of the authenticated user's orders, before the card is collected with the gateway's client SDK. The amount defaults to what is left to pay of the order. An order has at most one open intent; intents not confirmed in time expire.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param intent body services.CreatePaymentIntentRequest true "Payment intent"
+// @Success 201 {object} object{message=string,data=services.PaymentIntentResponse} "Payment intent created"
+// @Failure 400 {object} map[string]interface{} "Invalid request or order cannot be paid"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "Order not found"
+// @Failure 409 {object} map[string]interface{} "Order already has an open payment intent"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-intents [post]
+func (h *PaymentIntentHandler) CreateIntent(c *gin.Context) {
+	h.logger.Debug("Creating payment intent via API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreatePaymentIntentRequest)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	intent, err := h.intentService.CreateIntent(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to create payment intent", "error", err, "user_id", userID, "order_id", req.OrderID)
+		h.writeError(c, err, "Failed to create payment intent")
+		return
+	}
+
+	h.logger.Info("Payment intent created via API", "id", intent.ID, "order_id", intent.OrderID, "user_id", userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment intent created",
+		"data":    intent,
+	})
+}
+
+// GetIntent godoc
+// @Summary Get a payment intent
+// @Description Get one of the authenticated user's payment intents with its payment. A processing intent follows its payment once the payment completes or fails.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Success 200 {object} object{data=services.PaymentIntentResponse} "Payment intent"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "Payment intent not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-intents/{id} [get]
+func (h *PaymentIntentHandler) GetIntent(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	intentID := c.Param("id")
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	intent, err := h.intentService.GetIntent(c.Request.Context(), userID, intentID)
+	if err != nil {
+		h.logger.Error("Failed to get payment intent", "error", err, "id", intentID)
+		h.writeError(c, err, "Failed to get payment intent")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": intent,
+	})
+}
+
+// ConfirmIntent godoc
+// @Summary Confirm a payment intent
+// @Description Take the payment of a payment intent with the card token collected by the gateway's client SDK. A completed payment succeeds the intent, and pays the order once its payments cover the total. A payment held for retry or awaiting the gateway leaves the intent processing. A declined payment leaves the intent awaiting confirmation, to be confirmed again with another card.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Param confirmation body services.ConfirmPaymentIntentRequest true "Card collected by the gateway"
+// @Success 200 {object} object{message=string,data=services.PaymentIntentResponse} "Payment intent succeeded"
+// @Success 202 {object} object{message=string,data=services.PaymentIntentResponse} "Payment intent processing"
+// @Failure 400 {object} map[string]interface{} "Invalid request, intent not awaiting confirmation or order cannot be paid"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 402 {object} map[string]interface{} "Payment declined"
+// @Failure 403 {object} map[string]interface{} "Payment blocked by fraud screening"
+// @Failure 404 {object} map[string]interface{} "Payment intent not found"
+// @Failure 409 {object} map[string]interface{} "Payment intent confirmed concurrently, or another payment in flight"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-intents/{id}/confirm [post]
+func (h *PaymentIntentHandler) ConfirmIntent(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	intentID := c.Param("id")
+	h.logger.Debug("Confirming payment intent via API", "id", intentID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ConfirmPaymentIntentRequest)
+	req.ClientIP = c.ClientIP()
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	intent, err := h.intentService.ConfirmIntent(c.Request.Context(), userID, intentID, req)
+	if err != nil {
+		h.logger.Error("Failed to confirm payment intent", "error", err, "id", intentID)
+		h.writeError(c, err, "Failed to confirm payment intent")
+		return
+	}
+
+	h.logger.Info("Payment intent confirmed via API", "id", intent.ID, "order_id", intent.OrderID, "status", intent.Status)
+	if intent.Status != models.PaymentIntentStatusSucceeded {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Payment intent processing",
+			"data":    intent,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment intent succeeded",
+		"data":    intent,
+	})
+}
+
+// CancelIntent godoc
+// @Summary Cancel a payment intent
+// @Description Cancel one of the authenticated user's payment intents before it is confirmed
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment intent ID"
+// @Success 200 {object} object{message=string,data=services.PaymentIntentResponse} "Payment intent cancelled"
+// @Failure 400 {object} map[string]interface{} "Payment intent not awaiting confirmation"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 404 {object} map[string]interface{} "Payment intent not found"
+// @Failure 409 {object} map[string]interface{} "Payment intent confirmed concurrently"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /payment-intents/{id}/cancel [post]
+func (h *PaymentIntentHandler) CancelIntent(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	intentID := c.Param("id")
+	h.logger.Debug("Cancelling payment intent via API", "id", intentID)
+
+	// Extract user ID from JWT context (authenticated user)
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	intent, err := h.intentService.CancelIntent(c.Request.Context(), userID, intentID)
+	if err != nil {
+		h.logger.Error("Failed to cancel payment intent", "error", err, "id", intentID)
+		h.writeError(c, err, "Failed to cancel payment intent")
+		return
+	}
+
+	h.logger.Info("Payment intent cancelled via API", "id", intent.ID, "order_id", intent.OrderID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment intent cancelled",
+		"data":    intent,
+	})
+}
+
+// writeError maps a payment intent service error to a response
+func (h *PaymentIntentHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "PAYMENT_FAILED"):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "FORBIDDEN"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"), strings.Contains(err.Error(), "BUSINESS_ERROR"),
+		strings.Contains(err.Error(), "INVALID_TRANSITION"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentRoutingHandler handles payment routing rule HTTP requests
+type PaymentRoutingHandler struct {
+	routingService services.PaymentRoutingService
+	logger         *logger.Logger
+}
+
+// NewPaymentRoutingHandler creates a new payment routing handler
+func NewPaymentRoutingHandler(routingService services.PaymentRoutingService, logger *logger.Logger) *PaymentRoutingHandler {
+	return &PaymentRoutingHandler{
+		routingService: routingService,
+		logger:         logger,
+	}
+}
+
+// CreateRoutingRule godoc
+// @Summary Create a payment routing rule (Admin)
+// @Description Create a rule evaluated before a gateway is selected, matching payments by amount range, currencies and payment methods. A route rule sends matching payments to its gateway, falling back to the gateway selector while that gateway is unavailable; a bypass rule keeps matching offline payments (bank transfer, cash) away from every gateway. Rules are evaluated by descending priority, then name, and the first that matches decides.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rule body services.PaymentRoutingRuleRequest true "Routing rule"
+// @Success 201 {object} object{message=string,data=models.PaymentRoutingRule} "Routing rule created"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 409 {object} map[string]interface{} "Routing rule name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules [post]
+func (h *PaymentRoutingHandler) CreateRoutingRule(c *gin.Context) {
+	h.logger.Debug("Creating payment routing rule via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PaymentRoutingRuleRequest)
+
+	// Call service
+	rule, err := h.routingService.CreateRule(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create payment routing rule", "error", err, "name", req.Name)
+		h.writeError(c, err, "Failed to create payment routing rule")
+		return
+	}
+
+	h.logger.Info("Payment routing rule created successfully via admin API", "id", rule.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Payment routing rule created successfully",
+		"data":    rule,
+	})
+}
+
+// ListRoutingRules godoc
+// @Summary List payment routing rules (Admin)
+// @Description Get every payment routing rule, active or not, in evaluation order
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=[]models.PaymentRoutingRule} "Routing rules"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules [get]
+func (h *PaymentRoutingHandler) ListRoutingRules(c *gin.Context) {
+	h.logger.Debug("Listing payment routing rules via admin API")
+
+	// Call service
+	rules, err := h.routingService.ListRules(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list payment routing rules", "error", err)
+		h.writeError(c, err, "Failed to list payment routing rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rules,
+	})
+}
+
+// GetRoutingRule godoc
+// @Summary Get a payment routing rule (Admin)
+// @Description Get a payment routing rule by ID
+// @Tags admin
+// @Produce json
+// @Param id path string true "Routing rule ID"
+// @Success 200 {object} object{data=models.PaymentRoutingRule} "Routing rule"
+// @Failure 404 {object} map[string]interface{} "Routing rule not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules/{id} [get]
+func (h *PaymentRoutingHandler) GetRoutingRule(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	ruleID := c.Param("id")
+	h.logger.Debug("Getting payment routing rule via admin API", "id", ruleID)
+
+	// Call service
+	rule, err := h.routingService.GetRule(c.Request.Context(), ruleID)
+	if err != nil {
+		h.logger.Error("Failed to get payment routing rule", "error", err, "id", ruleID)
+		h.writeError(c, err, "Failed to get payment routing rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": rule,
+	})
+}
+
+// UpdateRoutingRule godoc
+// @Summary Replace a payment routing rule (Admin)
+// @Description Replace a payment routing rule's conditions, action and gateway, or deactivate it. Payments already processed are not affected.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Routing rule ID"
+// @Param rule body services.PaymentRoutingRuleRequest true "Routing rule"
+// @Success 200 {object} object{message=string,data=models.PaymentRoutingRule} "Routing rule updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Routing rule not found"
+// @Failure 409 {object} map[string]interface{} "Routing rule name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules/{id} [put]
+func (h *PaymentRoutingHandler) UpdateRoutingRule(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	ruleID := c.Param("id")
+	h.logger.Debug("Updating payment routing rule via admin API", "id", ruleID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PaymentRoutingRuleRequest)
+
+	// Call service
+	rule, err := h.routingService.UpdateRule(c.Request.Context(), ruleID, req)
+	if err != nil {
+		h.logger.Error("Failed to update payment routing rule", "error", err, "id", ruleID)
+		h.writeError(c, err, "Failed to update payment routing rule")
+		return
+	}
+
+	h.logger.Info("Payment routing rule updated successfully via admin API", "id", ruleID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment routing rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRoutingRule godoc
+// @Summary Delete a payment routing rule (Admin)
+// @Description Delete a payment routing rule; payments it matched are routed by the remaining rules and the gateway selector
+// @Tags admin
+// @Produce json
+// @Param id path string true "Routing rule ID"
+// @Success 200 {object} object{message=string} "Routing rule deleted"
+// @Failure 404 {object} map[string]interface{} "Routing rule not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules/{id} [delete]
+func (h *PaymentRoutingHandler) DeleteRoutingRule(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	ruleID := c.Param("id")
+	h.logger.Debug("Deleting payment routing rule via admin API", "id", ruleID)
+
+	if err := h.routingService.DeleteRule(c.Request.Context(), ruleID); err != nil {
+		h.logger.Error("Failed to delete payment routing rule", "error", err, "id", ruleID)
+		h.writeError(c, err, "Failed to delete payment routing rule")
+		return
+	}
+
+	h.logger.Info("Payment routing rule deleted via admin API", "id", ruleID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Payment routing rule deleted",
+	})
+}
+
+// EvaluateRoutingRules godoc
+// @Summary Dry-run payment routing (Admin)
+// @Description Route a payment by amount, currency and method without processing it, showing how each rule was evaluated, the rule that decided and the gateway the payment would be sent to now. Rules in the request are evaluated in place of the active rules, to try them out before saving them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param payment body services.EvaluatePaymentRoutingRequest true "Payment to route"
+// @Success 200 {object} object{data=services.PaymentRoutingEvaluationResponse} "Routing decision"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/payments/routing-rules/evaluate [post]
+func (h *PaymentRoutingHandler) EvaluateRoutingRules(c *gin.Context) {
+	h.logger.Debug("Evaluating payment routing rules via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.EvaluatePaymentRoutingRequest)
+
+	// Call service
+	evaluation, err := h.routingService.EvaluateRules(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to evaluate payment routing rules", "error", err)
+		h.writeError(c, err, "Failed to evaluate payment routing rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": evaluation,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *PaymentRoutingHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PhoneOrderHandler handles HTTP requests for orders agents place on behalf of customers
+type PhoneOrderHandler struct {
+	phoneOrderService services.PhoneOrderService
+	logger            *logger.Logger
+}
+
+// NewPhoneOrderHandler creates a new phone order handler
+func NewPhoneOrderHandler(phoneOrderService services.PhoneOrderService, logger *logger.Logger) *PhoneOrderHandler {
+	return &PhoneOrderHandler{
+		phoneOrderService: phoneOrderService,
+		logger:            logger,
+	}
+}
+
+// SearchProducts godoc
+// @Summary Search products for a phone order (Admin)
+// @Description Search active products by SKU, name and description at the prices the customer orders at, with the lowest unit price the price override policy allows
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param q query string true "Search query"
+// @Param limit query int false "Maximum number of products (default 20, at most 100)"
+// @Success 200 {object} object{data=services.PhoneOrderProductSearchResponse} "Products"
+// @Failure 400 {object} map[string]interface{} "Invalid query or customer deactivated"
+// @Failure 404 {object} map[string]interface{} "Customer not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/phone-order/products [get]
+func (h *PhoneOrderHandler) SearchProducts(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	customerID := c.Param("id")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.PhoneOrderProductSearchRequest)
+
+	// Call service
+	products, err := h.phoneOrderService.SearchProducts(c.Request.Context(), customerID, req)
+	if err != nil {
+		h.logger.Error("Failed to search products for phone order", "error", err, "customer_id", customerID)
+		h.writeError(c, err, "Failed to search products")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": products,
+	})
+}
+
+// GetCart godoc
+// @Summary Get a customer's cart (Admin)
+// @Description Get the customer's active cart stock holds, which a phone order places when it lists no items
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} object{data=[]services.CartHoldResponse} "Cart holds"
+// @Failure 400 {object} map[string]interface{} "Customer deactivated"
+// @Failure 404 {object} map[string]interface{} "Customer not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/cart/holds [get]
+func (h *PhoneOrderHandler) GetCart(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	customerID := c.Param("id")
+
+	// Call service
+	holds, err := h.phoneOrderService.GetCart(c.Request.Context(), customerID)
+	if err != nil {
+		h.logger.Error("Failed to get customer cart", "error", err, "customer_id", customerID)
+		h.writeError(c, err, "Failed to get cart holds")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": holds,
+	})
+}
+
+// HoldCartItem godoc
+// @Summary Add a product to a customer's cart (Admin)
+// @Description Reserve stock of a product in the customer's cart, as the customer would. Holds expire after the cart hold window.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param hold body services.HoldCartItemRequest true "Product and quantity"
+// @Success 201 {object} object{message=string,data=services.CartHoldResponse} "Item held"
+// @Failure 400 {object} map[string]interface{} "Invalid request, customer deactivated or cart holds disabled"
+// @Failure 404 {object} map[string]interface{} "Customer or product not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/cart/holds [post]
+func (h *PhoneOrderHandler) HoldCartItem(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	customerID := c.Param("id")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.HoldCartItemRequest)
+
+	// Call service
+	hold, err := h.phoneOrderService.HoldCartItem(c.Request.Context(), customerID, req)
+	if err != nil {
+		h.logger.Error("Failed to hold customer cart item", "error", err, "customer_id", customerID, "product_id", req.ProductID)
+		h.writeError(c, err, "Failed to hold cart item")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Item held",
+		"data":    hold,
+	})
+}
+
+// ReleaseCartItem godoc
+// @Summary Remove a product from a customer's cart (Admin)
+// @Description Release the customer's hold on a product, returning the stock to available inventory
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} object{message=string} "Hold released"
+// @Failure 404 {object} map[string]interface{} "Customer or cart hold not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/cart/holds/{product_id} [delete]
+func (h *PhoneOrderHandler) ReleaseCartItem(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	customerID := c.Param("id")
+	productID := c.Param("product_id")
+
+	// Call service
+	if err := h.phoneOrderService.ReleaseCartItem(c.Request.Context(), customerID, productID); err != nil {
+		h.logger.Error("Failed to release customer cart item", "error", err, "customer_id", customerID, "product_id", productID)
+		h.writeError(c, err, "Failed to release cart hold")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hold released",
+	})
+}
+
+// PlaceOrder godoc
+// @Summary Place a phone order for a customer (Admin)
+// @Description Place an order on behalf of the customer, from the given items or else the customer's cart, attributed to the authenticated agent for commission reporting. Items may be overridden below the customer's price within the price override policy. The order is paid with a card on file, charged right away; by invoice, on the customer's organization account; or offline, by bank transfer or cash recorded later through the manual payments API. A card that could not be charged leaves the order pending with the reason in payment_error.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param order body services.PlacePhoneOrderRequest true "Phone order"
+// @Success 201 {object} object{message=string,data=services.PhoneOrderResponse} "Phone order placed"
+// @Failure 400 {object} map[string]interface{} "Invalid request, empty cart, unusable payment method or customer deactivated"
+// @Failure 401 {object} map[string]interface{} "User authentication failed"
+// @Failure 403 {object} map[string]interface{} "Price override exceeds the maximum discount"
+// @Failure 404 {object} map[string]interface{} "Customer, product or payment method not found"
+// @Failure 409 {object} map[string]interface{} "Insufficient stock"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/phone-orders [post]
+func (h *PhoneOrderHandler) PlaceOrder(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	customerID := c.Param("id")
+	h.logger.Debug("Placing phone order via admin API", "customer_id", customerID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.PlacePhoneOrderRequest)
+
+	// Extract the agent's ID from JWT context
+	agentID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	response, err := h.phoneOrderService.PlaceOrder(c.Request.Context(), customerID, agentID, req)
+	if err != nil {
+		h.logger.Error("Failed to place phone order", "error", err, "customer_id", customerID, "agent_id", agentID)
+		h.writeError(c, err, "Failed to place phone order")
+		return
+	}
+
+	h.logger.Info("Phone order placed via admin API", "order_id", response.Order.ID, "customer_id", customerID,
+		"agent_id", agentID, "payment_type", response.PaymentType)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Phone order placed",
+		"data":    response,
+	})
+}
+
+// GenerateAgentSalesReport godoc
+// @Summary Generate agent sales report (Admin)
+// @Description Sum the sales of the phone orders each agent placed, less refunds, for commission, for an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.AgentSalesReportResponse} "Agent sales report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/agent-sales [get]
+func (h *PhoneOrderHandler) GenerateAgentSalesReport(c *gin.Context) {
+	h.logger.Debug("Generating agent sales report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.AgentSalesReportRequest)
+
+	// Call service
+	report, err := h.phoneOrderService.GenerateAgentSalesReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate agent sales report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+		h.writeError(c, err, "Failed to generate agent sales report")
+		return
+	}
+
+	h.logger.Info("Agent sales report generated successfully via admin API", "agents", len(report.Agents), "revenue", report.Totals.Revenue)
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// writeError maps a phone order service error to a response
+func (h *PhoneOrderHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"), strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"), strings.Contains(err.Error(), "INSUFFICIENT_STOCK"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "FORBIDDEN"):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"), strings.Contains(err.Error(), "BUSINESS_ERROR"),
+		strings.Contains(err.Error(), "invalid date"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceListHandler handles B2B price list HTTP requests
+type PriceListHandler struct {
+	priceListService services.PriceListService
+	logger           *logger.Logger
+}
+
+// NewPriceListHandler creates a new price list handler
+func NewPriceListHandler(priceListService services.PriceListService, logger *logger.Logger) *PriceListHandler {
+	return &PriceListHandler{
+		priceListService: priceListService,
+		logger:           logger,
+	}
+}
+
+// CreatePriceList godoc
+// @Summary Create a price list (Admin)
+// @Description Create an empty B2B price list. Import its rows, then assign it to organizations or customers, who order at its prices.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param price_list body services.CreatePriceListRequest true "Price list"
+// @Success 201 {object} object{message=string,data=services.PriceListResponse} "Price list created"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 409 {object} map[string]interface{} "Price list name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/price-lists [post]
+func (h *PriceListHandler) CreatePriceList(c *gin.Context) {
+	h.logger.Debug("Creating price list via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreatePriceListRequest)
+
+	// Call service
+	priceList, err := h.priceListService.CreatePriceList(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create price list", "error", err, "name", req.Name)
+		h.writeError(c, err, "Failed to create price list")
+		return
+	}
+
+	h.logger.Info("Price list created successfully via admin API", "id", priceList.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Price list created successfully",
+		"data":    priceList,
+	})
+}
+
+// ListPriceLists godoc
+// @Summary List price lists (Admin)
+// @Description Get a paginated list of B2B price lists, by name
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Success 200 {object} object{data=services.ListPriceListsResponse} "List of price lists"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/price-lists [get]
+func (h *PriceListHandler) ListPriceLists(c *gin.Context) {
+	h.logger.Debug("Listing price lists via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListPriceListsRequest)
+
+	// Call service
+	priceLists, err := h.priceListService.ListPriceLists(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list price lists", "error", err)
+		h.writeError(c, err, "Failed to list price lists")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": priceLists,
+	})
+}
+
+// GetPriceList godoc
+// @Summary Get a price list (Admin)
+// @Description Get a B2B price list with its rows by product, then effective date
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Price list ID"
+// @Success 200 {object} object{data=services.PriceListResponse} "Price list"
+// @Failure 404 {object} map[string]interface{} "Price list not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/price-lists/{id} [get]
+func (h *PriceListHandler) GetPriceList(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	priceListID := c.Param("id")
+	h.logger.Debug("Getting price list via admin API", "id", priceListID)
+
+	// Call service
+	priceList, err := h.priceListService.GetPriceList(c.Request.Context(), priceListID)
+	if err != nil {
+		h.logger.Error("Failed to get price list", "error", err, "id", priceListID)
+		h.writeError(c, err, "Failed to get price list")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": priceList,
+	})
+}
+
+// UpdatePriceList godoc
+// @Summary Update a price list (Admin)
+// @Description Update a B2B price list's name, description or active flag. Customers of a deactivated list order at list prices, or at their organization's list when their own is deactivated.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Price list ID"
+// @Param price_list body services.UpdatePriceListRequest true "Fields to update"
+// @Success 200 {object} object{message=string,data=services.PriceListResponse} "Price list updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Price list not found"
+// @Failure 409 {object} map[string]interface{} "Price list name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/price-lists/{id} [patch]
+func (h *PriceListHandler) UpdatePriceList(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	priceListID := c.Param("id")
+	h.logger.Debug("Updating price list via admin API", "id", priceListID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdatePriceListRequest)
+
+	// Call service
+	priceList, err := h.priceListService.UpdatePriceList(c.Request.Context(), priceListID, req)
+	if err != nil {
+		h.logger.Error("Failed to update price list", "error", err, "id", priceListID)
+		h.writeError(c, err, "Failed to update price list")
+		return
+	}
+
+	h.logger.Info("Price list updated successfully via admin API", "id", priceListID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price list updated successfully",
+		"data":    priceList,
+	})
+}
+
+// ImportPriceListItems godoc
+// @Summary Import price list rows (Admin)
+// @Description Add rows to a price list from a CSV of sku,price rows with optional effective_from and effective_to columns, as YYYY-MM-DD dates or RFC 3339 times; an empty date is an open end. When ranges of a product overlap, the one that started last applies. With replace=true the list's existing rows are removed first. Nothing is imported unless every row is valid.
+// @Tags admin
+// @Accept mpfd,text/csv
+// @Produce json
+// @Param id path string true "Price list ID"
+// @Param replace query bool false "Replace the list's existing rows" default(false)
+// @Param file formData file false "Price list CSV (sku,price[,effective_from,effective_to])"
+// @Success 200 {object} object{message=string,data=services.PriceListImportResponse} "Import processed"
+// @Failure 400 {object} map[string]interface{} "Invalid CSV file"
+// @Failure 404 {object} map[string]interface{} "Price list not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/price-lists/{id}/import [post]
+func (h *PriceListHandler) ImportPriceListItems(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	priceListID := c.Param("id")
+	replace := c.Query("replace") == "true"
+	h.logger.Debug("Importing price list items via admin API", "id", priceListID, "replace", replace)
+
+	// Accept either a multipart upload or a raw CSV body
+	var source io.Reader = c.Request.Body
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.logger.Error("Failed to open uploaded price list file", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Failed to read uploaded file",
+			})
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	// Call service
+	response, err := h.priceListService.ImportItems(c.Request.Context(), priceListID, source, replace)
+	if err != nil {
+		h.logger.Error("Failed to import price list items", "error", err, "id", priceListID)
+		h.writeError(c, err, "Failed to import price list items")
+		return
+	}
+
+	message := "Price list items imported"
+	if response.Failed > 0 {
+		message = "Price list items not imported, as some rows failed"
+	}
+
+	h.logger.Info("Price list items import processed via admin API",
+		"id", priceListID, "total_rows", response.TotalRows,
+		"imported", response.Imported, "failed", response.Failed)
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    response,
+	})
+}
+
+// AssignOrganizationPriceList godoc
+// @Summary Assign an organization's price list (Admin)
+// @Description Set the price list the members of a B2B customer account order at. Members with a price list of their own order at theirs. An empty price_list_id clears it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param assignment body services.AssignPriceListRequest true "Price list to assign"
+// @Success 200 {object} map[string]interface{} "Price list assigned"
+// @Failure 404 {object} map[string]interface{} "Organization or price list not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/organizations/{id}/price-list [put]
+func (h *PriceListHandler) AssignOrganizationPriceList(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	organizationID := c.Param("id")
+	h.logger.Debug("Assigning organization price list via admin API", "id", organizationID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.AssignPriceListRequest)
+
+	// Call service
+	if err := h.priceListService.AssignToOrganization(c.Request.Context(), organizationID, &req.PriceListID); err != nil {
+		h.logger.Error("Failed to assign organization price list", "error", err, "id", organizationID)
+		h.writeError(c, err, "Failed to assign price list")
+		return
+	}
+
+	h.logger.Info("Organization price list assigned via admin API", "id", organizationID, "price_list_id", req.PriceListID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price list assigned successfully",
+	})
+}
+
+// AssignUserPriceList godoc
+// @Summary Assign a customer's price list (Admin)
+// @Description Set the price list a customer orders at, ahead of their organization's. An empty price_list_id clears it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param assignment body services.AssignPriceListRequest true "Price list to assign"
+// @Success 200 {object} map[string]interface{} "Price list assigned"
+// @Failure 404 {object} map[string]interface{} "User or price list not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/users/{id}/price-list [put]
+func (h *PriceListHandler) AssignUserPriceList(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	userID := c.Param("id")
+	h.logger.Debug("Assigning user price list via admin API", "id", userID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.AssignPriceListRequest)
+
+	// Call service
+	if err := h.priceListService.AssignToUser(c.Request.Context(), userID, &req.PriceListID); err != nil {
+		h.logger.Error("Failed to assign user price list", "error", err, "id", userID)
+		h.writeError(c, err, "Failed to assign price list")
+		return
+	}
+
+	h.logger.Info("User price list assigned via admin API", "id", userID, "price_list_id", req.PriceListID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Price list assigned successfully",
+	})
+}
+
+// writeError maps a price list service error to a response
+func (h *PriceListHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
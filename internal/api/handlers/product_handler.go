@@ -28,7 +28,7 @@ func NewProductHandler(productService services.ProductService, logger *logger.Lo
 
 // CreateProduct godoc
 // @Summary Create a new product (Admin)
-// @Description Create a new product (Admin only)
+// @Description Create a new product (Admin only). The name and description are in the default locale (en); translations give them in other supported locales.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -89,11 +89,12 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 
 // GetProduct godoc
 // @Summary Get product by ID
-// @Description Retrieve product details by product ID
+// @Description Retrieve product details by product ID. The name and description are in the locale negotiated from Accept-Language, falling back to the default locale for text not translated; locale reports the locale used.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param Accept-Language header string false "Preferred locales, e.g. fr-CA,fr;q=0.9"
 // @Success 200 {object} object{data=services.ProductResponse} "Product details"
 // @Failure 400 {object} map[string]interface{} "Invalid product ID"
 // @Failure 404 {object} map[string]interface{} "Product not found"
@@ -131,7 +132,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 // UpdateProduct godoc
 // @Summary Update product (Admin)
-// @Description Update product details (Admin only)
+// @Description Update product details (Admin only). Translations replace the translation of each locale given; an empty translation removes the locale.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -172,6 +173,13 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 			return
 		}
 
+		if strings.Contains(err.Error(), "invalid metadata") || strings.Contains(err.Error(), "invalid translations") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update product",
 		})
@@ -187,7 +195,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 
 // ListProducts godoc
 // @Summary List products
-// @Description Get a paginated list of products with optional filters
+// @Description Get a paginated list of products with optional filters. Names and descriptions are in the locale negotiated from Accept-Language, falling back to the default locale. A search with q matches active products by SKU, or by name or description in the default or the negotiated locale.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -195,6 +203,8 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 // @Param limit query int false "Number of items per page" default(10)
 // @Param category_id query string false "Filter by category ID"
 // @Param active_only query boolean false "Show only active products" default(false)
+// @Param q query string false "Search text"
+// @Param Accept-Language header string false "Preferred locales, e.g. fr-CA,fr;q=0.9"
 // @Success 200 {object} object{data=services.ListProductsResponse} "List of products"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Security BearerAuth
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductSerialHandler handles serial number HTTP requests for serialized products
+type ProductSerialHandler struct {
+	serialService services.ProductSerialService
+	logger        *logger.Logger
+}
+
+// NewProductSerialHandler creates a new product serial handler
+func NewProductSerialHandler(serialService services.ProductSerialService, logger *logger.Logger) *ProductSerialHandler {
+	return &ProductSerialHandler{
+		serialService: serialService,
+		logger:        logger,
+	}
+}
+
+// ReceiveProductSerials godoc
+// @Summary Receive serial numbers of a lot (Admin)
+// @Description Record the serial numbers of a received lot of a serialized product, so they can be captured on the order items they ship on. Serial numbers the product already has are reported as duplicates and left in their lot.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param serials body services.ReceiveProductSerialsRequest true "Lot and serial numbers"
+// @Success 201 {object} object{message=string,data=services.ReceiveProductSerialsResponse} "Serial numbers received"
+// @Failure 400 {object} map[string]interface{} "Invalid serial numbers, or product not serialized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/products/{id}/serials [post]
+func (h *ProductSerialHandler) ReceiveProductSerials(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("id")
+	h.logger.Debug("Receiving product serials via admin API", "product_id", productID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ReceiveProductSerialsRequest)
+
+	// Call service
+	response, err := h.serialService.ReceiveSerials(c.Request.Context(), productID, req)
+	if err != nil {
+		h.logger.Error("Failed to receive product serials", "error", err, "product_id", productID)
+		h.respondWithError(c, err, "Failed to receive product serials")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Serial numbers received",
+		"data":    response,
+	})
+}
+
+// ListProductSerials godoc
+// @Summary List serial numbers of a product (Admin)
+// @Description List the received serial numbers of a serialized product in the order they were received, optionally of one lot, with the order item each is allocated to
+// @Tags admin
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param lot_number query string false "Lot number"
+// @Success 200 {object} object{data=services.ListProductSerialsResponse} "Serial numbers"
+// @Failure 400 {object} map[string]interface{} "Product not serialized"
+// @Failure 404 {object} map[string]interface{} "Product not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/products/{id}/serials [get]
+func (h *ProductSerialHandler) ListProductSerials(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	productID := c.Param("id")
+	h.logger.Debug("Listing product serials via admin API", "product_id", productID)
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListProductSerialsRequest)
+
+	// Call service
+	response, err := h.serialService.ListSerials(c.Request.Context(), productID, req)
+	if err != nil {
+		h.logger.Error("Failed to list product serials", "error", err, "product_id", productID)
+		h.respondWithError(c, err, "Failed to list product serials")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// CaptureOrderItemSerials godoc
+// @Summary Capture serial numbers of an order item (Admin)
+// @Description Capture the serial number of each unit of an order item of a serialized product at fulfillment, replacing those captured before. Each must have been received for the product and not be allocated to another order. Orders with serialized items cannot ship until their serial numbers are captured; customers see them on their order for warranty claims.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param item_id path string true "Order item ID"
+// @Param serials body services.CaptureOrderItemSerialsRequest true "Serial numbers, one per unit"
+// @Success 200 {object} object{message=string,data=services.OrderItemDetail} "Serial numbers captured"
+// @Failure 400 {object} map[string]interface{} "Wrong number of serial numbers, or serial numbers not received or allocated"
+// @Failure 404 {object} map[string]interface{} "Order or order item not found"
+// @Failure 409 {object} map[string]interface{} "Order not being fulfilled"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/orders/{id}/items/{item_id}/serials [put]
+func (h *ProductSerialHandler) CaptureOrderItemSerials(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	itemID := c.Param("item_id")
+	h.logger.Debug("Capturing order item serials via admin API", "order_id", orderID, "item_id", itemID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CaptureOrderItemSerialsRequest)
+
+	// Call service
+	item, err := h.serialService.CaptureSerials(c.Request.Context(), orderID, itemID, req)
+	if err != nil {
+		h.logger.Error("Failed to capture order item serials", "error", err, "order_id", orderID, "item_id", itemID)
+		h.respondWithError(c, err, "Failed to capture order item serials")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Serial numbers captured",
+		"data":    item,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *ProductSerialHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles checkout promotion HTTP requests
+type PromotionHandler struct {
+	promotionService services.PromotionService
+	logger           *logger.Logger
+}
+
+// NewPromotionHandler creates a new promotion handler
+func NewPromotionHandler(promotionService services.PromotionService, logger *logger.Logger) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		logger:           logger,
+	}
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion (Admin)
+// @Description Create a promotion applied automatically at checkout between its start and end: a percentage off the order, a BOGO on a category or free shipping. Orders qualify from min_subtotal, on channel if set, and as a first order with first_order_only. Promotions apply by descending priority; an exclusive promotion applies alone, and only one promotion of each stack_group applies.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param promotion body services.CreatePromotionRequest true "Promotion"
+// @Success 201 {object} object{message=string,data=models.Promotion} "Promotion created"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 409 {object} map[string]interface{} "Promotion name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	h.logger.Debug("Creating promotion via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreatePromotionRequest)
+
+	// Call service
+	promotion, err := h.promotionService.CreatePromotion(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create promotion", "error", err, "name", req.Name)
+		h.writeError(c, err, "Failed to create promotion")
+		return
+	}
+
+	h.logger.Info("Promotion created successfully via admin API", "id", promotion.ID)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Promotion created successfully",
+		"data":    promotion,
+	})
+}
+
+// ListPromotions godoc
+// @Summary List promotions (Admin)
+// @Description Get a paginated list of promotions, latest starting first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Param running query bool false "Only promotions running now" default(false)
+// @Success 200 {object} object{data=services.ListPromotionsResponse} "List of promotions"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/promotions [get]
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	h.logger.Debug("Listing promotions via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListPromotionsRequest)
+
+	// Call service
+	promotions, err := h.promotionService.ListPromotions(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list promotions", "error", err)
+		h.writeError(c, err, "Failed to list promotions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": promotions,
+	})
+}
+
+// GetPromotion godoc
+// @Summary Get a promotion (Admin)
+// @Description Get a promotion by ID
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} object{data=models.Promotion} "Promotion"
+// @Failure 404 {object} map[string]interface{} "Promotion not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/promotions/{id} [get]
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	promotionID := c.Param("id")
+	h.logger.Debug("Getting promotion via admin API", "id", promotionID)
+
+	// Call service
+	promotion, err := h.promotionService.GetPromotion(c.Request.Context(), promotionID)
+	if err != nil {
+		h.logger.Error("Failed to get promotion", "error", err, "id", promotionID)
+		h.writeError(c, err, "Failed to get promotion")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": promotion,
+	})
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion (Admin)
+// @Description Change a promotion's window, eligibility, stacking or discount, or deactivate it. Orders already placed keep the discount they got.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param promotion body services.UpdatePromotionRequest true "Fields to update"
+// @Success 200 {object} object{message=string,data=models.Promotion} "Promotion updated"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Promotion not found"
+// @Failure 409 {object} map[string]interface{} "Promotion name already taken"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/promotions/{id} [patch]
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	promotionID := c.Param("id")
+	h.logger.Debug("Updating promotion via admin API", "id", promotionID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.UpdatePromotionRequest)
+
+	// Call service
+	promotion, err := h.promotionService.UpdatePromotion(c.Request.Context(), promotionID, req)
+	if err != nil {
+		h.logger.Error("Failed to update promotion", "error", err, "id", promotionID)
+		h.writeError(c, err, "Failed to update promotion")
+		return
+	}
+
+	h.logger.Info("Promotion updated successfully via admin API", "id", promotionID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Promotion updated successfully",
+		"data":    promotion,
+	})
+}
+
+// GeneratePromotionReport godoc
+// @Summary Generate promotion performance report (Admin)
+// @Description Get the orders each promotion was applied to, those redeemed (not cancelled or failed), the discount given, their revenue and average order value, for orders placed in an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.PromotionReportResponse} "Promotion report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/promotions [get]
+func (h *PromotionHandler) GeneratePromotionReport(c *gin.Context) {
+	h.logger.Debug("Generating promotion report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.PromotionReportRequest)
+
+	report, err := h.promotionService.GeneratePromotionReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate promotion report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+		h.writeError(c, err, "Failed to generate promotion report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *PromotionHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferralHandler handles referral and store credit HTTP requests
+type ReferralHandler struct {
+	referralService services.ReferralService
+	logger          *logger.Logger
+}
+
+// NewReferralHandler creates a new referral handler
+func NewReferralHandler(referralService services.ReferralService, logger *logger.Logger) *ReferralHandler {
+	return &ReferralHandler{
+		referralService: referralService,
+		logger:          logger,
+	}
+}
+
+// GetReferralCode godoc
+// @Summary Get my referral code
+// @Description Get the current user's referral code, creating it the first time. New users who sign up with it are attributed to the current user, who is credited store credit once their first order is delivered and not returned.
+// @Tags referrals
+// @Produce json
+// @Success 200 {object} object{data=services.ReferralCodeResponse} "Referral code"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /referrals/code [get]
+func (h *ReferralHandler) GetReferralCode(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Getting referral code via API", "user_id", userID)
+
+	code, err := h.referralService.GetReferralCode(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get referral code", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to get referral code")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": code,
+	})
+}
+
+// ListReferrals godoc
+// @Summary List my referrals
+// @Description List the users who signed up with the current user's referral code, newest first, with the status of each referral and the rewards credited for them
+// @Tags referrals
+// @Produce json
+// @Success 200 {object} object{data=services.MyReferralsResponse} "Referrals"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /referrals [get]
+func (h *ReferralHandler) ListReferrals(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Listing referrals via API", "user_id", userID)
+
+	referrals, err := h.referralService.ListReferrals(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list referrals", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to list referrals")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": referrals,
+	})
+}
+
+// GetStoreCredit godoc
+// @Summary Get my store credit
+// @Description Get the current user's store credit balance and latest store credit transactions
+// @Tags referrals
+// @Produce json
+// @Success 200 {object} object{data=services.StoreCreditResponse} "Store credit"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /store-credit [get]
+func (h *ReferralHandler) GetStoreCredit(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Getting store credit via API", "user_id", userID)
+
+	credit, err := h.referralService.GetStoreCredit(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get store credit", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to get store credit")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": credit,
+	})
+}
+
+// ProcessReferralRewards godoc
+// @Summary Process referral rewards (Admin)
+// @Description Credit the referrers whose referred customers' first orders were delivered and kept past the return window, void the referrals whose order was returned, and free those whose order was cancelled for the customer's next order. This also runs periodically in the background.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{message=string,data=services.ReferralRewardRunResponse} "Rewards processed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/referrals/process-rewards [post]
+func (h *ReferralHandler) ProcessReferralRewards(c *gin.Context) {
+	h.logger.Debug("Processing referral rewards via admin API")
+
+	result, err := h.referralService.ProcessRewards(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to process referral rewards", "error", err)
+		h.respondWithError(c, err, "Failed to process referral rewards")
+		return
+	}
+
+	h.logger.Info("Referral rewards processed via admin API", "rewarded", result.Rewarded, "voided", result.Voided, "released", result.Released)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Referral rewards processed",
+		"data":    result,
+	})
+}
+
+// GenerateReferralReport godoc
+// @Summary Generate referral performance report (Admin)
+// @Description Get referral signups, first orders, rewards and voids, the revenue of referred first orders and the store credit rewarded, overall and per referrer, for referrals signed up in an inclusive range of UTC days (default: last 7 days)
+// @Tags admin
+// @Produce json
+// @Param start_date query string false "Start date in YYYY-MM-DD format"
+// @Param end_date query string false "End date in YYYY-MM-DD format"
+// @Success 200 {object} object{data=services.ReferralReportResponse} "Referral report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/referrals [get]
+func (h *ReferralHandler) GenerateReferralReport(c *gin.Context) {
+	h.logger.Debug("Generating referral report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ReferralReportRequest)
+
+	report, err := h.referralService.GenerateReferralReport(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate referral report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+		h.respondWithError(c, err, "Failed to generate referral report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *ReferralHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
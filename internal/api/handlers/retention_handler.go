@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler handles data retention HTTP requests
+type RetentionHandler struct {
+	retentionService services.RetentionService
+	logger           *logger.Logger
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(retentionService services.RetentionService, logger *logger.Logger) *RetentionHandler {
+	return &RetentionHandler{
+		retentionService: retentionService,
+		logger:           logger,
+	}
+}
+
+// GetRetentionMetrics godoc
+// @Summary Get retention metrics (Admin)
+// @Description Get each retention policy's configured retention and its purges since startup: runs, failures, records purged and the outcome of the last run
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.RetentionPolicyMetrics} "Retention metrics"
+// @Security BearerAuth
+// @Router /admin/retention/metrics [get]
+func (h *RetentionHandler) GetRetentionMetrics(c *gin.Context) {
+	h.logger.Debug("Getting retention metrics via API")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.retentionService.GetMetrics(),
+	})
+}
+
+// PurgeExpiredRecords godoc
+// @Summary Purge expired records (Admin)
+// @Description Run every enabled retention policy now. With dry_run, or when dry-run is configured, nothing is removed and each policy reports how many records it would purge.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Only count the records that would be purged"
+// @Success 200 {object} object{data=services.RetentionPurgeResponse} "Purge results"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/retention/purge [post]
+func (h *RetentionHandler) PurgeExpiredRecords(c *gin.Context) {
+	h.logger.Debug("Purging expired records via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.RetentionPurgeRequest)
+
+	// Call service
+	response, err := h.retentionService.Purge(c.Request.Context(), req.DryRun)
+	if err != nil {
+		h.logger.Error("Failed to purge expired records", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to purge expired records",
+			"data":  response,
+		})
+		return
+	}
+
+	h.logger.Info("Expired records purged via API", "dry_run", response.DryRun, "policies", len(response.Results))
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupportSearchHandler handles HTTP requests of customer support lookups
+type SupportSearchHandler struct {
+	searchService services.SupportSearchService
+	logger        *logger.Logger
+}
+
+// NewSupportSearchHandler creates a new support search handler
+func NewSupportSearchHandler(searchService services.SupportSearchService, logger *logger.Logger) *SupportSearchHandler {
+	return &SupportSearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// Search godoc
+// @Summary Support search (Support, Admin)
+// @Description Find users, orders and payments from partial details: part of an email, the last 4 digits of a saved card, part of a tracking number, or an order total, optionally on the day it was placed. Users match the email and card; orders match every detail given.
+// @Tags support
+// @Produce json
+// @Param email query string false "Part of the customer's email"
+// @Param card_last4 query string false "Last 4 digits of a card the customer saved"
+// @Param tracking_number query string false "Part of the order's tracking number"
+// @Param total query number false "Order total"
+// @Param date query string false "Day the order was placed (YYYY-MM-DD, UTC)"
+// @Param limit query int false "Maximum users and orders returned (default 20, max 50)"
+// @Success 200 {object} object{data=services.SupportSearchResponse} "Search results"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 403 {object} map[string]interface{} "Support or admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /support/search [get]
+func (h *SupportSearchHandler) Search(c *gin.Context) {
+	h.logger.Debug("Support search via API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected query type
+	req := *validatedQuery.(*services.SupportSearchRequest)
+
+	// Call service
+	response, err := h.searchService.Search(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to search for support", "error", err)
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
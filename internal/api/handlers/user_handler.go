@@ -28,7 +28,7 @@ func NewUserHandler(userService services.UserService, logger *logger.Logger) *Us
 
 // CreateUser godoc
 // @Summary Create a new user
-// @Description Register a new user account
+// @Description Register a new user account, optionally with the referral code of the user who referred them. An unknown referral code is refused.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -62,6 +62,11 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			return
 		}
 
+		if appErr, ok := err.(*errors.AppError); ok && appErr.Type == errors.ErrorTypeValidation {
+			middleware.AbortWithError(c, appErr)
+			return
+		}
+
 		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid") {
 			appErr := errors.NewValidationError(err.Error())
 			middleware.AbortWithError(c, appErr)
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WarrantyClaimHandler handles warranty and service claim HTTP requests
+type WarrantyClaimHandler struct {
+	claimService services.WarrantyClaimService
+	logger       *logger.Logger
+}
+
+// NewWarrantyClaimHandler creates a new warranty claim handler
+func NewWarrantyClaimHandler(claimService services.WarrantyClaimService, logger *logger.Logger) *WarrantyClaimHandler {
+	return &WarrantyClaimHandler{
+		claimService: claimService,
+		logger:       logger,
+	}
+}
+
+// CreateWarrantyClaim godoc
+// @Summary Claim a unit under warranty
+// @Description Open a warranty or service claim on a unit of a shipped or delivered order, identified by the serial number captured on the order item. A unit has one open claim at a time.
+// @Tags warranty-claims
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param item_id path string true "Order item ID"
+// @Param claim body services.CreateWarrantyClaimRequest true "Serial number and what is wrong with the unit"
+// @Success 201 {object} object{message=string,data=models.WarrantyClaim} "Claim opened"
+// @Failure 400 {object} map[string]interface{} "Serial number did not ship on the order item"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Order or order item not found"
+// @Failure 409 {object} map[string]interface{} "Order not shipped, or unit already has an open claim"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /orders/{id}/items/{item_id}/claims [post]
+func (h *WarrantyClaimHandler) CreateWarrantyClaim(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Path parameter validation is done by middleware
+	orderID := c.Param("id")
+	itemID := c.Param("item_id")
+	h.logger.Debug("Creating warranty claim via API", "user_id", userID, "order_id", orderID, "item_id", itemID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateWarrantyClaimRequest)
+
+	// Call service
+	claim, err := h.claimService.CreateClaim(c.Request.Context(), userID, orderID, itemID, req)
+	if err != nil {
+		h.logger.Error("Failed to create warranty claim", "error", err, "order_id", orderID, "item_id", itemID)
+		h.respondWithError(c, err, "Failed to create warranty claim")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Warranty claim opened",
+		"data":    claim,
+	})
+}
+
+// ListUserWarrantyClaims godoc
+// @Summary List my warranty claims
+// @Description List the authenticated user's warranty claims, newest first, with how each was resolved
+// @Tags warranty-claims
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Claim status" Enums(open, resolved, rejected)
+// @Param product_id query string false "Product ID"
+// @Success 200 {object} object{data=services.ListWarrantyClaimsResponse} "Warranty claims"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /warranty-claims [get]
+func (h *WarrantyClaimHandler) ListUserWarrantyClaims(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+	h.logger.Debug("Listing warranty claims via API", "user_id", userID)
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListWarrantyClaimsRequest)
+
+	// Call service
+	response, err := h.claimService.ListUserClaims(c.Request.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("Failed to list warranty claims", "error", err, "user_id", userID)
+		h.respondWithError(c, err, "Failed to list warranty claims")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ListWarrantyClaims godoc
+// @Summary List warranty claims (Admin)
+// @Description List all warranty claims, newest first, optionally by status or product
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Claim status" Enums(open, resolved, rejected)
+// @Param product_id query string false "Product ID"
+// @Success 200 {object} object{data=services.ListWarrantyClaimsResponse} "Warranty claims"
+// @Failure 400 {object} map[string]interface{} "Invalid status"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/warranty-claims [get]
+func (h *WarrantyClaimHandler) ListWarrantyClaims(c *gin.Context) {
+	h.logger.Debug("Listing warranty claims via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListWarrantyClaimsRequest)
+
+	// Call service
+	response, err := h.claimService.ListClaims(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list warranty claims", "error", err)
+		h.respondWithError(c, err, "Failed to list warranty claims")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ResolveWarrantyClaim godoc
+// @Summary Resolve a warranty claim (Admin)
+// @Description Accept an open warranty claim. A repair only closes the claim; a replacement places a zero-priced order for another unit, shipped to the original address and queued for fulfillment; a refund returns the unit's price to the order's payments. The claim is reopened if its refund fails.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param resolution body services.ResolveWarrantyClaimRequest true "Resolution"
+// @Success 200 {object} object{message=string,data=models.WarrantyClaim} "Claim resolved"
+// @Failure 400 {object} map[string]interface{} "Invalid resolution, or nothing left to refund"
+// @Failure 404 {object} map[string]interface{} "Claim not found"
+// @Failure 409 {object} map[string]interface{} "Claim already closed, or no stock for a replacement"
+// @Failure 502 {object} map[string]interface{} "Gateway refund failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/warranty-claims/{id}/resolve [post]
+func (h *WarrantyClaimHandler) ResolveWarrantyClaim(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Path parameter validation is done by middleware
+	claimID := c.Param("id")
+	h.logger.Debug("Resolving warranty claim via admin API", "claim_id", claimID, "user_id", userID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ResolveWarrantyClaimRequest)
+
+	// Call service
+	claim, err := h.claimService.ResolveClaim(c.Request.Context(), claimID, userID, req)
+	if err != nil {
+		h.logger.Error("Failed to resolve warranty claim", "error", err, "claim_id", claimID)
+		h.respondWithError(c, err, "Failed to resolve warranty claim")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranty claim resolved",
+		"data":    claim,
+	})
+}
+
+// RejectWarrantyClaim godoc
+// @Summary Reject a warranty claim (Admin)
+// @Description Reject an open warranty claim the warranty does not cover, with a note for the customer
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Claim ID"
+// @Param rejection body services.RejectWarrantyClaimRequest true "Why the claim is rejected"
+// @Success 200 {object} object{message=string,data=models.WarrantyClaim} "Claim rejected"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Claim not found"
+// @Failure 409 {object} map[string]interface{} "Claim already closed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/warranty-claims/{id}/reject [post]
+func (h *WarrantyClaimHandler) RejectWarrantyClaim(c *gin.Context) {
+	// Extract user ID from JWT context
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		h.logger.Error("User ID not found in context")
+		appErr := errors.NewUnauthorizedError("User authentication failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Path parameter validation is done by middleware
+	claimID := c.Param("id")
+	h.logger.Debug("Rejecting warranty claim via admin API", "claim_id", claimID, "user_id", userID)
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.RejectWarrantyClaimRequest)
+
+	// Call service
+	claim, err := h.claimService.RejectClaim(c.Request.Context(), claimID, userID, req)
+	if err != nil {
+		h.logger.Error("Failed to reject warranty claim", "error", err, "claim_id", claimID)
+		h.respondWithError(c, err, "Failed to reject warranty claim")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Warranty claim rejected",
+		"data":    claim,
+	})
+}
+
+// GenerateWarrantyClaimRateReport godoc
+// @Summary Warranty claim rate report (Admin)
+// @Description Per serialized product, the units shipped by orders placed in a range of UTC days, the warranty claims made in it, claims per hundred units, and how the claims were resolved. Highest claim rates first. Defaults to the last 7 days.
+// @Tags admin
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} object{data=services.WarrantyClaimRateReportResponse} "Claim rate report"
+// @Failure 400 {object} map[string]interface{} "Invalid date range"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/reports/warranty-claims [get]
+func (h *WarrantyClaimHandler) GenerateWarrantyClaimRateReport(c *gin.Context) {
+	h.logger.Debug("Generating warranty claim rate report via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.WarrantyClaimRateReportRequest)
+
+	report, err := h.claimService.GetClaimRates(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to generate warranty claim rate report", "error", err, "start_date", req.StartDate, "end_date", req.EndDate)
+		h.respondWithError(c, err, "Failed to generate warranty claim rate report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+	})
+}
+
+// respondWithError maps a service error to its HTTP status
+func (h *WarrantyClaimHandler) respondWithError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"), strings.Contains(err.Error(), "BUSINESS_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "CONFLICT"), strings.Contains(err.Error(), "INSUFFICIENT_STOCK"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "EXTERNAL_SERVICE_ERROR"):
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gateway refund failed"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
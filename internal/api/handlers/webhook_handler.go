@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookPayloadSize caps the size of a partner event or gateway payment callback
+const maxWebhookPayloadSize = 1 << 20
+
+// WebhookHandler handles inbound webhook requests and outbound webhook subscriptions
+type WebhookHandler struct {
+	webhookService      services.WebhookService
+	stockWebhookService services.StockWebhookService
+	logger              *logger.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService services.WebhookService, stockWebhookService services.StockWebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService:      webhookService,
+		stockWebhookService: stockWebhookService,
+		logger:              logger,
+	}
+}
+
+// ReceiveWebhook godoc
+// @Summary Receive a webhook event
+// @Description Verify a partner's signed webhook event, then record and process it exactly once. The body is signed with the partner's secret in X-Webhook-Signature as "sha256=" and the hex HMAC-SHA256. Payment events are refused; gateways send them signed to /payments/webhooks/{gateway}.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param provider path string true "Webhook provider"
+// @Param X-Webhook-Signature header string true "sha256= and the hex HMAC-SHA256 of the body"
+// @Param event body services.ReceiveWebhookRequest true "Webhook event"
+// @Success 200 {object} object{data=services.WebhookEventResponse} "Event accepted"
+// @Failure 400 {object} map[string]interface{} "Invalid event"
+// @Failure 401 {object} map[string]interface{} "Invalid signature or payment event"
+// @Failure 404 {object} map[string]interface{} "Provider webhooks not configured"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /webhooks/{provider} [post]
+func (h *WebhookHandler) ReceiveWebhook(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	provider := c.Param("provider")
+	h.logger.Debug("Receiving webhook via API", "provider", provider)
+
+	// The signature covers the raw body, so it is read as is rather than bound
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		h.logger.Error("Failed to read webhook payload", "error", err, "provider", provider)
+		appErr := errors.NewValidationError("Failed to read request body")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	event, err := h.webhookService.ReceiveEvent(c.Request.Context(), provider, c.Request.Header, body)
+	if err != nil {
+		h.logger.Error("Failed to receive webhook", "error", err, "provider", provider)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Webhooks are not configured for this provider",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "UNAUTHORIZED") {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to process webhook",
+		})
+		return
+	}
+
+	h.logger.Info("Webhook received via API", "provider", provider, "event_id", event.EventID, "status", event.Status, "duplicate", event.Duplicate)
+	c.JSON(http.StatusOK, gin.H{
+		"data": event,
+	})
+}
+
+// ReceivePaymentWebhook godoc
+// @Summary Receive a gateway payment webhook
+// @Description Verify a gateway's signed payment callback and settle, fail or refund the payment it confirms; succeeded payments move their order to paid
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param gateway path string true "Payment gateway" example(stripe)
+// @Success 200 {object} object{data=services.WebhookEventResponse} "Event accepted"
+// @Failure 400 {object} map[string]interface{} "Invalid event"
+// @Failure 401 {object} map[string]interface{} "Invalid signature"
+// @Failure 404 {object} map[string]interface{} "Gateway webhooks not configured"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /payments/webhooks/{gateway} [post]
+func (h *WebhookHandler) ReceivePaymentWebhook(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	gateway := c.Param("gateway")
+	h.logger.Debug("Receiving payment webhook via API", "gateway", gateway)
+
+	// The signature covers the raw body, so it is read as is rather than bound
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		h.logger.Error("Failed to read payment webhook payload", "error", err, "gateway", gateway)
+		appErr := errors.NewValidationError("Failed to read request body")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Call service
+	event, err := h.webhookService.ReceivePaymentWebhook(c.Request.Context(), gateway, c.Request.Header, body)
+	if err != nil {
+		h.logger.Error("Failed to receive payment webhook", "error", err, "gateway", gateway)
+
+		if strings.Contains(err.Error(), "NOT_FOUND") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Payment webhooks are not configured for this gateway",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "UNAUTHORIZED") {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid webhook signature",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to process payment webhook",
+		})
+		return
+	}
+
+	h.logger.Info("Payment webhook received via API", "gateway", gateway, "event_id", event.EventID, "status", event.Status, "duplicate", event.Duplicate)
+	c.JSON(http.StatusOK, gin.H{
+		"data": event,
+	})
+}
+
+// ListWebhookEvents godoc
+// @Summary List webhook events (Admin)
+// @Description Get a paginated list of recorded webhook events (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Param status query string false "Filter by processing status"
+// @Success 200 {object} object{data=services.ListWebhookEventsResponse} "List of webhook events"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/events [get]
+func (h *WebhookHandler) ListWebhookEvents(c *gin.Context) {
+	h.logger.Debug("Listing webhook events via admin API")
+
+	// Get validated query from context
+	validatedQuery, exists := middleware.GetValidatedQuery(c)
+	if !exists {
+		h.logger.Error("Validated query not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedQuery.(*services.ListWebhookEventsRequest)
+
+	// Call service
+	response, err := h.webhookService.ListEvents(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to list webhook events", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list webhook events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// ReplayWebhookEvent godoc
+// @Summary Replay a failed webhook event (Admin)
+// @Description Process a failed webhook event again (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook event ID"
+// @Success 200 {object} object{message=string,data=services.WebhookEventResponse} "Event replayed"
+// @Failure 404 {object} map[string]interface{} "Webhook event not found"
+// @Failure 409 {object} map[string]interface{} "Webhook event cannot be replayed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/events/{id}/replay [post]
+func (h *WebhookHandler) ReplayWebhookEvent(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	eventID := c.Param("id")
+	h.logger.Debug("Replaying webhook event via admin API", "id", eventID)
+
+	// Call service
+	event, err := h.webhookService.ReplayEvent(c.Request.Context(), eventID)
+	if err != nil {
+		h.logger.Error("Failed to replay webhook event", "error", err, "id", eventID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Webhook event not found",
+			})
+			return
+		}
+
+		if strings.Contains(err.Error(), "cannot be replayed") {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to replay webhook event",
+		})
+		return
+	}
+
+	h.logger.Info("Webhook event replayed via admin API", "id", eventID, "status", event.Status)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook event replayed",
+		"data":    event,
+	})
+}
+
+// CreateStockWebhookSubscription godoc
+// @Summary Subscribe to stock-change webhooks (Admin)
+// @Description Register an endpoint that receives stock changes, optionally limited to a set of products (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param subscription body services.CreateStockWebhookSubscriptionRequest true "Subscription details"
+// @Success 201 {object} object{message=string,data=services.StockWebhookSubscriptionResponse} "Subscription created"
+// @Failure 400 {object} map[string]interface{} "Invalid subscription"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/subscriptions [post]
+func (h *WebhookHandler) CreateStockWebhookSubscription(c *gin.Context) {
+	h.logger.Debug("Creating stock webhook subscription via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		appErr := errors.NewValidationError("Request validation failed")
+		middleware.AbortWithError(c, appErr)
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.CreateStockWebhookSubscriptionRequest)
+
+	// Call service
+	subscription, err := h.stockWebhookService.CreateSubscription(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create stock webhook subscription", "error", err, "name", req.Name)
+
+		if strings.Contains(err.Error(), "VALIDATION_ERROR") {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create stock webhook subscription",
+		})
+		return
+	}
+
+	h.logger.Info("Stock webhook subscription created via admin API", "id", subscription.ID, "name", subscription.Name)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stock webhook subscription created",
+		"data":    subscription,
+	})
+}
+
+// ListStockWebhookSubscriptions godoc
+// @Summary List stock-change webhook subscriptions (Admin)
+// @Description Get all stock-change webhook subscriptions with their last delivery status (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} object{data=[]services.StockWebhookSubscriptionResponse} "List of subscriptions"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/subscriptions [get]
+func (h *WebhookHandler) ListStockWebhookSubscriptions(c *gin.Context) {
+	h.logger.Debug("Listing stock webhook subscriptions via admin API")
+
+	subscriptions, err := h.stockWebhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list stock webhook subscriptions", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list stock webhook subscriptions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": subscriptions,
+	})
+}
+
+// DeleteStockWebhookSubscription godoc
+// @Summary Delete a stock-change webhook subscription (Admin)
+// @Description Stop delivering stock changes to a subscriber (Admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} object{message=string} "Subscription deleted"
+// @Failure 404 {object} map[string]interface{} "Subscription not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/webhooks/subscriptions/{id} [delete]
+func (h *WebhookHandler) DeleteStockWebhookSubscription(c *gin.Context) {
+	// Path parameter validation is done by middleware
+	subscriptionID := c.Param("id")
+	h.logger.Debug("Deleting stock webhook subscription via admin API", "id", subscriptionID)
+
+	if err := h.stockWebhookService.DeleteSubscription(c.Request.Context(), subscriptionID); err != nil {
+		h.logger.Error("Failed to delete stock webhook subscription", "error", err, "id", subscriptionID)
+
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Stock webhook subscription not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete stock webhook subscription",
+		})
+		return
+	}
+
+	h.logger.Info("Stock webhook subscription deleted via admin API", "id", subscriptionID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stock webhook subscription deleted",
+	})
+}
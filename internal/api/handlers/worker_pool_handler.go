@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkerPoolHandler handles HTTP requests on the background worker pools
+type WorkerPoolHandler struct {
+	workerPoolService services.WorkerPoolService
+	logger            *logger.Logger
+}
+
+// NewWorkerPoolHandler creates a new worker pool handler
+func NewWorkerPoolHandler(workerPoolService services.WorkerPoolService, logger *logger.Logger) *WorkerPoolHandler {
+	return &WorkerPoolHandler{
+		workerPoolService: workerPoolService,
+		logger:            logger,
+	}
+}
+
+// GetStats godoc
+// @Summary Worker pool stats (Admin)
+// @Description Report each worker pool's size, queue depth, in-flight jobs and stored backlog, with throughput and failure rates per job type since the pool started
+// @Tags admin
+// @Produce json
+// @Success 200 {object} object{data=services.WorkerStatsResponse} "Worker pool stats"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/workers/stats [get]
+func (h *WorkerPoolHandler) GetStats(c *gin.Context) {
+	h.logger.Debug("Getting worker pool stats via admin API")
+
+	// Call service
+	response, err := h.workerPoolService.GetStats(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get worker pool stats", "error", err)
+		h.writeError(c, err, "Failed to get worker pool stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// Resize godoc
+// @Summary Resize a worker pool (Admin)
+// @Description Change the number of workers of a pool without a restart; removed workers finish the job they are running first
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body services.ResizeWorkerPoolRequest true "Pool and worker count"
+// @Success 200 {object} object{message=string,data=services.WorkerPoolStats} "Worker pool resized"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 404 {object} map[string]interface{} "Worker pool not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Security BearerAuth
+// @Router /admin/workers/resize [post]
+func (h *WorkerPoolHandler) Resize(c *gin.Context) {
+	h.logger.Debug("Resizing worker pool via admin API")
+
+	// Get validated request from context
+	validatedReq, exists := middleware.GetValidatedRequest(c)
+	if !exists {
+		h.logger.Error("Validated request not found in context")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request validation failed"})
+		return
+	}
+
+	// Type asserts to the expected request type
+	req := *validatedReq.(*services.ResizeWorkerPoolRequest)
+
+	// Call service
+	response, err := h.workerPoolService.Resize(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to resize worker pool", "error", err, "pool", req.Pool)
+		h.writeError(c, err, "Failed to resize worker pool")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Worker pool resized",
+		"data":    response,
+	})
+}
+
+// writeError maps a service error to its HTTP status
+func (h *WorkerPoolHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case strings.Contains(err.Error(), "NOT_FOUND"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.Contains(err.Error(), "VALIDATION_ERROR"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
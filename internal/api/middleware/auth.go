@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/pkg/jwt"
@@ -11,23 +13,45 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware provides JWT authentication middleware
+// APIKeyHeader is the header requests authenticated with an API key carry it in
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator authenticates requests made with an API key instead of a token
+// and enforces the keys' monthly quotas
+type APIKeyAuthenticator interface {
+	// AuthenticateAPIKey returns the active key matching the raw key, with its user, or
+	// nil when there is none
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error)
+	// ReserveQuota counts a request at the time against the key's monthly quota. It
+	// reports false, without counting it, once the quota is exhausted.
+	ReserveQuota(ctx context.Context, key *models.APIKey, at time.Time) (bool, error)
+}
+
+// AuthMiddleware provides JWT and API key authentication middleware
 type AuthMiddleware struct {
 	tokenManager *jwt.TokenManager
+	apiKeys      APIKeyAuthenticator
 	logger       *logger.Logger
 }
 
 // NewAuthMiddleware creates new auth middleware
-func NewAuthMiddleware(tokenManager *jwt.TokenManager, logger *logger.Logger) *AuthMiddleware {
+func NewAuthMiddleware(tokenManager *jwt.TokenManager, apiKeys APIKeyAuthenticator, logger *logger.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenManager: tokenManager,
+		apiKeys:      apiKeys,
 		logger:       logger,
 	}
 }
 
-// RequireAuth is a middleware that requires valid JWT authentication
+// RequireAuth is a middleware that requires valid JWT authentication, or an API key in
+// the X-API-Key header
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" && m.apiKeys != nil {
+			m.authenticateAPIKey(c, rawKey)
+			return
+		}
+
 		// Extract token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -84,6 +108,53 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	}
 }
 
+// authenticateAPIKey authenticates the request as the user owning the API key, refusing
+// it with 429 Too Many Requests once the key's monthly quota is exhausted
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, rawKey string) {
+	key, err := m.apiKeys.AuthenticateAPIKey(c.Request.Context(), rawKey)
+	if err != nil {
+		m.logger.Error("Failed to authenticate API key", "error", err, "path", c.Request.URL.Path)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to authenticate API key",
+		})
+		c.Abort()
+		return
+	}
+	if key == nil {
+		m.logger.Warn("Invalid API key", "path", c.Request.URL.Path)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or revoked API key",
+		})
+		c.Abort()
+		return
+	}
+
+	// Store user information in context
+	c.Set("user_id", key.UserID)
+	c.Set("user_email", key.User.Email)
+	c.Set("user_role", key.User.Role)
+	c.Set("api_key_id", key.ID)
+
+	allowed, err := m.apiKeys.ReserveQuota(c.Request.Context(), key, time.Now())
+	if err != nil {
+		// An unknown quota usage lets the request through rather than failing the API
+		m.logger.Warn("Failed to check API key quota", "error", err, "api_key_id", key.ID)
+	} else if !allowed {
+		m.logger.Warn("API key quota exhausted", "api_key_id", key.ID, "user_id", key.UserID, "path", c.Request.URL.Path)
+		c.Set(quotaExceededKey, true)
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":         "Monthly API key quota exhausted",
+			"monthly_quota": key.MonthlyQuota,
+		})
+		c.Abort()
+		return
+	}
+
+	m.logger.Debug("API key authenticated", "api_key_id", key.ID, "user_id", key.UserID, "path", c.Request.URL.Path)
+
+	c.Next()
+}
+
 // RequireRole is a middleware that requires specific user roles
 func (m *AuthMiddleware) RequireRole(allowedRoles ...models.UserRole) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -132,6 +203,11 @@ func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
 	return m.RequireRole(models.UserRoleAdmin)
 }
 
+// RequireSupportOrAdmin allows customer support agents and admins
+func (m *AuthMiddleware) RequireSupportOrAdmin() gin.HandlerFunc {
+	return m.RequireRole(models.UserRoleSupport, models.UserRoleAdmin)
+}
+
 // RequireCustomerOrAdmin allows both customer and admin roles
 func (m *AuthMiddleware) RequireCustomerOrAdmin() gin.HandlerFunc {
 	return m.RequireRole(models.UserRoleCustomer, models.UserRoleAdmin)
@@ -215,6 +291,18 @@ func GetCurrentUserID(c *gin.Context) (string, bool) {
 	return userIDStr, ok
 }
 
+// GetCurrentAPIKeyID is a helper function to get the API key the request was
+// authenticated with, if any
+func GetCurrentAPIKeyID(c *gin.Context) (string, bool) {
+	apiKeyID, exists := c.Get("api_key_id")
+	if !exists {
+		return "", false
+	}
+
+	apiKeyIDStr, ok := apiKeyID.(string)
+	return apiKeyIDStr, ok
+}
+
 // IsCurrentUserAdmin checks if current user is admin
 func IsCurrentUserAdmin(c *gin.Context) bool {
 	_, role, exists := GetCurrentUser(c)
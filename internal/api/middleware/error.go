@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +12,15 @@ import (
 
 // ErrorMiddleware provides centralized error handling
 type ErrorMiddleware struct {
-	logger *logger.Logger
+	translator *i18n.Translator
+	logger     *logger.Logger
 }
 
 // NewErrorMiddleware creates a new error handling middleware
-func NewErrorMiddleware(logger *logger.Logger) *ErrorMiddleware {
+func NewErrorMiddleware(translator *i18n.Translator, logger *logger.Logger) *ErrorMiddleware {
 	return &ErrorMiddleware{
-		logger: logger,
+		translator: translator,
+		logger:     logger,
 	}
 }
 
@@ -74,10 +77,24 @@ func (em *ErrorMiddleware) handleAppError(c *gin.Context, appErr *errors.AppErro
 	}
 
 	// Return error response
-	response := errors.GetErrorResponse(appErr)
+	response := errors.GetErrorResponse(em.localize(c, appErr))
 	c.JSON(appErr.StatusCode, response)
 }
 
+// localize replaces the message with the catalog message for the request locale.
+// English responses keep the original, more specific message.
+func (em *ErrorMiddleware) localize(c *gin.Context, appErr *errors.AppError) *errors.AppError {
+	locale := GetLocale(c)
+	key := i18n.ErrorKey(string(appErr.Type))
+	if locale == i18n.DefaultLocale || !em.translator.HasMessage(locale, key) {
+		return appErr
+	}
+
+	localized := *appErr
+	localized.Message = em.translator.Translate(locale, key, nil)
+	return &localized
+}
+
 // handleGenericError processes non-structured errors
 func (em *ErrorMiddleware) handleGenericError(c *gin.Context, err error) {
 	em.logger.Error("Unhandled error",
@@ -89,7 +106,7 @@ func (em *ErrorMiddleware) handleGenericError(c *gin.Context, err error) {
 
 	// Create a generic internal error response
 	internalErr := errors.NewInternalError("An unexpected error occurred", err)
-	response := errors.GetErrorResponse(internalErr)
+	response := errors.GetErrorResponse(em.localize(c, internalErr))
 	c.JSON(http.StatusInternalServerError, response)
 }
 
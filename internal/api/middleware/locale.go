@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"easy-orders-backend/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware resolves the response locale from the Accept-Language header
+type LocaleMiddleware struct {
+	translator *i18n.Translator
+}
+
+// NewLocaleMiddleware creates new locale middleware
+func NewLocaleMiddleware(translator *i18n.Translator) *LocaleMiddleware {
+	return &LocaleMiddleware{
+		translator: translator,
+	}
+}
+
+// Handler stores the negotiated locale in the gin and request contexts so
+// services see it too, and reports it in the Content-Language header
+func (m *LocaleMiddleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := m.translator.Match(c.GetHeader("Accept-Language"))
+
+		c.Set("locale", locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+
+		c.Next()
+	}
+}
+
+// GetLocale returns the request locale, or the default locale when none was negotiated
+func GetLocale(c *gin.Context) string {
+	if locale, exists := c.Get("locale"); exists {
+		if localeStr, ok := locale.(string); ok {
+			return localeStr
+		}
+	}
+	return i18n.DefaultLocale
+}
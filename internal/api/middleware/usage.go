@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"easy-orders-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quotaExceededKey marks a request refused for an exhausted API key quota
+const quotaExceededKey = "api_quota_exceeded"
+
+// UsageRecorder aggregates the requests served for API usage analytics
+type UsageRecorder interface {
+	// RecordRequest adds the counts of one request to its day, caller and endpoint
+	RecordRequest(usage models.APIUsage)
+}
+
+// UsageMiddleware records the usage of every request that matched a route
+type UsageMiddleware struct {
+	recorder UsageRecorder
+}
+
+// NewUsageMiddleware creates new usage middleware
+func NewUsageMiddleware(recorder UsageRecorder) *UsageMiddleware {
+	return &UsageMiddleware{
+		recorder: recorder,
+	}
+}
+
+// Track records the request's caller, endpoint, latency and outcome once it was served.
+// Requests to unknown routes are not recorded, so probes can't flood the usage table.
+func (m *UsageMiddleware) Track() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+
+		usage := models.APIUsage{
+			Day:    start.UTC().Truncate(24 * time.Hour),
+			Method: c.Request.Method,
+			Route:  route,
+		}
+		usage.UserID, _ = GetCurrentUserID(c)
+		usage.APIKeyID, _ = GetCurrentAPIKeyID(c)
+
+		if c.GetBool(quotaExceededKey) {
+			usage.Rejected = 1
+		} else {
+			latency := time.Since(start).Milliseconds()
+			usage.Requests = 1
+			usage.TotalLatencyMs = latency
+			usage.MaxLatencyMs = latency
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				usage.Errors = 1
+			}
+		}
+
+		m.recorder.RecordRequest(usage)
+	}
+}
@@ -20,11 +20,55 @@ func RegisterAdminRoutes(router *gin.RouterGroup, adminHandler *handlers.AdminHa
 				adminHandler.GetAllOrders,
 			)
 
+			orders.POST("/import", adminHandler.ImportOrders)
+
+			orders.GET("/duplicates/metrics", adminHandler.GetDuplicateOrderMetrics)
+
+			orders.GET("/limits", adminHandler.GetOrderLimits)
+			orders.PUT("/limits",
+				validationMw.ValidateJSON(services.UpdateOrderLimitsRequest{}),
+				adminHandler.UpdateOrderLimits,
+			)
+
+			orders.GET("/:id/full",
+				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+				adminHandler.GetOrderFullView,
+			)
+
+			orders.POST("/:id/risk",
+				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+				adminHandler.RescoreOrderRisk,
+			)
+
+			orders.GET("/:id/packing-slip",
+				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+				validationMw.ValidateQuery(services.DocumentQuery{}),
+				adminHandler.GetPackingSlip,
+			)
+
+			orders.POST("/pick-list",
+				validationMw.ValidateQuery(services.DocumentQuery{}),
+				validationMw.ValidateJSON(services.PickListRequest{}),
+				adminHandler.GetPickList,
+			)
+
 			orders.PATCH("/:id/status",
 				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
 				validationMw.ValidateJSON(services.UpdateStatusRequest{}),
 				adminHandler.UpdateOrderStatus,
 			)
+
+			orders.PATCH("/:id/metadata",
+				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+				validationMw.ValidateJSON(services.UpdateMetadataRequest{}),
+				adminHandler.UpdateOrderMetadata,
+			)
+
+			orders.PATCH("/:id/items/:item_id/price",
+				validationMw.ValidatePathParams(map[string]string{"id": "required", "item_id": "required"}),
+				validationMw.ValidateJSON(services.OverrideItemPriceRequest{}),
+				adminHandler.OverrideOrderItemPrice,
+			)
 		}
 
 		// Reports - Only daily sales report as per README requirement
@@ -34,6 +78,40 @@ func RegisterAdminRoutes(router *gin.RouterGroup, adminHandler *handlers.AdminHa
 				validationMw.ValidateQuery(services.DailySalesReportQuery{}),
 				adminHandler.GenerateDailySalesReport,
 			)
+
+			reports.GET("/user-activity",
+				validationMw.ValidateQuery(services.UserActivityReportRequest{}),
+				adminHandler.GenerateUserActivityReport,
+			)
+
+			reports.GET("/settlement",
+				validationMw.ValidateQuery(services.SettlementReportRequest{}),
+				adminHandler.GenerateSettlementReport,
+			)
+
+			reports.GET("/payment-failures",
+				validationMw.ValidateQuery(services.PaymentFailureReportRequest{}),
+				adminHandler.GeneratePaymentFailureReport,
+			)
+
+			reports.GET("/notification-delivery",
+				validationMw.ValidateQuery(services.NotificationDeliveryReportRequest{}),
+				adminHandler.GenerateNotificationDeliveryReport,
+			)
+
+			reports.GET("/ar-aging", adminHandler.GenerateARAgingReport)
+			reports.GET("/credit-exposure", adminHandler.GenerateCreditExposureReport)
+			reports.GET("/shipping-sla", adminHandler.GenerateShippingSLAReport)
+
+			// Reports generated in the background, polled until completed
+			reports.POST("/results",
+				validationMw.ValidateJSON(services.QueueReportRequest{}),
+				adminHandler.QueueReport,
+			)
+			reports.GET("/results/:id",
+				validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+				adminHandler.GetReportResult,
+			)
 		}
 
 		// Inventory - Low stock alerts as per README requirement
@@ -43,6 +121,50 @@ func RegisterAdminRoutes(router *gin.RouterGroup, adminHandler *handlers.AdminHa
 				validationMw.ValidateQuery(services.LowStockQuery{}),
 				inventoryHandler.GetLowStockAlert,
 			)
+
+			inventory.PUT("/:product_id/location",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				validationMw.ValidateJSON(services.RelocateInventoryRequest{}),
+				inventoryHandler.RelocateInventory,
+			)
+
+			inventory.GET("/:product_id/relocations",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				inventoryHandler.GetInventoryRelocations,
+			)
+
+			inventory.GET("/:product_id/stock",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				inventoryHandler.GetStockLevels,
+			)
+
+			inventory.PUT("/:product_id/safety-stock",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				validationMw.ValidateJSON(services.SetSafetyStockRequest{}),
+				inventoryHandler.SetSafetyStock,
+			)
+
+			inventory.GET("/:product_id/shards",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				inventoryHandler.GetInventoryShards,
+			)
+			inventory.PUT("/:product_id/shards",
+				validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+				validationMw.ValidateJSON(services.SetInventoryShardsRequest{}),
+				inventoryHandler.SetInventoryShards,
+			)
 		}
+
+		// Anonymized data for staging and load-test environments
+		admin.GET("/sandbox-snapshot",
+			validationMw.ValidateQuery(services.SandboxSnapshotRequest{}),
+			adminHandler.ExportSandboxSnapshot,
+		)
+
+		// Repository query cache metrics
+		admin.GET("/cache/stats", adminHandler.GetCacheStats)
+
+		// Order placement and payment stage latencies
+		admin.GET("/pipeline/metrics", adminHandler.GetPipelineMetrics)
 	}
 }
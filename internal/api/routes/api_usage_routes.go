@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAPIKeyRoutes registers the routes users manage their API keys and see their
+// API usage with
+func RegisterAPIKeyRoutes(router *gin.RouterGroup, apiUsageHandler *handlers.APIUsageHandler, validationMw *middleware.ValidationMiddleware) {
+	apiKeys := router.Group("/api-keys")
+	{
+		apiKeys.POST("",
+			validationMw.ValidateJSON(services.CreateAPIKeyRequest{}),
+			apiUsageHandler.CreateAPIKey,
+		)
+		apiKeys.GET("", apiUsageHandler.ListAPIKeys)
+		apiKeys.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			apiUsageHandler.RevokeAPIKey,
+		)
+	}
+
+	router.GET("/usage",
+		validationMw.ValidateQuery(services.APIUsageRequest{}),
+		apiUsageHandler.GetMyAPIUsage,
+	)
+}
+
+// RegisterAdminAPIUsageRoutes registers the admin routes for API usage and API key quotas
+func RegisterAdminAPIUsageRoutes(router *gin.RouterGroup, apiUsageHandler *handlers.APIUsageHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.GET("/usage",
+			validationMw.ValidateQuery(services.APIUsageRequest{}),
+			apiUsageHandler.GetAPIUsage,
+		)
+		admin.PUT("/api-keys/:id/quota",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.SetAPIKeyQuotaRequest{}),
+			apiUsageHandler.SetAPIKeyQuota,
+		)
+	}
+}
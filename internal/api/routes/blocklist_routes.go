@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterBlocklistRoutes registers the admin routes for the fraud blocklist
+func RegisterBlocklistRoutes(router *gin.RouterGroup, blocklistHandler *handlers.BlocklistHandler, validationMw *middleware.ValidationMiddleware) {
+	blocklist := router.Group("/admin/blocklist")
+	{
+		blocklist.POST("",
+			validationMw.ValidateJSON(services.CreateBlocklistEntryRequest{}),
+			blocklistHandler.AddBlocklistEntry,
+		)
+		blocklist.GET("",
+			validationMw.ValidateQuery(services.ListBlocklistEntriesRequest{}),
+			blocklistHandler.ListBlocklistEntries,
+		)
+		blocklist.GET("/metrics", blocklistHandler.GetBlocklistMetrics)
+		blocklist.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			blocklistHandler.RemoveBlocklistEntry,
+		)
+	}
+}
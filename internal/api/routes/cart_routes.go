@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCartRoutes registers the authenticated user's cart stock hold routes
+func RegisterCartRoutes(router *gin.RouterGroup, cartHandler *handlers.CartHandler, validationMw *middleware.ValidationMiddleware) {
+	holds := router.Group("/cart/holds")
+	{
+		holds.GET("", cartHandler.GetCartHolds)
+		holds.PUT("",
+			validationMw.ValidateJSON(services.HoldCartItemRequest{}),
+			cartHandler.HoldItem,
+		)
+		holds.DELETE("/:product_id",
+			validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+			cartHandler.ReleaseItem,
+		)
+	}
+}
+
+// RegisterAdminCartRoutes registers cart hold admin routes
+func RegisterAdminCartRoutes(router *gin.RouterGroup, cartHandler *handlers.CartHandler, validationMw *middleware.ValidationMiddleware) {
+	router.GET("/admin/cart-holds/stats",
+		validationMw.ValidateQuery(services.CartHoldStatsRequest{}),
+		cartHandler.GetHoldStats,
+	)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterChangeFeedRoutes registers the change feed routes integrators sync from
+func RegisterChangeFeedRoutes(router *gin.RouterGroup, changeFeedHandler *handlers.ChangeFeedHandler, validationMw *middleware.ValidationMiddleware) {
+	router.GET("/inventory/changes",
+		validationMw.ValidateQuery(services.ChangeFeedRequest{}),
+		changeFeedHandler.ListInventoryChanges,
+	)
+	router.GET("/orders/changes",
+		validationMw.ValidateQuery(services.ChangeFeedRequest{}),
+		changeFeedHandler.ListOrderChanges,
+	)
+}
+
+// RegisterCatalogChangeFeedRoutes registers the catalog change feed, which only names
+// products anyone signed in can read, for frontends and CDNs to invalidate caches from
+func RegisterCatalogChangeFeedRoutes(router *gin.RouterGroup, changeFeedHandler *handlers.ChangeFeedHandler, validationMw *middleware.ValidationMiddleware) {
+	router.GET("/catalog/changes",
+		validationMw.ValidateQuery(services.ChangeFeedRequest{}),
+		changeFeedHandler.ListCatalogChanges,
+	)
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterChannelRoutes registers the marketplace channel admin routes
+func RegisterChannelRoutes(router *gin.RouterGroup, channelHandler *handlers.ChannelHandler, validationMw *middleware.ValidationMiddleware) {
+	channels := router.Group("/admin/channels")
+	{
+		channels.GET("", channelHandler.ListChannels)
+
+		channels.POST("/:channel/orders",
+			validationMw.ValidatePathParams(map[string]string{"channel": "required"}),
+			channelHandler.ImportChannelOrder,
+		)
+
+		channels.POST("/:channel/listings",
+			validationMw.ValidatePathParams(map[string]string{"channel": "required"}),
+			validationMw.ValidateJSON(services.CreateChannelListingRequest{}),
+			channelHandler.CreateChannelListing,
+		)
+
+		channels.GET("/:channel/listings",
+			validationMw.ValidatePathParams(map[string]string{"channel": "required"}),
+			channelHandler.ListChannelListings,
+		)
+
+		channels.POST("/:channel/sync",
+			validationMw.ValidatePathParams(map[string]string{"channel": "required"}),
+			channelHandler.SyncChannel,
+		)
+	}
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCheckoutRoutes registers the authenticated user's saved address, saved
+// payment method and express checkout routes
+func RegisterCheckoutRoutes(router *gin.RouterGroup, checkoutHandler *handlers.CheckoutHandler, validationMw *middleware.ValidationMiddleware) {
+	addresses := router.Group("/addresses")
+	{
+		addresses.GET("", checkoutHandler.ListAddresses)
+		addresses.POST("",
+			validationMw.ValidateJSON(services.CreateAddressRequest{}),
+			checkoutHandler.AddAddress,
+		)
+		addresses.PUT("/:id/default",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			checkoutHandler.SetDefaultAddress,
+		)
+		addresses.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			checkoutHandler.DeleteAddress,
+		)
+	}
+
+	paymentMethods := router.Group("/payment-methods")
+	{
+		paymentMethods.GET("", checkoutHandler.ListPaymentMethods)
+		paymentMethods.POST("",
+			validationMw.ValidateJSON(services.CreateSavedPaymentMethodRequest{}),
+			checkoutHandler.AddPaymentMethod,
+		)
+		paymentMethods.PUT("/:id/default",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			checkoutHandler.SetDefaultPaymentMethod,
+		)
+		paymentMethods.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			checkoutHandler.DeletePaymentMethod,
+		)
+	}
+
+	router.POST("/checkout/express",
+		validationMw.ValidateJSON(services.ExpressCheckoutRequest{}),
+		checkoutHandler.ExpressCheckout,
+	)
+}
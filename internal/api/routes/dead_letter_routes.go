@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDeadLetterRoutes registers the admin routes for the dead-letter queue of
+// background jobs
+func RegisterDeadLetterRoutes(router *gin.RouterGroup, deadLetterHandler *handlers.DeadLetterHandler, validationMw *middleware.ValidationMiddleware) {
+	deadJobs := router.Group("/admin/jobs/dead")
+	{
+		deadJobs.GET("",
+			validationMw.ValidateQuery(services.ListDeadJobsRequest{}),
+			deadLetterHandler.ListDeadJobs,
+		)
+		deadJobs.DELETE("",
+			validationMw.ValidateQuery(services.PurgeDeadJobsRequest{}),
+			deadLetterHandler.PurgeDeadJobs,
+		)
+		deadJobs.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			deadLetterHandler.GetDeadJob,
+		)
+		deadJobs.POST("/:id/requeue",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			deadLetterHandler.RequeueDeadJob,
+		)
+		deadJobs.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			deadLetterHandler.DeleteDeadJob,
+		)
+	}
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDeliverySlotRoutes registers the route customers list the delivery slots
+// they can book at checkout from
+func RegisterDeliverySlotRoutes(router *gin.RouterGroup, slotHandler *handlers.DeliverySlotHandler, validationMw *middleware.ValidationMiddleware) {
+	router.GET("/delivery-slots",
+		validationMw.ValidateQuery(services.ListDeliverySlotsRequest{}),
+		slotHandler.ListAvailableSlots,
+	)
+}
+
+// RegisterDeliverySlotAdminRoutes registers the admin routes for planning delivery
+// capacity
+func RegisterDeliverySlotAdminRoutes(router *gin.RouterGroup, slotHandler *handlers.DeliverySlotHandler, validationMw *middleware.ValidationMiddleware) {
+	slots := router.Group("/admin/delivery-slots")
+	{
+		slots.POST("",
+			validationMw.ValidateJSON(services.PlanDeliverySlotsRequest{}),
+			slotHandler.PlanSlots,
+		)
+
+		slots.GET("",
+			validationMw.ValidateQuery(services.ListDeliverySlotsRequest{}),
+			slotHandler.ListSlots,
+		)
+
+		slots.PATCH("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.UpdateDeliverySlotRequest{}),
+			slotHandler.UpdateSlot,
+		)
+	}
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterEventWebhookRoutes registers the admin routes for order and payment event
+// webhook endpoints and their delivery log
+func RegisterEventWebhookRoutes(router *gin.RouterGroup, eventWebhookHandler *handlers.EventWebhookHandler, validationMw *middleware.ValidationMiddleware) {
+	endpoints := router.Group("/admin/webhooks/endpoints")
+	{
+		endpoints.POST("",
+			validationMw.ValidateJSON(services.CreateWebhookEndpointRequest{}),
+			eventWebhookHandler.CreateWebhookEndpoint,
+		)
+
+		endpoints.GET("", eventWebhookHandler.ListWebhookEndpoints)
+
+		endpoints.PUT("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.UpdateWebhookEndpointRequest{}),
+			eventWebhookHandler.UpdateWebhookEndpoint,
+		)
+
+		endpoints.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			eventWebhookHandler.DeleteWebhookEndpoint,
+		)
+	}
+
+	deliveries := router.Group("/admin/webhooks/deliveries")
+	{
+		deliveries.GET("",
+			validationMw.ValidateQuery(services.ListWebhookDeliveriesRequest{}),
+			eventWebhookHandler.ListWebhookDeliveries,
+		)
+
+		deliveries.POST("/:id/redeliver",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			eventWebhookHandler.RedeliverWebhookDelivery,
+		)
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFulfillmentRoutes registers the admin routes for confirming paid orders and
+// the fulfillment queue
+func RegisterFulfillmentRoutes(router *gin.RouterGroup, fulfillmentHandler *handlers.FulfillmentHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/orders/:id/confirm",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			fulfillmentHandler.ConfirmOrder,
+		)
+		admin.GET("/orders/awaiting-confirmation",
+			validationMw.ValidateQuery(services.ListAwaitingConfirmationRequest{}),
+			fulfillmentHandler.ListAwaitingConfirmation,
+		)
+		admin.GET("/fulfillment/queue",
+			validationMw.ValidateQuery(services.ListFulfillmentQueueRequest{}),
+			fulfillmentHandler.ListFulfillmentQueue,
+		)
+	}
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterInventoryRoutes registers all inventory management routes
+func RegisterInventoryRoutes(router *gin.RouterGroup, inventoryHandler *handlers.InventoryHandler, authMw *middleware.AuthMiddleware, validationMw *middleware.ValidationMiddleware) {
+	inventory := router.Group("/inventory")
+	{
+		inventory.POST("/import",
+			authMw.RequireAdmin(),
+			inventoryHandler.ImportInventory,
+		)
+
+		inventory.GET("/imports/:id",
+			authMw.RequireAdmin(),
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateQuery(services.StockImportRowsRequest{}),
+			inventoryHandler.GetStockImport,
+		)
+
+		inventory.GET("/:product_id/history",
+			authMw.RequireAdmin(),
+			validationMw.ValidatePathParams(map[string]string{"product_id": "required"}),
+			validationMw.ValidateQuery(services.InventoryHistoryRequest{}),
+			inventoryHandler.GetInventoryHistory,
+		)
+	}
+}
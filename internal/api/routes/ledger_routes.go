@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterLedgerRoutes registers the payments ledger routes
+func RegisterLedgerRoutes(router *gin.RouterGroup, ledgerHandler *handlers.LedgerHandler, validationMw *middleware.ValidationMiddleware) {
+	ledger := router.Group("/admin/ledger")
+	{
+		ledger.GET("/balances",
+			validationMw.ValidateQuery(services.LedgerBalanceRequest{}),
+			ledgerHandler.GetLedgerBalances,
+		)
+		ledger.GET("/entries",
+			validationMw.ValidateQuery(services.ListLedgerEntriesRequest{}),
+			ledgerHandler.ListLedgerEntries,
+		)
+		ledger.GET("/consistency", ledgerHandler.CheckLedgerConsistency)
+	}
+}
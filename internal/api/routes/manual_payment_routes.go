@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterManualPaymentRoutes registers the admin routes for offline payments
+func RegisterManualPaymentRoutes(router *gin.RouterGroup, manualPaymentHandler *handlers.ManualPaymentHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/orders/:id/payments/manual",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.RecordManualPaymentRequest{}),
+			manualPaymentHandler.RecordManualPayment,
+		)
+		admin.GET("/payments/:id/proof",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			manualPaymentHandler.GetPaymentProof,
+		)
+	}
+}
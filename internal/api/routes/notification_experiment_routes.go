@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterNotificationExperimentRoutes registers the admin routes for A/B testing the
+// wording of customer notifications
+func RegisterNotificationExperimentRoutes(router *gin.RouterGroup, experimentHandler *handlers.NotificationExperimentHandler, validationMw *middleware.ValidationMiddleware) {
+	experiments := router.Group("/admin/notification-experiments")
+	{
+		experiments.POST("",
+			validationMw.ValidateJSON(services.CreateNotificationExperimentRequest{}),
+			experimentHandler.CreateNotificationExperiment,
+		)
+		experiments.GET("",
+			validationMw.ValidateQuery(services.ListNotificationExperimentsRequest{}),
+			experimentHandler.ListNotificationExperiments,
+		)
+		experiments.GET("/:id/results",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			experimentHandler.GetNotificationExperimentResults,
+		)
+		experiments.POST("/:id/stop",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.StopNotificationExperimentRequest{}),
+			experimentHandler.StopNotificationExperiment,
+		)
+	}
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterNotificationRoutes registers the notification routes used by system processes
+func RegisterNotificationRoutes(router *gin.RouterGroup, notificationHandler *handlers.NotificationHandler, validationMw *middleware.ValidationMiddleware) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.POST("/batch",
+			validationMw.ValidateJSON(services.SendBatchNotificationsRequest{}),
+			notificationHandler.SendBatch,
+		)
+		notifications.POST("/:id/receipts",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.NotificationReceiptRequest{}),
+			notificationHandler.RecordReceipt,
+		)
+	}
+}
+
+// RegisterUserNotificationRoutes registers the authenticated user's notification routes
+func RegisterUserNotificationRoutes(router *gin.RouterGroup, notificationHandler *handlers.NotificationHandler, validationMw *middleware.ValidationMiddleware) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.GET("",
+			validationMw.ValidateQuery(services.ListNotificationsRequest{}),
+			notificationHandler.ListNotifications,
+		)
+		notifications.PUT("/:id/read",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			notificationHandler.MarkRead,
+		)
+	}
+}
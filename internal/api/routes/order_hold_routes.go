@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterOrderHoldRoutes registers the admin routes for order compliance holds
+func RegisterOrderHoldRoutes(router *gin.RouterGroup, orderHoldHandler *handlers.OrderHoldHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/orders/:id/holds",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.PlaceOrderHoldRequest{}),
+			orderHoldHandler.PlaceOrderHold,
+		)
+		admin.GET("/orders/:id/holds",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			orderHoldHandler.GetOrderHolds,
+		)
+		admin.GET("/order-holds",
+			validationMw.ValidateQuery(services.ListOrderHoldsRequest{}),
+			orderHoldHandler.ListOrderHolds,
+		)
+		admin.GET("/order-holds/metrics",
+			validationMw.ValidateQuery(services.OrderHoldMetricsRequest{}),
+			orderHoldHandler.GetOrderHoldMetrics,
+		)
+		admin.PUT("/order-holds/:id/assignee",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.AssignOrderHoldRequest{}),
+			orderHoldHandler.AssignOrderHold,
+		)
+		admin.POST("/order-holds/:id/release",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.ReleaseOrderHoldRequest{}),
+			orderHoldHandler.ReleaseOrderHold,
+		)
+	}
+}
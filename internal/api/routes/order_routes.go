@@ -20,6 +20,10 @@ func RegisterOrderRoutes(router *gin.RouterGroup, handler *handlers.OrderHandler
 			validationMw.ValidateQuery(services.ListOrdersRequest{}),
 			handler.ListOrders,
 		)
+		orders.GET("/queue/:ticket",
+			validationMw.ValidatePathParams(map[string]string{"ticket": "required"}),
+			handler.GetQueuePosition,
+		)
 		orders.GET("/:id",
 			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
 			handler.GetOrder,
@@ -32,5 +36,19 @@ func RegisterOrderRoutes(router *gin.RouterGroup, handler *handlers.OrderHandler
 			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
 			handler.CancelOrder,
 		)
+		orders.PATCH("/:id/items/cancel",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.CancelOrderItemsRequest{}),
+			handler.CancelOrderItems,
+		)
+	}
+
+	users := router.Group("/users")
+	{
+		users.GET("/:id/orders",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateQuery(services.ListUserOrdersRequest{}),
+			handler.GetUserOrders,
+		)
 	}
 }
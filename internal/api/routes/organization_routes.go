@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterOrganizationRoutes registers the authenticated user's organization routes
+func RegisterOrganizationRoutes(router *gin.RouterGroup, organizationHandler *handlers.OrganizationHandler, validationMw *middleware.ValidationMiddleware) {
+	organization := router.Group("/organizations/me")
+	{
+		organization.GET("", organizationHandler.GetMyOrganization)
+		organization.GET("/orders",
+			validationMw.ValidateQuery(services.ListOrdersRequest{}),
+			organizationHandler.ListMyOrganizationOrders,
+		)
+	}
+}
+
+// RegisterAdminOrganizationRoutes registers the organization, credit review and bulk user import admin routes
+func RegisterAdminOrganizationRoutes(router *gin.RouterGroup, organizationHandler *handlers.OrganizationHandler, validationMw *middleware.ValidationMiddleware) {
+	organizations := router.Group("/admin/organizations")
+	{
+		organizations.POST("",
+			validationMw.ValidateJSON(services.CreateOrganizationRequest{}),
+			organizationHandler.CreateOrganization,
+		)
+
+		organizations.GET("",
+			validationMw.ValidateQuery(services.ListOrganizationsRequest{}),
+			organizationHandler.ListOrganizations,
+		)
+
+		organizations.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			organizationHandler.GetOrganization,
+		)
+
+		organizations.PATCH("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.UpdateOrganizationRequest{}),
+			organizationHandler.UpdateOrganization,
+		)
+
+		organizations.POST("/:id/members",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.AddOrganizationMemberRequest{}),
+			organizationHandler.AddOrganizationMember,
+		)
+
+		organizations.DELETE("/:id/members/:user_id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required", "user_id": "required"}),
+			organizationHandler.RemoveOrganizationMember,
+		)
+	}
+
+	creditHolds := router.Group("/admin/orders")
+	{
+		creditHolds.GET("/credit-holds", organizationHandler.ListCreditHolds)
+
+		creditHolds.POST("/:id/credit-review",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.ReviewCreditHoldRequest{}),
+			organizationHandler.ReviewCreditHold,
+		)
+	}
+
+	router.POST("/admin/users/import", organizationHandler.ImportUsers)
+}
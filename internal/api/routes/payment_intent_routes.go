@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPaymentIntentRoutes registers the routes of the authenticated user's card
+// payment intents
+func RegisterPaymentIntentRoutes(router *gin.RouterGroup, handler *handlers.PaymentIntentHandler, validationMw *middleware.ValidationMiddleware) {
+	intents := router.Group("/payment-intents")
+	{
+		intents.POST("",
+			validationMw.ValidateJSON(services.CreatePaymentIntentRequest{}),
+			handler.CreateIntent,
+		)
+		intents.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			handler.GetIntent,
+		)
+		intents.POST("/:id/confirm",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.ConfirmPaymentIntentRequest{}),
+			handler.ConfirmIntent,
+		)
+		intents.POST("/:id/cancel",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			handler.CancelIntent,
+		)
+	}
+}
@@ -20,6 +20,14 @@ func RegisterPaymentRoutes(router *gin.RouterGroup, handler *handlers.PaymentHan
 			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
 			handler.GetPayment,
 		)
+		payments.POST("/:id/resume",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			handler.ResumePayment,
+		)
+		payments.GET("/:id/refunds",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			handler.ListRefunds,
+		)
 	}
 
 	// Order payment routes
@@ -28,3 +36,12 @@ func RegisterPaymentRoutes(router *gin.RouterGroup, handler *handlers.PaymentHan
 		handler.GetOrderPayments,
 	)
 }
+
+// RegisterPaymentRefundRoutes registers the admin route for refunding payments
+func RegisterPaymentRefundRoutes(router *gin.RouterGroup, handler *handlers.PaymentHandler, validationMw *middleware.ValidationMiddleware) {
+	router.POST("/payments/:id/refunds",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		validationMw.ValidateJSON(services.RefundRequest{}),
+		handler.RefundPayment,
+	)
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPaymentRoutingRoutes registers the admin routes for payment routing rules
+// and their dry-run evaluation
+func RegisterPaymentRoutingRoutes(router *gin.RouterGroup, routingHandler *handlers.PaymentRoutingHandler, validationMw *middleware.ValidationMiddleware) {
+	rules := router.Group("/admin/payments/routing-rules")
+	{
+		rules.POST("",
+			validationMw.ValidateJSON(services.PaymentRoutingRuleRequest{}),
+			routingHandler.CreateRoutingRule,
+		)
+
+		rules.GET("",
+			routingHandler.ListRoutingRules,
+		)
+
+		rules.POST("/evaluate",
+			validationMw.ValidateJSON(services.EvaluatePaymentRoutingRequest{}),
+			routingHandler.EvaluateRoutingRules,
+		)
+
+		rules.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			routingHandler.GetRoutingRule,
+		)
+
+		rules.PUT("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.PaymentRoutingRuleRequest{}),
+			routingHandler.UpdateRoutingRule,
+		)
+
+		rules.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			routingHandler.DeleteRoutingRule,
+		)
+	}
+}
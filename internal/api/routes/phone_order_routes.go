@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPhoneOrderRoutes registers the admin routes for orders placed on behalf of customers
+func RegisterPhoneOrderRoutes(router *gin.RouterGroup, phoneOrderHandler *handlers.PhoneOrderHandler, validationMw *middleware.ValidationMiddleware) {
+	customers := router.Group("/admin/users/:id")
+	{
+		customers.GET("/phone-order/products",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateQuery(services.PhoneOrderProductSearchRequest{}),
+			phoneOrderHandler.SearchProducts,
+		)
+		customers.GET("/cart/holds",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			phoneOrderHandler.GetCart,
+		)
+		customers.POST("/cart/holds",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.HoldCartItemRequest{}),
+			phoneOrderHandler.HoldCartItem,
+		)
+		customers.DELETE("/cart/holds/:product_id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required", "product_id": "required"}),
+			phoneOrderHandler.ReleaseCartItem,
+		)
+		customers.POST("/phone-orders",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.PlacePhoneOrderRequest{}),
+			phoneOrderHandler.PlaceOrder,
+		)
+	}
+
+	router.GET("/admin/reports/agent-sales",
+		validationMw.ValidateQuery(services.AgentSalesReportRequest{}),
+		phoneOrderHandler.GenerateAgentSalesReport,
+	)
+}
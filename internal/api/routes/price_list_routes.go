@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPriceListRoutes registers the admin routes for B2B price lists and their
+// assignment to organizations and customers
+func RegisterPriceListRoutes(router *gin.RouterGroup, priceListHandler *handlers.PriceListHandler, validationMw *middleware.ValidationMiddleware) {
+	priceLists := router.Group("/admin/price-lists")
+	{
+		priceLists.POST("",
+			validationMw.ValidateJSON(services.CreatePriceListRequest{}),
+			priceListHandler.CreatePriceList,
+		)
+
+		priceLists.GET("",
+			validationMw.ValidateQuery(services.ListPriceListsRequest{}),
+			priceListHandler.ListPriceLists,
+		)
+
+		priceLists.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			priceListHandler.GetPriceList,
+		)
+
+		priceLists.PATCH("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.UpdatePriceListRequest{}),
+			priceListHandler.UpdatePriceList,
+		)
+
+		priceLists.POST("/:id/import",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			priceListHandler.ImportPriceListItems,
+		)
+	}
+
+	router.PUT("/admin/organizations/:id/price-list",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		validationMw.ValidateJSON(services.AssignPriceListRequest{}),
+		priceListHandler.AssignOrganizationPriceList,
+	)
+
+	router.PUT("/admin/users/:id/price-list",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		validationMw.ValidateJSON(services.AssignPriceListRequest{}),
+		priceListHandler.AssignUserPriceList,
+	)
+}
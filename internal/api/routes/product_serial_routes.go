@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterProductSerialRoutes registers the admin routes for receiving serial numbers
+// of serialized products and capturing them on order items at fulfillment
+func RegisterProductSerialRoutes(router *gin.RouterGroup, serialHandler *handlers.ProductSerialHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/products/:id/serials",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.ReceiveProductSerialsRequest{}),
+			serialHandler.ReceiveProductSerials,
+		)
+		admin.GET("/products/:id/serials",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateQuery(services.ListProductSerialsRequest{}),
+			serialHandler.ListProductSerials,
+		)
+		admin.PUT("/orders/:id/items/:item_id/serials",
+			validationMw.ValidatePathParams(map[string]string{"id": "required", "item_id": "required"}),
+			validationMw.ValidateJSON(services.CaptureOrderItemSerialsRequest{}),
+			serialHandler.CaptureOrderItemSerials,
+		)
+	}
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPromotionRoutes registers the admin routes for checkout promotions and
+// their performance report
+func RegisterPromotionRoutes(router *gin.RouterGroup, promotionHandler *handlers.PromotionHandler, validationMw *middleware.ValidationMiddleware) {
+	promotions := router.Group("/admin/promotions")
+	{
+		promotions.POST("",
+			validationMw.ValidateJSON(services.CreatePromotionRequest{}),
+			promotionHandler.CreatePromotion,
+		)
+
+		promotions.GET("",
+			validationMw.ValidateQuery(services.ListPromotionsRequest{}),
+			promotionHandler.ListPromotions,
+		)
+
+		promotions.GET("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			promotionHandler.GetPromotion,
+		)
+
+		promotions.PATCH("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.UpdatePromotionRequest{}),
+			promotionHandler.UpdatePromotion,
+		)
+	}
+
+	router.GET("/admin/reports/promotions",
+		validationMw.ValidateQuery(services.PromotionReportRequest{}),
+		promotionHandler.GeneratePromotionReport,
+	)
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterReferralRoutes registers the routes users share their referral code, follow
+// their referrals and see their store credit with
+func RegisterReferralRoutes(router *gin.RouterGroup, referralHandler *handlers.ReferralHandler, validationMw *middleware.ValidationMiddleware) {
+	referrals := router.Group("/referrals")
+	{
+		referrals.GET("", referralHandler.ListReferrals)
+		referrals.GET("/code", referralHandler.GetReferralCode)
+	}
+
+	router.GET("/store-credit", referralHandler.GetStoreCredit)
+}
+
+// RegisterAdminReferralRoutes registers the admin routes for referral rewards and the
+// referral performance report
+func RegisterAdminReferralRoutes(router *gin.RouterGroup, referralHandler *handlers.ReferralHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.POST("/referrals/process-rewards", referralHandler.ProcessReferralRewards)
+		admin.GET("/reports/referrals",
+			validationMw.ValidateQuery(services.ReferralReportRequest{}),
+			referralHandler.GenerateReferralReport,
+		)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRetentionRoutes registers the data retention routes
+func RegisterRetentionRoutes(router *gin.RouterGroup, retentionHandler *handlers.RetentionHandler, validationMw *middleware.ValidationMiddleware) {
+	retention := router.Group("/admin/retention")
+	{
+		retention.GET("/metrics", retentionHandler.GetRetentionMetrics)
+		retention.POST("/purge",
+			validationMw.ValidateQuery(services.RetentionPurgeRequest{}),
+			retentionHandler.PurgeExpiredRecords,
+		)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSupportSearchRoutes registers the customer support lookup routes
+func RegisterSupportSearchRoutes(router *gin.RouterGroup, searchHandler *handlers.SupportSearchHandler, validationMw *middleware.ValidationMiddleware) {
+	support := router.Group("/support")
+	{
+		support.GET("/search",
+			validationMw.ValidateQuery(services.SupportSearchRequest{}),
+			searchHandler.Search,
+		)
+	}
+}
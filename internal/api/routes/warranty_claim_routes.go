@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWarrantyClaimRoutes registers the routes customers claim received units
+// under warranty and follow their claims with
+func RegisterWarrantyClaimRoutes(router *gin.RouterGroup, claimHandler *handlers.WarrantyClaimHandler, validationMw *middleware.ValidationMiddleware) {
+	router.POST("/orders/:id/items/:item_id/claims",
+		validationMw.ValidatePathParams(map[string]string{"id": "required", "item_id": "required"}),
+		validationMw.ValidateJSON(services.CreateWarrantyClaimRequest{}),
+		claimHandler.CreateWarrantyClaim,
+	)
+	router.GET("/warranty-claims",
+		validationMw.ValidateQuery(services.ListWarrantyClaimsRequest{}),
+		claimHandler.ListUserWarrantyClaims,
+	)
+}
+
+// RegisterAdminWarrantyClaimRoutes registers the admin routes for reviewing warranty
+// claims and the claim rate report
+func RegisterAdminWarrantyClaimRoutes(router *gin.RouterGroup, claimHandler *handlers.WarrantyClaimHandler, validationMw *middleware.ValidationMiddleware) {
+	admin := router.Group("/admin")
+	{
+		admin.GET("/warranty-claims",
+			validationMw.ValidateQuery(services.ListWarrantyClaimsRequest{}),
+			claimHandler.ListWarrantyClaims,
+		)
+		admin.POST("/warranty-claims/:id/resolve",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.ResolveWarrantyClaimRequest{}),
+			claimHandler.ResolveWarrantyClaim,
+		)
+		admin.POST("/warranty-claims/:id/reject",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			validationMw.ValidateJSON(services.RejectWarrantyClaimRequest{}),
+			claimHandler.RejectWarrantyClaim,
+		)
+		admin.GET("/reports/warranty-claims",
+			validationMw.ValidateQuery(services.WarrantyClaimRateReportRequest{}),
+			claimHandler.GenerateWarrantyClaimRateReport,
+		)
+	}
+}
@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWebhookRoutes registers the public inbound webhook routes
+func RegisterWebhookRoutes(router *gin.RouterGroup, webhookHandler *handlers.WebhookHandler, validationMw *middleware.ValidationMiddleware) {
+	// Partner events, signed with the partner's secret
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.POST("/:provider",
+			validationMw.ValidatePathParams(map[string]string{"provider": "required"}),
+			webhookHandler.ReceiveWebhook,
+		)
+	}
+
+	// Signed payment callbacks, verified per gateway
+	router.POST("/payments/webhooks/:gateway",
+		validationMw.ValidatePathParams(map[string]string{"gateway": "required"}),
+		webhookHandler.ReceivePaymentWebhook,
+	)
+}
+
+// RegisterAdminWebhookRoutes registers the webhook event log and subscription admin routes
+func RegisterAdminWebhookRoutes(router *gin.RouterGroup, webhookHandler *handlers.WebhookHandler, validationMw *middleware.ValidationMiddleware) {
+	events := router.Group("/admin/webhooks/events")
+	{
+		events.GET("",
+			validationMw.ValidateQuery(services.ListWebhookEventsRequest{}),
+			webhookHandler.ListWebhookEvents,
+		)
+
+		events.POST("/:id/replay",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			webhookHandler.ReplayWebhookEvent,
+		)
+	}
+
+	subscriptions := router.Group("/admin/webhooks/subscriptions")
+	{
+		subscriptions.POST("",
+			validationMw.ValidateJSON(services.CreateStockWebhookSubscriptionRequest{}),
+			webhookHandler.CreateStockWebhookSubscription,
+		)
+
+		subscriptions.GET("", webhookHandler.ListStockWebhookSubscriptions)
+
+		subscriptions.DELETE("/:id",
+			validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+			webhookHandler.DeleteStockWebhookSubscription,
+		)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWorkerPoolRoutes registers the admin routes for the background worker pools
+func RegisterWorkerPoolRoutes(router *gin.RouterGroup, workerPoolHandler *handlers.WorkerPoolHandler, validationMw *middleware.ValidationMiddleware) {
+	pools := router.Group("/admin/workers")
+	{
+		pools.GET("/stats", workerPoolHandler.GetStats)
+		pools.POST("/resize",
+			validationMw.ValidateJSON(services.ResizeWorkerPoolRequest{}),
+			workerPoolHandler.Resize,
+		)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,27 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Redis    RedisConfig
+	Channels ChannelsConfig
+	Cache    CacheConfig
+	Payments PaymentsConfig
+	Cart     CartConfig
+	Notify   NotifyConfig
+
+	Availability AvailabilityConfig
+	Metrics      MetricsConfig
+	Migrations   MigrationsConfig
+	Stores       StoresConfig
+	Stock        StockConfig
+	ChangeFeeds  ChangeFeedsConfig
+	Retention    RetentionConfig
+	Orders       OrdersConfig
+	Webhooks     WebhooksConfig
+	Usage        UsageConfig
+	Referrals    ReferralsConfig
+	Promotions   PromotionsConfig
+	Reports      ReportsConfig
+	Storage      StorageConfig
+	Jobs         JobsConfig
 }
 
 type ServerConfig struct {
@@ -45,7 +67,331 @@ type RedisConfig struct {
 	DB       int
 }
 
+// ChannelsConfig holds marketplace connector credentials; empty values disable a connector
+type ChannelsConfig struct {
+	ShopifyShopDomain  string
+	ShopifyAccessToken string
+	ShopifyLocationID  int
+}
+
+// MigrationsConfig controls schema changes to hot tables at startup. DDL gives up
+// after LockTimeout instead of queueing behind stock reservations, and is retried
+// LockRetries times; backfills update BackfillBatchSize rows at a time, pausing
+// BackfillPause between batches. MoneyDualWrite keeps the minor-unit money columns
+// in sync with the decimal ones.
+type MigrationsConfig struct {
+	LockTimeout       time.Duration
+	LockRetries       int
+	BackfillBatchSize int
+	BackfillPause     time.Duration
+	MoneyDualWrite    bool
+}
+
+// CacheConfig selects which repositories serve reads from the in-memory query cache
+type CacheConfig struct {
+	Products   bool
+	Inventory  bool
+	TTL        time.Duration
+	MaxEntries int
+}
+
+// PaymentsConfig holds checkout payment settings. MethodAdjustments maps a payment
+// method to a percentage of the order subtotal: positive is a surcharge, negative a discount.
+// Transiently failed payments are held for retry within RetryWindow; zero fails them right away.
+// A Stripe secret key charges Stripe payments through Stripe instead of the simulated gateway.
+// Gateway payment webhooks are accepted from Stripe once StripeWebhookSecret is set, and
+// from the gateways in WebhookSecrets, which sign with a shared secret.
+// Payments are routed to gateways per RoutingStrategy: "ordered" takes the first healthy
+// gateway by name, "weighted" spreads payments per GatewayWeights and "least_latency"
+// picks the fastest gateway recently; CurrencyGateways pins currencies to gateways first.
+type PaymentsConfig struct {
+	MethodAdjustments map[string]float64
+
+	StripeSecretKey        string
+	StripeAPIVersion       string
+	StripeWebhookSecret    string
+	StripeWebhookTolerance time.Duration
+	WebhookSecrets         map[string]string
+
+	RoutingStrategy  string
+	GatewayWeights   map[string]int
+	CurrencyGateways map[string]string
+
+	RetryWindow        time.Duration
+	RetryBackoff       time.Duration
+	RetrySweepInterval time.Duration
+
+	// Reconciliation checks pending and processed payments untouched for
+	// ReconcileThreshold, and payments failed within ReconcileLookback, against their
+	// gateway every ReconcileInterval; a zero interval turns it off
+	ReconcileInterval  time.Duration
+	ReconcileThreshold time.Duration
+	ReconcileLookback  time.Duration
+
+	// Payments of gateways without a webhook secret are polled every PollInterval
+	// once pending or processed for PollThreshold, and escalated to the admins once
+	// still unsettled after PollEscalateAfter; a zero interval turns polling off and a
+	// zero escalation never escalates
+	PollInterval      time.Duration
+	PollThreshold     time.Duration
+	PollEscalateAfter time.Duration
+
+	// Card payment intents expire IntentTTL after they were created unless confirmed.
+	// Every IntentSweepInterval lapsed intents are expired and processing ones follow
+	// their settled payments; a zero interval turns the sweep off.
+	IntentTTL           time.Duration
+	IntentSweepInterval time.Duration
+}
+
+// CartConfig controls soft stock holds for cart items. HoldWindow is the store
+// default, which products can override.
+type CartConfig struct {
+	HoldsEnabled      bool
+	HoldWindow        time.Duration
+	HoldSweepInterval time.Duration
+}
+
+// NotifyConfig selects which order status changes notify the customer and on which
+// notification channels. An empty status list disables order status notifications.
+// Failover lists, per notification type or "*" for the others, the channels to fall
+// back to in order when delivery fails; a channel failing ChannelFailureThreshold
+// times is skipped for ChannelResetTimeout.
+type NotifyConfig struct {
+	OrderStatuses           []string
+	OrderChannels           []string
+	Failover                map[string][]string
+	ChannelFailureThreshold int
+	ChannelResetTimeout     time.Duration
+}
+
+// AvailabilityConfig bounds how old the storefront availability read model may be
+// before reads fall back to re-materializing it from inventory
+type AvailabilityConfig struct {
+	MaxStaleness time.Duration
+}
+
+// StoresConfig sets when each store, identified by its order channel, accepts and
+// ships orders. Hours and ShipCutoffs are keyed by channel, "*" being the default
+// store; stores without hours are always open and promise no ship date. Stores in
+// RejectOutsideHours refuse orders while closed instead of shipping them later.
+// Stores in GiftOptions, or every store with "*", take gift orders. Paid orders of
+// stores in ManualConfirmation, or of every store with "*", wait for an admin to
+// confirm them before they are queued for fulfillment.
+type StoresConfig struct {
+	Timezone           string
+	Hours              map[string]string
+	ShipCutoffs        map[string]string
+	RejectOutsideHours []string
+	GiftOptions        []string
+	ManualConfirmation []string
+}
+
+// StockConfig sets when products are low on stock. With DynamicThresholds, a
+// product's threshold covers its average daily sales over VelocityWindow for its
+// supplier lead time plus SafetyDays; otherwise it is the product's MinStock.
+// LeadTimeDays is the store default, which products can override. Every
+// ShardRebalanceInterval, the stock of sharded products is evened out over their
+// shards; 0 turns rebalancing off.
+type StockConfig struct {
+	DynamicThresholds      bool
+	VelocityWindow         time.Duration
+	LeadTimeDays           int
+	SafetyDays             int
+	ShardRebalanceInterval time.Duration
+}
+
+// ChangeFeedsConfig sets how long change feeds hold back new events. Events get their
+// position when written but become visible when their transaction commits, so feeds
+// only serve events older than SettleDelay, which must outlast those transactions.
+// When CatalogPurgeURL is set, catalog changes are also POSTed there every
+// CatalogPurgeInterval, signed with CatalogPurgeSecret, for CDN invalidation.
+type ChangeFeedsConfig struct {
+	SettleDelay          time.Duration
+	CatalogPurgeURL      string
+	CatalogPurgeSecret   string
+	CatalogPurgeInterval time.Duration
+}
+
+// RetentionConfig sets how many days records are kept before the purger removes
+// them; 0 keeps them forever. Report artifacts are files written by imports, such
+// as inventory recount variance reports. With DryRun, purges only count what they
+// would remove.
+type RetentionConfig struct {
+	NotificationDays   int
+	IdempotencyKeyDays int
+	WebhookEventDays   int
+	ReportArtifactDays int
+	PurgeInterval      time.Duration
+	DryRun             bool
+}
+
+// OrdersConfig sets how likely duplicate orders are caught: an order of the same items
+// as one the user placed within DuplicateWindow is flagged, and with
+// DuplicateConfirmation it is refused until the user confirms it. A zero window turns
+// the check off. Privileged users may override an order item's price up to
+// MaxPriceDiscountPercent below its list price. MaxItems, MaxQuantityPerSKU and
+// MaxPerUserPerHour are the initial order limits; zero is no limit. With
+// AllocationQueue on, as during flash sales, orders are granted stock in arrival order:
+// each waits up to AllocationQueueWait for its turn before being given a queue ticket,
+// which is dropped if not used within AllocationQueueTicketTTL. Every
+// ConfirmationInterval, orders paid since the last run are confirmed and queued for
+// fulfillment; zero turns automatic confirmation off. Every NotificationInterval,
+// customers are notified of the status changes recorded since the last run; zero
+// turns status notifications off. Every CheckoutRecoveryInterval, express checkouts
+// stopped mid-step for CheckoutStaleAfter, as by a crash, are resumed or compensated;
+// zero turns recovery off.
+type OrdersConfig struct {
+	DuplicateWindow          time.Duration
+	DuplicateConfirmation    bool
+	MaxPriceDiscountPercent  float64
+	MaxItems                 int
+	MaxQuantityPerSKU        int
+	MaxPerUserPerHour        int
+	AllocationQueue          bool
+	AllocationQueueWait      time.Duration
+	AllocationQueueTicketTTL time.Duration
+	HoldSLA                  time.Duration
+	ConfirmationInterval     time.Duration
+	NotificationInterval     time.Duration
+	CheckoutRecoveryInterval time.Duration
+	CheckoutStaleAfter       time.Duration
+}
+
+// WebhooksConfig sets how order and payment events are delivered to registered
+// webhook endpoints. Every DeliveryInterval, new order events are queued and due
+// deliveries sent, each attempt waiting up to Timeout. Failed attempts are retried
+// after InitialBackoff, doubling up to MaxBackoff, until MaxAttempts have failed.
+// Inbound partner events are accepted only from the providers in PartnerSecrets,
+// signed with their secret.
+type WebhooksConfig struct {
+	DeliveryInterval time.Duration
+	Timeout          time.Duration
+	MaxAttempts      int
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	PartnerSecrets   map[string]string
+}
+
+// UsageConfig sets how API usage is tracked. Requests are counted in memory and
+// written to the usage table every FlushInterval. New API keys get a monthly quota
+// of DefaultMonthlyQuota requests; 0 leaves them unlimited.
+type UsageConfig struct {
+	FlushInterval       time.Duration
+	DefaultMonthlyQuota int
+}
+
+// ReferralsConfig sets the store credit a referrer earns when a referred user's first
+// order was delivered and not returned within ReturnWindow. Every SweepInterval,
+// referrals whose order settled are rewarded, voided if the order was returned, or
+// freed for the next order if it was cancelled.
+type ReferralsConfig struct {
+	RewardAmount  float64
+	ReturnWindow  time.Duration
+	SweepInterval time.Duration
+}
+
+// PromotionsConfig holds checkout promotion settings. Every order is charged
+// ShippingFee unless a free shipping promotion waives it.
+type PromotionsConfig struct {
+	ShippingFee float64
+}
+
+// ReportsConfig selects where generated reports are cached: "memory" keeps them in each
+// instance, "redis" shares them between instances through the Redis server, under
+// CacheKeyPrefix. Report files in storage of at least SignedURLThreshold bytes are
+// downloaded from a URL signed for SignedURLExpiry; zero serves every file directly.
+// The end-of-day close report of each store in CloseTimes, keyed by order channel with
+// "*" for all stores, is emailed to the users in CloseRecipients at its local time,
+// checked every CloseCheckInterval.
+type ReportsConfig struct {
+	CacheBackend       string
+	CacheKeyPrefix     string
+	SignedURLThreshold int64
+	SignedURLExpiry    time.Duration
+	CloseTimes         map[string]string
+	CloseRecipients    []string
+	CloseCheckInterval time.Duration
+}
+
+// StorageConfig selects where files such as exported reports are kept: "database"
+// keeps them in their database rows, "local" in LocalDir, and "s3" in the bucket of
+// Amazon S3 or an S3-compatible service at S3Endpoint
+type StorageConfig struct {
+	Backend           string
+	LocalDir          string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+}
+
+// JobsConfig tunes the durable job queue behind the worker pools. Every PollInterval
+// each pool leases the stored jobs that are due, up to its free queue space. A leased
+// job is hidden from other instances for VisibilityTimeout, renewed while it runs, so
+// the jobs of an instance that crashed run again once their lease expires.
+type JobsConfig struct {
+	PollInterval      time.Duration
+	VisibilityTimeout time.Duration
+}
+
+// MetricsConfig sizes the window of recent executions that order placement and
+// payment stage latency percentiles are computed over
+type MetricsConfig struct {
+	StageWindow int
+}
+
 func Load() (*Config, error) {
+	methodAdjustments, err := parsePercentMap(getEnv("PAYMENT_METHOD_ADJUSTMENTS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_METHOD_ADJUSTMENTS: %w", err)
+	}
+
+	gatewayWeights, err := parseWeightMap(getEnv("PAYMENT_GATEWAY_WEIGHTS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_GATEWAY_WEIGHTS: %w", err)
+	}
+
+	currencyGateways, err := parsePairMap(getEnv("PAYMENT_CURRENCY_GATEWAYS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_CURRENCY_GATEWAYS: %w", err)
+	}
+
+	webhookSecrets, err := parsePairMap(getEnv("PAYMENT_WEBHOOK_SECRETS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAYMENT_WEBHOOK_SECRETS: %w", err)
+	}
+
+	partnerWebhookSecrets, err := parsePairMap(getEnv("PARTNER_WEBHOOK_SECRETS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PARTNER_WEBHOOK_SECRETS: %w", err)
+	}
+
+	storeHours, err := parseStoreMap(getEnv("STORE_HOURS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STORE_HOURS: %w", err)
+	}
+
+	shipCutoffs, err := parseStoreMap(getEnv("STORE_SHIP_CUTOFFS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STORE_SHIP_CUTOFFS: %w", err)
+	}
+
+	closeTimes, err := parseStoreMap(getEnv("REPORT_CLOSE_TIMES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPORT_CLOSE_TIMES: %w", err)
+	}
+
+	failover, err := parseStoreMap(getEnv("NOTIFICATION_FAILOVER", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_FAILOVER: %w", err)
+	}
+	notificationFailover := make(map[string][]string, len(failover))
+	for notificationType, channels := range failover {
+		notificationFailover[notificationType] = parseList(channels)
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
 			Port:         getEnv("SERVER_PORT", "8080"),
@@ -74,6 +420,152 @@ func Load() (*Config, error) {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getIntEnv("REDIS_DB", 0),
 		},
+		Channels: ChannelsConfig{
+			ShopifyShopDomain:  getEnv("SHOPIFY_SHOP_DOMAIN", ""),
+			ShopifyAccessToken: getEnv("SHOPIFY_ACCESS_TOKEN", ""),
+			ShopifyLocationID:  getIntEnv("SHOPIFY_LOCATION_ID", 0),
+		},
+		Migrations: MigrationsConfig{
+			LockTimeout:       getDurationEnv("MIGRATION_LOCK_TIMEOUT", 2*time.Second),
+			LockRetries:       getIntEnv("MIGRATION_LOCK_RETRIES", 5),
+			BackfillBatchSize: getIntEnv("MIGRATION_BACKFILL_BATCH_SIZE", 1000),
+			BackfillPause:     getDurationEnv("MIGRATION_BACKFILL_PAUSE", 100*time.Millisecond),
+			MoneyDualWrite:    getBoolEnv("MIGRATION_MONEY_DUAL_WRITE", true),
+		},
+		Cache: CacheConfig{
+			Products:   getBoolEnv("REPO_CACHE_PRODUCTS", false),
+			Inventory:  getBoolEnv("REPO_CACHE_INVENTORY", false),
+			TTL:        getDurationEnv("REPO_CACHE_TTL", 30*time.Second),
+			MaxEntries: getIntEnv("REPO_CACHE_MAX_ENTRIES", 10000),
+		},
+		Payments: PaymentsConfig{
+			MethodAdjustments:      methodAdjustments,
+			RetryWindow:            getDurationEnv("PAYMENT_RETRY_WINDOW", 0),
+			RetryBackoff:           getDurationEnv("PAYMENT_RETRY_BACKOFF", 30*time.Second),
+			RetrySweepInterval:     getDurationEnv("PAYMENT_RETRY_SWEEP_INTERVAL", 30*time.Second),
+			ReconcileInterval:      getDurationEnv("PAYMENT_RECONCILE_INTERVAL", 15*time.Minute),
+			ReconcileThreshold:     getDurationEnv("PAYMENT_RECONCILE_THRESHOLD", 30*time.Minute),
+			ReconcileLookback:      getDurationEnv("PAYMENT_RECONCILE_LOOKBACK", 72*time.Hour),
+			PollInterval:           getDurationEnv("PAYMENT_POLL_INTERVAL", time.Minute),
+			PollThreshold:          getDurationEnv("PAYMENT_POLL_THRESHOLD", 2*time.Minute),
+			PollEscalateAfter:      getDurationEnv("PAYMENT_POLL_ESCALATE_AFTER", time.Hour),
+			IntentTTL:              getDurationEnv("PAYMENT_INTENT_TTL", 30*time.Minute),
+			IntentSweepInterval:    getDurationEnv("PAYMENT_INTENT_SWEEP_INTERVAL", time.Minute),
+			StripeSecretKey:        getEnv("STRIPE_SECRET_KEY", ""),
+			StripeAPIVersion:       getEnv("STRIPE_API_VERSION", ""),
+			StripeWebhookSecret:    getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			StripeWebhookTolerance: getDurationEnv("STRIPE_WEBHOOK_TOLERANCE", 5*time.Minute),
+			WebhookSecrets:         webhookSecrets,
+			RoutingStrategy:        getEnv("PAYMENT_ROUTING_STRATEGY", "ordered"),
+			GatewayWeights:         gatewayWeights,
+			CurrencyGateways:       currencyGateways,
+		},
+		Cart: CartConfig{
+			HoldsEnabled:      getBoolEnv("CART_HOLDS_ENABLED", false),
+			HoldWindow:        getDurationEnv("CART_HOLD_WINDOW", 15*time.Minute),
+			HoldSweepInterval: getDurationEnv("CART_HOLD_SWEEP_INTERVAL", time.Minute),
+		},
+		Notify: NotifyConfig{
+			OrderStatuses:           parseList(getEnv("ORDER_STATUS_NOTIFICATIONS", "confirmed,shipped,delivered,cancelled")),
+			OrderChannels:           parseList(getEnv("ORDER_STATUS_NOTIFICATION_CHANNELS", "email,in_app")),
+			Failover:                notificationFailover,
+			ChannelFailureThreshold: getIntEnv("NOTIFICATION_CHANNEL_FAILURE_THRESHOLD", 5),
+			ChannelResetTimeout:     getDurationEnv("NOTIFICATION_CHANNEL_RESET_TIMEOUT", time.Minute),
+		},
+		Availability: AvailabilityConfig{
+			MaxStaleness: getDurationEnv("AVAILABILITY_MAX_STALENESS", 5*time.Minute),
+		},
+		Metrics: MetricsConfig{
+			StageWindow: getIntEnv("STAGE_METRICS_WINDOW", 1000),
+		},
+		Stores: StoresConfig{
+			Timezone:           getEnv("STORE_TIMEZONE", "UTC"),
+			Hours:              storeHours,
+			ShipCutoffs:        shipCutoffs,
+			RejectOutsideHours: parseList(getEnv("STORE_REJECT_OUTSIDE_HOURS", "")),
+			GiftOptions:        parseList(getEnv("STORE_GIFT_OPTIONS", "")),
+			ManualConfirmation: parseList(getEnv("STORE_MANUAL_CONFIRMATION", "")),
+		},
+		Stock: StockConfig{
+			DynamicThresholds:      getBoolEnv("LOW_STOCK_DYNAMIC_THRESHOLDS", false),
+			VelocityWindow:         getDurationEnv("LOW_STOCK_VELOCITY_WINDOW", 30*24*time.Hour),
+			LeadTimeDays:           getIntEnv("LOW_STOCK_LEAD_TIME_DAYS", 7),
+			SafetyDays:             getIntEnv("LOW_STOCK_SAFETY_DAYS", 3),
+			ShardRebalanceInterval: getDurationEnv("INVENTORY_SHARD_REBALANCE_INTERVAL", 30*time.Second),
+		},
+		ChangeFeeds: ChangeFeedsConfig{
+			SettleDelay:          getDurationEnv("CHANGE_FEED_SETTLE_DELAY", 5*time.Second),
+			CatalogPurgeURL:      getEnv("CATALOG_PURGE_WEBHOOK_URL", ""),
+			CatalogPurgeSecret:   getEnv("CATALOG_PURGE_WEBHOOK_SECRET", ""),
+			CatalogPurgeInterval: getDurationEnv("CATALOG_PURGE_INTERVAL", 5*time.Second),
+		},
+		Retention: RetentionConfig{
+			NotificationDays:   getIntEnv("RETENTION_NOTIFICATION_DAYS", 180),
+			IdempotencyKeyDays: getIntEnv("RETENTION_IDEMPOTENCY_KEY_DAYS", 30),
+			WebhookEventDays:   getIntEnv("RETENTION_WEBHOOK_EVENT_DAYS", 90),
+			ReportArtifactDays: getIntEnv("RETENTION_REPORT_ARTIFACT_DAYS", 30),
+			PurgeInterval:      getDurationEnv("RETENTION_PURGE_INTERVAL", time.Hour),
+			DryRun:             getBoolEnv("RETENTION_DRY_RUN", false),
+		},
+		Orders: OrdersConfig{
+			DuplicateWindow:          getDurationEnv("ORDER_DUPLICATE_WINDOW", 10*time.Minute),
+			DuplicateConfirmation:    getBoolEnv("ORDER_DUPLICATE_REQUIRE_CONFIRMATION", false),
+			MaxPriceDiscountPercent:  getFloatEnv("ORDER_PRICE_OVERRIDE_MAX_DISCOUNT_PERCENT", 20),
+			MaxItems:                 getIntEnv("ORDER_MAX_ITEMS", 0),
+			MaxQuantityPerSKU:        getIntEnv("ORDER_MAX_QUANTITY_PER_SKU", 0),
+			MaxPerUserPerHour:        getIntEnv("ORDER_MAX_PER_USER_PER_HOUR", 0),
+			AllocationQueue:          getBoolEnv("ORDER_ALLOCATION_QUEUE_ENABLED", false),
+			AllocationQueueWait:      getDurationEnv("ORDER_ALLOCATION_QUEUE_WAIT", 2*time.Second),
+			AllocationQueueTicketTTL: getDurationEnv("ORDER_ALLOCATION_QUEUE_TICKET_TTL", 30*time.Second),
+			HoldSLA:                  getDurationEnv("ORDER_HOLD_SLA", 48*time.Hour),
+			ConfirmationInterval:     getDurationEnv("ORDER_CONFIRMATION_INTERVAL", 5*time.Second),
+			NotificationInterval:     getDurationEnv("ORDER_NOTIFICATION_INTERVAL", 5*time.Second),
+			CheckoutRecoveryInterval: getDurationEnv("CHECKOUT_RECOVERY_INTERVAL", time.Minute),
+			CheckoutStaleAfter:       getDurationEnv("CHECKOUT_STALE_AFTER", 5*time.Minute),
+		},
+		Webhooks: WebhooksConfig{
+			DeliveryInterval: getDurationEnv("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second),
+			Timeout:          getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:      getIntEnv("WEBHOOK_MAX_ATTEMPTS", 10),
+			InitialBackoff:   getDurationEnv("WEBHOOK_INITIAL_BACKOFF", 30*time.Second),
+			MaxBackoff:       getDurationEnv("WEBHOOK_MAX_BACKOFF", 6*time.Hour),
+			PartnerSecrets:   partnerWebhookSecrets,
+		},
+		Usage: UsageConfig{
+			FlushInterval:       getDurationEnv("API_USAGE_FLUSH_INTERVAL", 30*time.Second),
+			DefaultMonthlyQuota: getIntEnv("API_KEY_DEFAULT_MONTHLY_QUOTA", 0),
+		},
+		Referrals: ReferralsConfig{
+			RewardAmount:  getFloatEnv("REFERRAL_REWARD_AMOUNT", 10),
+			ReturnWindow:  getDurationEnv("REFERRAL_RETURN_WINDOW", 14*24*time.Hour),
+			SweepInterval: getDurationEnv("REFERRAL_SWEEP_INTERVAL", time.Hour),
+		},
+		Promotions: PromotionsConfig{
+			ShippingFee: getFloatEnv("SHIPPING_FEE", 0),
+		},
+		Reports: ReportsConfig{
+			CacheBackend:       getEnv("REPORT_CACHE_BACKEND", "memory"),
+			CacheKeyPrefix:     getEnv("REPORT_CACHE_KEY_PREFIX", "reports:cache:"),
+			SignedURLThreshold: int64(getIntEnv("REPORT_SIGNED_URL_THRESHOLD", 5*1024*1024)),
+			SignedURLExpiry:    getDurationEnv("REPORT_SIGNED_URL_EXPIRY", 15*time.Minute),
+			CloseTimes:         closeTimes,
+			CloseRecipients:    parseList(getEnv("REPORT_CLOSE_RECIPIENTS", "")),
+			CloseCheckInterval: getDurationEnv("REPORT_CLOSE_CHECK_INTERVAL", time.Minute),
+		},
+		Storage: StorageConfig{
+			Backend:           getEnv("STORAGE_BACKEND", "database"),
+			LocalDir:          getEnv("STORAGE_LOCAL_DIR", "./storage"),
+			S3Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			S3Region:          getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getBoolEnv("S3_PATH_STYLE", false),
+		},
+		Jobs: JobsConfig{
+			PollInterval:      getDurationEnv("JOB_POLL_INTERVAL", time.Second),
+			VisibilityTimeout: getDurationEnv("JOB_VISIBILITY_TIMEOUT", 5*time.Minute),
+		},
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -87,6 +579,45 @@ func (c *Config) validate() error {
 	if c.JWT.Secret == "your-secret-key" {
 		return fmt.Errorf("JWT_SECRET must be set to a secure value")
 	}
+	switch c.Payments.RoutingStrategy {
+	case "ordered", "least_latency":
+	case "weighted":
+		if len(c.Payments.GatewayWeights) == 0 {
+			return fmt.Errorf("PAYMENT_GATEWAY_WEIGHTS must be set for the weighted routing strategy")
+		}
+	default:
+		return fmt.Errorf("PAYMENT_ROUTING_STRATEGY must be ordered, weighted or least_latency, got %q", c.Payments.RoutingStrategy)
+	}
+	if c.Payments.IntentTTL <= 0 {
+		return fmt.Errorf("PAYMENT_INTENT_TTL must be positive, got %s", c.Payments.IntentTTL)
+	}
+	if c.Payments.StripeWebhookTolerance <= 0 {
+		return fmt.Errorf("STRIPE_WEBHOOK_TOLERANCE must be positive, got %s", c.Payments.StripeWebhookTolerance)
+	}
+	if _, ok := c.Payments.WebhookSecrets["stripe"]; ok {
+		return fmt.Errorf("PAYMENT_WEBHOOK_SECRETS cannot list stripe; set STRIPE_WEBHOOK_SECRET instead")
+	}
+	if c.Promotions.ShippingFee < 0 {
+		return fmt.Errorf("SHIPPING_FEE cannot be negative, got %.2f", c.Promotions.ShippingFee)
+	}
+	if c.Reports.CacheBackend != "memory" && c.Reports.CacheBackend != "redis" {
+		return fmt.Errorf("REPORT_CACHE_BACKEND must be memory or redis, got %q", c.Reports.CacheBackend)
+	}
+	if c.Reports.SignedURLExpiry <= 0 || c.Reports.SignedURLExpiry > 7*24*time.Hour {
+		return fmt.Errorf("REPORT_SIGNED_URL_EXPIRY must be between 1s and 168h, got %s", c.Reports.SignedURLExpiry)
+	}
+	if c.Jobs.PollInterval <= 0 || c.Jobs.VisibilityTimeout <= 0 {
+		return fmt.Errorf("JOB_POLL_INTERVAL and JOB_VISIBILITY_TIMEOUT must be positive")
+	}
+	switch c.Storage.Backend {
+	case "database", "local":
+	case "s3":
+		if c.Storage.S3Bucket == "" || c.Storage.S3AccessKeyID == "" || c.Storage.S3SecretAccessKey == "" {
+			return fmt.Errorf("S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set for the s3 storage backend")
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be database, local or s3, got %q", c.Storage.Backend)
+	}
 	return nil
 }
 
@@ -119,3 +650,119 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// parsePercentMap parses "key:percent" pairs separated by commas, e.g. "credit_card:2,bank_transfer:-1"
+func parsePercentMap(value string) (map[string]float64, error) {
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, rawPercent, found := strings.Cut(pair, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("expected key:percent, got %q", pair)
+		}
+
+		percent, err := strconv.ParseFloat(strings.TrimSpace(rawPercent), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percent for %s: %w", key, err)
+		}
+		if percent <= -100 || percent > 100 {
+			return nil, fmt.Errorf("percent for %s must be greater than -100 and at most 100", key)
+		}
+
+		result[strings.TrimSpace(key)] = percent
+	}
+	return result, nil
+}
+
+// parsePairMap parses "key:value" pairs separated by commas, e.g. "EUR:paypal,GBP:square"
+func parsePairMap(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, setting, found := strings.Cut(pair, ":")
+		if !found || strings.TrimSpace(key) == "" || strings.TrimSpace(setting) == "" {
+			return nil, fmt.Errorf("expected key:value, got %q", pair)
+		}
+
+		result[strings.TrimSpace(key)] = strings.TrimSpace(setting)
+	}
+	return result, nil
+}
+
+// parseWeightMap parses "key:weight" pairs separated by commas, e.g. "stripe:70,paypal:30"
+func parseWeightMap(value string) (map[string]int, error) {
+	pairs, err := parsePairMap(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int, len(pairs))
+	for key, rawWeight := range pairs {
+		weight, err := strconv.Atoi(rawWeight)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %w", key, err)
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("weight for %s must not be negative", key)
+		}
+		result[key] = weight
+	}
+	return result, nil
+}
+
+// parseStoreMap parses "store=value" pairs separated by semicolons, e.g.
+// "*=mon-fri 09:00-17:00;shopify=mon-sun 00:00-24:00"
+func parseStoreMap(value string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, setting, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(key) == "" || strings.TrimSpace(setting) == "" {
+			return nil, fmt.Errorf("expected store=value, got %q", pair)
+		}
+
+		result[strings.TrimSpace(key)] = strings.TrimSpace(setting)
+	}
+	return result, nil
+}
+
+// parseList splits a comma-separated value, dropping blank entries
+func parseList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
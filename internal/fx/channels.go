@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/pkg/channels"
+	"easy-orders-backend/pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+// ChannelsModule provides marketplace channel connector dependencies
+var ChannelsModule = fx.Module("channels",
+	fx.Provide(
+		channels.NewChannelManager,
+	),
+
+	// Decorate the channel manager to register the configured connectors
+	fx.Decorate(func(channelManager *channels.ChannelManager, cfg *config.Config, logger *logger.Logger) *channels.ChannelManager {
+		channelManager.RegisterConnector(channels.NewMockConnector(logger))
+
+		if cfg.Channels.ShopifyShopDomain != "" && cfg.Channels.ShopifyAccessToken != "" {
+			channelManager.RegisterConnector(channels.NewShopifyConnector(&channels.ShopifyConfig{
+				ShopDomain:  cfg.Channels.ShopifyShopDomain,
+				AccessToken: cfg.Channels.ShopifyAccessToken,
+				LocationID:  int64(cfg.Channels.ShopifyLocationID),
+			}, logger))
+		}
+
+		logger.Info("Channel connectors registered", "channels", channelManager.ListChannels())
+		return channelManager
+	}),
+)
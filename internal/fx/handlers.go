@@ -15,5 +15,33 @@ var HandlersModule = fx.Module("handlers",
 		handlers.NewPaymentHandler,
 		handlers.NewInventoryHandler,
 		handlers.NewAdminHandler,
+		handlers.NewWebhookHandler,
+		handlers.NewChannelHandler,
+		handlers.NewNotificationHandler,
+		handlers.NewCartHandler,
+		handlers.NewOrganizationHandler,
+		handlers.NewCheckoutHandler,
+		handlers.NewChangeFeedHandler,
+		handlers.NewRetentionHandler,
+		handlers.NewLedgerHandler,
+		handlers.NewManualPaymentHandler,
+		handlers.NewOrderHoldHandler,
+		handlers.NewEventWebhookHandler,
+		handlers.NewAPIUsageHandler,
+		handlers.NewReferralHandler,
+		handlers.NewBlocklistHandler,
+		handlers.NewPriceListHandler,
+		handlers.NewPromotionHandler,
+		handlers.NewDeliverySlotHandler,
+		handlers.NewPaymentRoutingHandler,
+		handlers.NewFulfillmentHandler,
+		handlers.NewDeadLetterHandler,
+		handlers.NewWorkerPoolHandler,
+		handlers.NewSupportSearchHandler,
+		handlers.NewPaymentIntentHandler,
+		handlers.NewPhoneOrderHandler,
+		handlers.NewProductSerialHandler,
+		handlers.NewWarrantyClaimHandler,
+		handlers.NewNotificationExperimentHandler,
 	),
 )
@@ -20,9 +20,15 @@ var MiddlewareModule = fx.Module("middleware",
 		// Error Middleware
 		middleware2.NewErrorMiddleware,
 
-		// Auth Middleware
+		// Locale Middleware
+		middleware2.NewLocaleMiddleware,
+
+		// Auth Middleware, accepting tokens and API keys
 		middleware2.NewAuthMiddleware,
 
+		// API usage tracking Middleware
+		middleware2.NewUsageMiddleware,
+
 		// CORS Middleware
 		middleware2.NewCORSMiddleware,
 
@@ -6,6 +6,7 @@ import (
 
 	"easy-orders-backend/internal/config"
 	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/logger"
 
 	"go.uber.org/fx"
@@ -34,6 +35,11 @@ var LoggerModule = fx.Module("logger",
 	}),
 )
 
+// I18nModule provides message catalogs for localized errors and notifications
+var I18nModule = fx.Module("i18n",
+	fx.Provide(i18n.NewTranslator),
+)
+
 // DatabaseModule provides database connection
 var DatabaseModule = fx.Module("database",
 	fx.Provide(
@@ -71,6 +77,7 @@ var DatabaseModule = fx.Module("database",
 var CoreModules = fx.Options(
 	ConfigModule,
 	LoggerModule,
+	I18nModule,
 	DatabaseModule,
 )
 
@@ -83,5 +90,6 @@ var ApplicationModules = fx.Options(
 	WorkersModule,
 	NotificationsModule,
 	PaymentsModule,
+	ChannelsModule,
 	ReportsModule,
 )
@@ -1,6 +1,9 @@
 package fx
 
 import (
+	"context"
+
+	"easy-orders-backend/internal/services"
 	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/pkg/notifications"
 
@@ -19,10 +22,45 @@ var NotificationsModule = fx.Module("notifications",
 			}
 			return notifications.NewNotificationDispatcher(config, provider, logger)
 		},
+		// Digests are delivered through the notification service
+		notifications.NewDigestScheduler,
+		fx.Annotate(
+			services.NewNotificationDigestSender,
+			fx.As(new(notifications.DigestSink)),
+		),
+		func(digestScheduler *notifications.DigestScheduler) services.NotificationDigester {
+			return digestScheduler
+		},
 
 		// Default dispatcher config
 		func() *notifications.DispatcherConfig {
 			return notifications.DefaultDispatcherConfig()
 		},
+
+		// Default digest config
+		func() *notifications.DigestConfig {
+			return notifications.DefaultDigestConfig()
+		},
 	),
+
+	// Lifecycle hooks
+	fx.Invoke(func(lc fx.Lifecycle, dispatcher *notifications.NotificationDispatcher, digestScheduler *notifications.DigestScheduler, logger *logger.Logger) {
+		// The start context is cancelled once startup completes, so background
+		// workers get their own context and are stopped explicitly on shutdown
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := dispatcher.Start(context.Background()); err != nil {
+					return err
+				}
+				return digestScheduler.Start(context.Background())
+			},
+			OnStop: func(ctx context.Context) error {
+				// Flush pending digests while the notification service can still deliver them
+				if err := digestScheduler.Stop(); err != nil {
+					logger.Warn("Failed to stop digest scheduler", "error", err)
+				}
+				return dispatcher.Stop()
+			},
+		})
+	}),
 )
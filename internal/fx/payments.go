@@ -4,6 +4,9 @@ import (
 	"context"
 	"time"
 
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
 	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/pkg/payments"
 
@@ -13,32 +16,23 @@ import (
 // PaymentsModule provides payment processing infrastructure dependencies
 var PaymentsModule = fx.Module("payments",
 	fx.Provide(
-		// Payment gateway manager
-		payments.NewPaymentGatewayManager,
+		// Payment gateway manager with the gateways registered
+		newPaymentGatewayManager,
 
-		// Idempotency manager
-		func(logger *logger.Logger) *payments.IdempotencyManager {
-			return payments.NewIdempotencyManager(24*time.Hour, logger) // 24 hour TTL
+		// Idempotency manager keeping its records in the database
+		func(records repository.IdempotencyRepository, logger *logger.Logger) *payments.IdempotencyManager {
+			return payments.NewIdempotencyManager(24*time.Hour, records, logger) // 24 hour TTL
 		},
 
 		// Circuit breaker manager
 		payments.NewCircuitBreakerManager,
-	),
-
-	// Decorate the gateway manager to register mock gateways
-	fx.Decorate(func(gatewayManager *payments.PaymentGatewayManager, logger *logger.Logger) *payments.PaymentGatewayManager {
-		// Register mock gateways for testing
-		mockStripe := payments.NewMockPaymentGateway(payments.GatewayTypeStripe, 0.05, 500*time.Millisecond, logger)
-		mockPayPal := payments.NewMockPaymentGateway(payments.GatewayTypePayPal, 0.03, 300*time.Millisecond, logger)
-		mockSquare := payments.NewMockPaymentGateway(payments.GatewayTypeSquare, 0.04, 400*time.Millisecond, logger)
 
-		gatewayManager.RegisterGateway(mockStripe)
-		gatewayManager.RegisterGateway(mockPayPal)
-		gatewayManager.RegisterGateway(mockSquare)
+		// Payment processor with retries and gateway failover
+		payments.NewPaymentProcessor,
 
-		logger.Info("Payment gateways registered", "count", 3)
-		return gatewayManager
-	}),
+		// Signature verifiers of gateway payment webhooks
+		newPaymentWebhookVerifiers,
+	),
 
 	// Lifecycle hooks
 	fx.Invoke(func(lc fx.Lifecycle, idempotencyManager *payments.IdempotencyManager, logger *logger.Logger) {
@@ -55,3 +49,71 @@ var PaymentsModule = fx.Module("payments",
 		})
 	}),
 )
+
+// newPaymentGatewayManager registers the gateways and routes payments per the
+// configured strategy; Stripe is real once it has a secret key, the others are mocks
+// for testing. They are registered when the manager is built, as decorating it would
+// only apply within this module and not to the payment services using it.
+func newPaymentGatewayManager(cfg *config.Config, logger *logger.Logger) *payments.PaymentGatewayManager {
+	gatewayManager := payments.NewPaymentGatewayManager(logger)
+
+	var stripe payments.PaymentGateway = payments.NewMockPaymentGateway(payments.GatewayTypeStripe, 0.05, 500*time.Millisecond, logger)
+	if cfg.Payments.StripeSecretKey != "" {
+		stripe = payments.NewStripeGateway(&payments.StripeConfig{
+			SecretKey:  cfg.Payments.StripeSecretKey,
+			APIVersion: cfg.Payments.StripeAPIVersion,
+		}, logger)
+		logger.Info("Stripe gateway configured")
+	}
+	mockPayPal := payments.NewMockPaymentGateway(payments.GatewayTypePayPal, 0.03, 300*time.Millisecond, logger)
+	mockSquare := payments.NewMockPaymentGateway(payments.GatewayTypeSquare, 0.04, 400*time.Millisecond, logger)
+
+	gatewayManager.RegisterGateway(stripe)
+	gatewayManager.RegisterGateway(mockPayPal)
+	gatewayManager.RegisterGateway(mockSquare)
+	gatewayManager.SetSelector(newGatewaySelector(cfg.Payments))
+
+	logger.Info("Payment gateways registered", "count", 3, "routing", cfg.Payments.RoutingStrategy)
+	return gatewayManager
+}
+
+// newPaymentWebhookVerifiers builds a verifier for every gateway with a webhook
+// secret configured; callbacks from other gateways are not accepted
+func newPaymentWebhookVerifiers(cfg *config.Config) services.PaymentWebhookVerifiers {
+	verifiers := make(services.PaymentWebhookVerifiers, len(cfg.Payments.WebhookSecrets)+1)
+	for gateway, secret := range cfg.Payments.WebhookSecrets {
+		verifiers[gateway] = payments.NewHMACWebhookVerifier(secret)
+	}
+	if cfg.Payments.StripeWebhookSecret != "" {
+		verifiers[string(payments.GatewayTypeStripe)] = payments.NewStripeWebhookVerifier(
+			cfg.Payments.StripeWebhookSecret, cfg.Payments.StripeWebhookTolerance)
+	}
+	return verifiers
+}
+
+// newGatewaySelector builds the configured gateway routing strategy, pinning
+// currencies to gateways first when any are configured
+func newGatewaySelector(cfg config.PaymentsConfig) payments.GatewaySelector {
+	var selector payments.GatewaySelector
+	switch cfg.RoutingStrategy {
+	case payments.RoutingStrategyWeighted:
+		weights := make(map[payments.PaymentGatewayType]int, len(cfg.GatewayWeights))
+		for gateway, weight := range cfg.GatewayWeights {
+			weights[payments.PaymentGatewayType(gateway)] = weight
+		}
+		selector = payments.NewWeightedSelector(weights)
+	case payments.RoutingStrategyLeastLatency:
+		selector = payments.NewLeastLatencySelector()
+	default:
+		selector = payments.NewOrderedSelector()
+	}
+
+	if len(cfg.CurrencyGateways) == 0 {
+		return selector
+	}
+	affinity := make(map[string]payments.PaymentGatewayType, len(cfg.CurrencyGateways))
+	for currency, gateway := range cfg.CurrencyGateways {
+		affinity[currency] = payments.PaymentGatewayType(gateway)
+	}
+	return payments.NewCurrencyAffinitySelector(affinity, selector)
+}
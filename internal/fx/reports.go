@@ -2,24 +2,105 @@ package fx
 
 import (
 	"context"
+	"net"
+	"time"
+
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/pkg/storage"
 
 	"go.uber.org/fx"
 )
 
-// ReportsModule provides report system lifecycle hooks
+// ReportsModule provides the report manager generating reports in the background
 var ReportsModule = fx.Module("reports",
+	fx.Provide(
+		fx.Annotate(
+			repository.NewReportResultRepository,
+			fx.As(new(repository.ReportResultRepository)),
+		),
+		NewReportManager,
+		NewStorage,
+	),
+
 	// Lifecycle hooks
-	fx.Invoke(func(lc fx.Lifecycle, logger *logger.Logger) {
+	fx.Invoke(func(lc fx.Lifecycle, results repository.ReportResultRepository, manager *reports.ReportManager, logger *logger.Logger) {
 		lc.Append(fx.Hook{
 			OnStart: func(ctx context.Context) error {
+				// Reports still queued or generating were lost with the previous process
+				if _, err := results.FailUnfinished(ctx, time.Now(), "interrupted by a restart"); err != nil {
+					logger.Warn("Failed to fail unfinished report results", "error", err)
+				}
 				logger.Info("Enhanced report system initialized")
 				return nil
 			},
 			OnStop: func(ctx context.Context) error {
 				logger.Info("Enhanced report system shutting down")
-				return nil
+				return manager.Close()
 			},
 		})
 	}),
 )
+
+// NewStorage provides the configured file storage, or nil when files are kept in the
+// database
+func NewStorage(cfg *config.Config, logger *logger.Logger) (storage.Storage, error) {
+	switch cfg.Storage.Backend {
+	case "local":
+		logger.Info("Storing files locally", "dir", cfg.Storage.LocalDir)
+		return storage.NewLocalStorage(cfg.Storage.LocalDir)
+	case "s3":
+		logger.Info("Storing files in S3", "endpoint", cfg.Storage.S3Endpoint, "bucket", cfg.Storage.S3Bucket)
+		return storage.NewS3Storage(&storage.S3Config{
+			Endpoint:        cfg.Storage.S3Endpoint,
+			Region:          cfg.Storage.S3Region,
+			Bucket:          cfg.Storage.S3Bucket,
+			AccessKeyID:     cfg.Storage.S3AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3SecretAccessKey,
+			PathStyle:       cfg.Storage.S3PathStyle,
+		}), nil
+	default:
+		return nil, nil
+	}
+}
+
+// NewReportManager provides the report manager with the sales, inventory, customer and
+// payment reconciliation report generators, storing async results so they can be
+// polled, caching reports in the configured backend and keeping their files in the
+// configured storage
+func NewReportManager(
+	cfg *config.Config,
+	fileStorage storage.Storage,
+	results repository.ReportResultRepository,
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.PaymentRepository,
+	userRepo repository.UserRepository,
+	productRepo repository.ProductRepository,
+	inventoryRepo repository.InventoryRepository,
+	reconciliationRepo repository.PaymentReconciliationRepository,
+	logger *logger.Logger,
+) *reports.ReportManager {
+	managerConfig := reports.DefaultReportManagerConfig()
+	managerConfig.CacheBackend = cfg.Reports.CacheBackend
+	managerConfig.Storage = fileStorage
+	managerConfig.SignedURLThreshold = cfg.Reports.SignedURLThreshold
+	managerConfig.SignedURLExpiry = cfg.Reports.SignedURLExpiry
+	if cfg.Reports.CacheBackend == reports.CacheBackendRedis {
+		managerConfig.Redis = &reports.RedisCacheConfig{
+			Addr:      net.JoinHostPort(cfg.Redis.Host, cfg.Redis.Port),
+			Password:  cfg.Redis.Password,
+			DB:        cfg.Redis.DB,
+			KeyPrefix: cfg.Reports.CacheKeyPrefix,
+		}
+		logger.Info("Caching reports in Redis", "addr", managerConfig.Redis.Addr, "db", cfg.Redis.DB)
+	}
+
+	manager := reports.NewReportManager(managerConfig, results, logger)
+	manager.RegisterGenerator(reports.NewSalesReportGenerator(orderRepo, paymentRepo, userRepo, productRepo, logger))
+	manager.RegisterGenerator(reports.NewInventoryReportGenerator(inventoryRepo, productRepo, orderRepo, logger))
+	manager.RegisterGenerator(reports.NewCustomerReportGenerator(userRepo, orderRepo, paymentRepo, logger))
+	manager.RegisterGenerator(reports.NewReconciliationReportGenerator(reconciliationRepo, logger))
+	return manager
+}
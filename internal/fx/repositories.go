@@ -1,7 +1,11 @@
 package fx
 
 import (
+	"easy-orders-backend/internal/config"
 	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
 
 	"go.uber.org/fx"
 )
@@ -15,11 +19,11 @@ var RepositoriesModule = fx.Module("repositories",
 			fx.As(new(repository.UserRepository)),
 		),
 
+		// Query cache shared by the cached repositories
+		NewQueryCache,
+
 		// Product repository
-		fx.Annotate(
-			repository.NewProductRepository,
-			fx.As(new(repository.ProductRepository)),
-		),
+		NewProductRepository,
 
 		// Order repository
 		fx.Annotate(
@@ -34,10 +38,7 @@ var RepositoriesModule = fx.Module("repositories",
 		),
 
 		// Inventory repository
-		fx.Annotate(
-			repository.NewInventoryRepository,
-			fx.As(new(repository.InventoryRepository)),
-		),
+		NewInventoryRepository,
 
 		// Payment repository
 		fx.Annotate(
@@ -56,5 +57,274 @@ var RepositoriesModule = fx.Module("repositories",
 			repository.NewAuditLogRepository,
 			fx.As(new(repository.AuditLogRepository)),
 		),
+
+		// Webhook event repository
+		fx.Annotate(
+			repository.NewWebhookEventRepository,
+			fx.As(new(repository.WebhookEventRepository)),
+		),
+
+		// Stock webhook subscription repository
+		fx.Annotate(
+			repository.NewStockWebhookSubscriptionRepository,
+			fx.As(new(repository.StockWebhookSubscriptionRepository)),
+		),
+
+		// Outbound webhook endpoint and delivery log repositories
+		fx.Annotate(
+			repository.NewWebhookEndpointRepository,
+			fx.As(new(repository.WebhookEndpointRepository)),
+		),
+		fx.Annotate(
+			repository.NewWebhookDeliveryRepository,
+			fx.As(new(repository.WebhookDeliveryRepository)),
+		),
+
+		// Channel listing repository
+		fx.Annotate(
+			repository.NewChannelListingRepository,
+			fx.As(new(repository.ChannelListingRepository)),
+		),
+
+		// Saved address repository
+		fx.Annotate(
+			repository.NewAddressRepository,
+			fx.As(new(repository.AddressRepository)),
+		),
+
+		// Saved payment method repository
+		fx.Annotate(
+			repository.NewSavedPaymentMethodRepository,
+			fx.As(new(repository.SavedPaymentMethodRepository)),
+		),
+
+		// Activity event repository
+		fx.Annotate(
+			repository.NewActivityEventRepository,
+			fx.As(new(repository.ActivityEventRepository)),
+		),
+
+		// Inventory change event repository
+		fx.Annotate(
+			repository.NewInventoryEventRepository,
+			fx.As(new(repository.InventoryEventRepository)),
+		),
+
+		// Order lifecycle event repository
+		fx.Annotate(
+			repository.NewOrderEventRepository,
+			fx.As(new(repository.OrderEventRepository)),
+		),
+
+		// Catalog change event repository
+		fx.Annotate(
+			repository.NewCatalogEventRepository,
+			fx.As(new(repository.CatalogEventRepository)),
+		),
+
+		// Cart stock hold repository
+		fx.Annotate(
+			repository.NewStockHoldRepository,
+			fx.As(new(repository.StockHoldRepository)),
+		),
+
+		// Payment attempt repository
+		fx.Annotate(
+			repository.NewPaymentAttemptRepository,
+			fx.As(new(repository.PaymentAttemptRepository)),
+		),
+
+		// Storefront availability read model repository
+		fx.Annotate(
+			repository.NewProductAvailabilityRepository,
+			fx.As(new(repository.ProductAvailabilityRepository)),
+		),
+
+		// B2B organization repository
+		fx.Annotate(
+			repository.NewOrganizationRepository,
+			fx.As(new(repository.OrganizationRepository)),
+		),
+
+		// Double-entry payments ledger repository
+		fx.Annotate(
+			repository.NewLedgerRepository,
+			fx.As(new(repository.LedgerRepository)),
+		),
+
+		// Order compliance hold repository
+		fx.Annotate(
+			repository.NewOrderHoldRepository,
+			fx.As(new(repository.OrderHoldRepository)),
+		),
+
+		// API key and API usage repositories
+		fx.Annotate(
+			repository.NewAPIKeyRepository,
+			fx.As(new(repository.APIKeyRepository)),
+		),
+		fx.Annotate(
+			repository.NewAPIUsageRepository,
+			fx.As(new(repository.APIUsageRepository)),
+		),
+
+		// Referral and store credit repositories
+		fx.Annotate(
+			repository.NewReferralRepository,
+			fx.As(new(repository.ReferralRepository)),
+		),
+		fx.Annotate(
+			repository.NewStoreCreditRepository,
+			fx.As(new(repository.StoreCreditRepository)),
+		),
+
+		// Fraud blocklist repository
+		fx.Annotate(
+			repository.NewBlocklistRepository,
+			fx.As(new(repository.BlocklistRepository)),
+		),
+
+		// B2B price list repository
+		fx.Annotate(
+			repository.NewPriceListRepository,
+			fx.As(new(repository.PriceListRepository)),
+		),
+
+		// Checkout promotion repository
+		fx.Annotate(
+			repository.NewPromotionRepository,
+			fx.As(new(repository.PromotionRepository)),
+		),
+
+		// Delivery slot repository
+		fx.Annotate(
+			repository.NewDeliverySlotRepository,
+			fx.As(new(repository.DeliverySlotRepository)),
+		),
+
+		// Payment refund repository
+		fx.Annotate(
+			repository.NewRefundRepository,
+			fx.As(new(repository.RefundRepository)),
+		),
+
+		// Payment idempotency record repository
+		fx.Annotate(
+			repository.NewIdempotencyRepository,
+			fx.As(new(repository.IdempotencyRepository)),
+		),
+
+		// Payments checked against their gateway by reconciliation runs
+		fx.Annotate(
+			repository.NewPaymentReconciliationRepository,
+			fx.As(new(repository.PaymentReconciliationRepository)),
+		),
+
+		// Payment routing rule repository
+		fx.Annotate(
+			repository.NewPaymentRoutingRuleRepository,
+			fx.As(new(repository.PaymentRoutingRuleRepository)),
+		),
+
+		// Order confirmation and fulfillment queue repository
+		fx.Annotate(
+			repository.NewFulfillmentRepository,
+			fx.As(new(repository.FulfillmentRepository)),
+		),
+
+		// Background job repository, the durable queue of the worker pools
+		fx.Annotate(
+			repository.NewBackgroundJobRepository,
+			fx.As(new(repository.BackgroundJobRepository)),
+		),
+
+		// Close report repository, the end-of-day reports sent per store and day
+		fx.Annotate(
+			repository.NewCloseReportRepository,
+			fx.As(new(repository.CloseReportRepository)),
+		),
+
+		// Support search repository, the lookups behind the support search
+		fx.Annotate(
+			repository.NewSupportSearchRepository,
+			fx.As(new(repository.SupportSearchRepository)),
+		),
+
+		// Checkout saga repository, the progress of express checkouts through their steps
+		fx.Annotate(
+			repository.NewCheckoutSagaRepository,
+			fx.As(new(repository.CheckoutSagaRepository)),
+		),
+
+		// Inventory shard repository, the reservation rows of hot products' stock
+		fx.Annotate(
+			repository.NewInventoryShardRepository,
+			fx.As(new(repository.InventoryShardRepository)),
+		),
+
+		// Stock import repository, CSVs of stock adjustments and their rows
+		fx.Annotate(
+			repository.NewStockImportRepository,
+			fx.As(new(repository.StockImportRepository)),
+		),
+
+		// Payment intent repository, customers' card payments awaiting confirmation
+		fx.Annotate(
+			repository.NewPaymentIntentRepository,
+			fx.As(new(repository.PaymentIntentRepository)),
+		),
+
+		// Product serial repository, serialized products' units from lot to order item
+		fx.Annotate(
+			repository.NewProductSerialRepository,
+			fx.As(new(repository.ProductSerialRepository)),
+		),
+
+		// Warranty claim repository, claims on serialized units and their resolutions
+		fx.Annotate(
+			repository.NewWarrantyClaimRepository,
+			fx.As(new(repository.WarrantyClaimRepository)),
+		),
+
+		// Notification experiment repository, A/B tests of notification wording
+		fx.Annotate(
+			repository.NewNotificationExperimentRepository,
+			fx.As(new(repository.NotificationExperimentRepository)),
+		),
 	),
+	fx.Invoke(RegisterCacheInvalidation),
 )
+
+// NewQueryCache creates the repository query cache
+func NewQueryCache(cfg *config.Config) *cache.QueryCache {
+	return cache.NewQueryCache(cfg.Cache.TTL, cfg.Cache.MaxEntries)
+}
+
+// NewProductRepository provides the product repository, cached when enabled in config
+func NewProductRepository(cfg *config.Config, db *database.DB, queryCache *cache.QueryCache, logger *logger.Logger) repository.ProductRepository {
+	repo := repository.NewProductRepository(db, logger)
+	if !cfg.Cache.Products {
+		return repo
+	}
+	logger.Info("Product repository query cache enabled", "ttl", cfg.Cache.TTL)
+	return repository.NewCachedProductRepository(repo, queryCache, logger)
+}
+
+// NewInventoryRepository provides the inventory repository, cached when enabled in config
+func NewInventoryRepository(cfg *config.Config, db *database.DB, queryCache *cache.QueryCache, logger *logger.Logger) repository.InventoryRepository {
+	repo := repository.NewInventoryRepository(db, logger)
+	if !cfg.Cache.Inventory {
+		return repo
+	}
+	logger.Info("Inventory repository query cache enabled", "ttl", cfg.Cache.TTL)
+	return repository.NewCachedInventoryRepository(repo, queryCache, logger)
+}
+
+// RegisterCacheInvalidation invalidates cached products and inventory on
+// writes that bypass the cached repositories
+func RegisterCacheInvalidation(cfg *config.Config, db *database.DB, queryCache *cache.QueryCache) error {
+	if !cfg.Cache.Products && !cfg.Cache.Inventory {
+		return nil
+	}
+	return repository.RegisterCacheInvalidationHooks(db, queryCache)
+}
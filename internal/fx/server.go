@@ -32,7 +32,9 @@ func NewGinEngine(
 	cfg *config.Config,
 	logger *logger.Logger,
 	errorMiddleware *middleware2.ErrorMiddleware,
+	localeMiddleware *middleware2.LocaleMiddleware,
 	authMiddleware *middleware2.AuthMiddleware,
+	usageMiddleware *middleware2.UsageMiddleware,
 	corsMiddleware *middleware2.CORSMiddleware,
 	rateLimiter *middleware2.RateLimiter,
 	validationMiddleware *middleware2.ValidationMiddleware,
@@ -42,6 +44,34 @@ func NewGinEngine(
 	paymentHandler *handlers.PaymentHandler,
 	inventoryHandler *handlers.InventoryHandler,
 	adminHandler *handlers.AdminHandler,
+	webhookHandler *handlers.WebhookHandler,
+	channelHandler *handlers.ChannelHandler,
+	notificationHandler *handlers.NotificationHandler,
+	cartHandler *handlers.CartHandler,
+	organizationHandler *handlers.OrganizationHandler,
+	checkoutHandler *handlers.CheckoutHandler,
+	changeFeedHandler *handlers.ChangeFeedHandler,
+	retentionHandler *handlers.RetentionHandler,
+	ledgerHandler *handlers.LedgerHandler,
+	manualPaymentHandler *handlers.ManualPaymentHandler,
+	orderHoldHandler *handlers.OrderHoldHandler,
+	eventWebhookHandler *handlers.EventWebhookHandler,
+	apiUsageHandler *handlers.APIUsageHandler,
+	referralHandler *handlers.ReferralHandler,
+	blocklistHandler *handlers.BlocklistHandler,
+	priceListHandler *handlers.PriceListHandler,
+	promotionHandler *handlers.PromotionHandler,
+	deliverySlotHandler *handlers.DeliverySlotHandler,
+	paymentRoutingHandler *handlers.PaymentRoutingHandler,
+	fulfillmentHandler *handlers.FulfillmentHandler,
+	deadLetterHandler *handlers.DeadLetterHandler,
+	workerPoolHandler *handlers.WorkerPoolHandler,
+	supportSearchHandler *handlers.SupportSearchHandler,
+	paymentIntentHandler *handlers.PaymentIntentHandler,
+	phoneOrderHandler *handlers.PhoneOrderHandler,
+	productSerialHandler *handlers.ProductSerialHandler,
+	warrantyClaimHandler *handlers.WarrantyClaimHandler,
+	notificationExperimentHandler *handlers.NotificationExperimentHandler,
 ) *gin.Engine {
 	// Set gin mode based on the environment
 	if cfg.Server.Environment == "production" {
@@ -53,10 +83,12 @@ func NewGinEngine(
 	engine := gin.New()
 
 	// Add core middleware in order
-	engine.Use(gin.Recovery())            // Panic recovery
-	engine.Use(corsMiddleware.Handler())  // CORS handling
-	engine.Use(rateLimiter.Limit())       // Rate limiting
-	engine.Use(errorMiddleware.Handler()) // Centralized error handling
+	engine.Use(gin.Recovery())             // Panic recovery
+	engine.Use(corsMiddleware.Handler())   // CORS handling
+	engine.Use(usageMiddleware.Track())    // API usage analytics
+	engine.Use(rateLimiter.Limit())        // Rate limiting
+	engine.Use(localeMiddleware.Handler()) // Accept-Language negotiation
+	engine.Use(errorMiddleware.Handler())  // Centralized error handling
 
 	// Add basic request logging
 	engine.Use(func(c *gin.Context) {
@@ -89,14 +121,26 @@ func NewGinEngine(
 	{
 		// Public routes (no authentication required)
 		routes.RegisterUserRoutes(v1, userHandler, validationMiddleware) // Includes auth endpoint
+		routes.RegisterWebhookRoutes(v1, webhookHandler, validationMiddleware)
 
 		// Protected routes (require authentication)
 		protected := v1.Group("")
 		protected.Use(authMiddleware.RequireAuth())
 		{
 			routes.RegisterProductRoutes(protected, productHandler, inventoryHandler, authMiddleware, validationMiddleware)
+			routes.RegisterInventoryRoutes(protected, inventoryHandler, authMiddleware, validationMiddleware)
 			routes.RegisterOrderRoutes(protected, orderHandler, validationMiddleware)
 			routes.RegisterPaymentRoutes(protected, paymentHandler, validationMiddleware)
+			routes.RegisterPaymentIntentRoutes(protected, paymentIntentHandler, validationMiddleware)
+			routes.RegisterCartRoutes(protected, cartHandler, validationMiddleware)
+			routes.RegisterOrganizationRoutes(protected, organizationHandler, validationMiddleware)
+			routes.RegisterCheckoutRoutes(protected, checkoutHandler, validationMiddleware)
+			routes.RegisterUserNotificationRoutes(protected, notificationHandler, validationMiddleware)
+			routes.RegisterAPIKeyRoutes(protected, apiUsageHandler, validationMiddleware)
+			routes.RegisterReferralRoutes(protected, referralHandler, validationMiddleware)
+			routes.RegisterDeliverySlotRoutes(protected, deliverySlotHandler, validationMiddleware)
+			routes.RegisterWarrantyClaimRoutes(protected, warrantyClaimHandler, validationMiddleware)
+			routes.RegisterCatalogChangeFeedRoutes(protected, changeFeedHandler, validationMiddleware)
 		}
 
 		// Admin routes (require admin role)
@@ -105,6 +149,40 @@ func NewGinEngine(
 		admin.Use(authMiddleware.RequireAdmin())
 		{
 			routes.RegisterAdminRoutes(admin, adminHandler, inventoryHandler, validationMiddleware)
+			routes.RegisterAdminWebhookRoutes(admin, webhookHandler, validationMiddleware)
+			routes.RegisterChannelRoutes(admin, channelHandler, validationMiddleware)
+			routes.RegisterNotificationRoutes(admin, notificationHandler, validationMiddleware)
+			routes.RegisterAdminCartRoutes(admin, cartHandler, validationMiddleware)
+			routes.RegisterAdminOrganizationRoutes(admin, organizationHandler, validationMiddleware)
+			routes.RegisterChangeFeedRoutes(admin, changeFeedHandler, validationMiddleware)
+			routes.RegisterRetentionRoutes(admin, retentionHandler, validationMiddleware)
+			routes.RegisterLedgerRoutes(admin, ledgerHandler, validationMiddleware)
+			routes.RegisterManualPaymentRoutes(admin, manualPaymentHandler, validationMiddleware)
+			routes.RegisterPaymentRefundRoutes(admin, paymentHandler, validationMiddleware)
+			routes.RegisterOrderHoldRoutes(admin, orderHoldHandler, validationMiddleware)
+			routes.RegisterEventWebhookRoutes(admin, eventWebhookHandler, validationMiddleware)
+			routes.RegisterAdminAPIUsageRoutes(admin, apiUsageHandler, validationMiddleware)
+			routes.RegisterAdminReferralRoutes(admin, referralHandler, validationMiddleware)
+			routes.RegisterBlocklistRoutes(admin, blocklistHandler, validationMiddleware)
+			routes.RegisterPriceListRoutes(admin, priceListHandler, validationMiddleware)
+			routes.RegisterPromotionRoutes(admin, promotionHandler, validationMiddleware)
+			routes.RegisterDeliverySlotAdminRoutes(admin, deliverySlotHandler, validationMiddleware)
+			routes.RegisterPaymentRoutingRoutes(admin, paymentRoutingHandler, validationMiddleware)
+			routes.RegisterFulfillmentRoutes(admin, fulfillmentHandler, validationMiddleware)
+			routes.RegisterDeadLetterRoutes(admin, deadLetterHandler, validationMiddleware)
+			routes.RegisterWorkerPoolRoutes(admin, workerPoolHandler, validationMiddleware)
+			routes.RegisterPhoneOrderRoutes(admin, phoneOrderHandler, validationMiddleware)
+			routes.RegisterProductSerialRoutes(admin, productSerialHandler, validationMiddleware)
+			routes.RegisterAdminWarrantyClaimRoutes(admin, warrantyClaimHandler, validationMiddleware)
+			routes.RegisterNotificationExperimentRoutes(admin, notificationExperimentHandler, validationMiddleware)
+		}
+
+		// Support routes (require support or admin role)
+		support := v1.Group("")
+		support.Use(authMiddleware.RequireAuth())
+		support.Use(authMiddleware.RequireSupportOrAdmin())
+		{
+			routes.RegisterSupportSearchRoutes(support, supportSearchHandler, validationMiddleware)
 		}
 
 		// Health check under an API version
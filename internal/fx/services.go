@@ -1,7 +1,17 @@
 package fx
 
 import (
+	"context"
+	"time"
+
+	middleware2 "easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/pkg/webhooks"
 
 	"go.uber.org/fx"
 )
@@ -9,6 +19,51 @@ import (
 // ServicesModule provides all business logic services
 var ServicesModule = fx.Module("services",
 	fx.Provide(
+		// Activity recorder, notified by user, order and payment services
+		NewActivityRecorder,
+
+		// Checkout payment method surcharges and discounts
+		NewPaymentMethodAdjustments,
+
+		// Store hours and same-day shipping cutoffs, and the stores offering gift options,
+		// by order channel
+		NewStoreSchedules,
+		NewGiftOptions,
+
+		// Latency and outcome metrics of order placement and payment stages
+		NewStageRecorder,
+
+		// Fraud blocklist, screened in the order placement and payment fraud screening stage
+		services.NewFraudScreener,
+
+		// Order fraud risk scoring, when orders are placed and on demand by admins
+		services.NewRiskScorer,
+		fx.Annotate(
+			services.NewBlocklistService,
+			fx.As(new(services.BlocklistService)),
+		),
+
+		// B2B price lists, resolved at checkout
+		services.NewPriceListResolver,
+		fx.Annotate(
+			services.NewPriceListService,
+			fx.As(new(services.PriceListService)),
+		),
+
+		// Checkout promotions, applied at checkout
+		NewPromotionSettings,
+		services.NewPromotionEngine,
+		fx.Annotate(
+			services.NewPromotionService,
+			fx.As(new(services.PromotionService)),
+		),
+
+		// Delivery slots, booked at checkout
+		fx.Annotate(
+			services.NewDeliverySlotService,
+			fx.As(new(services.DeliverySlotService)),
+		),
+
 		// User service
 		fx.Annotate(
 			services.NewUserService,
@@ -22,33 +77,1179 @@ var ServicesModule = fx.Module("services",
 		),
 
 		// Inventory service
+		NewLowStockSettings,
 		fx.Annotate(
 			services.NewInventoryService,
 			fx.As(new(services.InventoryService)),
 		),
 
+		// Inventory shard service, for hot products' stock
+		fx.Annotate(
+			services.NewInventoryShardService,
+			fx.As(new(services.InventoryShardService)),
+		),
+
+		// Stock import service, applying CSVs of stock adjustments on the worker pool
+		fx.Annotate(
+			services.NewStockImportService,
+			fx.As(new(services.StockImportService)),
+		),
+
 		// Order service
+		NewDuplicateOrderSettings,
+		services.NewDuplicateOrderGuard,
+		NewOrderLimitSettings,
+		services.NewOrderLimiter,
+		NewAllocationQueueSettings,
+		services.NewAllocationQueue,
 		fx.Annotate(
 			services.NewOrderService,
 			fx.As(new(services.OrderService)),
 		),
 
-		// Payment service
+		// Admin order service
+		NewPriceOverrideSettings,
+		fx.Annotate(
+			services.NewAdminOrderService,
+			fx.As(new(services.AdminOrderService)),
+		),
+
+		// Order compliance holds, blocking fulfillment until released
+		NewOrderHoldSettings,
+		fx.Annotate(
+			services.NewOrderHoldService,
+			fx.As(new(services.OrderHoldService)),
+		),
+
+		// B2B organization service
+		fx.Annotate(
+			services.NewOrganizationService,
+			fx.As(new(services.OrganizationService)),
+		),
+
+		// Double-entry payments ledger, posted to by the payment and webhook services
+		fx.Annotate(
+			services.NewLedgerService,
+			fx.As(new(services.LedgerService)),
+		),
+		NewLedgerRecorder,
+
+		// Payment service, holding transiently failed payments for retry
+		NewPaymentRetrySettings,
 		fx.Annotate(
 			services.NewPaymentService,
 			fx.As(new(services.PaymentService)),
 		),
 
-		// Notification service
+		// Payment intents, taking card payments confirmed after the gateway's client SDK
+		NewPaymentIntentSettings,
+		fx.Annotate(
+			services.NewPaymentIntentService,
+			fx.As(new(services.PaymentIntentService)),
+		),
+		fx.Annotate(
+			services.NewPhoneOrderService,
+			fx.As(new(services.PhoneOrderService)),
+		),
+
+		// Serial numbers of serialized products, received by lot and captured at fulfillment
+		fx.Annotate(
+			services.NewProductSerialService,
+			fx.As(new(services.ProductSerialService)),
+		),
+		fx.Annotate(
+			services.NewWarrantyClaimService,
+			fx.As(new(services.WarrantyClaimService)),
+		),
+
+		// Payment reconciliation against gateway records, reported through the report manager
+		NewPaymentReconciliationSettings,
+		fx.Annotate(
+			services.NewPaymentReconciliationService,
+			fx.As(new(services.PaymentReconciliationService)),
+		),
+
+		// Payment routing rules, evaluated by the gateway manager before gateway selection
+		fx.Annotate(
+			services.NewPaymentRoutingService,
+			fx.As(new(services.PaymentRoutingService)),
+		),
+
+		// Confirmation of paid orders and their fulfillment queue, fed by the order event outbox
+		NewOrderConfirmationSettings,
+		fx.Annotate(
+			services.NewOrderConfirmationService,
+			fx.As(new(services.OrderConfirmationService)),
+		),
+
+		// Dead-letter queue of background jobs out of retries
+		fx.Annotate(
+			services.NewDeadLetterService,
+			fx.As(new(services.DeadLetterService)),
+		),
+
+		// Worker pool stats and runtime resizing
+		fx.Annotate(
+			services.NewWorkerPoolService,
+			fx.As(new(services.WorkerPoolService)),
+		),
+
+		// End-of-day close reports emailed to managers
+		NewCloseReportSettings,
+		fx.Annotate(
+			services.NewCloseReportService,
+			fx.As(new(services.CloseReportService)),
+		),
+
+		// Support search over orders, users and payments
+		fx.Annotate(
+			services.NewSupportSearchService,
+			fx.As(new(services.SupportSearchService)),
+		),
+
+		// Offline payments recorded by admins, outside the gateway flow
+		fx.Annotate(
+			services.NewManualPaymentService,
+			fx.As(new(services.ManualPaymentService)),
+		),
+
+		// A/B tests of notification wording, applied by the notification service
+		fx.Annotate(
+			services.NewNotificationExperimentService,
+			fx.As(new(services.NotificationExperimentService)),
+		),
+		NewNotificationVariantPicker,
+
+		// Notification service, failing over between channels per notification type
+		NewNotificationFailoverSettings,
+		services.NewNotificationFailover,
+		services.NewSimulatedNotificationSender,
 		fx.Annotate(
 			services.NewNotificationService,
 			fx.As(new(services.NotificationService)),
 		),
 
+		// Order status notifications, sent through the notification service; status
+		// changes are relayed from the order event outbox
+		NewOrderStatusNotificationSettings,
+		fx.Annotate(
+			services.NewOrderStatusNotifier,
+			fx.As(new(services.OrderStatusNotifier)),
+		),
+		NewOrderNotificationRelaySettings,
+		fx.Annotate(
+			services.NewOrderNotificationRelay,
+			fx.As(new(services.OrderNotificationRelay)),
+		),
+
+		// Anonymized sandbox snapshots
+		fx.Annotate(
+			services.NewSandboxSnapshotService,
+			fx.As(new(services.SandboxSnapshotService)),
+		),
+
 		// Report service
 		fx.Annotate(
 			services.NewReportService,
 			fx.As(new(services.ReportService)),
 		),
+
+		// Background report queue, backed by the report manager, whose finished reports
+		// are notified to the user who queued them
+		fx.Annotate(
+			services.NewReportQueueService,
+			fx.As(new(services.ReportQueueService)),
+		),
+		services.NewReportCompletionNotifier,
+
+		// Webhook service, accepting events from the partners with a secret configured
+		NewPartnerWebhookSecrets,
+		fx.Annotate(
+			services.NewWebhookService,
+			fx.As(new(services.WebhookService)),
+		),
+
+		// Order and payment event webhooks to registered endpoints, published to by the
+		// payment and webhook services
+		NewEventWebhookSettings,
+		NewWebhookClient,
+		fx.Annotate(
+			services.NewEventWebhookService,
+			fx.As(new(services.EventWebhookService)),
+		),
+		NewWebhookPublisher,
+
+		// Stock webhook service, notified through the stock change notifier
+		fx.Annotate(
+			services.NewStockWebhookService,
+			fx.As(new(services.StockWebhookService)),
+		),
+
+		// Storefront availability read model, refreshed on stock changes
+		NewAvailabilitySettings,
+		fx.Annotate(
+			services.NewAvailabilityService,
+			fx.As(new(services.AvailabilityService)),
+		),
+
+		// Stock change notifications fan out to webhooks, the availability read model and
+		// low-stock alerts
+		NewStockChangeNotifier,
+
+		// Channel service
+		fx.Annotate(
+			services.NewChannelService,
+			fx.As(new(services.ChannelService)),
+		),
+
+		// Cart stock holds
+		NewCartHoldSettings,
+		fx.Annotate(
+			services.NewCartHoldService,
+			fx.As(new(services.CartHoldService)),
+		),
+
+		// Saved addresses, saved payment methods and express checkout
+		NewCheckoutSagaSettings,
+		fx.Annotate(
+			services.NewCheckoutService,
+			fx.As(new(services.CheckoutService)),
+		),
+
+		// Change feeds of inventory, order and catalog events for integrators, and the
+		// purge webhook catalog changes are pushed to
+		NewChangeFeedSettings,
+		fx.Annotate(
+			services.NewChangeFeedService,
+			fx.As(new(services.ChangeFeedService)),
+		),
+		NewCatalogPurgeRelaySettings,
+		fx.Annotate(
+			services.NewCatalogPurgeRelay,
+			fx.As(new(services.CatalogPurgeRelay)),
+		),
+
+		// Data retention policies and their purges
+		NewRetentionSettings,
+		fx.Annotate(
+			services.NewRetentionService,
+			fx.As(new(services.RetentionService)),
+		),
+
+		// API keys, API usage analytics and API key quotas
+		NewAPIUsageSettings,
+		fx.Annotate(
+			services.NewAPIUsageService,
+			fx.As(new(services.APIUsageService)),
+		),
+		NewAPIKeyAuthenticator,
+		NewUsageRecorder,
+
+		// Referral codes, referral rewards and store credit
+		NewReferralSettings,
+		fx.Annotate(
+			services.NewReferralService,
+			fx.As(new(services.ReferralService)),
+		),
+		NewReferralTracker,
 	),
+	fx.Invoke(RegisterCartHoldSweeper),
+	fx.Invoke(RegisterPaymentRetrySweeper),
+	fx.Invoke(RegisterPaymentIntentSweeper),
+	fx.Invoke(RegisterPaymentReconciler),
+	fx.Invoke(RegisterPaymentPoller),
+	fx.Invoke(RegisterPaymentRoutingRules),
+	fx.Invoke(RegisterOrderConfirmer),
+	fx.Invoke(RegisterOrderNotificationRelay),
+	fx.Invoke(RegisterCatalogPurgeRelay),
+	fx.Invoke(RegisterCloseReportScheduler),
+	fx.Invoke(RegisterReportCompletionNotifier),
+	fx.Invoke(RegisterCheckoutRecovery),
+	fx.Invoke(RegisterInventoryShardRebalancer),
+	fx.Invoke(RegisterRetentionPurger),
+	fx.Invoke(RegisterWebhookDispatcher),
+	fx.Invoke(RegisterAPIUsageFlusher),
+	fx.Invoke(RegisterReferralRewarder),
 )
+
+// NewPaymentMethodAdjustments provides the payment method adjustments configured for checkout
+func NewPaymentMethodAdjustments(cfg *config.Config) (services.PaymentMethodAdjustments, error) {
+	return services.NewPaymentMethodAdjustments(cfg.Payments.MethodAdjustments)
+}
+
+// NewStoreSchedules provides the store hours and shipping cutoffs from configuration
+func NewStoreSchedules(cfg *config.Config) (*services.StoreSchedules, error) {
+	return services.NewStoreSchedules(cfg.Stores.Timezone, cfg.Stores.Hours, cfg.Stores.ShipCutoffs, cfg.Stores.RejectOutsideHours)
+}
+
+// NewPaymentRetrySettings provides the payment retry settings from configuration
+func NewPaymentRetrySettings(cfg *config.Config) services.PaymentRetrySettings {
+	return services.PaymentRetrySettings{
+		Window:  cfg.Payments.RetryWindow,
+		Backoff: cfg.Payments.RetryBackoff,
+	}
+}
+
+// NewPaymentIntentSettings provides the payment intent settings from configuration
+func NewPaymentIntentSettings(cfg *config.Config) services.PaymentIntentSettings {
+	return services.PaymentIntentSettings{
+		TTL: cfg.Payments.IntentTTL,
+	}
+}
+
+// NewPaymentReconciliationSettings provides the payment reconciliation settings from configuration
+func NewPaymentReconciliationSettings(cfg *config.Config) services.PaymentReconciliationSettings {
+	return services.PaymentReconciliationSettings{
+		Threshold:     cfg.Payments.ReconcileThreshold,
+		Lookback:      cfg.Payments.ReconcileLookback,
+		PollThreshold: cfg.Payments.PollThreshold,
+		EscalateAfter: cfg.Payments.PollEscalateAfter,
+	}
+}
+
+// NewOrderConfirmationSettings provides the order confirmation settings from configuration
+func NewOrderConfirmationSettings(cfg *config.Config) services.OrderConfirmationSettings {
+	return services.OrderConfirmationSettings{
+		ManualStores: cfg.Stores.ManualConfirmation,
+		SettleDelay:  cfg.ChangeFeeds.SettleDelay,
+	}
+}
+
+// NewOrderNotificationRelaySettings provides the order notification relay settings from
+// configuration
+func NewOrderNotificationRelaySettings(cfg *config.Config) services.OrderNotificationRelaySettings {
+	return services.OrderNotificationRelaySettings{
+		SettleDelay: cfg.ChangeFeeds.SettleDelay,
+	}
+}
+
+// NewCheckoutSagaSettings provides the express checkout saga settings from configuration
+func NewCheckoutSagaSettings(cfg *config.Config) services.CheckoutSagaSettings {
+	return services.CheckoutSagaSettings{
+		StaleAfter: cfg.Orders.CheckoutStaleAfter,
+	}
+}
+
+// NewCloseReportSettings provides the close report settings from configuration, in the
+// store timezone
+func NewCloseReportSettings(cfg *config.Config) (services.CloseReportSettings, error) {
+	return services.NewCloseReportSettings(cfg.Stores.Timezone, cfg.Reports.CloseTimes, cfg.Reports.CloseRecipients)
+}
+
+// NewLowStockSettings provides the low-stock threshold settings from configuration
+func NewLowStockSettings(cfg *config.Config) services.LowStockSettings {
+	return services.LowStockSettings{
+		Dynamic:        cfg.Stock.DynamicThresholds,
+		VelocityWindow: cfg.Stock.VelocityWindow,
+		LeadTimeDays:   cfg.Stock.LeadTimeDays,
+		SafetyDays:     cfg.Stock.SafetyDays,
+	}
+}
+
+// NewChangeFeedSettings provides the change feed settings from configuration
+func NewChangeFeedSettings(cfg *config.Config) services.ChangeFeedSettings {
+	return services.ChangeFeedSettings{
+		SettleDelay: cfg.ChangeFeeds.SettleDelay,
+	}
+}
+
+// NewCatalogPurgeRelaySettings provides the catalog purge webhook settings from
+// configuration
+func NewCatalogPurgeRelaySettings(cfg *config.Config) services.CatalogPurgeRelaySettings {
+	return services.CatalogPurgeRelaySettings{
+		URL:         cfg.ChangeFeeds.CatalogPurgeURL,
+		Secret:      cfg.ChangeFeeds.CatalogPurgeSecret,
+		SettleDelay: cfg.ChangeFeeds.SettleDelay,
+	}
+}
+
+// NewGiftOptions provides the stores that offer gift options from configuration
+func NewGiftOptions(cfg *config.Config) *services.GiftOptions {
+	return services.NewGiftOptions(cfg.Stores.GiftOptions)
+}
+
+// NewDuplicateOrderSettings provides the duplicate order check settings from configuration
+func NewDuplicateOrderSettings(cfg *config.Config) services.DuplicateOrderSettings {
+	return services.DuplicateOrderSettings{
+		Window:              cfg.Orders.DuplicateWindow,
+		RequireConfirmation: cfg.Orders.DuplicateConfirmation,
+	}
+}
+
+// NewOrderLimitSettings provides the initial order limits from configuration
+func NewOrderLimitSettings(cfg *config.Config) services.OrderLimitSettings {
+	return services.OrderLimitSettings{
+		MaxItemsPerOrder:  cfg.Orders.MaxItems,
+		MaxQuantityPerSKU: cfg.Orders.MaxQuantityPerSKU,
+		MaxOrdersPerHour:  cfg.Orders.MaxPerUserPerHour,
+	}
+}
+
+// NewAllocationQueueSettings provides the flash sale stock allocation queue settings from configuration
+func NewAllocationQueueSettings(cfg *config.Config) services.AllocationQueueSettings {
+	return services.AllocationQueueSettings{
+		Enabled:   cfg.Orders.AllocationQueue,
+		Wait:      cfg.Orders.AllocationQueueWait,
+		TicketTTL: cfg.Orders.AllocationQueueTicketTTL,
+	}
+}
+
+// NewPriceOverrideSettings provides the order item price override policy from configuration
+func NewPriceOverrideSettings(cfg *config.Config) services.PriceOverrideSettings {
+	return services.PriceOverrideSettings{
+		MaxDiscountPercent: cfg.Orders.MaxPriceDiscountPercent,
+	}
+}
+
+// NewOrderHoldSettings provides the order hold SLA from configuration
+func NewOrderHoldSettings(cfg *config.Config) services.OrderHoldSettings {
+	return services.OrderHoldSettings{
+		SLA: cfg.Orders.HoldSLA,
+	}
+}
+
+// NewRetentionSettings provides the data retention settings from configuration
+func NewRetentionSettings(cfg *config.Config) services.RetentionSettings {
+	day := 24 * time.Hour
+	return services.RetentionSettings{
+		Notifications:   time.Duration(cfg.Retention.NotificationDays) * day,
+		IdempotencyKeys: time.Duration(cfg.Retention.IdempotencyKeyDays) * day,
+		WebhookEvents:   time.Duration(cfg.Retention.WebhookEventDays) * day,
+		ReportArtifacts: time.Duration(cfg.Retention.ReportArtifactDays) * day,
+		DryRun:          cfg.Retention.DryRun,
+	}
+}
+
+// NewAPIUsageSettings provides the default API key quota from configuration
+func NewAPIUsageSettings(cfg *config.Config) services.APIUsageSettings {
+	return services.APIUsageSettings{
+		DefaultMonthlyQuota: cfg.Usage.DefaultMonthlyQuota,
+	}
+}
+
+// NewAPIKeyAuthenticator provides the API usage service to the auth middleware
+func NewAPIKeyAuthenticator(apiUsage services.APIUsageService) middleware2.APIKeyAuthenticator {
+	return apiUsage
+}
+
+// NewUsageRecorder provides the API usage service to the usage middleware
+func NewUsageRecorder(apiUsage services.APIUsageService) middleware2.UsageRecorder {
+	return apiUsage
+}
+
+// NewActivityRecorder provides the recorder told about user activity, which stores it
+// and attributes referred customers' first orders to their referrals
+func NewActivityRecorder(activityRepo repository.ActivityEventRepository, referrals services.ReferralService, logger *logger.Logger) services.ActivityRecorder {
+	return services.ActivityRecorders{services.NewActivityService(activityRepo, logger), referrals}
+}
+
+// NewReferralSettings provides the referral reward settings from configuration
+func NewReferralSettings(cfg *config.Config) services.ReferralSettings {
+	return services.ReferralSettings{
+		RewardAmount: cfg.Referrals.RewardAmount,
+		ReturnWindow: cfg.Referrals.ReturnWindow,
+	}
+}
+
+// NewPromotionSettings provides the checkout promotion settings from configuration
+func NewPromotionSettings(cfg *config.Config) services.PromotionSettings {
+	return services.PromotionSettings{
+		ShippingFee: cfg.Promotions.ShippingFee,
+	}
+}
+
+// NewReferralTracker provides the referral service to user signup
+func NewReferralTracker(referrals services.ReferralService) services.ReferralTracker {
+	return referrals
+}
+
+// NewStageRecorder provides the stage metrics recorder sized from configuration
+func NewStageRecorder(cfg *config.Config) *metrics.StageRecorder {
+	return metrics.NewStageRecorder(cfg.Metrics.StageWindow)
+}
+
+// NewOrderStatusNotificationSettings provides the configured order status notifications
+func NewOrderStatusNotificationSettings(cfg *config.Config) (services.OrderStatusNotificationSettings, error) {
+	return services.NewOrderStatusNotificationSettings(cfg.Notify.OrderStatuses, cfg.Notify.OrderChannels)
+}
+
+// NewNotificationFailoverSettings provides the notification channel failover policies from configuration
+func NewNotificationFailoverSettings(cfg *config.Config) (services.NotificationFailoverSettings, error) {
+	return services.NewNotificationFailoverSettings(cfg.Notify.Failover, cfg.Notify.ChannelFailureThreshold, cfg.Notify.ChannelResetTimeout)
+}
+
+// NewCartHoldSettings provides the cart stock hold settings from configuration
+func NewCartHoldSettings(cfg *config.Config) services.CartHoldSettings {
+	return services.CartHoldSettings{
+		Enabled: cfg.Cart.HoldsEnabled,
+		Window:  cfg.Cart.HoldWindow,
+	}
+}
+
+// NewAvailabilitySettings provides the storefront availability settings from configuration
+func NewAvailabilitySettings(cfg *config.Config) services.AvailabilitySettings {
+	return services.AvailabilitySettings{
+		MaxStaleness: cfg.Availability.MaxStaleness,
+	}
+}
+
+// NewStockChangeNotifier provides the notifier told about every inventory change
+func NewStockChangeNotifier(
+	webhooks services.StockWebhookService,
+	availability services.AvailabilityService,
+	lowStock services.LowStockSettings,
+	inventoryRepo repository.InventoryRepository,
+	userRepo repository.UserRepository,
+	digests services.NotificationDigester,
+	logger *logger.Logger,
+) services.StockChangeNotifier {
+	lowStockAlerts := services.NewLowStockNotifier(lowStock, inventoryRepo, userRepo, digests, logger)
+	return services.StockChangeNotifiers{availability, webhooks, lowStockAlerts}
+}
+
+// NewNotificationVariantPicker provides the experiment service to the notification
+// service, which words notifications by it
+func NewNotificationVariantPicker(experiments services.NotificationExperimentService) services.NotificationVariantPicker {
+	return experiments
+}
+
+// NewLedgerRecorder provides the ledger service to the services that post to it
+func NewLedgerRecorder(ledger services.LedgerService) services.LedgerRecorder {
+	return ledger
+}
+
+// NewPartnerWebhookSecrets provides the secrets partners sign their webhook events with
+func NewPartnerWebhookSecrets(cfg *config.Config) services.PartnerWebhookSecrets {
+	return services.PartnerWebhookSecrets(cfg.Webhooks.PartnerSecrets)
+}
+
+// NewEventWebhookSettings provides the webhook retry policy from configuration. Order
+// events are held back as long as change feeds hold them, and deliveries are claimed
+// for twice the attempt timeout.
+func NewEventWebhookSettings(cfg *config.Config) services.EventWebhookSettings {
+	return services.EventWebhookSettings{
+		Retry: webhooks.RetryPolicy{
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		},
+		SettleDelay: cfg.ChangeFeeds.SettleDelay,
+		Lease:       2 * cfg.Webhooks.Timeout,
+	}
+}
+
+// NewWebhookClient provides the client webhook deliveries are sent with
+func NewWebhookClient(cfg *config.Config) *webhooks.Client {
+	return webhooks.NewClient(cfg.Webhooks.Timeout, "easy-orders-webhooks/1.0")
+}
+
+// NewWebhookPublisher provides the event webhook service to the services that publish to it
+func NewWebhookPublisher(webhookService services.EventWebhookService) services.WebhookPublisher {
+	return webhookService
+}
+
+// RegisterCartHoldSweeper periodically releases expired cart holds back to available stock.
+// It also runs while holds are disabled, so holds placed before then still expire.
+func RegisterCartHoldSweeper(lc fx.Lifecycle, cfg *config.Config, cartHoldService services.CartHoldService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Cart.HoldSweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := cartHoldService.ReleaseExpiredHolds(context.Background()); err != nil {
+							logger.Warn("Failed to release expired cart holds", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Cart hold sweeper started", "interval", cfg.Cart.HoldSweepInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterPaymentRetrySweeper periodically retries held payments and fails those whose
+// retry window expired. It also runs while holding is disabled, so held payments settle.
+func RegisterPaymentRetrySweeper(lc fx.Lifecycle, cfg *config.Config, paymentService services.PaymentService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Payments.RetrySweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := paymentService.RetryHeldPayments(context.Background()); err != nil {
+							logger.Warn("Failed to retry held payments", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Payment retry sweeper started", "interval", cfg.Payments.RetrySweepInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterPaymentIntentSweeper periodically expires lapsed payment intents and settles
+// processing ones whose payment completed or failed, unless the sweep interval is zero
+func RegisterPaymentIntentSweeper(lc fx.Lifecycle, cfg *config.Config, intentService services.PaymentIntentService, logger *logger.Logger) {
+	if cfg.Payments.IntentSweepInterval <= 0 {
+		logger.Info("Payment intent sweep disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Payments.IntentSweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := intentService.SweepIntents(context.Background()); err != nil {
+							logger.Warn("Failed to sweep payment intents", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Payment intent sweeper started", "interval", cfg.Payments.IntentSweepInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterPaymentReconciler periodically checks stuck and recently failed payments
+// against their gateway, unless the reconciliation interval is zero
+func RegisterPaymentReconciler(lc fx.Lifecycle, cfg *config.Config, reconciliationService services.PaymentReconciliationService, logger *logger.Logger) {
+	if cfg.Payments.ReconcileInterval <= 0 {
+		logger.Info("Payment reconciliation disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Payments.ReconcileInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := reconciliationService.Reconcile(context.Background()); err != nil {
+							logger.Warn("Failed to reconcile payments", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Payment reconciler started", "interval", cfg.Payments.ReconcileInterval,
+				"threshold", cfg.Payments.ReconcileThreshold)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterPaymentPoller periodically polls the stuck payments of gateways without
+// webhooks for their status, unless the polling interval is zero
+func RegisterPaymentPoller(lc fx.Lifecycle, cfg *config.Config, reconciliationService services.PaymentReconciliationService, logger *logger.Logger) {
+	if cfg.Payments.PollInterval <= 0 {
+		logger.Info("Payment status polling disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Payments.PollInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := reconciliationService.Poll(context.Background()); err != nil {
+							logger.Warn("Failed to poll payments", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Payment poller started", "interval", cfg.Payments.PollInterval,
+				"threshold", cfg.Payments.PollThreshold, "escalate_after", cfg.Payments.PollEscalateAfter)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterOrderConfirmer starts the confirmation of paid orders from the order event
+// outbox every ORDER_CONFIRMATION_INTERVAL; a zero interval disables it, leaving every
+// paid order to be confirmed by an admin
+func RegisterOrderConfirmer(lc fx.Lifecycle, cfg *config.Config, confirmationService services.OrderConfirmationService, logger *logger.Logger) {
+	if cfg.Orders.ConfirmationInterval <= 0 {
+		logger.Info("Order confirmation disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Orders.ConfirmationInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := confirmationService.ProcessOrderEvents(context.Background()); err != nil {
+							logger.Warn("Failed to confirm paid orders", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Order confirmer started", "interval", cfg.Orders.ConfirmationInterval,
+				"manual_stores", cfg.Stores.ManualConfirmation)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterOrderNotificationRelay starts notifying customers of the order status changes
+// in the order event outbox every ORDER_NOTIFICATION_INTERVAL; a zero interval disables
+// it, and with it customer status notifications
+func RegisterOrderNotificationRelay(lc fx.Lifecycle, cfg *config.Config, relay services.OrderNotificationRelay, logger *logger.Logger) {
+	if cfg.Orders.NotificationInterval <= 0 {
+		logger.Info("Order status notifications disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Orders.NotificationInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := relay.RelayOrderEvents(context.Background()); err != nil {
+							logger.Warn("Failed to relay order status notifications", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Order notification relay started", "interval", cfg.Orders.NotificationInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterCatalogPurgeRelay starts posting catalog changes to CATALOG_PURGE_WEBHOOK_URL
+// every CATALOG_PURGE_INTERVAL; without a URL, or with a zero interval, catalog changes
+// are only served by the change feed
+func RegisterCatalogPurgeRelay(lc fx.Lifecycle, cfg *config.Config, relay services.CatalogPurgeRelay, logger *logger.Logger) {
+	if cfg.ChangeFeeds.CatalogPurgeURL == "" || cfg.ChangeFeeds.CatalogPurgeInterval <= 0 {
+		logger.Info("Catalog purge webhook disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.ChangeFeeds.CatalogPurgeInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := relay.RelayCatalogEvents(context.Background()); err != nil {
+							logger.Warn("Failed to relay catalog changes to purge webhook", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Catalog purge relay started", "interval", cfg.ChangeFeeds.CatalogPurgeInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterReportCompletionNotifier notifies the user who queued a background report
+// once it has completed or failed
+func RegisterReportCompletionNotifier(manager *reports.ReportManager, notifier *services.ReportCompletionNotifier) {
+	manager.OnCompletion(notifier.NotifyReportCompleted)
+}
+
+// RegisterCloseReportScheduler checks every REPORT_CLOSE_CHECK_INTERVAL for close reports
+// whose local time has come; without REPORT_CLOSE_TIMES or REPORT_CLOSE_RECIPIENTS no
+// report is sent
+func RegisterCloseReportScheduler(lc fx.Lifecycle, cfg *config.Config, closeReports services.CloseReportService, logger *logger.Logger) {
+	if len(cfg.Reports.CloseTimes) == 0 || len(cfg.Reports.CloseRecipients) == 0 || cfg.Reports.CloseCheckInterval <= 0 {
+		logger.Info("Close reports disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Reports.CloseCheckInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := closeReports.SendDue(context.Background()); err != nil {
+							logger.Warn("Failed to send close reports", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Close report scheduler started", "times", cfg.Reports.CloseTimes,
+				"recipients", len(cfg.Reports.CloseRecipients), "timezone", cfg.Stores.Timezone)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterCheckoutRecovery recovers the express checkouts left mid-step by a crash:
+// once on startup, then periodically, as a checkout only counts as stopped once it
+// made no progress for its stale period
+func RegisterCheckoutRecovery(lc fx.Lifecycle, cfg *config.Config, checkoutService services.CheckoutService, logger *logger.Logger) {
+	if cfg.Orders.CheckoutRecoveryInterval <= 0 {
+		logger.Info("Checkout recovery disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Orders.CheckoutRecoveryInterval)
+				defer ticker.Stop()
+
+				for {
+					if _, err := checkoutService.RecoverCheckouts(context.Background()); err != nil {
+						logger.Warn("Failed to recover checkouts", "error", err)
+					}
+
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			logger.Info("Checkout recovery started", "interval", cfg.Orders.CheckoutRecoveryInterval,
+				"stale_after", cfg.Orders.CheckoutStaleAfter)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterInventoryShardRebalancer periodically evens out the stock of sharded
+// products over their shards
+func RegisterInventoryShardRebalancer(lc fx.Lifecycle, cfg *config.Config, shardService services.InventoryShardService, logger *logger.Logger) {
+	if cfg.Stock.ShardRebalanceInterval <= 0 {
+		logger.Info("Inventory shard rebalancing disabled")
+		return
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Stock.ShardRebalanceInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := shardService.RebalanceShards(context.Background()); err != nil {
+							logger.Warn("Failed to rebalance inventory shards", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Inventory shard rebalancer started", "interval", cfg.Stock.ShardRebalanceInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterPaymentRoutingRules loads the active payment routing rules into the gateway
+// manager on startup. Payments are routed by the gateway selector alone until they load.
+func RegisterPaymentRoutingRules(lc fx.Lifecycle, routingService services.PaymentRoutingService, logger *logger.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := routingService.LoadRules(ctx); err != nil {
+				logger.Warn("Failed to load payment routing rules", "error", err)
+			}
+			return nil
+		},
+	})
+}
+
+// RegisterRetentionPurger periodically purges records older than their retention policy.
+// With dry-run configured it only records what each policy would purge.
+func RegisterRetentionPurger(lc fx.Lifecycle, cfg *config.Config, retentionService services.RetentionService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Retention.PurgeInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := retentionService.Purge(context.Background(), false); err != nil {
+							logger.Warn("Failed to purge expired records", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Retention purger started", "interval", cfg.Retention.PurgeInterval, "dry_run", cfg.Retention.DryRun)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterAPIUsageFlusher periodically writes the API usage recorded in memory to the
+// usage table, and once more on shutdown so the last requests are not lost
+func RegisterAPIUsageFlusher(lc fx.Lifecycle, cfg *config.Config, apiUsageService services.APIUsageService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Usage.FlushInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if err := apiUsageService.FlushUsage(context.Background()); err != nil {
+							logger.Warn("Failed to flush API usage", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("API usage flusher started", "interval", cfg.Usage.FlushInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			if err := apiUsageService.FlushUsage(ctx); err != nil {
+				logger.Warn("Failed to flush API usage on shutdown", "error", err)
+			}
+			return nil
+		},
+	})
+}
+
+// RegisterReferralRewarder periodically credits referrers whose referred customers'
+// first orders were delivered and kept past the return window
+func RegisterReferralRewarder(lc fx.Lifecycle, cfg *config.Config, referralService services.ReferralService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Referrals.SweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := referralService.ProcessRewards(context.Background()); err != nil {
+							logger.Warn("Failed to process referral rewards", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Referral rewarder started", "interval", cfg.Referrals.SweepInterval, "return_window", cfg.Referrals.ReturnWindow)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
+
+// RegisterWebhookDispatcher periodically queues webhook deliveries of new order events
+// and sends the deliveries that are due
+func RegisterWebhookDispatcher(lc fx.Lifecycle, cfg *config.Config, webhookService services.EventWebhookService, logger *logger.Logger) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+
+				ticker := time.NewTicker(cfg.Webhooks.DeliveryInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if _, err := webhookService.RelayOrderEvents(context.Background()); err != nil {
+							logger.Warn("Failed to relay order events to webhooks", "error", err)
+						}
+						if _, err := webhookService.DeliverDue(context.Background()); err != nil {
+							logger.Warn("Failed to send due webhook deliveries", "error", err)
+						}
+					}
+				}
+			}()
+			logger.Info("Webhook dispatcher started", "interval", cfg.Webhooks.DeliveryInterval)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			close(done)
+			<-stopped
+			return nil
+		},
+	})
+}
@@ -1,7 +1,12 @@
 package fx
 
 import (
+	"context"
+
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/pkg/workers"
 
 	"go.uber.org/fx"
@@ -10,10 +15,38 @@ import (
 // WorkersModule provides worker pool and background service dependencies
 var WorkersModule = fx.Module("workers",
 	fx.Provide(
-		// Pool manager
-		workers.NewPoolManager,
+		// Pool manager, backed by the background job table
+		NewPoolManager,
 
 		// Background service
 		services.NewBackgroundService,
 	),
+	fx.Invoke(RegisterBackgroundService),
 )
+
+// NewPoolManager provides a pool manager keeping background jobs in the database
+func NewPoolManager(cfg *config.Config, jobRepo repository.BackgroundJobRepository, logger *logger.Logger) *workers.PoolManager {
+	poolManager := workers.NewPoolManager(logger)
+	poolManager.SetJobStore(jobRepo, workers.DurableQueueConfig{
+		PollInterval:      cfg.Jobs.PollInterval,
+		VisibilityTimeout: cfg.Jobs.VisibilityTimeout,
+	})
+	return poolManager
+}
+
+// RegisterBackgroundService starts the worker pools with the application, resuming the
+// background jobs stored before it last stopped, and stops them on shutdown, returning
+// the jobs they did not start to the queue
+func RegisterBackgroundService(lc fx.Lifecycle, backgroundService *services.BackgroundService, logger *logger.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return backgroundService.Start(ctx)
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := backgroundService.Stop(); err != nil {
+				logger.Warn("Failed to stop background service", "error", err)
+			}
+			return nil
+		},
+	})
+}
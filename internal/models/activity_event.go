@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ActivityEventType identifies what a user did
+type ActivityEventType string
+
+const (
+	ActivityEventRegistered     ActivityEventType = "registered"
+	ActivityEventLogin          ActivityEventType = "login"
+	ActivityEventOrderPlaced    ActivityEventType = "order_placed"
+	ActivityEventOrderCancelled ActivityEventType = "order_cancelled"
+	ActivityEventPayment        ActivityEventType = "payment"
+)
+
+// ActivityEvent records a single user action for engagement reporting
+type ActivityEvent struct {
+	ID        string            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    string            `gorm:"type:uuid;not null;index" json:"user_id"`
+	Type      ActivityEventType `gorm:"type:varchar(30);not null;index" json:"type"`
+	EntityID  string            `gorm:"type:varchar(255)" json:"entity_id,omitempty"` // Order or payment the event refers to
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (e *ActivityEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for ActivityEvent model
+func (ActivityEvent) TableName() string {
+	return "activity_events"
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShippingAddress is where an order is delivered. Orders keep a copy of the address
+// they were placed with, so later changes to the customer's saved addresses do not
+// change where placed orders ship.
+type ShippingAddress struct {
+	Recipient  string `gorm:"type:varchar(255);not null" json:"recipient"`
+	Line1      string `gorm:"type:varchar(255);not null" json:"line1"`
+	Line2      string `gorm:"type:varchar(255)" json:"line2,omitempty"`
+	City       string `gorm:"type:varchar(100);not null" json:"city"`
+	Region     string `gorm:"type:varchar(100)" json:"region,omitempty"`
+	PostalCode string `gorm:"type:varchar(20);not null" json:"postal_code"`
+	Country    string `gorm:"type:varchar(2);not null" json:"country"` // ISO 3166-1 alpha-2
+	Phone      string `gorm:"type:varchar(30)" json:"phone,omitempty"`
+}
+
+// Address is a shipping address saved by a customer. A customer has at most one
+// default address, which express checkout ships to.
+type Address struct {
+	ID              string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          string          `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label           string          `gorm:"type:varchar(100)" json:"label,omitempty"`
+	ShippingAddress ShippingAddress `gorm:"embedded" json:"address"`
+	IsDefault       bool            `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (a *Address) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for Address model
+func (Address) TableName() string {
+	return "addresses"
+}
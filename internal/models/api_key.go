@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey lets a user's integration call the API with an X-API-Key header instead of a
+// token. Only the SHA-256 hash of the key is stored; Prefix, its first characters, tells
+// keys apart. A key with a MonthlyQuota is refused once it made that many requests in a
+// calendar month (UTC).
+type APIKey struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name         string     `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix       string     `gorm:"type:varchar(20);not null" json:"prefix"`
+	KeyHash      string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	MonthlyQuota *int       `json:"monthly_quota,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive returns true if the key has not been revoked
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// APIUsage aggregates the requests a caller made to one endpoint on one UTC day.
+// Callers are API keys, users signed in with a token (APIKeyID empty), or anonymous
+// (both empty). Route is the route pattern, such as /api/v1/orders/:id. Rejected
+// counts requests refused for an exhausted quota, which are not in Requests; Errors
+// counts requests answered with a 5xx status.
+type APIUsage struct {
+	Day            time.Time `gorm:"type:date;primaryKey" json:"day"`
+	APIKeyID       string    `gorm:"type:varchar(36);primaryKey;default:''" json:"api_key_id,omitempty"`
+	UserID         string    `gorm:"type:varchar(36);primaryKey;default:''" json:"user_id,omitempty"`
+	Method         string    `gorm:"type:varchar(10);primaryKey" json:"method"`
+	Route          string    `gorm:"type:varchar(255);primaryKey" json:"route"`
+	Requests       int64     `gorm:"not null;default:0" json:"requests"`
+	Errors         int64     `gorm:"not null;default:0" json:"errors"`
+	Rejected       int64     `gorm:"not null;default:0" json:"rejected"`
+	TotalLatencyMs int64     `gorm:"not null;default:0" json:"total_latency_ms"`
+	MaxLatencyMs   int64     `gorm:"not null;default:0" json:"max_latency_ms"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for APIUsage model
+func (APIUsage) TableName() string {
+	return "api_usage"
+}
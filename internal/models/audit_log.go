@@ -18,6 +18,9 @@ const (
 	AuditActionLogin  AuditAction = "login"
 	AuditActionLogout AuditAction = "logout"
 	AuditActionAccess AuditAction = "access"
+
+	AuditActionManualPayment AuditAction = "manual_payment" // Offline payment recorded by an admin
+	AuditActionRelocate      AuditAction = "relocate"       // Stock moved to another bin location
 )
 
 // AuditLog represents audit trail for tracking changes
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BackgroundJobStatus defines the status of a stored background job
+type BackgroundJobStatus string
+
+const (
+	BackgroundJobStatusQueued BackgroundJobStatus = "queued"
+	BackgroundJobStatusLeased BackgroundJobStatus = "leased"
+)
+
+// BackgroundJob is a job of the worker pools kept in the database, so jobs queued or
+// running when an instance stops are run again. A pool leases a queued job once RunAt
+// has passed, hiding it from other instances until LeasedUntil; a leased job whose
+// lease expired is leased again. Attempts counts the leases, and Payload holds the job
+// encoded as JSON. Completed jobs are deleted, and jobs out of retries move to the
+// dead-letter queue as DeadJob.
+type BackgroundJob struct {
+	ID          string              `gorm:"type:varchar(100);primaryKey" json:"id"`
+	Type        string              `gorm:"type:varchar(50);not null;index" json:"type"`
+	Pool        string              `gorm:"type:varchar(50);not null;index:idx_background_jobs_due,priority:1" json:"pool"`
+	Status      BackgroundJobStatus `gorm:"type:varchar(20);not null;default:'queued';index:idx_background_jobs_due,priority:2" json:"status"`
+	Priority    int                 `gorm:"not null;default:0" json:"priority"`
+	Payload     json.RawMessage     `gorm:"type:jsonb;serializer:json" json:"payload,omitempty"`
+	Attempts    int                 `gorm:"not null;default:0" json:"attempts"`
+	MaxRetries  int                 `gorm:"not null;default:0" json:"max_retries"`
+	RunAt       time.Time           `gorm:"not null;index:idx_background_jobs_due,priority:3" json:"run_at"`
+	LeaseOwner  string              `gorm:"type:varchar(100)" json:"lease_owner,omitempty"`
+	LeasedUntil *time.Time          `gorm:"index" json:"leased_until,omitempty"`
+	LastError   string              `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+// TableName returns the table name for BackgroundJob model
+func (BackgroundJob) TableName() string {
+	return "background_jobs"
+}
+
+// DeadJob is a background job that failed on its last retry, or could not be run at
+// all, set aside in the dead-letter queue with its payload and last error until an
+// admin requeues or purges it
+type DeadJob struct {
+	ID         string          `gorm:"type:varchar(100);primaryKey" json:"id"`
+	Type       string          `gorm:"type:varchar(50);not null;index" json:"type"`
+	Pool       string          `gorm:"type:varchar(50);not null;index" json:"pool"`
+	Priority   int             `gorm:"not null;default:0" json:"priority"`
+	Payload    json.RawMessage `gorm:"type:jsonb;serializer:json" json:"payload,omitempty"`
+	Attempts   int             `gorm:"not null;default:0" json:"attempts"`
+	MaxRetries int             `gorm:"not null;default:0" json:"max_retries"`
+	LastError  string          `gorm:"type:text" json:"last_error,omitempty"`
+	QueuedAt   time.Time       `json:"queued_at"`
+	DiedAt     time.Time       `gorm:"not null;index" json:"died_at"`
+}
+
+// TableName returns the table name for DeadJob model
+func (DeadJob) TableName() string {
+	return "dead_jobs"
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlocklistType is the kind of value a blocklist entry blocks
+type BlocklistType string
+
+const (
+	BlocklistTypeEmail           BlocklistType = "email"            // A customer email address
+	BlocklistTypeDomain          BlocklistType = "domain"           // An email domain and its subdomains
+	BlocklistTypeCardFingerprint BlocklistType = "card_fingerprint" // A card, by its gateway fingerprint
+	BlocklistTypeIPRange         BlocklistType = "ip_range"         // A client IP range in CIDR notation
+)
+
+// BlocklistTypes lists the kinds of blocklist entries
+var BlocklistTypes = []BlocklistType{
+	BlocklistTypeEmail,
+	BlocklistTypeDomain,
+	BlocklistTypeCardFingerprint,
+	BlocklistTypeIPRange,
+}
+
+// IsValid returns true if the type is a known blocklist type
+func (t BlocklistType) IsValid() bool {
+	for _, known := range BlocklistTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// BlocklistEntry blocks orders and payments of customers matching a known abusive
+// value. Values are stored normalized, so each one is listed at most once per type.
+// Entries stop blocking at ExpiresAt, if set. HitCount counts the attempts the entry
+// blocked.
+type BlocklistEntry struct {
+	ID        string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type      BlocklistType `gorm:"type:varchar(20);not null;uniqueIndex:idx_blocklist_type_value" json:"type"`
+	Value     string        `gorm:"type:varchar(255);not null;uniqueIndex:idx_blocklist_type_value" json:"value"`
+	Reason    string        `gorm:"type:varchar(255)" json:"reason,omitempty"`
+	CreatedBy *string       `gorm:"type:uuid" json:"created_by,omitempty"`
+	ExpiresAt *time.Time    `gorm:"index" json:"expires_at,omitempty"`
+	HitCount  int64         `gorm:"not null;default:0" json:"hit_count"`
+	LastHitAt *time.Time    `json:"last_hit_at,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (e *BlocklistEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for BlocklistEntry model
+func (BlocklistEntry) TableName() string {
+	return "blocklist_entries"
+}
+
+// IsActive returns true if the entry blocks at the given time
+func (e *BlocklistEntry) IsActive(at time.Time) bool {
+	return e.ExpiresAt == nil || at.Before(*e.ExpiresAt)
+}
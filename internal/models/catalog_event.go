@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+)
+
+// CatalogEventType identifies what changed about a product as the storefront shows it
+type CatalogEventType string
+
+const (
+	CatalogEventProductCreated      CatalogEventType = "product.created"
+	CatalogEventPriceChanged        CatalogEventType = "product.price_changed"
+	CatalogEventContentChanged      CatalogEventType = "product.content_changed"
+	CatalogEventAvailabilityChanged CatalogEventType = "product.availability_changed"
+	CatalogEventProductDeleted      CatalogEventType = "product.deleted"
+)
+
+// CatalogEvent is an append-only record of a change to a product's storefront pages,
+// written in the same transaction as the change, so caches and CDNs know what to
+// invalidate. It carries no product data: consumers refetch the product. Availability
+// changes are recorded when the product goes in or out of stock, or is activated or
+// deactivated, not on every stock movement.
+type CatalogEvent struct {
+	Sequence  int64            `gorm:"primaryKey;autoIncrement" json:"sequence"`
+	ProductID string           `gorm:"type:uuid;not null;index" json:"product_id"`
+	SKU       string           `gorm:"size:100;not null" json:"sku"`
+	Type      CatalogEventType `gorm:"type:varchar(40);not null" json:"type"`
+
+	// Set by the database to the start of the writing transaction
+	RecordedAt time.Time `gorm:"not null;default:now()" json:"recorded_at"`
+}
+
+// TableName returns the table name for CatalogEvent model
+func (CatalogEvent) TableName() string {
+	return "catalog_events"
+}
+
+// CatalogEventCursor is the position of a consumer in the catalog event stream, the
+// last sequence it handled
+type CatalogEventCursor struct {
+	Consumer  string    `gorm:"type:varchar(50);primaryKey" json:"consumer"`
+	Sequence  int64     `gorm:"not null" json:"sequence"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for CatalogEventCursor model
+func (CatalogEventCursor) TableName() string {
+	return "catalog_event_cursors"
+}
+
+// CatalogChanges returns the catalog events an update of a product from previous to
+// current records. Cost price and stock settings are not shown on the storefront and
+// record none.
+func CatalogChanges(previous, current *Product) []CatalogEventType {
+	var changes []CatalogEventType
+	if previous.Price != current.Price {
+		changes = append(changes, CatalogEventPriceChanged)
+	}
+	if previous.Name != current.Name ||
+		previous.Description != current.Description ||
+		previous.SKU != current.SKU ||
+		!sameStringPointer(previous.CategoryID, current.CategoryID) ||
+		!sameMetadata(previous.Metadata, current.Metadata) ||
+		!sameTranslations(previous.Translations, current.Translations) {
+		changes = append(changes, CatalogEventContentChanged)
+	}
+	if previous.IsActive != current.IsActive {
+		changes = append(changes, CatalogEventAvailabilityChanged)
+	}
+	return changes
+}
+
+func sameStringPointer(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameMetadata(a, b Metadata) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+func sameTranslations(a, b ProductTranslations) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for locale, translation := range a {
+		if other, ok := b[locale]; !ok || other != translation {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderChannelDirect attributes orders placed through this API rather than a marketplace
+const OrderChannelDirect = "direct"
+
+// OrderChannelPhone attributes orders an agent placed on a customer's behalf by phone
+const OrderChannelPhone = "phone"
+
+// OrderChannelMigration marks historical orders imported from a previous platform
+const OrderChannelMigration = "migration"
+
+// OrderChannelWarranty marks the zero-priced orders shipping replacements for warranty claims
+const OrderChannelWarranty = "warranty"
+
+// ChannelListing maps a product to its SKU on an external sales channel
+type ChannelListing struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Channel    string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_channel_listings_channel_sku" json:"channel"`
+	ChannelSKU string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_channel_listings_channel_sku" json:"channel_sku"`
+	ProductID  string    `gorm:"type:uuid;not null;index" json:"product_id"`
+	ExternalID string    `gorm:"type:varchar(255)" json:"external_id,omitempty"` // Channel's own listing identifier
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (l *ChannelListing) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for ChannelListing model
+func (ChannelListing) TableName() string {
+	return "channel_listings"
+}
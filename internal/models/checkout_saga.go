@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CheckoutSagaStatus defines the status of a checkout saga
+type CheckoutSagaStatus string
+
+const (
+	CheckoutSagaStatusRunning     CheckoutSagaStatus = "running"     // A step is in progress, or its process stopped mid-step
+	CheckoutSagaStatusCompleted   CheckoutSagaStatus = "completed"   // Every step ran; the order was placed and charged
+	CheckoutSagaStatusFailed      CheckoutSagaStatus = "failed"      // A step failed before anything was left behind
+	CheckoutSagaStatusCompensated CheckoutSagaStatus = "compensated" // A step failed and the steps before it were undone
+)
+
+// IsValid returns true if the checkout saga status is known
+func (s CheckoutSagaStatus) IsValid() bool {
+	switch s {
+	case CheckoutSagaStatusRunning, CheckoutSagaStatusCompleted, CheckoutSagaStatusFailed, CheckoutSagaStatusCompensated:
+		return true
+	}
+	return false
+}
+
+// IsFinished returns true once a checkout saga has nothing left to run or undo
+func (s CheckoutSagaStatus) IsFinished() bool {
+	return s != CheckoutSagaStatusRunning
+}
+
+// CheckoutSaga records the progress of an express checkout through its steps, with
+// the inputs needed to resume it, so that a checkout interrupted by a crash is
+// recovered or compensated on restart. Step is the step running, or the last one run
+// once the saga is finished; OrderID is set once the order is placed.
+type CheckoutSaga struct {
+	ID              string             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          string             `gorm:"type:uuid;not null;index" json:"user_id"`
+	IdempotencyKey  string             `gorm:"type:varchar(255);not null" json:"idempotency_key"`
+	ProductID       string             `gorm:"type:uuid;not null" json:"product_id"`
+	Quantity        int                `gorm:"not null" json:"quantity"`
+	PaymentMethodID string             `gorm:"type:uuid;not null" json:"payment_method_id"`
+	ClientIP        string             `gorm:"type:varchar(45)" json:"client_ip,omitempty"`
+	OrderID         *string            `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	Step            string             `gorm:"type:varchar(50);not null" json:"step"`
+	Status          CheckoutSagaStatus `gorm:"type:varchar(20);not null;default:'running';index:idx_checkout_sagas_running,priority:1" json:"status"`
+	LastError       string             `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `gorm:"index:idx_checkout_sagas_running,priority:2" json:"updated_at"`
+}
+
+// TableName returns the table name for CheckoutSaga model
+func (CheckoutSaga) TableName() string {
+	return "checkout_sagas"
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (s *CheckoutSaga) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CloseReportRun records that the end-of-day close report of a store went out for a
+// day, so it is sent once however many instances run the schedule. Date is the day in
+// the store timezone, as YYYY-MM-DD.
+type CloseReportRun struct {
+	Store  string    `gorm:"type:varchar(50);primaryKey" json:"store"`
+	Date   string    `gorm:"type:varchar(10);primaryKey" json:"date"`
+	SentAt time.Time `gorm:"not null" json:"sent_at"`
+}
+
+// TableName returns the table name for CloseReportRun model
+func (CloseReportRun) TableName() string {
+	return "close_report_runs"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DeliverySlot is a delivery window on a day in a region, which customers choose at
+// checkout. Up to Capacity orders can be booked into it; Reserved counts those booked,
+// and is only changed by the order holding the slot's row lock, like inventory.
+type DeliverySlot struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Region    string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_delivery_slots_window" json:"region"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_delivery_slots_window" json:"date"`
+	StartTime string    `gorm:"type:varchar(5);not null;uniqueIndex:idx_delivery_slots_window" json:"start_time"` // HH:MM
+	EndTime   string    `gorm:"type:varchar(5);not null" json:"end_time"`                                         // HH:MM
+	Capacity  int       `gorm:"not null;default:0" json:"capacity"`
+	Reserved  int       `gorm:"not null;default:0" json:"reserved"`
+	IsActive  bool      `gorm:"not null;default:true" json:"is_active"` // Inactive slots take no new orders
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (s *DeliverySlot) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for DeliverySlot model
+func (DeliverySlot) TableName() string {
+	return "delivery_slots"
+}
+
+// Remaining returns how many more orders can be booked into the slot
+func (s *DeliverySlot) Remaining() int {
+	if s.Reserved >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Reserved
+}
+
+// CanReserve returns true if the slot is active and not fully booked
+func (s *DeliverySlot) CanReserve() bool {
+	return s.IsActive && s.Remaining() > 0
+}
+
+// IsBookableFrom returns true if the slot is on day or later
+func (s *DeliverySlot) IsBookableFrom(day time.Time) bool {
+	return !s.Date.Before(day.UTC().Truncate(24 * time.Hour))
+}
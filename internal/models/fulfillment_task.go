@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FulfillmentTaskStatus defines the status of a fulfillment task
+type FulfillmentTaskStatus string
+
+const (
+	FulfillmentTaskStatusQueued    FulfillmentTaskStatus = "queued"    // Waiting to be picked, packed and shipped
+	FulfillmentTaskStatusCompleted FulfillmentTaskStatus = "completed" // The order shipped
+	FulfillmentTaskStatusCancelled FulfillmentTaskStatus = "cancelled" // The order was cancelled before shipping
+)
+
+// IsValid returns true if the fulfillment task status is known
+func (s FulfillmentTaskStatus) IsValid() bool {
+	switch s {
+	case FulfillmentTaskStatusQueued, FulfillmentTaskStatusCompleted, FulfillmentTaskStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// FulfillmentTask queues a confirmed order for fulfillment. There is one task per
+// order; it is closed when the order ships or is cancelled.
+type FulfillmentTask struct {
+	ID        string                `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID   string                `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	Status    FulfillmentTaskStatus `gorm:"type:varchar(20);not null;default:'queued';index:idx_fulfillment_tasks_queue" json:"status"`
+	QueuedAt  time.Time             `gorm:"not null;index:idx_fulfillment_tasks_queue" json:"queued_at"`
+	ClosedAt  *time.Time            `json:"closed_at,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+
+	// Relationships
+	Order *Order `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"order,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (t *FulfillmentTask) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for FulfillmentTask model
+func (FulfillmentTask) TableName() string {
+	return "fulfillment_tasks"
+}
+
+// OrderEventCursor is the position of a consumer in the order event outbox, the last
+// sequence it applied
+type OrderEventCursor struct {
+	Consumer  string    `gorm:"type:varchar(50);primaryKey" json:"consumer"`
+	Sequence  int64     `gorm:"not null" json:"sequence"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for OrderEventCursor model
+func (OrderEventCursor) TableName() string {
+	return "order_event_cursors"
+}
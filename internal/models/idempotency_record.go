@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// IdempotencyRecord is a payment idempotency key claimed by a request, shared by every
+// replica. The key is unique, RequestHash fingerprints the request that claimed it so
+// the key cannot be reused for a different payment, and Result holds the outcome once
+// the payment completes. Records are purged after ExpiresAt.
+type IdempotencyRecord struct {
+	Key            string          `gorm:"type:varchar(255);primaryKey" json:"key"`
+	PaymentID      string          `gorm:"type:varchar(100);not null" json:"payment_id"`
+	RequestHash    string          `gorm:"type:varchar(64);not null" json:"request_hash"`
+	Status         string          `gorm:"type:varchar(20);not null" json:"status"`
+	Result         json.RawMessage `gorm:"type:jsonb;serializer:json" json:"result,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	LastAccessedAt time.Time       `json:"last_accessed_at"`
+	ExpiresAt      time.Time       `gorm:"not null;index" json:"expires_at"`
+}
+
+// TableName returns the table name for IdempotencyRecord model
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
@@ -1,12 +1,18 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Inventory represents stock management for products
+// DefaultWarehouseID is the warehouse of stock recorded before warehouses were tracked
+const DefaultWarehouseID = "default"
+
+// Inventory represents stock management for products. Quantity is the physical stock on
+// hand; Available is the stock sellable online, which leaves out the reserved units and
+// the SafetyStock held back to buffer against count errors.
 type Inventory struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	ProductID string    `gorm:"type:uuid;uniqueIndex;not null" json:"product_id"`
@@ -19,6 +25,19 @@ type Inventory struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Added by the online migrations and read-only until stock is tracked per warehouse;
+	// rows are written with the column default
+	WarehouseID string `gorm:"->;-:migration" json:"warehouse_id,omitempty"`
+
+	// Bin location of the product in the warehouse, e.g. A-3-2. Pick lists walk the
+	// warehouse in location order; see CompareLocations. Added by the online migrations
+	// and written by relocations only.
+	Location string `gorm:"->;-:migration" json:"location,omitempty"`
+
+	// Units held back from online sales, visible to admins only. Added by the online
+	// migrations and written by safety stock adjustments only.
+	SafetyStock int `gorm:"->;-:migration" json:"-"`
+
 	// Relationships
 	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product,omitempty"`
 }
@@ -30,16 +49,26 @@ func (Inventory) TableName() string {
 
 // BeforeUpdate hook to update available quantity
 func (i *Inventory) BeforeUpdate(tx *gorm.DB) error {
-	i.Available = i.Quantity - i.Reserved
+	i.RefreshAvailable()
 	return nil
 }
 
 // BeforeCreate hook to set available quantity
 func (i *Inventory) BeforeCreate(tx *gorm.DB) error {
-	i.Available = i.Quantity - i.Reserved
+	i.RefreshAvailable()
 	return nil
 }
 
+// RefreshAvailable recomputes the sellable stock from the stock on hand, the reserved
+// units and the safety stock. Safety stock above the unreserved stock leaves nothing
+// to sell rather than a negative amount.
+func (i *Inventory) RefreshAvailable() {
+	i.Available = i.Quantity - i.Reserved - i.SafetyStock
+	if i.Available < 0 {
+		i.Available = 0
+	}
+}
+
 // IsLowStock returns true if current stock is below minimum threshold
 func (i *Inventory) IsLowStock() bool {
 	return i.Available <= i.MinStock
@@ -56,7 +85,7 @@ func (i *Inventory) Reserve(quantity int) error {
 		return gorm.ErrInvalidData
 	}
 	i.Reserved += quantity
-	i.Available = i.Quantity - i.Reserved
+	i.RefreshAvailable()
 	return nil
 }
 
@@ -66,7 +95,7 @@ func (i *Inventory) Release(quantity int) error {
 		return gorm.ErrInvalidData
 	}
 	i.Reserved -= quantity
-	i.Available = i.Quantity - i.Reserved
+	i.RefreshAvailable()
 	return nil
 }
 
@@ -77,6 +106,47 @@ func (i *Inventory) Fulfill(quantity int) error {
 	}
 	i.Reserved -= quantity
 	i.Quantity -= quantity
-	i.Available = i.Quantity - i.Reserved
+	i.RefreshAvailable()
 	return nil
 }
+
+// CompareLocations orders bin locations along the pick path: numbers within a location
+// compare by value, so aisle A-2 comes before A-10 whether or not they are zero-padded,
+// and the rest compares as text. It returns -1, 0 or +1.
+func CompareLocations(a, b string) int {
+	for a != "" && b != "" {
+		var chunkA, chunkB string
+		chunkA, a = nextLocationChunk(a)
+		chunkB, b = nextLocationChunk(b)
+
+		if isDigit(chunkA[0]) && isDigit(chunkB[0]) {
+			numberA, numberB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			if len(numberA) != len(numberB) {
+				if len(numberA) < len(numberB) {
+					return -1
+				}
+				return 1
+			}
+			chunkA, chunkB = numberA, numberB
+		}
+		if c := strings.Compare(chunkA, chunkB); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// nextLocationChunk splits a location into its leading run of digits or non-digits
+// and the rest
+func nextLocationChunk(location string) (string, string) {
+	digits := isDigit(location[0])
+	end := 1
+	for end < len(location) && isDigit(location[end]) == digits {
+		end++
+	}
+	return location[:end], location[end:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
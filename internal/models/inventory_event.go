@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// InventoryEventType identifies how an inventory change moved stock
+type InventoryEventType string
+
+const (
+	InventoryEventReserved  InventoryEventType = "reserved"
+	InventoryEventReleased  InventoryEventType = "released"
+	InventoryEventAdjusted  InventoryEventType = "adjusted"
+	InventoryEventFulfilled InventoryEventType = "fulfilled"
+	InventoryEventCounted   InventoryEventType = "counted" // Stock set to the quantity found by a recount
+	// Units held back from online sales changed, by Quantity; on hand stock is unchanged
+	InventoryEventSafetyStock InventoryEventType = "safety_stock"
+)
+
+// InventoryReferenceType identifies what an inventory change was made for
+type InventoryReferenceType string
+
+const (
+	InventoryReferenceOrder       InventoryReferenceType = "order"
+	InventoryReferenceStockHold   InventoryReferenceType = "stock_hold"
+	InventoryReferenceRecount     InventoryReferenceType = "recount"
+	InventoryReferenceStockImport InventoryReferenceType = "stock_import"
+)
+
+// InventoryEvent is an append-only record of a change to a product's inventory,
+// written in the same transaction as the change. Sequence orders events across
+// all products and is the cursor of the inventory change stream.
+type InventoryEvent struct {
+	Sequence  int64              `gorm:"primaryKey;autoIncrement" json:"sequence"`
+	ProductID string             `gorm:"type:uuid;not null;index" json:"product_id"`
+	Type      InventoryEventType `gorm:"type:varchar(20);not null" json:"type"`
+	Quantity  int                `gorm:"not null" json:"quantity"` // Units moved; negative when an adjustment lowers stock
+
+	// Stock levels and inventory version after the change
+	OnHand    int `gorm:"not null" json:"on_hand"`
+	Reserved  int `gorm:"not null" json:"reserved"`
+	Available int `gorm:"not null" json:"available"`
+	Version   int `gorm:"not null" json:"version"`
+
+	// Who made the change and what for, when known
+	ActorID       *string                `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ReferenceType InventoryReferenceType `gorm:"type:varchar(20)" json:"reference_type,omitempty"`
+	ReferenceID   string                 `gorm:"size:255" json:"reference_id,omitempty"`
+
+	// Set by the database to the start of the writing transaction
+	RecordedAt time.Time `gorm:"not null;default:now()" json:"recorded_at"`
+}
+
+// TableName returns the table name for InventoryEvent model
+func (InventoryEvent) TableName() string {
+	return "inventory_events"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// MaxInventoryShards bounds how many shards a product's stock can be split into
+const MaxInventoryShards = 64
+
+// InventoryShard is a slice of a hot product's sellable stock, reserved on its own row
+// so concurrent orders of the product lock different rows. The units of a product's
+// shards are counted as reserved on its inventory row until they are reserved for an
+// order or collapsed back into it; Available is what the shard can still reserve.
+type InventoryShard struct {
+	ProductID string    `gorm:"type:uuid;primaryKey" json:"product_id"`
+	Shard     int       `gorm:"primaryKey;autoIncrement:false" json:"shard"`
+	Available int       `gorm:"not null;default:0" json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for InventoryShard model
+func (InventoryShard) TableName() string {
+	return "inventory_shards"
+}
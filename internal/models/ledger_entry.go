@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// LedgerTransactionType identifies the money movement a ledger transaction records
+type LedgerTransactionType string
+
+const (
+	LedgerTransactionPayment             LedgerTransactionType = "payment"
+	LedgerTransactionRefund              LedgerTransactionType = "refund"
+	LedgerTransactionFee                 LedgerTransactionType = "fee"
+	LedgerTransactionGiftCardRedemption  LedgerTransactionType = "gift_card_redemption"
+	LedgerTransactionStoreCreditIssued   LedgerTransactionType = "store_credit_issued"
+	LedgerTransactionStoreCreditRedeemed LedgerTransactionType = "store_credit_redeemed"
+)
+
+// LedgerDirection is the side of an account a ledger entry posts to
+type LedgerDirection string
+
+const (
+	LedgerDebit  LedgerDirection = "debit"
+	LedgerCredit LedgerDirection = "credit"
+)
+
+// Ledger accounts. Gateway clearing and customer accounts are kept per gateway and
+// per customer, named "<account>:<gateway or user ID>".
+const (
+	LedgerAccountGatewayClearing = "gateway_clearing" // Captured funds the gateway has yet to pay out
+	LedgerAccountRevenue         = "revenue"          // Sales, net of refunds
+	LedgerAccountProcessingFees  = "processing_fees"  // Gateway fees charged on payments
+	LedgerAccountGiftCards       = "gift_cards"       // Outstanding gift card balances
+	LedgerAccountCustomer        = "customer"         // Store credit owed to a customer
+)
+
+// GatewayClearingAccount returns the clearing account of a payment gateway
+func GatewayClearingAccount(gateway string) string {
+	return LedgerAccountGatewayClearing + ":" + gateway
+}
+
+// CustomerAccount returns the store credit account of a customer
+func CustomerAccount(userID string) string {
+	return LedgerAccountCustomer + ":" + userID
+}
+
+// LedgerNormalBalance returns the side on which an account's balance grows: debit
+// for the clearing and fee accounts, credit for revenue and what is owed to customers
+func LedgerNormalBalance(account string) LedgerDirection {
+	kind, _, _ := strings.Cut(account, ":")
+	switch kind {
+	case LedgerAccountGatewayClearing, LedgerAccountProcessingFees:
+		return LedgerDebit
+	default:
+		return LedgerCredit
+	}
+}
+
+// LedgerEntry is one line of a double-entry ledger transaction. The lines of a
+// transaction share its TransactionKey, which names the movement it records (such as
+// "payment:<payment ID>") so that it is posted once, and their debits equal their
+// credits. Entries are append-only: a mistake is corrected by a reversing transaction.
+type LedgerEntry struct {
+	Sequence       int64                 `gorm:"primaryKey;autoIncrement" json:"sequence"`
+	TransactionKey string                `gorm:"type:varchar(255);not null;uniqueIndex:idx_ledger_entries_transaction_line" json:"transaction_key"`
+	Line           int                   `gorm:"not null;uniqueIndex:idx_ledger_entries_transaction_line" json:"line"`
+	Type           LedgerTransactionType `gorm:"type:varchar(30);not null" json:"type"`
+	Account        string                `gorm:"type:varchar(120);not null;index" json:"account"`
+	Direction      LedgerDirection       `gorm:"type:varchar(6);not null" json:"direction"`
+	Amount         float64               `gorm:"type:decimal(12,2);not null" json:"amount"` // Always positive; Direction gives the side
+	Currency       string                `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+
+	// What the transaction is about; empty when it has no order or payment
+	OrderID   string `gorm:"type:varchar(36);index" json:"order_id,omitempty"`
+	PaymentID string `gorm:"type:varchar(36);index" json:"payment_id,omitempty"`
+	Reference string `gorm:"type:varchar(255)" json:"reference,omitempty"` // Gift card, or the source of a store credit movement
+
+	// Set by the database to the start of the writing transaction
+	RecordedAt time.Time `gorm:"not null;default:now()" json:"recorded_at"`
+}
+
+// TableName returns the table name for LedgerEntry model
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
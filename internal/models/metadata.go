@@ -0,0 +1,143 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Metadata holds integrator-defined key/value pairs stored in a JSONB column
+type Metadata map[string]string
+
+// Value implements driver.Valuer so Metadata can be persisted as JSONB
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner so Metadata can be read from JSONB
+func (m *Metadata) Scan(value interface{}) error {
+	if value == nil {
+		*m = Metadata{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Metadata", value)
+	}
+
+	result := Metadata{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+	}
+	*m = result
+	return nil
+}
+
+// Get returns the value for a key and whether it was present
+func (m Metadata) Get(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// GetOrDefault returns the value for a key or the fallback if missing
+func (m Metadata) GetOrDefault(key, fallback string) string {
+	if value, ok := m[key]; ok {
+		return value
+	}
+	return fallback
+}
+
+// Set assigns a value to a key, initializing the map if needed
+func (m *Metadata) Set(key, value string) {
+	if *m == nil {
+		*m = Metadata{}
+	}
+	(*m)[key] = value
+}
+
+// Delete removes a key from the metadata
+func (m Metadata) Delete(key string) {
+	delete(m, key)
+}
+
+// Merge applies the given updates; empty values remove the key
+func (m *Metadata) Merge(updates map[string]string) {
+	for key, value := range updates {
+		if value == "" {
+			m.Delete(key)
+			continue
+		}
+		m.Set(key, value)
+	}
+}
+
+// metadataKeyPattern restricts keys to simple identifiers usable in JSONB filters
+var metadataKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]{0,63}$`)
+
+// MetadataSchema defines validation rules for an entity's metadata
+type MetadataSchema struct {
+	Entity         string
+	MaxKeys        int
+	MaxValueLength int
+	ReservedKeys   []string
+}
+
+// OrderMetadataSchema defines the metadata rules for orders
+var OrderMetadataSchema = MetadataSchema{
+	Entity:         "order",
+	MaxKeys:        20,
+	MaxValueLength: 255,
+	ReservedKeys:   []string{"id", "user_id", "status"},
+}
+
+// ProductMetadataSchema defines the metadata rules for products
+var ProductMetadataSchema = MetadataSchema{
+	Entity:         "product",
+	MaxKeys:        30,
+	MaxValueLength: 500,
+	ReservedKeys:   []string{"id", "sku"},
+}
+
+// IsValidMetadataKey reports whether a key can be stored and filtered on
+func IsValidMetadataKey(key string) bool {
+	return metadataKeyPattern.MatchString(key)
+}
+
+// Validate checks metadata against the schema and returns the first violation
+func (s MetadataSchema) Validate(metadata map[string]string) error {
+	if len(metadata) > s.MaxKeys {
+		return fmt.Errorf("%s metadata cannot have more than %d keys", s.Entity, s.MaxKeys)
+	}
+
+	for key, value := range metadata {
+		if !IsValidMetadataKey(key) {
+			return fmt.Errorf("invalid %s metadata key %q", s.Entity, key)
+		}
+		for _, reserved := range s.ReservedKeys {
+			if key == reserved {
+				return fmt.Errorf("%s metadata key %q is reserved", s.Entity, key)
+			}
+		}
+		if len(value) > s.MaxValueLength {
+			return fmt.Errorf("%s metadata value for %q exceeds %d characters", s.Entity, key, s.MaxValueLength)
+		}
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 // AllModels returns all model structs for migration
 func AllModels() []interface{} {
 	return []interface{}{
+		&Organization{},
 		&User{},
 		&Product{},
 		&Inventory{},
@@ -15,6 +16,54 @@ func AllModels() []interface{} {
 		&Payment{},
 		&Notification{},
 		&AuditLog{},
+		&WebhookEvent{},
+		&StockWebhookSubscription{},
+		&ChannelListing{},
+		&ActivityEvent{},
+		&StockHold{},
+		&PaymentAttempt{},
+		&ProductAvailability{},
+		&Address{},
+		&SavedPaymentMethod{},
+		&InventoryEvent{},
+		&OrderEvent{},
+		&LedgerEntry{},
+		&PaymentProof{},
+		&OrderHold{},
+		&WebhookEndpoint{},
+		&WebhookDelivery{},
+		&WebhookRelayCursor{},
+		&ReportResult{},
+		&APIKey{},
+		&APIUsage{},
+		&ReferralCode{},
+		&Referral{},
+		&StoreCreditTransaction{},
+		&BlocklistEntry{},
+		&PriceList{},
+		&PriceListItem{},
+		&Promotion{},
+		&OrderPromotion{},
+		&DeliverySlot{},
+		&Refund{},
+		&IdempotencyRecord{},
+		&PaymentReconciliation{},
+		&PaymentRoutingRule{},
+		&FulfillmentTask{},
+		&OrderEventCursor{},
+		&BackgroundJob{},
+		&DeadJob{},
+		&CloseReportRun{},
+		&CheckoutSaga{},
+		&InventoryShard{},
+		&PaymentIntent{},
+		&ProductSerial{},
+		&WarrantyClaim{},
+		&NotificationExperiment{},
+		&StockImport{},
+		&StockImportRow{},
+		&CatalogEvent{},
+		&CatalogEventCursor{},
 	}
 }
 
@@ -67,8 +116,67 @@ func CreateIndexes(db *gorm.DB) error {
 		return err
 	}
 
-	// Inventory: low stock alerts
-	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_inventory_low_stock ON inventory (available, min_stock) WHERE available <= min_stock").Error; err != nil {
+	// Inventory indexes are built concurrently by the online migrations, so that
+	// startup never blocks stock reservations
+
+	// Metadata: GIN indexes for key/value containment filters
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_metadata ON orders USING GIN (metadata jsonb_path_ops)").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_products_metadata ON products USING GIN (metadata jsonb_path_ops)").Error; err != nil {
+		return err
+	}
+
+	// Orders: a channel order can only be imported once
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_channel_external ON orders (channel, external_order_id) WHERE external_order_id <> ''").Error; err != nil {
+		return err
+	}
+
+	// Orders: keyset cursor for streaming exports
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_created_id ON orders (created_at DESC, id DESC)").Error; err != nil {
+		return err
+	}
+
+	// Activity events: range scans for the user activity report
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_activity_events_created_type ON activity_events (created_at, type, user_id)").Error; err != nil {
+		return err
+	}
+
+	// Stock holds: one active hold per cart item, and expiry sweeps
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_holds_active_user_product ON stock_holds (user_id, product_id) WHERE status = 'active'").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_stock_holds_active_expires ON stock_holds (expires_at) WHERE status = 'active'").Error; err != nil {
+		return err
+	}
+
+	// Orders: an express checkout key places one order per user
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_user_idempotency_key ON orders (user_id, idempotency_key) WHERE idempotency_key <> ''").Error; err != nil {
+		return err
+	}
+
+	// Addresses and saved payment methods: at most one default per user
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_addresses_user_default ON addresses (user_id) WHERE is_default").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_payment_methods_user_default ON saved_payment_methods (user_id) WHERE is_default").Error; err != nil {
+		return err
+	}
+
+	// Order holds: at most one active hold per order, and SLA sweeps of active holds
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_order_holds_active_order ON order_holds (order_id) WHERE released_at IS NULL").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_order_holds_active_due ON order_holds (due_at) WHERE released_at IS NULL").Error; err != nil {
+		return err
+	}
+
+	// Webhook deliveries: the delivery sweep picks pending deliveries that are due
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending'").Error; err != nil {
 		return err
 	}
 
@@ -107,20 +215,8 @@ func CreateConstraints(db *gorm.DB) error {
 		return err
 	}
 
-	// Ensure available quantity is calculated correctly
-	if err := db.Exec(`
-		DO $$
-		BEGIN
-			IF NOT EXISTS (
-				SELECT 1 FROM pg_constraint WHERE conname = 'chk_inventory_available'
-			) THEN
-				ALTER TABLE inventory ADD CONSTRAINT chk_inventory_available
-				CHECK (available = quantity - reserved);
-			END IF;
-		END $$;
-	`).Error; err != nil {
-		return err
-	}
+	// The check that available quantity is calculated correctly allows for safety stock,
+	// and is added by the online migrations with the column
 
 	// Ensure order item quantities are positive
 	if err := db.Exec(`
@@ -182,5 +278,34 @@ func CreateConstraints(db *gorm.DB) error {
 		return err
 	}
 
+	// Event tables are append-only: change feed cursors rely on events never changing,
+	// and the ledger is corrected by reversing transactions rather than edits
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION reject_event_changes() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+		END;
+		$$ LANGUAGE plpgsql
+	`).Error; err != nil {
+		return err
+	}
+
+	for _, table := range []string{"inventory_events", "order_events", "ledger_entries"} {
+		if err := db.Exec(`
+			DO $$
+			BEGIN
+				IF NOT EXISTS (
+					SELECT 1 FROM pg_trigger WHERE tgname = 'trg_` + table + `_append_only'
+				) THEN
+					CREATE TRIGGER trg_` + table + `_append_only
+					BEFORE UPDATE OR DELETE ON ` + table + `
+					FOR EACH ROW EXECUTE FUNCTION reject_event_changes();
+				END IF;
+			END $$;
+		`).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -11,15 +11,20 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeOrderConfirmed NotificationType = "order_confirmed"
-	NotificationTypeOrderShipped   NotificationType = "order_shipped"
-	NotificationTypeOrderDelivered NotificationType = "order_delivered"
-	NotificationTypeOrderCancelled NotificationType = "order_cancelled"
-	NotificationTypePaymentSuccess NotificationType = "payment_success"
-	NotificationTypePaymentFailed  NotificationType = "payment_failed"
-	NotificationTypeLowStock       NotificationType = "low_stock"
-	NotificationTypePromotion      NotificationType = "promotion"
-	NotificationTypeSystem         NotificationType = "system"
+	NotificationTypeOrderConfirmed      NotificationType = "order_confirmed"
+	NotificationTypeOrderShipped        NotificationType = "order_shipped"
+	NotificationTypeOrderDelivered      NotificationType = "order_delivered"
+	NotificationTypeOrderCancelled      NotificationType = "order_cancelled"
+	NotificationTypeOrderItemsCancelled NotificationType = "order_items_cancelled"
+	NotificationTypeOrderRefunded       NotificationType = "order_refunded"
+	NotificationTypePaymentSuccess      NotificationType = "payment_success"
+	NotificationTypePaymentFailed       NotificationType = "payment_failed"
+	NotificationTypeLowStock            NotificationType = "low_stock"
+	NotificationTypePromotion           NotificationType = "promotion"
+	NotificationTypeSystem              NotificationType = "system"
+	NotificationTypeCloseReport         NotificationType = "close_report"
+	NotificationTypeReportCompleted     NotificationType = "report_completed"
+	NotificationTypeDigest              NotificationType = "digest"
 )
 
 // NotificationChannel defines how the notification should be sent
@@ -47,6 +52,20 @@ type Notification struct {
 	CreatedAt time.Time           `json:"created_at"`
 	UpdatedAt time.Time           `json:"updated_at"`
 
+	// DeliveredAt is when the channel confirmed the notification reached the user.
+	// In-app notifications are delivered once sent; other channels report delivery
+	// through receipts.
+	DeliveredAt *time.Time `json:"delivered_at"`
+
+	// DeliveredChannel is the channel that delivered the notification, which differs
+	// from Channel when delivery failed over to a fallback channel
+	DeliveredChannel NotificationChannel `gorm:"type:varchar(20);index" json:"delivered_channel,omitempty"`
+
+	// ExperimentID and Variant are the running wording experiment the notification was
+	// sent in and the variant it was worded by
+	ExperimentID *string `gorm:"type:uuid;index" json:"experiment_id,omitempty"`
+	Variant      string  `gorm:"type:varchar(1)" json:"variant,omitempty"`
+
 	// Relationships
 	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
 }
@@ -64,12 +83,16 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
-// MarkAsRead marks the notification as read
+// MarkAsRead marks the notification as read. A notification that was read was
+// delivered, even when its delivery receipt never arrived.
 func (n *Notification) MarkAsRead() {
 	if !n.Read {
 		n.Read = true
 		now := time.Now()
 		n.ReadAt = &now
+		if n.DeliveredAt == nil {
+			n.DeliveredAt = &now
+		}
 	}
 }
 
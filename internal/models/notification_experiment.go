@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationExperimentStatus is where an A/B test of notification wording stands
+type NotificationExperimentStatus string
+
+const (
+	NotificationExperimentStatusRunning   NotificationExperimentStatus = "running"   // Notifications are split between the variants
+	NotificationExperimentStatusConcluded NotificationExperimentStatus = "concluded" // The winning variant is sent to everyone
+	NotificationExperimentStatusStopped   NotificationExperimentStatus = "stopped"   // The catalog wording is sent again
+)
+
+// NotificationConversionGoal is what a notified user does that counts as a conversion
+type NotificationConversionGoal string
+
+const (
+	// NotificationConversionPayment is a completed payment, of the notification's order
+	// when it has one
+	NotificationConversionPayment NotificationConversionGoal = "payment"
+	// NotificationConversionOrder is a new order placed by the user
+	NotificationConversionOrder NotificationConversionGoal = "order"
+)
+
+// Notification experiment variants
+const (
+	NotificationVariantA = "a"
+	NotificationVariantB = "b"
+)
+
+// NotificationExperiment is an A/B test of the wording of one notification type in one
+// locale. While it runs, each user is assigned a variant for good, SplitPercent of
+// users getting variant B, and their notifications of the type are worded by its
+// templates and tagged with it. Templates take the notification data's values as
+// {name} placeholders, like the message catalogs. A concluded experiment keeps
+// sending its winner's wording until a newer experiment of the type starts.
+type NotificationExperiment struct {
+	ID                    string                       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name                  string                       `gorm:"not null;size:255" json:"name"`
+	Type                  NotificationType             `gorm:"type:varchar(30);not null;index" json:"type"`
+	Locale                string                       `gorm:"type:varchar(10);not null;default:'en'" json:"locale"`
+	VariantATitle         string                       `gorm:"not null;size:255" json:"variant_a_title"`
+	VariantABody          string                       `gorm:"type:text;not null" json:"variant_a_body"`
+	VariantBTitle         string                       `gorm:"not null;size:255" json:"variant_b_title"`
+	VariantBBody          string                       `gorm:"type:text;not null" json:"variant_b_body"`
+	SplitPercent          int                          `gorm:"not null" json:"split_percent"`
+	ConversionGoal        NotificationConversionGoal   `gorm:"type:varchar(20);not null" json:"conversion_goal"`
+	ConversionWindowHours int                          `gorm:"not null" json:"conversion_window_hours"`
+	Status                NotificationExperimentStatus `gorm:"type:varchar(20);not null;default:'running';index" json:"status"`
+	Winner                string                       `gorm:"type:varchar(1)" json:"winner,omitempty"`
+	CreatedBy             string                       `gorm:"type:uuid;not null" json:"created_by"`
+	StartedAt             time.Time                    `gorm:"not null" json:"started_at"`
+	EndedAt               *time.Time                   `json:"ended_at,omitempty"`
+	CreatedAt             time.Time                    `json:"created_at"`
+	UpdatedAt             time.Time                    `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (e *NotificationExperiment) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for NotificationExperiment model
+func (NotificationExperiment) TableName() string {
+	return "notification_experiments"
+}
+
+// AssignVariant returns the variant a user is sent. The assignment hashes the
+// experiment and user, so a user keeps their variant for the whole experiment.
+func (e *NotificationExperiment) AssignVariant(userID string) string {
+	hash := fnv.New32a()
+	_, _ = fmt.Fprintf(hash, "%s:%s", e.ID, userID)
+	if int(hash.Sum32()%100) < e.SplitPercent {
+		return NotificationVariantB
+	}
+	return NotificationVariantA
+}
+
+// Template returns the title and body templates of a variant
+func (e *NotificationExperiment) Template(variant string) (string, string) {
+	if variant == NotificationVariantB {
+		return e.VariantBTitle, e.VariantBBody
+	}
+	return e.VariantATitle, e.VariantABody
+}
+
+// ConversionWindow is how long after a notification its user's conversion counts
+func (e *NotificationExperiment) ConversionWindow() time.Duration {
+	return time.Duration(e.ConversionWindowHours) * time.Hour
+}
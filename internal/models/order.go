@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,15 +23,84 @@ const (
 
 // Order represents an order in the system
 type Order struct {
-	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID      string         `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
-	Status      OrderStatus    `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
-	TotalAmount float64        `gorm:"type:decimal(10,2);not null" json:"total_amount" validate:"gte=0"`
-	Currency    string         `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
-	Notes       string         `gorm:"type:text" json:"notes"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID          string         `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
+	Status          OrderStatus    `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	TotalAmount     float64        `gorm:"type:decimal(10,2);not null" json:"total_amount" validate:"gte=0"`
+	Currency        string         `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Notes           string         `gorm:"type:text" json:"notes"`
+	Metadata        Metadata       `gorm:"type:jsonb;not null;default:'{}'" json:"metadata,omitempty"`
+	Channel         string         `gorm:"type:varchar(50);not null;default:'direct';index" json:"channel"`
+	ExternalOrderID string         `gorm:"type:varchar(255)" json:"external_order_id,omitempty"`
+	PlacedBy        *string        `gorm:"type:uuid;index" json:"placed_by,omitempty"` // Agent who placed a phone order for the customer
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Checkout payment method; PaymentAdjustment is the surcharge (positive) or discount
+	// (negative) included in TotalAmount, at PaymentAdjustmentRate percent of the subtotal
+	PaymentMethod         PaymentMethod `gorm:"type:varchar(20)" json:"payment_method,omitempty"`
+	PaymentAdjustmentRate float64       `gorm:"type:decimal(6,3);not null;default:0" json:"payment_adjustment_rate"`
+	PaymentAdjustment     float64       `gorm:"type:decimal(10,2);not null;default:0" json:"payment_adjustment"`
+
+	// Promotions applied at checkout took DiscountAmount off the items, before the
+	// payment method adjustment; ShippingFee is the shipping charged after any waiver.
+	// Both are included in TotalAmount and kept when items are repriced; cancelled items
+	// take their share of the discount by price with them.
+	DiscountAmount float64 `gorm:"type:decimal(10,2);not null;default:0" json:"discount_amount"`
+	ShippingFee    float64 `gorm:"type:decimal(10,2);not null;default:0" json:"shipping_fee"`
+
+	// Sum of the order's completed payments, which may each cover part of TotalAmount.
+	// It is added by an online migration and only written by refreshing it from the
+	// payments, see OrderRepository.RefreshPaidAmount.
+	PaidAmount float64 `gorm:"->;-:migration" json:"paid_amount"`
+
+	// Organization of the user who placed the order, whose members share its visibility.
+	// On-account orders are invoiced to the organization and due by InvoiceDueAt.
+	OrganizationID *string    `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	OnAccount      bool       `gorm:"not null;default:false" json:"on_account"`
+	InvoiceDueAt   *time.Time `json:"invoice_due_at,omitempty"`
+
+	// On-account orders over the organization's credit limit are held until an admin
+	// approves (overriding the limit) or rejects them
+	CreditHold       bool       `gorm:"not null;default:false;index" json:"credit_hold"`
+	CreditReviewedBy *string    `gorm:"type:uuid" json:"credit_reviewed_by,omitempty"`
+	CreditReviewedAt *time.Time `json:"credit_reviewed_at,omitempty"`
+
+	// Orders on a compliance hold, such as a fraud or export control review, cannot ship
+	// until the hold is released; see OrderHold
+	OnHold bool `gorm:"not null;default:false;index" json:"on_hold"`
+
+	// Fraud risk score from 0 to MaxRiskScore and the signals that raised it, taken when
+	// the order is placed and again when an admin rescores it; nil until scored
+	RiskScore    *int         `gorm:"index" json:"risk_score,omitempty"`
+	RiskSignals  []RiskSignal `gorm:"type:jsonb;serializer:json" json:"risk_signals,omitempty"`
+	RiskScoredAt *time.Time   `json:"risk_scored_at,omitempty"`
+
+	// When the store accepted the paid order and queued it for fulfillment, right after
+	// payment or, for stores requiring manual confirmation, when an admin confirmed it
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+
+	// End of the day the store promised to ship the order by, from its hours and same-day
+	// shipping cutoff when the order was placed; nil for stores without hours
+	PromisedShipBy *time.Time `gorm:"index" json:"promised_ship_by,omitempty"`
+
+	// Copy of the address the order ships to, taken when the order was placed
+	ShippingAddress *ShippingAddress `gorm:"type:jsonb;serializer:json" json:"shipping_address,omitempty"`
+
+	// Delivery slot chosen at checkout, whose capacity the order holds until cancelled.
+	// It is added by an online migration.
+	DeliverySlotID *string `gorm:"type:uuid;-:migration" json:"delivery_slot_id,omitempty"`
+
+	// Gift orders ship with the gift message; with GiftHidePrices the packing slip in the
+	// parcel leaves out prices
+	IsGift         bool   `gorm:"not null;default:false" json:"is_gift"`
+	GiftMessage    string `gorm:"type:varchar(500)" json:"gift_message,omitempty"`
+	GiftHidePrices bool   `gorm:"not null;default:false" json:"gift_hide_prices"`
+
+	// Client-chosen key of an express checkout; retries with the same key return this
+	// order instead of placing another one
+	IdempotencyKey string `gorm:"type:varchar(255)" json:"-"`
 
 	// Relationships
 	User      *User       `gorm:"foreignKey:UserID;constraint:OnDelete:RESTRICT" json:"user,omitempty"`
@@ -52,6 +122,22 @@ func (Order) TableName() string {
 	return "orders"
 }
 
+// Subtotal returns the order total before the payment method adjustment and shipping,
+// which is what the adjustment rate applies to
+func (o *Order) Subtotal() float64 {
+	return o.TotalAmount - o.PaymentAdjustment - o.ShippingFee
+}
+
+// BalanceDue returns what is left to pay of the order total after its completed payments
+func (o *Order) BalanceDue() float64 {
+	return math.Max(math.Round((o.TotalAmount-o.PaidAmount)*100)/100, 0)
+}
+
+// IsFullyPaid returns true if the order's completed payments cover its total
+func (o *Order) IsFullyPaid() bool {
+	return o.BalanceDue() == 0
+}
+
 // IsPending returns true if order is in pending status
 func (o *Order) IsPending() bool {
 	return o.Status == OrderStatusPending
@@ -67,9 +153,44 @@ func (o *Order) IsPaid() bool {
 	return o.Status == OrderStatusPaid
 }
 
-// IsCancellable returns true if order can be cancelled
+// IsAwaitingConfirmation returns true if the order is paid but the store has not
+// confirmed it for fulfillment yet
+func (o *Order) IsAwaitingConfirmation() bool {
+	return o.Status == OrderStatusPaid && o.ConfirmedAt == nil
+}
+
+// IsPayable returns true if a payment can be taken for the order. On-account
+// invoices can still be paid after the order has shipped, but not while on credit hold.
+func (o *Order) IsPayable() bool {
+	if o.CreditHold {
+		return false
+	}
+	switch o.Status {
+	case OrderStatusPending, OrderStatusConfirmed:
+		return true
+	case OrderStatusShipped, OrderStatusDelivered:
+		return o.OnAccount
+	default:
+		return false
+	}
+}
+
+// IsCancellable returns true if order can be cancelled: it has not shipped. Paid
+// orders are refunded when cancelled.
 func (o *Order) IsCancellable() bool {
-	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed
+	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed || o.Status == OrderStatusPaid
+}
+
+// IsHoldable returns true if the order can be put on a compliance hold: it has not
+// shipped, been cancelled or failed
+func (o *Order) IsHoldable() bool {
+	return o.Status == OrderStatusPending || o.Status == OrderStatusConfirmed || o.Status == OrderStatusPaid
+}
+
+// IsPickable returns true if the order is ready to be picked and packed: confirmed
+// or paid, and not held for credit or compliance review
+func (o *Order) IsPickable() bool {
+	return (o.Status == OrderStatusConfirmed || o.Status == OrderStatusPaid) && !o.CreditHold && !o.OnHold
 }
 
 // IsCompletable returns true if order can be marked as completed
@@ -79,11 +200,21 @@ func (o *Order) IsCompletable() bool {
 
 // CanTransitionTo checks if order can transition to the given status
 func (o *Order) CanTransitionTo(newStatus OrderStatus) bool {
+	// Orders on credit hold can only be abandoned until the hold is reviewed
+	if o.CreditHold {
+		return o.Status == OrderStatusPending && (newStatus == OrderStatusCancelled || newStatus == OrderStatusFailed)
+	}
+	// Orders on a compliance hold are not fulfilled until the hold is released
+	if o.OnHold && (newStatus == OrderStatusShipped || newStatus == OrderStatusDelivered) {
+		return false
+	}
 	switch o.Status {
 	case OrderStatusPending:
 		return newStatus == OrderStatusConfirmed || newStatus == OrderStatusCancelled || newStatus == OrderStatusFailed
 	case OrderStatusConfirmed:
-		return newStatus == OrderStatusPaid || newStatus == OrderStatusCancelled
+		// On-account orders ship before their invoice is paid
+		return newStatus == OrderStatusPaid || newStatus == OrderStatusCancelled ||
+			(o.OnAccount && newStatus == OrderStatusShipped)
 	case OrderStatusPaid:
 		return newStatus == OrderStatusShipped || newStatus == OrderStatusCancelled
 	case OrderStatusShipped:
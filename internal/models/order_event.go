@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OrderEventType identifies a step in an order's lifecycle. Payment completed events
+// are recorded when completed payments raise what was paid for an order.
+type OrderEventType string
+
+const (
+	OrderEventCreated          OrderEventType = "order.created"
+	OrderEventStatusChanged    OrderEventType = "order.status_changed"
+	OrderEventCreditReviewed   OrderEventType = "order.credit_reviewed"
+	OrderEventUpdated          OrderEventType = "order.updated"
+	OrderEventHoldPlaced       OrderEventType = "order.hold_placed"
+	OrderEventHoldReleased     OrderEventType = "order.hold_released"
+	OrderEventPaymentCompleted OrderEventType = "order.payment_completed"
+)
+
+// OrderEventPayloadVersion is the version of OrderEventPayload written with new
+// events. Bump it whenever a field is renamed, removed or changes meaning.
+const OrderEventPayloadVersion = 1
+
+// OrderEvent is an append-only record of an order lifecycle step, written in the
+// same transaction as the change. Sequence orders events across all orders and is
+// the cursor of the order change stream. Payload is kept as written, in the shape
+// of its PayloadVersion.
+type OrderEvent struct {
+	Sequence       int64           `gorm:"primaryKey;autoIncrement" json:"sequence"`
+	OrderID        string          `gorm:"type:uuid;not null" json:"order_id"`
+	Type           OrderEventType  `gorm:"type:varchar(40);not null" json:"type"`
+	PayloadVersion int             `gorm:"not null" json:"payload_version"`
+	Payload        json.RawMessage `gorm:"type:jsonb;serializer:json;not null" json:"payload"`
+
+	// Set by the database to the start of the writing transaction
+	RecordedAt time.Time `gorm:"not null;default:now()" json:"recorded_at"`
+}
+
+// TableName returns the table name for OrderEvent model
+func (OrderEvent) TableName() string {
+	return "order_events"
+}
+
+// OrderEventPayload is the order as it was after a lifecycle step. PreviousStatus is
+// set when the step changed the order status.
+type OrderEventPayload struct {
+	OrderID         string      `json:"order_id"`
+	UserID          string      `json:"user_id"`
+	OrganizationID  *string     `json:"organization_id,omitempty"`
+	Status          OrderStatus `json:"status"`
+	PreviousStatus  OrderStatus `json:"previous_status,omitempty"`
+	TotalAmount     float64     `json:"total_amount"`
+	PaidAmount      float64     `json:"paid_amount"`
+	Currency        string      `json:"currency"`
+	Channel         string      `json:"channel"`
+	ExternalOrderID string      `json:"external_order_id,omitempty"`
+	OnAccount       bool        `json:"on_account"`
+	CreditHold      bool        `json:"credit_hold"`
+	OnHold          bool        `json:"on_hold"`
+	PromisedShipBy  *time.Time  `json:"promised_ship_by,omitempty"`
+	Metadata        Metadata    `json:"metadata,omitempty"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// NewOrderEventPayload returns the event payload of an order
+func NewOrderEventPayload(order *Order, previousStatus OrderStatus) OrderEventPayload {
+	payload := OrderEventPayload{
+		OrderID:         order.ID,
+		UserID:          order.UserID,
+		OrganizationID:  order.OrganizationID,
+		Status:          order.Status,
+		TotalAmount:     order.TotalAmount,
+		PaidAmount:      order.PaidAmount,
+		Currency:        order.Currency,
+		Channel:         order.Channel,
+		ExternalOrderID: order.ExternalOrderID,
+		OnAccount:       order.OnAccount,
+		CreditHold:      order.CreditHold,
+		OnHold:          order.OnHold,
+		PromisedShipBy:  order.PromisedShipBy,
+		Metadata:        order.Metadata,
+		UpdatedAt:       order.UpdatedAt,
+	}
+	if previousStatus != order.Status {
+		payload.PreviousStatus = previousStatus
+	}
+	return payload
+}
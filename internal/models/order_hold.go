@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderHoldReason is why an order was put on a compliance hold
+type OrderHoldReason string
+
+const (
+	OrderHoldReasonFraud         OrderHoldReason = "fraud"
+	OrderHoldReasonExportControl OrderHoldReason = "export_control"
+	OrderHoldReasonAddressIssue  OrderHoldReason = "address_issue"
+)
+
+// OrderHoldReasons lists the reasons an order can be held for
+var OrderHoldReasons = []OrderHoldReason{
+	OrderHoldReasonFraud,
+	OrderHoldReasonExportControl,
+	OrderHoldReasonAddressIssue,
+}
+
+// OrderHold is a compliance hold on an order. While the hold is active (not released)
+// the order is flagged OnHold and cannot ship; an order has at most one active hold.
+// Holds are assigned to a reviewer and should be released by DueAt, the end of the
+// hold SLA. Released holds are kept as the order's hold history.
+type OrderHold struct {
+	ID          string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     string          `gorm:"type:uuid;not null;index" json:"order_id"`
+	Reason      OrderHoldReason `gorm:"type:varchar(30);not null;index" json:"reason"`
+	Note        string          `gorm:"type:text" json:"note,omitempty"`
+	PlacedBy    string          `gorm:"type:uuid;not null" json:"placed_by"`
+	AssignedTo  *string         `gorm:"type:uuid;index" json:"assigned_to,omitempty"`
+	AssignedAt  *time.Time      `json:"assigned_at,omitempty"`
+	DueAt       time.Time       `gorm:"not null" json:"due_at"`
+	ReleasedBy  *string         `gorm:"type:uuid" json:"released_by,omitempty"`
+	ReleasedAt  *time.Time      `gorm:"index" json:"released_at,omitempty"`
+	ReleaseNote string          `gorm:"type:text" json:"release_note,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+
+	// Relationships
+	Order *Order `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"order,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (h *OrderHold) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for OrderHold model
+func (OrderHold) TableName() string {
+	return "order_holds"
+}
+
+// IsActive returns true if the hold has not been released
+func (h *OrderHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
+
+// IsOverdue returns true if the hold is still active past its SLA at t
+func (h *OrderHold) IsOverdue(t time.Time) bool {
+	return h.IsActive() && t.After(h.DueAt)
+}
+
+// IsValid returns true if the reason is one of the supported hold reasons
+func (r OrderHoldReason) IsValid() bool {
+	for _, reason := range OrderHoldReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// PriceOverrideReason explains why the charged price of an order item differs from its list price
+type PriceOverrideReason string
+
+const (
+	PriceOverrideReasonNegotiated      PriceOverrideReason = "negotiated"
+	PriceOverrideReasonPriceMatch      PriceOverrideReason = "price_match"
+	PriceOverrideReasonBulkDiscount    PriceOverrideReason = "bulk_discount"
+	PriceOverrideReasonDamagedGoods    PriceOverrideReason = "damaged_goods"
+	PriceOverrideReasonServiceRecovery PriceOverrideReason = "service_recovery"
+)
+
+// IsValid reports whether the reason is a known price override reason code
+func (r PriceOverrideReason) IsValid() bool {
+	switch r {
+	case PriceOverrideReasonNegotiated, PriceOverrideReasonPriceMatch, PriceOverrideReasonBulkDiscount,
+		PriceOverrideReasonDamagedGoods, PriceOverrideReasonServiceRecovery:
+		return true
+	}
+	return false
+}
+
 // OrderItem represents individual items within an order
 type OrderItem struct {
 	ID         string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -18,6 +39,18 @@ type OrderItem struct {
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 
+	// List price of the product when it was ordered; 0 for items ordered before list
+	// prices were recorded, which were charged their list price. A privileged user may
+	// override UnitPrice below it, recording a reason code.
+	ListPrice           float64             `gorm:"type:decimal(10,2);not null;default:0" json:"list_price"`
+	PriceOverrideReason PriceOverrideReason `gorm:"type:varchar(30)" json:"price_override_reason,omitempty"`
+	PriceOverrideNote   string              `gorm:"type:text" json:"price_override_note,omitempty"`
+	PriceOverriddenBy   *string             `gorm:"type:uuid" json:"price_overridden_by,omitempty"`
+	PriceOverriddenAt   *time.Time          `json:"price_overridden_at,omitempty"`
+
+	// Serial numbers of the units of a serialized product, captured at fulfillment
+	SerialNumbers StringList `gorm:"type:jsonb;not null;default:'[]'" json:"serial_numbers,omitempty"`
+
 	// Relationships
 	Order   *Order   `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"order,omitempty"`
 	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:RESTRICT" json:"product,omitempty"`
@@ -47,3 +80,22 @@ func (OrderItem) TableName() string {
 func (oi *OrderItem) GetSubtotal() float64 {
 	return oi.UnitPrice * float64(oi.Quantity)
 }
+
+// ListUnitPrice returns the list price the item was ordered at
+func (oi *OrderItem) ListUnitPrice() float64 {
+	if oi.ListPrice > 0 {
+		return oi.ListPrice
+	}
+	return oi.UnitPrice
+}
+
+// AwaitsSerialNumbers reports whether the item is of a serialized product and does not
+// have a serial number captured for each of its units, which it needs to ship
+func (oi *OrderItem) AwaitsSerialNumbers() bool {
+	return oi.Product != nil && oi.Product.Serialized && len(oi.SerialNumbers) != oi.Quantity
+}
+
+// IsPriceOverridden reports whether a privileged user overrode the charged price
+func (oi *OrderItem) IsPriceOverridden() bool {
+	return oi.PriceOverriddenAt != nil
+}
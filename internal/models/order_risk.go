@@ -0,0 +1,47 @@
+package models
+
+// RiskBand groups order risk scores for review
+type RiskBand string
+
+const (
+	RiskBandLow    RiskBand = "low"    // Scores 0 to 29
+	RiskBandMedium RiskBand = "medium" // Scores 30 to 59
+	RiskBandHigh   RiskBand = "high"   // Scores 60 to 100
+)
+
+// MaxRiskScore is the highest order risk score
+const MaxRiskScore = 100
+
+// RiskSignal is one reason an order's fraud risk score was raised, with the points it
+// added to the score
+type RiskSignal struct {
+	Code   string `json:"code"`
+	Points int    `json:"points"`
+	Detail string `json:"detail"`
+}
+
+// RiskBandOf returns the band of a risk score
+func RiskBandOf(score int) RiskBand {
+	switch {
+	case score >= 60:
+		return RiskBandHigh
+	case score >= 30:
+		return RiskBandMedium
+	default:
+		return RiskBandLow
+	}
+}
+
+// ScoreRange returns the lowest and highest scores in the band; ok is false for an
+// unknown band
+func (b RiskBand) ScoreRange() (min, max int, ok bool) {
+	switch b {
+	case RiskBandLow:
+		return 0, 29, true
+	case RiskBandMedium:
+		return 30, 59, true
+	case RiskBandHigh:
+		return 60, MaxRiskScore, true
+	}
+	return 0, 0, false
+}
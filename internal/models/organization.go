@@ -0,0 +1,80 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreditLimitAction decides what happens to an on-account order that would take an
+// organization's outstanding balance over its credit limit
+type CreditLimitAction string
+
+const (
+	CreditLimitActionReject CreditLimitAction = "reject"
+	CreditLimitActionHold   CreditLimitAction = "hold" // Held orders wait for an admin to approve or reject them
+)
+
+// IsValid returns true if the action is known
+func (a CreditLimitAction) IsValid() bool {
+	return a == CreditLimitActionReject || a == CreditLimitActionHold
+}
+
+// Organization is a B2B customer account. Its members share visibility of the
+// organization's orders and, when it has payment terms, may order on account.
+type Organization struct {
+	ID               string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name             string         `gorm:"uniqueIndex;not null;size:255" json:"name" validate:"required"`
+	CreditLimit      float64        `gorm:"type:decimal(12,2);not null;default:0" json:"credit_limit" validate:"gte=0"`
+	PaymentTermsDays int            `gorm:"not null;default:0" json:"payment_terms_days" validate:"gte=0"` // Net terms; 0 means no ordering on account
+	IsActive         bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// What happens to on-account orders over the credit limit
+	CreditLimitAction CreditLimitAction `gorm:"type:varchar(10);not null;default:'reject'" json:"credit_limit_action"`
+
+	// Price list the members order at, if any
+	PriceListID *string `gorm:"type:uuid;index" json:"price_list_id,omitempty"`
+
+	// Relationships
+	Members []User  `gorm:"foreignKey:OrganizationID;constraint:OnDelete:SET NULL" json:"members,omitempty"`
+	Orders  []Order `gorm:"foreignKey:OrganizationID;constraint:OnDelete:RESTRICT" json:"orders,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// AllowsOrderingOnAccount returns true if members may place orders to be invoiced later
+func (o *Organization) AllowsOrderingOnAccount() bool {
+	return o.IsActive && o.PaymentTermsDays > 0
+}
+
+// InvoiceDueDate returns when an invoice issued at the given time must be paid
+func (o *Organization) InvoiceDueDate(issuedAt time.Time) time.Time {
+	return issuedAt.AddDate(0, 0, o.PaymentTermsDays)
+}
+
+// AvailableCredit returns how much more may be ordered on account given the outstanding balance
+func (o *Organization) AvailableCredit(outstanding float64) float64 {
+	return math.Round((o.CreditLimit-outstanding)*100) / 100
+}
+
+// ExceedsCreditLimit returns true if an order of the given amount would take the
+// outstanding balance over the credit limit
+func (o *Organization) ExceedsCreditLimit(outstanding, amount float64) bool {
+	return math.Round((outstanding+amount)*100) > math.Round(o.CreditLimit*100)
+}
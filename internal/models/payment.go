@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ const (
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusRefunded  PaymentStatus = "refunded"
 	PaymentStatusCancelled PaymentStatus = "cancelled"
+	PaymentStatusHeld      PaymentStatus = "held" // Failed transiently, waiting to be retried
 )
 
 // PaymentMethod defines the payment method
@@ -30,6 +32,26 @@ const (
 	PaymentMethodCash         PaymentMethod = "cash"
 )
 
+// IsOffline returns true if the payment method is settled outside any gateway and
+// recorded by an admin
+func (m PaymentMethod) IsOffline() bool {
+	return m == PaymentMethodBankTransfer || m == PaymentMethodCash
+}
+
+// IsCard returns true if the payment method is a card, paid through payment intents
+func (m PaymentMethod) IsCard() bool {
+	return m == PaymentMethodCreditCard || m == PaymentMethodDebitCard
+}
+
+// IsValid returns true if the payment method is one of the supported methods
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentMethodCreditCard, PaymentMethodDebitCard, PaymentMethodPayPal, PaymentMethodBankTransfer, PaymentMethodCash:
+		return true
+	}
+	return false
+}
+
 // Payment represents a payment transaction
 type Payment struct {
 	ID                string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -51,16 +73,33 @@ type Payment struct {
 	LastAttemptAt  *time.Time `json:"last_attempt_at"`
 
 	// Gateway information
-	Gateway       string  `gorm:"type:varchar(50)" json:"gateway"`
-	GatewayTxnID  string  `gorm:"type:varchar(255)" json:"gateway_txn_id"`
+	Gateway       string  `gorm:"type:varchar(50);index:idx_payments_gateway_txn" json:"gateway"`
+	GatewayTxnID  string  `gorm:"type:varchar(255);index:idx_payments_gateway_txn" json:"gateway_txn_id"`
 	ProcessingFee float64 `gorm:"type:decimal(10,2);default:0" json:"processing_fee"`
 
+	// Amount refunded so far. A payment stays completed while partially refunded and
+	// becomes refunded once all of it has been returned.
+	RefundedAmount float64 `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+
 	// Metadata and audit
 	Metadata *string `gorm:"type:jsonb" json:"metadata"` // JSON field for additional data
 
+	// Admin who recorded an offline payment; nil for payments taken through a gateway
+	RecordedBy *string `gorm:"type:uuid" json:"recorded_by,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Held payments are retried from NextRetryAt until RetryUntil, when they fail. The
+	// order and its stock reservation stay in place while the payment is held.
+	RetryUntil *time.Time `json:"retry_until,omitempty"`
+
+	// When the reconciliation job last checked the payment against its gateway
+	ReconciledAt *time.Time `json:"reconciled_at,omitempty"`
+
+	// When ops were notified that the payment was stuck in pending or processed
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+
 	// Relationships
 	Order     *Order     `gorm:"foreignKey:OrderID;constraint:OnDelete:RESTRICT" json:"order,omitempty"`
 	AuditLogs []AuditLog `gorm:"foreignKey:EntityID;constraint:OnDelete:CASCADE" json:"audit_logs,omitempty"`
@@ -97,6 +136,17 @@ func (p *Payment) IsFailed() bool {
 	return p.Status == PaymentStatusFailed
 }
 
+// IsHeld returns true if payment is waiting to be retried
+func (p *Payment) IsHeld() bool {
+	return p.Status == PaymentStatusHeld
+}
+
+// IsAwaitingConfirmation returns true if the gateway accepted the payment and
+// confirms its outcome later by webhook
+func (p *Payment) IsAwaitingConfirmation() bool {
+	return p.Status == PaymentStatusProcessed
+}
+
 // IsRefunded returns true if payment is refunded
 func (p *Payment) IsRefunded() bool {
 	return p.Status == PaymentStatusRefunded
@@ -107,6 +157,32 @@ func (p *Payment) CanRefund() bool {
 	return p.Status == PaymentStatusCompleted
 }
 
+// RefundableAmount returns how much of a completed payment has not been refunded yet
+func (p *Payment) RefundableAmount() float64 {
+	if !p.CanRefund() {
+		return 0
+	}
+	return math.Max(math.Round((p.Amount-p.RefundedAmount)*100)/100, 0)
+}
+
+// RefundedTotal returns how much of the payment has been refunded. Payments refunded
+// through a gateway webhook are refunded in full.
+func (p *Payment) RefundedTotal() float64 {
+	if p.IsRefunded() && p.RefundedAmount == 0 {
+		return p.Amount
+	}
+	return p.RefundedAmount
+}
+
+// ApplyRefund records a refund of amount, marking the payment refunded once nothing
+// is left to refund
+func (p *Payment) ApplyRefund(amount float64) {
+	p.RefundedAmount = math.Round((p.RefundedAmount+amount)*100) / 100
+	if p.RefundedAmount >= p.Amount {
+		p.Status = PaymentStatusRefunded
+	}
+}
+
 // CanRetry returns true if payment can be retried
 func (p *Payment) CanRetry() bool {
 	return p.Status == PaymentStatusFailed || p.Status == PaymentStatusCancelled
@@ -136,6 +212,18 @@ func (p *Payment) MarkFailed(reason string) {
 	p.LastAttemptAt = &now
 }
 
+// HoldForRetry holds the payment after a transient failure until it is retried at retryAt
+func (p *Payment) HoldForRetry(reason string, retryAt time.Time) {
+	p.Status = PaymentStatusHeld
+	p.FailureReason = reason
+	p.SetNextRetryAt(retryAt)
+}
+
+// RetryWindowExpired returns true if a held payment may no longer be retried at t
+func (p *Payment) RetryWindowExpired(t time.Time) bool {
+	return p.RetryUntil != nil && !t.Before(*p.RetryUntil)
+}
+
 // CanRetryAt checks if payment can be retried at the specified time
 func (p *Payment) CanRetryAt(t time.Time) bool {
 	if !p.CanRetry() {
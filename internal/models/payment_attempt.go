@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentAttempt records the outcome of one gateway attempt to process a payment.
+// Attempts are kept for failure analytics and are never updated.
+type PaymentAttempt struct {
+	ID               string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID        string        `gorm:"type:uuid;not null;index" json:"payment_id"`
+	OrderID          string        `gorm:"type:uuid;not null;index" json:"order_id"`
+	AttemptNumber    int           `gorm:"not null" json:"attempt_number"` // 1 for the first attempt to pay the order
+	Gateway          string        `gorm:"type:varchar(50);not null" json:"gateway"`
+	Method           PaymentMethod `gorm:"type:varchar(20);not null" json:"method"`
+	Success          bool          `gorm:"not null" json:"success"`
+	FailureType      string        `gorm:"type:varchar(50)" json:"failure_type,omitempty"`
+	FailureMessage   string        `gorm:"type:text" json:"failure_message,omitempty"`
+	ProcessingTimeMs int64         `json:"processing_time_ms"`
+	StartedAt        time.Time     `gorm:"not null;index" json:"started_at"`
+	CreatedAt        time.Time     `json:"created_at"`
+
+	// Relationships
+	Payment *Payment `gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE" json:"payment,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (a *PaymentAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PaymentAttempt model
+func (PaymentAttempt) TableName() string {
+	return "payment_attempts"
+}
+
+// IsRetry returns true if an earlier attempt was made to pay the same order
+func (a *PaymentAttempt) IsRetry() bool {
+	return a.AttemptNumber > 1
+}
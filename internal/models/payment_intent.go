@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentIntentStatus defines the status of a payment intent
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentStatusRequiresConfirmation PaymentIntentStatus = "requires_confirmation" // Created, or its last confirmation was declined
+	PaymentIntentStatusProcessing           PaymentIntentStatus = "processing"            // Confirmed; its payment is held for retry or awaiting the gateway
+	PaymentIntentStatusSucceeded            PaymentIntentStatus = "succeeded"             // Its payment completed
+	PaymentIntentStatusCancelled            PaymentIntentStatus = "cancelled"             // Cancelled by the customer before it was confirmed
+	PaymentIntentStatusExpired              PaymentIntentStatus = "expired"               // Not confirmed before it expired
+)
+
+// IsOpen returns true while an intent can still take or is taking a payment
+func (s PaymentIntentStatus) IsOpen() bool {
+	return s == PaymentIntentStatusRequiresConfirmation || s == PaymentIntentStatusProcessing
+}
+
+// PaymentIntent is a customer's intent to pay an order by card, created before the
+// card is collected by the gateway's client SDK and confirmed with the gateway token
+// afterwards. Confirming it takes the payment, which pays the order once it completes.
+// An order has at most one open intent; an intent not confirmed by ExpiresAt expires.
+type PaymentIntent struct {
+	ID        string              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID   string              `gorm:"type:uuid;not null;index" json:"order_id"`
+	UserID    string              `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount    float64             `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency  string              `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Method    PaymentMethod       `gorm:"type:varchar(20);not null" json:"method"`
+	Status    PaymentIntentStatus `gorm:"type:varchar(30);not null;default:'requires_confirmation';index:idx_payment_intents_status_expiry,priority:1" json:"status"`
+	PaymentID *string             `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	LastError string              `gorm:"type:text" json:"last_error,omitempty"`
+	ExpiresAt time.Time           `gorm:"not null;index:idx_payment_intents_status_expiry,priority:2" json:"expires_at"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// TableName returns the table name for PaymentIntent model
+func (PaymentIntent) TableName() string {
+	return "payment_intents"
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (i *PaymentIntent) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// IsLapsed returns true if the intent is still awaiting confirmation past its expiry
+func (i *PaymentIntent) IsLapsed(now time.Time) bool {
+	return i.Status == PaymentIntentStatusRequiresConfirmation && !now.Before(i.ExpiresAt)
+}
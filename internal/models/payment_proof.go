@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentProof is the proof attached to an offline payment recorded by an admin,
+// such as a bank transfer receipt or a signed cash-on-delivery slip. A payment has
+// at most one proof, which is kept as it was uploaded.
+type PaymentProof struct {
+	ID          string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID   string    `gorm:"type:uuid;not null;uniqueIndex" json:"payment_id"`
+	FileName    string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size        int       `gorm:"not null" json:"size"`
+	SHA256      string    `gorm:"type:varchar(64);not null" json:"sha256"`
+	Content     []byte    `gorm:"type:bytea;not null" json:"-"`
+	UploadedBy  string    `gorm:"type:uuid;not null" json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Relationships
+	Payment *Payment `gorm:"foreignKey:PaymentID;constraint:OnDelete:CASCADE" json:"payment,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (p *PaymentProof) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PaymentProof model
+func (PaymentProof) TableName() string {
+	return "payment_proofs"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReconciliationOutcome is what checking a payment against its gateway found
+type ReconciliationOutcome string
+
+const (
+	ReconciliationOutcomeInSync    ReconciliationOutcome = "in_sync"   // Gateway agrees with the local status
+	ReconciliationOutcomeCorrected ReconciliationOutcome = "corrected" // Local status was set to the gateway's
+	ReconciliationOutcomeMismatch  ReconciliationOutcome = "mismatch"  // Disagreement left for an admin to resolve
+	ReconciliationOutcomePending   ReconciliationOutcome = "pending"   // Gateway has not settled the payment yet
+	ReconciliationOutcomeError     ReconciliationOutcome = "error"     // Gateway could not be asked
+)
+
+// PaymentReconciliation records one payment checked against its gateway by a
+// reconciliation run. ResolvedStatus is the status the payment was corrected to, and
+// empty when it was left as it was.
+type PaymentReconciliation struct {
+	ID             string                `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RunID          string                `gorm:"type:uuid;not null;index" json:"run_id"`
+	PaymentID      string                `gorm:"type:uuid;not null;index" json:"payment_id"`
+	OrderID        string                `gorm:"type:uuid;not null" json:"order_id"`
+	Gateway        string                `gorm:"type:varchar(50)" json:"gateway"`
+	GatewayTxnID   string                `gorm:"type:varchar(255)" json:"gateway_txn_id"`
+	LocalStatus    PaymentStatus         `gorm:"type:varchar(20);not null" json:"local_status"`
+	GatewayStatus  string                `gorm:"type:varchar(50)" json:"gateway_status,omitempty"`
+	ResolvedStatus PaymentStatus         `gorm:"type:varchar(20)" json:"resolved_status,omitempty"`
+	Outcome        ReconciliationOutcome `gorm:"type:varchar(20);not null;index" json:"outcome"`
+	LocalAmount    float64               `gorm:"type:decimal(10,2);not null" json:"local_amount"`
+	GatewayAmount  float64               `gorm:"type:decimal(10,2)" json:"gateway_amount"`
+	Detail         string                `gorm:"type:text" json:"detail,omitempty"`
+	CreatedAt      time.Time             `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (r *PaymentReconciliation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PaymentReconciliation model
+func (PaymentReconciliation) TableName() string {
+	return "payment_reconciliations"
+}
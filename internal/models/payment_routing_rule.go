@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentRoutingAction is what a payment routing rule does with the payments it matches
+type PaymentRoutingAction string
+
+const (
+	// PaymentRoutingActionRoute sends matching payments to the rule's gateway
+	PaymentRoutingActionRoute PaymentRoutingAction = "route"
+	// PaymentRoutingActionBypass keeps matching offline payments away from every gateway
+	PaymentRoutingActionBypass PaymentRoutingAction = "bypass"
+)
+
+// IsValid returns true if the routing action is known
+func (a PaymentRoutingAction) IsValid() bool {
+	return a == PaymentRoutingActionRoute || a == PaymentRoutingActionBypass
+}
+
+// PaymentRoutingRule routes the payments it matches before a gateway is selected. A
+// payment matches when its amount is within [MinAmount, MaxAmount], leaving out an
+// unset bound, and its currency and method are among Currencies and PaymentMethods,
+// unless they are empty. Rules are evaluated by descending Priority, then by name,
+// and the first that matches decides.
+type PaymentRoutingRule struct {
+	ID             string               `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name           string               `gorm:"uniqueIndex;not null;size:255" json:"name"`
+	Priority       int                  `gorm:"not null;default:0" json:"priority"`
+	MinAmount      *float64             `gorm:"type:decimal(10,2)" json:"min_amount,omitempty"`
+	MaxAmount      *float64             `gorm:"type:decimal(10,2)" json:"max_amount,omitempty"`
+	Currencies     StringList           `gorm:"type:jsonb;not null;default:'[]'" json:"currencies"`
+	PaymentMethods StringList           `gorm:"type:jsonb;not null;default:'[]'" json:"payment_methods"`
+	Action         PaymentRoutingAction `gorm:"type:varchar(20);not null" json:"action"`
+	Gateway        string               `gorm:"type:varchar(50)" json:"gateway,omitempty"` // Empty for bypass rules
+	IsActive       bool                 `gorm:"default:true" json:"is_active"`             // Inactive rules are not evaluated
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (r *PaymentRoutingRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PaymentRoutingRule model
+func (PaymentRoutingRule) TableName() string {
+	return "payment_routing_rules"
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PriceList holds negotiated B2B prices. It is assigned to organizations, whose
+// members order at its prices, or to individual customers, whose own list takes
+// precedence over their organization's. Products without an effective price in the
+// list are charged their list price.
+type PriceList struct {
+	ID          string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string         `gorm:"uniqueIndex;not null;size:255" json:"name" validate:"required"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"` // Inactive lists are ignored at checkout
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Items []PriceListItem `gorm:"foreignKey:PriceListID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (p *PriceList) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PriceList model
+func (PriceList) TableName() string {
+	return "price_lists"
+}
+
+// PriceListItem is the price of a product in a price list from EffectiveFrom until
+// EffectiveTo; an open end is unbounded. When ranges of a product overlap, the one
+// that started last applies.
+type PriceListItem struct {
+	ID            string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PriceListID   string     `gorm:"type:uuid;not null;index:idx_price_list_items_list_product" json:"price_list_id"`
+	ProductID     string     `gorm:"type:uuid;not null;index:idx_price_list_items_list_product" json:"product_id"`
+	Price         float64    `gorm:"type:decimal(10,2);not null" json:"price" validate:"gt=0"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (i *PriceListItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for PriceListItem model
+func (PriceListItem) TableName() string {
+	return "price_list_items"
+}
+
+// IsEffective returns true if the price applies at the given time
+func (i *PriceListItem) IsEffective(at time.Time) bool {
+	if i.EffectiveFrom != nil && at.Before(*i.EffectiveFrom) {
+		return false
+	}
+	return i.EffectiveTo == nil || at.Before(*i.EffectiveTo)
+}
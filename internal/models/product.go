@@ -13,13 +13,37 @@ type Product struct {
 	Name        string         `gorm:"not null;size:255;index" json:"name" validate:"required,min=1,max=255"`
 	Description string         `gorm:"type:text" json:"description"`
 	Price       float64        `gorm:"type:decimal(10,2);not null" json:"price" validate:"required,gt=0"`
+	CostPrice   float64        `gorm:"type:decimal(10,2);not null;default:0" json:"cost_price" validate:"gte=0"`
 	SKU         string         `gorm:"uniqueIndex;not null;size:100" json:"sku" validate:"required"`
 	CategoryID  *string        `gorm:"type:uuid;index" json:"category_id"`
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	Metadata    Metadata       `gorm:"type:jsonb;not null;default:'{}'" json:"metadata,omitempty"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// CartHoldMinutes overrides the store cart hold window for this product; 0 uses the store default
+	CartHoldMinutes int `gorm:"not null;default:0" json:"cart_hold_minutes"`
+
+	// LeadTimeDays is how long the supplier takes to restock the product; 0 uses the
+	// store default. LowStockThreshold, when set, replaces the threshold computed from
+	// sales velocity and MinStock.
+	LeadTimeDays      int  `gorm:"not null;default:0" json:"lead_time_days"`
+	LowStockThreshold *int `json:"low_stock_threshold,omitempty"`
+
+	// PurchaseLimit caps the units of the product each customer may buy, for limited
+	// releases; 0 is no limit. Units are counted over the last PurchaseLimitDays days,
+	// or over the customer's whole order history when it is 0.
+	PurchaseLimit     int `gorm:"not null;default:0" json:"purchase_limit"`
+	PurchaseLimitDays int `gorm:"not null;default:0" json:"purchase_limit_days"`
+
+	// Serialized products have a serial number per unit, received with their lot and
+	// captured on the order item before it ships, for warranty claims
+	Serialized bool `gorm:"not null;default:false" json:"serialized"`
+
+	// Translations of Name and Description, which are in the default locale, keyed by locale
+	Translations ProductTranslations `gorm:"type:jsonb;not null;default:'{}'" json:"translations,omitempty"`
+
 	// Relationships
 	Inventory  *Inventory  `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"inventory,omitempty"`
 	OrderItems []OrderItem `gorm:"foreignKey:ProductID;constraint:OnDelete:RESTRICT" json:"order_items,omitempty"`
@@ -43,6 +67,33 @@ func (p *Product) IsAvailable() bool {
 	return p.IsActive && p.Inventory != nil && p.Inventory.Available > 0
 }
 
+// PurchaseLimitSince returns the start of the period the purchase limit counts units
+// in at now, or nil when it counts the whole order history
+func (p *Product) PurchaseLimitSince(now time.Time) *time.Time {
+	if p.PurchaseLimitDays <= 0 {
+		return nil
+	}
+	since := now.AddDate(0, 0, -p.PurchaseLimitDays)
+	return &since
+}
+
+// Localize returns the product's name and description in locale, falling back field
+// by field to the default-locale text, and the locale the text was resolved in
+func (p *Product) Localize(locale, defaultLocale string) (name, description, resolved string) {
+	translation, resolved, ok := p.Translations.Resolve(locale)
+	if !ok {
+		return p.Name, p.Description, defaultLocale
+	}
+	name, description = translation.Name, translation.Description
+	if name == "" {
+		name = p.Name
+	}
+	if description == "" {
+		description = p.Description
+	}
+	return name, description, resolved
+}
+
 // GetAvailableStock returns the available stock quantity
 func (p *Product) GetAvailableStock() int {
 	if p.Inventory == nil {
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// ProductAvailability is the storefront read model of a product's available stock.
+// It is re-materialized from inventory whenever stock changes, so product pages
+// never read the inventory rows that reservations lock.
+type ProductAvailability struct {
+	ProductID string    `gorm:"type:uuid;primary_key" json:"product_id"`
+	Available int       `gorm:"not null;default:0" json:"available"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"` // When inventory was last read
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName returns the table name for ProductAvailability model
+func (ProductAvailability) TableName() string {
+	return "product_availability"
+}
+
+// IsStale returns true if the availability was read from inventory more than maxAge ago
+func (a *ProductAvailability) IsStale(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(a.UpdatedAt) > maxAge
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductSerial is a unit of a serialized product, received with its lot. It is
+// allocated to the order item it is captured on at fulfillment; units allocated to
+// orders that were cancelled or failed can be captured again.
+type ProductSerial struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID    string     `gorm:"type:uuid;not null;uniqueIndex:idx_product_serials_number,priority:1" json:"product_id"`
+	SerialNumber string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_product_serials_number,priority:2" json:"serial_number"`
+	LotNumber    string     `gorm:"type:varchar(100);not null;index" json:"lot_number"`
+	OrderItemID  *string    `gorm:"type:uuid;index" json:"order_item_id,omitempty"`
+	ReceivedAt   time.Time  `gorm:"not null" json:"received_at"`
+	AllocatedAt  *time.Time `json:"allocated_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for ProductSerial model
+func (ProductSerial) TableName() string {
+	return "product_serials"
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (s *ProductSerial) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProductTranslation is a product's name and description in one locale. Fields left
+// empty fall back to the product's default-locale text.
+type ProductTranslation struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsEmpty returns true if the translation translates nothing
+func (t ProductTranslation) IsEmpty() bool {
+	return t.Name == "" && t.Description == ""
+}
+
+// ProductTranslations maps lowercase locale tags (e.g. "fr", "pt-br") to a product's
+// translated catalog text, stored as JSONB
+type ProductTranslations map[string]ProductTranslation
+
+// Value implements driver.Valuer so ProductTranslations can be persisted as JSONB
+func (t ProductTranslations) Value() (driver.Value, error) {
+	if t == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner so ProductTranslations can be read from JSONB
+func (t *ProductTranslations) Scan(value interface{}) error {
+	if value == nil {
+		*t = ProductTranslations{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ProductTranslations", value)
+	}
+
+	result := ProductTranslations{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+	}
+	*t = result
+	return nil
+}
+
+// Resolve returns the translation for locale, or for its base language when the
+// region has none (e.g. "fr" for "fr-ca"), along with the locale it was found under.
+// It returns false if neither is translated.
+func (t ProductTranslations) Resolve(locale string) (ProductTranslation, string, bool) {
+	locale = strings.ToLower(locale)
+	if translation, ok := t[locale]; ok && !translation.IsEmpty() {
+		return translation, locale, true
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if translation, ok := t[base]; ok && !translation.IsEmpty() {
+			return translation, base, true
+		}
+	}
+	return ProductTranslation{}, "", false
+}
+
+// Merge applies changes, replacing the translation of each locale given and removing
+// the locales given an empty translation
+func (t *ProductTranslations) Merge(changes map[string]ProductTranslation) {
+	if *t == nil {
+		*t = ProductTranslations{}
+	}
+	for locale, translation := range changes {
+		locale = strings.ToLower(locale)
+		if translation.IsEmpty() {
+			delete(*t, locale)
+			continue
+		}
+		(*t)[locale] = translation
+	}
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PromotionType defines what a promotion discounts
+type PromotionType string
+
+const (
+	// PromotionTypePercentOff takes PercentOff percent off the whole order
+	PromotionTypePercentOff PromotionType = "percent_off"
+	// PromotionTypeCategoryBOGO makes every second unit of the products in a category
+	// free, the cheaper unit of each pair
+	PromotionTypeCategoryBOGO PromotionType = "category_bogo"
+	// PromotionTypeFreeShipping waives the shipping fee
+	PromotionTypeFreeShipping PromotionType = "free_shipping"
+)
+
+// IsValid returns true if the promotion type is known
+func (t PromotionType) IsValid() bool {
+	switch t {
+	case PromotionTypePercentOff, PromotionTypeCategoryBOGO, PromotionTypeFreeShipping:
+		return true
+	}
+	return false
+}
+
+// Promotion is a discount applied automatically at checkout to eligible orders placed
+// between StartsAt and EndsAt. Orders qualify from MinSubtotal before discounts, on
+// Channel unless it is empty, and only as the customer's first order with FirstOrderOnly.
+//
+// Promotions are evaluated by descending Priority, then by start time and ID. An
+// Exclusive promotion applies alone: it is skipped once another promotion applied and
+// stops any other from applying after it. Of promotions sharing a StackGroup, only
+// the first to apply counts.
+type Promotion struct {
+	ID             string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name           string         `gorm:"uniqueIndex;not null;size:255" json:"name" validate:"required"`
+	Description    string         `gorm:"type:text" json:"description,omitempty"`
+	Type           PromotionType  `gorm:"type:varchar(20);not null" json:"type"`
+	PercentOff     float64        `gorm:"type:decimal(5,2);not null;default:0" json:"percent_off,omitempty"`
+	CategoryID     *string        `gorm:"type:uuid" json:"category_id,omitempty"`
+	MinSubtotal    float64        `gorm:"type:decimal(10,2);not null;default:0" json:"min_subtotal"`
+	FirstOrderOnly bool           `gorm:"not null;default:false" json:"first_order_only"`
+	Channel        string         `gorm:"type:varchar(50)" json:"channel,omitempty"`
+	Priority       int            `gorm:"not null;default:0" json:"priority"`
+	Exclusive      bool           `gorm:"not null;default:false" json:"exclusive"`
+	StackGroup     string         `gorm:"type:varchar(50)" json:"stack_group,omitempty"`
+	StartsAt       time.Time      `gorm:"not null;index:idx_promotions_window" json:"starts_at"`
+	EndsAt         time.Time      `gorm:"not null;index:idx_promotions_window" json:"ends_at"`
+	IsActive       bool           `gorm:"default:true" json:"is_active"` // Inactive promotions never apply
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (p *Promotion) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsRunning returns true if the promotion is active and its window includes at
+func (p *Promotion) IsRunning(at time.Time) bool {
+	return p.IsActive && !at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+// OrderPromotion records a promotion applied to an order and the discount it gave,
+// which for free shipping is the fee waived
+type OrderPromotion struct {
+	ID          string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID     string        `gorm:"type:uuid;not null;index" json:"order_id"`
+	PromotionID string        `gorm:"type:uuid;not null;index" json:"promotion_id"`
+	Name        string        `gorm:"size:255;not null" json:"name"`
+	Type        PromotionType `gorm:"type:varchar(20);not null" json:"type"`
+	Discount    float64       `gorm:"type:decimal(10,2);not null" json:"discount"`
+	CreatedAt   time.Time     `json:"created_at"`
+
+	// Relationships
+	Order     *Order     `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"-"`
+	Promotion *Promotion `gorm:"foreignKey:PromotionID;constraint:OnDelete:RESTRICT" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (p *OrderPromotion) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for OrderPromotion model
+func (OrderPromotion) TableName() string {
+	return "order_promotions"
+}
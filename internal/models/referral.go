@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReferralStatus is how far a referred user got towards earning their referrer a reward
+type ReferralStatus string
+
+const (
+	// ReferralStatusSignedUp referrals have no qualifying order yet
+	ReferralStatusSignedUp ReferralStatus = "signed_up"
+	// ReferralStatusOrdered referrals wait for their first order to be delivered and
+	// kept past the return window
+	ReferralStatusOrdered ReferralStatus = "ordered"
+	// ReferralStatusRewarded referrals earned their referrer store credit
+	ReferralStatusRewarded ReferralStatus = "rewarded"
+	// ReferralStatusVoid referrals' first order was returned, so they earn nothing
+	ReferralStatusVoid ReferralStatus = "void"
+)
+
+// ReferralCode is the code a user shares to refer others. Each user has one, created
+// the first time they ask for it.
+type ReferralCode struct {
+	UserID    string    `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Code      string    `gorm:"type:varchar(20);not null;uniqueIndex" json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName returns the table name for ReferralCode model
+func (ReferralCode) TableName() string {
+	return "referral_codes"
+}
+
+// Referral attributes a user who signed up with a referral code (the referee) to the
+// code's owner (the referrer). The referee's first order that is not cancelled is
+// attributed to it too; once that order was delivered and not returned within the
+// return window, the referrer is rewarded with store credit.
+type Referral struct {
+	ID           string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReferrerID   string         `gorm:"type:uuid;not null;index" json:"referrer_id"`
+	RefereeID    string         `gorm:"type:uuid;not null;uniqueIndex" json:"referee_id"`
+	Code         string         `gorm:"type:varchar(20);not null" json:"code"`
+	Status       ReferralStatus `gorm:"type:varchar(20);not null;default:'signed_up';index" json:"status"`
+	OrderID      *string        `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	OrderedAt    *time.Time     `json:"ordered_at,omitempty"`
+	RewardAmount float64        `gorm:"type:decimal(10,2);not null;default:0" json:"reward_amount"`
+	RewardedAt   *time.Time     `json:"rewarded_at,omitempty"`
+	VoidReason   string         `gorm:"type:varchar(255)" json:"void_reason,omitempty"`
+	CreatedAt    time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+
+	// Relationships
+	Referrer *User  `gorm:"foreignKey:ReferrerID;constraint:OnDelete:CASCADE" json:"referrer,omitempty"`
+	Referee  *User  `gorm:"foreignKey:RefereeID;constraint:OnDelete:CASCADE" json:"referee,omitempty"`
+	Order    *Order `gorm:"foreignKey:OrderID;constraint:OnDelete:SET NULL" json:"order,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (r *Referral) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for Referral model
+func (Referral) TableName() string {
+	return "referrals"
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Refund is part or all of a completed payment returned to the customer. A payment
+// can be refunded several times, up to the amount captured.
+type Refund struct {
+	ID        string  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PaymentID string  `gorm:"type:uuid;not null;index" json:"payment_id"`
+	OrderID   string  `gorm:"type:uuid;not null;index" json:"order_id"`
+	Amount    float64 `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency  string  `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Reason    string  `gorm:"type:varchar(255)" json:"reason,omitempty"`
+
+	// RefundKey names the refund at the gateway and in the ledger, so a retried refund
+	// is recorded once
+	RefundKey string `gorm:"type:varchar(255);not null;uniqueIndex" json:"-"`
+
+	// Admin who issued the refund; nil for refunds of cancelled orders
+	RequestedBy *string `gorm:"type:uuid" json:"requested_by,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Payment *Payment `gorm:"foreignKey:PaymentID;constraint:OnDelete:RESTRICT" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for Refund model
+func (Refund) TableName() string {
+	return "refunds"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReportResultStatus defines the generation status of a report result
+type ReportResultStatus string
+
+const (
+	ReportResultStatusPending    ReportResultStatus = "pending"
+	ReportResultStatusGenerating ReportResultStatus = "generating"
+	ReportResultStatusCompleted  ReportResultStatus = "completed"
+	ReportResultStatusFailed     ReportResultStatus = "failed"
+	ReportResultStatusCancelled  ReportResultStatus = "cancelled"
+)
+
+// ReportResult is the stored result of a report generated in the background. It is
+// created pending when the report is requested and moves to generating, then to
+// completed, failed or cancelled, so clients can poll it across restarts. Data holds
+// the report as JSON and Content the rendered file of formats other than JSON, unless
+// the file is kept in storage under FilePath.
+type ReportResult struct {
+	ID           string             `gorm:"type:varchar(100);primaryKey" json:"id"`
+	RequestID    string             `gorm:"type:varchar(100);not null;index" json:"request_id"`
+	Type         string             `gorm:"type:varchar(50);not null" json:"type"`
+	Format       string             `gorm:"type:varchar(10);not null" json:"format"`
+	Status       ReportResultStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Parameters   json.RawMessage    `gorm:"type:jsonb;serializer:json" json:"parameters,omitempty"`
+	RequestedBy  string             `gorm:"type:varchar(100);index" json:"requested_by,omitempty"`
+	Data         json.RawMessage    `gorm:"type:jsonb;serializer:json" json:"data,omitempty"`
+	Content      []byte             `gorm:"type:bytea" json:"-"`
+	FilePath     string             `gorm:"type:varchar(255)" json:"file_path,omitempty"`
+	FileSize     int64              `gorm:"not null;default:0" json:"file_size"`
+	RowCount     int                `gorm:"not null;default:0" json:"row_count"`
+	Error        string             `gorm:"type:text" json:"error,omitempty"`
+	ProcessingMs int64              `gorm:"not null;default:0" json:"processing_ms"`
+	GeneratedAt  *time.Time         `json:"generated_at,omitempty"`
+	ExpiresAt    *time.Time         `json:"expires_at,omitempty"`
+	CreatedAt    time.Time          `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// TableName returns the table name for ReportResult model
+func (ReportResult) TableName() string {
+	return "report_results"
+}
+
+// IsFinished returns true once the report will no longer change status
+func (r *ReportResult) IsFinished() bool {
+	switch r.Status {
+	case ReportResultStatusCompleted, ReportResultStatusFailed, ReportResultStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedPaymentMethod is a payment method a customer saved for later checkouts. The
+// gateway token charges it without the customer entering its details again and is
+// never returned by the API. A customer has at most one default payment method,
+// which express checkout pays with.
+type SavedPaymentMethod struct {
+	ID           string        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID       string        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Method       PaymentMethod `gorm:"type:varchar(20);not null" json:"method"`
+	Label        string        `gorm:"type:varchar(100)" json:"label,omitempty"`
+	Last4        string        `gorm:"type:varchar(4)" json:"last4,omitempty"`
+	GatewayToken string        `gorm:"type:varchar(255);not null" json:"-"`
+	Fingerprint  string        `gorm:"type:varchar(255)" json:"-"` // Identifies the card across tokens, for fraud screening
+	ExpiresAt    *time.Time    `json:"expires_at,omitempty"`
+	IsDefault    bool          `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (m *SavedPaymentMethod) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for SavedPaymentMethod model
+func (SavedPaymentMethod) TableName() string {
+	return "saved_payment_methods"
+}
+
+// IsExpired returns true if the payment method can no longer be charged at the given time
+func (m *SavedPaymentMethod) IsExpired(at time.Time) bool {
+	return m.ExpiresAt != nil && !at.Before(*m.ExpiresAt)
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockHoldStatus defines the status of a cart stock hold
+type StockHoldStatus string
+
+const (
+	StockHoldStatusActive    StockHoldStatus = "active"
+	StockHoldStatusConverted StockHoldStatus = "converted"
+	StockHoldStatusReleased  StockHoldStatus = "released"
+	StockHoldStatusExpired   StockHoldStatus = "expired"
+)
+
+// StockHold is a soft reservation of stock for an item in a user's cart. While
+// active, its quantity is counted in the product's reserved inventory.
+type StockHold struct {
+	ID         string          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     string          `gorm:"type:uuid;not null" json:"user_id"`
+	ProductID  string          `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity   int             `gorm:"not null" json:"quantity"`
+	Status     StockHoldStatus `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	ExpiresAt  time.Time       `gorm:"not null" json:"expires_at"`
+	OrderID    *string         `gorm:"type:uuid" json:"order_id,omitempty"` // Set when the hold is converted at checkout
+	ReleasedAt *time.Time      `json:"released_at,omitempty"`
+	CreatedAt  time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+
+	// Relationships
+	User    *User    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (h *StockHold) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for StockHold model
+func (StockHold) TableName() string {
+	return "stock_holds"
+}
+
+// IsActive returns true if the hold still reserves stock
+func (h *StockHold) IsActive() bool {
+	return h.Status == StockHoldStatusActive
+}
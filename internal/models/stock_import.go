@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockImportStatus is where a stock import stands
+type StockImportStatus string
+
+const (
+	StockImportStatusProcessing StockImportStatus = "processing" // Chunks of rows are queued or being applied
+	StockImportStatusCompleted  StockImportStatus = "completed"  // Every row was accepted or rejected
+	StockImportStatusFailed     StockImportStatus = "failed"     // Its chunks could not be queued; pending rows are not applied
+)
+
+// StockImportRowStatus is the outcome of one row of a stock import
+type StockImportRowStatus string
+
+const (
+	StockImportRowPending  StockImportRowStatus = "pending"
+	StockImportRowAccepted StockImportRowStatus = "accepted"
+	StockImportRowRejected StockImportRowStatus = "rejected"
+)
+
+// StockImport is an uploaded CSV of stock adjustments, applied in the background in
+// chunks of rows. Accepted and Rejected count the rows applied or refused so far.
+type StockImport struct {
+	ID          string            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Filename    string            `gorm:"size:255" json:"filename,omitempty"`
+	TotalRows   int               `gorm:"not null" json:"total_rows"`
+	Accepted    int               `gorm:"not null;default:0" json:"accepted"`
+	Rejected    int               `gorm:"not null;default:0" json:"rejected"`
+	Chunks      int               `gorm:"not null;default:0" json:"chunks"`
+	Status      StockImportStatus `gorm:"type:varchar(20);not null;default:'processing';index" json:"status"`
+	Error       string            `gorm:"type:text" json:"error,omitempty"`
+	CreatedBy   string            `gorm:"type:uuid;not null" json:"created_by"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (i *StockImport) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for StockImport model
+func (StockImport) TableName() string {
+	return "stock_imports"
+}
+
+// Pending is the number of rows not yet accepted or rejected
+func (i *StockImport) Pending() int {
+	return i.TotalRows - i.Accepted - i.Rejected
+}
+
+// StockImportRow is one CSV row of a stock import: a signed adjustment of a product's
+// on hand quantity. Row is the row's line in the file. OnHand is the product's on hand
+// quantity right after an accepted row was applied.
+type StockImportRow struct {
+	ImportID  string               `gorm:"type:uuid;primaryKey" json:"-"`
+	Row       int                  `gorm:"column:row_number;primaryKey;autoIncrement:false" json:"row"`
+	SKU       string               `gorm:"size:100" json:"sku"`
+	Quantity  int                  `gorm:"not null" json:"quantity"`
+	ProductID *string              `gorm:"type:uuid" json:"product_id,omitempty"`
+	OnHand    *int                 `json:"on_hand,omitempty"`
+	Status    StockImportRowStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Error     string               `gorm:"size:255" json:"error,omitempty"`
+}
+
+// TableName returns the table name for StockImportRow model
+func (StockImportRow) TableName() string {
+	return "stock_import_rows"
+}
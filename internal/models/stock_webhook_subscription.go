@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StringList is a list of strings stored in a JSONB column
+type StringList []string
+
+// Value implements driver.Valuer so StringList can be persisted as JSONB
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner so StringList can be read from JSONB
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = StringList{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+
+	result := StringList{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return err
+		}
+	}
+	*l = result
+	return nil
+}
+
+// Contains reports whether the list holds the given value
+func (l StringList) Contains(value string) bool {
+	for _, item := range l {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// StockWebhookSubscription registers an external endpoint (e.g. a marketplace
+// connector) that receives stock-change webhooks. An empty product list
+// subscribes to changes for every product.
+type StockWebhookSubscription struct {
+	ID              string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name            string     `gorm:"type:varchar(100);not null" json:"name"`
+	URL             string     `gorm:"type:varchar(500);not null" json:"url"`
+	Secret          string     `gorm:"type:varchar(255);not null" json:"-"`
+	ProductIDs      StringList `gorm:"type:jsonb;not null;default:'[]'" json:"product_ids"`
+	IsActive        bool       `gorm:"default:true;index" json:"is_active"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	LastError       string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (s *StockWebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for StockWebhookSubscription model
+func (StockWebhookSubscription) TableName() string {
+	return "stock_webhook_subscriptions"
+}
+
+// MatchesProduct returns true if the subscriber wants changes for the product
+func (s *StockWebhookSubscription) MatchesProduct(productID string) bool {
+	return len(s.ProductIDs) == 0 || s.ProductIDs.Contains(productID)
+}
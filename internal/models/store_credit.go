@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StoreCreditReason is why store credit was granted or used
+type StoreCreditReason string
+
+const (
+	StoreCreditReasonReferralReward StoreCreditReason = "referral_reward"
+)
+
+// StoreCreditTransaction grants (positive Amount) or uses (negative Amount) a user's
+// store credit; the balance is the sum of their transactions. ReferenceID is what the
+// transaction is for, such as the rewarded referral, and is unique per reason so the
+// same thing can't be credited twice.
+type StoreCreditTransaction struct {
+	ID          string            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      string            `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount      float64           `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Reason      StoreCreditReason `gorm:"type:varchar(30);not null;uniqueIndex:idx_store_credit_reference" json:"reason"`
+	ReferenceID string            `gorm:"type:varchar(100);not null;uniqueIndex:idx_store_credit_reference" json:"reference_id"`
+	Description string            `gorm:"type:varchar(255)" json:"description,omitempty"`
+	CreatedAt   time.Time         `gorm:"index" json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (t *StoreCreditTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for StoreCreditTransaction model
+func (StoreCreditTransaction) TableName() string {
+	return "store_credit_transactions"
+}
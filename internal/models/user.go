@@ -13,6 +13,7 @@ type UserRole string
 const (
 	UserRoleCustomer UserRole = "customer"
 	UserRoleAdmin    UserRole = "admin"
+	UserRoleSupport  UserRole = "support" // Customer support agents, who look up orders
 )
 
 // User represents a user in the system (customers and admins)
@@ -27,6 +28,15 @@ type User struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Locale negotiated at registration, used for notifications sent outside a request
+	Locale string `gorm:"size:10;not null;default:'en'" json:"locale"`
+
+	// B2B customer account the user belongs to, if any
+	OrganizationID *string `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+
+	// Price list the user orders at, ahead of their organization's, if any
+	PriceListID *string `gorm:"type:uuid;index" json:"price_list_id,omitempty"`
+
 	// Relationships
 	Orders        []Order        `gorm:"foreignKey:UserID;constraint:OnDelete:RESTRICT" json:"orders,omitempty"`
 	Notifications []Notification `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"notifications,omitempty"`
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WarrantyClaimStatus is where a warranty claim is in its review
+type WarrantyClaimStatus string
+
+const (
+	WarrantyClaimStatusOpen     WarrantyClaimStatus = "open"     // Awaiting review
+	WarrantyClaimStatusResolved WarrantyClaimStatus = "resolved" // Repaired, replaced or refunded
+	WarrantyClaimStatusRejected WarrantyClaimStatus = "rejected" // Not covered by the warranty
+)
+
+// IsValid returns true if the warranty claim status is known
+func (s WarrantyClaimStatus) IsValid() bool {
+	switch s {
+	case WarrantyClaimStatusOpen, WarrantyClaimStatusResolved, WarrantyClaimStatusRejected:
+		return true
+	}
+	return false
+}
+
+// WarrantyClaimResolution is how an accepted warranty claim was made good
+type WarrantyClaimResolution string
+
+const (
+	WarrantyClaimResolutionRepair  WarrantyClaimResolution = "repair"
+	WarrantyClaimResolutionReplace WarrantyClaimResolution = "replace" // Shipped on a zero-priced replacement order
+	WarrantyClaimResolutionRefund  WarrantyClaimResolution = "refund"  // The unit's price refunded to the order's payments
+)
+
+// IsValid returns true if the warranty claim resolution is known
+func (r WarrantyClaimResolution) IsValid() bool {
+	switch r {
+	case WarrantyClaimResolutionRepair, WarrantyClaimResolutionReplace, WarrantyClaimResolutionRefund:
+		return true
+	}
+	return false
+}
+
+// WarrantyClaim is a customer's warranty or service claim on a unit of a serialized
+// product they received, identified by the serial number captured on its order item.
+// A unit has at most one open claim. Resolving a claim by replacement places the
+// replacement order; by refund, refunds the unit's price.
+type WarrantyClaim struct {
+	ID           string              `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID      string              `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrderItemID  string              `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	ProductID    string              `gorm:"type:uuid;not null;index" json:"product_id"`
+	UserID       string              `gorm:"type:uuid;not null;index" json:"user_id"`
+	SerialNumber string              `gorm:"type:varchar(100);not null" json:"serial_number"`
+	Description  string              `gorm:"type:text;not null" json:"description"`
+	Status       WarrantyClaimStatus `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+
+	// Set when the claim is resolved or rejected
+	Resolution         WarrantyClaimResolution `gorm:"type:varchar(20)" json:"resolution,omitempty"`
+	ResolutionNote     string                  `gorm:"type:text" json:"resolution_note,omitempty"`
+	ReplacementOrderID *string                 `gorm:"type:uuid" json:"replacement_order_id,omitempty"`
+	RefundedAmount     float64                 `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+	ResolvedBy         *string                 `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt         *time.Time              `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	OrderItem *OrderItem `gorm:"foreignKey:OrderItemID;constraint:OnDelete:RESTRICT" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (c *WarrantyClaim) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for WarrantyClaim model
+func (WarrantyClaim) TableName() string {
+	return "warranty_claims"
+}
+
+// IsOpen returns true if the claim awaits review
+func (c *WarrantyClaim) IsOpen() bool {
+	return c.Status == WarrantyClaimStatusOpen
+}
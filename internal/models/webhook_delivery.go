@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryStatus defines the status of an event's delivery to an endpoint
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is the delivery log entry of one event to one endpoint. Pending
+// deliveries are attempted at NextAttemptAt, retried with backoff after failures and
+// marked failed once retries are exhausted or the endpoint rejects the event. EventID
+// identifies the event across its deliveries to different endpoints.
+type WebhookDelivery struct {
+	ID             string                `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EndpointID     string                `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_endpoint_event" json:"endpoint_id"`
+	EventID        string                `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_endpoint_event" json:"event_id"`
+	Topic          WebhookTopic          `gorm:"type:varchar(50);not null;index" json:"topic"`
+	Payload        json.RawMessage       `gorm:"type:jsonb;serializer:json;not null" json:"payload"`
+	OccurredAt     time.Time             `gorm:"not null" json:"occurred_at"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastResponse   string                `gorm:"type:text" json:"last_response,omitempty"`
+	LastError      string                `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+
+	// Relationships
+	Endpoint *WebhookEndpoint `gorm:"foreignKey:EndpointID" json:"endpoint,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// IsPending returns true if the delivery will be attempted again
+func (d *WebhookDelivery) IsPending() bool {
+	return d.Status == WebhookDeliveryStatusPending
+}
+
+// CanRedeliver returns true if the delivery failed and may be queued again
+func (d *WebhookDelivery) CanRedeliver() bool {
+	return d.Status == WebhookDeliveryStatusFailed
+}
+
+// WebhookRelayCursor is the position of a relay in an event stream it turns into
+// webhook deliveries, such as the order event outbox
+type WebhookRelayCursor struct {
+	Name      string    `gorm:"type:varchar(50);primaryKey" json:"name"`
+	Sequence  int64     `gorm:"not null" json:"sequence"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for WebhookRelayCursor model
+func (WebhookRelayCursor) TableName() string {
+	return "webhook_relay_cursors"
+}
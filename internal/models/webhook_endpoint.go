@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookTopic names an event sent to webhook endpoints
+type WebhookTopic string
+
+const (
+	WebhookTopicOrderCreated          WebhookTopic = "order.created"
+	WebhookTopicOrderPaid             WebhookTopic = "order.paid"
+	WebhookTopicOrderShipped          WebhookTopic = "order.shipped"
+	WebhookTopicOrderDelivered        WebhookTopic = "order.delivered"
+	WebhookTopicOrderCancelled        WebhookTopic = "order.cancelled"
+	WebhookTopicOrderPaymentCompleted WebhookTopic = "order.payment_completed"
+	WebhookTopicPaymentFailed         WebhookTopic = "payment.failed"
+)
+
+// WebhookTopics lists the topics endpoints can subscribe to
+var WebhookTopics = []WebhookTopic{
+	WebhookTopicOrderCreated,
+	WebhookTopicOrderPaid,
+	WebhookTopicOrderShipped,
+	WebhookTopicOrderDelivered,
+	WebhookTopicOrderCancelled,
+	WebhookTopicOrderPaymentCompleted,
+	WebhookTopicPaymentFailed,
+}
+
+// IsValid returns true if the topic is one endpoints can subscribe to
+func (t WebhookTopic) IsValid() bool {
+	for _, topic := range WebhookTopics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpoint registers an external URL that receives signed order and
+// payment lifecycle events for the topics it subscribes to. Removed endpoints are
+// soft-deleted so their delivery log is kept.
+type WebhookEndpoint struct {
+	ID        string         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
+	URL       string         `gorm:"type:varchar(500);not null" json:"url"`
+	Secret    string         `gorm:"type:varchar(255);not null" json:"-"`
+	Topics    StringList     `gorm:"type:jsonb;not null;default:'[]'" json:"topics"`
+	IsActive  bool           `gorm:"default:true;index" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (e *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for WebhookEndpoint model
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// Subscribes returns true if the endpoint receives events of the topic
+func (e *WebhookEndpoint) Subscribes(topic WebhookTopic) bool {
+	return e.Topics.Contains(string(topic))
+}
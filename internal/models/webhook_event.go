@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEventStatus defines the processing status of a webhook event
+type WebhookEventStatus string
+
+const (
+	WebhookEventStatusReceived   WebhookEventStatus = "received"
+	WebhookEventStatusProcessing WebhookEventStatus = "processing"
+	WebhookEventStatusProcessed  WebhookEventStatus = "processed"
+	WebhookEventStatusFailed     WebhookEventStatus = "failed"
+)
+
+// WebhookEventSource is where a webhook event came from. Sources are separate
+// namespaces of event IDs, so a partner cannot claim the ID of a gateway callback.
+type WebhookEventSource string
+
+const (
+	WebhookEventSourceGateway WebhookEventSource = "gateway"
+	WebhookEventSourcePartner WebhookEventSource = "partner"
+)
+
+// WebhookEvent records an inbound webhook delivery from a gateway or partner.
+// The source/provider/event ID triple is unique so redeliveries are detected.
+type WebhookEvent struct {
+	ID          string             `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Source      WebhookEventSource `gorm:"type:varchar(20);not null;default:'gateway';uniqueIndex:idx_webhook_events_source_event" json:"source"`
+	Provider    string             `gorm:"type:varchar(50);not null;uniqueIndex:idx_webhook_events_source_event" json:"provider"`
+	EventID     string             `gorm:"type:varchar(255);not null;uniqueIndex:idx_webhook_events_source_event" json:"event_id"`
+	EventType   string             `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	Payload     string             `gorm:"type:jsonb;not null" json:"payload"`
+	Status      WebhookEventStatus `gorm:"type:varchar(20);not null;default:'received';index" json:"status"`
+	Attempts    int                `gorm:"default:0" json:"attempts"`
+	LastError   string             `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessedAt *time.Time         `json:"processed_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not provided
+func (w *WebhookEvent) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TableName returns the table name for WebhookEvent model
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
+
+// IsProcessed returns true if the event has been handled successfully
+func (w *WebhookEvent) IsProcessed() bool {
+	return w.Status == WebhookEventStatusProcessed
+}
+
+// CanReplay returns true if the event failed and may be processed again
+func (w *WebhookEvent) CanReplay() bool {
+	return w.Status == WebhookEventStatusFailed
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+)
+
+// activityEventRepository implements ActivityEventRepository interface
+type activityEventRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewActivityEventRepository creates a new activity event repository
+func NewActivityEventRepository(db *database.DB, logger *logger.Logger) ActivityEventRepository {
+	return &activityEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *activityEventRepository) Create(ctx context.Context, event *models.ActivityEvent) error {
+	r.logger.Debug("Creating activity event", "user_id", event.UserID, "type", event.Type)
+
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		r.logger.Error("Failed to create activity event", "error", err, "user_id", event.UserID, "type", event.Type)
+		return err
+	}
+
+	return nil
+}
+
+func (r *activityEventRepository) CountActiveUsers(ctx context.Context, start, end time.Time) (int64, error) {
+	r.logger.Debug("Counting active users", "start", start, "end", end)
+
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.ActivityEvent{}).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Distinct("user_id").
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count active users", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *activityEventRepository) CountByType(ctx context.Context, start, end time.Time) ([]ActivityTypeCount, error) {
+	r.logger.Debug("Counting activity events by type", "start", start, "end", end)
+
+	var counts []ActivityTypeCount
+	if err := r.db.WithContext(ctx).
+		Model(&models.ActivityEvent{}).
+		Select("type, COUNT(*) AS events, COUNT(DISTINCT user_id) AS users").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("type").
+		Order("type").
+		Scan(&counts).Error; err != nil {
+		r.logger.Error("Failed to count activity events by type", "error", err)
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *activityEventRepository) DailyActiveUsers(ctx context.Context, start, end time.Time) ([]DailyActiveUserCount, error) {
+	r.logger.Debug("Counting daily active users", "start", start, "end", end)
+
+	var counts []DailyActiveUserCount
+	if err := r.db.WithContext(ctx).
+		Model(&models.ActivityEvent{}).
+		Select("date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, COUNT(DISTINCT user_id) AS active_users").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("day").
+		Order("day").
+		Scan(&counts).Error; err != nil {
+		r.logger.Error("Failed to count daily active users", "error", err)
+		return nil, err
+	}
+
+	return counts, nil
+}
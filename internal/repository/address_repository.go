@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// addressRepository implements AddressRepository interface
+type addressRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewAddressRepository creates a new saved address repository
+func NewAddressRepository(db *database.DB, logger *logger.Logger) AddressRepository {
+	return &addressRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *addressRepository) Create(ctx context.Context, address *models.Address) error {
+	r.logger.Debug("Creating address", "user_id", address.UserID, "is_default", address.IsDefault)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := tx.Model(&models.Address{}).
+				Where("user_id = ? AND is_default", address.UserID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to create address", "error", err, "user_id", address.UserID)
+		return err
+	}
+
+	r.logger.Info("Address created", "id", address.ID, "user_id", address.UserID)
+	return nil
+}
+
+func (r *addressRepository) GetByID(ctx context.Context, id string) (*models.Address, error) {
+	r.logger.Debug("Getting address by ID", "id", id)
+
+	var address models.Address
+	if err := r.db.WithContext(ctx).First(&address, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Address not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get address by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *addressRepository) ListByUserID(ctx context.Context, userID string) ([]*models.Address, error) {
+	r.logger.Debug("Listing addresses", "user_id", userID)
+
+	var addresses []*models.Address
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error; err != nil {
+		r.logger.Error("Failed to list addresses", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (r *addressRepository) GetDefault(ctx context.Context, userID string) (*models.Address, error) {
+	r.logger.Debug("Getting default address", "user_id", userID)
+
+	var address models.Address
+	if err := r.db.WithContext(ctx).First(&address, "user_id = ? AND is_default", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("User has no default address", "user_id", userID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get default address", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+func (r *addressRepository) SetDefault(ctx context.Context, userID, id string) error {
+	r.logger.Debug("Setting default address", "user_id", userID, "id", id)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND is_default AND id <> ?", userID, id).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Address{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("is_default", true).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to set default address", "error", err, "user_id", userID, "id", id)
+		return err
+	}
+
+	r.logger.Info("Default address set", "user_id", userID, "id", id)
+	return nil
+}
+
+func (r *addressRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting address", "id", id)
+
+	if err := r.db.WithContext(ctx).Delete(&models.Address{}, "id = ?", id).Error; err != nil {
+		r.logger.Error("Failed to delete address", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Address deleted", "id", id)
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *database.DB, logger *logger.Logger) APIKeyRepository {
+	return &apiKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	r.logger.Debug("Creating API key", "user_id", key.UserID, "prefix", key.Prefix)
+
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		r.logger.Error("Failed to create API key", "error", err, "user_id", key.UserID)
+		return err
+	}
+
+	r.logger.Info("API key created", "id", key.ID, "user_id", key.UserID)
+	return nil
+}
+
+func (r *apiKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	r.logger.Debug("Getting API key by ID", "id", id)
+
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).First(&key, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("API key not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get API key by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.WithContext(ctx).Preload("User").First(&key, "key_hash = ?", hash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get API key by hash", "error", err)
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) ListByUserID(ctx context.Context, userID string) ([]models.APIKey, error) {
+	r.logger.Debug("Listing API keys", "user_id", userID)
+
+	var keys []models.APIKey
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		r.logger.Error("Failed to list API keys", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, at time.Time) (bool, error) {
+	r.logger.Debug("Revoking API key", "id", id)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if update.Error != nil {
+		r.logger.Error("Failed to revoke API key", "error", update.Error, "id", id)
+		return false, update.Error
+	}
+
+	if update.RowsAffected > 0 {
+		r.logger.Info("API key revoked", "id", id)
+	}
+	return update.RowsAffected > 0, nil
+}
+
+func (r *apiKeyRepository) SetQuota(ctx context.Context, id string, quota *int) (bool, error) {
+	r.logger.Debug("Setting API key quota", "id", id, "quota", quota)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Update("monthly_quota", quota)
+	if update.Error != nil {
+		r.logger.Error("Failed to set API key quota", "error", update.Error, "id", id)
+		return false, update.Error
+	}
+
+	return update.RowsAffected > 0, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiUsageRepository implements APIUsageRepository interface
+type apiUsageRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewAPIUsageRepository creates a new API usage repository
+func NewAPIUsageRepository(db *database.DB, logger *logger.Logger) APIUsageRepository {
+	return &apiUsageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *apiUsageRepository) Increment(ctx context.Context, rows []models.APIUsage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	r.logger.Debug("Incrementing API usage", "rows", len(rows))
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "day"}, {Name: "api_key_id"}, {Name: "user_id"}, {Name: "method"}, {Name: "route"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "requests"}, Value: gorm.Expr("api_usage.requests + excluded.requests")},
+				{Column: clause.Column{Name: "errors"}, Value: gorm.Expr("api_usage.errors + excluded.errors")},
+				{Column: clause.Column{Name: "rejected"}, Value: gorm.Expr("api_usage.rejected + excluded.rejected")},
+				{Column: clause.Column{Name: "total_latency_ms"}, Value: gorm.Expr("api_usage.total_latency_ms + excluded.total_latency_ms")},
+				{Column: clause.Column{Name: "max_latency_ms"}, Value: gorm.Expr("GREATEST(api_usage.max_latency_ms, excluded.max_latency_ms)")},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("excluded.updated_at")},
+			},
+		}).
+		Create(&rows).Error; err != nil {
+		r.logger.Error("Failed to increment API usage", "error", err, "rows", len(rows))
+		return err
+	}
+
+	return nil
+}
+
+func (r *apiUsageRepository) CountRequests(ctx context.Context, apiKeyID string, from, to time.Time) (int64, error) {
+	var requests int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.APIUsage{}).
+		Select("COALESCE(SUM(requests), 0)").
+		Where("api_key_id = ? AND day >= ? AND day < ?", apiKeyID, from, to).
+		Scan(&requests).Error; err != nil {
+		r.logger.Error("Failed to count API key requests", "error", err, "api_key_id", apiKeyID)
+		return 0, err
+	}
+
+	return requests, nil
+}
+
+func (r *apiUsageRepository) SummarizeByEndpoint(ctx context.Context, filter APIUsageFilter) ([]APIUsageTotals, error) {
+	r.logger.Debug("Summarizing API usage by endpoint", "from", filter.From, "to", filter.To)
+
+	var totals []APIUsageTotals
+	if err := r.filtered(ctx, filter).
+		Select("method, route, " + usageTotalsColumns).
+		Group("method, route").
+		Order("requests DESC, route, method").
+		Scan(&totals).Error; err != nil {
+		r.logger.Error("Failed to summarize API usage by endpoint", "error", err)
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (r *apiUsageRepository) SummarizeByCaller(ctx context.Context, filter APIUsageFilter) ([]APIUsageTotals, error) {
+	r.logger.Debug("Summarizing API usage by caller", "from", filter.From, "to", filter.To)
+
+	var totals []APIUsageTotals
+	if err := r.filtered(ctx, filter).
+		Select("api_key_id, user_id, " + usageTotalsColumns).
+		Group("api_key_id, user_id").
+		Order("requests DESC, user_id, api_key_id").
+		Scan(&totals).Error; err != nil {
+		r.logger.Error("Failed to summarize API usage by caller", "error", err)
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// usageTotalsColumns sums the counts of grouped usage rows
+const usageTotalsColumns = `SUM(requests) AS requests,
+	SUM(errors) AS errors,
+	SUM(rejected) AS rejected,
+	SUM(total_latency_ms) AS total_latency_ms,
+	MAX(max_latency_ms) AS max_latency_ms`
+
+// filtered narrows the usage rows to the filter
+func (r *apiUsageRepository) filtered(ctx context.Context, filter APIUsageFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&models.APIUsage{}).
+		Where("day >= ? AND day < ?", filter.From, filter.To)
+	if filter.APIKeyID != "" {
+		query = query.Where("api_key_id = ?", filter.APIKeyID)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	return query
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backgroundJobRepository implements BackgroundJobRepository interface
+type backgroundJobRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewBackgroundJobRepository creates a new background job repository
+func NewBackgroundJobRepository(db *database.DB, logger *logger.Logger) BackgroundJobRepository {
+	return &backgroundJobRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *backgroundJobRepository) Enqueue(ctx context.Context, job *workers.StoredJob) error {
+	r.logger.Debug("Storing background job", "job_id", job.ID, "job_type", job.Type, "pool", job.Pool)
+
+	record := &models.BackgroundJob{
+		ID:         job.ID,
+		Type:       job.Type,
+		Pool:       job.Pool,
+		Status:     models.BackgroundJobStatusQueued,
+		Priority:   job.Priority,
+		Payload:    job.Payload,
+		MaxRetries: job.MaxRetries,
+		RunAt:      job.RunAt,
+	}
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		r.logger.Error("Failed to store background job", "error", err, "job_id", job.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *backgroundJobRepository) Lease(ctx context.Context, pool, owner string, limit int, visibility time.Duration) ([]*workers.StoredJob, error) {
+	now := time.Now()
+	leasedUntil := now.Add(visibility)
+
+	var records []*models.BackgroundJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Skip the jobs another instance is leasing at the same time
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("pool = ?", pool).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND leased_until < ?)",
+				models.BackgroundJobStatusQueued, now, models.BackgroundJobStatusLeased, now).
+			Order("priority DESC, run_at ASC").
+			Limit(limit).
+			Find(&records).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(records))
+		for i, record := range records {
+			ids[i] = record.ID
+		}
+		return tx.Model(&models.BackgroundJob{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":       models.BackgroundJobStatusLeased,
+				"lease_owner":  owner,
+				"leased_until": leasedUntil,
+				"attempts":     gorm.Expr("attempts + 1"),
+				"updated_at":   now,
+			}).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to lease background jobs", "error", err, "pool", pool)
+		return nil, err
+	}
+
+	jobs := make([]*workers.StoredJob, len(records))
+	for i, record := range records {
+		jobs[i] = &workers.StoredJob{
+			ID:         record.ID,
+			Type:       record.Type,
+			Pool:       record.Pool,
+			Priority:   record.Priority,
+			Payload:    record.Payload,
+			Attempts:   record.Attempts + 1,
+			MaxRetries: record.MaxRetries,
+			RunAt:      record.RunAt,
+		}
+	}
+	return jobs, nil
+}
+
+func (r *backgroundJobRepository) ExtendLease(ctx context.Context, id, owner string, visibility time.Duration) (bool, error) {
+	result := r.leased(ctx, id, owner).Updates(map[string]interface{}{
+		"leased_until": time.Now().Add(visibility),
+	})
+	if result.Error != nil {
+		r.logger.Error("Failed to extend background job lease", "error", result.Error, "job_id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *backgroundJobRepository) Release(ctx context.Context, id, owner string) error {
+	if err := r.leased(ctx, id, owner).Updates(map[string]interface{}{
+		"status":       models.BackgroundJobStatusQueued,
+		"lease_owner":  "",
+		"leased_until": nil,
+		"attempts":     gorm.Expr("attempts - 1"),
+	}).Error; err != nil {
+		r.logger.Error("Failed to release background job", "error", err, "job_id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *backgroundJobRepository) Complete(ctx context.Context, id, owner string) error {
+	if err := r.leased(ctx, id, owner).Delete(&models.BackgroundJob{}).Error; err != nil {
+		r.logger.Error("Failed to complete background job", "error", err, "job_id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *backgroundJobRepository) Retry(ctx context.Context, id, owner string, runAt time.Time, lastError string) error {
+	if err := r.leased(ctx, id, owner).Updates(map[string]interface{}{
+		"status":       models.BackgroundJobStatusQueued,
+		"run_at":       runAt,
+		"lease_owner":  "",
+		"leased_until": nil,
+		"last_error":   lastError,
+	}).Error; err != nil {
+		r.logger.Error("Failed to schedule background job retry", "error", err, "job_id", id)
+		return err
+	}
+
+	return nil
+}
+
+// Fail moves a job to the dead-letter queue
+func (r *backgroundJobRepository) Fail(ctx context.Context, id, owner string, lastError string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var job models.BackgroundJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ? AND lease_owner = ?", id, models.BackgroundJobStatusLeased, owner).
+			First(&job).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				// The lease was lost, and the job is the new owner's to settle
+				return nil
+			}
+			return err
+		}
+
+		dead := &models.DeadJob{
+			ID:         job.ID,
+			Type:       job.Type,
+			Pool:       job.Pool,
+			Priority:   job.Priority,
+			Payload:    job.Payload,
+			Attempts:   job.Attempts,
+			MaxRetries: job.MaxRetries,
+			LastError:  lastError,
+			QueuedAt:   job.CreatedAt,
+			DiedAt:     time.Now(),
+		}
+		if err := tx.Create(dead).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&job).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to move background job to the dead-letter queue", "error", err, "job_id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *backgroundJobRepository) CountQueuedJobs(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Pool  string
+		Count int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.BackgroundJob{}).
+		Select("pool, COUNT(*) AS count").
+		Where("status = ?", models.BackgroundJobStatusQueued).
+		Group("pool").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("Failed to count queued jobs", "error", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Pool] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *backgroundJobRepository) ListDeadJobs(ctx context.Context, filter DeadJobFilter, offset, limit int) ([]*models.DeadJob, error) {
+	r.logger.Debug("Listing dead jobs", "type", filter.Type, "pool", filter.Pool, "offset", offset, "limit", limit)
+
+	var jobs []*models.DeadJob
+	if err := r.deadJobs(ctx, filter).
+		Omit("payload").
+		Order("died_at DESC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		r.logger.Error("Failed to list dead jobs", "error", err)
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *backgroundJobRepository) CountDeadJobs(ctx context.Context, filter DeadJobFilter) (int64, error) {
+	var count int64
+	if err := r.deadJobs(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count dead jobs", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *backgroundJobRepository) GetDeadJob(ctx context.Context, id string) (*models.DeadJob, error) {
+	var job models.DeadJob
+	if err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get dead job", "error", err, "job_id", id)
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *backgroundJobRepository) RequeueDeadJob(ctx context.Context, id string, runAt time.Time) (*models.BackgroundJob, error) {
+	r.logger.Debug("Requeueing dead job", "job_id", id)
+
+	var requeued *models.BackgroundJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var dead models.DeadJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&dead, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		// The job starts over with all of its retries
+		job := &models.BackgroundJob{
+			ID:         dead.ID,
+			Type:       dead.Type,
+			Pool:       dead.Pool,
+			Status:     models.BackgroundJobStatusQueued,
+			Priority:   dead.Priority,
+			Payload:    dead.Payload,
+			MaxRetries: dead.MaxRetries,
+			RunAt:      runAt,
+			LastError:  dead.LastError,
+		}
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&dead).Error; err != nil {
+			return err
+		}
+		requeued = job
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to requeue dead job", "error", err, "job_id", id)
+		return nil, err
+	}
+
+	return requeued, nil
+}
+
+func (r *backgroundJobRepository) DeleteDeadJob(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&models.DeadJob{}, "id = ?", id)
+	if result.Error != nil {
+		r.logger.Error("Failed to delete dead job", "error", result.Error, "job_id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *backgroundJobRepository) PurgeDeadJobs(ctx context.Context, filter DeadJobFilter) (int64, error) {
+	result := r.deadJobs(ctx, filter).Delete(&models.DeadJob{})
+	if result.Error != nil {
+		r.logger.Error("Failed to purge dead jobs", "error", result.Error)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// deadJobs scopes a query to the dead jobs a filter selects
+func (r *backgroundJobRepository) deadJobs(ctx context.Context, filter DeadJobFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.DeadJob{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Pool != "" {
+		query = query.Where("pool = ?", filter.Pool)
+	}
+	if filter.DiedBefore != nil {
+		query = query.Where("died_at < ?", *filter.DiedBefore)
+	}
+	return query
+}
+
+// leased scopes a query to a job while owner holds its lease
+func (r *backgroundJobRepository) leased(ctx context.Context, id, owner string) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&models.BackgroundJob{}).
+		Where("id = ? AND status = ? AND lease_owner = ?", id, models.BackgroundJobStatusLeased, owner)
+}
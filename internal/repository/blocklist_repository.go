@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// blocklistRepository implements BlocklistRepository interface
+type blocklistRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewBlocklistRepository creates a new blocklist repository
+func NewBlocklistRepository(db *database.DB, logger *logger.Logger) BlocklistRepository {
+	return &blocklistRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *blocklistRepository) Create(ctx context.Context, entry *models.BlocklistEntry, auditLog *models.AuditLog) (bool, error) {
+	r.logger.Debug("Creating blocklist entry", "type", entry.Type)
+
+	var created bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "type"}, {Name: "value"}},
+			DoNothing: true,
+		}).Create(entry)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		created = true
+		auditLog.EntityID = entry.ID
+		return tx.Create(auditLog).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to create blocklist entry", "error", err, "type", entry.Type)
+		return false, err
+	}
+
+	if created {
+		r.logger.Info("Blocklist entry created", "id", entry.ID, "type", entry.Type)
+	}
+	return created, nil
+}
+
+func (r *blocklistRepository) GetByID(ctx context.Context, id string) (*models.BlocklistEntry, error) {
+	r.logger.Debug("Getting blocklist entry by ID", "id", id)
+
+	var entry models.BlocklistEntry
+	if err := r.db.WithContext(ctx).First(&entry, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get blocklist entry by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *blocklistRepository) List(ctx context.Context, filter BlocklistFilter, offset, limit int) ([]*models.BlocklistEntry, error) {
+	r.logger.Debug("Listing blocklist entries", "type", filter.Type, "search", filter.Search, "offset", offset, "limit", limit)
+
+	var entries []*models.BlocklistEntry
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		r.logger.Error("Failed to list blocklist entries", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *blocklistRepository) Count(ctx context.Context, filter BlocklistFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count blocklist entries", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered scopes a blocklist query to the filter
+func (r *blocklistRepository) filtered(ctx context.Context, filter BlocklistFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.BlocklistEntry{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Search != "" {
+		query = query.Where("value ILIKE ?", "%"+filter.Search+"%")
+	}
+	return query
+}
+
+func (r *blocklistRepository) Delete(ctx context.Context, id string, auditLog *models.AuditLog) (bool, error) {
+	r.logger.Debug("Deleting blocklist entry", "id", id)
+
+	var deleted bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.BlocklistEntry{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		deleted = true
+		return tx.Create(auditLog).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to delete blocklist entry", "error", err, "id", id)
+		return false, err
+	}
+
+	if deleted {
+		r.logger.Info("Blocklist entry deleted", "id", id)
+	}
+	return deleted, nil
+}
+
+func (r *blocklistRepository) FindMatches(ctx context.Context, match BlocklistMatch, at time.Time) ([]*models.BlocklistEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if len(match.Emails) > 0 {
+		conditions = append(conditions, "(type = ? AND value IN ?)")
+		args = append(args, models.BlocklistTypeEmail, match.Emails)
+	}
+	if len(match.Domains) > 0 {
+		conditions = append(conditions, "(type = ? AND value IN ?)")
+		args = append(args, models.BlocklistTypeDomain, match.Domains)
+	}
+	if len(match.CardFingerprints) > 0 {
+		conditions = append(conditions, "(type = ? AND value IN ?)")
+		args = append(args, models.BlocklistTypeCardFingerprint, match.CardFingerprints)
+	}
+	if match.IPAddress != "" {
+		conditions = append(conditions, "(type = ? AND value::cidr >>= ?::inet)")
+		args = append(args, models.BlocklistTypeIPRange, match.IPAddress)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var entries []*models.BlocklistEntry
+	if err := r.db.WithContext(ctx).
+		Where("(expires_at IS NULL OR expires_at > ?)", at).
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Order("type, value").
+		Find(&entries).Error; err != nil {
+		r.logger.Error("Failed to find blocklist matches", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *blocklistRepository) RecordHits(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.BlocklistEntry{}).
+		Where("id IN ?", ids).
+		UpdateColumns(map[string]interface{}{
+			"hit_count":   gorm.Expr("hit_count + 1"),
+			"last_hit_at": at,
+		}).Error; err != nil {
+		r.logger.Error("Failed to record blocklist hits", "error", err, "ids", ids)
+		return err
+	}
+	return nil
+}
+
+func (r *blocklistRepository) ListTopHits(ctx context.Context, limit int) ([]*models.BlocklistEntry, error) {
+	var entries []*models.BlocklistEntry
+	if err := r.db.WithContext(ctx).
+		Where("hit_count > 0").
+		Order("hit_count DESC, last_hit_at DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		r.logger.Error("Failed to list top blocklist hits", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Query cache namespaces. Products preload their inventory, so any stock
+// change invalidates the product namespaces as well as the inventory one.
+const (
+	ProductCacheNamespace        = "products"
+	ActiveProductsCacheNamespace = "active_products"
+	InventoryCacheNamespace      = "inventory"
+	AvailabilityCacheNamespace   = "availability"
+)
+
+// cachedProductRepository serves GetByID and GetActive from the query cache
+type cachedProductRepository struct {
+	ProductRepository
+	cache  *cache.QueryCache
+	logger *logger.Logger
+}
+
+// NewCachedProductRepository wraps a product repository with the query cache
+func NewCachedProductRepository(repo ProductRepository, queryCache *cache.QueryCache, logger *logger.Logger) ProductRepository {
+	return &cachedProductRepository{
+		ProductRepository: repo,
+		cache:             queryCache,
+		logger:            logger,
+	}
+}
+
+func (r *cachedProductRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	if cached, ok := r.cache.Get(ProductCacheNamespace, id); ok {
+		return cloneProduct(cached.(*models.Product)), nil
+	}
+
+	product, err := r.ProductRepository.GetByID(ctx, id)
+	if err != nil || product == nil {
+		return product, err
+	}
+
+	r.cache.Set(ProductCacheNamespace, id, cloneProduct(product))
+	return product, nil
+}
+
+func (r *cachedProductRepository) GetActive(ctx context.Context, offset, limit int) ([]*models.Product, error) {
+	key := fmt.Sprintf("%d:%d", offset, limit)
+	if cached, ok := r.cache.Get(ActiveProductsCacheNamespace, key); ok {
+		return cloneProducts(cached.([]*models.Product)), nil
+	}
+
+	products, err := r.ProductRepository.GetActive(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(ActiveProductsCacheNamespace, key, cloneProducts(products))
+	return products, nil
+}
+
+func (r *cachedProductRepository) Create(ctx context.Context, product *models.Product) error {
+	if err := r.ProductRepository.Create(ctx, product); err != nil {
+		return err
+	}
+	InvalidateProductCache(r.cache, product.ID)
+	return nil
+}
+
+func (r *cachedProductRepository) CreateWithInventory(ctx context.Context, product *models.Product, inventory *models.Inventory) error {
+	if err := r.ProductRepository.CreateWithInventory(ctx, product, inventory); err != nil {
+		return err
+	}
+	InvalidateProductCache(r.cache, product.ID)
+	return nil
+}
+
+func (r *cachedProductRepository) Update(ctx context.Context, product *models.Product) error {
+	if err := r.ProductRepository.Update(ctx, product); err != nil {
+		return err
+	}
+	InvalidateProductCache(r.cache, product.ID)
+	return nil
+}
+
+func (r *cachedProductRepository) Delete(ctx context.Context, id string) error {
+	if err := r.ProductRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	InvalidateProductCache(r.cache, id)
+	return nil
+}
+
+// cachedInventoryRepository serves GetByProductID from the query cache
+type cachedInventoryRepository struct {
+	InventoryRepository
+	cache  *cache.QueryCache
+	logger *logger.Logger
+}
+
+// NewCachedInventoryRepository wraps an inventory repository with the query cache
+func NewCachedInventoryRepository(repo InventoryRepository, queryCache *cache.QueryCache, logger *logger.Logger) InventoryRepository {
+	return &cachedInventoryRepository{
+		InventoryRepository: repo,
+		cache:               queryCache,
+		logger:              logger,
+	}
+}
+
+func (r *cachedInventoryRepository) GetByProductID(ctx context.Context, productID string) (*models.Inventory, error) {
+	if cached, ok := r.cache.Get(InventoryCacheNamespace, productID); ok {
+		return cloneInventory(cached.(*models.Inventory)), nil
+	}
+
+	inventory, err := r.InventoryRepository.GetByProductID(ctx, productID)
+	if err != nil || inventory == nil {
+		return inventory, err
+	}
+
+	r.cache.Set(InventoryCacheNamespace, productID, cloneInventory(inventory))
+	return inventory, nil
+}
+
+func (r *cachedInventoryRepository) Create(ctx context.Context, inventory *models.Inventory) error {
+	defer InvalidateProductCache(r.cache, inventory.ProductID)
+	return r.InventoryRepository.Create(ctx, inventory)
+}
+
+func (r *cachedInventoryRepository) UpdateStock(ctx context.Context, productID string, quantity int) error {
+	defer InvalidateProductCache(r.cache, productID)
+	return r.InventoryRepository.UpdateStock(ctx, productID, quantity)
+}
+
+func (r *cachedInventoryRepository) ReserveStock(ctx context.Context, productID string, quantity int) error {
+	defer InvalidateProductCache(r.cache, productID)
+	return r.InventoryRepository.ReserveStock(ctx, productID, quantity)
+}
+
+func (r *cachedInventoryRepository) ReleaseStock(ctx context.Context, productID string, quantity int) error {
+	defer InvalidateProductCache(r.cache, productID)
+	return r.InventoryRepository.ReleaseStock(ctx, productID, quantity)
+}
+
+func (r *cachedInventoryRepository) FulfillStock(ctx context.Context, productID string, quantity int) error {
+	defer InvalidateProductCache(r.cache, productID)
+	return r.InventoryRepository.FulfillStock(ctx, productID, quantity)
+}
+
+func (r *cachedInventoryRepository) BulkReserve(ctx context.Context, items []InventoryReservation) error {
+	defer InvalidateProductCache(r.cache, reservationProductIDs(items)...)
+	return r.InventoryRepository.BulkReserve(ctx, items)
+}
+
+func (r *cachedInventoryRepository) BulkRelease(ctx context.Context, items []InventoryReservation) error {
+	defer InvalidateProductCache(r.cache, reservationProductIDs(items)...)
+	return r.InventoryRepository.BulkRelease(ctx, items)
+}
+
+func (r *cachedInventoryRepository) BulkAdjustStock(ctx context.Context, items []InventoryStockAdjustment) error {
+	productIDs := make([]string, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	defer InvalidateProductCache(r.cache, productIDs...)
+	return r.InventoryRepository.BulkAdjustStock(ctx, items)
+}
+
+func (r *cachedInventoryRepository) Relocate(ctx context.Context, relocation InventoryRelocation) (bool, error) {
+	// Dropped even when the move lost a race, so that a retry reads the current location
+	defer InvalidateProductCache(r.cache, relocation.ProductID)
+	return r.InventoryRepository.Relocate(ctx, relocation)
+}
+
+// InvalidateProductCache drops cached product and inventory entries for the
+// given products, along with every cached active product page
+func InvalidateProductCache(queryCache *cache.QueryCache, productIDs ...string) {
+	queryCache.Invalidate(ProductCacheNamespace, productIDs...)
+	queryCache.Invalidate(InventoryCacheNamespace, productIDs...)
+	queryCache.InvalidateNamespace(ActiveProductsCacheNamespace)
+}
+
+// RegisterCacheInvalidationHooks invalidates the query cache after any GORM
+// write to the products, inventory or inventory shard tables. This covers writes
+// made outside the cached repositories, such as stock reservation in the order
+// transaction.
+// Writes inside a transaction are invalidated before commit, so a concurrent
+// read can re-cache the old row for at most one TTL.
+func RegisterCacheInvalidationHooks(db *database.DB, queryCache *cache.QueryCache) error {
+	hook := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+
+		switch tx.Statement.Schema.Table {
+		case models.Product{}.TableName(), models.Inventory{}.TableName(), models.InventoryShard{}.TableName():
+		default:
+			return
+		}
+
+		productIDs := statementProductIDs(tx.Statement)
+		if len(productIDs) == 0 {
+			// Bulk or filtered writes can touch any product
+			queryCache.InvalidateNamespace(ProductCacheNamespace)
+			queryCache.InvalidateNamespace(InventoryCacheNamespace)
+			queryCache.InvalidateNamespace(ActiveProductsCacheNamespace)
+			return
+		}
+		InvalidateProductCache(queryCache, productIDs...)
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("cache:invalidate_create", hook); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("cache:invalidate_update", hook); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("cache:invalidate_delete", hook)
+}
+
+// statementProductIDs extracts the affected product IDs from a write's model,
+// or returns nil when they can't be determined
+func statementProductIDs(statement *gorm.Statement) []string {
+	var productIDs []string
+	collect := func(value interface{}) bool {
+		switch v := value.(type) {
+		case *models.Product:
+			productIDs = append(productIDs, v.ID)
+		case *models.Inventory:
+			productIDs = append(productIDs, v.ProductID)
+		case []*models.Inventory:
+			for _, inventory := range v {
+				productIDs = append(productIDs, inventory.ProductID)
+			}
+		case *models.InventoryShard:
+			productIDs = append(productIDs, v.ProductID)
+		case []*models.InventoryShard:
+			for _, shard := range v {
+				productIDs = append(productIDs, shard.ProductID)
+			}
+		default:
+			return false
+		}
+		return true
+	}
+
+	if !collect(statement.Model) && !collect(statement.Dest) {
+		return nil
+	}
+	for _, id := range productIDs {
+		if id == "" {
+			return nil
+		}
+	}
+	return productIDs
+}
+
+// reservationProductIDs returns the product IDs of a reservation batch
+func reservationProductIDs(items []InventoryReservation) []string {
+	productIDs := make([]string, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	return productIDs
+}
+
+// cloneProduct copies a product so cached values can't be mutated by callers
+func cloneProduct(product *models.Product) *models.Product {
+	clone := *product
+	if product.Metadata != nil {
+		clone.Metadata = make(models.Metadata, len(product.Metadata))
+		for key, value := range product.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+	if product.Inventory != nil {
+		inventory := *product.Inventory
+		clone.Inventory = &inventory
+	}
+	return &clone
+}
+
+// cloneProducts copies a product list
+func cloneProducts(products []*models.Product) []*models.Product {
+	clones := make([]*models.Product, len(products))
+	for i, product := range products {
+		clones[i] = cloneProduct(product)
+	}
+	return clones
+}
+
+// cloneInventory copies an inventory record and its preloaded product
+func cloneInventory(inventory *models.Inventory) *models.Inventory {
+	clone := *inventory
+	if inventory.Product != nil {
+		clone.Product = cloneProduct(inventory.Product)
+	}
+	return &clone
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// catalogEventRepository implements CatalogEventRepository interface
+type catalogEventRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewCatalogEventRepository creates a new catalog event repository
+func NewCatalogEventRepository(db *database.DB, logger *logger.Logger) CatalogEventRepository {
+	return &catalogEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListSince returns up to limit events after the given sequence, leaving out events
+// recorded less than settle ago, like the inventory event stream
+func (r *catalogEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.CatalogEvent, error) {
+	r.logger.Debug("Listing catalog events", "since", sequence, "limit", limit)
+
+	var events []*models.CatalogEvent
+	if err := r.db.WithContext(ctx).
+		Where("sequence > ?", sequence).
+		Where("recorded_at <= NOW() - make_interval(secs => ?)", settle.Seconds()).
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		r.logger.Error("Failed to list catalog events", "error", err, "since", sequence)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *catalogEventRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.CatalogEvent{}).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&sequence).Error; err != nil {
+		r.logger.Error("Failed to get latest catalog event sequence", "error", err)
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
+func (r *catalogEventRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	var cursor models.CatalogEventCursor
+	if err := r.db.WithContext(ctx).First(&cursor, "consumer = ?", consumer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		r.logger.Error("Failed to get catalog event cursor", "error", err, "consumer", consumer)
+		return 0, false, err
+	}
+
+	return cursor.Sequence, true, nil
+}
+
+func (r *catalogEventRepository) SaveCursor(ctx context.Context, consumer string, sequence int64) error {
+	cursor := models.CatalogEventCursor{Consumer: consumer, Sequence: sequence}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sequence", "updated_at"}),
+	}).Create(&cursor).Error; err != nil {
+		r.logger.Error("Failed to save catalog event cursor", "error", err, "consumer", consumer, "sequence", sequence)
+		return err
+	}
+
+	return nil
+}
+
+// AppendCatalogEvents records changes to a product's storefront pages within tx,
+// after the product has been written
+func AppendCatalogEvents(tx *gorm.DB, product *models.Product, eventTypes ...models.CatalogEventType) error {
+	for _, eventType := range eventTypes {
+		if err := tx.Create(&models.CatalogEvent{
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Type:      eventType,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+)
+
+// channelListingRepository implements ChannelListingRepository interface
+type channelListingRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewChannelListingRepository creates a new channel listing repository
+func NewChannelListingRepository(db *database.DB, logger *logger.Logger) ChannelListingRepository {
+	return &channelListingRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *channelListingRepository) Create(ctx context.Context, listing *models.ChannelListing) error {
+	r.logger.Debug("Creating channel listing", "channel", listing.Channel, "channel_sku", listing.ChannelSKU, "product_id", listing.ProductID)
+
+	if err := r.db.WithContext(ctx).Create(listing).Error; err != nil {
+		r.logger.Error("Failed to create channel listing", "error", err, "channel", listing.Channel, "channel_sku", listing.ChannelSKU)
+		return err
+	}
+
+	r.logger.Info("Channel listing created", "id", listing.ID, "channel", listing.Channel, "channel_sku", listing.ChannelSKU)
+	return nil
+}
+
+func (r *channelListingRepository) GetByChannelSKUs(ctx context.Context, channel string, channelSKUs []string) ([]*models.ChannelListing, error) {
+	r.logger.Debug("Getting channel listings by SKUs", "channel", channel, "count", len(channelSKUs))
+
+	var listings []*models.ChannelListing
+	if len(channelSKUs) == 0 {
+		return listings, nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("channel = ? AND channel_sku IN ?", channel, channelSKUs).
+		Find(&listings).Error; err != nil {
+		r.logger.Error("Failed to get channel listings by SKUs", "error", err, "channel", channel)
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+func (r *channelListingRepository) ListByChannel(ctx context.Context, channel string) ([]*models.ChannelListing, error) {
+	r.logger.Debug("Listing channel listings", "channel", channel)
+
+	var listings []*models.ChannelListing
+	if err := r.db.WithContext(ctx).
+		Preload("Product").
+		Preload("Product.Inventory").
+		Where("channel = ?", channel).
+		Order("channel_sku ASC").
+		Find(&listings).Error; err != nil {
+		r.logger.Error("Failed to list channel listings", "error", err, "channel", channel)
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+func (r *channelListingRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting channel listing", "id", id)
+
+	if err := r.db.WithContext(ctx).Delete(&models.ChannelListing{}, "id = ?", id).Error; err != nil {
+		r.logger.Error("Failed to delete channel listing", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Channel listing deleted", "id", id)
+	return nil
+}
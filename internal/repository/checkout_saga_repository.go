@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+)
+
+// checkoutSagaRepository implements CheckoutSagaRepository interface
+type checkoutSagaRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewCheckoutSagaRepository creates a new checkout saga repository
+func NewCheckoutSagaRepository(db *database.DB, logger *logger.Logger) CheckoutSagaRepository {
+	return &checkoutSagaRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *checkoutSagaRepository) Create(ctx context.Context, saga *models.CheckoutSaga) error {
+	if err := r.db.WithContext(ctx).Create(saga).Error; err != nil {
+		r.logger.Error("Failed to create checkout saga", "error", err, "user_id", saga.UserID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *checkoutSagaRepository) Update(ctx context.Context, saga *models.CheckoutSaga) error {
+	result := r.db.WithContext(ctx).
+		Model(saga).
+		Updates(map[string]interface{}{
+			"order_id":   saga.OrderID,
+			"step":       saga.Step,
+			"status":     saga.Status,
+			"last_error": saga.LastError,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to update checkout saga", "error", result.Error, "id", saga.ID, "step", saga.Step)
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *checkoutSagaRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]*models.CheckoutSaga, error) {
+	var sagas []*models.CheckoutSaga
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", models.CheckoutSagaStatusRunning, before).
+		Order("updated_at").
+		Limit(limit).
+		Find(&sagas).Error; err != nil {
+		r.logger.Error("Failed to list stale checkout sagas", "error", err)
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+func (r *checkoutSagaRepository) Claim(ctx context.Context, saga *models.CheckoutSaga, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.CheckoutSaga{}).
+		Where("id = ? AND status = ? AND updated_at = ?", saga.ID, models.CheckoutSagaStatusRunning, saga.UpdatedAt).
+		Update("updated_at", at)
+	if result.Error != nil {
+		r.logger.Error("Failed to claim checkout saga", "error", result.Error, "id", saga.ID)
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	saga.UpdatedAt = at
+	return true, nil
+}
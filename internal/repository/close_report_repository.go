@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm/clause"
+)
+
+// closeReportRepository implements CloseReportRepository interface
+type closeReportRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewCloseReportRepository creates a new close report repository
+func NewCloseReportRepository(db *database.DB, logger *logger.Logger) CloseReportRepository {
+	return &closeReportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *closeReportRepository) Claim(ctx context.Context, store, date string, at time.Time) (bool, error) {
+	run := models.CloseReportRun{Store: store, Date: date, SentAt: at}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&run)
+	if result.Error != nil {
+		r.logger.Error("Failed to claim close report", "error", result.Error, "store", store, "date", date)
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *closeReportRepository) Release(ctx context.Context, store, date string) error {
+	if err := r.db.WithContext(ctx).
+		Where("store = ? AND date = ?", store, date).
+		Delete(&models.CloseReportRun{}).Error; err != nil {
+		r.logger.Error("Failed to release close report", "error", err, "store", store, "date", date)
+		return err
+	}
+
+	return nil
+}
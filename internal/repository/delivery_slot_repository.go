@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deliverySlotRepository implements DeliverySlotRepository interface
+type deliverySlotRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewDeliverySlotRepository creates a new delivery slot repository
+func NewDeliverySlotRepository(db *database.DB, logger *logger.Logger) DeliverySlotRepository {
+	return &deliverySlotRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *deliverySlotRepository) GetByID(ctx context.Context, id string) (*models.DeliverySlot, error) {
+	r.logger.Debug("Getting delivery slot by ID", "id", id)
+
+	var slot models.DeliverySlot
+	if err := r.db.WithContext(ctx).First(&slot, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get delivery slot by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &slot, nil
+}
+
+func (r *deliverySlotRepository) Plan(ctx context.Context, slots []*models.DeliverySlot) error {
+	r.logger.Debug("Planning delivery slots in database", "count", len(slots))
+
+	if len(slots) == 0 {
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "region"}, {Name: "date"}, {Name: "start_time"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "end_time"}, Value: gorm.Expr("excluded.end_time")},
+				{Column: clause.Column{Name: "capacity"}, Value: gorm.Expr("GREATEST(excluded.capacity, delivery_slots.reserved)")},
+				{Column: clause.Column{Name: "is_active"}, Value: true},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("NOW()")},
+			},
+		}).
+		Create(&slots).Error; err != nil {
+		r.logger.Error("Failed to plan delivery slots", "error", err, "count", len(slots))
+		return err
+	}
+
+	r.logger.Info("Delivery slots planned in database", "count", len(slots))
+	return nil
+}
+
+func (r *deliverySlotRepository) UpdateCapacity(ctx context.Context, id string, capacity int, isActive bool) (bool, error) {
+	r.logger.Debug("Updating delivery slot capacity", "id", id, "capacity", capacity, "is_active", isActive)
+
+	// Orders book the slot under its row lock, so the check against what they reserved
+	// and the update happen together
+	result := r.db.WithContext(ctx).Model(&models.DeliverySlot{}).
+		Where("id = ? AND reserved <= ?", id, capacity).
+		Updates(map[string]interface{}{
+			"capacity":  capacity,
+			"is_active": isActive,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to update delivery slot capacity", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *deliverySlotRepository) List(ctx context.Context, filter DeliverySlotFilter) ([]*models.DeliverySlot, error) {
+	r.logger.Debug("Listing delivery slots from database", "region", filter.Region, "from", filter.From, "to", filter.To)
+
+	query := r.db.WithContext(ctx).Where("date BETWEEN ? AND ?", filter.From, filter.To)
+	if filter.Region != "" {
+		query = query.Where("LOWER(region) = LOWER(?)", filter.Region)
+	}
+	if filter.Bookable {
+		query = query.Where("is_active AND reserved < capacity")
+	}
+
+	var slots []*models.DeliverySlot
+	if err := query.Order("date, region, start_time").Find(&slots).Error; err != nil {
+		r.logger.Error("Failed to list delivery slots", "error", err)
+		return nil, err
+	}
+
+	return slots, nil
+}
+
+// ReleaseDeliverySlot gives back the capacity an order held in a delivery slot within
+// db, e.g. when the order is cancelled
+func ReleaseDeliverySlot(db *gorm.DB, slotID string) error {
+	return db.Model(&models.DeliverySlot{}).
+		Where("id = ? AND reserved > 0", slotID).
+		UpdateColumns(map[string]interface{}{
+			"reserved":   gorm.Expr("reserved - 1"),
+			"updated_at": gorm.Expr("NOW()"),
+		}).Error
+}
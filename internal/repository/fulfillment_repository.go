@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fulfillmentRepository implements FulfillmentRepository interface
+type fulfillmentRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewFulfillmentRepository creates a new fulfillment repository
+func NewFulfillmentRepository(db *database.DB, logger *logger.Logger) FulfillmentRepository {
+	return &fulfillmentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *fulfillmentRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	var cursor models.OrderEventCursor
+	if err := r.db.WithContext(ctx).First(&cursor, "consumer = ?", consumer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		r.logger.Error("Failed to get order event cursor", "error", err, "consumer", consumer)
+		return 0, false, err
+	}
+
+	return cursor.Sequence, true, nil
+}
+
+func (r *fulfillmentRepository) Apply(ctx context.Context, consumer string, sequence int64, batch FulfillmentBatch, at time.Time) ([]string, error) {
+	r.logger.Debug("Applying order events to the fulfillment queue", "consumer", consumer, "sequence", sequence,
+		"confirm", len(batch.Confirm), "complete", len(batch.Complete), "cancel", len(batch.Cancel))
+
+	var confirmed []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if confirmed, err = confirmOrders(tx, batch.Confirm, at); err != nil {
+			return err
+		}
+		if err := closeFulfillmentTasks(tx, batch.Complete, models.FulfillmentTaskStatusCompleted, at); err != nil {
+			return err
+		}
+		if err := closeFulfillmentTasks(tx, batch.Cancel, models.FulfillmentTaskStatusCancelled, at); err != nil {
+			return err
+		}
+
+		cursor := models.OrderEventCursor{Consumer: consumer, Sequence: sequence}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "consumer"}},
+			DoUpdates: clause.AssignmentColumns([]string{"sequence", "updated_at"}),
+		}).Create(&cursor).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to apply order events to the fulfillment queue", "error", err, "consumer", consumer, "sequence", sequence)
+		return nil, err
+	}
+
+	return confirmed, nil
+}
+
+func (r *fulfillmentRepository) Confirm(ctx context.Context, orderID string, at time.Time) (bool, error) {
+	r.logger.Debug("Confirming order", "order_id", orderID)
+
+	var confirmed []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		confirmed, err = confirmOrders(tx, []string{orderID}, at)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to confirm order", "error", err, "order_id", orderID)
+		return false, err
+	}
+
+	return len(confirmed) > 0, nil
+}
+
+func (r *fulfillmentRepository) ListTasks(ctx context.Context, status models.FulfillmentTaskStatus, offset, limit int) ([]*models.FulfillmentTask, error) {
+	r.logger.Debug("Listing fulfillment tasks", "status", status, "offset", offset, "limit", limit)
+
+	var tasks []*models.FulfillmentTask
+	if err := r.db.WithContext(ctx).
+		Preload("Order").
+		Where("status = ?", status).
+		Order("queued_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&tasks).Error; err != nil {
+		r.logger.Error("Failed to list fulfillment tasks", "error", err, "status", status)
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *fulfillmentRepository) CountTasks(ctx context.Context, status models.FulfillmentTaskStatus) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.FulfillmentTask{}).
+		Where("status = ?", status).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count fulfillment tasks", "error", err, "status", status)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *fulfillmentRepository) ListAwaitingConfirmation(ctx context.Context, offset, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders awaiting confirmation", "offset", offset, "limit", limit)
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND confirmed_at IS NULL", models.OrderStatusPaid).
+		Order("updated_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders awaiting confirmation", "error", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *fulfillmentRepository) CountAwaitingConfirmation(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Where("status = ? AND confirmed_at IS NULL", models.OrderStatusPaid).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count orders awaiting confirmation", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// confirmOrders confirms the paid orders of orderIDs not confirmed yet within tx and
+// queues their fulfillment, returning the orders it confirmed
+func confirmOrders(tx *gorm.DB, orderIDs []string, at time.Time) ([]string, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	var confirmed []models.Order
+	if err := tx.Model(&confirmed).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("id IN ? AND status = ? AND confirmed_at IS NULL", orderIDs, models.OrderStatusPaid).
+		Update("confirmed_at", at).Error; err != nil {
+		return nil, err
+	}
+	if len(confirmed) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(confirmed))
+	tasks := make([]*models.FulfillmentTask, 0, len(confirmed))
+	for _, order := range confirmed {
+		ids = append(ids, order.ID)
+		tasks = append(tasks, &models.FulfillmentTask{
+			OrderID:  order.ID,
+			Status:   models.FulfillmentTaskStatusQueued,
+			QueuedAt: at,
+		})
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_id"}},
+		DoNothing: true,
+	}).Create(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// closeFulfillmentTasks closes the queued tasks of orderIDs within tx
+func closeFulfillmentTasks(tx *gorm.DB, orderIDs []string, status models.FulfillmentTaskStatus, at time.Time) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.FulfillmentTask{}).
+		Where("order_id IN ? AND status = ?", orderIDs, models.FulfillmentTaskStatusQueued).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"closed_at":  at,
+			"updated_at": at,
+		}).Error
+}
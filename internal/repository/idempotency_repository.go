@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyRepository implements IdempotencyRepository interface
+type idempotencyRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewIdempotencyRepository creates a new idempotency record repository
+func NewIdempotencyRepository(db *database.DB, logger *logger.Logger) IdempotencyRepository {
+	return &idempotencyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *idempotencyRepository) Claim(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	r.logger.Debug("Claiming idempotency key", "key", record.Key, "payment_id", record.PaymentID)
+
+	// The key's unique constraint settles concurrent claims from every replica; an
+	// expired record is replaced as if it had been purged
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"payment_id", "request_hash", "status", "result", "created_at", "last_accessed_at", "expires_at",
+			}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "idempotency_records.expires_at < excluded.created_at"},
+			}},
+		}).
+		Create(record)
+	if result.Error != nil {
+		r.logger.Error("Failed to claim idempotency key", "error", result.Error, "key", record.Key)
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		r.logger.Debug("Idempotency key already claimed", "key", record.Key)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (r *idempotencyRepository) GetByKey(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	r.logger.Debug("Getting idempotency record by key", "key", key)
+
+	var record models.IdempotencyRecord
+	if err := r.db.WithContext(ctx).First(&record, "key = ?", key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get idempotency record by key", "error", err, "key", key)
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Update(ctx context.Context, record *models.IdempotencyRecord) error {
+	r.logger.Debug("Updating idempotency record", "key", record.Key, "status", record.Status)
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.IdempotencyRecord{}).
+		Where("key = ?", record.Key).
+		Select("status", "result", "last_accessed_at").
+		Updates(record).Error; err != nil {
+		r.logger.Error("Failed to update idempotency record", "error", err, "key", record.Key)
+		return err
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, key string) error {
+	r.logger.Debug("Deleting idempotency record", "key", key)
+
+	if err := r.db.WithContext(ctx).Delete(&models.IdempotencyRecord{}, "key = ?", key).Error; err != nil {
+		r.logger.Error("Failed to delete idempotency record", "error", err, "key", key)
+		return err
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.logger.Debug("Deleting expired idempotency records", "before", before, "limit", limit)
+
+	batch := r.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).Select("key").
+		Where("expires_at < ?", before).
+		Limit(limit)
+	result := r.db.WithContext(ctx).
+		Where("key IN (?)", batch).
+		Delete(&models.IdempotencyRecord{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete expired idempotency records", "error", result.Error, "before", before)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *idempotencyRepository) CountExpired(ctx context.Context, before time.Time) (int64, int64, error) {
+	var counts struct {
+		Expired int64
+		Total   int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.IdempotencyRecord{}).
+		Select("COUNT(*) FILTER (WHERE expires_at < ?) AS expired, COUNT(*) AS total", before).
+		Scan(&counts).Error; err != nil {
+		r.logger.Error("Failed to count idempotency records", "error", err)
+		return 0, 0, err
+	}
+	return counts.Expired, counts.Total, nil
+}
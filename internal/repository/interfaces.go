@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/workers"
 )
 
 // Repository interfaces define contracts for data access layer
@@ -20,6 +21,12 @@ type UserRepository interface {
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, offset, limit int) ([]*models.User, error)
 	Count(ctx context.Context) (int64, error)
+	ListByOrganization(ctx context.Context, organizationID string) ([]*models.User, error)
+	// ListByRole returns the active users with the role
+	ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error)
+	// ListAfterID pages through every user, deleted ones included, in ID order
+	// starting after afterID, for exports that must keep the users orders reference
+	ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.User, error)
 }
 
 // ProductRepository defines product data access methods
@@ -28,14 +35,20 @@ type ProductRepository interface {
 	CreateWithInventory(ctx context.Context, product *models.Product, inventory *models.Inventory) error
 	GetByID(ctx context.Context, id string) (*models.Product, error)
 	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
+	GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error)
 	Update(ctx context.Context, product *models.Product) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, offset, limit int) ([]*models.Product, error)
-	Search(ctx context.Context, query string, offset, limit int) ([]*models.Product, error)
+	// Search returns active products whose SKU, name or description contain query,
+	// in the default locale or as translated in locale
+	Search(ctx context.Context, query, locale string, offset, limit int) ([]*models.Product, error)
 	GetActive(ctx context.Context, offset, limit int) ([]*models.Product, error)
 	Count(ctx context.Context) (int64, error)
 	CountActive(ctx context.Context) (int64, error)
-	CountSearch(ctx context.Context, query string) (int64, error)
+	CountSearch(ctx context.Context, query, locale string) (int64, error)
+	// ListAfterID pages through every product with its inventory, deleted ones
+	// included, in ID order starting after afterID
+	ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Product, error)
 }
 
 // OrderRepository defines order data access methods
@@ -43,15 +56,202 @@ type OrderRepository interface {
 	Create(ctx context.Context, order *models.Order) error
 	GetByID(ctx context.Context, id string) (*models.Order, error)
 	GetByIDWithItems(ctx context.Context, id string) (*models.Order, error)
+	// ListByIDsWithItems returns the orders with the given IDs that exist, with their items,
+	// products and product inventory preloaded
+	ListByIDsWithItems(ctx context.Context, ids []string) ([]*models.Order, error)
 	GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Order, error)
 	Update(ctx context.Context, order *models.Order) error
 	UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error
+	// TransitionStatus sets the order's status if, under its row lock, it can still
+	// transition to it. It reports false if it cannot, or the order does not exist.
+	TransitionStatus(ctx context.Context, id string, status models.OrderStatus) (bool, error)
 	List(ctx context.Context, offset, limit int) ([]*models.Order, error)
 	ListByStatus(ctx context.Context, status models.OrderStatus, offset, limit int) ([]*models.Order, error)
+	ListByMetadata(ctx context.Context, key, value string, offset, limit int) ([]*models.Order, error)
 	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Order, error)
 	Count(ctx context.Context) (int64, error)
 	CountByStatus(ctx context.Context, status models.OrderStatus) (int64, error)
 	CountByUserID(ctx context.Context, userID string) (int64, error)
+	// ListByUserID returns a page of the user's orders, newest first with their items and
+	// payments preloaded, only those in status unless it is empty
+	ListByUserID(ctx context.Context, userID string, status models.OrderStatus, offset, limit int) ([]*models.Order, error)
+	// CountByUserIDAndStatus counts the user's orders, only those in status unless it is empty
+	CountByUserIDAndStatus(ctx context.Context, userID string, status models.OrderStatus) (int64, error)
+	CountByMetadata(ctx context.Context, key, value string) (int64, error)
+	// ListByRiskBand returns a page of the scored orders whose risk score is in the band,
+	// highest score first
+	ListByRiskBand(ctx context.Context, band models.RiskBand, offset, limit int) ([]*models.Order, error)
+	CountByRiskBand(ctx context.Context, band models.RiskBand) (int64, error)
+	// UpdateRiskScore saves the order's risk score, signals and when it was scored
+	UpdateRiskScore(ctx context.Context, order *models.Order) error
+	GetByExternalID(ctx context.Context, channel, externalOrderID string) (*models.Order, error)
+	Iterate(filter OrderFilter, batchSize int) OrderIterator
+	ListByOrganization(ctx context.Context, organizationID string, offset, limit int) ([]*models.Order, error)
+	CountByOrganization(ctx context.Context, organizationID string) (int64, error)
+	// ListOpenInvoices returns on-account orders that are neither on credit hold,
+	// cancelled, failed nor fully paid, with the balance left to pay, oldest due date first
+	ListOpenInvoices(ctx context.Context) ([]OpenInvoice, error)
+	// GetOutstandingBalance sums the organization's open invoices, see ListOpenInvoices
+	GetOutstandingBalance(ctx context.Context, organizationID string) (float64, error)
+	ListCreditHolds(ctx context.Context) ([]*models.Order, error)
+	// ReviewCreditHold clears the order's credit hold, recording the reviewer, and sets
+	// its status. It reports false if the order was no longer on credit hold.
+	ReviewCreditHold(ctx context.Context, id, reviewerID string, status models.OrderStatus) (bool, error)
+	// OverrideItemPrice charges an item of a pending order a new unit price and updates the
+	// order total. It returns the order with its items, or nil if the order was not
+	// pending or has no such item.
+	OverrideItemPrice(ctx context.Context, orderID, itemID string, override ItemPriceOverride) (*models.Order, error)
+	// CancelItems takes the quantities, by product ID, off the items of an order that can
+	// still be cancelled, removing items with nothing left, and updates the order total.
+	// The cancelled items take their share of the checkout discount with them. It returns the order with its items and payments and how much its total went down,
+	// or nil if the order can no longer be cancelled or does not have the quantities.
+	CancelItems(ctx context.Context, orderID string, quantities map[string]int) (*models.Order, float64, error)
+	// RefreshPaidAmount sets the order's paid amount to the sum of its completed
+	// payments and returns it
+	RefreshPaidAmount(ctx context.Context, id string) (float64, error)
+	// ListCreditExposure returns the balances of organizations that have payment terms
+	// or open on-account orders, by organization name
+	ListCreditExposure(ctx context.Context) ([]CreditExposure, error)
+	// ListAwaitingShipment returns orders not shipped yet that were promised to ship
+	// by dueBy, earliest promise first
+	ListAwaitingShipment(ctx context.Context, dueBy time.Time) ([]*models.Order, error)
+	// GetByIdempotencyKey returns the user's order placed with an express checkout key,
+	// with its items and payments preloaded
+	GetByIdempotencyKey(ctx context.Context, userID, key string) (*models.Order, error)
+	// ListRecentByUser returns the user's orders placed since the given time, except
+	// cancelled and failed ones, newest first with their items preloaded
+	ListRecentByUser(ctx context.Context, userID string, since time.Time) ([]*models.Order, error)
+	// CountByUserSince counts the user's orders placed since the given time, in any status
+	CountByUserSince(ctx context.Context, userID string, since time.Time) (int64, error)
+	// ClearIdempotencyKeysBefore clears the idempotency keys of up to limit orders
+	// placed before the given time
+	ClearIdempotencyKeysBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountIdempotencyKeysBefore(ctx context.Context, before time.Time) (int64, error)
+	// AggregateSalesByDay sums the sales of orders placed in [start, end) per day, in the
+	// time zone of start, leaving out days without sales. See SoldOrderStatuses.
+	AggregateSalesByDay(ctx context.Context, start, end time.Time) ([]SalesDay, error)
+	// AggregateSalesByProduct sums the sales of each product ordered in [start, end),
+	// by revenue, highest first
+	AggregateSalesByProduct(ctx context.Context, start, end time.Time) ([]ProductSales, error)
+	// AggregateSalesByPaymentMethod sums the sales of orders placed in [start, end) per
+	// checkout payment method, by revenue, highest first
+	AggregateSalesByPaymentMethod(ctx context.Context, start, end time.Time) ([]PaymentMethodSales, error)
+	// AggregateSalesByCustomerType sums the sales of orders placed in [start, end) from
+	// new customers, whose first sale is in the range, and from returning customers
+	AggregateSalesByCustomerType(ctx context.Context, start, end time.Time) ([]CustomerTypeSales, error)
+	// AggregateSalesByAgent sums the sales of phone orders placed in [start, end) per
+	// agent who placed them, by revenue, highest first
+	AggregateSalesByAgent(ctx context.Context, start, end time.Time) ([]AgentSales, error)
+}
+
+// SoldOrderStatuses are the statuses of orders whose payment was taken and that count
+// as sales. Refunds of their payments are reported against the day they were placed.
+var SoldOrderStatuses = []models.OrderStatus{
+	models.OrderStatusPaid,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+}
+
+// SalesDay is the sales of the orders placed on one day
+type SalesDay struct {
+	Day            time.Time // Midnight UTC of the calendar day
+	Revenue        float64
+	RefundedAmount float64
+	Orders         int
+	Customers      int
+	ReturnedOrders int // Orders with a refund
+}
+
+// ProductSales is the sales of one product. Refunds are attributed to the items of
+// an order in proportion to their share of its total, so ReturnedQuantity is fractional
+// for partial refunds.
+type ProductSales struct {
+	ProductID        string
+	ProductName      string
+	SKU              string
+	CategoryID       *string
+	UnitCost         float64
+	Quantity         int
+	Revenue          float64
+	RefundedAmount   float64
+	ReturnedQuantity float64
+	Orders           int
+}
+
+// PaymentMethodSales is the sales of orders paid with one checkout payment method;
+// Method is empty for orders placed without choosing one
+type PaymentMethodSales struct {
+	Method  models.PaymentMethod
+	Orders  int
+	Revenue float64
+}
+
+// AgentSales is the sales of the phone orders placed by one agent
+type AgentSales struct {
+	AgentID        string
+	Orders         int
+	Revenue        float64
+	RefundedAmount float64
+}
+
+// CustomerTypeSales is the sales of new or returning customers
+type CustomerTypeSales struct {
+	New       bool `gorm:"column:is_new"`
+	Customers int
+	Orders    int
+	Revenue   float64
+}
+
+// OpenInvoice is an on-account order not fully paid with its organization; Amount is
+// the balance left to pay
+type OpenInvoice struct {
+	OrderID          string
+	OrganizationID   string
+	OrganizationName string
+	Amount           float64
+	InvoiceDueAt     time.Time
+}
+
+// CreditExposure is an organization's credit limit against its outstanding balance
+// and the orders held for exceeding it
+type CreditExposure struct {
+	OrganizationID    string
+	OrganizationName  string
+	CreditLimit       float64
+	CreditLimitAction models.CreditLimitAction
+	Outstanding       float64
+	HeldAmount        float64
+	HeldOrders        int64
+}
+
+// ItemPriceOverride is a unit price charged for an order item instead of its list
+// price, with the reason and the user who set it
+type ItemPriceOverride struct {
+	UnitPrice    float64
+	Reason       models.PriceOverrideReason
+	Note         string
+	OverriddenBy string
+}
+
+// OrderFilter narrows the orders walked by an OrderIterator; zero values match everything
+type OrderFilter struct {
+	Status        models.OrderStatus
+	MetadataKey   string
+	MetadataValue string
+	// CreatedFrom, when set, leaves out orders created before it
+	CreatedFrom time.Time
+	// RiskBand, when set, only matches scored orders with a risk score in the band
+	RiskBand models.RiskBand
+	// WithPayments also loads the payments of each order
+	WithPayments bool
+}
+
+// OrderIterator walks orders newest first in fixed-size batches. It pages with
+// a keyset cursor rather than an offset, so memory stays bounded and rows aren't
+// skipped or repeated when orders are created mid-walk.
+type OrderIterator interface {
+	// Next returns the next batch of orders, or an empty batch once exhausted
+	Next(ctx context.Context) ([]*models.Order, error)
 }
 
 // OrderItemRepository defines order item data access methods
@@ -72,8 +272,27 @@ type InventoryRepository interface {
 	ReleaseStock(ctx context.Context, productID string, quantity int) error
 	FulfillStock(ctx context.Context, productID string, quantity int) error
 	GetLowStockItems(ctx context.Context, threshold int) ([]*models.Inventory, error)
+	ListWithProducts(ctx context.Context) ([]*models.Inventory, error)
+	GetUnitsSoldSince(ctx context.Context, since time.Time) (map[string]int, error)
 	BulkReserve(ctx context.Context, items []InventoryReservation) error
 	BulkRelease(ctx context.Context, items []InventoryReservation) error
+	BulkAdjustStock(ctx context.Context, items []InventoryStockAdjustment) error
+	// Relocate moves a product's stock to another bin location and writes the audit log
+	// of the move in the same transaction. It reports false if the product has no
+	// inventory or its stock was no longer at the expected location.
+	Relocate(ctx context.Context, relocation InventoryRelocation) (bool, error)
+	// SetSafetyStock sets the units of a product held back from online sales, lowering or
+	// raising its available stock, and writes the audit log of the change in the same
+	// transaction. It returns nil if the product has no inventory.
+	SetSafetyStock(ctx context.Context, productID string, safetyStock int, auditLog *models.AuditLog) (*models.Inventory, error)
+}
+
+// InventoryRelocation moves a product's stock from one bin location to another
+type InventoryRelocation struct {
+	ProductID string
+	From      string
+	To        string
+	AuditLog  *models.AuditLog
 }
 
 // InventoryReservation represents a stock reservation request
@@ -82,15 +301,135 @@ type InventoryReservation struct {
 	Quantity  int
 }
 
+// InventoryStockAdjustment sets the on-hand quantity of a product,
+// guarded by the inventory version that was read when computing it
+type InventoryStockAdjustment struct {
+	ProductID       string
+	Quantity        int
+	ExpectedVersion int
+}
+
 // PaymentRepository defines payment data access methods
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *models.Payment) error
+	// CreateWithinBalance creates a gateway payment while the order row is locked, so
+	// concurrent payments of one order are serialized. It reports false, creating
+	// nothing, if the payment exceeds the order total less its completed payments and
+	// the payments still in progress: pending, awaiting confirmation or held.
+	CreateWithinBalance(ctx context.Context, payment *models.Payment) (bool, error)
 	GetByID(ctx context.Context, id string) (*models.Payment, error)
 	GetByTransactionID(ctx context.Context, transactionID string) (*models.Payment, error)
+	// GetByGatewayTransactionID returns the payment a gateway knows by its own
+	// transaction ID
+	GetByGatewayTransactionID(ctx context.Context, gateway, gatewayTxnID string) (*models.Payment, error)
 	GetByOrderID(ctx context.Context, orderID string) ([]*models.Payment, error)
+	// SumCompletedByOrderID sums the amounts of the order's completed payments
+	SumCompletedByOrderID(ctx context.Context, orderID string) (float64, error)
 	Update(ctx context.Context, payment *models.Payment) error
 	UpdateStatus(ctx context.Context, id string, status models.PaymentStatus) error
 	List(ctx context.Context, offset, limit int) ([]*models.Payment, error)
+	// SummarizeSettlement aggregates settled payments processed in [start, end) by payment method
+	SummarizeSettlement(ctx context.Context, start, end time.Time) ([]PaymentSettlementSummary, error)
+	// ListHeldDue returns held payments whose retry is due, or whose retry window has ended, at now
+	ListHeldDue(ctx context.Context, now time.Time, limit int) ([]*models.Payment, error)
+	// ClaimHeld moves a held payment back to pending before it is retried. It reports
+	// false if the payment was no longer held, e.g. because another retry claimed it.
+	ClaimHeld(ctx context.Context, id string) (bool, error)
+	// GetByIdempotencyKey returns the payment recorded under an idempotency key
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error)
+	// RecordManual records an offline payment in one transaction with its proof and
+	// audit log, and marks a pending or confirmed order as paid once its payments cover
+	// the total. It reports false if the order was no longer in the expected status,
+	// or another payment was settled or held for retry in the meantime.
+	RecordManual(ctx context.Context, record ManualPaymentRecord) (bool, error)
+	// GetProof returns the proof attached to an offline payment
+	GetProof(ctx context.Context, paymentID string) (*models.PaymentProof, error)
+	// ListForReconciliation returns up to limit gateway payments to check against their
+	// gateway, least recently updated first: pending or processed payments neither
+	// updated nor checked since stuckBefore, and payments failed since failedSince that
+	// were not checked after they failed
+	ListForReconciliation(ctx context.Context, stuckBefore, failedSince time.Time, limit int) ([]*models.Payment, error)
+	// Reconcile saves the status, failure reason and processing time a reconciliation
+	// corrected the payment to, along with its ReconciledAt, if its stored status is
+	// still from. It reports false if the payment had moved on in the meantime.
+	Reconcile(ctx context.Context, payment *models.Payment, from models.PaymentStatus) (bool, error)
+	// MarkReconciled records that the payment was checked against its gateway at the time
+	MarkReconciled(ctx context.Context, id string, at time.Time) error
+	// ListForPolling returns up to limit pending or processed payments of the gateways
+	// that were neither updated nor checked since stuckBefore, least recently updated first
+	ListForPolling(ctx context.Context, gateways []string, stuckBefore time.Time, limit int) ([]*models.Payment, error)
+	// MarkEscalated records that ops were notified of the stuck payment at the time
+	MarkEscalated(ctx context.Context, id string, at time.Time) error
+}
+
+// ManualPaymentRecord is an offline payment recorded by an admin. OrderStatus is the
+// status the order was in when the payment was validated, and PaidAmount the sum of
+// its completed payments then; Proof may be nil.
+type ManualPaymentRecord struct {
+	Payment     *models.Payment
+	Proof       *models.PaymentProof
+	AuditLog    *models.AuditLog
+	OrderStatus models.OrderStatus
+	PaidAmount  float64
+}
+
+// RefundRepository defines the interface for refund data operations
+type RefundRepository interface {
+	// Record applies a refund to its payment and records it in one transaction, under
+	// the payment's row lock. RefundedBefore is what had been refunded of the payment
+	// when the refund was validated; it returns the refunded payment, or nil if the
+	// payment was refunded or changed status in the meantime.
+	Record(ctx context.Context, refund *models.Refund, refundedBefore float64) (*models.Payment, error)
+	// ListByPaymentID returns the refunds of a payment, oldest first
+	ListByPaymentID(ctx context.Context, paymentID string) ([]*models.Refund, error)
+}
+
+// IdempotencyRepository defines payment idempotency record data access methods
+type IdempotencyRepository interface {
+	// Claim creates the record unless its key is held by a record that has not expired
+	// yet, taking over expired ones. It reports false if the key was held.
+	Claim(ctx context.Context, record *models.IdempotencyRecord) (bool, error)
+	// GetByKey returns the record of a key, expired or not, or nil when there is none
+	GetByKey(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+	// Update saves the record's status, result and last access
+	Update(ctx context.Context, record *models.IdempotencyRecord) error
+	Delete(ctx context.Context, key string) error
+	// DeleteExpired deletes up to limit records that expired before the time
+	DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error)
+	// CountExpired counts the records that expired before the time, out of total
+	CountExpired(ctx context.Context, before time.Time) (expired, total int64, err error)
+}
+
+// PaymentReconciliationRepository defines payment reconciliation record data access methods
+type PaymentReconciliationRepository interface {
+	CreateBatch(ctx context.Context, records []*models.PaymentReconciliation) error
+	// ListByRunID returns the records of a reconciliation run, mismatches first
+	ListByRunID(ctx context.Context, runID string) ([]*models.PaymentReconciliation, error)
+	// ListBetween returns the records created in [start, end), mismatches first
+	ListBetween(ctx context.Context, start, end time.Time) ([]*models.PaymentReconciliation, error)
+}
+
+// PaymentRoutingRuleRepository defines payment routing rule data access methods
+type PaymentRoutingRuleRepository interface {
+	Create(ctx context.Context, rule *models.PaymentRoutingRule) error
+	GetByID(ctx context.Context, id string) (*models.PaymentRoutingRule, error)
+	GetByName(ctx context.Context, name string) (*models.PaymentRoutingRule, error)
+	Update(ctx context.Context, rule *models.PaymentRoutingRule) error
+	Delete(ctx context.Context, id string) error
+	// List returns the rules in evaluation order, by descending priority then name,
+	// only the active ones with activeOnly
+	List(ctx context.Context, activeOnly bool) ([]*models.PaymentRoutingRule, error)
+}
+
+// PaymentSettlementSummary aggregates the settled payments of one method with the
+// checkout surcharges and discounts (as positive amounts) of their orders
+type PaymentSettlementSummary struct {
+	Method         models.PaymentMethod
+	Payments       int64
+	GrossAmount    float64
+	Surcharges     float64
+	Discounts      float64
+	ProcessingFees float64
 }
 
 // NotificationRepository defines notification data access methods
@@ -99,6 +438,37 @@ type NotificationRepository interface {
 	GetByID(ctx context.Context, id string) (*models.Notification, error)
 	GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Notification, error)
 	GetUnreadByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Notification, error)
+	// GetByOrderID returns notifications whose data references the order, oldest first
+	GetByOrderID(ctx context.Context, orderID string) ([]*models.Notification, error)
+	// MarkSent records when the notification was sent and the channel that delivered it.
+	// In-app notifications are delivered as they are sent, so their delivery is recorded too.
+	MarkSent(ctx context.Context, id string, channel models.NotificationChannel, sentAt time.Time) error
+	// MarkDelivered records when the notification was delivered, reporting false when
+	// its delivery was already recorded
+	MarkDelivered(ctx context.Context, id string, deliveredAt time.Time) (bool, error)
+	// MarkRead records when the notification was read, and that it was delivered by
+	// then, reporting false when it was already read
+	MarkRead(ctx context.Context, id string, readAt time.Time) (bool, error)
+	// SummarizeDelivery groups notifications created in [start, end) by type and the
+	// channel that delivered them, or the requested channel when none did
+	SummarizeDelivery(ctx context.Context, start, end time.Time) ([]NotificationDeliverySummary, error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NotificationDeliverySummary counts the notifications of one type and channel that
+// were created, sent, delivered and read. DeliverySeconds adds up the time from
+// sending to delivery of the delivered ones, and ReadSeconds the time from delivery
+// to reading of the read ones.
+type NotificationDeliverySummary struct {
+	Type            models.NotificationType
+	Channel         models.NotificationChannel
+	Notifications   int64
+	Sent            int64
+	Delivered       int64
+	Read            int64
+	DeliverySeconds float64
+	ReadSeconds     float64
 }
 
 // AuditLogRepository defines audit log data access methods
@@ -109,3 +479,824 @@ type AuditLogRepository interface {
 	GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.AuditLog, error)
 	List(ctx context.Context, offset, limit int) ([]*models.AuditLog, error)
 }
+
+// WebhookEventRepository defines webhook event log data access methods
+type WebhookEventRepository interface {
+	// CreateIfNotExists stores the event unless its source/provider/event ID triple
+	// exists, returning false when the event was already recorded
+	CreateIfNotExists(ctx context.Context, event *models.WebhookEvent) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.WebhookEvent, error)
+	GetByProviderEventID(ctx context.Context, source models.WebhookEventSource, provider, eventID string) (*models.WebhookEvent, error)
+	// Claim moves an event from one of the given statuses to processing,
+	// returning false when another worker already claimed it
+	Claim(ctx context.Context, id string, fromStatuses ...models.WebhookEventStatus) (bool, error)
+	MarkProcessed(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, reason string) error
+	ListByStatus(ctx context.Context, status models.WebhookEventStatus, offset, limit int) ([]*models.WebhookEvent, error)
+	CountByStatus(ctx context.Context, status models.WebhookEventStatus) (int64, error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	CountCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StockWebhookSubscriptionRepository defines stock-change webhook subscription data access methods
+type StockWebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.StockWebhookSubscription) error
+	GetByID(ctx context.Context, id string) (*models.StockWebhookSubscription, error)
+	List(ctx context.Context) ([]*models.StockWebhookSubscription, error)
+	ListActive(ctx context.Context) ([]*models.StockWebhookSubscription, error)
+	Delete(ctx context.Context, id string) error
+	// RecordDelivery stores the outcome of the latest delivery attempt;
+	// an empty deliveryErr marks a successful delivery
+	RecordDelivery(ctx context.Context, id string, deliveryErr string) error
+}
+
+// WebhookEndpointRepository defines outbound webhook endpoint data access methods
+type WebhookEndpointRepository interface {
+	Create(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	GetByID(ctx context.Context, id string) (*models.WebhookEndpoint, error)
+	List(ctx context.Context) ([]*models.WebhookEndpoint, error)
+	// ListSubscribed returns the active endpoints subscribed to the topic
+	ListSubscribed(ctx context.Context, topic models.WebhookTopic) ([]*models.WebhookEndpoint, error)
+	Update(ctx context.Context, endpoint *models.WebhookEndpoint) error
+	// Delete removes the endpoint and fails its pending deliveries
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryFilter selects webhook deliveries; empty fields match everything
+type WebhookDeliveryFilter struct {
+	EndpointID string
+	Topic      models.WebhookTopic
+	Status     models.WebhookDeliveryStatus
+}
+
+// WebhookDeliveryRepository defines outbound webhook delivery log data access methods
+type WebhookDeliveryRepository interface {
+	// Enqueue stores pending deliveries, skipping those of an event already queued
+	// for the endpoint
+	Enqueue(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	// EnqueueRelayed stores the deliveries of events read from a relayed stream and
+	// moves the relay's cursor to sequence, atomically
+	EnqueueRelayed(ctx context.Context, relay string, sequence int64, deliveries []*models.WebhookDelivery) error
+	// GetRelayCursor returns the last sequence a relay enqueued, 0 before its first run
+	GetRelayCursor(ctx context.Context, relay string) (int64, error)
+	// ClaimDue returns up to limit pending deliveries to active endpoints that are due
+	// at now, with their endpoint. Their next attempt is pushed back by lease so other
+	// instances skip them while they are sent.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error)
+	// RecordAttempt stores the outcome of the delivery's latest attempt
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error)
+	List(ctx context.Context, filter WebhookDeliveryFilter, offset, limit int) ([]*models.WebhookDelivery, error)
+	Count(ctx context.Context, filter WebhookDeliveryFilter) (int64, error)
+	// Requeue makes a failed delivery pending again from at, returning false when the
+	// delivery is no longer failed
+	Requeue(ctx context.Context, id string, at time.Time) (bool, error)
+}
+
+// AddressRepository defines saved shipping address data access methods
+type AddressRepository interface {
+	// Create stores the address; a default address replaces the user's previous default
+	Create(ctx context.Context, address *models.Address) error
+	GetByID(ctx context.Context, id string) (*models.Address, error)
+	ListByUserID(ctx context.Context, userID string) ([]*models.Address, error)
+	GetDefault(ctx context.Context, userID string) (*models.Address, error)
+	// SetDefault makes the address the user's default, replacing the previous one
+	SetDefault(ctx context.Context, userID, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SavedPaymentMethodRepository defines saved payment method data access methods
+type SavedPaymentMethodRepository interface {
+	// Create stores the payment method; a default method replaces the user's previous default
+	Create(ctx context.Context, method *models.SavedPaymentMethod) error
+	GetByID(ctx context.Context, id string) (*models.SavedPaymentMethod, error)
+	ListByUserID(ctx context.Context, userID string) ([]*models.SavedPaymentMethod, error)
+	GetDefault(ctx context.Context, userID string) (*models.SavedPaymentMethod, error)
+	// SetDefault makes the payment method the user's default, replacing the previous one
+	SetDefault(ctx context.Context, userID, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ChannelListingRepository defines channel SKU mapping data access methods
+type ChannelListingRepository interface {
+	Create(ctx context.Context, listing *models.ChannelListing) error
+	GetByChannelSKUs(ctx context.Context, channel string, channelSKUs []string) ([]*models.ChannelListing, error)
+	// ListByChannel returns the channel's listings with their product and inventory preloaded
+	ListByChannel(ctx context.Context, channel string) ([]*models.ChannelListing, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// StockHoldRepository defines cart stock hold data access methods. Holding and
+// releasing adjust the product's reserved inventory in the same transaction.
+type StockHoldRepository interface {
+	// Hold creates the user's active hold on a product, or resizes it and moves its expiry
+	Hold(ctx context.Context, hold *models.StockHold) error
+	GetActive(ctx context.Context, userID, productID string) (*models.StockHold, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]*models.StockHold, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.StockHold, error)
+	// Release ends an active hold with the given status and returns its stock.
+	// It reports false if the hold was no longer active.
+	Release(ctx context.Context, id string, status models.StockHoldStatus) (bool, error)
+	// CountByStatus aggregates holds created in [start, end) by their current status
+	CountByStatus(ctx context.Context, start, end time.Time) ([]StockHoldStatusCount, error)
+}
+
+// StockHoldStatusCount is the number of holds, and units held, in one status
+type StockHoldStatusCount struct {
+	Status models.StockHoldStatus
+	Holds  int64
+	Units  int64
+}
+
+// PaymentAttemptRepository defines payment attempt data access methods
+type PaymentAttemptRepository interface {
+	Create(ctx context.Context, attempt *models.PaymentAttempt) error
+	// SummarizeAttempts groups attempts started in [start, end) by gateway, method,
+	// failure type, UTC hour of day and whether the attempt was a retry
+	SummarizeAttempts(ctx context.Context, start, end time.Time) ([]PaymentAttemptSummary, error)
+}
+
+// PaymentAttemptSummary counts the attempts, and successful attempts, in one group
+type PaymentAttemptSummary struct {
+	Gateway     string
+	Method      models.PaymentMethod
+	FailureType string // Empty for successful attempts
+	Hour        int
+	Retry       bool
+	Attempts    int64
+	Successes   int64
+}
+
+// ProductAvailabilityRepository defines storefront availability read model data access methods
+type ProductAvailabilityRepository interface {
+	GetByProductID(ctx context.Context, productID string) (*models.ProductAvailability, error)
+	// Refresh re-materializes the products' availability from inventory and returns
+	// the rows written; products without inventory are skipped
+	Refresh(ctx context.Context, productIDs []string) ([]*models.ProductAvailability, error)
+}
+
+// OrganizationRepository defines B2B customer account data access methods
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *models.Organization) error
+	GetByID(ctx context.Context, id string) (*models.Organization, error)
+	GetByName(ctx context.Context, name string) (*models.Organization, error)
+	Update(ctx context.Context, organization *models.Organization) error
+	List(ctx context.Context, offset, limit int) ([]*models.Organization, error)
+	Count(ctx context.Context) (int64, error)
+	// ListAfterID pages through every organization, deleted ones included, in ID
+	// order starting after afterID
+	ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Organization, error)
+}
+
+// InventoryEventRepository defines inventory change event data access methods.
+// Events are appended by the inventory writers with AppendInventoryEvent.
+type InventoryEventRepository interface {
+	ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.InventoryEvent, error)
+	// ListHistory returns a product's inventory events merged with the relocations
+	// of its stock, newest first
+	ListHistory(ctx context.Context, productID string, offset, limit int) ([]InventoryHistoryEntry, error)
+	CountHistory(ctx context.Context, productID string) (int64, error)
+}
+
+// InventoryHistoryEntry is an inventory event of a product, or a relocation of its
+// stock between bin locations with Type "transferred". Stock levels are only known
+// for events, and locations and reason only for relocations.
+type InventoryHistoryEntry struct {
+	Type          string
+	Sequence      *int64
+	Quantity      int
+	OnHand        *int
+	Reserved      *int
+	Available     *int
+	FromLocation  *string
+	ToLocation    *string
+	Reason        string
+	ActorID       *string
+	ReferenceType string
+	ReferenceID   string
+	OccurredAt    time.Time
+}
+
+// OrderEventRepository defines order lifecycle event data access methods.
+// Events are appended by the order writers with AppendOrderEvent.
+type OrderEventRepository interface {
+	ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.OrderEvent, error)
+	// LatestSequence returns the sequence of the last event recorded, 0 when there is none
+	LatestSequence(ctx context.Context) (int64, error)
+	// GetCursor returns the last sequence a consumer of the outbox handled, and false
+	// before its first run
+	GetCursor(ctx context.Context, consumer string) (int64, bool, error)
+	// SaveCursor moves the cursor of a consumer to sequence
+	SaveCursor(ctx context.Context, consumer string, sequence int64) error
+}
+
+// CatalogEventRepository defines catalog change event data access methods. Events
+// are appended by the product writers with AppendCatalogEvents, and by the refresh of
+// product availability when a product goes in or out of stock.
+type CatalogEventRepository interface {
+	ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.CatalogEvent, error)
+	// LatestSequence returns the sequence of the last event recorded, 0 when there is none
+	LatestSequence(ctx context.Context) (int64, error)
+	// GetCursor returns the last sequence a consumer of the stream handled, and false
+	// before its first run
+	GetCursor(ctx context.Context, consumer string) (int64, bool, error)
+	// SaveCursor moves the cursor of a consumer to sequence
+	SaveCursor(ctx context.Context, consumer string, sequence int64) error
+}
+
+// FulfillmentRepository defines the data access methods of order confirmation and the
+// fulfillment queue
+type FulfillmentRepository interface {
+	// GetCursor returns the last order event sequence a consumer applied, and false
+	// before its first run
+	GetCursor(ctx context.Context, consumer string) (int64, bool, error)
+	// Apply applies a batch of order events in one transaction and moves the consumer's
+	// cursor to sequence. It returns the orders it confirmed.
+	Apply(ctx context.Context, consumer string, sequence int64, batch FulfillmentBatch, at time.Time) ([]string, error)
+	// Confirm confirms a paid order and queues it for fulfillment, returning false when
+	// the order is not paid or was already confirmed
+	Confirm(ctx context.Context, orderID string, at time.Time) (bool, error)
+	// ListTasks returns the tasks in a status, oldest queued first
+	ListTasks(ctx context.Context, status models.FulfillmentTaskStatus, offset, limit int) ([]*models.FulfillmentTask, error)
+	CountTasks(ctx context.Context, status models.FulfillmentTaskStatus) (int64, error)
+	// ListAwaitingConfirmation returns paid orders not confirmed yet, oldest first
+	ListAwaitingConfirmation(ctx context.Context, offset, limit int) ([]*models.Order, error)
+	CountAwaitingConfirmation(ctx context.Context) (int64, error)
+}
+
+// FulfillmentBatch is what a batch of order events does to the fulfillment queue:
+// paid orders in Confirm not confirmed yet are confirmed and queued, and the queued
+// tasks of orders in Complete and Cancel are closed
+type FulfillmentBatch struct {
+	Confirm  []string
+	Complete []string
+	Cancel   []string
+}
+
+// BackgroundJobRepository stores the jobs of the worker pools, which lease them through
+// the workers.JobStore methods, and the dead-letter queue of the jobs they failed
+type BackgroundJobRepository interface {
+	workers.JobStore
+
+	// CountQueuedJobs returns how many stored jobs wait to be leased, due or not, by pool
+	CountQueuedJobs(ctx context.Context) (map[string]int64, error)
+
+	// ListDeadJobs returns dead jobs without their payload, most recently failed first
+	ListDeadJobs(ctx context.Context, filter DeadJobFilter, offset, limit int) ([]*models.DeadJob, error)
+	CountDeadJobs(ctx context.Context, filter DeadJobFilter) (int64, error)
+	GetDeadJob(ctx context.Context, id string) (*models.DeadJob, error)
+	// RequeueDeadJob moves a dead job back to the queue to run at runAt with all of its
+	// retries, returning nil when there is no such dead job
+	RequeueDeadJob(ctx context.Context, id string, runAt time.Time) (*models.BackgroundJob, error)
+	// DeleteDeadJob returns false when there is no such dead job
+	DeleteDeadJob(ctx context.Context, id string) (bool, error)
+	// PurgeDeadJobs deletes the dead jobs a filter selects and returns how many
+	PurgeDeadJobs(ctx context.Context, filter DeadJobFilter) (int64, error)
+}
+
+// DeadJobFilter selects dead jobs by type and pool, and that failed before DiedBefore,
+// leaving out the criteria that are not set
+type DeadJobFilter struct {
+	Type       string
+	Pool       string
+	DiedBefore *time.Time
+}
+
+// LedgerRepository defines double-entry ledger data access methods. Entries are
+// only ever appended.
+type LedgerRepository interface {
+	// Append posts the lines of one ledger transaction atomically, returning false
+	// when a transaction with the same key was already posted
+	Append(ctx context.Context, entries []*models.LedgerEntry) (bool, error)
+	// List returns entries in posting order; empty filter fields match everything
+	List(ctx context.Context, filter LedgerFilter, offset, limit int) ([]*models.LedgerEntry, error)
+	Count(ctx context.Context, filter LedgerFilter) (int64, error)
+	// SumByAccount totals the debits and credits of the named account, or of every
+	// account of a kind ("customer" covers each "customer:<user ID>"), by account
+	SumByAccount(ctx context.Context, account string) ([]LedgerAccountTotals, error)
+	// ListUnbalanced returns the transactions whose debits and credits differ
+	ListUnbalanced(ctx context.Context, limit int) ([]LedgerTransactionTotals, error)
+	// ListPaymentMismatches returns the payments in the given statuses whose debits
+	// posted by transactions of the type do not add up to the payment amount
+	ListPaymentMismatches(ctx context.Context, transactionType models.LedgerTransactionType, statuses []models.PaymentStatus, limit int) ([]LedgerPaymentMismatch, error)
+}
+
+// LedgerFilter narrows the ledger entries listed
+type LedgerFilter struct {
+	Account   string
+	OrderID   string
+	PaymentID string
+}
+
+// LedgerAccountTotals sums the entries posted to one account
+type LedgerAccountTotals struct {
+	Account string
+	Debits  float64
+	Credits float64
+	Entries int64
+}
+
+// LedgerTransactionTotals sums the lines of one ledger transaction
+type LedgerTransactionTotals struct {
+	TransactionKey string
+	Debits         float64
+	Credits        float64
+}
+
+// LedgerPaymentMismatch is a payment whose ledger postings of one type do not match
+// its amount; Posted is zero when nothing was posted
+type LedgerPaymentMismatch struct {
+	PaymentID string
+	OrderID   string
+	Status    models.PaymentStatus
+	Amount    float64
+	Posted    float64
+}
+
+// OrderHoldRepository defines order compliance hold data access methods. Placing
+// and releasing a hold also sets the order's OnHold flag, in the same transaction.
+type OrderHoldRepository interface {
+	// Place puts the order on hold. It reports false if the order already had an
+	// active hold or could no longer be held.
+	Place(ctx context.Context, hold *models.OrderHold) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.OrderHold, error)
+	// GetActiveByOrderID returns the order's active hold, if any
+	GetActiveByOrderID(ctx context.Context, orderID string) (*models.OrderHold, error)
+	// ListByOrderID returns the order's holds, newest first
+	ListByOrderID(ctx context.Context, orderID string) ([]*models.OrderHold, error)
+	// List returns holds oldest due first; empty filter fields match everything
+	List(ctx context.Context, filter OrderHoldFilter, offset, limit int) ([]*models.OrderHold, error)
+	Count(ctx context.Context, filter OrderHoldFilter) (int64, error)
+	// Assign assigns an active hold to a reviewer. It reports false if the hold was
+	// released in the meantime.
+	Assign(ctx context.Context, id, assigneeID string, at time.Time) (bool, error)
+	// Release releases an active hold and clears the order's OnHold flag. It reports
+	// false if the hold was already released.
+	Release(ctx context.Context, id, releasedBy, note string, at time.Time) (bool, error)
+	// SummarizeByReason aggregates the holds placed in [start, end) by reason, counting
+	// those still active past their SLA at now as overdue
+	SummarizeByReason(ctx context.Context, start, end, now time.Time) ([]OrderHoldSummary, error)
+	// CountHeldOrders counts the orders placed in [start, end) and how many of them
+	// were ever put on hold
+	CountHeldOrders(ctx context.Context, start, end time.Time) (orders int64, held int64, err error)
+}
+
+// OrderHoldFilter narrows the holds listed. Overdue holds are active past their SLA at OverdueAt.
+type OrderHoldFilter struct {
+	Active     *bool
+	Reason     models.OrderHoldReason
+	AssignedTo string
+	OverdueAt  *time.Time
+}
+
+// OrderHoldSummary aggregates the holds of one reason. HoldSeconds sums how long the
+// released holds were held; WithinSLA counts those released by their due time.
+type OrderHoldSummary struct {
+	Reason      models.OrderHoldReason
+	Placed      int64
+	Released    int64
+	Active      int64
+	Overdue     int64
+	WithinSLA   int64
+	HoldSeconds float64
+}
+
+// ActivityEventRepository defines user activity event data access methods.
+// Ranges are half-open: start inclusive, end exclusive.
+type ActivityEventRepository interface {
+	Create(ctx context.Context, event *models.ActivityEvent) error
+	CountActiveUsers(ctx context.Context, start, end time.Time) (int64, error)
+	CountByType(ctx context.Context, start, end time.Time) ([]ActivityTypeCount, error)
+	DailyActiveUsers(ctx context.Context, start, end time.Time) ([]DailyActiveUserCount, error)
+}
+
+// ActivityTypeCount aggregates the events of one type in a range
+type ActivityTypeCount struct {
+	Type   models.ActivityEventType
+	Events int64
+	Users  int64
+}
+
+// DailyActiveUserCount is the number of distinct users active on a UTC day
+type DailyActiveUserCount struct {
+	Day         time.Time
+	ActiveUsers int64
+}
+
+// ReportResultRepository defines stored report result data access methods
+type ReportResultRepository interface {
+	Create(ctx context.Context, result *models.ReportResult) error
+	GetByID(ctx context.Context, id string) (*models.ReportResult, error)
+	// Transition saves the result's status and outcome if its stored status is one of
+	// from. It reports false if the result had moved on in the meantime.
+	Transition(ctx context.Context, result *models.ReportResult, from ...models.ReportResultStatus) (bool, error)
+	// FailUnfinished fails the results still pending or generating that were created
+	// before the time, such as those interrupted by a restart
+	FailUnfinished(ctx context.Context, before time.Time, reason string) (int64, error)
+}
+
+// APIKeyRepository defines API key data access methods
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByID(ctx context.Context, id string) (*models.APIKey, error)
+	// GetByHash returns the key with the hash, with its user, or nil when there is none
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	ListByUserID(ctx context.Context, userID string) ([]models.APIKey, error)
+	// Revoke revokes the key at the time. It reports false if it was already revoked.
+	Revoke(ctx context.Context, id string, at time.Time) (bool, error)
+	// SetQuota sets the key's monthly quota, or removes it when nil
+	SetQuota(ctx context.Context, id string, quota *int) (bool, error)
+}
+
+// APIUsageFilter narrows API usage to the days in [From, To) and, when set, to one
+// API key or user
+type APIUsageFilter struct {
+	From     time.Time
+	To       time.Time
+	APIKeyID string
+	UserID   string
+}
+
+// APIUsageTotals sums the API usage of one endpoint, or of one caller
+type APIUsageTotals struct {
+	APIKeyID       string
+	UserID         string
+	Method         string
+	Route          string
+	Requests       int64
+	Errors         int64
+	Rejected       int64
+	TotalLatencyMs int64
+	MaxLatencyMs   int64
+}
+
+// APIUsageRepository defines API usage data access methods
+type APIUsageRepository interface {
+	// Increment adds the counts of the rows to the stored usage of their day, caller
+	// and endpoint, keeping the highest max latency
+	Increment(ctx context.Context, rows []models.APIUsage) error
+	// CountRequests sums the requests the API key made on the days in [from, to)
+	CountRequests(ctx context.Context, apiKeyID string, from, to time.Time) (int64, error)
+	// SummarizeByEndpoint sums the usage matching the filter by method and route
+	SummarizeByEndpoint(ctx context.Context, filter APIUsageFilter) ([]APIUsageTotals, error)
+	// SummarizeByCaller sums the usage matching the filter by API key and user
+	SummarizeByCaller(ctx context.Context, filter APIUsageFilter) ([]APIUsageTotals, error)
+}
+
+// ReferralRepository defines referral and referral code data access methods
+type ReferralRepository interface {
+	GetCodeByUserID(ctx context.Context, userID string) (*models.ReferralCode, error)
+	GetCodeByCode(ctx context.Context, code string) (*models.ReferralCode, error)
+	// CreateCode stores a user's referral code. It reports false, storing nothing, if
+	// the user already has a code or the code is taken.
+	CreateCode(ctx context.Context, code *models.ReferralCode) (bool, error)
+	// Create stores a referral. It reports false if the referee was already referred.
+	Create(ctx context.Context, referral *models.Referral) (bool, error)
+	ListByReferrerID(ctx context.Context, referrerID string) ([]models.Referral, error)
+	// AttributeOrder attributes the order to the referee's referral if the referral
+	// has no order yet, or its order was cancelled or failed
+	AttributeOrder(ctx context.Context, refereeID, orderID string, at time.Time) (bool, error)
+	// ListSettleable lists the referrals waiting on an order that was delivered before
+	// deliveredBefore, or that was cancelled or failed, oldest first
+	ListSettleable(ctx context.Context, deliveredBefore time.Time, limit int) ([]ReferralOrderOutcome, error)
+	// Reward marks a referral waiting on its order rewarded and grants the referrer the
+	// store credit, atomically. It reports false if the referral was no longer waiting.
+	Reward(ctx context.Context, referral *models.Referral, credit *models.StoreCreditTransaction) (bool, error)
+	// Void marks a referral waiting on its order void for the reason
+	Void(ctx context.Context, id, reason string) (bool, error)
+	// ReleaseOrder detaches the order of a referral waiting on it, so the referee's
+	// next order can be attributed
+	ReleaseOrder(ctx context.Context, id string) (bool, error)
+	// SummarizeByReferrer aggregates the referrals made in [start, end) by referrer
+	SummarizeByReferrer(ctx context.Context, start, end time.Time) ([]ReferrerSummary, error)
+}
+
+// ReferralOrderOutcome is a referral waiting on its order, with how the order ended:
+// its status, whether it was deleted, its total and the amount refunded of it
+type ReferralOrderOutcome struct {
+	ID           string
+	ReferrerID   string
+	RefereeID    string
+	OrderID      string
+	OrderStatus  models.OrderStatus
+	OrderDeleted bool
+	OrderTotal   float64
+	Refunded     float64
+}
+
+// ReferrerSummary aggregates the referrals of one referrer. Ordered counts referees
+// who placed a first order, and Revenue sums those orders unless cancelled or failed.
+type ReferrerSummary struct {
+	ReferrerID string
+	Name       string
+	Email      string
+	Signups    int64
+	Ordered    int64
+	Rewarded   int64
+	Voided     int64
+	Rewards    float64
+	Revenue    float64
+}
+
+// StoreCreditRepository defines store credit data access methods
+type StoreCreditRepository interface {
+	// GetBalance sums the user's store credit transactions
+	GetBalance(ctx context.Context, userID string) (float64, error)
+	// ListByUserID returns the user's latest store credit transactions, newest first
+	ListByUserID(ctx context.Context, userID string, limit int) ([]models.StoreCreditTransaction, error)
+}
+
+// BlocklistRepository defines blocklist data access methods
+type BlocklistRepository interface {
+	// Create adds the entry with the audit log of its creation in one transaction. It
+	// reports false if the value was already listed for its type.
+	Create(ctx context.Context, entry *models.BlocklistEntry, auditLog *models.AuditLog) (bool, error)
+	GetByID(ctx context.Context, id string) (*models.BlocklistEntry, error)
+	// List returns entries newest first; empty filter fields match everything
+	List(ctx context.Context, filter BlocklistFilter, offset, limit int) ([]*models.BlocklistEntry, error)
+	Count(ctx context.Context, filter BlocklistFilter) (int64, error)
+	// Delete removes the entry with the audit log of its removal in one transaction. It
+	// reports false if the entry was already removed.
+	Delete(ctx context.Context, id string, auditLog *models.AuditLog) (bool, error)
+	// FindMatches returns the entries active at at that match any of the values
+	FindMatches(ctx context.Context, match BlocklistMatch, at time.Time) ([]*models.BlocklistEntry, error)
+	// RecordHits counts a blocked attempt against each entry
+	RecordHits(ctx context.Context, ids []string, at time.Time) error
+	// ListTopHits returns the entries that blocked the most attempts
+	ListTopHits(ctx context.Context, limit int) ([]*models.BlocklistEntry, error)
+}
+
+// PriceListRepository defines B2B price list data access methods
+type PriceListRepository interface {
+	Create(ctx context.Context, priceList *models.PriceList) error
+	GetByID(ctx context.Context, id string) (*models.PriceList, error)
+	GetByName(ctx context.Context, name string) (*models.PriceList, error)
+	Update(ctx context.Context, priceList *models.PriceList) error
+	List(ctx context.Context, offset, limit int) ([]*models.PriceList, error)
+	Count(ctx context.Context) (int64, error)
+	// ListItems returns the rows of a price list by product, then start
+	ListItems(ctx context.Context, priceListID string) ([]*models.PriceListItem, error)
+	// ImportItems adds rows to a price list in one transaction, first removing its
+	// existing rows when replace is set
+	ImportItems(ctx context.Context, priceListID string, items []*models.PriceListItem, replace bool) error
+	// FindEffectivePrices returns the prices of the products in effect at at, by
+	// product ID; products without one are left out
+	FindEffectivePrices(ctx context.Context, priceListID string, productIDs []string, at time.Time) (map[string]float64, error)
+}
+
+// PromotionRepository defines checkout promotion data access methods
+type PromotionRepository interface {
+	Create(ctx context.Context, promotion *models.Promotion) error
+	GetByID(ctx context.Context, id string) (*models.Promotion, error)
+	GetByName(ctx context.Context, name string) (*models.Promotion, error)
+	Update(ctx context.Context, promotion *models.Promotion) error
+	// List returns promotions, latest starting first, only those running at activeAt
+	// unless it is nil
+	List(ctx context.Context, activeAt *time.Time, offset, limit int) ([]*models.Promotion, error)
+	Count(ctx context.Context, activeAt *time.Time) (int64, error)
+	// ListRunning returns the active promotions whose window includes at, in evaluation
+	// order: by descending priority, then start and ID
+	ListRunning(ctx context.Context, at time.Time) ([]*models.Promotion, error)
+	// SummarizeRedemptions aggregates the promotions applied to orders placed in
+	// [start, end), by promotion
+	SummarizeRedemptions(ctx context.Context, start, end time.Time) ([]PromotionSummary, error)
+}
+
+// PromotionSummary aggregates the orders a promotion was applied to. Orders counts
+// them all; Redeemed, Discount and Revenue leave out cancelled and failed orders.
+type PromotionSummary struct {
+	PromotionID string
+	Name        string
+	Type        models.PromotionType
+	Orders      int64
+	Redeemed    int64
+	Discount    float64
+	Revenue     float64
+}
+
+// DeliverySlotRepository defines delivery slot capacity data access methods
+type DeliverySlotRepository interface {
+	GetByID(ctx context.Context, id string) (*models.DeliverySlot, error)
+	// Plan creates the slots, or updates the end time and capacity of those already
+	// planned for their region, day and start time and reactivates them. A capacity is
+	// never set below the orders already booked into the slot.
+	Plan(ctx context.Context, slots []*models.DeliverySlot) error
+	// UpdateCapacity sets the capacity of a slot and whether it takes new orders. It
+	// reports false if more orders than the capacity are booked into the slot.
+	UpdateCapacity(ctx context.Context, id string, capacity int, isActive bool) (bool, error)
+	// List returns the slots of the filter, by day, region and start time
+	List(ctx context.Context, filter DeliverySlotFilter) ([]*models.DeliverySlot, error)
+}
+
+// DeliverySlotFilter narrows the slots listed to an inclusive range of days and a
+// region unless it is empty; Bookable leaves out inactive and fully booked slots
+type DeliverySlotFilter struct {
+	Region   string
+	From     time.Time
+	To       time.Time
+	Bookable bool
+}
+
+// BlocklistFilter narrows the entries listed. Search matches part of the value.
+type BlocklistFilter struct {
+	Type   models.BlocklistType
+	Search string
+}
+
+// BlocklistMatch holds the normalized values of an attempt checked against the
+// blocklist. Domains are matched exactly, so they include every parent domain.
+type BlocklistMatch struct {
+	Emails           []string
+	Domains          []string
+	CardFingerprints []string
+	IPAddress        string
+}
+
+// SupportSearchRepository defines the lookups customer support combines to find orders,
+// users and payments from partial details
+type SupportSearchRepository interface {
+	// FindUsers returns the users matching the user inputs of the filter, newest first
+	FindUsers(ctx context.Context, filter SupportSearchFilter, limit int) ([]*models.User, error)
+	// FindOrders returns the orders matching every input of the filter, newest first
+	FindOrders(ctx context.Context, filter SupportSearchFilter, limit int) ([]*models.Order, error)
+	// ListPaymentsByOrders returns the payments of the orders, newest first
+	ListPaymentsByOrders(ctx context.Context, orderIDs []string) ([]*models.Payment, error)
+}
+
+// SupportSearchFilter holds the inputs of a support lookup; empty inputs match
+// everything. Email matches part of the user's email, CardLast4 the last 4 digits of a
+// card the user saved and TrackingNumber part of the tracking_number metadata of an
+// order. Orders are matched on Total to the cent and placed on the UTC day of Date.
+type SupportSearchFilter struct {
+	Email          string
+	CardLast4      string
+	TrackingNumber string
+	Total          *float64
+	Date           *time.Time
+}
+
+// CloseReportRepository records the end-of-day close reports sent
+type CloseReportRepository interface {
+	// Claim records the close report of a store for a day as sent, returning false when
+	// it already was
+	Claim(ctx context.Context, store, date string, at time.Time) (bool, error)
+	// Release forgets a claimed close report that could not be sent, so it is retried
+	Release(ctx context.Context, store, date string) error
+}
+
+// CheckoutSagaRepository persists the progress of express checkouts through their steps
+type CheckoutSagaRepository interface {
+	Create(ctx context.Context, saga *models.CheckoutSaga) error
+	// Update saves the order, step, status and last error of a saga
+	Update(ctx context.Context, saga *models.CheckoutSaga) error
+	// ListStale lists the running sagas last updated before the given time, oldest first
+	ListStale(ctx context.Context, before time.Time, limit int) ([]*models.CheckoutSaga, error)
+	// Claim marks a stale saga as being recovered at the given time, returning false
+	// when it was updated since it was listed, by its own request or another instance
+	Claim(ctx context.Context, saga *models.CheckoutSaga, at time.Time) (bool, error)
+}
+
+// InventoryShardRepository splits the stock of hot products into shards reserved
+// round-robin, so their orders do not queue on one inventory row. Orders reserve
+// sharded stock through ReserveInventory, and every inventory read overlays the shards,
+// so callers see the same stock levels whether a product is sharded or not.
+type InventoryShardRepository interface {
+	// Configure splits a product's available stock evenly into shards, or gathers it back
+	// onto its inventory row when shards is 0, and writes the audit log of the change in
+	// the same transaction. It returns nil if the product has no inventory.
+	Configure(ctx context.Context, productID string, shards int, auditLog *models.AuditLog) (*models.Inventory, error)
+	List(ctx context.Context, productID string) ([]*models.InventoryShard, error)
+	// Rebalance evens out the shards of the products whose shards drifted apart or whose
+	// inventory row has stock to hand out, and returns how many it rebalanced
+	Rebalance(ctx context.Context) (int, error)
+}
+
+// PaymentIntentRepository persists customers' intents to pay orders by card
+type PaymentIntentRepository interface {
+	// Create saves an intent unless its order already has an open one, expiring the
+	// order's lapsed intents first. It reports false when another intent is open, and
+	// returns gorm.ErrRecordNotFound if the order does not exist.
+	Create(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error)
+	// GetByID returns the intent, or nil if it does not exist
+	GetByID(ctx context.Context, id string) (*models.PaymentIntent, error)
+	// Claim moves an intent awaiting confirmation and not expired at now to processing
+	// before its payment is taken. It reports false if it was confirmed, cancelled or
+	// expired in the meantime.
+	Claim(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error)
+	// Transition saves the status, payment and last error of an intent still in status
+	// from, and reports false if it had moved on
+	Transition(ctx context.Context, intent *models.PaymentIntent, from models.PaymentIntentStatus) (bool, error)
+	// ListProcessing lists the processing intents with a payment, least recently updated first
+	ListProcessing(ctx context.Context, limit int) ([]*models.PaymentIntent, error)
+	// ExpireLapsed expires the intents still awaiting confirmation at their expiry, and
+	// returns how many it expired
+	ExpireLapsed(ctx context.Context, now time.Time) (int, error)
+}
+
+// ProductSerialRepository tracks the serial numbers of serialized products, from the
+// lot they were received in to the order item they ship on
+type ProductSerialRepository interface {
+	// Receive records the serial numbers of a received lot, skipping those the product
+	// already has, and returns the serials it recorded
+	Receive(ctx context.Context, serials []*models.ProductSerial) ([]*models.ProductSerial, error)
+	// ListByProduct lists the serials of a product, optionally of one lot, in the order
+	// they were received
+	ListByProduct(ctx context.Context, productID, lotNumber string, offset, limit int) ([]*models.ProductSerial, error)
+	CountByProduct(ctx context.Context, productID, lotNumber string) (int64, error)
+	// Capture allocates received serial numbers of the item's product to an order item and
+	// stores them on it, releasing those it held before. Serials allocated to items of
+	// cancelled or failed orders are reallocated. It returns the serial numbers that were
+	// never received or are allocated to another order, capturing nothing when there are
+	// any.
+	Capture(ctx context.Context, item *models.OrderItem, serialNumbers []string, at time.Time) ([]string, error)
+}
+
+// WarrantyClaimRepository persists customers' warranty claims on serialized units and
+// their resolution
+type WarrantyClaimRepository interface {
+	// Create saves a claim unless its unit already has an open claim, which it reports
+	// with false
+	Create(ctx context.Context, claim *models.WarrantyClaim) (bool, error)
+	// GetByID returns the claim, or nil if it does not exist
+	GetByID(ctx context.Context, id string) (*models.WarrantyClaim, error)
+	List(ctx context.Context, filter WarrantyClaimFilter, offset, limit int) ([]*models.WarrantyClaim, error)
+	Count(ctx context.Context, filter WarrantyClaimFilter) (int64, error)
+	// Resolve closes an open claim with its status, resolution and note. A replacement
+	// order is placed in the same transaction, reserving its stock and recording its
+	// creation in the order event outbox. It reports false if the claim was closed in
+	// the meantime, placing nothing.
+	Resolve(ctx context.Context, claim *models.WarrantyClaim, replacement *models.Order) (bool, error)
+	// Reopen returns a claim closed by Resolve to open, for a resolution that could not
+	// be carried out
+	Reopen(ctx context.Context, id string) error
+	// SummarizeByProduct counts per product the units shipped by the orders placed in
+	// [start, end), other than replacements, and the claims made in the range
+	SummarizeByProduct(ctx context.Context, start, end time.Time) ([]WarrantyClaimSummary, error)
+}
+
+// WarrantyClaimFilter narrows the claims listed
+type WarrantyClaimFilter struct {
+	UserID    string
+	ProductID string
+	Status    models.WarrantyClaimStatus
+}
+
+// WarrantyClaimSummary counts the units of a product shipped and the claims made on
+// its units, by resolution
+type WarrantyClaimSummary struct {
+	ProductID    string
+	Name         string
+	SKU          string
+	UnitsShipped int64
+	Claims       int64
+	Open         int64
+	Rejected     int64
+	Repairs      int64
+	Replacements int64
+	Refunds      int64
+}
+
+// NotificationExperimentRepository defines notification wording experiment data access methods
+type NotificationExperimentRepository interface {
+	// Create saves a running experiment unless its notification type and locale
+	// already have one, which it reports with false
+	Create(ctx context.Context, experiment *models.NotificationExperiment) (bool, error)
+	// GetByID returns the experiment, or nil if it does not exist
+	GetByID(ctx context.Context, id string) (*models.NotificationExperiment, error)
+	List(ctx context.Context, notificationType models.NotificationType, status models.NotificationExperimentStatus) ([]*models.NotificationExperiment, error)
+	// GetActive returns the newest running or concluded experiment of a notification
+	// type and locale, whose wording notifications are sent with, or nil if there is none
+	GetActive(ctx context.Context, notificationType models.NotificationType, locale string) (*models.NotificationExperiment, error)
+	// End moves an experiment from one of the from statuses to status, with its winner,
+	// reporting false if it was in none of them
+	End(ctx context.Context, id string, from []models.NotificationExperimentStatus, status models.NotificationExperimentStatus, winner string, endedAt time.Time) (bool, error)
+	// SummarizeVariants counts the notifications sent in an experiment per variant, and
+	// the conversions that followed them within its conversion window
+	SummarizeVariants(ctx context.Context, experiment *models.NotificationExperiment) ([]NotificationVariantSummary, error)
+}
+
+// NotificationVariantSummary is the delivery of the notifications sent with one
+// variant of an experiment, and how many of them converted
+type NotificationVariantSummary struct {
+	Variant string
+	NotificationDeliverySummary
+	Conversions int64
+}
+
+// StockImportRepository defines stock import data access methods
+type StockImportRepository interface {
+	// Create saves an import with all its rows
+	Create(ctx context.Context, stockImport *models.StockImport, rows []*models.StockImportRow) error
+	// GetByID returns the import, or nil if it does not exist
+	GetByID(ctx context.Context, id string) (*models.StockImport, error)
+	// ListRows returns a page of an import's rows in file order, of one status when given
+	ListRows(ctx context.Context, importID string, status models.StockImportRowStatus, offset, limit int) ([]*models.StockImportRow, error)
+	CountRows(ctx context.Context, importID string, status models.StockImportRowStatus) (int64, error)
+	// Fail marks a processing import as failed, leaving its pending rows unapplied
+	Fail(ctx context.Context, id string, message string) error
+	// ApplyChunk applies the pending rows of a processing import between two rows, in
+	// one transaction that also counts them on the import and completes it after its
+	// last row. Rows whose product is unknown or whose adjustment would leave less on
+	// hand than is reserved are rejected. It returns the IDs of the products adjusted.
+	ApplyChunk(ctx context.Context, importID string, firstRow, lastRow int) ([]string, error)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// inventoryEventRepository implements InventoryEventRepository interface
+type inventoryEventRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewInventoryEventRepository creates a new inventory event repository
+func NewInventoryEventRepository(db *database.DB, logger *logger.Logger) InventoryEventRepository {
+	return &inventoryEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListSince returns up to limit events after the given sequence, leaving out events
+// recorded less than settle ago. Sequences are taken when a transaction writes its
+// event but become visible when it commits, so a recent gap may still be filled.
+func (r *inventoryEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.InventoryEvent, error) {
+	r.logger.Debug("Listing inventory events", "since", sequence, "limit", limit)
+
+	var events []*models.InventoryEvent
+	if err := r.db.WithContext(ctx).
+		Where("sequence > ?", sequence).
+		Where("recorded_at <= NOW() - make_interval(secs => ?)", settle.Seconds()).
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		r.logger.Error("Failed to list inventory events", "error", err, "since", sequence)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// inventoryHistorySQL merges a product's inventory events with the relocations of its
+// stock from the audit log, newest first. Events written in one transaction share
+// their recorded time and keep their sequence order.
+const inventoryHistorySQL = `
+SELECT type, sequence, quantity, on_hand, reserved, available,
+	NULL AS from_location, NULL AS to_location, '' AS reason,
+	actor_id, COALESCE(reference_type, '') AS reference_type, COALESCE(reference_id, '') AS reference_id,
+	recorded_at AS occurred_at
+FROM inventory_events
+WHERE product_id = @product_id
+UNION ALL
+SELECT 'transferred', NULL, 0, NULL, NULL, NULL,
+	old_values->>'location', new_values->>'location', COALESCE(new_values->>'reason', ''),
+	user_id, '', '', created_at
+FROM audit_logs
+WHERE entity_type = 'inventory' AND entity_id = @product_id AND action = @relocate
+ORDER BY occurred_at DESC, sequence DESC NULLS FIRST
+OFFSET @offset LIMIT @limit`
+
+func (r *inventoryEventRepository) ListHistory(ctx context.Context, productID string, offset, limit int) ([]InventoryHistoryEntry, error) {
+	r.logger.Debug("Listing inventory history", "product_id", productID, "offset", offset, "limit", limit)
+
+	var entries []InventoryHistoryEntry
+	if err := r.db.WithContext(ctx).Raw(inventoryHistorySQL, map[string]interface{}{
+		"product_id": productID,
+		"relocate":   models.AuditActionRelocate,
+		"offset":     offset,
+		"limit":      limit,
+	}).Scan(&entries).Error; err != nil {
+		r.logger.Error("Failed to list inventory history", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *inventoryEventRepository) CountHistory(ctx context.Context, productID string) (int64, error) {
+	var events, relocations int64
+	if err := r.db.WithContext(ctx).Model(&models.InventoryEvent{}).
+		Where("product_id = ?", productID).
+		Count(&events).Error; err != nil {
+		r.logger.Error("Failed to count inventory events", "error", err, "product_id", productID)
+		return 0, err
+	}
+	if err := r.db.WithContext(ctx).Model(&models.AuditLog{}).
+		Where("entity_type = ? AND entity_id = ? AND action = ?", "inventory", productID, models.AuditActionRelocate).
+		Count(&relocations).Error; err != nil {
+		r.logger.Error("Failed to count inventory relocations", "error", err, "product_id", productID)
+		return 0, err
+	}
+
+	return events + relocations, nil
+}
+
+// InventoryEventSource is who made an inventory change and what for. It is recorded
+// on the events of changes made with a context carrying it.
+type InventoryEventSource struct {
+	ActorID       string
+	ReferenceType models.InventoryReferenceType
+	ReferenceID   string
+}
+
+type inventoryEventSourceKey struct{}
+
+// WithInventoryEventSource returns a copy of ctx whose inventory changes are recorded
+// as made by the source
+func WithInventoryEventSource(ctx context.Context, source InventoryEventSource) context.Context {
+	return context.WithValue(ctx, inventoryEventSourceKey{}, source)
+}
+
+// InventoryEventSourceFrom returns the source of the inventory changes made with ctx
+func InventoryEventSourceFrom(ctx context.Context) (InventoryEventSource, bool) {
+	if ctx == nil {
+		return InventoryEventSource{}, false
+	}
+	source, ok := ctx.Value(inventoryEventSourceKey{}).(InventoryEventSource)
+	return source, ok
+}
+
+// AppendInventoryEvent records a change to an inventory row within tx, after the row
+// has been updated to its new stock levels. The event is attributed to the source of
+// tx's context, if any; adjustments made for a recount are recorded as counts.
+func AppendInventoryEvent(tx *gorm.DB, inventory *models.Inventory, eventType models.InventoryEventType, quantity int) error {
+	event := &models.InventoryEvent{
+		ProductID: inventory.ProductID,
+		Type:      eventType,
+		Quantity:  quantity,
+		OnHand:    inventory.Quantity,
+		Reserved:  inventory.Reserved,
+		Available: inventory.Available,
+		Version:   inventory.Version,
+	}
+
+	if source, ok := InventoryEventSourceFrom(tx.Statement.Context); ok {
+		if source.ActorID != "" {
+			event.ActorID = &source.ActorID
+		}
+		event.ReferenceType = source.ReferenceType
+		event.ReferenceID = source.ReferenceID
+		if eventType == models.InventoryEventAdjusted && source.ReferenceType == models.InventoryReferenceRecount {
+			event.Type = models.InventoryEventCounted
+		}
+	}
+
+	return tx.Create(event).Error
+}
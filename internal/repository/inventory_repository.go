@@ -3,12 +3,15 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // inventoryRepository implements InventoryRepository interface
@@ -28,7 +31,12 @@ func NewInventoryRepository(db *database.DB, logger *logger.Logger) InventoryRep
 func (r *inventoryRepository) Create(ctx context.Context, inventory *models.Inventory) error {
 	r.logger.Debug("Creating inventory", "product_id", inventory.ProductID, "quantity", inventory.Quantity)
 
-	if err := r.db.WithContext(ctx).Create(inventory).Error; err != nil {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(inventory).Error; err != nil {
+			return err
+		}
+		return AppendInventoryEvent(tx, inventory, models.InventoryEventAdjusted, inventory.Quantity)
+	}); err != nil {
 		r.logger.Error("Failed to create inventory", "error", err, "product_id", inventory.ProductID)
 		return err
 	}
@@ -51,6 +59,10 @@ func (r *inventoryRepository) GetByProductID(ctx context.Context, productID stri
 		r.logger.Error("Failed to get inventory by product ID", "error", err, "product_id", productID)
 		return nil, err
 	}
+	if err := overlayInventoryShards(r.db.WithContext(ctx), &inventory); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err, "product_id", productID)
+		return nil, err
+	}
 
 	r.logger.Debug("Inventory retrieved from database", "product_id", productID, "available", inventory.Available)
 	return &inventory, nil
@@ -66,10 +78,15 @@ func (r *inventoryRepository) UpdateStock(ctx context.Context, productID string,
 			r.logger.Error("Failed to get inventory for update", "error", err, "product_id", productID)
 			return err
 		}
+		if err := collapseInventoryShards(tx, &inventory); err != nil {
+			r.logger.Warn("Failed to collapse inventory shards for update", "error", err, "product_id", productID)
+			return err
+		}
 
 		oldVersion := inventory.Version
+		oldQuantity := inventory.Quantity
 		inventory.Quantity = quantity
-		inventory.Available = inventory.Quantity - inventory.Reserved
+		inventory.RefreshAvailable()
 		inventory.Version++
 
 		// Update with version check for optimistic locking
@@ -91,6 +108,11 @@ func (r *inventoryRepository) UpdateStock(ctx context.Context, productID string,
 			return fmt.Errorf("inventory update conflict, please retry")
 		}
 
+		if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventAdjusted, quantity-oldQuantity); err != nil {
+			r.logger.Error("Failed to record inventory event", "error", err, "product_id", productID)
+			return err
+		}
+
 		r.logger.Info("Inventory updated successfully", "product_id", productID, "new_quantity", quantity, "available", inventory.Available)
 		return nil
 	})
@@ -99,45 +121,13 @@ func (r *inventoryRepository) UpdateStock(ctx context.Context, productID string,
 func (r *inventoryRepository) ReserveStock(ctx context.Context, productID string, quantity int) error {
 	r.logger.Debug("Reserving stock for product", "product_id", productID, "quantity", quantity)
 
-	// Use optimistic locking to prevent race conditions
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var inventory models.Inventory
-		if err := tx.First(&inventory, "product_id = ?", productID).Error; err != nil {
-			r.logger.Error("Failed to get inventory for reservation", "error", err, "product_id", productID)
+		inventory, err := ReserveInventory(tx, productID, quantity)
+		if err != nil {
+			r.logger.Warn("Inventory reservation failed", "error", err, "product_id", productID, "requested", quantity)
 			return err
 		}
 
-		// Check if we can reserve the requested quantity
-		if !inventory.CanReserve(quantity) {
-			r.logger.Warn("Insufficient stock for reservation", "product_id", productID, "requested", quantity, "available", inventory.Available)
-			return fmt.Errorf("insufficient stock: requested %d, available %d", quantity, inventory.Available)
-		}
-
-		oldVersion := inventory.Version
-		if err := inventory.Reserve(quantity); err != nil {
-			return err
-		}
-		inventory.Version++
-
-		// Update with version check for optimistic locking
-		result := tx.Model(&inventory).
-			Where("product_id = ? AND version = ?", productID, oldVersion).
-			Updates(map[string]interface{}{
-				"reserved":  inventory.Reserved,
-				"available": inventory.Available,
-				"version":   inventory.Version,
-			})
-
-		if result.Error != nil {
-			r.logger.Error("Failed to reserve inventory", "error", result.Error, "product_id", productID)
-			return result.Error
-		}
-
-		if result.RowsAffected == 0 {
-			r.logger.Warn("Inventory reservation failed due to version mismatch", "product_id", productID, "expected_version", oldVersion)
-			return fmt.Errorf("inventory reservation conflict, please retry")
-		}
-
 		r.logger.Info("Inventory reserved successfully", "product_id", productID, "quantity", quantity, "reserved", inventory.Reserved, "available", inventory.Available)
 		return nil
 	})
@@ -180,6 +170,11 @@ func (r *inventoryRepository) ReleaseStock(ctx context.Context, productID string
 			return fmt.Errorf("inventory release conflict, please retry")
 		}
 
+		if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventReleased, quantity); err != nil {
+			r.logger.Error("Failed to record inventory event", "error", err, "product_id", productID)
+			return err
+		}
+
 		r.logger.Info("Inventory released successfully", "product_id", productID, "quantity", quantity, "reserved", inventory.Reserved, "available", inventory.Available)
 		return nil
 	})
@@ -223,28 +218,183 @@ func (r *inventoryRepository) FulfillStock(ctx context.Context, productID string
 			return fmt.Errorf("inventory fulfillment conflict, please retry")
 		}
 
+		if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventFulfilled, quantity); err != nil {
+			r.logger.Error("Failed to record inventory event", "error", err, "product_id", productID)
+			return err
+		}
+
 		r.logger.Info("Inventory fulfilled successfully", "product_id", productID, "quantity", quantity, "total_quantity", inventory.Quantity, "reserved", inventory.Reserved, "available", inventory.Available)
 		return nil
 	})
 }
 
+func (r *inventoryRepository) Relocate(ctx context.Context, relocation InventoryRelocation) (bool, error) {
+	r.logger.Debug("Relocating inventory", "product_id", relocation.ProductID, "from", relocation.From, "to", relocation.To)
+
+	relocated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The column is read-only on the model, which leaves it out of every other write
+		result := tx.Table(models.Inventory{}.TableName()).
+			Where("product_id = ? AND location = ?", relocation.ProductID, relocation.From).
+			Updates(map[string]interface{}{"location": relocation.To, "updated_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(relocation.AuditLog).Error; err != nil {
+			return err
+		}
+		relocated = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to relocate inventory", "error", err, "product_id", relocation.ProductID)
+		return false, err
+	}
+
+	if relocated {
+		r.logger.Info("Inventory relocated", "product_id", relocation.ProductID, "from", relocation.From, "to", relocation.To)
+	}
+	return relocated, nil
+}
+
+func (r *inventoryRepository) SetSafetyStock(ctx context.Context, productID string, safetyStock int, auditLog *models.AuditLog) (*models.Inventory, error) {
+	r.logger.Debug("Setting safety stock", "product_id", productID, "safety_stock", safetyStock)
+
+	var updated *models.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inventory models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&inventory, "product_id = ?", productID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if err := collapseInventoryShards(tx, &inventory); err != nil {
+			return err
+		}
+
+		change := safetyStock - inventory.SafetyStock
+		inventory.SafetyStock = safetyStock
+		inventory.RefreshAvailable()
+		inventory.Version++
+
+		// The column is read-only on the model, which leaves it out of every other write
+		if err := tx.Table(models.Inventory{}.TableName()).
+			Where("product_id = ?", productID).
+			Updates(map[string]interface{}{
+				"safety_stock": inventory.SafetyStock,
+				"available":    inventory.Available,
+				"version":      inventory.Version,
+				"updated_at":   time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventSafetyStock, change); err != nil {
+			return err
+		}
+		if err := tx.Create(auditLog).Error; err != nil {
+			return err
+		}
+		updated = &inventory
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to set safety stock", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	if updated != nil {
+		r.logger.Info("Safety stock set", "product_id", productID, "safety_stock", safetyStock, "available", updated.Available)
+	}
+	return updated, nil
+}
+
 func (r *inventoryRepository) GetLowStockItems(ctx context.Context, threshold int) ([]*models.Inventory, error) {
 	r.logger.Debug("Getting low stock items", "threshold", threshold)
 
+	// The inventory rows of sharded products leave out the stock on their shards
 	var inventories []*models.Inventory
 	if err := r.db.WithContext(ctx).
 		Preload("Product").
-		Where("available <= ?", threshold).
+		Where("available <= ? OR product_id IN (?)", threshold,
+			r.db.Model(&models.InventoryShard{}).Select("product_id")).
 		Order("available ASC").
 		Find(&inventories).Error; err != nil {
 		r.logger.Error("Failed to get low stock items", "error", err)
 		return nil, err
 	}
+	if err := overlayInventoryShards(r.db.WithContext(ctx), inventories...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+	low := inventories[:0]
+	for _, inventory := range inventories {
+		if inventory.Available <= threshold {
+			low = append(low, inventory)
+		}
+	}
+	inventories = low
+	sortInventoriesByAvailable(inventories)
 
 	r.logger.Debug("Low stock items retrieved", "count", len(inventories), "threshold", threshold)
 	return inventories, nil
 }
 
+func (r *inventoryRepository) ListWithProducts(ctx context.Context) ([]*models.Inventory, error) {
+	r.logger.Debug("Listing inventory with products")
+
+	var inventories []*models.Inventory
+	if err := r.db.WithContext(ctx).
+		Preload("Product").
+		Order("available ASC").
+		Find(&inventories).Error; err != nil {
+		r.logger.Error("Failed to list inventory", "error", err)
+		return nil, err
+	}
+	if err := overlayInventoryShards(r.db.WithContext(ctx), inventories...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+	sortInventoriesByAvailable(inventories)
+
+	r.logger.Debug("Inventory listed", "count", len(inventories))
+	return inventories, nil
+}
+
+// GetUnitsSoldSince returns the units of each product ordered since the given time,
+// leaving out cancelled and failed orders
+func (r *inventoryRepository) GetUnitsSoldSince(ctx context.Context, since time.Time) (map[string]int, error) {
+	r.logger.Debug("Getting units sold", "since", since)
+
+	var rows []struct {
+		ProductID string
+		Units     int
+	}
+	if err := r.db.WithContext(ctx).
+		Table("order_items").
+		Select("order_items.product_id, COALESCE(SUM(order_items.quantity), 0) AS units").
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Where("orders.created_at >= ?", since).
+		Where("orders.status NOT IN ?", []models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed}).
+		Group("order_items.product_id").
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("Failed to get units sold", "error", err, "since", since)
+		return nil, err
+	}
+
+	unitsSold := make(map[string]int, len(rows))
+	for _, row := range rows {
+		unitsSold[row.ProductID] = row.Units
+	}
+	return unitsSold, nil
+}
+
 func (r *inventoryRepository) BulkReserve(ctx context.Context, items []InventoryReservation) error {
 	r.logger.Debug("Bulk reserving inventory items", "count", len(items))
 
@@ -255,24 +405,39 @@ func (r *inventoryRepository) BulkReserve(ctx context.Context, items []Inventory
 
 		for _, item := range items {
 			// Reserve using the transaction context
-			var inventory models.Inventory
-			if err := tx.First(&inventory, "product_id = ?", item.ProductID).Error; err != nil {
-				r.logger.Error("Failed to get inventory for bulk reservation", "error", err, "product_id", item.ProductID)
+			if _, err := ReserveInventory(tx, item.ProductID, item.Quantity); err != nil {
+				r.logger.Warn("Bulk inventory reservation failed",
+					"error", err, "product_id", item.ProductID, "requested", item.Quantity)
 				return err
 			}
 
-			// Check if we can reserve the requested quantity
-			if !inventory.CanReserve(item.Quantity) {
-				r.logger.Warn("Insufficient stock for bulk reservation",
-					"product_id", item.ProductID,
-					"requested", item.Quantity,
-					"available", inventory.Available)
-				return fmt.Errorf("insufficient stock for product %s: requested %d, available %d",
-					item.ProductID, item.Quantity, inventory.Available)
+			reservedItems = append(reservedItems, item)
+			r.logger.Debug("Item reserved in bulk operation", "product_id", item.ProductID, "quantity", item.Quantity)
+		}
+
+		r.logger.Info("Bulk inventory reservation completed successfully", "count", len(reservedItems))
+		return nil
+	})
+}
+
+func (r *inventoryRepository) BulkRelease(ctx context.Context, items []InventoryReservation) error {
+	r.logger.Debug("Bulk releasing inventory items", "count", len(items))
+
+	// Use a single transaction for all operations
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var releasedItems []InventoryReservation
+
+		for _, item := range items {
+			// Release using the transaction context
+			var inventory models.Inventory
+			if err := tx.First(&inventory, "product_id = ?", item.ProductID).Error; err != nil {
+				r.logger.Error("Failed to get inventory for bulk release", "error", err, "product_id", item.ProductID)
+				return err
 			}
 
 			oldVersion := inventory.Version
-			if err := inventory.Reserve(item.Quantity); err != nil {
+			if err := inventory.Release(item.Quantity); err != nil {
+				r.logger.Error("Failed to release inventory in bulk", "error", err, "product_id", item.ProductID, "quantity", item.Quantity)
 				return err
 			}
 			inventory.Version++
@@ -287,72 +452,91 @@ func (r *inventoryRepository) BulkReserve(ctx context.Context, items []Inventory
 				})
 
 			if result.Error != nil {
-				r.logger.Error("Failed to reserve inventory in bulk", "error", result.Error, "product_id", item.ProductID)
+				r.logger.Error("Failed to release inventory in bulk", "error", result.Error, "product_id", item.ProductID)
 				return result.Error
 			}
 
 			if result.RowsAffected == 0 {
-				r.logger.Warn("Bulk inventory reservation failed due to version mismatch",
+				r.logger.Warn("Bulk inventory release failed due to version mismatch",
 					"product_id", item.ProductID, "expected_version", oldVersion)
-				return fmt.Errorf("inventory reservation conflict for product %s, please retry", item.ProductID)
+				return fmt.Errorf("inventory release conflict for product %s, please retry", item.ProductID)
 			}
 
-			reservedItems = append(reservedItems, item)
-			r.logger.Debug("Item reserved in bulk operation", "product_id", item.ProductID, "quantity", item.Quantity)
+			if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventReleased, item.Quantity); err != nil {
+				r.logger.Error("Failed to record inventory event", "error", err, "product_id", item.ProductID)
+				return err
+			}
+
+			releasedItems = append(releasedItems, item)
+			r.logger.Debug("Item released in bulk operation", "product_id", item.ProductID, "quantity", item.Quantity)
 		}
 
-		r.logger.Info("Bulk inventory reservation completed successfully", "count", len(reservedItems))
+		r.logger.Info("Bulk inventory release completed successfully", "count", len(releasedItems))
 		return nil
 	})
 }
 
-func (r *inventoryRepository) BulkRelease(ctx context.Context, items []InventoryReservation) error {
-	r.logger.Debug("Bulk releasing inventory items", "count", len(items))
+func (r *inventoryRepository) BulkAdjustStock(ctx context.Context, items []InventoryStockAdjustment) error {
+	r.logger.Debug("Bulk adjusting inventory stock", "count", len(items))
 
-	// Use a single transaction for all operations
+	// All adjustments in the batch succeed or fail together
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var releasedItems []InventoryReservation
-
 		for _, item := range items {
-			// Release using the transaction context
 			var inventory models.Inventory
 			if err := tx.First(&inventory, "product_id = ?", item.ProductID).Error; err != nil {
-				r.logger.Error("Failed to get inventory for bulk release", "error", err, "product_id", item.ProductID)
+				r.logger.Error("Failed to get inventory for adjustment", "error", err, "product_id", item.ProductID)
 				return err
 			}
 
-			oldVersion := inventory.Version
-			if err := inventory.Release(item.Quantity); err != nil {
-				r.logger.Error("Failed to release inventory in bulk", "error", err, "product_id", item.ProductID, "quantity", item.Quantity)
+			// The adjustment was computed against a stale read
+			if inventory.Version != item.ExpectedVersion {
+				r.logger.Warn("Inventory adjustment skipped due to version mismatch",
+					"product_id", item.ProductID, "expected_version", item.ExpectedVersion, "actual_version", inventory.Version)
+				return errors.NewOptimisticLockError("inventory", item.ProductID)
+			}
+			if err := collapseInventoryShards(tx, &inventory); err != nil {
+				r.logger.Warn("Failed to collapse inventory shards for adjustment", "error", err, "product_id", item.ProductID)
 				return err
 			}
+
+			if item.Quantity < inventory.Reserved {
+				return errors.NewBusinessError(fmt.Sprintf(
+					"counted quantity %d for product %s is below reserved quantity %d",
+					item.Quantity, item.ProductID, inventory.Reserved))
+			}
+
+			change := item.Quantity - inventory.Quantity
+			inventory.Quantity = item.Quantity
+			inventory.RefreshAvailable()
+			oldVersion := inventory.Version
 			inventory.Version++
 
-			// Update with version check for optimistic locking
 			result := tx.Model(&inventory).
 				Where("product_id = ? AND version = ?", item.ProductID, oldVersion).
 				Updates(map[string]interface{}{
-					"reserved":  inventory.Reserved,
+					"quantity":  inventory.Quantity,
 					"available": inventory.Available,
 					"version":   inventory.Version,
 				})
 
 			if result.Error != nil {
-				r.logger.Error("Failed to release inventory in bulk", "error", result.Error, "product_id", item.ProductID)
+				r.logger.Error("Failed to adjust inventory", "error", result.Error, "product_id", item.ProductID)
 				return result.Error
 			}
 
 			if result.RowsAffected == 0 {
-				r.logger.Warn("Bulk inventory release failed due to version mismatch",
-					"product_id", item.ProductID, "expected_version", oldVersion)
-				return fmt.Errorf("inventory release conflict for product %s, please retry", item.ProductID)
+				r.logger.Warn("Inventory adjustment failed due to version mismatch",
+					"product_id", item.ProductID, "expected_version", item.ExpectedVersion)
+				return errors.NewOptimisticLockError("inventory", item.ProductID)
 			}
 
-			releasedItems = append(releasedItems, item)
-			r.logger.Debug("Item released in bulk operation", "product_id", item.ProductID, "quantity", item.Quantity)
+			if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventAdjusted, change); err != nil {
+				r.logger.Error("Failed to record inventory event", "error", err, "product_id", item.ProductID)
+				return err
+			}
 		}
 
-		r.logger.Info("Bulk inventory release completed successfully", "count", len(releasedItems))
+		r.logger.Info("Bulk inventory adjustment completed successfully", "count", len(items))
 		return nil
 	})
 }
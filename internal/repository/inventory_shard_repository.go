@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inventoryShardCursor spreads the reservations of this process round-robin over
+// the shards of a product
+var inventoryShardCursor atomic.Uint64
+
+// inventoryShardRepository implements InventoryShardRepository interface
+type inventoryShardRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewInventoryShardRepository creates a new inventory shard repository
+func NewInventoryShardRepository(db *database.DB, logger *logger.Logger) InventoryShardRepository {
+	return &inventoryShardRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *inventoryShardRepository) Configure(ctx context.Context, productID string, shards int, auditLog *models.AuditLog) (*models.Inventory, error) {
+	r.logger.Debug("Configuring inventory shards", "product_id", productID, "shards", shards)
+
+	var configured *models.Inventory
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inventory models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&inventory, "product_id = ?", productID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err := collapseInventoryShards(tx, &inventory); err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", productID).Delete(&models.InventoryShard{ProductID: productID}).Error; err != nil {
+			return err
+		}
+		if shards > 0 {
+			rows := make([]*models.InventoryShard, shards)
+			for i := range rows {
+				rows[i] = &models.InventoryShard{ProductID: productID, Shard: i}
+			}
+			if err := tx.Create(rows).Error; err != nil {
+				return err
+			}
+			if err := spreadInventoryShards(tx, &inventory, rows); err != nil {
+				return err
+			}
+		}
+
+		if auditLog != nil {
+			if err := tx.Create(auditLog).Error; err != nil {
+				return err
+			}
+		}
+		if err := overlayInventoryShards(tx, &inventory); err != nil {
+			return err
+		}
+		configured = &inventory
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to configure inventory shards", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	if configured != nil {
+		r.logger.Info("Inventory shards configured", "product_id", productID, "shards", shards, "available", configured.Available)
+	}
+	return configured, nil
+}
+
+func (r *inventoryShardRepository) List(ctx context.Context, productID string) ([]*models.InventoryShard, error) {
+	r.logger.Debug("Listing inventory shards", "product_id", productID)
+
+	var shards []*models.InventoryShard
+	if err := r.db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("shard").
+		Find(&shards).Error; err != nil {
+		r.logger.Error("Failed to list inventory shards", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	return shards, nil
+}
+
+// Rebalance evens out the shards of each sharded product that drifted apart, and
+// moves the stock returned to its inventory row by releases and restocks onto them.
+// Balanced products are left alone, so their inventory version does not move.
+func (r *inventoryShardRepository) Rebalance(ctx context.Context) (int, error) {
+	var productIDs []string
+	if err := r.db.WithContext(ctx).
+		Model(&models.InventoryShard{}).
+		Distinct("product_id").
+		Pluck("product_id", &productIDs).Error; err != nil {
+		r.logger.Error("Failed to list sharded products", "error", err)
+		return 0, err
+	}
+
+	rebalanced := 0
+	for _, productID := range productIDs {
+		moved := false
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var inventory models.Inventory
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&inventory, "product_id = ?", productID).Error; err != nil {
+				if stderrors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+
+			var shards []*models.InventoryShard
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("product_id = ?", productID).
+				Order("shard").
+				Find(&shards).Error; err != nil {
+				return err
+			}
+			if len(shards) == 0 || (inventory.Available == 0 && inventoryShardsBalanced(shards)) {
+				return nil
+			}
+
+			if err := collapseInventoryShards(tx, &inventory); err != nil {
+				return err
+			}
+			moved = true
+			return spreadInventoryShards(tx, &inventory, shards)
+		})
+		if err != nil {
+			r.logger.Error("Failed to rebalance inventory shards", "error", err, "product_id", productID)
+			return rebalanced, err
+		}
+		if moved {
+			rebalanced++
+		}
+	}
+
+	if rebalanced > 0 {
+		r.logger.Info("Inventory shards rebalanced", "products", rebalanced)
+	}
+	return rebalanced, nil
+}
+
+// inventoryShardsBalanced returns true if no shard holds more than one unit more
+// than another
+func inventoryShardsBalanced(shards []*models.InventoryShard) bool {
+	lowest, highest := shards[0].Available, shards[0].Available
+	for _, shard := range shards[1:] {
+		lowest = min(lowest, shard.Available)
+		highest = max(highest, shard.Available)
+	}
+	return highest-lowest <= 1
+}
+
+// spreadInventoryShards moves the available stock of a locked inventory row evenly
+// onto its empty, locked shards, counting it as reserved on the row
+func spreadInventoryShards(tx *gorm.DB, inventory *models.Inventory, shards []*models.InventoryShard) error {
+	units := inventory.Available
+	if units == 0 {
+		return nil
+	}
+
+	for i, shard := range shards {
+		shard.Available = units / len(shards)
+		if i < units%len(shards) {
+			shard.Available++
+		}
+		if err := updateInventoryShard(tx, shard); err != nil {
+			return err
+		}
+	}
+
+	oldVersion := inventory.Version
+	inventory.Reserved += units
+	inventory.RefreshAvailable()
+	inventory.Version++
+	return updateShardedInventory(tx, inventory, oldVersion)
+}
+
+// collapseInventoryShards moves the units left on a product's shards back onto its
+// inventory row, so that writes to the row see the whole stock. The row is locked
+// before the shards, the order every writer of sharded stock takes them in, at the
+// version the caller read; a row changed since is an optimistic lock error. Products
+// without shards are left alone.
+func collapseInventoryShards(tx *gorm.DB, inventory *models.Inventory) error {
+	var count int64
+	if err := tx.Model(&models.InventoryShard{}).Where("product_id = ?", inventory.ProductID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var locked models.Inventory
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND version = ?", inventory.ProductID, inventory.Version).
+		Limit(1).
+		Find(&locked)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewOptimisticLockError("inventory", inventory.ProductID)
+	}
+
+	var shards []*models.InventoryShard
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", inventory.ProductID).
+		Order("shard").
+		Find(&shards).Error; err != nil {
+		return err
+	}
+	free := 0
+	for _, shard := range shards {
+		free += shard.Available
+	}
+	if free > 0 {
+		if err := tx.Model(&models.InventoryShard{ProductID: inventory.ProductID}).
+			Where("product_id = ? AND available > 0", inventory.ProductID).
+			UpdateColumns(map[string]interface{}{
+				"available":  0,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+		for _, shard := range shards {
+			shard.Available = 0
+		}
+
+		oldVersion := locked.Version
+		locked.Reserved -= free
+		locked.RefreshAvailable()
+		locked.Version++
+		if err := updateShardedInventory(tx, &locked, oldVersion); err != nil {
+			return err
+		}
+	}
+
+	locked.Product = inventory.Product
+	*inventory = locked
+	return nil
+}
+
+// updateInventoryShard persists the available units of a locked shard. The shard is
+// matched explicitly, as shard 0 is a zero primary key to gorm.
+func updateInventoryShard(tx *gorm.DB, shard *models.InventoryShard) error {
+	return tx.Model(shard).
+		Where("product_id = ? AND shard = ?", shard.ProductID, shard.Shard).
+		UpdateColumns(map[string]interface{}{
+			"available":  shard.Available,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// updateShardedInventory persists the reserved and available quantities of an
+// inventory row whose units moved to or from its shards
+func updateShardedInventory(tx *gorm.DB, inventory *models.Inventory, oldVersion int) error {
+	result := tx.Model(inventory).
+		Where("product_id = ? AND version = ?", inventory.ProductID, oldVersion).
+		Updates(map[string]interface{}{
+			"reserved":  inventory.Reserved,
+			"available": inventory.Available,
+			"version":   inventory.Version,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewOptimisticLockError("inventory", inventory.ProductID)
+	}
+	return nil
+}
+
+// overlayInventoryShards counts the units left on the shards of the inventories as
+// available rather than reserved, the stock levels callers expect
+func overlayInventoryShards(db *gorm.DB, inventories ...*models.Inventory) error {
+	productIDs := make([]string, 0, len(inventories))
+	for _, inventory := range inventories {
+		productIDs = append(productIDs, inventory.ProductID)
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		ProductID string
+		Available int
+	}
+	if err := db.Model(&models.InventoryShard{}).
+		Select("product_id, COALESCE(SUM(available), 0) AS available").
+		Where("product_id IN ?", productIDs).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	free := make(map[string]int, len(rows))
+	for _, row := range rows {
+		free[row.ProductID] = row.Available
+	}
+	for _, inventory := range inventories {
+		if units := free[inventory.ProductID]; units > 0 {
+			inventory.Reserved -= units
+			inventory.RefreshAvailable()
+		}
+	}
+	return nil
+}
+
+// overlayProductShards overlays the shards of the products' preloaded inventories
+func overlayProductShards(db *gorm.DB, products ...*models.Product) error {
+	inventories := make([]*models.Inventory, 0, len(products))
+	for _, product := range products {
+		if product.Inventory != nil {
+			inventories = append(inventories, product.Inventory)
+		}
+	}
+	return overlayInventoryShards(db, inventories...)
+}
+
+// sortInventoriesByAvailable orders inventories from the least available, the order
+// the inventory listings are queried in, after their shards were overlaid
+func sortInventoriesByAvailable(inventories []*models.Inventory) {
+	sort.SliceStable(inventories, func(i, j int) bool {
+		return inventories[i].Available < inventories[j].Available
+	})
+}
+
+// LockInventory reads a product's inventory within tx for an order to check and
+// reserve its stock, locking the row so concurrent orders of the product are
+// serialized. The row of a sharded product is only locked when exclusive, as its
+// orders reserve from its shards; its stock levels are read with the shards overlaid.
+func LockInventory(tx *gorm.DB, productID string, exclusive bool) (*models.Inventory, error) {
+	var count int64
+	if err := tx.Model(&models.InventoryShard{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+
+	query := tx
+	if count == 0 || exclusive {
+		// FOR UPDATE locks the row for the duration of the transaction
+		query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var inventory models.Inventory
+	if err := query.First(&inventory, "product_id = ?", productID).Error; err != nil {
+		return nil, err
+	}
+
+	if count > 0 {
+		if err := overlayInventoryShards(tx, &inventory); err != nil {
+			return nil, err
+		}
+	}
+	return &inventory, nil
+}
+
+// ReserveInventory reserves units of a product within tx and records the reservation
+// in the inventory event log. A sharded product is reserved from the next of its
+// shards with enough stock, skipping shards locked by other orders, and from its
+// inventory row only when no single shard has enough; the row then takes back the
+// units of its shards until they are rebalanced.
+func ReserveInventory(tx *gorm.DB, productID string, quantity int) (*models.Inventory, error) {
+	var count int64
+	if err := tx.Model(&models.InventoryShard{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+		return nil, errors.NewDatabaseError("failed to get inventory shards", err)
+	}
+
+	if count > 0 {
+		shard, err := lockInventoryShard(tx, productID, quantity, int(count))
+		if err != nil {
+			return nil, errors.NewDatabaseError("failed to lock inventory shard", err)
+		}
+		if shard != nil {
+			return reserveInventoryShard(tx, shard, quantity)
+		}
+	}
+
+	return reserveInventoryRow(tx, productID, quantity, count > 0)
+}
+
+// lockInventoryShard locks a shard of a product with at least quantity units
+// available, trying the shards round-robin from the next one and skipping those
+// locked by other orders. When every such shard is locked, it waits for one. It
+// returns nil when no shard has enough.
+func lockInventoryShard(tx *gorm.DB, productID string, quantity, shards int) (*models.InventoryShard, error) {
+	start := int(inventoryShardCursor.Add(1) % uint64(shards))
+	order := clause.OrderBy{Expression: clause.Expr{SQL: "shard >= ? DESC, shard", Vars: []interface{}{start}}}
+
+	for _, locking := range []clause.Locking{
+		{Strength: "UPDATE", Options: "SKIP LOCKED"},
+		{Strength: "UPDATE"},
+	} {
+		var found []*models.InventoryShard
+		if err := tx.Clauses(locking).
+			Where("product_id = ? AND available >= ?", productID, quantity).
+			Order(order).
+			Limit(1).
+			Find(&found).Error; err != nil {
+			return nil, err
+		}
+		if len(found) > 0 {
+			return found[0], nil
+		}
+	}
+	return nil, nil
+}
+
+// reserveInventoryShard reserves units from a locked shard. The units were already
+// counted as reserved on the product's inventory row, which is left alone.
+func reserveInventoryShard(tx *gorm.DB, shard *models.InventoryShard, quantity int) (*models.Inventory, error) {
+	shard.Available -= quantity
+	if err := updateInventoryShard(tx, shard); err != nil {
+		return nil, errors.NewDatabaseError("failed to update inventory shard reservation", err)
+	}
+
+	var inventory models.Inventory
+	if err := tx.First(&inventory, "product_id = ?", shard.ProductID).Error; err != nil {
+		return nil, errors.NewDatabaseError("failed to get inventory for reservation", err)
+	}
+	if err := overlayInventoryShards(tx, &inventory); err != nil {
+		return nil, errors.NewDatabaseError("failed to get inventory shards", err)
+	}
+
+	if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventReserved, quantity); err != nil {
+		return nil, errors.NewDatabaseError("failed to record inventory reservation", err)
+	}
+	return &inventory, nil
+}
+
+// reserveInventoryRow reserves units from a product's inventory row with optimistic
+// locking. The row of a sharded product is locked, and takes back the units of its
+// shards when it is short.
+func reserveInventoryRow(tx *gorm.DB, productID string, quantity int, sharded bool) (*models.Inventory, error) {
+	query := tx
+	if sharded {
+		query = tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var inventory models.Inventory
+	if err := query.First(&inventory, "product_id = ?", productID).Error; err != nil {
+		return nil, errors.NewDatabaseError("failed to get inventory for reservation", err)
+	}
+
+	if sharded && !inventory.CanReserve(quantity) {
+		if err := collapseInventoryShards(tx, &inventory); err != nil {
+			return nil, errors.NewStockReservationConflictError(productID, err)
+		}
+	}
+
+	oldVersion := inventory.Version
+	if err := inventory.Reserve(quantity); err != nil {
+		return nil, errors.NewInsufficientStockError(productID, quantity, inventory.Available)
+	}
+	inventory.Version++
+
+	// Update with optimistic locking
+	result := tx.Model(&inventory).
+		Where("product_id = ? AND version = ?", productID, oldVersion).
+		Updates(map[string]interface{}{
+			"reserved":  inventory.Reserved,
+			"available": inventory.Available,
+			"version":   inventory.Version,
+		})
+	if result.Error != nil {
+		return nil, errors.NewDatabaseError("failed to update inventory reservation", result.Error)
+	}
+
+	// Optimistic lock failure - version mismatch
+	if result.RowsAffected == 0 {
+		return nil, errors.NewStockReservationConflictError(productID,
+			stderrors.New("inventory was modified by another transaction"))
+	}
+
+	if err := AppendInventoryEvent(tx, &inventory, models.InventoryEventReserved, quantity); err != nil {
+		return nil, errors.NewDatabaseError("failed to record inventory reservation", err)
+	}
+	return &inventory, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ledgerRepository implements LedgerRepository interface
+type ledgerRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewLedgerRepository creates a new ledger repository
+func NewLedgerRepository(db *database.DB, logger *logger.Logger) LedgerRepository {
+	return &ledgerRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ledgerRepository) Append(ctx context.Context, entries []*models.LedgerEntry) (bool, error) {
+	if len(entries) == 0 {
+		return false, nil
+	}
+	key := entries[0].TransactionKey
+	r.logger.Debug("Posting ledger transaction", "transaction_key", key, "lines", len(entries))
+
+	// The lines go in one statement, so a transaction is posted whole or not at all
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "transaction_key"}, {Name: "line"}},
+			DoNothing: true,
+		}).
+		Create(&entries)
+	if result.Error != nil {
+		r.logger.Error("Failed to post ledger transaction", "error", result.Error, "transaction_key", key)
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		r.logger.Debug("Ledger transaction already posted", "transaction_key", key)
+		return false, nil
+	}
+
+	r.logger.Info("Ledger transaction posted", "transaction_key", key, "lines", len(entries))
+	return true, nil
+}
+
+func (r *ledgerRepository) List(ctx context.Context, filter LedgerFilter, offset, limit int) ([]*models.LedgerEntry, error) {
+	r.logger.Debug("Listing ledger entries", "account", filter.Account, "order_id", filter.OrderID, "offset", offset, "limit", limit)
+
+	var entries []*models.LedgerEntry
+	if err := r.filtered(ctx, filter).
+		Order("sequence").
+		Offset(offset).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		r.logger.Error("Failed to list ledger entries", "error", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *ledgerRepository) Count(ctx context.Context, filter LedgerFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count ledger entries", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered scopes a ledger entry query to the filter
+func (r *ledgerRepository) filtered(ctx context.Context, filter LedgerFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.LedgerEntry{})
+	if filter.Account != "" {
+		query = query.Where("account = ?", filter.Account)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.PaymentID != "" {
+		query = query.Where("payment_id = ?", filter.PaymentID)
+	}
+	return query
+}
+
+func (r *ledgerRepository) SumByAccount(ctx context.Context, account string) ([]LedgerAccountTotals, error) {
+	r.logger.Debug("Summing ledger accounts", "account", account)
+
+	var totals []LedgerAccountTotals
+	if err := r.db.WithContext(ctx).
+		Model(&models.LedgerEntry{}).
+		Select(`account,
+			COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0) AS debits,
+			COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0) AS credits,
+			COUNT(*) AS entries`, models.LedgerDebit, models.LedgerCredit).
+		Where("account = ? OR account LIKE ?", account, account+":%").
+		Group("account").
+		Order("account").
+		Scan(&totals).Error; err != nil {
+		r.logger.Error("Failed to sum ledger accounts", "error", err, "account", account)
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (r *ledgerRepository) ListUnbalanced(ctx context.Context, limit int) ([]LedgerTransactionTotals, error) {
+	r.logger.Debug("Listing unbalanced ledger transactions", "limit", limit)
+
+	var totals []LedgerTransactionTotals
+	if err := r.db.WithContext(ctx).
+		Model(&models.LedgerEntry{}).
+		Select(`transaction_key,
+			COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0) AS debits,
+			COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0) AS credits`, models.LedgerDebit, models.LedgerCredit).
+		Group("transaction_key").
+		Having("COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0) <> COALESCE(SUM(amount) FILTER (WHERE direction = ?), 0)",
+			models.LedgerDebit, models.LedgerCredit).
+		Order("transaction_key").
+		Limit(limit).
+		Scan(&totals).Error; err != nil {
+		r.logger.Error("Failed to list unbalanced ledger transactions", "error", err)
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (r *ledgerRepository) ListPaymentMismatches(ctx context.Context, transactionType models.LedgerTransactionType, statuses []models.PaymentStatus, limit int) ([]LedgerPaymentMismatch, error) {
+	r.logger.Debug("Listing payments mismatching the ledger", "type", transactionType, "statuses", statuses)
+
+	var mismatches []LedgerPaymentMismatch
+	if err := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Select(`payments.id AS payment_id, payments.order_id, payments.status, payments.amount,
+			COALESCE(SUM(ledger_entries.amount), 0) AS posted`).
+		Joins(`LEFT JOIN ledger_entries ON ledger_entries.payment_id = payments.id::text
+			AND ledger_entries.type = ? AND ledger_entries.direction = ?`, transactionType, models.LedgerDebit).
+		Where("payments.status IN ?", statuses).
+		Group("payments.id, payments.order_id, payments.status, payments.amount").
+		Having("COALESCE(SUM(ledger_entries.amount), 0) <> payments.amount").
+		Order("payments.id").
+		Limit(limit).
+		Scan(&mismatches).Error; err != nil {
+		r.logger.Error("Failed to list payments mismatching the ledger", "error", err, "type", transactionType)
+		return nil, err
+	}
+
+	return mismatches, nil
+}
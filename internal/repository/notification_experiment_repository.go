@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// notificationExperimentRepository implements NotificationExperimentRepository interface
+type notificationExperimentRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewNotificationExperimentRepository creates a new notification experiment repository
+func NewNotificationExperimentRepository(db *database.DB, logger *logger.Logger) NotificationExperimentRepository {
+	return &notificationExperimentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *notificationExperimentRepository) Create(ctx context.Context, experiment *models.NotificationExperiment) (bool, error) {
+	r.logger.Debug("Creating notification experiment", "type", experiment.Type, "locale", experiment.Locale)
+
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// There is no row to lock before the first experiment of a type, so experiments
+		// of a type and locale are started one at a time under an advisory lock
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))",
+			"notification_experiment:"+string(experiment.Type)+":"+experiment.Locale).Error; err != nil {
+			return err
+		}
+
+		var running int64
+		if err := tx.Model(&models.NotificationExperiment{}).
+			Where("type = ? AND locale = ? AND status = ?",
+				experiment.Type, experiment.Locale, models.NotificationExperimentStatusRunning).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return nil
+		}
+
+		if err := tx.Create(experiment).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to create notification experiment", "error", err, "type", experiment.Type)
+		return false, err
+	}
+
+	return created, nil
+}
+
+func (r *notificationExperimentRepository) GetByID(ctx context.Context, id string) (*models.NotificationExperiment, error) {
+	r.logger.Debug("Getting notification experiment by ID", "id", id)
+
+	var experiment models.NotificationExperiment
+	if err := r.db.WithContext(ctx).First(&experiment, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get notification experiment by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &experiment, nil
+}
+
+func (r *notificationExperimentRepository) List(ctx context.Context, notificationType models.NotificationType, status models.NotificationExperimentStatus) ([]*models.NotificationExperiment, error) {
+	r.logger.Debug("Listing notification experiments", "type", notificationType, "status", status)
+
+	query := r.db.WithContext(ctx).Model(&models.NotificationExperiment{})
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var experiments []*models.NotificationExperiment
+	if err := query.Order("started_at DESC, id").Find(&experiments).Error; err != nil {
+		r.logger.Error("Failed to list notification experiments", "error", err)
+		return nil, err
+	}
+
+	return experiments, nil
+}
+
+func (r *notificationExperimentRepository) GetActive(ctx context.Context, notificationType models.NotificationType, locale string) (*models.NotificationExperiment, error) {
+	var experiment models.NotificationExperiment
+	if err := r.db.WithContext(ctx).
+		Where("type = ? AND locale = ? AND status IN ?", notificationType, locale, []models.NotificationExperimentStatus{
+			models.NotificationExperimentStatusRunning,
+			models.NotificationExperimentStatusConcluded,
+		}).
+		Order("started_at DESC").
+		First(&experiment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get active notification experiment", "error", err, "type", notificationType, "locale", locale)
+		return nil, err
+	}
+
+	return &experiment, nil
+}
+
+func (r *notificationExperimentRepository) End(ctx context.Context, id string, from []models.NotificationExperimentStatus, status models.NotificationExperimentStatus, winner string, endedAt time.Time) (bool, error) {
+	r.logger.Debug("Ending notification experiment", "id", id, "status", status, "winner", winner)
+
+	result := r.db.WithContext(ctx).Model(&models.NotificationExperiment{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{
+			"status":   status,
+			"winner":   winner,
+			"ended_at": gorm.Expr("COALESCE(ended_at, ?)", endedAt),
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to end notification experiment", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// notificationConversionSQL holds, per conversion goal, the condition under which a
+// notification n converted: its user paid or ordered within the conversion window,
+// given in hours as the only parameter
+var notificationConversionSQL = map[models.NotificationConversionGoal]string{
+	models.NotificationConversionPayment: `EXISTS (
+		SELECT 1 FROM payments p
+		JOIN orders o ON o.id = p.order_id
+		WHERE o.user_id = n.user_id
+			AND (n.data->>'order_id' IS NULL OR o.id::text = n.data->>'order_id')
+			AND p.status IN ('completed', 'refunded')
+			AND COALESCE(p.processed_at, p.created_at) >= n.created_at
+			AND COALESCE(p.processed_at, p.created_at) < n.created_at + make_interval(hours => ?))`,
+	models.NotificationConversionOrder: `EXISTS (
+		SELECT 1 FROM orders o
+		WHERE o.user_id = n.user_id
+			AND o.deleted_at IS NULL
+			AND o.created_at >= n.created_at
+			AND o.created_at < n.created_at + make_interval(hours => ?))`,
+}
+
+func (r *notificationExperimentRepository) SummarizeVariants(ctx context.Context, experiment *models.NotificationExperiment) ([]NotificationVariantSummary, error) {
+	r.logger.Debug("Summarizing notification experiment variants", "id", experiment.ID, "goal", experiment.ConversionGoal)
+
+	var summaries []NotificationVariantSummary
+	if err := r.db.WithContext(ctx).
+		Table("notifications AS n").
+		Select(`n.variant,
+			COUNT(*) AS notifications,
+			COUNT(n.sent_at) AS sent,
+			COUNT(n.delivered_at) AS delivered,
+			COUNT(*) FILTER (WHERE n.read) AS read,
+			COALESCE(SUM(EXTRACT(EPOCH FROM n.delivered_at - n.sent_at)) FILTER (WHERE n.delivered_at >= n.sent_at), 0) AS delivery_seconds,
+			COALESCE(SUM(EXTRACT(EPOCH FROM n.read_at - n.delivered_at)) FILTER (WHERE n.read AND n.read_at >= n.delivered_at), 0) AS read_seconds,
+			COUNT(*) FILTER (WHERE `+notificationConversionSQL[experiment.ConversionGoal]+`) AS conversions`,
+			experiment.ConversionWindowHours).
+		Where("n.experiment_id = ?", experiment.ID).
+		Group("n.variant").
+		Order("n.variant").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize notification experiment variants", "error", err, "id", experiment.ID)
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/pkg/database"
@@ -90,3 +91,124 @@ func (r *notificationRepository) GetUnreadByUserID(ctx context.Context, userID s
 	r.logger.Debug("Unread notifications retrieved for user", "user_id", userID, "count", len(notifications))
 	return notifications, nil
 }
+
+func (r *notificationRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.Notification, error) {
+	r.logger.Debug("Getting notifications by order ID", "order_id", orderID)
+
+	var notifications []*models.Notification
+	if err := r.db.WithContext(ctx).
+		Where("data IS NOT NULL AND data->>'order_id' = ?", orderID).
+		Order("created_at ASC").
+		Find(&notifications).Error; err != nil {
+		r.logger.Error("Failed to get notifications by order ID", "error", err, "order_id", orderID)
+		return nil, err
+	}
+
+	r.logger.Debug("Notifications retrieved for order", "order_id", orderID, "count", len(notifications))
+	return notifications, nil
+}
+
+func (r *notificationRepository) MarkSent(ctx context.Context, id string, channel models.NotificationChannel, sentAt time.Time) error {
+	r.logger.Debug("Marking notification as sent", "id", id, "channel", channel)
+
+	updates := map[string]interface{}{
+		"sent_at":           sentAt,
+		"delivered_channel": channel,
+	}
+	if channel == models.NotificationChannelInApp {
+		updates["delivered_at"] = gorm.Expr("COALESCE(delivered_at, ?)", sentAt)
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		r.logger.Error("Failed to mark notification as sent", "error", err, "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationRepository) MarkDelivered(ctx context.Context, id string, deliveredAt time.Time) (bool, error) {
+	r.logger.Debug("Marking notification as delivered", "id", id, "delivered_at", deliveredAt)
+
+	// Only the first receipt counts, so retried receipts do not move the delivery time
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND delivered_at IS NULL", id).
+		Update("delivered_at", deliveredAt)
+	if result.Error != nil {
+		r.logger.Error("Failed to mark notification as delivered", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id string, readAt time.Time) (bool, error) {
+	r.logger.Debug("Marking notification as read", "id", id, "read_at", readAt)
+
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND read = ?", id, false).
+		Updates(map[string]interface{}{
+			"read":         true,
+			"read_at":      readAt,
+			"delivered_at": gorm.Expr("COALESCE(delivered_at, ?)", readAt),
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to mark notification as read", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *notificationRepository) SummarizeDelivery(ctx context.Context, start, end time.Time) ([]NotificationDeliverySummary, error) {
+	r.logger.Debug("Summarizing notification delivery", "start", start, "end", end)
+
+	// The channel is grouped by position, since GROUP BY channel would mean the
+	// requested channel column rather than the selected one
+	var summaries []NotificationDeliverySummary
+	if err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Select(`type,
+			COALESCE(NULLIF(delivered_channel, ''), channel) AS channel,
+			COUNT(*) AS notifications,
+			COUNT(sent_at) AS sent,
+			COUNT(delivered_at) AS delivered,
+			COUNT(*) FILTER (WHERE read) AS read,
+			COALESCE(SUM(EXTRACT(EPOCH FROM delivered_at - sent_at)) FILTER (WHERE delivered_at >= sent_at), 0) AS delivery_seconds,
+			COALESCE(SUM(EXTRACT(EPOCH FROM read_at - delivered_at)) FILTER (WHERE read AND read_at >= delivered_at), 0) AS read_seconds`).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("type, 2").
+		Order("type, 2").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize notification delivery", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// DeleteCreatedBefore deletes up to limit notifications created before the given time,
+// returning how many were deleted
+func (r *notificationRepository) DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.logger.Debug("Deleting notifications", "before", before, "limit", limit)
+
+	batch := r.db.WithContext(ctx).Model(&models.Notification{}).Select("id").Where("created_at < ?", before).Limit(limit)
+	result := r.db.WithContext(ctx).Where("id IN (?)", batch).Delete(&models.Notification{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete notifications", "error", result.Error, "before", before)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *notificationRepository) CountCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Notification{}).Where("created_at < ?", before).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count notifications", "error", err, "before", before)
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderEventRepository implements OrderEventRepository interface
+type orderEventRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewOrderEventRepository creates a new order event repository
+func NewOrderEventRepository(db *database.DB, logger *logger.Logger) OrderEventRepository {
+	return &orderEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListSince returns up to limit events after the given sequence, leaving out events
+// recorded less than settle ago, like the inventory event stream
+func (r *orderEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.OrderEvent, error) {
+	r.logger.Debug("Listing order events", "since", sequence, "limit", limit)
+
+	var events []*models.OrderEvent
+	if err := r.db.WithContext(ctx).
+		Where("sequence > ?", sequence).
+		Where("recorded_at <= NOW() - make_interval(secs => ?)", settle.Seconds()).
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		r.logger.Error("Failed to list order events", "error", err, "since", sequence)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *orderEventRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	if err := r.db.WithContext(ctx).
+		Model(&models.OrderEvent{}).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&sequence).Error; err != nil {
+		r.logger.Error("Failed to get latest order event sequence", "error", err)
+		return 0, err
+	}
+
+	return sequence, nil
+}
+
+func (r *orderEventRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	var cursor models.OrderEventCursor
+	if err := r.db.WithContext(ctx).First(&cursor, "consumer = ?", consumer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		r.logger.Error("Failed to get order event cursor", "error", err, "consumer", consumer)
+		return 0, false, err
+	}
+
+	return cursor.Sequence, true, nil
+}
+
+func (r *orderEventRepository) SaveCursor(ctx context.Context, consumer string, sequence int64) error {
+	cursor := models.OrderEventCursor{Consumer: consumer, Sequence: sequence}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}},
+		DoUpdates: clause.AssignmentColumns([]string{"sequence", "updated_at"}),
+	}).Create(&cursor).Error; err != nil {
+		r.logger.Error("Failed to save order event cursor", "error", err, "consumer", consumer, "sequence", sequence)
+		return err
+	}
+
+	return nil
+}
+
+// AppendOrderEvent records a lifecycle step of an order within tx, after the order
+// has been written. previousStatus is the status before the step.
+func AppendOrderEvent(tx *gorm.DB, order *models.Order, eventType models.OrderEventType, previousStatus models.OrderStatus) error {
+	payload, err := json.Marshal(models.NewOrderEventPayload(order, previousStatus))
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OrderEvent{
+		OrderID:        order.ID,
+		Type:           eventType,
+		PayloadVersion: models.OrderEventPayloadVersion,
+		Payload:        payload,
+	}).Error
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderHoldRepository implements OrderHoldRepository interface
+type orderHoldRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewOrderHoldRepository creates a new order hold repository
+func NewOrderHoldRepository(db *database.DB, logger *logger.Logger) OrderHoldRepository {
+	return &orderHoldRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *orderHoldRepository) Place(ctx context.Context, hold *models.OrderHold) (bool, error) {
+	r.logger.Debug("Placing order hold", "order_id", hold.OrderID, "reason", hold.Reason)
+
+	placed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND NOT on_hold", hold.OrderID).
+			First(&order).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Already on hold
+				return nil
+			}
+			return err
+		}
+		if !order.IsHoldable() {
+			return nil
+		}
+
+		if err := tx.Create(hold).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Update("on_hold", true).Error; err != nil {
+			return err
+		}
+
+		placed = true
+		return AppendOrderEvent(tx, &order, models.OrderEventHoldPlaced, order.Status)
+	})
+	if err != nil {
+		r.logger.Error("Failed to place order hold", "error", err, "order_id", hold.OrderID)
+		return false, err
+	}
+
+	r.logger.Info("Order hold placed", "id", hold.ID, "order_id", hold.OrderID, "reason", hold.Reason, "placed", placed)
+	return placed, nil
+}
+
+func (r *orderHoldRepository) GetByID(ctx context.Context, id string) (*models.OrderHold, error) {
+	r.logger.Debug("Getting order hold by ID", "id", id)
+
+	var hold models.OrderHold
+	if err := r.db.WithContext(ctx).First(&hold, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Order hold not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get order hold by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+func (r *orderHoldRepository) GetActiveByOrderID(ctx context.Context, orderID string) (*models.OrderHold, error) {
+	r.logger.Debug("Getting active order hold", "order_id", orderID)
+
+	var hold models.OrderHold
+	if err := r.db.WithContext(ctx).
+		First(&hold, "order_id = ? AND released_at IS NULL", orderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get active order hold", "error", err, "order_id", orderID)
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+func (r *orderHoldRepository) ListByOrderID(ctx context.Context, orderID string) ([]*models.OrderHold, error) {
+	r.logger.Debug("Listing order holds", "order_id", orderID)
+
+	var holds []*models.OrderHold
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&holds).Error; err != nil {
+		r.logger.Error("Failed to list order holds", "error", err, "order_id", orderID)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r *orderHoldRepository) List(ctx context.Context, filter OrderHoldFilter, offset, limit int) ([]*models.OrderHold, error) {
+	r.logger.Debug("Listing order holds", "reason", filter.Reason, "assigned_to", filter.AssignedTo, "offset", offset, "limit", limit)
+
+	var holds []*models.OrderHold
+	if err := r.filtered(ctx, filter).
+		Order("due_at, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&holds).Error; err != nil {
+		r.logger.Error("Failed to list order holds", "error", err)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r *orderHoldRepository) Count(ctx context.Context, filter OrderHoldFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count order holds", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered scopes an order hold query to the filter
+func (r *orderHoldRepository) filtered(ctx context.Context, filter OrderHoldFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.OrderHold{})
+	if filter.Active != nil {
+		if *filter.Active {
+			query = query.Where("released_at IS NULL")
+		} else {
+			query = query.Where("released_at IS NOT NULL")
+		}
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+	if filter.AssignedTo != "" {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.OverdueAt != nil {
+		query = query.Where("released_at IS NULL AND due_at < ?", *filter.OverdueAt)
+	}
+	return query
+}
+
+func (r *orderHoldRepository) Assign(ctx context.Context, id, assigneeID string, at time.Time) (bool, error) {
+	r.logger.Debug("Assigning order hold", "id", id, "assigned_to", assigneeID)
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OrderHold{}).
+		Where("id = ? AND released_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"assigned_to": assigneeID,
+			"assigned_at": at,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to assign order hold", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *orderHoldRepository) Release(ctx context.Context, id, releasedBy, note string, at time.Time) (bool, error) {
+	r.logger.Debug("Releasing order hold", "id", id, "released_by", releasedBy)
+
+	released := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var hold models.OrderHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND released_at IS NULL", id).
+			First(&hold).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Already released
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Model(&hold).Updates(map[string]interface{}{
+			"released_by":  releasedBy,
+			"released_at":  at,
+			"release_note": note,
+		}).Error; err != nil {
+			return err
+		}
+
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", hold.OrderID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&order).Update("on_hold", false).Error; err != nil {
+			return err
+		}
+
+		released = true
+		return AppendOrderEvent(tx, &order, models.OrderEventHoldReleased, order.Status)
+	})
+	if err != nil {
+		r.logger.Error("Failed to release order hold", "error", err, "id", id)
+		return false, err
+	}
+
+	r.logger.Info("Order hold released", "id", id, "released_by", releasedBy, "released", released)
+	return released, nil
+}
+
+func (r *orderHoldRepository) SummarizeByReason(ctx context.Context, start, end, now time.Time) ([]OrderHoldSummary, error) {
+	r.logger.Debug("Summarizing order holds", "start", start, "end", end)
+
+	var summaries []OrderHoldSummary
+	if err := r.db.WithContext(ctx).
+		Model(&models.OrderHold{}).
+		Select(`reason,
+			COUNT(*) AS placed,
+			COUNT(*) FILTER (WHERE released_at IS NOT NULL) AS released,
+			COUNT(*) FILTER (WHERE released_at IS NULL) AS active,
+			COUNT(*) FILTER (WHERE released_at IS NULL AND due_at < ?) AS overdue,
+			COUNT(*) FILTER (WHERE released_at <= due_at) AS within_sla,
+			COALESCE(SUM(EXTRACT(EPOCH FROM released_at - created_at)) FILTER (WHERE released_at IS NOT NULL), 0) AS hold_seconds`, now).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("reason").
+		Order("reason").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize order holds", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+func (r *orderHoldRepository) CountHeldOrders(ctx context.Context, start, end time.Time) (int64, int64, error) {
+	r.logger.Debug("Counting held orders", "start", start, "end", end)
+
+	var counts struct {
+		Orders int64
+		Held   int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Select(`COUNT(*) AS orders,
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM order_holds WHERE order_holds.order_id = orders.id)) AS held`).
+		Where("orders.created_at >= ? AND orders.created_at < ?", start, end).
+		Scan(&counts).Error; err != nil {
+		r.logger.Error("Failed to count held orders", "error", err)
+		return 0, 0, err
+	}
+
+	return counts.Orders, counts.Held, nil
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"math"
 	"time"
 
 	"easy-orders-backend/internal/models"
@@ -9,8 +13,12 @@ import (
 	"easy-orders-backend/pkg/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errOrderItemsShort rolls back an item cancellation that asked for more than the order has
+var errOrderItemsShort = stderrors.New("order does not have the items to cancel")
+
 // orderRepository implements OrderRepository interface
 type orderRepository struct {
 	db     *database.DB
@@ -28,7 +36,12 @@ func NewOrderRepository(db *database.DB, logger *logger.Logger) OrderRepository
 func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
 	r.logger.Debug("Creating order in database", "user_id", order.UserID, "total", order.TotalAmount)
 
-	if err := r.db.WithContext(ctx).Create(order).Error; err != nil {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		return AppendOrderEvent(tx, order, models.OrderEventCreated, "")
+	}); err != nil {
 		r.logger.Error("Failed to create order", "error", err, "user_id", order.UserID)
 		return err
 	}
@@ -82,6 +95,27 @@ func (r *orderRepository) GetByIDWithItems(ctx context.Context, id string) (*mod
 	return &order, nil
 }
 
+func (r *orderRepository) ListByIDsWithItems(ctx context.Context, ids []string) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders with items by IDs", "count", len(ids))
+
+	var orders []*models.Order
+	if len(ids) == 0 {
+		return orders, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product").
+		Preload("Items.Product.Inventory").
+		Where("id IN ?", ids).
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders with items by IDs", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders with items listed", "requested", len(ids), "count", len(orders))
+	return orders, nil
+}
+
 func (r *orderRepository) GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Order, error) {
 	r.logger.Debug("Getting orders by user ID", "user_id", userID, "offset", offset, "limit", limit)
 
@@ -106,7 +140,12 @@ func (r *orderRepository) GetByUserID(ctx context.Context, userID string, offset
 func (r *orderRepository) Update(ctx context.Context, order *models.Order) error {
 	r.logger.Debug("Updating order in database", "id", order.ID)
 
-	if err := r.db.WithContext(ctx).Save(order).Error; err != nil {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(order).Error; err != nil {
+			return err
+		}
+		return AppendOrderEvent(tx, order, models.OrderEventUpdated, order.Status)
+	}); err != nil {
 		r.logger.Error("Failed to update order", "error", err, "id", order.ID)
 		return err
 	}
@@ -118,25 +157,67 @@ func (r *orderRepository) Update(ctx context.Context, order *models.Order) error
 func (r *orderRepository) UpdateStatus(ctx context.Context, id string, status models.OrderStatus) error {
 	r.logger.Debug("Updating order status", "id", id, "status", status)
 
-	result := r.db.WithContext(ctx).
-		Model(&models.Order{}).
-		Where("id = ?", id).
-		Update("status", status)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id).Error; err != nil {
+			return err
+		}
 
-	if result.Error != nil {
-		r.logger.Error("Failed to update order status", "error", result.Error, "id", id)
-		return result.Error
-	}
+		previousStatus := order.Status
+		if err := tx.Model(&order).Update("status", status).Error; err != nil {
+			return err
+		}
+		return AppendOrderEvent(tx, &order, models.OrderEventStatusChanged, previousStatus)
+	})
 
-	if result.RowsAffected == 0 {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
 		r.logger.Warn("No order found to update status", "id", id)
 		return gorm.ErrRecordNotFound
 	}
 
+	if err != nil {
+		r.logger.Error("Failed to update order status", "error", err, "id", id)
+		return err
+	}
+
 	r.logger.Info("Order status updated", "id", id, "status", status)
 	return nil
 }
 
+// TransitionStatus checks the transition against the order as locked, so concurrent
+// changes of status cannot both apply
+func (r *orderRepository) TransitionStatus(ctx context.Context, id string, status models.OrderStatus) (bool, error) {
+	r.logger.Debug("Transitioning order status", "id", id, "status", status)
+
+	transitioned := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", id).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if !order.CanTransitionTo(status) {
+			return nil
+		}
+
+		previousStatus := order.Status
+		if err := tx.Model(&order).Update("status", status).Error; err != nil {
+			return err
+		}
+		transitioned = true
+		return AppendOrderEvent(tx, &order, models.OrderEventStatusChanged, previousStatus)
+	})
+	if err != nil {
+		r.logger.Error("Failed to transition order status", "error", err, "id", id, "status", status)
+		return false, err
+	}
+
+	r.logger.Info("Order status transitioned", "id", id, "status", status, "transitioned", transitioned)
+	return transitioned, nil
+}
+
 func (r *orderRepository) List(ctx context.Context, offset, limit int) ([]*models.Order, error) {
 	r.logger.Debug("Listing orders from database", "offset", offset, "limit", limit)
 
@@ -178,6 +259,92 @@ func (r *orderRepository) ListByStatus(ctx context.Context, status models.OrderS
 	return orders, nil
 }
 
+func (r *orderRepository) ListByMetadata(ctx context.Context, key, value string, offset, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders by metadata", "key", key, "value", value, "offset", offset, "limit", limit)
+
+	filter, err := metadataContainsFilter(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Items").
+		Preload("Items.Product").
+		Where("metadata @> ?", filter).
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders by metadata", "error", err, "key", key)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders by metadata retrieved from database", "key", key, "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) ListByRiskBand(ctx context.Context, band models.RiskBand, offset, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders by risk band", "band", band, "offset", offset, "limit", limit)
+
+	min, max, ok := band.ScoreRange()
+	if !ok {
+		return nil, fmt.Errorf("invalid risk band %s", band)
+	}
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Items").
+		Preload("Items.Product").
+		Where("risk_score BETWEEN ? AND ?", min, max).
+		Offset(offset).
+		Limit(limit).
+		Order("risk_score DESC, created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders by risk band", "error", err, "band", band)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders by risk band retrieved from database", "band", band, "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) CountByRiskBand(ctx context.Context, band models.RiskBand) (int64, error) {
+	r.logger.Debug("Counting orders by risk band", "band", band)
+
+	min, max, ok := band.ScoreRange()
+	if !ok {
+		return 0, fmt.Errorf("invalid risk band %s", band)
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("risk_score BETWEEN ? AND ?", min, max).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count orders by risk band", "error", err, "band", band)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *orderRepository) UpdateRiskScore(ctx context.Context, order *models.Order) error {
+	r.logger.Debug("Updating order risk score", "id", order.ID, "risk_score", order.RiskScore)
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.Order{}).
+		Where("id = ?", order.ID).
+		Select("risk_score", "risk_signals", "risk_scored_at").
+		Updates(order).Error; err != nil {
+		r.logger.Error("Failed to update order risk score", "error", err, "id", order.ID)
+		return err
+	}
+
+	return nil
+}
+
 func (r *orderRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Order, error) {
 	r.logger.Debug("Getting orders by date range", "start_date", startDate, "end_date", endDate)
 
@@ -236,3 +403,863 @@ func (r *orderRepository) CountByUserID(ctx context.Context, userID string) (int
 	r.logger.Debug("Total orders by user counted", "user_id", userID, "count", count)
 	return count, nil
 }
+
+func (r *orderRepository) ListByUserID(ctx context.Context, userID string, status models.OrderStatus, offset, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders by user ID", "user_id", userID, "status", status, "offset", offset, "limit", limit)
+
+	query := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product").
+		Preload("Payments").
+		Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []*models.Order
+	if err := query.
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders by user ID", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders listed for user", "user_id", userID, "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) CountByUserIDAndStatus(ctx context.Context, userID string, status models.OrderStatus) (int64, error) {
+	r.logger.Debug("Counting orders by user ID and status", "user_id", userID, "status", status)
+
+	query := r.db.WithContext(ctx).Model(&models.Order{}).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count orders by user ID and status", "error", err, "user_id", userID)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *orderRepository) CountByMetadata(ctx context.Context, key, value string) (int64, error) {
+	r.logger.Debug("Counting orders by metadata", "key", key, "value", value)
+
+	filter, err := metadataContainsFilter(key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).Where("metadata @> ?", filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count orders by metadata", "error", err, "key", key)
+		return 0, err
+	}
+
+	r.logger.Debug("Total orders by metadata counted", "key", key, "count", count)
+	return count, nil
+}
+
+func (r *orderRepository) GetByExternalID(ctx context.Context, channel, externalOrderID string) (*models.Order, error) {
+	r.logger.Debug("Getting order by external ID", "channel", channel, "external_order_id", externalOrderID)
+
+	var order models.Order
+	if err := r.db.WithContext(ctx).
+		First(&order, "channel = ? AND external_order_id = ?", channel, externalOrderID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Order not found", "channel", channel, "external_order_id", externalOrderID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get order by external ID", "error", err, "channel", channel, "external_order_id", externalOrderID)
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r *orderRepository) ListByOrganization(ctx context.Context, organizationID string, offset, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders by organization", "organization_id", organizationID, "offset", offset, "limit", limit)
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Items").
+		Preload("Items.Product").
+		Where("organization_id = ?", organizationID).
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders by organization", "error", err, "organization_id", organizationID)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders by organization retrieved from database", "organization_id", organizationID, "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) CountByOrganization(ctx context.Context, organizationID string) (int64, error) {
+	r.logger.Debug("Counting orders by organization", "organization_id", organizationID)
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).Where("organization_id = ?", organizationID).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count orders by organization", "error", err, "organization_id", organizationID)
+		return 0, err
+	}
+
+	r.logger.Debug("Total orders by organization counted", "organization_id", organizationID, "count", count)
+	return count, nil
+}
+
+func (r *orderRepository) ListOpenInvoices(ctx context.Context) ([]OpenInvoice, error) {
+	r.logger.Debug("Listing open invoices")
+
+	var invoices []OpenInvoice
+	if err := r.db.WithContext(ctx).
+		Table("orders").
+		Scopes(openOnAccountOrders).
+		Select(`orders.id AS order_id, orders.organization_id, organizations.name AS organization_name,
+			orders.total_amount - orders.paid_amount AS amount, orders.invoice_due_at`).
+		Joins("JOIN organizations ON organizations.id = orders.organization_id").
+		Where("NOT orders.credit_hold").
+		Order("orders.invoice_due_at, orders.id").
+		Scan(&invoices).Error; err != nil {
+		r.logger.Error("Failed to list open invoices", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Open invoices retrieved from database", "count", len(invoices))
+	return invoices, nil
+}
+
+func (r *orderRepository) GetOutstandingBalance(ctx context.Context, organizationID string) (float64, error) {
+	r.logger.Debug("Getting organization outstanding balance", "organization_id", organizationID)
+
+	balance, err := OrganizationOutstandingBalance(r.db.WithContext(ctx), organizationID)
+	if err != nil {
+		r.logger.Error("Failed to get organization outstanding balance", "error", err, "organization_id", organizationID)
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *orderRepository) ListCreditHolds(ctx context.Context) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders on credit hold")
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Items").
+		Preload("Items.Product").
+		Where("credit_hold").
+		Order("created_at, id").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders on credit hold", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders on credit hold retrieved from database", "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) ListAwaitingShipment(ctx context.Context, dueBy time.Time) ([]*models.Order, error) {
+	r.logger.Debug("Listing orders awaiting shipment", "due_by", dueBy)
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Where("status IN ?", []models.OrderStatus{
+			models.OrderStatusPending,
+			models.OrderStatusConfirmed,
+			models.OrderStatusPaid,
+		}).
+		Where("promised_ship_by <= ?", dueBy).
+		Order("promised_ship_by, id").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list orders awaiting shipment", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Orders awaiting shipment retrieved from database", "count", len(orders))
+	return orders, nil
+}
+
+func (r *orderRepository) GetByIdempotencyKey(ctx context.Context, userID, key string) (*models.Order, error) {
+	r.logger.Debug("Getting order by idempotency key", "user_id", userID)
+
+	var order models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Payments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at")
+		}).
+		First(&order, "user_id = ? AND idempotency_key = ?", userID, key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Order not found for idempotency key", "user_id", userID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get order by idempotency key", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// ListRecentByUser returns the user's orders placed since the given time, except
+// cancelled and failed ones, newest first with their items preloaded
+func (r *orderRepository) ListRecentByUser(ctx context.Context, userID string, since time.Time) ([]*models.Order, error) {
+	r.logger.Debug("Listing recent orders of user", "user_id", userID, "since", since)
+
+	var orders []*models.Order
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("user_id = ? AND created_at >= ? AND status NOT IN ?", userID, since,
+			[]models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed}).
+		Order("created_at DESC").
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to list recent orders of user", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepository) CountByUserSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	r.logger.Debug("Counting recent orders of user", "user_id", userID, "since", since)
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count recent orders of user", "error", err, "user_id", userID)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ClearIdempotencyKeysBefore forgets the express checkout idempotency keys of up to
+// limit orders placed before the given time, returning how many were cleared. Retries
+// with a cleared key place a new order.
+func (r *orderRepository) ClearIdempotencyKeysBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.logger.Debug("Clearing order idempotency keys", "before", before, "limit", limit)
+
+	batch := r.db.WithContext(ctx).Unscoped().Model(&models.Order{}).Select("id").
+		Where("idempotency_key <> '' AND created_at < ?", before).
+		Limit(limit)
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Order{}).
+		Where("id IN (?)", batch).
+		UpdateColumn("idempotency_key", "")
+	if result.Error != nil {
+		r.logger.Error("Failed to clear order idempotency keys", "error", result.Error, "before", before)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *orderRepository) CountIdempotencyKeysBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&models.Order{}).
+		Where("idempotency_key <> '' AND created_at < ?", before).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count order idempotency keys", "error", err, "before", before)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *orderRepository) ReviewCreditHold(ctx context.Context, id, reviewerID string, status models.OrderStatus) (bool, error) {
+	r.logger.Debug("Reviewing order credit hold", "id", id, "reviewer_id", reviewerID, "status", status)
+
+	reviewed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND credit_hold", id).
+			First(&order).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Not held, or already reviewed
+				return nil
+			}
+			return err
+		}
+
+		previousStatus := order.Status
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"credit_hold":        false,
+			"credit_reviewed_by": reviewerID,
+			"credit_reviewed_at": time.Now(),
+			"status":             status,
+		}).Error; err != nil {
+			return err
+		}
+
+		reviewed = true
+		return AppendOrderEvent(tx, &order, models.OrderEventCreditReviewed, previousStatus)
+	})
+	if err != nil {
+		r.logger.Error("Failed to review order credit hold", "error", err, "id", id)
+		return false, err
+	}
+
+	r.logger.Info("Order credit hold reviewed", "id", id, "status", status, "reviewed", reviewed)
+	return reviewed, nil
+}
+
+func (r *orderRepository) OverrideItemPrice(ctx context.Context, orderID, itemID string, override ItemPriceOverride) (*models.Order, error) {
+	r.logger.Debug("Overriding order item price", "order_id", orderID, "item_id", itemID, "unit_price", override.UnitPrice)
+
+	var repriced *models.Order
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", orderID, models.OrderStatusPending).
+			First(&order).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// No longer pending
+				return nil
+			}
+			return err
+		}
+
+		var item models.OrderItem
+		if err := tx.First(&item, "id = ? AND order_id = ?", itemID, orderID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Items ordered before list prices were recorded keep the price they were charged
+		listPrice := item.ListUnitPrice()
+		if err := tx.Model(&item).Updates(map[string]interface{}{
+			"unit_price":            override.UnitPrice,
+			"total_price":           override.UnitPrice * float64(item.Quantity),
+			"list_price":            listPrice,
+			"price_override_reason": override.Reason,
+			"price_override_note":   override.Note,
+			"price_overridden_by":   override.OverriddenBy,
+			"price_overridden_at":   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		// The payment method adjustment keeps its checkout rate on the new subtotal, and
+		// the checkout promotion discount and shipping fee stay as they were
+		var subtotal float64
+		if err := tx.Model(&models.OrderItem{}).
+			Where("order_id = ?", orderID).
+			Select("COALESCE(SUM(total_price), 0)").
+			Scan(&subtotal).Error; err != nil {
+			return err
+		}
+		subtotal = math.Max(subtotal-order.DiscountAmount, 0)
+		adjustment := math.Round(subtotal*order.PaymentAdjustmentRate) / 100
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"total_amount":       subtotal + adjustment + order.ShippingFee,
+			"payment_adjustment": adjustment,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := AppendOrderEvent(tx, &order, models.OrderEventUpdated, order.Status); err != nil {
+			return err
+		}
+
+		if err := tx.Preload("Items").First(&order, "id = ?", orderID).Error; err != nil {
+			return err
+		}
+		repriced = &order
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to override order item price", "error", err, "order_id", orderID, "item_id", itemID)
+		return nil, err
+	}
+
+	r.logger.Info("Order item price overridden", "order_id", orderID, "item_id", itemID, "overridden", repriced != nil)
+	return repriced, nil
+}
+
+func (r *orderRepository) CancelItems(ctx context.Context, orderID string, quantities map[string]int) (*models.Order, float64, error) {
+	r.logger.Debug("Cancelling order items", "order_id", orderID, "products", len(quantities))
+
+	var reduced *models.Order
+	var reduction float64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", orderID).
+			First(&order).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if !order.IsCancellable() {
+			return nil
+		}
+
+		var items []models.OrderItem
+		if err := tx.Where("order_id = ?", orderID).Order("created_at, id").Find(&items).Error; err != nil {
+			return err
+		}
+
+		var itemsTotal float64
+		for _, item := range items {
+			itemsTotal += item.TotalPrice
+		}
+
+		remaining := make(map[string]int, len(quantities))
+		for productID, quantity := range quantities {
+			remaining[productID] = quantity
+		}
+		for i := range items {
+			item := &items[i]
+			cancelled := min(remaining[item.ProductID], item.Quantity)
+			if cancelled <= 0 {
+				continue
+			}
+			remaining[item.ProductID] -= cancelled
+
+			if cancelled == item.Quantity {
+				if err := tx.Delete(item).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			quantity := item.Quantity - cancelled
+			if err := tx.Model(item).Updates(map[string]interface{}{
+				"quantity":    quantity,
+				"total_price": item.UnitPrice * float64(quantity),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		for _, quantity := range remaining {
+			if quantity > 0 {
+				// Rolled back: the order does not have the quantities
+				return errOrderItemsShort
+			}
+		}
+
+		// The checkout promotion discount is spread over the items by price, so the
+		// remaining items keep their share of it and the cancelled ones take theirs
+		// away. The payment method adjustment keeps its checkout rate on the new
+		// subtotal, and the shipping fee stays as it was.
+		var remainingTotal float64
+		if err := tx.Model(&models.OrderItem{}).
+			Where("order_id = ?", orderID).
+			Select("COALESCE(SUM(total_price), 0)").
+			Scan(&remainingTotal).Error; err != nil {
+			return err
+		}
+		discount := order.DiscountAmount
+		if itemsTotal > 0 {
+			discount = math.Round(order.DiscountAmount*remainingTotal/itemsTotal*100) / 100
+		}
+		subtotal := math.Max(remainingTotal-discount, 0)
+		adjustment := math.Round(subtotal*order.PaymentAdjustmentRate) / 100
+		total := subtotal + adjustment + order.ShippingFee
+		reduction = math.Max(math.Round((order.TotalAmount-total)*100)/100, 0)
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"total_amount":       total,
+			"payment_adjustment": adjustment,
+			"discount_amount":    discount,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := AppendOrderEvent(tx, &order, models.OrderEventUpdated, order.Status); err != nil {
+			return err
+		}
+
+		if err := tx.Preload("Items").Preload("Payments").First(&order, "id = ?", orderID).Error; err != nil {
+			return err
+		}
+		reduced = &order
+		return nil
+	})
+	if stderrors.Is(err, errOrderItemsShort) {
+		r.logger.Warn("Order does not have the quantities to cancel", "order_id", orderID)
+		return nil, 0, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to cancel order items", "error", err, "order_id", orderID)
+		return nil, 0, err
+	}
+
+	r.logger.Info("Order items cancelled", "order_id", orderID, "cancelled", reduced != nil, "reduction", reduction)
+	return reduced, reduction, nil
+}
+
+func (r *orderRepository) RefreshPaidAmount(ctx context.Context, id string) (float64, error) {
+	r.logger.Debug("Refreshing order paid amount", "id", id)
+
+	var paid float64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		paid, err = RefreshOrderPaidAmount(tx, id)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to refresh order paid amount", "error", err, "id", id)
+		return 0, err
+	}
+
+	r.logger.Debug("Order paid amount refreshed", "id", id, "paid_amount", paid)
+	return paid, nil
+}
+
+func (r *orderRepository) ListCreditExposure(ctx context.Context) ([]CreditExposure, error) {
+	r.logger.Debug("Listing organization credit exposure")
+
+	db := r.db.WithContext(ctx)
+	balances := db.Table("orders").
+		Scopes(openOnAccountOrders).
+		Select(`orders.organization_id,
+			COALESCE(SUM(orders.total_amount - orders.paid_amount) FILTER (WHERE NOT orders.credit_hold), 0) AS outstanding,
+			COALESCE(SUM(orders.total_amount - orders.paid_amount) FILTER (WHERE orders.credit_hold), 0) AS held_amount,
+			COUNT(*) FILTER (WHERE orders.credit_hold) AS held_orders`).
+		Group("orders.organization_id")
+
+	var exposures []CreditExposure
+	if err := db.Table("organizations").
+		Select(`organizations.id AS organization_id, organizations.name AS organization_name,
+			organizations.credit_limit, organizations.credit_limit_action,
+			COALESCE(balances.outstanding, 0) AS outstanding,
+			COALESCE(balances.held_amount, 0) AS held_amount,
+			COALESCE(balances.held_orders, 0) AS held_orders`).
+		Joins("LEFT JOIN (?) AS balances ON balances.organization_id = organizations.id", balances).
+		Where("organizations.deleted_at IS NULL").
+		Where("organizations.payment_terms_days > 0 OR balances.organization_id IS NOT NULL").
+		Order("organizations.name").
+		Scan(&exposures).Error; err != nil {
+		r.logger.Error("Failed to list organization credit exposure", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Organization credit exposure retrieved from database", "count", len(exposures))
+	return exposures, nil
+}
+
+func (r *orderRepository) AggregateSalesByDay(ctx context.Context, start, end time.Time) ([]SalesDay, error) {
+	r.logger.Debug("Aggregating sales by day", "start", start, "end", end)
+
+	var days []SalesDay
+	if err := r.db.WithContext(ctx).
+		Table("orders").
+		Scopes(soldOrders(start, end), withOrderRefunds).
+		Select(`(orders.created_at AT TIME ZONE ?)::date AS day,
+			SUM(orders.total_amount) AS revenue,
+			SUM(`+orderRefunded+`) AS refunded_amount,
+			COUNT(*) AS orders,
+			COUNT(DISTINCT orders.user_id) AS customers,
+			COUNT(*) FILTER (WHERE `+orderRefunded+` > 0) AS returned_orders`, start.Location().String()).
+		Group("day").
+		Order("day").
+		Scan(&days).Error; err != nil {
+		r.logger.Error("Failed to aggregate sales by day", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Sales aggregated by day", "days", len(days))
+	return days, nil
+}
+
+func (r *orderRepository) AggregateSalesByProduct(ctx context.Context, start, end time.Time) ([]ProductSales, error) {
+	r.logger.Debug("Aggregating sales by product", "start", start, "end", end)
+
+	refundShare := "CASE WHEN orders.total_amount > 0 THEN " + orderRefunded + " / orders.total_amount ELSE 0 END"
+	var products []ProductSales
+	if err := r.db.WithContext(ctx).
+		Table("order_items").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN products ON products.id = order_items.product_id").
+		Scopes(soldOrders(start, end), withOrderRefunds).
+		Select(`order_items.product_id, products.name AS product_name, products.sku, products.category_id,
+			products.cost_price AS unit_cost,
+			SUM(order_items.quantity) AS quantity,
+			SUM(order_items.total_price) AS revenue,
+			SUM(order_items.total_price * ` + refundShare + `) AS refunded_amount,
+			SUM(order_items.quantity * ` + refundShare + `) AS returned_quantity,
+			COUNT(DISTINCT order_items.order_id) AS orders`).
+		Group("order_items.product_id, products.name, products.sku, products.category_id, products.cost_price").
+		Order("revenue DESC, order_items.product_id").
+		Scan(&products).Error; err != nil {
+		r.logger.Error("Failed to aggregate sales by product", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Sales aggregated by product", "products", len(products))
+	return products, nil
+}
+
+func (r *orderRepository) AggregateSalesByPaymentMethod(ctx context.Context, start, end time.Time) ([]PaymentMethodSales, error) {
+	r.logger.Debug("Aggregating sales by payment method", "start", start, "end", end)
+
+	var methods []PaymentMethodSales
+	if err := r.db.WithContext(ctx).
+		Table("orders").
+		Scopes(soldOrders(start, end)).
+		Select("COALESCE(orders.payment_method, '') AS method, COUNT(*) AS orders, SUM(orders.total_amount) AS revenue").
+		Group("method").
+		Order("revenue DESC, method").
+		Scan(&methods).Error; err != nil {
+		r.logger.Error("Failed to aggregate sales by payment method", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Sales aggregated by payment method", "methods", len(methods))
+	return methods, nil
+}
+
+func (r *orderRepository) AggregateSalesByCustomerType(ctx context.Context, start, end time.Time) ([]CustomerTypeSales, error) {
+	r.logger.Debug("Aggregating sales by customer type", "start", start, "end", end)
+
+	db := r.db.WithContext(ctx)
+	firstSales := db.Table("orders").
+		Select("orders.user_id, MIN(orders.created_at) AS first_sold_at").
+		Where("orders.deleted_at IS NULL AND orders.status IN ?", SoldOrderStatuses).
+		Group("orders.user_id")
+
+	var types []CustomerTypeSales
+	if err := db.Table("orders").
+		Joins("JOIN (?) AS first_sales ON first_sales.user_id = orders.user_id", firstSales).
+		Scopes(soldOrders(start, end)).
+		Select(`first_sales.first_sold_at >= ? AS is_new,
+			COUNT(DISTINCT orders.user_id) AS customers,
+			COUNT(*) AS orders,
+			SUM(orders.total_amount) AS revenue`, start).
+		Group("is_new").
+		Order("is_new DESC").
+		Scan(&types).Error; err != nil {
+		r.logger.Error("Failed to aggregate sales by customer type", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Sales aggregated by customer type", "types", len(types))
+	return types, nil
+}
+
+func (r *orderRepository) AggregateSalesByAgent(ctx context.Context, start, end time.Time) ([]AgentSales, error) {
+	r.logger.Debug("Aggregating sales by agent", "start", start, "end", end)
+
+	var agents []AgentSales
+	if err := r.db.WithContext(ctx).
+		Table("orders").
+		Scopes(soldOrders(start, end), withOrderRefunds).
+		Where("orders.placed_by IS NOT NULL").
+		Select(`orders.placed_by AS agent_id,
+			COUNT(*) AS orders,
+			SUM(orders.total_amount) AS revenue,
+			SUM(` + orderRefunded + `) AS refunded_amount`).
+		Group("orders.placed_by").
+		Order("revenue DESC, agent_id").
+		Scan(&agents).Error; err != nil {
+		r.logger.Error("Failed to aggregate sales by agent", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Sales aggregated by agent", "agents", len(agents))
+	return agents, nil
+}
+
+// orderRefunded is the amount refunded of an order joined with withOrderRefunds, at
+// most its total
+const orderRefunded = "LEAST(COALESCE(order_refunds.refunded, 0), orders.total_amount)"
+
+// withOrderRefunds joins a query on orders with the amount refunded of each order's
+// payments. Payments refunded before partial refunds were recorded count in full.
+func withOrderRefunds(db *gorm.DB) *gorm.DB {
+	return db.Joins(`LEFT JOIN (
+		SELECT payments.order_id, SUM(CASE
+			WHEN payments.status = ? AND payments.refunded_amount = 0 THEN payments.amount
+			ELSE payments.refunded_amount
+		END) AS refunded
+		FROM payments
+		GROUP BY payments.order_id
+	) AS order_refunds ON order_refunds.order_id = orders.id`, models.PaymentStatusRefunded)
+}
+
+// soldOrders scopes a query on orders to the sales placed in [start, end)
+func soldOrders(start, end time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("orders.deleted_at IS NULL AND orders.status IN ?", SoldOrderStatuses).
+			Where("orders.created_at >= ? AND orders.created_at < ?", start, end)
+	}
+}
+
+// OrganizationOutstandingBalance sums the organization's open on-account invoices
+// within db, e.g. a transaction holding the organization row lock. Orders on credit
+// hold are not invoiced until approved, so they are not part of the balance.
+func OrganizationOutstandingBalance(db *gorm.DB, organizationID string) (float64, error) {
+	var balance float64
+	err := db.Table("orders").
+		Scopes(openOnAccountOrders).
+		Select("COALESCE(SUM(orders.total_amount - orders.paid_amount), 0)").
+		Where("orders.organization_id = ? AND NOT orders.credit_hold", organizationID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// UserPurchasedQuantity sums the units of a product in the user's orders placed since
+// since, or ever when since is nil, within db, e.g. a transaction holding the
+// product's inventory row lock. Cancelled and failed orders do not count.
+func UserPurchasedQuantity(db *gorm.DB, userID, productID string, since *time.Time) (int, error) {
+	query := db.Table("order_items").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Select("COALESCE(SUM(order_items.quantity), 0)").
+		Where("orders.user_id = ? AND order_items.product_id = ?", userID, productID).
+		Where("orders.deleted_at IS NULL").
+		Where("orders.status NOT IN ?", []models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed})
+	if since != nil {
+		query = query.Where("orders.created_at >= ?", *since)
+	}
+
+	var quantity int
+	err := query.Scan(&quantity).Error
+	return quantity, err
+}
+
+// openOnAccountOrders scopes a query on orders to on-account orders that are neither
+// cancelled, failed nor fully paid by their completed payments, including those on
+// credit hold
+func openOnAccountOrders(db *gorm.DB) *gorm.DB {
+	return db.
+		Where("orders.on_account AND orders.deleted_at IS NULL").
+		Where("orders.status NOT IN ?", []models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed}).
+		Where("orders.paid_amount < orders.total_amount")
+}
+
+// RefreshOrderPaidAmount sets the paid amount of an order to the sum of its completed
+// payments within tx, e.g. a transaction that just settled one, and returns it. When
+// the amount rises, an order payment completed event is recorded in the same
+// transaction.
+func RefreshOrderPaidAmount(tx *gorm.DB, orderID string) (float64, error) {
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", orderID).
+		First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	previous := order.PaidAmount
+
+	if err := tx.Raw(`UPDATE orders SET paid_amount = (
+			SELECT COALESCE(SUM(payments.amount), 0) FROM payments
+			WHERE payments.order_id = orders.id AND payments.status = ?
+		), updated_at = NOW()
+		WHERE id = ?
+		RETURNING paid_amount, updated_at`, models.PaymentStatusCompleted, orderID).
+		Row().Scan(&order.PaidAmount, &order.UpdatedAt); err != nil {
+		return 0, err
+	}
+
+	if order.PaidAmount-previous >= 0.005 {
+		if err := AppendOrderEvent(tx, &order, models.OrderEventPaymentCompleted, order.Status); err != nil {
+			return 0, err
+		}
+	}
+	return order.PaidAmount, nil
+}
+
+func (r *orderRepository) Iterate(filter OrderFilter, batchSize int) OrderIterator {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &orderIterator{
+		db:        r.db,
+		logger:    r.logger,
+		filter:    filter,
+		batchSize: batchSize,
+	}
+}
+
+// orderIterator implements OrderIterator with a (created_at, id) keyset cursor
+type orderIterator struct {
+	db        *database.DB
+	logger    *logger.Logger
+	filter    OrderFilter
+	batchSize int
+
+	lastCreatedAt time.Time
+	lastID        string
+	done          bool
+}
+
+func (it *orderIterator) Next(ctx context.Context) ([]*models.Order, error) {
+	if it.done {
+		return nil, nil
+	}
+
+	query := it.db.WithContext(ctx).
+		Preload("Items").
+		Preload("Items.Product")
+
+	if it.filter.Status != "" {
+		query = query.Where("status = ?", it.filter.Status)
+	}
+	if it.filter.MetadataKey != "" {
+		filter, err := metadataContainsFilter(it.filter.MetadataKey, it.filter.MetadataValue)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("metadata @> ?", filter)
+	}
+	if !it.filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", it.filter.CreatedFrom)
+	}
+	if it.filter.RiskBand != "" {
+		min, max, ok := it.filter.RiskBand.ScoreRange()
+		if !ok {
+			return nil, fmt.Errorf("invalid risk band %s", it.filter.RiskBand)
+		}
+		query = query.Where("risk_score BETWEEN ? AND ?", min, max)
+	}
+	if it.filter.WithPayments {
+		query = query.Preload("Payments")
+	}
+	if it.lastID != "" {
+		query = query.Where("(created_at, id) < (?, ?)", it.lastCreatedAt, it.lastID)
+	}
+
+	var orders []*models.Order
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(it.batchSize).
+		Find(&orders).Error; err != nil {
+		it.logger.Error("Failed to fetch order batch", "error", err, "after_id", it.lastID)
+		return nil, err
+	}
+
+	if len(orders) < it.batchSize {
+		it.done = true
+	}
+	if len(orders) > 0 {
+		last := orders[len(orders)-1]
+		it.lastCreatedAt = last.CreatedAt
+		it.lastID = last.ID
+	}
+
+	it.logger.Debug("Order batch retrieved from database", "count", len(orders), "done", it.done)
+	return orders, nil
+}
+
+// metadataContainsFilter builds a JSONB containment document for a single key/value pair
+func metadataContainsFilter(key, value string) (string, error) {
+	data, err := json.Marshal(map[string]string{key: value})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
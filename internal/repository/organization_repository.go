@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// organizationRepository implements OrganizationRepository interface
+type organizationRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewOrganizationRepository creates a new organization repository
+func NewOrganizationRepository(db *database.DB, logger *logger.Logger) OrganizationRepository {
+	return &organizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *organizationRepository) Create(ctx context.Context, organization *models.Organization) error {
+	r.logger.Debug("Creating organization in database", "name", organization.Name)
+
+	if err := r.db.WithContext(ctx).Create(organization).Error; err != nil {
+		r.logger.Error("Failed to create organization", "error", err, "name", organization.Name)
+		return err
+	}
+
+	r.logger.Info("Organization created in database", "id", organization.ID, "name", organization.Name)
+	return nil
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id string) (*models.Organization, error) {
+	r.logger.Debug("Getting organization by ID", "id", id)
+
+	var organization models.Organization
+	if err := r.db.WithContext(ctx).First(&organization, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Debug("Organization not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get organization by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+func (r *organizationRepository) GetByName(ctx context.Context, name string) (*models.Organization, error) {
+	r.logger.Debug("Getting organization by name", "name", name)
+
+	var organization models.Organization
+	if err := r.db.WithContext(ctx).First(&organization, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Debug("Organization not found", "name", name)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get organization by name", "error", err, "name", name)
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, organization *models.Organization) error {
+	r.logger.Debug("Updating organization in database", "id", organization.ID)
+
+	if err := r.db.WithContext(ctx).Save(organization).Error; err != nil {
+		r.logger.Error("Failed to update organization", "error", err, "id", organization.ID)
+		return err
+	}
+
+	r.logger.Info("Organization updated in database", "id", organization.ID)
+	return nil
+}
+
+func (r *organizationRepository) List(ctx context.Context, offset, limit int) ([]*models.Organization, error) {
+	r.logger.Debug("Listing organizations from database", "offset", offset, "limit", limit)
+
+	var organizations []*models.Organization
+	if err := r.db.WithContext(ctx).
+		Offset(offset).
+		Limit(limit).
+		Order("name").
+		Find(&organizations).Error; err != nil {
+		r.logger.Error("Failed to list organizations", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Organizations retrieved from database", "count", len(organizations))
+	return organizations, nil
+}
+
+func (r *organizationRepository) Count(ctx context.Context) (int64, error) {
+	r.logger.Debug("Counting total organizations")
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Organization{}).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count organizations", "error", err)
+		return 0, err
+	}
+
+	r.logger.Debug("Total organizations counted", "count", count)
+	return count, nil
+}
+
+func (r *organizationRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Organization, error) {
+	r.logger.Debug("Listing organizations after ID", "after_id", afterID, "limit", limit)
+
+	var organizations []*models.Organization
+	query := r.db.WithContext(ctx).Unscoped()
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	if err := query.
+		Order("id ASC").
+		Limit(limit).
+		Find(&organizations).Error; err != nil {
+		r.logger.Error("Failed to list organizations after ID", "error", err, "after_id", afterID)
+		return nil, err
+	}
+
+	r.logger.Debug("Organizations retrieved from database", "count", len(organizations))
+	return organizations, nil
+}
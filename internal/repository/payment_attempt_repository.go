@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+)
+
+// paymentAttemptRepository implements PaymentAttemptRepository interface
+type paymentAttemptRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPaymentAttemptRepository creates a new payment attempt repository
+func NewPaymentAttemptRepository(db *database.DB, logger *logger.Logger) PaymentAttemptRepository {
+	return &paymentAttemptRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *paymentAttemptRepository) Create(ctx context.Context, attempt *models.PaymentAttempt) error {
+	r.logger.Debug("Creating payment attempt", "payment_id", attempt.PaymentID, "attempt", attempt.AttemptNumber)
+
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		r.logger.Error("Failed to create payment attempt", "error", err, "payment_id", attempt.PaymentID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentAttemptRepository) SummarizeAttempts(ctx context.Context, start, end time.Time) ([]PaymentAttemptSummary, error) {
+	r.logger.Debug("Summarizing payment attempts", "start", start, "end", end)
+
+	var summaries []PaymentAttemptSummary
+	if err := r.db.WithContext(ctx).
+		Model(&models.PaymentAttempt{}).
+		Select(`gateway, method, failure_type,
+			CAST(EXTRACT(HOUR FROM started_at AT TIME ZONE 'UTC') AS INTEGER) AS hour,
+			attempt_number > 1 AS retry,
+			COUNT(*) AS attempts,
+			COUNT(*) FILTER (WHERE success) AS successes`).
+		Where("started_at >= ? AND started_at < ?", start, end).
+		Group("gateway, method, failure_type, hour, retry").
+		Order("gateway, method, failure_type, hour, retry").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize payment attempts", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
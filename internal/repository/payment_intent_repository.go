@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// paymentIntentRepository implements PaymentIntentRepository interface
+type paymentIntentRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPaymentIntentRepository creates a new payment intent repository
+func NewPaymentIntentRepository(db *database.DB, logger *logger.Logger) PaymentIntentRepository {
+	return &paymentIntentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *paymentIntentRepository) Create(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error) {
+	r.logger.Debug("Creating payment intent", "order_id", intent.OrderID, "amount", intent.Amount)
+
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The order row serializes the intents of an order
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&order, "id = ?", intent.OrderID).Error; err != nil {
+			return err
+		}
+
+		if _, err := expireLapsedPaymentIntents(tx.Where("order_id = ?", intent.OrderID), now); err != nil {
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.PaymentIntent{}).
+			Where("order_id = ? AND status IN ?", intent.OrderID, openPaymentIntentStatuses).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return nil
+		}
+
+		if err := tx.Create(intent).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to create payment intent", "error", err, "order_id", intent.OrderID)
+		return false, err
+	}
+
+	return created, nil
+}
+
+func (r *paymentIntentRepository) GetByID(ctx context.Context, id string) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	if err := r.db.WithContext(ctx).First(&intent, "id = ?", id).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment intent", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &intent, nil
+}
+
+func (r *paymentIntentRepository) Claim(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Where("id = ? AND status = ? AND expires_at > ?", intent.ID, models.PaymentIntentStatusRequiresConfirmation, now).
+		Updates(map[string]interface{}{
+			"status":     models.PaymentIntentStatusProcessing,
+			"last_error": "",
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to claim payment intent", "error", result.Error, "id", intent.ID)
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	intent.Status = models.PaymentIntentStatusProcessing
+	intent.LastError = ""
+	return true, nil
+}
+
+func (r *paymentIntentRepository) Transition(ctx context.Context, intent *models.PaymentIntent, from models.PaymentIntentStatus) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.PaymentIntent{}).
+		Where("id = ? AND status = ?", intent.ID, from).
+		Updates(map[string]interface{}{
+			"status":     intent.Status,
+			"payment_id": intent.PaymentID,
+			"last_error": intent.LastError,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to update payment intent", "error", result.Error, "id", intent.ID,
+			"from", from, "to", intent.Status)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentIntentRepository) ListProcessing(ctx context.Context, limit int) ([]*models.PaymentIntent, error) {
+	var intents []*models.PaymentIntent
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND payment_id IS NOT NULL", models.PaymentIntentStatusProcessing).
+		Order("updated_at").
+		Limit(limit).
+		Find(&intents).Error; err != nil {
+		r.logger.Error("Failed to list processing payment intents", "error", err)
+		return nil, err
+	}
+
+	return intents, nil
+}
+
+func (r *paymentIntentRepository) ExpireLapsed(ctx context.Context, now time.Time) (int, error) {
+	expired, err := expireLapsedPaymentIntents(r.db.WithContext(ctx), now)
+	if err != nil {
+		r.logger.Error("Failed to expire payment intents", "error", err)
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+// openPaymentIntentStatuses are the statuses of intents that can still take, or are
+// taking, a payment
+var openPaymentIntentStatuses = []models.PaymentIntentStatus{
+	models.PaymentIntentStatusRequiresConfirmation,
+	models.PaymentIntentStatusProcessing,
+}
+
+// expireLapsedPaymentIntents expires the intents in scope still awaiting confirmation
+// past their expiry, and returns how many it expired
+func expireLapsedPaymentIntents(scope *gorm.DB, now time.Time) (int, error) {
+	result := scope.Model(&models.PaymentIntent{}).
+		Where("status = ? AND expires_at <= ?", models.PaymentIntentStatusRequiresConfirmation, now).
+		Update("status", models.PaymentIntentStatusExpired)
+	return int(result.RowsAffected), result.Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// paymentReconciliationRepository implements PaymentReconciliationRepository interface
+type paymentReconciliationRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPaymentReconciliationRepository creates a new payment reconciliation repository
+func NewPaymentReconciliationRepository(db *database.DB, logger *logger.Logger) PaymentReconciliationRepository {
+	return &paymentReconciliationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// reconciliationOutcomeOrder lists mismatches and errors, which need attention, first
+const reconciliationOutcomeOrder = "CASE outcome WHEN 'mismatch' THEN 0 WHEN 'error' THEN 1 WHEN 'corrected' THEN 2 WHEN 'pending' THEN 3 ELSE 4 END"
+
+func (r *paymentReconciliationRepository) CreateBatch(ctx context.Context, records []*models.PaymentReconciliation) error {
+	if len(records) == 0 {
+		return nil
+	}
+	r.logger.Debug("Creating payment reconciliation records", "count", len(records))
+
+	if err := r.db.WithContext(ctx).CreateInBatches(records, 100).Error; err != nil {
+		r.logger.Error("Failed to create payment reconciliation records", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentReconciliationRepository) ListByRunID(ctx context.Context, runID string) ([]*models.PaymentReconciliation, error) {
+	r.logger.Debug("Listing payment reconciliation records of run", "run_id", runID)
+
+	return r.list(r.db.WithContext(ctx).Where("run_id = ?", runID))
+}
+
+func (r *paymentReconciliationRepository) ListBetween(ctx context.Context, start, end time.Time) ([]*models.PaymentReconciliation, error) {
+	r.logger.Debug("Listing payment reconciliation records", "start", start, "end", end)
+
+	return r.list(r.db.WithContext(ctx).Where("created_at >= ? AND created_at < ?", start, end))
+}
+
+func (r *paymentReconciliationRepository) list(query *gorm.DB) ([]*models.PaymentReconciliation, error) {
+	var records []*models.PaymentReconciliation
+	if err := query.
+		Order(reconciliationOutcomeOrder).
+		Order("created_at, id").
+		Find(&records).Error; err != nil {
+		r.logger.Error("Failed to list payment reconciliation records", "error", err)
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	stderrors "errors"
+	"math"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/pkg/database"
 	"easy-orders-backend/pkg/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // paymentRepository implements PaymentRepository interface
@@ -36,6 +40,54 @@ func (r *paymentRepository) Create(ctx context.Context, payment *models.Payment)
 	return nil
 }
 
+func (r *paymentRepository) CreateWithinBalance(ctx context.Context, payment *models.Payment) (bool, error) {
+	r.logger.Debug("Creating payment within order balance", "order_id", payment.OrderID, "amount", payment.Amount, "method", payment.Method)
+
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", payment.OrderID).
+			First(&order).Error; err != nil {
+			return err
+		}
+
+		// Payments in progress may still complete, so they count against the balance
+		var committed float64
+		if err := tx.Model(&models.Payment{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("order_id = ? AND status IN ?", order.ID, []models.PaymentStatus{
+				models.PaymentStatusCompleted,
+				models.PaymentStatusPending,
+				models.PaymentStatusProcessed,
+				models.PaymentStatusHeld,
+			}).
+			Scan(&committed).Error; err != nil {
+			return err
+		}
+		if math.Round(payment.Amount*100) > math.Round((order.TotalAmount-committed)*100) {
+			return nil
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to create payment", "error", err, "order_id", payment.OrderID)
+		return false, err
+	}
+	if !created {
+		r.logger.Info("Payment exceeds the order balance, not created", "order_id", payment.OrderID, "amount", payment.Amount)
+		return false, nil
+	}
+
+	r.logger.Info("Payment created in database", "id", payment.ID, "transaction_id", payment.TransactionID, "order_id", payment.OrderID)
+	return true, nil
+}
+
 func (r *paymentRepository) GetByID(ctx context.Context, id string) (*models.Payment, error) {
 	r.logger.Debug("Getting payment by ID", "id", id)
 
@@ -76,6 +128,24 @@ func (r *paymentRepository) GetByTransactionID(ctx context.Context, transactionI
 	return &payment, nil
 }
 
+func (r *paymentRepository) GetByGatewayTransactionID(ctx context.Context, gateway, gatewayTxnID string) (*models.Payment, error) {
+	r.logger.Debug("Getting payment by gateway transaction ID", "gateway", gateway, "gateway_txn_id", gatewayTxnID)
+
+	var payment models.Payment
+	if err := r.db.WithContext(ctx).
+		First(&payment, "gateway = ? AND gateway_txn_id = ?", gateway, gatewayTxnID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Payment not found", "gateway", gateway, "gateway_txn_id", gatewayTxnID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment by gateway transaction ID", "error", err, "gateway", gateway, "gateway_txn_id", gatewayTxnID)
+		return nil, err
+	}
+
+	r.logger.Debug("Payment retrieved from database", "gateway_txn_id", gatewayTxnID, "status", payment.Status)
+	return &payment, nil
+}
+
 func (r *paymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.Payment, error) {
 	r.logger.Debug("Getting payments by order ID", "order_id", orderID)
 
@@ -92,6 +162,21 @@ func (r *paymentRepository) GetByOrderID(ctx context.Context, orderID string) ([
 	return payments, nil
 }
 
+func (r *paymentRepository) SumCompletedByOrderID(ctx context.Context, orderID string) (float64, error) {
+	r.logger.Debug("Summing completed payments for order", "order_id", orderID)
+
+	var paid float64
+	if err := r.db.WithContext(ctx).Model(&models.Payment{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("order_id = ? AND status = ?", orderID, models.PaymentStatusCompleted).
+		Scan(&paid).Error; err != nil {
+		r.logger.Error("Failed to sum completed payments", "error", err, "order_id", orderID)
+		return 0, err
+	}
+
+	return paid, nil
+}
+
 func (r *paymentRepository) Update(ctx context.Context, payment *models.Payment) error {
 	r.logger.Debug("Updating payment in database", "id", payment.ID)
 
@@ -144,3 +229,272 @@ func (r *paymentRepository) List(ctx context.Context, offset, limit int) ([]*mod
 	r.logger.Debug("Payments retrieved from database", "count", len(payments))
 	return payments, nil
 }
+
+func (r *paymentRepository) ListHeldDue(ctx context.Context, now time.Time, limit int) ([]*models.Payment, error) {
+	r.logger.Debug("Listing held payments due for retry", "now", now, "limit", limit)
+
+	var payments []*models.Payment
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", models.PaymentStatusHeld).
+		Where("next_retry_at <= ? OR retry_until <= ?", now, now).
+		Order("next_retry_at, id").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		r.logger.Error("Failed to list held payments due for retry", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Held payments due for retry retrieved from database", "count", len(payments))
+	return payments, nil
+}
+
+func (r *paymentRepository) ClaimHeld(ctx context.Context, id string) (bool, error) {
+	r.logger.Debug("Claiming held payment", "id", id)
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("id = ? AND status = ?", id, models.PaymentStatusHeld).
+		Update("status", models.PaymentStatusPending)
+	if result.Error != nil {
+		r.logger.Error("Failed to claim held payment", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error) {
+	r.logger.Debug("Getting payment by idempotency key", "idempotency_key", key)
+
+	var payment models.Payment
+	if err := r.db.WithContext(ctx).First(&payment, "idempotency_key = ?", key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment by idempotency key", "error", err, "idempotency_key", key)
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) RecordManual(ctx context.Context, record ManualPaymentRecord) (bool, error) {
+	payment := record.Payment
+	r.logger.Debug("Recording manual payment", "order_id", payment.OrderID, "method", payment.Method, "amount", payment.Amount)
+
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", payment.OrderID, record.OrderStatus).
+			First(&order).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Status changed since the payment was validated
+				return nil
+			}
+			return err
+		}
+		if !order.IsPayable() {
+			return nil
+		}
+
+		// A gateway payment may have settled, or be held for retry, in the meantime
+		var held int64
+		if err := tx.Model(&models.Payment{}).
+			Where("order_id = ? AND status = ?", order.ID, models.PaymentStatusHeld).
+			Count(&held).Error; err != nil {
+			return err
+		}
+		if held > 0 {
+			return nil
+		}
+		var paid float64
+		if err := tx.Model(&models.Payment{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("order_id = ? AND status = ?", order.ID, models.PaymentStatusCompleted).
+			Scan(&paid).Error; err != nil {
+			return err
+		}
+		if math.Abs(paid-record.PaidAmount) >= 0.005 {
+			return nil
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		if record.Proof != nil {
+			record.Proof.PaymentID = payment.ID
+			if err := tx.Create(record.Proof).Error; err != nil {
+				return err
+			}
+		}
+		paidAmount, err := RefreshOrderPaidAmount(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		order.PaidAmount = paidAmount
+
+		// On-account invoices paid after shipping keep their status
+		if order.IsFullyPaid() && (order.IsPending() || order.IsConfirmed()) {
+			previousStatus := order.Status
+			if err := tx.Model(&order).Update("status", models.OrderStatusPaid).Error; err != nil {
+				return err
+			}
+			if err := AppendOrderEvent(tx, &order, models.OrderEventStatusChanged, previousStatus); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(record.AuditLog).Error; err != nil {
+			return err
+		}
+		recorded = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to record manual payment", "error", err, "order_id", payment.OrderID)
+		return false, err
+	}
+
+	if recorded {
+		r.logger.Info("Manual payment recorded", "id", payment.ID, "order_id", payment.OrderID, "method", payment.Method)
+	}
+	return recorded, nil
+}
+
+func (r *paymentRepository) GetProof(ctx context.Context, paymentID string) (*models.PaymentProof, error) {
+	r.logger.Debug("Getting payment proof", "payment_id", paymentID)
+
+	var proof models.PaymentProof
+	if err := r.db.WithContext(ctx).First(&proof, "payment_id = ?", paymentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment proof", "error", err, "payment_id", paymentID)
+		return nil, err
+	}
+
+	return &proof, nil
+}
+
+func (r *paymentRepository) SummarizeSettlement(ctx context.Context, start, end time.Time) ([]PaymentSettlementSummary, error) {
+	r.logger.Debug("Summarizing payment settlement", "start", start, "end", end)
+
+	// Refunded payments were settled before the refund, so their gateway fees still count
+	var summaries []PaymentSettlementSummary
+	if err := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Select(`payments.method AS method,
+			COUNT(*) AS payments,
+			COALESCE(SUM(payments.amount), 0) AS gross_amount,
+			COALESCE(SUM(GREATEST(orders.payment_adjustment, 0)), 0) AS surcharges,
+			COALESCE(SUM(GREATEST(-orders.payment_adjustment, 0)), 0) AS discounts,
+			COALESCE(SUM(payments.processing_fee), 0) AS processing_fees`).
+		Joins("JOIN orders ON orders.id = payments.order_id").
+		Where("payments.status IN ?", []models.PaymentStatus{models.PaymentStatusCompleted, models.PaymentStatusRefunded}).
+		Where("payments.processed_at >= ? AND payments.processed_at < ?", start, end).
+		Group("payments.method").
+		Order("payments.method").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize payment settlement", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+func (r *paymentRepository) ListForReconciliation(ctx context.Context, stuckBefore, failedSince time.Time, limit int) ([]*models.Payment, error) {
+	r.logger.Debug("Listing payments for reconciliation", "stuck_before", stuckBefore, "failed_since", failedSince, "limit", limit)
+
+	var payments []*models.Payment
+	if err := r.db.WithContext(ctx).
+		Where("gateway_txn_id <> '' AND updated_at < ?", stuckBefore).
+		Where(r.db.
+			Where("status IN ? AND (reconciled_at IS NULL OR reconciled_at < ?)",
+				[]models.PaymentStatus{models.PaymentStatusPending, models.PaymentStatusProcessed}, stuckBefore).
+			Or("status = ? AND updated_at >= ? AND (reconciled_at IS NULL OR reconciled_at < updated_at)",
+				models.PaymentStatusFailed, failedSince)).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		r.logger.Error("Failed to list payments for reconciliation", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Payments for reconciliation retrieved from database", "count", len(payments))
+	return payments, nil
+}
+
+func (r *paymentRepository) Reconcile(ctx context.Context, payment *models.Payment, from models.PaymentStatus) (bool, error) {
+	r.logger.Debug("Saving reconciled payment", "id", payment.ID, "from", from, "status", payment.Status)
+
+	// The payment was updated when it was reconciled, so it is not checked again after failing
+	result := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("id = ? AND status = ?", payment.ID, from).
+		UpdateColumns(map[string]interface{}{
+			"status":         payment.Status,
+			"failure_reason": payment.FailureReason,
+			"processed_at":   payment.ProcessedAt,
+			"reconciled_at":  payment.ReconciledAt,
+			"updated_at":     payment.ReconciledAt,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to save reconciled payment", "error", result.Error, "id", payment.ID)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentRepository) MarkReconciled(ctx context.Context, id string, at time.Time) error {
+	r.logger.Debug("Marking payment reconciled", "id", id, "at", at)
+
+	// Checking a payment does not change it, so its update time is kept
+	if err := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("id = ?", id).
+		UpdateColumn("reconciled_at", at).Error; err != nil {
+		r.logger.Error("Failed to mark payment reconciled", "error", err, "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r *paymentRepository) ListForPolling(ctx context.Context, gateways []string, stuckBefore time.Time, limit int) ([]*models.Payment, error) {
+	r.logger.Debug("Listing payments for polling", "gateways", gateways, "stuck_before", stuckBefore, "limit", limit)
+
+	var payments []*models.Payment
+	if len(gateways) == 0 {
+		return payments, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("gateway IN ? AND gateway_txn_id <> '' AND updated_at < ?", gateways, stuckBefore).
+		Where("status IN ? AND (reconciled_at IS NULL OR reconciled_at < ?)",
+			[]models.PaymentStatus{models.PaymentStatusPending, models.PaymentStatusProcessed}, stuckBefore).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&payments).Error; err != nil {
+		r.logger.Error("Failed to list payments for polling", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Payments for polling retrieved from database", "count", len(payments))
+	return payments, nil
+}
+
+func (r *paymentRepository) MarkEscalated(ctx context.Context, id string, at time.Time) error {
+	r.logger.Debug("Marking payment escalated", "id", id, "at", at)
+
+	// Escalating a payment does not change it, so its update time is kept
+	if err := r.db.WithContext(ctx).
+		Model(&models.Payment{}).
+		Where("id = ?", id).
+		UpdateColumn("escalated_at", at).Error; err != nil {
+		r.logger.Error("Failed to mark payment escalated", "error", err, "id", id)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// paymentRoutingRuleRepository implements PaymentRoutingRuleRepository interface
+type paymentRoutingRuleRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPaymentRoutingRuleRepository creates a new payment routing rule repository
+func NewPaymentRoutingRuleRepository(db *database.DB, logger *logger.Logger) PaymentRoutingRuleRepository {
+	return &paymentRoutingRuleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *paymentRoutingRuleRepository) Create(ctx context.Context, rule *models.PaymentRoutingRule) error {
+	r.logger.Debug("Creating payment routing rule in database", "name", rule.Name, "action", rule.Action)
+
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		r.logger.Error("Failed to create payment routing rule", "error", err, "name", rule.Name)
+		return err
+	}
+
+	r.logger.Info("Payment routing rule created in database", "id", rule.ID)
+	return nil
+}
+
+func (r *paymentRoutingRuleRepository) GetByID(ctx context.Context, id string) (*models.PaymentRoutingRule, error) {
+	r.logger.Debug("Getting payment routing rule by ID", "id", id)
+
+	var rule models.PaymentRoutingRule
+	if err := r.db.WithContext(ctx).First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment routing rule by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *paymentRoutingRuleRepository) GetByName(ctx context.Context, name string) (*models.PaymentRoutingRule, error) {
+	r.logger.Debug("Getting payment routing rule by name", "name", name)
+
+	var rule models.PaymentRoutingRule
+	if err := r.db.WithContext(ctx).First(&rule, "name = ?", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get payment routing rule by name", "error", err, "name", name)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *paymentRoutingRuleRepository) Update(ctx context.Context, rule *models.PaymentRoutingRule) error {
+	r.logger.Debug("Updating payment routing rule in database", "id", rule.ID)
+
+	if err := r.db.WithContext(ctx).Save(rule).Error; err != nil {
+		r.logger.Error("Failed to update payment routing rule", "error", err, "id", rule.ID)
+		return err
+	}
+
+	r.logger.Info("Payment routing rule updated in database", "id", rule.ID)
+	return nil
+}
+
+func (r *paymentRoutingRuleRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting payment routing rule from database", "id", id)
+
+	if err := r.db.WithContext(ctx).Delete(&models.PaymentRoutingRule{}, "id = ?", id).Error; err != nil {
+		r.logger.Error("Failed to delete payment routing rule", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Payment routing rule deleted from database", "id", id)
+	return nil
+}
+
+func (r *paymentRoutingRuleRepository) List(ctx context.Context, activeOnly bool) ([]*models.PaymentRoutingRule, error) {
+	r.logger.Debug("Listing payment routing rules", "active_only", activeOnly)
+
+	query := r.db.WithContext(ctx).Model(&models.PaymentRoutingRule{})
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var rules []*models.PaymentRoutingRule
+	if err := query.Order("priority DESC, name ASC").Find(&rules).Error; err != nil {
+		r.logger.Error("Failed to list payment routing rules", "error", err)
+		return nil, err
+	}
+
+	return rules, nil
+}
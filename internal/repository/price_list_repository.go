@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// priceListRepository implements PriceListRepository interface
+type priceListRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPriceListRepository creates a new price list repository
+func NewPriceListRepository(db *database.DB, logger *logger.Logger) PriceListRepository {
+	return &priceListRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *priceListRepository) Create(ctx context.Context, priceList *models.PriceList) error {
+	r.logger.Debug("Creating price list in database", "name", priceList.Name)
+
+	if err := r.db.WithContext(ctx).Create(priceList).Error; err != nil {
+		r.logger.Error("Failed to create price list", "error", err, "name", priceList.Name)
+		return err
+	}
+
+	r.logger.Info("Price list created in database", "id", priceList.ID)
+	return nil
+}
+
+func (r *priceListRepository) GetByID(ctx context.Context, id string) (*models.PriceList, error) {
+	r.logger.Debug("Getting price list by ID", "id", id)
+
+	var priceList models.PriceList
+	if err := r.db.WithContext(ctx).First(&priceList, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get price list by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &priceList, nil
+}
+
+func (r *priceListRepository) GetByName(ctx context.Context, name string) (*models.PriceList, error) {
+	r.logger.Debug("Getting price list by name", "name", name)
+
+	var priceList models.PriceList
+	if err := r.db.WithContext(ctx).First(&priceList, "name = ?", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get price list by name", "error", err, "name", name)
+		return nil, err
+	}
+
+	return &priceList, nil
+}
+
+func (r *priceListRepository) Update(ctx context.Context, priceList *models.PriceList) error {
+	r.logger.Debug("Updating price list in database", "id", priceList.ID)
+
+	if err := r.db.WithContext(ctx).Omit("Items").Save(priceList).Error; err != nil {
+		r.logger.Error("Failed to update price list", "error", err, "id", priceList.ID)
+		return err
+	}
+
+	r.logger.Info("Price list updated in database", "id", priceList.ID)
+	return nil
+}
+
+func (r *priceListRepository) List(ctx context.Context, offset, limit int) ([]*models.PriceList, error) {
+	r.logger.Debug("Listing price lists from database", "offset", offset, "limit", limit)
+
+	var priceLists []*models.PriceList
+	if err := r.db.WithContext(ctx).
+		Offset(offset).
+		Limit(limit).
+		Order("name").
+		Find(&priceLists).Error; err != nil {
+		r.logger.Error("Failed to list price lists", "error", err)
+		return nil, err
+	}
+
+	return priceLists, nil
+}
+
+func (r *priceListRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.PriceList{}).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count price lists", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *priceListRepository) ListItems(ctx context.Context, priceListID string) ([]*models.PriceListItem, error) {
+	r.logger.Debug("Listing price list items", "price_list_id", priceListID)
+
+	var items []*models.PriceListItem
+	if err := r.db.WithContext(ctx).
+		Where("price_list_id = ?", priceListID).
+		Order("product_id, effective_from NULLS FIRST, created_at").
+		Find(&items).Error; err != nil {
+		r.logger.Error("Failed to list price list items", "error", err, "price_list_id", priceListID)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *priceListRepository) ImportItems(ctx context.Context, priceListID string, items []*models.PriceListItem, replace bool) error {
+	r.logger.Debug("Importing price list items", "price_list_id", priceListID, "count", len(items), "replace", replace)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("price_list_id = ?", priceListID).Delete(&models.PriceListItem{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for _, item := range items {
+			item.PriceListID = priceListID
+		}
+		return tx.CreateInBatches(items, 500).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to import price list items", "error", err, "price_list_id", priceListID)
+		return err
+	}
+
+	r.logger.Info("Price list items imported", "price_list_id", priceListID, "count", len(items))
+	return nil
+}
+
+func (r *priceListRepository) FindEffectivePrices(ctx context.Context, priceListID string, productIDs []string, at time.Time) (map[string]float64, error) {
+	if len(productIDs) == 0 {
+		return map[string]float64{}, nil
+	}
+
+	// The latest started row of each product comes first
+	var items []*models.PriceListItem
+	if err := r.db.WithContext(ctx).
+		Where("price_list_id = ? AND product_id IN ?", priceListID, productIDs).
+		Where("(effective_from IS NULL OR effective_from <= ?)", at).
+		Where("(effective_to IS NULL OR effective_to > ?)", at).
+		Order("product_id, effective_from DESC NULLS LAST, created_at DESC").
+		Find(&items).Error; err != nil {
+		r.logger.Error("Failed to find effective prices", "error", err, "price_list_id", priceListID)
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(items))
+	for _, item := range items {
+		if _, found := prices[item.ProductID]; !found {
+			prices[item.ProductID] = item.Price
+		}
+	}
+	return prices, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// productAvailabilityRepository implements ProductAvailabilityRepository interface
+type productAvailabilityRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewProductAvailabilityRepository creates a new product availability repository
+func NewProductAvailabilityRepository(db *database.DB, logger *logger.Logger) ProductAvailabilityRepository {
+	return &productAvailabilityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *productAvailabilityRepository) GetByProductID(ctx context.Context, productID string) (*models.ProductAvailability, error) {
+	r.logger.Debug("Getting product availability", "product_id", productID)
+
+	var availability models.ProductAvailability
+	if err := r.db.WithContext(ctx).First(&availability, "product_id = ?", productID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get product availability", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	return &availability, nil
+}
+
+// Refresh reads inventory without row locks, so it never waits on reservations in
+// progress; a concurrent change is picked up by the notification that follows it.
+// Products that went in or out of stock since their last refresh get a catalog event;
+// the statement reads their previous entries before its own writes.
+func (r *productAvailabilityRepository) Refresh(ctx context.Context, productIDs []string) ([]*models.ProductAvailability, error) {
+	r.logger.Debug("Refreshing product availability", "count", len(productIDs))
+
+	var rows []*models.ProductAvailability
+	if len(productIDs) == 0 {
+		return rows, nil
+	}
+
+	if err := r.db.WithContext(ctx).Raw(`WITH previous AS (
+			SELECT product_id, available FROM product_availability WHERE product_id IN ?
+		), refreshed AS (
+			INSERT INTO product_availability (product_id, available, updated_at)
+			SELECT product_id, available, NOW() FROM inventory WHERE product_id IN ?
+			ON CONFLICT (product_id) DO UPDATE SET available = EXCLUDED.available, updated_at = EXCLUDED.updated_at
+			RETURNING product_id, available, updated_at
+		), flipped AS (
+			INSERT INTO catalog_events (product_id, sku, type)
+			SELECT refreshed.product_id, products.sku, ?
+			FROM refreshed
+			JOIN previous ON previous.product_id = refreshed.product_id
+			JOIN products ON products.id = refreshed.product_id
+			WHERE (previous.available > 0) <> (refreshed.available > 0)
+		)
+		SELECT product_id, available, updated_at FROM refreshed`,
+		productIDs, productIDs, models.CatalogEventAvailabilityChanged).
+		Scan(&rows).Error; err != nil {
+		r.logger.Error("Failed to refresh product availability", "error", err, "count", len(productIDs))
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -8,6 +8,7 @@ import (
 	"easy-orders-backend/pkg/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // productRepository implements ProductRepository interface
@@ -27,7 +28,12 @@ func NewProductRepository(db *database.DB, logger *logger.Logger) ProductReposit
 func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
 	r.logger.Debug("Creating product in database", "name", product.Name, "sku", product.SKU)
 
-	if err := r.db.WithContext(ctx).Create(product).Error; err != nil {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		return AppendCatalogEvents(tx, product, models.CatalogEventProductCreated)
+	}); err != nil {
 		r.logger.Error("Failed to create product", "error", err, "sku", product.SKU)
 		return err
 	}
@@ -47,6 +53,11 @@ func (r *productRepository) CreateWithInventory(ctx context.Context, product *mo
 			return err
 		}
 
+		if err := AppendCatalogEvents(tx.WithContext(ctx), product, models.CatalogEventProductCreated); err != nil {
+			r.logger.Error("Failed to record catalog event", "error", err, "product_id", product.ID)
+			return err
+		}
+
 		r.logger.Info("Product created in transaction", "id", product.ID, "sku", product.SKU)
 
 		// Create inventory if provided
@@ -59,6 +70,11 @@ func (r *productRepository) CreateWithInventory(ctx context.Context, product *mo
 				return err
 			}
 
+			if err := AppendInventoryEvent(tx.WithContext(ctx), inventory, models.InventoryEventAdjusted, inventory.Quantity); err != nil {
+				r.logger.Error("Failed to record inventory event", "error", err, "product_id", product.ID)
+				return err
+			}
+
 			r.logger.Info("Inventory created in transaction", "product_id", product.ID, "quantity", inventory.Quantity, "available", inventory.Available)
 		}
 
@@ -81,6 +97,11 @@ func (r *productRepository) GetByID(ctx context.Context, id string) (*models.Pro
 		return nil, err
 	}
 
+	if err := overlayProductShards(r.db.WithContext(ctx), &product); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err, "id", id)
+		return nil, err
+	}
+
 	r.logger.Debug("Product retrieved from database", "id", id)
 	return &product, nil
 }
@@ -100,14 +121,56 @@ func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*models.P
 		return nil, err
 	}
 
+	if err := overlayProductShards(r.db.WithContext(ctx), &product); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err, "sku", sku)
+		return nil, err
+	}
+
 	r.logger.Debug("Product retrieved from database", "sku", sku)
 	return &product, nil
 }
 
+func (r *productRepository) GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+	r.logger.Debug("Getting products by SKUs", "count", len(skus))
+
+	var products []*models.Product
+	if len(skus) == 0 {
+		return products, nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Preload("Inventory").
+		Where("sku IN ?", skus).
+		Find(&products).Error; err != nil {
+		r.logger.Error("Failed to get products by SKUs", "error", err, "count", len(skus))
+		return nil, err
+	}
+
+	if err := overlayProductShards(r.db.WithContext(ctx), products...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Products retrieved by SKUs", "requested", len(skus), "found", len(products))
+	return products, nil
+}
+
 func (r *productRepository) Update(ctx context.Context, product *models.Product) error {
 	r.logger.Debug("Updating product in database", "id", product.ID)
 
-	if err := r.db.WithContext(ctx).Save(product).Error; err != nil {
+	// The product as stored is read in the same transaction, so the catalog events
+	// record what this update changed
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&previous, "id = ?", product.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(product).Error; err != nil {
+			return err
+		}
+		return AppendCatalogEvents(tx, product, models.CatalogChanges(&previous, product)...)
+	}); err != nil {
 		r.logger.Error("Failed to update product", "error", err, "id", product.ID)
 		return err
 	}
@@ -119,8 +182,21 @@ func (r *productRepository) Update(ctx context.Context, product *models.Product)
 func (r *productRepository) Delete(ctx context.Context, id string) error {
 	r.logger.Debug("Deleting product from database", "id", id)
 
-	// Soft delete the product
-	if err := r.db.WithContext(ctx).Delete(&models.Product{}, "id = ?", id).Error; err != nil {
+	// Soft delete the product, recording the deletion only if it was not already deleted
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&product, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		return AppendCatalogEvents(tx, &product, models.CatalogEventProductDeleted)
+	}); err != nil {
 		r.logger.Error("Failed to delete product", "error", err, "id", id)
 		return err
 	}
@@ -143,19 +219,23 @@ func (r *productRepository) List(ctx context.Context, offset, limit int) ([]*mod
 		return nil, err
 	}
 
+	if err := overlayProductShards(r.db.WithContext(ctx), products...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+
 	r.logger.Debug("Products retrieved from database", "count", len(products))
 	return products, nil
 }
 
-func (r *productRepository) Search(ctx context.Context, query string, offset, limit int) ([]*models.Product, error) {
-	r.logger.Debug("Searching products", "query", query, "offset", offset, "limit", limit)
+func (r *productRepository) Search(ctx context.Context, query, locale string, offset, limit int) ([]*models.Product, error) {
+	r.logger.Debug("Searching products", "query", query, "locale", locale, "offset", offset, "limit", limit)
 
 	var products []*models.Product
-	searchPattern := "%" + query + "%"
 
 	if err := r.db.WithContext(ctx).
 		Preload("Inventory").
-		Where("name ILIKE ? OR description ILIKE ? OR sku ILIKE ?", searchPattern, searchPattern, searchPattern).
+		Where(productSearchCondition(query, locale)).
 		Where("is_active = ?", true).
 		Offset(offset).
 		Limit(limit).
@@ -165,6 +245,11 @@ func (r *productRepository) Search(ctx context.Context, query string, offset, li
 		return nil, err
 	}
 
+	if err := overlayProductShards(r.db.WithContext(ctx), products...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err, "query", query)
+		return nil, err
+	}
+
 	r.logger.Debug("Products search results retrieved", "count", len(products), "query", query)
 	return products, nil
 }
@@ -184,6 +269,11 @@ func (r *productRepository) GetActive(ctx context.Context, offset, limit int) ([
 		return nil, err
 	}
 
+	if err := overlayProductShards(r.db.WithContext(ctx), products...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+
 	r.logger.Debug("Active products retrieved from database", "count", len(products))
 	return products, nil
 }
@@ -214,14 +304,13 @@ func (r *productRepository) CountActive(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
-func (r *productRepository) CountSearch(ctx context.Context, query string) (int64, error) {
-	r.logger.Debug("Counting search results", "query", query)
+func (r *productRepository) CountSearch(ctx context.Context, query, locale string) (int64, error) {
+	r.logger.Debug("Counting search results", "query", query, "locale", locale)
 
 	var count int64
-	searchPattern := "%" + query + "%"
 
 	if err := r.db.WithContext(ctx).Model(&models.Product{}).
-		Where("name ILIKE ? OR description ILIKE ? OR sku ILIKE ?", searchPattern, searchPattern, searchPattern).
+		Where(productSearchCondition(query, locale)).
 		Where("is_active = ?", true).
 		Count(&count).Error; err != nil {
 		r.logger.Error("Failed to count search results", "error", err, "query", query)
@@ -231,3 +320,38 @@ func (r *productRepository) CountSearch(ctx context.Context, query string) (int6
 	r.logger.Debug("Total search results counted", "query", query, "count", count)
 	return count, nil
 }
+
+func (r *productRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Product, error) {
+	r.logger.Debug("Listing products after ID", "after_id", afterID, "limit", limit)
+
+	var products []*models.Product
+	query := r.db.WithContext(ctx).Unscoped().
+		Preload("Inventory")
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	if err := query.
+		Order("id ASC").
+		Limit(limit).
+		Find(&products).Error; err != nil {
+		r.logger.Error("Failed to list products after ID", "error", err, "after_id", afterID)
+		return nil, err
+	}
+
+	if err := overlayProductShards(r.db.WithContext(ctx), products...); err != nil {
+		r.logger.Error("Failed to get inventory shards", "error", err)
+		return nil, err
+	}
+
+	r.logger.Debug("Products retrieved from database", "count", len(products))
+	return products, nil
+}
+
+// productSearchCondition matches products whose SKU, default-locale name or
+// description, or name or description translated in locale contain query
+func productSearchCondition(query, locale string) clause.Expr {
+	searchPattern := "%" + query + "%"
+	return gorm.Expr("name ILIKE ? OR description ILIKE ? OR sku ILIKE ? OR "+
+		"(translations -> ?) ->> 'name' ILIKE ? OR (translations -> ?) ->> 'description' ILIKE ?",
+		searchPattern, searchPattern, searchPattern, locale, searchPattern, locale, searchPattern)
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productSerialRepository implements ProductSerialRepository interface
+type productSerialRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewProductSerialRepository creates a new product serial repository
+func NewProductSerialRepository(db *database.DB, logger *logger.Logger) ProductSerialRepository {
+	return &productSerialRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *productSerialRepository) Receive(ctx context.Context, serials []*models.ProductSerial) ([]*models.ProductSerial, error) {
+	if len(serials) == 0 {
+		return nil, nil
+	}
+	productID := serials[0].ProductID
+	r.logger.Debug("Receiving product serials", "product_id", productID, "lot_number", serials[0].LotNumber, "count", len(serials))
+
+	var received []*models.ProductSerial
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		numbers := make([]string, len(serials))
+		for i, serial := range serials {
+			numbers[i] = serial.SerialNumber
+		}
+		var existing []string
+		if err := tx.Model(&models.ProductSerial{}).
+			Where("product_id = ? AND serial_number IN ?", productID, numbers).
+			Pluck("serial_number", &existing).Error; err != nil {
+			return err
+		}
+		known := make(map[string]bool, len(existing))
+		for _, number := range existing {
+			known[number] = true
+		}
+
+		for _, serial := range serials {
+			if !known[serial.SerialNumber] {
+				received = append(received, serial)
+			}
+		}
+		if len(received) == 0 {
+			return nil
+		}
+		return tx.Create(&received).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to receive product serials", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	return received, nil
+}
+
+func (r *productSerialRepository) ListByProduct(ctx context.Context, productID, lotNumber string, offset, limit int) ([]*models.ProductSerial, error) {
+	r.logger.Debug("Listing product serials", "product_id", productID, "lot_number", lotNumber, "offset", offset, "limit", limit)
+
+	var serials []*models.ProductSerial
+	if err := r.byProduct(ctx, productID, lotNumber).
+		Order("received_at ASC, serial_number ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&serials).Error; err != nil {
+		r.logger.Error("Failed to list product serials", "error", err, "product_id", productID)
+		return nil, err
+	}
+
+	return serials, nil
+}
+
+func (r *productSerialRepository) CountByProduct(ctx context.Context, productID, lotNumber string) (int64, error) {
+	var count int64
+	if err := r.byProduct(ctx, productID, lotNumber).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count product serials", "error", err, "product_id", productID)
+		return 0, err
+	}
+	return count, nil
+}
+
+// byProduct scopes a product serial query to a product and, if given, one of its lots
+func (r *productSerialRepository) byProduct(ctx context.Context, productID, lotNumber string) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.ProductSerial{}).Where("product_id = ?", productID)
+	if lotNumber != "" {
+		query = query.Where("lot_number = ?", lotNumber)
+	}
+	return query
+}
+
+func (r *productSerialRepository) Capture(ctx context.Context, item *models.OrderItem, serialNumbers []string, at time.Time) ([]string, error) {
+	r.logger.Debug("Capturing order item serials", "order_item_id", item.ID, "product_id", item.ProductID, "count", len(serialNumbers))
+
+	var rejected []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serials allocated to orders that will not ship are free to capture again
+		var serials []*models.ProductSerial
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND serial_number IN ?", item.ProductID, serialNumbers).
+			Find(&serials).Error; err != nil {
+			return err
+		}
+
+		var allocated []string
+		for _, serial := range serials {
+			if serial.OrderItemID != nil && *serial.OrderItemID != item.ID {
+				allocated = append(allocated, *serial.OrderItemID)
+			}
+		}
+		var active map[string]bool
+		if len(allocated) > 0 {
+			var activeItems []string
+			if err := tx.Model(&models.OrderItem{}).
+				Joins("JOIN orders ON orders.id = order_items.order_id").
+				Where("order_items.id IN ? AND orders.status NOT IN ?", allocated,
+					[]models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed}).
+				Pluck("order_items.id", &activeItems).Error; err != nil {
+				return err
+			}
+			active = make(map[string]bool, len(activeItems))
+			for _, id := range activeItems {
+				active[id] = true
+			}
+		}
+
+		found := make(map[string]bool, len(serials))
+		for _, serial := range serials {
+			if serial.OrderItemID != nil && active[*serial.OrderItemID] {
+				continue
+			}
+			found[serial.SerialNumber] = true
+		}
+		for _, number := range serialNumbers {
+			if !found[number] {
+				rejected = append(rejected, number)
+			}
+		}
+		if len(rejected) > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.ProductSerial{}).
+			Where("order_item_id = ?", item.ID).
+			Updates(map[string]interface{}{
+				"order_item_id": nil,
+				"allocated_at":  nil,
+				"updated_at":    at,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ProductSerial{}).
+			Where("product_id = ? AND serial_number IN ?", item.ProductID, serialNumbers).
+			Updates(map[string]interface{}{
+				"order_item_id": item.ID,
+				"allocated_at":  at,
+				"updated_at":    at,
+			}).Error; err != nil {
+			return err
+		}
+
+		item.SerialNumbers = models.StringList(serialNumbers)
+		return tx.Model(item).UpdateColumns(map[string]interface{}{
+			"serial_numbers": models.StringList(serialNumbers),
+			"updated_at":     at,
+		}).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to capture order item serials", "error", err, "order_item_id", item.ID)
+		return nil, err
+	}
+
+	return rejected, nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// promotionRepository implements PromotionRepository interface
+type promotionRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewPromotionRepository creates a new promotion repository
+func NewPromotionRepository(db *database.DB, logger *logger.Logger) PromotionRepository {
+	return &promotionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	r.logger.Debug("Creating promotion in database", "name", promotion.Name, "type", promotion.Type)
+
+	if err := r.db.WithContext(ctx).Create(promotion).Error; err != nil {
+		r.logger.Error("Failed to create promotion", "error", err, "name", promotion.Name)
+		return err
+	}
+
+	r.logger.Info("Promotion created in database", "id", promotion.ID)
+	return nil
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id string) (*models.Promotion, error) {
+	r.logger.Debug("Getting promotion by ID", "id", id)
+
+	var promotion models.Promotion
+	if err := r.db.WithContext(ctx).First(&promotion, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get promotion by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+func (r *promotionRepository) GetByName(ctx context.Context, name string) (*models.Promotion, error) {
+	r.logger.Debug("Getting promotion by name", "name", name)
+
+	var promotion models.Promotion
+	if err := r.db.WithContext(ctx).First(&promotion, "name = ?", name).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get promotion by name", "error", err, "name", name)
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	r.logger.Debug("Updating promotion in database", "id", promotion.ID)
+
+	if err := r.db.WithContext(ctx).Save(promotion).Error; err != nil {
+		r.logger.Error("Failed to update promotion", "error", err, "id", promotion.ID)
+		return err
+	}
+
+	r.logger.Info("Promotion updated in database", "id", promotion.ID)
+	return nil
+}
+
+func (r *promotionRepository) List(ctx context.Context, activeAt *time.Time, offset, limit int) ([]*models.Promotion, error) {
+	r.logger.Debug("Listing promotions from database", "offset", offset, "limit", limit)
+
+	var promotions []*models.Promotion
+	if err := r.running(r.db.WithContext(ctx), activeAt).
+		Offset(offset).
+		Limit(limit).
+		Order("starts_at DESC, id").
+		Find(&promotions).Error; err != nil {
+		r.logger.Error("Failed to list promotions", "error", err)
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) Count(ctx context.Context, activeAt *time.Time) (int64, error) {
+	var count int64
+	if err := r.running(r.db.WithContext(ctx).Model(&models.Promotion{}), activeAt).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count promotions", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *promotionRepository) ListRunning(ctx context.Context, at time.Time) ([]*models.Promotion, error) {
+	var promotions []*models.Promotion
+	if err := r.running(r.db.WithContext(ctx), &at).
+		Order("priority DESC, starts_at, id").
+		Find(&promotions).Error; err != nil {
+		r.logger.Error("Failed to list running promotions", "error", err)
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func (r *promotionRepository) SummarizeRedemptions(ctx context.Context, start, end time.Time) ([]PromotionSummary, error) {
+	r.logger.Debug("Summarizing promotion redemptions", "start", start, "end", end)
+
+	var summaries []PromotionSummary
+	if err := r.db.WithContext(ctx).
+		Table("order_promotions").
+		Joins("JOIN orders ON orders.id = order_promotions.order_id AND orders.deleted_at IS NULL").
+		Joins("JOIN promotions ON promotions.id = order_promotions.promotion_id").
+		Select(`order_promotions.promotion_id, promotions.name, promotions.type,
+			COUNT(*) AS orders,
+			COUNT(*) FILTER (WHERE orders.status NOT IN ?) AS redeemed,
+			COALESCE(SUM(order_promotions.discount) FILTER (WHERE orders.status NOT IN ?), 0) AS discount,
+			COALESCE(SUM(orders.total_amount) FILTER (WHERE orders.status NOT IN ?), 0) AS revenue`,
+			unsettledOrderStatuses, unsettledOrderStatuses, unsettledOrderStatuses).
+		Where("orders.created_at >= ? AND orders.created_at < ?", start, end).
+		Group("order_promotions.promotion_id, promotions.name, promotions.type").
+		Order("redeemed DESC, discount DESC, order_promotions.promotion_id").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize promotion redemptions", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// running narrows a query to the promotions running at at, unless it is nil
+func (r *promotionRepository) running(query *gorm.DB, at *time.Time) *gorm.DB {
+	if at == nil {
+		return query
+	}
+	return query.Where("is_active AND starts_at <= ? AND ends_at > ?", *at, *at)
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// unsettledOrderStatuses are the statuses of orders that will never be delivered, so
+// they don't qualify a referral
+var unsettledOrderStatuses = []models.OrderStatus{models.OrderStatusCancelled, models.OrderStatusFailed}
+
+// referralRepository implements ReferralRepository interface
+type referralRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewReferralRepository creates a new referral repository
+func NewReferralRepository(db *database.DB, logger *logger.Logger) ReferralRepository {
+	return &referralRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *referralRepository) GetCodeByUserID(ctx context.Context, userID string) (*models.ReferralCode, error) {
+	r.logger.Debug("Getting referral code by user ID", "user_id", userID)
+
+	var code models.ReferralCode
+	if err := r.db.WithContext(ctx).First(&code, "user_id = ?", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get referral code by user ID", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (r *referralRepository) GetCodeByCode(ctx context.Context, code string) (*models.ReferralCode, error) {
+	r.logger.Debug("Getting referral code", "code", code)
+
+	var referralCode models.ReferralCode
+	if err := r.db.WithContext(ctx).First(&referralCode, "code = ?", code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get referral code", "error", err, "code", code)
+		return nil, err
+	}
+
+	return &referralCode, nil
+}
+
+func (r *referralRepository) CreateCode(ctx context.Context, code *models.ReferralCode) (bool, error) {
+	r.logger.Debug("Creating referral code", "user_id", code.UserID)
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(code)
+	if result.Error != nil {
+		r.logger.Error("Failed to create referral code", "error", result.Error, "user_id", code.UserID)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *referralRepository) Create(ctx context.Context, referral *models.Referral) (bool, error) {
+	r.logger.Debug("Creating referral", "referrer_id", referral.ReferrerID, "referee_id", referral.RefereeID)
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "referee_id"}},
+			DoNothing: true,
+		}).
+		Create(referral)
+	if result.Error != nil {
+		r.logger.Error("Failed to create referral", "error", result.Error, "referee_id", referral.RefereeID)
+		return false, result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		r.logger.Info("Referral created", "id", referral.ID, "referrer_id", referral.ReferrerID, "referee_id", referral.RefereeID)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *referralRepository) ListByReferrerID(ctx context.Context, referrerID string) ([]models.Referral, error) {
+	r.logger.Debug("Listing referrals", "referrer_id", referrerID)
+
+	var referrals []models.Referral
+	if err := r.db.WithContext(ctx).
+		Where("referrer_id = ?", referrerID).
+		Order("created_at DESC").
+		Find(&referrals).Error; err != nil {
+		r.logger.Error("Failed to list referrals", "error", err, "referrer_id", referrerID)
+		return nil, err
+	}
+
+	return referrals, nil
+}
+
+func (r *referralRepository) AttributeOrder(ctx context.Context, refereeID, orderID string, at time.Time) (bool, error) {
+	r.logger.Debug("Attributing order to referral", "referee_id", refereeID, "order_id", orderID)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.Referral{}).
+		Where("referee_id = ?", refereeID).
+		Where("(status = ? OR (status = ? AND order_id IN (SELECT id FROM orders WHERE status IN ?)))",
+			models.ReferralStatusSignedUp, models.ReferralStatusOrdered, unsettledOrderStatuses).
+		Updates(map[string]interface{}{
+			"status":     models.ReferralStatusOrdered,
+			"order_id":   orderID,
+			"ordered_at": at,
+		})
+	if update.Error != nil {
+		r.logger.Error("Failed to attribute order to referral", "error", update.Error, "referee_id", refereeID, "order_id", orderID)
+		return false, update.Error
+	}
+
+	if update.RowsAffected > 0 {
+		r.logger.Info("Order attributed to referral", "referee_id", refereeID, "order_id", orderID)
+	}
+	return update.RowsAffected > 0, nil
+}
+
+func (r *referralRepository) ListSettleable(ctx context.Context, deliveredBefore time.Time, limit int) ([]ReferralOrderOutcome, error) {
+	r.logger.Debug("Listing settleable referrals", "delivered_before", deliveredBefore, "limit", limit)
+
+	// Orders are delivered when their status last changed to delivered, or, for orders
+	// without lifecycle events, when they were last updated
+	deliveredAt := `COALESCE((
+		SELECT MAX(order_events.recorded_at) FROM order_events
+		WHERE order_events.order_id = orders.id AND order_events.type = ?
+			AND order_events.payload->>'status' = ?
+	), orders.updated_at)`
+
+	var outcomes []ReferralOrderOutcome
+	if err := r.db.WithContext(ctx).
+		Table("referrals").
+		Joins("JOIN orders ON orders.id = referrals.order_id").
+		Scopes(withOrderRefunds).
+		Select(`referrals.id, referrals.referrer_id, referrals.referee_id, referrals.order_id,
+			orders.status AS order_status,
+			orders.deleted_at IS NOT NULL AS order_deleted,
+			orders.total_amount AS order_total,
+			`+orderRefunded+` AS refunded`).
+		Where("referrals.status = ?", models.ReferralStatusOrdered).
+		Where("(orders.status IN ? OR orders.deleted_at IS NOT NULL OR (orders.status = ? AND "+deliveredAt+" < ?))",
+			unsettledOrderStatuses, models.OrderStatusDelivered,
+			models.OrderEventStatusChanged, models.OrderStatusDelivered, deliveredBefore).
+		Order("referrals.ordered_at").
+		Limit(limit).
+		Scan(&outcomes).Error; err != nil {
+		r.logger.Error("Failed to list settleable referrals", "error", err)
+		return nil, err
+	}
+
+	return outcomes, nil
+}
+
+func (r *referralRepository) Reward(ctx context.Context, referral *models.Referral, credit *models.StoreCreditTransaction) (bool, error) {
+	r.logger.Debug("Rewarding referral", "id", referral.ID, "referrer_id", referral.ReferrerID, "amount", credit.Amount)
+
+	var rewarded bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		update := tx.Model(&models.Referral{}).
+			Where("id = ? AND status = ?", referral.ID, models.ReferralStatusOrdered).
+			Updates(map[string]interface{}{
+				"status":        models.ReferralStatusRewarded,
+				"reward_amount": referral.RewardAmount,
+				"rewarded_at":   referral.RewardedAt,
+			})
+		if update.Error != nil {
+			return update.Error
+		}
+		if update.RowsAffected == 0 {
+			return nil
+		}
+
+		rewarded = true
+		return tx.Create(credit).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to reward referral", "error", err, "id", referral.ID)
+		return false, err
+	}
+
+	if rewarded {
+		r.logger.Info("Referral rewarded", "id", referral.ID, "referrer_id", referral.ReferrerID, "amount", credit.Amount)
+	}
+	return rewarded, nil
+}
+
+func (r *referralRepository) Void(ctx context.Context, id, reason string) (bool, error) {
+	r.logger.Debug("Voiding referral", "id", id, "reason", reason)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.Referral{}).
+		Where("id = ? AND status = ?", id, models.ReferralStatusOrdered).
+		Updates(map[string]interface{}{
+			"status":      models.ReferralStatusVoid,
+			"void_reason": reason,
+		})
+	if update.Error != nil {
+		r.logger.Error("Failed to void referral", "error", update.Error, "id", id)
+		return false, update.Error
+	}
+
+	if update.RowsAffected > 0 {
+		r.logger.Info("Referral voided", "id", id, "reason", reason)
+	}
+	return update.RowsAffected > 0, nil
+}
+
+func (r *referralRepository) ReleaseOrder(ctx context.Context, id string) (bool, error) {
+	r.logger.Debug("Releasing referral order", "id", id)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.Referral{}).
+		Where("id = ? AND status = ?", id, models.ReferralStatusOrdered).
+		Updates(map[string]interface{}{
+			"status":     models.ReferralStatusSignedUp,
+			"order_id":   nil,
+			"ordered_at": nil,
+		})
+	if update.Error != nil {
+		r.logger.Error("Failed to release referral order", "error", update.Error, "id", id)
+		return false, update.Error
+	}
+
+	return update.RowsAffected > 0, nil
+}
+
+func (r *referralRepository) SummarizeByReferrer(ctx context.Context, start, end time.Time) ([]ReferrerSummary, error) {
+	r.logger.Debug("Summarizing referrals", "start", start, "end", end)
+
+	var summaries []ReferrerSummary
+	if err := r.db.WithContext(ctx).
+		Table("referrals").
+		Joins("JOIN users ON users.id = referrals.referrer_id").
+		Joins("LEFT JOIN orders ON orders.id = referrals.order_id").
+		Select(`referrals.referrer_id, users.name, users.email,
+			COUNT(*) AS signups,
+			COUNT(*) FILTER (WHERE referrals.status <> ?) AS ordered,
+			COUNT(*) FILTER (WHERE referrals.status = ?) AS rewarded,
+			COUNT(*) FILTER (WHERE referrals.status = ?) AS voided,
+			COALESCE(SUM(referrals.reward_amount), 0) AS rewards,
+			COALESCE(SUM(orders.total_amount) FILTER (WHERE orders.status NOT IN ?), 0) AS revenue`,
+			models.ReferralStatusSignedUp, models.ReferralStatusRewarded, models.ReferralStatusVoid, unsettledOrderStatuses).
+		Where("referrals.created_at >= ? AND referrals.created_at < ?", start, end).
+		Group("referrals.referrer_id, users.name, users.email").
+		Order("rewarded DESC, signups DESC, referrals.referrer_id").
+		Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize referrals", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"math"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refundRepository implements RefundRepository interface
+type refundRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewRefundRepository creates a new refund repository
+func NewRefundRepository(db *database.DB, logger *logger.Logger) RefundRepository {
+	return &refundRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *refundRepository) Record(ctx context.Context, refund *models.Refund, refundedBefore float64) (*models.Payment, error) {
+	r.logger.Debug("Recording refund", "payment_id", refund.PaymentID, "amount", refund.Amount)
+
+	var refunded *models.Payment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&payment, "id = ?", refund.PaymentID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		// Another refund may have been applied since this one was validated
+		if math.Abs(payment.RefundedAmount-refundedBefore) >= 0.005 || refund.Amount > payment.RefundableAmount() {
+			return nil
+		}
+
+		payment.ApplyRefund(refund.Amount)
+		if err := tx.Model(&payment).Updates(map[string]interface{}{
+			"refunded_amount": payment.RefundedAmount,
+			"status":          payment.Status,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		refunded = &payment
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to record refund", "error", err, "payment_id", refund.PaymentID)
+		return nil, err
+	}
+
+	if refunded != nil {
+		r.logger.Info("Refund recorded", "id", refund.ID, "payment_id", refund.PaymentID, "amount", refund.Amount)
+	}
+	return refunded, nil
+}
+
+func (r *refundRepository) ListByPaymentID(ctx context.Context, paymentID string) ([]*models.Refund, error) {
+	r.logger.Debug("Listing refunds of payment", "payment_id", paymentID)
+
+	var refunds []*models.Refund
+	if err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&refunds).Error; err != nil {
+		r.logger.Error("Failed to list refunds of payment", "error", err, "payment_id", paymentID)
+		return nil, err
+	}
+
+	return refunds, nil
+}
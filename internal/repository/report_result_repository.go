@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// reportResultRepository implements ReportResultRepository interface
+type reportResultRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewReportResultRepository creates a new report result repository
+func NewReportResultRepository(db *database.DB, logger *logger.Logger) ReportResultRepository {
+	return &reportResultRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *reportResultRepository) Create(ctx context.Context, result *models.ReportResult) error {
+	r.logger.Debug("Creating report result", "id", result.ID, "type", result.Type, "status", result.Status)
+
+	if err := r.db.WithContext(ctx).Create(result).Error; err != nil {
+		r.logger.Error("Failed to create report result", "error", err, "id", result.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *reportResultRepository) GetByID(ctx context.Context, id string) (*models.ReportResult, error) {
+	r.logger.Debug("Getting report result by ID", "id", id)
+
+	var result models.ReportResult
+	if err := r.db.WithContext(ctx).First(&result, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Report result not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get report result by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (r *reportResultRepository) Transition(ctx context.Context, result *models.ReportResult, from ...models.ReportResultStatus) (bool, error) {
+	r.logger.Debug("Transitioning report result", "id", result.ID, "from", from, "to", result.Status)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.ReportResult{}).
+		Where("id = ? AND status IN ?", result.ID, from).
+		Select("status", "data", "content", "file_path", "file_size", "row_count", "error", "processing_ms", "generated_at", "expires_at", "updated_at").
+		Updates(result)
+	if update.Error != nil {
+		r.logger.Error("Failed to transition report result", "error", update.Error, "id", result.ID, "status", result.Status)
+		return false, update.Error
+	}
+
+	return update.RowsAffected > 0, nil
+}
+
+func (r *reportResultRepository) FailUnfinished(ctx context.Context, before time.Time, reason string) (int64, error) {
+	r.logger.Debug("Failing unfinished report results", "before", before)
+
+	update := r.db.WithContext(ctx).
+		Model(&models.ReportResult{}).
+		Where("status IN ? AND created_at < ?", []models.ReportResultStatus{
+			models.ReportResultStatusPending, models.ReportResultStatusGenerating,
+		}, before).
+		Updates(map[string]interface{}{
+			"status": models.ReportResultStatusFailed,
+			"error":  reason,
+		})
+	if update.Error != nil {
+		r.logger.Error("Failed to fail unfinished report results", "error", update.Error)
+		return 0, update.Error
+	}
+
+	if update.RowsAffected > 0 {
+		r.logger.Warn("Unfinished report results failed", "count", update.RowsAffected, "reason", reason)
+	}
+	return update.RowsAffected, nil
+}
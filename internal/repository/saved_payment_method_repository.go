@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// savedPaymentMethodRepository implements SavedPaymentMethodRepository interface
+type savedPaymentMethodRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewSavedPaymentMethodRepository creates a new saved payment method repository
+func NewSavedPaymentMethodRepository(db *database.DB, logger *logger.Logger) SavedPaymentMethodRepository {
+	return &savedPaymentMethodRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *savedPaymentMethodRepository) Create(ctx context.Context, method *models.SavedPaymentMethod) error {
+	r.logger.Debug("Creating saved payment method", "user_id", method.UserID, "is_default", method.IsDefault)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if method.IsDefault {
+			if err := tx.Model(&models.SavedPaymentMethod{}).
+				Where("user_id = ? AND is_default", method.UserID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(method).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to create saved payment method", "error", err, "user_id", method.UserID)
+		return err
+	}
+
+	r.logger.Info("Saved payment method created", "id", method.ID, "user_id", method.UserID)
+	return nil
+}
+
+func (r *savedPaymentMethodRepository) GetByID(ctx context.Context, id string) (*models.SavedPaymentMethod, error) {
+	r.logger.Debug("Getting saved payment method by ID", "id", id)
+
+	var method models.SavedPaymentMethod
+	if err := r.db.WithContext(ctx).First(&method, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Saved payment method not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get saved payment method by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &method, nil
+}
+
+func (r *savedPaymentMethodRepository) ListByUserID(ctx context.Context, userID string) ([]*models.SavedPaymentMethod, error) {
+	r.logger.Debug("Listing saved payment methods", "user_id", userID)
+
+	var methods []*models.SavedPaymentMethod
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&methods).Error; err != nil {
+		r.logger.Error("Failed to list saved payment methods", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+func (r *savedPaymentMethodRepository) GetDefault(ctx context.Context, userID string) (*models.SavedPaymentMethod, error) {
+	r.logger.Debug("Getting default payment method", "user_id", userID)
+
+	var method models.SavedPaymentMethod
+	if err := r.db.WithContext(ctx).First(&method, "user_id = ? AND is_default", userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("User has no default payment method", "user_id", userID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get default payment method", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return &method, nil
+}
+
+func (r *savedPaymentMethodRepository) SetDefault(ctx context.Context, userID, id string) error {
+	r.logger.Debug("Setting default payment method", "user_id", userID, "id", id)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SavedPaymentMethod{}).
+			Where("user_id = ? AND is_default AND id <> ?", userID, id).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.SavedPaymentMethod{}).
+			Where("id = ? AND user_id = ?", id, userID).
+			Update("is_default", true).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to set default payment method", "error", err, "user_id", userID, "id", id)
+		return err
+	}
+
+	r.logger.Info("Default payment method set", "user_id", userID, "id", id)
+	return nil
+}
+
+func (r *savedPaymentMethodRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting saved payment method", "id", id)
+
+	if err := r.db.WithContext(ctx).Delete(&models.SavedPaymentMethod{}, "id = ?", id).Error; err != nil {
+		r.logger.Error("Failed to delete saved payment method", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Saved payment method deleted", "id", id)
+	return nil
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stockHoldRepository implements StockHoldRepository interface
+type stockHoldRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewStockHoldRepository creates a new stock hold repository
+func NewStockHoldRepository(db *database.DB, logger *logger.Logger) StockHoldRepository {
+	return &stockHoldRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Hold locks the hold row before the inventory row, the same order used when
+// holds are released or converted at checkout
+func (r *stockHoldRepository) Hold(ctx context.Context, hold *models.StockHold) error {
+	r.logger.Debug("Holding stock", "user_id", hold.UserID, "product_id", hold.ProductID, "quantity", hold.Quantity)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.StockHold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND product_id = ? AND status = ?", hold.UserID, hold.ProductID, models.StockHoldStatusActive).
+			First(&existing).Error
+		found := err == nil
+		if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("Failed to get existing stock hold", "error", err, "user_id", hold.UserID, "product_id", hold.ProductID)
+			return err
+		}
+
+		var inventory models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&inventory, "product_id = ?", hold.ProductID).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.NewNotFoundErrorWithID("inventory", hold.ProductID)
+			}
+			r.logger.Error("Failed to get inventory for stock hold", "error", err, "product_id", hold.ProductID)
+			return err
+		}
+
+		// New holds take their ID now so the reservation is recorded against them
+		if !found && hold.ID == "" {
+			hold.ID = uuid.New().String()
+		}
+		holdID := hold.ID
+		if found {
+			holdID = existing.ID
+		}
+
+		// Only the change in held quantity moves stock in or out of the reservation
+		delta := hold.Quantity
+		if found {
+			delta -= existing.Quantity
+		}
+		if delta > 0 {
+			// The stock of a sharded product is on its shards
+			if !inventory.CanReserve(delta) {
+				if err := collapseInventoryShards(tx, &inventory); err != nil {
+					r.logger.Error("Failed to collapse inventory shards for stock hold", "error", err, "product_id", hold.ProductID)
+					return err
+				}
+			}
+			if err := inventory.Reserve(delta); err != nil {
+				return errors.NewInsufficientStockError(hold.ProductID, delta, inventory.Available)
+			}
+		} else if delta < 0 {
+			if err := inventory.Release(-delta); err != nil {
+				return err
+			}
+		}
+
+		if delta != 0 {
+			if err := updateReservedStock(tx, &inventory); err != nil {
+				r.logger.Error("Failed to update inventory for stock hold", "error", err, "product_id", hold.ProductID)
+				return err
+			}
+
+			eventType, quantity := models.InventoryEventReserved, delta
+			if delta < 0 {
+				eventType, quantity = models.InventoryEventReleased, -delta
+			}
+			if err := AppendInventoryEvent(withStockHoldSource(tx, hold.UserID, holdID), &inventory, eventType, quantity); err != nil {
+				r.logger.Error("Failed to record inventory event", "error", err, "product_id", hold.ProductID)
+				return err
+			}
+		}
+
+		if found {
+			existing.Quantity = hold.Quantity
+			existing.ExpiresAt = hold.ExpiresAt
+			if err := tx.Save(&existing).Error; err != nil {
+				r.logger.Error("Failed to update stock hold", "error", err, "id", existing.ID)
+				return err
+			}
+			*hold = existing
+			return nil
+		}
+
+		hold.Status = models.StockHoldStatusActive
+		if err := tx.Create(hold).Error; err != nil {
+			r.logger.Error("Failed to create stock hold", "error", err, "user_id", hold.UserID, "product_id", hold.ProductID)
+			return err
+		}
+
+		r.logger.Info("Stock held", "id", hold.ID, "product_id", hold.ProductID, "quantity", hold.Quantity, "expires_at", hold.ExpiresAt)
+		return nil
+	})
+}
+
+func (r *stockHoldRepository) GetActive(ctx context.Context, userID, productID string) (*models.StockHold, error) {
+	r.logger.Debug("Getting active stock hold", "user_id", userID, "product_id", productID)
+
+	var hold models.StockHold
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND product_id = ? AND status = ?", userID, productID, models.StockHoldStatusActive).
+		First(&hold).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get active stock hold", "error", err, "user_id", userID, "product_id", productID)
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+func (r *stockHoldRepository) ListActiveByUser(ctx context.Context, userID string) ([]*models.StockHold, error) {
+	r.logger.Debug("Listing active stock holds", "user_id", userID)
+
+	var holds []*models.StockHold
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, models.StockHoldStatusActive).
+		Order("created_at").
+		Find(&holds).Error; err != nil {
+		r.logger.Error("Failed to list active stock holds", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r *stockHoldRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.StockHold, error) {
+	r.logger.Debug("Listing expired stock holds", "now", now, "limit", limit)
+
+	var holds []*models.StockHold
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", models.StockHoldStatusActive, now).
+		Order("expires_at").
+		Limit(limit).
+		Find(&holds).Error; err != nil {
+		r.logger.Error("Failed to list expired stock holds", "error", err)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (r *stockHoldRepository) Release(ctx context.Context, id string, status models.StockHoldStatus) (bool, error) {
+	r.logger.Debug("Releasing stock hold", "id", id, "status", status)
+
+	released := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var hold models.StockHold
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", id, models.StockHoldStatusActive).
+			First(&hold).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Already converted, released or expired
+				return nil
+			}
+			return err
+		}
+
+		if err := ReleaseHeldStock(tx, &hold); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&hold).Updates(map[string]interface{}{
+			"status":      status,
+			"released_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		released = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to release stock hold", "error", err, "id", id)
+		return false, err
+	}
+
+	if released {
+		r.logger.Info("Stock hold released", "id", id, "status", status)
+	}
+	return released, nil
+}
+
+func (r *stockHoldRepository) CountByStatus(ctx context.Context, start, end time.Time) ([]StockHoldStatusCount, error) {
+	r.logger.Debug("Counting stock holds by status", "start", start, "end", end)
+
+	var counts []StockHoldStatusCount
+	if err := r.db.WithContext(ctx).
+		Model(&models.StockHold{}).
+		Select("status, COUNT(*) AS holds, COALESCE(SUM(quantity), 0) AS units").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("status").
+		Order("status").
+		Scan(&counts).Error; err != nil {
+		r.logger.Error("Failed to count stock holds by status", "error", err)
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// ReleaseHeldStock returns the quantity of an active hold to available inventory
+// within tx. The caller must hold the hold row lock and update the hold status.
+func ReleaseHeldStock(tx *gorm.DB, hold *models.StockHold) error {
+	var inventory models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&inventory, "product_id = ?", hold.ProductID).Error; err != nil {
+		return err
+	}
+
+	if err := inventory.Release(hold.Quantity); err != nil {
+		return err
+	}
+
+	if err := updateReservedStock(tx, &inventory); err != nil {
+		return err
+	}
+
+	return AppendInventoryEvent(withStockHoldSource(tx, hold.UserID, hold.ID), &inventory, models.InventoryEventReleased, hold.Quantity)
+}
+
+// withStockHoldSource attributes the inventory events written with tx to a user's
+// stock hold
+func withStockHoldSource(tx *gorm.DB, userID, holdID string) *gorm.DB {
+	return tx.WithContext(WithInventoryEventSource(tx.Statement.Context, InventoryEventSource{
+		ActorID:       userID,
+		ReferenceType: models.InventoryReferenceStockHold,
+		ReferenceID:   holdID,
+	}))
+}
+
+// updateReservedStock persists the reserved and available quantities of a locked inventory row
+func updateReservedStock(tx *gorm.DB, inventory *models.Inventory) error {
+	inventory.Version++
+	return tx.Model(inventory).
+		Where("product_id = ?", inventory.ProductID).
+		Updates(map[string]interface{}{
+			"reserved":  inventory.Reserved,
+			"available": inventory.Available,
+			"version":   inventory.Version,
+		}).Error
+}
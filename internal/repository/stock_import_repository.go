@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// stockImportRowBatchSize is the number of rows inserted per statement
+const stockImportRowBatchSize = 500
+
+// stockImportRepository implements StockImportRepository interface
+type stockImportRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewStockImportRepository creates a new stock import repository
+func NewStockImportRepository(db *database.DB, logger *logger.Logger) StockImportRepository {
+	return &stockImportRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *stockImportRepository) Create(ctx context.Context, stockImport *models.StockImport, rows []*models.StockImportRow) error {
+	r.logger.Debug("Creating stock import", "total_rows", stockImport.TotalRows)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(stockImport).Error; err != nil {
+			r.logger.Error("Failed to create stock import", "error", err)
+			return err
+		}
+
+		for _, row := range rows {
+			row.ImportID = stockImport.ID
+		}
+		if err := tx.CreateInBatches(rows, stockImportRowBatchSize).Error; err != nil {
+			r.logger.Error("Failed to create stock import rows", "error", err, "import_id", stockImport.ID)
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *stockImportRepository) GetByID(ctx context.Context, id string) (*models.StockImport, error) {
+	r.logger.Debug("Getting stock import by ID", "id", id)
+
+	var stockImport models.StockImport
+	if err := r.db.WithContext(ctx).First(&stockImport, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get stock import by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &stockImport, nil
+}
+
+func (r *stockImportRepository) ListRows(ctx context.Context, importID string, status models.StockImportRowStatus, offset, limit int) ([]*models.StockImportRow, error) {
+	r.logger.Debug("Listing stock import rows", "import_id", importID, "status", status, "offset", offset, "limit", limit)
+
+	var rows []*models.StockImportRow
+	if err := r.rowsQuery(ctx, importID, status).
+		Order("row_number").
+		Offset(offset).
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		r.logger.Error("Failed to list stock import rows", "error", err, "import_id", importID)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (r *stockImportRepository) CountRows(ctx context.Context, importID string, status models.StockImportRowStatus) (int64, error) {
+	var count int64
+	if err := r.rowsQuery(ctx, importID, status).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count stock import rows", "error", err, "import_id", importID)
+		return 0, err
+	}
+	return count, nil
+}
+
+// rowsQuery selects an import's rows, of one status when given
+func (r *stockImportRepository) rowsQuery(ctx context.Context, importID string, status models.StockImportRowStatus) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.StockImportRow{}).Where("import_id = ?", importID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return query
+}
+
+func (r *stockImportRepository) Fail(ctx context.Context, id string, message string) error {
+	r.logger.Debug("Failing stock import", "id", id)
+
+	if err := r.db.WithContext(ctx).Model(&models.StockImport{}).
+		Where("id = ? AND status = ?", id, models.StockImportStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":       models.StockImportStatusFailed,
+			"error":        message,
+			"completed_at": time.Now(),
+		}).Error; err != nil {
+		r.logger.Error("Failed to fail stock import", "error", err, "id", id)
+		return err
+	}
+	return nil
+}
+
+func (r *stockImportRepository) ApplyChunk(ctx context.Context, importID string, firstRow, lastRow int) ([]string, error) {
+	r.logger.Debug("Applying stock import chunk", "import_id", importID, "first_row", firstRow, "last_row", lastRow)
+
+	var adjusted []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		adjusted = nil
+
+		var stockImport models.StockImport
+		if err := tx.First(&stockImport, "id = ?", importID).Error; err != nil {
+			return err
+		}
+		if stockImport.Status != models.StockImportStatusProcessing {
+			return nil
+		}
+
+		// Rows already applied by an earlier attempt of the chunk are no longer pending
+		var rows []*models.StockImportRow
+		if err := tx.Where("import_id = ? AND row_number BETWEEN ? AND ? AND status = ?",
+			importID, firstRow, lastRow, models.StockImportRowPending).
+			Order("row_number").
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		inventories, err := r.lockInventories(tx, rows)
+		if err != nil {
+			return err
+		}
+
+		// The adjustments are recorded in the inventory history against the import
+		tx = tx.WithContext(WithInventoryEventSource(tx.Statement.Context, InventoryEventSource{
+			ActorID:       stockImport.CreatedBy,
+			ReferenceType: models.InventoryReferenceStockImport,
+			ReferenceID:   importID,
+		}))
+
+		accepted, rejected := 0, 0
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			inventory, found := inventories[row.SKU]
+			switch {
+			case !found:
+				row.Status = models.StockImportRowRejected
+				row.Error = "product not found"
+			case inventory == nil:
+				row.Status = models.StockImportRowRejected
+				row.Error = "inventory not found"
+			case inventory.Quantity+row.Quantity < inventory.Reserved:
+				row.Status = models.StockImportRowRejected
+				row.Error = fmt.Sprintf("adjustment would leave %d on hand, below the %d reserved",
+					inventory.Quantity+row.Quantity, inventory.Reserved)
+			default:
+				if err := adjustImportedStock(tx, inventory, row.Quantity); err != nil {
+					r.logger.Error("Failed to apply stock import row", "error", err, "import_id", importID, "row", row.Row)
+					return err
+				}
+				onHand := inventory.Quantity
+				row.OnHand = &onHand
+				row.Status = models.StockImportRowAccepted
+				if !seen[inventory.ProductID] {
+					seen[inventory.ProductID] = true
+					adjusted = append(adjusted, inventory.ProductID)
+				}
+			}
+			if inventory != nil {
+				productID := inventory.ProductID
+				row.ProductID = &productID
+			}
+
+			if row.Status == models.StockImportRowAccepted {
+				accepted++
+			} else {
+				rejected++
+			}
+			if err := tx.Model(&models.StockImportRow{}).
+				Where("import_id = ? AND row_number = ?", importID, row.Row).
+				Updates(map[string]interface{}{
+					"status":     row.Status,
+					"error":      row.Error,
+					"product_id": row.ProductID,
+					"on_hand":    row.OnHand,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Concurrent chunks queue on the import row here, each seeing the counts of the
+		// ones committed before it, so exactly one of them completes the import
+		processed := accepted + rejected
+		return tx.Model(&models.StockImport{}).
+			Where("id = ?", importID).
+			Updates(map[string]interface{}{
+				"accepted": gorm.Expr("accepted + ?", accepted),
+				"rejected": gorm.Expr("rejected + ?", rejected),
+				"status": gorm.Expr("CASE WHEN accepted + rejected + ? >= total_rows THEN ? ELSE status END",
+					processed, models.StockImportStatusCompleted),
+				"completed_at": gorm.Expr("CASE WHEN accepted + rejected + ? >= total_rows THEN ? ELSE completed_at END",
+					processed, time.Now()),
+			}).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to apply stock import chunk", "error", err, "import_id", importID, "first_row", firstRow)
+		return nil, err
+	}
+
+	return adjusted, nil
+}
+
+// lockInventories locks the inventory of the products of the rows, in product order,
+// the order other writers of several products take them in, and collapses their
+// shards. It maps each known SKU to its inventory, which is nil for a product without
+// one.
+func (r *stockImportRepository) lockInventories(tx *gorm.DB, rows []*models.StockImportRow) (map[string]*models.Inventory, error) {
+	skus := make([]string, 0, len(rows))
+	for _, row := range rows {
+		skus = append(skus, row.SKU)
+	}
+
+	var products []*models.Product
+	if err := tx.Where("sku IN ?", skus).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	sort.Strings(productIDs)
+
+	var locked []*models.Inventory
+	if len(productIDs) > 0 {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN ?", productIDs).
+			Order("product_id").
+			Find(&locked).Error; err != nil {
+			return nil, err
+		}
+	}
+	byProduct := make(map[string]*models.Inventory, len(locked))
+	for _, inventory := range locked {
+		if err := collapseInventoryShards(tx, inventory); err != nil {
+			return nil, err
+		}
+		byProduct[inventory.ProductID] = inventory
+	}
+
+	inventories := make(map[string]*models.Inventory, len(products))
+	for _, product := range products {
+		inventories[product.SKU] = byProduct[product.ID]
+	}
+	return inventories, nil
+}
+
+// adjustImportedStock changes the on hand quantity of a locked inventory row by
+// quantity and records the adjustment
+func adjustImportedStock(tx *gorm.DB, inventory *models.Inventory, quantity int) error {
+	oldVersion := inventory.Version
+	inventory.Quantity += quantity
+	inventory.RefreshAvailable()
+	inventory.Version++
+
+	result := tx.Model(inventory).
+		Where("product_id = ? AND version = ?", inventory.ProductID, oldVersion).
+		Updates(map[string]interface{}{
+			"quantity":  inventory.Quantity,
+			"available": inventory.Available,
+			"version":   inventory.Version,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewOptimisticLockError("inventory", inventory.ProductID)
+	}
+
+	return AppendInventoryEvent(tx, inventory, models.InventoryEventAdjusted, quantity)
+}
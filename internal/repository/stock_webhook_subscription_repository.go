@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// stockWebhookSubscriptionRepository implements StockWebhookSubscriptionRepository interface
+type stockWebhookSubscriptionRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewStockWebhookSubscriptionRepository creates a new stock webhook subscription repository
+func NewStockWebhookSubscriptionRepository(db *database.DB, logger *logger.Logger) StockWebhookSubscriptionRepository {
+	return &stockWebhookSubscriptionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *stockWebhookSubscriptionRepository) Create(ctx context.Context, subscription *models.StockWebhookSubscription) error {
+	r.logger.Debug("Creating stock webhook subscription", "name", subscription.Name, "url", subscription.URL)
+
+	if err := r.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		r.logger.Error("Failed to create stock webhook subscription", "error", err, "name", subscription.Name)
+		return err
+	}
+
+	r.logger.Info("Stock webhook subscription created", "id", subscription.ID, "name", subscription.Name)
+	return nil
+}
+
+func (r *stockWebhookSubscriptionRepository) GetByID(ctx context.Context, id string) (*models.StockWebhookSubscription, error) {
+	r.logger.Debug("Getting stock webhook subscription by ID", "id", id)
+
+	var subscription models.StockWebhookSubscription
+	if err := r.db.WithContext(ctx).First(&subscription, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Stock webhook subscription not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get stock webhook subscription by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *stockWebhookSubscriptionRepository) List(ctx context.Context) ([]*models.StockWebhookSubscription, error) {
+	r.logger.Debug("Listing stock webhook subscriptions")
+
+	var subscriptions []*models.StockWebhookSubscription
+	if err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Find(&subscriptions).Error; err != nil {
+		r.logger.Error("Failed to list stock webhook subscriptions", "error", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *stockWebhookSubscriptionRepository) ListActive(ctx context.Context) ([]*models.StockWebhookSubscription, error) {
+	r.logger.Debug("Listing active stock webhook subscriptions")
+
+	var subscriptions []*models.StockWebhookSubscription
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Find(&subscriptions).Error; err != nil {
+		r.logger.Error("Failed to list active stock webhook subscriptions", "error", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *stockWebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting stock webhook subscription", "id", id)
+
+	if err := r.db.WithContext(ctx).Delete(&models.StockWebhookSubscription{}, "id = ?", id).Error; err != nil {
+		r.logger.Error("Failed to delete stock webhook subscription", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Stock webhook subscription deleted", "id", id)
+	return nil
+}
+
+func (r *stockWebhookSubscriptionRepository) RecordDelivery(ctx context.Context, id string, deliveryErr string) error {
+	updates := map[string]interface{}{
+		"last_error": deliveryErr,
+	}
+	if deliveryErr == "" {
+		updates["last_delivered_at"] = time.Now()
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.StockWebhookSubscription{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		r.logger.Error("Failed to record stock webhook delivery", "error", err, "id", id)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+)
+
+// storeCreditRepository implements StoreCreditRepository interface
+type storeCreditRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewStoreCreditRepository creates a new store credit repository
+func NewStoreCreditRepository(db *database.DB, logger *logger.Logger) StoreCreditRepository {
+	return &storeCreditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *storeCreditRepository) GetBalance(ctx context.Context, userID string) (float64, error) {
+	r.logger.Debug("Getting store credit balance", "user_id", userID)
+
+	var balance float64
+	if err := r.db.WithContext(ctx).
+		Model(&models.StoreCreditTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error; err != nil {
+		r.logger.Error("Failed to get store credit balance", "error", err, "user_id", userID)
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (r *storeCreditRepository) ListByUserID(ctx context.Context, userID string, limit int) ([]models.StoreCreditTransaction, error) {
+	r.logger.Debug("Listing store credit transactions", "user_id", userID, "limit", limit)
+
+	var transactions []models.StoreCreditTransaction
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&transactions).Error; err != nil {
+		r.logger.Error("Failed to list store credit transactions", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// supportSearchRepository implements SupportSearchRepository interface
+type supportSearchRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewSupportSearchRepository creates a new support search repository
+func NewSupportSearchRepository(db *database.DB, logger *logger.Logger) SupportSearchRepository {
+	return &supportSearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *supportSearchRepository) FindUsers(ctx context.Context, filter SupportSearchFilter, limit int) ([]*models.User, error) {
+	r.logger.Debug("Finding users for support", "limit", limit)
+
+	var users []*models.User
+	if err := r.db.WithContext(ctx).
+		Scopes(supportUserMatches(filter, "users.id")).
+		Order("users.created_at DESC").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		r.logger.Error("Failed to find users for support", "error", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *supportSearchRepository) FindOrders(ctx context.Context, filter SupportSearchFilter, limit int) ([]*models.Order, error) {
+	r.logger.Debug("Finding orders for support", "limit", limit)
+
+	query := r.db.WithContext(ctx).Model(&models.Order{}).Scopes(supportUserMatches(filter, "orders.user_id"))
+	if filter.TrackingNumber != "" {
+		query = query.Where("orders.metadata->>'tracking_number' ILIKE ?", likePattern(filter.TrackingNumber))
+	}
+	if filter.Total != nil {
+		// Totals are matched to the cent
+		query = query.Where("orders.total_amount BETWEEN ? AND ?", *filter.Total-0.005, *filter.Total+0.005)
+	}
+	if filter.Date != nil {
+		query = query.Where("orders.created_at >= ? AND orders.created_at < ?", *filter.Date, filter.Date.AddDate(0, 0, 1))
+	}
+
+	var orders []*models.Order
+	if err := query.
+		Order("orders.created_at DESC").
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		r.logger.Error("Failed to find orders for support", "error", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *supportSearchRepository) ListPaymentsByOrders(ctx context.Context, orderIDs []string) ([]*models.Payment, error) {
+	var payments []*models.Payment
+	if len(orderIDs) == 0 {
+		return payments, nil
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("order_id IN ?", orderIDs).
+		Order("created_at DESC").
+		Find(&payments).Error; err != nil {
+		r.logger.Error("Failed to list payments of orders for support", "error", err, "orders", len(orderIDs))
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// supportUserMatches scopes a query to rows whose user, identified by userColumn,
+// matches the user inputs of the filter
+func supportUserMatches(filter SupportSearchFilter, userColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Email != "" {
+			db = db.Where(userColumn+" IN (SELECT id FROM users WHERE email ILIKE ? AND deleted_at IS NULL)",
+				likePattern(filter.Email))
+		}
+		if filter.CardLast4 != "" {
+			db = db.Where(userColumn+" IN (SELECT user_id FROM saved_payment_methods WHERE last4 = ?)",
+				filter.CardLast4)
+		}
+		return db
+	}
+}
+
+// likePattern matches values containing part, escaping the LIKE wildcards in it
+func likePattern(part string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(part)
+	return "%" + escaped + "%"
+}
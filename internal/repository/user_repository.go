@@ -126,3 +126,55 @@ func (r *userRepository) Count(ctx context.Context) (int64, error) {
 	r.logger.Debug("Total users counted", "count", count)
 	return count, nil
 }
+
+func (r *userRepository) ListByOrganization(ctx context.Context, organizationID string) ([]*models.User, error) {
+	r.logger.Debug("Listing organization members", "organization_id", organizationID)
+
+	var users []*models.User
+	if err := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name, email").
+		Find(&users).Error; err != nil {
+		r.logger.Error("Failed to list organization members", "error", err, "organization_id", organizationID)
+		return nil, err
+	}
+
+	r.logger.Debug("Organization members retrieved from database", "organization_id", organizationID, "count", len(users))
+	return users, nil
+}
+
+func (r *userRepository) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	r.logger.Debug("Listing users by role", "role", role)
+
+	var users []*models.User
+	if err := r.db.WithContext(ctx).
+		Where("role = ? AND is_active = ?", role, true).
+		Order("name, email").
+		Find(&users).Error; err != nil {
+		r.logger.Error("Failed to list users by role", "error", err, "role", role)
+		return nil, err
+	}
+
+	r.logger.Debug("Users by role retrieved from database", "role", role, "count", len(users))
+	return users, nil
+}
+
+func (r *userRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.User, error) {
+	r.logger.Debug("Listing users after ID", "after_id", afterID, "limit", limit)
+
+	var users []*models.User
+	query := r.db.WithContext(ctx).Unscoped()
+	if afterID != "" {
+		query = query.Where("id > ?", afterID)
+	}
+	if err := query.
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		r.logger.Error("Failed to list users after ID", "error", err, "after_id", afterID)
+		return nil, err
+	}
+
+	r.logger.Debug("Users retrieved from database", "count", len(users))
+	return users, nil
+}
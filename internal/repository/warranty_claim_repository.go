@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// warrantyClaimRepository implements WarrantyClaimRepository interface
+type warrantyClaimRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewWarrantyClaimRepository creates a new warranty claim repository
+func NewWarrantyClaimRepository(db *database.DB, logger *logger.Logger) WarrantyClaimRepository {
+	return &warrantyClaimRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *warrantyClaimRepository) Create(ctx context.Context, claim *models.WarrantyClaim) (bool, error) {
+	r.logger.Debug("Creating warranty claim", "order_item_id", claim.OrderItemID, "serial_number", claim.SerialNumber)
+
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claims on a unit are made one at a time under its order item's lock
+		var item models.OrderItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&item, "id = ?", claim.OrderItemID).Error; err != nil {
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.WarrantyClaim{}).
+			Where("order_item_id = ? AND serial_number = ? AND status = ?",
+				claim.OrderItemID, claim.SerialNumber, models.WarrantyClaimStatusOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return nil
+		}
+
+		if err := tx.Create(claim).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to create warranty claim", "error", err, "order_item_id", claim.OrderItemID)
+		return false, err
+	}
+
+	return created, nil
+}
+
+func (r *warrantyClaimRepository) GetByID(ctx context.Context, id string) (*models.WarrantyClaim, error) {
+	r.logger.Debug("Getting warranty claim by ID", "id", id)
+
+	var claim models.WarrantyClaim
+	if err := r.db.WithContext(ctx).First(&claim, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		r.logger.Error("Failed to get warranty claim by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &claim, nil
+}
+
+func (r *warrantyClaimRepository) List(ctx context.Context, filter WarrantyClaimFilter, offset, limit int) ([]*models.WarrantyClaim, error) {
+	r.logger.Debug("Listing warranty claims", "user_id", filter.UserID, "product_id", filter.ProductID,
+		"status", filter.Status, "offset", offset, "limit", limit)
+
+	var claims []*models.WarrantyClaim
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&claims).Error; err != nil {
+		r.logger.Error("Failed to list warranty claims", "error", err)
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (r *warrantyClaimRepository) Count(ctx context.Context, filter WarrantyClaimFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count warranty claims", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered scopes a warranty claim query to the filter
+func (r *warrantyClaimRepository) filtered(ctx context.Context, filter WarrantyClaimFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.WarrantyClaim{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ProductID != "" {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+func (r *warrantyClaimRepository) Resolve(ctx context.Context, claim *models.WarrantyClaim, replacement *models.Order) (bool, error) {
+	r.logger.Debug("Resolving warranty claim", "id", claim.ID, "status", claim.Status, "resolution", claim.Resolution)
+
+	resolved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.WarrantyClaim
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", claim.ID, models.WarrantyClaimStatusOpen).
+			First(&current).Error; err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				// Already resolved or rejected
+				return nil
+			}
+			return err
+		}
+
+		if replacement != nil {
+			for _, item := range replacement.Items {
+				if _, err := ReserveInventory(tx, item.ProductID, item.Quantity); err != nil {
+					return err
+				}
+			}
+			if err := tx.Create(replacement).Error; err != nil {
+				return err
+			}
+			if err := AppendOrderEvent(tx, replacement, models.OrderEventCreated, ""); err != nil {
+				return err
+			}
+			claim.ReplacementOrderID = &replacement.ID
+		}
+
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"status":               claim.Status,
+			"resolution":           claim.Resolution,
+			"resolution_note":      claim.ResolutionNote,
+			"replacement_order_id": claim.ReplacementOrderID,
+			"refunded_amount":      claim.RefundedAmount,
+			"resolved_by":          claim.ResolvedBy,
+			"resolved_at":          claim.ResolvedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		resolved = true
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to resolve warranty claim", "error", err, "id", claim.ID)
+		return false, err
+	}
+
+	return resolved, nil
+}
+
+func (r *warrantyClaimRepository) Reopen(ctx context.Context, id string) error {
+	r.logger.Debug("Reopening warranty claim", "id", id)
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.WarrantyClaim{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          models.WarrantyClaimStatusOpen,
+			"resolution":      "",
+			"resolution_note": "",
+			"refunded_amount": 0,
+			"resolved_by":     nil,
+			"resolved_at":     nil,
+		}).Error; err != nil {
+		r.logger.Error("Failed to reopen warranty claim", "error", err, "id", id)
+		return err
+	}
+
+	return nil
+}
+
+// warrantyClaimSummarySQL counts per product the units shipped by the orders placed in
+// the range, other than warranty replacements, and the claims made in the range
+const warrantyClaimSummarySQL = `
+WITH shipped AS (
+	SELECT order_items.product_id, SUM(order_items.quantity) AS units
+	FROM order_items
+	JOIN orders ON orders.id = order_items.order_id
+	WHERE orders.status IN @shipped AND orders.channel <> @warranty
+		AND orders.created_at >= @start AND orders.created_at < @end AND orders.deleted_at IS NULL
+	GROUP BY order_items.product_id
+), claimed AS (
+	SELECT product_id,
+		COUNT(*) AS claims,
+		COUNT(*) FILTER (WHERE status = @open) AS open,
+		COUNT(*) FILTER (WHERE status = @rejected) AS rejected,
+		COUNT(*) FILTER (WHERE status = @resolved AND resolution = @repair) AS repairs,
+		COUNT(*) FILTER (WHERE status = @resolved AND resolution = @replace) AS replacements,
+		COUNT(*) FILTER (WHERE status = @resolved AND resolution = @refund) AS refunds
+	FROM warranty_claims
+	WHERE created_at >= @start AND created_at < @end
+	GROUP BY product_id
+)
+SELECT products.id AS product_id, products.name, products.sku,
+	COALESCE(shipped.units, 0) AS units_shipped,
+	COALESCE(claimed.claims, 0) AS claims,
+	COALESCE(claimed.open, 0) AS open,
+	COALESCE(claimed.rejected, 0) AS rejected,
+	COALESCE(claimed.repairs, 0) AS repairs,
+	COALESCE(claimed.replacements, 0) AS replacements,
+	COALESCE(claimed.refunds, 0) AS refunds
+FROM products
+LEFT JOIN shipped ON shipped.product_id = products.id
+LEFT JOIN claimed ON claimed.product_id = products.id
+WHERE products.serialized AND (shipped.units IS NOT NULL OR claimed.claims IS NOT NULL)
+ORDER BY products.name, products.id`
+
+func (r *warrantyClaimRepository) SummarizeByProduct(ctx context.Context, start, end time.Time) ([]WarrantyClaimSummary, error) {
+	r.logger.Debug("Summarizing warranty claims", "start", start, "end", end)
+
+	var summaries []WarrantyClaimSummary
+	if err := r.db.WithContext(ctx).Raw(warrantyClaimSummarySQL, map[string]interface{}{
+		"shipped":  []models.OrderStatus{models.OrderStatusShipped, models.OrderStatusDelivered},
+		"warranty": models.OrderChannelWarranty,
+		"start":    start,
+		"end":      end,
+		"open":     models.WarrantyClaimStatusOpen,
+		"rejected": models.WarrantyClaimStatusRejected,
+		"resolved": models.WarrantyClaimStatusResolved,
+		"repair":   models.WarrantyClaimResolutionRepair,
+		"replace":  models.WarrantyClaimResolutionReplace,
+		"refund":   models.WarrantyClaimResolutionRefund,
+	}).Scan(&summaries).Error; err != nil {
+		r.logger.Error("Failed to summarize warranty claims", "error", err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
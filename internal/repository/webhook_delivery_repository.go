@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookDeliveryRepository implements WebhookDeliveryRepository interface
+type webhookDeliveryRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *database.DB, logger *logger.Logger) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *webhookDeliveryRepository) Enqueue(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	r.logger.Debug("Enqueueing webhook deliveries", "count", len(deliveries))
+
+	if err := enqueueWebhookDeliveries(r.db.WithContext(ctx), deliveries); err != nil {
+		r.logger.Error("Failed to enqueue webhook deliveries", "error", err, "count", len(deliveries))
+		return err
+	}
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) EnqueueRelayed(ctx context.Context, relay string, sequence int64, deliveries []*models.WebhookDelivery) error {
+	r.logger.Debug("Enqueueing relayed webhook deliveries", "relay", relay, "sequence", sequence, "count", len(deliveries))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(deliveries) > 0 {
+			if err := enqueueWebhookDeliveries(tx, deliveries); err != nil {
+				return err
+			}
+		}
+
+		cursor := models.WebhookRelayCursor{Name: relay, Sequence: sequence}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"sequence", "updated_at"}),
+		}).Create(&cursor).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to enqueue relayed webhook deliveries", "error", err, "relay", relay, "sequence", sequence)
+		return err
+	}
+
+	return nil
+}
+
+// enqueueWebhookDeliveries inserts deliveries within tx, leaving out events already
+// queued for the endpoint
+func enqueueWebhookDeliveries(tx *gorm.DB, deliveries []*models.WebhookDelivery) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(&deliveries).Error
+}
+
+func (r *webhookDeliveryRepository) GetRelayCursor(ctx context.Context, relay string) (int64, error) {
+	var cursor models.WebhookRelayCursor
+	if err := r.db.WithContext(ctx).First(&cursor, "name = ?", relay).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		r.logger.Error("Failed to get webhook relay cursor", "error", err, "relay", relay)
+		return 0, err
+	}
+
+	return cursor.Sequence, nil
+}
+
+func (r *webhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deliveries claimed by another instance are skipped rather than waited on
+		if err := tx.Clauses(clause.Locking{
+			Strength: "UPDATE",
+			Table:    clause.Table{Name: "webhook_deliveries"},
+			Options:  "SKIP LOCKED",
+		}).
+			Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id").
+			Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+			Where("webhook_endpoints.is_active = ? AND webhook_endpoints.deleted_at IS NULL", true).
+			Order("webhook_deliveries.next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(deliveries))
+		endpointIDs := make([]string, 0, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+			endpointIDs = append(endpointIDs, delivery.EndpointID)
+		}
+		leaseUntil := now.Add(lease)
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", leaseUntil).Error; err != nil {
+			return err
+		}
+
+		var endpoints []*models.WebhookEndpoint
+		if err := tx.Where("id IN ?", endpointIDs).Find(&endpoints).Error; err != nil {
+			return err
+		}
+		byID := make(map[string]*models.WebhookEndpoint, len(endpoints))
+		for _, endpoint := range endpoints {
+			byID[endpoint.ID] = endpoint
+		}
+		for _, delivery := range deliveries {
+			delivery.NextAttemptAt = &leaseUntil
+			delivery.Endpoint = byID[delivery.EndpointID]
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("Failed to claim due webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).
+		Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_attempt_at":  delivery.LastAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_response":    delivery.LastResponse,
+			"last_error":       delivery.LastError,
+			"delivered_at":     delivery.DeliveredAt,
+		}).Error; err != nil {
+		r.logger.Error("Failed to record webhook delivery attempt", "error", err, "id", delivery.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *webhookDeliveryRepository) GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	r.logger.Debug("Getting webhook delivery by ID", "id", id)
+
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).First(&delivery, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Webhook delivery not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get webhook delivery by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (r *webhookDeliveryRepository) List(ctx context.Context, filter WebhookDeliveryFilter, offset, limit int) ([]*models.WebhookDelivery, error) {
+	r.logger.Debug("Listing webhook deliveries", "endpoint_id", filter.EndpointID, "status", filter.Status, "offset", offset, "limit", limit)
+
+	var deliveries []*models.WebhookDelivery
+	if err := r.filtered(ctx, filter).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		r.logger.Error("Failed to list webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookDeliveryRepository) Count(ctx context.Context, filter WebhookDeliveryFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count webhook deliveries", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered scopes a webhook delivery query to the filter
+func (r *webhookDeliveryRepository) filtered(ctx context.Context, filter WebhookDeliveryFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.WebhookDelivery{})
+	if filter.EndpointID != "" {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Topic != "" {
+		query = query.Where("topic = ?", filter.Topic)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+func (r *webhookDeliveryRepository) Requeue(ctx context.Context, id string, at time.Time) (bool, error) {
+	r.logger.Debug("Requeueing webhook delivery", "id", id)
+
+	result := r.db.WithContext(ctx).
+		Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, models.WebhookDeliveryStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryStatusPending,
+			"attempts":        0,
+			"next_attempt_at": at,
+		})
+	if result.Error != nil {
+		r.logger.Error("Failed to requeue webhook delivery", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// webhookEndpointRepository implements WebhookEndpointRepository interface
+type webhookEndpointRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewWebhookEndpointRepository creates a new webhook endpoint repository
+func NewWebhookEndpointRepository(db *database.DB, logger *logger.Logger) WebhookEndpointRepository {
+	return &webhookEndpointRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *webhookEndpointRepository) Create(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	r.logger.Debug("Creating webhook endpoint", "name", endpoint.Name, "url", endpoint.URL)
+
+	if err := r.db.WithContext(ctx).Create(endpoint).Error; err != nil {
+		r.logger.Error("Failed to create webhook endpoint", "error", err, "name", endpoint.Name)
+		return err
+	}
+
+	r.logger.Info("Webhook endpoint created", "id", endpoint.ID, "name", endpoint.Name)
+	return nil
+}
+
+func (r *webhookEndpointRepository) GetByID(ctx context.Context, id string) (*models.WebhookEndpoint, error) {
+	r.logger.Debug("Getting webhook endpoint by ID", "id", id)
+
+	var endpoint models.WebhookEndpoint
+	if err := r.db.WithContext(ctx).First(&endpoint, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Webhook endpoint not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get webhook endpoint by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+func (r *webhookEndpointRepository) List(ctx context.Context) ([]*models.WebhookEndpoint, error) {
+	r.logger.Debug("Listing webhook endpoints")
+
+	var endpoints []*models.WebhookEndpoint
+	if err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Find(&endpoints).Error; err != nil {
+		r.logger.Error("Failed to list webhook endpoints", "error", err)
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookEndpointRepository) ListSubscribed(ctx context.Context, topic models.WebhookTopic) ([]*models.WebhookEndpoint, error) {
+	r.logger.Debug("Listing webhook endpoints subscribed to topic", "topic", topic)
+
+	topics, err := json.Marshal([]models.WebhookTopic{topic})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []*models.WebhookEndpoint
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ? AND topics @> ?::jsonb", true, string(topics)).
+		Find(&endpoints).Error; err != nil {
+		r.logger.Error("Failed to list subscribed webhook endpoints", "error", err, "topic", topic)
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func (r *webhookEndpointRepository) Update(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	r.logger.Debug("Updating webhook endpoint", "id", endpoint.ID)
+
+	if err := r.db.WithContext(ctx).Save(endpoint).Error; err != nil {
+		r.logger.Error("Failed to update webhook endpoint", "error", err, "id", endpoint.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r *webhookEndpointRepository) Delete(ctx context.Context, id string) error {
+	r.logger.Debug("Deleting webhook endpoint", "id", id)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.WebhookEndpoint{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		// Deliveries to a removed endpoint would never be sent
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("endpoint_id = ? AND status = ?", id, models.WebhookDeliveryStatusPending).
+			Updates(map[string]interface{}{
+				"status":          models.WebhookDeliveryStatusFailed,
+				"next_attempt_at": nil,
+				"last_error":      "endpoint deleted",
+				"updated_at":      time.Now(),
+			}).Error
+	})
+	if err != nil {
+		r.logger.Error("Failed to delete webhook endpoint", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Webhook endpoint deleted", "id", id)
+	return nil
+}
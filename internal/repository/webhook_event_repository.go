@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// webhookEventRepository implements WebhookEventRepository interface
+type webhookEventRepository struct {
+	db     *database.DB
+	logger *logger.Logger
+}
+
+// NewWebhookEventRepository creates a new webhook event repository
+func NewWebhookEventRepository(db *database.DB, logger *logger.Logger) WebhookEventRepository {
+	return &webhookEventRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *webhookEventRepository) CreateIfNotExists(ctx context.Context, event *models.WebhookEvent) (bool, error) {
+	r.logger.Debug("Recording webhook event", "source", event.Source, "provider", event.Provider, "event_id", event.EventID, "type", event.EventType)
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "source"}, {Name: "provider"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(event)
+
+	if result.Error != nil {
+		r.logger.Error("Failed to record webhook event", "error", result.Error, "provider", event.Provider, "event_id", event.EventID)
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		r.logger.Info("Duplicate webhook event ignored", "provider", event.Provider, "event_id", event.EventID)
+		return false, nil
+	}
+
+	r.logger.Info("Webhook event recorded", "id", event.ID, "provider", event.Provider, "event_id", event.EventID)
+	return true, nil
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id string) (*models.WebhookEvent, error) {
+	r.logger.Debug("Getting webhook event by ID", "id", id)
+
+	var event models.WebhookEvent
+	if err := r.db.WithContext(ctx).First(&event, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Webhook event not found", "id", id)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get webhook event by ID", "error", err, "id", id)
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *webhookEventRepository) GetByProviderEventID(ctx context.Context, source models.WebhookEventSource, provider, eventID string) (*models.WebhookEvent, error) {
+	r.logger.Debug("Getting webhook event by provider event ID", "source", source, "provider", provider, "event_id", eventID)
+
+	var event models.WebhookEvent
+	if err := r.db.WithContext(ctx).
+		First(&event, "source = ? AND provider = ? AND event_id = ?", source, provider, eventID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			r.logger.Debug("Webhook event not found", "provider", provider, "event_id", eventID)
+			return nil, nil
+		}
+		r.logger.Error("Failed to get webhook event", "error", err, "provider", provider, "event_id", eventID)
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (r *webhookEventRepository) Claim(ctx context.Context, id string, fromStatuses ...models.WebhookEventStatus) (bool, error) {
+	r.logger.Debug("Claiming webhook event", "id", id, "from_statuses", fromStatuses)
+
+	// Conditional update so only one delivery or replay can win the claim
+	result := r.db.WithContext(ctx).
+		Model(&models.WebhookEvent{}).
+		Where("id = ? AND status IN ?", id, fromStatuses).
+		Updates(map[string]interface{}{
+			"status":   models.WebhookEventStatusProcessing,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+
+	if result.Error != nil {
+		r.logger.Error("Failed to claim webhook event", "error", result.Error, "id", id)
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *webhookEventRepository) MarkProcessed(ctx context.Context, id string) error {
+	r.logger.Debug("Marking webhook event processed", "id", id)
+
+	now := time.Now()
+	if err := r.db.WithContext(ctx).
+		Model(&models.WebhookEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.WebhookEventStatusProcessed,
+			"processed_at": &now,
+			"last_error":   "",
+		}).Error; err != nil {
+		r.logger.Error("Failed to mark webhook event processed", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Info("Webhook event processed", "id", id)
+	return nil
+}
+
+func (r *webhookEventRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	r.logger.Debug("Marking webhook event failed", "id", id)
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.WebhookEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.WebhookEventStatusFailed,
+			"last_error": reason,
+		}).Error; err != nil {
+		r.logger.Error("Failed to mark webhook event failed", "error", err, "id", id)
+		return err
+	}
+
+	r.logger.Warn("Webhook event failed", "id", id, "reason", reason)
+	return nil
+}
+
+func (r *webhookEventRepository) ListByStatus(ctx context.Context, status models.WebhookEventStatus, offset, limit int) ([]*models.WebhookEvent, error) {
+	r.logger.Debug("Listing webhook events by status", "status", status, "offset", offset, "limit", limit)
+
+	var events []*models.WebhookEvent
+	query := r.db.WithContext(ctx).Model(&models.WebhookEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.
+		Offset(offset).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&events).Error; err != nil {
+		r.logger.Error("Failed to list webhook events", "error", err, "status", status)
+		return nil, err
+	}
+
+	r.logger.Debug("Webhook events retrieved from database", "status", status, "count", len(events))
+	return events, nil
+}
+
+func (r *webhookEventRepository) CountByStatus(ctx context.Context, status models.WebhookEventStatus) (int64, error) {
+	r.logger.Debug("Counting webhook events by status", "status", status)
+
+	var count int64
+	query := r.db.WithContext(ctx).Model(&models.WebhookEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count webhook events", "error", err, "status", status)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteCreatedBefore deletes up to limit webhook events received before the given
+// time, except events still being processed, returning how many were deleted
+func (r *webhookEventRepository) DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.logger.Debug("Deleting webhook events", "before", before, "limit", limit)
+
+	batch := r.db.WithContext(ctx).Model(&models.WebhookEvent{}).Select("id").
+		Where("created_at < ? AND status <> ?", before, models.WebhookEventStatusProcessing).
+		Limit(limit)
+	result := r.db.WithContext(ctx).Where("id IN (?)", batch).Delete(&models.WebhookEvent{})
+	if result.Error != nil {
+		r.logger.Error("Failed to delete webhook events", "error", result.Error, "before", before)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *webhookEventRepository) CountCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.WebhookEvent{}).
+		Where("created_at < ? AND status <> ?", before, models.WebhookEventStatusProcessing).
+		Count(&count).Error; err != nil {
+		r.logger.Error("Failed to count webhook events", "error", err, "before", before)
+		return 0, err
+	}
+	return count, nil
+}
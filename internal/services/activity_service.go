@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+)
+
+// activityService implements ActivityRecorder interface
+type activityService struct {
+	activityRepo repository.ActivityEventRepository
+	logger       *logger.Logger
+}
+
+// NewActivityService creates a new activity recorder backed by activity_events
+func NewActivityService(activityRepo repository.ActivityEventRepository, logger *logger.Logger) ActivityRecorder {
+	return &activityService{
+		activityRepo: activityRepo,
+		logger:       logger,
+	}
+}
+
+func (s *activityService) RecordActivity(ctx context.Context, userID string, eventType models.ActivityEventType, entityID string) {
+	if userID == "" {
+		return
+	}
+
+	event := &models.ActivityEvent{
+		UserID:   userID,
+		Type:     eventType,
+		EntityID: entityID,
+	}
+
+	// Tracking is best-effort and must never fail the action being tracked
+	if err := s.activityRepo.Create(ctx, event); err != nil {
+		s.logger.Warn("Failed to record activity event", "error", err, "user_id", userID, "type", eventType)
+	}
+}
+
+// recordActivity records an event when a recorder is configured
+func recordActivity(ctx context.Context, recorder ActivityRecorder, userID string, eventType models.ActivityEventType, entityID string) {
+	if recorder == nil {
+		return
+	}
+	recorder.RecordActivity(ctx, userID, eventType, entityID)
+}
+
+// ActivityRecorders fans activity out to every recorder
+type ActivityRecorders []ActivityRecorder
+
+// RecordActivity records the activity with each recorder in order
+func (r ActivityRecorders) RecordActivity(ctx context.Context, userID string, eventType models.ActivityEventType, entityID string) {
+	for _, recorder := range r {
+		recorder.RecordActivity(ctx, userID, eventType, entityID)
+	}
+}
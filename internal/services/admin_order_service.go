@@ -0,0 +1,532 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// orderStatusHistoryLimit caps how many audit entries the full view returns
+const orderStatusHistoryLimit = 200
+
+// PriceOverrideSettings limits how far privileged users may discount an order item
+// below its list price, as a percentage of the list price
+type PriceOverrideSettings struct {
+	MaxDiscountPercent float64
+}
+
+// adminOrderService implements AdminOrderService interface
+type adminOrderService struct {
+	priceOverrides   PriceOverrideSettings
+	orderRepo        repository.OrderRepository
+	orderItemRepo    repository.OrderItemRepository
+	paymentRepo      repository.PaymentRepository
+	notificationRepo repository.NotificationRepository
+	auditRepo        repository.AuditLogRepository
+	userRepo         repository.UserRepository
+	risk             *RiskScorer
+	logger           *logger.Logger
+}
+
+// NewAdminOrderService creates a new admin order service
+func NewAdminOrderService(
+	priceOverrides PriceOverrideSettings,
+	orderRepo repository.OrderRepository,
+	orderItemRepo repository.OrderItemRepository,
+	paymentRepo repository.PaymentRepository,
+	notificationRepo repository.NotificationRepository,
+	auditRepo repository.AuditLogRepository,
+	userRepo repository.UserRepository,
+	risk *RiskScorer,
+	logger *logger.Logger,
+) AdminOrderService {
+	return &adminOrderService{
+		priceOverrides:   priceOverrides,
+		orderRepo:        orderRepo,
+		orderItemRepo:    orderItemRepo,
+		paymentRepo:      paymentRepo,
+		notificationRepo: notificationRepo,
+		auditRepo:        auditRepo,
+		userRepo:         userRepo,
+		risk:             risk,
+		logger:           logger,
+	}
+}
+
+func (s *adminOrderService) GetOrderFullView(ctx context.Context, id string) (*OrderFullViewResponse, error) {
+	s.logger.Debug("Getting order full view", "id", id)
+
+	if id == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get order for full view", "error", err, "id", id)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", id)
+	}
+
+	// The remaining lookups only depend on the order, so they run in parallel
+	var (
+		wg            sync.WaitGroup
+		user          *models.User
+		items         []*models.OrderItem
+		payments      []*models.Payment
+		notifications []*models.Notification
+		auditLogs     []*models.AuditLog
+		errs          = make([]error, 5)
+	)
+	lookups := []func() error{
+		func() (err error) { user, err = s.userRepo.GetByID(ctx, order.UserID); return },
+		func() (err error) { items, err = s.orderItemRepo.GetByOrderID(ctx, id); return },
+		func() (err error) { payments, err = s.paymentRepo.GetByOrderID(ctx, id); return },
+		func() (err error) { notifications, err = s.notificationRepo.GetByOrderID(ctx, id); return },
+		func() (err error) {
+			auditLogs, err = s.auditRepo.GetByEntityID(ctx, "order", id, 0, orderStatusHistoryLimit)
+			return
+		},
+	}
+	for i, lookup := range lookups {
+		wg.Add(1)
+		go func(i int, lookup func() error) {
+			defer wg.Done()
+			errs[i] = lookup()
+		}(i, lookup)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			s.logger.Error("Failed to assemble order full view", "error", err, "id", id)
+			return nil, err
+		}
+	}
+
+	response := &OrderFullViewResponse{
+		Order: OrderDetail{
+			ID:                order.ID,
+			UserID:            order.UserID,
+			Status:            order.Status,
+			PaymentAdjustment: newOrderPaymentAdjustment(order),
+			Total:             order.TotalAmount,
+			Currency:          order.Currency,
+			Notes:             order.Notes,
+			Metadata:          order.Metadata,
+			Channel:           order.Channel,
+			PlacedBy:          order.PlacedBy,
+			ExternalOrderID:   order.ExternalOrderID,
+			OrganizationID:    order.OrganizationID,
+			OnAccount:         order.OnAccount,
+			InvoiceDueAt:      order.InvoiceDueAt,
+			CreditHold:        order.CreditHold,
+			CreditReviewedBy:  order.CreditReviewedBy,
+			CreditReviewedAt:  order.CreditReviewedAt,
+			OnHold:            order.OnHold,
+			PromisedShipBy:    order.PromisedShipBy,
+			ShippingAddress:   order.ShippingAddress,
+			Gift:              newOrderGiftOptions(order),
+			Risk:              newOrderRisk(order),
+			CreatedAt:         order.CreatedAt,
+			UpdatedAt:         order.UpdatedAt,
+		},
+		Items:         make([]OrderItemDetail, len(items)),
+		Payments:      make([]OrderPaymentDetail, len(payments)),
+		Refunds:       []OrderRefundDetail{},
+		Notifications: make([]*NotificationResponse, len(notifications)),
+		StatusHistory: make([]OrderStatusHistoryEntry, len(auditLogs)),
+	}
+
+	if user != nil {
+		response.Customer = &UserResponse{
+			ID:       user.ID,
+			Email:    user.Email,
+			Name:     user.Name,
+			Role:     string(user.Role),
+			IsActive: user.IsActive,
+		}
+	}
+
+	for i, item := range items {
+		response.Items[i] = newOrderItemDetail(item)
+	}
+
+	for i, payment := range payments {
+		response.Payments[i] = newOrderPaymentDetail(payment)
+		if refunded := payment.RefundedTotal(); refunded > 0 {
+			response.Refunds = append(response.Refunds, OrderRefundDetail{
+				PaymentID:  payment.ID,
+				Amount:     refunded,
+				RefundedAt: payment.UpdatedAt,
+			})
+			response.RefundedAmount += refunded
+		}
+	}
+
+	for i, notification := range notifications {
+		response.Notifications[i] = newNotificationResponse(notification)
+	}
+
+	// Audit logs come back newest first; history reads oldest first
+	sort.SliceStable(auditLogs, func(i, j int) bool {
+		return auditLogs[i].CreatedAt.Before(auditLogs[j].CreatedAt)
+	})
+	for i, auditLog := range auditLogs {
+		response.StatusHistory[i] = OrderStatusHistoryEntry{
+			Action:    auditLog.Action,
+			UserID:    auditLog.UserID,
+			OldValues: rawJSON(auditLog.OldValues),
+			NewValues: rawJSON(auditLog.NewValues),
+			CreatedAt: auditLog.CreatedAt,
+		}
+	}
+
+	s.logger.Debug("Order full view assembled", "id", id, "items", len(items), "payments", len(payments),
+		"notifications", len(notifications), "history", len(auditLogs))
+
+	return response, nil
+}
+
+// RescoreOrder scores the order's risk again from its current data
+func (s *adminOrderService) RescoreOrder(ctx context.Context, id string) (*OrderRisk, error) {
+	s.logger.Info("Rescoring order risk", "id", id)
+
+	if s.risk == nil {
+		return nil, errors.NewBusinessError("order risk scoring is not enabled")
+	}
+
+	order, err := s.orderRepo.GetByIDWithItems(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get order for risk scoring", "error", err, "id", id)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", id)
+	}
+
+	if err := s.risk.Score(ctx, order); err != nil {
+		s.logger.Error("Failed to rescore order risk", "error", err, "id", id)
+		return nil, err
+	}
+
+	return newOrderRisk(order), nil
+}
+
+// OverrideItemPrice charges an item of a pending order a unit price below its list
+// price, such as a negotiated discount. The discount may not exceed the configured
+// maximum, and the list price is kept for margin reporting.
+func (s *adminOrderService) OverrideItemPrice(ctx context.Context, orderID, itemID, userID string, req OverrideItemPriceRequest) (*OrderItemPriceOverrideResponse, error) {
+	s.logger.Info("Overriding order item price", "order_id", orderID, "item_id", itemID, "user_id", userID,
+		"unit_price", req.UnitPrice, "reason_code", req.ReasonCode)
+
+	reason := models.PriceOverrideReason(req.ReasonCode)
+	if !reason.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid price override reason code %s", req.ReasonCode))
+	}
+	if req.UnitPrice <= 0 {
+		return nil, errors.NewValidationError("unit price must be greater than zero")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for price override", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if !order.IsPending() {
+		return nil, errors.NewConflictError(fmt.Sprintf("cannot override prices of a %s order, only pending orders", order.Status))
+	}
+
+	var item *models.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			item = &order.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, errors.NewNotFoundErrorWithID("order item", itemID)
+	}
+
+	listPrice := item.ListUnitPrice()
+	if req.UnitPrice > listPrice {
+		return nil, errors.NewValidationError(fmt.Sprintf("unit price %.2f is above the list price %.2f", req.UnitPrice, listPrice))
+	}
+	discount := discountPercent(listPrice, req.UnitPrice)
+	if discount > s.priceOverrides.MaxDiscountPercent {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("discount of %.2f%% exceeds the maximum of %.2f%%", discount, s.priceOverrides.MaxDiscountPercent))
+	}
+
+	repriced, err := s.orderRepo.OverrideItemPrice(ctx, orderID, itemID, repository.ItemPriceOverride{
+		UnitPrice:    req.UnitPrice,
+		Reason:       reason,
+		Note:         req.Note,
+		OverriddenBy: userID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to override order item price", "error", err, "order_id", orderID, "item_id", itemID)
+		return nil, errors.NewDatabaseError("failed to override order item price", err)
+	}
+	if repriced == nil {
+		// Paid or cancelled since it was read
+		return nil, errors.NewConflictError("cannot override prices of an order that is no longer pending")
+	}
+
+	response := &OrderItemPriceOverrideResponse{
+		OrderID:           repriced.ID,
+		Total:             repriced.TotalAmount,
+		PaymentAdjustment: repriced.PaymentAdjustment,
+	}
+	for i := range repriced.Items {
+		if repriced.Items[i].ID == itemID {
+			response.Item = newOrderItemDetail(&repriced.Items[i])
+		}
+	}
+
+	s.logger.Info("Order item price overridden", "order_id", orderID, "item_id", itemID,
+		"list_price", listPrice, "unit_price", req.UnitPrice, "discount_percent", discount, "total", repriced.TotalAmount)
+	return response, nil
+}
+
+// discountPercent returns how far below the list price a price is, as a percentage
+// of the list price rounded to hundredths
+func discountPercent(listPrice, price float64) float64 {
+	if listPrice <= 0 {
+		return 0
+	}
+	return math.Round((listPrice-price)/listPrice*10000) / 100
+}
+
+// newOrderItemDetail converts an order item and its current product
+func newOrderItemDetail(item *models.OrderItem) OrderItemDetail {
+	detail := OrderItemDetail{
+		ID:         item.ID,
+		ProductID:  item.ProductID,
+		Quantity:   item.Quantity,
+		UnitPrice:  item.UnitPrice,
+		TotalPrice: item.TotalPrice,
+		ListPrice:  item.ListUnitPrice(),
+	}
+	if len(item.SerialNumbers) > 0 {
+		detail.SerialNumbers = item.SerialNumbers
+	}
+	if item.IsPriceOverridden() {
+		detail.DiscountPercent = discountPercent(detail.ListPrice, item.UnitPrice)
+		detail.PriceOverride = &OrderItemPriceOverride{
+			ReasonCode:   string(item.PriceOverrideReason),
+			Note:         item.PriceOverrideNote,
+			OverriddenBy: item.PriceOverriddenBy,
+			OverriddenAt: *item.PriceOverriddenAt,
+		}
+	}
+
+	if item.Product != nil {
+		detail.Product = &ProductSnapshot{
+			Name:         item.Product.Name,
+			SKU:          item.Product.SKU,
+			CurrentPrice: item.Product.Price,
+			CostPrice:    item.Product.CostPrice,
+			IsActive:     item.Product.IsActive,
+		}
+		if item.Product.Inventory != nil {
+			available := item.Product.Inventory.Available
+			detail.Product.Available = &available
+		}
+	}
+
+	return detail
+}
+
+// newOrderPaymentDetail converts a payment with its attempt and retry state
+func newOrderPaymentDetail(payment *models.Payment) OrderPaymentDetail {
+	return OrderPaymentDetail{
+		ID:            payment.ID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Status:        payment.Status,
+		Method:        payment.Method,
+		Gateway:       payment.Gateway,
+		TransactionID: payment.TransactionID,
+		FailureReason: payment.FailureReason,
+		AttemptCount:  payment.AttemptCount,
+		MaxRetries:    payment.MaxRetries,
+		LastAttemptAt: payment.LastAttemptAt,
+		NextRetryAt:   payment.NextRetryAt,
+		ProcessedAt:   payment.ProcessedAt,
+		CreatedAt:     payment.CreatedAt,
+
+		ExternalReference: payment.ExternalReference,
+		RecordedBy:        payment.RecordedBy,
+	}
+}
+
+// rawJSON returns stored JSON as-is, or nil when empty or invalid
+func rawJSON(value string) json.RawMessage {
+	if value == "" || !json.Valid([]byte(value)) {
+		return nil
+	}
+	return json.RawMessage(value)
+}
+
+// GetPackingSlip builds the packing slip of an order. A gift order's slip carries the
+// gift message and, when the buyer asked to hide prices, no prices at all.
+func (s *adminOrderService) GetPackingSlip(ctx context.Context, id string) (*PackingSlipResponse, error) {
+	s.logger.Debug("Getting packing slip", "id", id)
+
+	if id == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get order for packing slip", "error", err, "id", id)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", id)
+	}
+
+	hidePrices := order.IsGift && order.GiftHidePrices
+	slip := &PackingSlipResponse{
+		OrderID:         order.ID,
+		PlacedAt:        order.CreatedAt,
+		ShippingAddress: order.ShippingAddress,
+		Items:           make([]PackingSlipItem, len(order.Items)),
+		Gift:            order.IsGift,
+		GiftMessage:     order.GiftMessage,
+		PricesHidden:    hidePrices,
+	}
+	if !hidePrices {
+		total := order.TotalAmount
+		slip.Total = &total
+		slip.Currency = order.Currency
+	}
+
+	for i, item := range order.Items {
+		line := PackingSlipItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if item.Product != nil {
+			line.SKU = item.Product.SKU
+			line.Name = item.Product.Name
+		}
+		if !hidePrices {
+			unitPrice, totalPrice := item.UnitPrice, item.TotalPrice
+			line.UnitPrice = &unitPrice
+			line.TotalPrice = &totalPrice
+		}
+		slip.Items[i] = line
+	}
+
+	return slip, nil
+}
+
+// GetPickList aggregates the items of a batch of orders into what to pick, a line per
+// product. Lines follow the bin locations of the products in pick-path order (see
+// models.CompareLocations) so the picker walks the warehouse once; products without a
+// location come last, by SKU. Every order must
+// be ready to pick.
+func (s *adminOrderService) GetPickList(ctx context.Context, req PickListRequest) (*PickListResponse, error) {
+	s.logger.Debug("Getting pick list", "orders", len(req.OrderIDs))
+
+	orderIDs := make([]string, 0, len(req.OrderIDs))
+	seen := make(map[string]bool, len(req.OrderIDs))
+	for _, id := range req.OrderIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			orderIDs = append(orderIDs, id)
+		}
+	}
+	if len(orderIDs) == 0 {
+		return nil, errors.NewValidationError("at least one order ID is required")
+	}
+
+	orders, err := s.orderRepo.ListByIDsWithItems(ctx, orderIDs)
+	if err != nil {
+		s.logger.Error("Failed to get orders for pick list", "error", err)
+		return nil, err
+	}
+	byID := make(map[string]*models.Order, len(orders))
+	for _, order := range orders {
+		byID[order.ID] = order
+	}
+
+	lines := make(map[string]*PickListLine)
+	response := &PickListResponse{
+		GeneratedAt: time.Now(),
+		OrderIDs:    orderIDs,
+		Lines:       []PickListLine{},
+	}
+	for _, id := range orderIDs {
+		order := byID[id]
+		if order == nil {
+			return nil, errors.NewNotFoundErrorWithID("order", id)
+		}
+		if !order.IsPickable() {
+			return nil, errors.NewConflictError(fmt.Sprintf("order %s is not ready to pick: it is %s%s", id, order.Status, heldSuffix(order)))
+		}
+
+		for _, item := range order.Items {
+			line := lines[item.ProductID]
+			if line == nil {
+				line = &PickListLine{ProductID: item.ProductID}
+				if item.Product != nil {
+					line.SKU = item.Product.SKU
+					line.Name = item.Product.Name
+					if item.Product.Inventory != nil {
+						line.Location = item.Product.Inventory.Location
+					}
+				}
+				lines[item.ProductID] = line
+			}
+			line.Quantity += item.Quantity
+			line.Orders = append(line.Orders, PickListOrder{OrderID: order.ID, Quantity: item.Quantity})
+			response.TotalUnits += item.Quantity
+		}
+	}
+
+	for _, line := range lines {
+		response.Lines = append(response.Lines, *line)
+	}
+	sort.Slice(response.Lines, func(i, j int) bool {
+		a, b := response.Lines[i], response.Lines[j]
+		if (a.Location == "") != (b.Location == "") {
+			return a.Location != ""
+		}
+		if c := models.CompareLocations(a.Location, b.Location); c != 0 {
+			return c < 0
+		}
+		if a.SKU != b.SKU {
+			return a.SKU < b.SKU
+		}
+		return a.ProductID < b.ProductID
+	})
+
+	s.logger.Info("Pick list generated", "orders", len(orderIDs), "lines", len(response.Lines), "units", response.TotalUnits)
+	return response, nil
+}
+
+// heldSuffix describes the hold an order is on, if any
+func heldSuffix(order *models.Order) string {
+	switch {
+	case order.OnHold:
+		return " and on hold"
+	case order.CreditHold:
+		return " and on credit hold"
+	}
+	return ""
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"sort"
+	"sync"
+	"time"
+
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// AllocationQueueSettings configures the stock allocation queue used during flash
+// sales. While enabled, orders are granted stock in the order they arrived: an order
+// waits up to Wait for its turn, and is otherwise answered with a queue ticket to
+// retry with. A ticket not retried or polled within TicketTTL loses its place.
+type AllocationQueueSettings struct {
+	Enabled   bool
+	Wait      time.Duration
+	TicketTTL time.Duration
+}
+
+// AllocationQueue grants orders stock in arrival order rather than leaving it to
+// whichever concurrent transaction wins the inventory row locks. Each product has a
+// FIFO queue of tickets; a ticket is granted once it heads the queue of every product
+// of its order, and keeps its grant until the order is placed or fails. The queue is
+// held in memory, so it orders the requests of a single instance and is emptied on
+// restart. A nil or disabled queue grants every order at once.
+type AllocationQueue struct {
+	settings AllocationQueueSettings
+	logger   *logger.Logger
+
+	mutex   sync.Mutex
+	tickets map[string]*allocationTicket
+	queues  map[string][]*allocationTicket
+	// changed is closed and replaced whenever a ticket leaves a queue, waking waiters
+	changed chan struct{}
+}
+
+// allocationTicket is an order's place in the queues of its products
+type allocationTicket struct {
+	id         string
+	userID     string
+	productIDs []string
+	granted    bool
+	waiters    int
+	lastSeen   time.Time
+}
+
+// NewAllocationQueue creates an allocation queue
+func NewAllocationQueue(settings AllocationQueueSettings, logger *logger.Logger) *AllocationQueue {
+	return &AllocationQueue{
+		settings: settings,
+		logger:   logger,
+		tickets:  make(map[string]*allocationTicket),
+		queues:   make(map[string][]*allocationTicket),
+		changed:  make(chan struct{}),
+	}
+}
+
+// Acquire waits for the order's turn to be allocated stock for items, returning a
+// release func to call once the order is placed or has failed. An order without a
+// ticket joins the queue; a retried order passes the ticket it was given. An order
+// whose turn does not come within the wait is refused with an allocation queued error
+// carrying its ticket and position.
+func (q *AllocationQueue) Acquire(ctx context.Context, ticketID, userID string, items []OrderItem) (func(), error) {
+	if q == nil || !q.settings.Enabled {
+		return func() {}, nil
+	}
+
+	q.mutex.Lock()
+	now := time.Now()
+	q.expire(now)
+
+	var ticket *allocationTicket
+	if ticketID == "" {
+		ticket = q.enqueue(userID, items, now)
+	} else {
+		ticket = q.tickets[ticketID]
+		if ticket == nil || ticket.userID != userID {
+			q.mutex.Unlock()
+			return nil, errors.NewNotFoundErrorWithID("queue ticket", ticketID)
+		}
+		if !sameProducts(ticket.productIDs, items) {
+			q.mutex.Unlock()
+			return nil, errors.NewValidationError("queue ticket was issued for an order of other products")
+		}
+		if ticket.granted {
+			// Another request of the same ticket holds the grant
+			q.mutex.Unlock()
+			return nil, errors.NewConflictError("an order with this queue ticket is already being placed")
+		}
+	}
+	ticket.waiters++
+	q.mutex.Unlock()
+
+	deadline := time.NewTimer(q.settings.Wait)
+	defer deadline.Stop()
+
+	for {
+		q.mutex.Lock()
+		now = time.Now()
+		q.expire(now)
+		if q.ready(ticket) {
+			ticket.granted = true
+			ticket.waiters--
+			q.mutex.Unlock()
+			return func() { q.release(ticket) }, nil
+		}
+		changed := q.changed
+		// A stale ticket ahead of this one expires without anything else changing
+		nextExpiry := q.nextExpiry(now)
+		q.mutex.Unlock()
+
+		var expiry *time.Timer
+		var expired <-chan time.Time
+		if nextExpiry > 0 {
+			expiry = time.NewTimer(nextExpiry)
+			expired = expiry.C
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+		case <-deadline.C:
+			return nil, q.queued(ticket)
+		case <-ctx.Done():
+			q.mutex.Lock()
+			ticket.waiters--
+			ticket.lastSeen = time.Now()
+			q.mutex.Unlock()
+			return nil, ctx.Err()
+		}
+		if expiry != nil {
+			expiry.Stop()
+		}
+	}
+}
+
+// Position returns the place in the queue of the user's ticket, keeping it from
+// expiring. A granted ticket has position zero.
+func (q *AllocationQueue) Position(ticketID, userID string) (*AllocationQueuePosition, error) {
+	if q == nil || !q.settings.Enabled {
+		return nil, errors.NewNotFoundErrorWithID("queue ticket", ticketID)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	q.expire(now)
+	ticket := q.tickets[ticketID]
+	if ticket == nil || ticket.userID != userID {
+		return nil, errors.NewNotFoundErrorWithID("queue ticket", ticketID)
+	}
+	ticket.lastSeen = now
+	return q.position(ticket), nil
+}
+
+// QueuePositionFromError returns the queue ticket and position of an order refused
+// with an allocation queued error
+func QueuePositionFromError(err error) (*AllocationQueuePosition, bool) {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) || appErr.Type != errors.ErrorTypeAllocationQueued {
+		return nil, false
+	}
+	position, ok := appErr.Context["queue"].(*AllocationQueuePosition)
+	return position, ok
+}
+
+// queued records that the ticket stopped waiting without its turn coming, and returns
+// the error telling the client where it stands
+func (q *AllocationQueue) queued(ticket *allocationTicket) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	ticket.waiters--
+	ticket.lastSeen = time.Now()
+	position := q.position(ticket)
+	q.logger.Info("Order queued for stock allocation", "ticket_id", ticket.id, "user_id", ticket.userID, "position", position.Position)
+	return errors.NewAllocationQueuedError(ticket.id, position.Position).WithContext("queue", position)
+}
+
+// enqueue issues a ticket for an order of items at the back of its products' queues
+func (q *AllocationQueue) enqueue(userID string, items []OrderItem, now time.Time) *allocationTicket {
+	ticket := &allocationTicket{
+		id:         uuid.New().String(),
+		userID:     userID,
+		productIDs: orderProductIDs(items),
+		lastSeen:   now,
+	}
+	q.tickets[ticket.id] = ticket
+	for _, productID := range ticket.productIDs {
+		q.queues[productID] = append(q.queues[productID], ticket)
+	}
+	return ticket
+}
+
+// ready reports whether the ticket heads the queue of every product of its order
+func (q *AllocationQueue) ready(ticket *allocationTicket) bool {
+	for _, productID := range ticket.productIDs {
+		if queue := q.queues[productID]; len(queue) == 0 || queue[0] != ticket {
+			return false
+		}
+	}
+	return true
+}
+
+// position is the ticket's place in the longest of its products' lines: one more than
+// the queued tickets ahead of it, so 1 is next in line. A granted ticket has position 0.
+func (q *AllocationQueue) position(ticket *allocationTicket) *AllocationQueuePosition {
+	position := 0
+	if !ticket.granted {
+		for _, productID := range ticket.productIDs {
+			ahead := 0
+			for _, queued := range q.queues[productID] {
+				if queued == ticket {
+					break
+				}
+				if !queued.granted {
+					ahead++
+				}
+			}
+			if ahead+1 > position {
+				position = ahead + 1
+			}
+		}
+	}
+	return &AllocationQueuePosition{
+		TicketID:  ticket.id,
+		Position:  position,
+		ExpiresAt: ticket.lastSeen.Add(q.settings.TicketTTL),
+	}
+}
+
+// release takes a granted ticket out of the queues, letting the next orders in
+func (q *AllocationQueue) release(ticket *allocationTicket) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.remove(ticket)
+}
+
+// expire drops the tickets nobody is waiting on that were not retried or polled
+// within the ticket TTL. Granted tickets are held until released.
+func (q *AllocationQueue) expire(now time.Time) {
+	for _, ticket := range q.tickets {
+		if q.stale(ticket, now) {
+			q.logger.Info("Stock allocation queue ticket expired", "ticket_id", ticket.id, "user_id", ticket.userID)
+			q.remove(ticket)
+		}
+	}
+}
+
+// nextExpiry returns how long until the next waiting-free ticket goes stale, or zero
+// when no ticket will
+func (q *AllocationQueue) nextExpiry(now time.Time) time.Duration {
+	var next time.Duration
+	for _, ticket := range q.tickets {
+		if ticket.granted || ticket.waiters > 0 {
+			continue
+		}
+		if wait := ticket.lastSeen.Add(q.settings.TicketTTL).Sub(now); next == 0 || wait < next {
+			next = wait
+		}
+	}
+	if next < 0 {
+		next = time.Millisecond
+	}
+	return next
+}
+
+// stale reports whether an ungranted ticket nobody is waiting on outlived its TTL
+func (q *AllocationQueue) stale(ticket *allocationTicket, now time.Time) bool {
+	return !ticket.granted && ticket.waiters == 0 && now.Sub(ticket.lastSeen) > q.settings.TicketTTL
+}
+
+// remove takes the ticket out of the queues and wakes the waiting orders
+func (q *AllocationQueue) remove(ticket *allocationTicket) {
+	delete(q.tickets, ticket.id)
+	for _, productID := range ticket.productIDs {
+		queue := q.queues[productID]
+		for i, queued := range queue {
+			if queued == ticket {
+				queue = append(queue[:i], queue[i+1:]...)
+				break
+			}
+		}
+		if len(queue) == 0 {
+			delete(q.queues, productID)
+		} else {
+			q.queues[productID] = queue
+		}
+	}
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// orderProductIDs returns the distinct products of items in a stable order
+func orderProductIDs(items []OrderItem) []string {
+	quantities := orderItemQuantities(items)
+	productIDs := make([]string, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	sort.Strings(productIDs)
+	return productIDs
+}
+
+// sameProducts reports whether items are of exactly the products of a ticket
+func sameProducts(productIDs []string, items []OrderItem) bool {
+	requested := orderProductIDs(items)
+	if len(requested) != len(productIDs) {
+		return false
+	}
+	for i := range requested {
+		if requested[i] != productIDs[i] {
+			return false
+		}
+	}
+	return true
+}
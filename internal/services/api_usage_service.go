@@ -0,0 +1,474 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// apiKeyPrefix starts every API key, so keys are recognizable in configuration and
+// logs, and other values sent as keys are refused without a lookup
+const apiKeyPrefix = "eo_"
+
+// apiKeyDisplayLength is how many leading characters of a key are kept to tell it apart
+const apiKeyDisplayLength = 11
+
+// APIUsageSettings sets the monthly quota new API keys get; 0 leaves them unlimited
+type APIUsageSettings struct {
+	DefaultMonthlyQuota int
+}
+
+// usageKey identifies the usage of one caller and endpoint on one UTC day
+type usageKey struct {
+	day      time.Time
+	apiKeyID string
+	userID   string
+	method   string
+	route    string
+}
+
+// quotaCounter counts the requests an API key made in a calendar month
+type quotaCounter struct {
+	month time.Time
+	used  int64
+}
+
+// apiUsageService implements APIUsageService interface. Requests are aggregated in
+// memory until FlushUsage writes them. Quotas are counted in memory as well, seeded
+// from the stored and buffered usage the first time a key is used in a month, so a
+// key used through several instances can exceed its quota by what the others served.
+type apiUsageService struct {
+	settings  APIUsageSettings
+	keyRepo   repository.APIKeyRepository
+	usageRepo repository.APIUsageRepository
+	now       func() time.Time
+	logger    *logger.Logger
+
+	mu     sync.Mutex
+	buffer map[usageKey]*models.APIUsage
+	quotas map[string]*quotaCounter
+}
+
+// NewAPIUsageService creates a new API usage service
+func NewAPIUsageService(
+	settings APIUsageSettings,
+	keyRepo repository.APIKeyRepository,
+	usageRepo repository.APIUsageRepository,
+	logger *logger.Logger,
+) APIUsageService {
+	return &apiUsageService{
+		settings:  settings,
+		keyRepo:   keyRepo,
+		usageRepo: usageRepo,
+		now:       time.Now,
+		logger:    logger,
+		buffer:    make(map[usageKey]*models.APIUsage),
+		quotas:    make(map[string]*quotaCounter),
+	}
+}
+
+// CreateAPIKey creates an API key for the user. The key itself is only returned here;
+// just its hash is stored.
+func (s *apiUsageService) CreateAPIKey(ctx context.Context, userID string, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	s.logger.Info("Creating API key", "user_id", userID, "name", req.Name)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.NewValidationError("API key name is required")
+	}
+
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.NewInternalError("failed to generate API key", err)
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(random)
+
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  rawKey[:apiKeyDisplayLength],
+		KeyHash: hashAPIKey(rawKey),
+	}
+	if s.settings.DefaultMonthlyQuota > 0 {
+		quota := s.settings.DefaultMonthlyQuota
+		key.MonthlyQuota = &quota
+	}
+
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, errors.NewDatabaseError("failed to create API key", err)
+	}
+
+	s.logger.Info("API key created", "id", key.ID, "user_id", userID, "prefix", key.Prefix)
+	return &CreateAPIKeyResponse{
+		APIKeyResponse: s.apiKeyResponse(key, 0),
+		Key:            rawKey,
+	}, nil
+}
+
+// ListAPIKeys lists the user's API keys, newest first, with their usage this month
+func (s *apiUsageService) ListAPIKeys(ctx context.Context, userID string) ([]APIKeyResponse, error) {
+	s.logger.Debug("Listing API keys", "user_id", userID)
+
+	keys, err := s.keyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list API keys", err)
+	}
+
+	responses := make([]APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		used, err := s.usedThisMonth(ctx, keys[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, s.apiKeyResponse(&keys[i], used))
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey revokes one of the user's API keys; requests made with it are refused
+// from then on
+func (s *apiUsageService) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	s.logger.Info("Revoking API key", "user_id", userID, "api_key_id", keyID)
+
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return errors.NewDatabaseError("failed to get API key", err)
+	}
+	// Other users' keys are reported missing, so their IDs can't be probed
+	if key == nil || key.UserID != userID {
+		return errors.NewNotFoundErrorWithID("API key", keyID)
+	}
+
+	revoked, err := s.keyRepo.Revoke(ctx, keyID, s.now())
+	if err != nil {
+		return errors.NewDatabaseError("failed to revoke API key", err)
+	}
+	if !revoked {
+		return errors.NewConflictError("API key is already revoked")
+	}
+
+	s.mu.Lock()
+	delete(s.quotas, keyID)
+	s.mu.Unlock()
+
+	s.logger.Info("API key revoked", "user_id", userID, "api_key_id", keyID)
+	return nil
+}
+
+// SetAPIKeyQuota sets the monthly quota of an API key, or makes it unlimited. The new
+// quota applies to the requests made with it from now on.
+func (s *apiUsageService) SetAPIKeyQuota(ctx context.Context, keyID string, req SetAPIKeyQuotaRequest) (*APIKeyResponse, error) {
+	s.logger.Info("Setting API key quota", "api_key_id", keyID, "monthly_quota", req.MonthlyQuota)
+
+	if req.MonthlyQuota != nil && *req.MonthlyQuota < 1 {
+		return nil, errors.NewValidationError("monthly quota must be at least 1")
+	}
+
+	updated, err := s.keyRepo.SetQuota(ctx, keyID, req.MonthlyQuota)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to set API key quota", err)
+	}
+	if !updated {
+		return nil, errors.NewNotFoundErrorWithID("API key", keyID)
+	}
+
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get API key", err)
+	}
+	if key == nil {
+		return nil, errors.NewNotFoundErrorWithID("API key", keyID)
+	}
+
+	used, err := s.usedThisMonth(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("API key quota set", "api_key_id", keyID, "monthly_quota", req.MonthlyQuota, "used_this_month", used)
+	response := s.apiKeyResponse(key, used)
+	return &response, nil
+}
+
+// GetAPIUsage reports the usage stored for an inclusive range of UTC days (default: the
+// last 7 days) by endpoint and by caller, busiest first
+func (s *apiUsageService) GetAPIUsage(ctx context.Context, req APIUsageRequest) (*APIUsageReportResponse, error) {
+	s.logger.Debug("Getting API usage", "start_date", req.StartDate, "end_date", req.EndDate,
+		"api_key_id", req.APIKeyID, "user_id", req.UserID)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	filter := repository.APIUsageFilter{
+		From:     startDate,
+		To:       end,
+		APIKeyID: req.APIKeyID,
+		UserID:   req.UserID,
+	}
+	endpoints, err := s.usageRepo.SummarizeByEndpoint(ctx, filter)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to summarize API usage by endpoint", err)
+	}
+	callers, err := s.usageRepo.SummarizeByCaller(ctx, filter)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to summarize API usage by caller", err)
+	}
+
+	var totals repository.APIUsageTotals
+	report := &APIUsageReportResponse{
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		ByEndpoint: make([]APIEndpointUsage, 0, len(endpoints)),
+		ByCaller:   make([]APICallerUsage, 0, len(callers)),
+	}
+	for _, endpoint := range endpoints {
+		totals.Requests += endpoint.Requests
+		totals.Errors += endpoint.Errors
+		totals.Rejected += endpoint.Rejected
+		totals.TotalLatencyMs += endpoint.TotalLatencyMs
+		totals.MaxLatencyMs = max(totals.MaxLatencyMs, endpoint.MaxLatencyMs)
+
+		report.ByEndpoint = append(report.ByEndpoint, APIEndpointUsage{
+			Method:        endpoint.Method,
+			Route:         endpoint.Route,
+			APIUsageStats: apiUsageStats(endpoint),
+		})
+	}
+	for _, caller := range callers {
+		report.ByCaller = append(report.ByCaller, APICallerUsage{
+			APIKeyID:      caller.APIKeyID,
+			UserID:        caller.UserID,
+			APIUsageStats: apiUsageStats(caller),
+		})
+	}
+	report.Totals = apiUsageStats(totals)
+
+	return report, nil
+}
+
+// AuthenticateAPIKey returns the key matching the raw key, with its user, or nil if
+// there is none, or the key is revoked or its user deactivated
+func (s *apiUsageService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil
+	}
+
+	key, err := s.keyRepo.GetByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get API key", err)
+	}
+	if key == nil || !key.IsActive() || key.User == nil || !key.User.IsActive {
+		return nil, nil
+	}
+	return key, nil
+}
+
+// ReserveQuota counts a request against the key's monthly quota, reporting false once
+// it is exhausted. Keys without a quota are always allowed.
+func (s *apiUsageService) ReserveQuota(ctx context.Context, key *models.APIKey, at time.Time) (bool, error) {
+	if key.MonthlyQuota == nil {
+		return true, nil
+	}
+
+	month := usageMonth(at)
+	s.mu.Lock()
+	counter := s.quotas[key.ID]
+	s.mu.Unlock()
+
+	if counter == nil || !counter.month.Equal(month) {
+		var err error
+		if counter, err = s.seedQuota(ctx, key.ID, month); err != nil {
+			return false, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if counter.used >= int64(*key.MonthlyQuota) {
+		return false, nil
+	}
+	counter.used++
+	return true, nil
+}
+
+// RecordRequest adds a request to the usage of its day, caller and endpoint
+func (s *apiUsageService) RecordRequest(usage models.APIUsage) {
+	key := usageKey{
+		day:      usage.Day.UTC(),
+		apiKeyID: usage.APIKeyID,
+		userID:   usage.UserID,
+		method:   usage.Method,
+		route:    usage.Route,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addUsage(key, usage)
+}
+
+// FlushUsage writes the buffered usage to the usage table. Usage that failed to be
+// written is kept for the next flush.
+func (s *apiUsageService) FlushUsage(ctx context.Context) error {
+	s.mu.Lock()
+	buffer := s.buffer
+	s.buffer = make(map[usageKey]*models.APIUsage)
+	s.mu.Unlock()
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	rows := make([]models.APIUsage, 0, len(buffer))
+	for _, usage := range buffer {
+		usage.UpdatedAt = now
+		rows = append(rows, *usage)
+	}
+	// A fixed order keeps concurrent flushes of several instances from deadlocking
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		if a.APIKeyID != b.APIKeyID {
+			return a.APIKeyID < b.APIKeyID
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Method < b.Method
+	})
+
+	if err := s.usageRepo.Increment(ctx, rows); err != nil {
+		s.mu.Lock()
+		for key, usage := range buffer {
+			s.addUsage(key, *usage)
+		}
+		s.mu.Unlock()
+		return errors.NewDatabaseError("failed to flush API usage", err)
+	}
+
+	s.logger.Debug("API usage flushed", "rows", len(rows))
+	return nil
+}
+
+// addUsage adds usage to the buffer; the caller holds the lock
+func (s *apiUsageService) addUsage(key usageKey, usage models.APIUsage) {
+	buffered, exists := s.buffer[key]
+	if !exists {
+		usage.Day = key.day
+		s.buffer[key] = &usage
+		return
+	}
+
+	buffered.Requests += usage.Requests
+	buffered.Errors += usage.Errors
+	buffered.Rejected += usage.Rejected
+	buffered.TotalLatencyMs += usage.TotalLatencyMs
+	buffered.MaxLatencyMs = max(buffered.MaxLatencyMs, usage.MaxLatencyMs)
+}
+
+// seedQuota starts counting the key's requests in the month from its stored and
+// buffered usage, unless a concurrent request did so meanwhile
+func (s *apiUsageService) seedQuota(ctx context.Context, keyID string, month time.Time) (*quotaCounter, error) {
+	stored, err := s.usageRepo.CountRequests(ctx, keyID, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count API key requests", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if counter := s.quotas[keyID]; counter != nil && counter.month.Equal(month) {
+		return counter, nil
+	}
+	counter := &quotaCounter{month: month, used: stored + s.bufferedRequests(keyID, month)}
+	s.quotas[keyID] = counter
+	return counter, nil
+}
+
+// usedThisMonth counts the requests made with the key this month, stored or buffered
+func (s *apiUsageService) usedThisMonth(ctx context.Context, keyID string) (int64, error) {
+	month := usageMonth(s.now())
+	stored, err := s.usageRepo.CountRequests(ctx, keyID, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to count API key requests", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return stored + s.bufferedRequests(keyID, month), nil
+}
+
+// bufferedRequests counts the key's buffered requests since the start of the month;
+// the caller holds the lock
+func (s *apiUsageService) bufferedRequests(keyID string, month time.Time) int64 {
+	var requests int64
+	for key, usage := range s.buffer {
+		if key.apiKeyID == keyID && !key.day.Before(month) {
+			requests += usage.Requests
+		}
+	}
+	return requests
+}
+
+// apiKeyResponse converts an API key with the requests made with it this month
+func (s *apiUsageService) apiKeyResponse(key *models.APIKey, used int64) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:            key.ID,
+		UserID:        key.UserID,
+		Name:          key.Name,
+		Prefix:        key.Prefix,
+		MonthlyQuota:  key.MonthlyQuota,
+		UsedThisMonth: used,
+		Active:        key.IsActive(),
+		RevokedAt:     key.RevokedAt,
+		CreatedAt:     key.CreatedAt,
+	}
+	if key.MonthlyQuota != nil {
+		remaining := max(int64(*key.MonthlyQuota)-used, 0)
+		response.Remaining = &remaining
+	}
+	return response
+}
+
+// apiUsageStats converts summed usage, computing the error rate and average latency
+func apiUsageStats(totals repository.APIUsageTotals) APIUsageStats {
+	stats := APIUsageStats{
+		Requests:     totals.Requests,
+		Errors:       totals.Errors,
+		Rejected:     totals.Rejected,
+		MaxLatencyMs: totals.MaxLatencyMs,
+	}
+	if totals.Requests > 0 {
+		stats.ErrorRate = roundCents(float64(totals.Errors) / float64(totals.Requests) * 100)
+		stats.AvgLatencyMs = roundCents(float64(totals.TotalLatencyMs) / float64(totals.Requests))
+	}
+	return stats
+}
+
+// usageMonth returns the start of the UTC calendar month quotas are counted in
+func usageMonth(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// hashAPIKey returns the hex SHA-256 hash API keys are stored and looked up by
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// AvailabilitySettings bounds how old a served availability entry may be.
+// Older entries are re-materialized from inventory before they are served.
+type AvailabilitySettings struct {
+	MaxStaleness time.Duration
+}
+
+// availabilityService implements AvailabilityService interface
+type availabilityService struct {
+	settings         AvailabilitySettings
+	availabilityRepo repository.ProductAvailabilityRepository
+	cache            *cache.QueryCache
+	logger           *logger.Logger
+}
+
+// NewAvailabilityService creates a new storefront availability service
+func NewAvailabilityService(
+	settings AvailabilitySettings,
+	availabilityRepo repository.ProductAvailabilityRepository,
+	queryCache *cache.QueryCache,
+	logger *logger.Logger,
+) AvailabilityService {
+	return &availabilityService{
+		settings:         settings,
+		availabilityRepo: availabilityRepo,
+		cache:            queryCache,
+		logger:           logger,
+	}
+}
+
+// GetAvailability serves the cached or stored read model entry while it is within
+// the staleness bound, and otherwise re-materializes it from inventory. If that
+// fails, a stale entry is served rather than failing the product page.
+func (s *availabilityService) GetAvailability(ctx context.Context, productID string) (*ProductAvailabilityResponse, error) {
+	if productID == "" {
+		return nil, errors.NewValidationError("product ID is required")
+	}
+
+	availability, err := s.lookup(ctx, productID)
+	if err != nil {
+		s.logger.Warn("Failed to read product availability, refreshing from inventory", "error", err, "product_id", productID)
+	}
+	if availability != nil && !availability.IsStale(time.Now(), s.settings.MaxStaleness) {
+		return newProductAvailabilityResponse(availability, false), nil
+	}
+
+	refreshed, refreshErr := s.refresh(ctx, []string{productID})
+	if refreshErr == nil && len(refreshed) > 0 {
+		return newProductAvailabilityResponse(refreshed[0], false), nil
+	}
+
+	if availability != nil {
+		s.logger.Warn("Serving stale product availability", "error", refreshErr, "product_id", productID,
+			"updated_at", availability.UpdatedAt)
+		return newProductAvailabilityResponse(availability, true), nil
+	}
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	return nil, errors.NewNotFoundErrorWithID("inventory", productID)
+}
+
+// NotifyStockChanges re-materializes the changed products. A failed refresh drops
+// their cached entries, so reads fall back to the staleness bound.
+func (s *availabilityService) NotifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(productIDs))
+	seen := make(map[string]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if !seen[productID] {
+			seen[productID] = true
+			ids = append(ids, productID)
+		}
+	}
+
+	if _, err := s.refresh(ctx, ids); err != nil {
+		s.logger.Error("Failed to refresh product availability", "error", err, "reason", reason, "count", len(ids))
+		s.cache.Invalidate(repository.AvailabilityCacheNamespace, ids...)
+	}
+}
+
+// lookup returns the read model entry from the cache, or from the database
+func (s *availabilityService) lookup(ctx context.Context, productID string) (*models.ProductAvailability, error) {
+	if cached, ok := s.cache.Get(repository.AvailabilityCacheNamespace, productID); ok {
+		availability := cached.(models.ProductAvailability)
+		return &availability, nil
+	}
+
+	availability, err := s.availabilityRepo.GetByProductID(ctx, productID)
+	if err != nil || availability == nil {
+		return nil, err
+	}
+
+	s.cache.Set(repository.AvailabilityCacheNamespace, productID, *availability)
+	return availability, nil
+}
+
+// refresh re-materializes the products from inventory and caches the new entries
+func (s *availabilityService) refresh(ctx context.Context, productIDs []string) ([]*models.ProductAvailability, error) {
+	rows, err := s.availabilityRepo.Refresh(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		s.cache.Set(repository.AvailabilityCacheNamespace, row.ProductID, *row)
+	}
+	return rows, nil
+}
+
+func newProductAvailabilityResponse(availability *models.ProductAvailability, stale bool) *ProductAvailabilityResponse {
+	return &ProductAvailabilityResponse{
+		ProductID: availability.ProductID,
+		Available: availability.Available,
+		InStock:   availability.Available > 0,
+		UpdatedAt: availability.UpdatedAt,
+		Stale:     stale,
+	}
+}
+
+// StockChangeNotifiers fans stock change notifications out to every notifier
+type StockChangeNotifiers []StockChangeNotifier
+
+// NotifyStockChanges notifies each notifier in order
+func (n StockChangeNotifiers) NotifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	for _, notifier := range n {
+		notifier.NotifyStockChanges(ctx, reason, productIDs)
+	}
+}
@@ -2,12 +2,14 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
 	"easy-orders-backend/pkg/workers"
 )
 
@@ -16,11 +18,14 @@ type BackgroundService struct {
 	poolManager *workers.PoolManager
 
 	// Service dependencies for job execution
-	reportService       ReportService
-	notificationService NotificationService
-	auditRepo           repository.AuditLogRepository
-	inventoryRepo       repository.InventoryRepository
-	orderRepo           repository.OrderRepository
+	reportService      ReportService
+	digests            NotificationDigester
+	templates          *notifications.TemplateManager
+	auditRepo          repository.AuditLogRepository
+	inventoryRepo      repository.InventoryRepository
+	orderRepo          repository.OrderRepository
+	inventoryService   InventoryService
+	stockImportService StockImportService
 
 	logger *logger.Logger
 }
@@ -29,30 +34,40 @@ type BackgroundService struct {
 func NewBackgroundService(
 	poolManager *workers.PoolManager,
 	reportService ReportService,
-	notificationService NotificationService,
+	digests NotificationDigester,
+	templates *notifications.TemplateManager,
 	auditRepo repository.AuditLogRepository,
 	inventoryRepo repository.InventoryRepository,
 	orderRepo repository.OrderRepository,
+	inventoryService InventoryService,
+	stockImportService StockImportService,
 	logger *logger.Logger,
 ) *BackgroundService {
 	return &BackgroundService{
-		poolManager:         poolManager,
-		reportService:       reportService,
-		notificationService: notificationService,
-		auditRepo:           auditRepo,
-		inventoryRepo:       inventoryRepo,
-		orderRepo:           orderRepo,
-		logger:              logger,
+		poolManager:        poolManager,
+		reportService:      reportService,
+		digests:            digests,
+		templates:          templates,
+		auditRepo:          auditRepo,
+		inventoryRepo:      inventoryRepo,
+		orderRepo:          orderRepo,
+		inventoryService:   inventoryService,
+		stockImportService: stockImportService,
+		logger:             logger,
 	}
 }
 
-// Start initializes the background service and worker pools
+// Start initializes the background service and worker pools. Jobs the pools stored
+// before a restart are resumed once the pools start.
 func (bs *BackgroundService) Start(ctx context.Context) error {
 	// Initialize default worker pools
 	if err := bs.poolManager.InitializeDefaultPools(); err != nil {
 		return fmt.Errorf("failed to initialize worker pools: %w", err)
 	}
 
+	// Rebuild stored jobs with the executors they run with
+	bs.registerJobTypes()
+
 	// Start all pools
 	if err := bs.poolManager.StartAllPools(); err != nil {
 		return fmt.Errorf("failed to start worker pools: %w", err)
@@ -69,57 +84,92 @@ func (bs *BackgroundService) Stop() error {
 
 // SubmitReportJob submits a report generation job
 func (bs *BackgroundService) SubmitReportJob(reportType string, params map[string]interface{}) error {
-	executor := &reportExecutor{
-		reportService: bs.reportService,
-		logger:        bs.logger,
-	}
-
-	job := workers.NewReportGenerationJob(reportType, params, executor)
+	job := workers.NewReportGenerationJob(reportType, params, bs.newReportExecutor())
 	return bs.poolManager.SubmitJob(job)
 }
 
 // SubmitNotificationJob submits a notification job
 func (bs *BackgroundService) SubmitNotificationJob(recipientType, recipientID, notificationType, template string, data map[string]interface{}) error {
-	executor := &notificationExecutor{
-		notificationService: bs.notificationService,
-		logger:              bs.logger,
-	}
-
-	job := workers.NewNotificationJob(recipientType, recipientID, notificationType, template, data, executor)
+	job := workers.NewNotificationJob(recipientType, recipientID, notificationType, template, data, bs.newNotificationExecutor())
 	return bs.poolManager.SubmitJob(job)
 }
 
 // SubmitAuditJob submits an audit processing job
 func (bs *BackgroundService) SubmitAuditJob(entityType, entityID, action, userID string, changes map[string]interface{}) error {
-	executor := &auditExecutor{
-		auditRepo: bs.auditRepo,
-		logger:    bs.logger,
-	}
-
-	job := workers.NewAuditProcessingJob(entityType, entityID, action, userID, changes, executor)
+	job := workers.NewAuditProcessingJob(entityType, entityID, action, userID, changes, bs.newAuditExecutor())
 	return bs.poolManager.SubmitJob(job)
 }
 
 // SubmitBulkProcessingJob submits a bulk processing job
 func (bs *BackgroundService) SubmitBulkProcessingJob(operationType string, entityIDs []string, batchSize int, params map[string]interface{}) error {
-	executor := &bulkExecutor{
+	job := workers.NewBulkProcessingJob(operationType, entityIDs, batchSize, params, bs.newBulkExecutor())
+	return bs.poolManager.SubmitJob(job)
+}
+
+// SubmitExternalIntegrationJob submits an external integration job
+func (bs *BackgroundService) SubmitExternalIntegrationJob(serviceName, operation string, payload map[string]interface{}, timeout time.Duration) error {
+	job := workers.NewExternalIntegrationJob(serviceName, operation, payload, timeout, bs.newIntegrationExecutor())
+	return bs.poolManager.SubmitJob(job)
+}
+
+// registerJobTypes makes every job type durable, rebuilding stored jobs with a fresh
+// executor and decoding the rest of the job from its payload
+func (bs *BackgroundService) registerJobTypes() {
+	bs.poolManager.RegisterJobType(workers.JobTypeReportGeneration, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewReportGenerationJob("", nil, bs.newReportExecutor()))
+	})
+	bs.poolManager.RegisterJobType(workers.JobTypeNotification, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewNotificationJob("", "", "", "", nil, bs.newNotificationExecutor()))
+	})
+	bs.poolManager.RegisterJobType(workers.JobTypeAuditProcessing, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewAuditProcessingJob("", "", "", "", nil, bs.newAuditExecutor()))
+	})
+	bs.poolManager.RegisterJobType(workers.JobTypeBulkProcessing, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewBulkProcessingJob("", nil, 0, nil, bs.newBulkExecutor()))
+	})
+	bs.poolManager.RegisterJobType(workers.JobTypeExternalIntegration, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewExternalIntegrationJob("", "", nil, 0, bs.newIntegrationExecutor()))
+	})
+	bs.poolManager.RegisterJobType(workers.JobTypeStockImport, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewStockImportJob("", 0, 0, bs.stockImportService))
+	})
+}
+
+func (bs *BackgroundService) newReportExecutor() *reportExecutor {
+	return &reportExecutor{
+		reportService:    bs.reportService,
+		inventoryService: bs.inventoryService,
+		logger:           bs.logger,
+	}
+}
+
+func (bs *BackgroundService) newNotificationExecutor() *notificationExecutor {
+	return &notificationExecutor{
+		digests:   bs.digests,
+		templates: bs.templates,
+		logger:    bs.logger,
+	}
+}
+
+func (bs *BackgroundService) newAuditExecutor() *auditExecutor {
+	return &auditExecutor{
+		auditRepo: bs.auditRepo,
+		logger:    bs.logger,
+	}
+}
+
+func (bs *BackgroundService) newBulkExecutor() *bulkExecutor {
+	return &bulkExecutor{
 		inventoryRepo: bs.inventoryRepo,
 		orderRepo:     bs.orderRepo,
 		logger:        bs.logger,
 	}
-
-	job := workers.NewBulkProcessingJob(operationType, entityIDs, batchSize, params, executor)
-	return bs.poolManager.SubmitJob(job)
 }
 
-// SubmitExternalIntegrationJob submits an external integration job
-func (bs *BackgroundService) SubmitExternalIntegrationJob(serviceName, operation string, payload map[string]interface{}, timeout time.Duration) error {
-	executor := &integrationExecutor{
+func (bs *BackgroundService) newIntegrationExecutor() *integrationExecutor {
+	return &integrationExecutor{
 		logger: bs.logger,
 	}
-
-	job := workers.NewExternalIntegrationJob(serviceName, operation, payload, timeout, executor)
-	return bs.poolManager.SubmitJob(job)
 }
 
 // GetMetrics returns metrics for all worker pools
@@ -136,8 +186,9 @@ func (bs *BackgroundService) GetPoolMetrics(poolName string) (*workers.PoolMetri
 
 // reportExecutor implements ReportExecutor interface
 type reportExecutor struct {
-	reportService ReportService
-	logger        *logger.Logger
+	reportService    ReportService
+	inventoryService InventoryService
+	logger           *logger.Logger
 }
 
 func (r *reportExecutor) GenerateReport(ctx context.Context, reportType string, params map[string]interface{}) (string, error) {
@@ -146,7 +197,7 @@ func (r *reportExecutor) GenerateReport(ctx context.Context, reportType string,
 	switch reportType {
 	case "daily_sales":
 		// Extract date parameter
-		date, ok := params["date"].(time.Time)
+		date, ok := timeParam(params, "date")
 		if !ok {
 			date = time.Now().AddDate(0, 0, -1) // Yesterday by default
 		}
@@ -158,15 +209,24 @@ func (r *reportExecutor) GenerateReport(ctx context.Context, reportType string,
 		return outputPath, nil
 
 	case "low_stock":
-		threshold, ok := params["threshold"].(int)
-		if !ok {
-			threshold = 10
+		// An explicit threshold applies to every product; otherwise each product
+		// is compared against its own threshold
+		var report *LowStockResponse
+		var err error
+		threshold, ok := intParam(params, "threshold")
+		if ok {
+			report, err = r.inventoryService.GetLowStockAlert(ctx, threshold)
+		} else {
+			report, err = r.inventoryService.GetProductLowStockAlert(ctx)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to generate low stock report: %w", err)
 		}
 
 		// Generate low stock report
 		// This would typically save the report and return the path
 		outputPath := fmt.Sprintf("/reports/low_stock_%s.json", time.Now().Format("2006-01-02"))
-		r.logger.Info("Low stock report generated", "path", outputPath, "threshold", threshold)
+		r.logger.Info("Low stock report generated", "path", outputPath, "threshold", threshold, "per_product", report.PerProduct, "count", report.Count)
 		return outputPath, nil
 
 	default:
@@ -174,25 +234,46 @@ func (r *reportExecutor) GenerateReport(ctx context.Context, reportType string,
 	}
 }
 
-// notificationExecutor implements NotificationExecutor interface
+// notificationExecutor implements NotificationExecutor interface. Notifications go
+// through the digest scheduler, so low-stock alerts, report completions and the like
+// reach their recipient batched into a digest rather than one by one.
 type notificationExecutor struct {
-	notificationService NotificationService
-	logger              *logger.Logger
+	digests   NotificationDigester
+	templates *notifications.TemplateManager
+	logger    *logger.Logger
 }
 
+// SendNotification renders the notification template with the job's data into the
+// title and body, sending it on the template's channel with the data attached
 func (n *notificationExecutor) SendNotification(ctx context.Context, recipient, notificationType, template string, data map[string]interface{}) error {
 	n.logger.Info("Sending notification in background",
 		"recipient", recipient,
 		"type", notificationType,
 		"template", template)
 
+	tmpl, ok := n.templates.GetTemplate(template)
+	if !ok {
+		return fmt.Errorf("notification template %s not found", template)
+	}
+	title, body, err := n.templates.ApplyTemplate(template, data)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
 	req := SendNotificationRequest{
-		UserID: recipient,
-		Type:   notificationType,
-		Data:   fmt.Sprintf("Notification: %s", template),
+		UserID:  recipient,
+		Type:    notificationType,
+		Channel: tmpl.Channel,
+		Title:   title,
+		Body:    body,
+		Data:    string(encoded),
 	}
 
-	return n.notificationService.SendNotification(ctx, req)
+	return n.digests.Submit(newDigestNotification(req))
 }
 
 // auditExecutor implements AuditExecutor interface
@@ -257,7 +338,7 @@ func (b *bulkExecutor) processBulkInventoryUpdate(ctx context.Context, productID
 
 		batch := productIDs[i:end]
 		for _, productID := range batch {
-			if quantity, ok := params["quantity"].(int); ok {
+			if quantity, ok := intParam(params, "quantity"); ok {
 				if err := b.inventoryRepo.UpdateStock(ctx, productID, quantity); err != nil {
 					b.logger.Error("Failed to update inventory in bulk", "product_id", productID, "error", err)
 					// Continue with other items
@@ -336,3 +417,28 @@ func (i *integrationExecutor) executeInventorySyncIntegration(ctx context.Contex
 		"synced_items": 150,
 	}, nil
 }
+
+// intParam returns an integer job parameter, which is a float64 once a stored job was
+// decoded from JSON
+func intParam(params map[string]interface{}, key string) (int, bool) {
+	switch value := params[key].(type) {
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+// timeParam returns a time job parameter, which is an RFC 3339 string once a stored job
+// was decoded from JSON
+func timeParam(params map[string]interface{}, key string) (time.Time, bool) {
+	switch value := params[key].(type) {
+	case time.Time:
+		return value, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, value)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// blocklistTopHitsLimit bounds the entries reported by blocked attempts
+const blocklistTopHitsLimit = 10
+
+// blocklistService implements BlocklistService interface
+type blocklistService struct {
+	blocklistRepo repository.BlocklistRepository
+	screener      *FraudScreener
+	now           func() time.Time
+	logger        *logger.Logger
+}
+
+// NewBlocklistService creates a new blocklist service
+func NewBlocklistService(blocklistRepo repository.BlocklistRepository, screener *FraudScreener, logger *logger.Logger) BlocklistService {
+	return &blocklistService{
+		blocklistRepo: blocklistRepo,
+		screener:      screener,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// AddEntry blocklists a value and records who added it in the audit log
+func (s *blocklistService) AddEntry(ctx context.Context, actor AuditActor, req CreateBlocklistEntryRequest) (*models.BlocklistEntry, error) {
+	s.logger.Info("Adding blocklist entry", "type", req.Type, "user_id", actor.UserID)
+
+	entryType := models.BlocklistType(req.Type)
+	if !entryType.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid blocklist type %s", req.Type))
+	}
+	value, err := normalizeBlocklistValue(entryType, req.Value)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, errors.NewValidationError("expiry must be in the future")
+	}
+
+	entry := &models.BlocklistEntry{
+		Type:      entryType,
+		Value:     value,
+		Reason:    req.Reason,
+		CreatedBy: &actor.UserID,
+		ExpiresAt: req.ExpiresAt,
+	}
+	auditLog, err := newBlocklistAuditLog(models.AuditActionCreate, "", actor, nil, entry)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to build blocklist audit log", err)
+	}
+
+	created, err := s.blocklistRepo.Create(ctx, entry, auditLog)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to create blocklist entry", err)
+	}
+	if !created {
+		return nil, errors.NewConflictError(fmt.Sprintf("%s %s is already blocklisted", entryType, value))
+	}
+
+	s.logger.Info("Blocklist entry added", "id", entry.ID, "type", entryType, "user_id", actor.UserID)
+	return entry, nil
+}
+
+func (s *blocklistService) ListEntries(ctx context.Context, req ListBlocklistEntriesRequest) (*ListBlocklistEntriesResponse, error) {
+	s.logger.Debug("Listing blocklist entries", "page", req.Page, "limit", req.Limit, "type", req.Type)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	filter := repository.BlocklistFilter{
+		Type:   models.BlocklistType(req.Type),
+		Search: req.Search,
+	}
+
+	entries, err := s.blocklistRepo.List(ctx, filter, offset, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list blocklist entries", err)
+	}
+
+	total, err := s.blocklistRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count blocklist entries", err)
+	}
+
+	if entries == nil {
+		entries = []*models.BlocklistEntry{}
+	}
+	return &ListBlocklistEntriesResponse{
+		Entries: entries,
+		Page:    page,
+		Limit:   limit,
+		Total:   int(total),
+	}, nil
+}
+
+// RemoveEntry removes a value from the blocklist and records who removed it in the
+// audit log
+func (s *blocklistService) RemoveEntry(ctx context.Context, actor AuditActor, id string) error {
+	s.logger.Info("Removing blocklist entry", "id", id, "user_id", actor.UserID)
+
+	entry, err := s.blocklistRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.NewDatabaseError("failed to get blocklist entry", err)
+	}
+	if entry == nil {
+		return errors.NewNotFoundErrorWithID("blocklist entry", id)
+	}
+
+	auditLog, err := newBlocklistAuditLog(models.AuditActionDelete, id, actor, entry, nil)
+	if err != nil {
+		return errors.NewInternalError("failed to build blocklist audit log", err)
+	}
+
+	deleted, err := s.blocklistRepo.Delete(ctx, id, auditLog)
+	if err != nil {
+		return errors.NewDatabaseError("failed to delete blocklist entry", err)
+	}
+	if !deleted {
+		return errors.NewNotFoundErrorWithID("blocklist entry", id)
+	}
+
+	s.logger.Info("Blocklist entry removed", "id", id, "type", entry.Type, "user_id", actor.UserID)
+	return nil
+}
+
+func (s *blocklistService) GetMetrics(ctx context.Context) (*BlocklistMetricsResponse, error) {
+	s.logger.Debug("Getting blocklist metrics")
+
+	topEntries, err := s.blocklistRepo.ListTopHits(ctx, blocklistTopHitsLimit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list top blocklist entries", err)
+	}
+	if topEntries == nil {
+		topEntries = []*models.BlocklistEntry{}
+	}
+
+	since, checkpoints := s.screener.Metrics()
+	return &BlocklistMetricsResponse{
+		Since:       since,
+		Checkpoints: checkpoints,
+		TopEntries:  topEntries,
+	}, nil
+}
+
+// newBlocklistAuditLog returns the audit log of an added or removed blocklist entry.
+// The ID of an added entry is set when it is created.
+func newBlocklistAuditLog(action models.AuditAction, entryID string, actor AuditActor, before, after *models.BlocklistEntry) (*models.AuditLog, error) {
+	auditLog := &models.AuditLog{
+		UserID:     &actor.UserID,
+		EntityType: "blocklist_entry",
+		EntityID:   entryID,
+		Action:     action,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+
+	if before != nil {
+		if err := auditLog.SetOldValues(blocklistAuditValues(before)); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if err := auditLog.SetNewValues(blocklistAuditValues(after)); err != nil {
+			return nil, err
+		}
+	}
+
+	return auditLog, nil
+}
+
+// blocklistAuditValues returns the audited values of a blocklist entry
+func blocklistAuditValues(entry *models.BlocklistEntry) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       entry.Type,
+		"value":      entry.Value,
+		"reason":     entry.Reason,
+		"expires_at": entry.ExpiresAt,
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// expiredHoldBatchSize bounds how many expired holds are released per query
+const expiredHoldBatchSize = 100
+
+// CartHoldSettings configures cart stock holds. Window is the store default hold
+// window; a product's CartHoldMinutes overrides it.
+type CartHoldSettings struct {
+	Enabled bool
+	Window  time.Duration
+}
+
+// cartHoldService implements CartHoldService interface
+type cartHoldService struct {
+	settings      CartHoldSettings
+	holdRepo      repository.StockHoldRepository
+	productRepo   repository.ProductRepository
+	stockNotifier StockChangeNotifier
+	logger        *logger.Logger
+}
+
+// NewCartHoldService creates a new cart hold service
+func NewCartHoldService(
+	settings CartHoldSettings,
+	holdRepo repository.StockHoldRepository,
+	productRepo repository.ProductRepository,
+	stockNotifier StockChangeNotifier,
+	logger *logger.Logger,
+) CartHoldService {
+	return &cartHoldService{
+		settings:      settings,
+		holdRepo:      holdRepo,
+		productRepo:   productRepo,
+		stockNotifier: stockNotifier,
+		logger:        logger,
+	}
+}
+
+func (s *cartHoldService) HoldItem(ctx context.Context, userID string, req HoldCartItemRequest) (*CartHoldResponse, error) {
+	s.logger.Info("Holding cart item", "user_id", userID, "product_id", req.ProductID, "quantity", req.Quantity)
+
+	if !s.settings.Enabled {
+		return nil, errors.NewBusinessError("cart holds are disabled")
+	}
+	if req.ProductID == "" {
+		return nil, errors.NewValidationError("product ID is required")
+	}
+	if req.Quantity <= 0 {
+		return nil, errors.NewValidationError("quantity must be greater than 0")
+	}
+
+	product, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
+		s.logger.Error("Failed to get product for cart hold", "error", err, "product_id", req.ProductID)
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.NewNotFoundErrorWithID("product", req.ProductID)
+	}
+	if !product.IsActive {
+		return nil, errors.NewBusinessError(fmt.Sprintf("product %s is not available", req.ProductID))
+	}
+
+	window := s.settings.Window
+	if product.CartHoldMinutes > 0 {
+		window = time.Duration(product.CartHoldMinutes) * time.Minute
+	}
+
+	hold := &models.StockHold{
+		UserID:    userID,
+		ProductID: req.ProductID,
+		Quantity:  req.Quantity,
+		ExpiresAt: time.Now().Add(window),
+	}
+	if err := s.holdRepo.Hold(ctx, hold); err != nil {
+		s.logger.Error("Failed to hold cart item", "error", err, "user_id", userID, "product_id", req.ProductID)
+		return nil, err
+	}
+
+	s.notifyStockChanges(ctx, StockChangeReasonReservation, []string{req.ProductID})
+
+	return newCartHoldResponse(hold), nil
+}
+
+func (s *cartHoldService) ReleaseItem(ctx context.Context, userID, productID string) error {
+	s.logger.Info("Releasing cart item hold", "user_id", userID, "product_id", productID)
+
+	if productID == "" {
+		return errors.NewValidationError("product ID is required")
+	}
+
+	hold, err := s.holdRepo.GetActive(ctx, userID, productID)
+	if err != nil {
+		s.logger.Error("Failed to get cart hold", "error", err, "user_id", userID, "product_id", productID)
+		return err
+	}
+	if hold == nil {
+		return errors.NewNotFoundErrorWithID("cart hold", productID)
+	}
+
+	released, err := s.holdRepo.Release(ctx, hold.ID, models.StockHoldStatusReleased)
+	if err != nil {
+		return err
+	}
+	if released {
+		s.notifyStockChanges(ctx, StockChangeReasonRelease, []string{productID})
+	}
+
+	return nil
+}
+
+func (s *cartHoldService) GetCartHolds(ctx context.Context, userID string) ([]*CartHoldResponse, error) {
+	s.logger.Debug("Getting cart holds", "user_id", userID)
+
+	holds, err := s.holdRepo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list cart holds", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	responses := make([]*CartHoldResponse, len(holds))
+	for i, hold := range holds {
+		responses[i] = newCartHoldResponse(hold)
+	}
+	return responses, nil
+}
+
+func (s *cartHoldService) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	now := time.Now()
+	released := 0
+	var productIDs []string
+
+	for {
+		holds, err := s.holdRepo.ListExpired(ctx, now, expiredHoldBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to list expired cart holds", "error", err)
+			return released, err
+		}
+
+		batchReleased := 0
+		for _, hold := range holds {
+			ok, err := s.holdRepo.Release(ctx, hold.ID, models.StockHoldStatusExpired)
+			if err != nil {
+				// Leave the hold for the next sweep rather than stopping this one
+				s.logger.Warn("Failed to release expired cart hold", "error", err, "id", hold.ID)
+				continue
+			}
+			if ok {
+				batchReleased++
+				productIDs = append(productIDs, hold.ProductID)
+			}
+		}
+		released += batchReleased
+
+		// A full batch that released nothing would be listed again, so stop until the next sweep
+		if len(holds) < expiredHoldBatchSize || batchReleased == 0 {
+			break
+		}
+	}
+
+	if released > 0 {
+		s.logger.Info("Expired cart holds released", "count", released)
+		s.notifyStockChanges(ctx, StockChangeReasonRelease, productIDs)
+	}
+	return released, nil
+}
+
+func (s *cartHoldService) GetConversionStats(ctx context.Context, req CartHoldStatsRequest) (*CartHoldStatsResponse, error) {
+	s.logger.Debug("Getting cart hold conversion stats", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.holdRepo.CountByStatus(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to count cart holds by status", "error", err)
+		return nil, err
+	}
+
+	stats := &CartHoldStatsResponse{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+	}
+	for _, count := range counts {
+		stats.Holds += int(count.Holds)
+		stats.HeldUnits += int(count.Units)
+
+		switch count.Status {
+		case models.StockHoldStatusActive:
+			stats.Active = int(count.Holds)
+		case models.StockHoldStatusConverted:
+			stats.Converted = int(count.Holds)
+			stats.ConvertedUnits = int(count.Units)
+		case models.StockHoldStatusReleased:
+			stats.Released = int(count.Holds)
+		case models.StockHoldStatusExpired:
+			stats.Expired = int(count.Holds)
+		}
+	}
+
+	if ended := stats.Holds - stats.Active; ended > 0 {
+		stats.ConversionRate = math.Round(float64(stats.Converted)/float64(ended)*10000) / 100
+	}
+
+	return stats, nil
+}
+
+// notifyStockChanges tells external channels about availability changed by holds
+func (s *cartHoldService) notifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	if s.stockNotifier != nil {
+		s.stockNotifier.NotifyStockChanges(ctx, reason, productIDs)
+	}
+}
+
+func newCartHoldResponse(hold *models.StockHold) *CartHoldResponse {
+	return &CartHoldResponse{
+		ID:        hold.ID,
+		ProductID: hold.ProductID,
+		Quantity:  hold.Quantity,
+		Status:    hold.Status,
+		ExpiresAt: hold.ExpiresAt,
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/webhooks"
+)
+
+const (
+	// CatalogChangedEvent is the event type of catalog purge webhook deliveries
+	CatalogChangedEvent = "catalog.changed"
+
+	// catalogPurgeConsumer names the cursor of the purge webhook in the catalog event stream
+	catalogPurgeConsumer = "catalog_purge"
+	// catalogPurgeBatchSize bounds how many catalog events are posted at once
+	catalogPurgeBatchSize = 200
+)
+
+// CatalogPurgeRelaySettings configures the purge webhook catalog changes are posted
+// to. Catalog events are read once older than SettleDelay, like the change feeds.
+type CatalogPurgeRelaySettings struct {
+	URL         string
+	Secret      string
+	SettleDelay time.Duration
+}
+
+// catalogPurgeRelay implements CatalogPurgeRelay interface
+type catalogPurgeRelay struct {
+	settings         CatalogPurgeRelaySettings
+	catalogEventRepo repository.CatalogEventRepository
+	client           *webhooks.Client
+	logger           *logger.Logger
+}
+
+// NewCatalogPurgeRelay creates a relay that posts catalog changes to the purge webhook
+func NewCatalogPurgeRelay(
+	settings CatalogPurgeRelaySettings,
+	catalogEventRepo repository.CatalogEventRepository,
+	client *webhooks.Client,
+	logger *logger.Logger,
+) CatalogPurgeRelay {
+	return &catalogPurgeRelay{
+		settings:         settings,
+		catalogEventRepo: catalogEventRepo,
+		client:           client,
+		logger:           logger,
+	}
+}
+
+// RelayCatalogEvents reads the catalog events from where the last run stopped and
+// posts each batch to the purge webhook, in the shape of a catalog change feed page.
+// The cursor is moved once a batch is accepted; a batch that failed and may succeed
+// later is posted again on the next run. A batch the webhook rejects outright is
+// skipped, so one bad batch cannot hold back every later purge. The first run starts
+// from the latest event.
+func (r *catalogPurgeRelay) RelayCatalogEvents(ctx context.Context) (int, error) {
+	sequence, started, err := r.catalogEventRepo.GetCursor(ctx, catalogPurgeConsumer)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to get catalog purge cursor", err)
+	}
+	if !started {
+		latest, err := r.catalogEventRepo.LatestSequence(ctx)
+		if err != nil {
+			return 0, errors.NewDatabaseError("failed to get latest catalog event", err)
+		}
+		if err := r.catalogEventRepo.SaveCursor(ctx, catalogPurgeConsumer, latest); err != nil {
+			return 0, errors.NewDatabaseError("failed to start catalog purges", err)
+		}
+		r.logger.Info("Catalog purges started", "sequence", latest)
+		return 0, nil
+	}
+
+	relayed := 0
+	for {
+		events, err := r.catalogEventRepo.ListSince(ctx, sequence, r.settings.SettleDelay, catalogPurgeBatchSize)
+		if err != nil {
+			r.logger.Error("Failed to list catalog events for purges", "error", err, "since", sequence)
+			return relayed, errors.NewDatabaseError("failed to list catalog events", err)
+		}
+		if len(events) == 0 {
+			return relayed, nil
+		}
+
+		first, last := events[0].Sequence, events[len(events)-1].Sequence
+		page := &CatalogChangesResponse{
+			Events:     make([]CatalogChangeEvent, len(events)),
+			NextCursor: formatChangeCursor(last),
+		}
+		for i, event := range events {
+			page.Events[i] = newCatalogChangeEvent(event)
+		}
+		data, err := json.Marshal(page)
+		if err != nil {
+			return relayed, errors.NewInternalError("failed to encode catalog changes", err)
+		}
+
+		// The ID names the batch, so a receiver can drop a batch posted again after a
+		// lost response
+		result := r.client.Send(ctx, r.settings.URL, r.settings.Secret, webhooks.Event{
+			ID:         fmt.Sprintf("catalog-%d-%d", first, last),
+			Type:       CatalogChangedEvent,
+			OccurredAt: events[len(events)-1].RecordedAt,
+			Data:       data,
+		})
+		switch {
+		case result.Delivered():
+			relayed += len(events)
+			r.logger.Debug("Catalog changes posted to purge webhook", "first", first, "last", last, "duration", result.Duration)
+		case result.Retryable():
+			r.logger.Warn("Failed to post catalog changes to purge webhook, will retry", "error", result.Err,
+				"first", first, "last", last)
+			return relayed, errors.NewInternalError("failed to post catalog changes", result.Err)
+		default:
+			r.logger.Error("Purge webhook rejected catalog changes, skipping them", "error", result.Err,
+				"first", first, "last", last, "response", result.Response)
+		}
+
+		if err := r.catalogEventRepo.SaveCursor(ctx, catalogPurgeConsumer, last); err != nil {
+			return relayed, errors.NewDatabaseError("failed to save catalog purge cursor", err)
+		}
+		sequence = last
+
+		if len(events) < catalogPurgeBatchSize {
+			return relayed, nil
+		}
+	}
+}
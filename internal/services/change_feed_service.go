@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// defaultChangeFeedLimit is the page size of change feeds when none is requested
+const defaultChangeFeedLimit = 100
+
+// ChangeFeedSettings configures change feeds. Only events recorded more than
+// SettleDelay ago are served, so that an event whose transaction commits after
+// later events cannot be skipped by a cursor that has already passed it.
+type ChangeFeedSettings struct {
+	SettleDelay time.Duration
+}
+
+// changeFeedService implements ChangeFeedService interface
+type changeFeedService struct {
+	settings           ChangeFeedSettings
+	inventoryEventRepo repository.InventoryEventRepository
+	orderEventRepo     repository.OrderEventRepository
+	catalogEventRepo   repository.CatalogEventRepository
+	logger             *logger.Logger
+}
+
+// NewChangeFeedService creates a new change feed service
+func NewChangeFeedService(
+	settings ChangeFeedSettings,
+	inventoryEventRepo repository.InventoryEventRepository,
+	orderEventRepo repository.OrderEventRepository,
+	catalogEventRepo repository.CatalogEventRepository,
+	logger *logger.Logger,
+) ChangeFeedService {
+	return &changeFeedService{
+		settings:           settings,
+		inventoryEventRepo: inventoryEventRepo,
+		orderEventRepo:     orderEventRepo,
+		catalogEventRepo:   catalogEventRepo,
+		logger:             logger,
+	}
+}
+
+func (s *changeFeedService) InventoryChanges(ctx context.Context, req ChangeFeedRequest) (*InventoryChangesResponse, error) {
+	s.logger.Debug("Listing inventory changes", "since", req.Since, "limit", req.Limit)
+
+	sequence, err := parseChangeCursor(req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := changeFeedLimit(req.Limit)
+	events, err := s.inventoryEventRepo.ListSince(ctx, sequence, s.settings.SettleDelay, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list inventory changes", "error", err, "since", sequence)
+		return nil, errors.NewDatabaseError("failed to list inventory changes", err)
+	}
+
+	response := &InventoryChangesResponse{
+		Events:     make([]InventoryChangeEvent, 0, limit),
+		NextCursor: formatChangeCursor(sequence),
+		HasMore:    len(events) > limit,
+	}
+	if response.HasMore {
+		events = events[:limit]
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, InventoryChangeEvent{
+			Cursor:     formatChangeCursor(event.Sequence),
+			ProductID:  event.ProductID,
+			Type:       string(event.Type),
+			Quantity:   event.Quantity,
+			OnHand:     event.OnHand,
+			Reserved:   event.Reserved,
+			Available:  event.Available,
+			Version:    event.Version,
+			RecordedAt: event.RecordedAt,
+		})
+		response.NextCursor = formatChangeCursor(event.Sequence)
+	}
+
+	return response, nil
+}
+
+func (s *changeFeedService) OrderChanges(ctx context.Context, req ChangeFeedRequest) (*OrderChangesResponse, error) {
+	s.logger.Debug("Listing order changes", "since", req.Since, "limit", req.Limit)
+
+	sequence, err := parseChangeCursor(req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := changeFeedLimit(req.Limit)
+	events, err := s.orderEventRepo.ListSince(ctx, sequence, s.settings.SettleDelay, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list order changes", "error", err, "since", sequence)
+		return nil, errors.NewDatabaseError("failed to list order changes", err)
+	}
+
+	response := &OrderChangesResponse{
+		Events:     make([]OrderChangeEvent, 0, limit),
+		NextCursor: formatChangeCursor(sequence),
+		HasMore:    len(events) > limit,
+	}
+	if response.HasMore {
+		events = events[:limit]
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, OrderChangeEvent{
+			Cursor:         formatChangeCursor(event.Sequence),
+			OrderID:        event.OrderID,
+			Type:           string(event.Type),
+			PayloadVersion: event.PayloadVersion,
+			Payload:        event.Payload,
+			RecordedAt:     event.RecordedAt,
+		})
+		response.NextCursor = formatChangeCursor(event.Sequence)
+	}
+
+	return response, nil
+}
+
+func (s *changeFeedService) CatalogChanges(ctx context.Context, req ChangeFeedRequest) (*CatalogChangesResponse, error) {
+	s.logger.Debug("Listing catalog changes", "since", req.Since, "limit", req.Limit)
+
+	sequence, err := parseChangeCursor(req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := changeFeedLimit(req.Limit)
+	events, err := s.catalogEventRepo.ListSince(ctx, sequence, s.settings.SettleDelay, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to list catalog changes", "error", err, "since", sequence)
+		return nil, errors.NewDatabaseError("failed to list catalog changes", err)
+	}
+
+	response := &CatalogChangesResponse{
+		Events:     make([]CatalogChangeEvent, 0, limit),
+		NextCursor: formatChangeCursor(sequence),
+		HasMore:    len(events) > limit,
+	}
+	if response.HasMore {
+		events = events[:limit]
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, newCatalogChangeEvent(event))
+		response.NextCursor = formatChangeCursor(event.Sequence)
+	}
+
+	return response, nil
+}
+
+// newCatalogChangeEvent returns the change feed entry of a catalog event
+func newCatalogChangeEvent(event *models.CatalogEvent) CatalogChangeEvent {
+	return CatalogChangeEvent{
+		Cursor:     formatChangeCursor(event.Sequence),
+		ProductID:  event.ProductID,
+		SKU:        event.SKU,
+		Type:       string(event.Type),
+		RecordedAt: event.RecordedAt,
+	}
+}
+
+// parseChangeCursor returns the event sequence a change feed cursor points at; the
+// empty cursor is before the first event
+func parseChangeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	sequence, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || sequence < 0 {
+		return 0, errors.NewValidationError("invalid change feed cursor: " + cursor)
+	}
+	return sequence, nil
+}
+
+// formatChangeCursor returns the change feed cursor of an event sequence
+func formatChangeCursor(sequence int64) string {
+	return strconv.FormatInt(sequence, 10)
+}
+
+// changeFeedLimit returns the requested page size, or the default one
+func changeFeedLimit(limit int) int {
+	if limit <= 0 {
+		return defaultChangeFeedLimit
+	}
+	return limit
+}
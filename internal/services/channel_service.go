@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/channels"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// channelService implements ChannelService interface
+type channelService struct {
+	channelManager *channels.ChannelManager
+	listingRepo    repository.ChannelListingRepository
+	orderRepo      repository.OrderRepository
+	productRepo    repository.ProductRepository
+	userRepo       repository.UserRepository
+	orderService   OrderService
+	logger         *logger.Logger
+}
+
+// NewChannelService creates a new channel service
+func NewChannelService(
+	channelManager *channels.ChannelManager,
+	listingRepo repository.ChannelListingRepository,
+	orderRepo repository.OrderRepository,
+	productRepo repository.ProductRepository,
+	userRepo repository.UserRepository,
+	orderService OrderService,
+	logger *logger.Logger,
+) ChannelService {
+	return &channelService{
+		channelManager: channelManager,
+		listingRepo:    listingRepo,
+		orderRepo:      orderRepo,
+		productRepo:    productRepo,
+		userRepo:       userRepo,
+		orderService:   orderService,
+		logger:         logger,
+	}
+}
+
+func (s *channelService) ListChannels(ctx context.Context) []*ChannelResponse {
+	channelTypes := s.channelManager.ListChannels()
+
+	responses := make([]*ChannelResponse, 0, len(channelTypes))
+	for _, channelType := range channelTypes {
+		connector, err := s.channelManager.GetConnector(channelType)
+		if err != nil {
+			continue
+		}
+		responses = append(responses, &ChannelResponse{
+			Channel: string(channelType),
+			Healthy: connector.IsHealthy(ctx),
+		})
+	}
+
+	return responses
+}
+
+func (s *channelService) ImportOrder(ctx context.Context, channel string, payload []byte) (*ChannelOrderImportResponse, error) {
+	s.logger.Info("Importing channel order", "channel", channel, "payload_size", len(payload))
+
+	connector, err := s.getConnector(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	externalOrder, err := connector.ParseOrder(payload)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid channel order", err.Error())
+	}
+	if err := externalOrder.Validate(); err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid channel order", err.Error())
+	}
+
+	// Marketplaces redeliver order notifications, so imports must be idempotent
+	existing, err := s.orderRepo.GetByExternalID(ctx, channel, externalOrder.ExternalID)
+	if err != nil {
+		s.logger.Error("Failed to check for imported channel order", "error", err, "channel", channel, "external_id", externalOrder.ExternalID)
+		return nil, err
+	}
+	if existing != nil {
+		s.logger.Info("Channel order already imported", "channel", channel, "external_id", externalOrder.ExternalID, "order_id", existing.ID)
+		order, err := s.orderService.GetOrder(ctx, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &ChannelOrderImportResponse{Order: order, Duplicate: true}, nil
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, externalOrder.CustomerEmail)
+	if err != nil {
+		s.logger.Error("Failed to get customer for channel order", "error", err, "channel", channel)
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundErrorWithID("customer", externalOrder.CustomerEmail)
+	}
+
+	items, err := s.mapOrderItems(ctx, channel, externalOrder.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderService.CreateOrder(ctx, CreateOrderRequest{
+		UserID:          user.ID,
+		Items:           items,
+		Notes:           externalOrder.Notes,
+		Channel:         channel,
+		ExternalOrderID: externalOrder.ExternalID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create order from channel", "error", err, "channel", channel, "external_id", externalOrder.ExternalID)
+		return nil, err
+	}
+
+	s.logger.Info("Channel order imported", "channel", channel, "external_id", externalOrder.ExternalID, "order_id", order.ID)
+	return &ChannelOrderImportResponse{Order: order}, nil
+}
+
+// mapOrderItems translates channel SKUs to products, merging repeated lines
+func (s *channelService) mapOrderItems(ctx context.Context, channel string, externalItems []channels.ExternalOrderItem) ([]OrderItem, error) {
+	skus := make([]string, 0, len(externalItems))
+	for _, item := range externalItems {
+		skus = append(skus, item.ChannelSKU)
+	}
+
+	listings, err := s.listingRepo.GetByChannelSKUs(ctx, channel, skus)
+	if err != nil {
+		s.logger.Error("Failed to get channel listings", "error", err, "channel", channel)
+		return nil, err
+	}
+
+	productBySKU := make(map[string]string, len(listings))
+	for _, listing := range listings {
+		productBySKU[listing.ChannelSKU] = listing.ProductID
+	}
+
+	items := make([]OrderItem, 0, len(externalItems))
+	indexByProduct := make(map[string]int, len(externalItems))
+	for _, item := range externalItems {
+		productID, ok := productBySKU[item.ChannelSKU]
+		if !ok {
+			return nil, errors.NewValidationErrorWithDetails(
+				"invalid channel order",
+				fmt.Sprintf("channel SKU %s is not mapped to a product", item.ChannelSKU))
+		}
+
+		if i, seen := indexByProduct[productID]; seen {
+			items[i].Quantity += item.Quantity
+			continue
+		}
+		indexByProduct[productID] = len(items)
+		items = append(items, OrderItem{
+			ProductID: productID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return items, nil
+}
+
+func (s *channelService) CreateListing(ctx context.Context, channel string, req CreateChannelListingRequest) (*ChannelListingResponse, error) {
+	s.logger.Info("Creating channel listing", "channel", channel, "channel_sku", req.ChannelSKU, "product_id", req.ProductID)
+
+	if _, err := s.getConnector(channel); err != nil {
+		return nil, err
+	}
+	if req.ProductID == "" || req.ChannelSKU == "" {
+		return nil, errors.NewValidationError("product ID and channel SKU are required")
+	}
+
+	product, err := s.productRepo.GetByID(ctx, req.ProductID)
+	if err != nil {
+		s.logger.Error("Failed to get product for channel listing", "error", err, "product_id", req.ProductID)
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.NewNotFoundErrorWithID("product", req.ProductID)
+	}
+
+	existing, err := s.listingRepo.GetByChannelSKUs(ctx, channel, []string{req.ChannelSKU})
+	if err != nil {
+		s.logger.Error("Failed to check channel listing", "error", err, "channel", channel, "channel_sku", req.ChannelSKU)
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.NewConflictError(fmt.Sprintf("channel listing for SKU %s already exists", req.ChannelSKU))
+	}
+
+	listing := &models.ChannelListing{
+		Channel:    channel,
+		ChannelSKU: req.ChannelSKU,
+		ProductID:  req.ProductID,
+		ExternalID: req.ExternalID,
+	}
+	if err := s.listingRepo.Create(ctx, listing); err != nil {
+		s.logger.Error("Failed to create channel listing", "error", err, "channel", channel, "channel_sku", req.ChannelSKU)
+		return nil, err
+	}
+
+	return newChannelListingResponse(listing), nil
+}
+
+func (s *channelService) ListListings(ctx context.Context, channel string) ([]*ChannelListingResponse, error) {
+	s.logger.Debug("Listing channel listings", "channel", channel)
+
+	if _, err := s.getConnector(channel); err != nil {
+		return nil, err
+	}
+
+	listings, err := s.listingRepo.ListByChannel(ctx, channel)
+	if err != nil {
+		s.logger.Error("Failed to list channel listings", "error", err, "channel", channel)
+		return nil, err
+	}
+
+	responses := make([]*ChannelListingResponse, len(listings))
+	for i, listing := range listings {
+		responses[i] = newChannelListingResponse(listing)
+	}
+
+	return responses, nil
+}
+
+// SyncChannel pushes current availability and prices for every listing on the channel
+func (s *channelService) SyncChannel(ctx context.Context, channel string) (*ChannelSyncResponse, error) {
+	s.logger.Info("Syncing channel", "channel", channel)
+
+	connector, err := s.getConnector(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	listings, err := s.listingRepo.ListByChannel(ctx, channel)
+	if err != nil {
+		s.logger.Error("Failed to list channel listings for sync", "error", err, "channel", channel)
+		return nil, err
+	}
+
+	inventoryUpdates := make([]channels.InventoryUpdate, 0, len(listings))
+	priceUpdates := make([]channels.PriceUpdate, 0, len(listings))
+	for _, listing := range listings {
+		if listing.Product == nil {
+			continue
+		}
+
+		// Inactive products are listed as out of stock rather than removed
+		available := 0
+		if listing.Product.IsActive && listing.Product.Inventory != nil {
+			available = listing.Product.Inventory.Available
+		}
+
+		inventoryUpdates = append(inventoryUpdates, channels.InventoryUpdate{
+			ChannelSKU: listing.ChannelSKU,
+			ExternalID: listing.ExternalID,
+			Available:  available,
+		})
+		priceUpdates = append(priceUpdates, channels.PriceUpdate{
+			ChannelSKU: listing.ChannelSKU,
+			ExternalID: listing.ExternalID,
+			Price:      listing.Product.Price,
+			Currency:   "USD",
+		})
+	}
+
+	if err := connector.PushInventory(ctx, inventoryUpdates); err != nil {
+		s.logger.Error("Failed to push channel inventory", "error", err, "channel", channel)
+		return nil, errors.NewExternalServiceError(channel, "failed to push inventory", err)
+	}
+	if err := connector.PushPrices(ctx, priceUpdates); err != nil {
+		s.logger.Error("Failed to push channel prices", "error", err, "channel", channel)
+		return nil, errors.NewExternalServiceError(channel, "failed to push prices", err)
+	}
+
+	s.logger.Info("Channel synced", "channel", channel, "inventory_updates", len(inventoryUpdates), "price_updates", len(priceUpdates))
+	return &ChannelSyncResponse{
+		Channel:          channel,
+		InventoryUpdates: len(inventoryUpdates),
+		PriceUpdates:     len(priceUpdates),
+	}, nil
+}
+
+// getConnector resolves a registered connector or returns a not found error
+func (s *channelService) getConnector(channel string) (channels.Connector, error) {
+	connector, err := s.channelManager.GetConnector(channels.ChannelType(channel))
+	if err != nil {
+		return nil, errors.NewNotFoundErrorWithID("channel", channel)
+	}
+	return connector, nil
+}
+
+// newChannelListingResponse converts a listing model to its response DTO
+func newChannelListingResponse(listing *models.ChannelListing) *ChannelListingResponse {
+	return &ChannelListingResponse{
+		ID:         listing.ID,
+		Channel:    listing.Channel,
+		ChannelSKU: listing.ChannelSKU,
+		ProductID:  listing.ProductID,
+		ExternalID: listing.ExternalID,
+		CreatedAt:  listing.CreatedAt,
+	}
+}
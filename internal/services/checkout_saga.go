@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/errors"
+)
+
+// Steps of the express checkout saga, in the order they run
+const (
+	CheckoutStepPlaceOrder    = "place_order"
+	CheckoutStepChargePayment = "charge_payment"
+)
+
+// checkoutRecoveryBatchSize bounds how many stale checkouts are recovered at once
+const checkoutRecoveryBatchSize = 50
+
+// CheckoutSagaSettings configures the recovery of express checkouts. A running
+// checkout that made no progress for StaleAfter is taken to have been interrupted,
+// so StaleAfter must outlast a checkout's slowest payment.
+type CheckoutSagaSettings struct {
+	StaleAfter time.Duration
+}
+
+// checkoutStep is a step of the express checkout saga. Run does the step's work;
+// Compensate undoes it when a later step cannot complete, and is nil for a step
+// with nothing to undo.
+type checkoutStep struct {
+	name       string
+	run        func(ctx context.Context, saga *models.CheckoutSaga, state *checkoutState) error
+	compensate func(ctx context.Context, saga *models.CheckoutSaga) error
+}
+
+// checkoutState carries what the steps of a checkout learn between them. A recovered
+// checkout starts without the request, address and payment method it was placed
+// with, and resumes from the saga alone.
+type checkoutState struct {
+	recovering bool
+	request    ExpressCheckoutRequest
+	address    *models.Address
+	method     *models.SavedPaymentMethod
+	response   *ExpressCheckoutResponse
+}
+
+// checkoutSteps returns the steps of the express checkout saga. Charging the payment
+// is the last step, so nothing after it can fail and a payment taken is never
+// compensated; refunds go through the refunds API.
+func (s *checkoutService) checkoutSteps() []checkoutStep {
+	return []checkoutStep{
+		{name: CheckoutStepPlaceOrder, run: s.placeCheckoutOrder, compensate: s.cancelCheckoutOrder},
+		{name: CheckoutStepChargePayment, run: s.chargeCheckoutOrder},
+	}
+}
+
+// runCheckoutSaga runs the steps of a checkout from the saga's current step. When a
+// step fails, the steps before it are compensated in reverse order and the saga is
+// compensated, or failed when the failing step was the first. A compensation that
+// fails leaves the saga running at the failed step, to be recovered again later.
+//
+// Progress is saved before each step. Saving is best effort: a saga that could not be
+// saved is brought up to date by the next save, or by recovery.
+func (s *checkoutService) runCheckoutSaga(ctx context.Context, saga *models.CheckoutSaga, state *checkoutState) error {
+	steps := s.checkoutSteps()
+	start := 0
+	for i, step := range steps {
+		if step.name == saga.Step {
+			start = i
+		}
+	}
+
+	for i := start; i < len(steps); i++ {
+		if saga.Step != steps[i].name {
+			saga.Step = steps[i].name
+			s.saveCheckoutSaga(ctx, saga)
+		}
+
+		if err := steps[i].run(ctx, saga, state); err != nil {
+			return s.compensateCheckoutSaga(ctx, saga, steps[:i], err)
+		}
+	}
+
+	saga.Status = models.CheckoutSagaStatusCompleted
+	saga.LastError = ""
+	s.saveCheckoutSaga(ctx, saga)
+	return nil
+}
+
+// compensateCheckoutSaga undoes the completed steps of a checkout after one failed
+func (s *checkoutService) compensateCheckoutSaga(ctx context.Context, saga *models.CheckoutSaga, completed []checkoutStep, cause error) error {
+	saga.LastError = cause.Error()
+	saga.Status = models.CheckoutSagaStatusFailed
+
+	for i := len(completed) - 1; i >= 0; i-- {
+		if completed[i].compensate == nil {
+			continue
+		}
+		if err := completed[i].compensate(ctx, saga); err != nil {
+			s.logger.Error("Failed to compensate checkout step", "error", err, "saga_id", saga.ID, "step", completed[i].name)
+			saga.Status = models.CheckoutSagaStatusRunning
+			saga.LastError = fmt.Sprintf("%s; compensating %s: %v", cause, completed[i].name, err)
+			s.saveCheckoutSaga(ctx, saga)
+			return cause
+		}
+		saga.Status = models.CheckoutSagaStatusCompensated
+	}
+
+	s.logger.Warn("Checkout saga stopped", "error", cause, "saga_id", saga.ID, "step", saga.Step, "status", saga.Status)
+	s.saveCheckoutSaga(ctx, saga)
+	return cause
+}
+
+// saveCheckoutSaga saves the progress of a checkout, logging failures
+func (s *checkoutService) saveCheckoutSaga(ctx context.Context, saga *models.CheckoutSaga) {
+	if err := s.sagaRepo.Update(ctx, saga); err != nil {
+		s.logger.Warn("Failed to save checkout saga", "error", err, "saga_id", saga.ID, "step", saga.Step)
+	}
+}
+
+// placeCheckoutOrder places the order of a checkout. A recovered checkout never
+// places its order, as the customer is no longer waiting for it: it picks up the
+// order its request placed before it stopped, if any.
+func (s *checkoutService) placeCheckoutOrder(ctx context.Context, saga *models.CheckoutSaga, state *checkoutState) error {
+	if state.recovering {
+		order, err := s.orderRepo.GetByIdempotencyKey(ctx, saga.UserID, saga.IdempotencyKey)
+		if err != nil {
+			return err
+		}
+		if order == nil {
+			return errors.NewBusinessError("checkout stopped before its order was placed")
+		}
+		saga.OrderID = &order.ID
+		return nil
+	}
+
+	shippingAddress := state.address.ShippingAddress
+	order, err := s.orderService.CreateOrder(ctx, CreateOrderRequest{
+		UserID:          saga.UserID,
+		Items:           []OrderItem{{ProductID: saga.ProductID, Quantity: saga.Quantity}},
+		Notes:           state.request.Notes,
+		PaymentMethod:   state.method.Method,
+		ShippingAddress: &shippingAddress,
+		IdempotencyKey:  saga.IdempotencyKey,
+		QueueTicket:     state.request.QueueTicket,
+		Gift:            state.request.Gift,
+		ClientIP:        saga.ClientIP,
+		DeliverySlotID:  state.request.DeliverySlotID,
+	})
+	if err != nil {
+		return err
+	}
+
+	saga.OrderID = &order.ID
+	state.response = &ExpressCheckoutResponse{Order: order}
+	return nil
+}
+
+// cancelCheckoutOrder compensates a placed order by cancelling it, which releases its
+// stock and delivery slot
+func (s *checkoutService) cancelCheckoutOrder(ctx context.Context, saga *models.CheckoutSaga) error {
+	if saga.OrderID == nil {
+		return nil
+	}
+	s.logger.Info("Cancelling order of checkout that could not be paid", "saga_id", saga.ID, "order_id", *saga.OrderID)
+	return s.orderService.CancelOrder(ctx, *saga.OrderID)
+}
+
+// chargeCheckoutOrder charges a checkout's order to its saved payment method. A
+// declined payment does not fail the step: the order stays pending for the customer
+// to pay or cancel, and the outcome is reported. A recovered checkout charges its
+// order only if it is still pending without payments, and fails the step when its
+// payment method was removed or expired since.
+func (s *checkoutService) chargeCheckoutOrder(ctx context.Context, saga *models.CheckoutSaga, state *checkoutState) error {
+	if state.recovering {
+		return s.resumeCheckoutCharge(ctx, saga)
+	}
+
+	order := state.response.Order
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+		OrderID:           order.ID,
+		Amount:            order.Total,
+		PaymentType:       string(state.method.Method),
+		ExternalReference: savedPaymentMethodReferencePrefix + state.method.ID,
+		CardFingerprint:   state.method.Fingerprint,
+		ClientIP:          saga.ClientIP,
+	})
+	if err != nil {
+		s.logger.Warn("Express checkout order placed but not paid", "error", err, "order_id", order.ID)
+		state.response.Outcome = ExpressCheckoutPaymentFailed
+		state.response.PaymentError = err.Error()
+		return nil
+	}
+
+	state.response.Payment = payment
+	state.response.Outcome = expressCheckoutOutcome(payment.Status)
+	if state.response.Outcome == ExpressCheckoutPaid {
+		order.Status = models.OrderStatusPaid
+	}
+	return nil
+}
+
+// resumeCheckoutCharge charges the order of a recovered checkout
+func (s *checkoutService) resumeCheckoutCharge(ctx context.Context, saga *models.CheckoutSaga) error {
+	if saga.OrderID == nil {
+		return errors.NewBusinessError("checkout has no order to charge")
+	}
+	order, err := s.orderRepo.GetByID(ctx, *saga.OrderID)
+	if err != nil {
+		return err
+	}
+	if order == nil || order.Status != models.OrderStatusPending || len(order.Payments) > 0 {
+		// Paid, cancelled or being paid since; nothing is left to charge
+		return nil
+	}
+
+	method, err := s.paymentMethodRepo.GetByID(ctx, saga.PaymentMethodID)
+	if err != nil {
+		return err
+	}
+	if method == nil || method.UserID != saga.UserID {
+		return errors.NewBusinessError(fmt.Sprintf("payment method %s was removed", saga.PaymentMethodID))
+	}
+	if method.IsExpired(time.Now()) {
+		return errors.NewBusinessError(fmt.Sprintf("payment method %s has expired", method.ID))
+	}
+
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+		OrderID:           order.ID,
+		Amount:            order.TotalAmount,
+		PaymentType:       string(method.Method),
+		ExternalReference: savedPaymentMethodReferencePrefix + method.ID,
+		CardFingerprint:   method.Fingerprint,
+		ClientIP:          saga.ClientIP,
+	})
+	if err != nil {
+		s.logger.Warn("Recovered checkout order placed but not paid", "error", err, "order_id", order.ID)
+		return nil
+	}
+	s.logger.Info("Recovered checkout order charged", "order_id", order.ID, "payment_status", payment.Status)
+	return nil
+}
+
+// RecoverCheckouts resumes the express checkouts that stopped mid-step, as when their
+// process crashed, and reports how many it finished. Each is claimed first, so a
+// checkout is recovered by one instance, and never while its request is still going.
+func (s *checkoutService) RecoverCheckouts(ctx context.Context) (int, error) {
+	now := time.Now()
+	sagas, err := s.sagaRepo.ListStale(ctx, now.Add(-s.sagaSettings.StaleAfter), checkoutRecoveryBatchSize)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to list stale checkouts", err)
+	}
+
+	recovered := 0
+	var firstErr error
+	for _, saga := range sagas {
+		claimed, err := s.sagaRepo.Claim(ctx, saga, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.NewDatabaseError("failed to claim checkout", err)
+			}
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		s.logger.Info("Recovering checkout", "saga_id", saga.ID, "step", saga.Step, "user_id", saga.UserID)
+		// A failed step is the expected end of some recoveries; the saga records it
+		_ = s.runCheckoutSaga(ctx, saga, &checkoutState{recovering: true})
+		if saga.Status.IsFinished() {
+			recovered++
+		}
+	}
+	return recovered, firstErr
+}
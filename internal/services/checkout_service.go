@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// maxIdempotencyKeyLength matches the orders.idempotency_key column
+const maxIdempotencyKeyLength = 255
+
+// savedPaymentMethodReferencePrefix marks the external reference of payments
+// charged to a saved payment method
+const savedPaymentMethodReferencePrefix = "saved_payment_method:"
+
+// checkoutService implements CheckoutService interface
+type checkoutService struct {
+	addressRepo       repository.AddressRepository
+	paymentMethodRepo repository.SavedPaymentMethodRepository
+	orderRepo         repository.OrderRepository
+	orderService      OrderService
+	paymentService    PaymentService
+	sagaRepo          repository.CheckoutSagaRepository
+	sagaSettings      CheckoutSagaSettings
+	logger            *logger.Logger
+}
+
+// NewCheckoutService creates a new checkout service
+func NewCheckoutService(
+	addressRepo repository.AddressRepository,
+	paymentMethodRepo repository.SavedPaymentMethodRepository,
+	orderRepo repository.OrderRepository,
+	orderService OrderService,
+	paymentService PaymentService,
+	sagaRepo repository.CheckoutSagaRepository,
+	sagaSettings CheckoutSagaSettings,
+	logger *logger.Logger,
+) CheckoutService {
+	return &checkoutService{
+		addressRepo:       addressRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		orderRepo:         orderRepo,
+		orderService:      orderService,
+		paymentService:    paymentService,
+		sagaRepo:          sagaRepo,
+		sagaSettings:      sagaSettings,
+		logger:            logger,
+	}
+}
+
+func (s *checkoutService) AddAddress(ctx context.Context, userID string, req CreateAddressRequest) (*AddressResponse, error) {
+	s.logger.Info("Adding address", "user_id", userID, "is_default", req.IsDefault)
+
+	if len(req.Country) != 2 {
+		return nil, errors.NewValidationError("country must be a two-letter ISO code")
+	}
+
+	// The first address becomes the default, so that express checkout works right away
+	isDefault := req.IsDefault
+	if !isDefault {
+		current, err := s.addressRepo.GetDefault(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get default address", "error", err, "user_id", userID)
+			return nil, err
+		}
+		isDefault = current == nil
+	}
+
+	address := &models.Address{
+		UserID: userID,
+		Label:  req.Label,
+		ShippingAddress: models.ShippingAddress{
+			Recipient:  req.Recipient,
+			Line1:      req.Line1,
+			Line2:      req.Line2,
+			City:       req.City,
+			Region:     req.Region,
+			PostalCode: req.PostalCode,
+			Country:    strings.ToUpper(req.Country),
+			Phone:      req.Phone,
+		},
+		IsDefault: isDefault,
+	}
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		s.logger.Error("Failed to create address", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return newAddressResponse(address), nil
+}
+
+func (s *checkoutService) ListAddresses(ctx context.Context, userID string) ([]*AddressResponse, error) {
+	s.logger.Debug("Listing addresses", "user_id", userID)
+
+	addresses, err := s.addressRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list addresses", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	responses := make([]*AddressResponse, len(addresses))
+	for i, address := range addresses {
+		responses[i] = newAddressResponse(address)
+	}
+	return responses, nil
+}
+
+func (s *checkoutService) SetDefaultAddress(ctx context.Context, userID, id string) (*AddressResponse, error) {
+	s.logger.Info("Setting default address", "user_id", userID, "id", id)
+
+	address, err := s.ownAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addressRepo.SetDefault(ctx, userID, id); err != nil {
+		s.logger.Error("Failed to set default address", "error", err, "user_id", userID, "id", id)
+		return nil, err
+	}
+
+	address.IsDefault = true
+	return newAddressResponse(address), nil
+}
+
+func (s *checkoutService) DeleteAddress(ctx context.Context, userID, id string) error {
+	s.logger.Info("Deleting address", "user_id", userID, "id", id)
+
+	if _, err := s.ownAddress(ctx, userID, id); err != nil {
+		return err
+	}
+
+	// Placed orders keep a copy of their address, so deleting it changes none of them
+	if err := s.addressRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete address", "error", err, "user_id", userID, "id", id)
+		return err
+	}
+	return nil
+}
+
+// ownAddress returns the user's address; addresses of other users are not found
+func (s *checkoutService) ownAddress(ctx context.Context, userID, id string) (*models.Address, error) {
+	address, err := s.addressRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get address", "error", err, "id", id)
+		return nil, err
+	}
+	if address == nil || address.UserID != userID {
+		return nil, errors.NewNotFoundErrorWithID("address", id)
+	}
+	return address, nil
+}
+
+func (s *checkoutService) AddPaymentMethod(ctx context.Context, userID string, req CreateSavedPaymentMethodRequest) (*SavedPaymentMethodResponse, error) {
+	s.logger.Info("Adding saved payment method", "user_id", userID, "method", req.Method, "is_default", req.IsDefault)
+
+	if !req.Method.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid payment method %s", req.Method))
+	}
+	if req.GatewayToken == "" {
+		return nil, errors.NewValidationError("gateway token is required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewValidationError("payment method has expired")
+	}
+
+	// The first payment method becomes the default, so that express checkout works right away
+	isDefault := req.IsDefault
+	if !isDefault {
+		current, err := s.paymentMethodRepo.GetDefault(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get default payment method", "error", err, "user_id", userID)
+			return nil, err
+		}
+		isDefault = current == nil
+	}
+
+	method := &models.SavedPaymentMethod{
+		UserID:       userID,
+		Method:       req.Method,
+		Label:        req.Label,
+		Last4:        req.Last4,
+		GatewayToken: req.GatewayToken,
+		Fingerprint:  req.Fingerprint,
+		ExpiresAt:    req.ExpiresAt,
+		IsDefault:    isDefault,
+	}
+
+	if err := s.paymentMethodRepo.Create(ctx, method); err != nil {
+		s.logger.Error("Failed to create saved payment method", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	return newSavedPaymentMethodResponse(method), nil
+}
+
+func (s *checkoutService) ListPaymentMethods(ctx context.Context, userID string) ([]*SavedPaymentMethodResponse, error) {
+	s.logger.Debug("Listing saved payment methods", "user_id", userID)
+
+	methods, err := s.paymentMethodRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list saved payment methods", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	responses := make([]*SavedPaymentMethodResponse, len(methods))
+	for i, method := range methods {
+		responses[i] = newSavedPaymentMethodResponse(method)
+	}
+	return responses, nil
+}
+
+func (s *checkoutService) SetDefaultPaymentMethod(ctx context.Context, userID, id string) (*SavedPaymentMethodResponse, error) {
+	s.logger.Info("Setting default payment method", "user_id", userID, "id", id)
+
+	method, err := s.ownPaymentMethod(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.paymentMethodRepo.SetDefault(ctx, userID, id); err != nil {
+		s.logger.Error("Failed to set default payment method", "error", err, "user_id", userID, "id", id)
+		return nil, err
+	}
+
+	method.IsDefault = true
+	return newSavedPaymentMethodResponse(method), nil
+}
+
+func (s *checkoutService) DeletePaymentMethod(ctx context.Context, userID, id string) error {
+	s.logger.Info("Deleting saved payment method", "user_id", userID, "id", id)
+
+	if _, err := s.ownPaymentMethod(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.paymentMethodRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete saved payment method", "error", err, "user_id", userID, "id", id)
+		return err
+	}
+	return nil
+}
+
+// ownPaymentMethod returns the user's saved payment method; methods of other users are not found
+func (s *checkoutService) ownPaymentMethod(ctx context.Context, userID, id string) (*models.SavedPaymentMethod, error) {
+	method, err := s.paymentMethodRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get saved payment method", "error", err, "id", id)
+		return nil, err
+	}
+	if method == nil || method.UserID != userID {
+		return nil, errors.NewNotFoundErrorWithID("payment method", id)
+	}
+	return method, nil
+}
+
+// ExpressCheckout places an order for one product, shipped to the user's default
+// address, and pays it with the default payment method. The order goes through the
+// same placement stages as any other order, then the payment stage.
+//
+// Placing the order and paying it are separate steps of a saga, whose progress is
+// saved so that a checkout interrupted between them is recovered by
+// RecoverCheckouts. When placement fails nothing is left behind and the error is
+// returned. Once the order is placed, a failed payment is not an error: the order
+// stays pending with its stock reserved, and the response reports the payment
+// outcome, so that the client can pay the order through the payments API or cancel it.
+//
+// A retry with the same idempotency key returns the order as it is now, without
+// placing or charging anything; a different product or quantity is a conflict.
+func (s *checkoutService) ExpressCheckout(ctx context.Context, userID string, req ExpressCheckoutRequest) (*ExpressCheckoutResponse, error) {
+	s.logger.Info("Express checkout", "user_id", userID, "product_id", req.ProductID, "quantity", req.Quantity)
+
+	if req.IdempotencyKey == "" {
+		return nil, errors.NewValidationError("idempotency key is required")
+	}
+	if len(req.IdempotencyKey) > maxIdempotencyKeyLength {
+		return nil, errors.NewValidationError(fmt.Sprintf("idempotency key must be at most %d characters", maxIdempotencyKeyLength))
+	}
+	if req.ProductID == "" {
+		return nil, errors.NewValidationError("product ID is required")
+	}
+	if req.Quantity <= 0 {
+		return nil, errors.NewValidationError("quantity must be greater than 0")
+	}
+
+	if replay, err := s.replay(ctx, userID, req); err != nil || replay != nil {
+		return replay, err
+	}
+
+	address, err := s.addressRepo.GetDefault(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get default address", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if address == nil {
+		return nil, errors.NewBusinessError("no default shipping address, save an address before using express checkout")
+	}
+
+	method, err := s.paymentMethodRepo.GetDefault(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get default payment method", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if method == nil {
+		return nil, errors.NewBusinessError("no default payment method, save a payment method before using express checkout")
+	}
+	if method.IsExpired(time.Now()) {
+		return nil, errors.NewBusinessError(fmt.Sprintf("default payment method %s has expired", method.ID))
+	}
+
+	saga := &models.CheckoutSaga{
+		UserID:          userID,
+		IdempotencyKey:  req.IdempotencyKey,
+		ProductID:       req.ProductID,
+		Quantity:        req.Quantity,
+		PaymentMethodID: method.ID,
+		ClientIP:        req.ClientIP,
+		Step:            CheckoutStepPlaceOrder,
+		Status:          models.CheckoutSagaStatusRunning,
+	}
+	if err := s.sagaRepo.Create(ctx, saga); err != nil {
+		return nil, errors.NewDatabaseError("failed to start checkout", err)
+	}
+
+	state := &checkoutState{request: req, address: address, method: method}
+	if err := s.runCheckoutSaga(ctx, saga, state); err != nil {
+		// A concurrent request with the same key may have placed the order first, in
+		// which case the unique key rejected this one
+		if replay, replayErr := s.replay(ctx, userID, req); replayErr == nil && replay != nil {
+			return replay, nil
+		}
+		s.logger.Warn("Express checkout order was not placed", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	s.logger.Info("Express checkout completed", "order_id", state.response.Order.ID, "outcome", state.response.Outcome)
+	return state.response, nil
+}
+
+// replay returns the order placed earlier with the request's idempotency key, or
+// nil when the key is new
+func (s *checkoutService) replay(ctx context.Context, userID string, req ExpressCheckoutRequest) (*ExpressCheckoutResponse, error) {
+	order, err := s.orderRepo.GetByIdempotencyKey(ctx, userID, req.IdempotencyKey)
+	if err != nil {
+		s.logger.Error("Failed to get order by idempotency key", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, nil
+	}
+
+	if len(order.Items) != 1 || order.Items[0].ProductID != req.ProductID || order.Items[0].Quantity != req.Quantity {
+		return nil, errors.NewConflictError("idempotency key was already used for a different checkout")
+	}
+
+	s.logger.Info("Replaying express checkout", "order_id", order.ID, "user_id", userID)
+
+	response := &ExpressCheckoutResponse{
+		Outcome:  ExpressCheckoutPaymentPending,
+		Order:    newOrderListResponse(order),
+		Replayed: true,
+	}
+
+	// Payments are preloaded oldest first; the latest one decides the outcome
+	if count := len(order.Payments); count > 0 {
+		payment := &order.Payments[count-1]
+		response.Payment = toPaymentResponse(payment)
+		response.Outcome = expressCheckoutOutcome(payment.Status)
+		if response.Outcome == ExpressCheckoutPaymentFailed {
+			response.PaymentError = payment.FailureReason
+		}
+	}
+	return response, nil
+}
+
+// expressCheckoutOutcome returns the outcome of an express checkout whose latest
+// payment has the given status
+func expressCheckoutOutcome(status models.PaymentStatus) ExpressCheckoutOutcome {
+	switch status {
+	case models.PaymentStatusCompleted, models.PaymentStatusProcessed, models.PaymentStatusRefunded:
+		return ExpressCheckoutPaid
+	case models.PaymentStatusHeld:
+		return ExpressCheckoutPaymentHeld
+	case models.PaymentStatusFailed, models.PaymentStatusCancelled:
+		return ExpressCheckoutPaymentFailed
+	default:
+		return ExpressCheckoutPaymentPending
+	}
+}
+
+// newAddressResponse converts an address model to its response DTO
+func newAddressResponse(address *models.Address) *AddressResponse {
+	return &AddressResponse{
+		ID:        address.ID,
+		Label:     address.Label,
+		Address:   address.ShippingAddress,
+		IsDefault: address.IsDefault,
+		CreatedAt: address.CreatedAt,
+	}
+}
+
+// newSavedPaymentMethodResponse converts a saved payment method model to its
+// response DTO, leaving out the gateway token
+func newSavedPaymentMethodResponse(method *models.SavedPaymentMethod) *SavedPaymentMethodResponse {
+	return &SavedPaymentMethodResponse{
+		ID:        method.ID,
+		Method:    method.Method,
+		Label:     method.Label,
+		Last4:     method.Last4,
+		ExpiresAt: method.ExpiresAt,
+		IsDefault: method.IsDefault,
+		CreatedAt: method.CreatedAt,
+	}
+}
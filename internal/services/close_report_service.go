@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+)
+
+// closeReportLowStockListed bounds the low-stock products named in a close report
+const closeReportLowStockListed = 10
+
+// CloseReportSettings configures the end-of-day close report. Times maps each store,
+// by order channel or DefaultStore for all stores, to the minutes after midnight its
+// report is sent, in Location. Recipients are the emails of the users it is sent to.
+type CloseReportSettings struct {
+	Location   *time.Location
+	Times      map[string]int
+	Recipients []string
+}
+
+// NewCloseReportSettings builds settings from configured send times, such as "21:00",
+// keyed by store
+func NewCloseReportSettings(timezone string, times map[string]string, recipients []string) (CloseReportSettings, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return CloseReportSettings{}, fmt.Errorf("invalid store timezone %q: %w", timezone, err)
+	}
+
+	settings := CloseReportSettings{
+		Location:   location,
+		Times:      make(map[string]int, len(times)),
+		Recipients: recipients,
+	}
+	for store, spec := range times {
+		clock, err := parseClock(spec)
+		if err != nil {
+			return settings, fmt.Errorf("invalid close report time for store %s: %w", store, err)
+		}
+		settings.Times[store] = clock
+	}
+	return settings, nil
+}
+
+// closeReportService implements CloseReportService interface
+type closeReportService struct {
+	settings      CloseReportSettings
+	reports       ReportService
+	inventory     InventoryService
+	closeRepo     repository.CloseReportRepository
+	userRepo      repository.UserRepository
+	notifications NotificationService
+	now           func() time.Time
+	logger        *logger.Logger
+}
+
+// NewCloseReportService creates a new close report service
+func NewCloseReportService(
+	settings CloseReportSettings,
+	reports ReportService,
+	inventory InventoryService,
+	closeRepo repository.CloseReportRepository,
+	userRepo repository.UserRepository,
+	notifications NotificationService,
+	logger *logger.Logger,
+) CloseReportService {
+	return &closeReportService{
+		settings:      settings,
+		reports:       reports,
+		inventory:     inventory,
+		closeRepo:     closeRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// SendDue sends the close report of each store whose local send time has passed today
+// and whose report for today was not sent yet. A report is claimed before it is sent,
+// so concurrent instances send it once; a report that cannot be composed is released
+// and retried on the next run.
+func (s *closeReportService) SendDue(ctx context.Context) (int, error) {
+	if len(s.settings.Recipients) == 0 {
+		return 0, nil
+	}
+
+	local := s.now().In(s.settings.Location)
+	minutes := local.Hour()*60 + local.Minute()
+	date := local.Format("2006-01-02")
+
+	stores := make([]string, 0, len(s.settings.Times))
+	for store, clock := range s.settings.Times {
+		if minutes >= clock {
+			stores = append(stores, store)
+		}
+	}
+	sort.Strings(stores)
+
+	sent := 0
+	var firstErr error
+	for _, store := range stores {
+		claimed, err := s.closeRepo.Claim(ctx, store, date, s.now())
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		report, err := s.Compose(ctx, store, date)
+		if err != nil {
+			s.logger.Error("Failed to compose close report", "error", err, "store", store, "date", date)
+			if releaseErr := s.closeRepo.Release(ctx, store, date); releaseErr != nil {
+				s.logger.Warn("Failed to release close report for retry", "error", releaseErr, "store", store, "date", date)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		s.send(ctx, report)
+		sent++
+	}
+	return sent, firstErr
+}
+
+// Compose builds the close report of a store for a day. Sales and shipping SLA
+// breaches are the store's own, or of all stores for DefaultStore; orders by status,
+// payment failures and low stock cover all stores, as they are not kept per store.
+func (s *closeReportService) Compose(ctx context.Context, store, date string) (*CloseReportResponse, error) {
+	sales, err := s.reports.GenerateDailySalesReport(ctx, date, 0)
+	if err != nil {
+		return nil, err
+	}
+	failures, err := s.reports.GeneratePaymentFailureReport(ctx, PaymentFailureReportRequest{StartDate: date, EndDate: date})
+	if err != nil {
+		return nil, err
+	}
+	lowStock, err := s.inventory.GetProductLowStockAlert(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sla, err := s.reports.GenerateShippingSLAReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CloseReportResponse{
+		Store:              store,
+		Date:               date,
+		Orders:             sales.TotalOrders,
+		Sales:              sales.TotalSales,
+		OrdersByStatus:     sales.OrdersByStatus,
+		PaymentAttempts:    failures.Totals.Attempts,
+		PaymentFailures:    failures.Totals.Failures,
+		PaymentFailureRate: failures.Totals.FailureRate,
+		LowStockCount:      lowStock.Count,
+		SLAOverdue:         sla.Totals.Overdue,
+		SLADueSoon:         sla.Totals.DueSoon,
+	}
+	if store != DefaultStore {
+		report.Orders = sales.OrdersByChannel[store]
+		report.Sales = sales.SalesByChannel[store]
+		report.SLAOverdue, report.SLADueSoon = 0, 0
+		for _, stats := range sla.Stores {
+			if stats.Channel == store {
+				report.SLAOverdue, report.SLADueSoon = stats.Overdue, stats.DueSoon
+			}
+		}
+	}
+	if report.Orders > 0 {
+		report.AverageOrderValue = report.Sales / float64(report.Orders)
+	}
+	for i, product := range lowStock.Products {
+		if i == closeReportLowStockListed {
+			break
+		}
+		report.LowStockProducts = append(report.LowStockProducts, product.ProductName)
+	}
+	return report, nil
+}
+
+// send emails a close report to each recipient. Recipients without an account are
+// logged and skipped.
+func (s *closeReportService) send(ctx context.Context, report *CloseReportResponse) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		s.logger.Error("Failed to encode close report", "error", err, "store", report.Store, "date", report.Date)
+		return
+	}
+
+	title := fmt.Sprintf("Close report for %s on %s", closeReportStoreName(report.Store), report.Date)
+	body := closeReportBody(report)
+	for _, email := range s.settings.Recipients {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil || user == nil {
+			s.logger.Warn("Close report recipient not found", "error", err, "email", email)
+			continue
+		}
+		if err := s.notifications.SendNotification(ctx, SendNotificationRequest{
+			UserID:  user.ID,
+			Type:    string(models.NotificationTypeCloseReport),
+			Channel: string(models.NotificationChannelEmail),
+			Title:   title,
+			Body:    body,
+			Data:    string(data),
+		}); err != nil {
+			s.logger.Warn("Failed to send close report", "error", err, "store", report.Store, "user_id", user.ID)
+		}
+	}
+	s.logger.Info("Close report sent", "store", report.Store, "date", report.Date, "recipients", len(s.settings.Recipients))
+}
+
+// closeReportStoreName names a store in close reports
+func closeReportStoreName(store string) string {
+	if store == DefaultStore {
+		return "all stores"
+	}
+	return store
+}
+
+// closeReportBody renders a close report as the text of its email
+func closeReportBody(report *CloseReportResponse) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Sales: %.2f from %d orders (average %.2f)\n", report.Sales, report.Orders, report.AverageOrderValue)
+
+	statuses := make([]string, 0, len(report.OrdersByStatus))
+	for status := range report.OrdersByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	body.WriteString("Orders by status (all stores):")
+	if len(statuses) == 0 {
+		body.WriteString(" none")
+	}
+	for _, status := range statuses {
+		fmt.Fprintf(&body, " %s %d", status, report.OrdersByStatus[status])
+	}
+	body.WriteString("\n")
+
+	fmt.Fprintf(&body, "Payment failures: %d of %d attempts (%.1f%%)\n",
+		report.PaymentFailures, report.PaymentAttempts, report.PaymentFailureRate)
+	fmt.Fprintf(&body, "Low stock: %d products", report.LowStockCount)
+	if len(report.LowStockProducts) > 0 {
+		fmt.Fprintf(&body, " (%s)", strings.Join(report.LowStockProducts, ", "))
+	}
+	body.WriteString("\n")
+	fmt.Fprintf(&body, "Shipping SLA: %d orders overdue, %d due soon\n", report.SLAOverdue, report.SLADueSoon)
+	return body.String()
+}
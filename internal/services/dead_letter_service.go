@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// deadLetterService implements DeadLetterService interface
+type deadLetterService struct {
+	jobRepo repository.BackgroundJobRepository
+	now     func() time.Time
+	logger  *logger.Logger
+}
+
+// NewDeadLetterService creates a new dead-letter service
+func NewDeadLetterService(jobRepo repository.BackgroundJobRepository, logger *logger.Logger) DeadLetterService {
+	return &deadLetterService{
+		jobRepo: jobRepo,
+		now:     time.Now,
+		logger:  logger,
+	}
+}
+
+func (s *deadLetterService) ListDeadJobs(ctx context.Context, req ListDeadJobsRequest) (*ListDeadJobsResponse, error) {
+	s.logger.Debug("Listing dead jobs", "type", req.Type, "pool", req.Pool, "page", req.Page, "limit", req.Limit)
+
+	page, limit := req.Page, req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	if page < 1 {
+		page = 1
+	}
+	filter := repository.DeadJobFilter{Type: req.Type, Pool: req.Pool}
+	jobs, err := s.jobRepo.ListDeadJobs(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list dead jobs", err)
+	}
+	total, err := s.jobRepo.CountDeadJobs(ctx, filter)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count dead jobs", err)
+	}
+
+	return &ListDeadJobsResponse{
+		Jobs:  jobs,
+		Page:  page,
+		Limit: limit,
+		Total: int(total),
+	}, nil
+}
+
+func (s *deadLetterService) GetDeadJob(ctx context.Context, id string) (*models.DeadJob, error) {
+	job, err := s.jobRepo.GetDeadJob(ctx, id)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get dead job", err)
+	}
+	if job == nil {
+		return nil, errors.NewNotFoundErrorWithID("dead job", id)
+	}
+	return job, nil
+}
+
+func (s *deadLetterService) RequeueDeadJob(ctx context.Context, id string) (*RequeueDeadJobResponse, error) {
+	s.logger.Info("Requeueing dead job", "job_id", id)
+
+	job, err := s.jobRepo.RequeueDeadJob(ctx, id, s.now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to requeue dead job", err)
+	}
+	if job == nil {
+		return nil, errors.NewNotFoundErrorWithID("dead job", id)
+	}
+
+	s.logger.Info("Dead job requeued", "job_id", job.ID, "job_type", job.Type, "pool", job.Pool)
+	return &RequeueDeadJobResponse{
+		ID:    job.ID,
+		Type:  job.Type,
+		Pool:  job.Pool,
+		RunAt: job.RunAt,
+	}, nil
+}
+
+func (s *deadLetterService) DeleteDeadJob(ctx context.Context, id string) error {
+	deleted, err := s.jobRepo.DeleteDeadJob(ctx, id)
+	if err != nil {
+		return errors.NewDatabaseError("failed to delete dead job", err)
+	}
+	if !deleted {
+		return errors.NewNotFoundErrorWithID("dead job", id)
+	}
+
+	s.logger.Info("Dead job deleted", "job_id", id)
+	return nil
+}
+
+func (s *deadLetterService) PurgeDeadJobs(ctx context.Context, req PurgeDeadJobsRequest) (*PurgeDeadJobsResponse, error) {
+	filter := repository.DeadJobFilter{Type: req.Type, Pool: req.Pool}
+	if req.DiedBefore != "" {
+		diedBefore, err := time.Parse(time.RFC3339, req.DiedBefore)
+		if err != nil {
+			return nil, errors.NewValidationError(fmt.Sprintf("invalid died_before %s", req.DiedBefore))
+		}
+		filter.DiedBefore = &diedBefore
+	}
+	if filter.Type == "" && filter.Pool == "" && filter.DiedBefore == nil && !req.All {
+		return nil, errors.NewValidationError("purging dead jobs requires type, pool, died_before or all")
+	}
+
+	purged, err := s.jobRepo.PurgeDeadJobs(ctx, filter)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to purge dead jobs", err)
+	}
+
+	s.logger.Info("Dead jobs purged", "purged", purged, "type", req.Type, "pool", req.Pool, "died_before", req.DiedBefore)
+	return &PurgeDeadJobsResponse{Purged: purged}, nil
+}
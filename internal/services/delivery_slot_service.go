@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// deliveryWindowLayout is the HH:MM format of delivery window times
+	deliveryWindowLayout = "15:04"
+	// defaultDeliverySlotDays is how many days ahead slots are listed by default
+	defaultDeliverySlotDays = 14
+	// maxDeliverySlotDays bounds the days planned or listed at once
+	maxDeliverySlotDays = 92
+)
+
+// deliverySlotService implements DeliverySlotService interface
+type deliverySlotService struct {
+	slotRepo repository.DeliverySlotRepository
+	logger   *logger.Logger
+}
+
+// NewDeliverySlotService creates a new delivery slot service
+func NewDeliverySlotService(slotRepo repository.DeliverySlotRepository, logger *logger.Logger) DeliverySlotService {
+	return &deliverySlotService{
+		slotRepo: slotRepo,
+		logger:   logger,
+	}
+}
+
+func (s *deliverySlotService) PlanSlots(ctx context.Context, req PlanDeliverySlotsRequest) (*ListDeliverySlotsResponse, error) {
+	s.logger.Info("Planning delivery slots", "region", req.Region, "start_date", req.StartDate, "end_date", req.EndDate, "windows", len(req.Windows))
+
+	region := strings.TrimSpace(req.Region)
+	if region == "" {
+		return nil, errors.NewValidationError("region is required")
+	}
+	if req.StartDate == "" || req.EndDate == "" {
+		return nil, errors.NewValidationError("start_date and end_date are required")
+	}
+	startDate, endDate, err := parseDeliverySlotRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if startDate.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return nil, errors.NewValidationError("delivery slots cannot be planned for past days")
+	}
+	if err := validateDeliveryWindows(req.Windows); err != nil {
+		return nil, err
+	}
+
+	var slots []*models.DeliverySlot
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		for _, window := range req.Windows {
+			slots = append(slots, &models.DeliverySlot{
+				Region:    region,
+				Date:      day,
+				StartTime: window.Start,
+				EndTime:   window.End,
+				Capacity:  window.Capacity,
+				IsActive:  true,
+			})
+		}
+	}
+
+	if err := s.slotRepo.Plan(ctx, slots); err != nil {
+		s.logger.Error("Failed to plan delivery slots", "error", err, "region", region)
+		return nil, err
+	}
+
+	s.logger.Info("Delivery slots planned successfully", "region", region, "slots", len(slots))
+	return s.listSlots(ctx, repository.DeliverySlotFilter{Region: region, From: startDate, To: endDate})
+}
+
+func (s *deliverySlotService) UpdateSlot(ctx context.Context, id string, req UpdateDeliverySlotRequest) (*DeliverySlotResponse, error) {
+	s.logger.Info("Updating delivery slot", "id", id)
+
+	slot, err := s.slotRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get delivery slot", "error", err, "id", id)
+		return nil, err
+	}
+	if slot == nil {
+		return nil, errors.NewNotFoundErrorWithID("delivery slot", id)
+	}
+
+	capacity, isActive := slot.Capacity, slot.IsActive
+	if req.Capacity != nil {
+		if *req.Capacity < 0 {
+			return nil, errors.NewValidationError("capacity cannot be negative")
+		}
+		capacity = *req.Capacity
+	}
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	updated, err := s.slotRepo.UpdateCapacity(ctx, id, capacity, isActive)
+	if err != nil {
+		s.logger.Error("Failed to update delivery slot", "error", err, "id", id)
+		return nil, err
+	}
+	if !updated {
+		// Orders were booked into the slot since it was read
+		return nil, errors.NewConflictError(fmt.Sprintf("delivery slot %s has more orders booked than a capacity of %d", id, capacity))
+	}
+
+	slot, err = s.slotRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get delivery slot", "error", err, "id", id)
+		return nil, err
+	}
+	if slot == nil {
+		return nil, errors.NewNotFoundErrorWithID("delivery slot", id)
+	}
+
+	s.logger.Info("Delivery slot updated successfully", "id", id, "capacity", slot.Capacity, "reserved", slot.Reserved)
+	return toDeliverySlotResponse(slot), nil
+}
+
+func (s *deliverySlotService) ListSlots(ctx context.Context, req ListDeliverySlotsRequest) (*ListDeliverySlotsResponse, error) {
+	s.logger.Debug("Listing delivery slots", "region", req.Region, "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, err := parseDeliverySlotRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.listSlots(ctx, repository.DeliverySlotFilter{
+		Region: strings.TrimSpace(req.Region),
+		From:   startDate,
+		To:     endDate,
+	})
+}
+
+func (s *deliverySlotService) ListAvailableSlots(ctx context.Context, req ListDeliverySlotsRequest) (*ListDeliverySlotsResponse, error) {
+	s.logger.Debug("Listing available delivery slots", "region", req.Region, "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, err := parseDeliverySlotRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	// Days already gone cannot be booked
+	if today := time.Now().UTC().Truncate(24 * time.Hour); startDate.Before(today) {
+		startDate = today
+	}
+	if endDate.Before(startDate) {
+		return &ListDeliverySlotsResponse{
+			StartDate: startDate.Format("2006-01-02"),
+			EndDate:   endDate.Format("2006-01-02"),
+			Region:    strings.TrimSpace(req.Region),
+			Slots:     []*DeliverySlotResponse{},
+		}, nil
+	}
+
+	return s.listSlots(ctx, repository.DeliverySlotFilter{
+		Region:   strings.TrimSpace(req.Region),
+		From:     startDate,
+		To:       endDate,
+		Bookable: true,
+	})
+}
+
+func (s *deliverySlotService) listSlots(ctx context.Context, filter repository.DeliverySlotFilter) (*ListDeliverySlotsResponse, error) {
+	slots, err := s.slotRepo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list delivery slots", "error", err, "region", filter.Region)
+		return nil, err
+	}
+
+	response := &ListDeliverySlotsResponse{
+		StartDate: filter.From.Format("2006-01-02"),
+		EndDate:   filter.To.Format("2006-01-02"),
+		Region:    filter.Region,
+		Slots:     make([]*DeliverySlotResponse, len(slots)),
+	}
+	for i, slot := range slots {
+		response.Slots[i] = toDeliverySlotResponse(slot)
+		response.Capacity += slot.Capacity
+		response.Reserved += slot.Reserved
+	}
+	return response, nil
+}
+
+// parseDeliverySlotRange parses an inclusive range of days, defaulting to the next
+// defaultDeliverySlotDays days from today
+func parseDeliverySlotRange(startParam, endParam string) (startDate, endDate time.Time, err error) {
+	startDate = time.Now().UTC().Truncate(24 * time.Hour)
+	if startParam != "" {
+		if startDate, err = time.Parse("2006-01-02", startParam); err != nil {
+			return startDate, endDate, errors.NewValidationError("invalid date format, use YYYY-MM-DD")
+		}
+	}
+	endDate = startDate.AddDate(0, 0, defaultDeliverySlotDays-1)
+	if endParam != "" {
+		if endDate, err = time.Parse("2006-01-02", endParam); err != nil {
+			return startDate, endDate, errors.NewValidationError("invalid date format, use YYYY-MM-DD")
+		}
+	}
+	if endDate.Before(startDate) {
+		return startDate, endDate, errors.NewValidationError("invalid date range: end date is before start date")
+	}
+	if endDate.Sub(startDate) >= maxDeliverySlotDays*24*time.Hour {
+		return startDate, endDate, errors.NewValidationError(fmt.Sprintf("invalid date range: at most %d days", maxDeliverySlotDays))
+	}
+	return startDate, endDate, nil
+}
+
+// validateDeliveryWindows checks that each window is a valid HH:MM range and that
+// the windows of a day don't overlap
+func validateDeliveryWindows(windows []DeliveryWindow) error {
+	type span struct {
+		start, end time.Time
+		window     DeliveryWindow
+	}
+
+	spans := make([]span, len(windows))
+	for i, window := range windows {
+		start, err := time.Parse(deliveryWindowLayout, window.Start)
+		if err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid window start %q, use HH:MM", window.Start))
+		}
+		end, err := time.Parse(deliveryWindowLayout, window.End)
+		if err != nil {
+			return errors.NewValidationError(fmt.Sprintf("invalid window end %q, use HH:MM", window.End))
+		}
+		if !end.After(start) {
+			return errors.NewValidationError(fmt.Sprintf("window %s-%s must end after it starts", window.Start, window.End))
+		}
+		if window.Capacity < 0 {
+			return errors.NewValidationError(fmt.Sprintf("window %s-%s cannot have a negative capacity", window.Start, window.End))
+		}
+		spans[i] = span{start: start, end: end, window: window}
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start.Before(spans[i-1].end) {
+			return errors.NewValidationError(fmt.Sprintf("windows %s-%s and %s-%s overlap",
+				spans[i-1].window.Start, spans[i-1].window.End, spans[i].window.Start, spans[i].window.End))
+		}
+	}
+	return nil
+}
+
+func toDeliverySlotResponse(slot *models.DeliverySlot) *DeliverySlotResponse {
+	return &DeliverySlotResponse{
+		ID:        slot.ID,
+		Region:    slot.Region,
+		Date:      slot.Date.Format("2006-01-02"),
+		StartTime: slot.StartTime,
+		EndTime:   slot.EndTime,
+		Capacity:  slot.Capacity,
+		Reserved:  slot.Reserved,
+		Remaining: slot.Remaining(),
+		IsActive:  slot.IsActive,
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// DuplicateOrderSettings configures the duplicate order check. An order of the same
+// products and quantities as one the user placed within Window is a likely
+// duplicate; with RequireConfirmation it is refused until confirmed, otherwise it is
+// placed with a warning. A zero window turns the check off.
+type DuplicateOrderSettings struct {
+	Window              time.Duration
+	RequireConfirmation bool
+}
+
+// DuplicateOrderGuard catches orders that likely repeat a recent order of the same
+// user, such as a double-submitted checkout without an idempotency key. Concurrent
+// submissions can both pass the check; it is a heuristic, not a lock. A nil guard
+// checks nothing.
+type DuplicateOrderGuard struct {
+	settings  DuplicateOrderSettings
+	orderRepo repository.OrderRepository
+	logger    *logger.Logger
+
+	mutex   sync.Mutex
+	metrics DuplicateOrderMetrics
+}
+
+// NewDuplicateOrderGuard creates a duplicate order guard
+func NewDuplicateOrderGuard(settings DuplicateOrderSettings, orderRepo repository.OrderRepository, logger *logger.Logger) *DuplicateOrderGuard {
+	return &DuplicateOrderGuard{
+		settings:  settings,
+		orderRepo: orderRepo,
+		logger:    logger,
+	}
+}
+
+// Check looks for an order of the user placed within the window with the same items,
+// returning a warning about it, or nil when there is none. An unconfirmed duplicate is
+// refused with a conflict error while confirmation is required.
+func (g *DuplicateOrderGuard) Check(ctx context.Context, userID string, items []OrderItem, confirmed bool) (*DuplicateOrderWarning, error) {
+	if g == nil || g.settings.Window <= 0 {
+		return nil, nil
+	}
+
+	orders, err := g.orderRepo.ListRecentByUser(ctx, userID, time.Now().Add(-g.settings.Window))
+	if err != nil {
+		g.logger.Error("Failed to list recent orders for duplicate check", "error", err, "user_id", userID)
+		return nil, errors.NewDatabaseError("failed to check for duplicate orders", err)
+	}
+
+	requested := orderItemQuantities(items)
+	for _, order := range orders {
+		placed := make([]OrderItem, len(order.Items))
+		for i, item := range order.Items {
+			placed[i] = OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+		}
+		if !sameQuantities(requested, orderItemQuantities(placed)) {
+			continue
+		}
+
+		warning := &DuplicateOrderWarning{
+			OrderID:  order.ID,
+			PlacedAt: order.CreatedAt,
+			Message:  fmt.Sprintf("order has the same items as order %s placed at %s", order.ID, order.CreatedAt.UTC().Format(time.RFC3339)),
+		}
+
+		switch {
+		case confirmed:
+			g.record(func(m *DuplicateOrderMetrics) { m.Confirmed++ })
+			g.logger.Info("Likely duplicate order confirmed", "user_id", userID, "duplicate_of", order.ID)
+		case g.settings.RequireConfirmation:
+			g.record(func(m *DuplicateOrderMetrics) { m.Prevented++ })
+			g.logger.Warn("Likely duplicate order prevented", "user_id", userID, "duplicate_of", order.ID)
+			return nil, errors.NewConflictError(fmt.Sprintf("possible duplicate order: %s; set confirm_duplicate to place it anyway", warning.Message)).
+				WithContext("duplicate_of", order.ID)
+		default:
+			g.record(func(m *DuplicateOrderMetrics) { m.Warned++ })
+			g.logger.Warn("Likely duplicate order placed with a warning", "user_id", userID, "duplicate_of", order.ID)
+		}
+		return warning, nil
+	}
+
+	g.record(func(m *DuplicateOrderMetrics) {})
+	return nil, nil
+}
+
+// Metrics returns the outcomes of the duplicate checks since startup
+func (g *DuplicateOrderGuard) Metrics() DuplicateOrderMetrics {
+	if g == nil {
+		return DuplicateOrderMetrics{}
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	metrics := g.metrics
+	metrics.Detected = metrics.Warned + metrics.Prevented + metrics.Confirmed
+	metrics.WindowSeconds = int64(g.settings.Window / time.Second)
+	metrics.RequireConfirmation = g.settings.RequireConfirmation
+	return metrics
+}
+
+// record counts a check and applies the update for its outcome under the lock
+func (g *DuplicateOrderGuard) record(outcome func(m *DuplicateOrderMetrics)) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.metrics.Checked++
+	outcome(&g.metrics)
+}
+
+// orderItemQuantities sums the quantities of items by product
+func orderItemQuantities(items []OrderItem) map[string]int {
+	quantities := make(map[string]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
+}
+
+// sameQuantities reports whether two orders have the same quantity of each product
+func sameQuantities(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for productID, quantity := range a {
+		if b[productID] != quantity {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/webhooks"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// orderEventRelay names the relay cursor of the order event outbox
+	orderEventRelay = "order_events"
+	// orderEventRelayBatchSize bounds how many order events are read at a time
+	orderEventRelayBatchSize = 500
+	// webhookDeliveryBatchSize bounds how many deliveries are sent per sweep
+	webhookDeliveryBatchSize = 20
+)
+
+// webhookEventNamespace derives event IDs from what the event is about, so the same
+// event queued twice for an endpoint is delivered once
+var webhookEventNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("easy-orders-backend/webhooks"))
+
+// orderStatusWebhookTopics maps order statuses to the topic of changes into them
+var orderStatusWebhookTopics = map[models.OrderStatus]models.WebhookTopic{
+	models.OrderStatusPaid:      models.WebhookTopicOrderPaid,
+	models.OrderStatusShipped:   models.WebhookTopicOrderShipped,
+	models.OrderStatusDelivered: models.WebhookTopicOrderDelivered,
+	models.OrderStatusCancelled: models.WebhookTopicOrderCancelled,
+}
+
+// EventWebhookSettings configures outbound webhook delivery. SettleDelay holds back
+// order events until their transactions have committed, like change feeds.
+// Deliveries are claimed for Lease while they are sent.
+type EventWebhookSettings struct {
+	Retry       webhooks.RetryPolicy
+	SettleDelay time.Duration
+	Lease       time.Duration
+}
+
+// eventWebhookService implements EventWebhookService interface
+type eventWebhookService struct {
+	settings       EventWebhookSettings
+	endpointRepo   repository.WebhookEndpointRepository
+	deliveryRepo   repository.WebhookDeliveryRepository
+	orderEventRepo repository.OrderEventRepository
+	client         *webhooks.Client
+	now            func() time.Time
+	logger         *logger.Logger
+}
+
+// NewEventWebhookService creates a new event webhook service sending through client
+func NewEventWebhookService(
+	settings EventWebhookSettings,
+	endpointRepo repository.WebhookEndpointRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	orderEventRepo repository.OrderEventRepository,
+	client *webhooks.Client,
+	logger *logger.Logger,
+) EventWebhookService {
+	return &eventWebhookService{
+		settings:       settings,
+		endpointRepo:   endpointRepo,
+		deliveryRepo:   deliveryRepo,
+		orderEventRepo: orderEventRepo,
+		client:         client,
+		now:            time.Now,
+		logger:         logger,
+	}
+}
+
+func (s *eventWebhookService) CreateEndpoint(ctx context.Context, req CreateWebhookEndpointRequest) (*WebhookEndpointResponse, error) {
+	s.logger.Info("Creating webhook endpoint", "name", req.Name, "url", req.URL, "topics", req.Topics)
+
+	if req.Name == "" || req.URL == "" {
+		return nil, errors.NewValidationError("endpoint name and URL are required")
+	}
+	if len(req.Secret) < 16 {
+		return nil, errors.NewValidationError("endpoint secret must be at least 16 characters")
+	}
+	topics, err := webhookTopicList(req.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		Name:     req.Name,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Topics:   topics,
+		IsActive: true,
+	}
+
+	if err := s.endpointRepo.Create(ctx, endpoint); err != nil {
+		s.logger.Error("Failed to create webhook endpoint", "error", err, "name", req.Name)
+		return nil, err
+	}
+
+	return newWebhookEndpointResponse(endpoint), nil
+}
+
+func (s *eventWebhookService) ListEndpoints(ctx context.Context) ([]*WebhookEndpointResponse, error) {
+	s.logger.Debug("Listing webhook endpoints")
+
+	endpoints, err := s.endpointRepo.List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list webhook endpoints", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*WebhookEndpointResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		responses[i] = newWebhookEndpointResponse(endpoint)
+	}
+
+	return responses, nil
+}
+
+func (s *eventWebhookService) UpdateEndpoint(ctx context.Context, id string, req UpdateWebhookEndpointRequest) (*WebhookEndpointResponse, error) {
+	s.logger.Info("Updating webhook endpoint", "id", id)
+
+	endpoint, err := s.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, errors.NewValidationError("endpoint name cannot be empty")
+		}
+		endpoint.Name = *req.Name
+	}
+	if req.URL != nil {
+		if *req.URL == "" {
+			return nil, errors.NewValidationError("endpoint URL cannot be empty")
+		}
+		endpoint.URL = *req.URL
+	}
+	if req.Secret != nil {
+		if len(*req.Secret) < 16 {
+			return nil, errors.NewValidationError("endpoint secret must be at least 16 characters")
+		}
+		endpoint.Secret = *req.Secret
+	}
+	if req.Topics != nil {
+		topics, err := webhookTopicList(req.Topics)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Topics = topics
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := s.endpointRepo.Update(ctx, endpoint); err != nil {
+		s.logger.Error("Failed to update webhook endpoint", "error", err, "id", id)
+		return nil, err
+	}
+
+	return newWebhookEndpointResponse(endpoint), nil
+}
+
+func (s *eventWebhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	s.logger.Info("Deleting webhook endpoint", "id", id)
+
+	if _, err := s.getEndpoint(ctx, id); err != nil {
+		return err
+	}
+
+	return s.endpointRepo.Delete(ctx, id)
+}
+
+func (s *eventWebhookService) ListDeliveries(ctx context.Context, req ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	s.logger.Debug("Listing webhook deliveries", "page", req.Page, "limit", req.Limit, "endpoint_id", req.EndpointID, "status", req.Status)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	filter := repository.WebhookDeliveryFilter{
+		EndpointID: req.EndpointID,
+		Topic:      models.WebhookTopic(req.Topic),
+		Status:     models.WebhookDeliveryStatus(req.Status),
+	}
+
+	deliveries, err := s.deliveryRepo.List(ctx, filter, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	total, err := s.deliveryRepo.Count(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	response := &ListWebhookDeliveriesResponse{
+		Deliveries: make([]*WebhookDeliveryResponse, len(deliveries)),
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+	}
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = newWebhookDeliveryResponse(delivery)
+	}
+
+	return response, nil
+}
+
+// Redeliver queues a failed delivery to be sent right away, with a fresh set of
+// retries. The event keeps its ID, so endpoints that did process it can drop it.
+func (s *eventWebhookService) Redeliver(ctx context.Context, id string) (*WebhookDeliveryResponse, error) {
+	s.logger.Info("Redelivering webhook delivery", "id", id)
+
+	if id == "" {
+		return nil, errors.NewValidationError("delivery ID is required")
+	}
+
+	delivery, err := s.deliveryRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get webhook delivery", "error", err, "id", id)
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, errors.NewNotFoundErrorWithID("webhook delivery", id)
+	}
+	if !delivery.CanRedeliver() {
+		return nil, errors.NewConflictError(fmt.Sprintf("webhook delivery in status %s cannot be redelivered", delivery.Status))
+	}
+
+	endpoint, err := s.endpointRepo.GetByID(ctx, delivery.EndpointID)
+	if err != nil {
+		s.logger.Error("Failed to get webhook endpoint", "error", err, "id", delivery.EndpointID)
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, errors.NewConflictError(fmt.Sprintf("webhook endpoint %s was deleted", delivery.EndpointID))
+	}
+
+	now := s.now()
+	requeued, err := s.deliveryRepo.Requeue(ctx, id, now)
+	if err != nil {
+		s.logger.Error("Failed to requeue webhook delivery", "error", err, "id", id)
+		return nil, err
+	}
+	if !requeued {
+		return nil, errors.NewConflictError(fmt.Sprintf("webhook delivery %s is no longer failed", id))
+	}
+
+	delivery.Status = models.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	return newWebhookDeliveryResponse(delivery), nil
+}
+
+// PublishPaymentFailed queues a payment.failed event for subscribed endpoints.
+// Publishing is best-effort and never fails the payment flow.
+func (s *eventWebhookService) PublishPaymentFailed(ctx context.Context, payment *models.Payment) {
+	data, err := json.Marshal(PaymentFailedWebhookData{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Method:        payment.Method,
+		Gateway:       payment.Gateway,
+		FailureReason: payment.FailureReason,
+		AttemptCount:  payment.AttemptCount,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to encode payment.failed webhook", "error", err, "payment_id", payment.ID)
+		return
+	}
+
+	endpoints, err := s.endpointRepo.ListSubscribed(ctx, models.WebhookTopicPaymentFailed)
+	if err != nil {
+		s.logger.Warn("Failed to load webhook endpoints", "error", err, "topic", models.WebhookTopicPaymentFailed)
+		return
+	}
+
+	eventID := webhookEventID("payment.failed", payment.ID, strconv.Itoa(payment.AttemptCount))
+	deliveries := s.newDeliveries(endpoints, eventID, models.WebhookTopicPaymentFailed, data, s.now())
+	if err := s.deliveryRepo.Enqueue(ctx, deliveries); err != nil {
+		s.logger.Warn("Failed to queue payment.failed webhooks", "error", err, "payment_id", payment.ID)
+		return
+	}
+
+	s.logger.Debug("Queued payment.failed webhooks", "payment_id", payment.ID, "endpoints", len(deliveries))
+}
+
+// RelayOrderEvents reads the order event outbox from where the last run stopped and
+// queues a delivery of each event with a webhook topic to the endpoints subscribed
+// to it. Deliveries are queued and the cursor moved in one transaction, so every
+// event is queued once however runs are interrupted.
+func (s *eventWebhookService) RelayOrderEvents(ctx context.Context) (int, error) {
+	sequence, err := s.deliveryRepo.GetRelayCursor(ctx, orderEventRelay)
+	if err != nil {
+		return 0, err
+	}
+
+	relayed := 0
+	subscribed := make(map[models.WebhookTopic][]*models.WebhookEndpoint)
+	for {
+		events, err := s.orderEventRepo.ListSince(ctx, sequence, s.settings.SettleDelay, orderEventRelayBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to list order events for webhooks", "error", err, "since", sequence)
+			return relayed, err
+		}
+		if len(events) == 0 {
+			return relayed, nil
+		}
+
+		now := s.now()
+		var deliveries []*models.WebhookDelivery
+		for _, event := range events {
+			topic, ok := orderEventWebhookTopic(event)
+			if !ok {
+				continue
+			}
+
+			endpoints, loaded := subscribed[topic]
+			if !loaded {
+				endpoints, err = s.endpointRepo.ListSubscribed(ctx, topic)
+				if err != nil {
+					s.logger.Error("Failed to load webhook endpoints", "error", err, "topic", topic)
+					return relayed, err
+				}
+				subscribed[topic] = endpoints
+			}
+
+			// Endpoints receive the events recorded after they were registered
+			var receiving []*models.WebhookEndpoint
+			for _, endpoint := range endpoints {
+				if !event.RecordedAt.Before(endpoint.CreatedAt) {
+					receiving = append(receiving, endpoint)
+				}
+			}
+
+			eventID := webhookEventID("order_event", strconv.FormatInt(event.Sequence, 10))
+			queued := s.newDeliveries(receiving, eventID, topic, event.Payload, now)
+			for _, delivery := range queued {
+				delivery.OccurredAt = event.RecordedAt
+			}
+			deliveries = append(deliveries, queued...)
+		}
+
+		last := events[len(events)-1].Sequence
+		if err := s.deliveryRepo.EnqueueRelayed(ctx, orderEventRelay, last, deliveries); err != nil {
+			s.logger.Error("Failed to queue order event webhooks", "error", err, "sequence", last)
+			return relayed, err
+		}
+
+		relayed += len(events)
+		sequence = last
+		if len(deliveries) > 0 {
+			s.logger.Info("Queued order event webhooks", "deliveries", len(deliveries), "through_sequence", last)
+		}
+		if len(events) < orderEventRelayBatchSize {
+			return relayed, nil
+		}
+	}
+}
+
+// DeliverDue sends the pending deliveries that are due, concurrently. A delivery the
+// endpoint rejects with a client error, or that fails its last attempt, is marked
+// failed; other failures are retried after a backoff that doubles with every attempt.
+func (s *eventWebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.deliveryRepo.ClaimDue(ctx, s.now(), s.settings.Lease, webhookDeliveryBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to claim due webhook deliveries", "error", err)
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliver makes one attempt to send the delivery and records its outcome
+func (s *eventWebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	endpoint := delivery.Endpoint
+	if endpoint == nil {
+		return
+	}
+
+	result := s.client.Send(ctx, endpoint.URL, endpoint.Secret, webhooks.Event{
+		ID:         delivery.EventID,
+		Type:       string(delivery.Topic),
+		OccurredAt: delivery.OccurredAt,
+		Data:       delivery.Payload,
+	})
+
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = result.StatusCode
+	delivery.LastResponse = result.Response
+	delivery.LastError = ""
+
+	switch {
+	case result.Delivered():
+		delivery.Status = models.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		s.logger.Info("Webhook delivered", "delivery_id", delivery.ID, "endpoint_id", endpoint.ID,
+			"topic", delivery.Topic, "attempts", delivery.Attempts, "duration", result.Duration)
+	case result.Retryable() && !s.settings.Retry.Exhausted(delivery.Attempts):
+		nextAttemptAt := now.Add(s.settings.Retry.Backoff(delivery.Attempts))
+		delivery.LastError = result.Err.Error()
+		delivery.NextAttemptAt = &nextAttemptAt
+		s.logger.Warn("Webhook delivery failed, will retry", "error", result.Err, "delivery_id", delivery.ID,
+			"endpoint_id", endpoint.ID, "attempts", delivery.Attempts, "next_attempt_at", nextAttemptAt)
+	default:
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		delivery.LastError = result.Err.Error()
+		delivery.NextAttemptAt = nil
+		s.logger.Error("Webhook delivery failed", "error", result.Err, "delivery_id", delivery.ID,
+			"endpoint_id", endpoint.ID, "topic", delivery.Topic, "attempts", delivery.Attempts)
+	}
+
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery); err != nil {
+		s.logger.Error("Failed to record webhook delivery attempt", "error", err, "delivery_id", delivery.ID)
+	}
+}
+
+// newDeliveries returns the pending deliveries of an event to endpoints, due at now
+func (s *eventWebhookService) newDeliveries(endpoints []*models.WebhookEndpoint, eventID string, topic models.WebhookTopic, payload json.RawMessage, now time.Time) []*models.WebhookDelivery {
+	deliveries := make([]*models.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			Topic:         topic,
+			Payload:       payload,
+			OccurredAt:    now,
+			Status:        models.WebhookDeliveryStatusPending,
+			NextAttemptAt: &now,
+		})
+	}
+	return deliveries
+}
+
+// getEndpoint returns an existing endpoint
+func (s *eventWebhookService) getEndpoint(ctx context.Context, id string) (*models.WebhookEndpoint, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("endpoint ID is required")
+	}
+
+	endpoint, err := s.endpointRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get webhook endpoint", "error", err, "id", id)
+		return nil, err
+	}
+	if endpoint == nil {
+		return nil, errors.NewNotFoundErrorWithID("webhook endpoint", id)
+	}
+
+	return endpoint, nil
+}
+
+// orderEventWebhookTopic returns the webhook topic of an order event, if it has one
+func orderEventWebhookTopic(event *models.OrderEvent) (models.WebhookTopic, bool) {
+	switch event.Type {
+	case models.OrderEventCreated:
+		return models.WebhookTopicOrderCreated, true
+	case models.OrderEventStatusChanged:
+		var payload models.OrderEventPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return "", false
+		}
+		topic, ok := orderStatusWebhookTopics[payload.Status]
+		return topic, ok
+	case models.OrderEventPaymentCompleted:
+		return models.WebhookTopicOrderPaymentCompleted, true
+	}
+	return "", false
+}
+
+// webhookEventID derives the ID of the event identified by parts
+func webhookEventID(parts ...string) string {
+	return uuid.NewSHA1(webhookEventNamespace, []byte(strings.Join(parts, ":"))).String()
+}
+
+// webhookTopicList validates topics and removes duplicates
+func webhookTopicList(topics []models.WebhookTopic) (models.StringList, error) {
+	if len(topics) == 0 {
+		return nil, errors.NewValidationError("at least one topic is required")
+	}
+
+	list := make(models.StringList, 0, len(topics))
+	for _, topic := range topics {
+		if !topic.IsValid() {
+			return nil, errors.NewValidationError(fmt.Sprintf("unknown webhook topic %s", topic))
+		}
+		if !list.Contains(string(topic)) {
+			list = append(list, string(topic))
+		}
+	}
+	return list, nil
+}
+
+// newWebhookEndpointResponse converts an endpoint model to its response DTO
+func newWebhookEndpointResponse(endpoint *models.WebhookEndpoint) *WebhookEndpointResponse {
+	topics := make([]models.WebhookTopic, len(endpoint.Topics))
+	for i, topic := range endpoint.Topics {
+		topics[i] = models.WebhookTopic(topic)
+	}
+
+	return &WebhookEndpointResponse{
+		ID:        endpoint.ID,
+		Name:      endpoint.Name,
+		URL:       endpoint.URL,
+		Topics:    topics,
+		IsActive:  endpoint.IsActive,
+		CreatedAt: endpoint.CreatedAt,
+		UpdatedAt: endpoint.UpdatedAt,
+	}
+}
+
+// newWebhookDeliveryResponse converts a delivery model to its response DTO
+func newWebhookDeliveryResponse(delivery *models.WebhookDelivery) *WebhookDeliveryResponse {
+	return &WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EndpointID:     delivery.EndpointID,
+		EventID:        delivery.EventID,
+		Topic:          delivery.Topic,
+		Payload:        delivery.Payload,
+		OccurredAt:     delivery.OccurredAt,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastResponse:   delivery.LastResponse,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+// publishPaymentFailed tells the webhook publisher about a failed payment when one is configured
+func publishPaymentFailed(ctx context.Context, publisher WebhookPublisher, payment *models.Payment) {
+	if publisher == nil {
+		return
+	}
+	publisher.PublishPaymentFailed(ctx, payment)
+}
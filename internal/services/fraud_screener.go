@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
+)
+
+// FraudCheckpoint is the point of the order flow an attempt is screened at
+type FraudCheckpoint string
+
+const (
+	FraudCheckpointOrder   FraudCheckpoint = "order"
+	FraudCheckpointPayment FraudCheckpoint = "payment"
+)
+
+// FraudSubject is who attempts an order or payment, and from where. Email is looked
+// up from UserID when empty; empty values are not screened.
+type FraudSubject struct {
+	UserID          string
+	Email           string
+	IPAddress       string
+	CardFingerprint string
+}
+
+// blockedAttemptKey counts the attempts blocked at a checkpoint by entries of a type
+type blockedAttemptKey struct {
+	checkpoint FraudCheckpoint
+	entryType  models.BlocklistType
+}
+
+// FraudScreener checks order and payment attempts against the blocklist in the fraud
+// screening stage, and counts the attempts it screened and blocked since startup. A
+// nil screener lets every attempt through.
+type FraudScreener struct {
+	blocklistRepo repository.BlocklistRepository
+	userRepo      repository.UserRepository
+	stages        *metrics.StageRecorder
+	now           func() time.Time
+	logger        *logger.Logger
+
+	mutex    sync.Mutex
+	since    time.Time
+	screened map[FraudCheckpoint]int64
+	blocked  map[FraudCheckpoint]int64
+	byType   map[blockedAttemptKey]int64
+}
+
+// NewFraudScreener creates a fraud screener backed by the blocklist
+func NewFraudScreener(
+	blocklistRepo repository.BlocklistRepository,
+	userRepo repository.UserRepository,
+	stages *metrics.StageRecorder,
+	logger *logger.Logger,
+) *FraudScreener {
+	return &FraudScreener{
+		blocklistRepo: blocklistRepo,
+		userRepo:      userRepo,
+		stages:        stages,
+		now:           time.Now,
+		logger:        logger,
+		since:         time.Now(),
+		screened:      make(map[FraudCheckpoint]int64),
+		blocked:       make(map[FraudCheckpoint]int64),
+		byType:        make(map[blockedAttemptKey]int64),
+	}
+}
+
+// Screen returns a forbidden error if any of the subject's values is blocklisted.
+// Which entry matched is logged but not returned, so the error tells an abuser
+// nothing about the blocklist.
+func (f *FraudScreener) Screen(ctx context.Context, checkpoint FraudCheckpoint, subject FraudSubject) error {
+	if f == nil {
+		return nil
+	}
+
+	start := time.Now()
+	entries, err := f.match(ctx, subject)
+	f.stages.Observe(StageFraudScreening, time.Since(start), err != nil || len(entries) > 0)
+	if err != nil {
+		return err
+	}
+
+	f.count(checkpoint, entries)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	// Hit counts only feed the metrics, so failing to record them doesn't unblock the attempt
+	if err := f.blocklistRepo.RecordHits(ctx, ids, f.now()); err != nil {
+		f.logger.Warn("Failed to record blocklist hits", "error", err, "entries", ids)
+	}
+
+	f.logger.Warn("Attempt blocked by fraud screening", "checkpoint", checkpoint, "user_id", subject.UserID, "entries", ids)
+	return errors.NewForbiddenError(fmt.Sprintf("%s blocked by fraud screening", checkpoint))
+}
+
+// match returns the active blocklist entries matching the subject
+func (f *FraudScreener) match(ctx context.Context, subject FraudSubject) ([]*models.BlocklistEntry, error) {
+	email := subject.Email
+	if email == "" && subject.UserID != "" {
+		user, err := f.userRepo.GetByID(ctx, subject.UserID)
+		if err != nil {
+			return nil, errors.NewDatabaseError("failed to get user for fraud screening", err)
+		}
+		if user != nil {
+			email = user.Email
+		}
+	}
+
+	var match repository.BlocklistMatch
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		match.Emails = []string{email}
+		if at := strings.LastIndex(email, "@"); at >= 0 {
+			match.Domains = parentDomains(email[at+1:])
+		}
+	}
+	if fingerprint := strings.TrimSpace(subject.CardFingerprint); fingerprint != "" {
+		match.CardFingerprints = []string{fingerprint}
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(subject.IPAddress)); err == nil {
+		match.IPAddress = addr.Unmap().String()
+	}
+
+	entries, err := f.blocklistRepo.FindMatches(ctx, match, f.now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to check blocklist", err)
+	}
+	return entries, nil
+}
+
+// count records a screened attempt and the entry types that blocked it
+func (f *FraudScreener) count(checkpoint FraudCheckpoint, entries []*models.BlocklistEntry) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.screened[checkpoint]++
+	if len(entries) == 0 {
+		return
+	}
+	f.blocked[checkpoint]++
+
+	types := make(map[models.BlocklistType]bool)
+	for _, entry := range entries {
+		if !types[entry.Type] {
+			types[entry.Type] = true
+			f.byType[blockedAttemptKey{checkpoint: checkpoint, entryType: entry.Type}]++
+		}
+	}
+}
+
+// Metrics returns the attempts screened and blocked at each checkpoint since startup
+func (f *FraudScreener) Metrics() (time.Time, []FraudCheckpointMetrics) {
+	if f == nil {
+		return time.Time{}, []FraudCheckpointMetrics{}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	checkpoints := make([]FraudCheckpointMetrics, 0, len(f.screened))
+	for checkpoint, screened := range f.screened {
+		metrics := FraudCheckpointMetrics{
+			Checkpoint: checkpoint,
+			Screened:   screened,
+			Blocked:    f.blocked[checkpoint],
+			ByType:     make(map[models.BlocklistType]int64),
+		}
+		if screened > 0 {
+			metrics.BlockRate = roundCents(float64(metrics.Blocked) / float64(screened) * 100)
+		}
+		for key, blocked := range f.byType {
+			if key.checkpoint == checkpoint {
+				metrics.ByType[key.entryType] = blocked
+			}
+		}
+		checkpoints = append(checkpoints, metrics)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Checkpoint < checkpoints[j].Checkpoint
+	})
+
+	return f.since, checkpoints
+}
+
+// normalizeBlocklistValue returns the form a value is listed and matched in: email
+// addresses and domains in lower case, and IP ranges as masked CIDR prefixes, where a
+// single address is a one-address range
+func normalizeBlocklistValue(entryType models.BlocklistType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("value is required")
+	}
+
+	switch entryType {
+	case models.BlocklistTypeEmail:
+		value = strings.ToLower(value)
+		at := strings.LastIndex(value, "@")
+		if at <= 0 || at == len(value)-1 || strings.ContainsAny(value, " \t") {
+			return "", fmt.Errorf("invalid email address %q", value)
+		}
+		return value, nil
+
+	case models.BlocklistTypeDomain:
+		value = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(value), "@"), "*.")
+		value = strings.TrimSuffix(value, ".")
+		if !strings.Contains(value, ".") || strings.ContainsAny(value, "@/ \t") ||
+			strings.HasPrefix(value, ".") || strings.Contains(value, "..") {
+			return "", fmt.Errorf("invalid domain %q", value)
+		}
+		return value, nil
+
+	case models.BlocklistTypeCardFingerprint:
+		return value, nil
+
+	case models.BlocklistTypeIPRange:
+		if addr, err := netip.ParseAddr(value); err == nil {
+			addr = addr.Unmap()
+			return netip.PrefixFrom(addr, addr.BitLen()).String(), nil
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid IP range %q, use an address or CIDR notation", value)
+		}
+		return prefix.Masked().String(), nil
+	}
+
+	return "", fmt.Errorf("invalid blocklist type %s", entryType)
+}
+
+// parentDomains returns the domain and each of its parent domains, so that blocking
+// a domain also blocks its subdomains
+func parentDomains(domain string) []string {
+	domain = strings.TrimSuffix(domain, ".")
+	var domains []string
+	for strings.Contains(domain, ".") {
+		domains = append(domains, domain)
+		domain = domain[strings.Index(domain, ".")+1:]
+	}
+	return domains
+}
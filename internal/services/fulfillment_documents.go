@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"easy-orders-backend/pkg/documents"
+)
+
+// fulfillmentTimeLayout is how times are printed on pick lists and packing slips
+const fulfillmentTimeLayout = "2006-01-02 15:04 MST"
+
+// Document lays out the pick list for printing, one row per product in picking order
+func (p *PickListResponse) Document() *documents.Document {
+	doc := &documents.Document{
+		Title: "Pick List",
+		Details: []documents.Field{
+			{Label: "Generated", Value: p.GeneratedAt.Format(fulfillmentTimeLayout)},
+			{Label: "Orders", Value: strconv.Itoa(len(p.OrderIDs))},
+		},
+		Table: documents.Table{
+			Columns: []string{"Location", "SKU", "Product", "Qty", "Orders"},
+			Rows:    make([][]string, len(p.Lines)),
+			Footer:  []string{"", "", "Total units", strconv.Itoa(p.TotalUnits), ""},
+		},
+	}
+
+	for i, line := range p.Lines {
+		orders := make([]string, len(line.Orders))
+		for j, order := range line.Orders {
+			orders[j] = fmt.Sprintf("%s x%d", shortOrderID(order.OrderID), order.Quantity)
+		}
+		doc.Table.Rows[i] = []string{
+			valueOr(line.Location, "-"),
+			line.SKU,
+			line.Name,
+			strconv.Itoa(line.Quantity),
+			strings.Join(orders, ", "),
+		}
+	}
+
+	return doc
+}
+
+// Document lays out the packing slip for printing. Prices are left out when hidden,
+// and a gift message closes the slip.
+func (s *PackingSlipResponse) Document() *documents.Document {
+	doc := &documents.Document{
+		Title: "Packing Slip",
+		Details: []documents.Field{
+			{Label: "Order", Value: s.OrderID},
+			{Label: "Placed", Value: s.PlacedAt.Format(fulfillmentTimeLayout)},
+		},
+	}
+	if s.ShippingAddress != nil {
+		doc.Details = append(doc.Details, documents.Field{Label: "Ship to", Value: formatShippingAddress(s)})
+	}
+
+	if s.PricesHidden {
+		doc.Table.Columns = []string{"SKU", "Product", "Qty"}
+	} else {
+		doc.Table.Columns = []string{"SKU", "Product", "Qty", "Unit price", "Total"}
+	}
+	doc.Table.Rows = make([][]string, len(s.Items))
+	for i, item := range s.Items {
+		row := []string{item.SKU, item.Name, strconv.Itoa(item.Quantity)}
+		if !s.PricesHidden {
+			row = append(row, formatPrice(item.UnitPrice, s.Currency), formatPrice(item.TotalPrice, s.Currency))
+		}
+		doc.Table.Rows[i] = row
+	}
+	if !s.PricesHidden {
+		doc.Table.Footer = []string{"", "", "", "Order total", formatPrice(s.Total, s.Currency)}
+	}
+
+	if s.Gift && s.GiftMessage != "" {
+		doc.Notes = append(doc.Notes, "Gift message:\n"+s.GiftMessage)
+	}
+
+	return doc
+}
+
+// formatShippingAddress prints the address a packing slip ships to on one line
+func formatShippingAddress(s *PackingSlipResponse) string {
+	address := s.ShippingAddress
+	parts := []string{address.Recipient, address.Line1, address.Line2, address.City, address.Region, address.PostalCode, address.Country}
+	filled := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			filled = append(filled, part)
+		}
+	}
+	return strings.Join(filled, ", ")
+}
+
+// formatPrice prints an amount in the currency, or nothing when there is no amount
+func formatPrice(amount *float64, currency string) string {
+	if amount == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", *amount, currency))
+}
+
+// shortOrderID is the first block of an order's UUID, enough to tell orders of one
+// pick list apart
+func shortOrderID(id string) string {
+	if i := strings.IndexByte(id, '-'); i > 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// valueOr returns value, or fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/errors"
+)
+
+// giftMessageMaxLength is the longest gift message, in characters, that fits a gift card
+const giftMessageMaxLength = 500
+
+// GiftOptions knows which stores, identified by their order channel, offer gift
+// options. DefaultStore among the stores offers them in every store. Nil gift
+// options are offered nowhere.
+type GiftOptions struct {
+	stores map[string]bool
+}
+
+// NewGiftOptions creates the gift options of the given stores
+func NewGiftOptions(stores []string) *GiftOptions {
+	options := &GiftOptions{stores: make(map[string]bool, len(stores))}
+	for _, store := range stores {
+		options.stores[store] = true
+	}
+	return options
+}
+
+// Offered reports whether the store offers gift options
+func (g *GiftOptions) Offered(store string) bool {
+	if g == nil {
+		return false
+	}
+	return g.stores[store] || g.stores[DefaultStore]
+}
+
+// Check refuses gift options for an order of a store that does not offer them, or
+// with a message too long for a gift card. Orders without gift options pass.
+func (g *GiftOptions) Check(store string, gift *OrderGiftOptions) error {
+	if gift == nil {
+		return nil
+	}
+	if !g.Offered(store) {
+		return errors.NewBusinessError(fmt.Sprintf("store %s does not offer gift options", store))
+	}
+	if utf8.RuneCountInString(gift.Message) > giftMessageMaxLength {
+		return errors.NewValidationError(fmt.Sprintf("gift message is longer than %d characters", giftMessageMaxLength))
+	}
+	return nil
+}
+
+// applyGiftOptions marks the order as a gift with the requested options
+func applyGiftOptions(order *models.Order, gift *OrderGiftOptions) {
+	if gift == nil {
+		return
+	}
+	order.IsGift = true
+	order.GiftMessage = strings.TrimSpace(gift.Message)
+	order.GiftHidePrices = gift.HidePrices
+}
+
+// newOrderGiftOptions returns the gift options of a gift order, or nil for other orders
+func newOrderGiftOptions(order *models.Order) *OrderGiftOptions {
+	if !order.IsGift {
+		return nil
+	}
+	return &OrderGiftOptions{
+		Message:    order.GiftMessage,
+		HidePrices: order.GiftHidePrices,
+	}
+}
@@ -2,9 +2,13 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
 	"time"
 
 	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/payments"
 )
 
 // Service interfaces define business logic contracts
@@ -32,7 +36,12 @@ type OrderService interface {
 	GetOrder(ctx context.Context, id string) (*OrderResponse, error)
 	UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (*OrderResponse, error)
 	CancelOrder(ctx context.Context, id string) error
+	CancelOrderItems(ctx context.Context, id string, req CancelOrderItemsRequest) (*OrderResponse, error)
 	ListOrders(ctx context.Context, req ListOrdersRequest) (*ListOrdersResponse, error)
+	GetUserOrders(ctx context.Context, userID string, req ListUserOrdersRequest) (*ListOrdersResponse, error)
+	StreamOrders(ctx context.Context, req ListOrdersRequest, fn func(*OrderResponse) error) error
+	UpdateOrderMetadata(ctx context.Context, id string, req UpdateMetadataRequest) (*OrderResponse, error)
+	ImportOrders(ctx context.Context, r io.Reader) (*OrderImportResponse, error)
 }
 
 // InventoryService defines inventory business logic
@@ -41,6 +50,154 @@ type InventoryService interface {
 	ReserveInventory(ctx context.Context, items []InventoryItem) error
 	ReleaseInventory(ctx context.Context, items []InventoryItem) error
 	GetLowStockAlert(ctx context.Context, threshold int) (*LowStockResponse, error)
+	GetProductLowStockAlert(ctx context.Context) (*LowStockResponse, error)
+	ImportRecount(ctx context.Context, r io.Reader, actor AuditActor) (*InventoryRecountResponse, error)
+	Relocate(ctx context.Context, productID string, actor AuditActor, req RelocateInventoryRequest) (*InventoryLocationResponse, error)
+	GetRelocations(ctx context.Context, productID string) ([]*InventoryRelocationEntry, error)
+	// GetStockLevels returns a product's physical stock alongside the stock sellable online
+	GetStockLevels(ctx context.Context, productID string) (*InventoryStockLevelsResponse, error)
+	SetSafetyStock(ctx context.Context, productID string, actor AuditActor, req SetSafetyStockRequest) (*InventoryStockLevelsResponse, error)
+	GetHistory(ctx context.Context, productID string, req InventoryHistoryRequest) (*InventoryHistoryResponse, error)
+}
+
+// InventoryShardService splits the stock of hot products into shards, so their
+// concurrent orders reserve on different rows instead of queueing on one
+type InventoryShardService interface {
+	SetShards(ctx context.Context, productID string, actor AuditActor, req SetInventoryShardsRequest) (*InventoryShardsResponse, error)
+	GetShards(ctx context.Context, productID string) (*InventoryShardsResponse, error)
+	RebalanceShards(ctx context.Context) (int, error)
+}
+
+// StockImportService applies CSVs of stock adjustments, such as supplier deliveries, in
+// the background. An import is queued as chunks of rows on the worker pool and its
+// accepted and rejected rows are fetched once applied.
+type StockImportService interface {
+	ImportStock(ctx context.Context, r io.Reader, filename string, actor AuditActor) (*models.StockImport, error)
+	GetImport(ctx context.Context, id string, req StockImportRowsRequest) (*StockImportResponse, error)
+	// ApplyStockImportChunk applies the rows of an import between two rows; it is run
+	// by the worker pool
+	ApplyStockImportChunk(ctx context.Context, importID string, firstRow, lastRow int) error
+}
+
+// CartHoldService manages soft stock reservations for cart items. Holds expire
+// after the hold window and are converted when the user places an order.
+type CartHoldService interface {
+	HoldItem(ctx context.Context, userID string, req HoldCartItemRequest) (*CartHoldResponse, error)
+	ReleaseItem(ctx context.Context, userID, productID string) error
+	GetCartHolds(ctx context.Context, userID string) ([]*CartHoldResponse, error)
+	ReleaseExpiredHolds(ctx context.Context) (int, error)
+	GetConversionStats(ctx context.Context, req CartHoldStatsRequest) (*CartHoldStatsResponse, error)
+}
+
+// CheckoutService manages the customer's saved addresses and payment methods, and
+// express checkout, which places and pays an order with the defaults in one call
+type CheckoutService interface {
+	AddAddress(ctx context.Context, userID string, req CreateAddressRequest) (*AddressResponse, error)
+	ListAddresses(ctx context.Context, userID string) ([]*AddressResponse, error)
+	SetDefaultAddress(ctx context.Context, userID, id string) (*AddressResponse, error)
+	DeleteAddress(ctx context.Context, userID, id string) error
+	AddPaymentMethod(ctx context.Context, userID string, req CreateSavedPaymentMethodRequest) (*SavedPaymentMethodResponse, error)
+	ListPaymentMethods(ctx context.Context, userID string) ([]*SavedPaymentMethodResponse, error)
+	SetDefaultPaymentMethod(ctx context.Context, userID, id string) (*SavedPaymentMethodResponse, error)
+	DeletePaymentMethod(ctx context.Context, userID, id string) error
+	ExpressCheckout(ctx context.Context, userID string, req ExpressCheckoutRequest) (*ExpressCheckoutResponse, error)
+	// RecoverCheckouts resumes or compensates the express checkouts stopped mid-step
+	RecoverCheckouts(ctx context.Context) (int, error)
+}
+
+// ChangeFeedService pages through append-only change events for integrators that
+// sync incrementally instead of polling every record
+type ChangeFeedService interface {
+	InventoryChanges(ctx context.Context, req ChangeFeedRequest) (*InventoryChangesResponse, error)
+	OrderChanges(ctx context.Context, req ChangeFeedRequest) (*OrderChangesResponse, error)
+	// CatalogChanges pages through the changes to products' storefront pages, for
+	// caches and CDNs to invalidate
+	CatalogChanges(ctx context.Context, req ChangeFeedRequest) (*CatalogChangesResponse, error)
+}
+
+// CatalogPurgeRelay pushes catalog changes to the configured purge webhook
+type CatalogPurgeRelay interface {
+	// RelayCatalogEvents posts the catalog changes recorded since the last run and
+	// returns how many it posted
+	RelayCatalogEvents(ctx context.Context) (int, error)
+}
+
+// RetentionService purges records older than their retention policy allows and keeps
+// per-policy metrics of the purges
+type RetentionService interface {
+	Purge(ctx context.Context, dryRun bool) (*RetentionPurgeResponse, error)
+	GetMetrics() []RetentionPolicyMetrics
+}
+
+// PaymentReconciliationService checks stuck and failed payments against their
+// gateway's records, corrects the local status of those the gateway settled and flags
+// the disagreements left for an admin, reporting each run through the reports subsystem
+type PaymentReconciliationService interface {
+	Reconcile(ctx context.Context) (*PaymentReconciliationRunResponse, error)
+	// Poll checks the stuck payments of gateways without webhooks, escalating those
+	// stuck too long to ops
+	Poll(ctx context.Context) (*PaymentReconciliationRunResponse, error)
+}
+
+// PaymentRoutingService manages the rules routing payments by amount, currency and
+// method before a gateway is selected, and keeps the gateway manager evaluating the
+// active ones
+type PaymentRoutingService interface {
+	// LoadRules loads the active rules into the gateway manager
+	LoadRules(ctx context.Context) error
+	ListRules(ctx context.Context) ([]*models.PaymentRoutingRule, error)
+	GetRule(ctx context.Context, id string) (*models.PaymentRoutingRule, error)
+	CreateRule(ctx context.Context, req PaymentRoutingRuleRequest) (*models.PaymentRoutingRule, error)
+	UpdateRule(ctx context.Context, id string, req PaymentRoutingRuleRequest) (*models.PaymentRoutingRule, error)
+	DeleteRule(ctx context.Context, id string) error
+	// EvaluateRules routes a payment without processing it, by the active rules or the
+	// rules of the request
+	EvaluateRules(ctx context.Context, req EvaluatePaymentRoutingRequest) (*PaymentRoutingEvaluationResponse, error)
+}
+
+// OrderConfirmationService confirms paid orders and queues them for fulfillment, from
+// the order event outbox or by an admin for stores that confirm orders manually
+type OrderConfirmationService interface {
+	// ProcessOrderEvents applies the order events recorded since the last run and
+	// returns how many orders it confirmed
+	ProcessOrderEvents(ctx context.Context) (int, error)
+	ConfirmOrder(ctx context.Context, orderID string) (*OrderConfirmationResponse, error)
+	ListAwaitingConfirmation(ctx context.Context, req ListAwaitingConfirmationRequest) (*ListAwaitingConfirmationResponse, error)
+	ListFulfillmentQueue(ctx context.Context, req ListFulfillmentQueueRequest) (*ListFulfillmentQueueResponse, error)
+}
+
+// OrderNotificationRelay notifies customers of the order status changes recorded in the
+// order event outbox
+type OrderNotificationRelay interface {
+	// RelayOrderEvents notifies the status changes recorded since the last run and
+	// returns how many it notified
+	RelayOrderEvents(ctx context.Context) (int, error)
+}
+
+// DeadLetterService lets admins inspect the background jobs that ran out of retries,
+// such as payment retries that never succeed, and requeue or purge them
+type DeadLetterService interface {
+	ListDeadJobs(ctx context.Context, req ListDeadJobsRequest) (*ListDeadJobsResponse, error)
+	// GetDeadJob returns a dead job with its payload
+	GetDeadJob(ctx context.Context, id string) (*models.DeadJob, error)
+	// RequeueDeadJob queues a dead job to run right away with all of its retries
+	RequeueDeadJob(ctx context.Context, id string) (*RequeueDeadJobResponse, error)
+	DeleteDeadJob(ctx context.Context, id string) error
+	PurgeDeadJobs(ctx context.Context, req PurgeDeadJobsRequest) (*PurgeDeadJobsResponse, error)
+}
+
+// WorkerPoolService reports on the background worker pools and resizes them at runtime
+type WorkerPoolService interface {
+	GetStats(ctx context.Context) (*WorkerStatsResponse, error)
+	// Resize changes the number of workers of a pool without a restart. Workers removed
+	// finish the job they are running first.
+	Resize(ctx context.Context, req ResizeWorkerPoolRequest) (*WorkerPoolStats, error)
+}
+
+// SupportSearchService finds orders, users and payments for customer support from
+// the partial details customers give
+type SupportSearchService interface {
+	Search(ctx context.Context, req SupportSearchRequest) (*SupportSearchResponse, error)
 }
 
 // EnhancedInventoryService extends InventoryService with advanced concurrency features
@@ -82,17 +239,365 @@ type PaymentService interface {
 	ProcessPayment(ctx context.Context, req ProcessPaymentRequest) (*PaymentResponse, error)
 	GetPayment(ctx context.Context, id string) (*PaymentResponse, error)
 	GetOrderPayments(ctx context.Context, orderID string) ([]*PaymentResponse, error)
+	ResumePayment(ctx context.Context, id string) (*PaymentResponse, error)
+	RetryHeldPayments(ctx context.Context) (int, error)
+	RefundPayment(ctx context.Context, id string, req RefundRequest) (*PaymentResponse, error)
+	// ListRefunds returns the refunds of a payment, oldest first
+	ListRefunds(ctx context.Context, id string) (*PaymentRefundsResponse, error)
+}
+
+// PaymentIntentService takes card payments in two steps, so frontends can collect the
+// card with the gateway's client SDK in between: an intent is created for an order,
+// then confirmed with the gateway token, or cancelled. Unconfirmed intents expire.
+type PaymentIntentService interface {
+	CreateIntent(ctx context.Context, userID string, req CreatePaymentIntentRequest) (*PaymentIntentResponse, error)
+	GetIntent(ctx context.Context, userID, id string) (*PaymentIntentResponse, error)
+	ConfirmIntent(ctx context.Context, userID, id string, req ConfirmPaymentIntentRequest) (*PaymentIntentResponse, error)
+	CancelIntent(ctx context.Context, userID, id string) (*PaymentIntentResponse, error)
+	// SweepIntents settles processing intents whose payment completed or failed since,
+	// and expires lapsed ones. It returns how many intents it moved on.
+	SweepIntents(ctx context.Context) (int, error)
 }
 
 // NotificationService defines notification business logic
 type NotificationService interface {
 	SendNotification(ctx context.Context, req SendNotificationRequest) error
+	SendBatch(ctx context.Context, req SendBatchNotificationsRequest) (*BatchNotificationResponse, error)
 	GetUserNotifications(ctx context.Context, userID string, req ListNotificationsRequest) (*ListNotificationsResponse, error)
+	// MarkRead marks one of the user's notifications as read
+	MarkRead(ctx context.Context, userID, notificationID string) (*NotificationResponse, error)
+	// RecordReceipt records a delivery or read receipt reported by a channel provider
+	RecordReceipt(ctx context.Context, notificationID string, req NotificationReceiptRequest) (*NotificationResponse, error)
+}
+
+// NotificationVariantPicker words notifications by the wording experiment of their
+// type running or concluded in the user's locale, if there is one
+type NotificationVariantPicker interface {
+	ApplyVariant(ctx context.Context, notification *models.Notification, locale string)
+}
+
+// NotificationExperimentService defines A/B testing of notification wording
+type NotificationExperimentService interface {
+	NotificationVariantPicker
+	CreateExperiment(ctx context.Context, userID string, req CreateNotificationExperimentRequest) (*models.NotificationExperiment, error)
+	ListExperiments(ctx context.Context, req ListNotificationExperimentsRequest) ([]*models.NotificationExperiment, error)
+	// StopExperiment ends an experiment, concluding it when a winner is picked
+	StopExperiment(ctx context.Context, id string, req StopNotificationExperimentRequest) (*models.NotificationExperiment, error)
+	GetResults(ctx context.Context, id string) (*NotificationExperimentResultsResponse, error)
+}
+
+// WebhookService defines inbound webhook processing logic
+type WebhookService interface {
+	// ReceiveEvent verifies a partner's signed event and records it as an event of that
+	// partner. Payment events change payments, orders and the ledger, so they are
+	// refused here and only accepted from gateways, through ReceivePaymentWebhook.
+	ReceiveEvent(ctx context.Context, provider string, header http.Header, body []byte) (*WebhookEventResponse, error)
+	// ReceivePaymentWebhook verifies a gateway's signed payment callback and records it
+	// as an event of that gateway
+	ReceivePaymentWebhook(ctx context.Context, gateway string, header http.Header, body []byte) (*WebhookEventResponse, error)
+	ReplayEvent(ctx context.Context, id string) (*WebhookEventResponse, error)
+	ListEvents(ctx context.Context, req ListWebhookEventsRequest) (*ListWebhookEventsResponse, error)
+}
+
+// LedgerRecorder is told about money movements, which it posts to the double-entry
+// payments ledger. Each movement is posted once however often it is recorded.
+type LedgerRecorder interface {
+	RecordPayment(ctx context.Context, payment *models.Payment) error
+	RecordRefund(ctx context.Context, payment *models.Payment) error
+	RecordPartialRefund(ctx context.Context, refund PaymentRefund) error
+	RecordGiftCardRedemption(ctx context.Context, redemption GiftCardRedemption) error
+	RecordStoreCredit(ctx context.Context, movement StoreCreditMovement) error
+}
+
+// LedgerService defines the payments ledger: posting money movements, account
+// balances, and checks that the ledger balances and agrees with the payments
+type LedgerService interface {
+	LedgerRecorder
+	GetBalances(ctx context.Context, req LedgerBalanceRequest) (*LedgerBalancesResponse, error)
+	ListEntries(ctx context.Context, req ListLedgerEntriesRequest) (*ListLedgerEntriesResponse, error)
+	CheckConsistency(ctx context.Context) (*LedgerConsistencyReport, error)
+}
+
+// ManualPaymentService records payments settled outside any gateway, such as bank
+// transfers and cash on delivery, on behalf of admins
+type ManualPaymentService interface {
+	RecordPayment(ctx context.Context, orderID string, actor AuditActor, req RecordManualPaymentRequest) (*ManualPaymentResponse, error)
+	GetProof(ctx context.Context, paymentID string) (*models.PaymentProof, error)
+}
+
+// OrderHoldService places orders on compliance holds, which keep them from shipping
+// until a reviewer releases them, and reports on hold rates and SLA compliance
+type OrderHoldService interface {
+	PlaceHold(ctx context.Context, orderID, userID string, req PlaceOrderHoldRequest) (*OrderHoldResponse, error)
+	AssignHold(ctx context.Context, holdID string, req AssignOrderHoldRequest) (*OrderHoldResponse, error)
+	ReleaseHold(ctx context.Context, holdID, userID string, req ReleaseOrderHoldRequest) (*OrderHoldResponse, error)
+	ListHolds(ctx context.Context, req ListOrderHoldsRequest) (*ListOrderHoldsResponse, error)
+	GetOrderHolds(ctx context.Context, orderID string) ([]*OrderHoldResponse, error)
+	GetHoldMetrics(ctx context.Context, req OrderHoldMetricsRequest) (*OrderHoldMetricsResponse, error)
+}
+
+// ProductSerialService tracks the serial numbers of serialized products: received with
+// their lot, then captured on the order items they ship on, for warranty claims
+type ProductSerialService interface {
+	ReceiveSerials(ctx context.Context, productID string, req ReceiveProductSerialsRequest) (*ReceiveProductSerialsResponse, error)
+	ListSerials(ctx context.Context, productID string, req ListProductSerialsRequest) (*ListProductSerialsResponse, error)
+	CaptureSerials(ctx context.Context, orderID, itemID string, req CaptureOrderItemSerialsRequest) (*OrderItemDetail, error)
+}
+
+// WarrantyClaimService takes customers' warranty claims on the serialized units they
+// received and resolves them by repair, replacement or refund
+type WarrantyClaimService interface {
+	CreateClaim(ctx context.Context, userID, orderID, itemID string, req CreateWarrantyClaimRequest) (*models.WarrantyClaim, error)
+	ListUserClaims(ctx context.Context, userID string, req ListWarrantyClaimsRequest) (*ListWarrantyClaimsResponse, error)
+	ListClaims(ctx context.Context, req ListWarrantyClaimsRequest) (*ListWarrantyClaimsResponse, error)
+	ResolveClaim(ctx context.Context, id, userID string, req ResolveWarrantyClaimRequest) (*models.WarrantyClaim, error)
+	RejectClaim(ctx context.Context, id, userID string, req RejectWarrantyClaimRequest) (*models.WarrantyClaim, error)
+	GetClaimRates(ctx context.Context, req WarrantyClaimRateReportRequest) (*WarrantyClaimRateReportResponse, error)
+}
+
+// StockChangeNotifier is told about inventory changes that external channels must see
+type StockChangeNotifier interface {
+	NotifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string)
+}
+
+// AvailabilityService serves storefront availability from a read model that is
+// refreshed by stock change notifications
+type AvailabilityService interface {
+	StockChangeNotifier
+	GetAvailability(ctx context.Context, productID string) (*ProductAvailabilityResponse, error)
+}
+
+// ActivityRecorder is told about user actions that feed the user activity report
+type ActivityRecorder interface {
+	RecordActivity(ctx context.Context, userID string, eventType models.ActivityEventType, entityID string)
+}
+
+// OrderStatusNotifier is told about order status changes, confirmations of paid
+// orders, cancelled items and refunds that customers are notified of
+type OrderStatusNotifier interface {
+	NotifyOrderStatusChange(ctx context.Context, order *models.Order, status models.OrderStatus)
+	NotifyOrderConfirmed(ctx context.Context, order *models.Order)
+	NotifyOrderItemsCancelled(ctx context.Context, order *models.Order)
+	NotifyOrderRefunded(ctx context.Context, order *models.Order, amount float64)
+}
+
+// StockWebhookService defines outbound stock-change webhook logic
+type StockWebhookService interface {
+	StockChangeNotifier
+	CreateSubscription(ctx context.Context, req CreateStockWebhookSubscriptionRequest) (*StockWebhookSubscriptionResponse, error)
+	ListSubscriptions(ctx context.Context) ([]*StockWebhookSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, id string) error
+}
+
+// WebhookPublisher is told about payment events that webhook endpoints can subscribe
+// to. Order events reach endpoints through the order event outbox instead.
+type WebhookPublisher interface {
+	PublishPaymentFailed(ctx context.Context, payment *models.Payment)
+}
+
+// EventWebhookService defines outbound webhooks for order and payment lifecycle events.
+// Events are queued as deliveries to each subscribed endpoint and sent, signed, in the
+// background with retries.
+type EventWebhookService interface {
+	WebhookPublisher
+	CreateEndpoint(ctx context.Context, req CreateWebhookEndpointRequest) (*WebhookEndpointResponse, error)
+	ListEndpoints(ctx context.Context) ([]*WebhookEndpointResponse, error)
+	UpdateEndpoint(ctx context.Context, id string, req UpdateWebhookEndpointRequest) (*WebhookEndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, req ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// Redeliver queues a failed delivery to be sent again
+	Redeliver(ctx context.Context, id string) (*WebhookDeliveryResponse, error)
+	// RelayOrderEvents queues deliveries of the order events recorded since the last
+	// run, returning how many events were relayed
+	RelayOrderEvents(ctx context.Context) (int, error)
+	// DeliverDue sends the deliveries that are due, returning how many were attempted
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// ChannelService defines marketplace channel integration logic
+type ChannelService interface {
+	ListChannels(ctx context.Context) []*ChannelResponse
+	ImportOrder(ctx context.Context, channel string, payload []byte) (*ChannelOrderImportResponse, error)
+	CreateListing(ctx context.Context, channel string, req CreateChannelListingRequest) (*ChannelListingResponse, error)
+	ListListings(ctx context.Context, channel string) ([]*ChannelListingResponse, error)
+	SyncChannel(ctx context.Context, channel string) (*ChannelSyncResponse, error)
+}
+
+// AdminOrderService defines admin-only order views that span several aggregates
+type AdminOrderService interface {
+	GetOrderFullView(ctx context.Context, id string) (*OrderFullViewResponse, error)
+	OverrideItemPrice(ctx context.Context, orderID, itemID, userID string, req OverrideItemPriceRequest) (*OrderItemPriceOverrideResponse, error)
+	GetPackingSlip(ctx context.Context, id string) (*PackingSlipResponse, error)
+	GetPickList(ctx context.Context, req PickListRequest) (*PickListResponse, error)
+	// RescoreOrder scores the order's risk again from its current data, such as after
+	// its address or payments changed
+	RescoreOrder(ctx context.Context, id string) (*OrderRisk, error)
+}
+
+// PhoneOrderService lets agents order on behalf of customers who call in: they find
+// products at the customer's prices, build the customer's cart and place the order
+// with price overrides and a payment method. Phone orders are attributed to the agent
+// who placed them for commission reporting.
+type PhoneOrderService interface {
+	SearchProducts(ctx context.Context, customerID string, req PhoneOrderProductSearchRequest) (*PhoneOrderProductSearchResponse, error)
+	GetCart(ctx context.Context, customerID string) ([]*CartHoldResponse, error)
+	HoldCartItem(ctx context.Context, customerID string, req HoldCartItemRequest) (*CartHoldResponse, error)
+	ReleaseCartItem(ctx context.Context, customerID, productID string) error
+	PlaceOrder(ctx context.Context, customerID, agentID string, req PlacePhoneOrderRequest) (*PhoneOrderResponse, error)
+	GenerateAgentSalesReport(ctx context.Context, req AgentSalesReportRequest) (*AgentSalesReportResponse, error)
+}
+
+// SandboxSnapshotService exports an anonymized copy of the store's data for staging
+// and load-test environments
+type SandboxSnapshotService interface {
+	// Export passes every exported record to fn in an order that can be loaded as is:
+	// organizations, users, products with their inventory, then orders followed by
+	// their items and payments
+	Export(ctx context.Context, req SandboxSnapshotRequest, fn func(*SandboxSnapshotRecord) error) error
+}
+
+// OrganizationService defines B2B customer account business logic. Members of an
+// organization share visibility of its orders and may order on account under its terms.
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (*OrganizationResponse, error)
+	GetOrganization(ctx context.Context, id string) (*OrganizationResponse, error)
+	UpdateOrganization(ctx context.Context, id string, req UpdateOrganizationRequest) (*OrganizationResponse, error)
+	ListOrganizations(ctx context.Context, req ListOrganizationsRequest) (*ListOrganizationsResponse, error)
+	AddMember(ctx context.Context, organizationID, userID string) (*OrganizationResponse, error)
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+	GetUserOrganization(ctx context.Context, userID string) (*OrganizationResponse, error)
+	ListOrganizationOrders(ctx context.Context, userID string, req ListOrdersRequest) (*ListOrdersResponse, error)
+	ImportUsers(ctx context.Context, r io.Reader) (*UserImportResponse, error)
+	ListCreditHolds(ctx context.Context) ([]*OrderResponse, error)
+	ReviewCreditHold(ctx context.Context, orderID, reviewerID string, req ReviewCreditHoldRequest) (*OrderResponse, error)
+}
+
+// PriceListService manages B2B price lists and assigns them to organizations and
+// customers, who order at their prices
+type PriceListService interface {
+	CreatePriceList(ctx context.Context, req CreatePriceListRequest) (*PriceListResponse, error)
+	// GetPriceList returns a price list with its rows
+	GetPriceList(ctx context.Context, id string) (*PriceListResponse, error)
+	UpdatePriceList(ctx context.Context, id string, req UpdatePriceListRequest) (*PriceListResponse, error)
+	ListPriceLists(ctx context.Context, req ListPriceListsRequest) (*ListPriceListsResponse, error)
+	// ImportItems adds the rows of a CSV to a price list, first removing its rows when
+	// replace is set. Nothing is imported unless every row is valid.
+	ImportItems(ctx context.Context, id string, r io.Reader, replace bool) (*PriceListImportResponse, error)
+	// AssignToOrganization sets the price list of an organization; nil clears it
+	AssignToOrganization(ctx context.Context, organizationID string, priceListID *string) error
+	// AssignToUser sets the price list of a customer, which applies ahead of their
+	// organization's; nil clears it
+	AssignToUser(ctx context.Context, userID string, priceListID *string) error
+}
+
+// PromotionService manages the promotions applied automatically at checkout and
+// reports how they perform
+type PromotionService interface {
+	CreatePromotion(ctx context.Context, req CreatePromotionRequest) (*models.Promotion, error)
+	GetPromotion(ctx context.Context, id string) (*models.Promotion, error)
+	UpdatePromotion(ctx context.Context, id string, req UpdatePromotionRequest) (*models.Promotion, error)
+	ListPromotions(ctx context.Context, req ListPromotionsRequest) (*ListPromotionsResponse, error)
+	GeneratePromotionReport(ctx context.Context, req PromotionReportRequest) (*PromotionReportResponse, error)
+}
+
+// DeliverySlotService plans the delivery capacity of each region per day, and lists
+// the delivery slots customers can choose at checkout
+type DeliverySlotService interface {
+	// PlanSlots creates the windows as slots on every day of the range, or updates the
+	// slots already planned for them, and returns the slots of the range
+	PlanSlots(ctx context.Context, req PlanDeliverySlotsRequest) (*ListDeliverySlotsResponse, error)
+	UpdateSlot(ctx context.Context, id string, req UpdateDeliverySlotRequest) (*DeliverySlotResponse, error)
+	// ListSlots returns every slot of the range with its bookings, for capacity planning
+	ListSlots(ctx context.Context, req ListDeliverySlotsRequest) (*ListDeliverySlotsResponse, error)
+	// ListAvailableSlots returns the slots of the range that can be chosen at checkout:
+	// active, not fully booked, from today on
+	ListAvailableSlots(ctx context.Context, req ListDeliverySlotsRequest) (*ListDeliverySlotsResponse, error)
 }
 
 // ReportService defines reporting business logic
 type ReportService interface {
-	GenerateDailySalesReport(ctx context.Context, date string) (*SalesReportResponse, error)
+	GenerateDailySalesReport(ctx context.Context, date string, lowMarginThreshold float64) (*SalesReportResponse, error)
+	GenerateUserActivityReport(ctx context.Context, req UserActivityReportRequest) (*UserActivityReportResponse, error)
+	GenerateSettlementReport(ctx context.Context, req SettlementReportRequest) (*SettlementReportResponse, error)
+	GeneratePaymentFailureReport(ctx context.Context, req PaymentFailureReportRequest) (*PaymentFailureReportResponse, error)
+	GenerateNotificationDeliveryReport(ctx context.Context, req NotificationDeliveryReportRequest) (*NotificationDeliveryReportResponse, error)
+	GenerateARAgingReport(ctx context.Context) (*ARAgingReportResponse, error)
+	GenerateCreditExposureReport(ctx context.Context) (*CreditExposureReportResponse, error)
+	GenerateShippingSLAReport(ctx context.Context) (*ShippingSLAReportResponse, error)
+}
+
+// CloseReportService emails each store's end-of-day close report to managers
+type CloseReportService interface {
+	// SendDue sends the close reports due today that were not sent yet, returning how
+	// many it sent
+	SendDue(ctx context.Context) (int, error)
+	// Compose builds the close report of a store, or of all stores for DefaultStore,
+	// for a day (YYYY-MM-DD)
+	Compose(ctx context.Context, store, date string) (*CloseReportResponse, error)
+}
+
+// ReportQueueService queues reports for generation in the background and returns
+// their stored results, so clients can poll a report until it completes
+type ReportQueueService interface {
+	QueueReport(ctx context.Context, userID string, req QueueReportRequest) (*ReportResultResponse, error)
+	GetReportResult(ctx context.Context, id string) (*ReportResultResponse, error)
+	DownloadReport(ctx context.Context, id string) (*ReportDownload, error)
+}
+
+// APIUsageService manages users' API keys and tracks API usage per API key, user and
+// endpoint, enforcing the keys' optional monthly quotas. It also authenticates API keys
+// and records requests for the auth and usage middleware.
+type APIUsageService interface {
+	CreateAPIKey(ctx context.Context, userID string, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID string) error
+	// SetAPIKeyQuota sets or removes the monthly quota of any user's key
+	SetAPIKeyQuota(ctx context.Context, keyID string, req SetAPIKeyQuotaRequest) (*APIKeyResponse, error)
+	GetAPIUsage(ctx context.Context, req APIUsageRequest) (*APIUsageReportResponse, error)
+
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error)
+	ReserveQuota(ctx context.Context, key *models.APIKey, at time.Time) (bool, error)
+	RecordRequest(usage models.APIUsage)
+	// FlushUsage writes the usage recorded since the last flush to the usage table
+	FlushUsage(ctx context.Context) error
+}
+
+// ReferralService runs the referral program. Users share their referral code; users
+// who sign up with it, and their first order, are attributed to the code's owner, who
+// earns store credit once that order was delivered and not returned. It records order
+// activity to attribute first orders.
+type ReferralService interface {
+	GetReferralCode(ctx context.Context, userID string) (*ReferralCodeResponse, error)
+	ListReferrals(ctx context.Context, userID string) (*MyReferralsResponse, error)
+	GetStoreCredit(ctx context.Context, userID string) (*StoreCreditResponse, error)
+	// ProcessRewards rewards the referrals whose order was delivered and kept past the
+	// return window, voids those whose order was returned and frees those whose order
+	// was cancelled for the referee's next order
+	ProcessRewards(ctx context.Context) (*ReferralRewardRunResponse, error)
+	GenerateReferralReport(ctx context.Context, req ReferralReportRequest) (*ReferralReportResponse, error)
+
+	ResolveReferralCode(ctx context.Context, code string) (string, error)
+	AttributeSignup(ctx context.Context, referrerID, userID, code string)
+	RecordActivity(ctx context.Context, userID string, eventType models.ActivityEventType, entityID string)
+}
+
+// BlocklistService manages the blocklist orders and payments are screened against
+type BlocklistService interface {
+	AddEntry(ctx context.Context, actor AuditActor, req CreateBlocklistEntryRequest) (*models.BlocklistEntry, error)
+	ListEntries(ctx context.Context, req ListBlocklistEntriesRequest) (*ListBlocklistEntriesResponse, error)
+	RemoveEntry(ctx context.Context, actor AuditActor, id string) error
+	// GetMetrics reports the attempts screened and blocked since startup, with the
+	// entries that blocked the most attempts
+	GetMetrics(ctx context.Context) (*BlocklistMetricsResponse, error)
+}
+
+// ReferralTracker attributes new signups to the users whose referral code they used
+type ReferralTracker interface {
+	// ResolveReferralCode returns the ID of the user owning the code, or an invalid
+	// referral code error
+	ResolveReferralCode(ctx context.Context, code string) (string, error)
+	// AttributeSignup records that the user signed up with the referrer's code
+	AttributeSignup(ctx context.Context, referrerID, userID, code string)
 }
 
 // CreateUserRequest Request/Response structs
@@ -100,6 +605,8 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
 	Name     string `json:"name" validate:"required"`
+	// ReferralCode attributes the signup to the user who shared the code
+	ReferralCode string `json:"referral_code,omitempty" validate:"omitempty,max=20"`
 }
 
 type UpdateUserRequest struct {
@@ -138,22 +645,57 @@ type AuthResponse struct {
 }
 
 type CreateProductRequest struct {
-	Name         string  `json:"name" validate:"required"`
-	Description  string  `json:"description"`
-	Price        float64 `json:"price" validate:"required,gt=0"`
-	SKU          string  `json:"sku" validate:"required"`
-	CategoryID   string  `json:"category_id"`
-	InitialStock int     `json:"initial_stock,omitempty"`
-	MinStock     int     `json:"min_stock,omitempty"`
-	MaxStock     int     `json:"max_stock,omitempty"`
+	Name            string            `json:"name" validate:"required"`
+	Description     string            `json:"description"`
+	Price           float64           `json:"price" validate:"required,gt=0"`
+	CostPrice       float64           `json:"cost_price,omitempty" validate:"omitempty,gte=0"`
+	SKU             string            `json:"sku" validate:"required"`
+	CategoryID      string            `json:"category_id"`
+	InitialStock    int               `json:"initial_stock,omitempty"`
+	MinStock        int               `json:"min_stock,omitempty"`
+	MaxStock        int               `json:"max_stock,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CartHoldMinutes int               `json:"cart_hold_minutes,omitempty" validate:"omitempty,gte=0,lte=1440"` // 0 uses the store default
+	// LeadTimeDays is the supplier lead time; 0 uses the store default. LowStockThreshold
+	// replaces the threshold computed from sales velocity and min stock.
+	LeadTimeDays      int  `json:"lead_time_days,omitempty" validate:"omitempty,gte=0,lte=365"`
+	LowStockThreshold *int `json:"low_stock_threshold,omitempty" validate:"omitempty,gte=0"`
+	// PurchaseLimit caps the units each customer may buy, over the last
+	// PurchaseLimitDays days or, when 0, ever; 0 is no limit
+	PurchaseLimit     int `json:"purchase_limit,omitempty" validate:"omitempty,gte=0"`
+	PurchaseLimitDays int `json:"purchase_limit_days,omitempty" validate:"omitempty,gte=0,lte=3650"`
+	// Serialized requires a received serial number per unit to ship
+	Serialized bool `json:"serialized,omitempty"`
+	// Translations of the name and description, which are in the default locale, keyed
+	// by supported locale
+	Translations map[string]models.ProductTranslation `json:"translations,omitempty"`
 }
 
 type UpdateProductRequest struct {
 	Name        string  `json:"name,omitempty"`
 	Description string  `json:"description,omitempty"`
 	Price       float64 `json:"price,omitempty" validate:"omitempty,gt=0"`
-	CategoryID  string  `json:"category_id,omitempty"`
-	IsActive    *bool   `json:"is_active,omitempty"`
+	// CostPrice is a pointer so a cost can be explicitly reset to zero
+	CostPrice  *float64 `json:"cost_price,omitempty" validate:"omitempty,gte=0"`
+	CategoryID string   `json:"category_id,omitempty"`
+	IsActive   *bool    `json:"is_active,omitempty"`
+	// Metadata is merged into the existing metadata; empty values remove keys
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// CartHoldMinutes overrides the store cart hold window; 0 restores the store default
+	CartHoldMinutes *int `json:"cart_hold_minutes,omitempty" validate:"omitempty,gte=0,lte=1440"`
+	// LeadTimeDays overrides the store supplier lead time; 0 restores the store default
+	LeadTimeDays *int `json:"lead_time_days,omitempty" validate:"omitempty,gte=0,lte=365"`
+	// LowStockThreshold overrides the computed low-stock threshold; -1 removes the override
+	LowStockThreshold *int `json:"low_stock_threshold,omitempty" validate:"omitempty,gte=-1"`
+	// PurchaseLimit caps the units each customer may buy; 0 removes the limit.
+	// PurchaseLimitDays is the period it counts units in; 0 counts them ever.
+	PurchaseLimit     *int `json:"purchase_limit,omitempty" validate:"omitempty,gte=0"`
+	PurchaseLimitDays *int `json:"purchase_limit_days,omitempty" validate:"omitempty,gte=0,lte=3650"`
+	// Serialized requires a received serial number per unit to ship
+	Serialized *bool `json:"serialized,omitempty"`
+	// Translations replace the translation of each locale given; an empty translation
+	// removes the locale
+	Translations map[string]models.ProductTranslation `json:"translations,omitempty"`
 }
 
 type ListProductsRequest struct {
@@ -161,6 +703,9 @@ type ListProductsRequest struct {
 	Limit      int    `json:"limit" form:"limit"`
 	CategoryID string `json:"category_id,omitempty" form:"category_id"`
 	ActiveOnly bool   `json:"active_only,omitempty" form:"active_only"`
+	// Query searches active products by SKU, and by name and description in the
+	// default locale and the request locale
+	Query string `json:"q,omitempty" form:"q" validate:"omitempty,max=255"`
 }
 
 type SearchProductsRequest struct {
@@ -170,14 +715,30 @@ type SearchProductsRequest struct {
 }
 
 type ProductResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	SKU         string  `json:"sku"`
-	CategoryID  string  `json:"category_id"`
-	IsActive    bool    `json:"is_active"`
-	Stock       int     `json:"stock"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description"`
+	Price           float64           `json:"price"`
+	CostPrice       float64           `json:"cost_price"`
+	SKU             string            `json:"sku"`
+	CategoryID      string            `json:"category_id"`
+	IsActive        bool              `json:"is_active"`
+	Stock           int               `json:"stock"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CartHoldMinutes int               `json:"cart_hold_minutes,omitempty"` // Cart hold window override, if any
+
+	LeadTimeDays      int  `json:"lead_time_days,omitempty"`      // Supplier lead time override, if any
+	LowStockThreshold *int `json:"low_stock_threshold,omitempty"` // Low-stock threshold override, if any
+
+	PurchaseLimit     int `json:"purchase_limit,omitempty"`      // Units each customer may buy, if limited
+	PurchaseLimitDays int `json:"purchase_limit_days,omitempty"` // Period of the purchase limit; 0 counts every order
+
+	Serialized bool `json:"serialized,omitempty"` // Units ship with a captured serial number
+
+	// Locale is the locale Name and Description were resolved in: the request locale,
+	// or the default locale when the product is not translated in it
+	Locale       string                               `json:"locale"`
+	Translations map[string]models.ProductTranslation `json:"translations,omitempty"`
 }
 
 type ListProductsResponse struct {
@@ -188,29 +749,451 @@ type ListProductsResponse struct {
 }
 
 type CreateOrderRequest struct {
-	UserID string      `json:"-"` // Populated from the JWT context, not from the request body
-	Items  []OrderItem `json:"items" validate:"required,dive"`
-	Notes  string      `json:"notes,omitempty"`
+	UserID   string            `json:"-"` // Populated from the JWT context, not from the request body
+	Items    []OrderItem       `json:"items" validate:"required,dive"`
+	Notes    string            `json:"notes,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// PaymentMethod selects the configured surcharge or discount applied to the order total
+	PaymentMethod models.PaymentMethod `json:"payment_method,omitempty" validate:"omitempty,oneof=credit_card debit_card paypal bank_transfer cash"`
+	// OnAccount places the order on the user's organization account, to be paid by invoice
+	OnAccount bool `json:"on_account,omitempty"`
+	// Channel and ExternalOrderID attribute imported marketplace orders
+	Channel         string `json:"-"`
+	ExternalOrderID string `json:"-"`
+	// PlacedBy is the agent who placed a phone order on the customer's behalf
+	PlacedBy string `json:"-"`
+	// Express checkout ships to the customer's saved address and places one order per key
+	ShippingAddress *models.ShippingAddress `json:"-"`
+	IdempotencyKey  string                  `json:"-"`
+	// ConfirmDuplicate places the order even if it repeats a recent order of the user
+	ConfirmDuplicate bool `json:"confirm_duplicate,omitempty"`
+	// QueueTicket keeps the place in the stock allocation queue of an order that was queued
+	QueueTicket string `json:"queue_ticket,omitempty"`
+	// Gift marks the order as a gift, where the store offers gift options
+	Gift *OrderGiftOptions `json:"gift,omitempty"`
+	// ClientIP is the address the order was placed from, screened against the blocklist
+	ClientIP string `json:"-"`
+	// RequestID identifies the submission; resubmitting it returns the order it placed
+	// instead of placing another one
+	RequestID string `json:"request_id,omitempty" validate:"omitempty,max=200"`
+	// DeliverySlotID books the order into a delivery slot of the region it ships to
+	DeliverySlotID string `json:"delivery_slot_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// OrderGiftOptions are the options of a gift order: a message for the recipient, and
+// whether the packing slip leaves out prices
+type OrderGiftOptions struct {
+	Message    string `json:"message,omitempty" validate:"omitempty,max=500"`
+	HidePrices bool   `json:"hide_prices,omitempty"`
 }
 
 type OrderItem struct {
 	ProductID string  `json:"product_id" validate:"required"`
 	Quantity  int     `json:"quantity" validate:"required,gt=0"`
 	UnitPrice float64 `json:"-"` // Fetched from the product database, not from a client request
+	// SerialNumbers of the units shipped, for warranty claims; set on responses only
+	SerialNumbers []string `json:"serial_numbers,omitempty" validate:"-"`
 }
 
 type ListOrdersRequest struct {
 	Page   int                `json:"page" form:"page"`
 	Limit  int                `json:"limit" form:"limit"`
 	Status models.OrderStatus `json:"status,omitempty" form:"status"`
+	// MetadataKey and MetadataValue filter orders by a single metadata entry
+	MetadataKey   string `json:"metadata_key,omitempty" form:"metadata_key"`
+	MetadataValue string `json:"metadata_value,omitempty" form:"metadata_value"`
+	// Format selects a paginated JSON page or a full NDJSON export stream
+	Format string `json:"format,omitempty" form:"format" validate:"omitempty,oneof=json ndjson"`
+	// RiskBand filters admin listings by the band of the orders' risk score, listing
+	// the highest scores first; unscored orders are in no band
+	RiskBand models.RiskBand `json:"risk_band,omitempty" form:"risk_band" validate:"omitempty,oneof=low medium high"`
+	// IncludeRisk adds each order's risk score, for admin listings
+	IncludeRisk bool `json:"-" form:"-"`
+}
+
+// ListUserOrdersRequest pages through one user's orders, optionally only those in a status
+type ListUserOrdersRequest struct {
+	Page   int                `json:"page" form:"page"`
+	Limit  int                `json:"limit" form:"limit"`
+	Status models.OrderStatus `json:"status,omitempty" form:"status" validate:"omitempty,oneof=pending confirmed paid shipped delivered cancelled failed"`
 }
 
 type OrderResponse struct {
-	ID     string             `json:"id"`
-	UserID string             `json:"user_id"`
-	Status models.OrderStatus `json:"status"`
-	Items  []OrderItem        `json:"items"`
-	Total  float64            `json:"total"`
+	ID                string                  `json:"id"`
+	UserID            string                  `json:"user_id"`
+	Status            models.OrderStatus      `json:"status"`
+	Items             []OrderItem             `json:"items"`
+	PaymentAdjustment *OrderPaymentAdjustment `json:"payment_adjustment,omitempty"`
+	Discount          float64                 `json:"discount,omitempty"`     // Taken off the items by promotions
+	ShippingFee       float64                 `json:"shipping_fee,omitempty"` // Charged after any free shipping
+	Promotions        []AppliedPromotion      `json:"promotions,omitempty"`   // Applied at checkout, only when the order is placed
+	DeliverySlotID    *string                 `json:"delivery_slot_id,omitempty"`
+	DeliverySlot      *DeliverySlotResponse   `json:"delivery_slot,omitempty"` // Booked at checkout, only when the order is placed
+	Total             float64                 `json:"total"`
+	Paid              float64                 `json:"paid,omitempty"`     // By completed payments, towards the total
+	Refunded          float64                 `json:"refunded,omitempty"` // Refunded for cancelled items
+	Metadata          map[string]string       `json:"metadata,omitempty"`
+	Channel           string                  `json:"channel,omitempty"`
+	PlacedBy          *string                 `json:"placed_by,omitempty"` // Agent who placed a phone order
+	OrganizationID    *string                 `json:"organization_id,omitempty"`
+	OnAccount         bool                    `json:"on_account,omitempty"`
+	InvoiceDueAt      *time.Time              `json:"invoice_due_at,omitempty"`
+	CreditHold        bool                    `json:"credit_hold,omitempty"`
+	PromisedShipBy    *time.Time              `json:"promised_ship_by,omitempty"`
+	ShippingAddress   *models.ShippingAddress `json:"shipping_address,omitempty"`
+	Gift              *OrderGiftOptions       `json:"gift,omitempty"`
+	DuplicateWarning  *DuplicateOrderWarning  `json:"duplicate_warning,omitempty"`
+	Replayed          bool                    `json:"replayed,omitempty"` // Placed earlier by a submission with the same request ID
+	Risk              *OrderRisk              `json:"risk,omitempty"`     // In admin listings, once scored
+}
+
+// OrderRisk is an order's fraud risk score from 0 to 100, its band and the signals
+// that raised it
+type OrderRisk struct {
+	Score    int                 `json:"score"`
+	Band     models.RiskBand     `json:"band"`
+	Signals  []models.RiskSignal `json:"signals"`
+	ScoredAt *time.Time          `json:"scored_at,omitempty"`
+}
+
+// CancelOrderItemsRequest lists the quantities of an order's products to cancel
+type CancelOrderItemsRequest struct {
+	Items []CancelOrderItem `json:"items" validate:"required,min=1,dive"`
+}
+
+type CancelOrderItem struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+}
+
+// DuplicateOrderWarning flags an order with the same items as a recent order of the user
+type DuplicateOrderWarning struct {
+	OrderID  string    `json:"order_id"`
+	PlacedAt time.Time `json:"placed_at"`
+	Message  string    `json:"message"`
+}
+
+// DuplicateOrderMetrics counts the duplicate order checks since startup. Detected is
+// the sum of Warned (placed with a warning), Prevented (refused pending confirmation)
+// and Confirmed (placed after the user confirmed it).
+type DuplicateOrderMetrics struct {
+	WindowSeconds       int64 `json:"window_seconds"`
+	RequireConfirmation bool  `json:"require_confirmation"`
+	Checked             int64 `json:"checked"`
+	Detected            int64 `json:"detected"`
+	Warned              int64 `json:"warned"`
+	Prevented           int64 `json:"prevented"`
+	Confirmed           int64 `json:"confirmed"`
+}
+
+// OrderLimitsResponse is the order limits in force, where zero is no limit, and how
+// many orders each limit has refused since startup
+type OrderLimitsResponse struct {
+	MaxItemsPerOrder  int                  `json:"max_items_per_order"`
+	MaxQuantityPerSKU int                  `json:"max_quantity_per_sku"`
+	MaxOrdersPerHour  int                  `json:"max_orders_per_hour"`
+	Rejected          OrderLimitRejections `json:"rejected"`
+}
+
+// OrderLimitRejections counts the orders refused by each order limit
+type OrderLimitRejections struct {
+	Items    int64 `json:"items"`
+	Quantity int64 `json:"quantity"`
+	Rate     int64 `json:"rate"`
+}
+
+// UpdateOrderLimitsRequest changes the order limits in force; zero removes a limit and
+// a limit left out keeps its value
+type UpdateOrderLimitsRequest struct {
+	MaxItemsPerOrder  *int `json:"max_items_per_order,omitempty" validate:"omitempty,gte=0"`
+	MaxQuantityPerSKU *int `json:"max_quantity_per_sku,omitempty" validate:"omitempty,gte=0"`
+	MaxOrdersPerHour  *int `json:"max_orders_per_hour,omitempty" validate:"omitempty,gte=0"`
+}
+
+// AllocationQueuePosition is the place of a queued order in the stock allocation
+// queue. Position 1 is next in line and 0 is being placed; the ticket is dropped at ExpiresAt unless the
+// order is retried or the position polled before then.
+type AllocationQueuePosition struct {
+	TicketID  string    `json:"ticket_id"`
+	Position  int       `json:"position"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SandboxSnapshotRequest tunes a sandbox snapshot. Orders placed from OrdersSince
+// (YYYY-MM-DD) are exported, every order when empty. Amounts are jittered by up to
+// JitterPercent, 10 by default. Snapshots taken with the same Seed anonymize records
+// alike; without one each snapshot draws a random seed.
+type SandboxSnapshotRequest struct {
+	OrdersSince   string  `form:"orders_since" validate:"omitempty,datetime=2006-01-02"`
+	JitterPercent float64 `form:"jitter_percent" validate:"omitempty,gt=0,lte=50"`
+	Seed          string  `form:"seed"`
+}
+
+// SandboxSnapshotRecord is one exported row and the table it belongs to
+type SandboxSnapshotRecord struct {
+	Table  string      `json:"table"`
+	Record interface{} `json:"record"`
+}
+
+// PackingSlipResponse is the slip packed with an order. Gift orders carry the gift
+// message, and those hiding prices leave every price out (PricesHidden).
+type PackingSlipResponse struct {
+	OrderID         string                  `json:"order_id"`
+	PlacedAt        time.Time               `json:"placed_at"`
+	ShippingAddress *models.ShippingAddress `json:"shipping_address,omitempty"`
+	Items           []PackingSlipItem       `json:"items"`
+	Total           *float64                `json:"total,omitempty"`
+	Currency        string                  `json:"currency,omitempty"`
+	Gift            bool                    `json:"gift"`
+	GiftMessage     string                  `json:"gift_message,omitempty"`
+	PricesHidden    bool                    `json:"prices_hidden"`
+}
+
+// PackingSlipItem is a line of a packing slip; prices are nil when hidden
+type PackingSlipItem struct {
+	ProductID  string   `json:"product_id"`
+	SKU        string   `json:"sku,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Quantity   int      `json:"quantity"`
+	UnitPrice  *float64 `json:"unit_price,omitempty"`
+	TotalPrice *float64 `json:"total_price,omitempty"`
+}
+
+// DocumentQuery selects the format of a printable document: JSON data (default), or
+// an HTML page or PDF to print. Queries of reports that can be printed embed it.
+type DocumentQuery struct {
+	Format string `json:"format,omitempty" form:"format" validate:"omitempty,oneof=json html pdf"`
+}
+
+// PickListRequest selects the orders to pick in one walk of the warehouse
+type PickListRequest struct {
+	OrderIDs []string `json:"order_ids" validate:"required,min=1,max=100,dive,required"`
+}
+
+// PickListResponse is what to pick for a batch of orders, a line per product in the
+// order of their bin locations, followed by products without a location
+type PickListResponse struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	OrderIDs    []string       `json:"order_ids"`
+	Lines       []PickListLine `json:"lines"`
+	TotalUnits  int            `json:"total_units"`
+}
+
+// PickListLine is the quantity of a product to pick, and how it splits across orders
+type PickListLine struct {
+	Location  string          `json:"location,omitempty"`
+	ProductID string          `json:"product_id"`
+	SKU       string          `json:"sku,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Quantity  int             `json:"quantity"`
+	Orders    []PickListOrder `json:"orders"`
+}
+
+// PickListOrder is the quantity of a pick list line that goes to one order
+type PickListOrder struct {
+	OrderID  string `json:"order_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// OrderPaymentAdjustment itemizes the surcharge (positive) or discount (negative)
+// for the checkout payment method; Subtotal plus Amount is the order total
+type OrderPaymentAdjustment struct {
+	PaymentMethod models.PaymentMethod `json:"payment_method"`
+	Subtotal      float64              `json:"subtotal"`
+	Rate          float64              `json:"rate_percent"`
+	Amount        float64              `json:"amount"`
+}
+
+// OrderFullViewResponse gathers everything known about an order for admins
+type OrderFullViewResponse struct {
+	Order          OrderDetail               `json:"order"`
+	Customer       *UserResponse             `json:"customer,omitempty"`
+	Items          []OrderItemDetail         `json:"items"`
+	Payments       []OrderPaymentDetail      `json:"payments"`
+	Refunds        []OrderRefundDetail       `json:"refunds"`
+	RefundedAmount float64                   `json:"refunded_amount"`
+	Notifications  []*NotificationResponse   `json:"notifications"`
+	StatusHistory  []OrderStatusHistoryEntry `json:"status_history"`
+}
+
+type OrderDetail struct {
+	ID                string                  `json:"id"`
+	UserID            string                  `json:"user_id"`
+	Status            models.OrderStatus      `json:"status"`
+	PaymentAdjustment *OrderPaymentAdjustment `json:"payment_adjustment,omitempty"`
+	Total             float64                 `json:"total"`
+	Currency          string                  `json:"currency"`
+	Notes             string                  `json:"notes,omitempty"`
+	Metadata          map[string]string       `json:"metadata,omitempty"`
+	Channel           string                  `json:"channel,omitempty"`
+	PlacedBy          *string                 `json:"placed_by,omitempty"`
+	ExternalOrderID   string                  `json:"external_order_id,omitempty"`
+	OrganizationID    *string                 `json:"organization_id,omitempty"`
+	OnAccount         bool                    `json:"on_account,omitempty"`
+	InvoiceDueAt      *time.Time              `json:"invoice_due_at,omitempty"`
+	CreditHold        bool                    `json:"credit_hold,omitempty"`
+	CreditReviewedBy  *string                 `json:"credit_reviewed_by,omitempty"`
+	CreditReviewedAt  *time.Time              `json:"credit_reviewed_at,omitempty"`
+	OnHold            bool                    `json:"on_hold,omitempty"`
+	PromisedShipBy    *time.Time              `json:"promised_ship_by,omitempty"`
+	ShippingAddress   *models.ShippingAddress `json:"shipping_address,omitempty"`
+	Gift              *OrderGiftOptions       `json:"gift,omitempty"`
+	Risk              *OrderRisk              `json:"risk,omitempty"` // Once scored
+	CreatedAt         time.Time               `json:"created_at"`
+	UpdatedAt         time.Time               `json:"updated_at"`
+}
+
+// OrderItemDetail is an order line with the product as it is now; UnitPrice is the price paid
+// OrderItemDetail is an order item with the price charged (UnitPrice) and its list
+// price; PriceOverride is set when a privileged user overrode the charged price
+type OrderItemDetail struct {
+	ID              string                  `json:"id"`
+	ProductID       string                  `json:"product_id"`
+	Quantity        int                     `json:"quantity"`
+	UnitPrice       float64                 `json:"unit_price"`
+	TotalPrice      float64                 `json:"total_price"`
+	ListPrice       float64                 `json:"list_price"`
+	DiscountPercent float64                 `json:"discount_percent,omitempty"`
+	PriceOverride   *OrderItemPriceOverride `json:"price_override,omitempty"`
+	SerialNumbers   []string                `json:"serial_numbers,omitempty"`
+	Product         *ProductSnapshot        `json:"product,omitempty"`
+}
+
+// OrderItemPriceOverride records who overrode the price of an order item and why
+type OrderItemPriceOverride struct {
+	ReasonCode   string    `json:"reason_code"`
+	Note         string    `json:"note,omitempty"`
+	OverriddenBy *string   `json:"overridden_by,omitempty"`
+	OverriddenAt time.Time `json:"overridden_at"`
+}
+
+// OverrideItemPriceRequest charges an order item UnitPrice instead of its list price.
+// The discount from the list price may not exceed the configured maximum.
+type OverrideItemPriceRequest struct {
+	UnitPrice  float64 `json:"unit_price" validate:"required,gt=0"`
+	ReasonCode string  `json:"reason_code" validate:"required,oneof=negotiated price_match bulk_discount damaged_goods service_recovery"`
+	Note       string  `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// OrderItemPriceOverrideResponse is the repriced item with the order's new total
+type OrderItemPriceOverrideResponse struct {
+	OrderID           string          `json:"order_id"`
+	Item              OrderItemDetail `json:"item"`
+	Total             float64         `json:"total"`
+	PaymentAdjustment float64         `json:"payment_adjustment"`
+}
+
+// PhoneOrderProductSearchRequest searches active products by SKU, name and description
+type PhoneOrderProductSearchRequest struct {
+	Query string `json:"q" form:"q" validate:"required,max=255"`
+	Limit int    `json:"limit" form:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// PhoneOrderProduct is a product as the customer would order it. MinOverridePrice is
+// the lowest unit price an agent may override the customer's price to.
+type PhoneOrderProduct struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	SKU              string  `json:"sku"`
+	ListPrice        float64 `json:"list_price"`
+	CustomerPrice    float64 `json:"customer_price"`
+	MinOverridePrice float64 `json:"min_override_price"`
+	Stock            int     `json:"stock"`
+}
+
+type PhoneOrderProductSearchResponse struct {
+	Products []*PhoneOrderProduct `json:"products"`
+}
+
+// PhoneOrderPaymentType is how the customer pays a phone order
+type PhoneOrderPaymentType string
+
+const (
+	// PhoneOrderPaymentCardOnFile charges one of the customer's saved cards when the
+	// order is placed
+	PhoneOrderPaymentCardOnFile PhoneOrderPaymentType = "card_on_file"
+	// PhoneOrderPaymentInvoice places the order on the customer's organization
+	// account, to be paid by invoice
+	PhoneOrderPaymentInvoice PhoneOrderPaymentType = "invoice"
+	// PhoneOrderPaymentOffline leaves the order pending until its bank transfer or cash
+	// payment is recorded through the manual payments API
+	PhoneOrderPaymentOffline PhoneOrderPaymentType = "offline"
+)
+
+// PlacePhoneOrderRequest places an order for a customer. Items default to the
+// customer's cart. PaymentMethodID selects the saved card charged for card_on_file,
+// defaulting to the customer's default payment method; OfflineMethod is how an
+// offline order is to be paid.
+type PlacePhoneOrderRequest struct {
+	Items           []PhoneOrderItem      `json:"items,omitempty" validate:"omitempty,dive"`
+	PaymentType     PhoneOrderPaymentType `json:"payment_type" validate:"required,oneof=card_on_file invoice offline"`
+	PaymentMethodID string                `json:"payment_method_id,omitempty" validate:"omitempty,uuid"`
+	OfflineMethod   models.PaymentMethod  `json:"offline_method,omitempty" validate:"omitempty,oneof=bank_transfer cash"`
+	Notes           string                `json:"notes,omitempty" validate:"omitempty,max=1000"`
+	DeliverySlotID  string                `json:"delivery_slot_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// PhoneOrderItem is an item of a phone order. PriceOverride charges it below the
+// customer's price, within the price override policy.
+type PhoneOrderItem struct {
+	ProductID     string                    `json:"product_id" validate:"required"`
+	Quantity      int                       `json:"quantity" validate:"required,gt=0"`
+	PriceOverride *OverrideItemPriceRequest `json:"price_override,omitempty"`
+}
+
+// PhoneOrderResponse is the placed phone order. A card on file that could not be
+// charged leaves the order pending, with the reason in PaymentError.
+type PhoneOrderResponse struct {
+	Order        *OrderResponse        `json:"order"`
+	PaymentType  PhoneOrderPaymentType `json:"payment_type"`
+	Payment      *PaymentResponse      `json:"payment,omitempty"`
+	PaymentError string                `json:"payment_error,omitempty"`
+}
+
+type ProductSnapshot struct {
+	Name         string  `json:"name"`
+	SKU          string  `json:"sku"`
+	CurrentPrice float64 `json:"current_price"`
+	CostPrice    float64 `json:"cost_price"`
+	IsActive     bool    `json:"is_active"`
+	Available    *int    `json:"available,omitempty"`
+}
+
+// OrderPaymentDetail is a payment with its gateway attempts and retry state
+type OrderPaymentDetail struct {
+	ID            string               `json:"id"`
+	Amount        float64              `json:"amount"`
+	Currency      string               `json:"currency"`
+	Status        models.PaymentStatus `json:"status"`
+	Method        models.PaymentMethod `json:"method"`
+	Gateway       string               `json:"gateway,omitempty"`
+	TransactionID string               `json:"transaction_id,omitempty"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+	AttemptCount  int                  `json:"attempt_count"`
+	MaxRetries    int                  `json:"max_retries"`
+	LastAttemptAt *time.Time           `json:"last_attempt_at,omitempty"`
+	NextRetryAt   *time.Time           `json:"next_retry_at,omitempty"`
+	ProcessedAt   *time.Time           `json:"processed_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+
+	// Reference given with the payment, and the admin who recorded an offline payment
+	ExternalReference string  `json:"external_reference,omitempty"`
+	RecordedBy        *string `json:"recorded_by,omitempty"`
+}
+
+type OrderRefundDetail struct {
+	PaymentID  string    `json:"payment_id"`
+	Amount     float64   `json:"amount"`
+	RefundedAt time.Time `json:"refunded_at"`
+}
+
+// OrderStatusHistoryEntry is an audit trail entry for the order, oldest first
+type OrderStatusHistoryEntry struct {
+	Action    models.AuditAction `json:"action"`
+	UserID    *string            `json:"user_id,omitempty"`
+	OldValues json.RawMessage    `json:"old_values,omitempty" swaggertype:"object"`
+	NewValues json.RawMessage    `json:"new_values,omitempty" swaggertype:"object"`
+	CreatedAt time.Time          `json:"created_at"`
 }
 
 type ListOrdersResponse struct {
@@ -225,161 +1208,2365 @@ type InventoryItem struct {
 	Quantity  int    `json:"quantity"`
 }
 
-type ProcessPaymentRequest struct {
-	OrderID           string  `json:"order_id" validate:"required"`
-	Amount            float64 `json:"amount" validate:"required,gt=0"`
-	PaymentType       string  `json:"payment_type" validate:"required"`
-	ExternalReference string  `json:"external_reference,omitempty"`
+// HoldCartItemRequest holds stock for a cart item; holding a product again replaces its quantity
+type HoldCartItemRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
 }
 
-type RefundRequest struct {
-	Amount float64 `json:"amount" validate:"required,gt=0"`
+type CartHoldResponse struct {
+	ID        string                 `json:"id"`
+	ProductID string                 `json:"product_id"`
+	Quantity  int                    `json:"quantity"`
+	Status    models.StockHoldStatus `json:"status"`
+	ExpiresAt time.Time              `json:"expires_at"`
 }
 
-type PaymentResponse struct {
-	ID      string               `json:"id"`
-	OrderID string               `json:"order_id"`
-	Amount  float64              `json:"amount"`
-	Status  models.PaymentStatus `json:"status"`
+// CreateAddressRequest saves a shipping address. The first address a user saves
+// becomes the default; a later default replaces the previous one.
+type CreateAddressRequest struct {
+	Label      string `json:"label,omitempty" validate:"omitempty,max=100"`
+	Recipient  string `json:"recipient" validate:"required,max=255"`
+	Line1      string `json:"line1" validate:"required,max=255"`
+	Line2      string `json:"line2,omitempty" validate:"omitempty,max=255"`
+	City       string `json:"city" validate:"required,max=100"`
+	Region     string `json:"region,omitempty" validate:"omitempty,max=100"`
+	PostalCode string `json:"postal_code" validate:"required,max=20"`
+	Country    string `json:"country" validate:"required,len=2"`
+	Phone      string `json:"phone,omitempty" validate:"omitempty,max=30"`
+	IsDefault  bool   `json:"is_default,omitempty"`
 }
 
-type SendNotificationRequest struct {
-	UserID  string `json:"user_id" validate:"required"`
-	Type    string `json:"type,omitempty"`
-	Channel string `json:"channel,omitempty"`
-	Title   string `json:"title" validate:"required"`
-	Body    string `json:"body" validate:"required"`
-	Data    string `json:"data,omitempty"`
+type AddressResponse struct {
+	ID        string                 `json:"id"`
+	Label     string                 `json:"label,omitempty"`
+	Address   models.ShippingAddress `json:"address"`
+	IsDefault bool                   `json:"is_default"`
+	CreatedAt time.Time              `json:"created_at"`
 }
 
-type ListNotificationsRequest struct {
-	Offset     int  `json:"offset"`
-	Limit      int  `json:"limit"`
-	UnreadOnly bool `json:"unread_only"`
+// CreateSavedPaymentMethodRequest saves a payment method tokenized by the gateway.
+// The first method a user saves becomes the default; a later default replaces the
+// previous one.
+type CreateSavedPaymentMethodRequest struct {
+	Method       models.PaymentMethod `json:"method" validate:"required,oneof=credit_card debit_card paypal bank_transfer cash"`
+	GatewayToken string               `json:"gateway_token" validate:"required,max=255"`
+	Fingerprint  string               `json:"fingerprint,omitempty" validate:"omitempty,max=255"`
+	Label        string               `json:"label,omitempty" validate:"omitempty,max=100"`
+	Last4        string               `json:"last4,omitempty" validate:"omitempty,len=4,numeric"`
+	ExpiresAt    *time.Time           `json:"expires_at,omitempty"`
+	IsDefault    bool                 `json:"is_default,omitempty"`
 }
 
-type ListNotificationsResponse struct {
-	Notifications []*NotificationResponse `json:"notifications"`
-	Offset        int                     `json:"offset"`
-	Limit         int                     `json:"limit"`
-	Total         int                     `json:"total"`
+type SavedPaymentMethodResponse struct {
+	ID        string               `json:"id"`
+	Method    models.PaymentMethod `json:"method"`
+	Label     string               `json:"label,omitempty"`
+	Last4     string               `json:"last4,omitempty"`
+	ExpiresAt *time.Time           `json:"expires_at,omitempty"`
+	IsDefault bool                 `json:"is_default"`
+	CreatedAt time.Time            `json:"created_at"`
 }
 
-type NotificationResponse struct {
-	ID      string     `json:"id"`
-	UserID  string     `json:"user_id"`
-	Type    string     `json:"type"`
-	Channel string     `json:"channel"`
-	Title   string     `json:"title"`
-	Body    string     `json:"body"`
-	Data    string     `json:"data,omitempty"`
-	Read    bool       `json:"read"`
-	SentAt  *time.Time `json:"sent_at,omitempty"`
-	ReadAt  *time.Time `json:"read_at,omitempty"`
+// ExpressCheckoutRequest orders a quantity of one product, shipped to the default
+// address and paid with the default payment method
+type ExpressCheckoutRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,gt=0"`
+	Notes     string `json:"notes,omitempty"`
+	// IdempotencyKey comes from the Idempotency-Key header. Retries with the same key
+	// return the order placed by the first request instead of placing another one.
+	IdempotencyKey string `json:"-"`
+	// QueueTicket keeps the place in the stock allocation queue of a checkout that was queued
+	QueueTicket string `json:"queue_ticket,omitempty"`
+	// Gift marks the order as a gift, where the store offers gift options
+	Gift *OrderGiftOptions `json:"gift,omitempty"`
+	// ClientIP is the address of the checkout, screened against the blocklist
+	ClientIP string `json:"-"`
+	// DeliverySlotID books the order into a delivery slot of the default address's region
+	DeliverySlotID string `json:"delivery_slot_id,omitempty" validate:"omitempty,uuid"`
 }
 
-// GenerateSalesReportRequest Report Service DTOs
-type GenerateSalesReportRequest struct {
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
+// ExpressCheckoutOutcome is how far an express checkout got
+type ExpressCheckoutOutcome string
+
+const (
+	// ExpressCheckoutPaid means the order was placed and paid
+	ExpressCheckoutPaid ExpressCheckoutOutcome = "paid"
+	// ExpressCheckoutPaymentHeld means the order was placed and its payment failed
+	// transiently; the payment is retried automatically
+	ExpressCheckoutPaymentHeld ExpressCheckoutOutcome = "payment_held"
+	// ExpressCheckoutPaymentFailed means the order was placed but not paid. It stays
+	// pending with its stock reserved, to be paid through the payments API or cancelled.
+	ExpressCheckoutPaymentFailed ExpressCheckoutOutcome = "payment_failed"
+	// ExpressCheckoutPaymentPending means the order was placed and no payment was
+	// recorded yet, as when the request that placed it was interrupted
+	ExpressCheckoutPaymentPending ExpressCheckoutOutcome = "payment_pending"
+)
+
+// ExpressCheckoutResponse reports the order an express checkout placed and its
+// payment. Replayed is true when the idempotency key was used before, in which case
+// the order is returned as it is now and nothing is placed or charged.
+type ExpressCheckoutResponse struct {
+	Outcome      ExpressCheckoutOutcome `json:"outcome"`
+	Order        *OrderResponse         `json:"order"`
+	Payment      *PaymentResponse       `json:"payment,omitempty"`
+	PaymentError string                 `json:"payment_error,omitempty"`
+	Replayed     bool                   `json:"replayed"`
 }
 
-type GetSalesReportRequest struct {
-	Date string `json:"date"`
+// ChangeFeedRequest selects a page of a change feed. Since is the cursor returned
+// with the previous page; empty starts from the oldest event.
+type ChangeFeedRequest struct {
+	Since string `json:"since" form:"since"`
+	Limit int    `json:"limit" form:"limit" validate:"omitempty,gte=1,lte=1000"`
 }
 
-type UpdateStatusRequest struct {
-	Status string `json:"status" validate:"required"`
+// InventoryChangesResponse is a page of inventory change events in the order they were
+// recorded. Passing NextCursor as since resumes after the last event; HasMore is set
+// when further events are ready.
+type InventoryChangesResponse struct {
+	Events     []InventoryChangeEvent `json:"events"`
+	NextCursor string                 `json:"next_cursor"`
+	HasMore    bool                   `json:"has_more"`
 }
 
-type DailySalesReportQuery struct {
-	Date string `form:"date"`
+// InventoryChangeEvent is a change to a product's inventory with the stock levels it left
+type InventoryChangeEvent struct {
+	Cursor     string    `json:"cursor"`
+	ProductID  string    `json:"product_id"`
+	Type       string    `json:"type"`
+	Quantity   int       `json:"quantity"`
+	OnHand     int       `json:"on_hand"`
+	Reserved   int       `json:"reserved"`
+	Available  int       `json:"available"`
+	Version    int       `json:"version"`
+	RecordedAt time.Time `json:"recorded_at"`
 }
 
-type LowStockQuery struct {
-	Threshold int `form:"threshold" validate:"omitempty,gte=0"`
+// OrderChangesResponse is a page of order lifecycle events in the order they were
+// recorded, resumed like InventoryChangesResponse
+type OrderChangesResponse struct {
+	Events     []OrderChangeEvent `json:"events"`
+	NextCursor string             `json:"next_cursor"`
+	HasMore    bool               `json:"has_more"`
 }
 
-type LowStockRequest struct {
-	Threshold int `json:"threshold"`
+// OrderChangeEvent is an order lifecycle step. Payload is the order after the step in
+// the shape of PayloadVersion, so consumers can handle payloads written before a change.
+type OrderChangeEvent struct {
+	Cursor         string          `json:"cursor"`
+	OrderID        string          `json:"order_id"`
+	Type           string          `json:"type"`
+	PayloadVersion int             `json:"payload_version"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	RecordedAt     time.Time       `json:"recorded_at"`
 }
 
-type LowStockResponse struct {
-	Threshold int               `json:"threshold"`
-	Products  []ProductLowStock `json:"products"`
-	Count     int               `json:"count"`
+// CatalogChangesResponse is a page of catalog change events in the order they were
+// recorded, resumed like InventoryChangesResponse
+type CatalogChangesResponse struct {
+	Events     []CatalogChangeEvent `json:"events"`
+	NextCursor string               `json:"next_cursor"`
+	HasMore    bool                 `json:"has_more"`
 }
 
-type ProductLowStock struct {
-	ProductID    string `json:"product_id"`
-	ProductName  string `json:"product_name"`
-	SKU          string `json:"sku"`
-	CurrentStock int    `json:"current_stock"`
-	MinThreshold int    `json:"min_threshold"`
+// CatalogChangeEvent is a change to what a product's storefront pages show. It only
+// names the product, which consumers refetch or purge.
+type CatalogChangeEvent struct {
+	Cursor     string    `json:"cursor"`
+	ProductID  string    `json:"product_id"`
+	SKU        string    `json:"sku"`
+	Type       string    `json:"type"`
+	RecordedAt time.Time `json:"recorded_at"`
 }
 
-type LowStockItem struct {
-	ProductID    string `json:"product_id"`
-	ProductName  string `json:"product_name"`
-	ProductSKU   string `json:"product_sku"`
-	CurrentStock int    `json:"current_stock"`
-	MinStock     int    `json:"min_stock"`
+// RetentionPurgeRequest selects whether a purge only counts the records it would remove
+type RetentionPurgeRequest struct {
+	DryRun bool `json:"dry_run" form:"dry_run"`
 }
 
-type SalesReportResponse struct {
-	Date              string                 `json:"date"`
-	TotalSales        float64                `json:"total_sales"`
-	TotalOrders       int                    `json:"total_orders"`
-	CompletedOrders   int                    `json:"completed_orders"`
-	CancelledOrders   int                    `json:"cancelled_orders"`
-	AverageOrderValue float64                `json:"average_order_value"`
-	OrdersByStatus    map[string]int         `json:"orders_by_status"`
-	Report            map[string]interface{} `json:"report"`
+// RetentionPurgeResponse reports a purge of every enabled retention policy. Records is
+// what a dry run would have removed, and Error is set for a policy that failed.
+type RetentionPurgeResponse struct {
+	DryRun  bool                   `json:"dry_run"`
+	Results []RetentionPurgeResult `json:"results"`
 }
 
-type InventoryReportResponse struct {
-	TotalProducts      int                    `json:"total_products"`
-	ActiveProducts     int                    `json:"active_products"`
-	LowStockProducts   int                    `json:"low_stock_products"`
-	OutOfStockProducts int                    `json:"out_of_stock_products"`
-	TotalStockValue    float64                `json:"total_stock_value"`
-	TotalStockQuantity int                    `json:"total_stock_quantity"`
-	ProductInventory   []ProductInventoryItem `json:"product_inventory"`
+// PaymentReconciliationRunResponse summarizes a reconciliation run. ReportResultID is
+// the queued report of the run, when there was anything to report. Escalated counts
+// the stuck payments a poll notified ops of.
+type PaymentReconciliationRunResponse struct {
+	RunID          string    `json:"run_id"`
+	StartedAt      time.Time `json:"started_at"`
+	Checked        int       `json:"checked"`
+	InSync         int       `json:"in_sync"`
+	Corrected      int       `json:"corrected"`
+	Mismatched     int       `json:"mismatched"`
+	Pending        int       `json:"pending"`
+	Errors         int       `json:"errors"`
+	Escalated      int       `json:"escalated,omitempty"`
+	ReportResultID string    `json:"report_result_id,omitempty"`
 }
 
-type UserActivityReportRequest struct {
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
+// PaymentRoutingRuleRequest defines a payment routing rule. Route rules name the
+// gateway matching payments go to; bypass rules name none and match only offline
+// payment methods, which are settled outside the gateways. Unset bounds and empty
+// lists match any payment.
+type PaymentRoutingRuleRequest struct {
+	Name           string   `json:"name" validate:"required,max=255"`
+	Priority       int      `json:"priority,omitempty"`
+	MinAmount      *float64 `json:"min_amount,omitempty" validate:"omitempty,gte=0"`
+	MaxAmount      *float64 `json:"max_amount,omitempty" validate:"omitempty,gte=0"`
+	Currencies     []string `json:"currencies,omitempty" validate:"omitempty,max=50,dive,len=3"`
+	PaymentMethods []string `json:"payment_methods,omitempty" validate:"omitempty,max=10,dive,required"`
+	Action         string   `json:"action" validate:"required,oneof=route bypass"`
+	Gateway        string   `json:"gateway,omitempty" validate:"omitempty,max=50"`
+	IsActive       *bool    `json:"is_active,omitempty"` // Defaults to true
 }
 
-type UserActivityReportResponse struct {
-	ActiveUsers int `json:"active_users"`
-	NewUsers    int `json:"new_users"`
+// EvaluatePaymentRoutingRequest is a payment to route in a dry run. With Rules, those
+// are evaluated in place of the active rules, to try them out before saving them.
+type EvaluatePaymentRoutingRequest struct {
+	Amount        float64                     `json:"amount" validate:"required,gt=0"`
+	Currency      string                      `json:"currency" validate:"required,len=3"`
+	PaymentMethod string                      `json:"payment_method" validate:"required"`
+	Rules         []PaymentRoutingRuleRequest `json:"rules,omitempty" validate:"omitempty,max=100,dive"`
 }
 
-// ProductInventoryItem represents inventory information for a product
-type ProductInventoryItem struct {
-	ProductID     string  `json:"product_id"`
-	ProductName   string  `json:"product_name"`
-	SKU           string  `json:"sku"`
-	Price         float64 `json:"price"`
-	StockQuantity int     `json:"stock_quantity"`
-	StockValue    float64 `json:"stock_value"`
-	Status        string  `json:"status"`
+// PaymentRoutingRuleCheck is how one rule was evaluated in a dry run; Reason says why
+// it did not match
+type PaymentRoutingRuleCheck struct {
+	RuleID   string `json:"rule_id,omitempty"`
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Matched  bool   `json:"matched"`
+	Reason   string `json:"reason,omitempty"`
 }
 
-// TopProductsResponse represents the response for the top products report
-type TopProductsResponse struct {
-	TopProducts []*TopProductItem `json:"top_products"`
-	Limit       int               `json:"limit"`
-	Period      string            `json:"period"`
+// PaymentRoutingEvaluationResponse is where a payment would be routed now. Decision is
+// route when a rule routed it, bypass when a rule kept it from the gateways, or
+// selector when no rule matched. Gateway is where it would be sent, which is not the
+// rule's gateway when that one is unavailable, and empty when there is none.
+type PaymentRoutingEvaluationResponse struct {
+	Decision    string                    `json:"decision"`
+	MatchedRule string                    `json:"matched_rule,omitempty"`
+	RuleGateway string                    `json:"rule_gateway,omitempty"`
+	Gateway     string                    `json:"gateway,omitempty"`
+	Checks      []PaymentRoutingRuleCheck `json:"checks"`
 }
 
-// TopProductItem represents a single product in the top products report
-type TopProductItem struct {
-	ProductID     string  `json:"product_id"`
-	ProductName   string  `json:"product_name"`
+// RetentionPurgeResult is the purge of one retention policy
+type RetentionPurgeResult struct {
+	Policy  string    `json:"policy"`
+	Cutoff  time.Time `json:"cutoff"`
+	Records int64     `json:"records"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// RetentionPolicyMetrics reports the purges of a retention policy since startup.
+// PurgedTotal excludes dry runs.
+type RetentionPolicyMetrics struct {
+	Policy         string     `json:"policy"`
+	RetentionDays  int        `json:"retention_days"`
+	Enabled        bool       `json:"enabled"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	PurgedTotal    int64      `json:"purged_total"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastDryRun     bool       `json:"last_dry_run"`
+	LastRecords    int64      `json:"last_records"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastError      string     `json:"last_error,omitempty"`
+}
+
+// CartHoldStatsRequest selects an inclusive range of UTC days by hold creation date
+type CartHoldStatsRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// CartHoldStatsResponse reports how cart holds ended. ConversionRate is the
+// percentage of ended holds (not active) that were converted into orders.
+type CartHoldStatsResponse struct {
+	StartDate      string  `json:"start_date"`
+	EndDate        string  `json:"end_date"`
+	Holds          int     `json:"holds"`
+	HeldUnits      int     `json:"held_units"`
+	Active         int     `json:"active"`
+	Converted      int     `json:"converted"`
+	Released       int     `json:"released"`
+	Expired        int     `json:"expired"`
+	ConvertedUnits int     `json:"converted_units"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type ProcessPaymentRequest struct {
+	OrderID           string  `json:"order_id" validate:"required"`
+	Amount            float64 `json:"amount" validate:"required,gt=0"`
+	PaymentType       string  `json:"payment_type" validate:"required"`
+	ExternalReference string  `json:"external_reference,omitempty"`
+	// CardFingerprint identifies the card across tokens, as reported by the gateway
+	CardFingerprint string `json:"card_fingerprint,omitempty" validate:"omitempty,max=255"`
+	// ClientIP is the address the payment was made from, screened against the blocklist
+	ClientIP string `json:"-"`
+}
+
+// CreatePaymentIntentRequest opens a card payment for an order. Amount defaults to
+// what is left to pay of the order.
+type CreatePaymentIntentRequest struct {
+	OrderID     string  `json:"order_id" validate:"required,uuid"`
+	Amount      float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	PaymentType string  `json:"payment_type" validate:"required,oneof=credit_card debit_card"`
+}
+
+// ConfirmPaymentIntentRequest confirms an intent with the card the gateway's client
+// SDK collected
+type ConfirmPaymentIntentRequest struct {
+	// ExternalReference is the gateway token of the card
+	ExternalReference string `json:"external_reference" validate:"required,max=255"`
+	// CardFingerprint identifies the card across tokens, as reported by the gateway
+	CardFingerprint string `json:"card_fingerprint,omitempty" validate:"omitempty,max=255"`
+	// ClientIP is the address the intent was confirmed from, screened against the blocklist
+	ClientIP string `json:"-"`
+}
+
+// PaymentIntentResponse is a customer's intent to pay an order by card, with the
+// payment taken when it was confirmed. LastError is why its last confirmation failed.
+type PaymentIntentResponse struct {
+	ID          string                     `json:"id"`
+	OrderID     string                     `json:"order_id"`
+	Amount      float64                    `json:"amount"`
+	Currency    string                     `json:"currency"`
+	PaymentType models.PaymentMethod       `json:"payment_type"`
+	Status      models.PaymentIntentStatus `json:"status"`
+	Payment     *PaymentResponse           `json:"payment,omitempty"`
+	LastError   string                     `json:"last_error,omitempty"`
+	ExpiresAt   time.Time                  `json:"expires_at"`
+	CreatedAt   time.Time                  `json:"created_at"`
+}
+
+type RefundRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Reason string  `json:"reason,omitempty" validate:"omitempty,max=255"`
+	// RequestedBy is the admin issuing the refund; empty for refunds of cancelled orders
+	RequestedBy string `json:"-"`
+}
+
+// RefundResponse is a refund of part or all of a payment
+type RefundResponse struct {
+	ID          string    `json:"id"`
+	PaymentID   string    `json:"payment_id"`
+	OrderID     string    `json:"order_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Reason      string    `json:"reason,omitempty"`
+	RequestedBy *string   `json:"requested_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PaymentRefundsResponse is the refund history of a payment, with how much of it is
+// left to refund. RefundedAmount also counts refunds reported by the gateway, which
+// are not in the history.
+type PaymentRefundsResponse struct {
+	PaymentID        string            `json:"payment_id"`
+	Amount           float64           `json:"amount"`
+	RefundedAmount   float64           `json:"refunded_amount"`
+	RefundableAmount float64           `json:"refundable_amount"`
+	Refunds          []*RefundResponse `json:"refunds"`
+}
+
+type PaymentResponse struct {
+	ID      string               `json:"id"`
+	OrderID string               `json:"order_id"`
+	Amount  float64              `json:"amount"`
+	Status  models.PaymentStatus `json:"status"`
+
+	// Set for failed and held payments; held payments are retried at RetryAt
+	FailureReason string     `json:"failure_reason,omitempty"`
+	RetryAt       *time.Time `json:"retry_at,omitempty"`
+
+	// Amount returned to the customer so far
+	RefundedAmount float64 `json:"refunded_amount"`
+
+	// Set when refunding the payment, to the refund recorded
+	Refund *RefundResponse `json:"refund,omitempty"`
+}
+
+type SendNotificationRequest struct {
+	UserID  string `json:"user_id" validate:"required"`
+	Type    string `json:"type,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Title   string `json:"title" validate:"required"`
+	Body    string `json:"body" validate:"required"`
+	Data    string `json:"data,omitempty"`
+}
+
+// SendBatchNotificationsRequest enqueues many notifications in one call
+type SendBatchNotificationsRequest struct {
+	Notifications []SendNotificationRequest `json:"notifications" validate:"required,min=1,max=1000,dive"`
+}
+
+// BatchNotificationResult is the outcome for one recipient, in request order
+type BatchNotificationResult struct {
+	Index          int    `json:"index"`
+	UserID         string `json:"user_id"`
+	NotificationID string `json:"notification_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+type BatchNotificationResponse struct {
+	Total   int                       `json:"total"`
+	Sent    int                       `json:"sent"`
+	Failed  int                       `json:"failed"`
+	Results []BatchNotificationResult `json:"results"`
+}
+
+type ListNotificationsRequest struct {
+	Offset     int  `json:"offset" form:"offset" validate:"omitempty,min=0"`
+	Limit      int  `json:"limit" form:"limit" validate:"omitempty,min=1,max=100"`
+	UnreadOnly bool `json:"unread_only" form:"unread_only"`
+}
+
+type ListNotificationsResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+	Offset        int                     `json:"offset"`
+	Limit         int                     `json:"limit"`
+	Total         int                     `json:"total"`
+}
+
+type NotificationResponse struct {
+	ID               string     `json:"id"`
+	UserID           string     `json:"user_id"`
+	Type             string     `json:"type"`
+	Channel          string     `json:"channel"`
+	DeliveredChannel string     `json:"delivered_channel,omitempty"`
+	Title            string     `json:"title"`
+	Body             string     `json:"body"`
+	Data             string     `json:"data,omitempty"`
+	Read             bool       `json:"read"`
+	SentAt           *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	ReadAt           *time.Time `json:"read_at,omitempty"`
+}
+
+// Notification receipt statuses
+const (
+	NotificationReceiptDelivered = "delivered"
+	NotificationReceiptRead      = "read"
+)
+
+// NotificationReceiptRequest reports that a notification was delivered to, or read
+// by, its recipient. OccurredAt defaults to when the receipt is recorded.
+type NotificationReceiptRequest struct {
+	Status     string     `json:"status" validate:"required,oneof=delivered read"`
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+}
+
+// ReceiveWebhookRequest is the envelope posted by gateways and partners
+type ReceiveWebhookRequest struct {
+	Provider  string                    `json:"-"` // Populated from the URL path
+	Source    models.WebhookEventSource `json:"-"` // Gateway callback or partner event
+	EventID   string                    `json:"id" validate:"required"`
+	EventType string                    `json:"type" validate:"required"`
+	Data      json.RawMessage           `json:"data,omitempty" swaggertype:"object"`
+}
+
+type ListWebhookEventsRequest struct {
+	Page   int                       `json:"page" form:"page"`
+	Limit  int                       `json:"limit" form:"limit"`
+	Status models.WebhookEventStatus `json:"status,omitempty" form:"status"`
+}
+
+type WebhookEventResponse struct {
+	ID          string                    `json:"id"`
+	Source      models.WebhookEventSource `json:"source"`
+	Provider    string                    `json:"provider"`
+	EventID     string                    `json:"event_id"`
+	EventType   string                    `json:"event_type"`
+	Status      models.WebhookEventStatus `json:"status"`
+	Attempts    int                       `json:"attempts"`
+	LastError   string                    `json:"last_error,omitempty"`
+	Duplicate   bool                      `json:"duplicate,omitempty"`
+	ProcessedAt *time.Time                `json:"processed_at,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+}
+
+type ListWebhookEventsResponse struct {
+	Events []*WebhookEventResponse `json:"events"`
+	Page   int                     `json:"page"`
+	Limit  int                     `json:"limit"`
+	Total  int                     `json:"total"`
+}
+
+type CreateWebhookEndpointRequest struct {
+	Name   string                `json:"name" validate:"required,max=100"`
+	URL    string                `json:"url" validate:"required,url,max=500"`
+	Secret string                `json:"secret" validate:"required,min=16,max=255"`
+	Topics []models.WebhookTopic `json:"topics" validate:"required,min=1,dive,oneof=order.created order.paid order.shipped order.delivered order.cancelled order.payment_completed payment.failed"`
+}
+
+// UpdateWebhookEndpointRequest changes the fields that are set
+type UpdateWebhookEndpointRequest struct {
+	Name     *string               `json:"name,omitempty" validate:"omitempty,max=100"`
+	URL      *string               `json:"url,omitempty" validate:"omitempty,url,max=500"`
+	Secret   *string               `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Topics   []models.WebhookTopic `json:"topics,omitempty" validate:"omitempty,min=1,dive,oneof=order.created order.paid order.shipped order.delivered order.cancelled order.payment_completed payment.failed"`
+	IsActive *bool                 `json:"is_active,omitempty"`
+}
+
+type WebhookEndpointResponse struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	URL       string                `json:"url"`
+	Topics    []models.WebhookTopic `json:"topics"`
+	IsActive  bool                  `json:"is_active"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	Page       int    `json:"page" form:"page"`
+	Limit      int    `json:"limit" form:"limit"`
+	EndpointID string `json:"endpoint_id,omitempty" form:"endpoint_id"`
+	Topic      string `json:"topic,omitempty" form:"topic"`
+	Status     string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=pending delivered failed"`
+}
+
+// WebhookDeliveryResponse is a delivery log entry with the outcome of its latest attempt
+type WebhookDeliveryResponse struct {
+	ID             string                       `json:"id"`
+	EndpointID     string                       `json:"endpoint_id"`
+	EventID        string                       `json:"event_id"`
+	Topic          models.WebhookTopic          `json:"topic"`
+	Payload        json.RawMessage              `json:"payload" swaggertype:"object"`
+	OccurredAt     time.Time                    `json:"occurred_at"`
+	Status         models.WebhookDeliveryStatus `json:"status"`
+	Attempts       int                          `json:"attempts"`
+	NextAttemptAt  *time.Time                   `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time                   `json:"last_attempt_at,omitempty"`
+	LastStatusCode int                          `json:"last_status_code,omitempty"`
+	LastResponse   string                       `json:"last_response,omitempty"`
+	LastError      string                       `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                   `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                    `json:"created_at"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDeliveryResponse `json:"deliveries"`
+	Page       int                        `json:"page"`
+	Limit      int                        `json:"limit"`
+	Total      int                        `json:"total"`
+}
+
+// PaymentFailedWebhookData is the data of payment.failed webhook events
+type PaymentFailedWebhookData struct {
+	PaymentID     string               `json:"payment_id"`
+	OrderID       string               `json:"order_id"`
+	Amount        float64              `json:"amount"`
+	Currency      string               `json:"currency"`
+	Method        models.PaymentMethod `json:"method"`
+	Gateway       string               `json:"gateway,omitempty"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+	AttemptCount  int                  `json:"attempt_count"`
+}
+
+// PaymentRefund is part or all of a payment returned to the customer. RefundID names
+// the refund, so that it is posted once.
+type PaymentRefund struct {
+	RefundID string
+	Payment  *models.Payment
+	Amount   float64
+}
+
+// GiftCardRedemption is a gift card balance spent on an order. RedemptionID names
+// the redemption, so that it is posted once.
+type GiftCardRedemption struct {
+	RedemptionID string
+	GiftCardID   string
+	OrderID      string
+	Amount       float64
+	Currency     string
+}
+
+// StoreCreditMovement is store credit issued to a customer, such as for a refund, or
+// redeemed by them on an order. MovementID names the movement, so that it is posted once.
+type StoreCreditMovement struct {
+	MovementID string
+	UserID     string
+	OrderID    string
+	Redeemed   bool
+	Amount     float64
+	Currency   string
+	Reason     string
+}
+
+type LedgerBalanceRequest struct {
+	// An account such as "revenue" or "customer:<user ID>", or a kind of account
+	// such as "customer" or "gateway_clearing" to list every account of the kind
+	Account string `json:"account" form:"account" validate:"required"`
+}
+
+// LedgerAccountBalance is the balance of one ledger account on its normal side:
+// debits less credits for clearing and fee accounts, credits less debits otherwise
+type LedgerAccountBalance struct {
+	Account       string                 `json:"account"`
+	NormalBalance models.LedgerDirection `json:"normal_balance"`
+	Debits        float64                `json:"debits"`
+	Credits       float64                `json:"credits"`
+	Balance       float64                `json:"balance"`
+	Entries       int64                  `json:"entries"`
+}
+
+type LedgerBalancesResponse struct {
+	Account  string                 `json:"account"`
+	Accounts []LedgerAccountBalance `json:"accounts"`
+	Balance  float64                `json:"balance"` // Sum of the accounts' balances
+}
+
+type ListLedgerEntriesRequest struct {
+	Page      int    `json:"page" form:"page"`
+	Limit     int    `json:"limit" form:"limit"`
+	Account   string `json:"account,omitempty" form:"account"`
+	OrderID   string `json:"order_id,omitempty" form:"order_id"`
+	PaymentID string `json:"payment_id,omitempty" form:"payment_id"`
+}
+
+type ListLedgerEntriesResponse struct {
+	Entries []*models.LedgerEntry `json:"entries"`
+	Page    int                   `json:"page"`
+	Limit   int                   `json:"limit"`
+	Total   int                   `json:"total"`
+}
+
+// LedgerConsistencyReport lists what the ledger consistency checks found: ledger
+// transactions whose debits and credits differ, and settled payments and refunds
+// that the ledger is missing or has posted for another amount. Each list is capped.
+type LedgerConsistencyReport struct {
+	Consistent             bool                         `json:"consistent"`
+	CheckedAt              time.Time                    `json:"checked_at"`
+	UnbalancedTransactions []LedgerTransactionImbalance `json:"unbalanced_transactions"`
+	PaymentMismatches      []LedgerPaymentDiscrepancy   `json:"payment_mismatches"`
+	RefundMismatches       []LedgerPaymentDiscrepancy   `json:"refund_mismatches"`
+}
+
+type LedgerTransactionImbalance struct {
+	TransactionKey string  `json:"transaction_key"`
+	Debits         float64 `json:"debits"`
+	Credits        float64 `json:"credits"`
+}
+
+type LedgerPaymentDiscrepancy struct {
+	PaymentID string               `json:"payment_id"`
+	OrderID   string               `json:"order_id"`
+	Status    models.PaymentStatus `json:"status"`
+	Amount    float64              `json:"amount"`
+	Posted    float64              `json:"posted"`
+}
+
+// AuditActor is the user making an audited change and the client they made it from
+type AuditActor struct {
+	UserID    string
+	IPAddress string
+	UserAgent string
+}
+
+// RecordManualPaymentRequest is an offline payment received for an order. Reference
+// is the bank transfer reference or receipt number, unique per method.
+type RecordManualPaymentRequest struct {
+	Method    models.PaymentMethod `json:"method" validate:"required,oneof=bank_transfer cash"`
+	Amount    float64              `json:"amount" validate:"required,gt=0"`
+	Reference string               `json:"reference" validate:"required,max=100"`
+	Note      string               `json:"note,omitempty" validate:"omitempty,max=500"`
+	Proof     *PaymentProofUpload  `json:"proof,omitempty"`
+}
+
+// PaymentProofUpload is a proof of an offline payment, such as a transfer receipt.
+// Content is the file, base64-encoded in JSON.
+type PaymentProofUpload struct {
+	FileName string `json:"file_name" validate:"required,max=255"`
+	Content  []byte `json:"content" validate:"required"`
+}
+
+type ManualPaymentResponse struct {
+	Payment     *PaymentResponse     `json:"payment"`
+	Method      models.PaymentMethod `json:"method"`
+	Reference   string               `json:"reference"`
+	OrderStatus models.OrderStatus   `json:"order_status"`
+	RecordedBy  string               `json:"recorded_by"`
+	Proof       *models.PaymentProof `json:"proof,omitempty"`
+}
+
+// PlaceOrderHoldRequest puts an order on hold, optionally assigning a reviewer
+type PlaceOrderHoldRequest struct {
+	Reason     models.OrderHoldReason `json:"reason" validate:"required,oneof=fraud export_control address_issue"`
+	Note       string                 `json:"note,omitempty" validate:"omitempty,max=1000"`
+	AssignedTo string                 `json:"assigned_to,omitempty" validate:"omitempty,uuid"`
+}
+
+type AssignOrderHoldRequest struct {
+	AssignedTo string `json:"assigned_to" validate:"required,uuid"`
+}
+
+type ReleaseOrderHoldRequest struct {
+	Note string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type ListOrderHoldsRequest struct {
+	Page       int    `json:"page" form:"page"`
+	Limit      int    `json:"limit" form:"limit"`
+	Status     string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=active released"`
+	Reason     string `json:"reason,omitempty" form:"reason" validate:"omitempty,oneof=fraud export_control address_issue"`
+	AssignedTo string `json:"assigned_to,omitempty" form:"assigned_to"`
+	Overdue    bool   `json:"overdue,omitempty" form:"overdue"` // Only active holds past their SLA
+}
+
+// OrderHoldResponse is an order hold; Overdue is set while it is active past DueAt
+type OrderHoldResponse struct {
+	ID          string                 `json:"id"`
+	OrderID     string                 `json:"order_id"`
+	Reason      models.OrderHoldReason `json:"reason"`
+	Note        string                 `json:"note,omitempty"`
+	PlacedBy    string                 `json:"placed_by"`
+	AssignedTo  *string                `json:"assigned_to,omitempty"`
+	AssignedAt  *time.Time             `json:"assigned_at,omitempty"`
+	DueAt       time.Time              `json:"due_at"`
+	Overdue     bool                   `json:"overdue"`
+	ReleasedBy  *string                `json:"released_by,omitempty"`
+	ReleasedAt  *time.Time             `json:"released_at,omitempty"`
+	ReleaseNote string                 `json:"release_note,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+type ListOrderHoldsResponse struct {
+	Holds []*OrderHoldResponse `json:"holds"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+	Total int                  `json:"total"`
+}
+
+type OrderHoldMetricsRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// OrderHoldMetricsResponse reports on the orders placed and holds placed in a date
+// range. HoldRate is the percentage of the orders placed that were held.
+type OrderHoldMetricsResponse struct {
+	StartDate  string                 `json:"start_date"`
+	EndDate    string                 `json:"end_date"`
+	SLAHours   float64                `json:"sla_hours"`
+	Orders     int64                  `json:"orders"`
+	HeldOrders int64                  `json:"held_orders"`
+	HoldRate   float64                `json:"hold_rate"`
+	Reasons    []OrderHoldReasonStats `json:"reasons"`
+	Totals     OrderHoldStats         `json:"totals"`
+}
+
+// OrderHoldReasonStats are the hold statistics of one reason
+type OrderHoldReasonStats struct {
+	Reason models.OrderHoldReason `json:"reason"`
+	OrderHoldStats
+}
+
+// OrderHoldStats sum holds. AverageHoldHours is over released holds; SLACompliance is
+// the percentage of released holds released within the SLA.
+type OrderHoldStats struct {
+	Placed           int64   `json:"placed"`
+	Released         int64   `json:"released"`
+	Active           int64   `json:"active"`
+	Overdue          int64   `json:"overdue"`
+	ReleasedInSLA    int64   `json:"released_in_sla"`
+	AverageHoldHours float64 `json:"average_hold_hours"`
+	SLACompliance    float64 `json:"sla_compliance"`
+}
+
+// StockChangeReason describes why an inventory level changed
+type StockChangeReason string
+
+const (
+	StockChangeReasonReservation StockChangeReason = "reservation"
+	StockChangeReasonRelease     StockChangeReason = "release"
+	StockChangeReasonAdjustment  StockChangeReason = "adjustment"
+)
+
+type CreateStockWebhookSubscriptionRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	URL    string `json:"url" validate:"required,url,max=500"`
+	Secret string `json:"secret" validate:"required,min=16,max=255"`
+	// ProductIDs limits deliveries to these products; empty means all products
+	ProductIDs []string `json:"product_ids,omitempty"`
+}
+
+type StockWebhookSubscriptionResponse struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	URL             string     `json:"url"`
+	ProductIDs      []string   `json:"product_ids"`
+	IsActive        bool       `json:"is_active"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// StockChangeWebhookPayload is the body POSTed to stock webhook subscribers
+type StockChangeWebhookPayload struct {
+	Event      string              `json:"event"`
+	Reason     StockChangeReason   `json:"reason"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Changes    []StockChangeRecord `json:"changes"`
+}
+
+type StockChangeRecord struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Available int    `json:"available"`
+	Quantity  int    `json:"quantity"`
+	Reserved  int    `json:"reserved"`
+}
+
+type ChannelResponse struct {
+	Channel string `json:"channel"`
+	Healthy bool   `json:"healthy"`
+}
+
+type ChannelOrderImportResponse struct {
+	Order *OrderResponse `json:"order"`
+	// Duplicate is true when the channel order had already been imported
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+type CreateChannelListingRequest struct {
+	ProductID  string `json:"product_id" validate:"required"`
+	ChannelSKU string `json:"channel_sku" validate:"required,max=255"`
+	ExternalID string `json:"external_id,omitempty" validate:"omitempty,max=255"`
+}
+
+type ChannelListingResponse struct {
+	ID         string    `json:"id"`
+	Channel    string    `json:"channel"`
+	ChannelSKU string    `json:"channel_sku"`
+	ProductID  string    `json:"product_id"`
+	ExternalID string    `json:"external_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ChannelSyncResponse struct {
+	Channel          string `json:"channel"`
+	InventoryUpdates int    `json:"inventory_updates"`
+	PriceUpdates     int    `json:"price_updates"`
+}
+
+// GenerateSalesReportRequest Report Service DTOs
+type GenerateSalesReportRequest struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type GetSalesReportRequest struct {
+	Date string `json:"date"`
+}
+
+type UpdateMetadataRequest struct {
+	// Metadata is merged into the existing metadata; empty values remove keys
+	Metadata map[string]string `json:"metadata" validate:"required"`
+}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+}
+
+type DailySalesReportQuery struct {
+	Date string `form:"date"`
+	// LowMarginThreshold is the margin percentage below which products are flagged
+	LowMarginThreshold float64 `form:"low_margin_threshold" validate:"omitempty,gte=0,lte=100"`
+	DocumentQuery
+}
+
+type LowStockQuery struct {
+	Threshold int `form:"threshold" validate:"omitempty,gte=0"`
+	DocumentQuery
+}
+
+type LowStockRequest struct {
+	Threshold int `json:"threshold"`
+}
+
+// LowStockResponse lists low-stock products. PerProduct alerts compare each product
+// against its own threshold rather than the single Threshold.
+type LowStockResponse struct {
+	Threshold  int               `json:"threshold"`
+	PerProduct bool              `json:"per_product"`
+	Products   []ProductLowStock `json:"products"`
+	Count      int               `json:"count"`
+}
+
+// RelocateInventoryRequest moves a product's stock to another bin location; an empty
+// location clears it
+type RelocateInventoryRequest struct {
+	Location string `json:"location" validate:"max=50"`
+	Reason   string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// InventoryLocationResponse is the bin location of a product's stock after a
+// relocation. Relocated is false when the stock was already at the location.
+type InventoryLocationResponse struct {
+	ProductID        string `json:"product_id"`
+	Location         string `json:"location"`
+	PreviousLocation string `json:"previous_location"`
+	Relocated        bool   `json:"relocated"`
+}
+
+// InventoryRelocationEntry is a past relocation of a product's stock, from its audit
+// log. UserID is empty for relocations made by the system.
+type InventoryRelocationEntry struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason,omitempty"`
+	UserID    *string   `json:"user_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetSafetyStock sets the units of a product held back from online sales
+type SetSafetyStockRequest struct {
+	SafetyStock *int   `json:"safety_stock" validate:"required,gte=0"`
+	Reason      string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// InventoryStockLevelsResponse breaks a product's stock down for admins. PhysicalStock
+// is the stock on hand, of which Reserved is held for orders and carts and SafetyStock
+// for count errors; Sellable is the rest, offered online.
+type InventoryStockLevelsResponse struct {
+	ProductID     string `json:"product_id"`
+	PhysicalStock int    `json:"physical_stock"`
+	Reserved      int    `json:"reserved"`
+	SafetyStock   int    `json:"safety_stock"`
+	Sellable      int    `json:"sellable"`
+	Location      string `json:"location,omitempty"`
+	Version       int    `json:"version"`
+}
+
+// SetInventoryShardsRequest splits a product's stock into Shards shards, or gathers it
+// back onto one row with 0
+type SetInventoryShardsRequest struct {
+	Shards *int   `json:"shards" validate:"required,gte=0,lte=64"`
+	Reason string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// InventoryShardsResponse shows how a product's sellable stock is spread over its
+// shards. Units released or restocked since the last rebalance are on no shard yet.
+type InventoryShardsResponse struct {
+	ProductID string                   `json:"product_id"`
+	Sellable  int                      `json:"sellable"`
+	Shards    []InventoryShardResponse `json:"shards"`
+}
+
+// InventoryShardResponse is the stock a shard can still reserve
+type InventoryShardResponse struct {
+	Shard     int `json:"shard"`
+	Available int `json:"available"`
+}
+
+// InventoryHistoryRequest pages through a product's inventory history
+type InventoryHistoryRequest struct {
+	Page  int `json:"page" form:"page"`
+	Limit int `json:"limit" form:"limit"`
+}
+
+// InventoryHistoryResponse is a page of a product's inventory history, newest first
+type InventoryHistoryResponse struct {
+	ProductID string                   `json:"product_id"`
+	Entries   []*InventoryHistoryEntry `json:"entries"`
+	Page      int                      `json:"page"`
+	Limit     int                      `json:"limit"`
+	Total     int                      `json:"total"`
+}
+
+// InventoryHistoryEntry is a change to a product's inventory: a reservation, release,
+// fulfillment, adjustment or count with the stock levels after it, or a transfer of
+// the stock between bin locations. ActorID is the user who made the change and the
+// reference what it was made for, e.g. an order, when known.
+type InventoryHistoryEntry struct {
+	Type          string    `json:"type"`
+	Sequence      *int64    `json:"sequence,omitempty"`
+	Quantity      int       `json:"quantity"`
+	OnHand        *int      `json:"on_hand,omitempty"`
+	Reserved      *int      `json:"reserved,omitempty"`
+	Available     *int      `json:"available,omitempty"`
+	FromLocation  *string   `json:"from_location,omitempty"`
+	ToLocation    *string   `json:"to_location,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	ActorID       *string   `json:"actor_id,omitempty"`
+	ReferenceType string    `json:"reference_type,omitempty"`
+	ReferenceID   string    `json:"reference_id,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// InventoryRecountResponse summarizes a bulk inventory recount import. Its counts are
+// recorded in the inventory history against RecountID.
+type InventoryRecountResponse struct {
+	RecountID    string                  `json:"recount_id"`
+	TotalRows    int                     `json:"total_rows"`
+	Adjusted     int                     `json:"adjusted"`
+	Unchanged    int                     `json:"unchanged"`
+	Failed       int                     `json:"failed"`
+	Relocated    int                     `json:"relocated"`
+	NetVariance  int                     `json:"net_variance"`
+	ArtifactPath string                  `json:"artifact_path,omitempty"`
+	Lines        []InventoryVarianceLine `json:"lines"`
+}
+
+// InventoryVarianceLine is the outcome of a single recount row
+type InventoryVarianceLine struct {
+	Row              int    `json:"row"`
+	SKU              string `json:"sku"`
+	ProductID        string `json:"product_id,omitempty"`
+	PreviousQuantity int    `json:"previous_quantity"`
+	CountedQuantity  int    `json:"counted_quantity"`
+	Variance         int    `json:"variance"`
+	Status           string `json:"status"`
+	Error            string `json:"error,omitempty"`
+
+	// Location is the bin location counted, when the row has one; Relocated is set
+	// when the stock was moved there from PreviousLocation
+	Location         string `json:"location,omitempty"`
+	PreviousLocation string `json:"previous_location,omitempty"`
+	Relocated        bool   `json:"relocated,omitempty"`
+}
+
+// StockImportRowsRequest pages through the rows of a stock import
+type StockImportRowsRequest struct {
+	Status string `json:"status" form:"status" validate:"omitempty,oneof=pending accepted rejected"`
+	Page   int    `json:"page" form:"page"`
+	Limit  int    `json:"limit" form:"limit"`
+}
+
+// StockImportResponse is a stock import with a page of its rows
+type StockImportResponse struct {
+	Import *models.StockImport      `json:"import"`
+	Rows   []*models.StockImportRow `json:"rows"`
+	Page   int                      `json:"page"`
+	Limit  int                      `json:"limit"`
+	Total  int                      `json:"total"`
+}
+
+// OrderImportResponse summarizes a historical order import
+type OrderImportResponse struct {
+	TotalRows        int                 `json:"total_rows"`
+	TotalOrders      int                 `json:"total_orders"`
+	Imported         int                 `json:"imported"`
+	Skipped          int                 `json:"skipped"`
+	Failed           int                 `json:"failed"`
+	CustomersCreated int                 `json:"customers_created"`
+	Orders           []OrderImportResult `json:"orders"`
+}
+
+// OrderImportResult is the outcome of one imported order; Rows are its CSV row numbers
+type OrderImportResult struct {
+	OrderRef string `json:"order_ref"`
+	Rows     []int  `json:"rows"`
+	OrderID  string `json:"order_id,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+type CreateOrganizationRequest struct {
+	Name        string  `json:"name" validate:"required,max=255"`
+	CreditLimit float64 `json:"credit_limit" validate:"gte=0"`
+	// PaymentTermsDays are the net days to pay on-account orders; 0 disables ordering on account
+	PaymentTermsDays int `json:"payment_terms_days" validate:"gte=0,lte=365"`
+	// CreditLimitAction is reject (the default) or hold for orders over the credit limit
+	CreditLimitAction models.CreditLimitAction `json:"credit_limit_action,omitempty" validate:"omitempty,oneof=reject hold"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name              *string                   `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	CreditLimit       *float64                  `json:"credit_limit,omitempty" validate:"omitempty,gte=0"`
+	PaymentTermsDays  *int                      `json:"payment_terms_days,omitempty" validate:"omitempty,gte=0,lte=365"`
+	CreditLimitAction *models.CreditLimitAction `json:"credit_limit_action,omitempty" validate:"omitempty,oneof=reject hold"`
+	IsActive          *bool                     `json:"is_active,omitempty"`
+}
+
+type ListOrganizationsRequest struct {
+	Page  int `json:"page" form:"page"`
+	Limit int `json:"limit" form:"limit"`
+}
+
+type AddOrganizationMemberRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+}
+
+// OrganizationResponse is an organization; its balances and members are only
+// included when a single organization is requested
+type OrganizationResponse struct {
+	ID                 string                   `json:"id"`
+	Name               string                   `json:"name"`
+	CreditLimit        float64                  `json:"credit_limit"`
+	CreditLimitAction  models.CreditLimitAction `json:"credit_limit_action"`
+	PaymentTermsDays   int                      `json:"payment_terms_days"`
+	PriceListID        *string                  `json:"price_list_id,omitempty"`
+	OutstandingBalance *float64                 `json:"outstanding_balance,omitempty"`
+	AvailableCredit    *float64                 `json:"available_credit,omitempty"`
+	IsActive           bool                     `json:"is_active"`
+	Members            []OrganizationMember     `json:"members,omitempty"`
+	CreatedAt          time.Time                `json:"created_at"`
+}
+
+// Credit hold review decisions
+const (
+	CreditReviewApprove = "approve"
+	CreditReviewReject  = "reject"
+)
+
+// ReviewCreditHoldRequest approves an order held over its organization's credit
+// limit, overriding the limit, or rejects it, which cancels the order
+type ReviewCreditHoldRequest struct {
+	Decision string `json:"decision" validate:"required,oneof=approve reject"`
+}
+
+type OrganizationMember struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type ListOrganizationsResponse struct {
+	Organizations []*OrganizationResponse `json:"organizations"`
+	Page          int                     `json:"page"`
+	Limit         int                     `json:"limit"`
+	Total         int                     `json:"total"`
+}
+
+type CreatePriceListRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description,omitempty"`
+}
+
+type UpdatePriceListRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string `json:"description,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+type ListPriceListsRequest struct {
+	Page  int `json:"page" form:"page"`
+	Limit int `json:"limit" form:"limit"`
+}
+
+// AssignPriceListRequest sets the price list of an organization or customer; an
+// empty price list ID clears it
+type AssignPriceListRequest struct {
+	PriceListID string `json:"price_list_id,omitempty"`
+}
+
+// PriceListResponse is a price list; its rows are only included when a single price
+// list is requested
+type PriceListResponse struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	IsActive    bool                 `json:"is_active"`
+	Items       []*PriceListItemLine `json:"items,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// PriceListItemLine is the price of a product in a price list over its effective
+// range; an open end is unbounded
+type PriceListItemLine struct {
+	ID            string     `json:"id"`
+	ProductID     string     `json:"product_id"`
+	Price         float64    `json:"price"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+}
+
+type ListPriceListsResponse struct {
+	PriceLists []*PriceListResponse `json:"price_lists"`
+	Page       int                  `json:"page"`
+	Limit      int                  `json:"limit"`
+	Total      int                  `json:"total"`
+}
+
+// PriceListImportResponse summarizes a price list import; Imported is zero when any
+// row failed, as nothing is imported then
+type PriceListImportResponse struct {
+	TotalRows int                  `json:"total_rows"`
+	Imported  int                  `json:"imported"`
+	Failed    int                  `json:"failed"`
+	Replaced  bool                 `json:"replaced"`
+	Rows      []PriceListImportRow `json:"rows"`
+}
+
+// PriceListImportRow is the outcome of one CSV row of a price list import
+type PriceListImportRow struct {
+	Row           int        `json:"row"`
+	SKU           string     `json:"sku"`
+	ProductID     string     `json:"product_id,omitempty"`
+	Price         float64    `json:"price"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	Status        string     `json:"status"`
+	Error         string     `json:"error,omitempty"`
+}
+
+type UserImportResponse struct {
+	TotalRows            int                `json:"total_rows"`
+	Created              int                `json:"created"`
+	Updated              int                `json:"updated"`
+	Skipped              int                `json:"skipped"`
+	Failed               int                `json:"failed"`
+	OrganizationsCreated int                `json:"organizations_created"`
+	Users                []UserImportResult `json:"users"`
+}
+
+// UserImportResult is the outcome of one CSV row of a user import
+type UserImportResult struct {
+	Row            int    `json:"row"`
+	Email          string `json:"email"`
+	UserID         string `json:"user_id,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ProductLowStock is a product low on stock. CurrentStock is the stock sellable online,
+// compared to the threshold; PhysicalStock is the stock on hand, including the reserved
+// units and the SafetyStock held back.
+type ProductLowStock struct {
+	ProductID     string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	SKU           string `json:"sku"`
+	CurrentStock  int    `json:"current_stock"`
+	PhysicalStock int    `json:"physical_stock"`
+	SafetyStock   int    `json:"safety_stock"`
+	MinThreshold  int    `json:"min_threshold"`
+
+	// Per-product alerts only: the threshold applied and where it came from, and the
+	// average units sold per day with the days of stock left at that rate
+	Threshold       int      `json:"threshold,omitempty"`
+	ThresholdSource string   `json:"threshold_source,omitempty"`
+	DailyVelocity   float64  `json:"daily_velocity,omitempty"`
+	DaysOfCover     *float64 `json:"days_of_cover,omitempty"`
+}
+
+type LowStockItem struct {
+	ProductID     string `json:"product_id"`
+	ProductName   string `json:"product_name"`
+	ProductSKU    string `json:"product_sku"`
+	CurrentStock  int    `json:"current_stock"`
+	PhysicalStock int    `json:"physical_stock"`
+	SafetyStock   int    `json:"safety_stock"`
+	MinStock      int    `json:"min_stock"`
+}
+
+// SalesReportResponse reports gross sales (TotalSales/GrossSales) alongside
+// net sales after refunds. ListSales is what the items sold would have been charged at
+// their list prices, and PriceOverrides the part of it given away by price overrides.
+type SalesReportResponse struct {
+	Date              string                  `json:"date"`
+	TotalSales        float64                 `json:"total_sales"`
+	GrossSales        float64                 `json:"gross_sales"`
+	ListSales         float64                 `json:"list_sales"`
+	PriceOverrides    float64                 `json:"price_overrides"`
+	RefundedAmount    float64                 `json:"refunded_amount"`
+	NetSales          float64                 `json:"net_sales"`
+	CostOfGoods       float64                 `json:"cost_of_goods"`
+	GrossMargin       float64                 `json:"gross_margin"`
+	MarginPercent     float64                 `json:"margin_percent"`
+	TotalOrders       int                     `json:"total_orders"`
+	CompletedOrders   int                     `json:"completed_orders"`
+	CancelledOrders   int                     `json:"cancelled_orders"`
+	ReturnedOrders    int                     `json:"returned_orders"`
+	ReturnRate        float64                 `json:"return_rate"`
+	AverageOrderValue float64                 `json:"average_order_value"`
+	OrdersByStatus    map[string]int          `json:"orders_by_status"`
+	OrdersByChannel   map[string]int          `json:"orders_by_channel"`
+	SalesByChannel    map[string]float64      `json:"sales_by_channel"`
+	ProductSales      []ProductSalesSummary   `json:"product_sales"`
+	CategoryMargins   []CategoryMarginSummary `json:"category_margins"`
+	// LowMarginThreshold and LowMarginProducts flag products selling below the margin threshold
+	LowMarginThreshold float64                `json:"low_margin_threshold"`
+	LowMarginProducts  []string               `json:"low_margin_products"`
+	Report             map[string]interface{} `json:"report"`
+}
+
+// ProductSalesSummary breaks down a product's sales and returns for a period
+type ProductSalesSummary struct {
+	ProductID        string  `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	SKU              string  `json:"sku"`
+	QuantitySold     int     `json:"quantity_sold"`
+	QuantityReturned int     `json:"quantity_returned"`
+	GrossRevenue     float64 `json:"gross_revenue"`
+	// ListRevenue is the units sold at their list prices; PriceOverrideDiscount is the
+	// difference to the prices charged on items whose price was overridden
+	ListRevenue           float64 `json:"list_revenue"`
+	PriceOverrideDiscount float64 `json:"price_override_discount"`
+	RefundedAmount        float64 `json:"refunded_amount"`
+	NetRevenue            float64 `json:"net_revenue"`
+	ReturnRate            float64 `json:"return_rate"`
+	CostOfGoods           float64 `json:"cost_of_goods"`
+	GrossMargin           float64 `json:"gross_margin"`
+	MarginPercent         float64 `json:"margin_percent"`
+	LowMargin             bool    `json:"low_margin"`
+}
+
+// CategoryMarginSummary aggregates net revenue and margin per product category
+type CategoryMarginSummary struct {
+	CategoryID    string  `json:"category_id"`
+	NetRevenue    float64 `json:"net_revenue"`
+	CostOfGoods   float64 `json:"cost_of_goods"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+type InventoryReportResponse struct {
+	TotalProducts      int                    `json:"total_products"`
+	ActiveProducts     int                    `json:"active_products"`
+	LowStockProducts   int                    `json:"low_stock_products"`
+	OutOfStockProducts int                    `json:"out_of_stock_products"`
+	TotalStockValue    float64                `json:"total_stock_value"`
+	TotalStockQuantity int                    `json:"total_stock_quantity"`
+	ProductInventory   []ProductInventoryItem `json:"product_inventory"`
+}
+
+// UserActivityReportRequest selects an inclusive range of UTC days
+type UserActivityReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+	DocumentQuery
+}
+
+// UserActivityReportResponse summarizes engagement from activity events.
+// ActiveUsers counts users with any event; NewUsers counts registrations.
+type UserActivityReportResponse struct {
+	StartDate        string                 `json:"start_date"`
+	EndDate          string                 `json:"end_date"`
+	ActiveUsers      int                    `json:"active_users"`
+	NewUsers         int                    `json:"new_users"`
+	PurchasingUsers  int                    `json:"purchasing_users"`
+	ConversionRate   float64                `json:"conversion_rate"` // Percentage of active users who placed an order
+	TotalEvents      int                    `json:"total_events"`
+	EventsPerUser    float64                `json:"events_per_user"`
+	Engagement       []ActivityEngagement   `json:"engagement"`
+	DailyActiveUsers []DailyActiveUsersItem `json:"daily_active_users"`
+}
+
+// ActivityEngagement breaks down one activity type
+type ActivityEngagement struct {
+	Type   string `json:"type"`
+	Events int    `json:"events"`
+	Users  int    `json:"users"`
+}
+
+// DailyActiveUsersItem is the number of distinct active users on a day
+type DailyActiveUsersItem struct {
+	Date        string `json:"date"`
+	ActiveUsers int    `json:"active_users"`
+}
+
+// AgentSalesReportRequest selects an inclusive range of UTC days by order placement date
+type AgentSalesReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// AgentSalesReportResponse sums the sales of the phone orders each agent placed, for
+// commission. Net revenue is revenue less refunds.
+type AgentSalesReportResponse struct {
+	StartDate string           `json:"start_date"`
+	EndDate   string           `json:"end_date"`
+	Agents    []AgentSalesItem `json:"agents"`
+	Totals    AgentSalesTotals `json:"totals"`
+}
+
+// AgentSalesItem is the sales of the phone orders placed by one agent
+type AgentSalesItem struct {
+	AgentID    string `json:"agent_id"`
+	AgentEmail string `json:"agent_email,omitempty"`
+	AgentSalesTotals
+}
+
+type AgentSalesTotals struct {
+	Orders         int     `json:"orders"`
+	Revenue        float64 `json:"revenue"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	NetRevenue     float64 `json:"net_revenue"`
+}
+
+// SettlementReportRequest selects an inclusive range of UTC days by payment processing date
+type SettlementReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// SettlementReportResponse reconciles payment method surcharges and discounts against gateway fees
+type SettlementReportResponse struct {
+	StartDate string                    `json:"start_date"`
+	EndDate   string                    `json:"end_date"`
+	Methods   []SettlementMethodSummary `json:"methods"`
+	Totals    SettlementTotals          `json:"totals"`
+}
+
+// SettlementMethodSummary is the settlement of the payments made with one method
+type SettlementMethodSummary struct {
+	Method models.PaymentMethod `json:"method"`
+	SettlementTotals
+}
+
+// SettlementTotals sums settled payments. NetAdjustment is surcharges less discounts;
+// FeeVariance is NetAdjustment less ProcessingFees, negative when fees are not recovered.
+type SettlementTotals struct {
+	Payments       int     `json:"payments"`
+	GrossAmount    float64 `json:"gross_amount"`
+	Surcharges     float64 `json:"surcharges"`
+	Discounts      float64 `json:"discounts"`
+	NetAdjustment  float64 `json:"net_adjustment"`
+	ProcessingFees float64 `json:"processing_fees"`
+	NetSettlement  float64 `json:"net_settlement"`
+	FeeVariance    float64 `json:"fee_variance"`
+}
+
+// PaymentFailureReportRequest selects an inclusive range of UTC days by payment attempt start time
+type PaymentFailureReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// PaymentFailureReportResponse breaks payment attempt failures down by decline reason,
+// gateway, payment method and UTC hour of day
+type PaymentFailureReportResponse struct {
+	StartDate     string                          `json:"start_date"`
+	EndDate       string                          `json:"end_date"`
+	Totals        PaymentFailureStats             `json:"totals"`
+	ByFailureType []PaymentFailureTypeSummary     `json:"by_failure_type"`
+	ByCategory    []PaymentFailureCategorySummary `json:"by_category"`
+	ByGateway     []PaymentFailureBreakdown       `json:"by_gateway"`
+	ByMethod      []PaymentFailureBreakdown       `json:"by_method"`
+	ByHour        []PaymentFailureHourSummary     `json:"by_hour"`
+}
+
+// PaymentFailureStats counts payment attempts. A retry is any attempt after the first
+// for the same order; rates are percentages.
+type PaymentFailureStats struct {
+	Attempts         int     `json:"attempts"`
+	Failures         int     `json:"failures"`
+	FailureRate      float64 `json:"failure_rate"`
+	Retries          int     `json:"retries"`
+	RetrySuccesses   int     `json:"retry_successes"`
+	RetrySuccessRate float64 `json:"retry_success_rate"`
+}
+
+// PaymentFailureTypeSummary counts the failures of one decline reason. Share is the
+// percentage of all failures; Retriable follows the default retry policy.
+type PaymentFailureTypeSummary struct {
+	FailureType string                   `json:"failure_type"`
+	Category    payments.FailureCategory `json:"category"`
+	Retriable   bool                     `json:"retriable"`
+	Failures    int                      `json:"failures"`
+	Share       float64                  `json:"share"`
+}
+
+// PaymentFailureCategorySummary counts the failures in one taxonomy category
+type PaymentFailureCategorySummary struct {
+	Category payments.FailureCategory `json:"category"`
+	Failures int                      `json:"failures"`
+	Share    float64                  `json:"share"`
+}
+
+// PaymentFailureBreakdown is the attempt statistics of one gateway or payment method
+type PaymentFailureBreakdown struct {
+	Key string `json:"key"`
+	PaymentFailureStats
+}
+
+// PaymentFailureHourSummary is the attempt statistics of one UTC hour of day
+type PaymentFailureHourSummary struct {
+	Hour int `json:"hour"`
+	PaymentFailureStats
+}
+
+// NotificationDeliveryReportRequest selects an inclusive range of UTC days by
+// notification creation time
+type NotificationDeliveryReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// NotificationDeliveryReportResponse shows how notifications fared from sending to
+// reading, by notification type, by the channel that delivered them and by both
+type NotificationDeliveryReportResponse struct {
+	StartDate        string                            `json:"start_date"`
+	EndDate          string                            `json:"end_date"`
+	Totals           NotificationDeliveryStats         `json:"totals"`
+	ByType           []NotificationDeliveryBreakdown   `json:"by_type"`
+	ByChannel        []NotificationDeliveryBreakdown   `json:"by_channel"`
+	ByTypeAndChannel []NotificationDeliveryTypeChannel `json:"by_type_and_channel"`
+}
+
+// NotificationDeliveryStats counts notifications through sending, delivery and
+// reading. The delivery rate is the percentage of sent notifications that were
+// delivered, and the read rate the percentage of delivered ones that were read.
+// Average times are in minutes.
+type NotificationDeliveryStats struct {
+	Notifications       int     `json:"notifications"`
+	Sent                int     `json:"sent"`
+	Delivered           int     `json:"delivered"`
+	Read                int     `json:"read"`
+	DeliveryRate        float64 `json:"delivery_rate"`
+	ReadRate            float64 `json:"read_rate"`
+	AvgMinutesToDeliver float64 `json:"avg_minutes_to_deliver"`
+	AvgMinutesToRead    float64 `json:"avg_minutes_to_read"`
+}
+
+// NotificationDeliveryBreakdown is the delivery statistics of one notification type or channel
+type NotificationDeliveryBreakdown struct {
+	Key string `json:"key"`
+	NotificationDeliveryStats
+}
+
+// NotificationDeliveryTypeChannel is the delivery statistics of one notification
+// type on one channel
+type NotificationDeliveryTypeChannel struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	NotificationDeliveryStats
+}
+
+// ARAgingReportResponse ages the open on-account invoices of each organization by
+// days past their due date, as of today (UTC)
+type ARAgingReportResponse struct {
+	AsOf          string                `json:"as_of"`
+	Totals        ARAgingBuckets        `json:"totals"`
+	Organizations []ARAgingOrganization `json:"organizations"`
+}
+
+// ARAgingBuckets sums open invoice amounts by days past due; Current is not yet due
+type ARAgingBuckets struct {
+	Current    float64 `json:"current"`
+	Days1To30  float64 `json:"days_1_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	Days61To90 float64 `json:"days_61_90"`
+	Over90     float64 `json:"over_90"`
+	Total      float64 `json:"total"`
+}
+
+// ARAgingOrganization is the receivables aging of one organization
+type ARAgingOrganization struct {
+	OrganizationID   string    `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	OpenInvoices     int       `json:"open_invoices"`
+	OldestDueAt      time.Time `json:"oldest_due_at"`
+	ARAgingBuckets
+}
+
+// CreditExposureReportResponse compares the outstanding balance of each organization
+// ordering on account with its credit limit, most utilized first
+type CreditExposureReportResponse struct {
+	Totals        CreditExposureTotals         `json:"totals"`
+	Organizations []CreditExposureOrganization `json:"organizations"`
+}
+
+type CreditExposureTotals struct {
+	CreditLimit        float64 `json:"credit_limit"`
+	Outstanding        float64 `json:"outstanding"`
+	HeldOrders         int     `json:"held_orders"`
+	HeldAmount         float64 `json:"held_amount"`
+	OverLimitAccounts  int     `json:"over_limit_accounts"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+}
+
+// CreditExposureOrganization is the credit position of one organization. Held orders
+// are not part of the outstanding balance until they are approved.
+type CreditExposureOrganization struct {
+	OrganizationID     string                   `json:"organization_id"`
+	OrganizationName   string                   `json:"organization_name"`
+	CreditLimit        float64                  `json:"credit_limit"`
+	CreditLimitAction  models.CreditLimitAction `json:"credit_limit_action"`
+	Outstanding        float64                  `json:"outstanding"`
+	AvailableCredit    float64                  `json:"available_credit"`
+	UtilizationPercent float64                  `json:"utilization_percent"`
+	OverLimit          bool                     `json:"over_limit"`
+	HeldOrders         int                      `json:"held_orders"`
+	HeldAmount         float64                  `json:"held_amount"`
+}
+
+// ShippingSLAReportResponse tracks unshipped orders against the ship date their store
+// promised when they were placed: orders past it, and orders due within the next day
+type ShippingSLAReportResponse struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Totals      ShippingSLATotals  `json:"totals"`
+	Stores      []ShippingSLAStore `json:"stores"`
+	Orders      []ShippingSLAOrder `json:"orders"`
+}
+
+type ShippingSLATotals struct {
+	Overdue int `json:"overdue"`
+	DueSoon int `json:"due_soon"`
+}
+
+// ShippingSLAStore counts the orders of one store, identified by its order channel
+type ShippingSLAStore struct {
+	Channel            string  `json:"channel"`
+	Overdue            int     `json:"overdue"`
+	DueSoon            int     `json:"due_soon"`
+	OldestOverdueHours float64 `json:"oldest_overdue_hours"`
+}
+
+// ShippingSLAOrder is an unshipped order; HoursOverdue is 0 until its promise passes
+type ShippingSLAOrder struct {
+	OrderID        string             `json:"order_id"`
+	Channel        string             `json:"channel"`
+	Status         models.OrderStatus `json:"status"`
+	PromisedShipBy time.Time          `json:"promised_ship_by"`
+	Overdue        bool               `json:"overdue"`
+	HoursOverdue   float64            `json:"hours_overdue"`
+}
+
+// ProductAvailabilityResponse is a product's storefront availability. Stale is set
+// when inventory could not be re-read and an entry older than the staleness bound is served.
+type ProductAvailabilityResponse struct {
+	ProductID string    `json:"product_id"`
+	Available int       `json:"available"`
+	InStock   bool      `json:"in_stock"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Stale     bool      `json:"stale,omitempty"`
+}
+
+// ProductInventoryItem represents inventory information for a product
+type ProductInventoryItem struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	SKU           string  `json:"sku"`
+	Price         float64 `json:"price"`
+	StockQuantity int     `json:"stock_quantity"`
+	StockValue    float64 `json:"stock_value"`
+	Status        string  `json:"status"`
+}
+
+// TopProductsResponse represents the response for the top products report
+type TopProductsResponse struct {
+	TopProducts []*TopProductItem `json:"top_products"`
+	Limit       int               `json:"limit"`
+	Period      string            `json:"period"`
+}
+
+// TopProductItem represents a single product in the top products report
+type TopProductItem struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
 	TotalQuantity int     `json:"total_quantity"`
 	TotalRevenue  float64 `json:"total_revenue"`
 	OrderCount    int     `json:"order_count"`
 }
+
+// QueueReportRequest requests a report generated in the background. Parameters are
+// those of the report type, such as date for daily sales or limit for top products.
+type QueueReportRequest struct {
+	Type       string                 `json:"type" validate:"required,oneof=daily_sales weekly_sales monthly_sales revenue top_products low_stock inventory_value customer_activity order_analytics payment_reconciliation"`
+	Format     string                 `json:"format,omitempty" validate:"omitempty,oneof=json pdf"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Timezone   string                 `json:"timezone,omitempty"`
+	Locale     string                 `json:"locale,omitempty"`
+}
+
+// ReportResultResponse is the status of a queued report and, once completed, its
+// data. PDF reports are downloaded as their rendered file instead.
+type ReportResultResponse struct {
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	Format       string      `json:"format"`
+	Status       string      `json:"status"`
+	Data         interface{} `json:"data,omitempty"`
+	FileSize     int64       `json:"file_size,omitempty"`
+	RowCount     int         `json:"row_count,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	ProcessingMs int64       `json:"processing_ms"`
+	GeneratedAt  *time.Time  `json:"generated_at,omitempty"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty"`
+}
+
+// ReportDownload is the rendered file of a completed report: either its Content,
+// which the caller closes, or a signed URL to download it from
+type ReportDownload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+	URL         string
+}
+
+// CreateAPIKeyRequest names a new API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// APIKeyResponse is an API key without its secret. UsedThisMonth counts the requests
+// made with it this calendar month (UTC), and Remaining what is left of its quota.
+type APIKeyResponse struct {
+	ID            string     `json:"id"`
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	MonthlyQuota  *int       `json:"monthly_quota,omitempty"`
+	UsedThisMonth int64      `json:"used_this_month"`
+	Remaining     *int64     `json:"remaining,omitempty"`
+	Active        bool       `json:"active"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is a new API key with its secret, which is shown only once
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// SetAPIKeyQuotaRequest sets the monthly request quota of an API key; a nil quota
+// leaves the key unlimited
+type SetAPIKeyQuotaRequest struct {
+	MonthlyQuota *int `json:"monthly_quota" validate:"omitempty,gte=1"`
+}
+
+// APIUsageRequest selects an inclusive range of UTC days and, optionally, one API key
+// or user
+type APIUsageRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+	APIKeyID  string `json:"api_key_id" form:"api_key_id"`
+	UserID    string `json:"user_id" form:"user_id"`
+}
+
+// APIUsageStats counts requests, those answered with a server error and those refused
+// for an exhausted quota. Latencies are in milliseconds, and the error rate is the
+// percentage of requests that failed.
+type APIUsageStats struct {
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"`
+	Rejected     int64   `json:"rejected"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	MaxLatencyMs int64   `json:"max_latency_ms"`
+}
+
+// APIEndpointUsage is the usage of one endpoint
+type APIEndpointUsage struct {
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	APIUsageStats
+}
+
+// APICallerUsage is the usage of one caller: an API key, a user signed in with a token
+// (no API key), or anonymous requests (neither)
+type APICallerUsage struct {
+	APIKeyID string `json:"api_key_id,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+	APIUsageStats
+}
+
+// APIUsageReportResponse shows API usage by endpoint and by caller. Usage is written
+// periodically, so the latest requests may not be included yet.
+type APIUsageReportResponse struct {
+	StartDate  string             `json:"start_date"`
+	EndDate    string             `json:"end_date"`
+	Totals     APIUsageStats      `json:"totals"`
+	ByEndpoint []APIEndpointUsage `json:"by_endpoint"`
+	ByCaller   []APICallerUsage   `json:"by_caller"`
+}
+
+// ReferralCodeResponse is the referral code a user shares
+type ReferralCodeResponse struct {
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReferralResponse is one of a user's referrals. Referees are not identified.
+type ReferralResponse struct {
+	ID           string     `json:"id"`
+	Status       string     `json:"status"`
+	OrderedAt    *time.Time `json:"ordered_at,omitempty"`
+	RewardAmount float64    `json:"reward_amount"`
+	RewardedAt   *time.Time `json:"rewarded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// MyReferralsResponse is a user's referral code with the referrals made with it and
+// the store credit they earned
+type MyReferralsResponse struct {
+	Code          string             `json:"code"`
+	Signups       int                `json:"signups"`
+	Rewarded      int                `json:"rewarded"`
+	RewardsEarned float64            `json:"rewards_earned"`
+	Referrals     []ReferralResponse `json:"referrals"`
+}
+
+// StoreCreditResponse is a user's store credit balance with their latest transactions
+type StoreCreditResponse struct {
+	Balance      float64                         `json:"balance"`
+	Transactions []models.StoreCreditTransaction `json:"transactions"`
+}
+
+// ReferralRewardRunResponse counts what a reward run did: referrals rewarded, voided
+// for a returned order and freed for the next order after a cancelled one
+type ReferralRewardRunResponse struct {
+	Rewarded int     `json:"rewarded"`
+	Voided   int     `json:"voided"`
+	Released int     `json:"released"`
+	Credited float64 `json:"credited"`
+}
+
+// ReferralReportRequest selects an inclusive range of UTC days by referral signup time
+type ReferralReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// ReferralStats counts referred signups, those who placed a first order and those
+// rewarded or voided. The conversion rate is the percentage of signups who placed a
+// first order; revenue sums those orders unless cancelled.
+type ReferralStats struct {
+	Signups        int64   `json:"signups"`
+	Ordered        int64   `json:"ordered"`
+	Rewarded       int64   `json:"rewarded"`
+	Voided         int64   `json:"voided"`
+	ConversionRate float64 `json:"conversion_rate"`
+	Rewards        float64 `json:"rewards"`
+	Revenue        float64 `json:"revenue"`
+}
+
+// ReferrerPerformance is the performance of one referrer's referrals
+type ReferrerPerformance struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	ReferralStats
+}
+
+// ReferralReportResponse shows how the referral program performed, overall and by
+// referrer, most rewarded first
+type ReferralReportResponse struct {
+	StartDate  string                `json:"start_date"`
+	EndDate    string                `json:"end_date"`
+	Totals     ReferralStats         `json:"totals"`
+	ByReferrer []ReferrerPerformance `json:"by_referrer"`
+}
+
+// CreateBlocklistEntryRequest blocks a value. Email addresses and domains are matched
+// regardless of case, a domain also blocks its subdomains, and IP ranges are an
+// address or a CIDR range.
+type CreateBlocklistEntryRequest struct {
+	Type      string     `json:"type" validate:"required,oneof=email domain card_fingerprint ip_range"`
+	Value     string     `json:"value" validate:"required,max=255"`
+	Reason    string     `json:"reason,omitempty" validate:"omitempty,max=255"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ListBlocklistEntriesRequest struct {
+	Page   int    `json:"page" form:"page"`
+	Limit  int    `json:"limit" form:"limit"`
+	Type   string `json:"type,omitempty" form:"type" validate:"omitempty,oneof=email domain card_fingerprint ip_range"`
+	Search string `json:"search,omitempty" form:"search" validate:"omitempty,max=255"`
+}
+
+type ListBlocklistEntriesResponse struct {
+	Entries []*models.BlocklistEntry `json:"entries"`
+	Page    int                      `json:"page"`
+	Limit   int                      `json:"limit"`
+	Total   int                      `json:"total"`
+}
+
+// FraudCheckpointMetrics counts the attempts screened and blocked at a checkpoint;
+// ByType counts blocked attempts by the type of entry that matched, so an attempt
+// matching entries of several types counts once per type
+type FraudCheckpointMetrics struct {
+	Checkpoint FraudCheckpoint                `json:"checkpoint"`
+	Screened   int64                          `json:"screened"`
+	Blocked    int64                          `json:"blocked"`
+	BlockRate  float64                        `json:"block_rate"`
+	ByType     map[models.BlocklistType]int64 `json:"by_type"`
+}
+
+// BlocklistMetricsResponse reports blocked attempts since Since, when the service
+// started, and the entries that blocked the most attempts overall
+// CreatePromotionRequest defines a promotion. Percent off promotions need a percent,
+// category BOGO promotions a category; free shipping promotions usually set a minimum
+// subtotal as their threshold.
+type CreatePromotionRequest struct {
+	Name           string               `json:"name" validate:"required,max=255"`
+	Description    string               `json:"description,omitempty"`
+	Type           models.PromotionType `json:"type" validate:"required,oneof=percent_off category_bogo free_shipping"`
+	PercentOff     float64              `json:"percent_off,omitempty" validate:"gte=0,lte=100"`
+	CategoryID     *string              `json:"category_id,omitempty" validate:"omitempty,uuid"`
+	MinSubtotal    float64              `json:"min_subtotal,omitempty" validate:"gte=0"`
+	FirstOrderOnly bool                 `json:"first_order_only,omitempty"`
+	Channel        string               `json:"channel,omitempty" validate:"omitempty,max=50"`
+	Priority       int                  `json:"priority,omitempty"`
+	Exclusive      bool                 `json:"exclusive,omitempty"`
+	StackGroup     string               `json:"stack_group,omitempty" validate:"omitempty,max=50"`
+	StartsAt       time.Time            `json:"starts_at" validate:"required"`
+	EndsAt         time.Time            `json:"ends_at" validate:"required"`
+}
+
+// UpdatePromotionRequest changes the fields that are set; the type of a promotion
+// cannot change
+type UpdatePromotionRequest struct {
+	Name           *string    `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string    `json:"description,omitempty"`
+	PercentOff     *float64   `json:"percent_off,omitempty" validate:"omitempty,gte=0,lte=100"`
+	CategoryID     *string    `json:"category_id,omitempty" validate:"omitempty,uuid"`
+	MinSubtotal    *float64   `json:"min_subtotal,omitempty" validate:"omitempty,gte=0"`
+	FirstOrderOnly *bool      `json:"first_order_only,omitempty"`
+	Channel        *string    `json:"channel,omitempty" validate:"omitempty,max=50"`
+	Priority       *int       `json:"priority,omitempty"`
+	Exclusive      *bool      `json:"exclusive,omitempty"`
+	StackGroup     *string    `json:"stack_group,omitempty" validate:"omitempty,max=50"`
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	IsActive       *bool      `json:"is_active,omitempty"`
+}
+
+// ListPromotionsRequest lists promotions, only those running now with Running
+type ListPromotionsRequest struct {
+	Page    int  `json:"page" form:"page"`
+	Limit   int  `json:"limit" form:"limit"`
+	Running bool `json:"running,omitempty" form:"running"`
+}
+
+type ListPromotionsResponse struct {
+	Promotions []*models.Promotion `json:"promotions"`
+	Page       int                 `json:"page"`
+	Limit      int                 `json:"limit"`
+	Total      int                 `json:"total"`
+}
+
+// AppliedPromotion is a promotion applied to an order at checkout with the discount
+// it gave; for free shipping, the fee waived
+type AppliedPromotion struct {
+	PromotionID string               `json:"promotion_id"`
+	Name        string               `json:"name"`
+	Type        models.PromotionType `json:"type"`
+	Discount    float64              `json:"discount"`
+}
+
+// PromotionReportRequest selects an inclusive range of UTC days by order time
+type PromotionReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// PromotionPerformance is how one promotion performed. Orders counts the orders it
+// was applied to; Redeemed, Discount and Revenue leave out cancelled and failed
+// ones. The average order value is of the redeemed orders.
+type PromotionPerformance struct {
+	PromotionID       string               `json:"promotion_id"`
+	Name              string               `json:"name"`
+	Type              models.PromotionType `json:"type"`
+	Orders            int64                `json:"orders"`
+	Redeemed          int64                `json:"redeemed"`
+	Discount          float64              `json:"discount"`
+	Revenue           float64              `json:"revenue"`
+	AverageOrderValue float64              `json:"average_order_value"`
+}
+
+// PromotionReportResponse shows how promotions performed, most redeemed first. The
+// totals add up the promotions, so an order with two promotions counts twice.
+type PromotionReportResponse struct {
+	StartDate  string                 `json:"start_date"`
+	EndDate    string                 `json:"end_date"`
+	Orders     int64                  `json:"orders"`
+	Redeemed   int64                  `json:"redeemed"`
+	Discount   float64                `json:"discount"`
+	Promotions []PromotionPerformance `json:"promotions"`
+}
+
+type BlocklistMetricsResponse struct {
+	Since       time.Time                `json:"since"`
+	Checkpoints []FraudCheckpointMetrics `json:"checkpoints"`
+	TopEntries  []*models.BlocklistEntry `json:"top_entries"`
+}
+
+// PlanDeliverySlotsRequest plans the same delivery windows for every day of an
+// inclusive range of days (YYYY-MM-DD) in a region
+type PlanDeliverySlotsRequest struct {
+	Region    string           `json:"region" validate:"required,max=100"`
+	StartDate string           `json:"start_date" validate:"required"`
+	EndDate   string           `json:"end_date" validate:"required"`
+	Windows   []DeliveryWindow `json:"windows" validate:"required,min=1,max=24,dive"`
+}
+
+// DeliveryWindow is a delivery window of a day from Start to End (HH:MM), taking up
+// to Capacity orders
+type DeliveryWindow struct {
+	Start    string `json:"start" validate:"required"`
+	End      string `json:"end" validate:"required"`
+	Capacity int    `json:"capacity" validate:"gte=0"`
+}
+
+// UpdateDeliverySlotRequest changes the fields that are set. The capacity cannot go
+// below the orders already booked into the slot.
+type UpdateDeliverySlotRequest struct {
+	Capacity *int  `json:"capacity,omitempty" validate:"omitempty,gte=0"`
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// ListDeliverySlotsRequest selects the slots of an inclusive range of days (default:
+// the next 14 days) and of a region unless it is empty
+type ListDeliverySlotsRequest struct {
+	Region    string `json:"region" form:"region"`
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// DeliverySlotResponse is a delivery slot with how many more orders it takes
+type DeliverySlotResponse struct {
+	ID        string `json:"id"`
+	Region    string `json:"region"`
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Capacity  int    `json:"capacity"`
+	Reserved  int    `json:"reserved"`
+	Remaining int    `json:"remaining"`
+	IsActive  bool   `json:"is_active"`
+}
+
+// ListDeliverySlotsResponse lists delivery slots by day, region and start time, with
+// the capacity and bookings they add up to
+type ListDeliverySlotsResponse struct {
+	StartDate string                  `json:"start_date"`
+	EndDate   string                  `json:"end_date"`
+	Region    string                  `json:"region,omitempty"`
+	Capacity  int                     `json:"capacity"`
+	Reserved  int                     `json:"reserved"`
+	Slots     []*DeliverySlotResponse `json:"slots"`
+}
+
+// OrderConfirmationResponse is a paid order confirmed and queued for fulfillment
+type OrderConfirmationResponse struct {
+	OrderID     string             `json:"order_id"`
+	Status      models.OrderStatus `json:"status"`
+	ConfirmedAt time.Time          `json:"confirmed_at"`
+}
+
+type ListAwaitingConfirmationRequest struct {
+	Page  int `json:"page" form:"page"`
+	Limit int `json:"limit" form:"limit"`
+}
+
+// ListAwaitingConfirmationResponse lists paid orders waiting for an admin to confirm
+// them, oldest first
+type ListAwaitingConfirmationResponse struct {
+	Orders []*models.Order `json:"orders"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+	Total  int             `json:"total"`
+}
+
+// ListFulfillmentQueueRequest selects the fulfillment tasks in a status (default:
+// queued)
+type ListFulfillmentQueueRequest struct {
+	Page   int    `json:"page" form:"page"`
+	Limit  int    `json:"limit" form:"limit"`
+	Status string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=queued completed cancelled"`
+}
+
+type ListFulfillmentQueueResponse struct {
+	Status models.FulfillmentTaskStatus `json:"status"`
+	Tasks  []*models.FulfillmentTask    `json:"tasks"`
+	Page   int                          `json:"page"`
+	Limit  int                          `json:"limit"`
+	Total  int                          `json:"total"`
+}
+
+// ListDeadJobsRequest selects dead jobs by type and pool
+type ListDeadJobsRequest struct {
+	Page  int    `json:"page" form:"page"`
+	Limit int    `json:"limit" form:"limit"`
+	Type  string `json:"type,omitempty" form:"type"`
+	Pool  string `json:"pool,omitempty" form:"pool"`
+}
+
+// ListDeadJobsResponse lists dead jobs without their payload, most recently failed first
+type ListDeadJobsResponse struct {
+	Jobs  []*models.DeadJob `json:"jobs"`
+	Page  int               `json:"page"`
+	Limit int               `json:"limit"`
+	Total int               `json:"total"`
+}
+
+// RequeueDeadJobResponse is a dead job queued again
+type RequeueDeadJobResponse struct {
+	ID    string    `json:"id"`
+	Type  string    `json:"type"`
+	Pool  string    `json:"pool"`
+	RunAt time.Time `json:"run_at"`
+}
+
+// PurgeDeadJobsRequest selects the dead jobs to purge by type, pool and failing before
+// DiedBefore (RFC 3339). At least one criterion is required, so the whole queue is not
+// purged by mistake, unless All is set.
+type PurgeDeadJobsRequest struct {
+	Type       string `json:"type,omitempty" form:"type"`
+	Pool       string `json:"pool,omitempty" form:"pool"`
+	DiedBefore string `json:"died_before,omitempty" form:"died_before" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	All        bool   `json:"all,omitempty" form:"all"`
+}
+
+type PurgeDeadJobsResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// WorkerStatsResponse reports every worker pool, by name, along with the in-memory jobs
+// waiting in the scheduler until they are due
+type WorkerStatsResponse struct {
+	Pools         []WorkerPoolStats `json:"pools"`
+	ScheduledJobs int               `json:"scheduled_jobs"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+}
+
+// WorkerPoolStats reports one worker pool. QueueDepth counts the jobs queued in memory
+// for its workers, and StoredJobs the durable jobs waiting in the job table to be
+// leased. Throughput is jobs finished per minute since the pool started, and the
+// failure rate the share of finished jobs that failed.
+type WorkerPoolStats struct {
+	Name                string               `json:"name"`
+	Workers             int                  `json:"workers"`
+	InFlight            int                  `json:"in_flight"`
+	QueueDepth          int                  `json:"queue_depth"`
+	QueueCapacity       int                  `json:"queue_capacity"`
+	StoredJobs          int64                `json:"stored_jobs"`
+	JobsProcessed       int64                `json:"jobs_processed"`
+	JobsSucceeded       int64                `json:"jobs_succeeded"`
+	JobsFailed          int64                `json:"jobs_failed"`
+	FailureRate         float64              `json:"failure_rate"`
+	ThroughputPerMinute float64              `json:"throughput_per_minute"`
+	AverageLatencyMs    int64                `json:"average_latency_ms"`
+	StartedAt           *time.Time           `json:"started_at,omitempty"`
+	JobTypes            []WorkerJobTypeStats `json:"job_types"`
+}
+
+// WorkerJobTypeStats reports the jobs of one type a pool ran
+type WorkerJobTypeStats struct {
+	Type                string  `json:"type"`
+	JobsProcessed       int64   `json:"jobs_processed"`
+	JobsSucceeded       int64   `json:"jobs_succeeded"`
+	JobsFailed          int64   `json:"jobs_failed"`
+	FailureRate         float64 `json:"failure_rate"`
+	ThroughputPerMinute float64 `json:"throughput_per_minute"`
+	AverageLatencyMs    int64   `json:"average_latency_ms"`
+}
+
+// ResizeWorkerPoolRequest sets the number of workers of a pool
+type ResizeWorkerPoolRequest struct {
+	Pool    string `json:"pool" validate:"required"`
+	Workers int    `json:"workers" validate:"required,min=1,max=100"`
+}
+
+// SupportSearchRequest holds the partial details a customer gave support. At least one
+// of Email, CardLast4, TrackingNumber or Total is required; Date (YYYY-MM-DD, UTC)
+// narrows orders to the day they were placed.
+type SupportSearchRequest struct {
+	Email          string  `json:"email,omitempty" form:"email" validate:"omitempty,min=3,max=255"`
+	CardLast4      string  `json:"card_last4,omitempty" form:"card_last4" validate:"omitempty,len=4,numeric"`
+	TrackingNumber string  `json:"tracking_number,omitempty" form:"tracking_number" validate:"omitempty,min=4,max=100"`
+	Total          float64 `json:"total,omitempty" form:"total" validate:"omitempty,gt=0"`
+	Date           string  `json:"date,omitempty" form:"date"`
+	Limit          int     `json:"limit,omitempty" form:"limit" validate:"omitempty,min=1,max=50"`
+}
+
+// SupportSearchResponse holds the users matching the email and card details, the
+// orders matching every detail and the payments of those orders
+type SupportSearchResponse struct {
+	Users    []*UserResponse      `json:"users"`
+	Orders   []*SupportOrderMatch `json:"orders"`
+	Payments []*PaymentResponse   `json:"payments"`
+}
+
+// SupportOrderMatch is an order found by a support search
+type SupportOrderMatch struct {
+	ID             string             `json:"id"`
+	UserID         string             `json:"user_id"`
+	Status         models.OrderStatus `json:"status"`
+	Total          float64            `json:"total"`
+	Paid           float64            `json:"paid"`
+	Currency       string             `json:"currency"`
+	Channel        string             `json:"channel"`
+	TrackingNumber string             `json:"tracking_number,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// CloseReportResponse is the end-of-day digest of a store. Orders, Sales and the SLA
+// counts are the store's own; OrdersByStatus, payment failures and low stock cover all
+// stores. LowStockProducts names the first low-stock products.
+type CloseReportResponse struct {
+	Store              string         `json:"store"`
+	Date               string         `json:"date"`
+	Orders             int            `json:"orders"`
+	Sales              float64        `json:"sales"`
+	AverageOrderValue  float64        `json:"average_order_value"`
+	OrdersByStatus     map[string]int `json:"orders_by_status"`
+	PaymentAttempts    int            `json:"payment_attempts"`
+	PaymentFailures    int            `json:"payment_failures"`
+	PaymentFailureRate float64        `json:"payment_failure_rate"`
+	LowStockCount      int            `json:"low_stock_count"`
+	LowStockProducts   []string       `json:"low_stock_products,omitempty"`
+	SLAOverdue         int            `json:"sla_overdue"`
+	SLADueSoon         int            `json:"sla_due_soon"`
+}
+
+// ReceiveProductSerialsRequest records the serial numbers of a received lot of a
+// serialized product
+type ReceiveProductSerialsRequest struct {
+	LotNumber     string   `json:"lot_number" validate:"required,max=100"`
+	SerialNumbers []string `json:"serial_numbers" validate:"required,min=1,max=1000,dive,required,max=100"`
+}
+
+// ReceiveProductSerialsResponse reports the serials recorded, and those skipped as the
+// product already had them
+type ReceiveProductSerialsResponse struct {
+	ProductID  string                  `json:"product_id"`
+	LotNumber  string                  `json:"lot_number"`
+	Received   []*models.ProductSerial `json:"received"`
+	Duplicates []string                `json:"duplicates,omitempty"`
+}
+
+type ListProductSerialsRequest struct {
+	Page      int    `json:"page" form:"page"`
+	Limit     int    `json:"limit" form:"limit"`
+	LotNumber string `json:"lot_number,omitempty" form:"lot_number" validate:"omitempty,max=100"`
+}
+
+type ListProductSerialsResponse struct {
+	Serials []*models.ProductSerial `json:"serials"`
+	Page    int                     `json:"page"`
+	Limit   int                     `json:"limit"`
+	Total   int                     `json:"total"`
+}
+
+// CaptureOrderItemSerialsRequest captures the serial number of each unit of an order
+// item, replacing those captured before
+type CaptureOrderItemSerialsRequest struct {
+	SerialNumbers []string `json:"serial_numbers" validate:"required,min=1,dive,required,max=100"`
+}
+
+// CreateWarrantyClaimRequest claims the warranty of a unit of an order item by its
+// serial number
+type CreateWarrantyClaimRequest struct {
+	SerialNumber string `json:"serial_number" validate:"required,max=100"`
+	Description  string `json:"description" validate:"required,max=2000"`
+}
+
+type ListWarrantyClaimsRequest struct {
+	Page      int    `json:"page" form:"page"`
+	Limit     int    `json:"limit" form:"limit"`
+	Status    string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=open resolved rejected"`
+	ProductID string `json:"product_id,omitempty" form:"product_id"`
+}
+
+type ListWarrantyClaimsResponse struct {
+	Claims []*models.WarrantyClaim `json:"claims"`
+	Page   int                     `json:"page"`
+	Limit  int                     `json:"limit"`
+	Total  int                     `json:"total"`
+}
+
+// ResolveWarrantyClaimRequest accepts an open claim: the unit is repaired, replaced on
+// a zero-priced order, or its price refunded
+type ResolveWarrantyClaimRequest struct {
+	Resolution models.WarrantyClaimResolution `json:"resolution" validate:"required,oneof=repair replace refund"`
+	Note       string                         `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type RejectWarrantyClaimRequest struct {
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+// WarrantyClaimRateReportRequest selects an inclusive range of UTC days by when orders
+// were placed and claims made
+type WarrantyClaimRateReportRequest struct {
+	StartDate string `json:"start_date" form:"start_date"`
+	EndDate   string `json:"end_date" form:"end_date"`
+}
+
+// WarrantyClaimRateReportResponse reports per serialized product the share of units
+// shipped that were claimed under warranty, and how the claims were resolved
+type WarrantyClaimRateReportResponse struct {
+	StartDate string                  `json:"start_date"`
+	EndDate   string                  `json:"end_date"`
+	Products  []WarrantyClaimRateItem `json:"products"`
+	Totals    WarrantyClaimRateStats  `json:"totals"`
+}
+
+type WarrantyClaimRateItem struct {
+	ProductID string `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	WarrantyClaimRateStats
+}
+
+// WarrantyClaimRateStats counts units shipped and claims; ClaimRate is claims per
+// hundred units shipped
+type WarrantyClaimRateStats struct {
+	UnitsShipped int64   `json:"units_shipped"`
+	Claims       int64   `json:"claims"`
+	ClaimRate    float64 `json:"claim_rate"`
+	Open         int64   `json:"open"`
+	Rejected     int64   `json:"rejected"`
+	Repairs      int64   `json:"repairs"`
+	Replacements int64   `json:"replacements"`
+	Refunds      int64   `json:"refunds"`
+}
+
+// CreateNotificationExperimentRequest starts an A/B test of the wording of a customer
+// notification type. Templates take the notification data's values as {name}
+// placeholders, such as {order_id}.
+type CreateNotificationExperimentRequest struct {
+	Name                  string                      `json:"name" validate:"required,max=255"`
+	Type                  string                      `json:"type" validate:"required,oneof=order_confirmed order_shipped order_delivered order_cancelled order_items_cancelled order_refunded payment_success payment_failed promotion"`
+	Locale                string                      `json:"locale,omitempty" validate:"omitempty,max=10"`              // Default: en
+	VariantA              NotificationTemplateVariant `json:"variant_a" validate:"required"`                             // Usually the current wording, as the control
+	VariantB              NotificationTemplateVariant `json:"variant_b" validate:"required"`                             // The wording tested against it
+	SplitPercent          int                         `json:"split_percent,omitempty" validate:"omitempty,min=1,max=99"` // Share of users sent variant B; default: 50
+	ConversionGoal        string                      `json:"conversion_goal" validate:"required,oneof=payment order"`
+	ConversionWindowHours int                         `json:"conversion_window_hours,omitempty" validate:"omitempty,min=1,max=720"` // Default: 72
+}
+
+type NotificationTemplateVariant struct {
+	Title string `json:"title" validate:"required,max=255"`
+	Body  string `json:"body" validate:"required"`
+}
+
+type ListNotificationExperimentsRequest struct {
+	Type   string `json:"type,omitempty" form:"type"`
+	Status string `json:"status,omitempty" form:"status" validate:"omitempty,oneof=running concluded stopped"`
+}
+
+// StopNotificationExperimentRequest ends an experiment. Naming a winner concludes a
+// running experiment, its winner's wording then being sent to everyone; without one
+// the catalog wording is sent again.
+type StopNotificationExperimentRequest struct {
+	Winner string `json:"winner,omitempty" validate:"omitempty,oneof=a b"`
+}
+
+// NotificationExperimentResultsResponse compares the variants of an experiment. Lift
+// is the percentage by which variant B's conversion rate exceeds variant A's, and
+// Significant reports whether the difference holds at 95% confidence; Leader is the
+// variant converting best, if any.
+type NotificationExperimentResultsResponse struct {
+	Experiment  *models.NotificationExperiment `json:"experiment"`
+	Variants    []NotificationVariantResult    `json:"variants"`
+	Leader      string                         `json:"leader,omitempty"`
+	Lift        float64                        `json:"lift"`
+	Significant bool                           `json:"significant"`
+}
+
+// NotificationVariantResult is the delivery of one variant's notifications, and the
+// percentage of them that converted
+type NotificationVariantResult struct {
+	Variant string `json:"variant"`
+	NotificationDeliveryStats
+	Conversions    int     `json:"conversions"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// recountBatchSize is the number of adjustments applied per transaction
+	recountBatchSize = 100
+	// recountMaxRows caps the size of a single recount import
+	recountMaxRows = 10000
+	// recountMaxAttempts is how often a batch is recomputed after a version conflict
+	recountMaxAttempts = 3
+	// recountMaxLocationLength is the size of the inventory location column
+	recountMaxLocationLength = 50
+)
+
+// Variance line statuses
+const (
+	RecountStatusAdjusted  = "adjusted"
+	RecountStatusUnchanged = "unchanged"
+	RecountStatusFailed    = "failed"
+)
+
+// recountArtifactDir is where variance report CSVs are written
+var recountArtifactDir = filepath.Join(os.TempDir(), "inventory-recounts")
+
+// recountRelocationReason is the audited reason of relocations made by a recount
+const recountRelocationReason = "inventory recount"
+
+func (s *inventoryService) ImportRecount(ctx context.Context, r io.Reader, actor AuditActor) (*InventoryRecountResponse, error) {
+	s.logger.Info("Importing inventory recount")
+
+	lines, err := parseRecountCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &InventoryRecountResponse{
+		RecountID: uuid.New().String(),
+		TotalRows: len(lines),
+		Lines:     lines,
+	}
+
+	// Counts are recorded in the inventory history against the recount
+	recount := repository.InventoryEventSource{
+		ActorID:       actor.UserID,
+		ReferenceType: models.InventoryReferenceRecount,
+		ReferenceID:   response.RecountID,
+	}
+
+	// Only lines that parsed cleanly take part in the adjustment
+	pending := make([]*InventoryVarianceLine, 0, len(lines))
+	for i := range lines {
+		if lines[i].Status == "" {
+			pending = append(pending, &lines[i])
+		}
+	}
+
+	for start := 0; start < len(pending); start += recountBatchSize {
+		end := start + recountBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		s.applyRecountBatch(ctx, recount, pending[start:end])
+	}
+
+	// Stock found at another bin than recorded is moved there, whether or not its
+	// count changed
+	for _, line := range pending {
+		if line.Location == "" || line.ProductID == "" || line.Status == RecountStatusFailed {
+			continue
+		}
+		location, err := s.relocate(ctx, line.ProductID, line.Location, recountRelocationReason, actor)
+		if err != nil {
+			s.logger.Warn("Failed to relocate recounted inventory", "error", err, "product_id", line.ProductID)
+			line.Error = "relocation failed: " + err.Error()
+			continue
+		}
+		line.Location = location.Location
+		line.PreviousLocation = location.PreviousLocation
+		line.Relocated = location.Relocated
+	}
+
+	for _, line := range lines {
+		switch line.Status {
+		case RecountStatusAdjusted:
+			response.Adjusted++
+			response.NetVariance += line.Variance
+		case RecountStatusUnchanged:
+			response.Unchanged++
+		case RecountStatusFailed:
+			response.Failed++
+		}
+		if line.Relocated {
+			response.Relocated++
+		}
+	}
+
+	adjustedProductIDs := make([]string, 0, response.Adjusted)
+	for _, line := range lines {
+		if line.Status == RecountStatusAdjusted {
+			adjustedProductIDs = append(adjustedProductIDs, line.ProductID)
+		}
+	}
+	s.notifyStockChanges(ctx, StockChangeReasonAdjustment, adjustedProductIDs)
+
+	artifactPath, err := writeRecountArtifact(lines)
+	if err != nil {
+		// The adjustments are already committed, so only log the failure
+		s.logger.Warn("Failed to write inventory recount artifact", "error", err)
+	}
+	response.ArtifactPath = artifactPath
+
+	s.logger.Info("Inventory recount imported",
+		"recount_id", response.RecountID,
+		"total_rows", response.TotalRows,
+		"adjusted", response.Adjusted,
+		"unchanged", response.Unchanged,
+		"failed", response.Failed,
+		"relocated", response.Relocated,
+		"net_variance", response.NetVariance)
+
+	return response, nil
+}
+
+// applyRecountBatch computes variances for a batch and applies them in one transaction
+// recorded as made by the recount, recomputing against fresh stock when another writer
+// changed an inventory row meanwhile
+func (s *inventoryService) applyRecountBatch(ctx context.Context, recount repository.InventoryEventSource, batch []*InventoryVarianceLine) {
+	skus := make([]string, len(batch))
+	for i, line := range batch {
+		skus[i] = line.SKU
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= recountMaxAttempts; attempt++ {
+		products, err := s.productRepo.GetBySKUs(ctx, skus)
+		if err != nil {
+			s.logger.Error("Failed to load products for recount", "error", err, "batch_size", len(batch))
+			lastErr = err
+			break
+		}
+
+		productsBySKU := make(map[string]*models.Product, len(products))
+		for _, product := range products {
+			productsBySKU[product.SKU] = product
+		}
+
+		adjustments := make([]repository.InventoryStockAdjustment, 0, len(batch))
+		for _, line := range batch {
+			line.Status = ""
+			line.Error = ""
+
+			product, ok := productsBySKU[line.SKU]
+			if !ok {
+				line.Status = RecountStatusFailed
+				line.Error = "product not found"
+				continue
+			}
+			if product.Inventory == nil {
+				line.ProductID = product.ID
+				line.Status = RecountStatusFailed
+				line.Error = "inventory not found"
+				continue
+			}
+
+			line.ProductID = product.ID
+			line.PreviousQuantity = product.Inventory.Quantity
+			line.Variance = line.CountedQuantity - product.Inventory.Quantity
+
+			if line.CountedQuantity < product.Inventory.Reserved {
+				line.Status = RecountStatusFailed
+				line.Error = fmt.Sprintf("counted quantity is below reserved quantity %d", product.Inventory.Reserved)
+				continue
+			}
+			if line.Variance == 0 {
+				line.Status = RecountStatusUnchanged
+				continue
+			}
+
+			adjustments = append(adjustments, repository.InventoryStockAdjustment{
+				ProductID:       product.ID,
+				Quantity:        line.CountedQuantity,
+				ExpectedVersion: product.Inventory.Version,
+			})
+		}
+
+		if len(adjustments) == 0 {
+			return
+		}
+
+		err = s.inventoryRepo.BulkAdjustStock(repository.WithInventoryEventSource(ctx, recount), adjustments)
+		if err == nil {
+			for _, line := range batch {
+				if line.Status == "" {
+					line.Status = RecountStatusAdjusted
+				}
+			}
+			return
+		}
+
+		lastErr = err
+		if !apperrors.IsConcurrencyError(err) {
+			break
+		}
+
+		s.logger.Warn("Inventory recount batch conflicted, recomputing variances",
+			"attempt", attempt, "batch_size", len(batch))
+	}
+
+	s.logger.Error("Failed to apply inventory recount batch", "error", lastErr, "batch_size", len(batch))
+	for _, line := range batch {
+		if line.Status == "" {
+			line.Status = RecountStatusFailed
+			line.Error = "adjustment failed: " + lastErr.Error()
+		}
+	}
+}
+
+// parseRecountCSV reads "sku,quantity" rows with an optional third column holding the
+// bin location the stock was counted at; a header row is optional.
+// Rows that cannot be parsed are returned already marked as failed.
+func parseRecountCSV(r io.Reader) ([]InventoryVarianceLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var lines []InventoryVarianceLine
+	seen := make(map[string]bool)
+	row := 0
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+		}
+		row++
+
+		if row == 1 && isRecountHeader(record) {
+			continue
+		}
+		if len(lines) >= recountMaxRows {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("recount import cannot exceed %d rows", recountMaxRows))
+		}
+
+		line := InventoryVarianceLine{Row: row}
+		if len(record) < 2 {
+			line.Status = RecountStatusFailed
+			line.Error = "expected sku and quantity columns"
+			lines = append(lines, line)
+			continue
+		}
+
+		line.SKU = strings.TrimSpace(record[0])
+		quantity, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		switch {
+		case line.SKU == "":
+			line.Status = RecountStatusFailed
+			line.Error = "sku is required"
+		case err != nil || quantity < 0:
+			line.Status = RecountStatusFailed
+			line.Error = "quantity must be a non-negative integer"
+		case seen[line.SKU]:
+			line.Status = RecountStatusFailed
+			line.Error = "duplicate sku in import"
+		case len(record) > 2 && len(strings.TrimSpace(record[2])) > recountMaxLocationLength:
+			line.Status = RecountStatusFailed
+			line.Error = fmt.Sprintf("location cannot exceed %d characters", recountMaxLocationLength)
+		default:
+			line.CountedQuantity = quantity
+			seen[line.SKU] = true
+			if len(record) > 2 {
+				line.Location = strings.ToUpper(strings.TrimSpace(record[2]))
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return nil, apperrors.NewValidationError("recount import is empty")
+	}
+
+	return lines, nil
+}
+
+// isRecountHeader reports whether the first record is a column header
+func isRecountHeader(record []string) bool {
+	if len(record) < 2 {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	return err != nil && strings.EqualFold(strings.TrimSpace(record[0]), "sku")
+}
+
+// writeRecountArtifact writes the variance report as a CSV file and returns its path
+func writeRecountArtifact(lines []InventoryVarianceLine) (string, error) {
+	if err := os.MkdirAll(recountArtifactDir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(recountArtifactDir, fmt.Sprintf("recount-%s.csv", time.Now().UTC().Format("20060102T150405.000000000")))
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"row", "sku", "product_id", "previous_quantity", "counted_quantity", "variance", "status", "error", "location", "previous_location", "relocated"}); err != nil {
+		return "", err
+	}
+	for _, line := range lines {
+		if err := writer.Write([]string{
+			strconv.Itoa(line.Row),
+			line.SKU,
+			line.ProductID,
+			strconv.Itoa(line.PreviousQuantity),
+			strconv.Itoa(line.CountedQuantity),
+			strconv.Itoa(line.Variance),
+			line.Status,
+			line.Error,
+			line.Location,
+			line.PreviousLocation,
+			strconv.FormatBool(line.Relocated),
+		}); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -4,27 +4,62 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
 )
 
+// LowStockSettings configures per-product low-stock thresholds. With Dynamic set,
+// a product's threshold covers its average daily sales over VelocityWindow for its
+// supplier lead time plus SafetyDays; LeadTimeDays applies to products without a
+// lead time of their own. Products without recent sales keep their min stock.
+type LowStockSettings struct {
+	Dynamic        bool
+	VelocityWindow time.Duration
+	LeadTimeDays   int
+	SafetyDays     int
+}
+
+// Sources of a product's low-stock threshold
+const (
+	LowStockThresholdOverride      = "override"
+	LowStockThresholdSalesVelocity = "sales_velocity"
+	LowStockThresholdMinStock      = "min_stock"
+)
+
 // inventoryService implements InventoryService interface
 type inventoryService struct {
+	lowStock      LowStockSettings
 	inventoryRepo repository.InventoryRepository
 	productRepo   repository.ProductRepository
+	auditRepo     repository.AuditLogRepository
+	eventRepo     repository.InventoryEventRepository
+	stockNotifier StockChangeNotifier
 	logger        *logger.Logger
 }
 
 // NewInventoryService creates a new inventory service
 func NewInventoryService(
+	lowStock LowStockSettings,
 	inventoryRepo repository.InventoryRepository,
 	productRepo repository.ProductRepository,
+	auditRepo repository.AuditLogRepository,
+	eventRepo repository.InventoryEventRepository,
+	stockNotifier StockChangeNotifier,
 	logger *logger.Logger,
 ) InventoryService {
 	return &inventoryService{
+		lowStock:      lowStock,
 		inventoryRepo: inventoryRepo,
 		productRepo:   productRepo,
+		auditRepo:     auditRepo,
+		eventRepo:     eventRepo,
+		stockNotifier: stockNotifier,
 		logger:        logger,
 	}
 }
@@ -91,6 +126,7 @@ func (s *inventoryService) ReserveInventory(ctx context.Context, items []Invento
 	}
 
 	s.logger.Info("Inventory reservation completed successfully", "items_count", len(items))
+	s.notifyStockChanges(ctx, StockChangeReasonReservation, inventoryItemProductIDs(items))
 	return nil
 }
 
@@ -128,6 +164,7 @@ func (s *inventoryService) ReleaseInventory(ctx context.Context, items []Invento
 	}
 
 	s.logger.Info("Inventory release completed successfully", "items_count", len(items))
+	s.notifyStockChanges(ctx, StockChangeReasonRelease, inventoryItemProductIDs(items))
 	return nil
 }
 
@@ -155,11 +192,13 @@ func (s *inventoryService) GetLowStockAlert(ctx context.Context, threshold int)
 		}
 
 		alerts[i] = LowStockItem{
-			ProductID:    inventory.ProductID,
-			ProductName:  productName,
-			ProductSKU:   productSKU,
-			CurrentStock: inventory.Available,
-			MinStock:     inventory.MinStock,
+			ProductID:     inventory.ProductID,
+			ProductName:   productName,
+			ProductSKU:    productSKU,
+			CurrentStock:  inventory.Available,
+			PhysicalStock: inventory.Quantity,
+			SafetyStock:   inventory.SafetyStock,
+			MinStock:      inventory.MinStock,
 		}
 	}
 
@@ -172,17 +211,417 @@ func (s *inventoryService) GetLowStockAlert(ctx context.Context, threshold int)
 	}, nil
 }
 
+// GetProductLowStockAlert returns the products at or below their own low-stock threshold:
+// the product's override if set, otherwise the days of cover from sales velocity when
+// dynamic thresholds are enabled, otherwise the inventory's min stock
+func (s *inventoryService) GetProductLowStockAlert(ctx context.Context) (*LowStockResponse, error) {
+	s.logger.Debug("Getting per-product low stock alert", "dynamic", s.lowStock.Dynamic)
+
+	inventories, err := s.inventoryRepo.ListWithProducts(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list inventory", "error", err)
+		return nil, err
+	}
+
+	var unitsSold map[string]int
+	if s.lowStock.Dynamic && s.lowStock.VelocityWindow > 0 {
+		unitsSold, err = s.inventoryRepo.GetUnitsSoldSince(ctx, time.Now().Add(-s.lowStock.VelocityWindow))
+		if err != nil {
+			s.logger.Error("Failed to get units sold", "error", err)
+			return nil, err
+		}
+	}
+
+	products := []ProductLowStock{}
+	for _, inventory := range inventories {
+		alert := s.lowStock.productLowStock(inventory, unitsSold[inventory.ProductID])
+		if inventory.Available <= alert.Threshold {
+			products = append(products, alert)
+		}
+	}
+
+	s.logger.Debug("Per-product low stock alert generated", "alert_count", len(products))
+
+	return &LowStockResponse{
+		PerProduct: true,
+		Products:   products,
+		Count:      len(products),
+	}, nil
+}
+
+// inventoryRelocationHistoryLimit caps the relocations returned for a product
+const inventoryRelocationHistoryLimit = 100
+
+// Relocate moves a product's stock to another bin location, which orders pick lists,
+// and records the move in the audit log. Locations are kept upper case so they sort
+// the same however they were typed.
+func (s *inventoryService) Relocate(ctx context.Context, productID string, actor AuditActor, req RelocateInventoryRequest) (*InventoryLocationResponse, error) {
+	s.logger.Debug("Relocating inventory", "product_id", productID, "location", req.Location)
+
+	if productID == "" {
+		return nil, apperrors.NewValidationError("product ID is required")
+	}
+
+	return s.relocate(ctx, productID, req.Location, strings.TrimSpace(req.Reason), actor)
+}
+
+// relocate moves a product's stock to the location unless it is already there
+func (s *inventoryService) relocate(ctx context.Context, productID, location, reason string, actor AuditActor) (*InventoryLocationResponse, error) {
+	location = strings.ToUpper(strings.TrimSpace(location))
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	response := &InventoryLocationResponse{
+		ProductID:        productID,
+		Location:         location,
+		PreviousLocation: inventory.Location,
+	}
+	if inventory.Location == location {
+		return response, nil
+	}
+
+	auditLog, err := newRelocationAuditLog(productID, inventory.Location, location, reason, actor)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build relocation audit log", err)
+	}
+
+	relocated, err := s.inventoryRepo.Relocate(ctx, repository.InventoryRelocation{
+		ProductID: productID,
+		From:      inventory.Location,
+		To:        location,
+		AuditLog:  auditLog,
+	})
+	if err != nil {
+		s.logger.Error("Failed to relocate inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to relocate inventory", err)
+	}
+	if !relocated {
+		return nil, apperrors.NewConflictError("inventory was relocated or removed in the meantime; retry the relocation")
+	}
+
+	s.logger.Info("Inventory relocated", "product_id", productID, "from", inventory.Location, "to", location)
+	response.Relocated = true
+	return response, nil
+}
+
+// newRelocationAuditLog returns the audit log of a move of a product's stock, recorded
+// against its inventory. System relocations have no user.
+func newRelocationAuditLog(productID, from, to, reason string, actor AuditActor) (*models.AuditLog, error) {
+	auditLog := &models.AuditLog{
+		EntityType: "inventory",
+		EntityID:   productID,
+		Action:     models.AuditActionRelocate,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+	if actor.UserID != "" {
+		auditLog.UserID = &actor.UserID
+	}
+
+	if err := auditLog.SetOldValues(relocationValues{Location: from}); err != nil {
+		return nil, err
+	}
+	if err := auditLog.SetNewValues(relocationValues{Location: to, Reason: reason}); err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// relocationValues are the audited values of a relocation
+type relocationValues struct {
+	Location string `json:"location"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (s *inventoryService) GetStockLevels(ctx context.Context, productID string) (*InventoryStockLevelsResponse, error) {
+	s.logger.Debug("Getting stock levels", "product_id", productID)
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	return toStockLevelsResponse(inventory), nil
+}
+
+// SetSafetyStock holds back units of a product from online sales, lowering its sellable
+// stock without touching the stock on hand. The change is audit-logged with the admin
+// and the reason, and external channels are told of the new sellable stock.
+func (s *inventoryService) SetSafetyStock(ctx context.Context, productID string, actor AuditActor, req SetSafetyStockRequest) (*InventoryStockLevelsResponse, error) {
+	if productID == "" {
+		return nil, apperrors.NewValidationError("product ID is required")
+	}
+	if req.SafetyStock == nil || *req.SafetyStock < 0 {
+		return nil, apperrors.NewValidationError("safety stock must be zero or more")
+	}
+	safetyStock := *req.SafetyStock
+	s.logger.Debug("Setting safety stock", "product_id", productID, "safety_stock", safetyStock)
+
+	current, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if current == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+	if current.SafetyStock == safetyStock {
+		return toStockLevelsResponse(current), nil
+	}
+
+	auditLog, err := newSafetyStockAuditLog(productID, current.SafetyStock, safetyStock, strings.TrimSpace(req.Reason), actor)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build safety stock audit log", err)
+	}
+
+	eventCtx := repository.WithInventoryEventSource(ctx, repository.InventoryEventSource{ActorID: actor.UserID})
+	inventory, err := s.inventoryRepo.SetSafetyStock(eventCtx, productID, safetyStock, auditLog)
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("failed to set safety stock", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	s.logger.Info("Safety stock set", "product_id", productID, "from", current.SafetyStock, "to", safetyStock,
+		"sellable", inventory.Available)
+	s.notifyStockChanges(ctx, StockChangeReasonAdjustment, []string{productID})
+	return toStockLevelsResponse(inventory), nil
+}
+
+// newSafetyStockAuditLog returns the audit log of a change to a product's safety stock,
+// recorded against its inventory
+func newSafetyStockAuditLog(productID string, from, to int, reason string, actor AuditActor) (*models.AuditLog, error) {
+	auditLog := &models.AuditLog{
+		EntityType: "inventory",
+		EntityID:   productID,
+		Action:     models.AuditActionUpdate,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+	if actor.UserID != "" {
+		auditLog.UserID = &actor.UserID
+	}
+
+	if err := auditLog.SetOldValues(safetyStockValues{SafetyStock: from}); err != nil {
+		return nil, err
+	}
+	if err := auditLog.SetNewValues(safetyStockValues{SafetyStock: to, Reason: reason}); err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// safetyStockValues are the audited values of a safety stock change
+type safetyStockValues struct {
+	SafetyStock int    `json:"safety_stock"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// toStockLevelsResponse breaks an inventory record down into its stock levels
+func toStockLevelsResponse(inventory *models.Inventory) *InventoryStockLevelsResponse {
+	return &InventoryStockLevelsResponse{
+		ProductID:     inventory.ProductID,
+		PhysicalStock: inventory.Quantity,
+		Reserved:      inventory.Reserved,
+		SafetyStock:   inventory.SafetyStock,
+		Sellable:      inventory.Available,
+		Location:      inventory.Location,
+		Version:       inventory.Version,
+	}
+}
+
+// GetRelocations lists the relocations of a product's stock from its audit log,
+// newest first
+func (s *inventoryService) GetRelocations(ctx context.Context, productID string) ([]*InventoryRelocationEntry, error) {
+	s.logger.Debug("Getting inventory relocations", "product_id", productID)
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	auditLogs, err := s.auditRepo.GetByEntityID(ctx, "inventory", productID, 0, inventoryRelocationHistoryLimit)
+	if err != nil {
+		s.logger.Error("Failed to get inventory audit logs", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory relocations", err)
+	}
+
+	relocations := make([]*InventoryRelocationEntry, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		if auditLog.Action != models.AuditActionRelocate {
+			continue
+		}
+
+		var from, to relocationValues
+		if err := auditLog.GetOldValues(&from); err != nil {
+			s.logger.Warn("Skipping unreadable relocation audit log", "error", err, "id", auditLog.ID)
+			continue
+		}
+		if err := auditLog.GetNewValues(&to); err != nil {
+			s.logger.Warn("Skipping unreadable relocation audit log", "error", err, "id", auditLog.ID)
+			continue
+		}
+
+		relocations = append(relocations, &InventoryRelocationEntry{
+			From:      from.Location,
+			To:        to.Location,
+			Reason:    to.Reason,
+			UserID:    auditLog.UserID,
+			CreatedAt: auditLog.CreatedAt,
+		})
+	}
+
+	return relocations, nil
+}
+
+// GetHistory returns a page of a product's inventory history: its inventory events
+// and the relocations of its stock, newest first
+func (s *inventoryService) GetHistory(ctx context.Context, productID string, req InventoryHistoryRequest) (*InventoryHistoryResponse, error) {
+	s.logger.Debug("Getting inventory history", "product_id", productID, "page", req.Page, "limit", req.Limit)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	history, err := s.eventRepo.ListHistory(ctx, productID, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list inventory history", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory history", err)
+	}
+
+	total, err := s.eventRepo.CountHistory(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to count inventory history", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory history", err)
+	}
+
+	entries := make([]*InventoryHistoryEntry, len(history))
+	for i, entry := range history {
+		entries[i] = &InventoryHistoryEntry{
+			Type:          entry.Type,
+			Sequence:      entry.Sequence,
+			Quantity:      entry.Quantity,
+			OnHand:        entry.OnHand,
+			Reserved:      entry.Reserved,
+			Available:     entry.Available,
+			FromLocation:  entry.FromLocation,
+			ToLocation:    entry.ToLocation,
+			Reason:        entry.Reason,
+			ActorID:       entry.ActorID,
+			ReferenceType: entry.ReferenceType,
+			ReferenceID:   entry.ReferenceID,
+			OccurredAt:    entry.OccurredAt,
+		}
+	}
+
+	return &InventoryHistoryResponse{
+		ProductID: productID,
+		Entries:   entries,
+		Page:      page,
+		Limit:     limit,
+		Total:     int(total),
+	}, nil
+}
+
+// productLowStock works out the low-stock threshold of an inventory record given the
+// units of the product sold over the velocity window
+func (s LowStockSettings) productLowStock(inventory *models.Inventory, unitsSold int) ProductLowStock {
+	alert := ProductLowStock{
+		ProductID:       inventory.ProductID,
+		ProductName:     "Unknown Product",
+		CurrentStock:    inventory.Available,
+		PhysicalStock:   inventory.Quantity,
+		SafetyStock:     inventory.SafetyStock,
+		MinThreshold:    inventory.MinStock,
+		Threshold:       inventory.MinStock,
+		ThresholdSource: LowStockThresholdMinStock,
+	}
+
+	leadTimeDays := s.LeadTimeDays
+	if product := inventory.Product; product != nil {
+		alert.ProductName = product.Name
+		alert.SKU = product.SKU
+		if product.LeadTimeDays > 0 {
+			leadTimeDays = product.LeadTimeDays
+		}
+	}
+
+	if unitsSold > 0 && s.VelocityWindow > 0 {
+		alert.DailyVelocity = float64(unitsSold) / (s.VelocityWindow.Hours() / 24)
+		daysOfCover := float64(inventory.Available) / alert.DailyVelocity
+		alert.DaysOfCover = &daysOfCover
+		alert.Threshold = int(math.Ceil(alert.DailyVelocity * float64(leadTimeDays+s.SafetyDays)))
+		alert.ThresholdSource = LowStockThresholdSalesVelocity
+	}
+
+	if inventory.Product != nil && inventory.Product.LowStockThreshold != nil {
+		alert.Threshold = *inventory.Product.LowStockThreshold
+		alert.ThresholdSource = LowStockThresholdOverride
+	}
+
+	return alert
+}
+
 // Helper function to convert LowStockItem to ProductLowStock
 func convertToProductLowStock(items []LowStockItem) []ProductLowStock {
 	products := make([]ProductLowStock, len(items))
 	for i, item := range items {
 		products[i] = ProductLowStock{
-			ProductID:    item.ProductID,
-			ProductName:  item.ProductName,
-			SKU:          item.ProductSKU,
-			CurrentStock: item.CurrentStock,
-			MinThreshold: item.MinStock,
+			ProductID:     item.ProductID,
+			ProductName:   item.ProductName,
+			SKU:           item.ProductSKU,
+			CurrentStock:  item.CurrentStock,
+			PhysicalStock: item.PhysicalStock,
+			SafetyStock:   item.SafetyStock,
+			MinThreshold:  item.MinStock,
 		}
 	}
 	return products
 }
+
+// notifyStockChanges tells external channels about changed stock levels, if configured
+func (s *inventoryService) notifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	if s.stockNotifier == nil {
+		return
+	}
+	s.stockNotifier.NotifyStockChanges(ctx, reason, productIDs)
+}
+
+// inventoryItemProductIDs returns the product IDs of the given items
+func inventoryItemProductIDs(items []InventoryItem) []string {
+	productIDs := make([]string, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+	return productIDs
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// inventoryShardService implements InventoryShardService interface
+type inventoryShardService struct {
+	inventoryRepo repository.InventoryRepository
+	shardRepo     repository.InventoryShardRepository
+	logger        *logger.Logger
+}
+
+// NewInventoryShardService creates a new inventory shard service
+func NewInventoryShardService(
+	inventoryRepo repository.InventoryRepository,
+	shardRepo repository.InventoryShardRepository,
+	logger *logger.Logger,
+) InventoryShardService {
+	return &inventoryShardService{
+		inventoryRepo: inventoryRepo,
+		shardRepo:     shardRepo,
+		logger:        logger,
+	}
+}
+
+// SetShards splits a product's sellable stock evenly into shards, or gathers it back
+// onto its inventory row with 0 shards. Its sellable stock is unchanged. The change
+// is audit-logged with the admin and the reason.
+func (s *inventoryShardService) SetShards(ctx context.Context, productID string, actor AuditActor, req SetInventoryShardsRequest) (*InventoryShardsResponse, error) {
+	if productID == "" {
+		return nil, apperrors.NewValidationError("product ID is required")
+	}
+	if req.Shards == nil || *req.Shards < 0 || *req.Shards > models.MaxInventoryShards {
+		return nil, apperrors.NewValidationErrorWithDetails("invalid shard count",
+			fmt.Sprintf("shards must be between 0 and %d", models.MaxInventoryShards))
+	}
+	shards := *req.Shards
+	s.logger.Debug("Setting inventory shards", "product_id", productID, "shards", shards)
+
+	current, err := s.shardRepo.List(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to list inventory shards", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to list inventory shards", err)
+	}
+
+	auditLog, err := newInventoryShardsAuditLog(productID, len(current), shards, strings.TrimSpace(req.Reason), actor)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to build inventory shards audit log", err)
+	}
+
+	inventory, err := s.shardRepo.Configure(ctx, productID, shards, auditLog)
+	if err != nil {
+		return nil, apperrors.NewDatabaseError("failed to set inventory shards", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	configured, err := s.shardRepo.List(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to list inventory shards", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to list inventory shards", err)
+	}
+
+	s.logger.Info("Inventory shards set", "product_id", productID, "from", len(current), "to", shards,
+		"sellable", inventory.Available)
+	return toInventoryShardsResponse(inventory, configured), nil
+}
+
+func (s *inventoryShardService) GetShards(ctx context.Context, productID string) (*InventoryShardsResponse, error) {
+	s.logger.Debug("Getting inventory shards", "product_id", productID)
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get inventory", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to get inventory", err)
+	}
+	if inventory == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("inventory", productID)
+	}
+
+	shards, err := s.shardRepo.List(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to list inventory shards", "error", err, "product_id", productID)
+		return nil, apperrors.NewDatabaseError("failed to list inventory shards", err)
+	}
+
+	return toInventoryShardsResponse(inventory, shards), nil
+}
+
+// RebalanceShards evens out the stock of sharded products over their shards, and
+// returns how many products it rebalanced
+func (s *inventoryShardService) RebalanceShards(ctx context.Context) (int, error) {
+	rebalanced, err := s.shardRepo.Rebalance(ctx)
+	if err != nil {
+		return rebalanced, apperrors.NewDatabaseError("failed to rebalance inventory shards", err)
+	}
+	return rebalanced, nil
+}
+
+// newInventoryShardsAuditLog returns the audit log of a change to the shards of a
+// product's stock, recorded against its inventory
+func newInventoryShardsAuditLog(productID string, from, to int, reason string, actor AuditActor) (*models.AuditLog, error) {
+	auditLog := &models.AuditLog{
+		EntityType: "inventory",
+		EntityID:   productID,
+		Action:     models.AuditActionUpdate,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+	if actor.UserID != "" {
+		auditLog.UserID = &actor.UserID
+	}
+
+	if err := auditLog.SetOldValues(inventoryShardsValues{Shards: from}); err != nil {
+		return nil, err
+	}
+	if err := auditLog.SetNewValues(inventoryShardsValues{Shards: to, Reason: reason}); err != nil {
+		return nil, err
+	}
+	return auditLog, nil
+}
+
+// inventoryShardsValues are the audited values of a change to a product's shards
+type inventoryShardsValues struct {
+	Shards int    `json:"shards"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// toInventoryShardsResponse shows a product's sellable stock with its shards
+func toInventoryShardsResponse(inventory *models.Inventory, shards []*models.InventoryShard) *InventoryShardsResponse {
+	response := &InventoryShardsResponse{
+		ProductID: inventory.ProductID,
+		Sellable:  inventory.Available,
+		Shards:    make([]InventoryShardResponse, len(shards)),
+	}
+	for i, shard := range shards {
+		response.Shards[i] = InventoryShardResponse{Shard: shard.Shard, Available: shard.Available}
+	}
+	return response
+}
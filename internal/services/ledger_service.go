@@ -0,0 +1,369 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// ledgerConsistencyLimit caps the findings of each ledger consistency check
+	ledgerConsistencyLimit = 100
+
+	// ledgerUnassignedGateway names the clearing account of payments settled
+	// without a recorded gateway
+	ledgerUnassignedGateway = "unassigned"
+
+	// ledgerDefaultCurrency is used for movements recorded without a currency
+	ledgerDefaultCurrency = "USD"
+)
+
+// ledgerService implements LedgerService interface. Every money movement is posted
+// as a balanced transaction of two lines:
+//
+//	payment                 debit gateway clearing, credit revenue
+//	fee                     debit processing fees,  credit gateway clearing
+//	refund                  debit revenue,          credit gateway clearing
+//	gift card redemption    debit gift cards,       credit revenue
+//	store credit issued     debit revenue,          credit customer
+//	store credit redeemed   debit customer,         credit revenue
+type ledgerService struct {
+	ledgerRepo repository.LedgerRepository
+	now        func() time.Time
+	logger     *logger.Logger
+}
+
+// NewLedgerService creates a new payments ledger service
+func NewLedgerService(ledgerRepo repository.LedgerRepository, logger *logger.Logger) LedgerService {
+	return &ledgerService{
+		ledgerRepo: ledgerRepo,
+		now:        time.Now,
+		logger:     logger,
+	}
+}
+
+// ledgerPosting is a ledger transaction to post: amount moves from the credited
+// account to the debited one
+type ledgerPosting struct {
+	key       string
+	kind      models.LedgerTransactionType
+	debit     string
+	credit    string
+	amount    float64
+	currency  string
+	orderID   string
+	paymentID string
+	reference string
+}
+
+func (s *ledgerService) RecordPayment(ctx context.Context, payment *models.Payment) error {
+	clearing := models.GatewayClearingAccount(ledgerGateway(payment))
+
+	if err := s.post(ctx, ledgerPosting{
+		key:       ledgerKey(models.LedgerTransactionPayment, payment.ID),
+		kind:      models.LedgerTransactionPayment,
+		debit:     clearing,
+		credit:    models.LedgerAccountRevenue,
+		amount:    payment.Amount,
+		currency:  payment.Currency,
+		orderID:   payment.OrderID,
+		paymentID: payment.ID,
+	}); err != nil {
+		return err
+	}
+
+	if payment.ProcessingFee <= 0 {
+		return nil
+	}
+	return s.post(ctx, ledgerPosting{
+		key:       ledgerKey(models.LedgerTransactionFee, payment.ID),
+		kind:      models.LedgerTransactionFee,
+		debit:     models.LedgerAccountProcessingFees,
+		credit:    clearing,
+		amount:    payment.ProcessingFee,
+		currency:  payment.Currency,
+		orderID:   payment.OrderID,
+		paymentID: payment.ID,
+	})
+}
+
+// RecordRefund posts the refund of a whole payment. The gateway keeps its processing
+// fee, so the fee posting stands.
+func (s *ledgerService) RecordRefund(ctx context.Context, payment *models.Payment) error {
+	return s.post(ctx, ledgerPosting{
+		key:       ledgerKey(models.LedgerTransactionRefund, payment.ID),
+		kind:      models.LedgerTransactionRefund,
+		debit:     models.LedgerAccountRevenue,
+		credit:    models.GatewayClearingAccount(ledgerGateway(payment)),
+		amount:    payment.Amount,
+		currency:  payment.Currency,
+		orderID:   payment.OrderID,
+		paymentID: payment.ID,
+	})
+}
+
+func (s *ledgerService) RecordPartialRefund(ctx context.Context, refund PaymentRefund) error {
+	if refund.RefundID == "" || refund.Payment == nil {
+		return errors.NewValidationError("refund ID and payment are required")
+	}
+
+	return s.post(ctx, ledgerPosting{
+		key:       ledgerKey(models.LedgerTransactionRefund, refund.RefundID),
+		kind:      models.LedgerTransactionRefund,
+		debit:     models.LedgerAccountRevenue,
+		credit:    models.GatewayClearingAccount(ledgerGateway(refund.Payment)),
+		amount:    refund.Amount,
+		currency:  refund.Payment.Currency,
+		orderID:   refund.Payment.OrderID,
+		paymentID: refund.Payment.ID,
+	})
+}
+
+func (s *ledgerService) RecordGiftCardRedemption(ctx context.Context, redemption GiftCardRedemption) error {
+	if redemption.RedemptionID == "" || redemption.GiftCardID == "" {
+		return errors.NewValidationError("gift card redemption ID and gift card ID are required")
+	}
+
+	return s.post(ctx, ledgerPosting{
+		key:       ledgerKey(models.LedgerTransactionGiftCardRedemption, redemption.RedemptionID),
+		kind:      models.LedgerTransactionGiftCardRedemption,
+		debit:     models.LedgerAccountGiftCards,
+		credit:    models.LedgerAccountRevenue,
+		amount:    redemption.Amount,
+		currency:  redemption.Currency,
+		orderID:   redemption.OrderID,
+		reference: redemption.GiftCardID,
+	})
+}
+
+func (s *ledgerService) RecordStoreCredit(ctx context.Context, movement StoreCreditMovement) error {
+	if movement.MovementID == "" || movement.UserID == "" {
+		return errors.NewValidationError("store credit movement ID and user ID are required")
+	}
+
+	posting := ledgerPosting{
+		kind:      models.LedgerTransactionStoreCreditIssued,
+		debit:     models.LedgerAccountRevenue,
+		credit:    models.CustomerAccount(movement.UserID),
+		amount:    movement.Amount,
+		currency:  movement.Currency,
+		orderID:   movement.OrderID,
+		reference: movement.Reason,
+	}
+	if movement.Redeemed {
+		posting.kind = models.LedgerTransactionStoreCreditRedeemed
+		posting.debit, posting.credit = posting.credit, posting.debit
+	}
+	posting.key = ledgerKey(posting.kind, movement.MovementID)
+
+	return s.post(ctx, posting)
+}
+
+// post appends a posting to the ledger as a debit line and a credit line
+func (s *ledgerService) post(ctx context.Context, posting ledgerPosting) error {
+	amount := roundCents(posting.amount)
+	if amount <= 0 {
+		return errors.NewValidationError(fmt.Sprintf("ledger %s amount must be greater than 0", posting.kind))
+	}
+	currency := posting.currency
+	if currency == "" {
+		currency = ledgerDefaultCurrency
+	}
+
+	entries := make([]*models.LedgerEntry, 0, 2)
+	for line, side := range []struct {
+		account   string
+		direction models.LedgerDirection
+	}{
+		{posting.debit, models.LedgerDebit},
+		{posting.credit, models.LedgerCredit},
+	} {
+		entries = append(entries, &models.LedgerEntry{
+			TransactionKey: posting.key,
+			Line:           line + 1,
+			Type:           posting.kind,
+			Account:        side.account,
+			Direction:      side.direction,
+			Amount:         amount,
+			Currency:       currency,
+			OrderID:        posting.orderID,
+			PaymentID:      posting.paymentID,
+			Reference:      posting.reference,
+		})
+	}
+
+	posted, err := s.ledgerRepo.Append(ctx, entries)
+	if err != nil {
+		s.logger.Error("Failed to post ledger transaction", "error", err, "transaction_key", posting.key)
+		return err
+	}
+	if !posted {
+		s.logger.Debug("Ledger transaction was already posted", "transaction_key", posting.key)
+	}
+	return nil
+}
+
+func (s *ledgerService) GetBalances(ctx context.Context, req LedgerBalanceRequest) (*LedgerBalancesResponse, error) {
+	s.logger.Debug("Getting ledger balances", "account", req.Account)
+
+	account := strings.TrimSpace(req.Account)
+	if account == "" {
+		return nil, errors.NewValidationError("ledger account is required")
+	}
+
+	totals, err := s.ledgerRepo.SumByAccount(ctx, account)
+	if err != nil {
+		s.logger.Error("Failed to sum ledger accounts", "error", err, "account", account)
+		return nil, err
+	}
+
+	response := &LedgerBalancesResponse{
+		Account:  account,
+		Accounts: make([]LedgerAccountBalance, len(totals)),
+	}
+	for i, total := range totals {
+		balance := LedgerAccountBalance{
+			Account:       total.Account,
+			NormalBalance: models.LedgerNormalBalance(total.Account),
+			Debits:        roundCents(total.Debits),
+			Credits:       roundCents(total.Credits),
+			Entries:       total.Entries,
+		}
+		balance.Balance = roundCents(balance.Credits - balance.Debits)
+		if balance.NormalBalance == models.LedgerDebit {
+			balance.Balance = -balance.Balance
+		}
+		response.Accounts[i] = balance
+		response.Balance += balance.Balance
+	}
+	response.Balance = roundCents(response.Balance)
+
+	return response, nil
+}
+
+func (s *ledgerService) ListEntries(ctx context.Context, req ListLedgerEntriesRequest) (*ListLedgerEntriesResponse, error) {
+	s.logger.Debug("Listing ledger entries", "page", req.Page, "limit", req.Limit, "account", req.Account)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	filter := repository.LedgerFilter{
+		Account:   req.Account,
+		OrderID:   req.OrderID,
+		PaymentID: req.PaymentID,
+	}
+
+	entries, err := s.ledgerRepo.List(ctx, filter, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list ledger entries", "error", err)
+		return nil, err
+	}
+
+	total, err := s.ledgerRepo.Count(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count ledger entries", "error", err)
+		return nil, err
+	}
+
+	return &ListLedgerEntriesResponse{
+		Entries: entries,
+		Page:    page,
+		Limit:   limit,
+		Total:   int(total),
+	}, nil
+}
+
+func (s *ledgerService) CheckConsistency(ctx context.Context) (*LedgerConsistencyReport, error) {
+	s.logger.Info("Checking ledger consistency")
+
+	report := &LedgerConsistencyReport{
+		CheckedAt:              s.now().UTC(),
+		UnbalancedTransactions: []LedgerTransactionImbalance{},
+	}
+
+	unbalanced, err := s.ledgerRepo.ListUnbalanced(ctx, ledgerConsistencyLimit)
+	if err != nil {
+		s.logger.Error("Failed to list unbalanced ledger transactions", "error", err)
+		return nil, err
+	}
+	for _, transaction := range unbalanced {
+		report.UnbalancedTransactions = append(report.UnbalancedTransactions, LedgerTransactionImbalance{
+			TransactionKey: transaction.TransactionKey,
+			Debits:         roundCents(transaction.Debits),
+			Credits:        roundCents(transaction.Credits),
+		})
+	}
+
+	// Refunded payments were settled before the refund, so both must be posted
+	report.PaymentMismatches, err = s.paymentMismatches(ctx, models.LedgerTransactionPayment,
+		models.PaymentStatusCompleted, models.PaymentStatusRefunded)
+	if err != nil {
+		return nil, err
+	}
+	report.RefundMismatches, err = s.paymentMismatches(ctx, models.LedgerTransactionRefund,
+		models.PaymentStatusRefunded)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Consistent = len(report.UnbalancedTransactions) == 0 &&
+		len(report.PaymentMismatches) == 0 && len(report.RefundMismatches) == 0
+	if !report.Consistent {
+		s.logger.Warn("Ledger is inconsistent",
+			"unbalanced_transactions", len(report.UnbalancedTransactions),
+			"payment_mismatches", len(report.PaymentMismatches),
+			"refund_mismatches", len(report.RefundMismatches))
+	}
+
+	return report, nil
+}
+
+// paymentMismatches lists the payments in the statuses whose postings of the type
+// do not match their amount
+func (s *ledgerService) paymentMismatches(ctx context.Context, kind models.LedgerTransactionType, statuses ...models.PaymentStatus) ([]LedgerPaymentDiscrepancy, error) {
+	mismatches, err := s.ledgerRepo.ListPaymentMismatches(ctx, kind, statuses, ledgerConsistencyLimit)
+	if err != nil {
+		s.logger.Error("Failed to list payments mismatching the ledger", "error", err, "type", kind)
+		return nil, err
+	}
+
+	discrepancies := make([]LedgerPaymentDiscrepancy, len(mismatches))
+	for i, mismatch := range mismatches {
+		discrepancies[i] = LedgerPaymentDiscrepancy{
+			PaymentID: mismatch.PaymentID,
+			OrderID:   mismatch.OrderID,
+			Status:    mismatch.Status,
+			Amount:    mismatch.Amount,
+			Posted:    roundCents(mismatch.Posted),
+		}
+	}
+	return discrepancies, nil
+}
+
+// ledgerKey names the ledger transaction recording a movement
+func ledgerKey(kind models.LedgerTransactionType, id string) string {
+	return string(kind) + ":" + id
+}
+
+// ledgerGateway returns the gateway whose clearing account a payment settles through
+func ledgerGateway(payment *models.Payment) string {
+	if payment.Gateway == "" {
+		return ledgerUnassignedGateway
+	}
+	return payment.Gateway
+}
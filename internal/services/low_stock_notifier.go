@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+)
+
+// lowStockAlertTimeout bounds the lookups behind the low-stock alerts of one change
+const lowStockAlertTimeout = 30 * time.Second
+
+// lowStockNotifier implements StockChangeNotifier, alerting admins when a stock change
+// takes a product to or below its low-stock threshold
+type lowStockNotifier struct {
+	settings      LowStockSettings
+	inventoryRepo repository.InventoryRepository
+	userRepo      repository.UserRepository
+	digests       NotificationDigester
+	logger        *logger.Logger
+
+	// alerted holds the products alerted on since they were last above their
+	// threshold, so a product is alerted on once each time it runs low
+	mutex   sync.Mutex
+	alerted map[string]bool
+}
+
+// NewLowStockNotifier creates a stock change notifier alerting admins about low stock.
+// The alerts go through the digest scheduler, which batches them into an hourly
+// summary per admin.
+func NewLowStockNotifier(
+	settings LowStockSettings,
+	inventoryRepo repository.InventoryRepository,
+	userRepo repository.UserRepository,
+	digests NotificationDigester,
+	logger *logger.Logger,
+) StockChangeNotifier {
+	return &lowStockNotifier{
+		settings:      settings,
+		inventoryRepo: inventoryRepo,
+		userRepo:      userRepo,
+		digests:       digests,
+		logger:        logger,
+		alerted:       make(map[string]bool),
+	}
+}
+
+// NotifyStockChanges checks the changed products against their low-stock thresholds
+// in the background, alerting every admin about the products that ran low
+func (n *lowStockNotifier) NotifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+
+	ids := append([]string(nil), productIDs...)
+
+	go func() {
+		// The request context ends with the request, so the checks get their own
+		checkCtx, cancel := context.WithTimeout(context.Background(), lowStockAlertTimeout)
+		defer cancel()
+
+		n.check(checkCtx, reason, ids)
+	}()
+}
+
+// check alerts on the products that are now at or below their threshold and were
+// not already low
+func (n *lowStockNotifier) check(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	var unitsSold map[string]int
+	if n.settings.Dynamic && n.settings.VelocityWindow > 0 {
+		var err error
+		unitsSold, err = n.inventoryRepo.GetUnitsSoldSince(ctx, time.Now().Add(-n.settings.VelocityWindow))
+		if err != nil {
+			n.logger.Error("Failed to get units sold for low stock alerts", "error", err)
+			return
+		}
+	}
+
+	var low []ProductLowStock
+	seen := make(map[string]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+
+		inventory, err := n.inventoryRepo.GetByProductID(ctx, productID)
+		if err != nil {
+			n.logger.Error("Failed to load inventory for low stock alert", "error", err, "product_id", productID)
+			continue
+		}
+		if inventory == nil {
+			continue
+		}
+
+		alert := n.settings.productLowStock(inventory, unitsSold[productID])
+		if n.ranLow(alert) {
+			low = append(low, alert)
+		}
+	}
+	if len(low) == 0 {
+		return
+	}
+
+	admins, err := n.userRepo.ListByRole(ctx, models.UserRoleAdmin)
+	if err != nil {
+		n.logger.Error("Failed to list admins for low stock alerts", "error", err)
+		return
+	}
+
+	for _, alert := range low {
+		n.logger.Info("Product ran low on stock", "product_id", alert.ProductID, "available", alert.CurrentStock,
+			"threshold", alert.Threshold, "reason", string(reason))
+
+		data, err := json.Marshal(alert)
+		if err != nil {
+			n.logger.Error("Failed to encode low stock alert", "error", err, "product_id", alert.ProductID)
+			continue
+		}
+
+		for _, admin := range admins {
+			if err := n.digests.Submit(newDigestNotification(SendNotificationRequest{
+				UserID:  admin.ID,
+				Type:    string(models.NotificationTypeLowStock),
+				Channel: string(models.NotificationChannelInApp),
+				Title:   fmt.Sprintf("%s is low on stock", alert.ProductName),
+				Body: fmt.Sprintf("%s (SKU %s) has %d units available, at or below its low-stock threshold of %d.",
+					alert.ProductName, alert.SKU, alert.CurrentStock, alert.Threshold),
+				Data: string(data),
+			})); err != nil {
+				n.logger.Error("Failed to submit low stock alert", "error", err, "product_id", alert.ProductID, "user_id", admin.ID)
+			}
+		}
+	}
+}
+
+// ranLow reports whether a product is at or below its threshold without having been
+// alerted on since it was last above it
+func (n *lowStockNotifier) ranLow(alert ProductLowStock) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if alert.CurrentStock > alert.Threshold {
+		delete(n.alerted, alert.ProductID)
+		return false
+	}
+	if n.alerted[alert.ProductID] {
+		return false
+	}
+	n.alerted[alert.ProductID] = true
+	return true
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// manualPaymentGateway is the gateway recorded on offline payments; the ledger
+	// clears them through its own account until they are reconciled with the bank
+	manualPaymentGateway = "manual"
+
+	// maxPaymentProofSize caps the size of a payment proof upload
+	maxPaymentProofSize = 5 << 20
+)
+
+// paymentProofTypes are the content types accepted as payment proof, as detected
+// from the uploaded file rather than as claimed by the client
+var paymentProofTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+}
+
+// manualPaymentService implements ManualPaymentService interface. Offline payments
+// skip the gateway flow: they are recorded as completed, with the admin who recorded
+// them and an audit log of the order's change, in one transaction.
+type manualPaymentService struct {
+	paymentRepo repository.PaymentRepository
+	orderRepo   repository.OrderRepository
+	activity    ActivityRecorder
+	ledger      LedgerRecorder
+	now         func() time.Time
+	logger      *logger.Logger
+}
+
+// NewManualPaymentService creates a new manual payment service
+func NewManualPaymentService(
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	activity ActivityRecorder,
+	ledger LedgerRecorder,
+	logger *logger.Logger,
+) ManualPaymentService {
+	return &manualPaymentService{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		activity:    activity,
+		ledger:      ledger,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+func (s *manualPaymentService) RecordPayment(ctx context.Context, orderID string, actor AuditActor, req RecordManualPaymentRequest) (*ManualPaymentResponse, error) {
+	s.logger.Info("Recording manual payment", "order_id", orderID, "user_id", actor.UserID,
+		"method", req.Method, "amount", req.Amount)
+
+	if orderID == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+	if !req.Method.IsOffline() {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment method %s cannot be recorded manually", req.Method))
+	}
+	reference := strings.TrimSpace(req.Reference)
+	if reference == "" {
+		return nil, errors.NewValidationError("payment reference is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for manual payment", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+
+	// The order total includes the surcharge or discount for the checkout payment method
+	if order.PaymentMethod != "" && req.Method != order.PaymentMethod {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment method %s does not match checkout payment method %s",
+			req.Method, order.PaymentMethod))
+	}
+	if !order.IsPayable() {
+		return nil, errors.NewConflictError(fmt.Sprintf("order in status %s cannot be paid", order.Status))
+	}
+
+	existingPayments, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to check existing payments", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	for _, payment := range existingPayments {
+		if payment.IsHeld() {
+			return nil, errors.NewConflictError(fmt.Sprintf("payment %s is held for retry", payment.ID))
+		}
+	}
+
+	// An order can be paid in parts, up to its total
+	paid, err := s.paymentRepo.SumCompletedByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to sum completed payments", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	balance := roundCents(order.TotalAmount - paid)
+	if balance <= 0 {
+		return nil, errors.NewConflictError("order has already been paid")
+	}
+	amount := roundCents(req.Amount)
+	if amount > balance {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment amount %.2f exceeds the %.2f left to pay of order total %.2f",
+			req.Amount, balance, order.TotalAmount))
+	}
+
+	// A reference can only be recorded once per method, so a transfer is not counted twice
+	key := manualPaymentKey(req.Method, reference)
+	duplicate, err := s.paymentRepo.GetByIdempotencyKey(ctx, key)
+	if err != nil {
+		s.logger.Error("Failed to check payment reference", "error", err, "reference", reference)
+		return nil, err
+	}
+	if duplicate != nil {
+		return nil, errors.NewConflictError(fmt.Sprintf("%s reference %s is already recorded on payment %s",
+			req.Method, reference, duplicate.ID))
+	}
+
+	proof, err := newPaymentProof(req.Proof, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	payment := &models.Payment{
+		ID:                uuid.New().String(),
+		OrderID:           order.ID,
+		Amount:            amount,
+		Currency:          order.Currency,
+		Status:            models.PaymentStatusCompleted,
+		Method:            req.Method,
+		ExternalReference: reference,
+		IdempotencyKey:    key,
+		Gateway:           manualPaymentGateway,
+		ProcessedAt:       &now,
+		RecordedBy:        &actor.UserID,
+	}
+	if note := strings.TrimSpace(req.Note); note != "" {
+		metadata, err := json.Marshal(map[string]string{"note": note})
+		if err != nil {
+			return nil, errors.NewInternalError("failed to encode payment note", err)
+		}
+		value := string(metadata)
+		payment.Metadata = &value
+	}
+
+	// The order is paid once the payment covers what is left, but on-account invoices
+	// paid after shipping keep their status
+	status := order.Status
+	if amount == balance && (order.IsPending() || order.IsConfirmed()) {
+		status = models.OrderStatusPaid
+	}
+
+	auditLog, err := newManualPaymentAuditLog(order, status, payment, proof, actor)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to encode payment audit log", err)
+	}
+
+	recorded, err := s.paymentRepo.RecordManual(ctx, repository.ManualPaymentRecord{
+		Payment:     payment,
+		Proof:       proof,
+		AuditLog:    auditLog,
+		OrderStatus: order.Status,
+		PaidAmount:  paid,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record manual payment", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if !recorded {
+		return nil, errors.NewConflictError("order was paid or changed status while the payment was being recorded")
+	}
+
+	// The payment is recorded either way, so a ledger failure is left to the ledger
+	// consistency check rather than failing it
+	if s.ledger != nil {
+		if err := s.ledger.RecordPayment(ctx, payment); err != nil {
+			s.logger.Error("Failed to post manual payment to the ledger", "error", err, "payment_id", payment.ID)
+		}
+	}
+
+	s.logger.Info("Manual payment recorded", "payment_id", payment.ID, "order_id", order.ID,
+		"user_id", actor.UserID, "method", req.Method, "reference", reference)
+	recordActivity(ctx, s.activity, order.UserID, models.ActivityEventPayment, payment.ID)
+
+	return &ManualPaymentResponse{
+		Payment:     toPaymentResponse(payment),
+		Method:      payment.Method,
+		Reference:   reference,
+		OrderStatus: status,
+		RecordedBy:  actor.UserID,
+		Proof:       proof,
+	}, nil
+}
+
+func (s *manualPaymentService) GetProof(ctx context.Context, paymentID string) (*models.PaymentProof, error) {
+	s.logger.Debug("Getting payment proof", "payment_id", paymentID)
+
+	if paymentID == "" {
+		return nil, errors.NewValidationError("payment ID is required")
+	}
+
+	proof, err := s.paymentRepo.GetProof(ctx, paymentID)
+	if err != nil {
+		s.logger.Error("Failed to get payment proof", "error", err, "payment_id", paymentID)
+		return nil, err
+	}
+	if proof == nil {
+		return nil, errors.NewNotFoundErrorWithID("payment proof", paymentID)
+	}
+
+	return proof, nil
+}
+
+// newPaymentProof checks an uploaded payment proof and returns it ready to store, or
+// nil when no proof was uploaded
+func newPaymentProof(upload *PaymentProofUpload, userID string) (*models.PaymentProof, error) {
+	if upload == nil {
+		return nil, nil
+	}
+	if len(upload.Content) == 0 {
+		return nil, errors.NewValidationError("payment proof is empty")
+	}
+	if len(upload.Content) > maxPaymentProofSize {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment proof exceeds %d MB", maxPaymentProofSize>>20))
+	}
+
+	contentType := http.DetectContentType(upload.Content)
+	if !paymentProofTypes[contentType] {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment proof of type %s is not supported; upload a PDF, PNG or JPEG", contentType))
+	}
+
+	sum := sha256.Sum256(upload.Content)
+	return &models.PaymentProof{
+		ID:          uuid.New().String(),
+		FileName:    filepath.Base(strings.TrimSpace(upload.FileName)),
+		ContentType: contentType,
+		Size:        len(upload.Content),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Content:     upload.Content,
+		UploadedBy:  userID,
+	}, nil
+}
+
+// newManualPaymentAuditLog returns the audit log of an offline payment, recorded
+// against the order so that it appears in the order's history
+func newManualPaymentAuditLog(order *models.Order, status models.OrderStatus, payment *models.Payment, proof *models.PaymentProof, actor AuditActor) (*models.AuditLog, error) {
+	auditLog := &models.AuditLog{
+		UserID:     &actor.UserID,
+		EntityType: "order",
+		EntityID:   order.ID,
+		Action:     models.AuditActionManualPayment,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+
+	if err := auditLog.SetOldValues(map[string]interface{}{"status": order.Status}); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"status":     status,
+		"payment_id": payment.ID,
+		"method":     payment.Method,
+		"amount":     payment.Amount,
+		"reference":  payment.ExternalReference,
+	}
+	if proof != nil {
+		values["proof_id"] = proof.ID
+		values["proof_sha256"] = proof.SHA256
+	}
+	if err := auditLog.SetNewValues(values); err != nil {
+		return nil, err
+	}
+
+	return auditLog, nil
+}
+
+// manualPaymentKey is the idempotency key of an offline payment, unique per method
+// and reference
+func manualPaymentKey(method models.PaymentMethod, reference string) string {
+	return "manual:" + string(method) + ":" + reference
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+)
+
+// notificationDigestDataKey carries the data of a notification request through the
+// digest scheduler
+const notificationDigestDataKey = "data"
+
+// NotificationDigester takes notifications that may wait: urgent ones are sent
+// straight away and the rest are batched into hourly or daily digests per recipient
+// and channel. notifications.DigestScheduler is a NotificationDigester.
+type NotificationDigester interface {
+	Submit(notification *notifications.Notification) error
+}
+
+// newDigestNotification converts a notification request for the digest scheduler
+func newDigestNotification(req SendNotificationRequest) *notifications.Notification {
+	notification := notifications.NewNotification(notifications.NotificationType(req.Type), req.Channel, req.UserID)
+	notification.Subject = req.Title
+	notification.Body = req.Body
+	if req.Data != "" {
+		notification.Data[notificationDigestDataKey] = req.Data
+	}
+	return notification
+}
+
+// NotificationDigestSender sends what the digest scheduler passes on through the
+// notification service, so digests are stored, fail over between channels and show in
+// the recipient's notifications like any other notification
+type NotificationDigestSender struct {
+	notificationService NotificationService
+	logger              *logger.Logger
+}
+
+// NewNotificationDigestSender creates a digest sink sending through the notification service
+func NewNotificationDigestSender(notificationService NotificationService, logger *logger.Logger) *NotificationDigestSender {
+	return &NotificationDigestSender{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// Dispatch sends a digest, or a notification that was not batched, to its recipient.
+// A digest carries the IDs and counts by type of the notifications it summarizes as
+// its data.
+func (s *NotificationDigestSender) Dispatch(notification *notifications.Notification) error {
+	req := SendNotificationRequest{
+		UserID:  notification.Recipient,
+		Type:    string(notification.Type),
+		Channel: notification.Channel,
+		Title:   notification.Subject,
+		Body:    notification.Body,
+	}
+	if data, ok := notification.Data[notificationDigestDataKey].(string); ok {
+		req.Data = data
+	} else if notification.Type == notifications.NotificationTypeDigest {
+		data, err := json.Marshal(notification.Data)
+		if err != nil {
+			s.logger.Error("Failed to encode notification digest", "error", err, "recipient", notification.Recipient)
+			return err
+		}
+		req.Data = string(data)
+	}
+
+	// Digests are flushed by the scheduler rather than on behalf of a request
+	return s.notificationService.SendNotification(context.Background(), req)
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// defaultNotificationSplitPercent sends half the users each variant
+	defaultNotificationSplitPercent = 50
+	// defaultNotificationConversionWindowHours is how long after a notification a
+	// conversion counts, unless the experiment says otherwise
+	defaultNotificationConversionWindowHours = 72
+	// notificationSignificanceZ is the z-score of a two-sided 95% confidence level
+	notificationSignificanceZ = 1.96
+)
+
+// notificationExperimentService implements NotificationExperimentService interface
+type notificationExperimentService struct {
+	experimentRepo repository.NotificationExperimentRepository
+	translator     *i18n.Translator
+	now            func() time.Time
+	logger         *logger.Logger
+}
+
+// NewNotificationExperimentService creates a new notification experiment service
+func NewNotificationExperimentService(
+	experimentRepo repository.NotificationExperimentRepository,
+	translator *i18n.Translator,
+	logger *logger.Logger,
+) NotificationExperimentService {
+	return &notificationExperimentService{
+		experimentRepo: experimentRepo,
+		translator:     translator,
+		now:            time.Now,
+		logger:         logger,
+	}
+}
+
+// CreateExperiment starts an A/B test of the wording of a notification type in one
+// locale. A type and locale run one experiment at a time; starting one supersedes the
+// winner of a concluded one.
+func (s *notificationExperimentService) CreateExperiment(ctx context.Context, userID string, req CreateNotificationExperimentRequest) (*models.NotificationExperiment, error) {
+	s.logger.Info("Creating notification experiment", "name", req.Name, "type", req.Type, "locale", req.Locale, "user_id", userID)
+
+	locale := strings.ToLower(strings.TrimSpace(req.Locale))
+	if locale == "" {
+		locale = i18n.DefaultLocale
+	}
+	if s.translator.Catalog(locale) == nil {
+		return nil, errors.NewValidationError(fmt.Sprintf("unsupported locale %s", locale))
+	}
+	goal := models.NotificationConversionGoal(req.ConversionGoal)
+	if goal != models.NotificationConversionPayment && goal != models.NotificationConversionOrder {
+		return nil, errors.NewValidationError(fmt.Sprintf("unsupported conversion goal %s", req.ConversionGoal))
+	}
+
+	split := req.SplitPercent
+	if split == 0 {
+		split = defaultNotificationSplitPercent
+	}
+	if split < 1 || split > 99 {
+		return nil, errors.NewValidationError("split percent must be between 1 and 99")
+	}
+	window := req.ConversionWindowHours
+	if window == 0 {
+		window = defaultNotificationConversionWindowHours
+	}
+
+	experiment := &models.NotificationExperiment{
+		Name:                  strings.TrimSpace(req.Name),
+		Type:                  models.NotificationType(req.Type),
+		Locale:                locale,
+		VariantATitle:         req.VariantA.Title,
+		VariantABody:          req.VariantA.Body,
+		VariantBTitle:         req.VariantB.Title,
+		VariantBBody:          req.VariantB.Body,
+		SplitPercent:          split,
+		ConversionGoal:        goal,
+		ConversionWindowHours: window,
+		Status:                models.NotificationExperimentStatusRunning,
+		CreatedBy:             userID,
+		StartedAt:             s.now(),
+	}
+	created, err := s.experimentRepo.Create(ctx, experiment)
+	if err != nil {
+		s.logger.Error("Failed to create notification experiment", "error", err, "type", req.Type)
+		return nil, errors.NewDatabaseError("failed to create notification experiment", err)
+	}
+	if !created {
+		return nil, errors.NewConflictError(fmt.Sprintf("%s notifications in %s already have a running experiment", req.Type, locale))
+	}
+
+	s.logger.Info("Notification experiment started", "experiment_id", experiment.ID, "type", experiment.Type, "split_percent", split)
+	return experiment, nil
+}
+
+func (s *notificationExperimentService) ListExperiments(ctx context.Context, req ListNotificationExperimentsRequest) ([]*models.NotificationExperiment, error) {
+	s.logger.Debug("Listing notification experiments", "type", req.Type, "status", req.Status)
+
+	experiments, err := s.experimentRepo.List(ctx, models.NotificationType(req.Type), models.NotificationExperimentStatus(req.Status))
+	if err != nil {
+		s.logger.Error("Failed to list notification experiments", "error", err)
+		return nil, errors.NewDatabaseError("failed to list notification experiments", err)
+	}
+	return experiments, nil
+}
+
+// StopExperiment concludes a running experiment with its winner, or stops a running or
+// concluded one, returning its notifications to the catalog wording
+func (s *notificationExperimentService) StopExperiment(ctx context.Context, id string, req StopNotificationExperimentRequest) (*models.NotificationExperiment, error) {
+	s.logger.Info("Stopping notification experiment", "experiment_id", id, "winner", req.Winner)
+
+	experiment, err := s.getExperiment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.NotificationExperimentStatusStopped
+	from := []models.NotificationExperimentStatus{
+		models.NotificationExperimentStatusRunning,
+		models.NotificationExperimentStatusConcluded,
+	}
+	switch req.Winner {
+	case "":
+	case models.NotificationVariantA, models.NotificationVariantB:
+		status = models.NotificationExperimentStatusConcluded
+		from = []models.NotificationExperimentStatus{models.NotificationExperimentStatusRunning}
+	default:
+		return nil, errors.NewValidationError(fmt.Sprintf("unknown variant %s", req.Winner))
+	}
+
+	now := s.now()
+	ended, err := s.experimentRepo.End(ctx, id, from, status, req.Winner, now)
+	if err != nil {
+		s.logger.Error("Failed to stop notification experiment", "error", err, "experiment_id", id)
+		return nil, errors.NewDatabaseError("failed to stop notification experiment", err)
+	}
+	if !ended {
+		return nil, errors.NewConflictError(fmt.Sprintf("notification experiment %s is already %s", id, experiment.Status))
+	}
+
+	if experiment.EndedAt == nil {
+		experiment.EndedAt = &now
+	}
+	experiment.Status = status
+	experiment.Winner = req.Winner
+
+	s.logger.Info("Notification experiment stopped", "experiment_id", id, "status", status, "winner", req.Winner)
+	return experiment, nil
+}
+
+// GetResults reports how each variant's notifications were delivered, read and
+// converted, and whether variant B's conversion rate differs from variant A's beyond
+// chance by a two-proportion z-test
+func (s *notificationExperimentService) GetResults(ctx context.Context, id string) (*NotificationExperimentResultsResponse, error) {
+	s.logger.Info("Getting notification experiment results", "experiment_id", id)
+
+	experiment, err := s.getExperiment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.experimentRepo.SummarizeVariants(ctx, experiment)
+	if err != nil {
+		s.logger.Error("Failed to summarize notification experiment", "error", err, "experiment_id", id)
+		return nil, errors.NewDatabaseError("failed to summarize notification experiment", err)
+	}
+
+	// Both variants are reported, even before either was sent
+	results := map[string]*NotificationVariantResult{
+		models.NotificationVariantA: {Variant: models.NotificationVariantA},
+		models.NotificationVariantB: {Variant: models.NotificationVariantB},
+	}
+	for _, summary := range summaries {
+		result, ok := results[summary.Variant]
+		if !ok {
+			continue
+		}
+		var group notificationDeliveryGroup
+		group.add(summary.NotificationDeliverySummary)
+		result.NotificationDeliveryStats = group.stats()
+		result.Conversions = int(summary.Conversions)
+		result.ConversionRate = percentage(result.Conversions, result.Notifications)
+	}
+
+	a, b := results[models.NotificationVariantA], results[models.NotificationVariantB]
+	response := &NotificationExperimentResultsResponse{
+		Experiment:  experiment,
+		Variants:    []NotificationVariantResult{*a, *b},
+		Significant: conversionDifferenceSignificant(a, b),
+	}
+	if a.ConversionRate > 0 {
+		response.Lift = roundCents((b.ConversionRate - a.ConversionRate) / a.ConversionRate * 100)
+	}
+	switch {
+	case b.ConversionRate > a.ConversionRate:
+		response.Leader = models.NotificationVariantB
+	case a.ConversionRate > b.ConversionRate:
+		response.Leader = models.NotificationVariantA
+	}
+
+	return response, nil
+}
+
+// ApplyVariant words a notification by the experiment of its type active in the
+// user's locale. While the experiment runs the user's variant is applied and
+// recorded on the notification; once concluded, its winner is applied to everyone.
+// Notifications without an experiment, or whose experiment cannot be read, keep the
+// wording they were sent with.
+func (s *notificationExperimentService) ApplyVariant(ctx context.Context, notification *models.Notification, locale string) {
+	experiment, err := s.experimentRepo.GetActive(ctx, notification.Type, locale)
+	if err != nil {
+		s.logger.Warn("Failed to get notification experiment, sending default wording", "error", err, "type", notification.Type)
+		return
+	}
+	if experiment == nil {
+		return
+	}
+
+	variant := experiment.Winner
+	if experiment.Status == models.NotificationExperimentStatusRunning {
+		variant = experiment.AssignVariant(notification.UserID)
+		notification.ExperimentID = &experiment.ID
+		notification.Variant = variant
+	}
+
+	params := notificationTemplateParams(notification.Data)
+	title, body := experiment.Template(variant)
+	notification.Title = fillNotificationTemplate(title, params)
+	notification.Body = fillNotificationTemplate(body, params)
+}
+
+// getExperiment returns an experiment, reporting a missing one as not found
+func (s *notificationExperimentService) getExperiment(ctx context.Context, id string) (*models.NotificationExperiment, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("experiment ID is required")
+	}
+
+	experiment, err := s.experimentRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get notification experiment", "error", err, "experiment_id", id)
+		return nil, errors.NewDatabaseError("failed to get notification experiment", err)
+	}
+	if experiment == nil {
+		return nil, errors.NewNotFoundErrorWithID("notification experiment", id)
+	}
+	return experiment, nil
+}
+
+// notificationTemplateParams reads the values of a notification's JSON data as
+// template parameters
+func notificationTemplateParams(data string) map[string]string {
+	if data == "" {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return nil
+	}
+	params := make(map[string]string, len(fields))
+	for key, value := range fields {
+		params[key] = fmt.Sprint(value)
+	}
+	return params
+}
+
+// fillNotificationTemplate replaces the {name} placeholders of a template
+func fillNotificationTemplate(template string, params map[string]string) string {
+	for name, value := range params {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+	return template
+}
+
+// conversionDifferenceSignificant reports whether the conversion rates of two variants
+// differ at 95% confidence by a two-proportion z-test
+func conversionDifferenceSignificant(a, b *NotificationVariantResult) bool {
+	if a.Notifications == 0 || b.Notifications == 0 {
+		return false
+	}
+	na, nb := float64(a.Notifications), float64(b.Notifications)
+	pa, pb := float64(a.Conversions)/na, float64(b.Conversions)/nb
+	pooled := float64(a.Conversions+b.Conversions) / (na + nb)
+	stderr := math.Sqrt(pooled * (1 - pooled) * (1/na + 1/nb))
+	if stderr == 0 {
+		return false
+	}
+	return math.Abs(pb-pa)/stderr >= notificationSignificanceZ
+}
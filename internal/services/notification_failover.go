@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+)
+
+// DefaultNotificationPolicy keys the failover policy of notification types without one
+// of their own
+const DefaultNotificationPolicy = "*"
+
+// ErrNotificationBounced is wrapped by senders when a channel rejected a single
+// notification, such as an email bouncing off its recipient's mailbox. A bounce falls
+// back to the next channel like any failure, but says nothing about the provider being
+// down, so it does not count against the channel's circuit breaker.
+var ErrNotificationBounced = stderrors.New("notification bounced")
+
+// NotificationSender delivers a notification through one channel. An error means the
+// channel did not deliver it.
+type NotificationSender interface {
+	Send(ctx context.Context, channel models.NotificationChannel, notification *models.Notification) error
+}
+
+// notificationTypes lists the notification types a failover policy may be set for
+var notificationTypes = map[models.NotificationType]bool{
+	models.NotificationTypeOrderConfirmed:  true,
+	models.NotificationTypeOrderShipped:    true,
+	models.NotificationTypeOrderDelivered:  true,
+	models.NotificationTypeOrderCancelled:  true,
+	models.NotificationTypePaymentSuccess:  true,
+	models.NotificationTypePaymentFailed:   true,
+	models.NotificationTypeLowStock:        true,
+	models.NotificationTypePromotion:       true,
+	models.NotificationTypeSystem:          true,
+	models.NotificationTypeCloseReport:     true,
+	models.NotificationTypeReportCompleted: true,
+	models.NotificationTypeDigest:          true,
+}
+
+// NotificationFailoverSettings configures notification channel failover. Policies
+// lists, per notification type, the channels to fall back to in order when the
+// requested channel fails; the DefaultNotificationPolicy entry covers the other types.
+// A channel whose provider fails FailureThreshold times is skipped for ResetTimeout.
+type NotificationFailoverSettings struct {
+	Policies         map[models.NotificationType][]models.NotificationChannel
+	FailureThreshold int
+	ResetTimeout     time.Duration
+}
+
+// NewNotificationFailoverSettings builds settings from configured type and channel
+// names, rejecting unknown ones
+func NewNotificationFailoverSettings(policies map[string][]string, failureThreshold int, resetTimeout time.Duration) (NotificationFailoverSettings, error) {
+	settings := NotificationFailoverSettings{
+		Policies:         make(map[models.NotificationType][]models.NotificationChannel, len(policies)),
+		FailureThreshold: failureThreshold,
+		ResetTimeout:     resetTimeout,
+	}
+	for name, channels := range policies {
+		notificationType := models.NotificationType(name)
+		if name != DefaultNotificationPolicy && !notificationTypes[notificationType] {
+			return settings, fmt.Errorf("unknown notification type %q", name)
+		}
+		for _, channel := range channels {
+			notificationChannel, err := parseNotificationChannel(channel)
+			if err != nil {
+				return settings, err
+			}
+			settings.Policies[notificationType] = append(settings.Policies[notificationType], notificationChannel)
+		}
+	}
+	return settings, nil
+}
+
+// NotificationFailover delivers notifications through the first working channel of
+// their type's policy, starting with the channel they were requested on. Each channel
+// has a circuit breaker, so a channel whose provider is down is skipped without
+// waiting on it. A nil failover delivers on the requested channel only.
+type NotificationFailover struct {
+	settings NotificationFailoverSettings
+	breakers map[models.NotificationChannel]*payments.CircuitBreaker
+	logger   *logger.Logger
+}
+
+// NewNotificationFailover creates the notification failover of the given settings
+func NewNotificationFailover(settings NotificationFailoverSettings, logger *logger.Logger) *NotificationFailover {
+	config := payments.DefaultCircuitBreakerConfig()
+	if settings.FailureThreshold > 0 {
+		config.FailureThreshold = settings.FailureThreshold
+	}
+	if settings.ResetTimeout > 0 {
+		config.ResetTimeout = settings.ResetTimeout
+	}
+	// One delivery getting through is enough to trust a recovered provider again
+	config.SuccessThreshold = 1
+
+	breakers := make(map[models.NotificationChannel]*payments.CircuitBreaker)
+	for _, channel := range []models.NotificationChannel{
+		models.NotificationChannelEmail, models.NotificationChannelSMS,
+		models.NotificationChannelPush, models.NotificationChannelInApp,
+	} {
+		breakers[channel] = payments.NewCircuitBreaker("notification_"+string(channel), config, logger)
+	}
+
+	return &NotificationFailover{
+		settings: settings,
+		breakers: breakers,
+		logger:   logger,
+	}
+}
+
+// Deliver sends the notification through the first channel that delivers it,
+// returning that channel, or the last failure when none did
+func (f *NotificationFailover) Deliver(ctx context.Context, sender NotificationSender, notification *models.Notification) (models.NotificationChannel, error) {
+	if f == nil {
+		if err := sender.Send(ctx, notification.Channel, notification); err != nil {
+			return "", err
+		}
+		return notification.Channel, nil
+	}
+
+	var lastErr error
+	for _, channel := range f.channels(notification) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		err := f.send(ctx, sender, channel, notification)
+		if err == nil {
+			return channel, nil
+		}
+		f.logger.Warn("Notification channel failed", "error", err, "notification_id", notification.ID, "channel", channel)
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// send delivers the notification through one channel unless its provider is down
+func (f *NotificationFailover) send(ctx context.Context, sender NotificationSender, channel models.NotificationChannel, notification *models.Notification) error {
+	breaker := f.breakers[channel]
+	if breaker == nil {
+		return sender.Send(ctx, channel, notification)
+	}
+	if !breaker.CanExecute() {
+		return fmt.Errorf("notification channel %s is down", channel)
+	}
+
+	err := sender.Send(ctx, channel, notification)
+	switch {
+	case err == nil:
+		breaker.RecordSuccess()
+	case !stderrors.Is(err, ErrNotificationBounced):
+		breaker.RecordFailure(err)
+	}
+	return err
+}
+
+// channels returns the channels to try for the notification in order: the requested
+// channel, then the fallbacks of its type's policy
+func (f *NotificationFailover) channels(notification *models.Notification) []models.NotificationChannel {
+	fallbacks, ok := f.settings.Policies[notification.Type]
+	if !ok {
+		fallbacks = f.settings.Policies[DefaultNotificationPolicy]
+	}
+
+	channels := []models.NotificationChannel{notification.Channel}
+	for _, channel := range fallbacks {
+		if channel != notification.Channel {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// parseNotificationChannel returns the notification channel of a configured name
+func parseNotificationChannel(name string) (models.NotificationChannel, error) {
+	channel := models.NotificationChannel(name)
+	switch channel {
+	case models.NotificationChannelEmail, models.NotificationChannelSMS,
+		models.NotificationChannelPush, models.NotificationChannelInApp:
+		return channel, nil
+	default:
+		return "", fmt.Errorf("unknown notification channel %q", name)
+	}
+}
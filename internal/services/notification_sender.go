@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/logger"
+)
+
+// simulatedNotificationSender simulates sending notifications via different channels.
+// In a real implementation, this would integrate with email, SMS, push notification services
+type simulatedNotificationSender struct {
+	logger *logger.Logger
+}
+
+// NewSimulatedNotificationSender creates a notification sender that simulates every channel
+func NewSimulatedNotificationSender(logger *logger.Logger) NotificationSender {
+	return &simulatedNotificationSender{logger: logger}
+}
+
+func (s *simulatedNotificationSender) Send(ctx context.Context, channel models.NotificationChannel, notification *models.Notification) error {
+	s.logger.Debug("Simulating notification send", "notification_id", notification.ID, "channel", channel)
+
+	switch channel {
+	case models.NotificationChannelEmail:
+		return s.simulateEmailNotification(ctx, notification)
+	case models.NotificationChannelSMS:
+		return s.simulateSMSNotification(ctx, notification)
+	case models.NotificationChannelPush:
+		return s.simulatePushNotification(ctx, notification)
+	case models.NotificationChannelInApp:
+		return s.simulateInAppNotification(ctx, notification)
+	default:
+		return fmt.Errorf("unsupported notification channel: %s", channel)
+	}
+}
+
+func (s *simulatedNotificationSender) simulateEmailNotification(ctx context.Context, notification *models.Notification) error {
+	s.logger.Debug("Simulating email notification", "notification_id", notification.ID)
+	// Simulate email sending delay
+	// In reality, this would integrate with email service like SendGrid, AWS SES, etc.
+	return nil
+}
+
+func (s *simulatedNotificationSender) simulateSMSNotification(ctx context.Context, notification *models.Notification) error {
+	s.logger.Debug("Simulating SMS notification", "notification_id", notification.ID)
+	// Simulate SMS sending delay
+	// In reality, this would integrate with SMS service like Twilio, AWS SNS, etc.
+	return nil
+}
+
+func (s *simulatedNotificationSender) simulatePushNotification(ctx context.Context, notification *models.Notification) error {
+	s.logger.Debug("Simulating push notification", "notification_id", notification.ID)
+	// Simulate push notification delay
+	// In reality, this would integrate with push service like Firebase Cloud Messaging, Apple Push Notification service, etc.
+	return nil
+}
+
+func (s *simulatedNotificationSender) simulateInAppNotification(ctx context.Context, notification *models.Notification) error {
+	s.logger.Debug("Simulating in-app notification", "notification_id", notification.ID)
+	// In-app notifications are just stored in the database and shown in the UI.
+	// No external service integration needed
+	return nil
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/logger"
 )
 
@@ -14,18 +18,32 @@ import (
 type notificationService struct {
 	notificationRepo repository.NotificationRepository
 	userRepo         repository.UserRepository
+	sender           NotificationSender
+	failover         *NotificationFailover
+	variants         NotificationVariantPicker
 	logger           *logger.Logger
 }
 
-// NewNotificationService creates a new notification service
+// NewNotificationService creates a new notification service. A nil sender delivers
+// through the simulated channels; a nil variant picker sends notifications as worded
+// by their senders.
 func NewNotificationService(
 	notificationRepo repository.NotificationRepository,
 	userRepo repository.UserRepository,
+	sender NotificationSender,
+	failover *NotificationFailover,
+	variants NotificationVariantPicker,
 	logger *logger.Logger,
 ) NotificationService {
+	if sender == nil {
+		sender = NewSimulatedNotificationSender(logger)
+	}
 	return &notificationService{
 		notificationRepo: notificationRepo,
 		userRepo:         userRepo,
+		sender:           sender,
+		failover:         failover,
+		variants:         variants,
 		logger:           logger,
 	}
 }
@@ -33,25 +51,132 @@ func NewNotificationService(
 func (s *notificationService) SendNotification(ctx context.Context, req SendNotificationRequest) error {
 	s.logger.Info("Sending notification", "user_id", req.UserID, "type", req.Type, "channel", req.Channel)
 
+	notification, err := s.createNotification(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := s.deliverNotification(ctx, notification); err != nil {
+		s.logger.Error("Failed to send notification", "error", err, "notification_id", notification.ID)
+		// Don't fail the request, just log the error
+		return nil
+	}
+
+	s.logger.Info("Notification sent successfully", "notification_id", notification.ID, "user_id", req.UserID)
+	return nil
+}
+
+const (
+	// maxBatchNotifications caps how many notifications one batch call may enqueue
+	maxBatchNotifications = 1000
+	// batchNotificationWorkers is the number of recipients processed concurrently
+	batchNotificationWorkers = 10
+
+	BatchNotificationStatusSent   = "sent"
+	BatchNotificationStatusFailed = "failed"
+)
+
+func (s *notificationService) SendBatch(ctx context.Context, req SendBatchNotificationsRequest) (*BatchNotificationResponse, error) {
+	total := len(req.Notifications)
+	s.logger.Info("Sending notification batch", "count", total)
+
+	if total == 0 || total > maxBatchNotifications {
+		return nil, fmt.Errorf("batch must contain between 1 and %d notifications", maxBatchNotifications)
+	}
+
+	results := make([]BatchNotificationResult, total)
+	jobs := make(chan int)
+
+	workerCount := batchNotificationWorkers
+	if total < workerCount {
+		workerCount = total
+	}
+
+	// Each worker writes only to the result slots of the indexes it receives
+	var wg sync.WaitGroup
+	for w := 0; w < workerCount; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.sendBatchItem(ctx, i, req.Notifications[i])
+			}
+		}()
+	}
+
+	for i := range req.Notifications {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	response := &BatchNotificationResponse{
+		Total:   total,
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Status == BatchNotificationStatusSent {
+			response.Sent++
+		} else {
+			response.Failed++
+		}
+	}
+
+	s.logger.Info("Notification batch processed", "total", total, "sent", response.Sent, "failed", response.Failed)
+	return response, nil
+}
+
+// sendBatchItem creates and delivers one notification of a batch
+func (s *notificationService) sendBatchItem(ctx context.Context, index int, req SendNotificationRequest) BatchNotificationResult {
+	result := BatchNotificationResult{
+		Index:  index,
+		UserID: req.UserID,
+		Status: BatchNotificationStatusFailed,
+	}
+
+	if err := ctx.Err(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	notification, err := s.createNotification(ctx, req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.NotificationID = notification.ID
+
+	if err := s.deliverNotification(ctx, notification); err != nil {
+		s.logger.Warn("Failed to deliver batch notification", "error", err, "notification_id", notification.ID)
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = BatchNotificationStatusSent
+	return result
+}
+
+// createNotification validates the request and stores the notification
+func (s *notificationService) createNotification(ctx context.Context, req SendNotificationRequest) (*models.Notification, error) {
 	// Validate request
 	if req.UserID == "" {
-		return errors.New("user ID is required")
+		return nil, errors.New("user ID is required")
 	}
 	if req.Title == "" {
-		return errors.New("notification title is required")
+		return nil, errors.New("notification title is required")
 	}
 	if req.Body == "" {
-		return errors.New("notification body is required")
+		return nil, errors.New("notification body is required")
 	}
 
 	// Check if a user exists
 	user, err := s.userRepo.GetByID(ctx, req.UserID)
 	if err != nil {
 		s.logger.Error("Failed to get user for notification", "error", err, "user_id", req.UserID)
-		return err
+		return nil, err
 	}
 	if user == nil {
-		return errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	// Set defaults if not provided
@@ -76,25 +201,45 @@ func (s *notificationService) SendNotification(ctx context.Context, req SendNoti
 		Read:    false,
 	}
 
+	// A wording experiment of the type rewords the notification in the user's locale
+	if s.variants != nil {
+		locale := user.Locale
+		if locale == "" {
+			locale = i18n.DefaultLocale
+		}
+		s.variants.ApplyVariant(ctx, notification, locale)
+	}
+
 	if err := s.notificationRepo.Create(ctx, notification); err != nil {
 		s.logger.Error("Failed to create notification", "error", err, "user_id", req.UserID)
-		return err
+		return nil, err
 	}
 
-	// Simulate sending notification via the specified channel
-	if err := s.simulateSendNotification(ctx, notification); err != nil {
-		s.logger.Error("Failed to send notification", "error", err, "notification_id", notification.ID)
-		// Don't fail the request, just log the error
-		return nil
+	return notification, nil
+}
+
+// deliverNotification sends a stored notification via its channel, falling back to
+// the other channels of its type's failover policy, and records which channel
+// delivered it
+func (s *notificationService) deliverNotification(ctx context.Context, notification *models.Notification) error {
+	channel, err := s.failover.Deliver(ctx, s.sender, notification)
+	if err != nil {
+		return err
+	}
+	if channel != notification.Channel {
+		s.logger.Info("Notification delivered on fallback channel", "notification_id", notification.ID,
+			"requested_channel", notification.Channel, "delivered_channel", channel)
 	}
 
-	// Mark the notification as sent
 	notification.MarkAsSent()
-	// Note: In a real implementation, we would need an Update method in the repository
-	// For now, we'll just log that the notification was sent
-	s.logger.Debug("Notification marked as sent", "notification_id", notification.ID)
-
-	s.logger.Info("Notification sent successfully", "notification_id", notification.ID, "user_id", req.UserID)
+	notification.DeliveredChannel = channel
+	if channel == models.NotificationChannelInApp && notification.DeliveredAt == nil {
+		notification.DeliveredAt = notification.SentAt
+	}
+	if err := s.notificationRepo.MarkSent(ctx, notification.ID, channel, *notification.SentAt); err != nil {
+		// The notification went out, so only the delivery record is missing
+		s.logger.Error("Failed to record notification delivery", "error", err, "notification_id", notification.ID)
+	}
 	return nil
 }
 
@@ -142,18 +287,7 @@ func (s *notificationService) GetUserNotifications(ctx context.Context, userID s
 	// Convert to response format
 	notificationResponses := make([]*NotificationResponse, len(notifications))
 	for i, notification := range notifications {
-		notificationResponses[i] = &NotificationResponse{
-			ID:      notification.ID,
-			UserID:  notification.UserID,
-			Type:    string(notification.Type),
-			Channel: string(notification.Channel),
-			Title:   notification.Title,
-			Body:    notification.Body,
-			Data:    notification.Data,
-			Read:    notification.Read,
-			ReadAt:  notification.ReadAt,
-			SentAt:  notification.SentAt,
-		}
+		notificationResponses[i] = newNotificationResponse(notification)
 	}
 
 	s.logger.Debug("User notifications retrieved", "user_id", userID, "count", len(notificationResponses))
@@ -166,49 +300,120 @@ func (s *notificationService) GetUserNotifications(ctx context.Context, userID s
 	}, nil
 }
 
-// simulateSendNotification simulates sending notification via different channels
-// In a real implementation. This would integrate with email, SMS, push notification services
-func (s *notificationService) simulateSendNotification(ctx context.Context, notification *models.Notification) error {
-	s.logger.Debug("Simulating notification send", "notification_id", notification.ID, "channel", notification.Channel)
-
-	switch notification.Channel {
-	case models.NotificationChannelEmail:
-		return s.simulateEmailNotification(ctx, notification)
-	case models.NotificationChannelSMS:
-		return s.simulateSMSNotification(ctx, notification)
-	case models.NotificationChannelPush:
-		return s.simulatePushNotification(ctx, notification)
-	case models.NotificationChannelInApp:
-		return s.simulateInAppNotification(ctx, notification)
-	default:
-		return fmt.Errorf("unsupported notification channel: %s", notification.Channel)
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID string) (*NotificationResponse, error) {
+	s.logger.Debug("Marking notification as read", "user_id", userID, "notification_id", notificationID)
+
+	notification, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		s.logger.Error("Failed to get notification", "error", err, "notification_id", notificationID)
+		return nil, apperrors.NewDatabaseError("failed to get notification", err)
+	}
+	// Other users' notifications are reported missing rather than forbidden, so their IDs are not confirmed
+	if notification == nil || notification.UserID != userID {
+		return nil, apperrors.NewNotFoundErrorWithID("Notification", notificationID)
 	}
-}
 
-func (s *notificationService) simulateEmailNotification(ctx context.Context, notification *models.Notification) error {
-	s.logger.Debug("Simulating email notification", "notification_id", notification.ID)
-	// Simulate email sending delay
-	// In reality, this would integrate with email service like SendGrid, AWS SES, etc.
-	return nil
+	if err := s.markRead(ctx, notification, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return newNotificationResponse(notification), nil
 }
 
-func (s *notificationService) simulateSMSNotification(ctx context.Context, notification *models.Notification) error {
-	s.logger.Debug("Simulating SMS notification", "notification_id", notification.ID)
-	// Simulate SMS sending delay
-	// In reality, this would integrate with SMS service like Twilio, AWS SNS, etc.
-	return nil
+// maxReceiptClockSkew is how far in the future a provider's receipt time may be,
+// allowing for its clock running ahead of ours
+const maxReceiptClockSkew = 5 * time.Minute
+
+func (s *notificationService) RecordReceipt(ctx context.Context, notificationID string, req NotificationReceiptRequest) (*NotificationResponse, error) {
+	s.logger.Info("Recording notification receipt", "notification_id", notificationID, "status", req.Status)
+
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(occurredAt.Add(maxReceiptClockSkew)) {
+			return nil, apperrors.NewValidationError("receipt time cannot be in the future")
+		}
+		occurredAt = *req.OccurredAt
+	}
+
+	notification, err := s.notificationRepo.GetByID(ctx, notificationID)
+	if err != nil {
+		s.logger.Error("Failed to get notification", "error", err, "notification_id", notificationID)
+		return nil, apperrors.NewDatabaseError("failed to get notification", err)
+	}
+	if notification == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("Notification", notificationID)
+	}
+	if occurredAt.Before(notification.CreatedAt) {
+		return nil, apperrors.NewValidationError("receipt time cannot be before the notification was created")
+	}
+
+	switch req.Status {
+	case NotificationReceiptDelivered:
+		// Receipts are retried by providers, so repeated ones are accepted and ignored
+		if notification.DeliveredAt != nil {
+			break
+		}
+		delivered, err := s.notificationRepo.MarkDelivered(ctx, notification.ID, occurredAt)
+		if err != nil {
+			s.logger.Error("Failed to record notification delivery", "error", err, "notification_id", notification.ID)
+			return nil, apperrors.NewDatabaseError("failed to record notification delivery", err)
+		}
+		if delivered {
+			notification.DeliveredAt = &occurredAt
+		}
+	case NotificationReceiptRead:
+		if err := s.markRead(ctx, notification, occurredAt); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, apperrors.NewValidationError(fmt.Sprintf("unknown receipt status %q", req.Status))
+	}
+
+	s.logger.Info("Notification receipt recorded", "notification_id", notification.ID, "status", req.Status,
+		"delivered_at", notification.DeliveredAt, "read_at", notification.ReadAt)
+	return newNotificationResponse(notification), nil
 }
 
-func (s *notificationService) simulatePushNotification(ctx context.Context, notification *models.Notification) error {
-	s.logger.Debug("Simulating push notification", "notification_id", notification.ID)
-	// Simulate push notification delay
-	// In reality, this would integrate with push service like Firebase Cloud Messaging, Apple Push Notification service, etc.
+// markRead records that the notification was read at readAt, unless it already was
+func (s *notificationService) markRead(ctx context.Context, notification *models.Notification, readAt time.Time) error {
+	if notification.Read {
+		return nil
+	}
+
+	read, err := s.notificationRepo.MarkRead(ctx, notification.ID, readAt)
+	if err != nil {
+		s.logger.Error("Failed to mark notification as read", "error", err, "notification_id", notification.ID)
+		return apperrors.NewDatabaseError("failed to mark notification as read", err)
+	}
+	if !read {
+		// Read concurrently; the other request recorded when
+		s.logger.Debug("Notification was already read", "notification_id", notification.ID)
+		notification.Read = true
+		return nil
+	}
+
+	notification.Read = true
+	notification.ReadAt = &readAt
+	if notification.DeliveredAt == nil {
+		notification.DeliveredAt = &readAt
+	}
 	return nil
 }
 
-func (s *notificationService) simulateInAppNotification(ctx context.Context, notification *models.Notification) error {
-	s.logger.Debug("Simulating in-app notification", "notification_id", notification.ID)
-	// In-app notifications are just stored in the database and shown in the UI.
-	// No external service integration needed
-	return nil
+// newNotificationResponse converts a notification to its response format
+func newNotificationResponse(notification *models.Notification) *NotificationResponse {
+	return &NotificationResponse{
+		ID:               notification.ID,
+		UserID:           notification.UserID,
+		Type:             string(notification.Type),
+		Channel:          string(notification.Channel),
+		DeliveredChannel: string(notification.DeliveredChannel),
+		Title:            notification.Title,
+		Body:             notification.Body,
+		Data:             notification.Data,
+		Read:             notification.Read,
+		ReadAt:           notification.ReadAt,
+		SentAt:           notification.SentAt,
+		DeliveredAt:      notification.DeliveredAt,
+	}
 }
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// orderConfirmationConsumer names the cursor of order confirmation in the order event outbox
+	orderConfirmationConsumer = "order_confirmation"
+	// orderConfirmationBatchSize bounds how many order events are applied at once
+	orderConfirmationBatchSize = 200
+)
+
+// OrderConfirmationSettings configures the confirmation of paid orders. Paid orders of
+// ManualStores, identified by their order channel or DefaultStore for every store,
+// wait for an admin to confirm them. Order events are read once older than
+// SettleDelay, like the change feeds.
+type OrderConfirmationSettings struct {
+	ManualStores []string
+	SettleDelay  time.Duration
+}
+
+// requiresManualConfirmation reports whether paid orders of the store wait for an admin
+func (s OrderConfirmationSettings) requiresManualConfirmation(store string) bool {
+	for _, manual := range s.ManualStores {
+		if manual == store || manual == DefaultStore {
+			return true
+		}
+	}
+	return false
+}
+
+// orderConfirmationService implements OrderConfirmationService interface
+type orderConfirmationService struct {
+	settings        OrderConfirmationSettings
+	fulfillmentRepo repository.FulfillmentRepository
+	orderEventRepo  repository.OrderEventRepository
+	orderRepo       repository.OrderRepository
+	notifier        OrderStatusNotifier
+	now             func() time.Time
+	logger          *logger.Logger
+}
+
+// NewOrderConfirmationService creates a new order confirmation service. A nil notifier
+// confirms orders without telling the customer.
+func NewOrderConfirmationService(
+	settings OrderConfirmationSettings,
+	fulfillmentRepo repository.FulfillmentRepository,
+	orderEventRepo repository.OrderEventRepository,
+	orderRepo repository.OrderRepository,
+	notifier OrderStatusNotifier,
+	logger *logger.Logger,
+) OrderConfirmationService {
+	return &orderConfirmationService{
+		settings:        settings,
+		fulfillmentRepo: fulfillmentRepo,
+		orderEventRepo:  orderEventRepo,
+		orderRepo:       orderRepo,
+		notifier:        notifier,
+		now:             time.Now,
+		logger:          logger,
+	}
+}
+
+// ProcessOrderEvents reads the order event outbox from where the last run stopped. Orders
+// that became paid are confirmed and queued for fulfillment, unless their store
+// requires manual confirmation, and their customer is notified; shipped and cancelled
+// orders close their fulfillment task. The first run starts from the latest event, so
+// orders paid before are left as they are.
+func (s *orderConfirmationService) ProcessOrderEvents(ctx context.Context) (int, error) {
+	sequence, started, err := s.fulfillmentRepo.GetCursor(ctx, orderConfirmationConsumer)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to get order confirmation cursor", err)
+	}
+	if !started {
+		latest, err := s.orderEventRepo.LatestSequence(ctx)
+		if err != nil {
+			return 0, errors.NewDatabaseError("failed to get latest order event", err)
+		}
+		if _, err := s.fulfillmentRepo.Apply(ctx, orderConfirmationConsumer, latest, repository.FulfillmentBatch{}, s.now()); err != nil {
+			return 0, errors.NewDatabaseError("failed to start order confirmation", err)
+		}
+		s.logger.Info("Order confirmation started", "sequence", latest)
+		return 0, nil
+	}
+
+	confirmedTotal := 0
+	for {
+		events, err := s.orderEventRepo.ListSince(ctx, sequence, s.settings.SettleDelay, orderConfirmationBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to list order events for confirmation", "error", err, "since", sequence)
+			return confirmedTotal, errors.NewDatabaseError("failed to list order events", err)
+		}
+		if len(events) == 0 {
+			return confirmedTotal, nil
+		}
+
+		batch, notify := s.planBatch(events)
+		last := events[len(events)-1].Sequence
+		confirmed, err := s.fulfillmentRepo.Apply(ctx, orderConfirmationConsumer, last, batch, s.now())
+		if err != nil {
+			return confirmedTotal, errors.NewDatabaseError("failed to apply order events to the fulfillment queue", err)
+		}
+		sequence = last
+		confirmedTotal += len(confirmed)
+
+		for _, orderID := range confirmed {
+			s.logger.Info("Paid order confirmed and queued for fulfillment", "order_id", orderID)
+			if notify[orderID] {
+				s.notifyConfirmed(ctx, orderID)
+			}
+		}
+
+		if len(events) < orderConfirmationBatchSize {
+			return confirmedTotal, nil
+		}
+	}
+}
+
+// planBatch works out what a batch of order events does to the fulfillment queue, and
+// which of the orders it confirms to notify the customer of
+func (s *orderConfirmationService) planBatch(events []*models.OrderEvent) (repository.FulfillmentBatch, map[string]bool) {
+	var batch repository.FulfillmentBatch
+	notify := make(map[string]bool)
+	for _, event := range events {
+		var payload models.OrderEventPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			s.logger.Warn("Failed to decode order event", "error", err, "sequence", event.Sequence)
+			continue
+		}
+
+		switch {
+		case becamePaid(event, &payload):
+			switch {
+			case payload.PreviousStatus == models.OrderStatusConfirmed:
+				// The store confirmed the order before it was paid, and the customer was
+				// told then
+				batch.Confirm = append(batch.Confirm, event.OrderID)
+			case s.settings.requiresManualConfirmation(payload.Channel):
+				s.logger.Debug("Paid order awaits manual confirmation", "order_id", event.OrderID, "store", payload.Channel)
+			default:
+				batch.Confirm = append(batch.Confirm, event.OrderID)
+				notify[event.OrderID] = true
+			}
+		case event.Type != models.OrderEventStatusChanged:
+		case payload.Status == models.OrderStatusShipped || payload.Status == models.OrderStatusDelivered:
+			batch.Complete = append(batch.Complete, event.OrderID)
+		case payload.Status == models.OrderStatusCancelled || payload.Status == models.OrderStatusFailed:
+			batch.Cancel = append(batch.Cancel, event.OrderID)
+		}
+	}
+	return batch, notify
+}
+
+// becamePaid reports whether an order event is an order becoming paid, by a payment
+// or placed already paid
+func becamePaid(event *models.OrderEvent, payload *models.OrderEventPayload) bool {
+	if payload.Status != models.OrderStatusPaid {
+		return false
+	}
+	switch event.Type {
+	case models.OrderEventCreated:
+		return true
+	case models.OrderEventStatusChanged:
+		return payload.PreviousStatus != models.OrderStatusPaid
+	}
+	return false
+}
+
+func (s *orderConfirmationService) ConfirmOrder(ctx context.Context, orderID string) (*OrderConfirmationResponse, error) {
+	s.logger.Info("Confirming order", "order_id", orderID)
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order to confirm", "error", err, "order_id", orderID)
+		return nil, errors.NewDatabaseError("failed to get order", err)
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if order.ConfirmedAt != nil {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is already confirmed", orderID))
+	}
+	if !order.IsPaid() {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is %s; only paid orders are confirmed for fulfillment", orderID, order.Status))
+	}
+
+	now := s.now()
+	confirmed, err := s.fulfillmentRepo.Confirm(ctx, orderID, now)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to confirm order", err)
+	}
+	if !confirmed {
+		// Confirmed, shipped or cancelled since it was read
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s changed while it was confirmed", orderID))
+	}
+	order.ConfirmedAt = &now
+
+	s.logger.Info("Order confirmed and queued for fulfillment", "order_id", orderID)
+	notifyOrderConfirmed(ctx, s.notifier, order)
+
+	return &OrderConfirmationResponse{
+		OrderID:     order.ID,
+		Status:      order.Status,
+		ConfirmedAt: now,
+	}, nil
+}
+
+func (s *orderConfirmationService) ListAwaitingConfirmation(ctx context.Context, req ListAwaitingConfirmationRequest) (*ListAwaitingConfirmationResponse, error) {
+	s.logger.Debug("Listing orders awaiting confirmation", "page", req.Page, "limit", req.Limit)
+
+	page, limit := normalizeConfirmationPage(req.Page, req.Limit)
+	orders, err := s.fulfillmentRepo.ListAwaitingConfirmation(ctx, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list orders awaiting confirmation", err)
+	}
+	total, err := s.fulfillmentRepo.CountAwaitingConfirmation(ctx)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count orders awaiting confirmation", err)
+	}
+
+	return &ListAwaitingConfirmationResponse{
+		Orders: orders,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+func (s *orderConfirmationService) ListFulfillmentQueue(ctx context.Context, req ListFulfillmentQueueRequest) (*ListFulfillmentQueueResponse, error) {
+	s.logger.Debug("Listing fulfillment queue", "status", req.Status, "page", req.Page, "limit", req.Limit)
+
+	status := models.FulfillmentTaskStatusQueued
+	if req.Status != "" {
+		status = models.FulfillmentTaskStatus(req.Status)
+	}
+	if !status.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("unsupported fulfillment task status %s", req.Status))
+	}
+
+	page, limit := normalizeConfirmationPage(req.Page, req.Limit)
+	tasks, err := s.fulfillmentRepo.ListTasks(ctx, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list fulfillment tasks", err)
+	}
+	total, err := s.fulfillmentRepo.CountTasks(ctx, status)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count fulfillment tasks", err)
+	}
+
+	return &ListFulfillmentQueueResponse{
+		Status: status,
+		Tasks:  tasks,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+// notifyConfirmed tells the customer of an order that it was confirmed. The order is
+// confirmed either way, so a failure to load it is only logged.
+func (s *orderConfirmationService) notifyConfirmed(ctx context.Context, orderID string) {
+	if s.notifier == nil {
+		return
+	}
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || order == nil {
+		s.logger.Warn("Failed to get confirmed order to notify", "error", err, "order_id", orderID)
+		return
+	}
+	notifyOrderConfirmed(ctx, s.notifier, order)
+}
+
+// normalizeConfirmationPage applies the default page and limit of the confirmation listings
+func normalizeConfirmationPage(page, limit int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	if page < 1 {
+		page = 1
+	}
+	return page, limit
+}
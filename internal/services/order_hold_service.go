@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// OrderHoldSettings sets how long an order may stay on hold before the hold is
+// overdue
+type OrderHoldSettings struct {
+	SLA time.Duration
+}
+
+// orderHoldService implements OrderHoldService interface
+type orderHoldService struct {
+	settings  OrderHoldSettings
+	holdRepo  repository.OrderHoldRepository
+	orderRepo repository.OrderRepository
+	userRepo  repository.UserRepository
+	now       func() time.Time
+	logger    *logger.Logger
+}
+
+// NewOrderHoldService creates a new order hold service
+func NewOrderHoldService(
+	settings OrderHoldSettings,
+	holdRepo repository.OrderHoldRepository,
+	orderRepo repository.OrderRepository,
+	userRepo repository.UserRepository,
+	logger *logger.Logger,
+) OrderHoldService {
+	return &orderHoldService{
+		settings:  settings,
+		holdRepo:  holdRepo,
+		orderRepo: orderRepo,
+		userRepo:  userRepo,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// PlaceHold puts an order that has not shipped on hold, which keeps it from shipping
+// until the hold is released. The hold is due for release within the hold SLA.
+func (s *orderHoldService) PlaceHold(ctx context.Context, orderID, userID string, req PlaceOrderHoldRequest) (*OrderHoldResponse, error) {
+	s.logger.Info("Placing order hold", "order_id", orderID, "user_id", userID, "reason", req.Reason)
+
+	if orderID == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+	if !req.Reason.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid hold reason %s", req.Reason))
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for hold", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if order.OnHold {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is already on hold", orderID))
+	}
+	if !order.IsHoldable() {
+		return nil, errors.NewConflictError(fmt.Sprintf("order in status %s cannot be held", order.Status))
+	}
+
+	now := s.now()
+	hold := &models.OrderHold{
+		OrderID:  orderID,
+		Reason:   req.Reason,
+		Note:     strings.TrimSpace(req.Note),
+		PlacedBy: userID,
+		DueAt:    now.Add(s.settings.SLA),
+	}
+	if req.AssignedTo != "" {
+		if err := s.checkReviewer(ctx, req.AssignedTo); err != nil {
+			return nil, err
+		}
+		hold.AssignedTo = &req.AssignedTo
+		hold.AssignedAt = &now
+	}
+
+	// Only one hold is placed however many admins hold the order at once
+	placed, err := s.holdRepo.Place(ctx, hold)
+	if err != nil {
+		s.logger.Error("Failed to place order hold", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if !placed {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is already on hold or can no longer be held", orderID))
+	}
+
+	s.logger.Info("Order hold placed", "hold_id", hold.ID, "order_id", orderID, "reason", req.Reason, "due_at", hold.DueAt)
+	return s.toResponse(hold), nil
+}
+
+func (s *orderHoldService) AssignHold(ctx context.Context, holdID string, req AssignOrderHoldRequest) (*OrderHoldResponse, error) {
+	s.logger.Info("Assigning order hold", "hold_id", holdID, "assigned_to", req.AssignedTo)
+
+	hold, err := s.getActiveHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReviewer(ctx, req.AssignedTo); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	assigned, err := s.holdRepo.Assign(ctx, holdID, req.AssignedTo, now)
+	if err != nil {
+		s.logger.Error("Failed to assign order hold", "error", err, "hold_id", holdID)
+		return nil, err
+	}
+	if !assigned {
+		return nil, errors.NewConflictError(fmt.Sprintf("order hold %s is already released", holdID))
+	}
+
+	hold.AssignedTo = &req.AssignedTo
+	hold.AssignedAt = &now
+	return s.toResponse(hold), nil
+}
+
+// ReleaseHold releases an active hold, after which the order can ship again
+func (s *orderHoldService) ReleaseHold(ctx context.Context, holdID, userID string, req ReleaseOrderHoldRequest) (*OrderHoldResponse, error) {
+	s.logger.Info("Releasing order hold", "hold_id", holdID, "user_id", userID)
+
+	hold, err := s.getActiveHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	note := strings.TrimSpace(req.Note)
+	released, err := s.holdRepo.Release(ctx, holdID, userID, note, now)
+	if err != nil {
+		s.logger.Error("Failed to release order hold", "error", err, "hold_id", holdID)
+		return nil, err
+	}
+	if !released {
+		return nil, errors.NewConflictError(fmt.Sprintf("order hold %s is already released", holdID))
+	}
+
+	hold.ReleasedBy = &userID
+	hold.ReleasedAt = &now
+	hold.ReleaseNote = note
+
+	s.logger.Info("Order hold released", "hold_id", holdID, "order_id", hold.OrderID, "reason", hold.Reason,
+		"held_for", now.Sub(hold.CreatedAt), "within_sla", !now.After(hold.DueAt))
+	return s.toResponse(hold), nil
+}
+
+func (s *orderHoldService) ListHolds(ctx context.Context, req ListOrderHoldsRequest) (*ListOrderHoldsResponse, error) {
+	s.logger.Debug("Listing order holds", "page", req.Page, "limit", req.Limit, "status", req.Status, "reason", req.Reason)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	filter := repository.OrderHoldFilter{
+		Reason:     models.OrderHoldReason(req.Reason),
+		AssignedTo: req.AssignedTo,
+	}
+	switch req.Status {
+	case "active":
+		active := true
+		filter.Active = &active
+	case "released":
+		active := false
+		filter.Active = &active
+	}
+	if req.Overdue {
+		now := s.now()
+		filter.OverdueAt = &now
+	}
+
+	holds, err := s.holdRepo.List(ctx, filter, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list order holds", "error", err)
+		return nil, err
+	}
+
+	total, err := s.holdRepo.Count(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count order holds", "error", err)
+		return nil, err
+	}
+
+	response := &ListOrderHoldsResponse{
+		Holds: make([]*OrderHoldResponse, len(holds)),
+		Page:  page,
+		Limit: limit,
+		Total: int(total),
+	}
+	for i, hold := range holds {
+		response.Holds[i] = s.toResponse(hold)
+	}
+
+	return response, nil
+}
+
+func (s *orderHoldService) GetOrderHolds(ctx context.Context, orderID string) ([]*OrderHoldResponse, error) {
+	s.logger.Debug("Getting order holds", "order_id", orderID)
+
+	if orderID == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for holds", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+
+	holds, err := s.holdRepo.ListByOrderID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to list order holds", "error", err, "order_id", orderID)
+		return nil, err
+	}
+
+	responses := make([]*OrderHoldResponse, len(holds))
+	for i, hold := range holds {
+		responses[i] = s.toResponse(hold)
+	}
+	return responses, nil
+}
+
+// GetHoldMetrics reports how many of the orders placed in a date range were held, and
+// per reason how long holds placed in the range lasted and whether they met the SLA
+func (s *orderHoldService) GetHoldMetrics(ctx context.Context, req OrderHoldMetricsRequest) (*OrderHoldMetricsResponse, error) {
+	s.logger.Info("Getting order hold metrics", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	orders, held, err := s.holdRepo.CountHeldOrders(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to count held orders", "error", err)
+		return nil, err
+	}
+
+	summaries, err := s.holdRepo.SummarizeByReason(ctx, startDate, end, s.now())
+	if err != nil {
+		s.logger.Error("Failed to summarize order holds", "error", err)
+		return nil, err
+	}
+
+	response := &OrderHoldMetricsResponse{
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		SLAHours:   roundCents(s.settings.SLA.Hours()),
+		Orders:     orders,
+		HeldOrders: held,
+		HoldRate:   percentage(int(held), int(orders)),
+		Reasons:    make([]OrderHoldReasonStats, len(summaries)),
+	}
+
+	var totals repository.OrderHoldSummary
+	for i, summary := range summaries {
+		response.Reasons[i] = OrderHoldReasonStats{
+			Reason:         summary.Reason,
+			OrderHoldStats: newOrderHoldStats(summary),
+		}
+		totals.Placed += summary.Placed
+		totals.Released += summary.Released
+		totals.Active += summary.Active
+		totals.Overdue += summary.Overdue
+		totals.WithinSLA += summary.WithinSLA
+		totals.HoldSeconds += summary.HoldSeconds
+	}
+	response.Totals = newOrderHoldStats(totals)
+
+	return response, nil
+}
+
+// getActiveHold returns a hold that has not been released
+func (s *orderHoldService) getActiveHold(ctx context.Context, holdID string) (*models.OrderHold, error) {
+	if holdID == "" {
+		return nil, errors.NewValidationError("hold ID is required")
+	}
+
+	hold, err := s.holdRepo.GetByID(ctx, holdID)
+	if err != nil {
+		s.logger.Error("Failed to get order hold", "error", err, "hold_id", holdID)
+		return nil, err
+	}
+	if hold == nil {
+		return nil, errors.NewNotFoundErrorWithID("order hold", holdID)
+	}
+	if !hold.IsActive() {
+		return nil, errors.NewConflictError(fmt.Sprintf("order hold %s is already released", holdID))
+	}
+
+	return hold, nil
+}
+
+// checkReviewer checks that holds can be assigned to the user: an active admin
+func (s *orderHoldService) checkReviewer(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get hold reviewer", "error", err, "user_id", userID)
+		return err
+	}
+	if user == nil || !user.IsActive || !user.IsAdmin() {
+		return errors.NewValidationError(fmt.Sprintf("user %s cannot review order holds", userID))
+	}
+	return nil
+}
+
+// toResponse converts an order hold to its response format
+func (s *orderHoldService) toResponse(hold *models.OrderHold) *OrderHoldResponse {
+	return &OrderHoldResponse{
+		ID:          hold.ID,
+		OrderID:     hold.OrderID,
+		Reason:      hold.Reason,
+		Note:        hold.Note,
+		PlacedBy:    hold.PlacedBy,
+		AssignedTo:  hold.AssignedTo,
+		AssignedAt:  hold.AssignedAt,
+		DueAt:       hold.DueAt,
+		Overdue:     hold.IsOverdue(s.now()),
+		ReleasedBy:  hold.ReleasedBy,
+		ReleasedAt:  hold.ReleasedAt,
+		ReleaseNote: hold.ReleaseNote,
+		CreatedAt:   hold.CreatedAt,
+	}
+}
+
+// newOrderHoldStats derives the averages and SLA compliance of summed holds
+func newOrderHoldStats(summary repository.OrderHoldSummary) OrderHoldStats {
+	stats := OrderHoldStats{
+		Placed:        summary.Placed,
+		Released:      summary.Released,
+		Active:        summary.Active,
+		Overdue:       summary.Overdue,
+		ReleasedInSLA: summary.WithinSLA,
+		SLACompliance: percentage(int(summary.WithinSLA), int(summary.Released)),
+	}
+	if summary.Released > 0 {
+		stats.AverageHoldHours = roundCents(summary.HoldSeconds / 3600 / float64(summary.Released))
+	}
+	return stats
+}
@@ -0,0 +1,537 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// orderImportMaxRows caps the size of a single order import
+	orderImportMaxRows = 10000
+	// orderImportSKUBatchSize is the number of SKUs resolved per product query
+	orderImportSKUBatchSize = 500
+)
+
+// Order import result statuses
+const (
+	OrderImportStatusImported = "imported"
+	OrderImportStatusSkipped  = "skipped"
+	OrderImportStatusFailed   = "failed"
+)
+
+// orderImportRequiredColumns must be present in the header row
+var orderImportRequiredColumns = []string{"order_ref", "customer_email", "status", "ordered_at", "sku", "quantity", "unit_price"}
+
+// orderImportTimeLayouts are the accepted ordered_at formats
+var orderImportTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// importedOrder collects the CSV rows of one historical order
+type importedOrder struct {
+	result        *OrderImportResult
+	customerEmail string
+	customerName  string
+	status        models.OrderStatus
+	orderedAt     time.Time
+	currency      string
+	total         *float64
+	items         []importedOrderItem
+	payment       *importedPayment
+	err           string
+}
+
+// importedOrderItem is one item row of an imported order
+type importedOrderItem struct {
+	sku       string
+	quantity  int
+	unitPrice float64
+}
+
+// importedPayment is the payment of an imported order
+type importedPayment struct {
+	method    models.PaymentMethod
+	status    models.PaymentStatus
+	amount    *float64
+	reference string
+}
+
+func (s *orderService) ImportOrders(ctx context.Context, r io.Reader) (*OrderImportResponse, error) {
+	s.logger.Info("Importing historical orders")
+
+	orders, totalRows, err := parseOrderImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &OrderImportResponse{
+		TotalRows:   totalRows,
+		TotalOrders: len(orders),
+		Orders:      make([]OrderImportResult, 0, len(orders)),
+	}
+
+	products, err := s.resolveImportProducts(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make(map[string]*models.User)
+	for _, order := range orders {
+		if order.err == "" {
+			s.importOrder(ctx, order, products, customers, response)
+		}
+		if order.err != "" {
+			order.result.Status = OrderImportStatusFailed
+			order.result.Error = order.err
+		}
+
+		switch order.result.Status {
+		case OrderImportStatusImported:
+			response.Imported++
+		case OrderImportStatusSkipped:
+			response.Skipped++
+		case OrderImportStatusFailed:
+			response.Failed++
+		}
+		response.Orders = append(response.Orders, *order.result)
+	}
+
+	s.logger.Info("Historical orders imported",
+		"total_rows", response.TotalRows,
+		"total_orders", response.TotalOrders,
+		"imported", response.Imported,
+		"skipped", response.Skipped,
+		"failed", response.Failed,
+		"customers_created", response.CustomersCreated)
+
+	return response, nil
+}
+
+// resolveImportProducts loads the products referenced by orders that parsed cleanly, keyed by SKU
+func (s *orderService) resolveImportProducts(ctx context.Context, orders []*importedOrder) (map[string]*models.Product, error) {
+	var skus []string
+	seen := make(map[string]bool)
+	for _, order := range orders {
+		if order.err != "" {
+			continue
+		}
+		for _, item := range order.items {
+			if !seen[item.sku] {
+				seen[item.sku] = true
+				skus = append(skus, item.sku)
+			}
+		}
+	}
+
+	products := make(map[string]*models.Product, len(skus))
+	for start := 0; start < len(skus); start += orderImportSKUBatchSize {
+		end := start + orderImportSKUBatchSize
+		if end > len(skus) {
+			end = len(skus)
+		}
+
+		batch, err := s.productRepo.GetBySKUs(ctx, skus[start:end])
+		if err != nil {
+			s.logger.Error("Failed to load products for order import", "error", err, "batch_size", end-start)
+			return nil, err
+		}
+		for _, product := range batch {
+			products[product.SKU] = product
+		}
+	}
+	return products, nil
+}
+
+// importOrder writes one historical order with its items and payment. Stock is not
+// reserved or deducted, since the order was fulfilled on the previous platform.
+func (s *orderService) importOrder(
+	ctx context.Context,
+	imported *importedOrder,
+	products map[string]*models.Product,
+	customers map[string]*models.User,
+	response *OrderImportResponse,
+) {
+	ref := imported.result.OrderRef
+
+	existing, err := s.orderRepo.GetByExternalID(ctx, models.OrderChannelMigration, ref)
+	if err != nil {
+		s.logger.Error("Failed to check for imported order", "error", err, "order_ref", ref)
+		imported.err = "lookup failed: " + err.Error()
+		return
+	}
+	if existing != nil {
+		// Already migrated by an earlier import
+		imported.result.Status = OrderImportStatusSkipped
+		imported.result.OrderID = existing.ID
+		return
+	}
+
+	orderItems := make([]*models.OrderItem, len(imported.items))
+	var subtotal float64
+	for i, item := range imported.items {
+		product, ok := products[item.sku]
+		if !ok {
+			imported.err = fmt.Sprintf("product with SKU %s not found", item.sku)
+			return
+		}
+
+		totalPrice := item.unitPrice * float64(item.quantity)
+		subtotal += totalPrice
+		orderItems[i] = &models.OrderItem{
+			ProductID:  product.ID,
+			Quantity:   item.quantity,
+			UnitPrice:  item.unitPrice,
+			TotalPrice: totalPrice,
+			ListPrice:  item.unitPrice, // Channels report the price they charged
+			CreatedAt:  imported.orderedAt,
+			UpdatedAt:  imported.orderedAt,
+		}
+	}
+
+	customer, created, err := s.importCustomer(ctx, imported, customers)
+	if err != nil {
+		imported.err = err.Error()
+		return
+	}
+
+	total := roundCents(subtotal)
+	if imported.total != nil {
+		total = *imported.total
+	}
+
+	order := &models.Order{
+		UserID:          customer.ID,
+		Status:          imported.status,
+		TotalAmount:     total,
+		Currency:        imported.currency,
+		Channel:         models.OrderChannelMigration,
+		ExternalOrderID: ref,
+		CreatedAt:       imported.orderedAt,
+		UpdatedAt:       imported.orderedAt,
+	}
+
+	var payment *models.Payment
+	if imported.payment != nil {
+		order.PaymentMethod = imported.payment.method
+		payment = newImportedPayment(imported, total)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if created {
+			if err := tx.Create(customer).Error; err != nil {
+				return fmt.Errorf("failed to create customer %s: %w", customer.Email, err)
+			}
+			order.UserID = customer.ID
+		}
+
+		if err := tx.Create(order).Error; err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
+		}
+		if err := repository.AppendOrderEvent(tx, order, models.OrderEventCreated, ""); err != nil {
+			return fmt.Errorf("failed to record order event: %w", err)
+		}
+
+		for _, item := range orderItems {
+			item.OrderID = order.ID
+		}
+		if err := tx.Create(&orderItems).Error; err != nil {
+			return fmt.Errorf("failed to create order items: %w", err)
+		}
+
+		if payment != nil {
+			payment.OrderID = order.ID
+			if payment.TransactionID == "" {
+				payment.TransactionID = "migrated-" + order.ID
+			}
+			if err := tx.Create(payment).Error; err != nil {
+				return fmt.Errorf("failed to create payment: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to import order", "error", err, "order_ref", ref)
+		imported.err = err.Error()
+		return
+	}
+
+	if created {
+		customers[customer.Email] = customer
+		response.CustomersCreated++
+	}
+	imported.result.Status = OrderImportStatusImported
+	imported.result.OrderID = order.ID
+}
+
+// importCustomer finds the order's customer by email, or prepares a new customer account.
+// New accounts get a random password, so customers must reset it before signing in.
+func (s *orderService) importCustomer(ctx context.Context, imported *importedOrder, customers map[string]*models.User) (*models.User, bool, error) {
+	if customer, ok := customers[imported.customerEmail]; ok {
+		return customer, false, nil
+	}
+
+	customer, err := s.userRepo.GetByEmail(ctx, imported.customerEmail)
+	if err != nil {
+		s.logger.Error("Failed to get customer for order import", "error", err, "email", imported.customerEmail)
+		return nil, false, fmt.Errorf("customer lookup failed: %w", err)
+	}
+	if customer != nil {
+		customers[imported.customerEmail] = customer
+		return customer, false, nil
+	}
+
+	password, err := bcrypt.GenerateFromPassword([]byte(uuid.New().String()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create customer password: %w", err)
+	}
+
+	name := imported.customerName
+	if name == "" {
+		name, _, _ = strings.Cut(imported.customerEmail, "@")
+	}
+
+	return &models.User{
+		Email:    imported.customerEmail,
+		Name:     name,
+		Password: string(password),
+		Role:     models.UserRoleCustomer,
+		IsActive: true,
+	}, true, nil
+}
+
+// newImportedPayment builds the payment record of an imported order
+func newImportedPayment(imported *importedOrder, orderTotal float64) *models.Payment {
+	amount := orderTotal
+	if imported.payment.amount != nil {
+		amount = *imported.payment.amount
+	}
+
+	payment := &models.Payment{
+		Amount:         amount,
+		Currency:       imported.currency,
+		Status:         imported.payment.status,
+		Method:         imported.payment.method,
+		TransactionID:  imported.payment.reference,
+		IdempotencyKey: "migration:" + imported.result.OrderRef,
+		Gateway:        models.OrderChannelMigration,
+		CreatedAt:      imported.orderedAt,
+		UpdatedAt:      imported.orderedAt,
+	}
+	if payment.Status != models.PaymentStatusPending && payment.Status != models.PaymentStatusFailed {
+		processedAt := imported.orderedAt
+		payment.ProcessedAt = &processedAt
+	}
+	return payment
+}
+
+// parseOrderImportCSV reads one row per order item, grouped into orders by order_ref.
+// A header row naming the columns is required; optional columns are customer_name,
+// currency, order_total, payment_method, payment_status, payment_amount and
+// payment_reference. Orders with an invalid row are returned already marked as failed.
+func parseOrderImportCSV(r io.Reader) ([]*importedOrder, int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, 0, apperrors.NewValidationError("order import is empty")
+	}
+	if err != nil {
+		return nil, 0, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range orderImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, 0, apperrors.NewValidationError(fmt.Sprintf("order import is missing the %s column", name))
+		}
+	}
+
+	var orders []*importedOrder
+	byRef := make(map[string]*importedOrder)
+	row := 1
+	totalRows := 0
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, 0, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+		}
+		row++
+		totalRows++
+
+		if totalRows > orderImportMaxRows {
+			return nil, 0, apperrors.NewValidationError(fmt.Sprintf("order import cannot exceed %d rows", orderImportMaxRows))
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		ref := field("order_ref")
+		order, ok := byRef[ref]
+		if !ok || ref == "" {
+			order = &importedOrder{result: &OrderImportResult{OrderRef: ref}}
+			orders = append(orders, order)
+			if ref != "" {
+				byRef[ref] = order
+			}
+		}
+		order.result.Rows = append(order.result.Rows, row)
+
+		if order.err != "" {
+			continue
+		}
+		if ref == "" {
+			order.err = fmt.Sprintf("row %d: order_ref is required", row)
+			continue
+		}
+		if rowErr := order.addRow(field); rowErr != "" {
+			order.err = fmt.Sprintf("row %d: %s", row, rowErr)
+		}
+	}
+
+	if totalRows == 0 {
+		return nil, 0, apperrors.NewValidationError("order import is empty")
+	}
+
+	return orders, totalRows, nil
+}
+
+// addRow adds one CSV row to the order. The first row sets the order fields; later
+// rows must agree with them. It returns a description of the first invalid value.
+func (o *importedOrder) addRow(field func(string) string) string {
+	email := strings.ToLower(field("customer_email"))
+	status := models.OrderStatus(strings.ToLower(field("status")))
+
+	if len(o.items) == 0 {
+		if email == "" {
+			return "customer_email is required"
+		}
+		if !isImportableOrderStatus(status) {
+			return fmt.Sprintf("invalid status %q", field("status"))
+		}
+
+		orderedAt, ok := parseImportTime(field("ordered_at"))
+		if !ok {
+			return "ordered_at must be RFC 3339, YYYY-MM-DD HH:MM:SS or YYYY-MM-DD"
+		}
+
+		o.customerEmail = email
+		o.customerName = field("customer_name")
+		o.status = status
+		o.orderedAt = orderedAt
+		o.currency = strings.ToUpper(field("currency"))
+		if o.currency == "" {
+			o.currency = "USD"
+		}
+
+		if value := field("order_total"); value != "" {
+			total, err := strconv.ParseFloat(value, 64)
+			if err != nil || total < 0 {
+				return "order_total must be a non-negative number"
+			}
+			o.total = &total
+		}
+
+		if rowErr := o.parsePayment(field); rowErr != "" {
+			return rowErr
+		}
+	} else if email != o.customerEmail || status != o.status {
+		return "customer_email and status must match the order's first row"
+	}
+
+	sku := field("sku")
+	if sku == "" {
+		return "sku is required"
+	}
+	quantity, err := strconv.Atoi(field("quantity"))
+	if err != nil || quantity <= 0 {
+		return "quantity must be a positive integer"
+	}
+	unitPrice, err := strconv.ParseFloat(field("unit_price"), 64)
+	if err != nil || unitPrice < 0 {
+		return "unit_price must be a non-negative number"
+	}
+
+	o.items = append(o.items, importedOrderItem{sku: sku, quantity: quantity, unitPrice: unitPrice})
+	return ""
+}
+
+// parsePayment reads the optional payment columns of the order's first row
+func (o *importedOrder) parsePayment(field func(string) string) string {
+	method := models.PaymentMethod(strings.ToLower(field("payment_method")))
+	if method == "" {
+		return ""
+	}
+	if !method.IsValid() {
+		return fmt.Sprintf("invalid payment_method %q", field("payment_method"))
+	}
+
+	payment := &importedPayment{
+		method:    method,
+		status:    models.PaymentStatus(strings.ToLower(field("payment_status"))),
+		reference: field("payment_reference"),
+	}
+	switch payment.status {
+	case "":
+		payment.status = models.PaymentStatusCompleted
+	case models.PaymentStatusPending, models.PaymentStatusProcessed, models.PaymentStatusCompleted,
+		models.PaymentStatusFailed, models.PaymentStatusRefunded, models.PaymentStatusCancelled:
+	default:
+		return fmt.Sprintf("invalid payment_status %q", field("payment_status"))
+	}
+
+	if value := field("payment_amount"); value != "" {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount <= 0 {
+			return "payment_amount must be a positive number"
+		}
+		payment.amount = &amount
+	}
+
+	o.payment = payment
+	return ""
+}
+
+// isImportableOrderStatus reports whether status is a known order status
+func isImportableOrderStatus(status models.OrderStatus) bool {
+	switch status {
+	case models.OrderStatusPending, models.OrderStatusConfirmed, models.OrderStatusPaid,
+		models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusCancelled, models.OrderStatusFailed:
+		return true
+	}
+	return false
+}
+
+// parseImportTime parses ordered_at in any of the accepted layouts
+func parseImportTime(value string) (time.Time, bool) {
+	for _, layout := range orderImportTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// orderRateWindow is the window the per-user order rate limit counts orders in
+const orderRateWindow = time.Hour
+
+// OrderLimitSettings bounds the orders users may place: how many different products
+// an order may have, how many units of each product, and how many orders a user may
+// place per hour, in any status. A zero limit is no limit.
+type OrderLimitSettings struct {
+	MaxItemsPerOrder  int
+	MaxQuantityPerSKU int
+	MaxOrdersPerHour  int
+}
+
+// OrderLimiter enforces the order limits, which admins may change at runtime. Changed
+// limits last until the next restart, when the configured limits apply again. A nil
+// limiter enforces nothing.
+type OrderLimiter struct {
+	orderRepo repository.OrderRepository
+	logger    *logger.Logger
+
+	mutex    sync.RWMutex
+	settings OrderLimitSettings
+	rejected OrderLimitRejections
+}
+
+// NewOrderLimiter creates an order limiter with the configured limits
+func NewOrderLimiter(settings OrderLimitSettings, orderRepo repository.OrderRepository, logger *logger.Logger) *OrderLimiter {
+	return &OrderLimiter{
+		orderRepo: orderRepo,
+		logger:    logger,
+		settings:  settings,
+	}
+}
+
+// Check refuses an order of items that exceeds the size limits, or that would take the
+// user past the hourly order limit. Repeated lines of a product count together.
+func (l *OrderLimiter) Check(ctx context.Context, userID string, items []OrderItem) error {
+	if l == nil {
+		return nil
+	}
+	settings := l.Settings()
+
+	quantities := orderItemQuantities(items)
+	if settings.MaxItemsPerOrder > 0 && len(quantities) > settings.MaxItemsPerOrder {
+		l.reject(func(r *OrderLimitRejections) { r.Items++ })
+		l.logger.Warn("Order refused for its number of products", "user_id", userID,
+			"items", len(quantities), "limit", settings.MaxItemsPerOrder)
+		return errors.NewOrderItemLimitError(len(quantities), settings.MaxItemsPerOrder)
+	}
+
+	if settings.MaxQuantityPerSKU > 0 {
+		// Report the first offending product in request order, so the error is stable
+		for _, item := range items {
+			if quantity := quantities[item.ProductID]; quantity > settings.MaxQuantityPerSKU {
+				l.reject(func(r *OrderLimitRejections) { r.Quantity++ })
+				l.logger.Warn("Order refused for the quantity of a product", "user_id", userID,
+					"product_id", item.ProductID, "quantity", quantity, "limit", settings.MaxQuantityPerSKU)
+				return errors.NewOrderQuantityLimitError(item.ProductID, quantity, settings.MaxQuantityPerSKU)
+			}
+		}
+	}
+
+	if settings.MaxOrdersPerHour > 0 {
+		placed, err := l.orderRepo.CountByUserSince(ctx, userID, time.Now().Add(-orderRateWindow))
+		if err != nil {
+			l.logger.Error("Failed to count recent orders for the order rate limit", "error", err, "user_id", userID)
+			return errors.NewDatabaseError("failed to check the order rate limit", err)
+		}
+		// Concurrent orders can each see the count below the limit; the limit is a
+		// brake on bots, not an exact quota
+		if placed >= int64(settings.MaxOrdersPerHour) {
+			l.reject(func(r *OrderLimitRejections) { r.Rate++ })
+			l.logger.Warn("Order refused by the order rate limit", "user_id", userID,
+				"placed", placed, "limit", settings.MaxOrdersPerHour)
+			return errors.NewOrderRateLimitError(settings.MaxOrdersPerHour, "hour")
+		}
+	}
+
+	return nil
+}
+
+// Settings returns the limits in force
+func (l *OrderLimiter) Settings() OrderLimitSettings {
+	if l == nil {
+		return OrderLimitSettings{}
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.settings
+}
+
+// Update replaces the limits in force; fields left out of the request keep their value
+func (l *OrderLimiter) Update(req UpdateOrderLimitsRequest) (*OrderLimitsResponse, error) {
+	if l == nil {
+		return nil, errors.NewBusinessError("order limits are not enabled")
+	}
+	for _, limit := range []*int{req.MaxItemsPerOrder, req.MaxQuantityPerSKU, req.MaxOrdersPerHour} {
+		if limit != nil && *limit < 0 {
+			return nil, errors.NewValidationError("order limits cannot be negative")
+		}
+	}
+
+	l.mutex.Lock()
+	if req.MaxItemsPerOrder != nil {
+		l.settings.MaxItemsPerOrder = *req.MaxItemsPerOrder
+	}
+	if req.MaxQuantityPerSKU != nil {
+		l.settings.MaxQuantityPerSKU = *req.MaxQuantityPerSKU
+	}
+	if req.MaxOrdersPerHour != nil {
+		l.settings.MaxOrdersPerHour = *req.MaxOrdersPerHour
+	}
+	settings := l.settings
+	l.mutex.Unlock()
+
+	l.logger.Info("Order limits updated", "max_items_per_order", settings.MaxItemsPerOrder,
+		"max_quantity_per_sku", settings.MaxQuantityPerSKU, "max_orders_per_hour", settings.MaxOrdersPerHour)
+	return l.Limits(), nil
+}
+
+// Limits returns the limits in force and how many orders each has refused since startup
+func (l *OrderLimiter) Limits() *OrderLimitsResponse {
+	if l == nil {
+		return &OrderLimitsResponse{}
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return &OrderLimitsResponse{
+		MaxItemsPerOrder:  l.settings.MaxItemsPerOrder,
+		MaxQuantityPerSKU: l.settings.MaxQuantityPerSKU,
+		MaxOrdersPerHour:  l.settings.MaxOrdersPerHour,
+		Rejected:          l.rejected,
+	}
+}
+
+// reject counts an order refused by a limit
+func (l *OrderLimiter) reject(count func(r *OrderLimitRejections)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	count(&l.rejected)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// orderNotificationConsumer names the cursor of customer notifications in the order
+	// event outbox
+	orderNotificationConsumer = "order_notifications"
+	// orderNotificationBatchSize bounds how many order events are notified at once
+	orderNotificationBatchSize = 200
+)
+
+// OrderNotificationRelaySettings configures the relay of order status changes to
+// customers. Order events are read once older than SettleDelay, like the change feeds.
+type OrderNotificationRelaySettings struct {
+	SettleDelay time.Duration
+}
+
+// orderNotificationRelay implements OrderNotificationRelay interface
+type orderNotificationRelay struct {
+	settings       OrderNotificationRelaySettings
+	orderEventRepo repository.OrderEventRepository
+	orderRepo      repository.OrderRepository
+	notifier       OrderStatusNotifier
+	logger         *logger.Logger
+}
+
+// NewOrderNotificationRelay creates a relay that notifies customers of the status
+// changes recorded in the order event outbox
+func NewOrderNotificationRelay(
+	settings OrderNotificationRelaySettings,
+	orderEventRepo repository.OrderEventRepository,
+	orderRepo repository.OrderRepository,
+	notifier OrderStatusNotifier,
+	logger *logger.Logger,
+) OrderNotificationRelay {
+	return &orderNotificationRelay{
+		settings:       settings,
+		orderEventRepo: orderEventRepo,
+		orderRepo:      orderRepo,
+		notifier:       notifier,
+		logger:         logger,
+	}
+}
+
+// RelayOrderEvents reads the order event outbox from where the last run stopped and
+// notifies the customer of each status change. The cursor is moved after a batch is
+// sent, so a run interrupted mid-batch sends its notifications again rather than
+// losing them. The first run starts from the latest event.
+func (r *orderNotificationRelay) RelayOrderEvents(ctx context.Context) (int, error) {
+	sequence, started, err := r.orderEventRepo.GetCursor(ctx, orderNotificationConsumer)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to get order notification cursor", err)
+	}
+	if !started {
+		latest, err := r.orderEventRepo.LatestSequence(ctx)
+		if err != nil {
+			return 0, errors.NewDatabaseError("failed to get latest order event", err)
+		}
+		if err := r.orderEventRepo.SaveCursor(ctx, orderNotificationConsumer, latest); err != nil {
+			return 0, errors.NewDatabaseError("failed to start order notifications", err)
+		}
+		r.logger.Info("Order notifications started", "sequence", latest)
+		return 0, nil
+	}
+
+	notified := 0
+	for {
+		events, err := r.orderEventRepo.ListSince(ctx, sequence, r.settings.SettleDelay, orderNotificationBatchSize)
+		if err != nil {
+			r.logger.Error("Failed to list order events for notifications", "error", err, "since", sequence)
+			return notified, errors.NewDatabaseError("failed to list order events", err)
+		}
+		if len(events) == 0 {
+			return notified, nil
+		}
+
+		for _, event := range events {
+			if r.notify(ctx, event) {
+				notified++
+			}
+		}
+
+		last := events[len(events)-1].Sequence
+		if err := r.orderEventRepo.SaveCursor(ctx, orderNotificationConsumer, last); err != nil {
+			return notified, errors.NewDatabaseError("failed to save order notification cursor", err)
+		}
+		sequence = last
+
+		if len(events) < orderNotificationBatchSize {
+			return notified, nil
+		}
+	}
+}
+
+// notify tells the customer of the status change an order event records, and reports
+// whether the event was one
+func (r *orderNotificationRelay) notify(ctx context.Context, event *models.OrderEvent) bool {
+	if event.Type != models.OrderEventStatusChanged {
+		return false
+	}
+	var payload models.OrderEventPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		r.logger.Warn("Failed to decode order event", "error", err, "sequence", event.Sequence)
+		return false
+	}
+	if payload.PreviousStatus == "" {
+		return false
+	}
+
+	order, err := r.orderRepo.GetByID(ctx, event.OrderID)
+	if err != nil || order == nil {
+		r.logger.Warn("Failed to get order to notify of status change", "error", err, "order_id", event.OrderID)
+		return false
+	}
+	r.notifier.NotifyOrderStatusChange(ctx, order, payload.Status)
+	return true
+}
@@ -4,18 +4,38 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/database"
 	"easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// Order placement and payment stages timed by the stage recorder
+const (
+	StageFraudScreening       = "fraud_screening" // Blocklist checks; blocked attempts count as failures
+	StageCartHolds            = "cart_holds"
+	StageOrderValidation      = "order_validation" // Product, stock and pricing checks
+	StageCreditCheck          = "credit_check"
+	StageOrderPersist         = "order_persist"
+	StageInventoryReservation = "inventory_reservation"
+	StageOrderCommit          = "order_commit"
+	StagePayment              = "payment"
+)
+
+// orderRequestKeyPrefix scopes the idempotency keys of orders placed with a request ID
+// apart from express checkout keys
+const orderRequestKeyPrefix = "request:"
+
 // orderService implements OrderService interface
 type orderService struct {
 	db            *database.DB
@@ -25,6 +45,21 @@ type orderService struct {
 	inventoryRepo repository.InventoryRepository
 	userRepo      repository.UserRepository
 	inventoryServ InventoryService
+	stockNotifier StockChangeNotifier
+	activity      ActivityRecorder
+	adjustments   PaymentMethodAdjustments
+	stores        *StoreSchedules
+	gifts         *GiftOptions
+	duplicates    *DuplicateOrderGuard
+	limits        *OrderLimiter
+	queue         *AllocationQueue
+	notifier      OrderStatusNotifier
+	payments      PaymentService
+	screener      *FraudScreener
+	risk          *RiskScorer
+	priceLists    *PriceListResolver
+	promotions    *PromotionEngine
+	stages        *metrics.StageRecorder
 	logger        *logger.Logger
 }
 
@@ -37,6 +72,21 @@ func NewOrderService(
 	inventoryRepo repository.InventoryRepository,
 	userRepo repository.UserRepository,
 	inventoryServ InventoryService,
+	stockNotifier StockChangeNotifier,
+	activity ActivityRecorder,
+	adjustments PaymentMethodAdjustments,
+	stores *StoreSchedules,
+	gifts *GiftOptions,
+	duplicates *DuplicateOrderGuard,
+	limits *OrderLimiter,
+	queue *AllocationQueue,
+	notifier OrderStatusNotifier,
+	payments PaymentService,
+	screener *FraudScreener,
+	risk *RiskScorer,
+	priceLists *PriceListResolver,
+	promotions *PromotionEngine,
+	stages *metrics.StageRecorder,
 	logger *logger.Logger,
 ) OrderService {
 	return &orderService{
@@ -47,6 +97,21 @@ func NewOrderService(
 		inventoryRepo: inventoryRepo,
 		userRepo:      userRepo,
 		inventoryServ: inventoryServ,
+		stockNotifier: stockNotifier,
+		activity:      activity,
+		adjustments:   adjustments,
+		stores:        stores,
+		gifts:         gifts,
+		duplicates:    duplicates,
+		limits:        limits,
+		queue:         queue,
+		notifier:      notifier,
+		payments:      payments,
+		screener:      screener,
+		risk:          risk,
+		priceLists:    priceLists,
+		promotions:    promotions,
+		stages:        stages,
 		logger:        logger,
 	}
 }
@@ -61,6 +126,35 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 	if len(req.Items) == 0 {
 		return nil, errors.NewValidationError("order must have at least one item")
 	}
+	if err := models.OrderMetadataSchema.Validate(req.Metadata); err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid metadata", err.Error())
+	}
+	if req.PaymentMethod != "" && !req.PaymentMethod.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid payment method %s", req.PaymentMethod))
+	}
+	if req.OnAccount && req.PaymentMethod != "" {
+		return nil, errors.NewValidationError("invalid payment method for an order on account, which is paid by invoice")
+	}
+
+	// A resubmitted request answers with the order its first submission placed, so a
+	// client retrying after a timeout does not place a second one
+	if req.RequestID != "" {
+		if len(orderRequestKeyPrefix)+len(req.RequestID) > maxIdempotencyKeyLength {
+			return nil, errors.NewValidationError(fmt.Sprintf("request ID must be at most %d characters", maxIdempotencyKeyLength-len(orderRequestKeyPrefix)))
+		}
+		req.IdempotencyKey = orderRequestKeyPrefix + req.RequestID
+		if replay, err := s.replayOrder(ctx, req); err != nil || replay != nil {
+			return replay, err
+		}
+	}
+
+	// Channel orders were already accepted by their marketplace, so only orders placed
+	// here are held to the order limits
+	if req.ExternalOrderID == "" {
+		if err := s.limits.Check(ctx, req.UserID, req.Items); err != nil {
+			return nil, err
+		}
+	}
 
 	// Check if user exists (outside transaction for better performance)
 	user, err := s.userRepo.GetByID(ctx, req.UserID)
@@ -71,20 +165,110 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 	if user == nil {
 		return nil, errors.NewNotFoundError("user")
 	}
+	if req.OnAccount && user.OrganizationID == nil {
+		return nil, errors.NewForbiddenError("ordering on account requires an organization account")
+	}
+	if err := s.screener.Screen(ctx, FraudCheckpointOrder, FraudSubject{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: req.ClientIP,
+	}); err != nil {
+		return nil, err
+	}
+
+	channel := req.Channel
+	if channel == "" {
+		channel = models.OrderChannelDirect
+	}
+	var placedBy *string
+	if req.PlacedBy != "" {
+		placedBy = &req.PlacedBy
+	}
+
+	// The store of the channel may refuse orders while closed; orders placed after its
+	// shipping cutoff are promised for the next open day
+	promisedShipBy, err := s.stores.Accept(channel, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.gifts.Check(channel, req.Gift); err != nil {
+		return nil, err
+	}
+
+	// Express checkout keys and channel order IDs already make retries safe; other
+	// orders are checked against the user's recent orders
+	var duplicateWarning *DuplicateOrderWarning
+	if req.IdempotencyKey == "" && req.ExternalOrderID == "" {
+		duplicateWarning, err = s.duplicates.Check(ctx, req.UserID, req.Items, req.ConfirmDuplicate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// During flash sales orders take their turn at the stock in arrival order; channel
+	// orders bypass the queue like the other checks for orders placed here
+	if req.ExternalOrderID == "" {
+		release, err := s.queue.Acquire(ctx, req.QueueTicket, req.UserID, req.Items)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Customers with a B2B price list order at its prices in effect now; other
+	// products, and other customers, at list price
+	requestedProductIDs := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		requestedProductIDs[i] = item.ProductID
+	}
+	contractPrices, err := s.priceLists.Resolve(ctx, user, requestedProductIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Running promotions the customer qualifies for are applied once the items are
+	// priced
+	eligiblePromotions, err := s.promotions.Eligible(ctx, user, channel, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	var order *models.Order
 	var orderItems []*models.OrderItem
 	var inventoryItems []InventoryItem
+	var promotionOutcome *PromotionOutcome
+	var deliverySlot *models.DeliverySlot
 
-	// Use database transaction for atomicity
+	// Use database transaction for atomicity; a failing stage rolls back the earlier ones
+	run := s.stages.Start()
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Create transaction context
 		txCtx := context.WithValue(ctx, "db_tx", tx)
 
+		run.Stage(StageCartHolds)
+
+		// Stock held in the user's cart goes back to available inventory, so the
+		// reservation below moves it to this order
+		productIDs := make([]string, len(req.Items))
+		for i, item := range req.Items {
+			productIDs[i] = item.ProductID
+		}
+		convertedHolds, err := s.releaseCartHoldsInTransaction(tx, txCtx, req.UserID, productIDs)
+		if err != nil {
+			s.logger.Error("Failed to release cart holds for order", "error", err, "user_id", req.UserID)
+			return err
+		}
+
 		// Validate products and calculate order total
+		run.Stage(StageOrderValidation)
 		var totalAmount float64
 		orderItems = make([]*models.OrderItem, 0, len(req.Items))
 		inventoryItems = make([]InventoryItem, 0, len(req.Items))
+		promotionLines := make([]PromotionLine, 0, len(req.Items))
+
+		// Repeated lines of a product count together towards its purchase limit
+		quantities := orderItemQuantities(req.Items)
+		limitChecked := make(map[string]bool)
 
 		for _, item := range req.Items {
 			if item.ProductID == "" {
@@ -111,12 +295,10 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 			}
 
 			// Check and lock inventory using SELECT FOR UPDATE
-			// This prevents race conditions by locking the inventory row until transaction commits
-			var inventory models.Inventory
-			if err := tx.WithContext(txCtx).Clauses(
-				// FOR UPDATE locks the row for the duration of the transaction
-				clause.Locking{Strength: "UPDATE"},
-			).First(&inventory, "product_id = ?", item.ProductID).Error; err != nil {
+			// This prevents race conditions by locking the inventory row until transaction
+			// commits. Purchase limits need the lock even on sharded stock; see below.
+			inventory, err := repository.LockInventory(tx.WithContext(txCtx), item.ProductID, product.PurchaseLimit > 0)
+			if err != nil {
 				if stderrors.Is(err, gorm.ErrRecordNotFound) {
 					return errors.NewNotFoundErrorWithID("inventory", item.ProductID)
 				}
@@ -129,6 +311,17 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 				return err
 			}
 
+			// Limited releases cap the units each customer may buy. Orders of the product
+			// wait on its inventory lock, so the user's order history read here includes
+			// any concurrent order of it that committed first. Channel orders were
+			// already accepted by their marketplace.
+			if product.PurchaseLimit > 0 && req.ExternalOrderID == "" && !limitChecked[product.ID] {
+				limitChecked[product.ID] = true
+				if err := s.checkPurchaseLimitInTransaction(tx, txCtx, req.UserID, &product, quantities[product.ID]); err != nil {
+					return err
+				}
+			}
+
 			// Check if sufficient stock is available
 			if !inventory.CanReserve(item.Quantity) {
 				return errors.NewInsufficientStockError(item.ProductID, item.Quantity, inventory.Available)
@@ -136,6 +329,9 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 
 			// Calculate prices
 			unitPrice := product.Price
+			if contractPrice, ok := contractPrices[product.ID]; ok {
+				unitPrice = contractPrice
+			}
 			totalPrice := unitPrice * float64(item.Quantity)
 			totalAmount += totalPrice
 
@@ -145,8 +341,15 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 				Quantity:   item.Quantity,
 				UnitPrice:  unitPrice,
 				TotalPrice: totalPrice,
+				ListPrice:  product.Price,
 			}
 			orderItems = append(orderItems, orderItem)
+			promotionLines = append(promotionLines, PromotionLine{
+				ProductID:  product.ID,
+				CategoryID: product.CategoryID,
+				UnitPrice:  unitPrice,
+				Quantity:   item.Quantity,
+			})
 
 			// Track inventory to reserve
 			inventoryItems = append(inventoryItems, InventoryItem{
@@ -155,13 +358,66 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 			})
 		}
 
+		// Promotions come off the items before the payment method adjustment, which
+		// does not apply to shipping
+		promotionOutcome = eligiblePromotions.Apply(promotionLines)
+		totalAmount = roundCents(totalAmount - promotionOutcome.Discount)
+
+		// Apply the surcharge or discount for the checkout payment method
+		adjustmentRate, adjustment := s.adjustments.Apply(req.PaymentMethod, totalAmount)
+
+		// Orders on account are invoiced to the organization under its payment terms,
+		// up to its credit limit
+		var invoiceDueAt *time.Time
+		var creditHold bool
+		if req.OnAccount {
+			run.Stage(StageCreditCheck)
+			organization, err := s.lockOrganizationInTransaction(tx, txCtx, *user.OrganizationID)
+			if err != nil {
+				return err
+			}
+			if creditHold, err = s.checkCreditLimitInTransaction(tx, txCtx, organization, totalAmount+adjustment+promotionOutcome.ShippingFee); err != nil {
+				return err
+			}
+			dueAt := organization.InvoiceDueDate(time.Now())
+			invoiceDueAt = &dueAt
+		}
+
+		// Book the delivery slot under its row lock, like the inventory below
+		if req.DeliverySlotID != "" {
+			if deliverySlot, err = s.reserveDeliverySlotInTransaction(tx, txCtx, req.DeliverySlotID, req.ShippingAddress, promisedShipBy); err != nil {
+				return err
+			}
+		}
+
 		// Create order within transaction
+		run.Stage(StageOrderPersist)
 		order = &models.Order{
-			UserID:      req.UserID,
-			Status:      models.OrderStatusPending,
-			TotalAmount: totalAmount,
-			Currency:    "USD",
-			Notes:       req.Notes,
+			UserID:                req.UserID,
+			Status:                models.OrderStatusPending,
+			TotalAmount:           totalAmount + adjustment + promotionOutcome.ShippingFee,
+			Currency:              "USD",
+			Notes:                 req.Notes,
+			Metadata:              models.Metadata(req.Metadata),
+			Channel:               channel,
+			ExternalOrderID:       req.ExternalOrderID,
+			PlacedBy:              placedBy,
+			PaymentMethod:         req.PaymentMethod,
+			PaymentAdjustmentRate: adjustmentRate,
+			PaymentAdjustment:     adjustment,
+			DiscountAmount:        promotionOutcome.Discount,
+			ShippingFee:           promotionOutcome.ShippingFee,
+			OrganizationID:        user.OrganizationID,
+			OnAccount:             req.OnAccount,
+			InvoiceDueAt:          invoiceDueAt,
+			CreditHold:            creditHold,
+			PromisedShipBy:        promisedShipBy,
+			ShippingAddress:       req.ShippingAddress,
+			IdempotencyKey:        req.IdempotencyKey,
+		}
+		applyGiftOptions(order, req.Gift)
+		if deliverySlot != nil {
+			order.DeliverySlotID = &deliverySlot.ID
 		}
 
 		if err := tx.WithContext(txCtx).Create(order).Error; err != nil {
@@ -169,6 +425,24 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 			return err
 		}
 
+		if err := repository.AppendOrderEvent(tx.WithContext(txCtx), order, models.OrderEventCreated, ""); err != nil {
+			s.logger.Error("Failed to record order event", "error", err, "order_id", order.ID)
+			return err
+		}
+
+		if len(convertedHolds) > 0 {
+			if err := tx.WithContext(txCtx).Model(&models.StockHold{}).
+				Where("id IN ?", convertedHolds).
+				Updates(map[string]interface{}{
+					"status":      models.StockHoldStatusConverted,
+					"order_id":    order.ID,
+					"released_at": time.Now(),
+				}).Error; err != nil {
+				s.logger.Error("Failed to convert cart holds", "error", err, "order_id", order.ID)
+				return err
+			}
+		}
+
 		// Set order ID for all items and create them
 		for _, orderItem := range orderItems {
 			orderItem.OrderID = order.ID
@@ -179,8 +453,27 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 			return err
 		}
 
+		// Record the promotions applied, for the promotion performance report
+		if len(promotionOutcome.Applied) > 0 {
+			orderPromotions := make([]*models.OrderPromotion, len(promotionOutcome.Applied))
+			for i, applied := range promotionOutcome.Applied {
+				orderPromotions[i] = &models.OrderPromotion{
+					OrderID:     order.ID,
+					PromotionID: applied.PromotionID,
+					Name:        applied.Name,
+					Type:        applied.Type,
+					Discount:    applied.Discount,
+				}
+			}
+			if err := tx.WithContext(txCtx).Create(&orderPromotions).Error; err != nil {
+				s.logger.Error("Failed to record order promotions", "error", err, "order_id", order.ID)
+				return err
+			}
+		}
+
 		// Reserve inventory within the same transaction
 		// Use bulk reserve for better performance
+		run.Stage(StageInventoryReservation)
 		reservations := make([]repository.InventoryReservation, len(inventoryItems))
 		for i, item := range inventoryItems {
 			reservations[i] = repository.InventoryReservation{
@@ -189,8 +482,13 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 			}
 		}
 
-		// Reserve using the transaction context
-		if err := s.reserveStockInTransaction(tx, txCtx, reservations); err != nil {
+		// Reserve using the transaction context, recorded against the order
+		reserveCtx := repository.WithInventoryEventSource(txCtx, repository.InventoryEventSource{
+			ActorID:       req.UserID,
+			ReferenceType: models.InventoryReferenceOrder,
+			ReferenceID:   order.ID,
+		})
+		if err := s.reserveStockInTransaction(tx, reserveCtx, reservations); err != nil {
 			s.logger.Error("Failed to reserve inventory", "error", err, "order_id", order.ID)
 			return fmt.Errorf("failed to reserve inventory: %w", err)
 		}
@@ -198,13 +496,29 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 		s.logger.Info("Order created and inventory reserved successfully",
 			"order_id", order.ID, "total", totalAmount, "items_count", len(orderItems))
 
+		run.Stage(StageOrderCommit)
 		return nil
 	})
 
 	if err != nil {
+		run.Fail(true)
+		// A concurrent submission of the same request may have placed the order first
+		if req.RequestID != "" {
+			if replay, replayErr := s.replayOrder(ctx, req); replayErr == nil && replay != nil {
+				return replay, nil
+			}
+		}
 		s.logger.Error("Transaction failed during order creation", "error", err, "user_id", req.UserID)
 		return nil, err
 	}
+	run.Succeed()
+
+	// Reservations are committed, so external channels can see the new availability
+	if s.stockNotifier != nil {
+		s.stockNotifier.NotifyStockChanges(ctx, StockChangeReasonReservation, inventoryItemProductIDs(inventoryItems))
+	}
+	recordActivity(ctx, s.activity, order.UserID, models.ActivityEventOrderPlaced, order.ID)
+	s.scoreRisk(ctx, order, orderItems)
 
 	// Convert to response format
 	responseItems := make([]OrderItem, len(orderItems))
@@ -217,48 +531,171 @@ func (s *orderService) CreateOrder(ctx context.Context, req CreateOrderRequest)
 	}
 
 	return &OrderResponse{
-		ID:     order.ID,
-		UserID: order.UserID,
-		Status: order.Status,
-		Items:  responseItems,
-		Total:  order.TotalAmount,
+		ID:                order.ID,
+		UserID:            order.UserID,
+		Status:            order.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(order),
+		Discount:          order.DiscountAmount,
+		ShippingFee:       order.ShippingFee,
+		Promotions:        promotionOutcome.Applied,
+		DeliverySlotID:    order.DeliverySlotID,
+		DeliverySlot:      newOrderDeliverySlot(deliverySlot),
+		Total:             order.TotalAmount,
+		Metadata:          order.Metadata,
+		Channel:           order.Channel,
+		PlacedBy:          order.PlacedBy,
+		OrganizationID:    order.OrganizationID,
+		OnAccount:         order.OnAccount,
+		InvoiceDueAt:      order.InvoiceDueAt,
+		CreditHold:        order.CreditHold,
+		PromisedShipBy:    order.PromisedShipBy,
+		ShippingAddress:   order.ShippingAddress,
+		Gift:              newOrderGiftOptions(order),
+		DuplicateWarning:  duplicateWarning,
 	}, nil
 }
 
-// reserveStockInTransaction reserves inventory within an existing transaction
-func (s *orderService) reserveStockInTransaction(tx *gorm.DB, ctx context.Context, items []repository.InventoryReservation) error {
-	for _, item := range items {
-		var inventory models.Inventory
-		if err := tx.WithContext(ctx).First(&inventory, "product_id = ?", item.ProductID).Error; err != nil {
-			return errors.NewDatabaseError("failed to get inventory for reservation", err)
+// releaseCartHoldsInTransaction returns the stock of the user's active cart holds on
+// the products to available inventory, and returns the IDs of the released holds
+func (s *orderService) releaseCartHoldsInTransaction(tx *gorm.DB, ctx context.Context, userID string, productIDs []string) ([]string, error) {
+	var holds []*models.StockHold
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND product_id IN ? AND status = ?", userID, productIDs, models.StockHoldStatusActive).
+		Order("product_id").
+		Find(&holds).Error; err != nil {
+		return nil, errors.NewDatabaseError("failed to get cart holds", err)
+	}
+
+	holdIDs := make([]string, len(holds))
+	for i, hold := range holds {
+		if err := repository.ReleaseHeldStock(tx.WithContext(ctx), hold); err != nil {
+			return nil, errors.NewDatabaseError("failed to release cart hold", err)
 		}
+		holdIDs[i] = hold.ID
+	}
+	return holdIDs, nil
+}
 
-		// Reserve the stock
-		oldVersion := inventory.Version
-		if err := inventory.Reserve(item.Quantity); err != nil {
-			return errors.NewInsufficientStockError(item.ProductID, item.Quantity, inventory.Available)
+// reserveDeliverySlotInTransaction books an order into a delivery slot under its row
+// lock. The slot must be in the region the order ships to, if it has an address, and
+// on or after the day the order is promised to ship.
+func (s *orderService) reserveDeliverySlotInTransaction(tx *gorm.DB, ctx context.Context, slotID string, address *models.ShippingAddress, promisedShipBy *time.Time) (*models.DeliverySlot, error) {
+	var slot models.DeliverySlot
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&slot, "id = ?", slotID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundErrorWithID("delivery slot", slotID)
 		}
-		inventory.Version++
+		s.logger.Error("Failed to get and lock delivery slot", "error", err, "slot_id", slotID)
+		return nil, err
+	}
 
-		// Update with optimistic locking
-		result := tx.WithContext(ctx).Model(&inventory).
-			Where("product_id = ? AND version = ?", item.ProductID, oldVersion).
-			Updates(map[string]interface{}{
-				"reserved":  inventory.Reserved,
-				"available": inventory.Available,
-				"version":   inventory.Version,
-			})
+	if address != nil && !strings.EqualFold(strings.TrimSpace(address.Region), slot.Region) {
+		return nil, errors.NewBusinessError(fmt.Sprintf("delivery slot %s is not available in region %s", slotID, address.Region))
+	}
+	earliest := time.Now()
+	if promisedShipBy != nil && promisedShipBy.After(earliest) {
+		earliest = *promisedShipBy
+	}
+	if !slot.IsActive || !slot.IsBookableFrom(earliest) {
+		return nil, errors.NewBusinessError(fmt.Sprintf("delivery slot %s is not available", slotID))
+	}
+	if !slot.CanReserve() {
+		return nil, errors.NewConflictError(fmt.Sprintf("delivery slot %s is fully booked", slotID))
+	}
+
+	if err := tx.WithContext(ctx).Model(&slot).UpdateColumns(map[string]interface{}{
+		"reserved":   gorm.Expr("reserved + 1"),
+		"updated_at": gorm.Expr("NOW()"),
+	}).Error; err != nil {
+		s.logger.Error("Failed to reserve delivery slot", "error", err, "slot_id", slotID)
+		return nil, errors.NewDatabaseError("failed to reserve delivery slot", err)
+	}
+	slot.Reserved++
+	return &slot, nil
+}
+
+// newOrderDeliverySlot returns the delivery slot booked for an order, if any
+func newOrderDeliverySlot(slot *models.DeliverySlot) *DeliverySlotResponse {
+	if slot == nil {
+		return nil
+	}
+	return toDeliverySlotResponse(slot)
+}
 
-		if result.Error != nil {
-			return errors.NewDatabaseError("failed to update inventory reservation", result.Error)
+// lockOrganizationInTransaction locks the organization an order is placed on account
+// with, so concurrent on-account orders of its members are serialized
+func (s *orderService) lockOrganizationInTransaction(tx *gorm.DB, ctx context.Context, organizationID string) (*models.Organization, error) {
+	var organization models.Organization
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&organization, "id = ?", organizationID).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundErrorWithID("organization", organizationID)
 		}
+		s.logger.Error("Failed to get and lock organization", "error", err, "organization_id", organizationID)
+		return nil, err
+	}
+
+	if !organization.AllowsOrderingOnAccount() {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("organization %s cannot order on account", organization.Name))
+	}
+	return &organization, nil
+}
+
+// checkCreditLimitInTransaction checks an on-account order against the available
+// credit of its locked organization. It returns true if the order goes over the
+// limit and must be held for review, or an error if such orders are rejected.
+func (s *orderService) checkCreditLimitInTransaction(tx *gorm.DB, ctx context.Context, organization *models.Organization, amount float64) (bool, error) {
+	outstanding, err := repository.OrganizationOutstandingBalance(tx.WithContext(ctx), organization.ID)
+	if err != nil {
+		s.logger.Error("Failed to get organization outstanding balance", "error", err, "organization_id", organization.ID)
+		return false, err
+	}
 
-		// Optimistic lock failure - version mismatch
-		if result.RowsAffected == 0 {
-			s.logger.Warn("Optimistic lock failed during inventory reservation",
-				"product_id", item.ProductID, "expected_version", oldVersion)
-			return errors.NewStockReservationConflictError(item.ProductID,
-				stderrors.New("inventory was modified by another transaction"))
+	if !organization.ExceedsCreditLimit(outstanding, amount) {
+		return false, nil
+	}
+
+	s.logger.Warn("On-account order exceeds organization credit limit",
+		"organization_id", organization.ID,
+		"credit_limit", organization.CreditLimit,
+		"outstanding", outstanding,
+		"amount", amount,
+		"action", organization.CreditLimitAction)
+
+	if organization.CreditLimitAction == models.CreditLimitActionHold {
+		return true, nil
+	}
+	return false, errors.NewBusinessError(fmt.Sprintf("credit limit exceeded: order total %.2f is more than the available credit of %.2f",
+		amount, organization.AvailableCredit(outstanding)))
+}
+
+// checkPurchaseLimitInTransaction refuses an order of quantity units of a product that
+// would take the user past its purchase limit
+func (s *orderService) checkPurchaseLimitInTransaction(tx *gorm.DB, ctx context.Context, userID string, product *models.Product, quantity int) error {
+	purchased, err := repository.UserPurchasedQuantity(tx.WithContext(ctx), userID, product.ID, product.PurchaseLimitSince(time.Now()))
+	if err != nil {
+		s.logger.Error("Failed to count purchased units for the purchase limit", "error", err,
+			"user_id", userID, "product_id", product.ID)
+		return errors.NewDatabaseError("failed to check the purchase limit", err)
+	}
+
+	if purchased+quantity <= product.PurchaseLimit {
+		return nil
+	}
+
+	s.logger.Warn("Order refused for the purchase limit of a product", "user_id", userID, "product_id", product.ID,
+		"quantity", quantity, "purchased", purchased, "limit", product.PurchaseLimit, "period_days", product.PurchaseLimitDays)
+	return errors.NewPurchaseLimitError(product.ID, quantity, purchased, product.PurchaseLimit, product.PurchaseLimitDays)
+}
+
+// reserveStockInTransaction reserves inventory within an existing transaction
+func (s *orderService) reserveStockInTransaction(tx *gorm.DB, ctx context.Context, items []repository.InventoryReservation) error {
+	for _, item := range items {
+		if _, err := repository.ReserveInventory(tx.WithContext(ctx), item.ProductID, item.Quantity); err != nil {
+			s.logger.Warn("Inventory reservation failed in transaction", "error", err, "product_id", item.ProductID)
+			return err
 		}
 
 		s.logger.Debug("Stock reserved in transaction", "product_id", item.ProductID, "quantity", item.Quantity)
@@ -288,18 +725,34 @@ func (s *orderService) GetOrder(ctx context.Context, id string) (*OrderResponse,
 	responseItems := make([]OrderItem, len(order.Items))
 	for i, item := range order.Items {
 		responseItems[i] = OrderItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			SerialNumbers: item.SerialNumbers,
 		}
 	}
 
 	return &OrderResponse{
-		ID:     order.ID,
-		UserID: order.UserID,
-		Status: order.Status,
-		Items:  responseItems,
-		Total:  order.TotalAmount,
+		ID:                order.ID,
+		UserID:            order.UserID,
+		Status:            order.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(order),
+		Discount:          order.DiscountAmount,
+		ShippingFee:       order.ShippingFee,
+		Total:             order.TotalAmount,
+		Paid:              order.PaidAmount,
+		Metadata:          order.Metadata,
+		Channel:           order.Channel,
+		PlacedBy:          order.PlacedBy,
+		OrganizationID:    order.OrganizationID,
+		OnAccount:         order.OnAccount,
+		InvoiceDueAt:      order.InvoiceDueAt,
+		CreditHold:        order.CreditHold,
+		PromisedShipBy:    order.PromisedShipBy,
+		ShippingAddress:   order.ShippingAddress,
+		DeliverySlotID:    order.DeliverySlotID,
+		Gift:              newOrderGiftOptions(order),
 	}, nil
 }
 
@@ -321,16 +774,36 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, id string, status
 		return nil, errors.NewNotFoundErrorWithID("order", id)
 	}
 
+	// Orders on a compliance hold are not fulfilled until it is released
+	if order.OnHold && (status == models.OrderStatusShipped || status == models.OrderStatusDelivered) {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is on hold and cannot be fulfilled until the hold is released", id))
+	}
+
+	// Serialized products ship with the serial number of each unit captured
+	if status == models.OrderStatusShipped {
+		for i := range order.Items {
+			if item := &order.Items[i]; item.AwaitsSerialNumbers() {
+				return nil, errors.NewConflictError(fmt.Sprintf("order %s cannot ship until the serial numbers of its %d units of product %s are captured",
+					id, item.Quantity, item.ProductID))
+			}
+		}
+	}
+
 	// Check if status transition is valid
 	if !order.CanTransitionTo(status) {
 		return nil, errors.NewInvalidTransitionError(string(order.Status), string(status))
 	}
 
-	// Update order status
-	if err := s.orderRepo.UpdateStatus(ctx, id, status); err != nil {
+	// Checked again under the order's lock, so a concurrent change of status, such as a
+	// cancellation, cannot be overwritten
+	transitioned, err := s.orderRepo.TransitionStatus(ctx, id, status)
+	if err != nil {
 		s.logger.Error("Failed to update order status", "error", err, "id", id, "status", status)
 		return nil, err
 	}
+	if !transitioned {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s changed while its status was being updated", id))
+	}
 
 	s.logger.Info("Order status updated successfully", "id", id, "new_status", status)
 
@@ -345,21 +818,38 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, id string, status
 	responseItems := make([]OrderItem, len(updatedOrder.Items))
 	for i, item := range updatedOrder.Items {
 		responseItems[i] = OrderItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			SerialNumbers: item.SerialNumbers,
 		}
 	}
 
 	return &OrderResponse{
-		ID:     updatedOrder.ID,
-		UserID: updatedOrder.UserID,
-		Status: updatedOrder.Status,
-		Items:  responseItems,
-		Total:  updatedOrder.TotalAmount,
+		ID:                updatedOrder.ID,
+		UserID:            updatedOrder.UserID,
+		Status:            updatedOrder.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(updatedOrder),
+		Discount:          updatedOrder.DiscountAmount,
+		ShippingFee:       updatedOrder.ShippingFee,
+		Total:             updatedOrder.TotalAmount,
+		Paid:              updatedOrder.PaidAmount,
+		Metadata:          updatedOrder.Metadata,
+		Channel:           updatedOrder.Channel,
+		PlacedBy:          updatedOrder.PlacedBy,
+		PromisedShipBy:    updatedOrder.PromisedShipBy,
+		ShippingAddress:   updatedOrder.ShippingAddress,
+		DeliverySlotID:    updatedOrder.DeliverySlotID,
+		Gift:              newOrderGiftOptions(updatedOrder),
 	}, nil
 }
 
+// CancelOrder cancels an order that has not shipped. The order is cancelled first,
+// under its row lock, so that it cannot ship or be cancelled twice meanwhile; only then
+// is its reserved stock returned to inventory and what the customer paid refunded. A
+// failed refund leaves the order cancelled and is returned, for the payments to be
+// refunded from the payment API.
 func (s *orderService) CancelOrder(ctx context.Context, id string) error {
 	s.logger.Info("Cancelling order", "id", id)
 
@@ -383,16 +873,231 @@ func (s *orderService) CancelOrder(ctx context.Context, id string) error {
 		return errors.NewBusinessError(fmt.Sprintf("order in status %s cannot be cancelled", order.Status))
 	}
 
-	// Update order status to cancelled
-	if err := s.orderRepo.UpdateStatus(ctx, id, models.OrderStatusCancelled); err != nil {
+	cancelled, err := s.orderRepo.TransitionStatus(ctx, id, models.OrderStatusCancelled)
+	if err != nil {
 		s.logger.Error("Failed to cancel order", "error", err, "id", id)
 		return err
 	}
+	if !cancelled {
+		return errors.NewBusinessError(fmt.Sprintf("order %s cannot be cancelled, it changed while being cancelled", id))
+	}
+
+	// Payments may have completed since the order was read
+	order, err = s.orderRepo.GetByID(ctx, id)
+	if err != nil || order == nil {
+		s.logger.Error("Failed to get cancelled order", "error", err, "id", id)
+		return errors.NewDatabaseError("order cancelled but could not be read to refund it", err)
+	}
 
-	s.logger.Info("Order cancelled successfully", "id", id)
+	quantities := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	s.restock(ctx, id, quantities)
+	s.releaseDeliverySlot(ctx, order)
+	recordActivity(ctx, s.activity, order.UserID, models.ActivityEventOrderCancelled, id)
+
+	refunded, err := s.refundOrder(ctx, order, orderRefundableAmount(order))
+	if err != nil {
+		s.logger.Error("Order cancelled but not refunded in full", "error", err, "id", id,
+			"refunded", refunded, "refundable", orderRefundableAmount(order))
+		notifyOrderRefunded(ctx, s.notifier, order, refunded)
+		return err
+	}
+
+	s.logger.Info("Order cancelled successfully", "id", id, "refunded", refunded)
+	notifyOrderRefunded(ctx, s.notifier, order, refunded)
 	return nil
 }
 
+// CancelOrderItems cancels some of the items of an order that has not shipped. The
+// order total is reduced by the cancelled items and their share of the payment method
+// adjustment, which is refunded if the order was paid, and their reserved stock is
+// returned to inventory.
+func (s *orderService) CancelOrderItems(ctx context.Context, id string, req CancelOrderItemsRequest) (*OrderResponse, error) {
+	s.logger.Info("Cancelling order items", "id", id, "items_count", len(req.Items))
+
+	if id == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, errors.NewValidationError("at least one item to cancel is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get order for item cancellation", "error", err, "id", id)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", id)
+	}
+	if !order.IsCancellable() {
+		return nil, errors.NewBusinessError(fmt.Sprintf("order in status %s cannot be cancelled", order.Status))
+	}
+
+	ordered := make(map[string]int, len(order.Items))
+	for _, item := range order.Items {
+		ordered[item.ProductID] += item.Quantity
+	}
+
+	quantities := make(map[string]int, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, errors.NewValidationError(fmt.Sprintf("quantity to cancel must be greater than 0 for product %s", item.ProductID))
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	remainingUnits := 0
+	for productID, quantity := range ordered {
+		remainingUnits += quantity - quantities[productID]
+	}
+	for productID, cancelled := range quantities {
+		if cancelled > ordered[productID] {
+			return nil, errors.NewValidationError(fmt.Sprintf("order %s has %d of product %s, cannot cancel %d",
+				id, ordered[productID], productID, cancelled))
+		}
+	}
+	if remainingUnits == 0 {
+		return nil, errors.NewValidationError("cancelling every item cancels the order; cancel the order instead")
+	}
+
+	// The items come off under the order's lock, which works out how far the total went
+	// down; only then is the difference refunded
+	reduced, reduction, err := s.orderRepo.CancelItems(ctx, id, quantities)
+	if err != nil {
+		s.logger.Error("Failed to cancel order items", "error", err, "id", id)
+		return nil, errors.NewDatabaseError("failed to cancel order items", err)
+	}
+	if reduced == nil {
+		return nil, errors.NewConflictError("order changed while its items were being cancelled")
+	}
+
+	s.restock(ctx, id, quantities)
+	notifyOrderItemsCancelled(ctx, s.notifier, reduced)
+
+	refunded, err := s.refundOrder(ctx, reduced, math.Min(reduction, orderRefundableAmount(reduced)))
+	if err != nil {
+		s.logger.Error("Order items cancelled but not refunded in full", "error", err, "id", id,
+			"refunded", refunded, "reduction", reduction)
+		notifyOrderRefunded(ctx, s.notifier, reduced, refunded)
+		return nil, err
+	}
+
+	s.logger.Info("Order items cancelled", "id", id, "total", reduced.TotalAmount, "refunded", refunded)
+	notifyOrderRefunded(ctx, s.notifier, reduced, refunded)
+
+	responseItems := make([]OrderItem, len(reduced.Items))
+	for i, item := range reduced.Items {
+		responseItems[i] = OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+	return &OrderResponse{
+		ID:                reduced.ID,
+		UserID:            reduced.UserID,
+		Status:            reduced.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(reduced),
+		Discount:          reduced.DiscountAmount,
+		ShippingFee:       reduced.ShippingFee,
+		Total:             reduced.TotalAmount,
+		Refunded:          refunded,
+		Metadata:          reduced.Metadata,
+		Channel:           reduced.Channel,
+		PlacedBy:          reduced.PlacedBy,
+		PromisedShipBy:    reduced.PromisedShipBy,
+		ShippingAddress:   reduced.ShippingAddress,
+		DeliverySlotID:    reduced.DeliverySlotID,
+		Gift:              newOrderGiftOptions(reduced),
+	}, nil
+}
+
+// refundOrder refunds up to amount of the order's completed payments, oldest first,
+// and returns how much was refunded. Without a payment service nothing is refunded.
+func (s *orderService) refundOrder(ctx context.Context, order *models.Order, amount float64) (float64, error) {
+	if amount <= 0 {
+		return 0, nil
+	}
+	if s.payments == nil {
+		s.logger.Warn("No payment service to refund the order", "order_id", order.ID, "amount", amount)
+		return 0, nil
+	}
+
+	payments := make([]models.Payment, len(order.Payments))
+	copy(payments, order.Payments)
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].CreatedAt.Before(payments[j].CreatedAt) })
+
+	var refunded float64
+	for i := range payments {
+		refundable := payments[i].RefundableAmount()
+		if refundable <= 0 {
+			continue
+		}
+		refund := math.Min(refundable, roundCents(amount-refunded))
+		if _, err := s.payments.RefundPayment(ctx, payments[i].ID, RefundRequest{Amount: refund, Reason: "order cancellation"}); err != nil {
+			s.logger.Error("Failed to refund order payment", "error", err, "order_id", order.ID,
+				"payment_id", payments[i].ID, "refunded", refunded)
+			return refunded, err
+		}
+		refunded = roundCents(refunded + refund)
+		if refunded >= amount {
+			break
+		}
+	}
+	return refunded, nil
+}
+
+// orderRefundableAmount sums what can still be refunded of the order's payments
+func orderRefundableAmount(order *models.Order) float64 {
+	var refundable float64
+	for i := range order.Payments {
+		refundable += order.Payments[i].RefundableAmount()
+	}
+	return roundCents(refundable)
+}
+
+// restock returns the reserved stock of cancelled items to inventory. The items are
+// cancelled either way, so a failure is logged for the stock to be corrected.
+func (s *orderService) restock(ctx context.Context, orderID string, quantities map[string]int) {
+	if s.inventoryServ == nil {
+		return
+	}
+
+	items := make([]InventoryItem, 0, len(quantities))
+	for productID, quantity := range quantities {
+		if quantity > 0 {
+			items = append(items, InventoryItem{ProductID: productID, Quantity: quantity})
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	ctx = repository.WithInventoryEventSource(ctx, repository.InventoryEventSource{
+		ReferenceType: models.InventoryReferenceOrder,
+		ReferenceID:   orderID,
+	})
+	if err := s.inventoryServ.ReleaseInventory(ctx, items); err != nil {
+		s.logger.Error("Failed to restock cancelled order items", "error", err, "order_id", orderID)
+	}
+}
+
+// releaseDeliverySlot gives back the delivery slot capacity a cancelled order held.
+// The order is cancelled either way, so a failure is logged for the slot to be corrected.
+func (s *orderService) releaseDeliverySlot(ctx context.Context, order *models.Order) {
+	if order.DeliverySlotID == nil || s.db == nil {
+		return
+	}
+	if err := repository.ReleaseDeliverySlot(s.db.WithContext(ctx), *order.DeliverySlotID); err != nil {
+		s.logger.Error("Failed to release delivery slot of cancelled order", "error", err,
+			"order_id", order.ID, "slot_id", *order.DeliverySlotID)
+	}
+}
+
 func (s *orderService) ListOrders(ctx context.Context, req ListOrdersRequest) (*ListOrdersResponse, error) {
 	s.logger.Debug("Listing orders", "page", req.Page, "limit", req.Limit, "status", req.Status)
 
@@ -415,7 +1120,14 @@ func (s *orderService) ListOrders(ctx context.Context, req ListOrdersRequest) (*
 	var orders []*models.Order
 	var err error
 
-	if req.Status != "" {
+	if req.MetadataKey != "" {
+		if !models.IsValidMetadataKey(req.MetadataKey) {
+			return nil, errors.NewValidationError("invalid metadata key")
+		}
+		orders, err = s.orderRepo.ListByMetadata(ctx, req.MetadataKey, req.MetadataValue, offset, limit)
+	} else if req.RiskBand != "" {
+		orders, err = s.orderRepo.ListByRiskBand(ctx, req.RiskBand, offset, limit)
+	} else if req.Status != "" {
 		orders, err = s.orderRepo.ListByStatus(ctx, req.Status, offset, limit)
 	} else {
 		orders, err = s.orderRepo.List(ctx, offset, limit)
@@ -428,7 +1140,11 @@ func (s *orderService) ListOrders(ctx context.Context, req ListOrdersRequest) (*
 
 	// Get total count
 	var totalCount int64
-	if req.Status != "" {
+	if req.MetadataKey != "" {
+		totalCount, err = s.orderRepo.CountByMetadata(ctx, req.MetadataKey, req.MetadataValue)
+	} else if req.RiskBand != "" {
+		totalCount, err = s.orderRepo.CountByRiskBand(ctx, req.RiskBand)
+	} else if req.Status != "" {
 		totalCount, err = s.orderRepo.CountByStatus(ctx, req.Status)
 	} else {
 		totalCount, err = s.orderRepo.Count(ctx)
@@ -442,21 +1158,9 @@ func (s *orderService) ListOrders(ctx context.Context, req ListOrdersRequest) (*
 	// Convert to response format
 	orderResponses := make([]*OrderResponse, len(orders))
 	for i, order := range orders {
-		responseItems := make([]OrderItem, len(order.Items))
-		for j, item := range order.Items {
-			responseItems[j] = OrderItem{
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				UnitPrice: item.UnitPrice,
-			}
-		}
-
-		orderResponses[i] = &OrderResponse{
-			ID:     order.ID,
-			UserID: order.UserID,
-			Status: order.Status,
-			Items:  responseItems,
-			Total:  order.TotalAmount,
+		orderResponses[i] = newOrderListResponse(order)
+		if req.IncludeRisk {
+			orderResponses[i].Risk = newOrderRisk(order)
 		}
 	}
 
@@ -469,3 +1173,255 @@ func (s *orderService) ListOrders(ctx context.Context, req ListOrdersRequest) (*
 		Total:  int(totalCount),
 	}, nil
 }
+
+// GetUserOrders returns a page of a user's orders, newest first, optionally only
+// those in a status
+func (s *orderService) GetUserOrders(ctx context.Context, userID string, req ListUserOrdersRequest) (*ListOrdersResponse, error) {
+	s.logger.Debug("Listing user orders", "user_id", userID, "page", req.Page, "limit", req.Limit, "status", req.Status)
+
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user for orders", "error", err, "user_id", userID)
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundErrorWithID("user", userID)
+	}
+
+	orders, err := s.orderRepo.ListByUserID(ctx, userID, req.Status, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list user orders", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	totalCount, err := s.orderRepo.CountByUserIDAndStatus(ctx, userID, req.Status)
+	if err != nil {
+		s.logger.Error("Failed to count user orders", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	orderResponses := make([]*OrderResponse, len(orders))
+	for i, order := range orders {
+		orderResponses[i] = newOrderListResponse(order)
+	}
+
+	return &ListOrdersResponse{
+		Orders: orderResponses,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(totalCount),
+	}, nil
+}
+
+// StreamOrders walks every order matching the list filters in batches and
+// passes each one to fn as it is fetched. Pagination fields are ignored.
+func (s *orderService) StreamOrders(ctx context.Context, req ListOrdersRequest, fn func(*OrderResponse) error) error {
+	s.logger.Info("Streaming orders", "status", req.Status, "metadata_key", req.MetadataKey)
+
+	if req.MetadataKey != "" && !models.IsValidMetadataKey(req.MetadataKey) {
+		return errors.NewValidationError("invalid metadata key")
+	}
+
+	iterator := s.orderRepo.Iterate(repository.OrderFilter{
+		Status:        req.Status,
+		MetadataKey:   req.MetadataKey,
+		MetadataValue: req.MetadataValue,
+		RiskBand:      req.RiskBand,
+	}, orderStreamBatchSize)
+
+	streamed := 0
+	for {
+		batch, err := iterator.Next(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch orders for streaming", "error", err, "streamed", streamed)
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, order := range batch {
+			response := newOrderListResponse(order)
+			if req.IncludeRisk {
+				response.Risk = newOrderRisk(order)
+			}
+			if err := fn(response); err != nil {
+				return err
+			}
+			streamed++
+		}
+	}
+
+	s.logger.Info("Orders streamed", "count", streamed)
+	return nil
+}
+
+// orderStreamBatchSize is how many orders StreamOrders fetches per query
+const orderStreamBatchSize = 500
+
+// replayOrder returns the order placed earlier by a submission with the request's ID,
+// or nil when there is none
+func (s *orderService) replayOrder(ctx context.Context, req CreateOrderRequest) (*OrderResponse, error) {
+	order, err := s.orderRepo.GetByIdempotencyKey(ctx, req.UserID, req.IdempotencyKey)
+	if err != nil {
+		s.logger.Error("Failed to get order by request ID", "error", err, "user_id", req.UserID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, nil
+	}
+
+	placed := make([]OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		placed[i] = OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	if !sameQuantities(orderItemQuantities(placed), orderItemQuantities(req.Items)) {
+		return nil, errors.NewConflictError("request ID was already used for a different order")
+	}
+
+	s.logger.Info("Replaying order submission", "order_id", order.ID, "user_id", req.UserID)
+
+	response := newOrderListResponse(order)
+	response.Replayed = true
+	return response, nil
+}
+
+// scoreRisk scores the risk of an order just placed. An order that fails to score is
+// still placed, and can be rescored by an admin.
+func (s *orderService) scoreRisk(ctx context.Context, order *models.Order, items []*models.OrderItem) {
+	if s.risk == nil {
+		return
+	}
+
+	order.Items = make([]models.OrderItem, len(items))
+	for i, item := range items {
+		order.Items[i] = *item
+	}
+	if err := s.risk.Score(ctx, order); err != nil {
+		s.logger.Warn("Failed to score order risk", "error", err, "order_id", order.ID)
+	}
+}
+
+// newOrderRisk returns the order's risk score, or nil if it was not scored
+func newOrderRisk(order *models.Order) *OrderRisk {
+	if order.RiskScore == nil {
+		return nil
+	}
+	return &OrderRisk{
+		Score:    *order.RiskScore,
+		Band:     models.RiskBandOf(*order.RiskScore),
+		Signals:  order.RiskSignals,
+		ScoredAt: order.RiskScoredAt,
+	}
+}
+
+// newOrderListResponse converts an order with preloaded items to its list response
+func newOrderListResponse(order *models.Order) *OrderResponse {
+	responseItems := make([]OrderItem, len(order.Items))
+	for j, item := range order.Items {
+		responseItems[j] = OrderItem{
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			SerialNumbers: item.SerialNumbers,
+		}
+	}
+
+	return &OrderResponse{
+		ID:                order.ID,
+		UserID:            order.UserID,
+		Status:            order.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(order),
+		Discount:          order.DiscountAmount,
+		ShippingFee:       order.ShippingFee,
+		Total:             order.TotalAmount,
+		Paid:              order.PaidAmount,
+		Metadata:          order.Metadata,
+		Channel:           order.Channel,
+		PlacedBy:          order.PlacedBy,
+		OrganizationID:    order.OrganizationID,
+		OnAccount:         order.OnAccount,
+		InvoiceDueAt:      order.InvoiceDueAt,
+		CreditHold:        order.CreditHold,
+		PromisedShipBy:    order.PromisedShipBy,
+		ShippingAddress:   order.ShippingAddress,
+		DeliverySlotID:    order.DeliverySlotID,
+		Gift:              newOrderGiftOptions(order),
+	}
+}
+
+func (s *orderService) UpdateOrderMetadata(ctx context.Context, id string, req UpdateMetadataRequest) (*OrderResponse, error) {
+	s.logger.Info("Updating order metadata", "id", id, "keys", len(req.Metadata))
+
+	if id == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+
+	order, err := s.orderRepo.GetByIDWithItems(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get order for metadata update", "error", err, "id", id)
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", id)
+	}
+
+	order.Metadata.Merge(req.Metadata)
+	if err := models.OrderMetadataSchema.Validate(order.Metadata); err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid metadata", err.Error())
+	}
+
+	if err := s.orderRepo.Update(ctx, order); err != nil {
+		s.logger.Error("Failed to update order metadata", "error", err, "id", id)
+		return nil, err
+	}
+
+	s.logger.Info("Order metadata updated successfully", "id", id)
+
+	responseItems := make([]OrderItem, len(order.Items))
+	for i, item := range order.Items {
+		responseItems[i] = OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+	}
+
+	return &OrderResponse{
+		ID:                order.ID,
+		UserID:            order.UserID,
+		Status:            order.Status,
+		Items:             responseItems,
+		PaymentAdjustment: newOrderPaymentAdjustment(order),
+		Discount:          order.DiscountAmount,
+		ShippingFee:       order.ShippingFee,
+		Total:             order.TotalAmount,
+		Paid:              order.PaidAmount,
+		Metadata:          order.Metadata,
+		Channel:           order.Channel,
+		PlacedBy:          order.PlacedBy,
+		OrganizationID:    order.OrganizationID,
+		OnAccount:         order.OnAccount,
+		InvoiceDueAt:      order.InvoiceDueAt,
+		CreditHold:        order.CreditHold,
+		PromisedShipBy:    order.PromisedShipBy,
+		ShippingAddress:   order.ShippingAddress,
+		DeliverySlotID:    order.DeliverySlotID,
+		Gift:              newOrderGiftOptions(order),
+	}, nil
+}
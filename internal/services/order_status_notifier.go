@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+)
+
+// orderStatusNotificationTypes lists the statuses customers can be notified about.
+// Titles and bodies come from the i18n catalogs under notification.<type>.
+var orderStatusNotificationTypes = map[models.OrderStatus]models.NotificationType{
+	models.OrderStatusConfirmed: models.NotificationTypeOrderConfirmed,
+	models.OrderStatusShipped:   models.NotificationTypeOrderShipped,
+	models.OrderStatusDelivered: models.NotificationTypeOrderDelivered,
+	models.OrderStatusCancelled: models.NotificationTypeOrderCancelled,
+}
+
+// OrderStatusNotificationSettings selects which status changes notify the customer
+// and on which channels
+type OrderStatusNotificationSettings struct {
+	Statuses []models.OrderStatus
+	Channels []models.NotificationChannel
+}
+
+// NewOrderStatusNotificationSettings builds settings from configured names, rejecting
+// statuses without a customer notification and unknown channels
+func NewOrderStatusNotificationSettings(statuses, channels []string) (OrderStatusNotificationSettings, error) {
+	var settings OrderStatusNotificationSettings
+	for _, status := range statuses {
+		orderStatus := models.OrderStatus(status)
+		if _, ok := orderStatusNotificationTypes[orderStatus]; !ok {
+			return settings, fmt.Errorf("order status %q has no customer notification", status)
+		}
+		settings.Statuses = append(settings.Statuses, orderStatus)
+	}
+	for _, channel := range channels {
+		notificationChannel, err := parseNotificationChannel(channel)
+		if err != nil {
+			return settings, err
+		}
+		settings.Channels = append(settings.Channels, notificationChannel)
+	}
+	return settings, nil
+}
+
+// orderStatusNotifier implements OrderStatusNotifier interface
+type orderStatusNotifier struct {
+	settings      OrderStatusNotificationSettings
+	notifications NotificationService
+	userRepo      repository.UserRepository
+	translator    *i18n.Translator
+	logger        *logger.Logger
+}
+
+// NewOrderStatusNotifier creates an order status notifier that sends through the notification
+// service, in the customer's locale
+func NewOrderStatusNotifier(
+	settings OrderStatusNotificationSettings,
+	notifications NotificationService,
+	userRepo repository.UserRepository,
+	translator *i18n.Translator,
+	logger *logger.Logger,
+) OrderStatusNotifier {
+	return &orderStatusNotifier{
+		settings:      settings,
+		notifications: notifications,
+		userRepo:      userRepo,
+		translator:    translator,
+		logger:        logger,
+	}
+}
+
+func (n *orderStatusNotifier) NotifyOrderStatusChange(ctx context.Context, order *models.Order, status models.OrderStatus) {
+	if !n.enabled(status) {
+		return
+	}
+	n.send(ctx, order, orderStatusNotificationTypes[status], status, nil)
+}
+
+// NotifyOrderConfirmed tells the customer that the store confirmed their paid order. It
+// is sent along with confirmation notifications.
+func (n *orderStatusNotifier) NotifyOrderConfirmed(ctx context.Context, order *models.Order) {
+	if !n.enabled(models.OrderStatusConfirmed) {
+		return
+	}
+	n.send(ctx, order, models.NotificationTypeOrderConfirmed, order.Status, nil)
+}
+
+// NotifyOrderItemsCancelled tells the customer that some items of their order were
+// cancelled. It is sent along with cancellation notifications.
+func (n *orderStatusNotifier) NotifyOrderItemsCancelled(ctx context.Context, order *models.Order) {
+	if !n.enabled(models.OrderStatusCancelled) {
+		return
+	}
+	n.send(ctx, order, models.NotificationTypeOrderItemsCancelled, order.Status, nil)
+}
+
+// NotifyOrderRefunded tells the customer that amount was refunded for their order. It
+// is sent along with cancellation notifications.
+func (n *orderStatusNotifier) NotifyOrderRefunded(ctx context.Context, order *models.Order, amount float64) {
+	if !n.enabled(models.OrderStatusCancelled) {
+		return
+	}
+	formatted := fmt.Sprintf("%.2f", amount)
+	n.send(ctx, order, models.NotificationTypeOrderRefunded, order.Status, map[string]string{"amount": formatted})
+}
+
+// send notifies the customer of an order on each configured channel, with the title
+// and body of notificationType in their locale. Extra values are passed to the
+// messages and included in the notification data.
+func (n *orderStatusNotifier) send(ctx context.Context, order *models.Order, notificationType models.NotificationType, status models.OrderStatus, extra map[string]string) {
+	fields := map[string]string{
+		"order_id": order.ID,
+		"status":   string(status),
+	}
+	if order.IsGift {
+		fields["gift"] = "true"
+	}
+	params := map[string]string{"order_id": order.ID}
+	for key, value := range extra {
+		fields[key] = value
+		params[key] = value
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		n.logger.Warn("Failed to encode order status notification data", "error", err, "order_id", order.ID)
+		return
+	}
+
+	locale := n.customerLocale(ctx, order.UserID)
+	title := n.translator.Translate(locale, "notification."+string(notificationType)+".title", params)
+	bodyKey := "notification." + string(notificationType) + ".body"
+	// Gift orders get their own wording where the catalogs have one for the status
+	if giftBodyKey := "notification." + string(notificationType) + ".gift_body"; order.IsGift && n.translator.HasMessage(i18n.DefaultLocale, giftBodyKey) {
+		bodyKey = giftBodyKey
+	}
+	body := n.translator.Translate(locale, bodyKey, params)
+
+	// Notifications are best-effort and must never fail the status change
+	for _, channel := range n.settings.Channels {
+		err := n.notifications.SendNotification(ctx, SendNotificationRequest{
+			UserID:  order.UserID,
+			Type:    string(notificationType),
+			Channel: string(channel),
+			Title:   title,
+			Body:    body,
+			Data:    string(data),
+		})
+		if err != nil {
+			n.logger.Warn("Failed to send order status notification",
+				"error", err, "order_id", order.ID, "type", notificationType, "channel", channel)
+		}
+	}
+}
+
+// customerLocale returns the locale stored for the customer. The request locale belongs
+// to whoever changed the status, so it is not used.
+func (n *orderStatusNotifier) customerLocale(ctx context.Context, userID string) string {
+	user, err := n.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil || user.Locale == "" {
+		return i18n.DefaultLocale
+	}
+	return user.Locale
+}
+
+// enabled reports whether customers are notified when an order moves to status
+func (n *orderStatusNotifier) enabled(status models.OrderStatus) bool {
+	for _, s := range n.settings.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyOrderConfirmed notifies the customer of a confirmed paid order when a notifier is configured
+func notifyOrderConfirmed(ctx context.Context, notifier OrderStatusNotifier, order *models.Order) {
+	if notifier == nil {
+		return
+	}
+	notifier.NotifyOrderConfirmed(ctx, order)
+}
+
+// notifyOrderItemsCancelled notifies the customer of cancelled items when a notifier is configured
+func notifyOrderItemsCancelled(ctx context.Context, notifier OrderStatusNotifier, order *models.Order) {
+	if notifier == nil {
+		return
+	}
+	notifier.NotifyOrderItemsCancelled(ctx, order)
+}
+
+// notifyOrderRefunded notifies the customer of a refund when a notifier is configured
+func notifyOrderRefunded(ctx context.Context, notifier OrderStatusNotifier, order *models.Order, amount float64) {
+	if notifier == nil || amount <= 0 {
+		return
+	}
+	notifier.NotifyOrderRefunded(ctx, order, amount)
+}
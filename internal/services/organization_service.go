@@ -0,0 +1,444 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// organizationService implements OrganizationService interface
+type organizationService struct {
+	organizationRepo repository.OrganizationRepository
+	userRepo         repository.UserRepository
+	orderRepo        repository.OrderRepository
+	logger           *logger.Logger
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(
+	organizationRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	orderRepo repository.OrderRepository,
+	logger *logger.Logger,
+) OrganizationService {
+	return &organizationService{
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		orderRepo:        orderRepo,
+		logger:           logger,
+	}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, req CreateOrganizationRequest) (*OrganizationResponse, error) {
+	s.logger.Info("Creating organization", "name", req.Name)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.NewValidationError("organization name is required")
+	}
+	if req.CreditLimit < 0 || req.PaymentTermsDays < 0 {
+		return nil, errors.NewValidationError("invalid credit terms: credit limit and payment terms must not be negative")
+	}
+
+	existing, err := s.organizationRepo.GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to check existing organization", "error", err, "name", name)
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewDuplicateError("organization", "name", name)
+	}
+
+	if req.CreditLimitAction == "" {
+		req.CreditLimitAction = models.CreditLimitActionReject
+	}
+	if !req.CreditLimitAction.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid credit limit action %s", req.CreditLimitAction))
+	}
+
+	organization := &models.Organization{
+		Name:              name,
+		CreditLimit:       req.CreditLimit,
+		CreditLimitAction: req.CreditLimitAction,
+		PaymentTermsDays:  req.PaymentTermsDays,
+		IsActive:          true,
+	}
+	if err := s.organizationRepo.Create(ctx, organization); err != nil {
+		s.logger.Error("Failed to create organization", "error", err, "name", name)
+		return nil, err
+	}
+
+	s.logger.Info("Organization created successfully", "id", organization.ID, "name", name)
+	return newOrganizationResponse(organization, nil), nil
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context, id string) (*OrganizationResponse, error) {
+	s.logger.Debug("Getting organization", "id", id)
+
+	organization, err := s.getOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.userRepo.ListByOrganization(ctx, organization.ID)
+	if err != nil {
+		s.logger.Error("Failed to list organization members", "error", err, "id", id)
+		return nil, err
+	}
+
+	outstanding, err := s.orderRepo.GetOutstandingBalance(ctx, organization.ID)
+	if err != nil {
+		s.logger.Error("Failed to get organization outstanding balance", "error", err, "id", id)
+		return nil, err
+	}
+
+	response := newOrganizationResponse(organization, members)
+	available := organization.AvailableCredit(outstanding)
+	response.OutstandingBalance = &outstanding
+	response.AvailableCredit = &available
+	return response, nil
+}
+
+func (s *organizationService) UpdateOrganization(ctx context.Context, id string, req UpdateOrganizationRequest) (*OrganizationResponse, error) {
+	s.logger.Info("Updating organization", "id", id)
+
+	organization, err := s.getOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.NewValidationError("organization name is required")
+		}
+		if name != organization.Name {
+			existing, err := s.organizationRepo.GetByName(ctx, name)
+			if err != nil {
+				s.logger.Error("Failed to check existing organization", "error", err, "name", name)
+				return nil, err
+			}
+			if existing != nil {
+				return nil, errors.NewDuplicateError("organization", "name", name)
+			}
+			organization.Name = name
+		}
+	}
+	if req.CreditLimit != nil {
+		if *req.CreditLimit < 0 {
+			return nil, errors.NewValidationError("invalid credit limit: must not be negative")
+		}
+		organization.CreditLimit = *req.CreditLimit
+	}
+	if req.PaymentTermsDays != nil {
+		if *req.PaymentTermsDays < 0 {
+			return nil, errors.NewValidationError("invalid payment terms: must not be negative")
+		}
+		organization.PaymentTermsDays = *req.PaymentTermsDays
+	}
+	if req.CreditLimitAction != nil {
+		if !req.CreditLimitAction.IsValid() {
+			return nil, errors.NewValidationError(fmt.Sprintf("invalid credit limit action %s", *req.CreditLimitAction))
+		}
+		organization.CreditLimitAction = *req.CreditLimitAction
+	}
+	if req.IsActive != nil {
+		organization.IsActive = *req.IsActive
+	}
+
+	if err := s.organizationRepo.Update(ctx, organization); err != nil {
+		s.logger.Error("Failed to update organization", "error", err, "id", id)
+		return nil, err
+	}
+
+	s.logger.Info("Organization updated successfully", "id", id)
+	return s.GetOrganization(ctx, id)
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, req ListOrganizationsRequest) (*ListOrganizationsResponse, error) {
+	s.logger.Debug("Listing organizations", "page", req.Page, "limit", req.Limit)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	organizations, err := s.organizationRepo.List(ctx, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list organizations", "error", err)
+		return nil, err
+	}
+
+	total, err := s.organizationRepo.Count(ctx)
+	if err != nil {
+		s.logger.Error("Failed to count organizations", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*OrganizationResponse, len(organizations))
+	for i, organization := range organizations {
+		responses[i] = newOrganizationResponse(organization, nil)
+	}
+
+	return &ListOrganizationsResponse{
+		Organizations: responses,
+		Page:          page,
+		Limit:         limit,
+		Total:         int(total),
+	}, nil
+}
+
+func (s *organizationService) AddMember(ctx context.Context, organizationID, userID string) (*OrganizationResponse, error) {
+	s.logger.Info("Adding organization member", "organization_id", organizationID, "user_id", userID)
+
+	organization, err := s.getOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.OrganizationID != nil {
+		if *user.OrganizationID == organization.ID {
+			return s.GetOrganization(ctx, organization.ID)
+		}
+		return nil, errors.NewConflictError(fmt.Sprintf("user %s already belongs to another organization", userID))
+	}
+
+	user.OrganizationID = &organization.ID
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to add organization member", "error", err, "organization_id", organizationID, "user_id", userID)
+		return nil, err
+	}
+
+	s.logger.Info("Organization member added", "organization_id", organizationID, "user_id", userID)
+	return s.GetOrganization(ctx, organization.ID)
+}
+
+// RemoveMember detaches a user from the organization. Orders the user already
+// placed stay with the organization.
+func (s *organizationService) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	s.logger.Info("Removing organization member", "organization_id", organizationID, "user_id", userID)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.OrganizationID == nil || *user.OrganizationID != organizationID {
+		return errors.NewNotFoundErrorWithID("organization member", userID)
+	}
+
+	user.OrganizationID = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to remove organization member", "error", err, "organization_id", organizationID, "user_id", userID)
+		return err
+	}
+
+	s.logger.Info("Organization member removed", "organization_id", organizationID, "user_id", userID)
+	return nil
+}
+
+func (s *organizationService) GetUserOrganization(ctx context.Context, userID string) (*OrganizationResponse, error) {
+	s.logger.Debug("Getting user organization", "user_id", userID)
+
+	organizationID, err := s.userOrganizationID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrganization(ctx, organizationID)
+}
+
+// ListOrganizationOrders lists the orders placed by every member of the user's organization
+func (s *organizationService) ListOrganizationOrders(ctx context.Context, userID string, req ListOrdersRequest) (*ListOrdersResponse, error) {
+	s.logger.Debug("Listing organization orders", "user_id", userID, "page", req.Page, "limit", req.Limit)
+
+	organizationID, err := s.userOrganizationID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	orders, err := s.orderRepo.ListByOrganization(ctx, organizationID, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list organization orders", "error", err, "organization_id", organizationID)
+		return nil, err
+	}
+
+	total, err := s.orderRepo.CountByOrganization(ctx, organizationID)
+	if err != nil {
+		s.logger.Error("Failed to count organization orders", "error", err, "organization_id", organizationID)
+		return nil, err
+	}
+
+	responses := make([]*OrderResponse, len(orders))
+	for i, order := range orders {
+		responses[i] = newOrderListResponse(order)
+	}
+
+	return &ListOrdersResponse{
+		Orders: responses,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+// ListCreditHolds lists the on-account orders waiting for a credit review, oldest first
+func (s *organizationService) ListCreditHolds(ctx context.Context) ([]*OrderResponse, error) {
+	s.logger.Debug("Listing orders on credit hold")
+
+	orders, err := s.orderRepo.ListCreditHolds(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list orders on credit hold", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*OrderResponse, len(orders))
+	for i, order := range orders {
+		responses[i] = newOrderListResponse(order)
+	}
+	return responses, nil
+}
+
+// ReviewCreditHold releases an order held over its organization's credit limit.
+// Approving overrides the limit and lets the order proceed; rejecting cancels it.
+func (s *organizationService) ReviewCreditHold(ctx context.Context, orderID, reviewerID string, req ReviewCreditHoldRequest) (*OrderResponse, error) {
+	s.logger.Info("Reviewing order credit hold", "order_id", orderID, "reviewer_id", reviewerID, "decision", req.Decision)
+
+	if orderID == "" {
+		return nil, errors.NewValidationError("order ID is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for credit review", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if !order.CreditHold {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is not on credit hold", orderID))
+	}
+
+	var status models.OrderStatus
+	switch req.Decision {
+	case CreditReviewApprove:
+		status = order.Status
+	case CreditReviewReject:
+		status = models.OrderStatusCancelled
+	default:
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid credit review decision %s", req.Decision))
+	}
+
+	// The hold is only cleared once, so concurrent reviews cannot both apply
+	reviewed, err := s.orderRepo.ReviewCreditHold(ctx, orderID, reviewerID, status)
+	if err != nil {
+		s.logger.Error("Failed to review order credit hold", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if !reviewed {
+		return nil, errors.NewConflictError(fmt.Sprintf("order %s is not on credit hold", orderID))
+	}
+
+	s.logger.Info("Order credit hold reviewed",
+		"order_id", orderID,
+		"organization_id", order.OrganizationID,
+		"reviewer_id", reviewerID,
+		"decision", req.Decision,
+		"total", order.TotalAmount)
+
+	updated, err := s.orderRepo.GetByIDWithItems(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get reviewed order", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	return newOrderListResponse(updated), nil
+}
+
+// getOrganization loads an organization, or returns a not found error
+func (s *organizationService) getOrganization(ctx context.Context, id string) (*models.Organization, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("organization ID is required")
+	}
+
+	organization, err := s.organizationRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get organization", "error", err, "id", id)
+		return nil, err
+	}
+	if organization == nil {
+		return nil, errors.NewNotFoundErrorWithID("organization", id)
+	}
+	return organization, nil
+}
+
+// getUser loads a user, or returns a not found error
+func (s *organizationService) getUser(ctx context.Context, id string) (*models.User, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err, "id", id)
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundErrorWithID("user", id)
+	}
+	return user, nil
+}
+
+// userOrganizationID returns the organization the user belongs to
+func (s *organizationService) userOrganizationID(ctx context.Context, userID string) (string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.OrganizationID == nil {
+		return "", errors.NewNotFoundError("organization")
+	}
+	return *user.OrganizationID, nil
+}
+
+// newOrganizationResponse converts an organization and its members to a response
+func newOrganizationResponse(organization *models.Organization, members []*models.User) *OrganizationResponse {
+	response := &OrganizationResponse{
+		ID:                organization.ID,
+		Name:              organization.Name,
+		CreditLimit:       organization.CreditLimit,
+		CreditLimitAction: organization.CreditLimitAction,
+		PaymentTermsDays:  organization.PaymentTermsDays,
+		PriceListID:       organization.PriceListID,
+		IsActive:          organization.IsActive,
+		CreatedAt:         organization.CreatedAt,
+	}
+	for _, member := range members {
+		response.Members = append(response.Members, OrganizationMember{
+			ID:    member.ID,
+			Email: member.Email,
+			Name:  member.Name,
+		})
+	}
+	return response
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"easy-orders-backend/internal/models"
+)
+
+// PaymentMethodAdjustments maps a payment method to the percentage of the order subtotal
+// added at checkout: positive rates are surcharges, negative rates are discounts.
+// A nil map applies no adjustments.
+type PaymentMethodAdjustments map[models.PaymentMethod]float64
+
+// NewPaymentMethodAdjustments builds adjustments from configured rates, rejecting unknown methods
+func NewPaymentMethodAdjustments(rates map[string]float64) (PaymentMethodAdjustments, error) {
+	adjustments := make(PaymentMethodAdjustments, len(rates))
+	for method, rate := range rates {
+		paymentMethod := models.PaymentMethod(method)
+		if !paymentMethod.IsValid() {
+			return nil, fmt.Errorf("unknown payment method %q in payment method adjustments", method)
+		}
+		adjustments[paymentMethod] = rate
+	}
+	return adjustments, nil
+}
+
+// Apply returns the rate for the method and the adjustment on subtotal, rounded to cents
+func (a PaymentMethodAdjustments) Apply(method models.PaymentMethod, subtotal float64) (rate, amount float64) {
+	rate = a[method]
+	if rate == 0 {
+		return 0, 0
+	}
+	return rate, math.Round(subtotal*rate) / 100
+}
+
+// newOrderPaymentAdjustment itemizes the checkout payment method adjustment of an order
+func newOrderPaymentAdjustment(order *models.Order) *OrderPaymentAdjustment {
+	if order.PaymentMethod == "" {
+		return nil
+	}
+	return &OrderPaymentAdjustment{
+		PaymentMethod: order.PaymentMethod,
+		Subtotal:      order.Subtotal(),
+		Rate:          order.PaymentAdjustmentRate,
+		Amount:        order.PaymentAdjustment,
+	}
+}
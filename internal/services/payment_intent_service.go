@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// processingIntentBatchSize bounds how many processing intents are settled per sweep
+const processingIntentBatchSize = 50
+
+// PaymentIntentSettings configures payment intents, which expire TTL after they were
+// created unless confirmed
+type PaymentIntentSettings struct {
+	TTL time.Duration
+}
+
+// paymentIntentService implements PaymentIntentService interface
+type paymentIntentService struct {
+	settings       PaymentIntentSettings
+	intentRepo     repository.PaymentIntentRepository
+	orderRepo      repository.OrderRepository
+	paymentRepo    repository.PaymentRepository
+	paymentService PaymentService
+	logger         *logger.Logger
+}
+
+// NewPaymentIntentService creates a new payment intent service
+func NewPaymentIntentService(
+	settings PaymentIntentSettings,
+	intentRepo repository.PaymentIntentRepository,
+	orderRepo repository.OrderRepository,
+	paymentRepo repository.PaymentRepository,
+	paymentService PaymentService,
+	logger *logger.Logger,
+) PaymentIntentService {
+	return &paymentIntentService{
+		settings:       settings,
+		intentRepo:     intentRepo,
+		orderRepo:      orderRepo,
+		paymentRepo:    paymentRepo,
+		paymentService: paymentService,
+		logger:         logger,
+	}
+}
+
+// CreateIntent opens a card payment of one of the user's orders, for what is left to
+// pay of it unless a smaller amount is asked for. An order has at most one open intent.
+func (s *paymentIntentService) CreateIntent(ctx context.Context, userID string, req CreatePaymentIntentRequest) (*PaymentIntentResponse, error) {
+	s.logger.Info("Creating payment intent", "user_id", userID, "order_id", req.OrderID, "method", req.PaymentType)
+
+	method := models.PaymentMethod(req.PaymentType)
+	if !method.IsCard() {
+		return nil, errors.NewValidationErrorWithDetails("invalid payment type", "payment intents take credit_card or debit_card payments")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, req.OrderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for payment intent", "error", err, "order_id", req.OrderID)
+		return nil, errors.NewDatabaseError("failed to get order", err)
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.NewNotFoundErrorWithID("order", req.OrderID)
+	}
+
+	// The order total includes the surcharge or discount for the checkout payment method
+	if order.PaymentMethod != "" && method != order.PaymentMethod {
+		return nil, errors.NewValidationError(fmt.Sprintf("payment type %s does not match checkout payment method %s", method, order.PaymentMethod))
+	}
+	if !order.IsPayable() {
+		return nil, errors.NewBusinessError(fmt.Sprintf("order in status %s cannot be paid", order.Status))
+	}
+
+	paid, err := s.paymentRepo.SumCompletedByOrderID(ctx, order.ID)
+	if err != nil {
+		s.logger.Error("Failed to sum completed payments", "error", err, "order_id", order.ID)
+		return nil, errors.NewDatabaseError("failed to sum completed payments", err)
+	}
+	balance := roundCents(order.TotalAmount - paid)
+	if balance <= 0 {
+		return nil, errors.NewBusinessError("order has already been paid")
+	}
+	amount := balance
+	if req.Amount > 0 {
+		amount = roundCents(req.Amount)
+	}
+	if amount > balance {
+		return nil, errors.NewValidationError(fmt.Sprintf("amount %.2f exceeds the %.2f left to pay of order total %.2f",
+			amount, balance, order.TotalAmount))
+	}
+
+	now := time.Now()
+	intent := &models.PaymentIntent{
+		OrderID:   order.ID,
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  "USD",
+		Method:    method,
+		Status:    models.PaymentIntentStatusRequiresConfirmation,
+		ExpiresAt: now.Add(s.settings.TTL),
+	}
+	created, err := s.intentRepo.Create(ctx, intent, now)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundErrorWithID("order", order.ID)
+		}
+		return nil, errors.NewDatabaseError("failed to create payment intent", err)
+	}
+	if !created {
+		return nil, errors.NewConflictError("order already has an open payment intent")
+	}
+
+	s.logger.Info("Payment intent created", "id", intent.ID, "order_id", order.ID, "amount", amount,
+		"expires_at", intent.ExpiresAt)
+	return toPaymentIntentResponse(intent, nil), nil
+}
+
+// GetIntent returns one of the user's intents, brought up to date with its payment
+func (s *paymentIntentService) GetIntent(ctx context.Context, userID, id string) (*PaymentIntentResponse, error) {
+	s.logger.Debug("Getting payment intent", "id", id, "user_id", userID)
+
+	intent, err := s.getUserIntent(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.refresh(ctx, intent)
+	if err != nil {
+		return nil, err
+	}
+	return toPaymentIntentResponse(intent, payment), nil
+}
+
+// ConfirmIntent takes the intent's payment with the card token the gateway's client
+// SDK returned. A completed payment succeeds the intent and pays the order once its
+// payments cover the total; a payment held for retry or awaiting the gateway leaves it
+// processing until the payment settles. A declined or refused payment returns the
+// intent to awaiting confirmation, to be confirmed again with another card.
+func (s *paymentIntentService) ConfirmIntent(ctx context.Context, userID, id string, req ConfirmPaymentIntentRequest) (*PaymentIntentResponse, error) {
+	s.logger.Info("Confirming payment intent", "id", id, "user_id", userID)
+
+	intent, err := s.getUserIntent(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if intent.IsLapsed(now) {
+		s.expire(ctx, intent)
+	}
+	if intent.Status != models.PaymentIntentStatusRequiresConfirmation {
+		return nil, errors.NewInvalidTransitionError(string(intent.Status), string(models.PaymentIntentStatusProcessing))
+	}
+
+	claimed, err := s.intentRepo.Claim(ctx, intent, now)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to confirm payment intent", err)
+	}
+	if !claimed {
+		return nil, errors.NewConflictError("payment intent was confirmed, cancelled or expired concurrently")
+	}
+
+	payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+		OrderID:           intent.OrderID,
+		Amount:            intent.Amount,
+		PaymentType:       string(intent.Method),
+		ExternalReference: strings.TrimSpace(req.ExternalReference),
+		CardFingerprint:   req.CardFingerprint,
+		ClientIP:          req.ClientIP,
+	})
+	if err != nil {
+		s.logger.Warn("Payment intent confirmation failed", "error", err, "id", intent.ID, "order_id", intent.OrderID)
+		intent.Status = models.PaymentIntentStatusRequiresConfirmation
+		intent.LastError = err.Error()
+		s.transition(ctx, intent, models.PaymentIntentStatusProcessing)
+		return nil, confirmationError(err)
+	}
+
+	intent.PaymentID = &payment.ID
+	intent.Status = paymentIntentStatus(payment.Status)
+	s.transition(ctx, intent, models.PaymentIntentStatusProcessing)
+
+	s.logger.Info("Payment intent confirmed", "id", intent.ID, "order_id", intent.OrderID,
+		"payment_id", payment.ID, "status", intent.Status)
+	return toPaymentIntentResponse(intent, payment), nil
+}
+
+// CancelIntent cancels one of the user's intents before it is confirmed
+func (s *paymentIntentService) CancelIntent(ctx context.Context, userID, id string) (*PaymentIntentResponse, error) {
+	s.logger.Info("Cancelling payment intent", "id", id, "user_id", userID)
+
+	intent, err := s.getUserIntent(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if intent.IsLapsed(time.Now()) {
+		s.expire(ctx, intent)
+	}
+	if intent.Status != models.PaymentIntentStatusRequiresConfirmation {
+		return nil, errors.NewInvalidTransitionError(string(intent.Status), string(models.PaymentIntentStatusCancelled))
+	}
+
+	intent.Status = models.PaymentIntentStatusCancelled
+	cancelled, err := s.intentRepo.Transition(ctx, intent, models.PaymentIntentStatusRequiresConfirmation)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to cancel payment intent", err)
+	}
+	if !cancelled {
+		return nil, errors.NewConflictError("payment intent was confirmed or expired concurrently")
+	}
+
+	s.logger.Info("Payment intent cancelled", "id", intent.ID, "order_id", intent.OrderID)
+	return toPaymentIntentResponse(intent, nil), nil
+}
+
+func (s *paymentIntentService) SweepIntents(ctx context.Context) (int, error) {
+	intents, err := s.intentRepo.ListProcessing(ctx, processingIntentBatchSize)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to list processing payment intents", err)
+	}
+
+	swept := 0
+	for _, intent := range intents {
+		if _, err := s.refresh(ctx, intent); err != nil {
+			s.logger.Warn("Failed to settle payment intent", "error", err, "id", intent.ID)
+			continue
+		}
+		if intent.Status != models.PaymentIntentStatusProcessing {
+			swept++
+		}
+	}
+
+	expired, err := s.intentRepo.ExpireLapsed(ctx, time.Now())
+	if err != nil {
+		return swept, errors.NewDatabaseError("failed to expire payment intents", err)
+	}
+	swept += expired
+
+	if swept > 0 {
+		s.logger.Info("Payment intents swept", "settled", swept-expired, "expired", expired)
+	}
+	return swept, nil
+}
+
+// getUserIntent returns one of the user's intents; other users' intents are not found
+func (s *paymentIntentService) getUserIntent(ctx context.Context, userID, id string) (*models.PaymentIntent, error) {
+	intent, err := s.intentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get payment intent", err)
+	}
+	if intent == nil || intent.UserID != userID {
+		return nil, errors.NewNotFoundErrorWithID("payment intent", id)
+	}
+	return intent, nil
+}
+
+// refresh brings an intent up to date: a lapsed intent expires, and a processing one
+// follows its payment once the payment completed or failed. It returns the intent's
+// payment, if any.
+func (s *paymentIntentService) refresh(ctx context.Context, intent *models.PaymentIntent) (*PaymentResponse, error) {
+	if intent.IsLapsed(time.Now()) {
+		s.expire(ctx, intent)
+	}
+	if intent.PaymentID == nil {
+		return nil, nil
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, *intent.PaymentID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get payment", err)
+	}
+	if payment == nil {
+		return nil, nil
+	}
+
+	if intent.Status == models.PaymentIntentStatusProcessing {
+		switch status := paymentIntentStatus(payment.Status); status {
+		case models.PaymentIntentStatusSucceeded:
+			intent.Status = status
+			s.transition(ctx, intent, models.PaymentIntentStatusProcessing)
+		case models.PaymentIntentStatusRequiresConfirmation:
+			intent.Status = status
+			intent.LastError = payment.FailureReason
+			s.transition(ctx, intent, models.PaymentIntentStatusProcessing)
+		}
+	}
+	return toPaymentResponse(payment), nil
+}
+
+// expire expires a lapsed intent. An intent confirmed or cancelled concurrently is
+// reloaded instead.
+func (s *paymentIntentService) expire(ctx context.Context, intent *models.PaymentIntent) {
+	intent.Status = models.PaymentIntentStatusExpired
+	s.transition(ctx, intent, models.PaymentIntentStatusRequiresConfirmation)
+}
+
+// transition saves an intent that was in status from. Saving is best effort: an intent
+// that moved on concurrently is reloaded, and one that could not be saved is settled
+// by the next sweep.
+func (s *paymentIntentService) transition(ctx context.Context, intent *models.PaymentIntent, from models.PaymentIntentStatus) {
+	saved, err := s.intentRepo.Transition(ctx, intent, from)
+	if err != nil {
+		s.logger.Error("Failed to save payment intent", "error", err, "id", intent.ID, "status", intent.Status)
+		return
+	}
+	if saved {
+		return
+	}
+
+	current, err := s.intentRepo.GetByID(ctx, intent.ID)
+	if err != nil || current == nil {
+		s.logger.Error("Failed to reload payment intent", "error", err, "id", intent.ID)
+		return
+	}
+	*intent = *current
+}
+
+// paymentIntentStatus returns the status of an intent whose payment is in status
+func paymentIntentStatus(status models.PaymentStatus) models.PaymentIntentStatus {
+	switch status {
+	case models.PaymentStatusCompleted, models.PaymentStatusRefunded:
+		return models.PaymentIntentStatusSucceeded
+	case models.PaymentStatusFailed, models.PaymentStatusCancelled:
+		return models.PaymentIntentStatusRequiresConfirmation
+	default:
+		return models.PaymentIntentStatusProcessing
+	}
+}
+
+// confirmationError maps an error taking an intent's payment to the error reported
+// to the customer
+func confirmationError(err error) error {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr
+	}
+
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "processing failed"):
+		return errors.NewPaymentFailedError(strings.TrimPrefix(message, "payment processing failed: "))
+	case strings.Contains(message, "held for retry"), strings.Contains(message, "awaiting confirmation"):
+		return errors.NewConflictError(message)
+	case strings.Contains(message, "cannot be paid"), strings.Contains(message, "already been paid"),
+		strings.Contains(message, "left to pay"), strings.Contains(message, "does not match"):
+		return errors.NewBusinessError(message)
+	default:
+		return errors.NewDatabaseError("failed to take payment", err)
+	}
+}
+
+// toPaymentIntentResponse converts an intent to its response format, with its payment
+func toPaymentIntentResponse(intent *models.PaymentIntent, payment *PaymentResponse) *PaymentIntentResponse {
+	return &PaymentIntentResponse{
+		ID:          intent.ID,
+		OrderID:     intent.OrderID,
+		Amount:      intent.Amount,
+		Currency:    intent.Currency,
+		PaymentType: intent.Method,
+		Status:      intent.Status,
+		Payment:     payment,
+		LastError:   intent.LastError,
+		ExpiresAt:   intent.ExpiresAt,
+		CreatedAt:   intent.CreatedAt,
+	}
+}
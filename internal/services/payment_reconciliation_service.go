@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/pkg/reports"
+
+	"github.com/google/uuid"
+)
+
+// reconciliationBatchSize bounds how many payments a reconciliation run checks
+const reconciliationBatchSize = 200
+
+// PaymentReconciliationSettings configures which payments are reconciled: pending and
+// processed payments untouched for Threshold, rechecked at most once per Threshold,
+// and payments that failed within Lookback, checked once. A zero Lookback leaves
+// failed payments out.
+//
+// Payments of gateways without webhooks are also polled once untouched for
+// PollThreshold, and escalated to ops once still unsettled after EscalateAfter; a zero
+// EscalateAfter never escalates.
+type PaymentReconciliationSettings struct {
+	Threshold time.Duration
+	Lookback  time.Duration
+
+	PollThreshold time.Duration
+	EscalateAfter time.Duration
+}
+
+// paymentReconciliationService implements PaymentReconciliationService interface
+type paymentReconciliationService struct {
+	settings           PaymentReconciliationSettings
+	paymentRepo        repository.PaymentRepository
+	orderRepo          repository.OrderRepository
+	reconciliationRepo repository.PaymentReconciliationRepository
+	gateways           *payments.PaymentGatewayManager
+	ledger             LedgerRecorder
+	webhooks           WebhookPublisher
+	reports            *reports.ReportManager
+	verifiers          PaymentWebhookVerifiers
+	userRepo           repository.UserRepository
+	notifications      NotificationService
+	now                func() time.Time
+	logger             *logger.Logger
+}
+
+// NewPaymentReconciliationService creates a new payment reconciliation service. A nil
+// report manager skips the report of each run. Gateways with a webhook verifier are
+// left out of polling, as they report settlement themselves.
+func NewPaymentReconciliationService(
+	settings PaymentReconciliationSettings,
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	reconciliationRepo repository.PaymentReconciliationRepository,
+	gateways *payments.PaymentGatewayManager,
+	ledger LedgerRecorder,
+	webhooks WebhookPublisher,
+	reportManager *reports.ReportManager,
+	verifiers PaymentWebhookVerifiers,
+	userRepo repository.UserRepository,
+	notifications NotificationService,
+	logger *logger.Logger,
+) PaymentReconciliationService {
+	return &paymentReconciliationService{
+		settings:           settings,
+		paymentRepo:        paymentRepo,
+		orderRepo:          orderRepo,
+		reconciliationRepo: reconciliationRepo,
+		gateways:           gateways,
+		ledger:             ledger,
+		webhooks:           webhooks,
+		reports:            reportManager,
+		verifiers:          verifiers,
+		userRepo:           userRepo,
+		notifications:      notifications,
+		now:                time.Now,
+		logger:             logger,
+	}
+}
+
+// Reconcile checks the payments due for reconciliation against their gateway, records
+// what it found for each and queues the report of the run
+func (s *paymentReconciliationService) Reconcile(ctx context.Context) (*PaymentReconciliationRunResponse, error) {
+	now := s.now()
+	failedSince := now
+	if s.settings.Lookback > 0 {
+		failedSince = now.Add(-s.settings.Lookback)
+	}
+
+	due, err := s.paymentRepo.ListForReconciliation(ctx, now.Add(-s.settings.Threshold), failedSince, reconciliationBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to list payments for reconciliation", "error", err)
+		return nil, errors.NewDatabaseError("failed to list payments for reconciliation", err)
+	}
+
+	run := &PaymentReconciliationRunResponse{
+		RunID:     uuid.New().String(),
+		StartedAt: now,
+	}
+	if len(due) == 0 {
+		return run, nil
+	}
+
+	records := make([]*models.PaymentReconciliation, 0, len(due))
+	for _, payment := range due {
+		record := s.reconcile(ctx, run.RunID, payment)
+		if record == nil {
+			continue
+		}
+		records = append(records, record)
+
+		s.count(run, payment, record)
+	}
+
+	if err := s.reconciliationRepo.CreateBatch(ctx, records); err != nil {
+		s.logger.Error("Failed to record payment reconciliation", "error", err, "run_id", run.RunID)
+		return nil, errors.NewDatabaseError("failed to record payment reconciliation", err)
+	}
+
+	if s.reports != nil && len(records) > 0 {
+		result, err := s.reports.GenerateReportAsync(context.WithoutCancel(ctx), &reports.ReportRequest{
+			ID:         uuid.New().String(),
+			Type:       reports.ReportTypePaymentReconciliation,
+			Format:     reports.ReportFormatJSON,
+			Priority:   reports.ReportPriorityLow,
+			Parameters: map[string]interface{}{"run_id": run.RunID},
+			CreatedAt:  now,
+		})
+		if err != nil {
+			s.logger.Warn("Failed to queue payment reconciliation report", "error", err, "run_id", run.RunID)
+		} else {
+			run.ReportResultID = result.ID
+		}
+	}
+
+	s.logger.Info("Payments reconciled", "run_id", run.RunID, "checked", run.Checked, "corrected", run.Corrected,
+		"mismatched", run.Mismatched, "pending", run.Pending, "errors", run.Errors, "report_result_id", run.ReportResultID)
+	return run, nil
+}
+
+// Poll checks the pending and processed payments of gateways without webhooks against
+// their gateway, which is the only way they learn of settlement, and escalates to ops
+// those still unsettled after EscalateAfter. Polls are recorded like reconciliation
+// runs, without a report.
+func (s *paymentReconciliationService) Poll(ctx context.Context) (*PaymentReconciliationRunResponse, error) {
+	now := s.now()
+	run := &PaymentReconciliationRunResponse{
+		RunID:     uuid.New().String(),
+		StartedAt: now,
+	}
+
+	var gateways []string
+	for _, gateway := range s.gateways.GetAvailableGateways() {
+		if _, ok := s.verifiers[string(gateway)]; !ok {
+			gateways = append(gateways, string(gateway))
+		}
+	}
+	if len(gateways) == 0 {
+		return run, nil
+	}
+
+	due, err := s.paymentRepo.ListForPolling(ctx, gateways, now.Add(-s.settings.PollThreshold), reconciliationBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to list payments for polling", "error", err)
+		return nil, errors.NewDatabaseError("failed to list payments for polling", err)
+	}
+	if len(due) == 0 {
+		return run, nil
+	}
+
+	records := make([]*models.PaymentReconciliation, 0, len(due))
+	for _, payment := range due {
+		record := s.reconcile(ctx, run.RunID, payment)
+		if record == nil {
+			continue
+		}
+		records = append(records, record)
+		s.count(run, payment, record)
+
+		if record.Outcome != models.ReconciliationOutcomeCorrected && s.escalate(ctx, payment, now) {
+			run.Escalated++
+		}
+	}
+
+	if err := s.reconciliationRepo.CreateBatch(ctx, records); err != nil {
+		s.logger.Error("Failed to record payment polling", "error", err, "run_id", run.RunID)
+		return nil, errors.NewDatabaseError("failed to record payment polling", err)
+	}
+
+	s.logger.Info("Payments polled", "run_id", run.RunID, "gateways", gateways, "checked", run.Checked,
+		"corrected", run.Corrected, "pending", run.Pending, "errors", run.Errors, "escalated", run.Escalated)
+	return run, nil
+}
+
+// count adds the outcome of one payment's check to the run
+func (s *paymentReconciliationService) count(run *PaymentReconciliationRunResponse, payment *models.Payment, record *models.PaymentReconciliation) {
+	run.Checked++
+	switch record.Outcome {
+	case models.ReconciliationOutcomeInSync:
+		run.InSync++
+	case models.ReconciliationOutcomeCorrected:
+		run.Corrected++
+	case models.ReconciliationOutcomeMismatch:
+		run.Mismatched++
+		s.logger.Warn("Payment does not match its gateway record", "payment_id", payment.ID,
+			"status", payment.Status, "gateway", payment.Gateway, "gateway_status", record.GatewayStatus, "detail", record.Detail)
+	case models.ReconciliationOutcomePending:
+		run.Pending++
+	case models.ReconciliationOutcomeError:
+		run.Errors++
+	}
+}
+
+// escalate notifies every admin of a payment unsettled since before EscalateAfter, once
+// per payment, and reports whether it did. Admins the notification could not be sent to
+// are logged; the payment is only marked escalated once at least one was notified.
+func (s *paymentReconciliationService) escalate(ctx context.Context, payment *models.Payment, now time.Time) bool {
+	if s.settings.EscalateAfter <= 0 || s.notifications == nil || payment.EscalatedAt != nil ||
+		payment.UpdatedAt.After(now.Add(-s.settings.EscalateAfter)) {
+		return false
+	}
+
+	admins, err := s.userRepo.ListByRole(ctx, models.UserRoleAdmin)
+	if err != nil {
+		s.logger.Error("Failed to list admins to escalate stuck payment", "error", err, "payment_id", payment.ID)
+		return false
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"payment_id":  payment.ID,
+		"order_id":    payment.OrderID,
+		"gateway":     payment.Gateway,
+		"status":      payment.Status,
+		"stuck_since": payment.UpdatedAt,
+	})
+	if err != nil {
+		s.logger.Error("Failed to encode stuck payment notification", "error", err, "payment_id", payment.ID)
+		return false
+	}
+
+	stuckFor := now.Sub(payment.UpdatedAt).Round(time.Minute)
+	notified := 0
+	for _, admin := range admins {
+		if err := s.notifications.SendNotification(ctx, SendNotificationRequest{
+			UserID:  admin.ID,
+			Type:    string(models.NotificationTypeSystem),
+			Channel: string(models.NotificationChannelInApp),
+			Title:   fmt.Sprintf("Payment stuck in %s", payment.Status),
+			Body: fmt.Sprintf("Payment %s of order %s has been %s with %s for %s without the gateway settling it.",
+				payment.ID, payment.OrderID, payment.Status, payment.Gateway, stuckFor),
+			Data: string(data),
+		}); err != nil {
+			s.logger.Warn("Failed to notify admin of stuck payment", "error", err, "payment_id", payment.ID, "user_id", admin.ID)
+			continue
+		}
+		notified++
+	}
+	if notified == 0 {
+		s.logger.Warn("No admin notified of stuck payment", "payment_id", payment.ID, "admins", len(admins))
+		return false
+	}
+
+	if err := s.paymentRepo.MarkEscalated(ctx, payment.ID, now); err != nil {
+		s.logger.Warn("Failed to mark payment escalated", "error", err, "payment_id", payment.ID)
+	}
+	s.logger.Warn("Stuck payment escalated to ops", "payment_id", payment.ID, "status", payment.Status,
+		"gateway", payment.Gateway, "stuck_for", stuckFor, "admins", notified)
+	return true
+}
+
+// reconcile checks one payment against its gateway, correcting a pending or processed
+// payment the gateway settled. It returns nil when the payment changed while it was
+// checked, leaving it to the next run.
+func (s *paymentReconciliationService) reconcile(ctx context.Context, runID string, payment *models.Payment) *models.PaymentReconciliation {
+	checkedAt := s.now()
+	record := &models.PaymentReconciliation{
+		RunID:        runID,
+		PaymentID:    payment.ID,
+		OrderID:      payment.OrderID,
+		Gateway:      payment.Gateway,
+		GatewayTxnID: payment.GatewayTxnID,
+		LocalStatus:  payment.Status,
+		LocalAmount:  payment.Amount,
+		CreatedAt:    checkedAt,
+	}
+
+	gateway, ok := s.gateways.GetGateway(payments.PaymentGatewayType(payment.Gateway))
+	if !ok {
+		record.Outcome = models.ReconciliationOutcomeError
+		record.Detail = fmt.Sprintf("gateway %s is not registered", payment.Gateway)
+		s.markReconciled(ctx, payment, checkedAt)
+		return record
+	}
+
+	// A failed lookup is retried by the next run
+	status, err := gateway.GetPaymentStatus(ctx, payment.GatewayTxnID)
+	if err != nil {
+		s.logger.Warn("Failed to get payment status from gateway", "error", err, "payment_id", payment.ID, "gateway", payment.Gateway)
+		record.Outcome = models.ReconciliationOutcomeError
+		record.Detail = err.Error()
+		return record
+	}
+	record.GatewayStatus = status.Status
+	record.GatewayAmount = status.Amount
+	settled := gatewaySettlement(status.Status)
+
+	// Failed payments are only compared: money the gateway captured for a payment that
+	// failed here may have been paid again since, so an admin decides what to do
+	if payment.IsFailed() {
+		switch settled {
+		case models.PaymentStatusFailed:
+			record.Outcome = models.ReconciliationOutcomeInSync
+		case models.PaymentStatusCompleted:
+			record.Outcome = models.ReconciliationOutcomeMismatch
+			record.Detail = "gateway captured the payment but it failed locally"
+		default:
+			record.Outcome = models.ReconciliationOutcomeMismatch
+			record.Detail = fmt.Sprintf("gateway reports %s for a payment that failed locally", status.Status)
+		}
+		s.markReconciled(ctx, payment, checkedAt)
+		return record
+	}
+
+	switch settled {
+	case models.PaymentStatusCompleted:
+		if math.Abs(status.Amount-payment.Amount) >= 0.005 {
+			record.Outcome = models.ReconciliationOutcomeMismatch
+			record.Detail = fmt.Sprintf("gateway captured %.2f for a payment of %.2f", status.Amount, payment.Amount)
+			s.markReconciled(ctx, payment, checkedAt)
+			return record
+		}
+		from := payment.Status
+		payment.MarkCompleted()
+		payment.NextRetryAt = nil
+		return s.correct(ctx, record, payment, from, checkedAt)
+	case models.PaymentStatusFailed:
+		from := payment.Status
+		reason := "gateway reports the payment failed"
+		if status.FailureMessage != "" {
+			reason += ": " + status.FailureMessage
+		}
+		payment.MarkFailed(reason)
+		payment.NextRetryAt = nil
+		return s.correct(ctx, record, payment, from, checkedAt)
+	case models.PaymentStatusPending:
+		record.Outcome = models.ReconciliationOutcomePending
+	default:
+		record.Outcome = models.ReconciliationOutcomeMismatch
+		record.Detail = fmt.Sprintf("gateway reports %s for a payment that is %s locally", status.Status, payment.Status)
+	}
+	s.markReconciled(ctx, payment, checkedAt)
+	return record
+}
+
+// correct saves the status a payment was corrected to, unless it changed since it was
+// listed, and applies the side effects of settling it
+func (s *paymentReconciliationService) correct(ctx context.Context, record *models.PaymentReconciliation, payment *models.Payment, from models.PaymentStatus, checkedAt time.Time) *models.PaymentReconciliation {
+	payment.ReconciledAt = &checkedAt
+	saved, err := s.paymentRepo.Reconcile(ctx, payment, from)
+	if err != nil {
+		s.logger.Error("Failed to save reconciled payment", "error", err, "payment_id", payment.ID)
+		record.Outcome = models.ReconciliationOutcomeError
+		record.Detail = fmt.Sprintf("failed to correct status to %s: %v", payment.Status, err)
+		return record
+	}
+	if !saved {
+		s.logger.Debug("Payment changed while it was reconciled", "payment_id", payment.ID)
+		return nil
+	}
+
+	record.Outcome = models.ReconciliationOutcomeCorrected
+	record.ResolvedStatus = payment.Status
+	record.Detail = fmt.Sprintf("corrected from %s to %s", from, payment.Status)
+	s.logger.Info("Payment corrected from its gateway record", "payment_id", payment.ID, "from", from, "status", payment.Status)
+
+	if payment.IsFailed() {
+		publishPaymentFailed(ctx, s.webhooks, payment)
+		return record
+	}
+
+	// The payment was captured either way, so a ledger or order failure is logged
+	// rather than undoing the correction
+	if s.ledger != nil {
+		if err := s.ledger.RecordPayment(ctx, payment); err != nil {
+			s.logger.Error("Failed to post reconciled payment to the ledger", "error", err, "payment_id", payment.ID)
+		}
+	}
+	order, err := s.orderRepo.GetByID(ctx, payment.OrderID)
+	if err != nil || order == nil {
+		s.logger.Error("Failed to get order of reconciled payment", "error", err, "order_id", payment.OrderID)
+		return record
+	}
+	if _, err := recordOrderPayment(ctx, s.orderRepo, order); err != nil {
+		s.logger.Error("Failed to update order after reconciled payment", "error", err, "order_id", order.ID)
+	}
+	return record
+}
+
+// markReconciled records that a payment was checked, so it is not checked again before
+// it is due
+func (s *paymentReconciliationService) markReconciled(ctx context.Context, payment *models.Payment, at time.Time) {
+	if err := s.paymentRepo.MarkReconciled(ctx, payment.ID, at); err != nil {
+		s.logger.Warn("Failed to mark payment reconciled", "error", err, "payment_id", payment.ID)
+	}
+}
+
+// gatewaySettlement maps a gateway's payment status to the local status it settles
+// the payment to, or pending while the gateway is still processing it
+func gatewaySettlement(status string) models.PaymentStatus {
+	switch strings.ToLower(status) {
+	case "completed", "succeeded", "captured":
+		return models.PaymentStatusCompleted
+	case "failed", "declined", "canceled", "cancelled":
+		return models.PaymentStatusFailed
+	case "refunded":
+		return models.PaymentStatusRefunded
+	default:
+		return models.PaymentStatusPending
+	}
+}
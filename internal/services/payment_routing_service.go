@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+)
+
+// Routing decisions of a dry run
+const (
+	routingDecisionRoute    = "route"
+	routingDecisionBypass   = "bypass"
+	routingDecisionSelector = "selector"
+)
+
+// paymentRoutingService implements PaymentRoutingService interface
+type paymentRoutingService struct {
+	ruleRepo repository.PaymentRoutingRuleRepository
+	gateways *payments.PaymentGatewayManager
+	logger   *logger.Logger
+}
+
+// NewPaymentRoutingService creates a new payment routing service
+func NewPaymentRoutingService(
+	ruleRepo repository.PaymentRoutingRuleRepository,
+	gateways *payments.PaymentGatewayManager,
+	logger *logger.Logger,
+) PaymentRoutingService {
+	return &paymentRoutingService{
+		ruleRepo: ruleRepo,
+		gateways: gateways,
+		logger:   logger,
+	}
+}
+
+func (s *paymentRoutingService) LoadRules(ctx context.Context) error {
+	rules, err := s.ruleRepo.List(ctx, true)
+	if err != nil {
+		s.logger.Error("Failed to load payment routing rules", "error", err)
+		return errors.NewDatabaseError("failed to load payment routing rules", err)
+	}
+	s.gateways.SetRoutingRules(toRoutingRules(rules))
+	return nil
+}
+
+func (s *paymentRoutingService) ListRules(ctx context.Context) ([]*models.PaymentRoutingRule, error) {
+	s.logger.Debug("Listing payment routing rules")
+
+	rules, err := s.ruleRepo.List(ctx, false)
+	if err != nil {
+		s.logger.Error("Failed to list payment routing rules", "error", err)
+		return nil, errors.NewDatabaseError("failed to list payment routing rules", err)
+	}
+	return rules, nil
+}
+
+func (s *paymentRoutingService) GetRule(ctx context.Context, id string) (*models.PaymentRoutingRule, error) {
+	s.logger.Debug("Getting payment routing rule", "id", id)
+
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get payment routing rule", "error", err, "id", id)
+		return nil, errors.NewDatabaseError("failed to get payment routing rule", err)
+	}
+	if rule == nil {
+		return nil, errors.NewNotFoundErrorWithID("payment routing rule", id)
+	}
+	return rule, nil
+}
+
+func (s *paymentRoutingService) CreateRule(ctx context.Context, req PaymentRoutingRuleRequest) (*models.PaymentRoutingRule, error) {
+	s.logger.Info("Creating payment routing rule", "name", req.Name, "action", req.Action)
+
+	rule := &models.PaymentRoutingRule{}
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, rule.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		s.logger.Error("Failed to create payment routing rule", "error", err, "name", rule.Name)
+		return nil, errors.NewDatabaseError("failed to create payment routing rule", err)
+	}
+	s.reloadRules(ctx)
+
+	s.logger.Info("Payment routing rule created successfully", "id", rule.ID, "name", rule.Name)
+	return rule, nil
+}
+
+func (s *paymentRoutingService) UpdateRule(ctx context.Context, id string, req PaymentRoutingRuleRequest) (*models.PaymentRoutingRule, error) {
+	s.logger.Info("Updating payment routing rule", "id", id)
+
+	rule, err := s.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousName := rule.Name
+	if err := s.applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if rule.Name != previousName {
+		if err := s.checkNameAvailable(ctx, rule.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		s.logger.Error("Failed to update payment routing rule", "error", err, "id", id)
+		return nil, errors.NewDatabaseError("failed to update payment routing rule", err)
+	}
+	s.reloadRules(ctx)
+
+	s.logger.Info("Payment routing rule updated successfully", "id", id)
+	return rule, nil
+}
+
+func (s *paymentRoutingService) DeleteRule(ctx context.Context, id string) error {
+	s.logger.Info("Deleting payment routing rule", "id", id)
+
+	if _, err := s.GetRule(ctx, id); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete payment routing rule", "error", err, "id", id)
+		return errors.NewDatabaseError("failed to delete payment routing rule", err)
+	}
+	s.reloadRules(ctx)
+
+	s.logger.Info("Payment routing rule deleted successfully", "id", id)
+	return nil
+}
+
+func (s *paymentRoutingService) EvaluateRules(ctx context.Context, req EvaluatePaymentRoutingRequest) (*PaymentRoutingEvaluationResponse, error) {
+	s.logger.Debug("Evaluating payment routing rules", "amount", req.Amount, "currency", req.Currency,
+		"payment_method", req.PaymentMethod, "candidate_rules", len(req.Rules))
+
+	if !models.PaymentMethod(req.PaymentMethod).IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("unsupported payment method %s", req.PaymentMethod))
+	}
+
+	var rules []*models.PaymentRoutingRule
+	if len(req.Rules) > 0 {
+		for i, ruleReq := range req.Rules {
+			rule := &models.PaymentRoutingRule{}
+			if err := s.applyRuleRequest(rule, ruleReq); err != nil {
+				return nil, errors.NewValidationError(fmt.Sprintf("rule %d: %s", i+1, err.Error()))
+			}
+			if rule.IsActive {
+				rules = append(rules, rule)
+			}
+		}
+	} else {
+		active, err := s.ruleRepo.List(ctx, true)
+		if err != nil {
+			s.logger.Error("Failed to list payment routing rules", "error", err)
+			return nil, errors.NewDatabaseError("failed to list payment routing rules", err)
+		}
+		rules = active
+	}
+
+	decision, gateway, ok := s.gateways.PreviewGateway(ctx, &payments.PaymentRequest{
+		Amount:        req.Amount,
+		Currency:      strings.ToUpper(req.Currency),
+		PaymentMethod: req.PaymentMethod,
+	}, toRoutingRules(rules))
+
+	response := &PaymentRoutingEvaluationResponse{
+		Decision: routingDecisionSelector,
+		Checks:   make([]PaymentRoutingRuleCheck, 0, len(decision.Checks)),
+	}
+	for _, check := range decision.Checks {
+		response.Checks = append(response.Checks, PaymentRoutingRuleCheck{
+			RuleID:   check.Rule.ID,
+			Name:     check.Rule.Name,
+			Priority: check.Rule.Priority,
+			Matched:  check.Matched,
+			Reason:   check.Reason,
+		})
+	}
+	if decision.Rule != nil {
+		response.MatchedRule = decision.Rule.Name
+		response.RuleGateway = string(decision.Rule.Gateway)
+		response.Decision = routingDecisionRoute
+		if decision.Bypass {
+			response.Decision = routingDecisionBypass
+		}
+	}
+	if ok {
+		response.Gateway = string(gateway)
+	}
+	return response, nil
+}
+
+// applyRuleRequest sets a rule from a request, normalizing its currencies to upper
+// case, and validates it
+func (s *paymentRoutingService) applyRuleRequest(rule *models.PaymentRoutingRule, req PaymentRoutingRuleRequest) error {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Priority = req.Priority
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.Currencies = make(models.StringList, 0, len(req.Currencies))
+	for _, currency := range req.Currencies {
+		rule.Currencies = append(rule.Currencies, strings.ToUpper(strings.TrimSpace(currency)))
+	}
+	rule.PaymentMethods = make(models.StringList, 0, len(req.PaymentMethods))
+	rule.PaymentMethods = append(rule.PaymentMethods, req.PaymentMethods...)
+	rule.Action = models.PaymentRoutingAction(req.Action)
+	rule.Gateway = strings.TrimSpace(req.Gateway)
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+	return s.validateRule(rule)
+}
+
+// validateRule checks that a rule's bounds are in order, its currencies and methods
+// are known, and that it routes to a registered gateway or bypasses only offline methods
+func (s *paymentRoutingService) validateRule(rule *models.PaymentRoutingRule) error {
+	if rule.Name == "" {
+		return errors.NewValidationError("routing rule name is required")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.NewValidationError("min_amount must not be above max_amount")
+	}
+	for _, currency := range rule.Currencies {
+		if !isCurrencyCode(currency) {
+			return errors.NewValidationError(fmt.Sprintf("invalid currency code %s", currency))
+		}
+	}
+	for _, method := range rule.PaymentMethods {
+		if !models.PaymentMethod(method).IsValid() {
+			return errors.NewValidationError(fmt.Sprintf("unsupported payment method %s", method))
+		}
+	}
+
+	switch rule.Action {
+	case models.PaymentRoutingActionRoute:
+		if rule.Gateway == "" {
+			return errors.NewValidationError("route rules must name a gateway")
+		}
+		if _, ok := s.gateways.GetGateway(payments.PaymentGatewayType(rule.Gateway)); !ok {
+			return errors.NewValidationError(fmt.Sprintf("payment gateway %s is not registered", rule.Gateway))
+		}
+	case models.PaymentRoutingActionBypass:
+		if rule.Gateway != "" {
+			return errors.NewValidationError("bypass rules must not name a gateway")
+		}
+		if len(rule.PaymentMethods) == 0 {
+			return errors.NewValidationError("bypass rules must list the offline payment methods they apply to")
+		}
+		for _, method := range rule.PaymentMethods {
+			if !models.PaymentMethod(method).IsOffline() {
+				return errors.NewValidationError(fmt.Sprintf("only offline payment methods may bypass the gateways, not %s", method))
+			}
+		}
+	default:
+		return errors.NewValidationError(fmt.Sprintf("unsupported routing action %s", rule.Action))
+	}
+	return nil
+}
+
+// checkNameAvailable returns a conflict error if another rule has the name
+func (s *paymentRoutingService) checkNameAvailable(ctx context.Context, name string) error {
+	existing, err := s.ruleRepo.GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to check existing payment routing rule", "error", err, "name", name)
+		return errors.NewDatabaseError("failed to check existing payment routing rule", err)
+	}
+	if existing != nil {
+		return errors.NewDuplicateError("payment routing rule", "name", name)
+	}
+	return nil
+}
+
+// reloadRules loads the active rules into the gateway manager after a change. The
+// change is saved either way, so a failure is logged and the rules are loaded again
+// on the next change or restart.
+func (s *paymentRoutingService) reloadRules(ctx context.Context) {
+	if err := s.LoadRules(ctx); err != nil {
+		s.logger.Error("Failed to reload payment routing rules", "error", err)
+	}
+}
+
+// toRoutingRules converts rules to the routing rules the gateway manager evaluates
+func toRoutingRules(rules []*models.PaymentRoutingRule) []payments.RoutingRule {
+	routingRules := make([]payments.RoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		routingRules = append(routingRules, payments.RoutingRule{
+			ID:             rule.ID,
+			Name:           rule.Name,
+			Priority:       rule.Priority,
+			MinAmount:      rule.MinAmount,
+			MaxAmount:      rule.MaxAmount,
+			Currencies:     rule.Currencies,
+			PaymentMethods: rule.PaymentMethods,
+			Action:         string(rule.Action),
+			Gateway:        payments.PaymentGatewayType(rule.Gateway),
+		})
+	}
+	return routingRules
+}
+
+// isCurrencyCode reports whether code looks like an ISO 4217 currency code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -4,29 +4,102 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
+	"easy-orders-backend/pkg/payments"
 )
 
+// heldPaymentBatchSize bounds how many held payments are retried per sweep
+const heldPaymentBatchSize = 50
+
+// PaymentRetrySettings configures holding transiently failed payments for retry.
+// Payments are held for at most Window after they were created, retried after
+// Backoff, doubling with every attempt. A zero Window fails payments right away.
+type PaymentRetrySettings struct {
+	Window  time.Duration
+	Backoff time.Duration
+}
+
 // paymentService implements PaymentService interface
 type paymentService struct {
+	retry       PaymentRetrySettings
 	paymentRepo repository.PaymentRepository
 	orderRepo   repository.OrderRepository
+	attemptRepo repository.PaymentAttemptRepository
+	refundRepo  repository.RefundRepository
+	activity    ActivityRecorder
+	ledger      LedgerRecorder
+	webhooks    WebhookPublisher
+	screener    *FraudScreener
+	stages      *metrics.StageRecorder
+	gateways    *payments.PaymentGatewayManager
+	processor   *payments.PaymentProcessor
+	now         func() time.Time
 	logger      *logger.Logger
 }
 
-// NewPaymentService creates a new payment service
+// NewPaymentService creates a new payment service that settles payments through the
+// payment processor and refunds them through the gateway that took them
 func NewPaymentService(
+	retry PaymentRetrySettings,
 	paymentRepo repository.PaymentRepository,
 	orderRepo repository.OrderRepository,
+	attemptRepo repository.PaymentAttemptRepository,
+	refundRepo repository.RefundRepository,
+	activity ActivityRecorder,
+	ledger LedgerRecorder,
+	webhooks WebhookPublisher,
+	screener *FraudScreener,
+	stages *metrics.StageRecorder,
+	gateways *payments.PaymentGatewayManager,
+	processor *payments.PaymentProcessor,
 	logger *logger.Logger,
 ) PaymentService {
+	return NewPaymentServiceWithClock(retry, paymentRepo, orderRepo, attemptRepo, refundRepo, activity, ledger, webhooks, screener, stages, gateways, processor, time.Now, logger)
+}
+
+// NewPaymentServiceWithClock creates a payment service that settles payments through
+// the payment processor and reads the time for retry scheduling from now. A
+// nil clock falls back to the system clock.
+func NewPaymentServiceWithClock(
+	retry PaymentRetrySettings,
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	attemptRepo repository.PaymentAttemptRepository,
+	refundRepo repository.RefundRepository,
+	activity ActivityRecorder,
+	ledger LedgerRecorder,
+	webhooks WebhookPublisher,
+	screener *FraudScreener,
+	stages *metrics.StageRecorder,
+	gateways *payments.PaymentGatewayManager,
+	processor *payments.PaymentProcessor,
+	now func() time.Time,
+	logger *logger.Logger,
+) PaymentService {
+	if now == nil {
+		now = time.Now
+	}
 	return &paymentService{
+		retry:       retry,
 		paymentRepo: paymentRepo,
 		orderRepo:   orderRepo,
+		attemptRepo: attemptRepo,
+		refundRepo:  refundRepo,
+		activity:    activity,
+		ledger:      ledger,
+		webhooks:    webhooks,
+		screener:    screener,
+		stages:      stages,
+		gateways:    gateways,
+		processor:   processor,
+		now:         now,
 		logger:      logger,
 	}
 }
@@ -55,17 +128,25 @@ func (s *paymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 		return nil, errors.New("order not found")
 	}
 
-	// Validate payment amount against order total
-	if req.Amount != order.TotalAmount {
-		return nil, fmt.Errorf("payment amount %.2f does not match order total %.2f", req.Amount, order.TotalAmount)
+	// The order total includes the surcharge or discount for the checkout payment method
+	if order.PaymentMethod != "" && models.PaymentMethod(req.PaymentType) != order.PaymentMethod {
+		return nil, fmt.Errorf("payment type %s does not match checkout payment method %s", req.PaymentType, order.PaymentMethod)
 	}
 
 	// Check if order is in a payable state
-	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusConfirmed {
+	if !order.IsPayable() {
 		return nil, fmt.Errorf("order in status %s cannot be paid", order.Status)
 	}
 
-	// Check for existing successful payments
+	if err := s.screener.Screen(ctx, FraudCheckpointPayment, FraudSubject{
+		UserID:          order.UserID,
+		IPAddress:       req.ClientIP,
+		CardFingerprint: req.CardFingerprint,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Check for payments still in flight
 	existingPayments, err := s.paymentRepo.GetByOrderID(ctx, req.OrderID)
 	if err != nil {
 		s.logger.Error("Failed to check existing payments", "error", err, "order_id", req.OrderID)
@@ -73,64 +154,479 @@ func (s *paymentService) ProcessPayment(ctx context.Context, req ProcessPaymentR
 	}
 
 	for _, payment := range existingPayments {
-		if payment.IsCompleted() {
-			return nil, errors.New("order has already been paid")
+		if payment.IsHeld() {
+			return nil, fmt.Errorf("payment %s is held for retry", payment.ID)
 		}
+		if payment.IsAwaitingConfirmation() {
+			return nil, fmt.Errorf("payment %s is awaiting confirmation by the payment gateway", payment.ID)
+		}
+	}
+
+	// An order can be paid in parts, up to its total
+	paid, err := s.paymentRepo.SumCompletedByOrderID(ctx, req.OrderID)
+	if err != nil {
+		s.logger.Error("Failed to sum completed payments", "error", err, "order_id", req.OrderID)
+		return nil, err
+	}
+	balance := roundCents(order.TotalAmount - paid)
+	if balance <= 0 {
+		return nil, errors.New("order has already been paid")
+	}
+	if roundCents(req.Amount) > balance {
+		return nil, fmt.Errorf("payment amount %.2f exceeds the %.2f left to pay of order total %.2f",
+			req.Amount, balance, order.TotalAmount)
+	}
+
+	// Payments are in the order's currency, which routes them to gateways by currency
+	currency := order.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+
+	// Routing rules may keep a payment away from the gateways, such as large bank
+	// transfers an admin records once the money has arrived
+	if decision := s.gateways.EvaluateRoutingRules(&payments.PaymentRequest{
+		OrderID:       req.OrderID,
+		Amount:        req.Amount,
+		Currency:      currency,
+		PaymentMethod: req.PaymentType,
+	}); decision.Bypass {
+		return nil, apperrors.NewBusinessError(fmt.Sprintf(
+			"payment cannot be paid through the payment gateways: routing rule %s settles it outside them, record it as a manual payment",
+			decision.Rule.Name))
 	}
 
 	// Create a payment record
 	payment := &models.Payment{
 		OrderID:           req.OrderID,
 		Amount:            req.Amount,
-		Currency:          "USD",
+		Currency:          currency,
 		Status:            models.PaymentStatusPending,
 		Method:            models.PaymentMethod(req.PaymentType),
 		ExternalReference: req.ExternalReference,
 	}
 
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+	// The balance is checked again with the order locked, counting payments in
+	// progress, so concurrent payments cannot together pay more than the total
+	created, err := s.paymentRepo.CreateWithinBalance(ctx, payment)
+	if err != nil {
 		s.logger.Error("Failed to create payment", "error", err, "order_id", req.OrderID)
 		return nil, err
 	}
+	if !created {
+		return nil, apperrors.NewConflictError(fmt.Sprintf(
+			"payment amount %.2f exceeds what is left to pay of order total %.2f once payments in progress are counted",
+			req.Amount, order.TotalAmount))
+	}
+
+	return s.settle(ctx, order, payment, attemptNumber(existingPayments))
+}
+
+// ResumePayment retries a held payment now instead of waiting for the retry sweeper
+func (s *paymentService) ResumePayment(ctx context.Context, id string) (*PaymentResponse, error) {
+	s.logger.Info("Resuming held payment", "id", id)
+
+	if id == "" {
+		return nil, errors.New("payment ID is required")
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get payment", "error", err, "id", id)
+		return nil, err
+	}
+	if payment == nil {
+		return nil, errors.New("payment not found")
+	}
+
+	return s.resume(ctx, payment)
+}
+
+// RefundPayment returns part or all of a completed payment to the customer. The refund
+// goes through the gateway that took the payment; offline payments, and payments
+// settled without a gateway transaction, are refunded outside any gateway. Each refund is recorded in the payment's refund
+// history, and the payment is marked refunded once all of it has been returned.
+func (s *paymentService) RefundPayment(ctx context.Context, id string, req RefundRequest) (*PaymentResponse, error) {
+	s.logger.Info("Refunding payment", "id", id, "amount", req.Amount)
+
+	if id == "" {
+		return nil, apperrors.NewValidationError("payment ID is required")
+	}
+	amount := roundCents(req.Amount)
+	if amount <= 0 {
+		return nil, apperrors.NewValidationError("refund amount must be greater than 0")
+	}
+
+	payment, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get payment for refund", "error", err, "id", id)
+		return nil, apperrors.NewDatabaseError("failed to get payment", err)
+	}
+	if payment == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("payment", id)
+	}
+	if !payment.CanRefund() {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("payment in status %s cannot be refunded", payment.Status))
+	}
+	if refundable := payment.RefundableAmount(); amount > refundable {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("refund amount %.2f exceeds the %.2f left to refund", amount, refundable))
+	}
+
+	// Refunds are named after how much had been refunded before them, so a retried
+	// refund is not returned twice
+	refundID := fmt.Sprintf("%s-refund-%d", payment.ID, int64(math.Round(payment.RefundedAmount*100)))
+	if payment.GatewayTxnID != "" && !payment.Method.IsOffline() {
+		gateway, ok := s.gateways.GetGateway(payments.PaymentGatewayType(payment.Gateway))
+		if !ok {
+			s.logger.Error("Gateway of payment to refund is not registered", "payment_id", payment.ID, "gateway", payment.Gateway)
+			return nil, apperrors.NewExternalServiceError("payment gateway",
+				fmt.Sprintf("gateway %s that took the payment is not available", payment.Gateway), nil)
+		}
+		response, err := gateway.RefundPayment(ctx, &payments.GatewayRefundRequest{
+			OriginalTransactionID: payment.GatewayTxnID,
+			Amount:                amount,
+			Currency:              payment.Currency,
+			Reason:                req.Reason,
+			IdempotencyKey:        refundID,
+		})
+		if err != nil {
+			s.logger.Error("Gateway refund failed", "error", err, "payment_id", payment.ID)
+			return nil, apperrors.NewExternalServiceError("payment gateway", "refund failed", err)
+		}
+		if response.Status != "completed" {
+			s.logger.Warn("Gateway declined refund", "payment_id", payment.ID, "failure_type", response.FailureType)
+			return nil, apperrors.NewBusinessError(fmt.Sprintf("refund declined: %s", response.FailureMessage))
+		}
+	}
+
+	refund := &models.Refund{
+		PaymentID: payment.ID,
+		OrderID:   payment.OrderID,
+		Amount:    amount,
+		Currency:  payment.Currency,
+		Reason:    req.Reason,
+		RefundKey: refundID,
+	}
+	if req.RequestedBy != "" {
+		refund.RequestedBy = &req.RequestedBy
+	}
+	refunded, err := s.refundRepo.Record(ctx, refund, payment.RefundedAmount)
+	if err != nil {
+		s.logger.Error("Failed to record payment refund", "error", err, "payment_id", payment.ID)
+		return nil, apperrors.NewDatabaseError("failed to record refund", err)
+	}
+	if refunded == nil {
+		// The gateway was given the same refund ID as the concurrent refund, so it
+		// returned the money once
+		return nil, apperrors.NewConflictError(fmt.Sprintf("payment %s was refunded concurrently, check its refunds before retrying", payment.ID))
+	}
+	payment = refunded
+
+	// A fully refunded payment no longer counts towards what was paid for the order
+	if payment.IsRefunded() {
+		if _, err := s.orderRepo.RefreshPaidAmount(ctx, payment.OrderID); err != nil {
+			s.logger.Error("Failed to refresh order paid amount after refund", "error", err, "order_id", payment.OrderID)
+		}
+	}
+
+	// The money was returned either way, so a ledger failure is left to the ledger
+	// consistency check rather than failing the refund
+	if s.ledger != nil {
+		if err := s.ledger.RecordPartialRefund(ctx, PaymentRefund{RefundID: refundID, Payment: payment, Amount: amount}); err != nil {
+			s.logger.Error("Failed to post refund to the ledger", "error", err, "payment_id", payment.ID)
+		}
+	}
+
+	s.logger.Info("Payment refunded", "payment_id", payment.ID, "order_id", payment.OrderID,
+		"amount", amount, "refunded", payment.RefundedAmount)
+	response := toPaymentResponse(payment)
+	response.Refund = toRefundResponse(refund)
+	return response, nil
+}
+
+func (s *paymentService) ListRefunds(ctx context.Context, id string) (*PaymentRefundsResponse, error) {
+	s.logger.Debug("Listing payment refunds", "id", id)
+
+	if id == "" {
+		return nil, apperrors.NewValidationError("payment ID is required")
+	}
 
-	// Simulate payment processing
-	success := s.simulatePaymentProcessing(ctx, payment)
+	payment, err := s.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get payment", "error", err, "id", id)
+		return nil, apperrors.NewDatabaseError("failed to get payment", err)
+	}
+	if payment == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("payment", id)
+	}
 
-	if success {
-		// Mark payment as processed and completed
+	refunds, err := s.refundRepo.ListByPaymentID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list payment refunds", "error", err, "id", id)
+		return nil, apperrors.NewDatabaseError("failed to list refunds", err)
+	}
+
+	response := &PaymentRefundsResponse{
+		PaymentID:        payment.ID,
+		Amount:           payment.Amount,
+		RefundedAmount:   payment.RefundedTotal(),
+		RefundableAmount: payment.RefundableAmount(),
+		Refunds:          make([]*RefundResponse, len(refunds)),
+	}
+	for i, refund := range refunds {
+		response.Refunds[i] = toRefundResponse(refund)
+	}
+	return response, nil
+}
+
+func toRefundResponse(refund *models.Refund) *RefundResponse {
+	return &RefundResponse{
+		ID:          refund.ID,
+		PaymentID:   refund.PaymentID,
+		OrderID:     refund.OrderID,
+		Amount:      refund.Amount,
+		Currency:    refund.Currency,
+		Reason:      refund.Reason,
+		RequestedBy: refund.RequestedBy,
+		CreatedAt:   refund.CreatedAt,
+	}
+}
+
+// RetryHeldPayments retries held payments whose retry is due and fails those whose
+// retry window has expired. It returns how many held payments were processed.
+func (s *paymentService) RetryHeldPayments(ctx context.Context) (int, error) {
+	due, err := s.paymentRepo.ListHeldDue(ctx, s.now(), heldPaymentBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to list held payments due for retry", "error", err)
+		return 0, err
+	}
+
+	for _, payment := range due {
+		if _, err := s.resume(ctx, payment); err != nil {
+			s.logger.Debug("Held payment was not settled", "payment_id", payment.ID, "error", err)
+		}
+	}
+
+	if len(due) > 0 {
+		s.logger.Info("Held payments processed", "count", len(due))
+	}
+	return len(due), nil
+}
+
+// resume claims a held payment and retries it, or fails it once its retry window
+// has expired or its order can no longer be paid
+func (s *paymentService) resume(ctx context.Context, payment *models.Payment) (*PaymentResponse, error) {
+	if !payment.IsHeld() {
+		return nil, fmt.Errorf("payment in status %s is not held for retry", payment.Status)
+	}
+
+	claimed, err := s.paymentRepo.ClaimHeld(ctx, payment.ID)
+	if err != nil {
+		s.logger.Error("Failed to claim held payment", "error", err, "payment_id", payment.ID)
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("payment %s is already being retried", payment.ID)
+	}
+	payment.Status = models.PaymentStatusPending
+
+	if payment.RetryWindowExpired(s.now()) {
+		return nil, s.fail(ctx, payment, "retry window expired: "+payment.FailureReason)
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, payment.OrderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for held payment", "error", err, "order_id", payment.OrderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, s.fail(ctx, payment, "order not found")
+	}
+	if !order.IsPayable() {
+		return nil, s.fail(ctx, payment, fmt.Sprintf("order in status %s cannot be paid", order.Status))
+	}
+
+	orderPayments, err := s.paymentRepo.GetByOrderID(ctx, payment.OrderID)
+	if err != nil {
+		s.logger.Error("Failed to check existing payments", "error", err, "order_id", payment.OrderID)
+		return nil, err
+	}
+
+	return s.settle(ctx, order, payment, attemptNumber(orderPayments))
+}
+
+// settle runs a pending payment through the payment processor, recording each gateway
+// attempt it made. A transient failure holds the payment for another attempt while the
+// retry window allows it; any other failure fails the payment. The order and its
+// reservation are left pending either way. A payment the gateway confirms
+// asynchronously awaits its webhook, which settles it.
+func (s *paymentService) settle(ctx context.Context, order *models.Order, payment *models.Payment, number int) (*PaymentResponse, error) {
+	run := s.stages.Start()
+	run.Stage(StagePayment)
+	attempts, awaitingConfirmation := s.processWithGateway(ctx, payment)
+	attempt := attempts[len(attempts)-1]
+	if attempt.Success {
+		run.Succeed()
+	} else {
+		run.Fail(false)
+	}
+	for i, gatewayAttempt := range attempts {
+		gatewayAttempt.AttemptNumber = number + i
+		s.recordAttempt(ctx, gatewayAttempt)
+	}
+	payment.IncrementAttempt()
+
+	if !attempt.Success {
+		if retryAt, ok := s.nextRetry(payment, payments.PaymentFailureType(attempt.FailureType)); ok {
+			payment.HoldForRetry(attempt.FailureMessage, retryAt)
+
+			if err := s.paymentRepo.Update(ctx, payment); err != nil {
+				s.logger.Error("Failed to hold payment for retry", "error", err, "payment_id", payment.ID)
+				return nil, err
+			}
+
+			s.logger.Warn("Payment held for retry", "payment_id", payment.ID, "order_id", payment.OrderID,
+				"failure_type", attempt.FailureType, "retry_at", retryAt)
+			return toPaymentResponse(payment), nil
+		}
+
+		s.logger.Warn("Payment processing failed", "payment_id", payment.ID, "order_id", payment.OrderID,
+			"failure_type", attempt.FailureType)
+		return nil, s.fail(ctx, payment, attempt.FailureMessage)
+	}
+
+	if awaitingConfirmation {
 		payment.MarkProcessed()
-		payment.MarkCompleted()
+		payment.NextRetryAt = nil
 
 		if err := s.paymentRepo.Update(ctx, payment); err != nil {
 			s.logger.Error("Failed to update payment status", "error", err, "payment_id", payment.ID)
 			return nil, err
 		}
 
-		// Update order status to paid
-		if err := s.orderRepo.UpdateStatus(ctx, req.OrderID, models.OrderStatusPaid); err != nil {
-			s.logger.Error("Failed to update order status after payment", "error", err, "order_id", req.OrderID)
-			// Don't fail the payment, just log the error
+		s.logger.Info("Payment awaiting gateway confirmation", "payment_id", payment.ID, "order_id", order.ID,
+			"gateway", payment.Gateway, "gateway_txn_id", payment.GatewayTxnID)
+		return toPaymentResponse(payment), nil
+	}
+
+	// Mark payment as processed and completed
+	payment.MarkProcessed()
+	payment.MarkCompleted()
+	payment.NextRetryAt = nil
+
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		s.logger.Error("Failed to update payment status", "error", err, "payment_id", payment.ID)
+		return nil, err
+	}
+
+	// The payment was captured either way, so a ledger failure is left to the
+	// ledger consistency check rather than failing it
+	if s.ledger != nil {
+		if err := s.ledger.RecordPayment(ctx, payment); err != nil {
+			s.logger.Error("Failed to post payment to the ledger", "error", err, "payment_id", payment.ID)
 		}
+	}
 
-		s.logger.Info("Payment processed successfully", "payment_id", payment.ID, "order_id", req.OrderID)
-	} else {
-		// Mark payment as failed
-		payment.MarkFailed("Payment processing failed")
+	// Don't fail the payment, just log the error
+	if _, err := recordOrderPayment(ctx, s.orderRepo, order); err != nil {
+		s.logger.Error("Failed to update order after payment", "error", err, "order_id", order.ID)
+	}
 
-		if err := s.paymentRepo.Update(ctx, payment); err != nil {
-			s.logger.Error("Failed to update failed payment status", "error", err, "payment_id", payment.ID)
+	s.logger.Info("Payment processed successfully", "payment_id", payment.ID, "order_id", order.ID,
+		"paid_amount", order.PaidAmount, "balance_due", order.BalanceDue())
+	recordActivity(ctx, s.activity, order.UserID, models.ActivityEventPayment, payment.ID)
+
+	return toPaymentResponse(payment), nil
+}
+
+// recordOrderPayment refreshes what was paid for an order after one of its payments
+// completed, and marks a pending or confirmed order as paid once its payments cover
+// the total. On-account invoices settled after shipping keep their status. It reports
+// whether the order was marked paid.
+func recordOrderPayment(ctx context.Context, orderRepo repository.OrderRepository, order *models.Order) (bool, error) {
+	paid, err := orderRepo.RefreshPaidAmount(ctx, order.ID)
+	if err != nil {
+		return false, err
+	}
+	order.PaidAmount = paid
+
+	if !order.IsFullyPaid() || !(order.IsPending() || order.IsConfirmed()) {
+		return false, nil
+	}
+	if err := orderRepo.UpdateStatus(ctx, order.ID, models.OrderStatusPaid); err != nil {
+		return false, err
+	}
+	order.Status = models.OrderStatusPaid
+	return true, nil
+}
+
+// nextRetry returns when a payment that failed with failureType should be retried,
+// backing off exponentially, or false when it should fail instead
+func (s *paymentService) nextRetry(payment *models.Payment, failureType payments.PaymentFailureType) (time.Time, bool) {
+	if s.retry.Window <= 0 || payment.AttemptCount >= payment.MaxRetries {
+		return time.Time{}, false
+	}
+	switch failureType.Category() {
+	case payments.FailureCategoryTechnical, payments.FailureCategorySoftDecline:
+	default:
+		return time.Time{}, false
+	}
+
+	if payment.RetryUntil == nil {
+		retryUntil := payment.CreatedAt.Add(s.retry.Window)
+		if payment.CreatedAt.IsZero() {
+			retryUntil = s.now().Add(s.retry.Window)
 		}
+		payment.RetryUntil = &retryUntil
+	}
 
-		s.logger.Warn("Payment processing failed", "payment_id", payment.ID, "order_id", req.OrderID)
-		return nil, errors.New("payment processing failed")
+	retryAt := s.now().Add(s.retry.Backoff << (payment.AttemptCount - 1))
+	if !retryAt.Before(*payment.RetryUntil) {
+		return time.Time{}, false
 	}
+	return retryAt, true
+}
+
+// fail marks a payment as failed and returns the error reported to the caller
+func (s *paymentService) fail(ctx context.Context, payment *models.Payment, reason string) error {
+	payment.MarkFailed(reason)
+	payment.NextRetryAt = nil
 
-	return &PaymentResponse{
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		s.logger.Error("Failed to update failed payment status", "error", err, "payment_id", payment.ID)
+	} else {
+		publishPaymentFailed(ctx, s.webhooks, payment)
+	}
+
+	return fmt.Errorf("payment processing failed: %s", reason)
+}
+
+// attemptNumber numbers the next gateway attempt for an order after its earlier payments
+func attemptNumber(existing []*models.Payment) int {
+	number := 1
+	for _, payment := range existing {
+		number += max(payment.AttemptCount, 1)
+	}
+	return number
+}
+
+// toPaymentResponse converts a payment to its response format
+func toPaymentResponse(payment *models.Payment) *PaymentResponse {
+	response := &PaymentResponse{
 		ID:      payment.ID,
 		OrderID: payment.OrderID,
 		Amount:  payment.Amount,
 		Status:  payment.Status,
-	}, nil
+
+		RefundedAmount: payment.RefundedAmount,
+	}
+	if payment.IsHeld() || payment.IsFailed() {
+		response.FailureReason = payment.FailureReason
+	}
+	if payment.IsHeld() {
+		response.RetryAt = payment.NextRetryAt
+	}
+	return response
 }
 
 func (s *paymentService) GetPayment(ctx context.Context, id string) (*PaymentResponse, error) {
@@ -150,12 +646,7 @@ func (s *paymentService) GetPayment(ctx context.Context, id string) (*PaymentRes
 		return nil, errors.New("payment not found")
 	}
 
-	return &PaymentResponse{
-		ID:      payment.ID,
-		OrderID: payment.OrderID,
-		Amount:  payment.Amount,
-		Status:  payment.Status,
-	}, nil
+	return toPaymentResponse(payment), nil
 }
 
 func (s *paymentService) GetOrderPayments(ctx context.Context, orderID string) ([]*PaymentResponse, error) {
@@ -174,12 +665,7 @@ func (s *paymentService) GetOrderPayments(ctx context.Context, orderID string) (
 	// Convert to response format
 	paymentResponses := make([]*PaymentResponse, len(payments))
 	for i, payment := range payments {
-		paymentResponses[i] = &PaymentResponse{
-			ID:      payment.ID,
-			OrderID: payment.OrderID,
-			Amount:  payment.Amount,
-			Status:  payment.Status,
-		}
+		paymentResponses[i] = toPaymentResponse(payment)
 	}
 
 	s.logger.Debug("Order payments retrieved", "order_id", orderID, "count", len(paymentResponses))
@@ -187,19 +673,112 @@ func (s *paymentService) GetOrderPayments(ctx context.Context, orderID string) (
 	return paymentResponses, nil
 }
 
-// simulatePaymentProcessing simulates external payment processing
-// In a real implementation, this would integrate with a payment gateway
-func (s *paymentService) simulatePaymentProcessing(ctx context.Context, payment *models.Payment) bool {
-	s.logger.Debug("Simulating payment processing", "payment_id", payment.ID, "method", payment.Method)
+// processWithGateway settles a payment through the payment processor, which routes it
+// by the gateway manager's routing rules and selector and fails over to another gateway
+// when one fails technically. It returns the gateway attempts made, the last deciding
+// the outcome, and whether the gateway accepted the payment but confirms its outcome
+// later. Processor errors, such as having no gateway available, are technical
+// failures, reported as timeouts when the deadline passed. A routing rule keeping the
+// payment away from the gateways fails it.
+func (s *paymentService) processWithGateway(ctx context.Context, payment *models.Payment) ([]*models.PaymentAttempt, bool) {
+	result, err := s.processor.ProcessPayment(ctx, &payments.PaymentRequest{
+		IdempotencyKey: fmt.Sprintf("%s-%d", payment.ID, payment.AttemptCount+1),
+		OrderID:        payment.OrderID,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		PaymentMethod:  string(payment.Method),
+		RetryPolicy:    settlementRetryPolicy(len(s.gateways.GetAvailableGateways())),
+		FailoverPolicy: settlementFailoverPolicy(),
+	})
+	if err != nil {
+		attempt := &models.PaymentAttempt{
+			PaymentID:      payment.ID,
+			OrderID:        payment.OrderID,
+			Method:         payment.Method,
+			FailureType:    string(payments.FailureTypeGatewayError),
+			FailureMessage: err.Error(),
+			StartedAt:      s.now(),
+		}
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			attempt.FailureType = string(payments.FailureTypeGatewayTimeout)
+		case errors.Is(err, payments.ErrPaymentBypassesGateways):
+			// A rule added since the payment was created; retrying won't reach a gateway
+			attempt.FailureType = string(payments.FailureTypeConfigurationError)
+		}
+		return []*models.PaymentAttempt{attempt}, false
+	}
+
+	attempts := make([]*models.PaymentAttempt, len(result.Attempts))
+	for i, gatewayAttempt := range result.Attempts {
+		attempts[i] = &models.PaymentAttempt{
+			PaymentID:        payment.ID,
+			OrderID:          payment.OrderID,
+			Gateway:          string(gatewayAttempt.Gateway),
+			Method:           payment.Method,
+			Success:          gatewayAttempt.Success,
+			FailureType:      string(gatewayAttempt.FailureType),
+			FailureMessage:   gatewayAttempt.FailureMessage,
+			ProcessingTimeMs: gatewayAttempt.ProcessingTimeMs,
+			StartedAt:        gatewayAttempt.StartedAt,
+		}
+	}
+
+	// Declined charges keep the gateway's transaction too, so reconciliation can check
+	// failed payments against what the gateway actually captured
+	for _, gatewayAttempt := range result.Attempts {
+		if gatewayAttempt.TransactionID != "" {
+			payment.Gateway = string(gatewayAttempt.Gateway)
+			payment.GatewayTxnID = gatewayAttempt.TransactionID
+		}
+	}
+	last := result.Attempts[len(result.Attempts)-1]
+	if last.Success {
+		payment.Gateway = string(last.Gateway)
+		payment.GatewayTxnID = last.TransactionID
+		payment.ProcessingFee = roundCents(last.ProcessingFee)
+	}
+
+	s.logger.Debug("Gateway payment processed", "payment_id", payment.ID, "gateway", last.Gateway,
+		"attempts", result.AttemptCount, "status", result.Status, "failure_type", result.FinalFailureType)
 
-	// Simulate processing delay
-	time.Sleep(100 * time.Millisecond)
+	return attempts, last.Pending
+}
 
-	// Simulate 95% success rate
-	// In reality, this would be determined by the payment gateway response
-	success := time.Now().UnixNano()%100 < 95
+// settlementRetryPolicy lets a payment move on to each registered gateway once while it
+// is being settled. The retry sweeper retries it later, so nothing waits in between.
+func settlementRetryPolicy(gateways int) *payments.RetryPolicy {
+	return &payments.RetryPolicy{
+		MaxAttempts: max(gateways, 1),
+		RetriableFailures: []payments.PaymentFailureType{
+			payments.FailureTypeNetworkError,
+			payments.FailureTypeGatewayTimeout,
+			payments.FailureTypeGatewayError,
+			payments.FailureTypeRateLimited,
+		},
+	}
+}
 
-	s.logger.Debug("Payment processing simulation completed", "payment_id", payment.ID, "success", success)
+// settlementFailoverPolicy fails over to another gateway after the first failure of
+// the gateway itself; declines are for the retry sweeper
+func settlementFailoverPolicy() *payments.FailoverPolicy {
+	return &payments.FailoverPolicy{
+		FailuresBeforeFailover: 1,
+		FailoverFailures: []payments.PaymentFailureType{
+			payments.FailureTypeNetworkError,
+			payments.FailureTypeGatewayTimeout,
+			payments.FailureTypeGatewayError,
+		},
+	}
+}
 
-	return success
+// recordAttempt persists a payment attempt for failure analytics. Recording is
+// best effort and never fails the payment.
+func (s *paymentService) recordAttempt(ctx context.Context, attempt *models.PaymentAttempt) {
+	if s.attemptRepo == nil {
+		return
+	}
+	if err := s.attemptRepo.Create(ctx, attempt); err != nil {
+		s.logger.Warn("Failed to record payment attempt", "error", err, "payment_id", attempt.PaymentID)
+	}
 }
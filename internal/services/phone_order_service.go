@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// defaultPhoneOrderSearchLimit is the number of products a phone order search returns
+// when the agent does not ask for a limit
+const defaultPhoneOrderSearchLimit = 20
+
+// phoneOrderService implements PhoneOrderService interface
+type phoneOrderService struct {
+	userRepo          repository.UserRepository
+	orderRepo         repository.OrderRepository
+	paymentMethodRepo repository.SavedPaymentMethodRepository
+	productService    ProductService
+	priceLists        *PriceListResolver
+	cartHolds         CartHoldService
+	orderService      OrderService
+	adminOrderService AdminOrderService
+	paymentService    PaymentService
+	priceOverrides    PriceOverrideSettings
+	logger            *logger.Logger
+}
+
+// NewPhoneOrderService creates a new phone order service
+func NewPhoneOrderService(
+	userRepo repository.UserRepository,
+	orderRepo repository.OrderRepository,
+	paymentMethodRepo repository.SavedPaymentMethodRepository,
+	productService ProductService,
+	priceLists *PriceListResolver,
+	cartHolds CartHoldService,
+	orderService OrderService,
+	adminOrderService AdminOrderService,
+	paymentService PaymentService,
+	priceOverrides PriceOverrideSettings,
+	logger *logger.Logger,
+) PhoneOrderService {
+	return &phoneOrderService{
+		userRepo:          userRepo,
+		orderRepo:         orderRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		productService:    productService,
+		priceLists:        priceLists,
+		cartHolds:         cartHolds,
+		orderService:      orderService,
+		adminOrderService: adminOrderService,
+		paymentService:    paymentService,
+		priceOverrides:    priceOverrides,
+		logger:            logger,
+	}
+}
+
+func (s *phoneOrderService) SearchProducts(ctx context.Context, customerID string, req PhoneOrderProductSearchRequest) (*PhoneOrderProductSearchResponse, error) {
+	s.logger.Debug("Searching products for phone order", "customer_id", customerID, "query", req.Query)
+
+	customer, err := s.getCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultPhoneOrderSearchLimit
+	}
+	found, err := s.productService.ListProducts(ctx, ListProductsRequest{
+		Page:       1,
+		Limit:      limit,
+		ActiveOnly: true,
+		Query:      req.Query,
+	})
+	if err != nil {
+		s.logger.Error("Failed to search products for phone order", "error", err, "query", req.Query)
+		return nil, err
+	}
+
+	productIDs := make([]string, len(found.Products))
+	for i, product := range found.Products {
+		productIDs[i] = product.ID
+	}
+	prices, err := s.priceLists.Resolve(ctx, customer, productIDs, time.Now())
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to resolve customer prices", err)
+	}
+
+	response := &PhoneOrderProductSearchResponse{Products: make([]*PhoneOrderProduct, 0, len(found.Products))}
+	for _, product := range found.Products {
+		customerPrice := product.Price
+		if price, ok := prices[product.ID]; ok {
+			customerPrice = price
+		}
+		response.Products = append(response.Products, &PhoneOrderProduct{
+			ID:               product.ID,
+			Name:             product.Name,
+			SKU:              product.SKU,
+			ListPrice:        product.Price,
+			CustomerPrice:    customerPrice,
+			MinOverridePrice: s.minOverridePrice(customerPrice),
+			Stock:            product.Stock,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *phoneOrderService) GetCart(ctx context.Context, customerID string) ([]*CartHoldResponse, error) {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return s.cartHolds.GetCartHolds(ctx, customerID)
+}
+
+func (s *phoneOrderService) HoldCartItem(ctx context.Context, customerID string, req HoldCartItemRequest) (*CartHoldResponse, error) {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	return s.cartHolds.HoldItem(ctx, customerID, req)
+}
+
+func (s *phoneOrderService) ReleaseCartItem(ctx context.Context, customerID, productID string) error {
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return err
+	}
+	return s.cartHolds.ReleaseItem(ctx, customerID, productID)
+}
+
+func (s *phoneOrderService) PlaceOrder(ctx context.Context, customerID, agentID string, req PlacePhoneOrderRequest) (*PhoneOrderResponse, error) {
+	s.logger.Info("Placing phone order", "customer_id", customerID, "agent_id", agentID, "payment_type", req.PaymentType)
+
+	if _, err := s.getCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.phoneOrderItems(ctx, customerID, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	orderReq := CreateOrderRequest{
+		UserID:         customerID,
+		Notes:          req.Notes,
+		Channel:        models.OrderChannelPhone,
+		PlacedBy:       agentID,
+		DeliverySlotID: req.DeliverySlotID,
+	}
+	overrides := make(map[string]*OverrideItemPriceRequest)
+	for _, item := range items {
+		if item.PriceOverride != nil {
+			if _, ok := overrides[item.ProductID]; ok {
+				return nil, errors.NewValidationError(fmt.Sprintf("product %s is overridden more than once", item.ProductID))
+			}
+			overrides[item.ProductID] = item.PriceOverride
+		}
+		orderReq.Items = append(orderReq.Items, OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	var method *models.SavedPaymentMethod
+	switch req.PaymentType {
+	case PhoneOrderPaymentCardOnFile:
+		if method, err = s.cardOnFile(ctx, customerID, req.PaymentMethodID); err != nil {
+			return nil, err
+		}
+		orderReq.PaymentMethod = method.Method
+	case PhoneOrderPaymentInvoice:
+		orderReq.OnAccount = true
+	case PhoneOrderPaymentOffline:
+		if req.OfflineMethod != models.PaymentMethodBankTransfer && req.OfflineMethod != models.PaymentMethodCash {
+			return nil, errors.NewValidationError("offline orders are paid by bank_transfer or cash")
+		}
+		orderReq.PaymentMethod = req.OfflineMethod
+	default:
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid phone order payment type %s", req.PaymentType))
+	}
+
+	order, err := s.orderService.CreateOrder(ctx, orderReq)
+	if err != nil {
+		s.logger.Warn("Phone order was not placed", "error", err, "customer_id", customerID, "agent_id", agentID)
+		return nil, err
+	}
+
+	if len(overrides) > 0 {
+		repriced, err := s.overridePrices(ctx, order.ID, agentID, overrides)
+		if err != nil {
+			// The customer agreed to the overridden prices; don't leave the order at others
+			if cancelErr := s.orderService.CancelOrder(ctx, order.ID); cancelErr != nil {
+				s.logger.Error("Failed to cancel phone order after price override failed", "error", cancelErr, "order_id", order.ID)
+			}
+			return nil, err
+		}
+		order = repriced
+	}
+
+	response := &PhoneOrderResponse{Order: order, PaymentType: req.PaymentType}
+	if method != nil {
+		payment, err := s.paymentService.ProcessPayment(ctx, ProcessPaymentRequest{
+			OrderID:           order.ID,
+			Amount:            order.Total,
+			PaymentType:       string(method.Method),
+			ExternalReference: savedPaymentMethodReferencePrefix + method.ID,
+			CardFingerprint:   method.Fingerprint,
+		})
+		if err != nil {
+			s.logger.Warn("Phone order placed but not paid", "error", err, "order_id", order.ID)
+			response.PaymentError = err.Error()
+		} else {
+			response.Payment = payment
+			if expressCheckoutOutcome(payment.Status) == ExpressCheckoutPaid {
+				order.Status = models.OrderStatusPaid
+			}
+		}
+	}
+
+	s.logger.Info("Phone order placed", "order_id", order.ID, "customer_id", customerID, "agent_id", agentID,
+		"total", order.Total, "overrides", len(overrides))
+	return response, nil
+}
+
+func (s *phoneOrderService) GenerateAgentSalesReport(ctx context.Context, req AgentSalesReportRequest) (*AgentSalesReportResponse, error) {
+	s.logger.Info("Generating agent sales report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.orderRepo.AggregateSalesByAgent(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to aggregate sales by agent", "error", err)
+		return nil, err
+	}
+
+	report := &AgentSalesReportResponse{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Agents:    make([]AgentSalesItem, 0, len(sales)),
+	}
+	for _, agent := range sales {
+		item := AgentSalesItem{
+			AgentID: agent.AgentID,
+			AgentSalesTotals: newAgentSalesTotals(AgentSalesTotals{
+				Orders:         agent.Orders,
+				Revenue:        agent.Revenue,
+				RefundedAmount: agent.RefundedAmount,
+			}),
+		}
+		// Agents may have left since; their sales are still reported
+		if user, err := s.userRepo.GetByID(ctx, agent.AgentID); err == nil && user != nil {
+			item.AgentEmail = user.Email
+		}
+		report.Agents = append(report.Agents, item)
+
+		report.Totals.Orders += agent.Orders
+		report.Totals.Revenue += agent.Revenue
+		report.Totals.RefundedAmount += agent.RefundedAmount
+	}
+	report.Totals = newAgentSalesTotals(report.Totals)
+
+	s.logger.Info("Agent sales report generated", "start_date", report.StartDate, "end_date", report.EndDate,
+		"agents", len(report.Agents), "revenue", report.Totals.Revenue)
+	return report, nil
+}
+
+// getCustomer returns the active customer a phone order is placed for
+func (s *phoneOrderService) getCustomer(ctx context.Context, customerID string) (*models.User, error) {
+	customer, err := s.userRepo.GetByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get phone order customer", "error", err, "customer_id", customerID)
+		return nil, errors.NewDatabaseError("failed to get customer", err)
+	}
+	if customer == nil {
+		return nil, errors.NewNotFoundErrorWithID("customer", customerID)
+	}
+	if !customer.IsActive {
+		return nil, errors.NewBusinessError(fmt.Sprintf("customer %s is deactivated", customerID))
+	}
+	return customer, nil
+}
+
+// phoneOrderItems returns the items to order, defaulting to the customer's cart
+func (s *phoneOrderService) phoneOrderItems(ctx context.Context, customerID string, items []PhoneOrderItem) ([]PhoneOrderItem, error) {
+	if len(items) > 0 {
+		return items, nil
+	}
+
+	holds, err := s.cartHolds.GetCartHolds(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, errors.NewValidationError("no items to order and the customer's cart is empty")
+	}
+
+	items = make([]PhoneOrderItem, len(holds))
+	for i, hold := range holds {
+		items[i] = PhoneOrderItem{ProductID: hold.ProductID, Quantity: hold.Quantity}
+	}
+	return items, nil
+}
+
+// cardOnFile returns the customer's saved card to charge: the given one, or else
+// their default
+func (s *phoneOrderService) cardOnFile(ctx context.Context, customerID, methodID string) (*models.SavedPaymentMethod, error) {
+	var method *models.SavedPaymentMethod
+	var err error
+	if methodID != "" {
+		method, err = s.paymentMethodRepo.GetByID(ctx, methodID)
+	} else {
+		method, err = s.paymentMethodRepo.GetDefault(ctx, customerID)
+	}
+	if err != nil {
+		s.logger.Error("Failed to get card on file", "error", err, "customer_id", customerID)
+		return nil, errors.NewDatabaseError("failed to get payment method", err)
+	}
+	if method == nil || method.UserID != customerID {
+		if methodID == "" {
+			return nil, errors.NewBusinessError("customer has no default payment method")
+		}
+		return nil, errors.NewNotFoundErrorWithID("payment method", methodID)
+	}
+	if !method.Method.IsCard() {
+		return nil, errors.NewBusinessError(fmt.Sprintf("payment method %s is not a card", method.ID))
+	}
+	if method.IsExpired(time.Now()) {
+		return nil, errors.NewBusinessError(fmt.Sprintf("payment method %s has expired", method.ID))
+	}
+	return method, nil
+}
+
+// overridePrices overrides the prices of the order's items by product, as the agent,
+// and returns the repriced order
+func (s *phoneOrderService) overridePrices(ctx context.Context, orderID, agentID string, overrides map[string]*OverrideItemPriceRequest) (*OrderResponse, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get order for price override", err)
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+
+	for _, item := range order.Items {
+		override, ok := overrides[item.ProductID]
+		if !ok {
+			continue
+		}
+		if _, err := s.adminOrderService.OverrideItemPrice(ctx, orderID, item.ID, agentID, *override); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.orderService.GetOrder(ctx, orderID)
+}
+
+// minOverridePrice is the lowest unit price the price override policy allows an
+// agent to charge instead of price
+func (s *phoneOrderService) minOverridePrice(price float64) float64 {
+	return math.Ceil(price*(100-s.priceOverrides.MaxDiscountPercent)) / 100
+}
+
+// newAgentSalesTotals rounds the sums and derives the net revenue
+func newAgentSalesTotals(totals AgentSalesTotals) AgentSalesTotals {
+	totals.Revenue = roundCents(totals.Revenue)
+	totals.RefundedAmount = roundCents(totals.RefundedAmount)
+	totals.NetRevenue = roundCents(totals.Revenue - totals.RefundedAmount)
+	return totals
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	apperrors "easy-orders-backend/pkg/errors"
+)
+
+// priceListImportMaxRows caps the size of a single price list import
+const priceListImportMaxRows = 10000
+
+// Price list import row statuses
+const (
+	PriceListImportStatusImported = "imported"
+	PriceListImportStatusValid    = "valid" // Valid, but not imported as other rows failed
+	PriceListImportStatusFailed   = "failed"
+)
+
+// priceListDateLayouts are the accepted formats of effective dates; a bare date is
+// midnight UTC
+var priceListDateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+func (s *priceListService) ImportItems(ctx context.Context, id string, r io.Reader, replace bool) (*PriceListImportResponse, error) {
+	s.logger.Info("Importing price list items", "id", id, "replace", replace)
+
+	priceList, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parsePriceListCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.Status == "" {
+			skus = append(skus, row.SKU)
+		}
+	}
+	products, err := s.productRepo.GetBySKUs(ctx, skus)
+	if err != nil {
+		s.logger.Error("Failed to load products for price list import", "error", err, "id", id)
+		return nil, err
+	}
+	productsBySKU := make(map[string]*models.Product, len(products))
+	for _, product := range products {
+		productsBySKU[product.SKU] = product
+	}
+
+	response := &PriceListImportResponse{TotalRows: len(rows), Rows: rows}
+	items := make([]*models.PriceListItem, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != "" {
+			continue
+		}
+		product, ok := productsBySKU[row.SKU]
+		if !ok {
+			row.Status = PriceListImportStatusFailed
+			row.Error = "product not found"
+			continue
+		}
+		row.ProductID = product.ID
+		items = append(items, &models.PriceListItem{
+			ProductID:     product.ID,
+			Price:         row.Price,
+			EffectiveFrom: row.EffectiveFrom,
+			EffectiveTo:   row.EffectiveTo,
+		})
+	}
+
+	for _, row := range rows {
+		if row.Status == PriceListImportStatusFailed {
+			response.Failed++
+		}
+	}
+
+	// A partial import would leave the list pricing some products from the old rows
+	// and some from the new, so failed rows block the whole import
+	status := PriceListImportStatusValid
+	if response.Failed == 0 {
+		if err := s.priceListRepo.ImportItems(ctx, priceList.ID, items, replace); err != nil {
+			s.logger.Error("Failed to import price list items", "error", err, "id", id)
+			return nil, err
+		}
+		status = PriceListImportStatusImported
+		response.Imported = len(items)
+		response.Replaced = replace
+	}
+	for i := range rows {
+		if rows[i].Status == "" {
+			rows[i].Status = status
+		}
+	}
+
+	s.logger.Info("Price list items import finished",
+		"id", id,
+		"total_rows", response.TotalRows,
+		"imported", response.Imported,
+		"failed", response.Failed)
+
+	return response, nil
+}
+
+// parsePriceListCSV reads "sku,price" rows with optional effective_from and
+// effective_to columns; a header row is optional. Rows that cannot be parsed are
+// returned already marked as failed.
+func parsePriceListCSV(r io.Reader) ([]PriceListImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []PriceListImportRow
+	rowNumber := 0
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+		}
+		rowNumber++
+
+		if rowNumber == 1 && isPriceListHeader(record) {
+			continue
+		}
+		if len(rows) >= priceListImportMaxRows {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("price list import cannot exceed %d rows", priceListImportMaxRows))
+		}
+
+		row := PriceListImportRow{Row: rowNumber}
+		if err := parsePriceListRecord(record, &row); err != nil {
+			row.Status = PriceListImportStatusFailed
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationError("price list import is empty")
+	}
+
+	// Rows of a product may only overlap when they start at different times, which
+	// decides the one that applies
+	starts := make(map[string]bool)
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != "" {
+			continue
+		}
+		start := row.SKU + "@"
+		if row.EffectiveFrom != nil {
+			start += row.EffectiveFrom.UTC().Format(time.RFC3339)
+		}
+		if starts[start] {
+			row.Status = PriceListImportStatusFailed
+			row.Error = "duplicate sku and effective_from in import"
+			continue
+		}
+		starts[start] = true
+	}
+
+	return rows, nil
+}
+
+// isPriceListHeader reports whether the first record is a column header
+func isPriceListHeader(record []string) bool {
+	if len(record) < 2 {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	return err != nil && strings.EqualFold(strings.TrimSpace(record[0]), "sku")
+}
+
+// parsePriceListRecord fills a row from its CSV record
+func parsePriceListRecord(record []string, row *PriceListImportRow) error {
+	if len(record) < 2 {
+		return errors.New("expected sku and price columns")
+	}
+
+	row.SKU = strings.TrimSpace(record[0])
+	if row.SKU == "" {
+		return errors.New("sku is required")
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+	if err != nil || price <= 0 {
+		return errors.New("price must be a positive number")
+	}
+	row.Price = price
+
+	if len(record) > 2 {
+		if row.EffectiveFrom, err = parsePriceListDate(record[2]); err != nil {
+			return fmt.Errorf("invalid effective_from: %w", err)
+		}
+	}
+	if len(record) > 3 {
+		if row.EffectiveTo, err = parsePriceListDate(record[3]); err != nil {
+			return fmt.Errorf("invalid effective_to: %w", err)
+		}
+	}
+	if row.EffectiveFrom != nil && row.EffectiveTo != nil && !row.EffectiveTo.After(*row.EffectiveFrom) {
+		return errors.New("effective_to must be after effective_from")
+	}
+	return nil
+}
+
+// parsePriceListDate parses an effective date; an empty value is an open end
+func parsePriceListDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range priceListDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			parsed = parsed.UTC()
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("expected YYYY-MM-DD or RFC 3339 time, got %q", value)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+)
+
+// PriceListResolver finds the prices customers order at from their B2B price list:
+// their own, or else their organization's. A nil resolver charges every customer
+// list prices.
+type PriceListResolver struct {
+	priceListRepo    repository.PriceListRepository
+	organizationRepo repository.OrganizationRepository
+	logger           *logger.Logger
+}
+
+// NewPriceListResolver creates a price list resolver
+func NewPriceListResolver(
+	priceListRepo repository.PriceListRepository,
+	organizationRepo repository.OrganizationRepository,
+	logger *logger.Logger,
+) *PriceListResolver {
+	return &PriceListResolver{
+		priceListRepo:    priceListRepo,
+		organizationRepo: organizationRepo,
+		logger:           logger,
+	}
+}
+
+// Resolve returns the prices in effect at at of the products in the user's price
+// list, by product ID. Products the list has no price for are left out, and so is
+// every product when the user has no active list; they sell at their list price.
+func (r *PriceListResolver) Resolve(ctx context.Context, user *models.User, productIDs []string, at time.Time) (map[string]float64, error) {
+	if r == nil || len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	priceList, err := r.priceList(ctx, user)
+	if err != nil || priceList == nil {
+		return nil, err
+	}
+
+	prices, err := r.priceListRepo.FindEffectivePrices(ctx, priceList.ID, productIDs, at)
+	if err != nil {
+		r.logger.Error("Failed to find price list prices", "error", err, "price_list_id", priceList.ID)
+		return nil, err
+	}
+
+	r.logger.Debug("Resolved price list prices",
+		"user_id", user.ID, "price_list_id", priceList.ID, "priced", len(prices), "products", len(productIDs))
+	return prices, nil
+}
+
+// priceList returns the active price list the user orders at, if any. The user's own
+// list applies ahead of their organization's, unless it is inactive.
+func (r *PriceListResolver) priceList(ctx context.Context, user *models.User) (*models.PriceList, error) {
+	if user.PriceListID != nil {
+		priceList, err := r.priceListRepo.GetByID(ctx, *user.PriceListID)
+		if err != nil {
+			r.logger.Error("Failed to get user price list", "error", err, "user_id", user.ID)
+			return nil, err
+		}
+		if priceList != nil && priceList.IsActive {
+			return priceList, nil
+		}
+	}
+
+	if user.OrganizationID == nil {
+		return nil, nil
+	}
+	organization, err := r.organizationRepo.GetByID(ctx, *user.OrganizationID)
+	if err != nil {
+		r.logger.Error("Failed to get organization for price list", "error", err, "organization_id", *user.OrganizationID)
+		return nil, err
+	}
+	if organization == nil || organization.PriceListID == nil {
+		return nil, nil
+	}
+
+	priceList, err := r.priceListRepo.GetByID(ctx, *organization.PriceListID)
+	if err != nil {
+		r.logger.Error("Failed to get organization price list", "error", err, "organization_id", organization.ID)
+		return nil, err
+	}
+	if priceList == nil || !priceList.IsActive {
+		return nil, nil
+	}
+	return priceList, nil
+}
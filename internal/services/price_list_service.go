@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// priceListService implements PriceListService interface
+type priceListService struct {
+	priceListRepo    repository.PriceListRepository
+	productRepo      repository.ProductRepository
+	organizationRepo repository.OrganizationRepository
+	userRepo         repository.UserRepository
+	logger           *logger.Logger
+}
+
+// NewPriceListService creates a new price list service
+func NewPriceListService(
+	priceListRepo repository.PriceListRepository,
+	productRepo repository.ProductRepository,
+	organizationRepo repository.OrganizationRepository,
+	userRepo repository.UserRepository,
+	logger *logger.Logger,
+) PriceListService {
+	return &priceListService{
+		priceListRepo:    priceListRepo,
+		productRepo:      productRepo,
+		organizationRepo: organizationRepo,
+		userRepo:         userRepo,
+		logger:           logger,
+	}
+}
+
+func (s *priceListService) CreatePriceList(ctx context.Context, req CreatePriceListRequest) (*PriceListResponse, error) {
+	s.logger.Info("Creating price list", "name", req.Name)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.NewValidationError("price list name is required")
+	}
+
+	existing, err := s.priceListRepo.GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to check existing price list", "error", err, "name", name)
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewDuplicateError("price list", "name", name)
+	}
+
+	priceList := &models.PriceList{
+		Name:        name,
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if err := s.priceListRepo.Create(ctx, priceList); err != nil {
+		s.logger.Error("Failed to create price list", "error", err, "name", name)
+		return nil, err
+	}
+
+	s.logger.Info("Price list created successfully", "id", priceList.ID, "name", name)
+	return newPriceListResponse(priceList, nil), nil
+}
+
+func (s *priceListService) GetPriceList(ctx context.Context, id string) (*PriceListResponse, error) {
+	s.logger.Debug("Getting price list", "id", id)
+
+	priceList, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.priceListRepo.ListItems(ctx, priceList.ID)
+	if err != nil {
+		s.logger.Error("Failed to list price list items", "error", err, "id", id)
+		return nil, err
+	}
+
+	return newPriceListResponse(priceList, items), nil
+}
+
+func (s *priceListService) UpdatePriceList(ctx context.Context, id string, req UpdatePriceListRequest) (*PriceListResponse, error) {
+	s.logger.Info("Updating price list", "id", id)
+
+	priceList, err := s.getPriceList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.NewValidationError("price list name is required")
+		}
+		if name != priceList.Name {
+			existing, err := s.priceListRepo.GetByName(ctx, name)
+			if err != nil {
+				s.logger.Error("Failed to check existing price list", "error", err, "name", name)
+				return nil, err
+			}
+			if existing != nil {
+				return nil, errors.NewDuplicateError("price list", "name", name)
+			}
+			priceList.Name = name
+		}
+	}
+	if req.Description != nil {
+		priceList.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		priceList.IsActive = *req.IsActive
+	}
+
+	if err := s.priceListRepo.Update(ctx, priceList); err != nil {
+		s.logger.Error("Failed to update price list", "error", err, "id", id)
+		return nil, err
+	}
+
+	s.logger.Info("Price list updated successfully", "id", id)
+	return s.GetPriceList(ctx, id)
+}
+
+func (s *priceListService) ListPriceLists(ctx context.Context, req ListPriceListsRequest) (*ListPriceListsResponse, error) {
+	s.logger.Debug("Listing price lists", "page", req.Page, "limit", req.Limit)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	priceLists, err := s.priceListRepo.List(ctx, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list price lists", "error", err)
+		return nil, err
+	}
+
+	total, err := s.priceListRepo.Count(ctx)
+	if err != nil {
+		s.logger.Error("Failed to count price lists", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*PriceListResponse, len(priceLists))
+	for i, priceList := range priceLists {
+		responses[i] = newPriceListResponse(priceList, nil)
+	}
+
+	return &ListPriceListsResponse{
+		PriceLists: responses,
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+	}, nil
+}
+
+func (s *priceListService) AssignToOrganization(ctx context.Context, organizationID string, priceListID *string) error {
+	s.logger.Info("Assigning organization price list", "organization_id", organizationID, "price_list_id", priceListID)
+
+	if organizationID == "" {
+		return errors.NewValidationError("organization ID is required")
+	}
+	organization, err := s.organizationRepo.GetByID(ctx, organizationID)
+	if err != nil {
+		s.logger.Error("Failed to get organization", "error", err, "id", organizationID)
+		return err
+	}
+	if organization == nil {
+		return errors.NewNotFoundErrorWithID("organization", organizationID)
+	}
+
+	if priceListID, err = s.assignablePriceList(ctx, priceListID); err != nil {
+		return err
+	}
+
+	organization.PriceListID = priceListID
+	if err := s.organizationRepo.Update(ctx, organization); err != nil {
+		s.logger.Error("Failed to assign organization price list", "error", err, "organization_id", organizationID)
+		return err
+	}
+
+	s.logger.Info("Organization price list assigned", "organization_id", organizationID, "price_list_id", priceListID)
+	return nil
+}
+
+func (s *priceListService) AssignToUser(ctx context.Context, userID string, priceListID *string) error {
+	s.logger.Info("Assigning user price list", "user_id", userID, "price_list_id", priceListID)
+
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err, "id", userID)
+		return err
+	}
+	if user == nil {
+		return errors.NewNotFoundErrorWithID("user", userID)
+	}
+
+	if priceListID, err = s.assignablePriceList(ctx, priceListID); err != nil {
+		return err
+	}
+
+	user.PriceListID = priceListID
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to assign user price list", "error", err, "user_id", userID)
+		return err
+	}
+
+	s.logger.Info("User price list assigned", "user_id", userID, "price_list_id", priceListID)
+	return nil
+}
+
+// assignablePriceList checks the price list to assign exists, returning nil for an
+// empty ID, which clears the assignment
+func (s *priceListService) assignablePriceList(ctx context.Context, priceListID *string) (*string, error) {
+	if priceListID == nil || *priceListID == "" {
+		return nil, nil
+	}
+	priceList, err := s.getPriceList(ctx, *priceListID)
+	if err != nil {
+		return nil, err
+	}
+	return &priceList.ID, nil
+}
+
+func (s *priceListService) getPriceList(ctx context.Context, id string) (*models.PriceList, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("price list ID is required")
+	}
+
+	priceList, err := s.priceListRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get price list", "error", err, "id", id)
+		return nil, err
+	}
+	if priceList == nil {
+		return nil, errors.NewNotFoundErrorWithID("price list", id)
+	}
+	return priceList, nil
+}
+
+func newPriceListResponse(priceList *models.PriceList, items []*models.PriceListItem) *PriceListResponse {
+	response := &PriceListResponse{
+		ID:          priceList.ID,
+		Name:        priceList.Name,
+		Description: priceList.Description,
+		IsActive:    priceList.IsActive,
+		CreatedAt:   priceList.CreatedAt,
+		UpdatedAt:   priceList.UpdatedAt,
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, &PriceListItemLine{
+			ID:            item.ID,
+			ProductID:     item.ProductID,
+			Price:         item.Price,
+			EffectiveFrom: item.EffectiveFrom,
+			EffectiveTo:   item.EffectiveTo,
+		})
+	}
+	return response
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// productSerialService implements ProductSerialService interface
+type productSerialService struct {
+	serialRepo  repository.ProductSerialRepository
+	productRepo repository.ProductRepository
+	orderRepo   repository.OrderRepository
+	now         func() time.Time
+	logger      *logger.Logger
+}
+
+// NewProductSerialService creates a new product serial service
+func NewProductSerialService(
+	serialRepo repository.ProductSerialRepository,
+	productRepo repository.ProductRepository,
+	orderRepo repository.OrderRepository,
+	logger *logger.Logger,
+) ProductSerialService {
+	return &productSerialService{
+		serialRepo:  serialRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+// ReceiveSerials records the serial numbers of a lot of a serialized product as it is
+// received. Serial numbers the product already has are reported as duplicates rather
+// than moved to the new lot.
+func (s *productSerialService) ReceiveSerials(ctx context.Context, productID string, req ReceiveProductSerialsRequest) (*ReceiveProductSerialsResponse, error) {
+	s.logger.Info("Receiving product serials", "product_id", productID, "lot_number", req.LotNumber, "count", len(req.SerialNumbers))
+
+	lot := strings.TrimSpace(req.LotNumber)
+	if lot == "" {
+		return nil, errors.NewValidationError("lot number is required")
+	}
+	numbers, err := normalizeSerialNumbers(req.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getSerializedProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	serials := make([]*models.ProductSerial, len(numbers))
+	for i, number := range numbers {
+		serials[i] = &models.ProductSerial{
+			ProductID:    productID,
+			SerialNumber: number,
+			LotNumber:    lot,
+			ReceivedAt:   now,
+		}
+	}
+
+	received, err := s.serialRepo.Receive(ctx, serials)
+	if err != nil {
+		s.logger.Error("Failed to receive product serials", "error", err, "product_id", productID)
+		return nil, errors.NewDatabaseError("failed to receive product serials", err)
+	}
+
+	response := &ReceiveProductSerialsResponse{
+		ProductID: productID,
+		LotNumber: lot,
+		Received:  received,
+	}
+	recorded := make(map[string]bool, len(received))
+	for _, serial := range received {
+		recorded[serial.SerialNumber] = true
+	}
+	for _, number := range numbers {
+		if !recorded[number] {
+			response.Duplicates = append(response.Duplicates, number)
+		}
+	}
+
+	s.logger.Info("Product serials received", "product_id", productID, "lot_number", lot,
+		"received", len(received), "duplicates", len(response.Duplicates))
+	return response, nil
+}
+
+func (s *productSerialService) ListSerials(ctx context.Context, productID string, req ListProductSerialsRequest) (*ListProductSerialsResponse, error) {
+	s.logger.Debug("Listing product serials", "product_id", productID, "lot_number", req.LotNumber, "page", req.Page, "limit", req.Limit)
+
+	if _, err := s.getSerializedProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	lot := strings.TrimSpace(req.LotNumber)
+	serials, err := s.serialRepo.ListByProduct(ctx, productID, lot, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list product serials", "error", err, "product_id", productID)
+		return nil, errors.NewDatabaseError("failed to list product serials", err)
+	}
+	total, err := s.serialRepo.CountByProduct(ctx, productID, lot)
+	if err != nil {
+		s.logger.Error("Failed to count product serials", "error", err, "product_id", productID)
+		return nil, errors.NewDatabaseError("failed to count product serials", err)
+	}
+
+	return &ListProductSerialsResponse{
+		Serials: serials,
+		Page:    page,
+		Limit:   limit,
+		Total:   int(total),
+	}, nil
+}
+
+// CaptureSerials captures the serial number of each unit of an order item of a
+// serialized product as it is packed, which the order needs before it can ship. Each
+// serial number must have been received for the product and not be allocated to
+// another order that may still ship.
+func (s *productSerialService) CaptureSerials(ctx context.Context, orderID, itemID string, req CaptureOrderItemSerialsRequest) (*OrderItemDetail, error) {
+	s.logger.Info("Capturing order item serials", "order_id", orderID, "item_id", itemID, "count", len(req.SerialNumbers))
+
+	numbers, err := normalizeSerialNumbers(req.SerialNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for serial capture", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if order.Status != models.OrderStatusConfirmed && order.Status != models.OrderStatusPaid {
+		return nil, errors.NewConflictError(fmt.Sprintf("cannot capture serial numbers of a %s order, only of orders being fulfilled", order.Status))
+	}
+
+	var item *models.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			item = &order.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, errors.NewNotFoundErrorWithID("order item", itemID)
+	}
+	if item.Product == nil || !item.Product.Serialized {
+		return nil, errors.NewValidationError(fmt.Sprintf("product %s is not serialized", item.ProductID))
+	}
+	if len(numbers) != item.Quantity {
+		return nil, errors.NewValidationError(fmt.Sprintf("order item has %d units, got %d serial numbers", item.Quantity, len(numbers)))
+	}
+
+	rejected, err := s.serialRepo.Capture(ctx, item, numbers, s.now())
+	if err != nil {
+		s.logger.Error("Failed to capture order item serials", "error", err, "order_id", orderID, "item_id", itemID)
+		return nil, errors.NewDatabaseError("failed to capture order item serials", err)
+	}
+	if len(rejected) > 0 {
+		return nil, errors.NewValidationError(fmt.Sprintf("serial numbers not received for product %s or allocated to another order: %s",
+			item.ProductID, strings.Join(rejected, ", ")))
+	}
+
+	s.logger.Info("Order item serials captured", "order_id", orderID, "item_id", itemID, "product_id", item.ProductID)
+	detail := newOrderItemDetail(item)
+	return &detail, nil
+}
+
+// getSerializedProduct returns a product, checking that it is serialized
+func (s *productSerialService) getSerializedProduct(ctx context.Context, productID string) (*models.Product, error) {
+	if productID == "" {
+		return nil, errors.NewValidationError("product ID is required")
+	}
+
+	product, err := s.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get product for serials", "error", err, "product_id", productID)
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.NewNotFoundErrorWithID("product", productID)
+	}
+	if !product.Serialized {
+		return nil, errors.NewValidationError(fmt.Sprintf("product %s is not serialized", productID))
+	}
+
+	return product, nil
+}
+
+// normalizeSerialNumbers trims serial numbers and rejects blank and repeated ones
+func normalizeSerialNumbers(serialNumbers []string) ([]string, error) {
+	if len(serialNumbers) == 0 {
+		return nil, errors.NewValidationError("at least one serial number is required")
+	}
+
+	numbers := make([]string, len(serialNumbers))
+	seen := make(map[string]bool, len(serialNumbers))
+	for i, number := range serialNumbers {
+		number = strings.TrimSpace(number)
+		if number == "" {
+			return nil, errors.NewValidationError("serial numbers cannot be blank")
+		}
+		if seen[number] {
+			return nil, errors.NewValidationError(fmt.Sprintf("serial number %s is repeated", number))
+		}
+		seen[number] = true
+		numbers[i] = number
+	}
+
+	return numbers, nil
+}
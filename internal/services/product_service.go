@@ -3,28 +3,47 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/logger"
 )
 
+// maxCartHoldMinutes bounds a product's cart hold window override
+const maxCartHoldMinutes = 1440
+
+// maxLeadTimeDays bounds a product's supplier lead time override
+const maxLeadTimeDays = 365
+
+// maxPurchaseLimitDays bounds the period of a product's purchase limit
+const maxPurchaseLimitDays = 3650
+
 // productService implements ProductService interface
 type productService struct {
 	productRepo   repository.ProductRepository
 	inventoryRepo repository.InventoryRepository
+	availability  AvailabilityService
+	translator    *i18n.Translator
 	logger        *logger.Logger
 }
 
-// NewProductService creates a new product service
+// NewProductService creates a new product service. Products are translated into the
+// locales the translator supports.
 func NewProductService(
 	productRepo repository.ProductRepository,
 	inventoryRepo repository.InventoryRepository,
+	availability AvailabilityService,
+	translator *i18n.Translator,
 	logger *logger.Logger,
 ) ProductService {
 	return &productService{
 		productRepo:   productRepo,
 		inventoryRepo: inventoryRepo,
+		availability:  availability,
+		translator:    translator,
 		logger:        logger,
 	}
 }
@@ -42,6 +61,32 @@ func (s *productService) CreateProduct(ctx context.Context, req CreateProductReq
 	if req.Price <= 0 {
 		return nil, errors.New("product price must be greater than 0")
 	}
+	if req.CostPrice < 0 {
+		return nil, errors.New("product cost price cannot be negative")
+	}
+	if req.CartHoldMinutes < 0 || req.CartHoldMinutes > maxCartHoldMinutes {
+		return nil, fmt.Errorf("cart hold minutes must be between 0 and %d", maxCartHoldMinutes)
+	}
+	if req.LeadTimeDays < 0 || req.LeadTimeDays > maxLeadTimeDays {
+		return nil, fmt.Errorf("lead time days must be between 0 and %d", maxLeadTimeDays)
+	}
+	if req.LowStockThreshold != nil && *req.LowStockThreshold < 0 {
+		return nil, errors.New("low stock threshold cannot be negative")
+	}
+	if req.PurchaseLimit < 0 {
+		return nil, errors.New("purchase limit cannot be negative")
+	}
+	if req.PurchaseLimitDays < 0 || req.PurchaseLimitDays > maxPurchaseLimitDays {
+		return nil, fmt.Errorf("purchase limit days must be between 0 and %d", maxPurchaseLimitDays)
+	}
+	if err := models.ProductMetadataSchema.Validate(req.Metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	translations := models.ProductTranslations{}
+	translations.Merge(req.Translations)
+	if err := s.validateTranslations(translations); err != nil {
+		return nil, err
+	}
 
 	// Check if SKU already exists
 	existingProduct, err := s.productRepo.GetBySKU(ctx, req.SKU)
@@ -54,11 +99,20 @@ func (s *productService) CreateProduct(ctx context.Context, req CreateProductReq
 	}
 
 	product := &models.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		SKU:         req.SKU,
-		IsActive:    true,
+		Name:              req.Name,
+		Description:       req.Description,
+		Price:             req.Price,
+		CostPrice:         req.CostPrice,
+		SKU:               req.SKU,
+		IsActive:          true,
+		Metadata:          models.Metadata(req.Metadata),
+		CartHoldMinutes:   req.CartHoldMinutes,
+		LeadTimeDays:      req.LeadTimeDays,
+		LowStockThreshold: req.LowStockThreshold,
+		PurchaseLimit:     req.PurchaseLimit,
+		PurchaseLimitDays: req.PurchaseLimitDays,
+		Serialized:        req.Serialized,
+		Translations:      translations,
 	}
 
 	// Prepare inventory if initial stock is provided
@@ -97,15 +151,23 @@ func (s *productService) CreateProduct(ctx context.Context, req CreateProductReq
 		stock = req.InitialStock
 	}
 
-	return &ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
-		Stock:       stock,
-	}, nil
+	return s.localize(ctx, product, &ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		CostPrice:         product.CostPrice,
+		SKU:               product.SKU,
+		IsActive:          product.IsActive,
+		Stock:             stock,
+		Metadata:          product.Metadata,
+		CartHoldMinutes:   product.CartHoldMinutes,
+		LeadTimeDays:      product.LeadTimeDays,
+		LowStockThreshold: product.LowStockThreshold,
+		PurchaseLimit:     product.PurchaseLimit,
+		PurchaseLimitDays: product.PurchaseLimitDays,
+		Serialized:        product.Serialized,
+	}), nil
 }
 
 func (s *productService) GetProduct(ctx context.Context, id string) (*ProductResponse, error) {
@@ -125,27 +187,25 @@ func (s *productService) GetProduct(ctx context.Context, id string) (*ProductRes
 		return nil, errors.New("product not found")
 	}
 
-	// Get inventory information
-	inventory, err := s.inventoryRepo.GetByProductID(ctx, id)
-	if err != nil {
-		s.logger.Error("Failed to get product inventory", "error", err, "product_id", id)
-		// Don't fail the request, just log the error
-	}
-
-	stock := 0
-	if inventory != nil {
-		stock = inventory.Available
-	}
-
-	return &ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
-		Stock:       stock,
-	}, nil
+	stock := s.availableStock(ctx, id)
+
+	return s.localize(ctx, product, &ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		CostPrice:         product.CostPrice,
+		SKU:               product.SKU,
+		IsActive:          product.IsActive,
+		Stock:             stock,
+		Metadata:          product.Metadata,
+		CartHoldMinutes:   product.CartHoldMinutes,
+		LeadTimeDays:      product.LeadTimeDays,
+		LowStockThreshold: product.LowStockThreshold,
+		PurchaseLimit:     product.PurchaseLimit,
+		PurchaseLimitDays: product.PurchaseLimitDays,
+		Serialized:        product.Serialized,
+	}), nil
 }
 
 func (s *productService) UpdateProduct(ctx context.Context, id string, req UpdateProductRequest) (*ProductResponse, error) {
@@ -176,9 +236,65 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req Updat
 	if req.Price > 0 {
 		product.Price = req.Price
 	}
+	if req.CostPrice != nil {
+		if *req.CostPrice < 0 {
+			return nil, errors.New("product cost price cannot be negative")
+		}
+		product.CostPrice = *req.CostPrice
+	}
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
 	}
+	if req.CartHoldMinutes != nil {
+		if *req.CartHoldMinutes < 0 || *req.CartHoldMinutes > maxCartHoldMinutes {
+			return nil, fmt.Errorf("cart hold minutes must be between 0 and %d", maxCartHoldMinutes)
+		}
+		product.CartHoldMinutes = *req.CartHoldMinutes
+	}
+	if req.LeadTimeDays != nil {
+		if *req.LeadTimeDays < 0 || *req.LeadTimeDays > maxLeadTimeDays {
+			return nil, fmt.Errorf("lead time days must be between 0 and %d", maxLeadTimeDays)
+		}
+		product.LeadTimeDays = *req.LeadTimeDays
+	}
+	if req.LowStockThreshold != nil {
+		switch {
+		case *req.LowStockThreshold == -1:
+			product.LowStockThreshold = nil
+		case *req.LowStockThreshold < 0:
+			return nil, errors.New("low stock threshold cannot be negative")
+		default:
+			threshold := *req.LowStockThreshold
+			product.LowStockThreshold = &threshold
+		}
+	}
+	if req.PurchaseLimit != nil {
+		if *req.PurchaseLimit < 0 {
+			return nil, errors.New("purchase limit cannot be negative")
+		}
+		product.PurchaseLimit = *req.PurchaseLimit
+	}
+	if req.PurchaseLimitDays != nil {
+		if *req.PurchaseLimitDays < 0 || *req.PurchaseLimitDays > maxPurchaseLimitDays {
+			return nil, fmt.Errorf("purchase limit days must be between 0 and %d", maxPurchaseLimitDays)
+		}
+		product.PurchaseLimitDays = *req.PurchaseLimitDays
+	}
+	if req.Serialized != nil {
+		product.Serialized = *req.Serialized
+	}
+	if len(req.Metadata) > 0 {
+		product.Metadata.Merge(req.Metadata)
+		if err := models.ProductMetadataSchema.Validate(product.Metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+	if len(req.Translations) > 0 {
+		product.Translations.Merge(req.Translations)
+		if err := s.validateTranslations(product.Translations); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.productRepo.Update(ctx, product); err != nil {
 		s.logger.Error("Failed to update product", "error", err, "id", id)
@@ -198,15 +314,23 @@ func (s *productService) UpdateProduct(ctx context.Context, id string, req Updat
 		stock = inventory.Available
 	}
 
-	return &ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
-		Stock:       stock,
-	}, nil
+	return s.localize(ctx, product, &ProductResponse{
+		ID:                product.ID,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		CostPrice:         product.CostPrice,
+		SKU:               product.SKU,
+		IsActive:          product.IsActive,
+		Stock:             stock,
+		Metadata:          product.Metadata,
+		CartHoldMinutes:   product.CartHoldMinutes,
+		LeadTimeDays:      product.LeadTimeDays,
+		LowStockThreshold: product.LowStockThreshold,
+		PurchaseLimit:     product.PurchaseLimit,
+		PurchaseLimitDays: product.PurchaseLimitDays,
+		Serialized:        product.Serialized,
+	}), nil
 }
 
 func (s *productService) ListProducts(ctx context.Context, req ListProductsRequest) (*ListProductsResponse, error) {
@@ -231,9 +355,16 @@ func (s *productService) ListProducts(ctx context.Context, req ListProductsReque
 	var products []*models.Product
 	var err error
 
-	if req.ActiveOnly {
+	// Search also matches the text translated in the request locale
+	locale := i18n.LocaleFromContext(ctx)
+	query := strings.TrimSpace(req.Query)
+
+	switch {
+	case query != "":
+		products, err = s.productRepo.Search(ctx, query, locale, offset, limit)
+	case req.ActiveOnly:
 		products, err = s.productRepo.GetActive(ctx, offset, limit)
-	} else {
+	default:
 		products, err = s.productRepo.List(ctx, offset, limit)
 	}
 
@@ -244,9 +375,12 @@ func (s *productService) ListProducts(ctx context.Context, req ListProductsReque
 
 	// Get total count
 	var totalCount int64
-	if req.ActiveOnly {
+	switch {
+	case query != "":
+		totalCount, err = s.productRepo.CountSearch(ctx, query, locale)
+	case req.ActiveOnly:
 		totalCount, err = s.productRepo.CountActive(ctx)
-	} else {
+	default:
 		totalCount, err = s.productRepo.Count(ctx)
 	}
 
@@ -263,15 +397,23 @@ func (s *productService) ListProducts(ctx context.Context, req ListProductsReque
 			stock = product.Inventory.Available
 		}
 
-		productResponses[i] = &ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			SKU:         product.SKU,
-			IsActive:    product.IsActive,
-			Stock:       stock,
-		}
+		productResponses[i] = s.localize(ctx, product, &ProductResponse{
+			ID:                product.ID,
+			Name:              product.Name,
+			Description:       product.Description,
+			Price:             product.Price,
+			CostPrice:         product.CostPrice,
+			SKU:               product.SKU,
+			IsActive:          product.IsActive,
+			Stock:             stock,
+			Metadata:          product.Metadata,
+			CartHoldMinutes:   product.CartHoldMinutes,
+			LeadTimeDays:      product.LeadTimeDays,
+			LowStockThreshold: product.LowStockThreshold,
+			PurchaseLimit:     product.PurchaseLimit,
+			PurchaseLimitDays: product.PurchaseLimitDays,
+			Serialized:        product.Serialized,
+		})
 	}
 
 	s.logger.Debug("Products listed successfully", "count", len(productResponses))
@@ -283,3 +425,60 @@ func (s *productService) ListProducts(ctx context.Context, req ListProductsReque
 		Total:    int(totalCount),
 	}, nil
 }
+
+// availableStock returns the product's available stock from the storefront read
+// model when configured, or from inventory. Errors are logged and read as no stock.
+func (s *productService) availableStock(ctx context.Context, productID string) int {
+	if s.availability != nil {
+		availability, err := s.availability.GetAvailability(ctx, productID)
+		if err != nil {
+			s.logger.Error("Failed to get product availability", "error", err, "product_id", productID)
+			return 0
+		}
+		return availability.Available
+	}
+
+	inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get product inventory", "error", err, "product_id", productID)
+		return 0
+	}
+	if inventory == nil {
+		return 0
+	}
+	return inventory.Available
+}
+
+// localize fills the response's name and description in the request locale, falling
+// back to the default locale, and lists the product's translations
+func (s *productService) localize(ctx context.Context, product *models.Product, response *ProductResponse) *ProductResponse {
+	response.Name, response.Description, response.Locale = product.Localize(i18n.LocaleFromContext(ctx), i18n.DefaultLocale)
+	if len(product.Translations) > 0 {
+		response.Translations = product.Translations
+	}
+	return response
+}
+
+// validateTranslations checks that products are only translated into supported
+// locales other than the default one, whose text is the product's own
+func (s *productService) validateTranslations(translations models.ProductTranslations) error {
+	if len(translations) == 0 {
+		return nil
+	}
+	if s.translator == nil {
+		return errors.New("invalid translations: products cannot be translated")
+	}
+	for locale, translation := range translations {
+		if locale == i18n.DefaultLocale {
+			return fmt.Errorf("invalid translations: %s is the default locale, set the name and description instead", locale)
+		}
+		if s.translator.Catalog(locale) == nil {
+			return fmt.Errorf("invalid translations: locale %s is not supported, use one of %s",
+				locale, strings.Join(s.translator.Locales(), ", "))
+		}
+		if len(translation.Name) > 255 {
+			return fmt.Errorf("invalid translations: %s name is longer than 255 characters", locale)
+		}
+	}
+	return nil
+}
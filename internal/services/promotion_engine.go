@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+)
+
+// PromotionSettings configures checkout pricing. Every order is charged ShippingFee,
+// unless a free shipping promotion waives it.
+type PromotionSettings struct {
+	ShippingFee float64
+}
+
+// PromotionEngine finds the promotions an order qualifies for and applies them at
+// checkout. A nil engine applies none and charges no shipping.
+type PromotionEngine struct {
+	promotionRepo repository.PromotionRepository
+	orderRepo     repository.OrderRepository
+	settings      PromotionSettings
+	logger        *logger.Logger
+}
+
+// NewPromotionEngine creates a promotion engine
+func NewPromotionEngine(
+	promotionRepo repository.PromotionRepository,
+	orderRepo repository.OrderRepository,
+	settings PromotionSettings,
+	logger *logger.Logger,
+) *PromotionEngine {
+	return &PromotionEngine{
+		promotionRepo: promotionRepo,
+		orderRepo:     orderRepo,
+		settings:      settings,
+		logger:        logger,
+	}
+}
+
+// PromotionLine is an order line promotions are applied to
+type PromotionLine struct {
+	ProductID  string
+	CategoryID *string
+	UnitPrice  float64
+	Quantity   int
+}
+
+// PromotionOutcome is what the applied promotions took off an order: Discount off its
+// items and the ShippingFee left to charge, with each promotion applied in order
+type PromotionOutcome struct {
+	Discount    float64
+	ShippingFee float64
+	Applied     []AppliedPromotion
+}
+
+// EligiblePromotions are the running promotions a customer's order qualifies for
+// before its items are known, in evaluation order
+type EligiblePromotions struct {
+	promotions  []*models.Promotion
+	shippingFee float64
+}
+
+// Eligible returns the promotions running at at that an order of the user on the
+// channel may get: those limited to another channel are left out, and so are
+// first-order promotions unless the user has not ordered before
+func (e *PromotionEngine) Eligible(ctx context.Context, user *models.User, channel string, at time.Time) (*EligiblePromotions, error) {
+	if e == nil {
+		return nil, nil
+	}
+
+	running, err := e.promotionRepo.ListRunning(ctx, at)
+	if err != nil {
+		e.logger.Error("Failed to list running promotions", "error", err)
+		return nil, err
+	}
+
+	var firstOrder *bool
+	eligible := &EligiblePromotions{shippingFee: e.settings.ShippingFee}
+	for _, promotion := range running {
+		if promotion.Channel != "" && promotion.Channel != channel {
+			continue
+		}
+		if promotion.FirstOrderOnly {
+			if firstOrder == nil {
+				count, err := e.orderRepo.CountByUserID(ctx, user.ID)
+				if err != nil {
+					e.logger.Error("Failed to count orders for first-order promotions", "error", err, "user_id", user.ID)
+					return nil, err
+				}
+				isFirst := count == 0
+				firstOrder = &isFirst
+			}
+			if !*firstOrder {
+				continue
+			}
+		}
+		eligible.promotions = append(eligible.promotions, promotion)
+	}
+
+	e.logger.Debug("Found eligible promotions", "user_id", user.ID, "channel", channel,
+		"running", len(running), "eligible", len(eligible.promotions))
+	return eligible, nil
+}
+
+// Apply applies the eligible promotions to an order's lines. Promotions are taken in
+// evaluation order, each discounting what the ones before it left, and skipped when
+// the items total less than their minimum subtotal, when they would take nothing off,
+// or when the stacking rules exclude them. The same lines always get the same outcome.
+func (p *EligiblePromotions) Apply(lines []PromotionLine) *PromotionOutcome {
+	if p == nil {
+		return &PromotionOutcome{}
+	}
+
+	var subtotal float64
+	for _, line := range lines {
+		subtotal += line.UnitPrice * float64(line.Quantity)
+	}
+
+	outcome := &PromotionOutcome{ShippingFee: p.shippingFee}
+	remaining := subtotal
+	usedGroups := make(map[string]bool)
+	for _, promotion := range p.promotions {
+		if subtotal < promotion.MinSubtotal {
+			continue
+		}
+		if promotion.StackGroup != "" && usedGroups[promotion.StackGroup] {
+			continue
+		}
+		if promotion.Exclusive && len(outcome.Applied) > 0 {
+			continue
+		}
+
+		var discount float64
+		switch promotion.Type {
+		case models.PromotionTypePercentOff:
+			discount = math.Min(roundCents(remaining*promotion.PercentOff/100), remaining)
+		case models.PromotionTypeCategoryBOGO:
+			discount = math.Min(categoryBOGODiscount(lines, promotion.CategoryID), remaining)
+		case models.PromotionTypeFreeShipping:
+			discount = outcome.ShippingFee
+		}
+		if discount <= 0 {
+			continue
+		}
+
+		if promotion.Type == models.PromotionTypeFreeShipping {
+			outcome.ShippingFee = 0
+		} else {
+			remaining = roundCents(remaining - discount)
+			outcome.Discount = roundCents(outcome.Discount + discount)
+		}
+		outcome.Applied = append(outcome.Applied, AppliedPromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Type:        promotion.Type,
+			Discount:    discount,
+		})
+		if promotion.StackGroup != "" {
+			usedGroups[promotion.StackGroup] = true
+		}
+		if promotion.Exclusive {
+			break
+		}
+	}
+
+	return outcome
+}
+
+// categoryBOGODiscount returns the price of every second unit of the lines in the
+// category, pairing units from the most expensive down so the cheaper unit of each
+// pair is free
+func categoryBOGODiscount(lines []PromotionLine, categoryID *string) float64 {
+	if categoryID == nil {
+		return 0
+	}
+
+	var prices []float64
+	for _, line := range lines {
+		if line.CategoryID == nil || *line.CategoryID != *categoryID {
+			continue
+		}
+		for i := 0; i < line.Quantity; i++ {
+			prices = append(prices, line.UnitPrice)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+
+	var discount float64
+	for i := 1; i < len(prices); i += 2 {
+		discount += prices[i]
+	}
+	return roundCents(discount)
+}
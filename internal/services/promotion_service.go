@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// promotionService implements PromotionService interface
+type promotionService struct {
+	promotionRepo repository.PromotionRepository
+	logger        *logger.Logger
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(promotionRepo repository.PromotionRepository, logger *logger.Logger) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+		logger:        logger,
+	}
+}
+
+func (s *promotionService) CreatePromotion(ctx context.Context, req CreatePromotionRequest) (*models.Promotion, error) {
+	s.logger.Info("Creating promotion", "name", req.Name, "type", req.Type)
+
+	promotion := &models.Promotion{
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		Type:           req.Type,
+		PercentOff:     req.PercentOff,
+		CategoryID:     req.CategoryID,
+		MinSubtotal:    req.MinSubtotal,
+		FirstOrderOnly: req.FirstOrderOnly,
+		Channel:        req.Channel,
+		Priority:       req.Priority,
+		Exclusive:      req.Exclusive,
+		StackGroup:     req.StackGroup,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		IsActive:       true,
+	}
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, promotion.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(ctx, promotion); err != nil {
+		s.logger.Error("Failed to create promotion", "error", err, "name", promotion.Name)
+		return nil, err
+	}
+
+	s.logger.Info("Promotion created successfully", "id", promotion.ID, "name", promotion.Name)
+	return promotion, nil
+}
+
+func (s *promotionService) GetPromotion(ctx context.Context, id string) (*models.Promotion, error) {
+	s.logger.Debug("Getting promotion", "id", id)
+
+	promotion, err := s.promotionRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get promotion", "error", err, "id", id)
+		return nil, err
+	}
+	if promotion == nil {
+		return nil, errors.NewNotFoundErrorWithID("promotion", id)
+	}
+	return promotion, nil
+}
+
+func (s *promotionService) UpdatePromotion(ctx context.Context, id string, req UpdatePromotionRequest) (*models.Promotion, error) {
+	s.logger.Info("Updating promotion", "id", id)
+
+	promotion, err := s.GetPromotion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != promotion.Name {
+			if err := s.checkNameAvailable(ctx, name); err != nil {
+				return nil, err
+			}
+			promotion.Name = name
+		}
+	}
+	if req.Description != nil {
+		promotion.Description = *req.Description
+	}
+	if req.PercentOff != nil {
+		promotion.PercentOff = *req.PercentOff
+	}
+	if req.CategoryID != nil {
+		promotion.CategoryID = req.CategoryID
+	}
+	if req.MinSubtotal != nil {
+		promotion.MinSubtotal = *req.MinSubtotal
+	}
+	if req.FirstOrderOnly != nil {
+		promotion.FirstOrderOnly = *req.FirstOrderOnly
+	}
+	if req.Channel != nil {
+		promotion.Channel = *req.Channel
+	}
+	if req.Priority != nil {
+		promotion.Priority = *req.Priority
+	}
+	if req.Exclusive != nil {
+		promotion.Exclusive = *req.Exclusive
+	}
+	if req.StackGroup != nil {
+		promotion.StackGroup = *req.StackGroup
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		promotion.EndsAt = *req.EndsAt
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Update(ctx, promotion); err != nil {
+		s.logger.Error("Failed to update promotion", "error", err, "id", id)
+		return nil, err
+	}
+
+	s.logger.Info("Promotion updated successfully", "id", id)
+	return promotion, nil
+}
+
+func (s *promotionService) ListPromotions(ctx context.Context, req ListPromotionsRequest) (*ListPromotionsResponse, error) {
+	s.logger.Debug("Listing promotions", "page", req.Page, "limit", req.Limit, "running", req.Running)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	var activeAt *time.Time
+	if req.Running {
+		now := time.Now()
+		activeAt = &now
+	}
+
+	promotions, err := s.promotionRepo.List(ctx, activeAt, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list promotions", "error", err)
+		return nil, err
+	}
+
+	total, err := s.promotionRepo.Count(ctx, activeAt)
+	if err != nil {
+		s.logger.Error("Failed to count promotions", "error", err)
+		return nil, err
+	}
+
+	return &ListPromotionsResponse{
+		Promotions: promotions,
+		Page:       page,
+		Limit:      limit,
+		Total:      int(total),
+	}, nil
+}
+
+func (s *promotionService) GeneratePromotionReport(ctx context.Context, req PromotionReportRequest) (*PromotionReportResponse, error) {
+	s.logger.Info("Generating promotion report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	summaries, err := s.promotionRepo.SummarizeRedemptions(ctx, startDate, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to summarize promotion redemptions", err)
+	}
+
+	report := &PromotionReportResponse{
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		Promotions: make([]PromotionPerformance, 0, len(summaries)),
+	}
+	for _, summary := range summaries {
+		report.Orders += summary.Orders
+		report.Redeemed += summary.Redeemed
+		report.Discount = roundCents(report.Discount + summary.Discount)
+
+		performance := PromotionPerformance{
+			PromotionID: summary.PromotionID,
+			Name:        summary.Name,
+			Type:        summary.Type,
+			Orders:      summary.Orders,
+			Redeemed:    summary.Redeemed,
+			Discount:    roundCents(summary.Discount),
+			Revenue:     roundCents(summary.Revenue),
+		}
+		if summary.Redeemed > 0 {
+			performance.AverageOrderValue = roundCents(summary.Revenue / float64(summary.Redeemed))
+		}
+		report.Promotions = append(report.Promotions, performance)
+	}
+
+	s.logger.Info("Promotion report generated", "promotions", len(summaries), "redeemed", report.Redeemed, "discount", report.Discount)
+	return report, nil
+}
+
+// checkNameAvailable returns a conflict error if another promotion has the name
+func (s *promotionService) checkNameAvailable(ctx context.Context, name string) error {
+	existing, err := s.promotionRepo.GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to check existing promotion", "error", err, "name", name)
+		return err
+	}
+	if existing != nil {
+		return errors.NewDuplicateError("promotion", "name", name)
+	}
+	return nil
+}
+
+// validatePromotion checks that a promotion has what its type needs and a window
+// that ends after it starts
+func validatePromotion(promotion *models.Promotion) error {
+	if promotion.Name == "" {
+		return errors.NewValidationError("promotion name is required")
+	}
+	if !promotion.Type.IsValid() {
+		return errors.NewValidationError(fmt.Sprintf("invalid promotion type %s", promotion.Type))
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return errors.NewValidationError("promotion must end after it starts")
+	}
+	if promotion.MinSubtotal < 0 || math.IsNaN(promotion.MinSubtotal) {
+		return errors.NewValidationError("minimum subtotal cannot be negative")
+	}
+
+	switch promotion.Type {
+	case models.PromotionTypePercentOff:
+		if promotion.PercentOff <= 0 || promotion.PercentOff > 100 {
+			return errors.NewValidationError("percent off promotions need a percent greater than 0 and at most 100")
+		}
+	case models.PromotionTypeCategoryBOGO:
+		if promotion.CategoryID == nil || *promotion.CategoryID == "" {
+			return errors.NewValidationError("category BOGO promotions need a category")
+		}
+	}
+	return nil
+}
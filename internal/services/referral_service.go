@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// referralCodeAlphabet leaves out characters easily mistaken for one another, such as
+// 0 and O, so codes survive being read out or typed
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	referralCodeLength       = 8
+	referralCodeAttempts     = 5
+	referralRewardBatchSize  = 200
+	storeCreditHistoryLength = 50
+)
+
+// ReferralSettings sets the store credit a referrer earns and how long a referred
+// order must be kept after delivery, so returns don't earn rewards
+type ReferralSettings struct {
+	RewardAmount float64
+	ReturnWindow time.Duration
+}
+
+// referralService implements ReferralService interface
+type referralService struct {
+	settings     ReferralSettings
+	referralRepo repository.ReferralRepository
+	creditRepo   repository.StoreCreditRepository
+	now          func() time.Time
+	logger       *logger.Logger
+}
+
+// NewReferralService creates a new referral service
+func NewReferralService(
+	settings ReferralSettings,
+	referralRepo repository.ReferralRepository,
+	creditRepo repository.StoreCreditRepository,
+	logger *logger.Logger,
+) ReferralService {
+	return &referralService{
+		settings:     settings,
+		referralRepo: referralRepo,
+		creditRepo:   creditRepo,
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+// GetReferralCode returns the user's referral code, creating it the first time
+func (s *referralService) GetReferralCode(ctx context.Context, userID string) (*ReferralCodeResponse, error) {
+	s.logger.Debug("Getting referral code", "user_id", userID)
+
+	code, err := s.referralRepo.GetCodeByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get referral code", err)
+	}
+
+	for attempt := 0; code == nil && attempt < referralCodeAttempts; attempt++ {
+		candidate, err := generateReferralCode()
+		if err != nil {
+			return nil, errors.NewInternalError("failed to generate referral code", err)
+		}
+
+		created, err := s.referralRepo.CreateCode(ctx, &models.ReferralCode{UserID: userID, Code: candidate})
+		if err != nil {
+			return nil, errors.NewDatabaseError("failed to create referral code", err)
+		}
+		if created {
+			s.logger.Info("Referral code created", "user_id", userID, "code", candidate)
+			code = &models.ReferralCode{UserID: userID, Code: candidate, CreatedAt: s.now()}
+			break
+		}
+
+		// Either a concurrent request created the user's code, or the code is taken
+		if code, err = s.referralRepo.GetCodeByUserID(ctx, userID); err != nil {
+			return nil, errors.NewDatabaseError("failed to get referral code", err)
+		}
+	}
+	if code == nil {
+		return nil, errors.NewInternalError("failed to create a unique referral code", nil)
+	}
+
+	return &ReferralCodeResponse{
+		Code:      code.Code,
+		CreatedAt: code.CreatedAt,
+	}, nil
+}
+
+// ListReferrals returns the user's referral code with the referrals made with it,
+// newest first
+func (s *referralService) ListReferrals(ctx context.Context, userID string) (*MyReferralsResponse, error) {
+	s.logger.Debug("Listing referrals", "user_id", userID)
+
+	code, err := s.GetReferralCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	referrals, err := s.referralRepo.ListByReferrerID(ctx, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list referrals", err)
+	}
+
+	response := &MyReferralsResponse{
+		Code:      code.Code,
+		Signups:   len(referrals),
+		Referrals: make([]ReferralResponse, 0, len(referrals)),
+	}
+	for _, referral := range referrals {
+		if referral.Status == models.ReferralStatusRewarded {
+			response.Rewarded++
+			response.RewardsEarned += referral.RewardAmount
+		}
+		response.Referrals = append(response.Referrals, ReferralResponse{
+			ID:           referral.ID,
+			Status:       string(referral.Status),
+			OrderedAt:    referral.OrderedAt,
+			RewardAmount: referral.RewardAmount,
+			RewardedAt:   referral.RewardedAt,
+			CreatedAt:    referral.CreatedAt,
+		})
+	}
+	response.RewardsEarned = roundCents(response.RewardsEarned)
+
+	return response, nil
+}
+
+// GetStoreCredit returns the user's store credit balance with their latest transactions
+func (s *referralService) GetStoreCredit(ctx context.Context, userID string) (*StoreCreditResponse, error) {
+	s.logger.Debug("Getting store credit", "user_id", userID)
+
+	balance, err := s.creditRepo.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get store credit balance", err)
+	}
+
+	transactions, err := s.creditRepo.ListByUserID(ctx, userID, storeCreditHistoryLength)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list store credit transactions", err)
+	}
+	if transactions == nil {
+		transactions = []models.StoreCreditTransaction{}
+	}
+
+	return &StoreCreditResponse{
+		Balance:      roundCents(balance),
+		Transactions: transactions,
+	}, nil
+}
+
+// ProcessRewards settles the referrals whose order was delivered before the return
+// window, or will never be delivered. Each referral is settled on its own, so one
+// failure doesn't hold back the others.
+func (s *referralService) ProcessRewards(ctx context.Context) (*ReferralRewardRunResponse, error) {
+	now := s.now()
+	outcomes, err := s.referralRepo.ListSettleable(ctx, now.Add(-s.settings.ReturnWindow), referralRewardBatchSize)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list settleable referrals", err)
+	}
+
+	run := &ReferralRewardRunResponse{}
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.OrderDeleted || outcome.OrderStatus != models.OrderStatusDelivered:
+			// The order will never be delivered; the referee's next order may qualify
+			released, err := s.referralRepo.ReleaseOrder(ctx, outcome.ID)
+			if err != nil {
+				s.logger.Warn("Failed to release referral order", "error", err, "referral_id", outcome.ID)
+				continue
+			}
+			if released {
+				run.Released++
+			}
+
+		case outcome.Refunded > 0:
+			voided, err := s.referralRepo.Void(ctx, outcome.ID, fmt.Sprintf("order %s was returned", outcome.OrderID))
+			if err != nil {
+				s.logger.Warn("Failed to void referral", "error", err, "referral_id", outcome.ID)
+				continue
+			}
+			if voided {
+				run.Voided++
+			}
+
+		default:
+			rewarded, err := s.reward(ctx, outcome, now)
+			if err != nil {
+				s.logger.Warn("Failed to reward referral", "error", err, "referral_id", outcome.ID)
+				continue
+			}
+			if rewarded {
+				run.Rewarded++
+				run.Credited += s.settings.RewardAmount
+			}
+		}
+	}
+	run.Credited = roundCents(run.Credited)
+
+	if run.Rewarded > 0 || run.Voided > 0 || run.Released > 0 {
+		s.logger.Info("Referral rewards processed", "rewarded", run.Rewarded, "voided", run.Voided,
+			"released", run.Released, "credited", run.Credited)
+	}
+	return run, nil
+}
+
+// reward marks the referral rewarded and grants its referrer the reward as store credit
+func (s *referralService) reward(ctx context.Context, outcome repository.ReferralOrderOutcome, at time.Time) (bool, error) {
+	referral := &models.Referral{
+		ID:           outcome.ID,
+		ReferrerID:   outcome.ReferrerID,
+		RewardAmount: s.settings.RewardAmount,
+		RewardedAt:   &at,
+	}
+	credit := &models.StoreCreditTransaction{
+		UserID:      outcome.ReferrerID,
+		Amount:      s.settings.RewardAmount,
+		Reason:      models.StoreCreditReasonReferralReward,
+		ReferenceID: outcome.ID,
+		Description: fmt.Sprintf("Referral reward for order %s", outcome.OrderID),
+	}
+	return s.referralRepo.Reward(ctx, referral, credit)
+}
+
+// GenerateReferralReport reports the referrals made in an inclusive range of UTC days
+// (default: the last 7 days) by referrer
+func (s *referralService) GenerateReferralReport(ctx context.Context, req ReferralReportRequest) (*ReferralReportResponse, error) {
+	s.logger.Info("Generating referral report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	summaries, err := s.referralRepo.SummarizeByReferrer(ctx, startDate, end)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to summarize referrals", err)
+	}
+
+	var totals repository.ReferrerSummary
+	report := &ReferralReportResponse{
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		ByReferrer: make([]ReferrerPerformance, 0, len(summaries)),
+	}
+	for _, summary := range summaries {
+		totals.Signups += summary.Signups
+		totals.Ordered += summary.Ordered
+		totals.Rewarded += summary.Rewarded
+		totals.Voided += summary.Voided
+		totals.Rewards += summary.Rewards
+		totals.Revenue += summary.Revenue
+
+		report.ByReferrer = append(report.ByReferrer, ReferrerPerformance{
+			UserID:        summary.ReferrerID,
+			Name:          summary.Name,
+			Email:         summary.Email,
+			ReferralStats: referralStats(summary),
+		})
+	}
+	report.Totals = referralStats(totals)
+
+	s.logger.Info("Referral report generated", "referrers", len(summaries), "signups", totals.Signups, "rewarded", totals.Rewarded)
+	return report, nil
+}
+
+// ResolveReferralCode returns the ID of the user owning the code. Codes are matched
+// regardless of case and surrounding spaces.
+func (s *referralService) ResolveReferralCode(ctx context.Context, code string) (string, error) {
+	referralCode, err := s.referralRepo.GetCodeByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return "", errors.NewDatabaseError("failed to get referral code", err)
+	}
+	if referralCode == nil {
+		return "", errors.NewValidationError("invalid referral code")
+	}
+	return referralCode.UserID, nil
+}
+
+// AttributeSignup records the referral of a new user. Attribution is best-effort and
+// never fails the signup.
+func (s *referralService) AttributeSignup(ctx context.Context, referrerID, userID, code string) {
+	if referrerID == userID {
+		return
+	}
+
+	referral := &models.Referral{
+		ReferrerID: referrerID,
+		RefereeID:  userID,
+		Code:       strings.ToUpper(strings.TrimSpace(code)),
+		Status:     models.ReferralStatusSignedUp,
+	}
+	created, err := s.referralRepo.Create(ctx, referral)
+	if err != nil {
+		s.logger.Warn("Failed to attribute referred signup", "error", err, "referrer_id", referrerID, "user_id", userID)
+		return
+	}
+	if !created {
+		s.logger.Warn("User was already referred", "referrer_id", referrerID, "user_id", userID)
+	}
+}
+
+// RecordActivity attributes a referred user's first order, or the order placed after
+// their first one was cancelled, to their referral
+func (s *referralService) RecordActivity(ctx context.Context, userID string, eventType models.ActivityEventType, entityID string) {
+	if eventType != models.ActivityEventOrderPlaced || userID == "" {
+		return
+	}
+
+	if _, err := s.referralRepo.AttributeOrder(ctx, userID, entityID, s.now()); err != nil {
+		s.logger.Warn("Failed to attribute order to referral", "error", err, "user_id", userID, "order_id", entityID)
+	}
+}
+
+// referralStats converts summed referrals, computing the conversion rate
+func referralStats(summary repository.ReferrerSummary) ReferralStats {
+	stats := ReferralStats{
+		Signups:  summary.Signups,
+		Ordered:  summary.Ordered,
+		Rewarded: summary.Rewarded,
+		Voided:   summary.Voided,
+		Rewards:  roundCents(summary.Rewards),
+		Revenue:  roundCents(summary.Revenue),
+	}
+	if summary.Signups > 0 {
+		stats.ConversionRate = roundCents(float64(summary.Ordered) / float64(summary.Signups) * 100)
+	}
+	return stats
+}
+
+// generateReferralCode returns a random referral code
+func generateReferralCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(referralCodeAlphabet)))
+	code := make([]byte, referralCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = referralCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+)
+
+// ReportCompletionNotifier tells the user who queued a report that it finished. The
+// notifications go through the digest scheduler, so reports queued together arrive as
+// one hourly summary.
+type ReportCompletionNotifier struct {
+	digests NotificationDigester
+	logger  *logger.Logger
+}
+
+// NewReportCompletionNotifier creates a notifier for finished reports
+func NewReportCompletionNotifier(digests NotificationDigester, logger *logger.Logger) *ReportCompletionNotifier {
+	return &ReportCompletionNotifier{
+		digests: digests,
+		logger:  logger,
+	}
+}
+
+// NotifyReportCompleted notifies the user who queued a report that it is ready to
+// download, or that it failed. It is the report manager's completion handler.
+func (n *ReportCompletionNotifier) NotifyReportCompleted(req *reports.ReportRequest, result *reports.ReportResult) {
+	if req.UserID == "" {
+		return
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"result_id":  result.ID,
+		"request_id": req.ID,
+		"type":       result.Type,
+		"format":     result.Format,
+		"status":     result.Status,
+		"error":      result.Error,
+	})
+	if err != nil {
+		n.logger.Error("Failed to encode report notification", "error", err, "result_id", result.ID)
+		return
+	}
+
+	name := strings.ReplaceAll(string(result.Type), "_", " ")
+	notification := SendNotificationRequest{
+		UserID:  req.UserID,
+		Type:    string(models.NotificationTypeReportCompleted),
+		Channel: string(models.NotificationChannelInApp),
+		Title:   fmt.Sprintf("Your %s report is ready", name),
+		Body:    fmt.Sprintf("The %s report you queued is ready to download from report result %s.", name, result.ID),
+		Data:    string(data),
+	}
+	if result.Status == reports.ReportStatusFailed {
+		notification.Title = fmt.Sprintf("Your %s report failed", name)
+		notification.Body = fmt.Sprintf("The %s report you queued could not be generated: %s. Queue it again to retry.", name, result.Error)
+	}
+
+	if err := n.digests.Submit(newDigestNotification(notification)); err != nil {
+		n.logger.Error("Failed to submit report notification", "error", err, "result_id", result.ID, "user_id", req.UserID)
+	}
+}
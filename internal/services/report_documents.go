@@ -0,0 +1,167 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"easy-orders-backend/pkg/documents"
+)
+
+// DocumentFormat returns the requested format, JSON when none was
+func (q DocumentQuery) DocumentFormat() documents.Format {
+	if q.Format == "" {
+		return documents.FormatJSON
+	}
+	return documents.Format(q.Format)
+}
+
+// Document lays out the sales report for printing: the day's totals, then its
+// sales by channel, order statuses, product sales and category margins
+func (r *SalesReportResponse) Document() *documents.Document {
+	doc := &documents.Document{
+		Title: "Daily Sales Report",
+		Details: []documents.Field{
+			{Label: "Date", Value: r.Date},
+			{Label: "Orders", Value: fmt.Sprintf("%d (%d completed, %d cancelled, %d returned)",
+				r.TotalOrders, r.CompletedOrders, r.CancelledOrders, r.ReturnedOrders)},
+			{Label: "Gross sales", Value: formatReportAmount(r.GrossSales)},
+			{Label: "Price overrides", Value: formatReportAmount(r.PriceOverrides)},
+			{Label: "Refunded", Value: formatReportAmount(r.RefundedAmount)},
+			{Label: "Net sales", Value: formatReportAmount(r.NetSales)},
+			{Label: "Cost of goods", Value: formatReportAmount(r.CostOfGoods)},
+			{Label: "Gross margin", Value: formatReportAmount(r.GrossMargin) + " (" + formatReportPercent(r.MarginPercent) + ")"},
+			{Label: "Average order value", Value: formatReportAmount(r.AverageOrderValue)},
+			{Label: "Return rate", Value: formatReportPercent(r.ReturnRate)},
+		},
+	}
+
+	channels := documents.Section{Title: "Sales by Channel", Table: documents.Table{Columns: []string{"Channel", "Orders", "Sales"}}}
+	for _, channel := range breakdownKeys(r.OrdersByChannel) {
+		channels.Table.Rows = append(channels.Table.Rows, []string{
+			channel, strconv.Itoa(r.OrdersByChannel[channel]), formatReportAmount(r.SalesByChannel[channel]),
+		})
+	}
+
+	statuses := documents.Section{Title: "Orders by Status", Table: documents.Table{Columns: []string{"Status", "Orders"}}}
+	for _, status := range breakdownKeys(r.OrdersByStatus) {
+		statuses.Table.Rows = append(statuses.Table.Rows, []string{status, strconv.Itoa(r.OrdersByStatus[status])})
+	}
+
+	products := documents.Section{Title: "Product Sales", Table: documents.Table{
+		Columns: []string{"SKU", "Product", "Sold", "Returned", "Gross", "Refunded", "Net", "Margin %"},
+	}}
+	for _, product := range r.ProductSales {
+		name := product.ProductName
+		if product.LowMargin {
+			name += " *"
+		}
+		products.Table.Rows = append(products.Table.Rows, []string{
+			product.SKU, name, strconv.Itoa(product.QuantitySold), strconv.Itoa(product.QuantityReturned),
+			formatReportAmount(product.GrossRevenue), formatReportAmount(product.RefundedAmount),
+			formatReportAmount(product.NetRevenue), formatReportPercent(product.MarginPercent),
+		})
+	}
+	products.Table.Footer = []string{"", "Total", "", "", formatReportAmount(r.GrossSales),
+		formatReportAmount(r.RefundedAmount), formatReportAmount(r.NetSales), formatReportPercent(r.MarginPercent)}
+
+	categories := documents.Section{Title: "Category Margins", Table: documents.Table{
+		Columns: []string{"Category", "Net", "Cost of goods", "Margin", "Margin %"},
+	}}
+	for _, category := range r.CategoryMargins {
+		categories.Table.Rows = append(categories.Table.Rows, []string{
+			valueOr(category.CategoryID, "Uncategorized"), formatReportAmount(category.NetRevenue),
+			formatReportAmount(category.CostOfGoods), formatReportAmount(category.GrossMargin), formatReportPercent(category.MarginPercent),
+		})
+	}
+
+	doc.Sections = []documents.Section{channels, statuses, products, categories}
+	if len(r.LowMarginProducts) > 0 {
+		doc.Notes = append(doc.Notes, "* Margin below the low margin threshold of "+formatReportPercent(r.LowMarginThreshold))
+	}
+	return doc
+}
+
+// Document lays out the user activity report for printing: its totals, engagement
+// by activity type and active users per day
+func (r *UserActivityReportResponse) Document() *documents.Document {
+	doc := &documents.Document{
+		Title: "User Activity Report",
+		Details: []documents.Field{
+			{Label: "Period", Value: r.StartDate + " - " + r.EndDate},
+			{Label: "Active users", Value: strconv.Itoa(r.ActiveUsers)},
+			{Label: "New users", Value: strconv.Itoa(r.NewUsers)},
+			{Label: "Purchasing users", Value: strconv.Itoa(r.PurchasingUsers)},
+			{Label: "Conversion rate", Value: formatReportPercent(r.ConversionRate)},
+			{Label: "Events", Value: fmt.Sprintf("%d (%.2f per user)", r.TotalEvents, r.EventsPerUser)},
+		},
+	}
+
+	engagement := documents.Section{Title: "Engagement", Table: documents.Table{Columns: []string{"Activity", "Events", "Users"}}}
+	for _, activity := range r.Engagement {
+		engagement.Table.Rows = append(engagement.Table.Rows, []string{activity.Type, strconv.Itoa(activity.Events), strconv.Itoa(activity.Users)})
+	}
+
+	days := documents.Section{Title: "Daily Active Users", Table: documents.Table{Columns: []string{"Date", "Active users"}}}
+	for _, day := range r.DailyActiveUsers {
+		days.Table.Rows = append(days.Table.Rows, []string{day.Date, strconv.Itoa(day.ActiveUsers)})
+	}
+
+	doc.Sections = []documents.Section{engagement, days}
+	return doc
+}
+
+// Document lays out the low stock alert for printing, one row per product
+func (r *LowStockResponse) Document() *documents.Document {
+	threshold := strconv.Itoa(r.Threshold)
+	if r.PerProduct {
+		threshold = "Per product"
+	}
+
+	doc := &documents.Document{
+		Title: "Low Stock Report",
+		Details: []documents.Field{
+			{Label: "Threshold", Value: threshold},
+			{Label: "Products", Value: strconv.Itoa(r.Count)},
+		},
+		Table: documents.Table{
+			Columns: []string{"SKU", "Product", "Sellable", "Physical", "Safety", "Threshold", "Source", "Per day", "Days left"},
+		},
+	}
+	for _, product := range r.Products {
+		productThreshold, velocity, cover := strconv.Itoa(product.MinThreshold), "", ""
+		if r.PerProduct {
+			productThreshold = strconv.Itoa(product.Threshold)
+			velocity = strconv.FormatFloat(product.DailyVelocity, 'f', 2, 64)
+		}
+		if product.DaysOfCover != nil {
+			cover = strconv.FormatFloat(*product.DaysOfCover, 'f', 1, 64)
+		}
+		doc.Table.Rows = append(doc.Table.Rows, []string{
+			product.SKU, product.ProductName, strconv.Itoa(product.CurrentStock),
+			strconv.Itoa(product.PhysicalStock), strconv.Itoa(product.SafetyStock), productThreshold,
+			product.ThresholdSource, velocity, cover,
+		})
+	}
+	return doc
+}
+
+// formatReportAmount prints an amount on a printed report
+func formatReportAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatReportPercent prints a percentage on a printed report
+func formatReportPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', 1, 64) + "%"
+}
+
+// breakdownKeys returns the keys of a report breakdown in order
+func breakdownKeys(breakdown map[string]int) []string {
+	keys := make([]string, 0, len(breakdown))
+	for key := range breakdown {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+
+	"github.com/google/uuid"
+)
+
+// reportQueueService implements ReportQueueService interface
+type reportQueueService struct {
+	manager *reports.ReportManager
+	logger  *logger.Logger
+}
+
+// NewReportQueueService creates a new report queue service
+func NewReportQueueService(manager *reports.ReportManager, logger *logger.Logger) ReportQueueService {
+	return &reportQueueService{
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// QueueReport queues the report and returns its pending result. The report outlives
+// the request that queued it.
+func (s *reportQueueService) QueueReport(ctx context.Context, userID string, req QueueReportRequest) (*ReportResultResponse, error) {
+	s.logger.Info("Queueing report", "type", req.Type, "format", req.Format, "user_id", userID)
+
+	format := reports.ReportFormatJSON
+	if req.Format != "" {
+		format = reports.ReportFormat(req.Format)
+	}
+
+	result, err := s.manager.GenerateReportAsync(context.WithoutCancel(ctx), &reports.ReportRequest{
+		ID:         uuid.New().String(),
+		Type:       reports.ReportType(req.Type),
+		Format:     format,
+		Priority:   reports.ReportPriorityNormal,
+		Parameters: req.Parameters,
+		Timezone:   req.Timezone,
+		Locale:     req.Locale,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to queue report", "error", err, "type", req.Type)
+		if strings.Contains(err.Error(), "invalid report request") {
+			return nil, errors.NewValidationError(err.Error())
+		}
+		return nil, errors.NewDatabaseError("failed to queue report", err)
+	}
+
+	s.logger.Info("Report queued", "id", result.ID, "type", req.Type, "status", string(result.Status))
+	return s.toReportResultResponse(result), nil
+}
+
+// GetReportResult returns the stored result of a queued report
+func (s *reportQueueService) GetReportResult(ctx context.Context, id string) (*ReportResultResponse, error) {
+	s.logger.Debug("Getting report result", "id", id)
+
+	result, err := s.manager.GetResult(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get report result", "error", err, "id", id)
+		if stderrors.Is(err, reports.ErrResultsNotStored) {
+			return nil, errors.NewInternalError("report results are not stored", err)
+		}
+		return nil, errors.NewDatabaseError("failed to get report result", err)
+	}
+	if result == nil {
+		return nil, errors.NewNotFoundErrorWithID("Report result", id)
+	}
+
+	return s.toReportResultResponse(result), nil
+}
+
+// DownloadReport opens the rendered file of a completed report
+func (s *reportQueueService) DownloadReport(ctx context.Context, id string) (*ReportDownload, error) {
+	s.logger.Debug("Downloading report", "id", id)
+
+	result, err := s.manager.GetResult(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get report result", "error", err, "id", id)
+		if stderrors.Is(err, reports.ErrResultsNotStored) {
+			return nil, errors.NewInternalError("report results are not stored", err)
+		}
+		return nil, errors.NewDatabaseError("failed to get report result", err)
+	}
+	if result == nil {
+		return nil, errors.NewNotFoundErrorWithID("Report result", id)
+	}
+	if result.Status != reports.ReportStatusCompleted {
+		return nil, errors.NewConflictError(fmt.Sprintf("report has no file to download while %s", result.Status))
+	}
+
+	file, err := s.manager.OpenFile(ctx, result)
+	if err != nil {
+		if stderrors.Is(err, reports.ErrNoReportFile) {
+			return nil, errors.NewConflictError("report has no file to download")
+		}
+		s.logger.Error("Failed to open report file", "error", err, "id", id)
+		return nil, errors.NewInternalError("failed to open report file", err)
+	}
+
+	return &ReportDownload{
+		Filename:    fmt.Sprintf("%s-report.%s", result.Type, result.Format),
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		Content:     file.Content,
+		URL:         file.URL,
+	}, nil
+}
+
+// toReportResultResponse converts a report result to a response
+func (s *reportQueueService) toReportResultResponse(result *reports.ReportResult) *ReportResultResponse {
+	response := &ReportResultResponse{
+		ID:           result.ID,
+		Type:         string(result.Type),
+		Format:       string(result.Format),
+		Status:       string(result.Status),
+		FileSize:     result.FileSize,
+		RowCount:     result.RowCount,
+		Error:        result.Error,
+		ProcessingMs: result.ProcessingTime.Milliseconds(),
+		GeneratedAt:  result.GeneratedAt,
+		ExpiresAt:    result.ExpiresAt,
+	}
+	// PDF reports are downloaded as their content rather than their data
+	if result.Format != reports.ReportFormatPDF {
+		response.Data = result.Data
+	}
+	return response
+}
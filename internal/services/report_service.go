@@ -3,11 +3,15 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
 )
 
 // reportService implements ReportService interface
@@ -16,6 +20,9 @@ type reportService struct {
 	paymentRepo   repository.PaymentRepository
 	inventoryRepo repository.InventoryRepository
 	productRepo   repository.ProductRepository
+	activityRepo  repository.ActivityEventRepository
+	attemptRepo   repository.PaymentAttemptRepository
+	notifications repository.NotificationRepository
 	logger        *logger.Logger
 }
 
@@ -25,6 +32,9 @@ func NewReportService(
 	paymentRepo repository.PaymentRepository,
 	inventoryRepo repository.InventoryRepository,
 	productRepo repository.ProductRepository,
+	activityRepo repository.ActivityEventRepository,
+	attemptRepo repository.PaymentAttemptRepository,
+	notifications repository.NotificationRepository,
 	logger *logger.Logger,
 ) ReportService {
 	return &reportService{
@@ -32,12 +42,23 @@ func NewReportService(
 		paymentRepo:   paymentRepo,
 		inventoryRepo: inventoryRepo,
 		productRepo:   productRepo,
+		activityRepo:  activityRepo,
+		attemptRepo:   attemptRepo,
+		notifications: notifications,
 		logger:        logger,
 	}
 }
 
-func (s *reportService) GenerateDailySalesReport(ctx context.Context, date string) (*SalesReportResponse, error) {
-	s.logger.Info("Generating daily sales report", "date", date)
+// DefaultLowMarginThreshold is the margin percentage below which products are
+// flagged when the caller does not provide a threshold
+const DefaultLowMarginThreshold = 20.0
+
+func (s *reportService) GenerateDailySalesReport(ctx context.Context, date string, lowMarginThreshold float64) (*SalesReportResponse, error) {
+	s.logger.Info("Generating daily sales report", "date", date, "low_margin_threshold", lowMarginThreshold)
+
+	if lowMarginThreshold <= 0 {
+		lowMarginThreshold = DefaultLowMarginThreshold
+	}
 
 	if date == "" {
 		date = time.Now().Format("2006-01-02")
@@ -67,6 +88,11 @@ func (s *reportService) GenerateDailySalesReport(ctx context.Context, date strin
 	var completedOrders int
 	var cancelledOrders int
 	ordersByStatus := make(map[string]int)
+	var refundedAmount float64
+	var returnedOrders int
+	ordersByChannel := make(map[string]int)
+	salesByChannel := make(map[string]float64)
+	productSales := make(map[string]*ProductSalesSummary)
 
 	for _, order := range orders {
 		totalOrders++
@@ -74,11 +100,25 @@ func (s *reportService) GenerateDailySalesReport(ctx context.Context, date strin
 		statusStr := string(order.Status)
 		ordersByStatus[statusStr]++
 
+		channel := order.Channel
+		if channel == "" {
+			channel = models.OrderChannelDirect
+		}
+		ordersByChannel[channel]++
+
 		switch order.Status {
 		case models.OrderStatusDelivered:
 			completedOrders++
 			// Only count completed/delivered orders in total sales
 			totalSales += order.TotalAmount
+			salesByChannel[channel] += order.TotalAmount
+
+			orderRefund := orderRefundedAmount(order)
+			if orderRefund > 0 {
+				returnedOrders++
+				refundedAmount += orderRefund
+			}
+			accumulateProductSales(productSales, order, orderRefund)
 		case models.OrderStatusCancelled:
 			cancelledOrders++
 		}
@@ -90,17 +130,830 @@ func (s *reportService) GenerateDailySalesReport(ctx context.Context, date strin
 		averageOrderValue = totalSales / float64(completedOrders)
 	}
 
+	returnRate := float64(0)
+	if completedOrders > 0 {
+		returnRate = float64(returnedOrders) / float64(completedOrders)
+	}
+
+	productSummaries := sortedProductSales(productSales, lowMarginThreshold)
+
+	var costOfGoods, listSales, priceOverrideDiscount float64
+	var lowMarginProducts []string
+	for _, summary := range productSummaries {
+		costOfGoods += summary.CostOfGoods
+		listSales += summary.ListRevenue
+		priceOverrideDiscount += summary.PriceOverrideDiscount
+		if summary.LowMargin {
+			lowMarginProducts = append(lowMarginProducts, summary.ProductID)
+		}
+	}
+	netSales := totalSales - refundedAmount
+
 	report := &SalesReportResponse{
-		Date:              date,
-		TotalSales:        totalSales,
-		TotalOrders:       totalOrders,
-		CompletedOrders:   completedOrders,
-		CancelledOrders:   cancelledOrders,
-		AverageOrderValue: averageOrderValue,
-		OrdersByStatus:    ordersByStatus,
+		Date:               date,
+		TotalSales:         totalSales,
+		GrossSales:         totalSales,
+		ListSales:          listSales,
+		PriceOverrides:     priceOverrideDiscount,
+		RefundedAmount:     refundedAmount,
+		NetSales:           netSales,
+		CostOfGoods:        costOfGoods,
+		GrossMargin:        netSales - costOfGoods,
+		MarginPercent:      marginPercent(netSales, netSales-costOfGoods),
+		TotalOrders:        totalOrders,
+		CompletedOrders:    completedOrders,
+		CancelledOrders:    cancelledOrders,
+		ReturnedOrders:     returnedOrders,
+		ReturnRate:         returnRate,
+		AverageOrderValue:  averageOrderValue,
+		OrdersByStatus:     ordersByStatus,
+		OrdersByChannel:    ordersByChannel,
+		SalesByChannel:     salesByChannel,
+		ProductSales:       productSummaries,
+		CategoryMargins:    categoryMargins(productSales, productCategories(orders)),
+		LowMarginThreshold: lowMarginThreshold,
+		LowMarginProducts:  lowMarginProducts,
+	}
+
+	s.logger.Info("Daily sales report generated", "date", date, "total_sales", totalSales,
+		"net_sales", report.NetSales, "total_orders", totalOrders, "returned_orders", returnedOrders,
+		"margin_percent", report.MarginPercent, "low_margin_products", len(lowMarginProducts))
+
+	return report, nil
+}
+
+// maxReportRangeDays bounds the range of the date range reports
+const maxReportRangeDays = 366
+
+// parseReportDateRange parses an inclusive range of UTC days, defaulting to the last 7 days.
+// It returns the start and end days and the exclusive end of the range for queries.
+func parseReportDateRange(startParam, endParam string) (startDate, endDate, end time.Time, err error) {
+	// Default to the last 7 days, including today
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	startDate, endDate = today.AddDate(0, 0, -6), today
+
+	if startParam != "" {
+		if startDate, err = time.Parse("2006-01-02", startParam); err != nil {
+			return startDate, endDate, end, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+	if endParam != "" {
+		if endDate, err = time.Parse("2006-01-02", endParam); err != nil {
+			return startDate, endDate, end, errors.New("invalid date format, use YYYY-MM-DD")
+		}
+	}
+	if endDate.Before(startDate) {
+		return startDate, endDate, end, errors.New("invalid date range: end date is before start date")
+	}
+
+	// The end date is inclusive, so the query range ends at the following midnight
+	end = endDate.AddDate(0, 0, 1)
+	if end.Sub(startDate) > maxReportRangeDays*24*time.Hour {
+		return startDate, endDate, end, fmt.Errorf("invalid date range: at most %d days", maxReportRangeDays)
+	}
+
+	return startDate, endDate, end, nil
+}
+
+func (s *reportService) GenerateUserActivityReport(ctx context.Context, req UserActivityReportRequest) (*UserActivityReportResponse, error) {
+	s.logger.Info("Generating user activity report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Daily sales report generated", "date", date, "total_sales", totalSales, "total_orders", totalOrders)
+	activeUsers, err := s.activityRepo.CountActiveUsers(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to count active users", "error", err)
+		return nil, err
+	}
+
+	typeCounts, err := s.activityRepo.CountByType(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to count activity by type", "error", err)
+		return nil, err
+	}
+
+	dailyCounts, err := s.activityRepo.DailyActiveUsers(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to count daily active users", "error", err)
+		return nil, err
+	}
+
+	report := &UserActivityReportResponse{
+		StartDate:        startDate.Format("2006-01-02"),
+		EndDate:          endDate.Format("2006-01-02"),
+		ActiveUsers:      int(activeUsers),
+		Engagement:       make([]ActivityEngagement, 0, len(typeCounts)),
+		DailyActiveUsers: dailyActiveUsers(startDate, end, dailyCounts),
+	}
+
+	for _, count := range typeCounts {
+		report.TotalEvents += int(count.Events)
+		report.Engagement = append(report.Engagement, ActivityEngagement{
+			Type:   string(count.Type),
+			Events: int(count.Events),
+			Users:  int(count.Users),
+		})
+
+		switch count.Type {
+		case models.ActivityEventRegistered:
+			report.NewUsers = int(count.Users)
+		case models.ActivityEventOrderPlaced:
+			report.PurchasingUsers = int(count.Users)
+		}
+	}
+
+	if report.ActiveUsers > 0 {
+		report.ConversionRate = math.Round(float64(report.PurchasingUsers)/float64(report.ActiveUsers)*10000) / 100
+		report.EventsPerUser = math.Round(float64(report.TotalEvents)/float64(report.ActiveUsers)*100) / 100
+	}
+
+	s.logger.Info("User activity report generated", "start_date", report.StartDate, "end_date", report.EndDate,
+		"active_users", report.ActiveUsers, "new_users", report.NewUsers, "total_events", report.TotalEvents)
 
 	return report, nil
 }
+
+func (s *reportService) GenerateSettlementReport(ctx context.Context, req SettlementReportRequest) (*SettlementReportResponse, error) {
+	s.logger.Info("Generating settlement report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.paymentRepo.SummarizeSettlement(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to summarize payment settlement", "error", err)
+		return nil, err
+	}
+
+	report := &SettlementReportResponse{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Methods:   make([]SettlementMethodSummary, 0, len(summaries)),
+	}
+
+	for _, summary := range summaries {
+		method := newSettlementMethodSummary(summary)
+		report.Methods = append(report.Methods, method)
+
+		report.Totals.Payments += method.Payments
+		report.Totals.GrossAmount += method.GrossAmount
+		report.Totals.Surcharges += method.Surcharges
+		report.Totals.Discounts += method.Discounts
+		report.Totals.ProcessingFees += method.ProcessingFees
+	}
+	report.Totals = newSettlementTotals(report.Totals)
+
+	s.logger.Info("Settlement report generated", "start_date", report.StartDate, "end_date", report.EndDate,
+		"payments", report.Totals.Payments, "fee_variance", report.Totals.FeeVariance)
+
+	return report, nil
+}
+
+// newSettlementMethodSummary reconciles the adjustments collected for a payment method against its gateway fees
+func newSettlementMethodSummary(summary repository.PaymentSettlementSummary) SettlementMethodSummary {
+	totals := newSettlementTotals(SettlementTotals{
+		Payments:       int(summary.Payments),
+		GrossAmount:    summary.GrossAmount,
+		Surcharges:     summary.Surcharges,
+		Discounts:      summary.Discounts,
+		ProcessingFees: summary.ProcessingFees,
+	})
+	return SettlementMethodSummary{Method: summary.Method, SettlementTotals: totals}
+}
+
+// newSettlementTotals rounds the sums and derives the net settlement and fee variance
+func newSettlementTotals(totals SettlementTotals) SettlementTotals {
+	totals.GrossAmount = roundCents(totals.GrossAmount)
+	totals.Surcharges = roundCents(totals.Surcharges)
+	totals.Discounts = roundCents(totals.Discounts)
+	totals.ProcessingFees = roundCents(totals.ProcessingFees)
+	totals.NetAdjustment = roundCents(totals.Surcharges - totals.Discounts)
+	totals.NetSettlement = roundCents(totals.GrossAmount - totals.ProcessingFees)
+	totals.FeeVariance = roundCents(totals.NetAdjustment - totals.ProcessingFees)
+	return totals
+}
+
+func (s *reportService) GeneratePaymentFailureReport(ctx context.Context, req PaymentFailureReportRequest) (*PaymentFailureReportResponse, error) {
+	s.logger.Info("Generating payment failure report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.attemptRepo.SummarizeAttempts(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to summarize payment attempts", "error", err)
+		return nil, err
+	}
+
+	var totals PaymentFailureStats
+	byFailureType := make(map[string]int)
+	byCategory := make(map[payments.FailureCategory]int)
+	byGateway := make(map[string]*PaymentFailureStats)
+	byMethod := make(map[string]*PaymentFailureStats)
+	byHour := make([]PaymentFailureHourSummary, 24)
+	for hour := range byHour {
+		byHour[hour].Hour = hour
+	}
+
+	for _, summary := range summaries {
+		failures := int(summary.Attempts - summary.Successes)
+		if failures > 0 {
+			failureType := payments.PaymentFailureType(summary.FailureType)
+			byFailureType[summary.FailureType] += failures
+			byCategory[failureType.Category()] += failures
+		}
+
+		if byGateway[summary.Gateway] == nil {
+			byGateway[summary.Gateway] = &PaymentFailureStats{}
+		}
+		if byMethod[string(summary.Method)] == nil {
+			byMethod[string(summary.Method)] = &PaymentFailureStats{}
+		}
+
+		addPaymentAttempts(&totals, summary)
+		addPaymentAttempts(byGateway[summary.Gateway], summary)
+		addPaymentAttempts(byMethod[string(summary.Method)], summary)
+		if summary.Hour >= 0 && summary.Hour < len(byHour) {
+			addPaymentAttempts(&byHour[summary.Hour].PaymentFailureStats, summary)
+		}
+	}
+
+	report := &PaymentFailureReportResponse{
+		StartDate:     startDate.Format("2006-01-02"),
+		EndDate:       endDate.Format("2006-01-02"),
+		Totals:        withPaymentFailureRates(totals),
+		ByFailureType: make([]PaymentFailureTypeSummary, 0, len(byFailureType)),
+		ByCategory:    make([]PaymentFailureCategorySummary, 0, len(byCategory)),
+		ByGateway:     paymentFailureBreakdowns(byGateway),
+		ByMethod:      paymentFailureBreakdowns(byMethod),
+		ByHour:        byHour,
+	}
+
+	retryPolicy := payments.DefaultRetryPolicy()
+	for failureType, failures := range byFailureType {
+		report.ByFailureType = append(report.ByFailureType, PaymentFailureTypeSummary{
+			FailureType: failureType,
+			Category:    payments.PaymentFailureType(failureType).Category(),
+			Retriable:   retryPolicy.IsRetriable(payments.PaymentFailureType(failureType)),
+			Failures:    failures,
+			Share:       percentage(failures, totals.Failures),
+		})
+	}
+	sort.Slice(report.ByFailureType, func(i, j int) bool {
+		a, b := report.ByFailureType[i], report.ByFailureType[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.FailureType < b.FailureType
+	})
+
+	for category, failures := range byCategory {
+		report.ByCategory = append(report.ByCategory, PaymentFailureCategorySummary{
+			Category: category,
+			Failures: failures,
+			Share:    percentage(failures, totals.Failures),
+		})
+	}
+	sort.Slice(report.ByCategory, func(i, j int) bool {
+		a, b := report.ByCategory[i], report.ByCategory[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Category < b.Category
+	})
+
+	for i := range report.ByHour {
+		report.ByHour[i].PaymentFailureStats = withPaymentFailureRates(report.ByHour[i].PaymentFailureStats)
+	}
+
+	s.logger.Info("Payment failure report generated", "start_date", report.StartDate, "end_date", report.EndDate,
+		"attempts", report.Totals.Attempts, "failures", report.Totals.Failures)
+
+	return report, nil
+}
+
+// addPaymentAttempts adds a group of summarized attempts to stats
+func addPaymentAttempts(stats *PaymentFailureStats, summary repository.PaymentAttemptSummary) {
+	stats.Attempts += int(summary.Attempts)
+	stats.Failures += int(summary.Attempts - summary.Successes)
+	if summary.Retry {
+		stats.Retries += int(summary.Attempts)
+		stats.RetrySuccesses += int(summary.Successes)
+	}
+}
+
+// withPaymentFailureRates derives the failure and retry success rates of stats
+func withPaymentFailureRates(stats PaymentFailureStats) PaymentFailureStats {
+	stats.FailureRate = percentage(stats.Failures, stats.Attempts)
+	stats.RetrySuccessRate = percentage(stats.RetrySuccesses, stats.Retries)
+	return stats
+}
+
+// paymentFailureBreakdowns lists grouped attempt statistics by key
+func paymentFailureBreakdowns(groups map[string]*PaymentFailureStats) []PaymentFailureBreakdown {
+	breakdowns := make([]PaymentFailureBreakdown, 0, len(groups))
+	for key, stats := range groups {
+		breakdowns = append(breakdowns, PaymentFailureBreakdown{Key: key, PaymentFailureStats: withPaymentFailureRates(*stats)})
+	}
+	sort.Slice(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Key < breakdowns[j].Key
+	})
+	return breakdowns
+}
+
+func (s *reportService) GenerateNotificationDeliveryReport(ctx context.Context, req NotificationDeliveryReportRequest) (*NotificationDeliveryReportResponse, error) {
+	s.logger.Info("Generating notification delivery report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.notifications.SummarizeDelivery(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to summarize notification delivery", "error", err)
+		return nil, err
+	}
+
+	var totals notificationDeliveryGroup
+	byType := make(map[string]*notificationDeliveryGroup)
+	byChannel := make(map[string]*notificationDeliveryGroup)
+	byTypeAndChannel := make([]NotificationDeliveryTypeChannel, 0, len(summaries))
+
+	for _, summary := range summaries {
+		notificationType, channel := string(summary.Type), string(summary.Channel)
+		if byType[notificationType] == nil {
+			byType[notificationType] = &notificationDeliveryGroup{}
+		}
+		if byChannel[channel] == nil {
+			byChannel[channel] = &notificationDeliveryGroup{}
+		}
+
+		totals.add(summary)
+		byType[notificationType].add(summary)
+		byChannel[channel].add(summary)
+
+		var group notificationDeliveryGroup
+		group.add(summary)
+		byTypeAndChannel = append(byTypeAndChannel, NotificationDeliveryTypeChannel{
+			Type:                      notificationType,
+			Channel:                   channel,
+			NotificationDeliveryStats: group.stats(),
+		})
+	}
+	sort.Slice(byTypeAndChannel, func(i, j int) bool {
+		a, b := byTypeAndChannel[i], byTypeAndChannel[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Channel < b.Channel
+	})
+
+	report := &NotificationDeliveryReportResponse{
+		StartDate:        startDate.Format("2006-01-02"),
+		EndDate:          endDate.Format("2006-01-02"),
+		Totals:           totals.stats(),
+		ByType:           notificationDeliveryBreakdowns(byType),
+		ByChannel:        notificationDeliveryBreakdowns(byChannel),
+		ByTypeAndChannel: byTypeAndChannel,
+	}
+
+	s.logger.Info("Notification delivery report generated", "start_date", report.StartDate, "end_date", report.EndDate,
+		"notifications", report.Totals.Notifications, "delivery_rate", report.Totals.DeliveryRate, "read_rate", report.Totals.ReadRate)
+
+	return report, nil
+}
+
+// notificationDeliveryGroup adds up summarized notifications, keeping the total
+// delivery and read times the averages are taken from
+type notificationDeliveryGroup struct {
+	counts          NotificationDeliveryStats
+	deliverySeconds float64
+	readSeconds     float64
+}
+
+// add adds a group of summarized notifications
+func (g *notificationDeliveryGroup) add(summary repository.NotificationDeliverySummary) {
+	g.counts.Notifications += int(summary.Notifications)
+	g.counts.Sent += int(summary.Sent)
+	g.counts.Delivered += int(summary.Delivered)
+	g.counts.Read += int(summary.Read)
+	g.deliverySeconds += summary.DeliverySeconds
+	g.readSeconds += summary.ReadSeconds
+}
+
+// stats derives the rates and average times of the group
+func (g *notificationDeliveryGroup) stats() NotificationDeliveryStats {
+	stats := g.counts
+	stats.DeliveryRate = percentage(stats.Delivered, stats.Sent)
+	stats.ReadRate = percentage(stats.Read, stats.Delivered)
+	stats.AvgMinutesToDeliver = averageMinutes(g.deliverySeconds, stats.Delivered)
+	stats.AvgMinutesToRead = averageMinutes(g.readSeconds, stats.Read)
+	return stats
+}
+
+// notificationDeliveryBreakdowns lists grouped delivery statistics by key
+func notificationDeliveryBreakdowns(groups map[string]*notificationDeliveryGroup) []NotificationDeliveryBreakdown {
+	breakdowns := make([]NotificationDeliveryBreakdown, 0, len(groups))
+	for key, group := range groups {
+		breakdowns = append(breakdowns, NotificationDeliveryBreakdown{Key: key, NotificationDeliveryStats: group.stats()})
+	}
+	sort.Slice(breakdowns, func(i, j int) bool {
+		return breakdowns[i].Key < breakdowns[j].Key
+	})
+	return breakdowns
+}
+
+// averageMinutes returns the average of count durations adding up to seconds, in
+// minutes rounded to two decimal places
+func averageMinutes(seconds float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return roundCents(seconds / float64(count) / 60)
+}
+
+// percentage returns part as a percentage of whole, rounded to two decimal places
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return roundCents(float64(part) / float64(whole) * 100)
+}
+
+// roundCents rounds an amount to two decimal places
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// dailyActiveUsers lists every day of the range, filling days without activity with zero
+func dailyActiveUsers(start, end time.Time, counts []repository.DailyActiveUserCount) []DailyActiveUsersItem {
+	byDay := make(map[string]int, len(counts))
+	for _, count := range counts {
+		byDay[count.Day.Format("2006-01-02")] = int(count.ActiveUsers)
+	}
+
+	var days []DailyActiveUsersItem
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		days = append(days, DailyActiveUsersItem{Date: date, ActiveUsers: byDay[date]})
+	}
+	return days
+}
+
+// orderRefundedAmount sums the refunds of an order's payments, capped at the order total
+func orderRefundedAmount(order *models.Order) float64 {
+	var refunded float64
+	for _, payment := range order.Payments {
+		refunded += payment.RefundedTotal()
+	}
+	return math.Min(refunded, order.TotalAmount)
+}
+
+// accumulateProductSales adds an order's items to the per-product summaries. A
+// refund is attributed to the items in proportion to their share of the order
+// total, so a full refund returns every unit and a partial one returns a share.
+func accumulateProductSales(summaries map[string]*ProductSalesSummary, order *models.Order, orderRefund float64) {
+	refundShare := float64(0)
+	if order.TotalAmount > 0 {
+		refundShare = orderRefund / order.TotalAmount
+	}
+
+	for _, item := range order.Items {
+		summary, exists := summaries[item.ProductID]
+		if !exists {
+			summary = &ProductSalesSummary{ProductID: item.ProductID}
+			if item.Product != nil {
+				summary.ProductName = item.Product.Name
+				summary.SKU = item.Product.SKU
+			}
+			summaries[item.ProductID] = summary
+		}
+
+		itemRefund := item.TotalPrice * refundShare
+		summary.QuantitySold += item.Quantity
+		summary.QuantityReturned += int(math.Round(float64(item.Quantity) * refundShare))
+		summary.GrossRevenue += item.TotalPrice
+		// Items are charged their list price unless a privileged user overrode it
+		if item.IsPriceOverridden() {
+			listRevenue := item.ListUnitPrice() * float64(item.Quantity)
+			summary.ListRevenue += listRevenue
+			summary.PriceOverrideDiscount += listRevenue - item.TotalPrice
+		} else {
+			summary.ListRevenue += item.TotalPrice
+		}
+		summary.RefundedAmount += itemRefund
+		summary.NetRevenue += item.TotalPrice - itemRefund
+
+		// Returned units go back to stock, so only kept units carry a cost
+		if item.Product != nil {
+			keptUnits := float64(item.Quantity) * (1 - refundShare)
+			summary.CostOfGoods += item.Product.CostPrice * keptUnits
+		}
+	}
+}
+
+// sortedProductSales finalizes return rates and margins and orders products by gross revenue
+func sortedProductSales(summaries map[string]*ProductSalesSummary, lowMarginThreshold float64) []ProductSalesSummary {
+	productSales := make([]ProductSalesSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.QuantitySold > 0 {
+			summary.ReturnRate = float64(summary.QuantityReturned) / float64(summary.QuantitySold)
+		}
+		summary.GrossMargin = summary.NetRevenue - summary.CostOfGoods
+		summary.MarginPercent = marginPercent(summary.NetRevenue, summary.GrossMargin)
+		summary.LowMargin = summary.NetRevenue > 0 && summary.MarginPercent < lowMarginThreshold
+		productSales = append(productSales, *summary)
+	}
+
+	sort.Slice(productSales, func(i, j int) bool {
+		if productSales[i].GrossRevenue != productSales[j].GrossRevenue {
+			return productSales[i].GrossRevenue > productSales[j].GrossRevenue
+		}
+		return productSales[i].ProductID < productSales[j].ProductID
+	})
+
+	return productSales
+}
+
+// productCategories maps each sold product to its category
+func productCategories(orders []*models.Order) map[string]string {
+	categories := make(map[string]string)
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if item.Product != nil && item.Product.CategoryID != nil {
+				categories[item.ProductID] = *item.Product.CategoryID
+			}
+		}
+	}
+	return categories
+}
+
+// categoryMargins rolls product margins up to their categories
+func categoryMargins(summaries map[string]*ProductSalesSummary, categories map[string]string) []CategoryMarginSummary {
+	byCategory := make(map[string]*CategoryMarginSummary)
+	for productID, summary := range summaries {
+		categoryID, ok := categories[productID]
+		if !ok {
+			categoryID = uncategorized
+		}
+
+		category, exists := byCategory[categoryID]
+		if !exists {
+			category = &CategoryMarginSummary{CategoryID: categoryID}
+			byCategory[categoryID] = category
+		}
+		category.NetRevenue += summary.NetRevenue
+		category.CostOfGoods += summary.CostOfGoods
+	}
+
+	margins := make([]CategoryMarginSummary, 0, len(byCategory))
+	for _, category := range byCategory {
+		category.GrossMargin = category.NetRevenue - category.CostOfGoods
+		category.MarginPercent = marginPercent(category.NetRevenue, category.GrossMargin)
+		margins = append(margins, *category)
+	}
+
+	sort.Slice(margins, func(i, j int) bool {
+		return margins[i].CategoryID < margins[j].CategoryID
+	})
+
+	return margins
+}
+
+// uncategorized groups products without a category in margin reports
+const uncategorized = "uncategorized"
+
+// marginPercent returns margin as a percentage of revenue, or zero without revenue
+func marginPercent(revenue, margin float64) float64 {
+	if revenue <= 0 {
+		return 0
+	}
+	return margin / revenue * 100
+}
+
+func (s *reportService) GenerateARAgingReport(ctx context.Context) (*ARAgingReportResponse, error) {
+	s.logger.Info("Generating AR aging report")
+
+	invoices, err := s.orderRepo.ListOpenInvoices(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list open invoices", "error", err)
+		return nil, err
+	}
+
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	report := &ARAgingReportResponse{
+		AsOf:          asOf.Format("2006-01-02"),
+		Organizations: []ARAgingOrganization{},
+	}
+
+	byOrganization := make(map[string]*ARAgingOrganization)
+	for _, invoice := range invoices {
+		organization, ok := byOrganization[invoice.OrganizationID]
+		if !ok {
+			organization = &ARAgingOrganization{
+				OrganizationID:   invoice.OrganizationID,
+				OrganizationName: invoice.OrganizationName,
+				OldestDueAt:      invoice.InvoiceDueAt,
+			}
+			byOrganization[invoice.OrganizationID] = organization
+		}
+		organization.OpenInvoices++
+		if invoice.InvoiceDueAt.Before(organization.OldestDueAt) {
+			organization.OldestDueAt = invoice.InvoiceDueAt
+		}
+
+		daysPastDue := int(asOf.Sub(invoice.InvoiceDueAt.UTC().Truncate(24*time.Hour)).Hours() / 24)
+		addAgedAmount(&organization.ARAgingBuckets, daysPastDue, invoice.Amount)
+		addAgedAmount(&report.Totals, daysPastDue, invoice.Amount)
+	}
+
+	for _, organization := range byOrganization {
+		roundAgingBuckets(&organization.ARAgingBuckets)
+		report.Organizations = append(report.Organizations, *organization)
+	}
+	roundAgingBuckets(&report.Totals)
+
+	sort.Slice(report.Organizations, func(i, j int) bool {
+		a, b := report.Organizations[i], report.Organizations[j]
+		if a.OrganizationName != b.OrganizationName {
+			return a.OrganizationName < b.OrganizationName
+		}
+		return a.OrganizationID < b.OrganizationID
+	})
+
+	s.logger.Info("AR aging report generated", "as_of", report.AsOf,
+		"organizations", len(report.Organizations), "total", report.Totals.Total)
+
+	return report, nil
+}
+
+// addAgedAmount adds an open invoice amount to the bucket for its days past due
+func addAgedAmount(buckets *ARAgingBuckets, daysPastDue int, amount float64) {
+	switch {
+	case daysPastDue <= 0:
+		buckets.Current += amount
+	case daysPastDue <= 30:
+		buckets.Days1To30 += amount
+	case daysPastDue <= 60:
+		buckets.Days31To60 += amount
+	case daysPastDue <= 90:
+		buckets.Days61To90 += amount
+	default:
+		buckets.Over90 += amount
+	}
+	buckets.Total += amount
+}
+
+// roundAgingBuckets rounds the bucket sums to cents
+func roundAgingBuckets(buckets *ARAgingBuckets) {
+	buckets.Current = roundCents(buckets.Current)
+	buckets.Days1To30 = roundCents(buckets.Days1To30)
+	buckets.Days31To60 = roundCents(buckets.Days31To60)
+	buckets.Days61To90 = roundCents(buckets.Days61To90)
+	buckets.Over90 = roundCents(buckets.Over90)
+	buckets.Total = roundCents(buckets.Total)
+}
+
+func (s *reportService) GenerateCreditExposureReport(ctx context.Context) (*CreditExposureReportResponse, error) {
+	s.logger.Info("Generating credit exposure report")
+
+	exposures, err := s.orderRepo.ListCreditExposure(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list credit exposure", "error", err)
+		return nil, err
+	}
+
+	report := &CreditExposureReportResponse{
+		Organizations: make([]CreditExposureOrganization, 0, len(exposures)),
+	}
+	for _, exposure := range exposures {
+		organization := models.Organization{CreditLimit: exposure.CreditLimit}
+		row := CreditExposureOrganization{
+			OrganizationID:     exposure.OrganizationID,
+			OrganizationName:   exposure.OrganizationName,
+			CreditLimit:        exposure.CreditLimit,
+			CreditLimitAction:  exposure.CreditLimitAction,
+			Outstanding:        roundCents(exposure.Outstanding),
+			AvailableCredit:    organization.AvailableCredit(exposure.Outstanding),
+			UtilizationPercent: utilization(exposure.Outstanding, exposure.CreditLimit),
+			OverLimit:          organization.ExceedsCreditLimit(exposure.Outstanding, 0),
+			HeldOrders:         int(exposure.HeldOrders),
+			HeldAmount:         roundCents(exposure.HeldAmount),
+		}
+		report.Organizations = append(report.Organizations, row)
+
+		report.Totals.CreditLimit += exposure.CreditLimit
+		report.Totals.Outstanding += exposure.Outstanding
+		report.Totals.HeldOrders += row.HeldOrders
+		report.Totals.HeldAmount += exposure.HeldAmount
+		if row.OverLimit {
+			report.Totals.OverLimitAccounts++
+		}
+	}
+	report.Totals.UtilizationPercent = utilization(report.Totals.Outstanding, report.Totals.CreditLimit)
+	report.Totals.CreditLimit = roundCents(report.Totals.CreditLimit)
+	report.Totals.Outstanding = roundCents(report.Totals.Outstanding)
+	report.Totals.HeldAmount = roundCents(report.Totals.HeldAmount)
+
+	// Accounts over their limit first, then the most utilized
+	sort.SliceStable(report.Organizations, func(i, j int) bool {
+		a, b := report.Organizations[i], report.Organizations[j]
+		if a.OverLimit != b.OverLimit {
+			return a.OverLimit
+		}
+		return a.UtilizationPercent > b.UtilizationPercent
+	})
+
+	s.logger.Info("Credit exposure report generated",
+		"organizations", len(report.Organizations),
+		"outstanding", report.Totals.Outstanding,
+		"over_limit_accounts", report.Totals.OverLimitAccounts)
+
+	return report, nil
+}
+
+// shippingSLADueSoonWindow is how far ahead the shipping SLA report looks for orders
+// about to miss their promised ship date
+const shippingSLADueSoonWindow = 24 * time.Hour
+
+func (s *reportService) GenerateShippingSLAReport(ctx context.Context) (*ShippingSLAReportResponse, error) {
+	s.logger.Info("Generating shipping SLA report")
+
+	now := time.Now()
+	orders, err := s.orderRepo.ListAwaitingShipment(ctx, now.Add(shippingSLADueSoonWindow))
+	if err != nil {
+		s.logger.Error("Failed to list orders awaiting shipment", "error", err)
+		return nil, err
+	}
+
+	report := &ShippingSLAReportResponse{
+		GeneratedAt: now,
+		Stores:      []ShippingSLAStore{},
+		Orders:      make([]ShippingSLAOrder, 0, len(orders)),
+	}
+	stores := make(map[string]*ShippingSLAStore)
+	for _, order := range orders {
+		if order.PromisedShipBy == nil {
+			continue
+		}
+
+		row := ShippingSLAOrder{
+			OrderID:        order.ID,
+			Channel:        order.Channel,
+			Status:         order.Status,
+			PromisedShipBy: *order.PromisedShipBy,
+			Overdue:        order.PromisedShipBy.Before(now),
+		}
+
+		store, ok := stores[order.Channel]
+		if !ok {
+			store = &ShippingSLAStore{Channel: order.Channel}
+			stores[order.Channel] = store
+		}
+		if row.Overdue {
+			row.HoursOverdue = roundCents(now.Sub(row.PromisedShipBy).Hours())
+			store.Overdue++
+			store.OldestOverdueHours = math.Max(store.OldestOverdueHours, row.HoursOverdue)
+			report.Totals.Overdue++
+		} else {
+			store.DueSoon++
+			report.Totals.DueSoon++
+		}
+		report.Orders = append(report.Orders, row)
+	}
+
+	for _, store := range stores {
+		report.Stores = append(report.Stores, *store)
+	}
+	// Stores with the most overdue orders first
+	sort.Slice(report.Stores, func(i, j int) bool {
+		a, b := report.Stores[i], report.Stores[j]
+		if a.Overdue != b.Overdue {
+			return a.Overdue > b.Overdue
+		}
+		return a.Channel < b.Channel
+	})
+
+	s.logger.Info("Shipping SLA report generated",
+		"overdue", report.Totals.Overdue,
+		"due_soon", report.Totals.DueSoon)
+
+	return report, nil
+}
+
+// utilization returns the outstanding balance as a percentage of the credit limit;
+// it is 0 without a limit, where any balance is over the limit
+func utilization(outstanding, creditLimit float64) float64 {
+	if creditLimit <= 0 {
+		return 0
+	}
+	return roundCents(outstanding / creditLimit * 100)
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// Retention policies, each purging one kind of record
+const (
+	RetentionPolicyNotifications   = "notifications"
+	RetentionPolicyIdempotencyKeys = "idempotency_keys"
+	RetentionPolicyWebhookEvents   = "webhook_events"
+	RetentionPolicyReportArtifacts = "report_artifacts"
+)
+
+// retentionPurgeBatchSize is how many records a purge removes per statement, so a
+// large backlog does not hold locks for long
+const retentionPurgeBatchSize = 1000
+
+// RetentionSettings configures how long records are kept before they are purged.
+// A zero duration keeps that kind of record forever. With DryRun set, purges only
+// count what they would remove.
+type RetentionSettings struct {
+	Notifications   time.Duration
+	IdempotencyKeys time.Duration
+	WebhookEvents   time.Duration
+	ReportArtifacts time.Duration
+	DryRun          bool
+}
+
+// retentionService implements RetentionService interface
+type retentionService struct {
+	settings         RetentionSettings
+	notificationRepo repository.NotificationRepository
+	orderRepo        repository.OrderRepository
+	webhookEventRepo repository.WebhookEventRepository
+	logger           *logger.Logger
+
+	mu      sync.Mutex
+	metrics map[string]*RetentionPolicyMetrics
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(
+	settings RetentionSettings,
+	notificationRepo repository.NotificationRepository,
+	orderRepo repository.OrderRepository,
+	webhookEventRepo repository.WebhookEventRepository,
+	logger *logger.Logger,
+) RetentionService {
+	return &retentionService{
+		settings:         settings,
+		notificationRepo: notificationRepo,
+		orderRepo:        orderRepo,
+		webhookEventRepo: webhookEventRepo,
+		logger:           logger,
+		metrics:          make(map[string]*RetentionPolicyMetrics),
+	}
+}
+
+// retentionPolicy purges or counts the records of one policy older than a cutoff
+type retentionPolicy struct {
+	name      string
+	retention time.Duration
+	purge     func(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error)
+}
+
+func (s *retentionService) policies() []retentionPolicy {
+	return []retentionPolicy{
+		{
+			name:      RetentionPolicyNotifications,
+			retention: s.settings.Notifications,
+			purge: func(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+				if dryRun {
+					return s.notificationRepo.CountCreatedBefore(ctx, cutoff)
+				}
+				return purgeInBatches(ctx, cutoff, s.notificationRepo.DeleteCreatedBefore)
+			},
+		},
+		{
+			name:      RetentionPolicyIdempotencyKeys,
+			retention: s.settings.IdempotencyKeys,
+			purge: func(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+				if dryRun {
+					return s.orderRepo.CountIdempotencyKeysBefore(ctx, cutoff)
+				}
+				return purgeInBatches(ctx, cutoff, s.orderRepo.ClearIdempotencyKeysBefore)
+			},
+		},
+		{
+			name:      RetentionPolicyWebhookEvents,
+			retention: s.settings.WebhookEvents,
+			purge: func(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+				if dryRun {
+					return s.webhookEventRepo.CountCreatedBefore(ctx, cutoff)
+				}
+				return purgeInBatches(ctx, cutoff, s.webhookEventRepo.DeleteCreatedBefore)
+			},
+		},
+		{
+			name:      RetentionPolicyReportArtifacts,
+			retention: s.settings.ReportArtifacts,
+			purge: func(ctx context.Context, cutoff time.Time, dryRun bool) (int64, error) {
+				return purgeArtifactsBefore(recountArtifactDir, cutoff, dryRun)
+			},
+		},
+	}
+}
+
+// Purge removes the records every enabled policy no longer retains. A failing policy
+// is reported in its result and does not stop the others.
+func (s *retentionService) Purge(ctx context.Context, dryRun bool) (*RetentionPurgeResponse, error) {
+	dryRun = dryRun || s.settings.DryRun
+	s.logger.Debug("Purging expired records", "dry_run", dryRun)
+
+	response := &RetentionPurgeResponse{
+		DryRun:  dryRun,
+		Results: []RetentionPurgeResult{},
+	}
+
+	now := time.Now()
+	for _, policy := range s.policies() {
+		if policy.retention <= 0 {
+			continue
+		}
+
+		cutoff := now.Add(-policy.retention)
+		started := time.Now()
+		purged, err := policy.purge(ctx, cutoff, dryRun)
+		s.recordRun(policy, started, purged, dryRun, err)
+
+		result := RetentionPurgeResult{
+			Policy:  policy.name,
+			Cutoff:  cutoff,
+			Records: purged,
+		}
+		if err != nil {
+			s.logger.Error("Retention purge failed", "error", err, "policy", policy.name)
+			result.Error = err.Error()
+		} else if purged > 0 {
+			s.logger.Info("Retention purge completed", "policy", policy.name, "records", purged, "dry_run", dryRun)
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	for _, result := range response.Results {
+		if result.Error == "" {
+			return response, nil
+		}
+	}
+	if len(response.Results) > 0 {
+		return response, errors.NewInternalError("all retention policies failed to purge", nil)
+	}
+	return response, nil
+}
+
+// GetMetrics returns the purge metrics of every policy, including those not run yet
+func (s *retentionService) GetMetrics() []RetentionPolicyMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policies := s.policies()
+	metrics := make([]RetentionPolicyMetrics, 0, len(policies))
+	for _, policy := range policies {
+		entry := RetentionPolicyMetrics{
+			Policy:        policy.name,
+			RetentionDays: int(policy.retention / (24 * time.Hour)),
+			Enabled:       policy.retention > 0,
+		}
+		if recorded, ok := s.metrics[policy.name]; ok {
+			entry = *recorded
+			entry.RetentionDays = int(policy.retention / (24 * time.Hour))
+			entry.Enabled = policy.retention > 0
+		}
+		metrics = append(metrics, entry)
+	}
+	return metrics
+}
+
+// recordRun updates the metrics of a policy after a purge
+func (s *retentionService) recordRun(policy retentionPolicy, started time.Time, purged int64, dryRun bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.metrics[policy.name]
+	if !ok {
+		entry = &RetentionPolicyMetrics{Policy: policy.name}
+		s.metrics[policy.name] = entry
+	}
+
+	entry.Runs++
+	entry.LastRunAt = &started
+	entry.LastDryRun = dryRun
+	entry.LastDurationMs = time.Since(started).Milliseconds()
+	entry.LastRecords = purged
+	entry.LastError = ""
+	if !dryRun {
+		// Batches removed before a failure stay removed
+		entry.PurgedTotal += purged
+	}
+	if err != nil {
+		entry.Failures++
+		entry.LastError = err.Error()
+	}
+}
+
+// purgeInBatches runs a batched delete until a batch comes back short
+func purgeInBatches(ctx context.Context, cutoff time.Time, purge func(ctx context.Context, before time.Time, limit int) (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		purged, err := purge(ctx, cutoff, retentionPurgeBatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < retentionPurgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// purgeArtifactsBefore removes the report files in dir last written before the cutoff.
+// A missing directory holds no artifacts.
+func purgeArtifactsBefore(dir string, cutoff time.Time, dryRun bool) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return purged, err
+		}
+		if !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return purged, err
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
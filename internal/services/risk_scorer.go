@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// Codes of the order risk signals
+const (
+	RiskSignalNewAccount     = "new_account"      // Account opened shortly before the order
+	RiskSignalHighValue      = "high_value"       // Order total well above the usual basket
+	RiskSignalOrderVelocity  = "order_velocity"   // Many orders from the user in a day
+	RiskSignalBulkQuantity   = "bulk_quantity"    // Unusually many units of a product
+	RiskSignalFailedPayments = "failed_payments"  // Payments of the order that failed
+	RiskSignalNewShipCountry = "new_ship_country" // Ships where none of the user's saved addresses are
+)
+
+// Points each risk signal adds to the score, and the thresholds that raise them
+const (
+	riskNewAccountDayPoints    = 25
+	riskNewAccountWeekPoints   = 10
+	riskHighValuePoints        = 25
+	riskMediumValuePoints      = 10
+	riskOrderVelocityPoints    = 15
+	riskBulkQuantityPoints     = 10
+	riskFailedPaymentPoints    = 10 // Per failed payment, up to riskMaxFailedPaymentPoints
+	riskMaxFailedPaymentPoints = 30
+	riskNewShipCountryPoints   = 15
+
+	riskHighValueTotal   = 1000.0
+	riskMediumValueTotal = 500.0
+	riskVelocityOrders   = 3 // Other orders in the day before the order
+	riskBulkItemQuantity = 10
+)
+
+// RiskScorer scores the fraud risk of orders from signals in the order, its customer's
+// account and history, and its payments. The score is the sum of the points of the
+// signals found, capped at models.MaxRiskScore. A nil scorer scores nothing.
+type RiskScorer struct {
+	orderRepo   repository.OrderRepository
+	userRepo    repository.UserRepository
+	paymentRepo repository.PaymentRepository
+	addressRepo repository.AddressRepository
+	now         func() time.Time
+	logger      *logger.Logger
+}
+
+// NewRiskScorer creates a new order risk scorer
+func NewRiskScorer(
+	orderRepo repository.OrderRepository,
+	userRepo repository.UserRepository,
+	paymentRepo repository.PaymentRepository,
+	addressRepo repository.AddressRepository,
+	logger *logger.Logger,
+) *RiskScorer {
+	return &RiskScorer{
+		orderRepo:   orderRepo,
+		userRepo:    userRepo,
+		paymentRepo: paymentRepo,
+		addressRepo: addressRepo,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+// Score scores the order, which must have its items loaded, and saves the score and
+// signals on it
+func (r *RiskScorer) Score(ctx context.Context, order *models.Order) error {
+	if r == nil {
+		return nil
+	}
+
+	signals, err := r.signals(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	score := 0
+	for _, signal := range signals {
+		score += signal.Points
+	}
+	if score > models.MaxRiskScore {
+		score = models.MaxRiskScore
+	}
+	scoredAt := r.now()
+
+	order.RiskScore = &score
+	order.RiskSignals = signals
+	order.RiskScoredAt = &scoredAt
+	if err := r.orderRepo.UpdateRiskScore(ctx, order); err != nil {
+		return errors.NewDatabaseError("failed to save order risk score", err)
+	}
+
+	r.logger.Info("Order risk scored", "order_id", order.ID, "risk_score", score, "band", models.RiskBandOf(score), "signals", len(signals))
+	return nil
+}
+
+// signals returns the risk signals found for the order
+func (r *RiskScorer) signals(ctx context.Context, order *models.Order) ([]models.RiskSignal, error) {
+	signals := []models.RiskSignal{}
+
+	user, err := r.userRepo.GetByID(ctx, order.UserID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get user for risk scoring", err)
+	}
+	if user != nil {
+		accountAge := order.CreatedAt.Sub(user.CreatedAt)
+		switch {
+		case accountAge < 24*time.Hour:
+			signals = append(signals, models.RiskSignal{Code: RiskSignalNewAccount, Points: riskNewAccountDayPoints,
+				Detail: "account opened less than a day before the order"})
+		case accountAge < 7*24*time.Hour:
+			signals = append(signals, models.RiskSignal{Code: RiskSignalNewAccount, Points: riskNewAccountWeekPoints,
+				Detail: "account opened less than a week before the order"})
+		}
+	}
+
+	switch {
+	case order.TotalAmount >= riskHighValueTotal:
+		signals = append(signals, models.RiskSignal{Code: RiskSignalHighValue, Points: riskHighValuePoints,
+			Detail: fmt.Sprintf("order total of %.2f is at least %.2f", order.TotalAmount, riskHighValueTotal)})
+	case order.TotalAmount >= riskMediumValueTotal:
+		signals = append(signals, models.RiskSignal{Code: RiskSignalHighValue, Points: riskMediumValuePoints,
+			Detail: fmt.Sprintf("order total of %.2f is at least %.2f", order.TotalAmount, riskMediumValueTotal)})
+	}
+
+	// The count includes the order itself
+	recent, err := r.orderRepo.CountByUserSince(ctx, order.UserID, order.CreatedAt.Add(-24*time.Hour))
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count user orders for risk scoring", err)
+	}
+	if others := recent - 1; others >= riskVelocityOrders {
+		signals = append(signals, models.RiskSignal{Code: RiskSignalOrderVelocity, Points: riskOrderVelocityPoints,
+			Detail: fmt.Sprintf("%d other orders placed by the user since a day before the order", others)})
+	}
+
+	for _, item := range order.Items {
+		if item.Quantity >= riskBulkItemQuantity {
+			signals = append(signals, models.RiskSignal{Code: RiskSignalBulkQuantity, Points: riskBulkQuantityPoints,
+				Detail: fmt.Sprintf("%d units of product %s", item.Quantity, item.ProductID)})
+			break
+		}
+	}
+
+	payments, err := r.paymentRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to get payments for risk scoring", err)
+	}
+	failed := 0
+	for _, payment := range payments {
+		if payment.Status == models.PaymentStatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		points := failed * riskFailedPaymentPoints
+		if points > riskMaxFailedPaymentPoints {
+			points = riskMaxFailedPaymentPoints
+		}
+		signals = append(signals, models.RiskSignal{Code: RiskSignalFailedPayments, Points: points,
+			Detail: fmt.Sprintf("%d failed payments", failed)})
+	}
+
+	// Customers who saved addresses usually ship to one of their countries
+	if order.ShippingAddress != nil && order.ShippingAddress.Country != "" {
+		addresses, err := r.addressRepo.ListByUserID(ctx, order.UserID)
+		if err != nil {
+			return nil, errors.NewDatabaseError("failed to get addresses for risk scoring", err)
+		}
+		if len(addresses) > 0 && !shipsToSavedCountry(order.ShippingAddress.Country, addresses) {
+			signals = append(signals, models.RiskSignal{Code: RiskSignalNewShipCountry, Points: riskNewShipCountryPoints,
+				Detail: fmt.Sprintf("ships to %s, where none of the user's saved addresses are", order.ShippingAddress.Country)})
+		}
+	}
+
+	return signals, nil
+}
+
+// shipsToSavedCountry returns true if one of the addresses is in the country
+func shipsToSavedCountry(country string, addresses []*models.Address) bool {
+	for _, address := range addresses {
+		if strings.EqualFold(address.ShippingAddress.Country, country) {
+			return true
+		}
+	}
+	return false
+}
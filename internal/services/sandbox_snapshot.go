@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// sandboxSnapshotBatchSize is how many rows the snapshot fetches per query
+	sandboxSnapshotBatchSize = 500
+	// sandboxSnapshotDefaultJitter is the default amount jitter, in percent
+	sandboxSnapshotDefaultJitter = 10
+)
+
+// Sandbox snapshot tables, in load order
+const (
+	SandboxTableOrganizations = "organizations"
+	SandboxTableUsers         = "users"
+	SandboxTableProducts      = "products"
+	SandboxTableInventory     = "inventory"
+	SandboxTableOrders        = "orders"
+	SandboxTableOrderItems    = "order_items"
+	SandboxTablePayments      = "payments"
+)
+
+// sandboxSnapshotService implements SandboxSnapshotService interface
+type sandboxSnapshotService struct {
+	userRepo         repository.UserRepository
+	organizationRepo repository.OrganizationRepository
+	productRepo      repository.ProductRepository
+	orderRepo        repository.OrderRepository
+	logger           *logger.Logger
+}
+
+// NewSandboxSnapshotService creates a new sandbox snapshot service
+func NewSandboxSnapshotService(
+	userRepo repository.UserRepository,
+	organizationRepo repository.OrganizationRepository,
+	productRepo repository.ProductRepository,
+	orderRepo repository.OrderRepository,
+	logger *logger.Logger,
+) SandboxSnapshotService {
+	return &sandboxSnapshotService{
+		userRepo:         userRepo,
+		organizationRepo: organizationRepo,
+		productRepo:      productRepo,
+		orderRepo:        orderRepo,
+		logger:           logger,
+	}
+}
+
+// Export walks the store's data in batches and passes each record to fn once it is
+// anonymized. IDs are kept, so every reference between records still resolves, and
+// deleted users, products and organizations are exported as ordinary records since
+// orders may reference them. Emails are replaced by a hash, names are shuffled among
+// the users' first and last names, and addresses keep only their city, region and
+// country. Prices are jittered per product, and order totals and payments are scaled
+// with their items, so reports over the snapshot follow the production distributions.
+// Passwords are never exported.
+func (s *sandboxSnapshotService) Export(ctx context.Context, req SandboxSnapshotRequest, fn func(*SandboxSnapshotRecord) error) error {
+	s.logger.Info("Exporting sandbox snapshot", "orders_since", req.OrdersSince, "jitter_percent", req.JitterPercent)
+
+	var ordersSince time.Time
+	if req.OrdersSince != "" {
+		var err error
+		ordersSince, err = time.Parse("2006-01-02", req.OrdersSince)
+		if err != nil {
+			return errors.NewValidationError("orders_since must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	jitter := req.JitterPercent
+	if jitter == 0 {
+		jitter = sandboxSnapshotDefaultJitter
+	}
+	if jitter < 0 || jitter > 50 {
+		return errors.NewValidationError("jitter_percent must be greater than 0 and at most 50")
+	}
+
+	seed := req.Seed
+	if seed == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return err
+		}
+		seed = hex.EncodeToString(random)
+	}
+
+	anonymizer := &snapshotAnonymizer{key: []byte(seed), jitter: jitter / 100}
+	if err := s.loadNamePools(ctx, anonymizer); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	emit := func(table string, record interface{}) error {
+		counts[table]++
+		return fn(&SandboxSnapshotRecord{Table: table, Record: record})
+	}
+
+	err := s.exportOrganizations(ctx, anonymizer, emit)
+	if err == nil {
+		err = s.exportUsers(ctx, anonymizer, emit)
+	}
+	if err == nil {
+		err = s.exportProducts(ctx, anonymizer, emit)
+	}
+	if err == nil {
+		err = s.exportOrders(ctx, anonymizer, ordersSince, emit)
+	}
+	if err != nil {
+		s.logger.Error("Failed to export sandbox snapshot", "error", err, "counts", counts)
+		return err
+	}
+
+	s.logger.Info("Sandbox snapshot exported", "counts", counts)
+	return nil
+}
+
+// loadNamePools collects the first and last names of every user, which the exported
+// users' names are drawn from
+func (s *sandboxSnapshotService) loadNamePools(ctx context.Context, anonymizer *snapshotAnonymizer) error {
+	firstNames := make(map[string]bool)
+	lastNames := make(map[string]bool)
+
+	afterID := ""
+	for {
+		users, err := s.userRepo.ListAfterID(ctx, afterID, sandboxSnapshotBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			parts := strings.Fields(user.Name)
+			if len(parts) == 0 {
+				continue
+			}
+			firstNames[parts[0]] = true
+			if len(parts) > 1 {
+				lastNames[parts[len(parts)-1]] = true
+			}
+		}
+		if len(users) < sandboxSnapshotBatchSize {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	anonymizer.firstNames = sortedKeys(firstNames)
+	anonymizer.lastNames = sortedKeys(lastNames)
+	return nil
+}
+
+func (s *sandboxSnapshotService) exportOrganizations(ctx context.Context, anonymizer *snapshotAnonymizer, emit func(string, interface{}) error) error {
+	afterID := ""
+	for {
+		organizations, err := s.organizationRepo.ListAfterID(ctx, afterID, sandboxSnapshotBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, organization := range organizations {
+			organization.Name = "Organization " + anonymizer.token("organization", organization.ID)
+			organization.CreditLimit = roundCents(organization.CreditLimit * anonymizer.factor("credit_limit", organization.ID))
+			organization.Members = nil
+			organization.Orders = nil
+			if err := emit(SandboxTableOrganizations, organization); err != nil {
+				return err
+			}
+		}
+		if len(organizations) < sandboxSnapshotBatchSize {
+			return nil
+		}
+		afterID = organizations[len(organizations)-1].ID
+	}
+}
+
+func (s *sandboxSnapshotService) exportUsers(ctx context.Context, anonymizer *snapshotAnonymizer, emit func(string, interface{}) error) error {
+	afterID := ""
+	for {
+		users, err := s.userRepo.ListAfterID(ctx, afterID, sandboxSnapshotBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			user.Email = anonymizer.email(user.Email)
+			user.Name = anonymizer.name(user.ID)
+			user.Password = ""
+			user.Orders = nil
+			user.Notifications = nil
+			user.AuditLogs = nil
+			if err := emit(SandboxTableUsers, user); err != nil {
+				return err
+			}
+		}
+		if len(users) < sandboxSnapshotBatchSize {
+			return nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+func (s *sandboxSnapshotService) exportProducts(ctx context.Context, anonymizer *snapshotAnonymizer, emit func(string, interface{}) error) error {
+	afterID := ""
+	for {
+		products, err := s.productRepo.ListAfterID(ctx, afterID, sandboxSnapshotBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			factor := anonymizer.factor("price", product.ID)
+			product.Price = roundCents(product.Price * factor)
+			product.CostPrice = roundCents(product.CostPrice * factor)
+
+			inventory := product.Inventory
+			product.Inventory = nil
+			product.OrderItems = nil
+			if err := emit(SandboxTableProducts, product); err != nil {
+				return err
+			}
+			if inventory != nil {
+				inventory.Product = nil
+				if err := emit(SandboxTableInventory, inventory); err != nil {
+					return err
+				}
+			}
+		}
+		if len(products) < sandboxSnapshotBatchSize {
+			return nil
+		}
+		afterID = products[len(products)-1].ID
+	}
+}
+
+func (s *sandboxSnapshotService) exportOrders(ctx context.Context, anonymizer *snapshotAnonymizer, since time.Time, emit func(string, interface{}) error) error {
+	iterator := s.orderRepo.Iterate(repository.OrderFilter{
+		CreatedFrom:  since,
+		WithPayments: true,
+	}, sandboxSnapshotBatchSize)
+
+	for {
+		batch, err := iterator.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, order := range batch {
+			items, payments := order.Items, order.Payments
+			anonymizer.order(order)
+			if err := emit(SandboxTableOrders, order); err != nil {
+				return err
+			}
+			for i := range items {
+				if err := emit(SandboxTableOrderItems, &items[i]); err != nil {
+					return err
+				}
+			}
+			for i := range payments {
+				if err := emit(SandboxTablePayments, &payments[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// snapshotAnonymizer derives every replacement value from a keyed hash of the original,
+// so a snapshot anonymizes a value the same way wherever it appears
+type snapshotAnonymizer struct {
+	key        []byte
+	jitter     float64
+	firstNames []string
+	lastNames  []string
+}
+
+// order anonymizes an order with its items and payments, detaching them from it. Item
+// prices take their product's jitter, and the order's amounts and payments are scaled
+// by how much that changed its subtotal, so totals still add up.
+func (a *snapshotAnonymizer) order(order *models.Order) {
+	var subtotal, jitteredSubtotal float64
+	for i := range order.Items {
+		item := &order.Items[i]
+		factor := a.factor("price", item.ProductID)
+		subtotal += item.TotalPrice
+
+		item.UnitPrice = roundCents(item.UnitPrice * factor)
+		item.ListPrice = roundCents(item.ListPrice * factor)
+		item.TotalPrice = roundCents(item.UnitPrice * float64(item.Quantity))
+		item.PriceOverrideNote = ""
+		item.Order = nil
+		item.Product = nil
+		jitteredSubtotal += item.TotalPrice
+	}
+	scale := 1.0
+	if subtotal > 0 {
+		scale = jitteredSubtotal / subtotal
+	}
+
+	order.TotalAmount = roundCents(order.TotalAmount * scale)
+	order.PaymentAdjustment = roundCents(order.PaymentAdjustment * scale)
+	order.Notes = ""
+	order.Metadata = models.Metadata{}
+	order.IdempotencyKey = ""
+	if order.ExternalOrderID != "" {
+		order.ExternalOrderID = a.token("external_order", order.ExternalOrderID)
+	}
+	if order.IsGift {
+		order.GiftMessage = ""
+	}
+	if order.ShippingAddress != nil {
+		order.ShippingAddress = a.address(order.UserID, order.ShippingAddress)
+	}
+
+	for i := range order.Payments {
+		payment := &order.Payments[i]
+		payment.Amount = roundCents(payment.Amount * scale)
+		payment.ProcessingFee = roundCents(payment.ProcessingFee * scale)
+		payment.TransactionID = a.reference("transaction", payment.TransactionID)
+		payment.GatewayTxnID = a.reference("gateway_txn", payment.GatewayTxnID)
+		payment.ExternalReference = a.reference("external_reference", payment.ExternalReference)
+		payment.IdempotencyKey = a.reference("idempotency_key", payment.IdempotencyKey)
+		payment.Metadata = nil
+		payment.Order = nil
+		payment.AuditLogs = nil
+	}
+
+	order.User = nil
+	order.Items = nil
+	order.Payments = nil
+	order.AuditLogs = nil
+}
+
+// email replaces an email address with one derived from its hash
+func (a *snapshotAnonymizer) email(email string) string {
+	return fmt.Sprintf("user-%s@example.com", a.token("email", strings.ToLower(email)))
+}
+
+// name draws a user's name from the first and last names of all users
+func (a *snapshotAnonymizer) name(userID string) string {
+	if len(a.firstNames) == 0 {
+		return "Sandbox User"
+	}
+	name := a.firstNames[a.number("first_name", userID)%uint64(len(a.firstNames))]
+	if len(a.lastNames) > 0 {
+		name += " " + a.lastNames[a.number("last_name", userID)%uint64(len(a.lastNames))]
+	}
+	return name
+}
+
+// address keeps where a user's address is, down to the city, and replaces the rest
+func (a *snapshotAnonymizer) address(userID string, address *models.ShippingAddress) *models.ShippingAddress {
+	anonymized := *address
+	anonymized.Recipient = a.name(userID)
+	anonymized.Line1 = fmt.Sprintf("%d Sandbox Street", 1+a.number("street", userID)%999)
+	anonymized.Line2 = ""
+	anonymized.PostalCode = fmt.Sprintf("%05d", a.number("postal_code", userID)%100000)
+	if anonymized.Phone != "" {
+		anonymized.Phone = fmt.Sprintf("+1555%07d", a.number("phone", userID)%10000000)
+	}
+	return &anonymized
+}
+
+// reference replaces a non-empty external reference with its hash, keeping it unique
+func (a *snapshotAnonymizer) reference(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return a.token(label, value)
+}
+
+// factor returns the jitter multiplier of a value, within 1 ± the jitter
+func (a *snapshotAnonymizer) factor(label, value string) float64 {
+	unit := float64(a.number(label, value)>>11) / (1 << 53)
+	return 1 + a.jitter*(2*unit-1)
+}
+
+// token returns a hex token derived from the value
+func (a *snapshotAnonymizer) token(label, value string) string {
+	return hex.EncodeToString(a.digest(label, value)[:8])
+}
+
+// number returns a number derived from the value
+func (a *snapshotAnonymizer) number(label, value string) uint64 {
+	return binary.BigEndian.Uint64(a.digest(label, value))
+}
+
+// digest is the keyed hash of a labelled value, so equal values under different
+// labels do not hash alike
+func (a *snapshotAnonymizer) digest(label, value string) []byte {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(label))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
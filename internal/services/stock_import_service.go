@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+)
+
+const (
+	// stockImportChunkSize is the number of rows applied per worker pool job
+	stockImportChunkSize = 500
+	// stockImportMaxRows caps the size of a single stock import
+	stockImportMaxRows = 50000
+	// stockImportMaxSKULength is the size of the product SKU column
+	stockImportMaxSKULength = 100
+)
+
+// stockImportService implements StockImportService interface
+type stockImportService struct {
+	importRepo    repository.StockImportRepository
+	poolManager   *workers.PoolManager
+	stockNotifier StockChangeNotifier
+	now           func() time.Time
+	logger        *logger.Logger
+}
+
+// NewStockImportService creates a new stock import service
+func NewStockImportService(
+	importRepo repository.StockImportRepository,
+	poolManager *workers.PoolManager,
+	stockNotifier StockChangeNotifier,
+	logger *logger.Logger,
+) StockImportService {
+	return &stockImportService{
+		importRepo:    importRepo,
+		poolManager:   poolManager,
+		stockNotifier: stockNotifier,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// ImportStock saves the rows of a CSV of stock adjustments and queues them on the
+// worker pool in chunks. Rows that cannot be parsed are rejected right away; an import
+// without any other row is completed without being queued.
+func (s *stockImportService) ImportStock(ctx context.Context, r io.Reader, filename string, actor AuditActor) (*models.StockImport, error) {
+	s.logger.Info("Importing stock adjustments", "filename", filename, "user_id", actor.UserID)
+
+	rows, err := parseStockImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	stockImport := &models.StockImport{
+		Filename:  filename,
+		TotalRows: len(rows),
+		Status:    models.StockImportStatusProcessing,
+		CreatedBy: actor.UserID,
+	}
+	for _, row := range rows {
+		if row.Status == models.StockImportRowRejected {
+			stockImport.Rejected++
+		}
+	}
+
+	chunks := stockImportChunks(rows)
+	stockImport.Chunks = len(chunks)
+	if len(chunks) == 0 {
+		completedAt := s.now()
+		stockImport.Status = models.StockImportStatusCompleted
+		stockImport.CompletedAt = &completedAt
+	}
+
+	if err := s.importRepo.Create(ctx, stockImport, rows); err != nil {
+		s.logger.Error("Failed to create stock import", "error", err)
+		return nil, apperrors.NewDatabaseError("failed to create stock import", err)
+	}
+
+	for _, chunk := range chunks {
+		job := workers.NewStockImportJob(stockImport.ID, chunk[0], chunk[1], s)
+		if err := s.poolManager.SubmitJob(job); err != nil {
+			// Chunks already queued still run; the rest of the import is never applied
+			s.logger.Error("Failed to queue stock import chunk", "error", err, "import_id", stockImport.ID, "first_row", chunk[0])
+			message := fmt.Sprintf("failed to queue rows %d to %d: %v", chunk[0], chunk[1], err)
+			if failErr := s.importRepo.Fail(ctx, stockImport.ID, message); failErr != nil {
+				s.logger.Error("Failed to mark stock import as failed", "error", failErr, "import_id", stockImport.ID)
+			}
+			return nil, apperrors.NewInternalError("failed to queue stock import", err)
+		}
+	}
+
+	s.logger.Info("Stock import queued",
+		"import_id", stockImport.ID,
+		"total_rows", stockImport.TotalRows,
+		"rejected", stockImport.Rejected,
+		"chunks", stockImport.Chunks)
+	return stockImport, nil
+}
+
+// GetImport returns an import with a page of its rows, in file order
+func (s *stockImportService) GetImport(ctx context.Context, id string, req StockImportRowsRequest) (*StockImportResponse, error) {
+	s.logger.Debug("Getting stock import", "import_id", id, "status", req.Status, "page", req.Page, "limit", req.Limit)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100 // Default limit
+	}
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	stockImport, err := s.importRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get stock import", "error", err, "import_id", id)
+		return nil, apperrors.NewDatabaseError("failed to get stock import", err)
+	}
+	if stockImport == nil {
+		return nil, apperrors.NewNotFoundErrorWithID("stock import", id)
+	}
+
+	status := models.StockImportRowStatus(req.Status)
+	rows, err := s.importRepo.ListRows(ctx, id, status, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list stock import rows", "error", err, "import_id", id)
+		return nil, apperrors.NewDatabaseError("failed to get stock import rows", err)
+	}
+	total, err := s.importRepo.CountRows(ctx, id, status)
+	if err != nil {
+		s.logger.Error("Failed to count stock import rows", "error", err, "import_id", id)
+		return nil, apperrors.NewDatabaseError("failed to get stock import rows", err)
+	}
+
+	return &StockImportResponse{
+		Import: stockImport,
+		Rows:   rows,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+func (s *stockImportService) ApplyStockImportChunk(ctx context.Context, importID string, firstRow, lastRow int) error {
+	s.logger.Debug("Applying stock import chunk", "import_id", importID, "first_row", firstRow, "last_row", lastRow)
+
+	adjusted, err := s.importRepo.ApplyChunk(ctx, importID, firstRow, lastRow)
+	if err != nil {
+		s.logger.Error("Failed to apply stock import chunk", "error", err, "import_id", importID, "first_row", firstRow)
+		return fmt.Errorf("failed to apply rows %d to %d of stock import %s: %w", firstRow, lastRow, importID, err)
+	}
+
+	if len(adjusted) > 0 && s.stockNotifier != nil {
+		s.stockNotifier.NotifyStockChanges(ctx, StockChangeReasonAdjustment, adjusted)
+	}
+
+	s.logger.Info("Stock import chunk applied", "import_id", importID, "first_row", firstRow, "adjusted", len(adjusted))
+	return nil
+}
+
+// stockImportChunks splits the rows left pending by parsing into chunks, each given
+// by its first and last row
+func stockImportChunks(rows []*models.StockImportRow) [][2]int {
+	var chunks [][2]int
+	for start := 0; start < len(rows); start += stockImportChunkSize {
+		end := start + stockImportChunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		first, last := 0, 0
+		for _, row := range rows[start:end] {
+			if row.Status != models.StockImportRowPending {
+				continue
+			}
+			if first == 0 {
+				first = row.Row
+			}
+			last = row.Row
+		}
+		if first != 0 {
+			chunks = append(chunks, [2]int{first, last})
+		}
+	}
+	return chunks
+}
+
+// parseStockImportCSV reads "sku,quantity" rows, the quantity being a signed change to
+// the product's on hand stock; a header row is optional. The same SKU may appear on
+// several rows, each applied in turn. Rows that cannot be parsed are returned already
+// rejected.
+func parseStockImportCSV(r io.Reader) ([]*models.StockImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []*models.StockImportRow
+	line := 0
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+		}
+		line++
+
+		if line == 1 && isRecountHeader(record) {
+			continue
+		}
+		if len(rows) >= stockImportMaxRows {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("stock import cannot exceed %d rows", stockImportMaxRows))
+		}
+
+		row := &models.StockImportRow{Row: line, Status: models.StockImportRowPending}
+		rows = append(rows, row)
+		if len(record) < 2 {
+			row.Status = models.StockImportRowRejected
+			row.Error = "expected sku and quantity columns"
+			continue
+		}
+
+		row.SKU = strings.TrimSpace(record[0])
+		quantity, err := strconv.Atoi(strings.TrimSpace(record[1]))
+		switch {
+		case row.SKU == "":
+			row.Status = models.StockImportRowRejected
+			row.Error = "sku is required"
+		case len(row.SKU) > stockImportMaxSKULength:
+			row.Status = models.StockImportRowRejected
+			row.Error = fmt.Sprintf("sku cannot exceed %d characters", stockImportMaxSKULength)
+			row.SKU = row.SKU[:stockImportMaxSKULength]
+		case err != nil || quantity == 0:
+			row.Status = models.StockImportRowRejected
+			row.Error = "quantity must be a non-zero integer"
+		default:
+			row.Quantity = quantity
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, apperrors.NewValidationError("stock import is empty")
+	}
+
+	return rows, nil
+}
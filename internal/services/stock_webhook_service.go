@@ -0,0 +1,278 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+const (
+	// StockChangedEvent is the event name sent to stock webhook subscribers
+	StockChangedEvent = "inventory.stock_changed"
+	// StockWebhookSignatureHeader carries the hex HMAC-SHA256 of the body keyed by the subscriber secret
+	StockWebhookSignatureHeader = "X-Webhook-Signature"
+
+	stockWebhookTimeout         = 10 * time.Second
+	stockWebhookDeliveryTimeout = time.Minute
+	stockWebhookMaxAttempts     = 3
+	stockWebhookRetryDelay      = time.Second
+)
+
+// stockWebhookService implements StockWebhookService interface
+type stockWebhookService struct {
+	subscriptionRepo repository.StockWebhookSubscriptionRepository
+	inventoryRepo    repository.InventoryRepository
+	client           *http.Client
+	logger           *logger.Logger
+}
+
+// NewStockWebhookService creates a new stock webhook service
+func NewStockWebhookService(
+	subscriptionRepo repository.StockWebhookSubscriptionRepository,
+	inventoryRepo repository.InventoryRepository,
+	logger *logger.Logger,
+) StockWebhookService {
+	return &stockWebhookService{
+		subscriptionRepo: subscriptionRepo,
+		inventoryRepo:    inventoryRepo,
+		client:           &http.Client{Timeout: stockWebhookTimeout},
+		logger:           logger,
+	}
+}
+
+func (s *stockWebhookService) CreateSubscription(ctx context.Context, req CreateStockWebhookSubscriptionRequest) (*StockWebhookSubscriptionResponse, error) {
+	s.logger.Info("Creating stock webhook subscription", "name", req.Name, "url", req.URL, "products", len(req.ProductIDs))
+
+	if req.Name == "" || req.URL == "" {
+		return nil, errors.NewValidationError("subscription name and URL are required")
+	}
+	if len(req.Secret) < 16 {
+		return nil, errors.NewValidationError("subscription secret must be at least 16 characters")
+	}
+
+	subscription := &models.StockWebhookSubscription{
+		Name:       req.Name,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		ProductIDs: models.StringList(req.ProductIDs),
+		IsActive:   true,
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		s.logger.Error("Failed to create stock webhook subscription", "error", err, "name", req.Name)
+		return nil, err
+	}
+
+	return newStockWebhookSubscriptionResponse(subscription), nil
+}
+
+func (s *stockWebhookService) ListSubscriptions(ctx context.Context) ([]*StockWebhookSubscriptionResponse, error) {
+	s.logger.Debug("Listing stock webhook subscriptions")
+
+	subscriptions, err := s.subscriptionRepo.List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list stock webhook subscriptions", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*StockWebhookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = newStockWebhookSubscriptionResponse(subscription)
+	}
+
+	return responses, nil
+}
+
+func (s *stockWebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	s.logger.Info("Deleting stock webhook subscription", "id", id)
+
+	if id == "" {
+		return errors.NewValidationError("subscription ID is required")
+	}
+
+	subscription, err := s.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get stock webhook subscription", "error", err, "id", id)
+		return err
+	}
+	if subscription == nil {
+		return errors.NewNotFoundErrorWithID("stock webhook subscription", id)
+	}
+
+	return s.subscriptionRepo.Delete(ctx, id)
+}
+
+// NotifyStockChanges delivers the current stock levels of the given products to
+// every matching subscriber. Delivery runs in the background so inventory writes
+// never wait on external endpoints.
+func (s *stockWebhookService) NotifyStockChanges(ctx context.Context, reason StockChangeReason, productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+
+	occurredAt := time.Now().UTC()
+	ids := append([]string(nil), productIDs...)
+
+	go func() {
+		// The request context ends with the request, so deliveries get their own
+		deliveryCtx, cancel := context.WithTimeout(context.Background(), stockWebhookDeliveryTimeout)
+		defer cancel()
+
+		s.publish(deliveryCtx, reason, occurredAt, ids)
+	}()
+}
+
+// publish builds one payload per subscriber containing only the products it follows
+func (s *stockWebhookService) publish(ctx context.Context, reason StockChangeReason, occurredAt time.Time, productIDs []string) {
+	subscriptions, err := s.subscriptionRepo.ListActive(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load stock webhook subscriptions", "error", err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	records := make([]StockChangeRecord, 0, len(productIDs))
+	seen := make(map[string]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			continue
+		}
+		seen[productID] = true
+
+		inventory, err := s.inventoryRepo.GetByProductID(ctx, productID)
+		if err != nil {
+			s.logger.Error("Failed to load inventory for stock webhook", "error", err, "product_id", productID)
+			continue
+		}
+		if inventory == nil {
+			continue
+		}
+
+		record := StockChangeRecord{
+			ProductID: productID,
+			Available: inventory.Available,
+			Quantity:  inventory.Quantity,
+			Reserved:  inventory.Reserved,
+		}
+		if inventory.Product != nil {
+			record.SKU = inventory.Product.SKU
+		}
+		records = append(records, record)
+	}
+
+	for _, subscription := range subscriptions {
+		changes := make([]StockChangeRecord, 0, len(records))
+		for _, record := range records {
+			if subscription.MatchesProduct(record.ProductID) {
+				changes = append(changes, record)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		payload := StockChangeWebhookPayload{
+			Event:      StockChangedEvent,
+			Reason:     reason,
+			OccurredAt: occurredAt,
+			Changes:    changes,
+		}
+
+		deliveryErr := ""
+		if err := s.deliver(ctx, subscription, payload); err != nil {
+			s.logger.Error("Failed to deliver stock webhook", "error", err, "subscription_id", subscription.ID, "url", subscription.URL)
+			deliveryErr = err.Error()
+		} else {
+			s.logger.Info("Stock webhook delivered", "subscription_id", subscription.ID, "reason", reason, "changes", len(changes))
+		}
+
+		if err := s.subscriptionRepo.RecordDelivery(ctx, subscription.ID, deliveryErr); err != nil {
+			s.logger.Warn("Failed to record stock webhook delivery", "error", err, "subscription_id", subscription.ID)
+		}
+	}
+}
+
+// deliver POSTs the signed payload, retrying transport errors and 5xx responses
+func (s *stockWebhookService) deliver(ctx context.Context, subscription *models.StockWebhookSubscription, payload StockChangeWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	signature := SignStockWebhookPayload(subscription.Secret, body)
+
+	var lastErr error
+	for attempt := 1; attempt <= stockWebhookMaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(stockWebhookRetryDelay * time.Duration(attempt-1)):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(StockWebhookSignatureHeader, signature)
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+
+		lastErr = fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+		if resp.StatusCode < 500 {
+			// Client errors will not succeed on retry
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// SignStockWebhookPayload returns the signature subscribers use to verify a delivery
+func SignStockWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newStockWebhookSubscriptionResponse converts a subscription model to its response DTO
+func newStockWebhookSubscriptionResponse(subscription *models.StockWebhookSubscription) *StockWebhookSubscriptionResponse {
+	productIDs := []string(subscription.ProductIDs)
+	if productIDs == nil {
+		productIDs = []string{}
+	}
+
+	return &StockWebhookSubscriptionResponse{
+		ID:              subscription.ID,
+		Name:            subscription.Name,
+		URL:             subscription.URL,
+		ProductIDs:      productIDs,
+		IsActive:        subscription.IsActive,
+		LastDeliveredAt: subscription.LastDeliveredAt,
+		LastError:       subscription.LastError,
+		CreatedAt:       subscription.CreatedAt,
+	}
+}
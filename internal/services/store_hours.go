@@ -0,0 +1,305 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"easy-orders-backend/pkg/errors"
+)
+
+// DefaultStore keys the schedule of stores without one of their own
+const DefaultStore = "*"
+
+// shipLookaheadDays bounds the search for the next shipping day
+const shipLookaheadDays = 14
+
+// minutesPerDay is the closing time of stores open until midnight
+const minutesPerDay = 24 * 60
+
+// weekdays maps day abbreviations in store hours to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// openingHours are the minutes after midnight a store opens and closes on a day
+type openingHours struct {
+	open  int
+	close int
+}
+
+// StoreSchedule is when a store accepts orders and the cutoff for shipping them
+// the same day
+type StoreSchedule struct {
+	hours              map[time.Weekday]openingHours
+	cutoff             int // Minutes after midnight; -1 ships orders placed until closing time the same day
+	rejectOutsideHours bool
+}
+
+// StoreSchedules maps an order channel to the schedule of its store, in the store
+// timezone. Stores without a schedule, and all stores of nil schedules, are always
+// open and promise no ship date.
+type StoreSchedules struct {
+	location *time.Location
+	stores   map[string]*StoreSchedule
+}
+
+// NewStoreSchedules builds store schedules from configured hours, such as
+// "mon-fri 09:00-17:00,sat 10:00-14:00", and same-day shipping cutoffs, such as
+// "14:00", both keyed by channel
+func NewStoreSchedules(timezone string, hours, cutoffs map[string]string, rejectOutsideHours []string) (*StoreSchedules, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store timezone %q: %w", timezone, err)
+	}
+
+	schedules := &StoreSchedules{
+		location: location,
+		stores:   make(map[string]*StoreSchedule, len(hours)),
+	}
+	for store, spec := range hours {
+		schedule, err := parseStoreHours(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hours for store %s: %w", store, err)
+		}
+		schedules.stores[store] = schedule
+	}
+
+	// The default cutoff goes first, so that stores following the default hours inherit it
+	if spec, ok := cutoffs[DefaultStore]; ok {
+		if err := schedules.setCutoff(DefaultStore, spec); err != nil {
+			return nil, err
+		}
+	}
+	for store, spec := range cutoffs {
+		if store == DefaultStore {
+			continue
+		}
+		if err := schedules.setCutoff(store, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, store := range rejectOutsideHours {
+		schedule := schedules.own(store)
+		if schedule == nil {
+			return nil, fmt.Errorf("store %s rejects orders outside hours but has no hours", store)
+		}
+		schedule.rejectOutsideHours = true
+	}
+
+	return schedules, nil
+}
+
+// setCutoff sets the same-day shipping cutoff of a store
+func (s *StoreSchedules) setCutoff(store, spec string) error {
+	schedule := s.own(store)
+	if schedule == nil {
+		return fmt.Errorf("shipping cutoff for store %s, which has no hours", store)
+	}
+
+	cutoff, err := parseClock(spec)
+	if err != nil {
+		return fmt.Errorf("invalid shipping cutoff for store %s: %w", store, err)
+	}
+	schedule.cutoff = cutoff
+	return nil
+}
+
+// own returns the schedule of a store for a setting of its own. Stores following
+// the default hours get a copy of them; nil means there are no hours to follow.
+func (s *StoreSchedules) own(store string) *StoreSchedule {
+	if schedule, ok := s.stores[store]; ok {
+		return schedule
+	}
+	defaultSchedule, ok := s.stores[DefaultStore]
+	if !ok {
+		return nil
+	}
+	schedule := *defaultSchedule
+	s.stores[store] = &schedule
+	return &schedule
+}
+
+// Accept decides whether the store of a channel takes an order placed at the given
+// time, and returns the end of the day it promises to ship the order by; nil for
+// stores without hours. Stores that reject orders outside hours return a business
+// error while closed.
+func (s *StoreSchedules) Accept(channel string, at time.Time) (*time.Time, error) {
+	schedule := s.schedule(channel)
+	if schedule == nil {
+		return nil, nil
+	}
+
+	local := at.In(s.location)
+	if schedule.rejectOutsideHours && !schedule.isOpen(local) {
+		message := fmt.Sprintf("store %s is closed", channel)
+		if opens := schedule.nextOpening(local); opens != nil {
+			message += ", orders are accepted from " + opens.Format(time.RFC3339)
+		}
+		return nil, errors.NewBusinessError(message)
+	}
+
+	return schedule.promisedShipBy(local), nil
+}
+
+// schedule returns the schedule of the store of a channel, or the default one
+func (s *StoreSchedules) schedule(channel string) *StoreSchedule {
+	if s == nil {
+		return nil
+	}
+	if schedule, ok := s.stores[channel]; ok {
+		return schedule
+	}
+	return s.stores[DefaultStore]
+}
+
+// isOpen returns true if the store accepts orders at the local time
+func (s *StoreSchedule) isOpen(local time.Time) bool {
+	hours, ok := s.hours[local.Weekday()]
+	if !ok {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= hours.open && minute < hours.close
+}
+
+// nextOpening returns when the store next opens after the local time
+func (s *StoreSchedule) nextOpening(local time.Time) *time.Time {
+	for day := 0; day <= shipLookaheadDays; day++ {
+		date := local.AddDate(0, 0, day)
+		hours, ok := s.hours[date.Weekday()]
+		if !ok {
+			continue
+		}
+		opens := atMinute(date, hours.open)
+		if opens.After(local) {
+			return &opens
+		}
+	}
+	return nil
+}
+
+// promisedShipBy returns the closing time of the first open day the store ships an
+// order placed at the local time: the same day before the cutoff, else the next one
+func (s *StoreSchedule) promisedShipBy(local time.Time) *time.Time {
+	minute := local.Hour()*60 + local.Minute()
+	for day := 0; day <= shipLookaheadDays; day++ {
+		date := local.AddDate(0, 0, day)
+		hours, ok := s.hours[date.Weekday()]
+		if !ok {
+			continue
+		}
+		if day == 0 && minute >= s.cutoffOn(hours) {
+			continue
+		}
+		shipBy := atMinute(date, hours.close)
+		return &shipBy
+	}
+	return nil
+}
+
+// cutoffOn returns the same-day shipping cutoff on a day with the given hours
+func (s *StoreSchedule) cutoffOn(hours openingHours) int {
+	if s.cutoff < 0 || s.cutoff > hours.close {
+		return hours.close
+	}
+	return s.cutoff
+}
+
+// atMinute returns the given minute after midnight on the day of t, in its location
+func atMinute(t time.Time, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+}
+
+// parseStoreHours parses comma-separated day ranges with their hours, e.g.
+// "mon-fri 09:00-17:00,sat 10:00-14:00"
+func parseStoreHours(spec string) (*StoreSchedule, error) {
+	schedule := &StoreSchedule{
+		hours:  make(map[time.Weekday]openingHours),
+		cutoff: -1,
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		days, clock, found := strings.Cut(entry, " ")
+		if !found {
+			return nil, fmt.Errorf("expected days and hours, got %q", entry)
+		}
+
+		first, last, isRange := strings.Cut(strings.ToLower(days), "-")
+		if !isRange {
+			last = first
+		}
+		from, ok := weekdays[first]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", first)
+		}
+		to, ok := weekdays[last]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", last)
+		}
+
+		rawOpen, rawClose, found := strings.Cut(strings.TrimSpace(clock), "-")
+		if !found {
+			return nil, fmt.Errorf("expected hours as HH:MM-HH:MM, got %q", clock)
+		}
+		open, err := parseClock(rawOpen)
+		if err != nil {
+			return nil, err
+		}
+		closing, err := parseClock(rawClose)
+		if err != nil {
+			return nil, err
+		}
+		if closing <= open {
+			return nil, fmt.Errorf("hours %q must close after they open", clock)
+		}
+
+		// Day ranges may wrap around the week, e.g. fri-mon
+		for day := from; ; day = (day + 1) % 7 {
+			schedule.hours[day] = openingHours{open: open, close: closing}
+			if day == to {
+				break
+			}
+		}
+	}
+
+	if len(schedule.hours) == 0 {
+		return nil, fmt.Errorf("no opening hours")
+	}
+	return schedule, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight; 24:00 is midnight at the end of the day
+func parseClock(value string) (int, error) {
+	rawHour, rawMinute, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	hour, err := strconv.Atoi(rawHour)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	minute, err := strconv.Atoi(rawMinute)
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+
+	clock := hour*60 + minute
+	if hour < 0 || clock > minutesPerDay {
+		return 0, fmt.Errorf("time %q is outside the day", value)
+	}
+	return clock, nil
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// defaultSupportSearchLimit bounds the users and orders of a search that sets no limit
+const defaultSupportSearchLimit = 20
+
+// supportSearchService implements SupportSearchService interface
+type supportSearchService struct {
+	searchRepo repository.SupportSearchRepository
+	logger     *logger.Logger
+}
+
+// NewSupportSearchService creates a new support search service
+func NewSupportSearchService(searchRepo repository.SupportSearchRepository, logger *logger.Logger) SupportSearchService {
+	return &supportSearchService{
+		searchRepo: searchRepo,
+		logger:     logger,
+	}
+}
+
+// Search finds the users matching the email and card inputs, the orders matching every
+// input and the payments of those orders. Date only narrows the other inputs, as every
+// day has too many orders to look through.
+func (s *supportSearchService) Search(ctx context.Context, req SupportSearchRequest) (*SupportSearchResponse, error) {
+	filter := repository.SupportSearchFilter{
+		Email:          strings.TrimSpace(req.Email),
+		CardLast4:      req.CardLast4,
+		TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+	}
+	if req.Total > 0 {
+		total := req.Total
+		filter.Total = &total
+	}
+	if req.Date != "" {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, errors.NewValidationError("date must be formatted as YYYY-MM-DD")
+		}
+		filter.Date = &date
+	}
+	if filter.Email == "" && filter.CardLast4 == "" && filter.TrackingNumber == "" && filter.Total == nil {
+		return nil, errors.NewValidationError("search by email, card_last4, tracking_number or total")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSupportSearchLimit
+	}
+	s.logger.Info("Support search", "email", filter.Email != "", "card_last4", filter.CardLast4 != "",
+		"tracking_number", filter.TrackingNumber != "", "total", filter.Total != nil, "date", req.Date)
+
+	response := &SupportSearchResponse{
+		Users:    []*UserResponse{},
+		Orders:   []*SupportOrderMatch{},
+		Payments: []*PaymentResponse{},
+	}
+
+	if filter.Email != "" || filter.CardLast4 != "" {
+		users, err := s.searchRepo.FindUsers(ctx, filter, limit)
+		if err != nil {
+			return nil, errors.NewDatabaseError("failed to search users", err)
+		}
+		for _, user := range users {
+			response.Users = append(response.Users, &UserResponse{
+				ID:       user.ID,
+				Email:    user.Email,
+				Name:     user.Name,
+				Role:     string(user.Role),
+				IsActive: user.IsActive,
+			})
+		}
+	}
+
+	orders, err := s.searchRepo.FindOrders(ctx, filter, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to search orders", err)
+	}
+	orderIDs := make([]string, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+		response.Orders = append(response.Orders, newSupportOrderMatch(order))
+	}
+
+	payments, err := s.searchRepo.ListPaymentsByOrders(ctx, orderIDs)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to list payments of orders", err)
+	}
+	for _, payment := range payments {
+		response.Payments = append(response.Payments, toPaymentResponse(payment))
+	}
+
+	return response, nil
+}
+
+// newSupportOrderMatch returns the support search result of an order
+func newSupportOrderMatch(order *models.Order) *SupportOrderMatch {
+	return &SupportOrderMatch{
+		ID:             order.ID,
+		UserID:         order.UserID,
+		Status:         order.Status,
+		Total:          order.TotalAmount,
+		Paid:           order.PaidAmount,
+		Currency:       order.Currency,
+		Channel:        order.Channel,
+		TrackingNumber: order.Metadata["tracking_number"],
+		CreatedAt:      order.CreatedAt,
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"easy-orders-backend/internal/models"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userImportMaxRows caps the size of a single user import
+const userImportMaxRows = 5000
+
+// User import result statuses
+const (
+	UserImportStatusCreated = "created"
+	UserImportStatusUpdated = "updated"
+	UserImportStatusSkipped = "skipped"
+	UserImportStatusFailed  = "failed"
+)
+
+// userImportRequiredColumns must be present in the header row
+var userImportRequiredColumns = []string{"email", "name"}
+
+// importedUser is one CSV row of a user import
+type importedUser struct {
+	result       *UserImportResult
+	name         string
+	organization string
+	password     string
+}
+
+// ImportUsers creates customer accounts in bulk and assigns them to organizations,
+// which are created when they don't exist yet. Existing users are only moved into
+// the row's organization, and only when they don't belong to another one.
+func (s *organizationService) ImportUsers(ctx context.Context, r io.Reader) (*UserImportResponse, error) {
+	s.logger.Info("Importing users")
+
+	users, err := parseUserImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UserImportResponse{
+		TotalRows: len(users),
+		Users:     make([]UserImportResult, 0, len(users)),
+	}
+
+	organizations := make(map[string]*models.Organization)
+	for _, user := range users {
+		if user.result.Status == "" {
+			if err := s.importUser(ctx, user, organizations, response); err != nil {
+				user.result.Status = UserImportStatusFailed
+				user.result.Error = err.Error()
+			}
+		}
+
+		switch user.result.Status {
+		case UserImportStatusCreated:
+			response.Created++
+		case UserImportStatusUpdated:
+			response.Updated++
+		case UserImportStatusSkipped:
+			response.Skipped++
+		case UserImportStatusFailed:
+			response.Failed++
+		}
+		response.Users = append(response.Users, *user.result)
+	}
+
+	s.logger.Info("Users imported",
+		"total_rows", response.TotalRows,
+		"created", response.Created,
+		"updated", response.Updated,
+		"skipped", response.Skipped,
+		"failed", response.Failed,
+		"organizations_created", response.OrganizationsCreated)
+
+	return response, nil
+}
+
+// importUser creates or updates the user of one row
+func (s *organizationService) importUser(
+	ctx context.Context,
+	imported *importedUser,
+	organizations map[string]*models.Organization,
+	response *UserImportResponse,
+) error {
+	email := imported.result.Email
+
+	var organization *models.Organization
+	if imported.organization != "" {
+		var err error
+		if organization, err = s.importOrganization(ctx, imported.organization, organizations, response); err != nil {
+			return err
+		}
+		imported.result.OrganizationID = organization.ID
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		s.logger.Error("Failed to get user for import", "error", err, "email", email)
+		return fmt.Errorf("user lookup failed: %w", err)
+	}
+	if existing != nil {
+		imported.result.UserID = existing.ID
+		if organization == nil || (existing.OrganizationID != nil && *existing.OrganizationID == organization.ID) {
+			imported.result.Status = UserImportStatusSkipped
+			return nil
+		}
+		if existing.OrganizationID != nil {
+			return errors.New("user already belongs to another organization")
+		}
+
+		existing.OrganizationID = &organization.ID
+		if err := s.userRepo.Update(ctx, existing); err != nil {
+			s.logger.Error("Failed to assign imported user to organization", "error", err, "email", email)
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		imported.result.Status = UserImportStatusUpdated
+		return nil
+	}
+
+	// Users imported without a password get a random one and must reset it before signing in
+	password := imported.password
+	if password == "" {
+		password = uuid.New().String()
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to process password: %w", err)
+	}
+
+	user := &models.User{
+		Email:    email,
+		Name:     imported.name,
+		Password: string(hashedPassword),
+		Role:     models.UserRoleCustomer,
+		IsActive: true,
+		Locale:   i18n.DefaultLocale,
+	}
+	if organization != nil {
+		user.OrganizationID = &organization.ID
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.logger.Error("Failed to create imported user", "error", err, "email", email)
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	imported.result.UserID = user.ID
+	imported.result.Status = UserImportStatusCreated
+	return nil
+}
+
+// importOrganization finds an organization by name, or creates it without credit
+// terms; terms are set by an admin afterwards
+func (s *organizationService) importOrganization(
+	ctx context.Context,
+	name string,
+	organizations map[string]*models.Organization,
+	response *UserImportResponse,
+) (*models.Organization, error) {
+	if organization, ok := organizations[name]; ok {
+		return organization, nil
+	}
+
+	organization, err := s.organizationRepo.GetByName(ctx, name)
+	if err != nil {
+		s.logger.Error("Failed to get organization for user import", "error", err, "name", name)
+		return nil, fmt.Errorf("organization lookup failed: %w", err)
+	}
+	if organization == nil {
+		organization = &models.Organization{Name: name, IsActive: true}
+		if err := s.organizationRepo.Create(ctx, organization); err != nil {
+			s.logger.Error("Failed to create organization for user import", "error", err, "name", name)
+			return nil, fmt.Errorf("failed to create organization %s: %w", name, err)
+		}
+		response.OrganizationsCreated++
+	}
+
+	organizations[name] = organization
+	return organization, nil
+}
+
+// parseUserImportCSV reads one user per row. A header row naming the columns is
+// required; optional columns are organization and password. Invalid rows are
+// returned already marked as failed.
+func parseUserImportCSV(r io.Reader) ([]*importedUser, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, apperrors.NewValidationError("user import is empty")
+	}
+	if err != nil {
+		return nil, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range userImportRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("user import is missing the %s column", name))
+		}
+	}
+
+	var users []*importedUser
+	row := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, apperrors.NewValidationErrorWithDetails("invalid CSV file", err.Error())
+		}
+		row++
+
+		if len(users) >= userImportMaxRows {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("user import cannot exceed %d rows", userImportMaxRows))
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		user := &importedUser{
+			result:       &UserImportResult{Row: row, Email: strings.ToLower(field("email"))},
+			name:         field("name"),
+			organization: field("organization"),
+			password:     field("password"),
+		}
+		switch {
+		case user.result.Email == "" || !strings.Contains(user.result.Email, "@"):
+			user.result.Error = "a valid email is required"
+		case user.name == "":
+			user.result.Error = "name is required"
+		case user.password != "" && len(user.password) < 8:
+			user.result.Error = "password must be at least 8 characters"
+		}
+		if user.result.Error != "" {
+			user.result.Status = UserImportStatusFailed
+		}
+		users = append(users, user)
+	}
+
+	if len(users) == 0 {
+		return nil, apperrors.NewValidationError("user import is empty")
+	}
+
+	return users, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/jwt"
 	"easy-orders-backend/pkg/logger"
 
@@ -16,14 +17,18 @@ import (
 type userService struct {
 	userRepo     repository.UserRepository
 	tokenManager *jwt.TokenManager
+	activity     ActivityRecorder
+	referrals    ReferralTracker
 	logger       *logger.Logger
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository, tokenManager *jwt.TokenManager, logger *logger.Logger) UserService {
+func NewUserService(userRepo repository.UserRepository, tokenManager *jwt.TokenManager, activity ActivityRecorder, referrals ReferralTracker, logger *logger.Logger) UserService {
 	return &userService{
 		userRepo:     userRepo,
 		tokenManager: tokenManager,
+		activity:     activity,
+		referrals:    referrals,
 		logger:       logger,
 	}
 }
@@ -53,6 +58,15 @@ func (s *userService) CreateUser(ctx context.Context, req CreateUserRequest) (*U
 		return nil, errors.New("user with this email already exists")
 	}
 
+	// Signups with an unknown referral code are refused, so a mistyped code can be fixed
+	var referrerID string
+	if req.ReferralCode != "" && s.referrals != nil {
+		if referrerID, err = s.referrals.ResolveReferralCode(ctx, req.ReferralCode); err != nil {
+			s.logger.Warn("Invalid referral code", "error", err, "email", req.Email)
+			return nil, err
+		}
+	}
+
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -66,6 +80,7 @@ func (s *userService) CreateUser(ctx context.Context, req CreateUserRequest) (*U
 		Password: string(hashedPassword),
 		Role:     models.UserRoleCustomer, // Default role
 		IsActive: true,
+		Locale:   i18n.LocaleFromContext(ctx),
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -74,6 +89,10 @@ func (s *userService) CreateUser(ctx context.Context, req CreateUserRequest) (*U
 	}
 
 	s.logger.Info("User created successfully", "id", user.ID, "email", req.Email)
+	recordActivity(ctx, s.activity, user.ID, models.ActivityEventRegistered, "")
+	if referrerID != "" {
+		s.referrals.AttributeSignup(ctx, referrerID, user.ID, req.ReferralCode)
+	}
 
 	return &UserResponse{
 		ID:       user.ID,
@@ -180,6 +199,7 @@ func (s *userService) AuthenticateUser(ctx context.Context, email, password stri
 	}
 
 	s.logger.Info("User authenticated successfully", "user_id", user.ID, "email", user.Email)
+	recordActivity(ctx, s.activity, user.ID, models.ActivityEventLogin, "")
 
 	return &AuthResponse{
 		Token: token,
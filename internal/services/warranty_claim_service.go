@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+)
+
+// warrantyClaimService implements WarrantyClaimService interface
+type warrantyClaimService struct {
+	claimRepo repository.WarrantyClaimRepository
+	orderRepo repository.OrderRepository
+	payments  PaymentService
+	now       func() time.Time
+	logger    *logger.Logger
+}
+
+// NewWarrantyClaimService creates a new warranty claim service
+func NewWarrantyClaimService(
+	claimRepo repository.WarrantyClaimRepository,
+	orderRepo repository.OrderRepository,
+	payments PaymentService,
+	logger *logger.Logger,
+) WarrantyClaimService {
+	return &warrantyClaimService{
+		claimRepo: claimRepo,
+		orderRepo: orderRepo,
+		payments:  payments,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// CreateClaim opens a warranty claim on a unit the customer received, identified by
+// the serial number captured on its order item. A unit has one open claim at a time.
+func (s *warrantyClaimService) CreateClaim(ctx context.Context, userID, orderID, itemID string, req CreateWarrantyClaimRequest) (*models.WarrantyClaim, error) {
+	s.logger.Info("Creating warranty claim", "user_id", userID, "order_id", orderID, "item_id", itemID, "serial_number", req.SerialNumber)
+
+	serial := strings.TrimSpace(req.SerialNumber)
+	description := strings.TrimSpace(req.Description)
+	if serial == "" {
+		return nil, errors.NewValidationError("serial number is required")
+	}
+	if description == "" {
+		return nil, errors.NewValidationError("description is required")
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Error("Failed to get order for warranty claim", "error", err, "order_id", orderID)
+		return nil, err
+	}
+	if order == nil || order.UserID != userID {
+		return nil, errors.NewNotFoundErrorWithID("order", orderID)
+	}
+	if order.Status != models.OrderStatusShipped && order.Status != models.OrderStatusDelivered {
+		return nil, errors.NewConflictError(fmt.Sprintf("order in status %s has not shipped; only received units can be claimed", order.Status))
+	}
+
+	var item *models.OrderItem
+	for i := range order.Items {
+		if order.Items[i].ID == itemID {
+			item = &order.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, errors.NewNotFoundErrorWithID("order item", itemID)
+	}
+	found := false
+	for _, number := range item.SerialNumbers {
+		if number == serial {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.NewValidationError(fmt.Sprintf("serial number %s did not ship on this order item", serial))
+	}
+
+	claim := &models.WarrantyClaim{
+		OrderID:      orderID,
+		OrderItemID:  itemID,
+		ProductID:    item.ProductID,
+		UserID:       userID,
+		SerialNumber: serial,
+		Description:  description,
+		Status:       models.WarrantyClaimStatusOpen,
+	}
+	created, err := s.claimRepo.Create(ctx, claim)
+	if err != nil {
+		s.logger.Error("Failed to create warranty claim", "error", err, "order_id", orderID, "item_id", itemID)
+		return nil, errors.NewDatabaseError("failed to create warranty claim", err)
+	}
+	if !created {
+		return nil, errors.NewConflictError(fmt.Sprintf("serial number %s already has an open warranty claim", serial))
+	}
+
+	s.logger.Info("Warranty claim created", "claim_id", claim.ID, "order_id", orderID, "product_id", item.ProductID)
+	return claim, nil
+}
+
+func (s *warrantyClaimService) ListUserClaims(ctx context.Context, userID string, req ListWarrantyClaimsRequest) (*ListWarrantyClaimsResponse, error) {
+	s.logger.Debug("Listing user warranty claims", "user_id", userID, "page", req.Page, "limit", req.Limit)
+
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+	return s.list(ctx, repository.WarrantyClaimFilter{
+		UserID:    userID,
+		ProductID: req.ProductID,
+		Status:    models.WarrantyClaimStatus(req.Status),
+	}, req)
+}
+
+func (s *warrantyClaimService) ListClaims(ctx context.Context, req ListWarrantyClaimsRequest) (*ListWarrantyClaimsResponse, error) {
+	s.logger.Debug("Listing warranty claims", "status", req.Status, "product_id", req.ProductID, "page", req.Page, "limit", req.Limit)
+
+	return s.list(ctx, repository.WarrantyClaimFilter{
+		ProductID: req.ProductID,
+		Status:    models.WarrantyClaimStatus(req.Status),
+	}, req)
+}
+
+// list pages through the claims matching the filter, newest first
+func (s *warrantyClaimService) list(ctx context.Context, filter repository.WarrantyClaimFilter, req ListWarrantyClaimsRequest) (*ListWarrantyClaimsResponse, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("unsupported warranty claim status %s", filter.Status))
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	claims, err := s.claimRepo.List(ctx, filter, (page-1)*limit, limit)
+	if err != nil {
+		s.logger.Error("Failed to list warranty claims", "error", err)
+		return nil, errors.NewDatabaseError("failed to list warranty claims", err)
+	}
+	total, err := s.claimRepo.Count(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to count warranty claims", "error", err)
+		return nil, errors.NewDatabaseError("failed to count warranty claims", err)
+	}
+
+	return &ListWarrantyClaimsResponse{
+		Claims: claims,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+// ResolveClaim accepts an open claim. A repair only closes the claim; a replacement
+// places a zero-priced order for another unit, shipped to the original address and
+// queued for fulfillment like any paid order; a refund returns the unit's price to the
+// order's payments, oldest first. A claim is resolved once however many admins resolve
+// it at once, and is reopened if its refund fails.
+func (s *warrantyClaimService) ResolveClaim(ctx context.Context, id, userID string, req ResolveWarrantyClaimRequest) (*models.WarrantyClaim, error) {
+	s.logger.Info("Resolving warranty claim", "claim_id", id, "user_id", userID, "resolution", req.Resolution)
+
+	if !req.Resolution.IsValid() {
+		return nil, errors.NewValidationError(fmt.Sprintf("invalid warranty claim resolution %s", req.Resolution))
+	}
+
+	claim, err := s.getOpenClaim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	claim.Status = models.WarrantyClaimStatusResolved
+	claim.Resolution = req.Resolution
+	claim.ResolutionNote = strings.TrimSpace(req.Note)
+	claim.ResolvedBy = &userID
+	claim.ResolvedAt = &now
+
+	var order *models.Order
+	var replacement *models.Order
+	if req.Resolution != models.WarrantyClaimResolutionRepair {
+		order, err = s.orderRepo.GetByID(ctx, claim.OrderID)
+		if err != nil {
+			s.logger.Error("Failed to get order of warranty claim", "error", err, "claim_id", id, "order_id", claim.OrderID)
+			return nil, err
+		}
+		if order == nil {
+			return nil, errors.NewNotFoundErrorWithID("order", claim.OrderID)
+		}
+	}
+
+	switch req.Resolution {
+	case models.WarrantyClaimResolutionReplace:
+		replacement = s.newReplacementOrder(claim, order)
+	case models.WarrantyClaimResolutionRefund:
+		claim.RefundedAmount = math.Min(claimUnitPrice(claim, order), orderRefundableAmount(order))
+		if claim.RefundedAmount <= 0 {
+			return nil, errors.NewValidationError(fmt.Sprintf("order %s has nothing left to refund", claim.OrderID))
+		}
+	}
+
+	resolved, err := s.claimRepo.Resolve(ctx, claim, replacement)
+	if err != nil {
+		s.logger.Error("Failed to resolve warranty claim", "error", err, "claim_id", id)
+		if errors.IsErrorType(err, errors.ErrorTypeInsufficientStock) {
+			return nil, err
+		}
+		return nil, errors.NewDatabaseError("failed to resolve warranty claim", err)
+	}
+	if !resolved {
+		return nil, errors.NewConflictError(fmt.Sprintf("warranty claim %s is already closed", id))
+	}
+
+	if req.Resolution == models.WarrantyClaimResolutionRefund {
+		if err := s.refund(ctx, claim, order); err != nil {
+			if reopenErr := s.claimRepo.Reopen(ctx, id); reopenErr != nil {
+				s.logger.Error("Failed to reopen warranty claim after its refund failed", "error", reopenErr, "claim_id", id)
+			}
+			return nil, err
+		}
+	}
+
+	s.logger.Info("Warranty claim resolved", "claim_id", id, "resolution", req.Resolution,
+		"replacement_order_id", claim.ReplacementOrderID, "refunded", claim.RefundedAmount)
+	return claim, nil
+}
+
+func (s *warrantyClaimService) RejectClaim(ctx context.Context, id, userID string, req RejectWarrantyClaimRequest) (*models.WarrantyClaim, error) {
+	s.logger.Info("Rejecting warranty claim", "claim_id", id, "user_id", userID)
+
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, errors.NewValidationError("a note explaining the rejection is required")
+	}
+
+	claim, err := s.getOpenClaim(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	claim.Status = models.WarrantyClaimStatusRejected
+	claim.ResolutionNote = note
+	claim.ResolvedBy = &userID
+	claim.ResolvedAt = &now
+
+	rejected, err := s.claimRepo.Resolve(ctx, claim, nil)
+	if err != nil {
+		s.logger.Error("Failed to reject warranty claim", "error", err, "claim_id", id)
+		return nil, errors.NewDatabaseError("failed to reject warranty claim", err)
+	}
+	if !rejected {
+		return nil, errors.NewConflictError(fmt.Sprintf("warranty claim %s is already closed", id))
+	}
+
+	s.logger.Info("Warranty claim rejected", "claim_id", id)
+	return claim, nil
+}
+
+// GetClaimRates reports per serialized product the units shipped by the orders placed
+// in a date range and the warranty claims made in it
+func (s *warrantyClaimService) GetClaimRates(ctx context.Context, req WarrantyClaimRateReportRequest) (*WarrantyClaimRateReportResponse, error) {
+	s.logger.Info("Generating warranty claim rate report", "start_date", req.StartDate, "end_date", req.EndDate)
+
+	startDate, endDate, end, err := parseReportDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, errors.NewValidationError(err.Error())
+	}
+
+	summaries, err := s.claimRepo.SummarizeByProduct(ctx, startDate, end)
+	if err != nil {
+		s.logger.Error("Failed to summarize warranty claims", "error", err)
+		return nil, errors.NewDatabaseError("failed to summarize warranty claims", err)
+	}
+
+	response := &WarrantyClaimRateReportResponse{
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Products:  make([]WarrantyClaimRateItem, len(summaries)),
+	}
+	var totals repository.WarrantyClaimSummary
+	for i, summary := range summaries {
+		response.Products[i] = WarrantyClaimRateItem{
+			ProductID:              summary.ProductID,
+			Name:                   summary.Name,
+			SKU:                    summary.SKU,
+			WarrantyClaimRateStats: newWarrantyClaimRateStats(summary),
+		}
+		totals.UnitsShipped += summary.UnitsShipped
+		totals.Claims += summary.Claims
+		totals.Open += summary.Open
+		totals.Rejected += summary.Rejected
+		totals.Repairs += summary.Repairs
+		totals.Replacements += summary.Replacements
+		totals.Refunds += summary.Refunds
+	}
+	// Highest claim rates first, for the products to look into
+	sort.SliceStable(response.Products, func(i, j int) bool {
+		return response.Products[i].ClaimRate > response.Products[j].ClaimRate
+	})
+	response.Totals = newWarrantyClaimRateStats(totals)
+
+	return response, nil
+}
+
+// getOpenClaim returns a claim awaiting review
+func (s *warrantyClaimService) getOpenClaim(ctx context.Context, id string) (*models.WarrantyClaim, error) {
+	if id == "" {
+		return nil, errors.NewValidationError("claim ID is required")
+	}
+
+	claim, err := s.claimRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get warranty claim", "error", err, "claim_id", id)
+		return nil, err
+	}
+	if claim == nil {
+		return nil, errors.NewNotFoundErrorWithID("warranty claim", id)
+	}
+	if !claim.IsOpen() {
+		return nil, errors.NewConflictError(fmt.Sprintf("warranty claim %s is already %s", id, claim.Status))
+	}
+
+	return claim, nil
+}
+
+// newReplacementOrder returns the zero-priced order shipping a replacement unit for a
+// claim to the address the claimed unit shipped to. It is placed paid, so it is
+// confirmed and queued for fulfillment with the paid orders.
+func (s *warrantyClaimService) newReplacementOrder(claim *models.WarrantyClaim, order *models.Order) *models.Order {
+	listPrice := 0.0
+	for i := range order.Items {
+		if order.Items[i].ID == claim.OrderItemID {
+			listPrice = order.Items[i].ListUnitPrice()
+		}
+	}
+
+	replacement := &models.Order{
+		UserID:          claim.UserID,
+		Status:          models.OrderStatusPaid,
+		TotalAmount:     0,
+		Currency:        order.Currency,
+		Notes:           fmt.Sprintf("Warranty replacement for serial number %s (claim %s)", claim.SerialNumber, claim.ID),
+		Channel:         models.OrderChannelWarranty,
+		ExternalOrderID: claim.ID,
+		OrganizationID:  order.OrganizationID,
+		Items: []models.OrderItem{{
+			ProductID: claim.ProductID,
+			Quantity:  1,
+			UnitPrice: 0,
+			ListPrice: listPrice,
+		}},
+	}
+	if order.ShippingAddress != nil {
+		address := *order.ShippingAddress
+		replacement.ShippingAddress = &address
+	}
+	return replacement
+}
+
+// refund refunds a claim's amount from the claimed order's payments, oldest first
+func (s *warrantyClaimService) refund(ctx context.Context, claim *models.WarrantyClaim, order *models.Order) error {
+	payments := make([]models.Payment, len(order.Payments))
+	copy(payments, order.Payments)
+	sort.SliceStable(payments, func(i, j int) bool { return payments[i].CreatedAt.Before(payments[j].CreatedAt) })
+
+	var refunded float64
+	for i := range payments {
+		refundable := payments[i].RefundableAmount()
+		if refundable <= 0 {
+			continue
+		}
+		amount := math.Min(refundable, roundCents(claim.RefundedAmount-refunded))
+		if _, err := s.payments.RefundPayment(ctx, payments[i].ID, RefundRequest{
+			Amount:      amount,
+			Reason:      "warranty claim " + claim.ID,
+			RequestedBy: *claim.ResolvedBy,
+		}); err != nil {
+			s.logger.Error("Failed to refund warranty claim", "error", err, "claim_id", claim.ID,
+				"payment_id", payments[i].ID, "refunded", refunded)
+			return err
+		}
+		refunded = roundCents(refunded + amount)
+		if refunded >= claim.RefundedAmount {
+			break
+		}
+	}
+	return nil
+}
+
+// claimUnitPrice returns what the customer was charged for the claimed unit
+func claimUnitPrice(claim *models.WarrantyClaim, order *models.Order) float64 {
+	for i := range order.Items {
+		if order.Items[i].ID == claim.OrderItemID {
+			return order.Items[i].UnitPrice
+		}
+	}
+	return 0
+}
+
+// newWarrantyClaimRateStats derives the claim rate of summed units and claims
+func newWarrantyClaimRateStats(summary repository.WarrantyClaimSummary) WarrantyClaimRateStats {
+	return WarrantyClaimRateStats{
+		UnitsShipped: summary.UnitsShipped,
+		Claims:       summary.Claims,
+		ClaimRate:    percentage(int(summary.Claims), int(summary.UnitsShipped)),
+		Open:         summary.Open,
+		Rejected:     summary.Rejected,
+		Repairs:      summary.Repairs,
+		Replacements: summary.Replacements,
+		Refunds:      summary.Refunds,
+	}
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+)
+
+// WebhookEventHandler applies the side effects of a single webhook event
+type WebhookEventHandler func(ctx context.Context, event *models.WebhookEvent) error
+
+// PaymentWebhookVerifiers verifies gateway payment callbacks, keyed by the gateway
+// name in the callback URL
+type PaymentWebhookVerifiers map[string]payments.WebhookVerifier
+
+// PartnerWebhookSecrets holds the secret each partner signs its events with, keyed
+// by the provider name in the webhook URL
+type PartnerWebhookSecrets map[string]string
+
+// webhookService implements WebhookService interface
+type webhookService struct {
+	webhookRepo repository.WebhookEventRepository
+	paymentRepo repository.PaymentRepository
+	orderRepo   repository.OrderRepository
+	verifiers   PaymentWebhookVerifiers
+	partners    PartnerWebhookSecrets
+	ledger      LedgerRecorder
+	webhooks    WebhookPublisher
+	handlers    map[string]WebhookEventHandler
+	logger      *logger.Logger
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	webhookRepo repository.WebhookEventRepository,
+	paymentRepo repository.PaymentRepository,
+	orderRepo repository.OrderRepository,
+	verifiers PaymentWebhookVerifiers,
+	partners PartnerWebhookSecrets,
+	ledger LedgerRecorder,
+	webhooks WebhookPublisher,
+	logger *logger.Logger,
+) WebhookService {
+	s := &webhookService{
+		webhookRepo: webhookRepo,
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		verifiers:   verifiers,
+		partners:    partners,
+		ledger:      ledger,
+		webhooks:    webhooks,
+		handlers:    make(map[string]WebhookEventHandler),
+		logger:      logger,
+	}
+
+	// Gateway payment lifecycle events, dispatched only for signed gateway callbacks
+	// and replays of recorded events
+	s.handlers["payment.succeeded"] = s.paymentStatusHandler(models.PaymentStatusCompleted)
+	s.handlers["payment.failed"] = s.paymentStatusHandler(models.PaymentStatusFailed)
+	s.handlers["payment.refunded"] = s.paymentStatusHandler(models.PaymentStatusRefunded)
+
+	return s
+}
+
+func (s *webhookService) ReceiveEvent(ctx context.Context, provider string, header http.Header, body []byte) (*WebhookEventResponse, error) {
+	s.logger.Info("Receiving partner webhook", "provider", provider)
+
+	secret, ok := s.partners[provider]
+	if !ok || secret == "" {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("webhooks for provider %s", provider))
+	}
+	if !validPartnerSignature(secret, header, body) {
+		s.logger.Warn("Rejected partner webhook with an invalid signature", "provider", provider)
+		return nil, errors.NewUnauthorizedError("invalid webhook signature")
+	}
+
+	var req ReceiveWebhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid webhook payload", err.Error())
+	}
+	if req.EventID == "" || req.EventType == "" {
+		return nil, errors.NewValidationError("webhook event ID and type are required")
+	}
+	req.Provider = provider
+	req.Source = models.WebhookEventSourcePartner
+
+	// Partners are not gateways, so events with side effects on payments are refused
+	// before they are recorded
+	if _, handled := s.handlers[req.EventType]; handled {
+		s.logger.Warn("Rejected payment event from a partner", "provider", provider, "event_id", req.EventID, "type", req.EventType)
+		return nil, errors.NewUnauthorizedError("payment events must be sent by the gateway to /payments/webhooks/" + provider)
+	}
+
+	return s.receive(ctx, req)
+}
+
+// validPartnerSignature checks the X-Webhook-Signature header holds "sha256=" and the
+// hex HMAC-SHA256 of the body keyed by the partner's secret
+func validPartnerSignature(secret string, header http.Header, body []byte) bool {
+	signature, found := strings.CutPrefix(header.Get(payments.WebhookSignatureHeader), "sha256=")
+	if !found {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	return err == nil && hmac.Equal(expected, payments.SignWebhookPayload([]byte(secret), body))
+}
+
+// receive records an event once and processes it, or reports the duplicate
+func (s *webhookService) receive(ctx context.Context, req ReceiveWebhookRequest) (*WebhookEventResponse, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid webhook payload", err.Error())
+	}
+
+	event := &models.WebhookEvent{
+		Source:    req.Source,
+		Provider:  req.Provider,
+		EventID:   req.EventID,
+		EventType: req.EventType,
+		Payload:   string(payload),
+		Status:    models.WebhookEventStatusReceived,
+	}
+
+	created, err := s.webhookRepo.CreateIfNotExists(ctx, event)
+	if err != nil {
+		s.logger.Error("Failed to record webhook event", "error", err, "provider", req.Provider, "event_id", req.EventID)
+		return nil, err
+	}
+
+	if !created {
+		existing, err := s.webhookRepo.GetByProviderEventID(ctx, req.Source, req.Provider, req.EventID)
+		if err != nil {
+			s.logger.Error("Failed to load duplicate webhook event", "error", err, "provider", req.Provider, "event_id", req.EventID)
+			return nil, err
+		}
+		if existing == nil {
+			return nil, errors.NewInternalError("webhook event disappeared after conflict", nil)
+		}
+
+		// A redelivery of a failed event is another chance to process it
+		if existing.CanReplay() {
+			return s.processEvent(ctx, existing, models.WebhookEventStatusFailed)
+		}
+
+		response := newWebhookEventResponse(existing)
+		response.Duplicate = true
+		return response, nil
+	}
+
+	return s.processEvent(ctx, event, models.WebhookEventStatusReceived)
+}
+
+func (s *webhookService) ReceivePaymentWebhook(ctx context.Context, gateway string, header http.Header, body []byte) (*WebhookEventResponse, error) {
+	s.logger.Info("Receiving payment gateway webhook", "gateway", gateway)
+
+	verifier, ok := s.verifiers[gateway]
+	if !ok || verifier == nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("payment webhooks for gateway %s", gateway))
+	}
+
+	event, err := verifier.VerifyWebhook(header, body)
+	if stderrors.Is(err, payments.ErrInvalidWebhookSignature) {
+		s.logger.Warn("Rejected payment webhook with an invalid signature", "gateway", gateway, "error", err)
+		return nil, errors.NewUnauthorizedError("invalid webhook signature")
+	}
+	if err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid payment webhook", err.Error())
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"gateway_transaction_id": event.TransactionID,
+		"failure_type":           string(event.FailureType),
+		"failure_message":        event.FailureMessage,
+	})
+	if err != nil {
+		return nil, errors.NewValidationErrorWithDetails("invalid payment webhook", err.Error())
+	}
+
+	// Recorded like any other provider event, so redeliveries are deduplicated and
+	// failed events can be replayed
+	return s.receive(ctx, ReceiveWebhookRequest{
+		Provider:  gateway,
+		Source:    models.WebhookEventSourceGateway,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Data:      data,
+	})
+}
+
+func (s *webhookService) ReplayEvent(ctx context.Context, id string) (*WebhookEventResponse, error) {
+	s.logger.Info("Replaying webhook event", "id", id)
+
+	if id == "" {
+		return nil, errors.NewValidationError("webhook event ID is required")
+	}
+
+	event, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get webhook event for replay", "error", err, "id", id)
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.NewNotFoundErrorWithID("webhook event", id)
+	}
+
+	if !event.CanReplay() {
+		return nil, errors.NewConflictError(fmt.Sprintf("webhook event in status %s cannot be replayed", event.Status))
+	}
+
+	return s.processEvent(ctx, event, models.WebhookEventStatusFailed)
+}
+
+func (s *webhookService) ListEvents(ctx context.Context, req ListWebhookEventsRequest) (*ListWebhookEventsResponse, error) {
+	s.logger.Debug("Listing webhook events", "page", req.Page, "limit", req.Limit, "status", req.Status)
+
+	limit := req.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20 // Default limit
+	}
+
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	events, err := s.webhookRepo.ListByStatus(ctx, req.Status, offset, limit)
+	if err != nil {
+		s.logger.Error("Failed to list webhook events", "error", err)
+		return nil, err
+	}
+
+	total, err := s.webhookRepo.CountByStatus(ctx, req.Status)
+	if err != nil {
+		s.logger.Error("Failed to count webhook events", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*WebhookEventResponse, len(events))
+	for i, event := range events {
+		responses[i] = newWebhookEventResponse(event)
+	}
+
+	return &ListWebhookEventsResponse{
+		Events: responses,
+		Page:   page,
+		Limit:  limit,
+		Total:  int(total),
+	}, nil
+}
+
+// processEvent claims the event and runs its handler. The claim is a conditional
+// status update, so concurrent deliveries of the same event run the handler once.
+func (s *webhookService) processEvent(ctx context.Context, event *models.WebhookEvent, from models.WebhookEventStatus) (*WebhookEventResponse, error) {
+	claimed, err := s.webhookRepo.Claim(ctx, event.ID, from)
+	if err != nil {
+		s.logger.Error("Failed to claim webhook event", "error", err, "id", event.ID)
+		return nil, err
+	}
+
+	if !claimed {
+		s.logger.Info("Webhook event already claimed by another worker", "id", event.ID)
+		response := newWebhookEventResponse(event)
+		response.Duplicate = true
+		return response, nil
+	}
+
+	event.Attempts++
+	event.Status = models.WebhookEventStatusProcessing
+
+	handler, ok := s.handlers[event.EventType]
+	if !ok {
+		// Unhandled event types are acknowledged so providers stop redelivering them
+		s.logger.Debug("No handler registered for webhook event type", "type", event.EventType, "id", event.ID)
+	} else if handleErr := handler(ctx, event); handleErr != nil {
+		s.logger.Error("Webhook event handler failed", "error", handleErr, "id", event.ID, "type", event.EventType)
+
+		if err := s.webhookRepo.MarkFailed(ctx, event.ID, handleErr.Error()); err != nil {
+			return nil, err
+		}
+		event.Status = models.WebhookEventStatusFailed
+		event.LastError = handleErr.Error()
+		return newWebhookEventResponse(event), nil
+	}
+
+	if err := s.webhookRepo.MarkProcessed(ctx, event.ID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	event.Status = models.WebhookEventStatusProcessed
+	event.LastError = ""
+	event.ProcessedAt = &now
+
+	s.logger.Info("Webhook event processed", "id", event.ID, "type", event.EventType, "attempts", event.Attempts)
+	return newWebhookEventResponse(event), nil
+}
+
+// paymentStatusHandler updates the payment referenced by the event's transaction ID,
+// or by the gateway's transaction ID for verified gateway callbacks, and posts
+// settlements and refunds to the ledger. Posting is idempotent, so a replayed event
+// posts what an earlier failed attempt did not. A completed payment moves its order
+// to paid.
+func (s *webhookService) paymentStatusHandler(status models.PaymentStatus) WebhookEventHandler {
+	return func(ctx context.Context, event *models.WebhookEvent) error {
+		var payload struct {
+			Data struct {
+				TransactionID        string `json:"transaction_id"`
+				GatewayTransactionID string `json:"gateway_transaction_id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			return fmt.Errorf("invalid payment event payload: %w", err)
+		}
+
+		var payment *models.Payment
+		var err error
+		switch {
+		case payload.Data.GatewayTransactionID != "":
+			payment, err = s.paymentRepo.GetByGatewayTransactionID(ctx, event.Provider, payload.Data.GatewayTransactionID)
+		case payload.Data.TransactionID != "":
+			payment, err = s.paymentRepo.GetByTransactionID(ctx, payload.Data.TransactionID)
+		default:
+			return fmt.Errorf("payment event is missing data.transaction_id")
+		}
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			return fmt.Errorf("payment with transaction %s%s not found", payload.Data.TransactionID, payload.Data.GatewayTransactionID)
+		}
+
+		// Gateways do not guarantee delivery order, so a late failure or success
+		// never undoes a payment that has since settled or been refunded
+		if isStalePaymentTransition(payment.Status, status) {
+			s.logger.Info("Ignoring stale payment webhook", "payment_id", payment.ID,
+				"status", payment.Status, "event_status", status, "event_id", event.EventID)
+			return nil
+		}
+
+		if payment.Status != status {
+			if err := s.paymentRepo.UpdateStatus(ctx, payment.ID, status); err != nil {
+				return err
+			}
+			payment.Status = status
+
+			if status == models.PaymentStatusFailed {
+				publishPaymentFailed(ctx, s.webhooks, payment)
+			}
+		}
+
+		switch status {
+		case models.PaymentStatusCompleted:
+			if err := s.markOrderPaid(ctx, payment.OrderID); err != nil {
+				return err
+			}
+		case models.PaymentStatusRefunded:
+			// A refunded payment no longer counts towards what was paid for the order
+			if s.orderRepo != nil {
+				if _, err := s.orderRepo.RefreshPaidAmount(ctx, payment.OrderID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if s.ledger == nil {
+			return nil
+		}
+		switch status {
+		case models.PaymentStatusCompleted:
+			return s.ledger.RecordPayment(ctx, payment)
+		case models.PaymentStatusRefunded:
+			return s.ledger.RecordRefund(ctx, payment)
+		}
+		return nil
+	}
+}
+
+// markOrderPaid adds a confirmed payment to what was paid for its order, and moves the
+// order to paid once fully covered, unless it has already moved on or is an
+// on-account invoice settled after shipping
+func (s *webhookService) markOrderPaid(ctx context.Context, orderID string) error {
+	if s.orderRepo == nil {
+		return nil
+	}
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return nil
+	}
+
+	paid, err := recordOrderPayment(ctx, s.orderRepo, order)
+	if err != nil {
+		return err
+	}
+	if paid {
+		s.logger.Info("Order paid on gateway confirmation", "order_id", order.ID)
+	}
+	return nil
+}
+
+// isStalePaymentTransition reports whether moving a payment from current to next
+// would undo a later outcome
+func isStalePaymentTransition(current, next models.PaymentStatus) bool {
+	switch next {
+	case models.PaymentStatusFailed:
+		return current == models.PaymentStatusCompleted || current == models.PaymentStatusRefunded
+	case models.PaymentStatusCompleted:
+		return current == models.PaymentStatusRefunded
+	}
+	return false
+}
+
+// newWebhookEventResponse converts a webhook event model to its response
+func newWebhookEventResponse(event *models.WebhookEvent) *WebhookEventResponse {
+	return &WebhookEventResponse{
+		ID:          event.ID,
+		Source:      event.Source,
+		Provider:    event.Provider,
+		EventID:     event.EventID,
+		EventType:   event.EventType,
+		Status:      event.Status,
+		Attempts:    event.Attempts,
+		LastError:   event.LastError,
+		ProcessedAt: event.ProcessedAt,
+		CreatedAt:   event.CreatedAt,
+	}
+}
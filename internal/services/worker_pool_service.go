@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+)
+
+// maxPoolWorkers bounds how many workers a pool can be resized to
+const maxPoolWorkers = 100
+
+// workerPoolService implements WorkerPoolService interface
+type workerPoolService struct {
+	poolManager *workers.PoolManager
+	jobRepo     repository.BackgroundJobRepository
+	now         func() time.Time
+	logger      *logger.Logger
+}
+
+// NewWorkerPoolService creates a new worker pool service
+func NewWorkerPoolService(poolManager *workers.PoolManager, jobRepo repository.BackgroundJobRepository, logger *logger.Logger) WorkerPoolService {
+	return &workerPoolService{
+		poolManager: poolManager,
+		jobRepo:     jobRepo,
+		now:         time.Now,
+		logger:      logger,
+	}
+}
+
+func (s *workerPoolService) GetStats(ctx context.Context) (*WorkerStatsResponse, error) {
+	s.logger.Debug("Getting worker pool stats")
+
+	stored, err := s.jobRepo.CountQueuedJobs(ctx)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count queued jobs", err)
+	}
+
+	now := s.now()
+	metrics := s.poolManager.GetAllPoolMetrics()
+	response := &WorkerStatsResponse{
+		Pools:         make([]WorkerPoolStats, 0, len(metrics)),
+		ScheduledJobs: s.poolManager.GetScheduledJobCount(),
+		GeneratedAt:   now,
+	}
+	for name, m := range metrics {
+		response.Pools = append(response.Pools, toWorkerPoolStats(name, m, stored[name], now))
+	}
+	sort.Slice(response.Pools, func(i, j int) bool {
+		return response.Pools[i].Name < response.Pools[j].Name
+	})
+	return response, nil
+}
+
+func (s *workerPoolService) Resize(ctx context.Context, req ResizeWorkerPoolRequest) (*WorkerPoolStats, error) {
+	if req.Workers < 1 || req.Workers > maxPoolWorkers {
+		return nil, errors.NewValidationError("workers must be between 1 and 100")
+	}
+
+	metrics, err := s.poolManager.GetPoolMetrics(req.Pool)
+	if err != nil {
+		return nil, errors.NewNotFoundErrorWithID("worker pool", req.Pool)
+	}
+	if metrics.WorkerCount != req.Workers {
+		if err := s.poolManager.ResizePool(req.Pool, req.Workers); err != nil {
+			return nil, errors.NewInternalError("failed to resize worker pool", err)
+		}
+		s.logger.Info("Worker pool resized", "pool", req.Pool, "from", metrics.WorkerCount, "to", req.Workers)
+		if metrics, err = s.poolManager.GetPoolMetrics(req.Pool); err != nil {
+			return nil, errors.NewNotFoundErrorWithID("worker pool", req.Pool)
+		}
+	}
+
+	stored, err := s.jobRepo.CountQueuedJobs(ctx)
+	if err != nil {
+		return nil, errors.NewDatabaseError("failed to count queued jobs", err)
+	}
+	stats := toWorkerPoolStats(req.Pool, metrics, stored[req.Pool], s.now())
+	return &stats, nil
+}
+
+// toWorkerPoolStats reports a pool from its metrics, with throughput measured over the
+// time since it started
+func toWorkerPoolStats(name string, m *workers.PoolMetrics, storedJobs int64, now time.Time) WorkerPoolStats {
+	var minutes float64
+	var startedAt *time.Time
+	if !m.StartedAt.IsZero() {
+		started := m.StartedAt
+		startedAt = &started
+		minutes = now.Sub(started).Minutes()
+	}
+
+	stats := WorkerPoolStats{
+		Name:                name,
+		Workers:             m.WorkerCount,
+		InFlight:            m.ActiveWorkers,
+		QueueDepth:          m.QueueDepth,
+		QueueCapacity:       m.QueueCapacity,
+		StoredJobs:          storedJobs,
+		JobsProcessed:       m.JobsProcessed,
+		JobsSucceeded:       m.JobsSucceeded,
+		JobsFailed:          m.JobsFailed,
+		FailureRate:         failureRate(m.JobsFailed, m.JobsProcessed),
+		ThroughputPerMinute: throughput(m.JobsProcessed, minutes),
+		AverageLatencyMs:    m.AverageLatency.Milliseconds(),
+		StartedAt:           startedAt,
+		JobTypes:            make([]WorkerJobTypeStats, 0, len(m.JobTypes)),
+	}
+	for jobType, t := range m.JobTypes {
+		stats.JobTypes = append(stats.JobTypes, WorkerJobTypeStats{
+			Type:                jobType,
+			JobsProcessed:       t.JobsProcessed,
+			JobsSucceeded:       t.JobsSucceeded,
+			JobsFailed:          t.JobsFailed,
+			FailureRate:         failureRate(t.JobsFailed, t.JobsProcessed),
+			ThroughputPerMinute: throughput(t.JobsProcessed, minutes),
+			AverageLatencyMs:    t.AverageLatency.Milliseconds(),
+		})
+	}
+	sort.Slice(stats.JobTypes, func(i, j int) bool {
+		return stats.JobTypes[i].Type < stats.JobTypes[j].Type
+	})
+	return stats
+}
+
+// failureRate returns the share of processed jobs that failed, from 0 to 1
+func failureRate(failed, processed int64) float64 {
+	if processed == 0 {
+		return 0
+	}
+	return float64(failed) / float64(processed)
+}
+
+// throughput returns the jobs processed per minute over minutes
+func throughput(processed int64, minutes float64) float64 {
+	if minutes <= 0 {
+		return 0
+	}
+	return float64(processed) / minutes
+}
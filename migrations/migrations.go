@@ -1,6 +1,7 @@
 package migrations
 
 import (
+	"easy-orders-backend/internal/config"
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/pkg/logger"
 
@@ -9,15 +10,17 @@ import (
 
 // Migrator handles database migrations
 type Migrator struct {
-	db     *gorm.DB
-	logger *logger.Logger
+	db       *gorm.DB
+	settings config.MigrationsConfig
+	logger   *logger.Logger
 }
 
 // NewMigrator creates a new database migrator
-func NewMigrator(db *gorm.DB, logger *logger.Logger) *Migrator {
+func NewMigrator(db *gorm.DB, cfg *config.Config, logger *logger.Logger) *Migrator {
 	return &Migrator{
-		db:     db,
-		logger: logger,
+		db:       db,
+		settings: cfg.Migrations,
+		logger:   logger,
 	}
 }
 
@@ -49,6 +52,12 @@ func (m *Migrator) RunMigrations() error {
 		return err
 	}
 
+	// Change hot tables without blocking them
+	if err := m.runOnlineMigrations(); err != nil {
+		m.logger.Error("Failed to run online migrations", "error", err)
+		return err
+	}
+
 	m.logger.Info("Database migrations completed successfully")
 	return nil
 }
@@ -77,6 +86,43 @@ func (m *Migrator) RollbackMigrations() error {
 
 	// Drop tables in reverse dependency order
 	tables := []string{
+		"dead_jobs",
+		"background_jobs",
+		"order_event_cursors",
+		"fulfillment_tasks",
+		"payment_routing_rules",
+		"payment_reconciliations",
+		"idempotency_records",
+		"refunds",
+		"delivery_slots",
+		"order_promotions",
+		"promotions",
+		"price_list_items",
+		"price_lists",
+		"blocklist_entries",
+		"store_credit_transactions",
+		"referrals",
+		"referral_codes",
+		"api_usage",
+		"api_keys",
+		"report_results",
+		"webhook_relay_cursors",
+		"webhook_deliveries",
+		"webhook_endpoints",
+		"order_holds",
+		"payment_proofs",
+		"ledger_entries",
+		"order_events",
+		"inventory_events",
+		"saved_payment_methods",
+		"addresses",
+		"product_availability",
+		"payment_attempts",
+		"stock_holds",
+		"activity_events",
+		"channel_listings",
+		"stock_webhook_subscriptions",
+		"webhook_events",
 		"audit_logs",
 		"notifications",
 		"payments",
@@ -85,6 +131,7 @@ func (m *Migrator) RollbackMigrations() error {
 		"inventory",
 		"products",
 		"users",
+		"organizations",
 	}
 
 	for _, table := range tables {
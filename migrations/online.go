@@ -0,0 +1,295 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockNotAvailable is the PostgreSQL error code raised when lock_timeout expires
+const lockNotAvailable = "55P03"
+
+// Backfill fills new columns of existing rows in batches. Set is the SET clause and
+// Pending the condition of rows still to fill; Set must make Pending false, or the
+// backfill never ends.
+type Backfill struct {
+	Table   string
+	Set     string
+	Pending string
+}
+
+// DualWrite keeps new columns in sync with the columns they replace while both are
+// in use, with a trigger so that instances still running the previous release write
+// them too. Assign sets the new columns from NEW, as in "NEW.b := NEW.a * 100".
+type DualWrite struct {
+	Name    string
+	Table   string
+	Columns []string // the replaced columns whose changes fire the trigger
+	Assign  string
+}
+
+// withLockTimeout runs DDL on a hot table. The statement gives up after the lock
+// timeout instead of holding every query on the table behind it while it waits for
+// long transactions, such as stock reservations, and is retried with backoff.
+func (m *Migrator) withLockTimeout(description string, fc func(tx *gorm.DB) error) error {
+	var err error
+	for attempt := 0; attempt <= m.settings.LockRetries; attempt++ {
+		if attempt > 0 {
+			wait := m.settings.LockTimeout << (attempt - 1)
+			m.logger.Warn("Lock not available for migration, retrying",
+				"migration", description, "attempt", attempt, "wait", wait)
+			time.Sleep(wait)
+		}
+
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf("SET LOCAL lock_timeout = '%dms'", m.settings.LockTimeout.Milliseconds())).Error; err != nil {
+				return err
+			}
+			return fc(tx)
+		})
+		if err == nil || !isLockNotAvailable(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%s: lock not available after %d retries: %w", description, m.settings.LockRetries, err)
+}
+
+// addColumns adds columns to a table unless they exist. Columns must be nullable or
+// have a constant default, which PostgreSQL adds without rewriting the table.
+func (m *Migrator) addColumns(table string, columns ...string) error {
+	clauses := make([]string, len(columns))
+	for i, column := range columns {
+		clauses[i] = "ADD COLUMN IF NOT EXISTS " + column
+	}
+
+	return m.withLockTimeout("add columns to "+table, func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s %s", table, strings.Join(clauses, ", "))).Error
+	})
+}
+
+// createIndexConcurrently builds an index without blocking writes to the table. An
+// invalid index left behind by an interrupted build is dropped and built again.
+func (m *Migrator) createIndexConcurrently(name, definition string) error {
+	m.logger.Debug("Creating index concurrently", "index", name)
+
+	// CONCURRENTLY cannot run inside a transaction, so SET LOCAL is not available and
+	// the lock timeout is set on a pinned connection for the session instead
+	return m.db.Connection(func(conn *gorm.DB) error {
+		// Session only gives each statement fresh gorm statement state on the pinned
+		// connection; it does not touch the PostgreSQL session or its lock_timeout
+		conn = conn.Session(&gorm.Session{})
+
+		if err := conn.Exec(fmt.Sprintf("SET lock_timeout = '%dms'", m.settings.LockTimeout.Milliseconds())).Error; err != nil {
+			return err
+		}
+
+		err := m.buildIndex(conn, name, definition)
+
+		// The setting would otherwise stay on the connection after it goes back to
+		// the pool, failing unrelated statements that wait on a lock
+		if resetErr := conn.Exec("RESET lock_timeout").Error; resetErr != nil {
+			m.logger.Error("Failed to reset lock timeout after index build", "index", name, "error", resetErr)
+			if err == nil {
+				err = fmt.Errorf("reset lock timeout after creating index %s: %w", name, resetErr)
+			}
+		}
+		return err
+	})
+}
+
+// buildIndex builds an index concurrently on a connection with a lock timeout,
+// retrying builds that time out on the lock
+func (m *Migrator) buildIndex(conn *gorm.DB, name, definition string) error {
+	var err error
+	for attempt := 0; attempt <= m.settings.LockRetries; attempt++ {
+		if attempt > 0 {
+			wait := m.settings.LockTimeout << (attempt - 1)
+			m.logger.Warn("Lock not available for index build, retrying", "index", name, "attempt", attempt, "wait", wait)
+			time.Sleep(wait)
+		}
+
+		var invalid bool
+		if err = conn.Raw(`SELECT EXISTS (
+			SELECT 1 FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid
+			WHERE c.relname = ? AND NOT i.indisvalid
+		)`, name).Scan(&invalid).Error; err != nil {
+			return err
+		}
+		if invalid {
+			m.logger.Warn("Dropping invalid index left by an interrupted build", "index", name)
+			if err = conn.Exec("DROP INDEX CONCURRENTLY IF EXISTS " + name).Error; err != nil {
+				if isLockNotAvailable(err) {
+					continue
+				}
+				return err
+			}
+		}
+
+		err = conn.Exec(fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s", name, definition)).Error
+		if err == nil || !isLockNotAvailable(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("create index %s: lock not available after %d retries: %w", name, m.settings.LockRetries, err)
+}
+
+// backfill updates pending rows one batch at a time, each in its own short
+// transaction, until a batch comes back short. Rows locked by other transactions are
+// skipped rather than waited for; they are filled by the next run, as backfills are
+// safe to repeat.
+func (m *Migrator) backfill(b Backfill) error {
+	m.logger.Info("Backfilling", "table", b.Table, "batch_size", m.settings.BackfillBatchSize)
+
+	statement := fmt.Sprintf(`UPDATE %[1]s SET %[2]s WHERE id IN (
+		SELECT id FROM %[1]s WHERE %[3]s LIMIT ? FOR UPDATE SKIP LOCKED
+	)`, b.Table, b.Set, b.Pending)
+
+	var total int64
+	for {
+		var updated int64
+		err := m.withLockTimeout("backfill "+b.Table, func(tx *gorm.DB) error {
+			result := tx.Exec(statement, m.settings.BackfillBatchSize)
+			updated = result.RowsAffected
+			return result.Error
+		})
+		if err != nil {
+			return err
+		}
+
+		total += updated
+		if updated > 0 {
+			m.logger.Debug("Backfilled batch", "table", b.Table, "rows", updated, "total", total)
+		}
+		// A short batch found every unlocked pending row, so another would find none
+		if updated == 0 || updated < int64(m.settings.BackfillBatchSize) {
+			break
+		}
+		time.Sleep(m.settings.BackfillPause)
+	}
+
+	var remaining int64
+	if err := m.db.Table(b.Table).Where(b.Pending).Count(&remaining).Error; err != nil {
+		return err
+	}
+	if remaining > 0 {
+		m.logger.Warn("Backfill skipped locked rows, they are filled on the next run",
+			"table", b.Table, "remaining", remaining)
+	}
+
+	m.logger.Info("Backfill completed", "table", b.Table, "rows", total)
+	return nil
+}
+
+// enableDualWrite installs the trigger of a dual write, replacing its function so
+// that changes to Assign take effect
+func (m *Migrator) enableDualWrite(d DualWrite) error {
+	function := fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger AS $$
+	BEGIN
+		%s;
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`, d.Name, d.Assign)
+	if err := m.db.Exec(function).Error; err != nil {
+		return err
+	}
+
+	exists, err := m.triggerExists(d)
+	if err != nil || exists {
+		return err
+	}
+
+	m.logger.Info("Enabling dual write", "trigger", d.Name, "table", d.Table)
+	return m.withLockTimeout("enable dual write "+d.Name, func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("CREATE TRIGGER %[1]s BEFORE INSERT OR UPDATE OF %[2]s ON %[3]s FOR EACH ROW EXECUTE FUNCTION %[1]s()",
+			d.Name, strings.Join(d.Columns, ", "), d.Table)).Error
+	})
+}
+
+// disableDualWrite drops the trigger of a dual write, leaving the new columns as
+// they are. A later enable backfills the rows that changed in between.
+func (m *Migrator) disableDualWrite(d DualWrite) error {
+	exists, err := m.triggerExists(d)
+	if err != nil || !exists {
+		return err
+	}
+
+	m.logger.Warn("Disabling dual write", "trigger", d.Name, "table", d.Table)
+	return m.withLockTimeout("disable dual write "+d.Name, func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", d.Name, d.Table)).Error
+	})
+}
+
+// addCheckConstraint adds a check constraint unless it exists. It is added NOT VALID,
+// which checks new writes only and takes the table lock briefly, then validated for
+// existing rows without blocking writes.
+func (m *Migrator) addCheckConstraint(table, name, check string) error {
+	var validated []bool
+	if err := m.db.Raw("SELECT convalidated FROM pg_constraint WHERE conname = ? AND conrelid = ?::regclass", name, table).
+		Scan(&validated).Error; err != nil {
+		return err
+	}
+	if len(validated) > 0 && validated[0] {
+		return nil
+	}
+
+	if len(validated) == 0 {
+		if err := m.withLockTimeout("add constraint "+name, func(tx *gorm.DB) error {
+			return tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s) NOT VALID", table, name, check)).Error
+		}); err != nil {
+			return err
+		}
+	}
+
+	return m.withLockTimeout("validate constraint "+name, func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", table, name)).Error
+	})
+}
+
+// dropConstraint drops a constraint if it exists
+func (m *Migrator) dropConstraint(table, name string) error {
+	var exists bool
+	if err := m.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = ? AND conrelid = ?::regclass)", name, table).
+		Scan(&exists).Error; err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	return m.withLockTimeout("drop constraint "+name, func(tx *gorm.DB) error {
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", table, name)).Error
+	})
+}
+
+// dropIndex drops an index if it exists
+func (m *Migrator) dropIndex(name string) error {
+	var exists bool
+	if err := m.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_class WHERE relname = ? AND relkind = 'i')", name).
+		Scan(&exists).Error; err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	return m.withLockTimeout("drop index "+name, func(tx *gorm.DB) error {
+		return tx.Exec("DROP INDEX IF EXISTS " + name).Error
+	})
+}
+
+// triggerExists checks the catalog, so that no lock is taken when there is nothing to do
+func (m *Migrator) triggerExists(d DualWrite) (bool, error) {
+	var exists bool
+	err := m.db.Raw("SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = ? AND tgrelid = ?::regclass)", d.Name, d.Table).
+		Scan(&exists).Error
+	return exists, err
+}
+
+// isLockNotAvailable reports whether err is a lock_timeout expiry
+func isLockNotAvailable(err error) bool {
+	return strings.Contains(err.Error(), lockNotAvailable) || strings.Contains(err.Error(), "lock timeout")
+}
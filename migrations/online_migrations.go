@@ -0,0 +1,111 @@
+package migrations
+
+import "easy-orders-backend/internal/models"
+
+// productMoneyDualWrite keeps the minor-unit prices of products in sync with the
+// decimal ones until reads and writes move to the minor-unit columns
+var productMoneyDualWrite = DualWrite{
+	Name:    "products_money_minor_units",
+	Table:   "products",
+	Columns: []string{"price", "cost_price"},
+	Assign:  "NEW.price_cents := ROUND(NEW.price * 100); NEW.cost_price_cents := ROUND(NEW.cost_price * 100)",
+}
+
+// productMoneyBackfill fills the minor-unit prices of rows written without the dual write
+var productMoneyBackfill = Backfill{
+	Table: "products",
+	Set:   "price_cents = ROUND(price * 100), cost_price_cents = ROUND(cost_price * 100)",
+	Pending: "price_cents IS DISTINCT FROM ROUND(price * 100)::bigint " +
+		"OR cost_price_cents IS DISTINCT FROM ROUND(cost_price * 100)::bigint",
+}
+
+// orderPaidAmountBackfill fills the paid amount of orders paid before it was tracked
+var orderPaidAmountBackfill = Backfill{
+	Table: "orders",
+	Set: "paid_amount = (SELECT COALESCE(SUM(payments.amount), 0) FROM payments " +
+		"WHERE payments.order_id = orders.id AND payments.status = '" + string(models.PaymentStatusCompleted) + "')",
+	Pending: "paid_amount = 0 AND EXISTS (SELECT 1 FROM payments " +
+		"WHERE payments.order_id = orders.id AND payments.status = '" + string(models.PaymentStatusCompleted) + "')",
+}
+
+// runOnlineMigrations applies schema changes to hot tables without blocking them.
+// The columns they add are left out of auto-migration, which would take locks
+// without a timeout, and every step is safe to repeat on each startup.
+func (m *Migrator) runOnlineMigrations() error {
+	m.logger.Info("Running online migrations...")
+
+	// Inventory: stock is kept per warehouse. The constant default is added without a
+	// table rewrite and fills existing rows, so no backfill is needed.
+	if err := m.addColumns("inventory",
+		"warehouse_id varchar(50) NOT NULL DEFAULT '"+models.DefaultWarehouseID+"'",
+	); err != nil {
+		return err
+	}
+	if err := m.createIndexConcurrently("idx_inventory_warehouse_product", "inventory (warehouse_id, product_id)"); err != nil {
+		return err
+	}
+
+	// Inventory: bin locations, walked in order by pick lists
+	if err := m.addColumns("inventory", "location varchar(50) NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	// Inventory: safety stock held back from online sales. Available leaves it out, so
+	// the check that available is the unreserved stock gives way to one allowing for it.
+	if err := m.addColumns("inventory", "safety_stock integer NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := m.dropConstraint("inventory", "chk_inventory_available"); err != nil {
+		return err
+	}
+	if err := m.addCheckConstraint("inventory", "chk_inventory_sellable",
+		"safety_stock >= 0 AND available = GREATEST(quantity - reserved - safety_stock, 0)"); err != nil {
+		return err
+	}
+
+	// Inventory: low stock alerts
+	if err := m.createIndexConcurrently("idx_inventory_low_stock",
+		"inventory (available, min_stock) WHERE available <= min_stock"); err != nil {
+		return err
+	}
+
+	// Products: prices in minor units (cents), written by a trigger while the decimal
+	// columns are still in use, then backfilled for existing rows
+	if err := m.addColumns("products", "price_cents bigint", "cost_price_cents bigint"); err != nil {
+		return err
+	}
+	if m.settings.MoneyDualWrite {
+		if err := m.enableDualWrite(productMoneyDualWrite); err != nil {
+			return err
+		}
+		if err := m.backfill(productMoneyBackfill); err != nil {
+			return err
+		}
+	} else if err := m.disableDualWrite(productMoneyDualWrite); err != nil {
+		return err
+	}
+
+	// Orders: the amount paid by their completed payments, which may each cover part
+	// of the total; orders paid before it was tracked are backfilled
+	if err := m.addColumns("orders", "paid_amount decimal(10,2) NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := m.backfill(orderPaidAmountBackfill); err != nil {
+		return err
+	}
+
+	// Orders: the delivery slot chosen at checkout. The nullable column is added
+	// without a table rewrite, and existing orders have none.
+	if err := m.addColumns("orders", "delivery_slot_id uuid"); err != nil {
+		return err
+	}
+
+	// Webhook events: event IDs are unique per source, so the index over the provider
+	// and event ID alone, replaced by auto-migration, is dropped
+	if err := m.dropIndex("idx_webhook_events_provider_event"); err != nil {
+		return err
+	}
+
+	m.logger.Info("Online migrations completed successfully")
+	return nil
+}
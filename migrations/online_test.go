@@ -0,0 +1,387 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/config"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// errLockTimeout is how PostgreSQL reports an expired lock_timeout
+var errLockTimeout = errors.New("ERROR: canceling statement due to lock timeout (SQLSTATE 55P03)")
+
+// scriptedDB is a database/sql connector whose statements are answered by the test.
+// Exec returns the rows a statement affected; Query the single row a query returns.
+type scriptedDB struct {
+	mutex      sync.Mutex
+	statements []string
+	exec       func(query string) (int64, error)
+	query      func(query string) ([]string, []driver.Value, error)
+}
+
+func (db *scriptedDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &scriptedConn{db: db}, nil
+}
+func (db *scriptedDB) Driver() driver.Driver { return scriptedDriver{} }
+
+// executed returns the statements run so far, transaction boundaries included
+func (db *scriptedDB) executed() []string {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return append([]string(nil), db.statements...)
+}
+
+// count returns how many statements run so far contain fragment
+func (db *scriptedDB) count(fragment string) int {
+	count := 0
+	for _, statement := range db.executed() {
+		if strings.Contains(statement, fragment) {
+			count++
+		}
+	}
+	return count
+}
+
+func (db *scriptedDB) record(statement string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.statements = append(db.statements, statement)
+}
+
+type scriptedDriver struct{}
+
+func (scriptedDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("scripted driver is opened through its connector")
+}
+
+type scriptedConn struct {
+	db *scriptedDB
+}
+
+func (c *scriptedConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("scripted driver does not prepare statements")
+}
+func (c *scriptedConn) Close() error { return nil }
+func (c *scriptedConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return scriptedTx{db: c.db}, nil
+}
+
+func (c *scriptedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	var affected int64
+	if c.db.exec != nil {
+		var err error
+		if affected, err = c.db.exec(query); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (c *scriptedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query)
+	if c.db.query == nil {
+		return nil, errors.New("unexpected query: " + query)
+	}
+	columns, row, err := c.db.query(query)
+	if err != nil {
+		return nil, err
+	}
+	return &scriptedRows{columns: columns, row: row}, nil
+}
+
+type scriptedTx struct {
+	db *scriptedDB
+}
+
+func (tx scriptedTx) Commit() error   { tx.db.record("COMMIT"); return nil }
+func (tx scriptedTx) Rollback() error { tx.db.record("ROLLBACK"); return nil }
+
+type scriptedRows struct {
+	columns []string
+	row     []driver.Value
+	read    bool
+}
+
+func (r *scriptedRows) Columns() []string { return r.columns }
+func (r *scriptedRows) Close() error      { return nil }
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.row)
+	return nil
+}
+
+// OnlineMigrationsTestSuite defines the test suite for the online migration steps
+type OnlineMigrationsTestSuite struct {
+	suite.Suite
+	db       *scriptedDB
+	migrator *Migrator
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OnlineMigrationsTestSuite) SetupTest() {
+	suite.db = &scriptedDB{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(suite.db)}),
+		&gorm.Config{Logger: gormlogger.Discard})
+	suite.Require().NoError(err)
+
+	suite.migrator = &Migrator{
+		db: db,
+		settings: config.MigrationsConfig{
+			LockTimeout:       time.Millisecond,
+			LockRetries:       2,
+			BackfillBatchSize: 3,
+		},
+		logger: &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	}
+}
+
+// countRow answers count queries with n
+func countRow(n int64) func(string) ([]string, []driver.Value, error) {
+	return func(string) ([]string, []driver.Value, error) {
+		return []string{"count"}, []driver.Value{n}, nil
+	}
+}
+
+// Test backfill - A short batch means no pending rows are left, so no more are run
+func (suite *OnlineMigrationsTestSuite) TestBackfill_StopsOnShortBatch() {
+	batches := []int64{3, 3, 1}
+	suite.db.exec = func(query string) (int64, error) {
+		if !strings.HasPrefix(query, "UPDATE") {
+			return 0, nil
+		}
+		updated := batches[0]
+		batches = batches[1:]
+		return updated, nil
+	}
+	suite.db.query = countRow(0)
+
+	// Execute
+	err := suite.migrator.backfill(productMoneyBackfill)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, suite.db.count("UPDATE products"))
+	assert.Equal(suite.T(), 1, suite.db.count("SELECT count(*) FROM \"products\""))
+}
+
+// Test backfill - An empty batch ends the backfill, whether first or after full ones
+func (suite *OnlineMigrationsTestSuite) TestBackfill_StopsOnEmptyBatch() {
+	batches := []int64{3, 0}
+	suite.db.exec = func(query string) (int64, error) {
+		if !strings.HasPrefix(query, "UPDATE") {
+			return 0, nil
+		}
+		updated := batches[0]
+		batches = batches[1:]
+		return updated, nil
+	}
+	// Rows locked by other transactions are left for the next run
+	suite.db.query = countRow(2)
+
+	// Execute
+	err := suite.migrator.backfill(productMoneyBackfill)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, suite.db.count("UPDATE products"))
+	assert.Equal(suite.T(), 2, suite.db.count("SET LOCAL lock_timeout = '1ms'"))
+}
+
+// Test withLockTimeout - A statement that times out on the lock is retried in a new
+// transaction
+func (suite *OnlineMigrationsTestSuite) TestWithLockTimeout_RetriesLockTimeout() {
+	attempts := 0
+
+	// Execute
+	err := suite.migrator.withLockTimeout("add columns to inventory", func(tx *gorm.DB) error {
+		attempts++
+		if attempts < 3 {
+			return errLockTimeout
+		}
+		return nil
+	})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, attempts)
+	assert.Equal(suite.T(), 3, suite.db.count("SET LOCAL lock_timeout = '1ms'"))
+	assert.Equal(suite.T(), 2, suite.db.count("ROLLBACK"))
+	assert.Equal(suite.T(), 1, suite.db.count("COMMIT"))
+}
+
+// Test withLockTimeout - The migration fails once the lock is still taken after the
+// configured retries
+func (suite *OnlineMigrationsTestSuite) TestWithLockTimeout_GivesUpAfterRetries() {
+	attempts := 0
+
+	// Execute
+	err := suite.migrator.withLockTimeout("add columns to inventory", func(tx *gorm.DB) error {
+		attempts++
+		return errLockTimeout
+	})
+
+	// Assert
+	require.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, errLockTimeout)
+	assert.Contains(suite.T(), err.Error(), "lock not available after 2 retries")
+	assert.Equal(suite.T(), 3, attempts)
+}
+
+// Test withLockTimeout - Other errors fail the migration without a retry
+func (suite *OnlineMigrationsTestSuite) TestWithLockTimeout_OtherErrorNotRetried() {
+	attempts := 0
+	failure := errors.New("ERROR: column \"warehouse_id\" contains null values (SQLSTATE 23502)")
+
+	// Execute
+	err := suite.migrator.withLockTimeout("add columns to inventory", func(tx *gorm.DB) error {
+		attempts++
+		return failure
+	})
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, failure)
+	assert.Equal(suite.T(), 1, attempts)
+}
+
+// Test createIndexConcurrently - An invalid index left by an interrupted build is
+// dropped and built again
+func (suite *OnlineMigrationsTestSuite) TestCreateIndexConcurrently_RebuildsInvalidIndex() {
+	suite.db.query = func(query string) ([]string, []driver.Value, error) {
+		return []string{"exists"}, []driver.Value{true}, nil
+	}
+
+	// Execute
+	err := suite.migrator.createIndexConcurrently("idx_inventory_low_stock", "inventory (available, min_stock)")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"SET lock_timeout = '1ms'",
+		"DROP INDEX CONCURRENTLY IF EXISTS idx_inventory_low_stock",
+		"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_inventory_low_stock ON inventory (available, min_stock)",
+		"RESET lock_timeout",
+	}, withoutQueries(suite.db.executed()))
+}
+
+// Test createIndexConcurrently - A valid or missing index is left to IF NOT EXISTS
+func (suite *OnlineMigrationsTestSuite) TestCreateIndexConcurrently_ValidIndexKept() {
+	suite.db.query = func(query string) ([]string, []driver.Value, error) {
+		return []string{"exists"}, []driver.Value{false}, nil
+	}
+
+	// Execute
+	err := suite.migrator.createIndexConcurrently("idx_inventory_low_stock", "inventory (available, min_stock)")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, suite.db.count("DROP INDEX"))
+	assert.Equal(suite.T(), 1, suite.db.count("CREATE INDEX CONCURRENTLY"))
+}
+
+// Test createIndexConcurrently - A build that times out on the lock checks the index
+// again, dropping what the failed build left behind
+func (suite *OnlineMigrationsTestSuite) TestCreateIndexConcurrently_RetriesLockTimeout() {
+	builds := 0
+	suite.db.exec = func(query string) (int64, error) {
+		if strings.HasPrefix(query, "CREATE INDEX") {
+			builds++
+			if builds == 1 {
+				return 0, errLockTimeout
+			}
+		}
+		return 0, nil
+	}
+	suite.db.query = func(query string) ([]string, []driver.Value, error) {
+		return []string{"exists"}, []driver.Value{builds == 1}, nil
+	}
+
+	// Execute
+	err := suite.migrator.createIndexConcurrently("idx_inventory_low_stock", "inventory (available, min_stock)")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, builds)
+	assert.Equal(suite.T(), 1, suite.db.count("DROP INDEX CONCURRENTLY IF EXISTS idx_inventory_low_stock"))
+}
+
+// Test createIndexConcurrently - The session lock timeout is reset before the
+// connection goes back to the pool, also when the build fails
+func (suite *OnlineMigrationsTestSuite) TestCreateIndexConcurrently_ResetsLockTimeoutOnFailure() {
+	failure := errors.New("ERROR: could not create unique index (SQLSTATE 23505)")
+	suite.db.exec = func(query string) (int64, error) {
+		if strings.HasPrefix(query, "CREATE INDEX") {
+			return 0, failure
+		}
+		return 0, nil
+	}
+	suite.db.query = func(query string) ([]string, []driver.Value, error) {
+		return []string{"exists"}, []driver.Value{false}, nil
+	}
+
+	// Execute
+	err := suite.migrator.createIndexConcurrently("idx_inventory_low_stock", "inventory (available, min_stock)")
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, failure)
+	executed := withoutQueries(suite.db.executed())
+	assert.Equal(suite.T(), "RESET lock_timeout", executed[len(executed)-1])
+}
+
+// Test createIndexConcurrently - A lock timeout that could not be reset fails the
+// migration rather than leaving the setting on a pooled connection unnoticed
+func (suite *OnlineMigrationsTestSuite) TestCreateIndexConcurrently_ResetError() {
+	failure := errors.New("driver: bad connection")
+	suite.db.exec = func(query string) (int64, error) {
+		if query == "RESET lock_timeout" {
+			return 0, failure
+		}
+		return 0, nil
+	}
+	suite.db.query = func(query string) ([]string, []driver.Value, error) {
+		return []string{"exists"}, []driver.Value{false}, nil
+	}
+
+	// Execute
+	err := suite.migrator.createIndexConcurrently("idx_inventory_low_stock", "inventory (available, min_stock)")
+
+	// Assert
+	assert.ErrorIs(suite.T(), err, failure)
+	assert.Contains(suite.T(), err.Error(), "reset lock timeout")
+}
+
+// withoutQueries leaves the catalog lookups out of executed statements
+func withoutQueries(statements []string) []string {
+	var executed []string
+	for _, statement := range statements {
+		if !strings.HasPrefix(statement, "SELECT") {
+			executed = append(executed, statement)
+		}
+	}
+	return executed
+}
+
+// TestOnlineMigrationsTestSuite runs the test suite
+func TestOnlineMigrationsTestSuite(t *testing.T) {
+	suite.Run(t, new(OnlineMigrationsTestSuite))
+}
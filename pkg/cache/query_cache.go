@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats reports cache effectiveness for one namespace
+type Stats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Invalidations int64   `json:"invalidations"`
+	Entries       int     `json:"entries"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+// entry is a cached value and its expiry
+type entry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// counters tracks hit/miss metrics for a namespace
+type counters struct {
+	hits          int64 // atomic, Get only holds the read lock
+	misses        int64 // atomic
+	invalidations int64 // guarded by the write lock
+}
+
+// QueryCache is an in-memory TTL cache for query results. Keys are grouped in
+// namespaces so related entries can be invalidated together and metrics are
+// reported per namespace.
+type QueryCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mutex    sync.RWMutex
+	entries  map[string]map[string]entry
+	size     int
+	counters map[string]*counters
+
+	now func() time.Time
+}
+
+// NewQueryCache creates a cache whose entries expire after ttl. When
+// maxEntries is reached, expired entries are dropped before new ones are added.
+func NewQueryCache(ttl time.Duration, maxEntries int) *QueryCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+
+	return &QueryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]map[string]entry),
+		counters:   make(map[string]*counters),
+		now:        time.Now,
+	}
+}
+
+// SetClock overrides the time source, for tests
+func (c *QueryCache) SetClock(now func() time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// Get returns the cached value for a key if it has not expired
+func (c *QueryCache) Get(namespace, key string) (interface{}, bool) {
+	c.mutex.RLock()
+	cached, ok := c.entries[namespace][key]
+	now := c.now()
+	counter := c.counters[namespace]
+	c.mutex.RUnlock()
+
+	if ok && now.Before(cached.expiresAt) {
+		if counter != nil {
+			atomic.AddInt64(&counter.hits, 1)
+		}
+		return cached.value, true
+	}
+
+	c.recordMiss(namespace)
+	return nil, false
+}
+
+// Set stores a value under a key for the cache TTL
+func (c *QueryCache) Set(namespace, key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bucket, ok := c.entries[namespace]
+	if !ok {
+		bucket = make(map[string]entry)
+		c.entries[namespace] = bucket
+	}
+
+	if _, exists := bucket[key]; !exists {
+		if c.size >= c.maxEntries {
+			c.evictLocked()
+		}
+		if c.size >= c.maxEntries {
+			// Still full of live entries; skip caching rather than grow unbounded
+			return
+		}
+		c.size++
+	}
+
+	bucket[key] = entry{value: value, expiresAt: c.now().Add(c.ttl)}
+}
+
+// Invalidate removes the given keys from a namespace
+func (c *QueryCache) Invalidate(namespace string, keys ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bucket := c.entries[namespace]
+	for _, key := range keys {
+		if _, ok := bucket[key]; ok {
+			delete(bucket, key)
+			c.size--
+		}
+	}
+	c.counterLocked(namespace).invalidations += int64(len(keys))
+}
+
+// InvalidateNamespace removes every key in a namespace
+func (c *QueryCache) InvalidateNamespace(namespace string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.size -= len(c.entries[namespace])
+	delete(c.entries, namespace)
+	c.counterLocked(namespace).invalidations++
+}
+
+// Stats returns metrics for every namespace that has been used
+func (c *QueryCache) Stats() map[string]Stats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	stats := make(map[string]Stats, len(c.counters))
+	for namespace, counter := range c.counters {
+		hits := atomic.LoadInt64(&counter.hits)
+		misses := atomic.LoadInt64(&counter.misses)
+
+		s := Stats{
+			Hits:          hits,
+			Misses:        misses,
+			Invalidations: counter.invalidations,
+			Entries:       len(c.entries[namespace]),
+		}
+		if hits+misses > 0 {
+			s.HitRate = float64(hits) / float64(hits+misses)
+		}
+		stats[namespace] = s
+	}
+	return stats
+}
+
+// recordMiss counts a miss, creating the namespace counters on first use
+func (c *QueryCache) recordMiss(namespace string) {
+	c.mutex.Lock()
+	counter := c.counterLocked(namespace)
+	c.mutex.Unlock()
+	atomic.AddInt64(&counter.misses, 1)
+}
+
+// counterLocked returns the counters for a namespace; the caller holds the write lock
+func (c *QueryCache) counterLocked(namespace string) *counters {
+	counter, ok := c.counters[namespace]
+	if !ok {
+		counter = &counters{}
+		c.counters[namespace] = counter
+	}
+	return counter
+}
+
+// evictLocked drops expired entries; the caller holds the write lock
+func (c *QueryCache) evictLocked() {
+	now := c.now()
+	for _, bucket := range c.entries {
+		for key, cached := range bucket {
+			if !now.Before(cached.expiresAt) {
+				delete(bucket, key)
+				c.size--
+			}
+		}
+	}
+}
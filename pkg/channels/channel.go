@@ -0,0 +1,142 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// ChannelType identifies an external sales channel
+type ChannelType string
+
+const (
+	ChannelTypeMock    ChannelType = "mock"
+	ChannelTypeShopify ChannelType = "shopify"
+)
+
+// Connector defines the interface for external sales channel integrations.
+// Orders flow in from the channel; inventory and prices flow out to it.
+type Connector interface {
+	// GetChannelType returns the channel type
+	GetChannelType() ChannelType
+
+	// ParseOrder maps a channel-specific order payload to an ExternalOrder
+	ParseOrder(payload []byte) (*ExternalOrder, error)
+
+	// PushInventory publishes available quantities for listed products
+	PushInventory(ctx context.Context, updates []InventoryUpdate) error
+
+	// PushPrices publishes prices for listed products
+	PushPrices(ctx context.Context, updates []PriceUpdate) error
+
+	// IsHealthy checks if the channel is reachable
+	IsHealthy(ctx context.Context) bool
+}
+
+// ExternalOrder is a channel order normalized to channel SKUs
+type ExternalOrder struct {
+	Channel       ChannelType         `json:"channel"`
+	ExternalID    string              `json:"external_id"`
+	CustomerEmail string              `json:"customer_email"`
+	Notes         string              `json:"notes,omitempty"`
+	Items         []ExternalOrderItem `json:"items"`
+}
+
+// ExternalOrderItem is a single line of a channel order
+type ExternalOrderItem struct {
+	ChannelSKU string  `json:"channel_sku"`
+	Quantity   int     `json:"quantity"`
+	UnitPrice  float64 `json:"unit_price"`
+}
+
+// InventoryUpdate is an available quantity to publish for one listing
+type InventoryUpdate struct {
+	ChannelSKU string `json:"channel_sku"`
+	ExternalID string `json:"external_id,omitempty"`
+	Available  int    `json:"available"`
+}
+
+// PriceUpdate is a price to publish for one listing
+type PriceUpdate struct {
+	ChannelSKU string  `json:"channel_sku"`
+	ExternalID string  `json:"external_id,omitempty"`
+	Price      float64 `json:"price"`
+	Currency   string  `json:"currency"`
+}
+
+// Validate checks that a parsed order can be imported
+func (o *ExternalOrder) Validate() error {
+	if o.ExternalID == "" {
+		return fmt.Errorf("external order ID is required")
+	}
+	if o.CustomerEmail == "" {
+		return fmt.Errorf("customer email is required")
+	}
+	if len(o.Items) == 0 {
+		return fmt.Errorf("order must have at least one item")
+	}
+	for _, item := range o.Items {
+		if item.ChannelSKU == "" {
+			return fmt.Errorf("channel SKU is required for all items")
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity must be greater than 0 for channel SKU %s", item.ChannelSKU)
+		}
+	}
+	return nil
+}
+
+// ChannelManager keeps the registered channel connectors
+type ChannelManager struct {
+	connectors map[ChannelType]Connector
+	mutex      sync.RWMutex
+	logger     *logger.Logger
+}
+
+// NewChannelManager creates a new channel manager
+func NewChannelManager(logger *logger.Logger) *ChannelManager {
+	return &ChannelManager{
+		connectors: make(map[ChannelType]Connector),
+		logger:     logger,
+	}
+}
+
+// RegisterConnector registers a channel connector
+func (cm *ChannelManager) RegisterConnector(connector Connector) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+
+	channelType := connector.GetChannelType()
+	cm.connectors[channelType] = connector
+	cm.logger.Info("Channel connector registered", "channel", channelType)
+}
+
+// GetConnector returns the connector for a channel
+func (cm *ChannelManager) GetConnector(channelType ChannelType) (Connector, error) {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	connector, exists := cm.connectors[channelType]
+	if !exists {
+		return nil, fmt.Errorf("channel %s not found", channelType)
+	}
+	return connector, nil
+}
+
+// ListChannels returns the registered channel types in name order
+func (cm *ChannelManager) ListChannels() []ChannelType {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+
+	channelTypes := make([]ChannelType, 0, len(cm.connectors))
+	for channelType := range cm.connectors {
+		channelTypes = append(channelTypes, channelType)
+	}
+	sort.Slice(channelTypes, func(i, j int) bool {
+		return channelTypes[i] < channelTypes[j]
+	})
+	return channelTypes
+}
@@ -0,0 +1,109 @@
+package channels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// mockOrderPayload is the simple JSON order format accepted by the mock connector
+type mockOrderPayload struct {
+	OrderID       string `json:"order_id"`
+	CustomerEmail string `json:"customer_email"`
+	Notes         string `json:"notes"`
+	Items         []struct {
+		SKU      string  `json:"sku"`
+		Quantity int     `json:"quantity"`
+		Price    float64 `json:"price"`
+	} `json:"items"`
+}
+
+// MockConnector is an in-memory connector for development and testing.
+// It records every pushed update instead of calling an external API.
+type MockConnector struct {
+	mutex            sync.Mutex
+	inventoryUpdates []InventoryUpdate
+	priceUpdates     []PriceUpdate
+	logger           *logger.Logger
+}
+
+// NewMockConnector creates a new mock connector
+func NewMockConnector(logger *logger.Logger) *MockConnector {
+	return &MockConnector{
+		logger: logger,
+	}
+}
+
+// GetChannelType returns the channel type
+func (m *MockConnector) GetChannelType() ChannelType {
+	return ChannelTypeMock
+}
+
+// ParseOrder maps a mock order payload to an ExternalOrder
+func (m *MockConnector) ParseOrder(payload []byte) (*ExternalOrder, error) {
+	var raw mockOrderPayload
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid mock order payload: %w", err)
+	}
+
+	order := &ExternalOrder{
+		Channel:       ChannelTypeMock,
+		ExternalID:    raw.OrderID,
+		CustomerEmail: raw.CustomerEmail,
+		Notes:         raw.Notes,
+		Items:         make([]ExternalOrderItem, len(raw.Items)),
+	}
+	for i, item := range raw.Items {
+		order.Items[i] = ExternalOrderItem{
+			ChannelSKU: item.SKU,
+			Quantity:   item.Quantity,
+			UnitPrice:  item.Price,
+		}
+	}
+
+	return order, nil
+}
+
+// PushInventory records the inventory updates
+func (m *MockConnector) PushInventory(ctx context.Context, updates []InventoryUpdate) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.inventoryUpdates = append(m.inventoryUpdates, updates...)
+	m.logger.Debug("Mock channel inventory pushed", "updates", len(updates))
+	return nil
+}
+
+// PushPrices records the price updates
+func (m *MockConnector) PushPrices(ctx context.Context, updates []PriceUpdate) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.priceUpdates = append(m.priceUpdates, updates...)
+	m.logger.Debug("Mock channel prices pushed", "updates", len(updates))
+	return nil
+}
+
+// IsHealthy always reports the mock channel as healthy
+func (m *MockConnector) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+// GetInventoryUpdates returns the inventory updates pushed so far
+func (m *MockConnector) GetInventoryUpdates() []InventoryUpdate {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]InventoryUpdate(nil), m.inventoryUpdates...)
+}
+
+// GetPriceUpdates returns the price updates pushed so far
+func (m *MockConnector) GetPriceUpdates() []PriceUpdate {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]PriceUpdate(nil), m.priceUpdates...)
+}
@@ -0,0 +1,185 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// ShopifyConfig holds the credentials for a Shopify store
+type ShopifyConfig struct {
+	ShopDomain  string        `json:"shop_domain"` // e.g. my-store.myshopify.com
+	AccessToken string        `json:"-"`
+	LocationID  int64         `json:"location_id"`
+	APIVersion  string        `json:"api_version"`
+	Timeout     time.Duration `json:"timeout"`
+}
+
+// shopifyOrderPayload is the subset of the Shopify order webhook that is imported
+type shopifyOrderPayload struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	Note      string `json:"note"`
+	LineItems []struct {
+		SKU      string `json:"sku"`
+		Quantity int    `json:"quantity"`
+		Price    string `json:"price"`
+	} `json:"line_items"`
+}
+
+// ShopifyConnector integrates with the Shopify Admin REST API.
+// Listings use the Shopify inventory item ID for stock and the variant ID for
+// prices, so the listing external ID is expected in "inventoryItemID:variantID" form.
+type ShopifyConnector struct {
+	config *ShopifyConfig
+	client *http.Client
+	logger *logger.Logger
+}
+
+// NewShopifyConnector creates a new Shopify connector
+func NewShopifyConnector(config *ShopifyConfig, logger *logger.Logger) *ShopifyConnector {
+	if config.APIVersion == "" {
+		config.APIVersion = "2024-01"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &ShopifyConnector{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: logger,
+	}
+}
+
+// GetChannelType returns the channel type
+func (s *ShopifyConnector) GetChannelType() ChannelType {
+	return ChannelTypeShopify
+}
+
+// ParseOrder maps a Shopify order webhook payload to an ExternalOrder
+func (s *ShopifyConnector) ParseOrder(payload []byte) (*ExternalOrder, error) {
+	var raw shopifyOrderPayload
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("invalid shopify order payload: %w", err)
+	}
+
+	order := &ExternalOrder{
+		Channel:       ChannelTypeShopify,
+		CustomerEmail: raw.Email,
+		Notes:         raw.Note,
+		Items:         make([]ExternalOrderItem, len(raw.LineItems)),
+	}
+	if raw.ID != 0 {
+		order.ExternalID = strconv.FormatInt(raw.ID, 10)
+	}
+
+	for i, item := range raw.LineItems {
+		// Shopify sends money amounts as decimal strings
+		price, err := strconv.ParseFloat(item.Price, 64)
+		if err != nil && item.Price != "" {
+			return nil, fmt.Errorf("invalid price %q for shopify SKU %s", item.Price, item.SKU)
+		}
+		order.Items[i] = ExternalOrderItem{
+			ChannelSKU: item.SKU,
+			Quantity:   item.Quantity,
+			UnitPrice:  price,
+		}
+	}
+
+	return order, nil
+}
+
+// PushInventory sets the available quantity of each listing at the configured location
+func (s *ShopifyConnector) PushInventory(ctx context.Context, updates []InventoryUpdate) error {
+	for _, update := range updates {
+		inventoryItemID, _, err := splitShopifyExternalID(update.ExternalID)
+		if err != nil {
+			return fmt.Errorf("shopify SKU %s: %w", update.ChannelSKU, err)
+		}
+
+		body := map[string]interface{}{
+			"location_id":       s.config.LocationID,
+			"inventory_item_id": inventoryItemID,
+			"available":         update.Available,
+		}
+		if err := s.do(ctx, http.MethodPost, "/inventory_levels/set.json", body); err != nil {
+			return fmt.Errorf("failed to push inventory for shopify SKU %s: %w", update.ChannelSKU, err)
+		}
+	}
+
+	s.logger.Info("Shopify inventory pushed", "shop", s.config.ShopDomain, "updates", len(updates))
+	return nil
+}
+
+// PushPrices updates the price of each listing's variant
+func (s *ShopifyConnector) PushPrices(ctx context.Context, updates []PriceUpdate) error {
+	for _, update := range updates {
+		_, variantID, err := splitShopifyExternalID(update.ExternalID)
+		if err != nil {
+			return fmt.Errorf("shopify SKU %s: %w", update.ChannelSKU, err)
+		}
+
+		body := map[string]interface{}{
+			"variant": map[string]interface{}{
+				"id":    variantID,
+				"price": strconv.FormatFloat(update.Price, 'f', 2, 64),
+			},
+		}
+		if err := s.do(ctx, http.MethodPut, fmt.Sprintf("/variants/%d.json", variantID), body); err != nil {
+			return fmt.Errorf("failed to push price for shopify SKU %s: %w", update.ChannelSKU, err)
+		}
+	}
+
+	s.logger.Info("Shopify prices pushed", "shop", s.config.ShopDomain, "updates", len(updates))
+	return nil
+}
+
+// IsHealthy checks that the store API accepts the configured credentials
+func (s *ShopifyConnector) IsHealthy(ctx context.Context) bool {
+	return s.do(ctx, http.MethodGet, "/shop.json", nil) == nil
+}
+
+// do sends an authenticated request to the Shopify Admin API
+func (s *ShopifyConnector) do(ctx context.Context, method, path string, body interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	url := fmt.Sprintf("https://%s/admin/api/%s%s", s.config.ShopDomain, s.config.APIVersion, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Shopify-Access-Token", s.config.AccessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("shopify responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// splitShopifyExternalID parses an "inventoryItemID:variantID" listing external ID
+func splitShopifyExternalID(externalID string) (int64, int64, error) {
+	var inventoryItemID, variantID int64
+	if _, err := fmt.Sscanf(externalID, "%d:%d", &inventoryItemID, &variantID); err != nil {
+		return 0, 0, fmt.Errorf("external ID must be in inventoryItemID:variantID form")
+	}
+	return inventoryItemID, variantID, nil
+}
@@ -0,0 +1,72 @@
+// Package documents renders printable documents, such as pick lists, packing slips
+// and reports, as HTML or PDF. A document is a title, a few labelled details, a table
+// and closing notes, which is all a warehouse printout needs; longer documents such
+// as reports follow the table with titled sections of their own.
+package documents
+
+import "fmt"
+
+// Format is the output format of a rendered document
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+	FormatPDF  Format = "pdf"
+)
+
+// ContentType returns the MIME type of documents rendered in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Document is a printable document. A table without columns is left out.
+type Document struct {
+	Title    string
+	Details  []Field
+	Table    Table
+	Sections []Section
+	Notes    []string
+}
+
+// Section is a titled part of a document printed after its table, such as one
+// breakdown of a report
+type Section struct {
+	Title   string
+	Details []Field
+	Table   Table
+}
+
+// Field is a labelled detail printed above the table, e.g. the order number
+type Field struct {
+	Label string
+	Value string
+}
+
+// Table is the body of a document. Footer, when set, is printed below the rows
+// and has a cell per column.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+	Footer  []string
+}
+
+// Render renders the document in the format. JSON is not rendered here; callers
+// serialize their own data for it.
+func Render(doc *Document, format Format) ([]byte, error) {
+	switch format {
+	case FormatHTML:
+		return RenderHTML(doc)
+	case FormatPDF:
+		return RenderPDF(doc), nil
+	default:
+		return nil, fmt.Errorf("unsupported document format: %s", format)
+	}
+}
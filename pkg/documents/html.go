@@ -0,0 +1,74 @@
+package documents
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 12px; margin: 24px; }
+h1 { font-size: 18px; margin: 0 0 12px; }
+h2 { font-size: 14px; margin: 20px 0 8px; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 2px 12px; margin: 0 0 16px; }
+dt { font-weight: bold; }
+dd { margin: 0; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 6px; text-align: left; vertical-align: top; }
+tfoot td { font-weight: bold; border-bottom: none; }
+p { margin: 12px 0 0; white-space: pre-wrap; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- template "details" .Details}}
+{{- template "table" .Table}}
+{{- range .Sections}}
+<h2>{{.Title}}</h2>
+{{- template "details" .Details}}
+{{- template "table" .Table}}
+{{- end}}
+{{- range .Notes}}
+<p>{{.}}</p>
+{{- end}}
+</body>
+</html>
+{{- define "details"}}
+{{- if .}}
+<dl>
+{{- range .}}
+<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+</dl>
+{{- end}}
+{{- end}}
+{{- define "table"}}
+{{- if .Columns}}
+<table>
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+{{- if .Footer}}
+<tfoot><tr>{{range .Footer}}<td>{{.}}</td>{{end}}</tr></tfoot>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+`))
+
+// RenderHTML renders the document as a standalone HTML page, ready to print
+func RenderHTML(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,283 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Documents are laid out in Courier on US Letter pages, so table columns line up
+// by character count without font metrics. Courier glyphs are 0.6 em wide.
+const (
+	pageWidth      = 612.0
+	pageHeight     = 792.0
+	pageMargin     = 48.0
+	fontSize       = 9.0
+	titleFontSize  = 14.0
+	lineHeight     = 12.0
+	columnGap      = 2
+	minColumnWidth = 6
+
+	// lineChars is how many characters fit on a line between the margins
+	lineChars = 95
+	// pageLines is how many lines fit on a page above the page number
+	pageLines = 55
+)
+
+// pdfLine is a line of text on a page
+type pdfLine struct {
+	text  string
+	bold  bool
+	title bool
+}
+
+// pdfLayout breaks a document into pages of lines
+type pdfLayout struct {
+	pages  [][]pdfLine
+	header []pdfLine // repeated at the top of each page the table continues on
+}
+
+// RenderPDF renders the document as a PDF. Long cells wrap within their column, the
+// table header is repeated on every page the table continues on, and pages are numbered.
+func RenderPDF(doc *Document) []byte {
+	layout := &pdfLayout{pages: [][]pdfLine{nil}}
+
+	layout.add(pdfLine{text: doc.Title, bold: true, title: true})
+	layout.add(pdfLine{})
+	layout.addDetails(doc.Details)
+	layout.addTable(doc.Table)
+
+	for _, section := range doc.Sections {
+		layout.add(pdfLine{})
+		// Keep a section title with the first lines below it
+		layout.addGroup([]pdfLine{{text: section.Title, bold: true}, {}})
+		layout.addDetails(section.Details)
+		layout.addTable(section.Table)
+	}
+
+	for _, note := range doc.Notes {
+		layout.add(pdfLine{})
+		for _, paragraph := range strings.Split(note, "\n") {
+			for _, text := range wrap(paragraph, lineChars) {
+				layout.add(pdfLine{text: text})
+			}
+		}
+	}
+
+	return writePDF(layout.pages)
+}
+
+// addDetails adds labelled details, one per line, followed by a blank line
+func (l *pdfLayout) addDetails(details []Field) {
+	for _, field := range details {
+		for _, text := range wrap(field.Label+": "+field.Value, lineChars) {
+			l.add(pdfLine{text: text})
+		}
+	}
+	if len(details) > 0 {
+		l.add(pdfLine{})
+	}
+}
+
+// addTable adds a table, unless it has no columns
+func (l *pdfLayout) addTable(table Table) {
+	if len(table.Columns) == 0 {
+		return
+	}
+
+	widths := columnWidths(table)
+	rule := pdfLine{text: strings.Repeat("-", min(tableWidth(widths), lineChars))}
+	header := append(tableRow(table.Columns, widths, true), rule)
+	l.addGroup(header)
+	l.header = header
+	for _, row := range table.Rows {
+		l.addGroup(tableRow(row, widths, false))
+	}
+	l.header = nil
+	if len(table.Footer) > 0 {
+		l.addGroup(append([]pdfLine{rule}, tableRow(table.Footer, widths, true)...))
+	}
+}
+
+// add adds a line to the last page, starting a new page when it is full
+func (l *pdfLayout) add(line pdfLine) {
+	l.addGroup([]pdfLine{line})
+}
+
+// addGroup adds lines that are kept on the same page, such as the wrapped cells of
+// a table row. A new page started within the table opens with the table header.
+func (l *pdfLayout) addGroup(lines []pdfLine) {
+	last := len(l.pages) - 1
+	if len(l.pages[last]) > 0 && len(l.pages[last])+len(lines) > pageLines {
+		l.pages = append(l.pages, append([]pdfLine(nil), l.header...))
+		last++
+	}
+	l.pages[last] = append(l.pages[last], lines...)
+}
+
+// columnWidths sizes each column to its longest cell, then narrows the widest
+// columns until the table fits between the margins
+func columnWidths(table Table) []int {
+	widths := make([]int, len(table.Columns))
+	rows := append([][]string{table.Columns, table.Footer}, table.Rows...)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	for tableWidth(widths) > lineChars {
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+	}
+	return widths
+}
+
+// tableWidth is the width of a table line with columns of the given widths
+func tableWidth(widths []int) int {
+	width := 0
+	for _, w := range widths {
+		width += w
+	}
+	return width + columnGap*max(len(widths)-1, 0)
+}
+
+// tableRow lays out a row of cells, wrapping each within its column
+func tableRow(cells []string, widths []int, bold bool) []pdfLine {
+	wrapped := make([][]string, len(widths))
+	height := 1
+	for i := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		wrapped[i] = wrap(cell, widths[i])
+		height = max(height, len(wrapped[i]))
+	}
+
+	lines := make([]pdfLine, height)
+	gap := strings.Repeat(" ", columnGap)
+	for n := range lines {
+		parts := make([]string, len(widths))
+		for i, width := range widths {
+			text := ""
+			if n < len(wrapped[i]) {
+				text = wrapped[i][n]
+			}
+			parts[i] = text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+		}
+		lines[n] = pdfLine{text: strings.TrimRight(strings.Join(parts, gap), " "), bold: bold}
+	}
+	return lines
+}
+
+// wrap breaks text into lines of at most width characters, at spaces where it can
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > width {
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		last := len(lines) - 1
+		if last >= 0 && utf8.RuneCountInString(lines[last])+1+utf8.RuneCountInString(word) <= width {
+			lines[last] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	if len(lines) == 0 {
+		return []string{""}
+	}
+	return lines
+}
+
+// writePDF writes the pages as a PDF document using the standard Courier fonts
+func writePDF(pages [][]pdfLine) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1 to 4 are the catalog, the page tree and the fonts; each page is
+	// followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content strings.Builder
+		y := pageHeight - pageMargin - titleFontSize
+		for _, line := range lines {
+			font, size := "/F1", fontSize
+			if line.bold {
+				font = "/F2"
+			}
+			if line.title {
+				size = titleFontSize
+			}
+			if line.text != "" {
+				fmt.Fprintf(&content, "BT %s %g Tf %g %g Td (%s) Tj ET\n", font, size, pageMargin, y, pdfString(line.text))
+			}
+			y -= lineHeight
+		}
+		fmt.Fprintf(&content, "BT /F1 %g Tf %g %g Td (%s) Tj ET\n", fontSize, pageMargin, pageMargin,
+			pdfString(fmt.Sprintf("Page %d of %d", i+1, len(pages))))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfString escapes text for a PDF string literal. The standard fonts only cover
+// Latin-1, so other characters print as a question mark.
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -33,6 +33,15 @@ const (
 	// ErrorTypeRateLimit Rate limiting errors
 	ErrorTypeRateLimit ErrorType = "RATE_LIMIT_EXCEEDED"
 
+	// ErrorTypeOrderItemLimit Order limit errors
+	ErrorTypeOrderItemLimit     ErrorType = "ORDER_ITEM_LIMIT_EXCEEDED"
+	ErrorTypeOrderQuantityLimit ErrorType = "ORDER_QUANTITY_LIMIT_EXCEEDED"
+	ErrorTypeOrderRateLimit     ErrorType = "ORDER_RATE_LIMIT_EXCEEDED"
+	ErrorTypePurchaseLimit      ErrorType = "PURCHASE_LIMIT_EXCEEDED"
+
+	// ErrorTypeAllocationQueued Stock allocation queue outcomes
+	ErrorTypeAllocationQueued ErrorType = "ALLOCATION_QUEUED"
+
 	// ErrorTypeConcurrency Concurrency-related errors
 	ErrorTypeConcurrencyConflict      ErrorType = "CONCURRENCY_CONFLICT"
 	ErrorTypeOptimisticLockFailed     ErrorType = "OPTIMISTIC_LOCK_FAILED"
@@ -40,6 +49,32 @@ const (
 	ErrorTypeLockTimeout              ErrorType = "LOCK_TIMEOUT"
 )
 
+// AllErrorTypes lists every error type, e.g. to check message catalogs cover them
+var AllErrorTypes = []ErrorType{
+	ErrorTypeValidation,
+	ErrorTypeNotFound,
+	ErrorTypeConflict,
+	ErrorTypeUnauthorized,
+	ErrorTypeForbidden,
+	ErrorTypeBusiness,
+	ErrorTypeInsufficientStock,
+	ErrorTypeInvalidTransition,
+	ErrorTypePaymentFailed,
+	ErrorTypeDatabase,
+	ErrorTypeExternal,
+	ErrorTypeInternal,
+	ErrorTypeRateLimit,
+	ErrorTypeOrderItemLimit,
+	ErrorTypeOrderQuantityLimit,
+	ErrorTypeOrderRateLimit,
+	ErrorTypePurchaseLimit,
+	ErrorTypeAllocationQueued,
+	ErrorTypeConcurrencyConflict,
+	ErrorTypeOptimisticLockFailed,
+	ErrorTypeStockReservationConflict,
+	ErrorTypeLockTimeout,
+}
+
 // AppError represents a structured application error
 type AppError struct {
 	Type       ErrorType              `json:"type"`
@@ -192,6 +227,64 @@ func NewRateLimitError(limit int, window string) *AppError {
 	return err
 }
 
+// NewOrderItemLimitError Order Limit Errors
+func NewOrderItemLimitError(items, limit int) *AppError {
+	err := NewAppError(ErrorTypeOrderItemLimit,
+		fmt.Sprintf("order has %d different products, more than the limit of %d", items, limit), http.StatusBadRequest)
+	err.WithContext("items", items)
+	err.WithContext("limit", limit)
+	return err
+}
+
+func NewOrderQuantityLimitError(productID string, quantity, limit int) *AppError {
+	err := NewAppError(ErrorTypeOrderQuantityLimit,
+		fmt.Sprintf("order has %d units of product %s, more than the limit of %d", quantity, productID, limit), http.StatusBadRequest)
+	err.WithContext("product_id", productID)
+	err.WithContext("quantity", quantity)
+	err.WithContext("limit", limit)
+	return err
+}
+
+func NewOrderRateLimitError(limit int, window string) *AppError {
+	err := NewAppError(ErrorTypeOrderRateLimit,
+		fmt.Sprintf("at most %d orders may be placed per %s", limit, window), http.StatusTooManyRequests)
+	err.WithContext("limit", limit)
+	err.WithContext("window", window)
+	return err
+}
+
+// NewPurchaseLimitError is returned when an order would take a customer past the units
+// of a product they may buy; periodDays is 0 for a lifetime limit
+func NewPurchaseLimitError(productID string, requested, purchased, limit, periodDays int) *AppError {
+	period := "in total"
+	if periodDays > 0 {
+		period = fmt.Sprintf("per %d days", periodDays)
+	}
+	remaining := limit - purchased
+	if remaining < 0 {
+		remaining = 0
+	}
+	err := NewAppError(ErrorTypePurchaseLimit,
+		fmt.Sprintf("product %s is limited to %d units per customer %s; %d already purchased, %d requested",
+			productID, limit, period, purchased, requested), http.StatusBadRequest)
+	err.WithContext("product_id", productID)
+	err.WithContext("requested", requested)
+	err.WithContext("purchased", purchased)
+	err.WithContext("remaining", remaining)
+	err.WithContext("limit", limit)
+	err.WithContext("period_days", periodDays)
+	return err
+}
+
+// NewAllocationQueuedError Stock Allocation Queue Errors
+func NewAllocationQueuedError(ticketID string, position int) *AppError {
+	err := NewAppError(ErrorTypeAllocationQueued,
+		fmt.Sprintf("order is queued for stock at position %d; retry with queue ticket %s", position, ticketID), http.StatusAccepted)
+	err.WithContext("ticket_id", ticketID)
+	err.WithContext("position", position)
+	return err
+}
+
 // Helper functions for common error patterns
 
 // WrapDatabaseError wraps a database error with context
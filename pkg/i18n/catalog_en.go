@@ -0,0 +1,52 @@
+package i18n
+
+// catalogEN holds the English messages, the fallback for every other locale
+var catalogEN = Catalog{
+	// Error responses, keyed by error type
+	"error.VALIDATION_ERROR":           "The request is invalid.",
+	"error.NOT_FOUND":                  "The requested resource was not found.",
+	"error.CONFLICT":                   "The request conflicts with the current state of the resource.",
+	"error.UNAUTHORIZED":               "Authentication is required.",
+	"error.FORBIDDEN":                  "You do not have permission to perform this action.",
+	"error.BUSINESS_ERROR":             "The request cannot be completed.",
+	"error.INSUFFICIENT_STOCK":         "There is not enough stock available.",
+	"error.INVALID_TRANSITION":         "The order cannot move to the requested status.",
+	"error.PAYMENT_FAILED":             "The payment could not be processed.",
+	"error.DATABASE_ERROR":             "A storage error occurred. Please try again later.",
+	"error.EXTERNAL_SERVICE_ERROR":     "An external service is unavailable. Please try again later.",
+	"error.INTERNAL_ERROR":             "An unexpected error occurred.",
+	"error.RATE_LIMIT_EXCEEDED":        "Too many requests. Please slow down.",
+	"error.CONCURRENCY_CONFLICT":       "The resource was changed by another request. Please retry.",
+	"error.OPTIMISTIC_LOCK_FAILED":     "The resource was updated by another request. Please retry.",
+	"error.STOCK_RESERVATION_CONFLICT": "Another order reserved this stock at the same time. Please retry your order.",
+	"error.LOCK_TIMEOUT":               "The system is busy. Please try again in a moment.",
+
+	// Order limit errors, keyed by error type
+	"error.ORDER_ITEM_LIMIT_EXCEEDED":     "The order has more different products than allowed.",
+	"error.ORDER_QUANTITY_LIMIT_EXCEEDED": "The order has more units of a product than allowed.",
+	"error.ORDER_RATE_LIMIT_EXCEEDED":     "Too many orders were placed recently. Please try again later.",
+	"error.PURCHASE_LIMIT_EXCEEDED":       "You have reached the purchase limit for this product.",
+
+	// Stock allocation queue outcomes, keyed by error type
+	"error.ALLOCATION_QUEUED": "Your order is waiting in line for stock. Please retry with your queue ticket.",
+
+	// Order status notifications
+	"notification.order_confirmed.title": "Order confirmed",
+	"notification.order_confirmed.body":  "Your order {order_id} has been confirmed.",
+	"notification.order_shipped.title":   "Order shipped",
+	"notification.order_shipped.body":    "Your order {order_id} is on its way.",
+	"notification.order_delivered.title": "Order delivered",
+	"notification.order_delivered.body":  "Your order {order_id} has been delivered.",
+	"notification.order_cancelled.title": "Order cancelled",
+	"notification.order_cancelled.body":  "Your order {order_id} has been cancelled.",
+
+	// Cancellations and refunds
+	"notification.order_items_cancelled.title": "Items cancelled",
+	"notification.order_items_cancelled.body":  "Some items of your order {order_id} have been cancelled.",
+	"notification.order_refunded.title":        "Refund issued",
+	"notification.order_refunded.body":         "A refund of {amount} for your order {order_id} is on its way.",
+
+	// Gift orders
+	"notification.order_shipped.gift_body":   "Your gift order {order_id} is on its way to its recipient.",
+	"notification.order_delivered.gift_body": "Your gift order {order_id} has been delivered.",
+}
@@ -0,0 +1,52 @@
+package i18n
+
+// catalogES holds the Spanish messages
+var catalogES = Catalog{
+	// Error responses, keyed by error type
+	"error.VALIDATION_ERROR":           "La solicitud no es válida.",
+	"error.NOT_FOUND":                  "No se encontró el recurso solicitado.",
+	"error.CONFLICT":                   "La solicitud entra en conflicto con el estado actual del recurso.",
+	"error.UNAUTHORIZED":               "Se requiere autenticación.",
+	"error.FORBIDDEN":                  "No tiene permiso para realizar esta acción.",
+	"error.BUSINESS_ERROR":             "No se puede completar la solicitud.",
+	"error.INSUFFICIENT_STOCK":         "No hay suficiente stock disponible.",
+	"error.INVALID_TRANSITION":         "El pedido no puede pasar al estado solicitado.",
+	"error.PAYMENT_FAILED":             "No se pudo procesar el pago.",
+	"error.DATABASE_ERROR":             "Se produjo un error de almacenamiento. Inténtelo de nuevo más tarde.",
+	"error.EXTERNAL_SERVICE_ERROR":     "Un servicio externo no está disponible. Inténtelo de nuevo más tarde.",
+	"error.INTERNAL_ERROR":             "Se produjo un error inesperado.",
+	"error.RATE_LIMIT_EXCEEDED":        "Demasiadas solicitudes. Por favor, reduzca la frecuencia.",
+	"error.CONCURRENCY_CONFLICT":       "Otra solicitud modificó el recurso. Vuelva a intentarlo.",
+	"error.OPTIMISTIC_LOCK_FAILED":     "Otra solicitud actualizó el recurso. Vuelva a intentarlo.",
+	"error.STOCK_RESERVATION_CONFLICT": "Otro pedido reservó este stock al mismo tiempo. Vuelva a intentar su pedido.",
+	"error.LOCK_TIMEOUT":               "El sistema está ocupado. Inténtelo de nuevo en un momento.",
+
+	// Order limit errors, keyed by error type
+	"error.ORDER_ITEM_LIMIT_EXCEEDED":     "El pedido contiene más productos distintos de los permitidos.",
+	"error.ORDER_QUANTITY_LIMIT_EXCEEDED": "El pedido contiene más unidades de un producto de las permitidas.",
+	"error.ORDER_RATE_LIMIT_EXCEEDED":     "Se han realizado demasiados pedidos recientemente. Inténtelo de nuevo más tarde.",
+	"error.PURCHASE_LIMIT_EXCEEDED":       "Ha alcanzado el límite de compra de este producto.",
+
+	// Stock allocation queue outcomes, keyed by error type
+	"error.ALLOCATION_QUEUED": "Su pedido está en la cola de asignación de stock. Vuelva a intentarlo con su ticket de cola.",
+
+	// Order status notifications
+	"notification.order_confirmed.title": "Pedido confirmado",
+	"notification.order_confirmed.body":  "Su pedido {order_id} ha sido confirmado.",
+	"notification.order_shipped.title":   "Pedido enviado",
+	"notification.order_shipped.body":    "Su pedido {order_id} está en camino.",
+	"notification.order_delivered.title": "Pedido entregado",
+	"notification.order_delivered.body":  "Su pedido {order_id} ha sido entregado.",
+	"notification.order_cancelled.title": "Pedido cancelado",
+	"notification.order_cancelled.body":  "Su pedido {order_id} ha sido cancelado.",
+
+	// Cancellations and refunds
+	"notification.order_items_cancelled.title": "Artículos cancelados",
+	"notification.order_items_cancelled.body":  "Algunos artículos de su pedido {order_id} han sido cancelados.",
+	"notification.order_refunded.title":        "Reembolso emitido",
+	"notification.order_refunded.body":         "Un reembolso de {amount} por su pedido {order_id} está en camino.",
+
+	// Gift orders
+	"notification.order_shipped.gift_body":   "Su pedido de regalo {order_id} está en camino hacia su destinatario.",
+	"notification.order_delivered.gift_body": "Su pedido de regalo {order_id} ha sido entregado.",
+}
@@ -0,0 +1,52 @@
+package i18n
+
+// catalogFR holds the French messages
+var catalogFR = Catalog{
+	// Error responses, keyed by error type
+	"error.VALIDATION_ERROR":           "La requête n'est pas valide.",
+	"error.NOT_FOUND":                  "La ressource demandée est introuvable.",
+	"error.CONFLICT":                   "La requête est en conflit avec l'état actuel de la ressource.",
+	"error.UNAUTHORIZED":               "Une authentification est requise.",
+	"error.FORBIDDEN":                  "Vous n'êtes pas autorisé à effectuer cette action.",
+	"error.BUSINESS_ERROR":             "La requête ne peut pas aboutir.",
+	"error.INSUFFICIENT_STOCK":         "Le stock disponible est insuffisant.",
+	"error.INVALID_TRANSITION":         "La commande ne peut pas passer au statut demandé.",
+	"error.PAYMENT_FAILED":             "Le paiement n'a pas pu être traité.",
+	"error.DATABASE_ERROR":             "Une erreur de stockage est survenue. Veuillez réessayer plus tard.",
+	"error.EXTERNAL_SERVICE_ERROR":     "Un service externe est indisponible. Veuillez réessayer plus tard.",
+	"error.INTERNAL_ERROR":             "Une erreur inattendue est survenue.",
+	"error.RATE_LIMIT_EXCEEDED":        "Trop de requêtes. Veuillez ralentir.",
+	"error.CONCURRENCY_CONFLICT":       "La ressource a été modifiée par une autre requête. Veuillez réessayer.",
+	"error.OPTIMISTIC_LOCK_FAILED":     "La ressource a été mise à jour par une autre requête. Veuillez réessayer.",
+	"error.STOCK_RESERVATION_CONFLICT": "Une autre commande a réservé ce stock au même moment. Veuillez renouveler votre commande.",
+	"error.LOCK_TIMEOUT":               "Le système est occupé. Veuillez réessayer dans un instant.",
+
+	// Order limit errors, keyed by error type
+	"error.ORDER_ITEM_LIMIT_EXCEEDED":     "La commande contient plus de produits différents que le nombre autorisé.",
+	"error.ORDER_QUANTITY_LIMIT_EXCEEDED": "La commande contient plus d'unités d'un produit que le nombre autorisé.",
+	"error.ORDER_RATE_LIMIT_EXCEEDED":     "Trop de commandes ont été passées récemment. Veuillez réessayer plus tard.",
+	"error.PURCHASE_LIMIT_EXCEEDED":       "Vous avez atteint la limite d'achat pour ce produit.",
+
+	// Stock allocation queue outcomes, keyed by error type
+	"error.ALLOCATION_QUEUED": "Votre commande est en file d'attente pour le stock. Veuillez réessayer avec votre ticket de file d'attente.",
+
+	// Order status notifications
+	"notification.order_confirmed.title": "Commande confirmée",
+	"notification.order_confirmed.body":  "Votre commande {order_id} a été confirmée.",
+	"notification.order_shipped.title":   "Commande expédiée",
+	"notification.order_shipped.body":    "Votre commande {order_id} est en route.",
+	"notification.order_delivered.title": "Commande livrée",
+	"notification.order_delivered.body":  "Votre commande {order_id} a été livrée.",
+	"notification.order_cancelled.title": "Commande annulée",
+	"notification.order_cancelled.body":  "Votre commande {order_id} a été annulée.",
+
+	// Cancellations and refunds
+	"notification.order_items_cancelled.title": "Articles annulés",
+	"notification.order_items_cancelled.body":  "Certains articles de votre commande {order_id} ont été annulés.",
+	"notification.order_refunded.title":        "Remboursement émis",
+	"notification.order_refunded.body":         "Un remboursement de {amount} pour votre commande {order_id} est en cours.",
+
+	// Gift orders
+	"notification.order_shipped.gift_body":   "Votre commande cadeau {order_id} est en route vers son destinataire.",
+	"notification.order_delivered.gift_body": "Votre commande cadeau {order_id} a été livrée.",
+}
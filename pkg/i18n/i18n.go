@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when a request names no supported locale and for
+// messages missing from the requested locale's catalog
+const DefaultLocale = "en"
+
+// Catalog maps message keys to the text for one locale. Text may contain
+// {name} placeholders filled from the params passed to Translate.
+type Catalog map[string]string
+
+// Translator resolves messages from per-locale catalogs
+type Translator struct {
+	catalogs map[string]Catalog
+}
+
+// NewTranslator creates a translator with the built-in message catalogs
+func NewTranslator() *Translator {
+	return NewTranslatorWithCatalogs(map[string]Catalog{
+		"en": catalogEN,
+		"es": catalogES,
+		"fr": catalogFR,
+	})
+}
+
+// NewTranslatorWithCatalogs creates a translator from catalogs keyed by locale
+func NewTranslatorWithCatalogs(catalogs map[string]Catalog) *Translator {
+	normalized := make(map[string]Catalog, len(catalogs))
+	for locale, catalog := range catalogs {
+		normalized[normalizeLocale(locale)] = catalog
+	}
+	return &Translator{catalogs: normalized}
+}
+
+// Locales returns the supported locales in sorted order
+func (t *Translator) Locales() []string {
+	locales := make([]string, 0, len(t.catalogs))
+	for locale := range t.catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Catalog returns the messages of a locale, or nil if it is not supported
+func (t *Translator) Catalog(locale string) Catalog {
+	return t.catalogs[normalizeLocale(locale)]
+}
+
+// HasMessage reports whether the locale's own catalog defines key
+func (t *Translator) HasMessage(locale, key string) bool {
+	_, ok := t.catalogs[normalizeLocale(locale)][key]
+	return ok
+}
+
+// Translate returns the message for key in locale, falling back to
+// DefaultLocale and then to the key itself
+func (t *Translator) Translate(locale, key string, params map[string]string) string {
+	message, ok := t.catalogs[normalizeLocale(locale)][key]
+	if !ok {
+		message, ok = t.catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", value)
+	}
+	return message
+}
+
+// Match picks the supported locale that best satisfies an Accept-Language
+// header, e.g. "fr-CA,fr;q=0.9,en;q=0.8". A regional tag matches its base
+// language when the region itself is not supported.
+func (t *Translator) Match(acceptLanguage string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if _, ok := t.catalogs[tag]; ok {
+			return tag
+		}
+		if base, _, found := strings.Cut(tag, "-"); found {
+			if _, ok := t.catalogs[base]; ok {
+				return base
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// weightedTag is one language range of an Accept-Language header
+type weightedTag struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage returns the header's language tags by descending quality,
+// dropping wildcards and tags with zero quality
+func parseAcceptLanguage(header string) []string {
+	var weighted []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalizeLocale(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		weighted = append(weighted, weightedTag{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].quality > weighted[j].quality
+	})
+
+	tags := make([]string, len(weighted))
+	for i, w := range weighted {
+		tags[i] = w.tag
+	}
+	return tags
+}
+
+// normalizeLocale lowercases a tag and uses "-" as the subtag separator
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// localeKey is the context key holding the request locale
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext returns the locale stored by WithLocale, or DefaultLocale
+func LocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// ErrorKey returns the catalog key of an error type's message
+func ErrorKey(errorType string) string {
+	return "error." + errorType
+}
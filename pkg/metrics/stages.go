@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the number of recent executions per stage that latency
+// percentiles are computed over
+const DefaultWindow = 1000
+
+// LatencyBucketsMs are the upper bounds of the cumulative latency histogram; the
+// last bucket counts every execution
+var LatencyBucketsMs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, math.Inf(1)}
+
+// StageSummary reports the executions of one stage. Percentiles cover the recent
+// window; counters and buckets cover every execution since startup.
+type StageSummary struct {
+	Stage      string          `json:"stage"`
+	Executions int64           `json:"executions"`
+	Successes  int64           `json:"successes"`
+	Failures   int64           `json:"failures"`
+	Rollbacks  int64           `json:"rollbacks"`
+	Recent     int             `json:"recent"`
+	MeanMs     float64         `json:"mean_ms"`
+	P50Ms      float64         `json:"p50_ms"`
+	P95Ms      float64         `json:"p95_ms"`
+	P99Ms      float64         `json:"p99_ms"`
+	MaxMs      float64         `json:"max_ms"`
+	Buckets    []LatencyBucket `json:"buckets"`
+}
+
+// LatencyBucket is the number of executions that took at most LeMs milliseconds;
+// LeMs is null for the last bucket
+type LatencyBucket struct {
+	LeMs  *float64 `json:"le_ms"`
+	Count int64    `json:"count"`
+}
+
+// stageMetrics accumulates the executions of one stage
+type stageMetrics struct {
+	successes int64
+	failures  int64
+	rollbacks int64
+	buckets   []int64
+	recent    []time.Duration // ring buffer of the last window latencies
+	next      int
+}
+
+// StageRecorder records the latency and outcome of named stages of multi-step
+// operations, such as order placement, to find where time is spent. A nil
+// recorder records nothing.
+type StageRecorder struct {
+	window int
+
+	mutex  sync.Mutex
+	stages map[string]*stageMetrics
+}
+
+// NewStageRecorder creates a recorder keeping the latencies of the last window
+// executions of each stage
+func NewStageRecorder(window int) *StageRecorder {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	return &StageRecorder{
+		window: window,
+		stages: make(map[string]*stageMetrics),
+	}
+}
+
+// Observe records one execution of a stage
+func (r *StageRecorder) Observe(stage string, elapsed time.Duration, failed bool) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	metrics := r.stageLocked(stage)
+	if failed {
+		metrics.failures++
+	} else {
+		metrics.successes++
+	}
+
+	ms := float64(elapsed) / float64(time.Millisecond)
+	for i, bound := range LatencyBucketsMs {
+		if ms <= bound {
+			metrics.buckets[i]++
+		}
+	}
+
+	if len(metrics.recent) < r.window {
+		metrics.recent = append(metrics.recent, elapsed)
+	} else {
+		metrics.recent[metrics.next] = elapsed
+	}
+	metrics.next = (metrics.next + 1) % r.window
+}
+
+// Rollback records that the work of an operation was undone after the stage failed
+func (r *StageRecorder) Rollback(stage string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stageLocked(stage).rollbacks++
+}
+
+// Summary returns every stage recorded so far, slowest recent p95 first
+func (r *StageRecorder) Summary() []StageSummary {
+	if r == nil {
+		return []StageSummary{}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summaries := make([]StageSummary, 0, len(r.stages))
+	for stage, metrics := range r.stages {
+		summary := StageSummary{
+			Stage:      stage,
+			Executions: metrics.successes + metrics.failures,
+			Successes:  metrics.successes,
+			Failures:   metrics.failures,
+			Rollbacks:  metrics.rollbacks,
+			Recent:     len(metrics.recent),
+			Buckets:    make([]LatencyBucket, len(LatencyBucketsMs)),
+		}
+		for i, bound := range LatencyBucketsMs {
+			summary.Buckets[i].Count = metrics.buckets[i]
+			if !math.IsInf(bound, 1) {
+				le := bound
+				summary.Buckets[i].LeMs = &le
+			}
+		}
+
+		if len(metrics.recent) > 0 {
+			latencies := make([]time.Duration, len(metrics.recent))
+			copy(latencies, metrics.recent)
+			sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+			var total time.Duration
+			for _, latency := range latencies {
+				total += latency
+			}
+			summary.MeanMs = milliseconds(total / time.Duration(len(latencies)))
+			summary.P50Ms = milliseconds(percentile(latencies, 50))
+			summary.P95Ms = milliseconds(percentile(latencies, 95))
+			summary.P99Ms = milliseconds(percentile(latencies, 99))
+			summary.MaxMs = milliseconds(latencies[len(latencies)-1])
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].P95Ms != summaries[j].P95Ms {
+			return summaries[i].P95Ms > summaries[j].P95Ms
+		}
+		return summaries[i].Stage < summaries[j].Stage
+	})
+	return summaries
+}
+
+// Start begins timing an execution of consecutive stages
+func (r *StageRecorder) Start() *Run {
+	if r == nil {
+		return nil
+	}
+	return &Run{recorder: r}
+}
+
+// stageLocked returns the metrics of a stage; the caller holds the lock
+func (r *StageRecorder) stageLocked(stage string) *stageMetrics {
+	metrics, ok := r.stages[stage]
+	if !ok {
+		metrics = &stageMetrics{buckets: make([]int64, len(LatencyBucketsMs))}
+		r.stages[stage] = metrics
+	}
+	return metrics
+}
+
+// Run times one execution of consecutive stages. Each stage lasts until the next
+// one starts or the run ends, so callers only mark where stages begin. A nil run
+// records nothing.
+type Run struct {
+	recorder *StageRecorder
+	stage    string
+	started  time.Time
+}
+
+// Stage ends the current stage as successful and starts the named one
+func (r *Run) Stage(stage string) {
+	if r == nil {
+		return
+	}
+	r.end(false)
+	r.stage = stage
+	r.started = time.Now()
+}
+
+// Succeed ends the current stage as successful
+func (r *Run) Succeed() {
+	if r == nil {
+		return
+	}
+	r.end(false)
+}
+
+// Fail ends the current stage as failed, counting a rollback for it when the
+// work of the earlier stages was undone
+func (r *Run) Fail(rolledBack bool) {
+	if r == nil {
+		return
+	}
+	stage := r.stage
+	r.end(true)
+	if rolledBack && stage != "" {
+		r.recorder.Rollback(stage)
+	}
+}
+
+// end records the current stage, if any
+func (r *Run) end(failed bool) {
+	if r.stage == "" {
+		return
+	}
+	r.recorder.Observe(r.stage, time.Since(r.started), failed)
+	r.stage = ""
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// milliseconds converts a duration to milliseconds, rounded to microseconds
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
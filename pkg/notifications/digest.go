@@ -0,0 +1,298 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// DigestUrgency classifies how quickly a notification type must reach the user
+type DigestUrgency string
+
+const (
+	UrgencyImmediate DigestUrgency = "immediate"
+	UrgencyHourly    DigestUrgency = "hourly"
+	UrgencyDaily     DigestUrgency = "daily"
+)
+
+// DigestPolicy maps notification types to their urgency
+type DigestPolicy map[NotificationType]DigestUrgency
+
+// DefaultDigestPolicy batches informational types and delivers everything else immediately
+func DefaultDigestPolicy() DigestPolicy {
+	return DigestPolicy{
+		NotificationTypeLowStock:        UrgencyHourly,
+		NotificationTypeReportCompleted: UrgencyHourly,
+		NotificationTypePromotion:       UrgencyDaily,
+	}
+}
+
+// Classify returns the urgency for a notification. High and critical priority
+// notifications always bypass digests regardless of their type.
+func (p DigestPolicy) Classify(notification *Notification) DigestUrgency {
+	if notification.Priority >= PriorityHigh {
+		return UrgencyImmediate
+	}
+	if urgency, ok := p[notification.Type]; ok {
+		return urgency
+	}
+	return UrgencyImmediate
+}
+
+// DigestConfig configures the digest scheduler
+type DigestConfig struct {
+	Policy            DigestPolicy  `json:"policy"`
+	CheckInterval     time.Duration `json:"check_interval"`
+	MaxItemsPerDigest int           `json:"max_items_per_digest"`
+}
+
+// DefaultDigestConfig returns default configuration
+func DefaultDigestConfig() *DigestConfig {
+	return &DigestConfig{
+		Policy:            DefaultDigestPolicy(),
+		CheckInterval:     time.Minute,
+		MaxItemsPerDigest: 50,
+	}
+}
+
+// DigestSink delivers what a digest scheduler passes on: urgent notifications as they
+// are submitted and the digests of the rest. NotificationDispatcher is a DigestSink.
+type DigestSink interface {
+	Dispatch(notification *Notification) error
+}
+
+// digestKey groups buffered notifications per recipient, channel and window
+type digestKey struct {
+	Recipient string
+	Channel   string
+	Urgency   DigestUrgency
+}
+
+// digestBucket holds notifications waiting for their window to close
+type digestBucket struct {
+	items     []*Notification
+	windowEnd time.Time
+}
+
+// DigestScheduler buffers non-urgent notifications and dispatches them as
+// hourly or daily summaries per recipient and channel
+type DigestScheduler struct {
+	config *DigestConfig
+	sink   DigestSink
+	logger *logger.Logger
+
+	mutex   sync.Mutex
+	buckets map[digestKey]*digestBucket
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  int32 // atomic
+}
+
+// NewDigestScheduler creates a new digest scheduler
+func NewDigestScheduler(config *DigestConfig, sink DigestSink, logger *logger.Logger) *DigestScheduler {
+	if config == nil {
+		config = DefaultDigestConfig()
+	}
+	if config.Policy == nil {
+		config.Policy = DefaultDigestPolicy()
+	}
+
+	return &DigestScheduler{
+		config:   config,
+		sink:     sink,
+		logger:   logger,
+		buckets:  make(map[digestKey]*digestBucket),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins periodically flushing digests whose window has closed
+func (ds *DigestScheduler) Start(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&ds.running, 0, 1) {
+		return fmt.Errorf("digest scheduler is already running")
+	}
+
+	ds.logger.Info("Starting notification digest scheduler", "check_interval", ds.config.CheckInterval)
+
+	ds.wg.Add(1)
+	go func() {
+		defer ds.wg.Done()
+
+		ticker := time.NewTicker(ds.config.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ds.stopChan:
+				return
+			case now := <-ticker.C:
+				ds.FlushDue(now)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the scheduler and delivers everything still buffered
+func (ds *DigestScheduler) Stop() error {
+	if !atomic.CompareAndSwapInt32(&ds.running, 1, 0) {
+		return fmt.Errorf("digest scheduler is not running")
+	}
+
+	close(ds.stopChan)
+	ds.wg.Wait()
+
+	// Don't drop pending summaries on shutdown
+	ds.flush(func(digestKey, *digestBucket) bool { return true })
+
+	ds.logger.Info("Notification digest scheduler stopped")
+	return nil
+}
+
+// Submit dispatches urgent notifications immediately and buffers the rest. While the
+// scheduler is not running nothing is buffered, so notifications submitted after Stop
+// are not left waiting for a flush that never comes.
+func (ds *DigestScheduler) Submit(notification *Notification) error {
+	urgency := ds.config.Policy.Classify(notification)
+	if urgency == UrgencyImmediate {
+		return ds.sink.Dispatch(notification)
+	}
+
+	key := digestKey{
+		Recipient: notification.Recipient,
+		Channel:   notification.Channel,
+		Urgency:   urgency,
+	}
+
+	// Checked under the lock, so Stop's final flush sees whatever was buffered before it
+	ds.mutex.Lock()
+	if atomic.LoadInt32(&ds.running) == 0 {
+		ds.mutex.Unlock()
+		return ds.sink.Dispatch(notification)
+	}
+	bucket, exists := ds.buckets[key]
+	if !exists {
+		bucket = &digestBucket{windowEnd: digestWindowEnd(urgency, time.Now())}
+		ds.buckets[key] = bucket
+	}
+	bucket.items = append(bucket.items, notification)
+	full := len(bucket.items) >= ds.config.MaxItemsPerDigest
+	ds.mutex.Unlock()
+
+	ds.logger.Debug("Notification buffered for digest",
+		"id", notification.ID,
+		"type", notification.Type,
+		"recipient", notification.Recipient,
+		"urgency", urgency)
+
+	// Oversized digests are sent early rather than growing without bound
+	if full {
+		ds.flush(func(k digestKey, _ *digestBucket) bool { return k == key })
+	}
+
+	return nil
+}
+
+// FlushDue dispatches every digest whose window ended at or before now
+func (ds *DigestScheduler) FlushDue(now time.Time) {
+	ds.flush(func(_ digestKey, bucket *digestBucket) bool {
+		return !bucket.windowEnd.After(now)
+	})
+}
+
+// GetPendingCount returns the number of buffered notifications
+func (ds *DigestScheduler) GetPendingCount() int {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	count := 0
+	for _, bucket := range ds.buckets {
+		count += len(bucket.items)
+	}
+	return count
+}
+
+// flush removes the selected buckets and dispatches one digest for each
+func (ds *DigestScheduler) flush(selectFn func(digestKey, *digestBucket) bool) {
+	ds.mutex.Lock()
+	ready := make(map[digestKey]*digestBucket)
+	for key, bucket := range ds.buckets {
+		if selectFn(key, bucket) {
+			ready[key] = bucket
+			delete(ds.buckets, key)
+		}
+	}
+	ds.mutex.Unlock()
+
+	for key, bucket := range ready {
+		digest := buildDigest(key, bucket.items)
+		if err := ds.sink.Dispatch(digest); err != nil {
+			ds.logger.Error("Failed to dispatch notification digest",
+				"error", err,
+				"recipient", key.Recipient,
+				"channel", key.Channel,
+				"items", len(bucket.items))
+			continue
+		}
+
+		ds.logger.Info("Notification digest dispatched",
+			"recipient", key.Recipient,
+			"channel", key.Channel,
+			"urgency", key.Urgency,
+			"items", len(bucket.items))
+	}
+}
+
+// buildDigest summarizes buffered notifications into a single notification
+func buildDigest(key digestKey, items []*Notification) *Notification {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	digest := NewNotification(NotificationTypeDigest, key.Channel, key.Recipient)
+	digest.SetPriority(PriorityLow)
+	digest.Subject = fmt.Sprintf("Your %s summary (%d updates)", key.Urgency, len(items))
+
+	countsByType := make(map[NotificationType]int)
+	ids := make([]string, 0, len(items))
+
+	var body strings.Builder
+	for _, item := range items {
+		countsByType[item.Type]++
+		ids = append(ids, item.ID)
+
+		line := item.Subject
+		if line == "" {
+			line = string(item.Type)
+		}
+		fmt.Fprintf(&body, "- %s\n", line)
+	}
+	digest.Body = body.String()
+
+	digest.Data["notification_ids"] = ids
+	digest.Data["counts_by_type"] = countsByType
+	digest.Metadata["urgency"] = string(key.Urgency)
+
+	return digest
+}
+
+// digestWindowEnd returns when a digest window opened at now should be delivered
+func digestWindowEnd(urgency DigestUrgency, now time.Time) time.Time {
+	now = now.UTC()
+	switch urgency {
+	case UrgencyDaily:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour)
+	default:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+}
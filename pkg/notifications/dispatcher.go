@@ -255,10 +255,13 @@ func (nd *NotificationDispatcher) batchProcessor(ctx context.Context) {
 	}
 }
 
-// retryProcessor handles failed notifications that need to be retried
+// retryProcessor holds failed notifications until their next retry is due and then
+// queues them again. One timer waits for the earliest retry, so no goroutine sleeps
+// per notification.
 func (nd *NotificationDispatcher) retryProcessor(ctx context.Context) {
-	ticker := time.NewTicker(nd.config.RetryCheckInterval)
-	defer ticker.Stop()
+	var waiting []*pendingRetry
+	timer := time.NewTimer(nd.config.RetryCheckInterval)
+	defer timer.Stop()
 
 	for {
 		select {
@@ -266,9 +269,15 @@ func (nd *NotificationDispatcher) retryProcessor(ctx context.Context) {
 			return
 		case <-nd.stopChan:
 			return
-		case <-ticker.C:
-			// Process retry queue is handled by the main queue processor
-		case notification := <-nd.retryQueue:
+		case <-timer.C:
+			var stopped bool
+			if waiting, stopped = nd.requeueDue(ctx, waiting, time.Now()); stopped {
+				return
+			}
+		case notification, ok := <-nd.retryQueue:
+			if !ok {
+				return
+			}
 			if notification.IsExpired() {
 				nd.updateMetrics(func(m *DispatcherMetrics) {
 					m.NotificationsExpired++
@@ -279,23 +288,55 @@ func (nd *NotificationDispatcher) retryProcessor(ctx context.Context) {
 
 			if notification.ShouldRetry() {
 				notification.MarkAsRetrying()
+				waiting = append(waiting, &pendingRetry{notification: notification, dueAt: notification.GetNextRetryTime()})
+			}
+		}
 
-				// Re-queue for retry after delay
-				go func(n *Notification) {
-					time.Sleep(time.Until(n.GetNextRetryTime()))
-
-					select {
-					case nd.notificationQueue <- n:
-						nd.updateMetrics(func(m *DispatcherMetrics) {
-							m.NotificationsRetried++
-						})
-					case <-nd.stopChan:
-						return
-					}
-				}(notification)
+		// Wait for the earliest retry, checking again at the retry interval when none waits
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		wait := nd.config.RetryCheckInterval
+		for _, retry := range waiting {
+			if until := time.Until(retry.dueAt); until < wait {
+				wait = until
 			}
 		}
+		timer.Reset(wait)
+	}
+}
+
+// pendingRetry is a failed notification waiting in the retry processor until dueAt
+type pendingRetry struct {
+	notification *Notification
+	dueAt        time.Time
+}
+
+// requeueDue queues the retries due at now for delivery and returns those still
+// waiting. It reports whether the dispatcher stopped while queueing.
+func (nd *NotificationDispatcher) requeueDue(ctx context.Context, waiting []*pendingRetry, now time.Time) ([]*pendingRetry, bool) {
+	remaining := waiting[:0]
+	for i, retry := range waiting {
+		if retry.dueAt.After(now) {
+			remaining = append(remaining, retry)
+			continue
+		}
+
+		select {
+		case nd.notificationQueue <- retry.notification:
+			nd.updateMetrics(func(m *DispatcherMetrics) {
+				m.NotificationsRetried++
+			})
+		case <-ctx.Done():
+			return append(remaining, waiting[i:]...), true
+		case <-nd.stopChan:
+			return append(remaining, waiting[i:]...), true
+		}
 	}
+	return remaining, false
 }
 
 // addToBatch adds a notification to the appropriate batch
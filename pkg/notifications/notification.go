@@ -21,6 +21,8 @@ const (
 	NotificationTypePasswordReset     NotificationType = "password_reset"
 	NotificationTypePromotion         NotificationType = "promotion"
 	NotificationTypeSystemAlert       NotificationType = "system_alert"
+	NotificationTypeReportCompleted   NotificationType = "report_completed"
+	NotificationTypeDigest            NotificationType = "digest"
 )
 
 // NotificationPriority defines the priority level
@@ -244,12 +244,14 @@ func (dw *DispatchWorker) queueForRetry(notification *Notification) {
 		return
 	}
 
+	// The retry processor owns the notification once queued
+	retryCount := notification.RetryCount
 	select {
 	case dw.dispatcher.retryQueue <- notification:
 		dw.logger.Debug("Notification queued for retry",
 			"worker_id", dw.id,
 			"notification_id", notification.ID,
-			"retry_count", notification.RetryCount)
+			"retry_count", retryCount)
 	default:
 		dw.logger.Error("Retry queue is full, dropping notification",
 			"worker_id", dw.id,
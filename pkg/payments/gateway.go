@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"easy-orders-backend/pkg/logger"
@@ -320,20 +321,36 @@ func (mpg *MockPaymentGateway) simulateFailure(req *GatewayPaymentRequest, proce
 	}, nil
 }
 
-// PaymentGatewayManager manages multiple payment gateways
+// PaymentGatewayManager manages multiple payment gateways and routes payments to them,
+// by its routing rules first and then with its gateway selector
 type PaymentGatewayManager struct {
 	gateways map[PaymentGatewayType]PaymentGateway
+	selector GatewaySelector
 	logger   *logger.Logger
+
+	latencyMutex sync.RWMutex
+	latencies    map[PaymentGatewayType]*latencyStats
+
+	rulesMutex sync.RWMutex
+	rules      []RoutingRule
 }
 
-// NewPaymentGatewayManager creates a new payment gateway manager
+// NewPaymentGatewayManager creates a new payment gateway manager routing payments to
+// the first gateway by name until another selector is set
 func NewPaymentGatewayManager(logger *logger.Logger) *PaymentGatewayManager {
 	return &PaymentGatewayManager{
-		gateways: make(map[PaymentGatewayType]PaymentGateway),
-		logger:   logger,
+		gateways:  make(map[PaymentGatewayType]PaymentGateway),
+		selector:  NewOrderedSelector(),
+		logger:    logger,
+		latencies: make(map[PaymentGatewayType]*latencyStats),
 	}
 }
 
+// SetSelector sets how payments are routed to gateways
+func (pgm *PaymentGatewayManager) SetSelector(selector GatewaySelector) {
+	pgm.selector = selector
+}
+
 // RegisterGateway registers a payment gateway
 func (pgm *PaymentGatewayManager) RegisterGateway(gateway PaymentGateway) {
 	pgm.gateways[gateway.GetGatewayType()] = gateway
@@ -365,3 +382,102 @@ func (pgm *PaymentGatewayManager) GetHealthyGateways(ctx context.Context) []Paym
 	}
 	return healthyGateways
 }
+
+// SetRoutingRules replaces the routing rules payments are evaluated against before
+// the selector is asked
+func (pgm *PaymentGatewayManager) SetRoutingRules(rules []RoutingRule) {
+	sorted := make([]RoutingRule, len(rules))
+	copy(sorted, rules)
+	SortRoutingRules(sorted)
+
+	pgm.rulesMutex.Lock()
+	pgm.rules = sorted
+	pgm.rulesMutex.Unlock()
+	pgm.logger.Info("Payment routing rules loaded", "count", len(sorted))
+}
+
+// RoutingRules returns the routing rules in evaluation order
+func (pgm *PaymentGatewayManager) RoutingRules() []RoutingRule {
+	pgm.rulesMutex.RLock()
+	defer pgm.rulesMutex.RUnlock()
+	rules := make([]RoutingRule, len(pgm.rules))
+	copy(rules, pgm.rules)
+	return rules
+}
+
+// EvaluateRoutingRules evaluates the routing rules against a payment
+func (pgm *PaymentGatewayManager) EvaluateRoutingRules(req *PaymentRequest) RoutingDecision {
+	pgm.rulesMutex.RLock()
+	defer pgm.rulesMutex.RUnlock()
+	return EvaluateRoutingRules(pgm.rules, req)
+}
+
+// SelectGateway routes a payment to one of the healthy gateways eligible accepts, or
+// returns false when there is none or a routing rule bypasses the gateways. A payment
+// a rule routes goes to the rule's gateway while it is a candidate, and is left to the
+// selector otherwise.
+func (pgm *PaymentGatewayManager) SelectGateway(ctx context.Context, req *PaymentRequest, eligible func(PaymentGatewayType) bool) (PaymentGatewayType, bool) {
+	return pgm.selectGateway(ctx, req, eligible, pgm.EvaluateRoutingRules(req))
+}
+
+// PreviewGateway evaluates rules, in place of the routing rules, against a payment
+// and selects the gateway it would be routed to now without processing it
+func (pgm *PaymentGatewayManager) PreviewGateway(ctx context.Context, req *PaymentRequest, rules []RoutingRule) (RoutingDecision, PaymentGatewayType, bool) {
+	sorted := make([]RoutingRule, len(rules))
+	copy(sorted, rules)
+	SortRoutingRules(sorted)
+
+	decision := EvaluateRoutingRules(sorted, req)
+	gateway, ok := pgm.selectGateway(ctx, req, nil, decision)
+	return decision, gateway, ok
+}
+
+// selectGateway routes a payment per the decision of the routing rules, then with the
+// selector among the healthy gateways eligible accepts
+func (pgm *PaymentGatewayManager) selectGateway(ctx context.Context, req *PaymentRequest, eligible func(PaymentGatewayType) bool, decision RoutingDecision) (PaymentGatewayType, bool) {
+	if decision.Bypass {
+		return "", false
+	}
+	healthy := pgm.GetHealthyGateways(ctx)
+
+	var candidates []GatewayCandidate
+	pgm.latencyMutex.RLock()
+	for _, gatewayType := range healthy {
+		if eligible != nil && !eligible(gatewayType) {
+			continue
+		}
+		candidate := GatewayCandidate{Type: gatewayType}
+		if stats, ok := pgm.latencies[gatewayType]; ok {
+			candidate.AverageLatency = stats.average()
+			candidate.Samples = len(stats.samples)
+		}
+		candidates = append(candidates, candidate)
+	}
+	pgm.latencyMutex.RUnlock()
+
+	if decision.Gateway != "" {
+		for _, candidate := range candidates {
+			if candidate.Type == decision.Gateway {
+				return candidate.Type, true
+			}
+		}
+		pgm.logger.Warn("Routed payment gateway is unavailable, falling back to the selector",
+			"rule", decision.Rule.Name, "gateway", string(decision.Gateway))
+	}
+
+	sortCandidates(candidates)
+	return pgm.selector.SelectGateway(req, candidates)
+}
+
+// RecordLatency records how long an attempt on a gateway took, for routing by latency
+func (pgm *PaymentGatewayManager) RecordLatency(gatewayType PaymentGatewayType, latency time.Duration) {
+	pgm.latencyMutex.Lock()
+	defer pgm.latencyMutex.Unlock()
+
+	stats, ok := pgm.latencies[gatewayType]
+	if !ok {
+		stats = &latencyStats{}
+		pgm.latencies[gatewayType] = stats
+	}
+	stats.observe(latency)
+}
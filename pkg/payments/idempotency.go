@@ -4,17 +4,35 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/logger"
 )
 
-// IdempotencyManager handles idempotency for payment operations
+// idempotencyCleanupBatchSize caps the expired records deleted from the database at once
+const idempotencyCleanupBatchSize = 500
+
+var (
+	// ErrIdempotencyKeyReused is returned when an idempotency key comes back with a
+	// request different from the one that first used it
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different payment request")
+	// ErrIdempotencyKeyInUse is returned when another request claimed the key first
+	ErrIdempotencyKeyInUse = errors.New("idempotency key is already in use")
+)
+
+// IdempotencyManager handles idempotency for payment operations. Records are kept in
+// the database when it has a repository, so they survive restarts and are shared by
+// every replica, and in memory otherwise.
 type IdempotencyManager struct {
 	cache      map[string]*IdempotencyRecord
 	cacheMutex sync.RWMutex
+	records    repository.IdempotencyRepository // Stores the records, when set
 	ttl        time.Duration
 	logger     *logger.Logger
 
@@ -35,14 +53,16 @@ type IdempotencyRecord struct {
 	ExpiresAt      time.Time      `json:"expires_at"`
 }
 
-// NewIdempotencyManager creates a new idempotency manager
-func NewIdempotencyManager(ttl time.Duration, logger *logger.Logger) *IdempotencyManager {
+// NewIdempotencyManager creates a new idempotency manager. Records are stored with
+// records, or in memory when it is nil.
+func NewIdempotencyManager(ttl time.Duration, records repository.IdempotencyRepository, logger *logger.Logger) *IdempotencyManager {
 	if ttl == 0 {
 		ttl = 24 * time.Hour // Default 24 hours
 	}
 
 	manager := &IdempotencyManager{
 		cache:       make(map[string]*IdempotencyRecord),
+		records:     records,
 		ttl:         ttl,
 		logger:      logger,
 		stopCleanup: make(chan struct{}),
@@ -76,14 +96,15 @@ func (im *IdempotencyManager) GenerateRequestHash(req *PaymentRequest) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// CheckIdempotency checks if a request has been processed before
-func (im *IdempotencyManager) CheckIdempotency(ctx context.Context, req *PaymentRequest) (*IdempotencyRecord, bool) {
-	im.cacheMutex.RLock()
-	defer im.cacheMutex.RUnlock()
-
-	record, exists := im.cache[req.IdempotencyKey]
-	if !exists {
-		return nil, false
+// CheckIdempotency checks if a request has been processed before. It returns
+// ErrIdempotencyKeyReused if the key was used for a different request.
+func (im *IdempotencyManager) CheckIdempotency(ctx context.Context, req *PaymentRequest) (*IdempotencyRecord, bool, error) {
+	record, err := im.getRecord(ctx, req.IdempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	if record == nil {
+		return nil, false, nil
 	}
 
 	// Check if the record has expired
@@ -91,7 +112,7 @@ func (im *IdempotencyManager) CheckIdempotency(ctx context.Context, req *Payment
 		im.logger.Debug("Idempotency record expired",
 			"key", req.IdempotencyKey,
 			"expired_at", record.ExpiresAt)
-		return nil, false
+		return nil, false, nil
 	}
 
 	// Update last accessed time
@@ -104,7 +125,7 @@ func (im *IdempotencyManager) CheckIdempotency(ctx context.Context, req *Payment
 			"key", req.IdempotencyKey,
 			"original_hash", record.RequestHash,
 			"current_hash", requestHash)
-		return nil, false
+		return nil, false, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, req.IdempotencyKey)
 	}
 
 	im.logger.Debug("Idempotency record found",
@@ -112,14 +133,12 @@ func (im *IdempotencyManager) CheckIdempotency(ctx context.Context, req *Payment
 		"payment_id", record.PaymentID,
 		"status", record.Status)
 
-	return record, true
+	return record, true, nil
 }
 
-// StoreIdempotencyRecord stores a new idempotency record
-func (im *IdempotencyManager) StoreIdempotencyRecord(ctx context.Context, req *PaymentRequest, paymentID string, status string) *IdempotencyRecord {
-	im.cacheMutex.Lock()
-	defer im.cacheMutex.Unlock()
-
+// StoreIdempotencyRecord claims the request's key with a new record. It returns
+// ErrIdempotencyKeyInUse if another request holds the key and it has not expired.
+func (im *IdempotencyManager) StoreIdempotencyRecord(ctx context.Context, req *PaymentRequest, paymentID string, status string) (*IdempotencyRecord, error) {
 	now := time.Now()
 	record := &IdempotencyRecord{
 		Key:            req.IdempotencyKey,
@@ -131,7 +150,31 @@ func (im *IdempotencyManager) StoreIdempotencyRecord(ctx context.Context, req *P
 		ExpiresAt:      now.Add(im.ttl),
 	}
 
-	im.cache[req.IdempotencyKey] = record
+	if im.records != nil {
+		claimed, err := im.records.Claim(ctx, &models.IdempotencyRecord{
+			Key:            record.Key,
+			PaymentID:      record.PaymentID,
+			RequestHash:    record.RequestHash,
+			Status:         record.Status,
+			CreatedAt:      record.CreatedAt,
+			LastAccessedAt: record.LastAccessedAt,
+			ExpiresAt:      record.ExpiresAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store idempotency record: %w", err)
+		}
+		if !claimed {
+			return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyInUse, req.IdempotencyKey)
+		}
+	} else {
+		im.cacheMutex.Lock()
+		if existing, exists := im.cache[req.IdempotencyKey]; exists && !now.After(existing.ExpiresAt) {
+			im.cacheMutex.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyInUse, req.IdempotencyKey)
+		}
+		im.cache[req.IdempotencyKey] = record
+		im.cacheMutex.Unlock()
+	}
 
 	im.logger.Debug("Idempotency record stored",
 		"key", req.IdempotencyKey,
@@ -139,24 +182,40 @@ func (im *IdempotencyManager) StoreIdempotencyRecord(ctx context.Context, req *P
 		"status", status,
 		"expires_at", record.ExpiresAt)
 
-	return record
+	return record, nil
 }
 
 // UpdateIdempotencyRecord updates an existing idempotency record
 func (im *IdempotencyManager) UpdateIdempotencyRecord(ctx context.Context, key string, status string, result *PaymentResult) {
-	im.cacheMutex.Lock()
-	defer im.cacheMutex.Unlock()
-
-	record, exists := im.cache[key]
-	if !exists {
-		im.logger.Warn("Attempted to update non-existent idempotency record", "key", key)
-		return
+	if im.records != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			im.logger.Error("Failed to encode idempotency result", "error", err, "key", key)
+			return
+		}
+		if err := im.records.Update(ctx, &models.IdempotencyRecord{
+			Key:            key,
+			Status:         status,
+			Result:         data,
+			LastAccessedAt: time.Now(),
+		}); err != nil {
+			im.logger.Error("Failed to update idempotency record", "error", err, "key", key)
+			return
+		}
+	} else {
+		im.cacheMutex.Lock()
+		record, exists := im.cache[key]
+		if !exists {
+			im.cacheMutex.Unlock()
+			im.logger.Warn("Attempted to update non-existent idempotency record", "key", key)
+			return
+		}
+		record.Status = status
+		record.Result = result
+		record.LastAccessedAt = time.Now()
+		im.cacheMutex.Unlock()
 	}
 
-	record.Status = status
-	record.Result = result
-	record.LastAccessedAt = time.Now()
-
 	im.logger.Debug("Idempotency record updated",
 		"key", key,
 		"status", status,
@@ -165,16 +224,71 @@ func (im *IdempotencyManager) UpdateIdempotencyRecord(ctx context.Context, key s
 
 // RemoveIdempotencyRecord removes an idempotency record
 func (im *IdempotencyManager) RemoveIdempotencyRecord(ctx context.Context, key string) {
-	im.cacheMutex.Lock()
-	defer im.cacheMutex.Unlock()
-
-	delete(im.cache, key)
+	if im.records != nil {
+		if err := im.records.Delete(ctx, key); err != nil {
+			im.logger.Error("Failed to remove idempotency record", "error", err, "key", key)
+			return
+		}
+	} else {
+		im.cacheMutex.Lock()
+		delete(im.cache, key)
+		im.cacheMutex.Unlock()
+	}
 
 	im.logger.Debug("Idempotency record removed", "key", key)
 }
 
+// getRecord returns the record of a key, expired or not, or nil when there is none
+func (im *IdempotencyManager) getRecord(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	if im.records == nil {
+		im.cacheMutex.RLock()
+		defer im.cacheMutex.RUnlock()
+		return im.cache[key], nil
+	}
+
+	stored, err := im.records.GetByKey(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+
+	record := &IdempotencyRecord{
+		Key:            stored.Key,
+		PaymentID:      stored.PaymentID,
+		RequestHash:    stored.RequestHash,
+		Status:         stored.Status,
+		CreatedAt:      stored.CreatedAt,
+		LastAccessedAt: stored.LastAccessedAt,
+		ExpiresAt:      stored.ExpiresAt,
+	}
+	if len(stored.Result) > 0 && string(stored.Result) != "null" {
+		if err := json.Unmarshal(stored.Result, &record.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode idempotency result: %w", err)
+		}
+	}
+	return record, nil
+}
+
 // GetStats returns statistics about the idempotency cache
 func (im *IdempotencyManager) GetStats() map[string]interface{} {
+	if im.records != nil {
+		expired, total, err := im.records.CountExpired(context.Background(), time.Now())
+		if err != nil {
+			return map[string]interface{}{
+				"error":     err.Error(),
+				"ttl_hours": im.ttl.Hours(),
+			}
+		}
+		return map[string]interface{}{
+			"total_records":   total,
+			"active_records":  total - expired,
+			"expired_records": expired,
+			"ttl_hours":       im.ttl.Hours(),
+		}
+	}
+
 	im.cacheMutex.RLock()
 	defer im.cacheMutex.RUnlock()
 
@@ -218,8 +332,29 @@ func (im *IdempotencyManager) startCleanup() {
 	}()
 }
 
-// cleanupExpiredRecords removes expired records from the cache
+// cleanupExpiredRecords removes expired records from the cache, or from the database
+// in batches
 func (im *IdempotencyManager) cleanupExpiredRecords() {
+	if im.records != nil {
+		now := time.Now()
+		var removed int64
+		for {
+			deleted, err := im.records.DeleteExpired(context.Background(), now, idempotencyCleanupBatchSize)
+			removed += deleted
+			if err != nil {
+				im.logger.Error("Failed to clean up expired idempotency records", "error", err, "removed_count", removed)
+				return
+			}
+			if deleted < idempotencyCleanupBatchSize {
+				break
+			}
+		}
+		if removed > 0 {
+			im.logger.Info("Cleaned up expired idempotency records", "removed_count", removed)
+		}
+		return
+	}
+
 	im.cacheMutex.Lock()
 	defer im.cacheMutex.Unlock()
 
@@ -0,0 +1,258 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// PaymentProcessor processes payments through the registered gateways, retrying
+// retriable failures and failing over to an alternate gateway per the request policies
+type PaymentProcessor struct {
+	gateways    *PaymentGatewayManager
+	breakers    *CircuitBreakerManager
+	idempotency *IdempotencyManager
+	logger      *logger.Logger
+}
+
+// NewPaymentProcessor creates a new payment processor
+func NewPaymentProcessor(
+	gateways *PaymentGatewayManager,
+	breakers *CircuitBreakerManager,
+	idempotency *IdempotencyManager,
+	logger *logger.Logger,
+) *PaymentProcessor {
+	return &PaymentProcessor{
+		gateways:    gateways,
+		breakers:    breakers,
+		idempotency: idempotency,
+		logger:      logger,
+	}
+}
+
+// GatewayIdempotencyKey scopes a payment's idempotency key to one gateway, so a
+// gateway that takes over a payment never sees a key another gateway was sent
+func GatewayIdempotencyKey(idempotencyKey string, gateway PaymentGatewayType) string {
+	return fmt.Sprintf("%s:%s", idempotencyKey, gateway)
+}
+
+// ProcessPayment processes a payment once per idempotency key. Repeating a
+// completed request returns the stored result instead of charging again, while
+// reusing the key for a different request fails with ErrIdempotencyKeyReused. A request
+// without a gateway is routed by the gateway manager's routing rules and selector, and
+// fails with ErrPaymentBypassesGateways when a rule keeps it away from the gateways.
+// A charge the gateway confirms later succeeds with status "processing".
+func (p *PaymentProcessor) ProcessPayment(ctx context.Context, req *PaymentRequest) (*PaymentResult, error) {
+	record, found, err := p.idempotency.CheckIdempotency(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if record.Result == nil {
+			return nil, fmt.Errorf("payment with idempotency key %s is already being processed", req.IdempotencyKey)
+		}
+		return record.Result, nil
+	}
+
+	gatewayType := req.Gateway
+	if gatewayType == "" {
+		if decision := p.gateways.EvaluateRoutingRules(req); decision.Bypass {
+			return nil, fmt.Errorf("%w: routing rule %s", ErrPaymentBypassesGateways, decision.Rule.Name)
+		}
+		selected, ok := p.gateways.SelectGateway(ctx, req, p.breakerAllows)
+		if !ok {
+			return nil, fmt.Errorf("no healthy payment gateway is available")
+		}
+		gatewayType = selected
+	} else if _, exists := p.gateways.GetGateway(gatewayType); !exists {
+		return nil, fmt.Errorf("payment gateway %s is not registered", gatewayType)
+	}
+
+	result := &PaymentResult{
+		PaymentID:      uuid.New().String(),
+		IdempotencyKey: req.IdempotencyKey,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		CreatedAt:      time.Now(),
+	}
+	if _, err := p.idempotency.StoreIdempotencyRecord(ctx, req, result.PaymentID, "processing"); err != nil {
+		if errors.Is(err, ErrIdempotencyKeyInUse) {
+			return nil, fmt.Errorf("payment with idempotency key %s is already being processed", req.IdempotencyKey)
+		}
+		return nil, err
+	}
+
+	if err := p.processPaymentWithRetries(ctx, req, gatewayType, result); err != nil {
+		// Nothing was settled, so let the caller retry under the same key
+		p.idempotency.RemoveIdempotencyRecord(ctx, req.IdempotencyKey)
+		return nil, err
+	}
+
+	completedAt := time.Now()
+	result.CompletedAt = &completedAt
+	result.TotalProcessingTime = completedAt.Sub(result.CreatedAt)
+	result.Status = "failed"
+	if result.Success {
+		result.Status = "completed"
+		if result.Attempts[len(result.Attempts)-1].Pending {
+			result.Status = "processing"
+		}
+	}
+	p.idempotency.UpdateIdempotencyRecord(ctx, req.IdempotencyKey, result.Status, result)
+
+	return result, nil
+}
+
+// processPaymentWithRetries makes attempts, starting on gatewayType, until one
+// succeeds, a failure is not retriable or the retry policy runs out. After enough
+// failover failures, or when the gateway's circuit breaker is open, the next attempt
+// goes to another healthy gateway that has not been tried yet.
+func (p *PaymentProcessor) processPaymentWithRetries(ctx context.Context, req *PaymentRequest, gatewayType PaymentGatewayType, result *PaymentResult) error {
+	retryPolicy := req.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
+	failoverPolicy := req.FailoverPolicy
+	if failoverPolicy == nil {
+		failoverPolicy = DefaultFailoverPolicy()
+	}
+
+	tried := map[PaymentGatewayType]bool{gatewayType: true}
+	failoverFailures := 0
+
+	for attemptNumber := 1; attemptNumber <= retryPolicy.MaxAttempts; attemptNumber++ {
+		attempt, breakerOpen := p.attemptPayment(ctx, req, gatewayType, attemptNumber)
+		result.Attempts = append(result.Attempts, attempt)
+		result.AttemptCount = attemptNumber
+
+		if attempt.Success {
+			result.Success = true
+			result.FinalFailureType = ""
+			result.FinalFailureMessage = ""
+			return nil
+		}
+		result.FinalFailureType = attempt.FailureType
+		result.FinalFailureMessage = attempt.FailureMessage
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !breakerOpen && !retryPolicy.IsRetriable(attempt.FailureType) {
+			return nil
+		}
+
+		if failoverPolicy.IsFailoverFailure(attempt.FailureType) {
+			failoverFailures++
+		}
+		if breakerOpen || failoverPolicy.ShouldFailover(failoverFailures) {
+			if next, ok := p.failoverGateway(ctx, req, tried); ok {
+				p.logger.Warn("Failing over payment to alternate gateway",
+					"idempotency_key", req.IdempotencyKey,
+					"from", string(gatewayType),
+					"to", string(next),
+					"failure_type", string(attempt.FailureType))
+
+				gatewayType = next
+				tried[next] = true
+				failoverFailures = 0
+				continue // The alternate gateway has not seen this payment, so there is nothing to back off from
+			}
+		}
+
+		if attemptNumber < retryPolicy.MaxAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retryPolicy.CalculateNextRetryDelay(attemptNumber)):
+			}
+		}
+	}
+
+	return nil
+}
+
+// attemptPayment makes one attempt on a gateway through its circuit breaker. It
+// reports whether the breaker refused the attempt.
+func (p *PaymentProcessor) attemptPayment(ctx context.Context, req *PaymentRequest, gatewayType PaymentGatewayType, attemptNumber int) (PaymentAttempt, bool) {
+	attempt := PaymentAttempt{
+		AttemptNumber: attemptNumber,
+		Gateway:       gatewayType,
+		StartedAt:     time.Now(),
+	}
+
+	breaker := p.breakers.GetCircuitBreaker(gatewayType)
+	if !breaker.CanExecute() {
+		attempt.FailureType = FailureTypeGatewayError
+		attempt.FailureMessage = fmt.Sprintf("circuit breaker is open for gateway %s", gatewayType)
+		attempt.CompletedAt = &attempt.StartedAt
+		return attempt, true
+	}
+
+	gateway, _ := p.gateways.GetGateway(gatewayType)
+	response, err := gateway.ProcessPayment(ctx, &GatewayPaymentRequest{
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		PaymentMethod:   req.PaymentMethod,
+		IdempotencyKey:  GatewayIdempotencyKey(req.IdempotencyKey, gatewayType),
+		OrderReference:  req.OrderID,
+		Metadata:        req.Metadata,
+		TimeoutDuration: req.TimeoutDuration,
+	})
+	completedAt := time.Now()
+	attempt.CompletedAt = &completedAt
+	attempt.ProcessingTimeMs = completedAt.Sub(attempt.StartedAt).Milliseconds()
+	p.gateways.RecordLatency(gatewayType, completedAt.Sub(attempt.StartedAt))
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		attempt.FailureType = FailureTypeGatewayTimeout
+		attempt.FailureMessage = err.Error()
+	case err != nil:
+		attempt.FailureType = FailureTypeNetworkError
+		attempt.FailureMessage = err.Error()
+	case response.Status == "processing" || response.Status == "pending":
+		// The gateway took the charge and confirms its outcome later, by webhook or
+		// when polled for its status
+		attempt.Success = true
+		attempt.Pending = true
+		attempt.TransactionID = response.TransactionID
+		attempt.GatewayResponse = response.GatewayResponse
+	case response.Status != "completed":
+		attempt.TransactionID = response.TransactionID
+		attempt.FailureType = response.FailureType
+		attempt.FailureMessage = response.FailureMessage
+		attempt.GatewayResponse = response.GatewayResponse
+	default:
+		attempt.Success = true
+		attempt.TransactionID = response.TransactionID
+		attempt.ProcessingFee = response.ProcessingFee
+		attempt.GatewayResponse = response.GatewayResponse
+	}
+
+	// Only failures of the gateway itself count against its breaker, not card declines
+	if attempt.Success {
+		breaker.RecordSuccess()
+	} else if attempt.FailureType.Category() == FailureCategoryTechnical {
+		breaker.RecordFailure(fmt.Errorf("%s: %s", attempt.FailureType, attempt.FailureMessage))
+	}
+
+	return attempt, false
+}
+
+// failoverGateway routes the payment to a healthy gateway that has not been tried and
+// whose circuit breaker allows requests
+func (p *PaymentProcessor) failoverGateway(ctx context.Context, req *PaymentRequest, tried map[PaymentGatewayType]bool) (PaymentGatewayType, bool) {
+	return p.gateways.SelectGateway(ctx, req, func(candidate PaymentGatewayType) bool {
+		return !tried[candidate] && p.breakerAllows(candidate)
+	})
+}
+
+// breakerAllows reports whether a gateway's circuit breaker allows requests
+func (p *PaymentProcessor) breakerAllows(gatewayType PaymentGatewayType) bool {
+	return p.breakers.GetCircuitBreaker(gatewayType).CanExecute()
+}
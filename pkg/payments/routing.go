@@ -0,0 +1,179 @@
+package payments
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Gateway routing strategies, as configured
+const (
+	RoutingStrategyOrdered      = "ordered"
+	RoutingStrategyWeighted     = "weighted"
+	RoutingStrategyLeastLatency = "least_latency"
+)
+
+// latencyWindow is how many recent attempts a gateway's average latency covers
+const latencyWindow = 20
+
+// GatewayCandidate is a healthy gateway a payment can be routed to, with its average
+// latency over its recent attempts; Samples is zero for a gateway not tried yet
+type GatewayCandidate struct {
+	Type           PaymentGatewayType
+	AverageLatency time.Duration
+	Samples        int
+}
+
+// GatewaySelector picks the gateway a payment is routed to among candidates, which
+// are sorted by name. It returns false when none of them fits.
+type GatewaySelector interface {
+	SelectGateway(req *PaymentRequest, candidates []GatewayCandidate) (PaymentGatewayType, bool)
+}
+
+// orderedSelector routes every payment to the first candidate by name
+type orderedSelector struct{}
+
+// NewOrderedSelector creates a selector routing payments to the first gateway by name
+func NewOrderedSelector() GatewaySelector {
+	return orderedSelector{}
+}
+
+func (orderedSelector) SelectGateway(req *PaymentRequest, candidates []GatewayCandidate) (PaymentGatewayType, bool) {
+	if len(candidates) == 0 {
+		return "", false
+	}
+	return candidates[0].Type, true
+}
+
+// weightedSelector spreads payments randomly over the gateways in proportion to their
+// weights. Gateways without a weight are used only when no weighted gateway is a
+// candidate.
+type weightedSelector struct {
+	weights map[PaymentGatewayType]int
+	intn    func(n int) int
+}
+
+// NewWeightedSelector creates a selector spreading payments over the gateways in
+// proportion to their weights
+func NewWeightedSelector(weights map[PaymentGatewayType]int) GatewaySelector {
+	return NewWeightedSelectorWithRandom(weights, rand.Intn)
+}
+
+// NewWeightedSelectorWithRandom creates a weighted selector drawing with intn, which
+// returns a number in [0, n)
+func NewWeightedSelectorWithRandom(weights map[PaymentGatewayType]int, intn func(n int) int) GatewaySelector {
+	return &weightedSelector{weights: weights, intn: intn}
+}
+
+func (s *weightedSelector) SelectGateway(req *PaymentRequest, candidates []GatewayCandidate) (PaymentGatewayType, bool) {
+	totalWeight := 0
+	for _, candidate := range candidates {
+		if weight := s.weights[candidate.Type]; weight > 0 {
+			totalWeight += weight
+		}
+	}
+	if totalWeight == 0 {
+		return NewOrderedSelector().SelectGateway(req, candidates)
+	}
+
+	randomValue := s.intn(totalWeight)
+	currentWeight := 0
+	for _, candidate := range candidates {
+		if weight := s.weights[candidate.Type]; weight > 0 {
+			currentWeight += weight
+			if randomValue < currentWeight {
+				return candidate.Type, true
+			}
+		}
+	}
+	return "", false
+}
+
+// leastLatencySelector routes payments to the gateway with the lowest average latency
+// over its recent attempts. Gateways not tried yet go first, so every gateway gets
+// measured.
+type leastLatencySelector struct{}
+
+// NewLeastLatencySelector creates a selector routing payments to the fastest gateway
+func NewLeastLatencySelector() GatewaySelector {
+	return leastLatencySelector{}
+}
+
+func (leastLatencySelector) SelectGateway(req *PaymentRequest, candidates []GatewayCandidate) (PaymentGatewayType, bool) {
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if best.Samples == 0 {
+			break
+		}
+		if candidate.Samples == 0 || candidate.AverageLatency < best.AverageLatency {
+			best = candidate
+		}
+	}
+	return best.Type, true
+}
+
+// currencyAffinitySelector routes payments in a currency to the gateway that currency
+// is pinned to, while it is a candidate, and the others with the fallback selector
+type currencyAffinitySelector struct {
+	affinity map[string]PaymentGatewayType
+	fallback GatewaySelector
+}
+
+// NewCurrencyAffinitySelector creates a selector pinning currencies, by ISO code, to
+// gateways and routing other payments with fallback
+func NewCurrencyAffinitySelector(affinity map[string]PaymentGatewayType, fallback GatewaySelector) GatewaySelector {
+	normalized := make(map[string]PaymentGatewayType, len(affinity))
+	for currency, gateway := range affinity {
+		normalized[strings.ToUpper(currency)] = gateway
+	}
+	return &currencyAffinitySelector{affinity: normalized, fallback: fallback}
+}
+
+func (s *currencyAffinitySelector) SelectGateway(req *PaymentRequest, candidates []GatewayCandidate) (PaymentGatewayType, bool) {
+	if gateway, pinned := s.affinity[strings.ToUpper(req.Currency)]; pinned {
+		for _, candidate := range candidates {
+			if candidate.Type == gateway {
+				return gateway, true
+			}
+		}
+	}
+	return s.fallback.SelectGateway(req, candidates)
+}
+
+// latencyStats keeps the latencies of a gateway's recent attempts
+type latencyStats struct {
+	samples []time.Duration
+	next    int
+}
+
+func (s *latencyStats) observe(latency time.Duration) {
+	if len(s.samples) < latencyWindow {
+		s.samples = append(s.samples, latency)
+		return
+	}
+	s.samples[s.next] = latency
+	s.next = (s.next + 1) % latencyWindow
+}
+
+func (s *latencyStats) average() time.Duration {
+	if len(s.samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, sample := range s.samples {
+		total += sample
+	}
+	return total / time.Duration(len(s.samples))
+}
+
+// sortCandidates sorts candidates by gateway name
+func sortCandidates(candidates []GatewayCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Type < candidates[j].Type
+	})
+}
@@ -0,0 +1,110 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrPaymentBypassesGateways is returned for a payment a routing rule settles outside
+// the payment gateways, such as a bank transfer recorded by an admin
+var ErrPaymentBypassesGateways = errors.New("payment is settled outside the payment gateways")
+
+// Routing rule actions
+const (
+	// RoutingActionRoute sends matching payments to the rule's gateway
+	RoutingActionRoute = "route"
+	// RoutingActionBypass keeps matching payments away from every gateway
+	RoutingActionBypass = "bypass"
+)
+
+// RoutingRule routes the payments it matches before the gateway selector is asked.
+// A payment matches when its amount is within [MinAmount, MaxAmount], leaving out an
+// unset bound, and its currency and method are among Currencies and PaymentMethods,
+// unless they are empty.
+type RoutingRule struct {
+	ID             string
+	Name           string
+	Priority       int
+	MinAmount      *float64
+	MaxAmount      *float64
+	Currencies     []string
+	PaymentMethods []string
+	Action         string
+	Gateway        PaymentGatewayType
+}
+
+// Match reports whether the rule matches a payment and, when it does not, why
+func (r *RoutingRule) Match(req *PaymentRequest) (bool, string) {
+	if r.MinAmount != nil && req.Amount < *r.MinAmount {
+		return false, fmt.Sprintf("amount %.2f is below %.2f", req.Amount, *r.MinAmount)
+	}
+	if r.MaxAmount != nil && req.Amount > *r.MaxAmount {
+		return false, fmt.Sprintf("amount %.2f is above %.2f", req.Amount, *r.MaxAmount)
+	}
+	if len(r.Currencies) > 0 && !containsFold(r.Currencies, req.Currency) {
+		return false, fmt.Sprintf("currency %s is not one of %s", strings.ToUpper(req.Currency), strings.Join(r.Currencies, ", "))
+	}
+	if len(r.PaymentMethods) > 0 && !containsFold(r.PaymentMethods, req.PaymentMethod) {
+		return false, fmt.Sprintf("payment method %s is not one of %s", req.PaymentMethod, strings.Join(r.PaymentMethods, ", "))
+	}
+	return true, ""
+}
+
+// RoutingRuleCheck is how one rule was evaluated against a payment
+type RoutingRuleCheck struct {
+	Rule    RoutingRule
+	Matched bool
+	Reason  string // Why the rule did not match
+}
+
+// RoutingDecision is the outcome of evaluating routing rules against a payment. Rule
+// is the first rule that matched, or nil when none did and the selector decides.
+type RoutingDecision struct {
+	Rule    *RoutingRule
+	Bypass  bool
+	Gateway PaymentGatewayType
+	Checks  []RoutingRuleCheck
+}
+
+// SortRoutingRules sorts rules in evaluation order: by descending priority, then name
+func SortRoutingRules(rules []RoutingRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
+		}
+		return rules[i].Name < rules[j].Name
+	})
+}
+
+// EvaluateRoutingRules evaluates rules, which must be in evaluation order, against a
+// payment until one matches
+func EvaluateRoutingRules(rules []RoutingRule, req *PaymentRequest) RoutingDecision {
+	var decision RoutingDecision
+	for i := range rules {
+		matched, reason := rules[i].Match(req)
+		decision.Checks = append(decision.Checks, RoutingRuleCheck{Rule: rules[i], Matched: matched, Reason: reason})
+		if !matched {
+			continue
+		}
+		rule := rules[i]
+		decision.Rule = &rule
+		decision.Bypass = rule.Action == RoutingActionBypass
+		if !decision.Bypass {
+			decision.Gateway = rule.Gateway
+		}
+		break
+	}
+	return decision
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,522 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// StripeConfig holds the credentials of a Stripe account
+type StripeConfig struct {
+	SecretKey  string        `json:"-"`
+	BaseURL    string        `json:"base_url"`    // Defaults to the Stripe API
+	APIVersion string        `json:"api_version"` // Defaults to the account's API version
+	Timeout    time.Duration `json:"timeout"`
+}
+
+// StripeGateway charges payments through Stripe PaymentIntents, confirmed as soon as
+// they are created. PaymentMethod is the Stripe PaymentMethod ID to charge, such as
+// the pm_ ID returned by Stripe.js; payments needing customer authentication fail,
+// since nobody is there to complete it.
+type StripeGateway struct {
+	config *StripeConfig
+	client *http.Client
+	logger *logger.Logger
+}
+
+// NewStripeGateway creates a new Stripe gateway
+func NewStripeGateway(config *StripeConfig, logger *logger.Logger) *StripeGateway {
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.stripe.com"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &StripeGateway{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		logger: logger,
+	}
+}
+
+// stripeError is the error object of a failed Stripe API request, or the last
+// payment error of a PaymentIntent
+type stripeError struct {
+	Type        string `json:"type"`
+	Code        string `json:"code"`
+	DeclineCode string `json:"decline_code"`
+	Message     string `json:"message"`
+	Param       string `json:"param"`
+	// PaymentIntent is the declined PaymentIntent of a card error
+	PaymentIntent *struct {
+		ID string `json:"id"`
+	} `json:"payment_intent"`
+}
+
+// stripePaymentIntent is the subset of a Stripe PaymentIntent the gateway reads
+type stripePaymentIntent struct {
+	ID               string       `json:"id"`
+	Status           string       `json:"status"`
+	Amount           int64        `json:"amount"`
+	Currency         string       `json:"currency"`
+	Created          int64        `json:"created"`
+	LatestCharge     stripeCharge `json:"latest_charge"`
+	LastPaymentError *stripeError `json:"last_payment_error"`
+}
+
+// stripeCharge is the latest charge of a PaymentIntent, which is only an ID unless
+// the charge was expanded
+type stripeCharge struct {
+	ID                string `json:"id"`
+	AuthorizationCode string `json:"authorization_code"`
+}
+
+func (c *stripeCharge) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		c.ID = id
+		return nil
+	}
+
+	type charge stripeCharge
+	return json.Unmarshal(data, (*charge)(c))
+}
+
+// stripeRefund is the subset of a Stripe Refund the gateway reads
+type stripeRefund struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	FailureReason string `json:"failure_reason"`
+}
+
+// stripeAPIError is returned for Stripe API requests answered with an error
+type stripeAPIError struct {
+	StatusCode int
+	Err        stripeError
+}
+
+func (e *stripeAPIError) Error() string {
+	return fmt.Sprintf("stripe responded with status %d: %s", e.StatusCode, e.Err.Message)
+}
+
+// ProcessPayment creates and confirms a PaymentIntent for the payment. Declines and
+// rejected requests are failed responses; errors mean Stripe could not be reached.
+func (s *StripeGateway) ProcessPayment(ctx context.Context, req *GatewayPaymentRequest) (*GatewayPaymentResponse, error) {
+	startTime := time.Now()
+
+	form := url.Values{}
+	form.Set("amount", fmt.Sprint(stripeAmount(req.Amount, req.Currency)))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("payment_method", req.PaymentMethod)
+	form.Set("confirm", "true")
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("automatic_payment_methods[allow_redirects]", "never")
+	form.Add("expand[]", "latest_charge")
+	if req.OrderReference != "" {
+		form.Set("metadata[order_reference]", req.OrderReference)
+	}
+	if req.CustomerEmail != "" {
+		form.Set("receipt_email", req.CustomerEmail)
+	}
+	for key, value := range req.Metadata {
+		form.Set(fmt.Sprintf("metadata[%s]", key), fmt.Sprint(value))
+	}
+
+	ctx, cancel := s.withTimeout(ctx, req.TimeoutDuration)
+	defer cancel()
+
+	var intent stripePaymentIntent
+	err := s.do(ctx, http.MethodPost, "/v1/payment_intents", form, req.IdempotencyKey, &intent)
+	response := &GatewayPaymentResponse{
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}
+
+	var apiErr *stripeAPIError
+	switch {
+	case errors.As(err, &apiErr):
+		response.Status = "failed"
+		response.FailureType = stripeFailureType(apiErr.StatusCode, apiErr.Err)
+		response.FailureMessage = apiErr.Err.Message
+		response.GatewayResponse = stripeErrorResponse(apiErr.Err)
+		if apiErr.Err.PaymentIntent != nil {
+			response.TransactionID = apiErr.Err.PaymentIntent.ID
+		}
+	case isTimeout(err):
+		response.Status = "failed"
+		response.FailureType = FailureTypeGatewayTimeout
+		response.FailureMessage = err.Error()
+	case err != nil:
+		return nil, fmt.Errorf("stripe payment failed: %w", err)
+	default:
+		response.TransactionID = intent.ID
+		response.Status, response.FailureType, response.FailureMessage = stripeIntentOutcome(&intent)
+		response.AuthorizationCode = intent.LatestCharge.AuthorizationCode
+		response.GatewayResponse = map[string]interface{}{
+			"gateway":         string(GatewayTypeStripe),
+			"payment_intent":  intent.ID,
+			"intent_status":   intent.Status,
+			"charge":          intent.LatestCharge.ID,
+			"reference":       req.OrderReference,
+			"idempotency_key": req.IdempotencyKey,
+		}
+	}
+	response.ProcessingTimeMs = time.Since(startTime).Milliseconds()
+
+	s.logger.Debug("Stripe payment processed", "payment_intent", response.TransactionID,
+		"status", response.Status, "failure_type", string(response.FailureType))
+
+	return response, nil
+}
+
+// RefundPayment refunds the amount of a PaymentIntent, or all of it without an amount
+func (s *StripeGateway) RefundPayment(ctx context.Context, req *GatewayRefundRequest) (*GatewayRefundResponse, error) {
+	startTime := time.Now()
+
+	form := url.Values{}
+	form.Set("payment_intent", req.OriginalTransactionID)
+	if req.Amount > 0 {
+		form.Set("amount", fmt.Sprint(stripeAmount(req.Amount, req.Currency)))
+	}
+	// Stripe only takes its own refund reasons; others are kept as metadata
+	switch req.Reason {
+	case "duplicate", "fraudulent", "requested_by_customer":
+		form.Set("reason", req.Reason)
+	case "":
+	default:
+		form.Set("metadata[reason]", req.Reason)
+	}
+	for key, value := range req.Metadata {
+		form.Set(fmt.Sprintf("metadata[%s]", key), fmt.Sprint(value))
+	}
+
+	var refund stripeRefund
+	err := s.do(ctx, http.MethodPost, "/v1/refunds", form, req.IdempotencyKey, &refund)
+	response := &GatewayRefundResponse{
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}
+
+	var apiErr *stripeAPIError
+	switch {
+	case errors.As(err, &apiErr):
+		response.Status = "failed"
+		response.FailureType = stripeFailureType(apiErr.StatusCode, apiErr.Err)
+		response.FailureMessage = apiErr.Err.Message
+		response.GatewayResponse = stripeErrorResponse(apiErr.Err)
+	case isTimeout(err):
+		response.Status = "failed"
+		response.FailureType = FailureTypeGatewayTimeout
+		response.FailureMessage = err.Error()
+	case err != nil:
+		return nil, fmt.Errorf("stripe refund failed: %w", err)
+	default:
+		response.RefundID = refund.ID
+		response.Amount = stripeMajorAmount(refund.Amount, refund.Currency)
+		response.GatewayResponse = map[string]interface{}{
+			"gateway":         string(GatewayTypeStripe),
+			"refund_status":   refund.Status,
+			"original_txn":    req.OriginalTransactionID,
+			"idempotency_key": req.IdempotencyKey,
+		}
+
+		switch refund.Status {
+		case "succeeded":
+			response.Status = "completed"
+		case "failed", "canceled":
+			response.Status = "failed"
+			response.FailureType = stripeRefundFailureType(refund.FailureReason)
+			response.FailureMessage = fmt.Sprintf("refund %s: %s", refund.Status, refund.FailureReason)
+		default:
+			// Pending refunds, and those waiting for customer details, settle later
+			response.Status = "pending"
+		}
+	}
+	response.ProcessingTimeMs = time.Since(startTime).Milliseconds()
+
+	return response, nil
+}
+
+// GetPaymentStatus retrieves the PaymentIntent with the given ID
+func (s *StripeGateway) GetPaymentStatus(ctx context.Context, gatewayTransactionID string) (*GatewayPaymentStatus, error) {
+	var intent stripePaymentIntent
+	if err := s.do(ctx, http.MethodGet, "/v1/payment_intents/"+url.PathEscape(gatewayTransactionID), nil, "", &intent); err != nil {
+		return nil, fmt.Errorf("failed to get stripe payment status: %w", err)
+	}
+
+	status := &GatewayPaymentStatus{
+		TransactionID: intent.ID,
+		Amount:        stripeMajorAmount(intent.Amount, intent.Currency),
+		Currency:      strings.ToUpper(intent.Currency),
+		CreatedAt:     time.Unix(intent.Created, 0),
+		UpdatedAt:     time.Now(),
+		GatewayResponse: map[string]interface{}{
+			"gateway":       string(GatewayTypeStripe),
+			"intent_status": intent.Status,
+			"charge":        intent.LatestCharge.ID,
+		},
+	}
+	status.Status, status.FailureType, status.FailureMessage = stripeIntentOutcome(&intent)
+	return status, nil
+}
+
+// GetGatewayType returns the gateway type
+func (s *StripeGateway) GetGatewayType() PaymentGatewayType {
+	return GatewayTypeStripe
+}
+
+// IsHealthy checks that the Stripe API accepts the configured secret key
+func (s *StripeGateway) IsHealthy(ctx context.Context) bool {
+	return s.do(ctx, http.MethodGet, "/v1/balance", nil, "", nil) == nil
+}
+
+// withTimeout bounds a request by the payment's timeout, when it has one
+func (s *StripeGateway) withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// do sends an authenticated request to the Stripe API and decodes its response into
+// out. Stripe replays the response of a request repeated with the same idempotency key.
+func (s *StripeGateway) do(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.config.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.config.SecretKey)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if s.config.APIVersion != "" {
+		req.Header.Set("Stripe-Version", s.config.APIVersion)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var payload struct {
+			Error stripeError `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			payload.Error.Message = http.StatusText(resp.StatusCode)
+		}
+		return &stripeAPIError{StatusCode: resp.StatusCode, Err: payload.Error}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return nil
+}
+
+// stripeIntentOutcome returns the gateway status of a PaymentIntent, with the failure
+// of one that did not succeed
+func stripeIntentOutcome(intent *stripePaymentIntent) (string, PaymentFailureType, string) {
+	switch intent.Status {
+	case "succeeded":
+		return "completed", "", ""
+	case "processing":
+		return "processing", "", ""
+	case "requires_action":
+		return "failed", FailureTypeInvalidCard, "the card requires authentication by the customer"
+	case "canceled":
+		return "failed", FailureTypeGatewayError, "payment was canceled"
+	default:
+		if intent.LastPaymentError != nil {
+			return "failed", stripeFailureType(http.StatusPaymentRequired, *intent.LastPaymentError), intent.LastPaymentError.Message
+		}
+		return "pending", "", ""
+	}
+}
+
+// stripeDeclineFailureTypes maps Stripe decline codes, and the card error codes that
+// name their reason, to failure types
+var stripeDeclineFailureTypes = map[string]PaymentFailureType{
+	"insufficient_funds":              FailureTypeInsufficientFunds,
+	"withdrawal_count_limit_exceeded": FailureTypeInsufficientFunds,
+	"card_velocity_exceeded":          FailureTypeInsufficientFunds,
+
+	"incorrect_number":               FailureTypeInvalidCard,
+	"invalid_number":                 FailureTypeInvalidCard,
+	"incorrect_cvc":                  FailureTypeInvalidCard,
+	"invalid_cvc":                    FailureTypeInvalidCard,
+	"invalid_expiry_month":           FailureTypeInvalidCard,
+	"invalid_expiry_year":            FailureTypeInvalidCard,
+	"incorrect_zip":                  FailureTypeInvalidCard,
+	"incorrect_pin":                  FailureTypeInvalidCard,
+	"invalid_pin":                    FailureTypeInvalidCard,
+	"invalid_account":                FailureTypeInvalidCard,
+	"card_not_supported":             FailureTypeInvalidCard,
+	"offline_pin_required":           FailureTypeInvalidCard,
+	"online_or_offline_pin_required": FailureTypeInvalidCard,
+	"authentication_required":        FailureTypeInvalidCard,
+
+	"expired_card": FailureTypeExpiredCard,
+
+	"fraudulent":         FailureTypeFraudSuspected,
+	"merchant_blacklist": FailureTypeFraudSuspected,
+
+	"lost_card":                        FailureTypeCardBlocked,
+	"stolen_card":                      FailureTypeCardBlocked,
+	"pickup_card":                      FailureTypeCardBlocked,
+	"restricted_card":                  FailureTypeCardBlocked,
+	"security_violation":               FailureTypeCardBlocked,
+	"revocation_of_authorization":      FailureTypeCardBlocked,
+	"revocation_of_all_authorizations": FailureTypeCardBlocked,
+	"stop_payment_order":               FailureTypeCardBlocked,
+	"do_not_honor":                     FailureTypeCardBlocked,
+	"generic_decline":                  FailureTypeCardBlocked,
+	"call_issuer":                      FailureTypeCardBlocked,
+	"not_permitted":                    FailureTypeCardBlocked,
+	"service_not_allowed":              FailureTypeCardBlocked,
+	"transaction_not_allowed":          FailureTypeCardBlocked,
+	"no_action_taken":                  FailureTypeCardBlocked,
+
+	"try_again_later":       FailureTypeTemporaryDecline,
+	"issuer_not_available":  FailureTypeTemporaryDecline,
+	"processing_error":      FailureTypeTemporaryDecline,
+	"reenter_transaction":   FailureTypeTemporaryDecline,
+	"approve_with_id":       FailureTypeTemporaryDecline,
+	"duplicate_transaction": FailureTypeTemporaryDecline,
+
+	"invalid_amount":         FailureTypeInvalidAmount,
+	"amount_too_large":       FailureTypeInvalidAmount,
+	"amount_too_small":       FailureTypeInvalidAmount,
+	"currency_not_supported": FailureTypeInvalidCurrency,
+}
+
+// stripeFailureType maps a Stripe error to a failure type. Declines are mapped by
+// their decline code, falling back to the error code; declines Stripe gives no known
+// reason for are refusals only the issuer can explain.
+func stripeFailureType(statusCode int, err stripeError) PaymentFailureType {
+	switch err.Type {
+	case "card_error":
+		if failureType, ok := stripeDeclineFailureTypes[err.DeclineCode]; ok {
+			return failureType
+		}
+		if failureType, ok := stripeDeclineFailureTypes[err.Code]; ok {
+			return failureType
+		}
+		return FailureTypeCardBlocked
+	case "authentication_error":
+		return FailureTypeAuthenticationError
+	case "rate_limit_error":
+		return FailureTypeRateLimited
+	case "idempotency_error":
+		return FailureTypeConfigurationError
+	case "invalid_request_error":
+		if failureType, ok := stripeDeclineFailureTypes[err.Code]; ok {
+			return failureType
+		}
+		switch err.Param {
+		case "currency":
+			return FailureTypeInvalidCurrency
+		case "amount":
+			return FailureTypeInvalidAmount
+		case "payment_method":
+			return FailureTypeInvalidCard
+		}
+		return FailureTypeConfigurationError
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return FailureTypeAuthenticationError
+	case statusCode == http.StatusTooManyRequests:
+		return FailureTypeRateLimited
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return FailureTypeGatewayTimeout
+	default:
+		return FailureTypeGatewayError
+	}
+}
+
+// stripeRefundFailureType maps the failure reason of a Stripe refund to a failure type
+func stripeRefundFailureType(reason string) PaymentFailureType {
+	switch reason {
+	case "expired_or_canceled_card":
+		return FailureTypeExpiredCard
+	case "lost_or_stolen_card":
+		return FailureTypeCardBlocked
+	case "insufficient_funds":
+		return FailureTypeInsufficientFunds
+	default:
+		return FailureTypeGatewayError
+	}
+}
+
+// stripeErrorResponse keeps the details of a Stripe error as the gateway response
+func stripeErrorResponse(err stripeError) map[string]interface{} {
+	return map[string]interface{}{
+		"gateway":      string(GatewayTypeStripe),
+		"failed_at":    time.Now().UTC(),
+		"error_type":   err.Type,
+		"error_code":   err.Code,
+		"decline_code": err.DeclineCode,
+	}
+}
+
+// stripeZeroDecimalCurrencies are charged in whole units rather than cents
+var stripeZeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true,
+	"krw": true, "mga": true, "pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// stripeAmount converts an amount to the smallest unit of its currency
+func stripeAmount(amount float64, currency string) int64 {
+	if stripeZeroDecimalCurrencies[strings.ToLower(currency)] {
+		return int64(math.Round(amount))
+	}
+	return int64(math.Round(amount * 100))
+}
+
+// stripeMajorAmount converts an amount in the smallest unit of its currency back
+func stripeMajorAmount(amount int64, currency string) float64 {
+	if stripeZeroDecimalCurrencies[strings.ToLower(currency)] {
+		return float64(amount)
+	}
+	return float64(amount) / 100
+}
+
+// isTimeout reports whether a request failed by running out of time
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
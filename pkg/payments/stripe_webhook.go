@@ -0,0 +1,140 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStripeWebhookTolerance is how old a Stripe callback's signature timestamp
+// may be, as Stripe's own libraries default to
+const DefaultStripeWebhookTolerance = 5 * time.Minute
+
+// StripeWebhookVerifier verifies Stripe webhook callbacks by their Stripe-Signature
+// header, signed with the endpoint's signing secret, and parses PaymentIntent and
+// refund events. Callbacks signed longer than the tolerance ago are rejected, so a
+// captured callback cannot be replayed later.
+type StripeWebhookVerifier struct {
+	secret    []byte
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewStripeWebhookVerifier creates a verifier of callbacks signed with the signing
+// secret (whsec_...) of a Stripe webhook endpoint
+func NewStripeWebhookVerifier(secret string, tolerance time.Duration) *StripeWebhookVerifier {
+	return NewStripeWebhookVerifierWithClock(secret, tolerance, time.Now)
+}
+
+// NewStripeWebhookVerifierWithClock creates a Stripe webhook verifier checking
+// signature timestamps against the times now returns
+func NewStripeWebhookVerifierWithClock(secret string, tolerance time.Duration, now func() time.Time) *StripeWebhookVerifier {
+	if tolerance <= 0 {
+		tolerance = DefaultStripeWebhookTolerance
+	}
+	return &StripeWebhookVerifier{secret: []byte(secret), tolerance: tolerance, now: now}
+}
+
+// stripeEvent is the subset of a Stripe Event the verifier reads
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeRefundedCharge is the subset of a refunded Stripe Charge the verifier reads
+type stripeRefundedCharge struct {
+	PaymentIntent string `json:"payment_intent"`
+	Refunded      bool   `json:"refunded"`
+}
+
+func (v *StripeWebhookVerifier) VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	if err := v.verifySignature(header.Get("Stripe-Signature"), body); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid stripe event: %w", err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, errors.New("invalid stripe event: id and type are required")
+	}
+
+	parsed := &WebhookEvent{ID: event.ID, Type: event.Type}
+	switch event.Type {
+	case "payment_intent.succeeded", "payment_intent.payment_failed":
+		var intent stripePaymentIntent
+		if err := json.Unmarshal(event.Data.Object, &intent); err != nil {
+			return nil, fmt.Errorf("invalid stripe payment intent: %w", err)
+		}
+		parsed.TransactionID = intent.ID
+		parsed.Type = WebhookEventPaymentSucceeded
+		if event.Type == "payment_intent.payment_failed" {
+			parsed.Type = WebhookEventPaymentFailed
+			parsed.FailureType = FailureTypeGatewayError
+			if intent.LastPaymentError != nil {
+				parsed.FailureType = stripeFailureType(http.StatusPaymentRequired, *intent.LastPaymentError)
+				parsed.FailureMessage = intent.LastPaymentError.Message
+			}
+		}
+	case "charge.refunded":
+		// Payments are only refunded by callbacks once nothing is left to refund;
+		// partial refunds are made through the API
+		var charge stripeRefundedCharge
+		if err := json.Unmarshal(event.Data.Object, &charge); err != nil {
+			return nil, fmt.Errorf("invalid stripe charge: %w", err)
+		}
+		parsed.TransactionID = charge.PaymentIntent
+		if charge.Refunded {
+			parsed.Type = WebhookEventPaymentRefunded
+		}
+	}
+	return parsed, nil
+}
+
+// verifySignature checks a Stripe-Signature header of the form t=<unix time>,v1=<hex
+// HMAC-SHA256 of "<t>.<body>">, which may hold several v1 signatures while the
+// signing secret is rolled
+func (v *StripeWebhookVerifier) verifySignature(header string, body []byte) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidWebhookSignature
+	}
+	if age := v.now().Sub(time.Unix(seconds, 0)); age > v.tolerance || age < -v.tolerance {
+		return fmt.Errorf("%w: timestamp outside the tolerance of %s", ErrInvalidWebhookSignature, v.tolerance)
+	}
+
+	expected := SignWebhookPayload(v.secret, append([]byte(timestamp+"."), body...))
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookSignature
+}
@@ -0,0 +1,47 @@
+package payments
+
+// FailureCategory groups payment failure types by what can be done about them
+type FailureCategory string
+
+const (
+	// FailureCategoryTechnical failures come from the gateway or the network and
+	// usually succeed when retried or routed to another gateway
+	FailureCategoryTechnical FailureCategory = "technical"
+	// FailureCategorySoftDecline failures are issuer declines that may clear on retry
+	FailureCategorySoftDecline FailureCategory = "soft_decline"
+	// FailureCategoryHardDecline failures need the customer to change something
+	FailureCategoryHardDecline FailureCategory = "hard_decline"
+	// FailureCategoryFraud failures were blocked by fraud screening
+	FailureCategoryFraud FailureCategory = "fraud"
+	// FailureCategorySystem failures point at our own integration or configuration
+	FailureCategorySystem FailureCategory = "system"
+	// FailureCategoryUnknown covers failure types outside the taxonomy
+	FailureCategoryUnknown FailureCategory = "unknown"
+)
+
+// failureCategories maps each known failure type to its category
+var failureCategories = map[PaymentFailureType]FailureCategory{
+	FailureTypeNetworkError:        FailureCategoryTechnical,
+	FailureTypeGatewayTimeout:      FailureCategoryTechnical,
+	FailureTypeGatewayError:        FailureCategoryTechnical,
+	FailureTypeRateLimited:         FailureCategoryTechnical,
+	FailureTypeTemporaryDecline:    FailureCategorySoftDecline,
+	FailureTypeInsufficientFunds:   FailureCategoryHardDecline,
+	FailureTypeInvalidCard:         FailureCategoryHardDecline,
+	FailureTypeExpiredCard:         FailureCategoryHardDecline,
+	FailureTypeCardBlocked:         FailureCategoryHardDecline,
+	FailureTypeInvalidAmount:       FailureCategoryHardDecline,
+	FailureTypeInvalidCurrency:     FailureCategoryHardDecline,
+	FailureTypeFraudSuspected:      FailureCategoryFraud,
+	FailureTypeConfigurationError:  FailureCategorySystem,
+	FailureTypeInternalError:       FailureCategorySystem,
+	FailureTypeAuthenticationError: FailureCategorySystem,
+}
+
+// Category returns the taxonomy category of the failure type
+func (t PaymentFailureType) Category() FailureCategory {
+	if category, ok := failureCategories[t]; ok {
+		return category
+	}
+	return FailureCategoryUnknown
+}
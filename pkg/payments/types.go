@@ -48,6 +48,9 @@ type PaymentAttempt struct {
 	StartedAt        time.Time              `json:"started_at"`
 	CompletedAt      *time.Time             `json:"completed_at,omitempty"`
 	Success          bool                   `json:"success"`
+	Pending          bool                   `json:"pending,omitempty"`
+	TransactionID    string                 `json:"transaction_id,omitempty"`
+	ProcessingFee    float64                `json:"processing_fee,omitempty"`
 	FailureType      PaymentFailureType     `json:"failure_type,omitempty"`
 	FailureMessage   string                 `json:"failure_message,omitempty"`
 	GatewayResponse  map[string]interface{} `json:"gateway_response,omitempty"`
@@ -125,6 +128,40 @@ func (p *RetryPolicy) IsRetriable(failureType PaymentFailureType) bool {
 	return false
 }
 
+// FailoverPolicy defines when a payment moves from its gateway to an alternate one.
+// Only failures listed in FailoverFailures count towards FailuresBeforeFailover;
+// declines are not the gateway's fault and are retried on the same gateway.
+type FailoverPolicy struct {
+	FailuresBeforeFailover int                  `json:"failures_before_failover"` // 0 disables failover
+	FailoverFailures       []PaymentFailureType `json:"failover_failures"`
+}
+
+// DefaultFailoverPolicy fails over after two network errors or timeouts on the same gateway
+func DefaultFailoverPolicy() *FailoverPolicy {
+	return &FailoverPolicy{
+		FailuresBeforeFailover: 2,
+		FailoverFailures: []PaymentFailureType{
+			FailureTypeNetworkError,
+			FailureTypeGatewayTimeout,
+		},
+	}
+}
+
+// IsFailoverFailure checks if a failure type counts towards failing over
+func (p *FailoverPolicy) IsFailoverFailure(failureType PaymentFailureType) bool {
+	for _, failoverType := range p.FailoverFailures {
+		if failoverType == failureType {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldFailover checks if a gateway has returned enough failover failures to be abandoned
+func (p *FailoverPolicy) ShouldFailover(failures int) bool {
+	return p.FailuresBeforeFailover > 0 && failures >= p.FailuresBeforeFailover
+}
+
 // CalculateNextRetryDelay calculates the delay before the next retry attempt
 func (p *RetryPolicy) CalculateNextRetryDelay(attemptNumber int) time.Duration {
 	if attemptNumber >= p.MaxAttempts {
@@ -169,6 +206,7 @@ type PaymentRequest struct {
 	Gateway         PaymentGatewayType     `json:"gateway"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 	RetryPolicy     *RetryPolicy           `json:"retry_policy,omitempty"`
+	FailoverPolicy  *FailoverPolicy        `json:"failover_policy,omitempty"`
 	CallbackURL     string                 `json:"callback_url,omitempty"`
 	TimeoutDuration time.Duration          `json:"timeout_duration,omitempty"`
 }
@@ -0,0 +1,98 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidWebhookSignature is returned for gateway callbacks whose signature does
+// not verify
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// Gateway webhook event types the platform acts on, the same for every gateway
+const (
+	WebhookEventPaymentSucceeded = "payment.succeeded"
+	WebhookEventPaymentFailed    = "payment.failed"
+	WebhookEventPaymentRefunded  = "payment.refunded"
+)
+
+// WebhookEvent is a verified gateway callback about a payment. Events the platform
+// does not act on keep the gateway's own type.
+type WebhookEvent struct {
+	ID             string
+	Type           string
+	TransactionID  string // The gateway's transaction ID of the payment
+	FailureType    PaymentFailureType
+	FailureMessage string
+}
+
+// WebhookVerifier checks the signature of a gateway's webhook callbacks and parses
+// them into events
+type WebhookVerifier interface {
+	VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error)
+}
+
+// WebhookSignatureHeader carries the signature of callbacks verified by HMACWebhookVerifier
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// HMACWebhookVerifier verifies callbacks signed with a shared secret: the
+// X-Webhook-Signature header holds "sha256=" and the hex HMAC-SHA256 of the body.
+// The body is an event in the platform's own format:
+//
+//	{"id": "...", "type": "payment.succeeded", "data": {"transaction_id": "...", "failure_type": "...", "failure_message": "..."}}
+type HMACWebhookVerifier struct {
+	secret []byte
+}
+
+// NewHMACWebhookVerifier creates a verifier of callbacks signed with secret
+func NewHMACWebhookVerifier(secret string) *HMACWebhookVerifier {
+	return &HMACWebhookVerifier{secret: []byte(secret)}
+}
+
+func (v *HMACWebhookVerifier) VerifyWebhook(header http.Header, body []byte) (*WebhookEvent, error) {
+	signature, found := strings.CutPrefix(header.Get(WebhookSignatureHeader), "sha256=")
+	if !found {
+		return nil, ErrInvalidWebhookSignature
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, SignWebhookPayload(v.secret, body)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			TransactionID  string             `json:"transaction_id"`
+			FailureType    PaymentFailureType `json:"failure_type"`
+			FailureMessage string             `json:"failure_message"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if payload.ID == "" || payload.Type == "" {
+		return nil, errors.New("invalid webhook payload: id and type are required")
+	}
+
+	return &WebhookEvent{
+		ID:             payload.ID,
+		Type:           payload.Type,
+		TransactionID:  payload.Data.TransactionID,
+		FailureType:    payload.Data.FailureType,
+		FailureMessage: payload.Data.FailureMessage,
+	}, nil
+}
+
+// SignWebhookPayload returns the HMAC-SHA256 of a payload with secret
+func SignWebhookPayload(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
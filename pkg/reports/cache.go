@@ -0,0 +1,188 @@
+package reports
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+)
+
+// Report cache backends selectable in ReportManagerConfig
+const (
+	CacheBackendMemory = "memory" // Cached in this process
+	CacheBackendRedis  = "redis"  // Shared between instances through Redis
+)
+
+// Cache stores generated reports under their cache key until they expire
+type Cache interface {
+	// Get returns the unexpired report cached under key, counting the hit, or nil
+	Get(ctx context.Context, key string) (*ReportCache, error)
+
+	// Set caches a report until its ExpiresAt
+	Set(ctx context.Context, entry *ReportCache) error
+
+	// Flush removes every cached report and returns how many there were
+	Flush(ctx context.Context) (int, error)
+
+	// Stats summarizes the cached reports
+	Stats(ctx context.Context) (CacheStats, error)
+
+	// Close releases the cache's resources
+	Close() error
+}
+
+// CacheStats summarizes the reports in a cache
+type CacheStats struct {
+	Entries        int
+	ExpiredEntries int // Expired but not yet removed
+	TotalHits      int
+}
+
+// memoryCache caches reports in process memory, evicting the least recently accessed
+// report when full and removing expired ones every cleanup interval
+type memoryCache struct {
+	entries map[string]*ReportCache
+	mutex   sync.RWMutex
+	maxSize int
+	done    chan struct{}
+	once    sync.Once
+	logger  *logger.Logger
+}
+
+// NewMemoryCache creates a cache of up to maxSize reports in process memory
+func NewMemoryCache(maxSize int, cleanupInterval time.Duration, logger *logger.Logger) Cache {
+	c := &memoryCache{
+		entries: make(map[string]*ReportCache),
+		maxSize: maxSize,
+		done:    make(chan struct{}),
+		logger:  logger,
+	}
+
+	go c.cleanupRoutine(cleanupInterval)
+
+	return c
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (*ReportCache, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return nil, nil
+	}
+
+	if entry.IsExpired() {
+		c.logger.Debug("Cached report expired", "cache_key", key)
+		delete(c.entries, key)
+		return nil, nil
+	}
+
+	entry.HitCount++
+	entry.LastAccessed = time.Now()
+
+	// Callers get a copy, so later hits do not race with their reads
+	hit := *entry
+	return &hit, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, entry *ReportCache) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.entries[entry.Key]; !exists && len(c.entries) >= c.maxSize {
+		c.evictOldest()
+	}
+
+	cached := *entry
+	c.entries[entry.Key] = &cached
+	return nil
+}
+
+func (c *memoryCache) Flush(ctx context.Context) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count := len(c.entries)
+	c.entries = make(map[string]*ReportCache)
+	return count, nil
+}
+
+func (c *memoryCache) Stats(ctx context.Context) (CacheStats, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	stats := CacheStats{Entries: len(c.entries)}
+	now := time.Now()
+	for _, entry := range c.entries {
+		stats.TotalHits += entry.HitCount
+		if now.After(entry.ExpiresAt) {
+			stats.ExpiredEntries++
+		}
+	}
+	return stats, nil
+}
+
+func (c *memoryCache) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+
+// evictOldest removes the least recently accessed report; the caller holds the lock
+func (c *memoryCache) evictOldest() {
+	var oldestKey string
+	var oldestTime time.Time
+
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.LastAccessed.Before(oldestTime) {
+			oldestKey = key
+			oldestTime = entry.LastAccessed
+		}
+	}
+
+	if oldestKey != "" {
+		delete(c.entries, oldestKey)
+		c.logger.Debug("Evicted oldest cache entry", "cache_key", oldestKey)
+	}
+}
+
+// cleanupRoutine periodically removes expired reports until the cache is closed
+func (c *memoryCache) cleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanupExpired()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// cleanupExpired removes expired reports
+func (c *memoryCache) cleanupExpired() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var expiredKeys []string
+	now := time.Now()
+
+	for key, entry := range c.entries {
+		if now.After(entry.ExpiresAt) {
+			expiredKeys = append(expiredKeys, key)
+		}
+	}
+
+	for _, key := range expiredKeys {
+		delete(c.entries, key)
+	}
+
+	if len(expiredKeys) > 0 {
+		c.logger.Info("Cleaned up expired cache entries",
+			"removed_count", len(expiredKeys),
+			"remaining_count", len(c.entries))
+	}
+}
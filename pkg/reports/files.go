@@ -0,0 +1,85 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"easy-orders-backend/pkg/documents"
+	"easy-orders-backend/pkg/storage"
+)
+
+// ErrNoReportFile is returned when opening the file of a report that has none, such
+// as a JSON report or one that is not completed
+var ErrNoReportFile = errors.New("report has no file")
+
+// ReportFile is the rendered file of a report, to be read from Content or, for large
+// files in storage that signs URLs, downloaded from URL
+type ReportFile struct {
+	Content     io.ReadCloser
+	URL         string
+	Size        int64
+	ContentType string
+}
+
+// storeFile moves the rendered file of a result into storage, when there is one, so
+// the database only keeps its key
+func (rm *ReportManager) storeFile(ctx context.Context, req *ReportRequest, result *ReportResult) error {
+	if rm.storage == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("reports/%s.%s", result.ID, req.Format)
+	if err := rm.storage.Put(context.WithoutCancel(ctx), key, result.Content, documents.Format(req.Format).ContentType()); err != nil {
+		return fmt.Errorf("failed to store report file: %w", err)
+	}
+	result.FilePath = key
+	result.Content = nil
+	return nil
+}
+
+// OpenFile returns the rendered file of a result. Files in storage of at least the
+// signed URL threshold are returned as a signed URL when the storage can sign one.
+// The caller closes Content.
+func (rm *ReportManager) OpenFile(ctx context.Context, result *ReportResult) (*ReportFile, error) {
+	file := &ReportFile{
+		Size:        result.FileSize,
+		ContentType: documents.Format(result.Format).ContentType(),
+	}
+
+	if result.FilePath == "" {
+		if len(result.Content) == 0 {
+			return nil, ErrNoReportFile
+		}
+		file.Content = io.NopCloser(bytes.NewReader(result.Content))
+		file.Size = int64(len(result.Content))
+		return file, nil
+	}
+
+	if rm.storage == nil {
+		return nil, fmt.Errorf("report file %s is in storage, but none is configured", result.FilePath)
+	}
+
+	if rm.signedURLThreshold > 0 && result.FileSize >= rm.signedURLThreshold {
+		url, err := rm.storage.SignedURL(ctx, result.FilePath, rm.signedURLExpiry)
+		if err == nil {
+			file.URL = url
+			return file, nil
+		}
+		if !errors.Is(err, storage.ErrSignedURLsUnsupported) {
+			return nil, fmt.Errorf("failed to sign report file URL: %w", err)
+		}
+	}
+
+	content, err := rm.storage.Open(ctx, result.FilePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrNoReportFile
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report file: %w", err)
+	}
+	file.Content = content
+	return file, nil
+}
@@ -9,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/storage"
 )
 
 // ReportGenerator defines the interface for generating reports
@@ -30,17 +32,23 @@ type ReportGenerator interface {
 // ReportManager manages concurrent report generation with caching and scheduling
 type ReportManager struct {
 	generators    map[ReportType]ReportGenerator
-	cache         map[string]*ReportCache
-	cacheMutex    sync.RWMutex
+	layouts       map[ReportType]Layout
+	cache         Cache
+	cacheBackend  string
 	generatorPool chan struct{} // Semaphore for concurrent generation limit
 	metrics       *ReportMetrics
 	metricsMutex  sync.RWMutex
+	results       repository.ReportResultRepository // Stores async results, when set
+	storage       storage.Storage                   // Keeps rendered files, when set
+	onCompletion  CompletionHandler                 // Told about finished async reports, when set
 	logger        *logger.Logger
 
 	// Configuration
 	maxConcurrentReports int
 	defaultCacheTTL      time.Duration
 	maxCacheSize         int
+	signedURLThreshold   int64
+	signedURLExpiry      time.Duration
 }
 
 // ReportManagerConfig configures the report manager
@@ -49,6 +57,20 @@ type ReportManagerConfig struct {
 	DefaultCacheTTL      time.Duration `json:"default_cache_ttl"`
 	MaxCacheSize         int           `json:"max_cache_size"`
 	CleanupInterval      time.Duration `json:"cleanup_interval"`
+
+	// CacheBackend is where reports are cached: CacheBackendMemory keeps them in this
+	// process, CacheBackendRedis shares them between instances through Redis. Reports
+	// expire at the same time either way; MaxCacheSize and CleanupInterval only apply
+	// to the memory cache.
+	CacheBackend string            `json:"cache_backend"`
+	Redis        *RedisCacheConfig `json:"redis,omitempty"` // Required by the Redis backend
+
+	// Storage keeps rendered report files outside the database when set. Files of at
+	// least SignedURLThreshold bytes are downloaded from a URL signed for
+	// SignedURLExpiry when the storage can sign one; zero never signs URLs.
+	Storage            storage.Storage `json:"-"`
+	SignedURLThreshold int64           `json:"signed_url_threshold"`
+	SignedURLExpiry    time.Duration   `json:"signed_url_expiry"`
 }
 
 // DefaultReportManagerConfig returns default configuration
@@ -58,32 +80,64 @@ func DefaultReportManagerConfig() *ReportManagerConfig {
 		DefaultCacheTTL:      30 * time.Minute,
 		MaxCacheSize:         1000,
 		CleanupInterval:      time.Hour,
+		CacheBackend:         CacheBackendMemory,
+		SignedURLThreshold:   5 * 1024 * 1024,
+		SignedURLExpiry:      15 * time.Minute,
 	}
 }
 
-// NewReportManager creates a new report manager
-func NewReportManager(config *ReportManagerConfig, logger *logger.Logger) *ReportManager {
+// NewReportManager creates a new report manager. Async results are stored with results
+// so they can be polled; without it they are only kept in memory.
+func NewReportManager(config *ReportManagerConfig, results repository.ReportResultRepository, logger *logger.Logger) *ReportManager {
 	if config == nil {
 		config = DefaultReportManagerConfig()
 	}
 
 	rm := &ReportManager{
 		generators:           make(map[ReportType]ReportGenerator),
-		cache:                make(map[string]*ReportCache),
+		layouts:              defaultLayouts(),
 		generatorPool:        make(chan struct{}, config.MaxConcurrentReports),
 		metrics:              &ReportMetrics{},
+		results:              results,
+		storage:              config.Storage,
 		logger:               logger,
 		maxConcurrentReports: config.MaxConcurrentReports,
 		defaultCacheTTL:      config.DefaultCacheTTL,
 		maxCacheSize:         config.MaxCacheSize,
+		signedURLThreshold:   config.SignedURLThreshold,
+		signedURLExpiry:      config.SignedURLExpiry,
 	}
 
-	// Start a cache cleanup routine
-	go rm.cacheCleanupRoutine(config.CleanupInterval)
+	switch {
+	case config.CacheBackend == CacheBackendRedis && config.Redis != nil:
+		rm.cache = NewRedisCache(*config.Redis)
+		rm.cacheBackend = CacheBackendRedis
+	default:
+		if config.CacheBackend != "" && config.CacheBackend != CacheBackendMemory {
+			logger.Warn("Unusable report cache backend, caching in memory", "backend", config.CacheBackend)
+		}
+		rm.cache = NewMemoryCache(config.MaxCacheSize, config.CleanupInterval, logger)
+		rm.cacheBackend = CacheBackendMemory
+	}
 
 	return rm
 }
 
+// Close releases the report cache
+func (rm *ReportManager) Close() error {
+	return rm.cache.Close()
+}
+
+// CompletionHandler is told about each report generated in the background once it
+// has completed or failed, after its stored result was updated
+type CompletionHandler func(req *ReportRequest, result *ReportResult)
+
+// OnCompletion sets the handler told about finished async reports. It is set while
+// wiring the manager, before reports are queued.
+func (rm *ReportManager) OnCompletion(handler CompletionHandler) {
+	rm.onCompletion = handler
+}
+
 // RegisterGenerator registers a report generator for specific report types
 func (rm *ReportManager) RegisterGenerator(generator ReportGenerator) {
 	supportedTypes := generator.GetSupportedTypes()
@@ -95,7 +149,8 @@ func (rm *ReportManager) RegisterGenerator(generator ReportGenerator) {
 	}
 }
 
-// GenerateReportAsync generates a report asynchronously
+// GenerateReportAsync generates a report asynchronously. The returned result is a
+// snapshot of the queued report; poll GetResult for its progress.
 func (rm *ReportManager) GenerateReportAsync(ctx context.Context, req *ReportRequest) (*ReportResult, error) {
 	rm.logger.Info("Starting async report generation",
 		"id", req.ID,
@@ -103,8 +158,13 @@ func (rm *ReportManager) GenerateReportAsync(ctx context.Context, req *ReportReq
 		"format", string(req.Format),
 		"priority", req.Priority)
 
+	// Reject unknown timezones and locales before queueing the request
+	if _, err := req.Localizer(); err != nil {
+		return nil, fmt.Errorf("invalid report request: %w", err)
+	}
+
 	// Check cache first
-	if cachedResult := rm.checkCache(req); cachedResult != nil {
+	if cachedResult := rm.checkCache(ctx, req); cachedResult != nil {
 		rm.logger.Debug("Report served from cache", "id", req.ID, "cache_key", rm.generateCacheKey(req))
 		rm.updateMetrics(func(m *ReportMetrics) {
 			// Update cache hit rate
@@ -112,6 +172,9 @@ func (rm *ReportManager) GenerateReportAsync(ctx context.Context, req *ReportReq
 			hitRate := (m.CacheHitRate*float64(m.TotalReports) + 1.0) / float64(totalRequests)
 			m.CacheHitRate = hitRate
 		})
+		if err := rm.storeResult(ctx, req, cachedResult); err != nil {
+			return nil, err
+		}
 		return cachedResult, nil
 	}
 
@@ -124,6 +187,9 @@ func (rm *ReportManager) GenerateReportAsync(ctx context.Context, req *ReportReq
 		Status:    ReportStatusPending,
 		Metadata:  make(map[string]interface{}),
 	}
+	if err := rm.storeResult(ctx, req, result); err != nil {
+		return nil, err
+	}
 
 	// Update metrics
 	rm.updateMetrics(func(m *ReportMetrics) {
@@ -132,8 +198,10 @@ func (rm *ReportManager) GenerateReportAsync(ctx context.Context, req *ReportReq
 		m.QueueDepth++
 	})
 
-	// Generate report in goroutine
-	go rm.generateReportConcurrent(ctx, req, result)
+	// Generate report in goroutine, on its own copy of the result
+	queued := *result
+	queued.Metadata = make(map[string]interface{})
+	go rm.generateReportConcurrent(ctx, req, &queued)
 
 	return result, nil
 }
@@ -144,8 +212,12 @@ func (rm *ReportManager) GenerateReportSync(ctx context.Context, req *ReportRequ
 		"id", req.ID,
 		"type", string(req.Type))
 
+	if _, err := req.Localizer(); err != nil {
+		return nil, fmt.Errorf("invalid report request: %w", err)
+	}
+
 	// Check cache first
-	if cachedResult := rm.checkCache(req); cachedResult != nil {
+	if cachedResult := rm.checkCache(ctx, req); cachedResult != nil {
 		rm.logger.Debug("Report served from cache", "id", req.ID)
 		return cachedResult, nil
 	}
@@ -194,7 +266,7 @@ func (rm *ReportManager) GenerateReportSync(ctx context.Context, req *ReportRequ
 	}
 
 	// Cache the result
-	rm.cacheResult(req, result)
+	rm.cacheResult(ctx, req, result)
 
 	rm.logger.Info("Sync report generation completed",
 		"id", req.ID,
@@ -212,11 +284,13 @@ func (rm *ReportManager) generateReportConcurrent(ctx context.Context, req *Repo
 	case <-ctx.Done():
 		result.Status = ReportStatusCancelled
 		result.Error = "Context cancelled while waiting for generator slot"
+		rm.transitionResult(ctx, result, ReportStatusPending)
 		return
 	}
 
 	// Update status and metrics
 	result.Status = ReportStatusGenerating
+	rm.transitionResult(ctx, result, ReportStatusPending)
 	rm.updateMetrics(func(m *ReportMetrics) {
 		m.PendingReports--
 		m.ActiveGenerators++
@@ -247,15 +321,26 @@ func (rm *ReportManager) generateReportConcurrent(ctx context.Context, req *Repo
 		result.Status = ReportStatusFailed
 		result.Error = err.Error()
 		rm.logger.Error("Async report generation failed", "id", req.ID, "error", err)
+		rm.transitionResult(ctx, result, ReportStatusGenerating)
+		rm.notifyCompletion(req, result)
 		return
 	}
+	rm.transitionResult(ctx, result, ReportStatusGenerating)
 
 	// Cache the result
-	rm.cacheResult(req, result)
+	rm.cacheResult(ctx, req, result)
 
 	rm.logger.Info("Async report generation completed",
 		"id", req.ID,
 		"duration_ms", duration.Milliseconds())
+	rm.notifyCompletion(req, result)
+}
+
+// notifyCompletion tells the completion handler, if any, about a finished report
+func (rm *ReportManager) notifyCompletion(req *ReportRequest, result *ReportResult) {
+	if rm.onCompletion != nil {
+		rm.onCompletion(req, result)
+	}
 }
 
 // generateReport performs the actual report generation
@@ -282,6 +367,21 @@ func (rm *ReportManager) generateReport(ctx context.Context, req *ReportRequest,
 	result.FilePath = generatedResult.FilePath
 	result.FileSize = generatedResult.FileSize
 	result.RowCount = generatedResult.RowCount
+
+	// Render the report for download in its format
+	if req.Format == ReportFormatPDF {
+		content, err := rm.RenderReport(result)
+		if err != nil {
+			return fmt.Errorf("render failed: %w", err)
+		}
+		result.Content = content
+		result.FileSize = int64(len(content))
+
+		if err := rm.storeFile(ctx, req, result); err != nil {
+			return err
+		}
+	}
+
 	result.Status = ReportStatusCompleted
 	now := time.Now()
 	result.GeneratedAt = &now
@@ -311,31 +411,20 @@ func (rm *ReportManager) generateReport(ctx context.Context, req *ReportRequest,
 	return nil
 }
 
-// checkCache checks if a cached result exists for the request
-func (rm *ReportManager) checkCache(req *ReportRequest) *ReportResult {
+// checkCache checks if a cached result exists for the request. The cache is an
+// optimization, so a failing cache is logged and treated as a miss.
+func (rm *ReportManager) checkCache(ctx context.Context, req *ReportRequest) *ReportResult {
 	cacheKey := rm.generateCacheKey(req)
 
-	rm.cacheMutex.RLock()
-	cachedReport, exists := rm.cache[cacheKey]
-	rm.cacheMutex.RUnlock()
-
-	if !exists {
+	cachedReport, err := rm.cache.Get(ctx, cacheKey)
+	if err != nil {
+		rm.logger.Warn("Failed to read report cache", "error", err, "cache_key", cacheKey)
 		return nil
 	}
-
-	// Check if the cache is expired
-	if cachedReport.IsExpired() {
-		rm.logger.Debug("Cached report expired", "cache_key", cacheKey)
-		rm.removeCacheEntry(cacheKey)
+	if cachedReport == nil {
 		return nil
 	}
 
-	// Update cache access statistics
-	rm.cacheMutex.Lock()
-	cachedReport.HitCount++
-	cachedReport.LastAccessed = time.Now()
-	rm.cacheMutex.Unlock()
-
 	// Convert cached data to the result format
 	result := &ReportResult{
 		ID:             fmt.Sprintf("cached_%s_%d", req.ID, time.Now().UnixNano()),
@@ -344,6 +433,9 @@ func (rm *ReportManager) checkCache(req *ReportRequest) *ReportResult {
 		Format:         req.Format,
 		Status:         ReportStatusCompleted,
 		Data:           cachedReport.Data,
+		Content:        cachedReport.Content,
+		FilePath:       cachedReport.FilePath,
+		FileSize:       cachedReport.FileSize,
 		GeneratedAt:    &cachedReport.GeneratedAt,
 		ExpiresAt:      &cachedReport.ExpiresAt,
 		ProcessingTime: 0, // Cached result
@@ -359,7 +451,7 @@ func (rm *ReportManager) checkCache(req *ReportRequest) *ReportResult {
 }
 
 // cacheResult stores a generated report result in cache
-func (rm *ReportManager) cacheResult(req *ReportRequest, result *ReportResult) {
+func (rm *ReportManager) cacheResult(ctx context.Context, req *ReportRequest, result *ReportResult) {
 	if result.Status != ReportStatusCompleted || result.Data == nil {
 		return
 	}
@@ -375,22 +467,20 @@ func (rm *ReportManager) cacheResult(req *ReportRequest, result *ReportResult) {
 		ReportType:   req.Type,
 		Parameters:   req.Parameters,
 		Data:         result.Data,
+		Content:      result.Content,
+		FilePath:     result.FilePath,
+		FileSize:     result.FileSize,
 		GeneratedAt:  time.Now(),
 		ExpiresAt:    expiresAt,
 		HitCount:     0,
 		LastAccessed: time.Now(),
 	}
 
-	rm.cacheMutex.Lock()
-	defer rm.cacheMutex.Unlock()
-
-	// Check cache size limit
-	if len(rm.cache) >= rm.maxCacheSize {
-		rm.evictOldestCache()
+	if err := rm.cache.Set(context.WithoutCancel(ctx), cache); err != nil {
+		rm.logger.Warn("Failed to cache report", "error", err, "cache_key", cacheKey)
+		return
 	}
 
-	rm.cache[cacheKey] = cache
-
 	rm.logger.Debug("Report cached",
 		"cache_key", cacheKey,
 		"type", string(req.Type),
@@ -404,6 +494,8 @@ func (rm *ReportManager) generateCacheKey(req *ReportRequest) string {
 		"type":       string(req.Type),
 		"format":     string(req.Format),
 		"parameters": req.Parameters,
+		"timezone":   req.Timezone,
+		"locale":     req.Locale,
 	}
 
 	// Serialize to JSON for consistent hashing
@@ -412,66 +504,6 @@ func (rm *ReportManager) generateCacheKey(req *ReportRequest) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// removeCacheEntry removes a cache entry
-func (rm *ReportManager) removeCacheEntry(key string) {
-	rm.cacheMutex.Lock()
-	defer rm.cacheMutex.Unlock()
-	delete(rm.cache, key)
-}
-
-// evictOldestCache removes the oldest cache entry
-func (rm *ReportManager) evictOldestCache() {
-	var oldestKey string
-	var oldestTime time.Time
-
-	for key, cache := range rm.cache {
-		if oldestKey == "" || cache.LastAccessed.Before(oldestTime) {
-			oldestKey = key
-			oldestTime = cache.LastAccessed
-		}
-	}
-
-	if oldestKey != "" {
-		delete(rm.cache, oldestKey)
-		rm.logger.Debug("Evicted oldest cache entry", "cache_key", oldestKey)
-	}
-}
-
-// cacheCleanupRoutine periodically cleans up expired cache entries
-func (rm *ReportManager) cacheCleanupRoutine(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		rm.cleanupExpiredCache()
-	}
-}
-
-// cleanupExpiredCache removes expired cache entries
-func (rm *ReportManager) cleanupExpiredCache() {
-	rm.cacheMutex.Lock()
-	defer rm.cacheMutex.Unlock()
-
-	var expiredKeys []string
-	now := time.Now()
-
-	for key, cache := range rm.cache {
-		if now.After(cache.ExpiresAt) {
-			expiredKeys = append(expiredKeys, key)
-		}
-	}
-
-	for _, key := range expiredKeys {
-		delete(rm.cache, key)
-	}
-
-	if len(expiredKeys) > 0 {
-		rm.logger.Info("Cleaned up expired cache entries",
-			"removed_count", len(expiredKeys),
-			"remaining_count", len(rm.cache))
-	}
-}
-
 // GetMetrics returns current report generation metrics
 func (rm *ReportManager) GetMetrics() *ReportMetrics {
 	rm.metricsMutex.RLock()
@@ -492,39 +524,31 @@ func (rm *ReportManager) updateMetrics(updateFunc func(*ReportMetrics)) {
 }
 
 // GetCacheStats returns cache statistics
-func (rm *ReportManager) GetCacheStats() map[string]interface{} {
-	rm.cacheMutex.RLock()
-	defer rm.cacheMutex.RUnlock()
-
-	totalHits := 0
-	expiredCount := 0
-	now := time.Now()
-
-	for _, cache := range rm.cache {
-		totalHits += cache.HitCount
-		if now.After(cache.ExpiresAt) {
-			expiredCount++
-		}
+func (rm *ReportManager) GetCacheStats(ctx context.Context) (map[string]interface{}, error) {
+	stats, err := rm.cache.Stats(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	return map[string]interface{}{
-		"total_entries":   len(rm.cache),
-		"expired_entries": expiredCount,
-		"total_hits":      totalHits,
+		"backend":         rm.cacheBackend,
+		"total_entries":   stats.Entries,
+		"expired_entries": stats.ExpiredEntries,
+		"total_hits":      stats.TotalHits,
 		"max_size":        rm.maxCacheSize,
 		"default_ttl":     rm.defaultCacheTTL.String(),
-	}
+	}, nil
 }
 
 // FlushCache clears all cached reports
-func (rm *ReportManager) FlushCache() {
-	rm.cacheMutex.Lock()
-	defer rm.cacheMutex.Unlock()
-
-	cacheCount := len(rm.cache)
-	rm.cache = make(map[string]*ReportCache)
+func (rm *ReportManager) FlushCache(ctx context.Context) error {
+	cacheCount, err := rm.cache.Flush(ctx)
+	if err != nil {
+		return err
+	}
 
 	rm.logger.Info("Cache flushed", "cleared_entries", cacheCount)
+	return nil
 }
 
 // GetSupportedTypes returns all supported report types
@@ -0,0 +1,193 @@
+package reports
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimezone is used when a report request does not set a timezone
+	DefaultTimezone = "UTC"
+	// DefaultLocale is used when a report request does not set a locale
+	DefaultLocale = "en-US"
+
+	// reportCurrency is the currency report amounts are expressed in
+	reportCurrency = "USD"
+)
+
+// localeFormat describes how dates and amounts are written in a locale
+type localeFormat struct {
+	DateLayout        string
+	MonthLayout       string
+	DecimalSeparator  string
+	GroupSeparator    string
+	SymbolAfterAmount bool
+}
+
+// localeFormats lists the locales reports can be rendered in
+var localeFormats = map[string]localeFormat{
+	"en-US": {DateLayout: "01/02/2006", MonthLayout: "January 2006", DecimalSeparator: ".", GroupSeparator: ","},
+	"en-GB": {DateLayout: "02/01/2006", MonthLayout: "January 2006", DecimalSeparator: ".", GroupSeparator: ","},
+	"de-DE": {DateLayout: "02.01.2006", MonthLayout: "01.2006", DecimalSeparator: ",", GroupSeparator: ".", SymbolAfterAmount: true},
+	"fr-FR": {DateLayout: "02/01/2006", MonthLayout: "01/2006", DecimalSeparator: ",", GroupSeparator: " ", SymbolAfterAmount: true},
+	"ja-JP": {DateLayout: "2006/01/02", MonthLayout: "2006/01", DecimalSeparator: ".", GroupSeparator: ","},
+}
+
+// currencySymbols maps ISO currency codes to their display symbol
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// currencyDecimals overrides the number of minor digits for currencies without cents
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+}
+
+// Localizer computes period boundaries in the requester's timezone and
+// formats dates and amounts for the requester's locale
+type Localizer struct {
+	location *time.Location
+	locale   string
+	format   localeFormat
+}
+
+// NewLocalizer creates a localizer for an IANA timezone and a locale tag.
+// Empty values fall back to DefaultTimezone and DefaultLocale.
+func NewLocalizer(timezone, locale string) (*Localizer, error) {
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	if locale == "" {
+		locale = DefaultLocale
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported timezone %q", timezone)
+	}
+
+	format, ok := localeFormats[locale]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	return &Localizer{
+		location: location,
+		locale:   locale,
+		format:   format,
+	}, nil
+}
+
+// Localizer returns the localizer for the request's timezone and locale
+func (req *ReportRequest) Localizer() (*Localizer, error) {
+	return NewLocalizer(req.Timezone, req.Locale)
+}
+
+// Location returns the timezone report periods are computed in
+func (l *Localizer) Location() *time.Location {
+	return l.location
+}
+
+// Timezone returns the IANA name of the report timezone
+func (l *Localizer) Timezone() string {
+	return l.location.String()
+}
+
+// Locale returns the locale tag reports are formatted for
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// Now returns the current time in the report timezone
+func (l *Localizer) Now() time.Time {
+	return time.Now().In(l.location)
+}
+
+// StartOfDay returns local midnight of the day containing t
+func (l *Localizer) StartOfDay(t time.Time) time.Time {
+	t = t.In(l.location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, l.location)
+}
+
+// ParseDate parses a YYYY-MM-DD date as local midnight in the report timezone
+func (l *Localizer) ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", value, l.location)
+}
+
+// FormatDate formats a date for the report locale
+func (l *Localizer) FormatDate(t time.Time) string {
+	return t.In(l.location).Format(l.format.DateLayout)
+}
+
+// FormatMonth formats a month label for the report locale
+func (l *Localizer) FormatMonth(t time.Time) string {
+	return t.In(l.location).Format(l.format.MonthLayout)
+}
+
+// FormatCurrency formats an amount with grouping, decimal separator and
+// currency symbol placement for the report locale
+func (l *Localizer) FormatCurrency(amount float64, currency string) string {
+	decimals, ok := currencyDecimals[currency]
+	if !ok {
+		decimals = 2
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = math.Abs(amount)
+	}
+
+	digits := strconv.FormatFloat(amount, 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(l.format.GroupSeparator)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	number := grouped.String()
+	if fraction != "" {
+		number += l.format.DecimalSeparator + fraction
+	}
+
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+
+	if l.format.SymbolAfterAmount {
+		return sign + number + " " + symbol
+	}
+	return sign + symbol + number
+}
+
+// applyLocalization labels a sales report with its timezone and locale and
+// fills the locale-formatted display values
+func applyLocalization(report *SalesReportData, localizer *Localizer) {
+	report.Timezone = localizer.Timezone()
+	report.Locale = localizer.Locale()
+
+	for i := range report.SalesByDay {
+		report.SalesByDay[i].Label = localizer.FormatDate(report.SalesByDay[i].Date)
+	}
+
+	report.Display = map[string]string{
+		"start_date":          localizer.FormatDate(report.StartDate),
+		"end_date":            localizer.FormatDate(report.EndDate.AddDate(0, 0, -1)), // EndDate is exclusive
+		"total_revenue":       localizer.FormatCurrency(report.TotalRevenue, reportCurrency),
+		"net_revenue":         localizer.FormatCurrency(report.NetRevenue, reportCurrency),
+		"refunded_amount":     localizer.FormatCurrency(report.RefundedAmount, reportCurrency),
+		"gross_margin":        localizer.FormatCurrency(report.GrossMargin, reportCurrency),
+		"average_order_value": localizer.FormatCurrency(report.AverageOrderValue, reportCurrency),
+	}
+}
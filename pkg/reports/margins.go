@@ -0,0 +1,71 @@
+package reports
+
+// DefaultLowMarginThreshold is the margin percentage below which products are
+// flagged when the request does not set a "low_margin_threshold" parameter
+const DefaultLowMarginThreshold = 20.0
+
+// lowMarginThreshold reads the alert threshold from report parameters
+func lowMarginThreshold(params map[string]interface{}) float64 {
+	if threshold, ok := params["low_margin_threshold"].(float64); ok && threshold > 0 {
+		return threshold
+	}
+	return DefaultLowMarginThreshold
+}
+
+// marginPercent returns margin as a percentage of revenue, or zero without revenue
+func marginPercent(revenue, margin float64) float64 {
+	if revenue <= 0 {
+		return 0
+	}
+	return margin / revenue * 100
+}
+
+// applyProductMargins computes cost of goods and margin on net revenue for each
+// product at its unit cost and returns the IDs of products below the threshold.
+// Returned units go back to stock, so only kept units carry a cost. Returns must
+// be applied first.
+func applyProductMargins(products []ProductSalesData, threshold float64) []string {
+	lowMargin := []string{}
+	for i := range products {
+		product := &products[i]
+		product.CostOfGoods = product.UnitCost * float64(product.Quantity-product.ReturnedQuantity)
+		product.GrossMargin = product.NetRevenue - product.CostOfGoods
+		product.MarginPercent = marginPercent(product.NetRevenue, product.GrossMargin)
+		product.LowMargin = product.NetRevenue > 0 && product.MarginPercent < threshold
+		if product.LowMargin {
+			lowMargin = append(lowMargin, product.ProductID)
+		}
+	}
+	return lowMargin
+}
+
+// applyCategoryMargins computes the margin of each category from its cost of goods
+func applyCategoryMargins(categories []CategoryData) {
+	for i := range categories {
+		category := &categories[i]
+		category.GrossMargin = category.Revenue - category.CostOfGoods
+		category.MarginPercent = marginPercent(category.Revenue, category.GrossMargin)
+	}
+}
+
+// applyMargins fills the margin columns of a sales report from every product sold
+// in the period, which may be more than the report lists as top products. Returns
+// must be applied first.
+func applyMargins(report *SalesReportData, products []ProductSalesData, threshold float64) {
+	report.LowMarginProducts = applyProductMargins(products, threshold)
+
+	report.CostOfGoods = 0
+	for _, product := range products {
+		report.CostOfGoods += product.CostOfGoods
+	}
+	report.GrossMargin = report.NetRevenue - report.CostOfGoods
+	report.MarginPercent = marginPercent(report.NetRevenue, report.GrossMargin)
+
+	if report.Summary == nil {
+		report.Summary = make(map[string]interface{})
+	}
+	report.Summary["gross_margin"] = report.GrossMargin
+	report.Summary["margin_percent"] = report.MarginPercent
+	report.Summary["low_margin_threshold"] = threshold
+	report.Summary["low_margin_products"] = len(report.LowMarginProducts)
+}
@@ -0,0 +1,196 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCacheConfig locates the Redis server shared by the report caches of all instances
+type RedisCacheConfig struct {
+	Addr      string `json:"addr"`
+	Password  string `json:"-"`
+	DB        int    `json:"db"`
+	KeyPrefix string `json:"key_prefix"` // Namespaces the report keys in a shared database
+}
+
+// defaultRedisKeyPrefix namespaces the report keys when no prefix is configured
+const defaultRedisKeyPrefix = "reports:cache:"
+
+// redisScanBatch is how many keys each SCAN step asks Redis for
+const redisScanBatch = 500
+
+// redisHitScript returns a cached report and counts the hit, without recreating a
+// report that expired between the lookup and the update
+var redisHitScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+redis.call('HINCRBY', KEYS[1], 'hit_count', 1)
+redis.call('HSET', KEYS[1], 'last_accessed', ARGV[1])
+return redis.call('HGETALL', KEYS[1])
+`)
+
+// redisCache caches reports in Redis so every instance serves the reports any of them
+// generated. Each report is a hash Redis expires at the report's ExpiresAt; when the
+// server runs out of memory its eviction policy decides which reports go, so the
+// manager's cache size limit does not apply.
+type redisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache creates a cache of reports in the configured Redis database
+func NewRedisCache(config RedisCacheConfig) Cache {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = defaultRedisKeyPrefix
+	}
+
+	return &redisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:     config.Addr,
+			Password: config.Password,
+			DB:       config.DB,
+		}),
+		prefix: config.KeyPrefix,
+	}
+}
+
+func (c *redisCache) Get(ctx context.Context, key string) (*ReportCache, error) {
+	now := time.Now()
+	fields, err := redisHitScript.Run(ctx, c.client, []string{c.prefix + key}, now.Format(time.RFC3339Nano)).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached report: %w", err)
+	}
+
+	values := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		values[fields[i]] = fields[i+1]
+	}
+
+	entry := &ReportCache{
+		Key:        key,
+		ReportType: ReportType(values["report_type"]),
+	}
+	if data := values["data"]; data != "" && data != "null" {
+		// Like stored results, cached reports come back as JSON
+		entry.Data = json.RawMessage(data)
+	}
+	if content := values["content"]; content != "" {
+		entry.Content = []byte(content)
+	}
+	entry.FilePath = values["file_path"]
+	entry.FileSize, _ = strconv.ParseInt(values["file_size"], 10, 64)
+	entry.HitCount, _ = strconv.Atoi(values["hit_count"])
+	entry.GeneratedAt, _ = time.Parse(time.RFC3339Nano, values["generated_at"])
+	entry.ExpiresAt, _ = time.Parse(time.RFC3339Nano, values["expires_at"])
+	entry.LastAccessed, _ = time.Parse(time.RFC3339Nano, values["last_accessed"])
+
+	// Redis expires keys in the background; a report past its expiry is not served
+	if entry.IsExpired() {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, entry *ReportCache) error {
+	data, err := json.Marshal(entry.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode cached report: %w", err)
+	}
+
+	key := c.prefix + entry.Key
+	pipe := c.client.TxPipeline()
+	// Replacing a report starts its hit count over
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key,
+		"report_type", string(entry.ReportType),
+		"data", data,
+		"content", entry.Content,
+		"file_path", entry.FilePath,
+		"file_size", entry.FileSize,
+		"generated_at", entry.GeneratedAt.Format(time.RFC3339Nano),
+		"expires_at", entry.ExpiresAt.Format(time.RFC3339Nano),
+		"hit_count", entry.HitCount,
+		"last_accessed", entry.LastAccessed.Format(time.RFC3339Nano),
+	)
+	pipe.PExpireAt(ctx, key, entry.ExpiresAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache report: %w", err)
+	}
+	return nil
+}
+
+func (c *redisCache) Flush(ctx context.Context) (int, error) {
+	removed := 0
+	err := c.scan(ctx, func(keys []string) error {
+		count, err := c.client.Del(ctx, keys...).Result()
+		removed += int(count)
+		return err
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to flush report cache: %w", err)
+	}
+	return removed, nil
+}
+
+func (c *redisCache) Stats(ctx context.Context) (CacheStats, error) {
+	var stats CacheStats
+	err := c.scan(ctx, func(keys []string) error {
+		pipe := c.client.Pipeline()
+		hits := make([]*redis.StringCmd, len(keys))
+		for i, key := range keys {
+			hits[i] = pipe.HGet(ctx, key, "hit_count")
+		}
+		// Reports expiring during the scan have no hit count left
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		for _, hit := range hits {
+			count, err := hit.Int()
+			if err != nil {
+				continue
+			}
+			stats.Entries++
+			stats.TotalHits += count
+		}
+		return nil
+	})
+	if err != nil {
+		return CacheStats{}, fmt.Errorf("failed to get report cache stats: %w", err)
+	}
+	return stats, nil
+}
+
+func (c *redisCache) Close() error {
+	return c.client.Close()
+}
+
+// scan calls fn with each batch of report keys
+func (c *redisCache) scan(ctx context.Context, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.prefix+"*", redisScanBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
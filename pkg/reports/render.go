@@ -0,0 +1,434 @@
+package reports
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"easy-orders-backend/pkg/documents"
+)
+
+// Layout lays out the data of a completed report as a printable document: a summary
+// of its totals followed by a section per breakdown
+type Layout func(result *ReportResult) (*documents.Document, error)
+
+// defaultLayouts returns the layouts of the report types generated in this package
+func defaultLayouts() map[ReportType]Layout {
+	return map[ReportType]Layout{
+		ReportTypeDailySales:       salesLayout,
+		ReportTypeWeeklySales:      salesLayout,
+		ReportTypeMonthlySales:     salesLayout,
+		ReportTypeRevenue:          salesLayout,
+		ReportTypeTopProducts:      topProductsLayout,
+		ReportTypeLowStock:         lowStockLayout,
+		ReportTypeInventoryValue:   inventoryValueLayout,
+		ReportTypeCustomerActivity: customerActivityLayout,
+		ReportTypeOrderAnalytics:   orderAnalyticsLayout,
+
+		ReportTypePaymentReconciliation: reconciliationLayout,
+	}
+}
+
+// RegisterLayout sets the layout reports of the type are rendered with, replacing
+// the built-in one
+func (rm *ReportManager) RegisterLayout(reportType ReportType, layout Layout) {
+	rm.layouts[reportType] = layout
+	rm.logger.Info("Report layout registered", "type", string(reportType))
+}
+
+// RenderReport renders a completed report in its format. Only PDF is rendered here;
+// JSON reports are served as their data.
+func (rm *ReportManager) RenderReport(result *ReportResult) ([]byte, error) {
+	if result.Format != ReportFormatPDF {
+		return nil, fmt.Errorf("unsupported report format: %s", result.Format)
+	}
+
+	layout, exists := rm.layouts[result.Type]
+	if !exists {
+		return nil, fmt.Errorf("no layout found for report type: %s", result.Type)
+	}
+
+	doc, err := layout(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lay out %s report: %w", result.Type, err)
+	}
+	return documents.RenderPDF(doc), nil
+}
+
+// reportTitles are the document titles of the report types
+var reportTitles = map[ReportType]string{
+	ReportTypeDailySales:       "Daily Sales Report",
+	ReportTypeWeeklySales:      "Weekly Sales Report",
+	ReportTypeMonthlySales:     "Monthly Sales Report",
+	ReportTypeRevenue:          "Revenue Report",
+	ReportTypeTopProducts:      "Top Products Report",
+	ReportTypeLowStock:         "Low Stock Report",
+	ReportTypeInventoryValue:   "Inventory Value Report",
+	ReportTypeCustomerActivity: "Customer Activity Report",
+	ReportTypeOrderAnalytics:   "Order Analytics Report",
+
+	ReportTypePaymentReconciliation: "Payment Reconciliation Report",
+}
+
+// unexpectedData reports report data of another type than the layout lays out
+func unexpectedData(result *ReportResult) error {
+	return fmt.Errorf("unexpected %T data for %s report", result.Data, result.Type)
+}
+
+// salesLayout lays out sales and revenue reports: totals, then sales by day, products,
+// payment methods and customer segments
+func salesLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*SalesReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	// Dates and amounts are printed as localized when the report was generated
+	display := func(key, fallback string) string {
+		if value, ok := report.Display[key]; ok {
+			return value
+		}
+		return fallback
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Period", Value: display("start_date", formatReportDate(report.StartDate)) + " - " +
+				display("end_date", formatReportDate(report.EndDate.AddDate(0, 0, -1)))},
+			{Label: "Time zone", Value: report.Timezone},
+			{Label: "Orders", Value: strconv.Itoa(report.TotalOrders)},
+			{Label: "Gross revenue", Value: display("total_revenue", formatAmount(report.GrossRevenue))},
+			{Label: "Refunded", Value: display("refunded_amount", formatAmount(report.RefundedAmount))},
+			{Label: "Net revenue", Value: display("net_revenue", formatAmount(report.NetRevenue))},
+			{Label: "Gross margin", Value: display("gross_margin", formatAmount(report.GrossMargin)) + " (" + formatPercent(report.MarginPercent) + ")"},
+			{Label: "Average order value", Value: display("average_order_value", formatAmount(report.AverageOrderValue))},
+			{Label: "Return rate", Value: formatRate(report.ReturnRate)},
+		},
+	}
+
+	days := documents.Section{Title: "Sales by Day", Table: documents.Table{
+		Columns: []string{"Date", "Orders", "Customers", "Revenue", "Refunded", "Net", "Avg order"},
+	}}
+	for _, day := range report.SalesByDay {
+		days.Table.Rows = append(days.Table.Rows, []string{
+			valueOrDefault(day.Label, formatReportDate(day.Date)), strconv.Itoa(day.OrderCount), strconv.Itoa(day.CustomerCount),
+			formatAmount(day.Revenue), formatAmount(day.RefundedAmount), formatAmount(day.NetRevenue), formatAmount(day.AvgOrderValue),
+		})
+	}
+	days.Table.Footer = []string{"Total", strconv.Itoa(report.TotalOrders), "",
+		formatAmount(report.GrossRevenue), formatAmount(report.RefundedAmount), formatAmount(report.NetRevenue), ""}
+
+	methods := documents.Section{Title: "Payment Methods", Table: documents.Table{
+		Columns: []string{"Method", "Orders", "Revenue", "Share", "Avg order"},
+	}}
+	for _, method := range report.PaymentMethods {
+		methods.Table.Rows = append(methods.Table.Rows, []string{
+			method.Method, strconv.Itoa(method.OrderCount), formatAmount(method.Revenue), formatPercent(method.Percentage), formatAmount(method.AvgOrderValue),
+		})
+	}
+
+	segments := documents.Section{Title: "Customer Segments", Table: documents.Table{
+		Columns: []string{"Segment", "Customers", "Orders", "Revenue", "Share", "Avg order"},
+	}}
+	for _, segment := range report.CustomerSegments {
+		segments.Table.Rows = append(segments.Table.Rows, []string{
+			segment.Segment, strconv.Itoa(segment.CustomerCount), strconv.Itoa(segment.OrderCount),
+			formatAmount(segment.Revenue), formatPercent(segment.Percentage), formatAmount(segment.AvgOrderValue),
+		})
+	}
+
+	doc.Sections = []documents.Section{days, productSection(report.TopProducts), methods, segments}
+	doc.Notes = lowMarginNote(report.LowMarginProducts)
+	return doc, nil
+}
+
+// topProductsLayout lays out the ranked products and their categories
+func topProductsLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*TopProductsReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Period", Value: formatReportDate(report.StartDate) + " - " + formatReportDate(report.EndDate.AddDate(0, 0, -1))},
+			{Label: "Time zone", Value: report.Timezone},
+		},
+	}
+
+	categories := documents.Section{Title: "Categories", Table: documents.Table{
+		Columns: []string{"Category", "Products", "Orders", "Revenue", "Margin", "Margin %", "Share"},
+	}}
+	for _, category := range report.Categories {
+		categories.Table.Rows = append(categories.Table.Rows, []string{
+			category.CategoryName, strconv.Itoa(category.ProductCount), strconv.Itoa(category.OrderCount), formatAmount(category.Revenue),
+			formatAmount(category.GrossMargin), formatPercent(category.MarginPercent), formatPercent(category.Percentage),
+		})
+	}
+
+	doc.Sections = []documents.Section{productSection(report.TopProducts), categories}
+	doc.Notes = lowMarginNote(report.LowMarginProducts)
+	return doc, nil
+}
+
+// lowStockLayout lays out the products at or below their stock threshold
+func lowStockLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*LowStockReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Generated", Value: report.GeneratedAt.Format(reportTimeLayout)},
+			{Label: "Threshold", Value: strconv.Itoa(report.Threshold)},
+			{Label: "Items", Value: fmt.Sprintf("%d (%d critical, %d warning)", report.TotalItems, report.CriticalItems, report.WarningItems)},
+		},
+		Table: documents.Table{
+			Columns: []string{"SKU", "Product", "Level", "Stock", "Reserved", "Available", "Min", "Reorder"},
+		},
+	}
+	for _, item := range report.Items {
+		doc.Table.Rows = append(doc.Table.Rows, []string{
+			item.SKU, item.ProductName, item.StockLevel, strconv.Itoa(item.CurrentStock), strconv.Itoa(item.ReservedStock),
+			strconv.Itoa(item.AvailableStock), strconv.Itoa(item.MinThreshold), strconv.Itoa(item.RecommendedOrder),
+		})
+	}
+	return doc, nil
+}
+
+// inventoryValueLayout lays out stock value by category and product
+func inventoryValueLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*InventoryValueReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Generated", Value: report.GeneratedAt.Format(reportTimeLayout)},
+			{Label: "Items", Value: strconv.Itoa(report.TotalItems)},
+			{Label: "Units", Value: strconv.Itoa(report.TotalQuantity)},
+			{Label: "Cost value", Value: formatAmount(report.TotalValue)},
+			{Label: "Retail value", Value: formatAmount(report.TotalRetailValue)},
+			{Label: "Margin", Value: formatAmount(report.TotalMargin) + " (" + formatPercent(report.MarginPercent) + ")"},
+		},
+	}
+
+	categories := documents.Section{Title: "Categories", Table: documents.Table{
+		Columns: []string{"Category", "Products", "Units", "Cost value", "Retail value", "Margin %", "Share"},
+	}}
+	for _, category := range report.Categories {
+		categories.Table.Rows = append(categories.Table.Rows, []string{
+			category.Category, strconv.Itoa(category.ProductCount), strconv.Itoa(category.TotalQuantity), formatAmount(category.TotalValue),
+			formatAmount(category.TotalRetail), formatPercent(category.MarginPercent), formatPercent(category.Percentage),
+		})
+	}
+
+	items := documents.Section{Title: "Items", Table: documents.Table{
+		Columns: []string{"SKU", "Product", "Category", "Units", "Unit cost", "Cost value", "Retail value", "Margin %"},
+	}}
+	for _, item := range report.Items {
+		items.Table.Rows = append(items.Table.Rows, []string{
+			item.SKU, item.ProductName, item.Category, strconv.Itoa(item.Quantity), formatAmount(item.UnitCost),
+			formatAmount(item.TotalValue), formatAmount(item.TotalRetail), formatPercent(item.MarginPercent),
+		})
+	}
+	items.Table.Footer = []string{"", "", "Total", strconv.Itoa(report.TotalQuantity), "",
+		formatAmount(report.TotalValue), formatAmount(report.TotalRetailValue), formatPercent(report.MarginPercent)}
+
+	doc.Sections = []documents.Section{categories, items}
+	return doc, nil
+}
+
+// customerActivityLayout lays out customer acquisition, retention and the top customers
+func customerActivityLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*CustomerActivityReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Period", Value: formatReportDate(report.StartDate) + " - " + formatReportDate(report.EndDate.AddDate(0, 0, -1))},
+			{Label: "Customers", Value: strconv.Itoa(report.TotalCustomers)},
+			{Label: "Active customers", Value: strconv.Itoa(report.ActiveCustomers)},
+			{Label: "New customers", Value: strconv.Itoa(report.NewCustomers)},
+			{Label: "Retention rate", Value: formatRate(report.RetentionRate)},
+			{Label: "Churn rate", Value: formatRate(report.ChurnRate)},
+		},
+	}
+
+	customers := documents.Section{Title: "Top Customers", Table: documents.Table{
+		Columns: []string{"Rank", "Customer", "Segment", "Orders", "Spent", "Avg order", "Last order"},
+	}}
+	for _, customer := range report.TopCustomers {
+		customers.Table.Rows = append(customers.Table.Rows, []string{
+			strconv.Itoa(customer.Rank), valueOrDefault(customer.CustomerName, customer.Email), customer.Segment, strconv.Itoa(customer.OrderCount),
+			formatAmount(customer.TotalSpent), formatAmount(customer.AvgOrderValue), formatReportDate(customer.LastOrderDate),
+		})
+	}
+
+	days := documents.Section{Title: "Activity by Day", Table: documents.Table{
+		Columns: []string{"Date", "Active", "New", "Orders", "Revenue"},
+	}}
+	for _, day := range report.ActivityByDay {
+		days.Table.Rows = append(days.Table.Rows, []string{
+			formatReportDate(day.Date), strconv.Itoa(day.ActiveCustomers), strconv.Itoa(day.NewCustomers), strconv.Itoa(day.OrderCount), formatAmount(day.Revenue),
+		})
+	}
+
+	doc.Sections = []documents.Section{customers, days}
+	return doc, nil
+}
+
+// orderAnalyticsLayout lays out order volumes by status, size and hour of day
+func orderAnalyticsLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*OrderAnalyticsReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			{Label: "Period", Value: formatReportDate(report.StartDate) + " - " + formatReportDate(report.EndDate.AddDate(0, 0, -1))},
+			{Label: "Orders", Value: fmt.Sprintf("%d (%d completed, %d cancelled, %d pending)",
+				report.TotalOrders, report.CompletedOrders, report.CancelledOrders, report.PendingOrders)},
+			{Label: "Revenue", Value: formatAmount(report.TotalRevenue)},
+			{Label: "Average order value", Value: formatAmount(report.AverageOrderValue)},
+		},
+	}
+
+	statuses := documents.Section{Title: "Orders by Status", Table: documents.Table{
+		Columns: []string{"Status", "Orders", "Share", "Revenue"},
+	}}
+	for _, status := range report.OrderStatusBreakdown {
+		statuses.Table.Rows = append(statuses.Table.Rows, []string{
+			status.Status, strconv.Itoa(status.Count), formatPercent(status.Percentage), formatAmount(status.Revenue),
+		})
+	}
+
+	sizes := documents.Section{Title: "Order Sizes", Table: documents.Table{
+		Columns: []string{"Size", "Orders", "Share", "Avg value", "Revenue"},
+	}}
+	for _, size := range report.OrderSizeDistribution {
+		sizes.Table.Rows = append(sizes.Table.Rows, []string{
+			size.SizeRange, strconv.Itoa(size.Count), formatPercent(size.Percentage), formatAmount(size.AvgValue), formatAmount(size.TotalRevenue),
+		})
+	}
+
+	hours := documents.Section{Title: "Orders by Hour", Table: documents.Table{
+		Columns: []string{"Hour", "Orders", "Revenue"},
+	}}
+	for _, hour := range report.HourlyPatterns {
+		hours.Table.Rows = append(hours.Table.Rows, []string{
+			fmt.Sprintf("%02d:00", hour.Hour), strconv.Itoa(hour.OrderCount), formatAmount(hour.Revenue),
+		})
+	}
+
+	doc.Sections = []documents.Section{statuses, sizes, hours}
+	return doc, nil
+}
+
+// reconciliationLayout lays out the payments checked against their gateway, those
+// needing review first
+func reconciliationLayout(result *ReportResult) (*documents.Document, error) {
+	report, ok := result.Data.(*PaymentReconciliationReportData)
+	if !ok {
+		return nil, unexpectedData(result)
+	}
+
+	scope := documents.Field{Label: "Run", Value: report.RunID}
+	if report.RunID == "" {
+		scope = documents.Field{Label: "Period", Value: formatReportDate(report.StartDate) + " - " + formatReportDate(report.EndDate.AddDate(0, 0, -1))}
+	}
+	doc := &documents.Document{
+		Title: reportTitles[result.Type],
+		Details: []documents.Field{
+			scope,
+			{Label: "Payments checked", Value: fmt.Sprintf("%d (%d in sync, %d corrected, %d pending)",
+				report.Checked, report.InSync, report.Corrected, report.Pending)},
+			{Label: "Needs review", Value: fmt.Sprintf("%d mismatched, %d errors", report.Mismatched, report.Errors)},
+		},
+		Table: documents.Table{
+			Columns: []string{"Payment", "Gateway", "Outcome", "Local", "Gateway status", "Resolved", "Amount", "Detail"},
+		},
+	}
+	for _, entry := range report.Entries {
+		doc.Table.Rows = append(doc.Table.Rows, []string{
+			entry.PaymentID, entry.Gateway, entry.Outcome, entry.LocalStatus, entry.GatewayStatus, entry.ResolvedStatus,
+			formatAmount(entry.LocalAmount), entry.Detail,
+		})
+	}
+	return doc, nil
+}
+
+// productSection lays out ranked product sales with their returns and margins
+func productSection(products []ProductSalesData) documents.Section {
+	section := documents.Section{Title: "Top Products", Table: documents.Table{
+		Columns: []string{"#", "SKU", "Product", "Qty", "Revenue", "Returned", "Net", "Margin %"},
+	}}
+	for i, product := range products {
+		rank := product.Rank
+		if rank == 0 {
+			rank = i + 1
+		}
+		name := product.ProductName
+		if product.LowMargin {
+			name += " *"
+		}
+		section.Table.Rows = append(section.Table.Rows, []string{
+			strconv.Itoa(rank), product.SKU, name, strconv.Itoa(product.Quantity), formatAmount(product.Revenue),
+			strconv.Itoa(product.ReturnedQuantity), formatAmount(product.NetRevenue), formatPercent(product.MarginPercent),
+		})
+	}
+	return section
+}
+
+// lowMarginNote explains the marker on low-margin products, when there are any
+func lowMarginNote(products []string) []string {
+	if len(products) == 0 {
+		return nil
+	}
+	return []string{"* Margin below the low margin threshold"}
+}
+
+// reportTimeLayout is how generation times are printed on reports
+const reportTimeLayout = "2006-01-02 15:04 MST"
+
+// formatReportDate prints a report date, or nothing for a zero date
+func formatReportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatAmount prints an amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatPercent prints a percentage with one decimal
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', 1, 64) + "%"
+}
+
+// formatRate prints a rate between 0 and 1 as a percentage
+func formatRate(rate float64) string {
+	return formatPercent(rate * 100)
+}
+
+// valueOrDefault returns value, or fallback when it is empty
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,133 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"easy-orders-backend/internal/models"
+)
+
+// ErrResultsNotStored is returned when polling a manager that keeps no results
+var ErrResultsNotStored = errors.New("report results are not stored")
+
+// GetResult returns a stored async report result, or nil when there is none. Data
+// holds the report as JSON.
+func (rm *ReportManager) GetResult(ctx context.Context, id string) (*ReportResult, error) {
+	if rm.results == nil {
+		return nil, ErrResultsNotStored
+	}
+
+	record, err := rm.results.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report result: %w", err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+	return resultFromRecord(record), nil
+}
+
+// storeResult stores a newly queued or cached result so it can be polled
+func (rm *ReportManager) storeResult(ctx context.Context, req *ReportRequest, result *ReportResult) error {
+	if rm.results == nil {
+		return nil
+	}
+
+	record, err := newResultRecord(req, result)
+	if err != nil {
+		return fmt.Errorf("failed to store report result: %w", err)
+	}
+	if err := rm.results.Create(context.WithoutCancel(ctx), record); err != nil {
+		return fmt.Errorf("failed to store report result: %w", err)
+	}
+	return nil
+}
+
+// transitionResult saves the new status and outcome of a stored result. Failures are
+// logged: the report itself is unaffected, only its stored status falls behind.
+func (rm *ReportManager) transitionResult(ctx context.Context, result *ReportResult, from ReportStatus) {
+	if rm.results == nil {
+		return
+	}
+
+	record, err := resultRecord(result)
+	if err == nil {
+		var moved bool
+		moved, err = rm.results.Transition(context.WithoutCancel(ctx), record, models.ReportResultStatus(from))
+		if err == nil && !moved {
+			rm.logger.Warn("Stored report result was not transitioned", "id", result.ID, "from", string(from), "to", string(result.Status))
+		}
+	}
+	if err != nil {
+		rm.logger.Error("Failed to store report result status", "error", err, "id", result.ID, "status", string(result.Status))
+	}
+}
+
+// newResultRecord converts a result with the request it answers for storage
+func newResultRecord(req *ReportRequest, result *ReportResult) (*models.ReportResult, error) {
+	record, err := resultRecord(result)
+	if err != nil {
+		return nil, err
+	}
+
+	record.RequestID = result.RequestID
+	record.Type = string(result.Type)
+	record.Format = string(result.Format)
+	record.RequestedBy = req.UserID
+	if req.Parameters != nil {
+		if record.Parameters, err = json.Marshal(req.Parameters); err != nil {
+			return nil, fmt.Errorf("failed to encode report parameters: %w", err)
+		}
+	}
+	return record, nil
+}
+
+// resultRecord converts the status and outcome of a result for storage
+func resultRecord(result *ReportResult) (*models.ReportResult, error) {
+	record := &models.ReportResult{
+		ID:           result.ID,
+		Status:       models.ReportResultStatus(result.Status),
+		Content:      result.Content,
+		FilePath:     result.FilePath,
+		FileSize:     result.FileSize,
+		RowCount:     result.RowCount,
+		Error:        result.Error,
+		ProcessingMs: result.ProcessingTime.Milliseconds(),
+		GeneratedAt:  result.GeneratedAt,
+		ExpiresAt:    result.ExpiresAt,
+	}
+	if result.Data != nil {
+		data, err := json.Marshal(result.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report data: %w", err)
+		}
+		record.Data = data
+	}
+	return record, nil
+}
+
+// resultFromRecord converts a stored result back
+func resultFromRecord(record *models.ReportResult) *ReportResult {
+	result := &ReportResult{
+		ID:             record.ID,
+		RequestID:      record.RequestID,
+		Type:           ReportType(record.Type),
+		Format:         ReportFormat(record.Format),
+		Status:         ReportStatus(record.Status),
+		Content:        record.Content,
+		FilePath:       record.FilePath,
+		FileSize:       record.FileSize,
+		RowCount:       record.RowCount,
+		GeneratedAt:    record.GeneratedAt,
+		ExpiresAt:      record.ExpiresAt,
+		ProcessingTime: time.Duration(record.ProcessingMs) * time.Millisecond,
+		Error:          record.Error,
+	}
+	if len(record.Data) > 0 && string(record.Data) != "null" {
+		result.Data = record.Data
+	}
+	return result
+}
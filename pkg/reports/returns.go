@@ -0,0 +1,41 @@
+package reports
+
+// applyProductReturns derives net revenue and return rate for each product from
+// its refunded amount and returned quantity
+func applyProductReturns(products []ProductSalesData) {
+	for i := range products {
+		product := &products[i]
+		product.NetRevenue = product.Revenue - product.RefundedAmount
+		if product.Quantity > 0 {
+			product.ReturnRate = float64(product.ReturnedQuantity) / float64(product.Quantity)
+		}
+	}
+}
+
+// applyReturns fills the gross vs net revenue columns of a sales report from the
+// refunds of each day. The report totals are the sum of the daily breakdown.
+func applyReturns(report *SalesReportData, products []ProductSalesData) {
+	applyProductReturns(products)
+
+	var refunded float64
+	for i := range report.SalesByDay {
+		day := &report.SalesByDay[i]
+		day.NetRevenue = day.Revenue - day.RefundedAmount
+		refunded += day.RefundedAmount
+	}
+
+	report.GrossRevenue = report.TotalRevenue
+	report.RefundedAmount = refunded
+	report.NetRevenue = report.GrossRevenue - refunded
+	report.ReturnRate = 0
+	if report.TotalOrders > 0 {
+		report.ReturnRate = float64(report.ReturnedOrders) / float64(report.TotalOrders)
+	}
+
+	if report.Summary == nil {
+		report.Summary = make(map[string]interface{})
+	}
+	report.Summary["gross_revenue"] = report.GrossRevenue
+	report.Summary["net_revenue"] = report.NetRevenue
+	report.Summary["return_rate"] = report.ReturnRate
+}
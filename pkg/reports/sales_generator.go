@@ -3,6 +3,8 @@ package reports
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"easy-orders-backend/internal/repository"
@@ -43,20 +45,24 @@ func (srg *SalesReportGenerator) GenerateReport(ctx context.Context, req *Report
 
 	startTime := time.Now()
 
+	localizer, err := req.Localizer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid sales report request: %w", err)
+	}
+
 	var reportData interface{}
-	var err error
 
 	switch req.Type {
 	case ReportTypeDailySales:
-		reportData, err = srg.generateDailySalesReport(ctx, req.Parameters)
+		reportData, err = srg.generateDailySalesReport(ctx, req.Parameters, localizer)
 	case ReportTypeWeeklySales:
-		reportData, err = srg.generateWeeklySalesReport(ctx, req.Parameters)
+		reportData, err = srg.generateWeeklySalesReport(ctx, req.Parameters, localizer)
 	case ReportTypeMonthlySales:
-		reportData, err = srg.generateMonthlySalesReport(ctx, req.Parameters)
+		reportData, err = srg.generateMonthlySalesReport(ctx, req.Parameters, localizer)
 	case ReportTypeTopProducts:
-		reportData, err = srg.generateTopProductsReport(ctx, req.Parameters)
+		reportData, err = srg.generateTopProductsReport(ctx, req.Parameters, localizer)
 	case ReportTypeRevenue:
-		reportData, err = srg.generateRevenueReport(ctx, req.Parameters)
+		reportData, err = srg.generateRevenueReport(ctx, req.Parameters, localizer)
 	default:
 		return nil, fmt.Errorf("unsupported sales report type: %s", req.Type)
 	}
@@ -79,7 +85,9 @@ func (srg *SalesReportGenerator) GenerateReport(ctx context.Context, req *Report
 		Metadata: map[string]interface{}{
 			"generator":     "SalesReportGenerator",
 			"processing_ms": processingTime.Milliseconds(),
-			"generated_at":  time.Now(),
+			"generated_at":  localizer.Now(),
+			"timezone":      localizer.Timezone(),
+			"locale":        localizer.Locale(),
 		},
 	}
 
@@ -126,151 +134,45 @@ func (srg *SalesReportGenerator) EstimateGenerationTime(req *ReportRequest) time
 	}
 }
 
+// salesReportTopProducts is how many products a daily, weekly or monthly sales report lists
+const salesReportTopProducts = 10
+
 // generateDailySalesReport generates a daily sales report
-func (srg *SalesReportGenerator) generateDailySalesReport(ctx context.Context, params map[string]interface{}) (*SalesReportData, error) {
+func (srg *SalesReportGenerator) generateDailySalesReport(ctx context.Context, params map[string]interface{}, localizer *Localizer) (*SalesReportData, error) {
 	// Parse parameters
-	date := time.Now().Truncate(24 * time.Hour)
+	date := localizer.StartOfDay(localizer.Now())
 	if dateStr, ok := params["date"].(string); ok {
-		if parsedDate, err := time.Parse("2006-01-02", dateStr); err == nil {
+		if parsedDate, err := localizer.ParseDate(dateStr); err == nil {
 			date = parsedDate
 		}
 	}
 
+	// Local days are 23 or 25 hours long across DST transitions
 	startDate := date
-	endDate := date.Add(24 * time.Hour)
+	endDate := date.AddDate(0, 0, 1)
 
 	srg.logger.Debug("Generating daily sales report",
 		"start_date", startDate,
 		"end_date", endDate)
 
-	// Simulate data aggregation (in real implementation, this would query the database)
-	totalRevenue := 15750.50
-	totalOrders := 45
-	avgOrderValue := totalRevenue / float64(totalOrders)
-
-	// Generate mock data for demonstration
-	topProducts := []ProductSalesData{
-		{
-			ProductID:   "prod-001",
-			ProductName: "Premium Widget",
-			SKU:         "PWD-001",
-			Quantity:    25,
-			Revenue:     5500.00,
-			OrderCount:  15,
-			AvgPrice:    220.00,
-			Rank:        1,
-		},
-		{
-			ProductID:   "prod-002",
-			ProductName: "Standard Widget",
-			SKU:         "SWD-002",
-			Quantity:    42,
-			Revenue:     4200.00,
-			OrderCount:  18,
-			AvgPrice:    100.00,
-			Rank:        2,
-		},
-		{
-			ProductID:   "prod-003",
-			ProductName: "Economy Widget",
-			SKU:         "EWD-003",
-			Quantity:    68,
-			Revenue:     3400.00,
-			OrderCount:  20,
-			AvgPrice:    50.00,
-			Rank:        3,
-		},
-	}
-
-	salesByDay := []DailySalesData{
-		{
-			Date:          date,
-			Revenue:       totalRevenue,
-			OrderCount:    totalOrders,
-			CustomerCount: 38,
-			AvgOrderValue: avgOrderValue,
-		},
-	}
-
-	paymentMethods := []PaymentMethodData{
-		{
-			Method:        "credit_card",
-			OrderCount:    30,
-			Revenue:       10500.35,
-			Percentage:    66.7,
-			AvgOrderValue: 350.01,
-		},
-		{
-			Method:        "paypal",
-			OrderCount:    10,
-			Revenue:       3250.15,
-			Percentage:    20.6,
-			AvgOrderValue: 325.02,
-		},
-		{
-			Method:        "bank_transfer",
-			OrderCount:    5,
-			Revenue:       2000.00,
-			Percentage:    12.7,
-			AvgOrderValue: 400.00,
-		},
-	}
-
-	customerSegments := []CustomerSegmentData{
-		{
-			Segment:       "premium",
-			CustomerCount: 8,
-			Revenue:       7875.25,
-			OrderCount:    12,
-			AvgOrderValue: 656.27,
-			Percentage:    50.0,
-		},
-		{
-			Segment:       "regular",
-			CustomerCount: 20,
-			Revenue:       5512.50,
-			OrderCount:    22,
-			AvgOrderValue: 250.57,
-			Percentage:    35.0,
-		},
-		{
-			Segment:       "new",
-			CustomerCount: 10,
-			Revenue:       2362.75,
-			OrderCount:    11,
-			AvgOrderValue: 214.80,
-			Percentage:    15.0,
-		},
+	// Growth is compared to the previous day
+	report, err := srg.buildSalesReport(ctx, startDate, endDate, startDate.AddDate(0, 0, -1), params, localizer)
+	if err != nil {
+		return nil, err
 	}
+	report.Period = fmt.Sprintf("Daily - %s", localizer.FormatDate(date))
 
-	report := &SalesReportData{
-		Period:            fmt.Sprintf("Daily - %s", date.Format("2006-01-02")),
-		StartDate:         startDate,
-		EndDate:           endDate,
-		TotalRevenue:      totalRevenue,
-		TotalOrders:       totalOrders,
-		AverageOrderValue: avgOrderValue,
-		TopProducts:       topProducts,
-		SalesByDay:        salesByDay,
-		PaymentMethods:    paymentMethods,
-		CustomerSegments:  customerSegments,
-		Summary: map[string]interface{}{
-			"best_selling_product": topProducts[0].ProductName,
-			"conversion_rate":      0.084, // 8.4%
-			"repeat_customer_rate": 0.632, // 63.2%
-			"growth_rate":          0.125, // 12.5% compared to previous day
-		},
-	}
+	applyLocalization(report, localizer)
 
 	return report, nil
 }
 
 // generateWeeklySalesReport generates a weekly sales report
-func (srg *SalesReportGenerator) generateWeeklySalesReport(ctx context.Context, params map[string]interface{}) (*SalesReportData, error) {
+func (srg *SalesReportGenerator) generateWeeklySalesReport(ctx context.Context, params map[string]interface{}, localizer *Localizer) (*SalesReportData, error) {
 	// Parse week start date
-	startDate := time.Now().AddDate(0, 0, -7).Truncate(24 * time.Hour)
+	startDate := localizer.StartOfDay(localizer.Now()).AddDate(0, 0, -7)
 	if weekStr, ok := params["week_start"].(string); ok {
-		if parsedDate, err := time.Parse("2006-01-02", weekStr); err == nil {
+		if parsedDate, err := localizer.ParseDate(weekStr); err == nil {
 			startDate = parsedDate
 		}
 	}
@@ -281,138 +183,25 @@ func (srg *SalesReportGenerator) generateWeeklySalesReport(ctx context.Context,
 		"start_date", startDate,
 		"end_date", endDate)
 
-	// Simulate weekly aggregation
-	totalRevenue := 87425.75
-	totalOrders := 312
-	avgOrderValue := totalRevenue / float64(totalOrders)
-
-	// Generate daily breakdown
-	var salesByDay []DailySalesData
-	for i := 0; i < 7; i++ {
-		day := startDate.AddDate(0, 0, i)
-		dailyRevenue := totalRevenue * (0.1 + float64(i%3)*0.05) // Varying daily sales
-		dailyOrders := int(float64(totalOrders) * (0.1 + float64(i%3)*0.05))
-		dailyCustomers := int(float64(dailyOrders) * 0.85) // Some customers place multiple orders
-
-		salesByDay = append(salesByDay, DailySalesData{
-			Date:          day,
-			Revenue:       dailyRevenue,
-			OrderCount:    dailyOrders,
-			CustomerCount: dailyCustomers,
-			AvgOrderValue: dailyRevenue / float64(dailyOrders),
-		})
-	}
-
-	topProducts := []ProductSalesData{
-		{
-			ProductID:   "prod-001",
-			ProductName: "Premium Widget",
-			SKU:         "PWD-001",
-			Quantity:    156,
-			Revenue:     34320.00,
-			OrderCount:  78,
-			AvgPrice:    220.00,
-			Rank:        1,
-		},
-		{
-			ProductID:   "prod-002",
-			ProductName: "Standard Widget",
-			SKU:         "SWD-002",
-			Quantity:    234,
-			Revenue:     23400.00,
-			OrderCount:  89,
-			AvgPrice:    100.00,
-			Rank:        2,
-		},
-		{
-			ProductID:   "prod-003",
-			ProductName: "Economy Widget",
-			SKU:         "EWD-003",
-			Quantity:    378,
-			Revenue:     18900.00,
-			OrderCount:  98,
-			AvgPrice:    50.00,
-			Rank:        3,
-		},
-	}
-
-	paymentMethods := []PaymentMethodData{
-		{
-			Method:        "credit_card",
-			OrderCount:    210,
-			Revenue:       58197.52,
-			Percentage:    66.6,
-			AvgOrderValue: 277.13,
-		},
-		{
-			Method:        "paypal",
-			OrderCount:    65,
-			Revenue:       18706.44,
-			Percentage:    21.4,
-			AvgOrderValue: 287.79,
-		},
-		{
-			Method:        "bank_transfer",
-			OrderCount:    37,
-			Revenue:       10521.79,
-			Percentage:    12.0,
-			AvgOrderValue: 284.37,
-		},
+	// Growth is compared to the previous week
+	report, err := srg.buildSalesReport(ctx, startDate, endDate, startDate.AddDate(0, 0, -7), params, localizer)
+	if err != nil {
+		return nil, err
 	}
-
-	customerSegments := []CustomerSegmentData{
-		{
-			Segment:       "premium",
-			CustomerCount: 45,
-			Revenue:       43712.88,
-			OrderCount:    67,
-			AvgOrderValue: 652.43,
-			Percentage:    50.0,
-		},
-		{
-			Segment:       "regular",
-			CustomerCount: 125,
-			Revenue:       30597.01,
-			OrderCount:    156,
-			AvgOrderValue: 196.14,
-			Percentage:    35.0,
-		},
-		{
-			Segment:       "new",
-			CustomerCount: 68,
-			Revenue:       13115.86,
-			OrderCount:    89,
-			AvgOrderValue: 147.37,
-			Percentage:    15.0,
-		},
+	report.Period = fmt.Sprintf("Weekly - %s to %s", localizer.FormatDate(startDate), localizer.FormatDate(endDate.AddDate(0, 0, -1)))
+	if best := bestDay(report.SalesByDay); best != nil {
+		report.Summary["best_day"] = best.Date.Format("Monday")
 	}
 
-	report := &SalesReportData{
-		Period:            fmt.Sprintf("Weekly - %s to %s", startDate.Format("2006-01-02"), endDate.AddDate(0, 0, -1).Format("2006-01-02")),
-		StartDate:         startDate,
-		EndDate:           endDate,
-		TotalRevenue:      totalRevenue,
-		TotalOrders:       totalOrders,
-		AverageOrderValue: avgOrderValue,
-		TopProducts:       topProducts,
-		SalesByDay:        salesByDay,
-		PaymentMethods:    paymentMethods,
-		CustomerSegments:  customerSegments,
-		Summary: map[string]interface{}{
-			"best_day":             salesByDay[0].Date.Format("Monday"),
-			"conversion_rate":      0.092, // 9.2%
-			"repeat_customer_rate": 0.714, // 71.4%
-			"growth_rate":          0.086, // 8.6% compared to previous week
-		},
-	}
+	applyLocalization(report, localizer)
 
 	return report, nil
 }
 
 // generateMonthlySalesReport generates a monthly sales report
-func (srg *SalesReportGenerator) generateMonthlySalesReport(ctx context.Context, params map[string]interface{}) (*SalesReportData, error) {
+func (srg *SalesReportGenerator) generateMonthlySalesReport(ctx context.Context, params map[string]interface{}, localizer *Localizer) (*SalesReportData, error) {
 	// Parse month
-	now := time.Now()
+	now := localizer.Now()
 	year := now.Year()
 	month := int(now.Month())
 
@@ -423,7 +212,7 @@ func (srg *SalesReportGenerator) generateMonthlySalesReport(ctx context.Context,
 		month = int(monthParam)
 	}
 
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, localizer.Location())
 	endDate := startDate.AddDate(0, 1, 0)
 
 	srg.logger.Debug("Generating monthly sales report",
@@ -432,147 +221,229 @@ func (srg *SalesReportGenerator) generateMonthlySalesReport(ctx context.Context,
 		"start_date", startDate,
 		"end_date", endDate)
 
-	// Simulate monthly aggregation
-	totalRevenue := 367890.25
-	totalOrders := 1456
-	avgOrderValue := totalRevenue / float64(totalOrders)
+	// Growth is compared to the previous month
+	report, err := srg.buildSalesReport(ctx, startDate, endDate, startDate.AddDate(0, -1, 0), params, localizer)
+	if err != nil {
+		return nil, err
+	}
+	report.Period = fmt.Sprintf("Monthly - %s", localizer.FormatMonth(startDate))
+	if weekday, ok := bestDayOfWeek(report.SalesByDay); ok {
+		report.Summary["best_day_of_week"] = weekday.String()
+	}
+
+	applyLocalization(report, localizer)
+
+	return report, nil
+}
+
+// buildSalesReport aggregates the sales of orders placed in [startDate, endDate) into
+// a report with a row for every calendar day of the period. Growth is measured against
+// the sales of [previousStart, startDate).
+func (srg *SalesReportGenerator) buildSalesReport(ctx context.Context, startDate, endDate, previousStart time.Time, params map[string]interface{}, localizer *Localizer) (*SalesReportData, error) {
+	days, err := srg.orderRepo.AggregateSalesByDay(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate daily sales: %w", err)
+	}
+	productSales, err := srg.orderRepo.AggregateSalesByProduct(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate product sales: %w", err)
+	}
+	methodSales, err := srg.orderRepo.AggregateSalesByPaymentMethod(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate payment method sales: %w", err)
+	}
+	customerSales, err := srg.orderRepo.AggregateSalesByCustomerType(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate customer sales: %w", err)
+	}
+	previousDays, err := srg.orderRepo.AggregateSalesByDay(ctx, previousStart, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate previous period sales: %w", err)
+	}
+
+	report := &SalesReportData{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		SalesByDay: dailySales(days, startDate, endDate),
+		Summary:    make(map[string]interface{}),
+	}
+	for _, day := range report.SalesByDay {
+		report.TotalRevenue += day.Revenue
+		report.TotalOrders += day.OrderCount
+	}
+	for _, day := range days {
+		report.ReturnedOrders += day.ReturnedOrders
+	}
+	if report.TotalOrders > 0 {
+		report.AverageOrderValue = report.TotalRevenue / float64(report.TotalOrders)
+	}
+
+	products := productSalesData(productSales)
+	report.PaymentMethods = paymentMethodData(methodSales, report.TotalRevenue)
+	report.CustomerSegments = customerSegmentData(customerSales, report.TotalRevenue)
+
+	// Margins cover every product sold, not only the ones listed
+	applyReturns(report, products)
+	applyMargins(report, products, lowMarginThreshold(params))
+	if len(products) > salesReportTopProducts {
+		products = products[:salesReportTopProducts]
+	}
+	report.TopProducts = products
+
+	if len(products) > 0 {
+		report.Summary["best_selling_product"] = products[0].ProductName
+	}
+	var customers, returning int
+	for _, segment := range report.CustomerSegments {
+		customers += segment.CustomerCount
+		if segment.Segment == "returning" {
+			returning = segment.CustomerCount
+		}
+	}
+	if customers > 0 {
+		report.Summary["repeat_customer_rate"] = float64(returning) / float64(customers)
+	}
+	var previousRevenue float64
+	for _, day := range previousDays {
+		previousRevenue += day.Revenue
+	}
+	if previousRevenue > 0 {
+		report.Summary["growth_rate"] = (report.TotalRevenue - previousRevenue) / previousRevenue
+	}
+
+	return report, nil
+}
+
+// dailySales lays out the sales of each calendar day in [startDate, endDate), with
+// zeros for days without sales
+func dailySales(days []repository.SalesDay, startDate, endDate time.Time) []DailySalesData {
+	byDate := make(map[string]repository.SalesDay, len(days))
+	for _, day := range days {
+		byDate[day.Day.Format("2006-01-02")] = day
+	}
 
-	// Generate daily breakdown for the month
 	var salesByDay []DailySalesData
-	daysInMonth := endDate.Sub(startDate).Hours() / 24
-	for i := 0; i < int(daysInMonth); i++ {
-		day := startDate.AddDate(0, 0, i)
-		// Simulate varying daily sales with weekend patterns
-		dayOfWeek := day.Weekday()
-		multiplier := 1.0
-		if dayOfWeek == time.Saturday || dayOfWeek == time.Sunday {
-			multiplier = 0.7 // Lower weekend sales
+	for date := startDate; date.Before(endDate); date = date.AddDate(0, 0, 1) {
+		day := byDate[date.Format("2006-01-02")]
+		data := DailySalesData{
+			Date:           date,
+			Revenue:        day.Revenue,
+			RefundedAmount: day.RefundedAmount,
+			OrderCount:     day.Orders,
+			CustomerCount:  day.Customers,
+		}
+		if day.Orders > 0 {
+			data.AvgOrderValue = day.Revenue / float64(day.Orders)
 		}
+		salesByDay = append(salesByDay, data)
+	}
+	return salesByDay
+}
 
-		dailyRevenue := (totalRevenue / daysInMonth) * multiplier
-		dailyOrders := int((float64(totalOrders) / daysInMonth) * multiplier)
-		dailyCustomers := int(float64(dailyOrders) * 0.82)
-
-		salesByDay = append(salesByDay, DailySalesData{
-			Date:          day,
-			Revenue:       dailyRevenue,
-			OrderCount:    dailyOrders,
-			CustomerCount: dailyCustomers,
-			AvgOrderValue: dailyRevenue / float64(dailyOrders),
-		})
-	}
-
-	topProducts := []ProductSalesData{
-		{
-			ProductID:   "prod-001",
-			ProductName: "Premium Widget",
-			SKU:         "PWD-001",
-			Quantity:    685,
-			Revenue:     150700.00,
-			OrderCount:  342,
-			AvgPrice:    220.00,
-			Rank:        1,
-		},
-		{
-			ProductID:   "prod-002",
-			ProductName: "Standard Widget",
-			SKU:         "SWD-002",
-			Quantity:    987,
-			Revenue:     98700.00,
-			OrderCount:  456,
-			AvgPrice:    100.00,
-			Rank:        2,
-		},
-		{
-			ProductID:   "prod-003",
-			ProductName: "Economy Widget",
-			SKU:         "EWD-003",
-			Quantity:    1234,
-			Revenue:     61700.00,
-			OrderCount:  523,
-			AvgPrice:    50.00,
-			Rank:        3,
-		},
+// productSalesData ranks the products by revenue. Partial refunds return a fraction
+// of a unit, which is rounded to whole units.
+func productSalesData(sales []repository.ProductSales) []ProductSalesData {
+	products := make([]ProductSalesData, len(sales))
+	for i, sale := range sales {
+		products[i] = ProductSalesData{
+			ProductID:        sale.ProductID,
+			ProductName:      sale.ProductName,
+			SKU:              sale.SKU,
+			Quantity:         sale.Quantity,
+			Revenue:          sale.Revenue,
+			ReturnedQuantity: int(math.Round(sale.ReturnedQuantity)),
+			RefundedAmount:   sale.RefundedAmount,
+			UnitCost:         sale.UnitCost,
+			OrderCount:       sale.Orders,
+			Rank:             i + 1,
+		}
+		if sale.Quantity > 0 {
+			products[i].AvgPrice = sale.Revenue / float64(sale.Quantity)
+		}
 	}
+	return products
+}
 
-	paymentMethods := []PaymentMethodData{
-		{
-			Method:        "credit_card",
-			OrderCount:    972,
-			Revenue:       245252.27,
-			Percentage:    66.7,
-			AvgOrderValue: 252.37,
-		},
-		{
-			Method:        "paypal",
-			OrderCount:    291,
-			Revenue:       78523.04,
-			Percentage:    21.3,
-			AvgOrderValue: 270.01,
-		},
-		{
-			Method:        "bank_transfer",
-			OrderCount:    193,
-			Revenue:       44114.94,
-			Percentage:    12.0,
-			AvgOrderValue: 228.58,
-		},
+// paymentMethodData reports each payment method's share of revenue
+func paymentMethodData(sales []repository.PaymentMethodSales, totalRevenue float64) []PaymentMethodData {
+	methods := make([]PaymentMethodData, len(sales))
+	for i, sale := range sales {
+		methods[i] = PaymentMethodData{
+			Method:     string(sale.Method),
+			OrderCount: sale.Orders,
+			Revenue:    sale.Revenue,
+			Percentage: percentOf(sale.Revenue, totalRevenue),
+		}
+		if methods[i].Method == "" {
+			methods[i].Method = "unspecified"
+		}
+		if sale.Orders > 0 {
+			methods[i].AvgOrderValue = sale.Revenue / float64(sale.Orders)
+		}
 	}
+	return methods
+}
 
-	customerSegments := []CustomerSegmentData{
-		{
-			Segment:       "premium",
-			CustomerCount: 198,
-			Revenue:       183945.13,
-			OrderCount:    287,
-			AvgOrderValue: 640.72,
-			Percentage:    50.0,
-		},
-		{
-			Segment:       "regular",
-			CustomerCount: 567,
-			Revenue:       128661.59,
-			OrderCount:    734,
-			AvgOrderValue: 175.29,
-			Percentage:    35.0,
-		},
-		{
-			Segment:       "new",
-			CustomerCount: 289,
-			Revenue:       55283.53,
-			OrderCount:    435,
-			AvgOrderValue: 127.01,
-			Percentage:    15.0,
-		},
+// customerSegmentData reports the sales of new and returning customers
+func customerSegmentData(sales []repository.CustomerTypeSales, totalRevenue float64) []CustomerSegmentData {
+	segments := make([]CustomerSegmentData, len(sales))
+	for i, sale := range sales {
+		segments[i] = CustomerSegmentData{
+			Segment:       "returning",
+			CustomerCount: sale.Customers,
+			Revenue:       sale.Revenue,
+			OrderCount:    sale.Orders,
+			Percentage:    percentOf(sale.Revenue, totalRevenue),
+		}
+		if sale.New {
+			segments[i].Segment = "new"
+		}
+		if sale.Orders > 0 {
+			segments[i].AvgOrderValue = sale.Revenue / float64(sale.Orders)
+		}
 	}
+	return segments
+}
 
-	report := &SalesReportData{
-		Period:            fmt.Sprintf("Monthly - %s %d", time.Month(month).String(), year),
-		StartDate:         startDate,
-		EndDate:           endDate,
-		TotalRevenue:      totalRevenue,
-		TotalOrders:       totalOrders,
-		AverageOrderValue: avgOrderValue,
-		TopProducts:       topProducts,
-		SalesByDay:        salesByDay,
-		PaymentMethods:    paymentMethods,
-		CustomerSegments:  customerSegments,
-		Summary: map[string]interface{}{
-			"best_day_of_week":     "Tuesday",
-			"conversion_rate":      0.087, // 8.7%
-			"repeat_customer_rate": 0.756, // 75.6%
-			"growth_rate":          0.154, // 15.4% compared to previous month
-		},
+// percentOf returns part as a percentage of total, or zero without a total
+func percentOf(part, total float64) float64 {
+	if total <= 0 {
+		return 0
 	}
+	return part / total * 100
+}
 
-	return report, nil
+// bestDay returns the day with the highest revenue, or nil when nothing sold
+func bestDay(days []DailySalesData) *DailySalesData {
+	var best *DailySalesData
+	for i := range days {
+		if days[i].Revenue > 0 && (best == nil || days[i].Revenue > best.Revenue) {
+			best = &days[i]
+		}
+	}
+	return best
+}
+
+// bestDayOfWeek returns the weekday with the highest total revenue
+func bestDayOfWeek(days []DailySalesData) (time.Weekday, bool) {
+	var revenue [7]float64
+	for _, day := range days {
+		revenue[day.Date.Weekday()] += day.Revenue
+	}
+	best, found := time.Sunday, false
+	for weekday, amount := range revenue {
+		if amount > 0 && (!found || amount > revenue[best]) {
+			best, found = time.Weekday(weekday), true
+		}
+	}
+	return best, found
 }
 
 // generateTopProductsReport generates a top products report
-func (srg *SalesReportGenerator) generateTopProductsReport(ctx context.Context, params map[string]interface{}) (*TopProductsReportData, error) {
+func (srg *SalesReportGenerator) generateTopProductsReport(ctx context.Context, params map[string]interface{}, localizer *Localizer) (*TopProductsReportData, error) {
 	// Parse parameters
 	limit := 20
-	if limitParam, ok := params["limit"].(float64); ok {
+	if limitParam, ok := params["limit"].(float64); ok && limitParam > 0 {
 		limit = int(limitParam)
 	}
 
@@ -582,7 +453,7 @@ func (srg *SalesReportGenerator) generateTopProductsReport(ctx context.Context,
 	}
 
 	var startDate, endDate time.Time
-	now := time.Now()
+	now := localizer.Now()
 
 	switch period {
 	case "last_7_days":
@@ -605,54 +476,109 @@ func (srg *SalesReportGenerator) generateTopProductsReport(ctx context.Context,
 		"start_date", startDate,
 		"end_date", endDate)
 
-	// Generate mock top products data
-	topProducts := []ProductSalesData{
-		{ProductID: "prod-001", ProductName: "Premium Widget", SKU: "PWD-001", Quantity: 856, Revenue: 188320.00, OrderCount: 428, AvgPrice: 220.00, Rank: 1},
-		{ProductID: "prod-002", ProductName: "Standard Widget", SKU: "SWD-002", Quantity: 1243, Revenue: 124300.00, OrderCount: 567, AvgPrice: 100.00, Rank: 2},
-		{ProductID: "prod-003", ProductName: "Economy Widget", SKU: "EWD-003", Quantity: 1876, Revenue: 93800.00, OrderCount: 723, AvgPrice: 50.00, Rank: 3},
-		{ProductID: "prod-004", ProductName: "Deluxe Gadget", SKU: "DLX-004", Quantity: 234, Revenue: 70200.00, OrderCount: 156, AvgPrice: 300.00, Rank: 4},
-		{ProductID: "prod-005", ProductName: "Basic Tool", SKU: "BSC-005", Quantity: 567, Revenue: 56700.00, OrderCount: 234, AvgPrice: 100.00, Rank: 5},
-		{ProductID: "prod-006", ProductName: "Professional Kit", SKU: "PRO-006", Quantity: 89, Revenue: 44500.00, OrderCount: 67, AvgPrice: 500.00, Rank: 6},
-		{ProductID: "prod-007", ProductName: "Student Package", SKU: "STU-007", Quantity: 345, Revenue: 34500.00, OrderCount: 189, AvgPrice: 100.00, Rank: 7},
-		{ProductID: "prod-008", ProductName: "Enterprise Solution", SKU: "ENT-008", Quantity: 23, Revenue: 23000.00, OrderCount: 12, AvgPrice: 1000.00, Rank: 8},
-		{ProductID: "prod-009", ProductName: "Starter Set", SKU: "STA-009", Quantity: 456, Revenue: 22800.00, OrderCount: 234, AvgPrice: 50.00, Rank: 9},
-		{ProductID: "prod-010", ProductName: "Advanced Module", SKU: "ADV-010", Quantity: 67, Revenue: 20100.00, OrderCount: 45, AvgPrice: 300.00, Rank: 10},
-	}
-
-	// Limit results
+	productSales, err := srg.orderRepo.AggregateSalesByProduct(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate product sales: %w", err)
+	}
+
+	products := productSalesData(productSales)
+	applyProductReturns(products)
+	lowMarginProducts := applyProductMargins(products, lowMarginThreshold(params))
+
+	// Categories cover every product sold, not only the ones listed
+	categories := categoryData(products, productSales)
+	applyCategoryMargins(categories)
+
+	topProducts := products
 	if limit < len(topProducts) {
 		topProducts = topProducts[:limit]
 	}
 
-	categories := []CategoryData{
-		{CategoryName: "Widgets", ProductCount: 3, Revenue: 406420.00, OrderCount: 1718, AvgPrice: 147.83, Percentage: 60.5},
-		{CategoryName: "Gadgets", ProductCount: 2, Revenue: 114700.00, OrderCount: 223, AvgPrice: 285.71, Percentage: 17.1},
-		{CategoryName: "Tools", ProductCount: 2, Revenue: 79200.00, OrderCount: 301, AvgPrice: 166.67, Percentage: 11.8},
-		{CategoryName: "Kits", ProductCount: 2, Revenue: 57500.00, OrderCount: 134, AvgPrice: 333.33, Percentage: 8.6},
-		{CategoryName: "Solutions", ProductCount: 1, Revenue: 23000.00, OrderCount: 12, AvgPrice: 1000.00, Percentage: 3.4},
+	summary := map[string]interface{}{
+		"total_products_analyzed": len(products),
+		"low_margin_products":     len(lowMarginProducts),
+	}
+	if len(categories) > 0 {
+		summary["top_category"] = categories[0].CategoryName
+	}
+	if len(topProducts) > 0 {
+		// The highest return rate flags products that may need quality review
+		highestReturnRate := topProducts[0]
+		lowestPrice, highestPrice := topProducts[0].AvgPrice, topProducts[0].AvgPrice
+		for _, product := range topProducts[1:] {
+			if product.ReturnRate > highestReturnRate.ReturnRate {
+				highestReturnRate = product
+			}
+			lowestPrice = math.Min(lowestPrice, product.AvgPrice)
+			highestPrice = math.Max(highestPrice, product.AvgPrice)
+		}
+		summary["best_performer"] = topProducts[0].ProductName
+		summary["highest_return_rate"] = highestReturnRate.ProductName
+		summary["avg_price_range"] = fmt.Sprintf("%s - %s",
+			localizer.FormatCurrency(lowestPrice, reportCurrency), localizer.FormatCurrency(highestPrice, reportCurrency))
 	}
 
 	report := &TopProductsReportData{
-		Period:      fmt.Sprintf("Top Products - %s", period),
-		StartDate:   startDate,
-		EndDate:     endDate,
-		TopProducts: topProducts,
-		Categories:  categories,
-		Summary: map[string]interface{}{
-			"total_products_analyzed": 247,
-			"top_category":            "Widgets",
-			"avg_price_range":         "$50 - $1000",
-			"best_performer":          topProducts[0].ProductName,
-		},
+		Period:            fmt.Sprintf("Top Products - %s", period),
+		StartDate:         startDate,
+		EndDate:           endDate,
+		Timezone:          localizer.Timezone(),
+		Locale:            localizer.Locale(),
+		TopProducts:       topProducts,
+		Categories:        categories,
+		LowMarginProducts: lowMarginProducts,
+		Summary:           summary,
 	}
 
 	return report, nil
 }
 
+// categoryData sums product sales per category, by revenue, highest first. Categories
+// are identified by ID; products without one are grouped as "uncategorized". Margins
+// must be applied to the products first.
+func categoryData(products []ProductSalesData, sales []repository.ProductSales) []CategoryData {
+	var totalRevenue float64
+	var categories []CategoryData
+	quantities := make(map[string]int)
+	index := make(map[string]int)
+	for i, product := range products {
+		name := "uncategorized"
+		if sales[i].CategoryID != nil && *sales[i].CategoryID != "" {
+			name = *sales[i].CategoryID
+		}
+		n, ok := index[name]
+		if !ok {
+			n = len(categories)
+			index[name] = n
+			categories = append(categories, CategoryData{CategoryName: name})
+		}
+
+		category := &categories[n]
+		category.ProductCount++
+		category.Revenue += product.Revenue
+		category.CostOfGoods += product.CostOfGoods
+		category.OrderCount += product.OrderCount
+		quantities[name] += product.Quantity
+		totalRevenue += product.Revenue
+	}
+
+	for i := range categories {
+		category := &categories[i]
+		if quantity := quantities[category.CategoryName]; quantity > 0 {
+			category.AvgPrice = category.Revenue / float64(quantity)
+		}
+		category.Percentage = percentOf(category.Revenue, totalRevenue)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Revenue > categories[j].Revenue
+	})
+	return categories
+}
+
 // generateRevenueReport generates a revenue analytics report
-func (srg *SalesReportGenerator) generateRevenueReport(ctx context.Context, params map[string]interface{}) (*SalesReportData, error) {
+func (srg *SalesReportGenerator) generateRevenueReport(ctx context.Context, params map[string]interface{}, localizer *Localizer) (*SalesReportData, error) {
 	// This is similar to sales report but focuses more on revenue analytics
-	return srg.generateMonthlySalesReport(ctx, params)
+	return srg.generateMonthlySalesReport(ctx, params, localizer)
 }
 
 // getRowCount estimates the number of rows in the report data
@@ -18,6 +18,9 @@ const (
 	ReportTypeOrderAnalytics   ReportType = "order_analytics"
 	ReportTypePaymentAnalytics ReportType = "payment_analytics"
 	ReportTypeRevenue          ReportType = "revenue"
+
+	// ReportTypePaymentReconciliation reports payments checked against their gateway
+	ReportTypePaymentReconciliation ReportType = "payment_reconciliation"
 )
 
 // ReportStatus represents the status of report generation
@@ -59,6 +62,8 @@ type ReportRequest struct {
 	Format      ReportFormat           `json:"format"`
 	Priority    ReportPriority         `json:"priority"`
 	Parameters  map[string]interface{} `json:"parameters"`
+	Timezone    string                 `json:"timezone,omitempty"` // IANA name, defaults to UTC
+	Locale      string                 `json:"locale,omitempty"`   // e.g. en-US, defaults to en-US
 	UserID      string                 `json:"user_id,omitempty"`
 	Email       string                 `json:"email,omitempty"`
 	ScheduledAt *time.Time             `json:"scheduled_at,omitempty"`
@@ -74,6 +79,7 @@ type ReportResult struct {
 	Format         ReportFormat           `json:"format"`
 	Status         ReportStatus           `json:"status"`
 	Data           interface{}            `json:"data,omitempty"`
+	Content        []byte                 `json:"-"` // The rendered report, for formats other than JSON
 	FilePath       string                 `json:"file_path,omitempty"`
 	FileSize       int64                  `json:"file_size,omitempty"`
 	RowCount       int                    `json:"row_count,omitempty"`
@@ -102,8 +108,20 @@ type SalesReportData struct {
 	Period            string                 `json:"period"`
 	StartDate         time.Time              `json:"start_date"`
 	EndDate           time.Time              `json:"end_date"`
-	TotalRevenue      float64                `json:"total_revenue"`
+	Timezone          string                 `json:"timezone"`
+	Locale            string                 `json:"locale"`
+	Display           map[string]string      `json:"display"`       // locale-formatted dates and amounts
+	TotalRevenue      float64                `json:"total_revenue"` // gross, kept for existing consumers
+	GrossRevenue      float64                `json:"gross_revenue"`
+	RefundedAmount    float64                `json:"refunded_amount"`
+	NetRevenue        float64                `json:"net_revenue"`
 	TotalOrders       int                    `json:"total_orders"`
+	ReturnedOrders    int                    `json:"returned_orders"`
+	ReturnRate        float64                `json:"return_rate"`
+	CostOfGoods       float64                `json:"cost_of_goods"`
+	GrossMargin       float64                `json:"gross_margin"`
+	MarginPercent     float64                `json:"margin_percent"`
+	LowMarginProducts []string               `json:"low_margin_products"`
 	AverageOrderValue float64                `json:"average_order_value"`
 	TopProducts       []ProductSalesData     `json:"top_products"`
 	SalesByDay        []DailySalesData       `json:"sales_by_day"`
@@ -114,23 +132,35 @@ type SalesReportData struct {
 
 // ProductSalesData represents product sales information
 type ProductSalesData struct {
-	ProductID   string  `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	SKU         string  `json:"sku"`
-	Quantity    int     `json:"quantity"`
-	Revenue     float64 `json:"revenue"`
-	OrderCount  int     `json:"order_count"`
-	AvgPrice    float64 `json:"avg_price"`
-	Rank        int     `json:"rank"`
+	ProductID        string  `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	SKU              string  `json:"sku"`
+	Quantity         int     `json:"quantity"`
+	Revenue          float64 `json:"revenue"`
+	ReturnedQuantity int     `json:"returned_quantity"`
+	RefundedAmount   float64 `json:"refunded_amount"`
+	NetRevenue       float64 `json:"net_revenue"`
+	ReturnRate       float64 `json:"return_rate"`
+	UnitCost         float64 `json:"unit_cost"`
+	CostOfGoods      float64 `json:"cost_of_goods"`
+	GrossMargin      float64 `json:"gross_margin"`
+	MarginPercent    float64 `json:"margin_percent"`
+	LowMargin        bool    `json:"low_margin"`
+	OrderCount       int     `json:"order_count"`
+	AvgPrice         float64 `json:"avg_price"`
+	Rank             int     `json:"rank"`
 }
 
 // DailySalesData represents daily sales breakdown
 type DailySalesData struct {
-	Date          time.Time `json:"date"`
-	Revenue       float64   `json:"revenue"`
-	OrderCount    int       `json:"order_count"`
-	CustomerCount int       `json:"customer_count"`
-	AvgOrderValue float64   `json:"avg_order_value"`
+	Date           time.Time `json:"date"`
+	Label          string    `json:"label"`
+	Revenue        float64   `json:"revenue"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	NetRevenue     float64   `json:"net_revenue"`
+	OrderCount     int       `json:"order_count"`
+	CustomerCount  int       `json:"customer_count"`
+	AvgOrderValue  float64   `json:"avg_order_value"`
 }
 
 // PaymentMethodData represents payment method analytics
@@ -181,22 +211,28 @@ type LowStockItemData struct {
 
 // TopProductsReportData represents top-selling products report
 type TopProductsReportData struct {
-	Period      string                 `json:"period"`
-	StartDate   time.Time              `json:"start_date"`
-	EndDate     time.Time              `json:"end_date"`
-	TopProducts []ProductSalesData     `json:"top_products"`
-	Categories  []CategoryData         `json:"categories"`
-	Summary     map[string]interface{} `json:"summary"`
+	Period            string                 `json:"period"`
+	StartDate         time.Time              `json:"start_date"`
+	EndDate           time.Time              `json:"end_date"`
+	Timezone          string                 `json:"timezone"`
+	Locale            string                 `json:"locale"`
+	TopProducts       []ProductSalesData     `json:"top_products"`
+	Categories        []CategoryData         `json:"categories"`
+	LowMarginProducts []string               `json:"low_margin_products"`
+	Summary           map[string]interface{} `json:"summary"`
 }
 
 // CategoryData represents category performance data
 type CategoryData struct {
-	CategoryName string  `json:"category_name"`
-	ProductCount int     `json:"product_count"`
-	Revenue      float64 `json:"revenue"`
-	OrderCount   int     `json:"order_count"`
-	AvgPrice     float64 `json:"avg_price"`
-	Percentage   float64 `json:"percentage"`
+	CategoryName  string  `json:"category_name"`
+	ProductCount  int     `json:"product_count"`
+	Revenue       float64 `json:"revenue"`
+	CostOfGoods   float64 `json:"cost_of_goods"`
+	GrossMargin   float64 `json:"gross_margin"`
+	MarginPercent float64 `json:"margin_percent"`
+	OrderCount    int     `json:"order_count"`
+	AvgPrice      float64 `json:"avg_price"`
+	Percentage    float64 `json:"percentage"`
 }
 
 // CustomerActivityReportData represents customer activity analytics
@@ -242,6 +278,9 @@ type ReportCache struct {
 	ReportType   ReportType             `json:"report_type"`
 	Parameters   map[string]interface{} `json:"parameters"`
 	Data         interface{}            `json:"data"`
+	Content      []byte                 `json:"-"`
+	FilePath     string                 `json:"file_path,omitempty"` // Key of the rendered report in storage, instead of Content
+	FileSize     int64                  `json:"file_size,omitempty"`
 	GeneratedAt  time.Time              `json:"generated_at"`
 	ExpiresAt    time.Time              `json:"expires_at"`
 	HitCount     int                    `json:"hit_count"`
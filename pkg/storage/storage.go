@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when no file is stored under a key
+var ErrNotFound = errors.New("file not found")
+
+// ErrSignedURLsUnsupported is returned by storage that cannot sign download URLs
+var ErrSignedURLsUnsupported = errors.New("signed URLs are not supported")
+
+// Storage keeps files, such as exported reports, under slash-separated keys where
+// every instance of the application can read them
+type Storage interface {
+	// Put stores content under key, replacing any file stored there
+	Put(ctx context.Context, key string, content []byte, contentType string) error
+
+	// Open returns a reader of the file under key, or ErrNotFound. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the file under key; a missing file is not an error
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns a URL the file under key can be downloaded from without
+	// credentials until expiry passes, or ErrSignedURLsUnsupported
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
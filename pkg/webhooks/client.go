@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxResponseExcerpt bounds how much of an endpoint's response is kept for the delivery log
+const maxResponseExcerpt = 1024
+
+// Result is the outcome of one delivery attempt
+type Result struct {
+	StatusCode int           // Zero when no response was received
+	Response   string        // Start of the response body
+	Duration   time.Duration // Time until the response, or the error
+	Err        error         // Set unless the endpoint responded with 2xx
+}
+
+// Delivered reports whether the endpoint accepted the event
+func (r Result) Delivered() bool {
+	return r.Err == nil
+}
+
+// Retryable reports whether a failed attempt may succeed later: the endpoint was
+// unreachable, timed out, was rate limited or had a server error. Other client
+// errors will fail again.
+func (r Result) Retryable() bool {
+	if r.Err == nil {
+		return false
+	}
+	switch {
+	case r.StatusCode == 0:
+		return true
+	case r.StatusCode == http.StatusRequestTimeout, r.StatusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return r.StatusCode >= 500
+	}
+}
+
+// Client POSTs signed events to webhook endpoints
+type Client struct {
+	httpClient *http.Client
+	userAgent  string
+	now        func() time.Time
+}
+
+// NewClient creates a client giving each attempt up to timeout
+func NewClient(timeout time.Duration, userAgent string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		userAgent:  userAgent,
+		now:        time.Now,
+	}
+}
+
+// NewClientWithHTTP creates a client sending through httpClient
+func NewClientWithHTTP(httpClient *http.Client, userAgent string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		userAgent:  userAgent,
+		now:        time.Now,
+	}
+}
+
+// Send makes one attempt to deliver event to url, signed with secret
+func (c *Client) Send(ctx context.Context, url, secret string, event Event) Result {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return Result{Err: fmt.Errorf("failed to encode webhook event: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{Err: fmt.Errorf("invalid webhook request: %w", err)}
+	}
+
+	signedAt := c.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set(IDHeader, event.ID)
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(signedAt.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, signedAt, body))
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{Duration: time.Since(start), Err: err}
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+	result := Result{
+		StatusCode: resp.StatusCode,
+		Response:   strings.ToValidUTF8(string(excerpt), ""),
+		Duration:   time.Since(start),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package webhooks
+
+import "time"
+
+// RetryPolicy sets how often a failed delivery is retried. The wait before the
+// next attempt starts at InitialBackoff and doubles after every failed attempt, up
+// to MaxBackoff. A delivery is given up after MaxAttempts.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns a policy retrying for about a day
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     6 * time.Hour,
+	}
+}
+
+// Backoff returns the wait after the given number of failed attempts
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	backoff := p.InitialBackoff
+	for i := 1; i < attempts && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// Exhausted reports whether a delivery that failed the given number of attempts
+// is given up
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// IDHeader carries the event ID, the same on every retry so receivers can
+	// drop deliveries they already processed
+	IDHeader = "X-Webhook-Id"
+	// EventHeader carries the event type
+	EventHeader = "X-Webhook-Event"
+	// TimestampHeader carries the Unix time the delivery was signed at
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the timestamp,
+	// a dot and the body, keyed by the endpoint secret
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature does not match the body
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	// ErrStaleTimestamp is returned when a delivery was signed outside the tolerance
+	ErrStaleTimestamp = errors.New("webhook timestamp outside tolerance")
+)
+
+// Event is the body POSTed to webhook endpoints
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Sign returns the signature of a body signed at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a delivery's signature and that it was signed within tolerance of
+// now, so that captured deliveries cannot be replayed later. A zero tolerance skips
+// the timestamp check.
+func Verify(secret, timestampHeader, signature string, body []byte, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp := time.Unix(unix, 0)
+
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && math.Abs(float64(now.Sub(timestamp))) > float64(tolerance) {
+		return ErrStaleTimestamp
+	}
+	return nil
+}
@@ -71,6 +71,7 @@ const (
 	JobTypeCleanup             = "cleanup"
 	JobTypeDataExport          = "data_export"
 	JobTypePaymentRetry        = "payment_retry"
+	JobTypeStockImport         = "stock_import"
 )
 
 // Priority levels
@@ -285,3 +286,38 @@ func (e *ExternalIntegrationJob) Execute(ctx context.Context) error {
 	_, err := e.executor.ExecuteIntegration(ctx, e.ServiceName, e.Operation, e.Payload)
 	return err
 }
+
+// StockImportJob applies one chunk of the rows of a stock import, from FirstRow to
+// LastRow. A chunk retried after a failure skips the rows it already applied.
+type StockImportJob struct {
+	*BaseJob
+	ImportID string `json:"import_id"`
+	FirstRow int    `json:"first_row"`
+	LastRow  int    `json:"last_row"`
+	executor StockImportExecutor
+}
+
+// StockImportExecutor interface for stock imports
+type StockImportExecutor interface {
+	ApplyStockImportChunk(ctx context.Context, importID string, firstRow, lastRow int) error
+}
+
+// NewStockImportJob creates a new stock import job
+func NewStockImportJob(importID string, firstRow, lastRow int, executor StockImportExecutor) *StockImportJob {
+	return &StockImportJob{
+		BaseJob:  NewBaseJob(JobTypeStockImport, PriorityNormal, 3),
+		ImportID: importID,
+		FirstRow: firstRow,
+		LastRow:  lastRow,
+		executor: executor,
+	}
+}
+
+// Execute runs the stock import job
+func (s *StockImportJob) Execute(ctx context.Context) error {
+	if s.executor == nil {
+		return fmt.Errorf("no stock import executor configured")
+	}
+
+	return s.executor.ApplyStockImportChunk(ctx, s.ImportID, s.FirstRow, s.LastRow)
+}
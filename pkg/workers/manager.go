@@ -2,32 +2,80 @@ package workers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	"easy-orders-backend/pkg/logger"
+
+	"github.com/google/uuid"
 )
 
-// PoolManager manages multiple worker pools for different job types
+// PoolManager manages multiple worker pools for different job types. With a job store,
+// jobs of the types registered with a factory are stored and leased by their pool
+// instead of queued in memory, so they survive restarts; other jobs stay in memory.
+// Jobs scheduled for later are dispatched only once due: stored jobs when leased, and
+// in-memory jobs by a scheduler holding them until then.
 type PoolManager struct {
 	pools  map[string]*WorkerPool
 	logger *logger.Logger
 	mu     sync.RWMutex
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	// Durable job queue
+	store       JobStore
+	queueConfig DurableQueueConfig
+	owner       string
+	factories   map[string]JobFactory
+	dispatchers map[string]*dispatcher
+	dispatchMu  sync.Mutex
+
+	// Delayed in-memory jobs
+	scheduler *scheduler
+}
+
+// dispatcher leases a pool's stored jobs until stop is closed
+type dispatcher struct {
+	stop    chan struct{}
+	stopped chan struct{}
 }
 
 // NewPoolManager creates a new pool manager
 func NewPoolManager(logger *logger.Logger) *PoolManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &PoolManager{
-		pools:  make(map[string]*WorkerPool),
-		logger: logger,
-		ctx:    ctx,
-		cancel: cancel,
+	pm := &PoolManager{
+		pools:       make(map[string]*WorkerPool),
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+		owner:       uuid.New().String(),
+		factories:   make(map[string]JobFactory),
+		dispatchers: make(map[string]*dispatcher),
 	}
+	pm.scheduler = newScheduler(pm)
+	return pm
+}
+
+// SetJobStore backs the pools with a durable job store. It must be set before the
+// pools start.
+func (pm *PoolManager) SetJobStore(store JobStore, config DurableQueueConfig) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.store = store
+	pm.queueConfig = config
+}
+
+// RegisterJobType makes the jobs of a type durable: they are stored when submitted and
+// rebuilt by factory when leased, possibly by another process after a restart
+func (pm *PoolManager) RegisterJobType(jobType string, factory JobFactory) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.factories[jobType] = factory
 }
 
 // CreatePool creates a new worker pool with the given configuration
@@ -56,7 +104,11 @@ func (pm *PoolManager) StartPool(name string) error {
 		return fmt.Errorf("pool %s not found", name)
 	}
 
-	return pool.Start(pm.ctx)
+	if err := pool.Start(pm.ctx); err != nil {
+		return err
+	}
+	pm.startDispatcher(name, pool)
+	return nil
 }
 
 // StopPool stops a specific worker pool
@@ -69,7 +121,7 @@ func (pm *PoolManager) StopPool(name string) error {
 		return fmt.Errorf("pool %s not found", name)
 	}
 
-	return pool.Stop()
+	return pm.stopPool(name, pool)
 }
 
 // StartAllPools starts all worker pools
@@ -82,6 +134,7 @@ func (pm *PoolManager) StartAllPools() error {
 			pm.logger.Error("Failed to start pool", "name", name, "error", err)
 			return err
 		}
+		pm.startDispatcher(name, pool)
 	}
 
 	pm.logger.Info("All worker pools started", "count", len(pm.pools))
@@ -95,7 +148,7 @@ func (pm *PoolManager) StopAllPools() error {
 
 	var errors []error
 	for name, pool := range pm.pools {
-		if err := pool.Stop(); err != nil {
+		if err := pm.stopPool(name, pool); err != nil {
 			pm.logger.Error("Failed to stop pool", "name", name, "error", err)
 			errors = append(errors, err)
 		}
@@ -121,7 +174,7 @@ func (pm *PoolManager) SubmitJob(job Job) error {
 		return fmt.Errorf("no pool found for job type %s (expected pool: %s)", job.GetType(), poolName)
 	}
 
-	return pool.SubmitJob(job)
+	return pm.submit(poolName, pool, job, time.Time{})
 }
 
 // SubmitJobToPool submits a job to a specific pool
@@ -134,7 +187,55 @@ func (pm *PoolManager) SubmitJobToPool(poolName string, job Job) error {
 		return fmt.Errorf("pool %s not found", poolName)
 	}
 
-	return pool.SubmitJob(job)
+	return pm.submit(poolName, pool, job, time.Time{})
+}
+
+// ScheduleJob submits a job to the pool of its type to run once runAt has passed. The
+// job is dispatched to a worker only when due; a runAt already passed runs it right away.
+func (pm *PoolManager) ScheduleJob(job Job, runAt time.Time) error {
+	poolName := pm.getPoolNameForJobType(job.GetType())
+
+	pm.mu.RLock()
+	pool, exists := pm.pools[poolName]
+	pm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("no pool found for job type %s (expected pool: %s)", job.GetType(), poolName)
+	}
+
+	return pm.submit(poolName, pool, job, runAt)
+}
+
+// ScheduleJobToPool submits a job to a specific pool to run once runAt has passed
+func (pm *PoolManager) ScheduleJobToPool(poolName string, job Job, runAt time.Time) error {
+	pm.mu.RLock()
+	pool, exists := pm.pools[poolName]
+	pm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("pool %s not found", poolName)
+	}
+
+	return pm.submit(poolName, pool, job, runAt)
+}
+
+// GetScheduledJobCount returns how many in-memory jobs wait to be due. Stored jobs
+// scheduled for later wait in the job store instead.
+func (pm *PoolManager) GetScheduledJobCount() int {
+	return pm.scheduler.pending()
+}
+
+// ResizePool changes the number of workers of a pool at runtime
+func (pm *PoolManager) ResizePool(name string, workerCount int) error {
+	pm.mu.RLock()
+	pool, exists := pm.pools[name]
+	pm.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("pool %s not found", name)
+	}
+
+	return pool.Resize(workerCount)
 }
 
 // GetPoolMetrics returns metrics for a specific pool
@@ -192,6 +293,185 @@ func (pm *PoolManager) Shutdown() error {
 	return nil
 }
 
+// submit stores a durable job for its pool to lease once runAt has passed, or queues
+// any other job in memory, holding it in the scheduler until runAt. A zero runAt runs
+// the job right away.
+func (pm *PoolManager) submit(poolName string, pool *WorkerPool, job Job, runAt time.Time) error {
+	pm.mu.RLock()
+	store := pm.store
+	_, durable := pm.factories[job.GetType()]
+	pm.mu.RUnlock()
+
+	now := time.Now()
+	if runAt.IsZero() || runAt.Before(now) {
+		runAt = now
+	}
+
+	if store == nil || !durable {
+		if !runAt.After(now) {
+			return pool.SubmitJob(job)
+		}
+		if !pool.IsRunning() {
+			return fmt.Errorf("worker pool %s is not running", poolName)
+		}
+		pm.scheduler.schedule(pool, job, runAt)
+		pm.logger.Debug("Job scheduled for pool",
+			"pool", poolName,
+			"job_id", job.GetID(),
+			"job_type", job.GetType(),
+			"run_at", runAt)
+		return nil
+	}
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.GetID(), err)
+	}
+	stored := &StoredJob{
+		ID:         job.GetID(),
+		Type:       job.GetType(),
+		Pool:       poolName,
+		Priority:   job.GetPriority(),
+		Payload:    payload,
+		MaxRetries: job.GetMaxRetries(),
+		RunAt:      runAt,
+	}
+	if err := store.Enqueue(context.Background(), stored); err != nil {
+		return fmt.Errorf("failed to store job %s: %w", job.GetID(), err)
+	}
+
+	pm.logger.Debug("Job stored for pool",
+		"pool", poolName,
+		"job_id", stored.ID,
+		"job_type", stored.Type,
+		"priority", stored.Priority,
+		"run_at", stored.RunAt)
+	return nil
+}
+
+// startDispatcher starts leasing a pool's stored jobs when the pools have a job store
+func (pm *PoolManager) startDispatcher(name string, pool *WorkerPool) {
+	if pm.store == nil {
+		return
+	}
+
+	pm.dispatchMu.Lock()
+	defer pm.dispatchMu.Unlock()
+
+	if _, running := pm.dispatchers[name]; running {
+		return
+	}
+	d := &dispatcher{stop: make(chan struct{}), stopped: make(chan struct{})}
+	pm.dispatchers[name] = d
+
+	go func() {
+		defer close(d.stopped)
+
+		ticker := time.NewTicker(pm.queueConfig.PollInterval)
+		defer ticker.Stop()
+
+		// Lease right away, resuming the jobs left when the pool last stopped
+		for {
+			pm.leaseJobs(name, pool)
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stopPool stops leasing a pool's stored jobs, stops the pool and releases the leased
+// jobs it did not get to
+func (pm *PoolManager) stopPool(name string, pool *WorkerPool) error {
+	pm.dispatchMu.Lock()
+	d, running := pm.dispatchers[name]
+	delete(pm.dispatchers, name)
+	pm.dispatchMu.Unlock()
+
+	if running {
+		close(d.stop)
+		<-d.stopped
+	}
+
+	if err := pool.Stop(); err != nil {
+		return err
+	}
+
+	for _, job := range pool.drainQueue() {
+		leased, ok := job.(*leasedJob)
+		if !ok {
+			continue
+		}
+		if err := pm.store.Release(context.Background(), leased.stored.ID, pm.owner); err != nil {
+			pm.logger.Warn("Failed to release stored job", "job_id", leased.stored.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// leaseJobs leases as many due jobs of a pool as its queue has room for and queues them
+func (pm *PoolManager) leaseJobs(name string, pool *WorkerPool) {
+	limit := pool.config.QueueSize - pool.GetQueueDepth()
+	if limit <= 0 {
+		return
+	}
+
+	stored, err := pm.store.Lease(context.Background(), name, pm.owner, limit, pm.queueConfig.VisibilityTimeout)
+	if err != nil {
+		pm.logger.Warn("Failed to lease stored jobs", "pool", name, "error", err)
+		return
+	}
+
+	for _, s := range stored {
+		// A job leased more often than it may run was lost with the process running it
+		// each time, so it is given up rather than run again
+		if s.Attempts > s.MaxRetries+1 {
+			pm.logger.Warn("Stored job abandoned after its leases expired", "job_id", s.ID, "attempts", s.Attempts)
+			if err := pm.store.Fail(context.Background(), s.ID, pm.owner, "lease expired on every attempt"); err != nil {
+				pm.logger.Warn("Failed to fail stored job", "job_id", s.ID, "error", err)
+			}
+			continue
+		}
+
+		job, err := pm.restoreJob(s)
+		if err != nil {
+			pm.logger.Error("Failed to restore stored job", "job_id", s.ID, "job_type", s.Type, "error", err)
+			if err := pm.store.Fail(context.Background(), s.ID, pm.owner, err.Error()); err != nil {
+				pm.logger.Warn("Failed to fail stored job", "job_id", s.ID, "error", err)
+			}
+			continue
+		}
+
+		leased := &leasedJob{
+			Job:        job,
+			stored:     s,
+			store:      pm.store,
+			owner:      pm.owner,
+			visibility: pm.queueConfig.VisibilityTimeout,
+			retryDelay: pool.config.RetryDelay,
+			manager:    pm,
+		}
+		if err := pool.SubmitJob(leased); err != nil {
+			// The lease expires and the job is leased again
+			pm.logger.Warn("Failed to queue leased job", "job_id", s.ID, "pool", name, "error", err)
+		}
+	}
+}
+
+// restoreJob rebuilds a stored job with the factory of its type
+func (pm *PoolManager) restoreJob(stored *StoredJob) (Job, error) {
+	pm.mu.RLock()
+	factory, ok := pm.factories[stored.Type]
+	pm.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no factory registered for job type %s", stored.Type)
+	}
+	return factory(stored.Payload)
+}
+
 // getPoolNameForJobType maps job types to pool names
 func (pm *PoolManager) getPoolNameForJobType(jobType string) string {
 	switch jobType {
@@ -201,7 +481,7 @@ func (pm *PoolManager) getPoolNameForJobType(jobType string) string {
 		return "notifications"
 	case JobTypeAuditProcessing:
 		return "audit"
-	case JobTypeBulkProcessing:
+	case JobTypeBulkProcessing, JobTypeStockImport:
 		return "bulk"
 	case JobTypeExternalIntegration:
 		return "external"
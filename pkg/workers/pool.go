@@ -78,11 +78,14 @@ type WorkerPool struct {
 	shutdown    chan struct{}
 
 	// Worker management
-	workers []*Worker
-	wg      sync.WaitGroup
+	workers      []*Worker
+	wg           sync.WaitGroup
+	nextWorkerID int
 
 	// State management
+	ctx        context.Context
 	running    bool
+	startedAt  time.Time
 	runningMux sync.RWMutex
 
 	// Metrics
@@ -99,7 +102,23 @@ type PoolMetrics struct {
 	TotalLatency   time.Duration `json:"total_latency"`
 	ActiveWorkers  int           `json:"active_workers"`
 	QueueDepth     int           `json:"queue_depth"`
-	mux            sync.RWMutex
+	WorkerCount    int           `json:"worker_count"`
+	QueueCapacity  int           `json:"queue_capacity"`
+	StartedAt      time.Time     `json:"started_at"`
+
+	// Metrics of each job type the pool ran since it was created
+	JobTypes map[string]*JobTypeMetrics `json:"job_types"`
+
+	mux sync.RWMutex
+}
+
+// JobTypeMetrics tracks the jobs of one type a pool ran
+type JobTypeMetrics struct {
+	JobsProcessed  int64         `json:"jobs_processed"`
+	JobsSucceeded  int64         `json:"jobs_succeeded"`
+	JobsFailed     int64         `json:"jobs_failed"`
+	AverageLatency time.Duration `json:"average_latency"`
+	TotalLatency   time.Duration `json:"total_latency"`
 }
 
 // NewWorkerPool creates a new worker pool
@@ -114,8 +133,8 @@ func NewWorkerPool(config *WorkerPoolConfig, logger *logger.Logger) *WorkerPool
 		jobQueue:    make(chan Job, config.QueueSize),
 		resultQueue: make(chan JobResult, config.QueueSize),
 		shutdown:    make(chan struct{}),
-		workers:     make([]*Worker, config.WorkerCount),
-		metrics:     &PoolMetrics{},
+		workers:     make([]*Worker, 0, config.WorkerCount),
+		metrics:     &PoolMetrics{JobTypes: make(map[string]*JobTypeMetrics)},
 	}
 
 	return pool
@@ -136,15 +155,10 @@ func (wp *WorkerPool) Start(ctx context.Context) error {
 		"queue_size", wp.config.QueueSize)
 
 	// Start workers
+	wp.ctx = ctx
+	wp.workers = wp.workers[:0]
 	for i := 0; i < wp.config.WorkerCount; i++ {
-		worker := NewWorker(i+1, wp.jobQueue, wp.resultQueue, wp.logger)
-		wp.workers[i] = worker
-
-		wp.wg.Add(1)
-		go func(w *Worker) {
-			defer wp.wg.Done()
-			w.Start(ctx)
-		}(worker)
+		wp.startWorker()
 	}
 
 	// Start the result processor if metrics are enabled
@@ -157,11 +171,53 @@ func (wp *WorkerPool) Start(ctx context.Context) error {
 	}
 
 	wp.running = true
+	wp.startedAt = time.Now()
 	wp.logger.Info("Worker pool started successfully", "name", wp.config.Name)
 
 	return nil
 }
 
+// startWorker starts one more worker on the pool's queue. The caller holds runningMux.
+func (wp *WorkerPool) startWorker() {
+	wp.nextWorkerID++
+	worker := NewWorker(wp.nextWorkerID, wp.jobQueue, wp.resultQueue, wp.logger)
+	wp.workers = append(wp.workers, worker)
+
+	ctx := wp.ctx
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		worker.Start(ctx)
+	}()
+}
+
+// Resize changes the number of workers of the pool without restarting it: new workers
+// start right away, and surplus workers stop once they finish the job they are running.
+// A stopped pool starts with the new number of workers.
+func (wp *WorkerPool) Resize(workerCount int) error {
+	if workerCount < 1 {
+		return fmt.Errorf("worker pool %s needs at least one worker", wp.config.Name)
+	}
+
+	wp.runningMux.Lock()
+	defer wp.runningMux.Unlock()
+
+	from := wp.config.WorkerCount
+	wp.config.WorkerCount = workerCount
+	if wp.running {
+		for len(wp.workers) < workerCount {
+			wp.startWorker()
+		}
+		for _, worker := range wp.workers[workerCount:] {
+			worker.Stop()
+		}
+		wp.workers = wp.workers[:workerCount]
+	}
+
+	wp.logger.Info("Worker pool resized", "name", wp.config.Name, "from", from, "to", workerCount, "running", wp.running)
+	return nil
+}
+
 // Stop gracefully shuts down the worker pool
 func (wp *WorkerPool) Stop() error {
 	wp.runningMux.Lock()
@@ -225,37 +281,54 @@ func (wp *WorkerPool) SubmitJobWithPriority(job Job) error {
 
 // GetMetrics returns current pool metrics
 func (wp *WorkerPool) GetMetrics() *PoolMetrics {
-	wp.metrics.mux.RLock()
-	defer wp.metrics.mux.RUnlock()
-
-	// Update queue depth
-	wp.metrics.QueueDepth = len(wp.jobQueue)
-
 	// Count active workers
+	wp.runningMux.RLock()
 	activeWorkers := 0
 	for _, worker := range wp.workers {
 		if worker.IsActive() {
 			activeWorkers++
 		}
 	}
-	wp.metrics.ActiveWorkers = activeWorkers
+	workerCount := wp.config.WorkerCount
+	startedAt := wp.startedAt
+	wp.runningMux.RUnlock()
 
-	// Calculate average latency
-	if wp.metrics.JobsProcessed > 0 {
-		wp.metrics.AverageLatency = time.Duration(int64(wp.metrics.TotalLatency) / wp.metrics.JobsProcessed)
-	}
+	wp.metrics.mux.RLock()
+	defer wp.metrics.mux.RUnlock()
 
 	// Return copy of metrics
-	return &PoolMetrics{
+	metrics := &PoolMetrics{
 		JobsProcessed:  wp.metrics.JobsProcessed,
 		JobsSucceeded:  wp.metrics.JobsSucceeded,
 		JobsFailed:     wp.metrics.JobsFailed,
 		JobsRetried:    wp.metrics.JobsRetried,
-		AverageLatency: wp.metrics.AverageLatency,
+		AverageLatency: averageLatency(wp.metrics.TotalLatency, wp.metrics.JobsProcessed),
 		TotalLatency:   wp.metrics.TotalLatency,
 		ActiveWorkers:  activeWorkers,
 		QueueDepth:     len(wp.jobQueue),
+		WorkerCount:    workerCount,
+		QueueCapacity:  cap(wp.jobQueue),
+		StartedAt:      startedAt,
+		JobTypes:       make(map[string]*JobTypeMetrics, len(wp.metrics.JobTypes)),
+	}
+	for jobType, m := range wp.metrics.JobTypes {
+		metrics.JobTypes[jobType] = &JobTypeMetrics{
+			JobsProcessed:  m.JobsProcessed,
+			JobsSucceeded:  m.JobsSucceeded,
+			JobsFailed:     m.JobsFailed,
+			AverageLatency: averageLatency(m.TotalLatency, m.JobsProcessed),
+			TotalLatency:   m.TotalLatency,
+		}
+	}
+	return metrics
+}
+
+// averageLatency returns the average latency of count jobs taking total together
+func averageLatency(total time.Duration, count int64) time.Duration {
+	if count == 0 {
+		return 0
 	}
+	return time.Duration(int64(total) / count)
 }
 
 // IsRunning returns whether the pool is currently running
@@ -270,6 +343,22 @@ func (wp *WorkerPool) GetQueueDepth() int {
 	return len(wp.jobQueue)
 }
 
+// drainQueue returns the jobs left in the queue of a stopped pool
+func (wp *WorkerPool) drainQueue() []Job {
+	var jobs []Job
+	for {
+		select {
+		case job, ok := <-wp.jobQueue:
+			if !ok {
+				return jobs
+			}
+			jobs = append(jobs, job)
+		default:
+			return jobs
+		}
+	}
+}
+
 // processResults processes job results and updates metrics
 func (wp *WorkerPool) processResults(ctx context.Context) {
 	for {
@@ -304,10 +393,20 @@ func (wp *WorkerPool) updateMetrics(result JobResult) {
 	wp.metrics.JobsProcessed++
 	wp.metrics.TotalLatency += result.Duration
 
+	jobType, ok := wp.metrics.JobTypes[result.JobType]
+	if !ok {
+		jobType = &JobTypeMetrics{}
+		wp.metrics.JobTypes[result.JobType] = jobType
+	}
+	jobType.JobsProcessed++
+	jobType.TotalLatency += result.Duration
+
 	if result.Success {
 		wp.metrics.JobsSucceeded++
+		jobType.JobsSucceeded++
 	} else {
 		wp.metrics.JobsFailed++
+		jobType.JobsFailed++
 	}
 }
 
@@ -0,0 +1,159 @@
+package workers
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// delayedJob is an in-memory job waiting in the scheduler until RunAt
+type delayedJob struct {
+	job   Job
+	pool  *WorkerPool
+	runAt time.Time
+}
+
+// delayedJobHeap orders delayed jobs by due time, highest priority first among jobs due
+// at the same time
+type delayedJobHeap []*delayedJob
+
+func (h delayedJobHeap) Len() int { return len(h) }
+
+func (h delayedJobHeap) Less(i, j int) bool {
+	if !h[i].runAt.Equal(h[j].runAt) {
+		return h[i].runAt.Before(h[j].runAt)
+	}
+	return h[i].job.GetPriority() > h[j].job.GetPriority()
+}
+
+func (h delayedJobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *delayedJobHeap) Push(x interface{}) { *h = append(*h, x.(*delayedJob)) }
+
+func (h *delayedJobHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return job
+}
+
+// scheduler holds in-memory jobs until they are due and then queues them to their pool,
+// so no worker is tied up waiting for a job's time
+type scheduler struct {
+	manager *PoolManager
+	jobs    delayedJobHeap
+	mu      sync.Mutex
+	wake    chan struct{}
+	once    sync.Once
+}
+
+func newScheduler(manager *PoolManager) *scheduler {
+	return &scheduler{
+		manager: manager,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// schedule holds a job until runAt, starting the scheduler with the first job
+func (s *scheduler) schedule(pool *WorkerPool, job Job, runAt time.Time) {
+	s.once.Do(func() {
+		go s.run(s.manager.ctx)
+	})
+
+	s.mu.Lock()
+	heap.Push(&s.jobs, &delayedJob{job: job, pool: pool, runAt: runAt})
+	s.mu.Unlock()
+
+	// Wake the scheduler in case the job is due before the one it waits for
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pending returns how many jobs wait in the scheduler
+func (s *scheduler) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// run queues jobs to their pool as they become due until ctx is done. Jobs still
+// waiting then are dropped with the pool manager.
+func (s *scheduler) run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		next := s.dispatchDue(time.Now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if next.IsZero() {
+			timer.Reset(time.Hour)
+		} else {
+			timer.Reset(time.Until(next))
+		}
+
+		select {
+		case <-ctx.Done():
+			if dropped := s.pending(); dropped > 0 {
+				s.manager.logger.Warn("Scheduled jobs dropped with the pool manager", "count", dropped)
+			}
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// dispatchDue queues the jobs due at now and returns when the next job is due, or the
+// zero time when no job waits
+func (s *scheduler) dispatchDue(now time.Time) time.Time {
+	s.mu.Lock()
+	var due []*delayedJob
+	for len(s.jobs) > 0 && !s.jobs[0].runAt.After(now) {
+		due = append(due, heap.Pop(&s.jobs).(*delayedJob))
+	}
+	var next time.Time
+	if len(s.jobs) > 0 {
+		next = s.jobs[0].runAt
+	}
+	s.mu.Unlock()
+
+	var retry []*delayedJob
+	for _, d := range due {
+		err := d.pool.SubmitJob(d.job)
+		switch {
+		case err == nil:
+		case d.pool.IsRunning():
+			// The pool's queue is full, so the job waits for it to make room
+			delay := d.pool.config.RetryDelay
+			if delay <= 0 {
+				delay = time.Second
+			}
+			d.runAt = now.Add(delay)
+			retry = append(retry, d)
+		default:
+			s.manager.logger.Error("Failed to queue scheduled job", "job_id", d.job.GetID(),
+				"job_type", d.job.GetType(), "pool", d.pool.config.Name, "error", err)
+		}
+	}
+	if len(retry) == 0 {
+		return next
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range retry {
+		heap.Push(&s.jobs, d)
+	}
+	return s.jobs[0].runAt
+}
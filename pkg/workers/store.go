@@ -0,0 +1,154 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StoredJob is a job kept in a JobStore. Payload is the job encoded as JSON, decoded
+// again by the JobFactory of its type. Attempts counts the leases of the job,
+// including the current one.
+type StoredJob struct {
+	ID         string
+	Type       string
+	Pool       string
+	Priority   int
+	Payload    []byte
+	Attempts   int
+	MaxRetries int
+	RunAt      time.Time
+}
+
+// JobStore keeps jobs durably so they outlive the process that submitted them. Pools
+// lease the jobs that are due; a lease hides a job from other owners until it expires,
+// so the jobs of a process that stopped without settling them are leased again. A
+// job is settled only by the owner of its lease.
+type JobStore interface {
+	// Enqueue stores a job to run once RunAt has passed
+	Enqueue(ctx context.Context, job *StoredJob) error
+	// Lease leases up to limit jobs of a pool to owner for visibility, highest priority
+	// and oldest due first. Jobs are due when queued with RunAt passed, or when their
+	// lease expired. Each lease counts an attempt.
+	Lease(ctx context.Context, pool, owner string, limit int, visibility time.Duration) ([]*StoredJob, error)
+	// ExtendLease renews the lease of a running job, returning false when owner no
+	// longer holds it
+	ExtendLease(ctx context.Context, id, owner string, visibility time.Duration) (bool, error)
+	// Release returns a leased job that never ran to the queue, without counting the attempt
+	Release(ctx context.Context, id, owner string) error
+	Complete(ctx context.Context, id, owner string) error
+	// Retry queues a failed job to run again at runAt
+	Retry(ctx context.Context, id, owner string, runAt time.Time, lastError string) error
+	// Fail gives up on a job, setting it aside as dead with its error
+	Fail(ctx context.Context, id, owner string, lastError string) error
+}
+
+// JobFactory rebuilds a job of a type, with the dependencies it runs with, from the
+// payload it was stored with
+type JobFactory func(payload []byte) (Job, error)
+
+// DurableQueueConfig tunes how pools lease jobs from a JobStore
+type DurableQueueConfig struct {
+	// PollInterval is how often each pool leases the jobs that are due
+	PollInterval time.Duration
+	// VisibilityTimeout is how long a leased job is hidden from other owners. It is
+	// renewed at half the timeout while the job runs.
+	VisibilityTimeout time.Duration
+}
+
+// DecodeJob decodes the payload of a stored job into job, which keeps the unexported
+// dependencies it was created with. JobFactory implementations create the job with its
+// dependencies and decode the rest.
+func DecodeJob(payload []byte, job Job) (Job, error) {
+	if err := json.Unmarshal(payload, job); err != nil {
+		return nil, fmt.Errorf("failed to decode %T: %w", job, err)
+	}
+	return job, nil
+}
+
+// leasedJob is a stored job leased to a pool. It renews its lease while it runs and
+// settles it in the store once it finished.
+type leasedJob struct {
+	Job
+	stored     *StoredJob
+	store      JobStore
+	owner      string
+	visibility time.Duration
+	retryDelay time.Duration
+	manager    *PoolManager
+}
+
+// GetRetryCount returns how many times the job ran before this attempt
+func (l *leasedJob) GetRetryCount() int {
+	return l.stored.Attempts - 1
+}
+
+// Execute runs the job, renewing its lease until it returns, and settles it: completed,
+// retried after a backoff doubling with each attempt, or failed once out of retries
+func (l *leasedJob) Execute(ctx context.Context) error {
+	done := make(chan struct{})
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		l.heartbeat(done)
+	}()
+
+	err := l.Job.Execute(ctx)
+	close(done)
+	<-heartbeatStopped
+
+	// Settle even when the pool is shutting down, so the job is not run again
+	settleCtx := context.Background()
+	switch {
+	case err == nil:
+		if settleErr := l.store.Complete(settleCtx, l.stored.ID, l.owner); settleErr != nil {
+			l.manager.logger.Error("Failed to complete stored job", "job_id", l.stored.ID, "error", settleErr)
+		}
+	case l.stored.Attempts <= l.stored.MaxRetries:
+		runAt := time.Now().Add(l.backoff())
+		if settleErr := l.store.Retry(settleCtx, l.stored.ID, l.owner, runAt, err.Error()); settleErr != nil {
+			l.manager.logger.Error("Failed to schedule stored job retry", "job_id", l.stored.ID, "error", settleErr)
+		} else {
+			l.manager.logger.Info("Stored job scheduled for retry", "job_id", l.stored.ID,
+				"job_type", l.stored.Type, "attempt", l.stored.Attempts, "run_at", runAt)
+		}
+	default:
+		if settleErr := l.store.Fail(settleCtx, l.stored.ID, l.owner, err.Error()); settleErr != nil {
+			l.manager.logger.Error("Failed to fail stored job", "job_id", l.stored.ID, "error", settleErr)
+		} else {
+			l.manager.logger.Warn("Stored job failed after its last retry", "job_id", l.stored.ID,
+				"job_type", l.stored.Type, "attempts", l.stored.Attempts)
+		}
+	}
+	return err
+}
+
+// heartbeat renews the job's lease at half the visibility timeout until done is closed
+func (l *leasedJob) heartbeat(done <-chan struct{}) {
+	ticker := time.NewTicker(l.visibility / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			held, err := l.store.ExtendLease(context.Background(), l.stored.ID, l.owner, l.visibility)
+			if err != nil {
+				l.manager.logger.Warn("Failed to extend stored job lease", "job_id", l.stored.ID, "error", err)
+			} else if !held {
+				l.manager.logger.Warn("Stored job lease lost while running", "job_id", l.stored.ID)
+			}
+		}
+	}
+}
+
+// backoff returns the pool's retry delay doubled for each attempt after the first
+func (l *leasedJob) backoff() time.Duration {
+	delay := l.retryDelay
+	for i := 1; i < l.stored.Attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return delay
+}
@@ -2,6 +2,7 @@ package workers
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,6 +16,8 @@ type Worker struct {
 	resultQueue chan<- JobResult
 	logger      *logger.Logger
 	active      int32 // atomic flag for active status
+	quit        chan struct{}
+	quitOnce    sync.Once
 }
 
 // NewWorker creates a new worker
@@ -24,6 +27,7 @@ func NewWorker(id int, jobQueue <-chan Job, resultQueue chan<- JobResult, logger
 		jobQueue:    jobQueue,
 		resultQueue: resultQueue,
 		logger:      logger,
+		quit:        make(chan struct{}),
 	}
 }
 
@@ -32,10 +36,21 @@ func (w *Worker) Start(ctx context.Context) {
 	w.logger.Debug("Worker started", "worker_id", w.id)
 
 	for {
+		// A stopped worker takes no further job, even with jobs queued
+		select {
+		case <-w.quit:
+			w.logger.Debug("Worker stopped", "worker_id", w.id)
+			return
+		default:
+		}
+
 		select {
 		case <-ctx.Done():
 			w.logger.Debug("Worker stopped due to context cancellation", "worker_id", w.id)
 			return
+		case <-w.quit:
+			w.logger.Debug("Worker stopped", "worker_id", w.id)
+			return
 		case job, ok := <-w.jobQueue:
 			if !ok {
 				w.logger.Debug("Worker stopped due to closed job queue", "worker_id", w.id)
@@ -106,6 +121,13 @@ func (w *Worker) processJob(ctx context.Context, job Job) {
 	}
 }
 
+// Stop makes the worker return once the job it is running, if any, is done
+func (w *Worker) Stop() {
+	w.quitOnce.Do(func() {
+		close(w.quit)
+	})
+}
+
 // IsActive returns whether the worker is currently processing a job
 func (w *Worker) IsActive() bool {
 	return atomic.LoadInt32(&w.active) == 1
@@ -0,0 +1,121 @@
+package documents_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"easy-orders-backend/pkg/documents"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pickList is a document with rows rows
+func pickList(rows int) *documents.Document {
+	doc := &documents.Document{
+		Title:   "Pick List",
+		Details: []documents.Field{{Label: "Orders", Value: "2"}},
+		Table: documents.Table{
+			Columns: []string{"Location", "SKU", "Product", "Qty"},
+			Footer:  []string{"", "", "Total units", strconv.Itoa(rows)},
+		},
+		Notes: []string{"Gift message:\nHappy birthday (again)!"},
+	}
+	for i := 0; i < rows; i++ {
+		doc.Table.Rows = append(doc.Table.Rows, []string{fmt.Sprintf("A-%02d-1", i), fmt.Sprintf("SKU-%d", i), "Widget", "1"})
+	}
+	return doc
+}
+
+// Test RenderPDF - The PDF is well formed: the cross-reference table points at each object
+func TestRenderPDF_CrossReference(t *testing.T) {
+	pdf := documents.RenderPDF(pickList(3))
+
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n")))
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	require.NotEmpty(t, offsets)
+	for i, match := range offsets {
+		offset, err := strconv.Atoi(string(match[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+// Test RenderPDF - Text is escaped, and the table header repeats on every page
+func TestRenderPDF_Pages(t *testing.T) {
+	pdf := string(documents.RenderPDF(pickList(120)))
+
+	assert.Contains(t, pdf, `(Happy birthday \(again\)!) Tj`)
+	assert.Contains(t, pdf, "/Count 3 ")
+	assert.Contains(t, pdf, "(Page 3 of 3) Tj")
+	assert.Equal(t, 3, strings.Count(pdf, "(Location  SKU"))
+	assert.Contains(t, pdf, "(A-119-1")
+}
+
+// Test RenderPDF - Cells wider than the page wrap within their column
+func TestRenderPDF_WrapsLongCells(t *testing.T) {
+	doc := pickList(1)
+	doc.Table.Rows[0][2] = strings.Repeat("Extra long product name ", 8)
+
+	pdf := string(documents.RenderPDF(doc))
+
+	for _, line := range regexp.MustCompile(`\((.*)\) Tj`).FindAllStringSubmatch(pdf, -1) {
+		assert.LessOrEqual(t, len(line[1]), 95, line[1])
+	}
+	assert.Greater(t, strings.Count(pdf, "Extra long product name"), 1)
+}
+
+// Test RenderHTML - Values are escaped into a printable page
+func TestRenderHTML(t *testing.T) {
+	doc := pickList(1)
+	doc.Table.Rows[0][2] = "<b>Widget</b>"
+
+	html, err := documents.RenderHTML(doc)
+
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<title>Pick List</title>")
+	assert.Contains(t, string(html), "<td>&lt;b&gt;Widget&lt;/b&gt;</td>")
+	assert.Contains(t, string(html), "<tfoot><tr><td></td><td></td><td>Total units</td><td>1</td></tr></tfoot>")
+}
+
+// Test Render - JSON is left to callers
+func TestRender_UnsupportedFormat(t *testing.T) {
+	_, err := documents.Render(pickList(1), documents.FormatJSON)
+	assert.Error(t, err)
+}
+
+// Test Render - Sections follow the main table, each with its own title and table
+func TestRender_Sections(t *testing.T) {
+	doc := &documents.Document{
+		Title:   "Sales Report",
+		Details: []documents.Field{{Label: "Orders", Value: "3"}},
+		Sections: []documents.Section{
+			{Title: "By Channel", Table: documents.Table{Columns: []string{"Channel", "Orders"}, Rows: [][]string{{"web", "3"}}}},
+			{Title: "Totals", Details: []documents.Field{{Label: "Net", Value: "90.00"}}},
+		},
+	}
+
+	pdf := string(documents.RenderPDF(doc))
+	assert.Contains(t, pdf, "(By Channel) Tj")
+	assert.Contains(t, pdf, "(Channel  Orders) Tj")
+	assert.Contains(t, pdf, "(Net: 90.00) Tj")
+	assert.Less(t, strings.Index(pdf, "(Orders: 3) Tj"), strings.Index(pdf, "(By Channel) Tj"))
+
+	html, err := documents.RenderHTML(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<h2>By Channel</h2>")
+	assert.Contains(t, string(html), "<h2>Totals</h2>")
+	assert.Equal(t, 1, strings.Count(string(html), "<table>"))
+}
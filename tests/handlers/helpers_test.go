@@ -0,0 +1,25 @@
+package handlers_test
+
+import (
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns a router that renders errors like the server and serves each
+// request as the given user, as the auth middleware would
+func newTestRouter(logger *logger.Logger, userID string, role models.UserRole) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.NewErrorMiddleware(i18n.NewTranslator(), logger).Handler())
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("user_role", role)
+		c.Next()
+	})
+	return router
+}
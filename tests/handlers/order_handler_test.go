@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// OrderHandlerTestSuite defines the test suite for OrderHandler
+type OrderHandlerTestSuite struct {
+	suite.Suite
+	orderService *mocks.MockOrderService
+	logger       *logger.Logger
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrderHandlerTestSuite) SetupTest() {
+	suite.orderService = new(mocks.MockOrderService)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrderHandlerTestSuite) TearDownTest() {
+	suite.orderService.AssertExpectations(suite.T())
+}
+
+// router serves the order cancellation routes as the given user
+func (suite *OrderHandlerTestSuite) router(userID string, role models.UserRole) *gin.Engine {
+	handler := handlers.NewOrderHandler(suite.orderService, nil, suite.logger)
+	validationMw := middleware.NewValidationMiddleware(suite.logger)
+
+	router := newTestRouter(suite.logger, userID, role)
+	router.PATCH("/orders/:id/items/cancel",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		validationMw.ValidateJSON(services.CancelOrderItemsRequest{}),
+		handler.CancelOrderItems,
+	)
+	router.PATCH("/orders/:id/cancel",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		handler.CancelOrder,
+	)
+	return router
+}
+
+// cancelItems sends a request cancelling one unit of a product of the order
+func cancelItems(router *gin.Engine, orderID string) *httptest.ResponseRecorder {
+	body := `{"items":[{"product_id":"product-1","quantity":1}]}`
+	req := httptest.NewRequest(http.MethodPatch, "/orders/"+orderID+"/items/cancel", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// cancelOrder sends a request cancelling the order
+func cancelOrder(router *gin.Engine, orderID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/orders/"+orderID+"/cancel", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// Test CancelOrderItems - Customers cannot cancel, and so refund, another customer's order
+func (suite *OrderHandlerTestSuite) TestCancelOrderItems_AnotherUsersOrderForbidden() {
+	// Mock expectations
+	suite.orderService.On("GetOrder", mock.Anything, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+
+	// Execute
+	recorder := cancelItems(suite.router("intruder-1", models.UserRoleCustomer), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.orderService.AssertNotCalled(suite.T(), "CancelOrderItems", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test CancelOrderItems - The order's owner cancels its items
+func (suite *OrderHandlerTestSuite) TestCancelOrderItems_Owner() {
+	// Mock expectations
+	suite.orderService.On("GetOrder", mock.Anything, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+	suite.orderService.On("CancelOrderItems", mock.Anything, "order-1", mock.AnythingOfType("services.CancelOrderItemsRequest")).
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+
+	// Execute
+	recorder := cancelItems(suite.router("owner-1", models.UserRoleCustomer), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
+
+// Test CancelOrderItems - Admins cancel items of any order without the ownership lookup
+func (suite *OrderHandlerTestSuite) TestCancelOrderItems_Admin() {
+	// Mock expectations
+	suite.orderService.On("CancelOrderItems", mock.Anything, "order-1", mock.AnythingOfType("services.CancelOrderItemsRequest")).
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+
+	// Execute
+	recorder := cancelItems(suite.router("admin-1", models.UserRoleAdmin), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.orderService.AssertNotCalled(suite.T(), "GetOrder", mock.Anything, mock.Anything)
+}
+
+// Test CancelOrder - Customers cannot cancel, and so refund, another customer's order
+func (suite *OrderHandlerTestSuite) TestCancelOrder_AnotherUsersOrderForbidden() {
+	// Mock expectations
+	suite.orderService.On("GetOrder", mock.Anything, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+
+	// Execute
+	recorder := cancelOrder(suite.router("intruder-1", models.UserRoleCustomer), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.orderService.AssertNotCalled(suite.T(), "CancelOrder", mock.Anything, mock.Anything)
+}
+
+// Test CancelOrder - The order's owner cancels it
+func (suite *OrderHandlerTestSuite) TestCancelOrder_Owner() {
+	// Mock expectations
+	suite.orderService.On("GetOrder", mock.Anything, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1", Status: models.OrderStatusPaid}, nil)
+	suite.orderService.On("CancelOrder", mock.Anything, "order-1").Return(nil)
+
+	// Execute
+	recorder := cancelOrder(suite.router("owner-1", models.UserRoleCustomer), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
+
+// Test CancelOrder - Admins cancel any order without the ownership lookup
+func (suite *OrderHandlerTestSuite) TestCancelOrder_Admin() {
+	// Mock expectations
+	suite.orderService.On("CancelOrder", mock.Anything, "order-1").Return(nil)
+
+	// Execute
+	recorder := cancelOrder(suite.router("admin-1", models.UserRoleAdmin), "order-1")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.orderService.AssertNotCalled(suite.T(), "GetOrder", mock.Anything, mock.Anything)
+}
+
+// TestOrderHandlerTestSuite runs the test suite
+func TestOrderHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderHandlerTestSuite))
+}
@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"easy-orders-backend/internal/api/handlers"
+	"easy-orders-backend/internal/api/middleware"
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// PaymentHandlerTestSuite defines the test suite for PaymentHandler
+type PaymentHandlerTestSuite struct {
+	suite.Suite
+	paymentService *mocks.MockPaymentService
+	orderService   *mocks.MockOrderService
+	logger         *logger.Logger
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentHandlerTestSuite) SetupTest() {
+	suite.paymentService = new(mocks.MockPaymentService)
+	suite.orderService = new(mocks.MockOrderService)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PaymentHandlerTestSuite) TearDownTest() {
+	suite.paymentService.AssertExpectations(suite.T())
+	suite.orderService.AssertExpectations(suite.T())
+}
+
+// router serves the customer payment routes as the given user
+func (suite *PaymentHandlerTestSuite) router(userID string, role models.UserRole) *gin.Engine {
+	handler := handlers.NewPaymentHandler(suite.paymentService, suite.orderService, suite.logger)
+	validationMw := middleware.NewValidationMiddleware(suite.logger)
+
+	router := newTestRouter(suite.logger, userID, role)
+	router.POST("/payments/:id/resume",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		handler.ResumePayment,
+	)
+	router.GET("/payments/:id/refunds",
+		validationMw.ValidatePathParams(map[string]string{"id": "required"}),
+		handler.ListRefunds,
+	)
+	return router
+}
+
+// expectPaymentOfOwner makes payment-1 a payment of an order of owner-1
+func (suite *PaymentHandlerTestSuite) expectPaymentOfOwner() {
+	suite.paymentService.On("GetPayment", mock.Anything, "payment-1").
+		Return(&services.PaymentResponse{ID: "payment-1", OrderID: "order-1", Status: models.PaymentStatusHeld}, nil)
+	suite.orderService.On("GetOrder", mock.Anything, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", UserID: "owner-1"}, nil)
+}
+
+// send sends a request to the router and returns its response
+func send(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+// Test ResumePayment - Customers cannot retry the charge of another customer's payment
+func (suite *PaymentHandlerTestSuite) TestResumePayment_AnotherUsersPaymentForbidden() {
+	suite.expectPaymentOfOwner()
+
+	// Execute
+	recorder := send(suite.router("intruder-1", models.UserRoleCustomer), http.MethodPost, "/payments/payment-1/resume")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.paymentService.AssertNotCalled(suite.T(), "ResumePayment", mock.Anything, mock.Anything)
+}
+
+// Test ResumePayment - The owner of the payment's order resumes it
+func (suite *PaymentHandlerTestSuite) TestResumePayment_Owner() {
+	suite.expectPaymentOfOwner()
+	suite.paymentService.On("ResumePayment", mock.Anything, "payment-1").
+		Return(&services.PaymentResponse{ID: "payment-1", OrderID: "order-1", Status: models.PaymentStatusCompleted}, nil)
+
+	// Execute
+	recorder := send(suite.router("owner-1", models.UserRoleCustomer), http.MethodPost, "/payments/payment-1/resume")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
+
+// Test ResumePayment - Unknown payments are not found
+func (suite *PaymentHandlerTestSuite) TestResumePayment_NotFound() {
+	suite.paymentService.On("GetPayment", mock.Anything, "payment-9").Return(nil, errors.New("payment not found"))
+
+	// Execute
+	recorder := send(suite.router("owner-1", models.UserRoleCustomer), http.MethodPost, "/payments/payment-9/resume")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.paymentService.AssertNotCalled(suite.T(), "ResumePayment", mock.Anything, mock.Anything)
+}
+
+// Test ListRefunds - Customers cannot see the refunds of another customer's payment
+func (suite *PaymentHandlerTestSuite) TestListRefunds_AnotherUsersPaymentForbidden() {
+	suite.expectPaymentOfOwner()
+
+	// Execute
+	recorder := send(suite.router("intruder-1", models.UserRoleCustomer), http.MethodGet, "/payments/payment-1/refunds")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.paymentService.AssertNotCalled(suite.T(), "ListRefunds", mock.Anything, mock.Anything)
+}
+
+// Test ListRefunds - Admins list the refunds of any payment
+func (suite *PaymentHandlerTestSuite) TestListRefunds_Admin() {
+	suite.paymentService.On("ListRefunds", mock.Anything, "payment-1").
+		Return(&services.PaymentRefundsResponse{PaymentID: "payment-1"}, nil)
+
+	// Execute
+	recorder := send(suite.router("admin-1", models.UserRoleAdmin), http.MethodGet, "/payments/payment-1/refunds")
+
+	// Assert
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
+
+// TestPaymentHandlerTestSuite runs the test suite
+func TestPaymentHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentHandlerTestSuite))
+}
@@ -0,0 +1,105 @@
+package i18n_test
+
+import (
+	"strings"
+	"testing"
+
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// TranslatorTestSuite defines the test suite for the i18n translator and its catalogs
+type TranslatorTestSuite struct {
+	suite.Suite
+	translator *i18n.Translator
+}
+
+// SetupTest runs before each test in the suite
+func (suite *TranslatorTestSuite) SetupTest() {
+	suite.translator = i18n.NewTranslator()
+}
+
+// Test catalogs - Every error type has an English message and at least one translation
+func (suite *TranslatorTestSuite) TestCatalogs_CoverEveryErrorType() {
+	for _, errorType := range apperrors.AllErrorTypes {
+		key := i18n.ErrorKey(string(errorType))
+
+		assert.True(suite.T(), suite.translator.HasMessage(i18n.DefaultLocale, key), "%s has no English message", errorType)
+		assert.NotEmpty(suite.T(), suite.otherLocalesWith(key), "%s has no translation besides English", errorType)
+	}
+}
+
+// Test catalogs - Every English message has at least one translation
+func (suite *TranslatorTestSuite) TestCatalogs_CoverEveryEnglishMessage() {
+	for key := range suite.translator.Catalog(i18n.DefaultLocale) {
+		assert.NotEmpty(suite.T(), suite.otherLocalesWith(key), "%s has no translation besides English", key)
+	}
+}
+
+// Test catalogs - Translations keep the placeholders of the English message
+func (suite *TranslatorTestSuite) TestCatalogs_KeepPlaceholders() {
+	english := suite.translator.Catalog(i18n.DefaultLocale)
+	for _, locale := range suite.translator.Locales() {
+		for key, message := range suite.translator.Catalog(locale) {
+			source, ok := english[key]
+			if !assert.True(suite.T(), ok, "%s/%s is not in the English catalog", locale, key) {
+				continue
+			}
+			if strings.Contains(source, "{order_id}") {
+				assert.Contains(suite.T(), message, "{order_id}", "%s/%s drops {order_id}", locale, key)
+			}
+		}
+	}
+}
+
+// Test Translate - Missing keys fall back to English, then to the key
+func (suite *TranslatorTestSuite) TestTranslate_Fallbacks() {
+	translator := i18n.NewTranslatorWithCatalogs(map[string]i18n.Catalog{
+		"en": {"greeting": "Hello {name}", "farewell": "Goodbye"},
+		"es": {"greeting": "Hola {name}"},
+	})
+
+	assert.Equal(suite.T(), "Hola Ana", translator.Translate("es", "greeting", map[string]string{"name": "Ana"}))
+	assert.Equal(suite.T(), "Goodbye", translator.Translate("es", "farewell", nil))
+	assert.Equal(suite.T(), "Goodbye", translator.Translate("de", "farewell", nil))
+	assert.Equal(suite.T(), "missing.key", translator.Translate("es", "missing.key", nil))
+}
+
+// Test Match - Accept-Language negotiation
+func (suite *TranslatorTestSuite) TestMatch_AcceptLanguage() {
+	tests := map[string]string{
+		"":                            "en",
+		"fr":                          "fr",
+		"es-MX":                       "es",
+		"de-DE,fr;q=0.5,es;q=0.8":     "es",
+		"fr-CA,fr;q=0.9,en;q=0.8":     "fr",
+		"es;q=0, en":                  "en",
+		"*":                           "en",
+		"de, it;q=0.9":                "en",
+		"FR_fr":                       "fr",
+		"en-US,en;q=0.9,es;q=invalid": "en",
+	}
+
+	for header, expected := range tests {
+		assert.Equal(suite.T(), expected, suite.translator.Match(header), "Accept-Language %q", header)
+	}
+}
+
+// otherLocalesWith returns the non-default locales whose catalog defines key
+func (suite *TranslatorTestSuite) otherLocalesWith(key string) []string {
+	var locales []string
+	for _, locale := range suite.translator.Locales() {
+		if locale != i18n.DefaultLocale && suite.translator.HasMessage(locale, key) {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// TestTranslatorTestSuite runs the test suite
+func TestTranslatorTestSuite(t *testing.T) {
+	suite.Run(t, new(TranslatorTestSuite))
+}
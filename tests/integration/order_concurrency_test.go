@@ -66,8 +66,12 @@ func (suite *OrderConcurrencyTestSuite) SetupTest() {
 
 	// Initialize services
 	suite.inventoryService = services.NewInventoryService(
+		services.LowStockSettings{}, // Static low-stock thresholds
 		suite.inventoryRepo,
 		suite.productRepo,
+		nil, // Relocations are not audited
+		nil, // No inventory history
+		nil, // No stock webhook subscribers
 		suite.log,
 	)
 
@@ -79,6 +83,21 @@ func (suite *OrderConcurrencyTestSuite) SetupTest() {
 		suite.inventoryRepo,
 		suite.userRepo,
 		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
 		suite.log,
 	)
 }
@@ -200,33 +219,14 @@ func (suite *OrderConcurrencyTestSuite) TestConcurrentOrdersForSameProduct() {
 
 // TestConcurrentOrdersMultipleProducts tests concurrent orders for multiple products
 func (suite *OrderConcurrencyTestSuite) TestConcurrentOrdersMultipleProducts() {
-	// Create test user
-	user := testutil.CreateTestUser(nil)
-	err := suite.userRepo.Create(suite.ctx, user)
-	require.NoError(suite.T(), err)
-
-	// Create two products with limited stock
-	product1 := testutil.CreateTestProduct(func(p *models.Product) {
-		p.IsActive = true
-		p.Price = 30.00
-	})
-	inventory1 := testutil.CreateTestInventory(product1.ID, func(i *models.Inventory) {
-		i.Quantity = 50
-		i.Available = 50
-	})
-	err = suite.productRepo.CreateWithInventory(suite.ctx, product1, inventory1)
-	require.NoError(suite.T(), err)
-
-	product2 := testutil.CreateTestProduct(func(p *models.Product) {
-		p.IsActive = true
-		p.Price = 40.00
-	})
-	inventory2 := testutil.CreateTestInventory(product2.ID, func(i *models.Inventory) {
-		i.Quantity = 50
-		i.Available = 50
-	})
-	err = suite.productRepo.CreateWithInventory(suite.ctx, product2, inventory2)
+	// One user and two products with limited stock
+	scenario, err := testutil.NewScenario().
+		WithUser().
+		WithProduct(50, func(p *models.Product) { p.Price = 30.00 }).
+		WithProduct(50, func(p *models.Product) { p.Price = 40.00 }).
+		Seed(suite.ctx, suite.db)
 	require.NoError(suite.T(), err)
+	user, product1, product2 := scenario.User(), scenario.Product(0), scenario.Product(1)
 
 	// Launch concurrent orders with both products
 	numOrders := 15
@@ -383,6 +383,64 @@ func (suite *OrderConcurrencyTestSuite) TestTransactionRollback() {
 	assert.Empty(suite.T(), orders, "No orders should be created")
 }
 
+// TestPurchaseLimit tests that a customer cannot buy more units of a limited product
+// than its purchase limit across orders, including concurrent ones, and that cancelled
+// orders do not count towards it
+func (suite *OrderConcurrencyTestSuite) TestPurchaseLimit() {
+	user := testutil.CreateTestUser(nil)
+	require.NoError(suite.T(), suite.userRepo.Create(suite.ctx, user))
+
+	product := testutil.CreateTestProduct(func(p *models.Product) {
+		p.IsActive = true
+		p.Price = 100.00
+		p.PurchaseLimit = 3
+		p.PurchaseLimitDays = 30
+	})
+	inventory := testutil.CreateTestInventory(product.ID, func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Available = 100
+	})
+	require.NoError(suite.T(), suite.productRepo.CreateWithInventory(suite.ctx, product, inventory))
+
+	order := func(quantity int) (*services.OrderResponse, error) {
+		return suite.orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{
+			UserID: user.ID,
+			Items:  []services.OrderItem{{ProductID: product.ID, Quantity: quantity}},
+		})
+	}
+
+	first, err := order(2)
+	require.NoError(suite.T(), err)
+
+	// Two more units would take the customer past the limit
+	_, err = order(2)
+	require.Error(suite.T(), err)
+	assert.True(suite.T(), errors.IsErrorType(err, errors.ErrorTypePurchaseLimit))
+
+	// Concurrent orders of the last unit: only one gets it
+	var wg sync.WaitGroup
+	var placed, refused int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := order(1); err == nil {
+				atomic.AddInt32(&placed, 1)
+			} else if errors.IsErrorType(err, errors.ErrorTypePurchaseLimit) {
+				atomic.AddInt32(&refused, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(suite.T(), int32(1), placed)
+	assert.Equal(suite.T(), int32(4), refused)
+
+	// Cancelled orders give their units back to the customer's allowance
+	require.NoError(suite.T(), suite.orderService.CancelOrder(suite.ctx, first.ID))
+	_, err = order(2)
+	assert.NoError(suite.T(), err)
+}
+
 // TestConcurrentOrdersWithDifferentQuantities tests various order sizes concurrently
 func (suite *OrderConcurrencyTestSuite) TestConcurrentOrdersWithDifferentQuantities() {
 	// Create test user
@@ -437,6 +495,95 @@ func (suite *OrderConcurrencyTestSuite) TestConcurrentOrdersWithDifferentQuantit
 	assert.Equal(suite.T(), 100-inv.Reserved, inv.Available, "Available should be correct")
 }
 
+// TestConcurrentOrdersForShardedProduct tests that orders of a product whose stock is
+// split into shards reserve all of it without overselling, falling back to the
+// inventory row when no shard has enough, and that released stock is handed back
+// out to the shards by a rebalance
+func (suite *OrderConcurrencyTestSuite) TestConcurrentOrdersForShardedProduct() {
+	scenario, err := testutil.NewScenario().
+		WithUser().
+		WithProduct(100).
+		Seed(suite.ctx, suite.db)
+	require.NoError(suite.T(), err)
+	user, product := scenario.User(), scenario.Product(0)
+
+	// 25 units on each of 4 shards
+	shardRepo := repository.NewInventoryShardRepository(suite.db, suite.log)
+	configured, err := shardRepo.Configure(suite.ctx, product.ID, 4, nil)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), configured)
+	assert.Equal(suite.T(), 100, configured.Available, "Sharding leaves the sellable stock unchanged")
+
+	// Two orders fit on each shard, and the 5 units left on each make two more
+	numOrders := 20
+	var wg sync.WaitGroup
+	var successCount int32
+	var mu sync.Mutex
+	successfulOrderIDs := make([]string, 0)
+
+	for i := 0; i < numOrders; i++ {
+		wg.Add(1)
+		go func(orderNum int) {
+			defer wg.Done()
+
+			response, err := suite.orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{
+				UserID: user.ID,
+				Items:  []services.OrderItem{{ProductID: product.ID, Quantity: 10}},
+				Notes:  fmt.Sprintf("Sharded order #%d", orderNum),
+			})
+			if err != nil {
+				assert.True(suite.T(), errors.IsErrorType(err, errors.ErrorTypeInsufficientStock),
+					"Error should be insufficient stock, got: %v", err)
+				return
+			}
+			atomic.AddInt32(&successCount, 1)
+			mu.Lock()
+			successfulOrderIDs = append(successfulOrderIDs, response.ID)
+			mu.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(10), successCount, "Expected 10 successful orders")
+	inv, err := suite.inventoryRepo.GetByProductID(suite.ctx, product.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100, inv.Reserved, "All stock should be reserved")
+	assert.Equal(suite.T(), 0, inv.Available, "No stock should be available")
+
+	// A cancelled order returns its stock to the inventory row, and the rebalance
+	// spreads it over the shards
+	require.NotEmpty(suite.T(), successfulOrderIDs)
+	require.NoError(suite.T(), suite.orderService.CancelOrder(suite.ctx, successfulOrderIDs[0]))
+
+	rebalanced, err := shardRepo.Rebalance(suite.ctx)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, rebalanced)
+
+	shards, err := shardRepo.List(suite.ctx, product.ID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), shards, 4)
+	onShards := 0
+	for _, shard := range shards {
+		assert.InDelta(suite.T(), 2.5, shard.Available, 0.5, "Shards should be even")
+		onShards += shard.Available
+	}
+	assert.Equal(suite.T(), 10, onShards)
+
+	inv, err = suite.inventoryRepo.GetByProductID(suite.ctx, product.ID)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 90, inv.Reserved, "Stock on shards is not reserved")
+	assert.Equal(suite.T(), 10, inv.Available, "Stock on shards is available")
+
+	// Unsharding gathers the stock back onto the inventory row
+	unsharded, err := shardRepo.Configure(suite.ctx, product.ID, 0, nil)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 10, unsharded.Available)
+	shards, err = shardRepo.List(suite.ctx, product.ID)
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), shards)
+}
+
 // TestOrderServiceTestSuite runs the test suite
 func TestOrderConcurrencyTestSuite(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,188 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/testutil"
+
+	"go.uber.org/zap"
+)
+
+// benchmarkStock is stocked for every benchmark product, so no run sells out
+const benchmarkStock = 100_000_000
+
+// orderBenchmark holds an order service against the test database
+type orderBenchmark struct {
+	orderService services.OrderService
+	productRepo  repository.ProductRepository
+	userID       string
+}
+
+// newOrderBenchmark connects to the test database, empties it and builds the order
+// service the way the concurrency tests do, with a single customer
+func newOrderBenchmark(b *testing.B) *orderBenchmark {
+	b.Helper()
+	if testing.Short() {
+		b.Skip("Skipping integration benchmarks in short mode")
+	}
+
+	db, err := testutil.SetupTestDatabase()
+	if err != nil {
+		b.Fatalf("failed to connect to test database: %v", err)
+	}
+	b.Cleanup(func() { testutil.TeardownTestDatabase(db) })
+
+	if err := testutil.RunMigrations(db); err != nil {
+		b.Fatalf("failed to run migrations: %v", err)
+	}
+	testutil.CleanDatabase(db)
+
+	// Info logging on every order would dominate the measurements
+	log := &logger.Logger{SugaredLogger: zap.NewNop().Sugar()}
+
+	orderRepo := repository.NewOrderRepository(db, log)
+	productRepo := repository.NewProductRepository(db, log)
+	inventoryRepo := repository.NewInventoryRepository(db, log)
+	userRepo := repository.NewUserRepository(db, log)
+
+	inventoryService := services.NewInventoryService(
+		services.LowStockSettings{}, // Static low-stock thresholds
+		inventoryRepo,
+		productRepo,
+		nil, // Relocations are not audited
+		nil, // No inventory history
+		nil, // No stock webhook subscribers
+		log,
+	)
+
+	orderService := services.NewOrderService(
+		db,
+		orderRepo,
+		repository.NewOrderItemRepository(db, log),
+		productRepo,
+		inventoryRepo,
+		userRepo,
+		inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		log,
+	)
+
+	user := testutil.CreateTestUser(nil)
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		b.Fatalf("failed to create user: %v", err)
+	}
+
+	return &orderBenchmark{
+		orderService: orderService,
+		productRepo:  productRepo,
+		userID:       user.ID,
+	}
+}
+
+// createProducts creates count products with plenty of stock and returns one order
+// line of a single unit for each
+func (ob *orderBenchmark) createProducts(b *testing.B, count int) []services.OrderItem {
+	b.Helper()
+
+	items := make([]services.OrderItem, count)
+	for i := range items {
+		product := testutil.CreateTestProduct(func(p *models.Product) {
+			p.Price = 10.00
+		})
+		inventory := testutil.CreateTestInventory(product.ID, func(inv *models.Inventory) {
+			inv.Quantity = benchmarkStock
+			inv.Available = benchmarkStock
+			inv.MaxStock = benchmarkStock
+		})
+		if err := ob.productRepo.CreateWithInventory(context.Background(), product, inventory); err != nil {
+			b.Fatalf("failed to create product: %v", err)
+		}
+		items[i] = services.OrderItem{ProductID: product.ID, Quantity: 1}
+	}
+	return items
+}
+
+// createOrder places one order of items, failing the benchmark on error
+func (ob *orderBenchmark) createOrder(b *testing.B, items []services.OrderItem) {
+	if _, err := ob.orderService.CreateOrder(context.Background(), services.CreateOrderRequest{
+		UserID: ob.userID,
+		Items:  items,
+	}); err != nil {
+		b.Errorf("failed to create order: %v", err)
+	}
+}
+
+// BenchmarkCreateOrder measures sequential order placement for orders of a growing
+// number of lines, which is where per-item product and inventory lookups show up
+func BenchmarkCreateOrder(b *testing.B) {
+	ob := newOrderBenchmark(b)
+
+	for _, itemCount := range []int{1, 5, 20, 50} {
+		items := ob.createProducts(b, itemCount)
+
+		b.Run(fmt.Sprintf("items=%d", itemCount), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ob.createOrder(b, items)
+			}
+		})
+	}
+}
+
+// BenchmarkCreateOrderContention measures concurrent order placement. Under high
+// contention every goroutine orders the same products and queues on their inventory
+// row locks; under low contention each goroutine orders products of its own.
+func BenchmarkCreateOrderContention(b *testing.B) {
+	ob := newOrderBenchmark(b)
+	const itemCount = 5
+
+	for _, parallelism := range []int{1, 4} {
+		workers := parallelism * runtime.GOMAXPROCS(0)
+		shared := ob.createProducts(b, itemCount)
+		own := make([][]services.OrderItem, workers)
+		for i := range own {
+			own[i] = ob.createProducts(b, itemCount)
+		}
+
+		for _, contention := range []string{"high", "low"} {
+			b.Run(fmt.Sprintf("contention=%s/parallelism=%d", contention, parallelism), func(b *testing.B) {
+				var next atomic.Int64
+				b.SetParallelism(parallelism)
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					items := shared
+					if contention == "low" {
+						items = own[int(next.Add(1)-1)%workers]
+					}
+					for pb.Next() {
+						ob.createOrder(b, items)
+					}
+				})
+			})
+		}
+	}
+}
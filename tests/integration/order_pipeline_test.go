@@ -0,0 +1,699 @@
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeClock is a clock that only moves when the test advances it
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+// Now returns the current fake time
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// gatewayOutcome is the scripted result of one fake gateway call
+type gatewayOutcome struct {
+	failureType payments.PaymentFailureType
+	message     string
+	timeout     bool
+}
+
+var (
+	gatewayCompleted = gatewayOutcome{}
+	gatewayTimeout   = gatewayOutcome{timeout: true}
+)
+
+func gatewayDecline(failureType payments.PaymentFailureType, message string) gatewayOutcome {
+	return gatewayOutcome{failureType: failureType, message: message}
+}
+
+// fakePaymentGateway answers payment requests with scripted outcomes, in order, and
+// completes every request once the script runs out. It never sleeps: a timeout is
+// reported as an expired deadline right away.
+type fakePaymentGateway struct {
+	mu       sync.Mutex
+	outcomes []gatewayOutcome
+	requests []payments.GatewayPaymentRequest
+}
+
+// Script queues the outcomes of the next gateway calls
+func (g *fakePaymentGateway) Script(outcomes ...gatewayOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.outcomes = append(g.outcomes, outcomes...)
+}
+
+// Requests returns the payment requests the gateway received
+func (g *fakePaymentGateway) Requests() []payments.GatewayPaymentRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]payments.GatewayPaymentRequest(nil), g.requests...)
+}
+
+func (g *fakePaymentGateway) ProcessPayment(ctx context.Context, req *payments.GatewayPaymentRequest) (*payments.GatewayPaymentResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.requests = append(g.requests, *req)
+	outcome := gatewayCompleted
+	if len(g.outcomes) > 0 {
+		outcome = g.outcomes[0]
+		g.outcomes = g.outcomes[1:]
+	}
+
+	switch {
+	case outcome.timeout:
+		return nil, fmt.Errorf("gateway did not respond: %w", context.DeadlineExceeded)
+	case outcome.failureType != "":
+		return &payments.GatewayPaymentResponse{
+			Status:         "failed",
+			Amount:         req.Amount,
+			Currency:       req.Currency,
+			FailureType:    outcome.failureType,
+			FailureMessage: outcome.message,
+		}, nil
+	default:
+		return &payments.GatewayPaymentResponse{
+			TransactionID: fmt.Sprintf("fake_%d", len(g.requests)),
+			Status:        "completed",
+			Amount:        req.Amount,
+			Currency:      req.Currency,
+			ProcessingFee: req.Amount * 0.029,
+		}, nil
+	}
+}
+
+func (g *fakePaymentGateway) RefundPayment(ctx context.Context, req *payments.GatewayRefundRequest) (*payments.GatewayRefundResponse, error) {
+	return &payments.GatewayRefundResponse{Status: "completed", Amount: req.Amount, Currency: req.Currency}, nil
+}
+
+func (g *fakePaymentGateway) GetPaymentStatus(ctx context.Context, gatewayTransactionID string) (*payments.GatewayPaymentStatus, error) {
+	return &payments.GatewayPaymentStatus{TransactionID: gatewayTransactionID, Status: "completed"}, nil
+}
+
+func (g *fakePaymentGateway) GetGatewayType() payments.PaymentGatewayType {
+	return payments.GatewayTypeMock
+}
+
+func (g *fakePaymentGateway) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+// notificationSink is a notification service that keeps sent notifications in memory
+type notificationSink struct {
+	mu   sync.Mutex
+	sent []services.SendNotificationRequest
+}
+
+// Sent returns the notifications sent so far
+func (n *notificationSink) Sent() []services.SendNotificationRequest {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]services.SendNotificationRequest(nil), n.sent...)
+}
+
+func (n *notificationSink) SendNotification(ctx context.Context, req services.SendNotificationRequest) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, req)
+	return nil
+}
+
+func (n *notificationSink) SendBatch(ctx context.Context, req services.SendBatchNotificationsRequest) (*services.BatchNotificationResponse, error) {
+	response := &services.BatchNotificationResponse{Total: len(req.Notifications)}
+	for i, notification := range req.Notifications {
+		_ = n.SendNotification(ctx, notification)
+		response.Sent++
+		response.Results = append(response.Results, services.BatchNotificationResult{Index: i, UserID: notification.UserID, Status: "sent"})
+	}
+	return response, nil
+}
+
+func (n *notificationSink) GetUserNotifications(ctx context.Context, userID string, req services.ListNotificationsRequest) (*services.ListNotificationsResponse, error) {
+	return &services.ListNotificationsResponse{Notifications: []*services.NotificationResponse{}, Offset: req.Offset, Limit: req.Limit}, nil
+}
+
+func (n *notificationSink) MarkRead(ctx context.Context, userID, notificationID string) (*services.NotificationResponse, error) {
+	return nil, fmt.Errorf("notification %s not found", notificationID)
+}
+
+func (n *notificationSink) RecordReceipt(ctx context.Context, notificationID string, req services.NotificationReceiptRequest) (*services.NotificationResponse, error) {
+	return nil, fmt.Errorf("notification %s not found", notificationID)
+}
+
+// OrderPipelineTestSuite runs orders through placement, confirmation and payment
+// against the test database, with a scripted payment gateway, a fake clock and an
+// in-memory notification sink
+type OrderPipelineTestSuite struct {
+	suite.Suite
+	db             *database.DB
+	ctx            context.Context
+	log            *logger.Logger
+	clock          *fakeClock
+	gateway        *fakePaymentGateway
+	idempotency    *payments.IdempotencyManager
+	notifications  *notificationSink
+	orderService   services.OrderService
+	paymentService services.PaymentService
+	ledgerService  services.LedgerService
+	orderRepo      repository.OrderRepository
+	paymentRepo    repository.PaymentRepository
+	productRepo    repository.ProductRepository
+	inventoryRepo  repository.InventoryRepository
+	userRepo       repository.UserRepository
+}
+
+// pipelineRetry holds transiently failed payments for half an hour, retrying after a
+// minute and doubling from there
+var pipelineRetry = services.PaymentRetrySettings{
+	Window:  30 * time.Minute,
+	Backoff: time.Minute,
+}
+
+// SetupSuite runs once before all tests
+func (suite *OrderPipelineTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+	suite.log = testutil.NewTestLogger()
+
+	db, err := testutil.SetupTestDatabase()
+	require.NoError(suite.T(), err)
+	suite.db = db
+
+	err = testutil.RunMigrations(db)
+	require.NoError(suite.T(), err)
+}
+
+// SetupTest runs before each test
+func (suite *OrderPipelineTestSuite) SetupTest() {
+	testutil.CleanDatabase(suite.db)
+
+	// Payments record their creation time from the database, so the fake clock starts now
+	suite.clock = newFakeClock(time.Now())
+	suite.gateway = &fakePaymentGateway{}
+	suite.notifications = &notificationSink{}
+
+	suite.orderRepo = repository.NewOrderRepository(suite.db, suite.log)
+	suite.paymentRepo = repository.NewPaymentRepository(suite.db, suite.log)
+	suite.productRepo = repository.NewProductRepository(suite.db, suite.log)
+	suite.inventoryRepo = repository.NewInventoryRepository(suite.db, suite.log)
+	suite.userRepo = repository.NewUserRepository(suite.db, suite.log)
+
+	inventoryService := services.NewInventoryService(
+		services.LowStockSettings{}, // Static low-stock thresholds
+		suite.inventoryRepo,
+		suite.productRepo,
+		nil, // Relocations are not audited
+		nil, // No inventory history
+		nil, // No stock webhook subscribers
+		suite.log,
+	)
+
+	notifierSettings, err := services.NewOrderStatusNotificationSettings(
+		[]string{"confirmed", "cancelled"},
+		[]string{"email", "in_app"},
+	)
+	require.NoError(suite.T(), err)
+	notifier := services.NewOrderStatusNotifier(notifierSettings, suite.notifications, suite.userRepo, i18n.NewTranslator(), suite.log)
+
+	suite.ledgerService = services.NewLedgerService(repository.NewLedgerRepository(suite.db, suite.log), suite.log)
+
+	gateways := payments.NewPaymentGatewayManager(suite.log)
+	gateways.RegisterGateway(suite.gateway)
+	suite.idempotency = payments.NewIdempotencyManager(time.Hour, nil, suite.log) // Records kept in memory
+	processor := payments.NewPaymentProcessor(gateways, payments.NewCircuitBreakerManager(suite.log), suite.idempotency, suite.log)
+
+	suite.paymentService = services.NewPaymentServiceWithClock(
+		pipelineRetry,
+		suite.paymentRepo,
+		suite.orderRepo,
+		repository.NewPaymentAttemptRepository(suite.db, suite.log),
+		repository.NewRefundRepository(suite.db, suite.log),
+		nil, // No activity tracking
+		suite.ledgerService,
+		nil, // No webhooks
+		nil, // No fraud screening
+		nil, // No stage metrics
+		gateways,
+		processor,
+		suite.clock.Now,
+		suite.log,
+	)
+
+	suite.orderService = services.NewOrderService(
+		suite.db,
+		suite.orderRepo,
+		repository.NewOrderItemRepository(suite.db, suite.log),
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		notifier,
+		suite.paymentService,
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.log,
+	)
+}
+
+// TearDownTest runs after each test
+func (suite *OrderPipelineTestSuite) TearDownTest() {
+	suite.idempotency.Stop()
+}
+
+// TearDownSuite runs once after all tests
+func (suite *OrderPipelineTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		testutil.TeardownTestDatabase(suite.db)
+	}
+}
+
+// placeConfirmedOrder places and confirms an order of quantity units of a product
+// with ten in stock, priced at 25.00
+func (suite *OrderPipelineTestSuite) placeConfirmedOrder(quantity int) (*models.User, *models.Product, *services.OrderResponse) {
+	scenario, err := testutil.NewScenario().
+		WithUser().
+		WithProduct(10, func(p *models.Product) { p.Price = 25.00 }).
+		Seed(suite.ctx, suite.db)
+	require.NoError(suite.T(), err)
+	user, product := scenario.User(), scenario.Product(0)
+
+	order, err := suite.orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{
+		UserID:        user.ID,
+		Items:         []services.OrderItem{{ProductID: product.ID, Quantity: quantity}},
+		PaymentMethod: models.PaymentMethodCreditCard,
+	})
+	require.NoError(suite.T(), err)
+	suite.Equal(models.OrderStatusPending, order.Status)
+	suite.Equal(25.00*float64(quantity), order.Total)
+
+	_, err = suite.orderService.UpdateOrderStatus(suite.ctx, order.ID, models.OrderStatusConfirmed)
+	require.NoError(suite.T(), err)
+
+	return user, product, order
+}
+
+// pay takes payment for an order through the payment service
+func (suite *OrderPipelineTestSuite) pay(order *services.OrderResponse) (*services.PaymentResponse, error) {
+	return suite.paymentService.ProcessPayment(suite.ctx, services.ProcessPaymentRequest{
+		OrderID:     order.ID,
+		Amount:      order.Total,
+		PaymentType: string(models.PaymentMethodCreditCard),
+	})
+}
+
+// assertStock checks the stock of a product
+func (suite *OrderPipelineTestSuite) assertStock(productID string, available, reserved int) {
+	inventory, err := suite.inventoryRepo.GetByProductID(suite.ctx, productID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), inventory)
+	suite.Equal(10, inventory.Quantity)
+	suite.Equal(available, inventory.Available)
+	suite.Equal(reserved, inventory.Reserved)
+}
+
+// assertOrderStatus checks the stored status of an order
+func (suite *OrderPipelineTestSuite) assertOrderStatus(orderID string, status models.OrderStatus) {
+	order, err := suite.orderRepo.GetByID(suite.ctx, orderID)
+	require.NoError(suite.T(), err)
+	require.NotNil(suite.T(), order)
+	suite.Equal(status, order.Status)
+}
+
+// orderPayment returns the only payment taken for an order
+func (suite *OrderPipelineTestSuite) orderPayment(orderID string) *models.Payment {
+	orderPayments, err := suite.paymentRepo.GetByOrderID(suite.ctx, orderID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), orderPayments, 1)
+	return orderPayments[0]
+}
+
+// assertNotified checks the notification types sent to a user, in order, on each channel
+func (suite *OrderPipelineTestSuite) assertNotified(userID string, types ...models.NotificationType) {
+	var expected []string
+	for _, notificationType := range types {
+		for _, channel := range []models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelInApp} {
+			expected = append(expected, string(notificationType)+"/"+string(channel))
+		}
+	}
+
+	var sent []string
+	for _, notification := range suite.notifications.Sent() {
+		suite.Equal(userID, notification.UserID)
+		sent = append(sent, notification.Type+"/"+notification.Channel)
+	}
+	suite.Equal(expected, sent)
+}
+
+// assertLedgerBalance checks the balance of a ledger account, or of every account of a kind
+func (suite *OrderPipelineTestSuite) assertLedgerBalance(account string, balance float64) {
+	response, err := suite.ledgerService.GetBalances(suite.ctx, services.LedgerBalanceRequest{Account: account})
+	require.NoError(suite.T(), err)
+	suite.InDelta(balance, response.Balance, 0.001, account)
+}
+
+// TestPipeline_Success tests an order that is paid on the first attempt
+func (suite *OrderPipelineTestSuite) TestPipeline_Success() {
+	user, product, order := suite.placeConfirmedOrder(4)
+	suite.assertStock(product.ID, 6, 4)
+
+	payment, err := suite.pay(order)
+
+	require.NoError(suite.T(), err)
+	suite.Equal(models.PaymentStatusCompleted, payment.Status)
+	suite.Equal(100.00, payment.Amount)
+
+	// One gateway call for the order total
+	requests := suite.gateway.Requests()
+	require.Len(suite.T(), requests, 1)
+	suite.Equal(100.00, requests[0].Amount)
+	suite.Equal(order.ID, requests[0].OrderReference)
+
+	stored := suite.orderPayment(order.ID)
+	suite.Equal("fake_1", stored.GatewayTxnID)
+	suite.Equal(2.90, stored.ProcessingFee)
+	suite.Equal(1, stored.AttemptCount)
+
+	// The order is paid and keeps its reservation until it ships
+	suite.assertOrderStatus(order.ID, models.OrderStatusPaid)
+	suite.assertStock(product.ID, 6, 4)
+	suite.assertNotified(user.ID, models.NotificationTypeOrderConfirmed)
+
+	// The payment and its fee are posted to the ledger, which balances
+	suite.assertLedgerBalance(models.LedgerAccountRevenue, 100.00)
+	suite.assertLedgerBalance(models.LedgerAccountGatewayClearing, 97.10)
+	suite.assertLedgerBalance(models.LedgerAccountProcessingFees, 2.90)
+
+	report, err := suite.ledgerService.CheckConsistency(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.True(report.Consistent)
+}
+
+// TestPipeline_ConcurrentPayments tests paying an order from several requests at once:
+// the balance is checked with the order locked, counting payments still in progress,
+// so only one payment is taken
+func (suite *OrderPipelineTestSuite) TestPipeline_ConcurrentPayments() {
+	_, _, order := suite.placeConfirmedOrder(4)
+
+	const attempts = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := suite.pay(order)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	suite.Equal(1, succeeded)
+
+	// One gateway call and one payment for the order total
+	suite.Len(suite.gateway.Requests(), 1)
+	payment := suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusCompleted, payment.Status)
+	suite.Equal(100.00, payment.Amount)
+
+	suite.assertOrderStatus(order.ID, models.OrderStatusPaid)
+	suite.assertLedgerBalance(models.LedgerAccountRevenue, 100.00)
+}
+
+// TestPipeline_PlacementRollback tests an order whose second item is short of stock:
+// placement is one transaction, so the first item's reservation is rolled back with
+// the order, and nothing reaches the payment stage
+func (suite *OrderPipelineTestSuite) TestPipeline_PlacementRollback() {
+	scenario, err := testutil.NewScenario().
+		WithUser().
+		WithProduct(10, func(p *models.Product) { p.Price = 25.00 }).
+		WithProduct(1, func(p *models.Product) { p.Price = 40.00 }).
+		Seed(suite.ctx, suite.db)
+	require.NoError(suite.T(), err)
+	user, plenty, scarce := scenario.User(), scenario.Product(0), scenario.Product(1)
+
+	order, err := suite.orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{
+		UserID: user.ID,
+		Items: []services.OrderItem{
+			{ProductID: plenty.ID, Quantity: 3},
+			{ProductID: scarce.ID, Quantity: 2},
+		},
+		PaymentMethod: models.PaymentMethodCreditCard,
+	})
+
+	suite.Nil(order)
+	require.Error(suite.T(), err)
+	suite.True(errors.IsErrorType(err, errors.ErrorTypeInsufficientStock), "got: %v", err)
+
+	// Neither item keeps a reservation, and no order or payment was written
+	suite.assertStock(plenty.ID, 10, 0)
+	suite.assertStock(scarce.ID, 1, 0)
+
+	var orderCount, paymentCount int64
+	require.NoError(suite.T(), suite.db.Model(&models.Order{}).Where("user_id = ?", user.ID).Count(&orderCount).Error)
+	require.NoError(suite.T(), suite.db.Model(&models.Payment{}).Count(&paymentCount).Error)
+	suite.Zero(orderCount)
+	suite.Zero(paymentCount)
+	suite.Empty(suite.gateway.Requests())
+	suite.Empty(suite.notifications.Sent())
+}
+
+// TestPipeline_PaymentDeclined tests an order whose card is declined
+func (suite *OrderPipelineTestSuite) TestPipeline_PaymentDeclined() {
+	suite.gateway.Script(gatewayDecline(payments.FailureTypeInsufficientFunds, "Insufficient funds"))
+	user, product, order := suite.placeConfirmedOrder(2)
+
+	payment, err := suite.pay(order)
+
+	// A hard decline fails the payment without holding it for retry
+	suite.Nil(payment)
+	require.Error(suite.T(), err)
+	suite.Contains(err.Error(), "Insufficient funds")
+	suite.Len(suite.gateway.Requests(), 1)
+
+	stored := suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusFailed, stored.Status)
+	suite.Equal("Insufficient funds", stored.FailureReason)
+	suite.Nil(stored.NextRetryAt)
+
+	// The order stays confirmed with its stock reserved, so it can still be paid
+	suite.assertOrderStatus(order.ID, models.OrderStatusConfirmed)
+	suite.assertStock(product.ID, 8, 2)
+
+	// Nothing retries a declined payment, however much time passes
+	suite.clock.Advance(time.Hour)
+	processed, err := suite.paymentService.RetryHeldPayments(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.Equal(0, processed)
+	suite.Len(suite.gateway.Requests(), 1)
+
+	// The customer gives up and is told the order is cancelled; nothing was paid, so
+	// nothing is refunded, and the reservation goes back to stock
+	require.NoError(suite.T(), suite.orderService.CancelOrder(suite.ctx, order.ID))
+	suite.assertOrderStatus(order.ID, models.OrderStatusCancelled)
+	suite.assertStock(product.ID, 10, 0)
+	suite.assertNotified(user.ID, models.NotificationTypeOrderConfirmed, models.NotificationTypeOrderCancelled)
+}
+
+// TestPipeline_CancelPaidOrder tests a paid order that is cancelled an item at a time,
+// each cancellation refunding the customer and restocking the items
+func (suite *OrderPipelineTestSuite) TestPipeline_CancelPaidOrder() {
+	user, product, order := suite.placeConfirmedOrder(4)
+	_, err := suite.pay(order)
+	require.NoError(suite.T(), err)
+
+	// Cancelling one unit refunds its price and returns it to stock
+	reduced, err := suite.orderService.CancelOrderItems(suite.ctx, order.ID, services.CancelOrderItemsRequest{
+		Items: []services.CancelOrderItem{{ProductID: product.ID, Quantity: 1}},
+	})
+	require.NoError(suite.T(), err)
+	suite.Equal(75.00, reduced.Total)
+	suite.Equal(25.00, reduced.Refunded)
+	suite.assertOrderStatus(order.ID, models.OrderStatusPaid)
+	suite.assertStock(product.ID, 7, 3)
+
+	stored := suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusCompleted, stored.Status)
+	suite.Equal(25.00, stored.RefundedAmount)
+
+	// Cancelling the order refunds the rest of the payment and restocks the rest
+	require.NoError(suite.T(), suite.orderService.CancelOrder(suite.ctx, order.ID))
+	suite.assertOrderStatus(order.ID, models.OrderStatusCancelled)
+	suite.assertStock(product.ID, 10, 0)
+
+	stored = suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusRefunded, stored.Status)
+	suite.Equal(100.00, stored.RefundedAmount)
+
+	suite.assertNotified(user.ID,
+		models.NotificationTypeOrderConfirmed,
+		models.NotificationTypeOrderItemsCancelled, models.NotificationTypeOrderRefunded,
+		models.NotificationTypeOrderCancelled, models.NotificationTypeOrderRefunded)
+
+	// Both refunds are posted to the ledger, which only keeps the processing fee
+	suite.assertLedgerBalance(models.LedgerAccountRevenue, 0)
+	suite.assertLedgerBalance(models.LedgerAccountProcessingFees, 2.90)
+
+	report, err := suite.ledgerService.CheckConsistency(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.True(report.Consistent)
+}
+
+// TestPipeline_CancelDiscountedItem tests cancelling an item of an order with a checkout
+// discount: the cancelled item takes its share of the discount, so only what was paid
+// for it is refunded
+func (suite *OrderPipelineTestSuite) TestPipeline_CancelDiscountedItem() {
+	scenario, err := testutil.NewScenario().
+		WithUser().
+		WithProduct(10, func(p *models.Product) { p.Price = 90.00 }).
+		WithProduct(10, func(p *models.Product) { p.Price = 10.00 }).
+		Seed(suite.ctx, suite.db)
+	require.NoError(suite.T(), err)
+	user, expensive, cheap := scenario.User(), scenario.Product(0), scenario.Product(1)
+
+	order, err := suite.orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{
+		UserID: user.ID,
+		Items: []services.OrderItem{
+			{ProductID: expensive.ID, Quantity: 1},
+			{ProductID: cheap.ID, Quantity: 1},
+		},
+		PaymentMethod: models.PaymentMethodCreditCard,
+	})
+	require.NoError(suite.T(), err)
+
+	// A checkout promotion took 50.00 off the 100.00 of items
+	require.NoError(suite.T(), suite.db.Model(&models.Order{}).Where("id = ?", order.ID).
+		Updates(map[string]interface{}{"discount_amount": 50.00, "total_amount": 50.00}).Error)
+	order.Total = 50.00
+	_, err = suite.orderService.UpdateOrderStatus(suite.ctx, order.ID, models.OrderStatusConfirmed)
+	require.NoError(suite.T(), err)
+	_, err = suite.pay(order)
+	require.NoError(suite.T(), err)
+
+	// The 90.00 item carried 45.00 of the discount, so 45.00 was paid for it
+	reduced, err := suite.orderService.CancelOrderItems(suite.ctx, order.ID, services.CancelOrderItemsRequest{
+		Items: []services.CancelOrderItem{{ProductID: expensive.ID, Quantity: 1}},
+	})
+	require.NoError(suite.T(), err)
+	suite.Equal(5.00, reduced.Discount)
+	suite.Equal(5.00, reduced.Total)
+	suite.Equal(45.00, reduced.Refunded)
+
+	stored := suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusCompleted, stored.Status)
+	suite.Equal(45.00, stored.RefundedAmount)
+	suite.assertOrderStatus(order.ID, models.OrderStatusPaid)
+}
+
+// TestPipeline_GatewayTimeout tests an order whose gateway keeps timing out until the
+// retry window closes
+func (suite *OrderPipelineTestSuite) TestPipeline_GatewayTimeout() {
+	suite.gateway.Script(gatewayTimeout, gatewayTimeout)
+	user, product, order := suite.placeConfirmedOrder(3)
+
+	payment, err := suite.pay(order)
+
+	// A timeout holds the payment for a retry after the backoff
+	require.NoError(suite.T(), err)
+	suite.Equal(models.PaymentStatusHeld, payment.Status)
+	require.NotNil(suite.T(), payment.RetryAt)
+	suite.WithinDuration(suite.clock.Now().Add(time.Minute), *payment.RetryAt, time.Second)
+
+	// Nothing is retried before the backoff has passed
+	suite.clock.Advance(30 * time.Second)
+	processed, err := suite.paymentService.RetryHeldPayments(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.Equal(0, processed)
+	suite.Len(suite.gateway.Requests(), 1)
+
+	// The retry times out as well and is held again, backing off for twice as long
+	suite.clock.Advance(time.Minute)
+	processed, err = suite.paymentService.RetryHeldPayments(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.Equal(1, processed)
+	suite.Len(suite.gateway.Requests(), 2)
+
+	stored := suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusHeld, stored.Status)
+	suite.Equal(2, stored.AttemptCount)
+	require.NotNil(suite.T(), stored.NextRetryAt)
+	suite.WithinDuration(suite.clock.Now().Add(2*time.Minute), *stored.NextRetryAt, time.Second)
+
+	// While held, the order and its reservation stay in place
+	suite.assertOrderStatus(order.ID, models.OrderStatusConfirmed)
+	suite.assertStock(product.ID, 7, 3)
+
+	// Once the retry window has closed the payment fails without calling the gateway
+	suite.clock.Advance(pipelineRetry.Window)
+	processed, err = suite.paymentService.RetryHeldPayments(suite.ctx)
+	require.NoError(suite.T(), err)
+	suite.Equal(1, processed)
+	suite.Len(suite.gateway.Requests(), 2)
+
+	stored = suite.orderPayment(order.ID)
+	suite.Equal(models.PaymentStatusFailed, stored.Status)
+	suite.Contains(stored.FailureReason, "retry window expired")
+
+	suite.assertOrderStatus(order.ID, models.OrderStatusConfirmed)
+	suite.assertStock(product.ID, 7, 3)
+	suite.assertNotified(user.ID, models.NotificationTypeOrderConfirmed)
+}
+
+// TestOrderPipelineTestSuite runs the test suite
+func TestOrderPipelineTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	suite.Run(t, new(OrderPipelineTestSuite))
+}
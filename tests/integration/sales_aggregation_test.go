@@ -0,0 +1,196 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/database"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// SalesAggregationTestSuite runs the sales report aggregations against a real database
+type SalesAggregationTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	db        *database.DB
+	orderRepo repository.OrderRepository
+
+	day    time.Time
+	widget *models.Product
+	gadget *models.Product
+}
+
+// SetupSuite runs once before all tests
+func (suite *SalesAggregationTestSuite) SetupSuite() {
+	suite.ctx = context.Background()
+
+	db, err := testutil.SetupTestDatabase()
+	require.NoError(suite.T(), err)
+	suite.db = db
+
+	err = testutil.RunMigrations(db)
+	require.NoError(suite.T(), err)
+}
+
+// SetupTest seeds the sales of one day, 2025-06-10 UTC:
+//   - alice, a returning customer, pays 100 by card for 2 widgets and is refunded 25
+//   - bob, a new customer, is shipped 1 widget and 4 gadgets for 340, without a payment method
+//
+// Around it, alice's first sale a month earlier, bob's pending order, and alice's
+// order two hours after midnight UTC, still the evening of the 10th in New York,
+// which was refunded in full before partial refunds were recorded.
+func (suite *SalesAggregationTestSuite) SetupTest() {
+	testutil.CleanDatabase(suite.db)
+	suite.orderRepo = repository.NewOrderRepository(suite.db, testutil.NewTestLogger())
+	suite.day = time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	alice := testutil.CreateTestUser(func(u *models.User) { u.Email = "alice@example.com" })
+	bob := testutil.CreateTestUser(func(u *models.User) { u.Email = "bob@example.com" })
+	suite.create(alice, bob)
+
+	suite.widget = testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Widget"; p.CostPrice = 20 })
+	suite.gadget = testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Gadget"; p.CostPrice = 35 })
+	suite.create(suite.widget, suite.gadget)
+
+	first := suite.order(alice.ID, models.OrderStatusDelivered, suite.day.AddDate(0, -1, 0), 50, models.PaymentMethodCreditCard)
+	suite.create(testutil.CreateTestOrderItem(first.ID, suite.widget.ID, func(i *models.OrderItem) { i.Quantity = 1; i.TotalPrice = 50 }))
+
+	paid := suite.order(alice.ID, models.OrderStatusPaid, suite.day.Add(10*time.Hour), 100, models.PaymentMethodCreditCard)
+	suite.create(
+		testutil.CreateTestOrderItem(paid.ID, suite.widget.ID, func(i *models.OrderItem) { i.Quantity = 2; i.UnitPrice = 50; i.TotalPrice = 100 }),
+		testutil.CreateTestPayment(paid.ID, func(p *models.Payment) {
+			p.Amount = 100
+			p.Status = models.PaymentStatusCompleted
+			p.RefundedAmount = 25
+		}),
+	)
+
+	shipped := suite.order(bob.ID, models.OrderStatusShipped, suite.day.Add(23*time.Hour+30*time.Minute), 340, "")
+	suite.create(
+		testutil.CreateTestOrderItem(shipped.ID, suite.widget.ID, func(i *models.OrderItem) { i.Quantity = 1; i.UnitPrice = 100; i.TotalPrice = 100 }),
+		testutil.CreateTestOrderItem(shipped.ID, suite.gadget.ID, func(i *models.OrderItem) { i.Quantity = 4; i.UnitPrice = 60; i.TotalPrice = 240 }),
+	)
+
+	suite.order(bob.ID, models.OrderStatusPending, suite.day.Add(12*time.Hour), 999, models.PaymentMethodCreditCard)
+
+	late := suite.order(alice.ID, models.OrderStatusPaid, suite.day.Add(26*time.Hour), 60, models.PaymentMethodCreditCard)
+	suite.create(testutil.CreateTestPayment(late.ID, func(p *models.Payment) {
+		p.Amount = 60
+		p.Status = models.PaymentStatusRefunded
+	}))
+}
+
+// TearDownSuite runs once after all tests
+func (suite *SalesAggregationTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		testutil.TeardownTestDatabase(suite.db)
+	}
+}
+
+// create inserts the records
+func (suite *SalesAggregationTestSuite) create(records ...interface{}) {
+	for _, record := range records {
+		require.NoError(suite.T(), suite.db.Create(record).Error)
+	}
+}
+
+// order inserts an order placed at createdAt
+func (suite *SalesAggregationTestSuite) order(userID string, status models.OrderStatus, createdAt time.Time, total float64, method models.PaymentMethod) *models.Order {
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.Status = status
+		o.CreatedAt = createdAt
+		o.TotalAmount = total
+		o.PaymentMethod = method
+	})
+	suite.create(order)
+	return order
+}
+
+// Test AggregateSalesByDay - Only sales count, and refunds are capped per order
+func (suite *SalesAggregationTestSuite) TestAggregateSalesByDay() {
+	days, err := suite.orderRepo.AggregateSalesByDay(suite.ctx, suite.day, suite.day.AddDate(0, 0, 1))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(days, 1)
+	suite.Equal("2025-06-10", days[0].Day.Format("2006-01-02"))
+	suite.InDelta(440.0, days[0].Revenue, 0.001)
+	suite.InDelta(25.0, days[0].RefundedAmount, 0.001)
+	suite.Equal(2, days[0].Orders)
+	suite.Equal(2, days[0].Customers)
+	suite.Equal(1, days[0].ReturnedOrders)
+}
+
+// Test AggregateSalesByDay - Days follow the time zone of the range
+func (suite *SalesAggregationTestSuite) TestAggregateSalesByDay_TimeZone() {
+	newYork, err := time.LoadLocation("America/New_York")
+	suite.Require().NoError(err)
+	start := time.Date(2025, 6, 10, 0, 0, 0, 0, newYork)
+
+	days, err := suite.orderRepo.AggregateSalesByDay(suite.ctx, start, start.AddDate(0, 0, 1))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(days, 1)
+	suite.Equal("2025-06-10", days[0].Day.Format("2006-01-02"))
+	suite.InDelta(500.0, days[0].Revenue, 0.001)
+	suite.InDelta(85.0, days[0].RefundedAmount, 0.001)
+	suite.Equal(3, days[0].Orders)
+	suite.Equal(2, days[0].ReturnedOrders)
+}
+
+// Test AggregateSalesByProduct - Refunds are spread over an order's items by their share of its total
+func (suite *SalesAggregationTestSuite) TestAggregateSalesByProduct() {
+	products, err := suite.orderRepo.AggregateSalesByProduct(suite.ctx, suite.day, suite.day.AddDate(0, 0, 1))
+
+	suite.Require().NoError(err)
+	suite.Require().Len(products, 2)
+
+	suite.Equal(suite.gadget.ID, products[0].ProductID)
+	suite.Equal("Gadget", products[0].ProductName)
+	suite.Equal(35.0, products[0].UnitCost)
+	suite.Equal(4, products[0].Quantity)
+	suite.InDelta(240.0, products[0].Revenue, 0.001)
+	suite.InDelta(0.0, products[0].RefundedAmount, 0.001)
+
+	suite.Equal(suite.widget.ID, products[1].ProductID)
+	suite.Equal(3, products[1].Quantity)
+	suite.InDelta(200.0, products[1].Revenue, 0.001)
+	suite.InDelta(25.0, products[1].RefundedAmount, 0.001)
+	suite.InDelta(0.5, products[1].ReturnedQuantity, 0.001)
+	suite.Equal(2, products[1].Orders)
+}
+
+// Test AggregateSalesByPaymentMethod - Orders without a payment method are grouped together
+func (suite *SalesAggregationTestSuite) TestAggregateSalesByPaymentMethod() {
+	methods, err := suite.orderRepo.AggregateSalesByPaymentMethod(suite.ctx, suite.day, suite.day.AddDate(0, 0, 1))
+
+	suite.Require().NoError(err)
+	suite.Equal([]repository.PaymentMethodSales{
+		{Method: "", Orders: 1, Revenue: 340},
+		{Method: models.PaymentMethodCreditCard, Orders: 1, Revenue: 100},
+	}, methods)
+}
+
+// Test AggregateSalesByCustomerType - Customers whose first sale is in the range are new
+func (suite *SalesAggregationTestSuite) TestAggregateSalesByCustomerType() {
+	types, err := suite.orderRepo.AggregateSalesByCustomerType(suite.ctx, suite.day, suite.day.AddDate(0, 0, 1))
+
+	suite.Require().NoError(err)
+	suite.Equal([]repository.CustomerTypeSales{
+		{New: true, Customers: 1, Orders: 1, Revenue: 340},
+		{New: false, Customers: 1, Orders: 1, Revenue: 100},
+	}, types)
+}
+
+// TestSalesAggregationTestSuite runs the test suite
+func TestSalesAggregationTestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	suite.Run(t, new(SalesAggregationTestSuite))
+}
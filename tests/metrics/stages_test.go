@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/metrics"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// StageRecorderTestSuite defines the test suite for the stage metrics recorder
+type StageRecorderTestSuite struct {
+	suite.Suite
+	recorder *metrics.StageRecorder
+}
+
+// SetupTest runs before each test in the suite
+func (suite *StageRecorderTestSuite) SetupTest() {
+	suite.recorder = metrics.NewStageRecorder(4)
+}
+
+// Test Summary - Percentiles cover the recent window, counters every execution
+func (suite *StageRecorderTestSuite) TestSummary_PercentilesOverRecentWindow() {
+	for _, ms := range []int{1, 200, 20, 30, 40} {
+		suite.recorder.Observe("inventory_reservation", time.Duration(ms)*time.Millisecond, false)
+	}
+	suite.recorder.Observe("inventory_reservation", 50*time.Millisecond, true)
+
+	// Execute
+	summaries := suite.recorder.Summary()
+
+	// Assert
+	suite.Require().Len(summaries, 1)
+	summary := summaries[0]
+	suite.Equal(int64(6), summary.Executions)
+	suite.Equal(int64(5), summary.Successes)
+	suite.Equal(int64(1), summary.Failures)
+	suite.Equal(4, summary.Recent) // 20, 30, 40 and 50ms; the 1 and 200ms executions left the window
+	suite.Equal(35.0, summary.MeanMs)
+	suite.Equal(30.0, summary.P50Ms)
+	suite.Equal(50.0, summary.P95Ms)
+	suite.Equal(50.0, summary.MaxMs)
+
+	// Cumulative buckets: 5ms, 10ms, 25ms, 50ms, 100ms, 250ms, ... +Inf
+	suite.Equal(int64(1), summary.Buckets[0].Count)
+	suite.Equal(int64(2), summary.Buckets[2].Count)
+	suite.Equal(int64(5), summary.Buckets[3].Count)
+	suite.Equal(int64(6), summary.Buckets[5].Count)
+	last := summary.Buckets[len(summary.Buckets)-1]
+	suite.Nil(last.LeMs)
+	suite.Equal(int64(6), last.Count)
+}
+
+// Test Summary - The slowest stage by p95 comes first
+func (suite *StageRecorderTestSuite) TestSummary_SlowestStageFirst() {
+	suite.recorder.Observe("cart_holds", 2*time.Millisecond, false)
+	suite.recorder.Observe("payment", 120*time.Millisecond, false)
+	suite.recorder.Observe("inventory_reservation", 15*time.Millisecond, false)
+
+	// Execute
+	summaries := suite.recorder.Summary()
+
+	// Assert
+	suite.Require().Len(summaries, 3)
+	suite.Equal("payment", summaries[0].Stage)
+	suite.Equal("inventory_reservation", summaries[1].Stage)
+	suite.Equal("cart_holds", summaries[2].Stage)
+}
+
+// Test Run - Each stage lasts until the next starts, and a failed run counts a rollback for its stage
+func (suite *StageRecorderTestSuite) TestRun_FailureRollsBackCurrentStage() {
+	run := suite.recorder.Start()
+	run.Stage("order_validation")
+	run.Stage("inventory_reservation")
+	run.Fail(true)
+
+	// Execute
+	summaries := suite.recorder.Summary()
+
+	// Assert
+	byStage := make(map[string]metrics.StageSummary, len(summaries))
+	for _, summary := range summaries {
+		byStage[summary.Stage] = summary
+	}
+	suite.Require().Len(byStage, 2)
+	suite.Equal(int64(1), byStage["order_validation"].Successes)
+	suite.Zero(byStage["order_validation"].Rollbacks)
+	suite.Equal(int64(1), byStage["inventory_reservation"].Failures)
+	suite.Equal(int64(1), byStage["inventory_reservation"].Rollbacks)
+}
+
+// Test nil recorder - Services built without metrics record nothing
+func (suite *StageRecorderTestSuite) TestNilRecorder_RecordsNothing() {
+	var recorder *metrics.StageRecorder
+
+	// Execute
+	run := recorder.Start()
+	run.Stage("payment")
+	run.Fail(true)
+	recorder.Observe("payment", time.Millisecond, false)
+
+	// Assert
+	suite.Empty(recorder.Summary())
+}
+
+// Run the test suite
+func TestStageRecorderTestSuite(t *testing.T) {
+	suite.Run(t, new(StageRecorderTestSuite))
+}
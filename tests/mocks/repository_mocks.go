@@ -6,6 +6,7 @@ import (
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/workers"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -41,6 +42,14 @@ func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (*mode
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
+func (m *MockProductRepository) GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+	args := m.Called(ctx, skus)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
 func (m *MockProductRepository) Update(ctx context.Context, product *models.Product) error {
 	args := m.Called(ctx, product)
 	return args.Error(0)
@@ -59,8 +68,8 @@ func (m *MockProductRepository) List(ctx context.Context, offset, limit int) ([]
 	return args.Get(0).([]*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Search(ctx context.Context, query string, offset, limit int) ([]*models.Product, error) {
-	args := m.Called(ctx, query, offset, limit)
+func (m *MockProductRepository) Search(ctx context.Context, query, locale string, offset, limit int) ([]*models.Product, error) {
+	args := m.Called(ctx, query, locale, offset, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -85,11 +94,19 @@ func (m *MockProductRepository) CountActive(ctx context.Context) (int64, error)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockProductRepository) CountSearch(ctx context.Context, query string) (int64, error) {
-	args := m.Called(ctx, query)
+func (m *MockProductRepository) CountSearch(ctx context.Context, query, locale string) (int64, error) {
+	args := m.Called(ctx, query, locale)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockProductRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Product, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
 // MockInventoryRepository is a mock implementation of repository.InventoryRepository
 type MockInventoryRepository struct {
 	mock.Mock
@@ -108,6 +125,19 @@ func (m *MockInventoryRepository) GetByProductID(ctx context.Context, productID
 	return args.Get(0).(*models.Inventory), args.Error(1)
 }
 
+func (m *MockInventoryRepository) Relocate(ctx context.Context, relocation repository.InventoryRelocation) (bool, error) {
+	args := m.Called(ctx, relocation)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInventoryRepository) SetSafetyStock(ctx context.Context, productID string, safetyStock int, auditLog *models.AuditLog) (*models.Inventory, error) {
+	args := m.Called(ctx, productID, safetyStock, auditLog)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Inventory), args.Error(1)
+}
+
 func (m *MockInventoryRepository) UpdateStock(ctx context.Context, productID string, quantity int) error {
 	args := m.Called(ctx, productID, quantity)
 	return args.Error(0)
@@ -136,6 +166,22 @@ func (m *MockInventoryRepository) GetLowStockItems(ctx context.Context, threshol
 	return args.Get(0).([]*models.Inventory), args.Error(1)
 }
 
+func (m *MockInventoryRepository) ListWithProducts(ctx context.Context) ([]*models.Inventory, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryRepository) GetUnitsSoldSince(ctx context.Context, since time.Time) (map[string]int, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 func (m *MockInventoryRepository) BulkReserve(ctx context.Context, items []repository.InventoryReservation) error {
 	args := m.Called(ctx, items)
 	return args.Error(0)
@@ -146,6 +192,11 @@ func (m *MockInventoryRepository) BulkRelease(ctx context.Context, items []repos
 	return args.Error(0)
 }
 
+func (m *MockInventoryRepository) BulkAdjustStock(ctx context.Context, items []repository.InventoryStockAdjustment) error {
+	args := m.Called(ctx, items)
+	return args.Error(0)
+}
+
 // MockOrderRepository is a mock implementation of repository.OrderRepository
 type MockOrderRepository struct {
 	mock.Mock
@@ -172,6 +223,54 @@ func (m *MockOrderRepository) GetByIDWithItems(ctx context.Context, id string) (
 	return args.Get(0).(*models.Order), args.Error(1)
 }
 
+func (m *MockOrderRepository) ListByIDsWithItems(ctx context.Context, ids []string) ([]*models.Order, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) AggregateSalesByDay(ctx context.Context, start, end time.Time) ([]repository.SalesDay, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.SalesDay), args.Error(1)
+}
+
+func (m *MockOrderRepository) AggregateSalesByProduct(ctx context.Context, start, end time.Time) ([]repository.ProductSales, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ProductSales), args.Error(1)
+}
+
+func (m *MockOrderRepository) AggregateSalesByPaymentMethod(ctx context.Context, start, end time.Time) ([]repository.PaymentMethodSales, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PaymentMethodSales), args.Error(1)
+}
+
+func (m *MockOrderRepository) AggregateSalesByCustomerType(ctx context.Context, start, end time.Time) ([]repository.CustomerTypeSales, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.CustomerTypeSales), args.Error(1)
+}
+
+func (m *MockOrderRepository) AggregateSalesByAgent(ctx context.Context, start, end time.Time) ([]repository.AgentSales, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.AgentSales), args.Error(1)
+}
+
 func (m *MockOrderRepository) GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Order, error) {
 	args := m.Called(ctx, userID, offset, limit)
 	if args.Get(0) == nil {
@@ -190,6 +289,11 @@ func (m *MockOrderRepository) UpdateStatus(ctx context.Context, id string, statu
 	return args.Error(0)
 }
 
+func (m *MockOrderRepository) TransitionStatus(ctx context.Context, id string, status models.OrderStatus) (bool, error) {
+	args := m.Called(ctx, id, status)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockOrderRepository) List(ctx context.Context, offset, limit int) ([]*models.Order, error) {
 	args := m.Called(ctx, offset, limit)
 	if args.Get(0) == nil {
@@ -224,11 +328,198 @@ func (m *MockOrderRepository) CountByStatus(ctx context.Context, status models.O
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockOrderRepository) ListByUserID(ctx context.Context, userID string, status models.OrderStatus, offset, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, userID, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountByUserIDAndStatus(ctx context.Context, userID string, status models.OrderStatus) (int64, error) {
+	args := m.Called(ctx, userID, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockOrderRepository) CountByUserID(ctx context.Context, userID string) (int64, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockOrderRepository) ListByMetadata(ctx context.Context, key, value string, offset, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, key, value, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountByMetadata(ctx context.Context, key, value string) (int64, error) {
+	args := m.Called(ctx, key, value)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListByRiskBand(ctx context.Context, band models.RiskBand, offset, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, band, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountByRiskBand(ctx context.Context, band models.RiskBand) (int64, error) {
+	args := m.Called(ctx, band)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) UpdateRiskScore(ctx context.Context, order *models.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetByExternalID(ctx context.Context, channel, externalOrderID string) (*models.Order, error) {
+	args := m.Called(ctx, channel, externalOrderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListByOrganization(ctx context.Context, organizationID string, offset, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, organizationID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountByOrganization(ctx context.Context, organizationID string) (int64, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListOpenInvoices(ctx context.Context) ([]repository.OpenInvoice, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.OpenInvoice), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetOutstandingBalance(ctx context.Context, organizationID string) (float64, error) {
+	args := m.Called(ctx, organizationID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListAwaitingShipment(ctx context.Context, dueBy time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, dueBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByIdempotencyKey(ctx context.Context, userID, key string) (*models.Order, error) {
+	args := m.Called(ctx, userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) OverrideItemPrice(ctx context.Context, orderID, itemID string, override repository.ItemPriceOverride) (*models.Order, error) {
+	args := m.Called(ctx, orderID, itemID, override)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CancelItems(ctx context.Context, orderID string, quantities map[string]int) (*models.Order, float64, error) {
+	args := m.Called(ctx, orderID, quantities)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(float64), args.Error(2)
+	}
+	return args.Get(0).(*models.Order), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *MockOrderRepository) RefreshPaidAmount(ctx context.Context, id string) (float64, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListRecentByUser(ctx context.Context, userID string, since time.Time) ([]*models.Order, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountByUserSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	args := m.Called(ctx, userID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ClearIdempotencyKeysBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) CountIdempotencyKeysBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListCreditHolds(ctx context.Context) ([]*models.Order, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ReviewCreditHold(ctx context.Context, id, reviewerID string, status models.OrderStatus) (bool, error) {
+	args := m.Called(ctx, id, reviewerID, status)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListCreditExposure(ctx context.Context) ([]repository.CreditExposure, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.CreditExposure), args.Error(1)
+}
+
+func (m *MockOrderRepository) Iterate(filter repository.OrderFilter, batchSize int) repository.OrderIterator {
+	args := m.Called(filter, batchSize)
+	return args.Get(0).(repository.OrderIterator)
+}
+
+// OrderSliceIterator is an in-memory repository.OrderIterator that returns preset batches
+type OrderSliceIterator struct {
+	batches [][]*models.Order
+	Err     error
+}
+
+// NewOrderSliceIterator creates an iterator returning the given batches in order
+func NewOrderSliceIterator(batches ...[]*models.Order) *OrderSliceIterator {
+	return &OrderSliceIterator{batches: batches}
+}
+
+func (it *OrderSliceIterator) Next(ctx context.Context) ([]*models.Order, error) {
+	if len(it.batches) == 0 {
+		if it.Err != nil {
+			return nil, it.Err
+		}
+		return nil, nil
+	}
+	batch := it.batches[0]
+	it.batches = it.batches[1:]
+	return batch, nil
+}
+
 // MockOrderItemRepository is a mock implementation of repository.OrderItemRepository
 type MockOrderItemRepository struct {
 	mock.Mock
@@ -311,6 +602,30 @@ func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockUserRepository) ListByOrganization(ctx context.Context, organizationID string) ([]*models.User, error) {
+	args := m.Called(ctx, organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.User, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
 // MockPaymentRepository is a mock implementation of repository.PaymentRepository
 type MockPaymentRepository struct {
 	mock.Mock
@@ -321,6 +636,11 @@ func (m *MockPaymentRepository) Create(ctx context.Context, payment *models.Paym
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) CreateWithinBalance(ctx context.Context, payment *models.Payment) (bool, error) {
+	args := m.Called(ctx, payment)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockPaymentRepository) GetByID(ctx context.Context, id string) (*models.Payment, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -337,6 +657,14 @@ func (m *MockPaymentRepository) GetByTransactionID(ctx context.Context, transact
 	return args.Get(0).(*models.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetByGatewayTransactionID(ctx context.Context, gateway, gatewayTxnID string) (*models.Payment, error) {
+	args := m.Called(ctx, gateway, gatewayTxnID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Payment), args.Error(1)
+}
+
 func (m *MockPaymentRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.Payment, error) {
 	args := m.Called(ctx, orderID)
 	if args.Get(0) == nil {
@@ -345,6 +673,11 @@ func (m *MockPaymentRepository) GetByOrderID(ctx context.Context, orderID string
 	return args.Get(0).([]*models.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) SumCompletedByOrderID(ctx context.Context, orderID string) (float64, error) {
+	args := m.Called(ctx, orderID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
 func (m *MockPaymentRepository) Update(ctx context.Context, payment *models.Payment) error {
 	args := m.Called(ctx, payment)
 	return args.Error(0)
@@ -355,10 +688,1888 @@ func (m *MockPaymentRepository) UpdateStatus(ctx context.Context, id string, sta
 	return args.Error(0)
 }
 
-func (m *MockPaymentRepository) List(ctx context.Context, offset, limit int) ([]*models.Payment, error) {
-	args := m.Called(ctx, offset, limit)
+func (m *MockPaymentRepository) ListHeldDue(ctx context.Context, now time.Time, limit int) ([]*models.Payment, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ClaimHeld(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Payment, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) RecordManual(ctx context.Context, record repository.ManualPaymentRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetProof(ctx context.Context, paymentID string) (*models.PaymentProof, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentProof), args.Error(1)
+}
+
+func (m *MockPaymentRepository) ListForReconciliation(ctx context.Context, stuckBefore, failedSince time.Time, limit int) ([]*models.Payment, error) {
+	args := m.Called(ctx, stuckBefore, failedSince, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) Reconcile(ctx context.Context, payment *models.Payment, from models.PaymentStatus) (bool, error) {
+	args := m.Called(ctx, payment, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentRepository) MarkReconciled(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) ListForPolling(ctx context.Context, gateways []string, stuckBefore time.Time, limit int) ([]*models.Payment, error) {
+	args := m.Called(ctx, gateways, stuckBefore, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.Payment), args.Error(1)
 }
+
+func (m *MockPaymentRepository) MarkEscalated(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRepository) List(ctx context.Context, offset, limit int) ([]*models.Payment, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) SummarizeSettlement(ctx context.Context, start, end time.Time) ([]repository.PaymentSettlementSummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PaymentSettlementSummary), args.Error(1)
+}
+
+// MockWebhookEventRepository is a mock implementation of repository.WebhookEventRepository
+type MockWebhookEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookEventRepository) CreateIfNotExists(ctx context.Context, event *models.WebhookEvent) (bool, error) {
+	args := m.Called(ctx, event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) GetByID(ctx context.Context, id string) (*models.WebhookEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEvent), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) GetByProviderEventID(ctx context.Context, source models.WebhookEventSource, provider, eventID string) (*models.WebhookEvent, error) {
+	args := m.Called(ctx, source, provider, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEvent), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) Claim(ctx context.Context, id string, fromStatuses ...models.WebhookEventStatus) (bool, error) {
+	args := m.Called(ctx, id, fromStatuses)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) MarkProcessed(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEventRepository) MarkFailed(ctx context.Context, id string, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEventRepository) ListByStatus(ctx context.Context, status models.WebhookEventStatus, offset, limit int) ([]*models.WebhookEvent, error) {
+	args := m.Called(ctx, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookEvent), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) CountByStatus(ctx context.Context, status models.WebhookEventStatus) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) CountCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockStockWebhookSubscriptionRepository is a mock implementation of repository.StockWebhookSubscriptionRepository
+type MockStockWebhookSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockWebhookSubscriptionRepository) Create(ctx context.Context, subscription *models.StockWebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockStockWebhookSubscriptionRepository) GetByID(ctx context.Context, id string) (*models.StockWebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockWebhookSubscription), args.Error(1)
+}
+
+func (m *MockStockWebhookSubscriptionRepository) List(ctx context.Context) ([]*models.StockWebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockWebhookSubscription), args.Error(1)
+}
+
+func (m *MockStockWebhookSubscriptionRepository) ListActive(ctx context.Context) ([]*models.StockWebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockWebhookSubscription), args.Error(1)
+}
+
+func (m *MockStockWebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockStockWebhookSubscriptionRepository) RecordDelivery(ctx context.Context, id string, deliveryErr string) error {
+	args := m.Called(ctx, id, deliveryErr)
+	return args.Error(0)
+}
+
+// MockChannelListingRepository is a mock implementation of repository.ChannelListingRepository
+type MockChannelListingRepository struct {
+	mock.Mock
+}
+
+func (m *MockChannelListingRepository) Create(ctx context.Context, listing *models.ChannelListing) error {
+	args := m.Called(ctx, listing)
+	return args.Error(0)
+}
+
+func (m *MockChannelListingRepository) GetByChannelSKUs(ctx context.Context, channel string, channelSKUs []string) ([]*models.ChannelListing, error) {
+	args := m.Called(ctx, channel, channelSKUs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ChannelListing), args.Error(1)
+}
+
+func (m *MockChannelListingRepository) ListByChannel(ctx context.Context, channel string) ([]*models.ChannelListing, error) {
+	args := m.Called(ctx, channel)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ChannelListing), args.Error(1)
+}
+
+func (m *MockChannelListingRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockNotificationRepository is a mock implementation of repository.NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) GetByID(ctx context.Context, id string) (*models.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Notification, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetUnreadByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.Notification, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetByOrderID(ctx context.Context, orderID string) ([]*models.Notification, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkSent(ctx context.Context, id string, channel models.NotificationChannel, sentAt time.Time) error {
+	args := m.Called(ctx, id, channel, sentAt)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkDelivered(ctx context.Context, id string, deliveredAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, deliveredAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, id string, readAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, readAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) SummarizeDelivery(ctx context.Context, start, end time.Time) ([]repository.NotificationDeliverySummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.NotificationDeliverySummary), args.Error(1)
+}
+
+func (m *MockNotificationRepository) DeleteCreatedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationRepository) CountCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockActivityEventRepository is a mock implementation of repository.ActivityEventRepository
+type MockActivityEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockActivityEventRepository) Create(ctx context.Context, event *models.ActivityEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockActivityEventRepository) CountActiveUsers(ctx context.Context, start, end time.Time) (int64, error) {
+	args := m.Called(ctx, start, end)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockActivityEventRepository) CountByType(ctx context.Context, start, end time.Time) ([]repository.ActivityTypeCount, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ActivityTypeCount), args.Error(1)
+}
+
+func (m *MockActivityEventRepository) DailyActiveUsers(ctx context.Context, start, end time.Time) ([]repository.DailyActiveUserCount, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.DailyActiveUserCount), args.Error(1)
+}
+
+// MockAuditLogRepository is a mock implementation of repository.AuditLogRepository
+type MockAuditLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAuditLogRepository) GetByID(ctx context.Context, id string) (*models.AuditLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditLogRepository) GetByEntityID(ctx context.Context, entityType, entityID string, offset, limit int) ([]*models.AuditLog, error) {
+	args := m.Called(ctx, entityType, entityID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditLogRepository) GetByUserID(ctx context.Context, userID string, offset, limit int) ([]*models.AuditLog, error) {
+	args := m.Called(ctx, userID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditLogRepository) List(ctx context.Context, offset, limit int) ([]*models.AuditLog, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditLog), args.Error(1)
+}
+
+// MockStockHoldRepository is a mock implementation of repository.StockHoldRepository
+type MockStockHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockHoldRepository) Hold(ctx context.Context, hold *models.StockHold) error {
+	args := m.Called(ctx, hold)
+	return args.Error(0)
+}
+
+func (m *MockStockHoldRepository) GetActive(ctx context.Context, userID, productID string) (*models.StockHold, error) {
+	args := m.Called(ctx, userID, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockHold), args.Error(1)
+}
+
+func (m *MockStockHoldRepository) ListActiveByUser(ctx context.Context, userID string) ([]*models.StockHold, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockHold), args.Error(1)
+}
+
+func (m *MockStockHoldRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.StockHold, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockHold), args.Error(1)
+}
+
+func (m *MockStockHoldRepository) Release(ctx context.Context, id string, status models.StockHoldStatus) (bool, error) {
+	args := m.Called(ctx, id, status)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockStockHoldRepository) CountByStatus(ctx context.Context, start, end time.Time) ([]repository.StockHoldStatusCount, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.StockHoldStatusCount), args.Error(1)
+}
+
+// MockPaymentAttemptRepository is a mock implementation of repository.PaymentAttemptRepository
+type MockPaymentAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentAttemptRepository) Create(ctx context.Context, attempt *models.PaymentAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockPaymentAttemptRepository) SummarizeAttempts(ctx context.Context, start, end time.Time) ([]repository.PaymentAttemptSummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PaymentAttemptSummary), args.Error(1)
+}
+
+// MockProductAvailabilityRepository is a mock implementation of repository.ProductAvailabilityRepository
+type MockProductAvailabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductAvailabilityRepository) GetByProductID(ctx context.Context, productID string) (*models.ProductAvailability, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductAvailability), args.Error(1)
+}
+
+func (m *MockProductAvailabilityRepository) Refresh(ctx context.Context, productIDs []string) ([]*models.ProductAvailability, error) {
+	args := m.Called(ctx, productIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProductAvailability), args.Error(1)
+}
+
+// MockOrganizationRepository is a mock implementation of repository.OrganizationRepository
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) Create(ctx context.Context, organization *models.Organization) error {
+	args := m.Called(ctx, organization)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) GetByID(ctx context.Context, id string) (*models.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetByName(ctx context.Context, name string) (*models.Organization, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) Update(ctx context.Context, organization *models.Organization) error {
+	args := m.Called(ctx, organization)
+	return args.Error(0)
+}
+
+func (m *MockOrganizationRepository) List(ctx context.Context, offset, limit int) ([]*models.Organization, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListAfterID(ctx context.Context, afterID string, limit int) ([]*models.Organization, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+// MockAddressRepository is a mock implementation of repository.AddressRepository
+type MockAddressRepository struct {
+	mock.Mock
+}
+
+func (m *MockAddressRepository) Create(ctx context.Context, address *models.Address) error {
+	args := m.Called(ctx, address)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) GetByID(ctx context.Context, id string) (*models.Address, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) ListByUserID(ctx context.Context, userID string) ([]*models.Address, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) GetDefault(ctx context.Context, userID string) (*models.Address, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) SetDefault(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockSavedPaymentMethodRepository is a mock implementation of repository.SavedPaymentMethodRepository
+type MockSavedPaymentMethodRepository struct {
+	mock.Mock
+}
+
+func (m *MockSavedPaymentMethodRepository) Create(ctx context.Context, method *models.SavedPaymentMethod) error {
+	args := m.Called(ctx, method)
+	return args.Error(0)
+}
+
+func (m *MockSavedPaymentMethodRepository) GetByID(ctx context.Context, id string) (*models.SavedPaymentMethod, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedPaymentMethod), args.Error(1)
+}
+
+func (m *MockSavedPaymentMethodRepository) ListByUserID(ctx context.Context, userID string) ([]*models.SavedPaymentMethod, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SavedPaymentMethod), args.Error(1)
+}
+
+func (m *MockSavedPaymentMethodRepository) GetDefault(ctx context.Context, userID string) (*models.SavedPaymentMethod, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SavedPaymentMethod), args.Error(1)
+}
+
+func (m *MockSavedPaymentMethodRepository) SetDefault(ctx context.Context, userID, id string) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockSavedPaymentMethodRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockInventoryEventRepository is a mock implementation of repository.InventoryEventRepository
+type MockInventoryEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.InventoryEvent, error) {
+	args := m.Called(ctx, sequence, settle, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InventoryEvent), args.Error(1)
+}
+
+func (m *MockInventoryEventRepository) ListHistory(ctx context.Context, productID string, offset, limit int) ([]repository.InventoryHistoryEntry, error) {
+	args := m.Called(ctx, productID, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.InventoryHistoryEntry), args.Error(1)
+}
+
+func (m *MockInventoryEventRepository) CountHistory(ctx context.Context, productID string) (int64, error) {
+	args := m.Called(ctx, productID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockOrderEventRepository is a mock implementation of repository.OrderEventRepository
+type MockOrderEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.OrderEvent, error) {
+	args := m.Called(ctx, sequence, settle, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrderEvent), args.Error(1)
+}
+
+func (m *MockOrderEventRepository) LatestSequence(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderEventRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	args := m.Called(ctx, consumer)
+	return args.Get(0).(int64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockOrderEventRepository) SaveCursor(ctx context.Context, consumer string, sequence int64) error {
+	args := m.Called(ctx, consumer, sequence)
+	return args.Error(0)
+}
+
+// MockCatalogEventRepository is a mock implementation of repository.CatalogEventRepository
+type MockCatalogEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogEventRepository) ListSince(ctx context.Context, sequence int64, settle time.Duration, limit int) ([]*models.CatalogEvent, error) {
+	args := m.Called(ctx, sequence, settle, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CatalogEvent), args.Error(1)
+}
+
+func (m *MockCatalogEventRepository) LatestSequence(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCatalogEventRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	args := m.Called(ctx, consumer)
+	return args.Get(0).(int64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockCatalogEventRepository) SaveCursor(ctx context.Context, consumer string, sequence int64) error {
+	args := m.Called(ctx, consumer, sequence)
+	return args.Error(0)
+}
+
+// MockLedgerRepository is a mock implementation of repository.LedgerRepository
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) Append(ctx context.Context, entries []*models.LedgerEntry) (bool, error) {
+	args := m.Called(ctx, entries)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLedgerRepository) List(ctx context.Context, filter repository.LedgerFilter, offset, limit int) ([]*models.LedgerEntry, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.LedgerEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) Count(ctx context.Context, filter repository.LedgerFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockLedgerRepository) SumByAccount(ctx context.Context, account string) ([]repository.LedgerAccountTotals, error) {
+	args := m.Called(ctx, account)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.LedgerAccountTotals), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListUnbalanced(ctx context.Context, limit int) ([]repository.LedgerTransactionTotals, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.LedgerTransactionTotals), args.Error(1)
+}
+
+func (m *MockLedgerRepository) ListPaymentMismatches(ctx context.Context, transactionType models.LedgerTransactionType, statuses []models.PaymentStatus, limit int) ([]repository.LedgerPaymentMismatch, error) {
+	args := m.Called(ctx, transactionType, statuses, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.LedgerPaymentMismatch), args.Error(1)
+}
+
+// MockOrderHoldRepository is a mock implementation of repository.OrderHoldRepository
+type MockOrderHoldRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderHoldRepository) Place(ctx context.Context, hold *models.OrderHold) (bool, error) {
+	args := m.Called(ctx, hold)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) GetByID(ctx context.Context, id string) (*models.OrderHold, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderHold), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) GetActiveByOrderID(ctx context.Context, orderID string) (*models.OrderHold, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderHold), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) ListByOrderID(ctx context.Context, orderID string) ([]*models.OrderHold, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrderHold), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) List(ctx context.Context, filter repository.OrderHoldFilter, offset, limit int) ([]*models.OrderHold, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrderHold), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) Count(ctx context.Context, filter repository.OrderHoldFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) Assign(ctx context.Context, id, assigneeID string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, assigneeID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) Release(ctx context.Context, id, releasedBy, note string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, releasedBy, note, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) SummarizeByReason(ctx context.Context, start, end, now time.Time) ([]repository.OrderHoldSummary, error) {
+	args := m.Called(ctx, start, end, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.OrderHoldSummary), args.Error(1)
+}
+
+func (m *MockOrderHoldRepository) CountHeldOrders(ctx context.Context, start, end time.Time) (int64, int64, error) {
+	args := m.Called(ctx, start, end)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+// MockWebhookEndpointRepository is a mock implementation of repository.WebhookEndpointRepository
+type MockWebhookEndpointRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookEndpointRepository) Create(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEndpointRepository) GetByID(ctx context.Context, id string) (*models.WebhookEndpoint, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookEndpointRepository) List(ctx context.Context) ([]*models.WebhookEndpoint, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookEndpointRepository) ListSubscribed(ctx context.Context, topic models.WebhookTopic) ([]*models.WebhookEndpoint, error) {
+	args := m.Called(ctx, topic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockWebhookEndpointRepository) Update(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEndpointRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockWebhookDeliveryRepository is a mock implementation of repository.WebhookDeliveryRepository
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookDeliveryRepository) Enqueue(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) EnqueueRelayed(ctx context.Context, relay string, sequence int64, deliveries []*models.WebhookDelivery) error {
+	args := m.Called(ctx, relay, sequence, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) GetRelayCursor(ctx context.Context, relay string) (int64, error) {
+	args := m.Called(ctx, relay)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) GetByID(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) List(ctx context.Context, filter repository.WebhookDeliveryFilter, offset, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Count(ctx context.Context, filter repository.WebhookDeliveryFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Requeue(ctx context.Context, id string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockReportResultRepository is a mock implementation of repository.ReportResultRepository
+type MockReportResultRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportResultRepository) Create(ctx context.Context, result *models.ReportResult) error {
+	args := m.Called(ctx, result)
+	return args.Error(0)
+}
+
+func (m *MockReportResultRepository) GetByID(ctx context.Context, id string) (*models.ReportResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportResult), args.Error(1)
+}
+
+func (m *MockReportResultRepository) Transition(ctx context.Context, result *models.ReportResult, from ...models.ReportResultStatus) (bool, error) {
+	args := m.Called(ctx, result, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReportResultRepository) FailUnfinished(ctx context.Context, before time.Time, reason string) (int64, error) {
+	args := m.Called(ctx, before, reason)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockAPIKeyRepository is a mock implementation of repository.APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListByUserID(ctx context.Context, userID string) ([]models.APIKey, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) SetQuota(ctx context.Context, id string, quota *int) (bool, error) {
+	args := m.Called(ctx, id, quota)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAPIUsageRepository is a mock implementation of repository.APIUsageRepository
+type MockAPIUsageRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIUsageRepository) Increment(ctx context.Context, rows []models.APIUsage) error {
+	args := m.Called(ctx, rows)
+	return args.Error(0)
+}
+
+func (m *MockAPIUsageRepository) CountRequests(ctx context.Context, apiKeyID string, from, to time.Time) (int64, error) {
+	args := m.Called(ctx, apiKeyID, from, to)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPIUsageRepository) SummarizeByEndpoint(ctx context.Context, filter repository.APIUsageFilter) ([]repository.APIUsageTotals, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.APIUsageTotals), args.Error(1)
+}
+
+func (m *MockAPIUsageRepository) SummarizeByCaller(ctx context.Context, filter repository.APIUsageFilter) ([]repository.APIUsageTotals, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.APIUsageTotals), args.Error(1)
+}
+
+// MockReferralRepository is a mock implementation of repository.ReferralRepository
+type MockReferralRepository struct {
+	mock.Mock
+}
+
+func (m *MockReferralRepository) GetCodeByUserID(ctx context.Context, userID string) (*models.ReferralCode, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReferralCode), args.Error(1)
+}
+
+func (m *MockReferralRepository) GetCodeByCode(ctx context.Context, code string) (*models.ReferralCode, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReferralCode), args.Error(1)
+}
+
+func (m *MockReferralRepository) CreateCode(ctx context.Context, code *models.ReferralCode) (bool, error) {
+	args := m.Called(ctx, code)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) Create(ctx context.Context, referral *models.Referral) (bool, error) {
+	args := m.Called(ctx, referral)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) ListByReferrerID(ctx context.Context, referrerID string) ([]models.Referral, error) {
+	args := m.Called(ctx, referrerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Referral), args.Error(1)
+}
+
+func (m *MockReferralRepository) AttributeOrder(ctx context.Context, refereeID, orderID string, at time.Time) (bool, error) {
+	args := m.Called(ctx, refereeID, orderID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) ListSettleable(ctx context.Context, deliveredBefore time.Time, limit int) ([]repository.ReferralOrderOutcome, error) {
+	args := m.Called(ctx, deliveredBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ReferralOrderOutcome), args.Error(1)
+}
+
+func (m *MockReferralRepository) Reward(ctx context.Context, referral *models.Referral, credit *models.StoreCreditTransaction) (bool, error) {
+	args := m.Called(ctx, referral, credit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) Void(ctx context.Context, id, reason string) (bool, error) {
+	args := m.Called(ctx, id, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) ReleaseOrder(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferralRepository) SummarizeByReferrer(ctx context.Context, start, end time.Time) ([]repository.ReferrerSummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.ReferrerSummary), args.Error(1)
+}
+
+// MockStoreCreditRepository is a mock implementation of repository.StoreCreditRepository
+type MockStoreCreditRepository struct {
+	mock.Mock
+}
+
+func (m *MockStoreCreditRepository) GetBalance(ctx context.Context, userID string) (float64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *MockStoreCreditRepository) ListByUserID(ctx context.Context, userID string, limit int) ([]models.StoreCreditTransaction, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StoreCreditTransaction), args.Error(1)
+}
+
+// MockBlocklistRepository is a mock implementation of repository.BlocklistRepository
+type MockBlocklistRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlocklistRepository) Create(ctx context.Context, entry *models.BlocklistEntry, auditLog *models.AuditLog) (bool, error) {
+	args := m.Called(ctx, entry, auditLog)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) GetByID(ctx context.Context, id string) (*models.BlocklistEntry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BlocklistEntry), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) List(ctx context.Context, filter repository.BlocklistFilter, offset, limit int) ([]*models.BlocklistEntry, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BlocklistEntry), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) Count(ctx context.Context, filter repository.BlocklistFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) Delete(ctx context.Context, id string, auditLog *models.AuditLog) (bool, error) {
+	args := m.Called(ctx, id, auditLog)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) FindMatches(ctx context.Context, match repository.BlocklistMatch, at time.Time) ([]*models.BlocklistEntry, error) {
+	args := m.Called(ctx, match, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BlocklistEntry), args.Error(1)
+}
+
+func (m *MockBlocklistRepository) RecordHits(ctx context.Context, ids []string, at time.Time) error {
+	args := m.Called(ctx, ids, at)
+	return args.Error(0)
+}
+
+func (m *MockBlocklistRepository) ListTopHits(ctx context.Context, limit int) ([]*models.BlocklistEntry, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BlocklistEntry), args.Error(1)
+}
+
+// MockPriceListRepository is a mock implementation of repository.PriceListRepository
+type MockPriceListRepository struct {
+	mock.Mock
+}
+
+func (m *MockPriceListRepository) Create(ctx context.Context, priceList *models.PriceList) error {
+	args := m.Called(ctx, priceList)
+	return args.Error(0)
+}
+
+func (m *MockPriceListRepository) GetByID(ctx context.Context, id string) (*models.PriceList, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PriceList), args.Error(1)
+}
+
+func (m *MockPriceListRepository) GetByName(ctx context.Context, name string) (*models.PriceList, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PriceList), args.Error(1)
+}
+
+func (m *MockPriceListRepository) Update(ctx context.Context, priceList *models.PriceList) error {
+	args := m.Called(ctx, priceList)
+	return args.Error(0)
+}
+
+func (m *MockPriceListRepository) List(ctx context.Context, offset, limit int) ([]*models.PriceList, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PriceList), args.Error(1)
+}
+
+func (m *MockPriceListRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPriceListRepository) ListItems(ctx context.Context, priceListID string) ([]*models.PriceListItem, error) {
+	args := m.Called(ctx, priceListID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PriceListItem), args.Error(1)
+}
+
+func (m *MockPriceListRepository) ImportItems(ctx context.Context, priceListID string, items []*models.PriceListItem, replace bool) error {
+	args := m.Called(ctx, priceListID, items, replace)
+	return args.Error(0)
+}
+
+func (m *MockPriceListRepository) FindEffectivePrices(ctx context.Context, priceListID string, productIDs []string, at time.Time) (map[string]float64, error) {
+	args := m.Called(ctx, priceListID, productIDs, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
+
+// MockPromotionRepository is a mock implementation of repository.PromotionRepository
+type MockPromotionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	args := m.Called(ctx, promotion)
+	return args.Error(0)
+}
+
+func (m *MockPromotionRepository) GetByID(ctx context.Context, id string) (*models.Promotion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) GetByName(ctx context.Context, name string) (*models.Promotion, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	args := m.Called(ctx, promotion)
+	return args.Error(0)
+}
+
+func (m *MockPromotionRepository) List(ctx context.Context, activeAt *time.Time, offset, limit int) ([]*models.Promotion, error) {
+	args := m.Called(ctx, activeAt, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) Count(ctx context.Context, activeAt *time.Time) (int64, error) {
+	args := m.Called(ctx, activeAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPromotionRepository) ListRunning(ctx context.Context, at time.Time) ([]*models.Promotion, error) {
+	args := m.Called(ctx, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) SummarizeRedemptions(ctx context.Context, start, end time.Time) ([]repository.PromotionSummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PromotionSummary), args.Error(1)
+}
+
+// MockDeliverySlotRepository is a mock implementation of DeliverySlotRepository
+type MockDeliverySlotRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeliverySlotRepository) GetByID(ctx context.Context, id string) (*models.DeliverySlot, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeliverySlot), args.Error(1)
+}
+
+func (m *MockDeliverySlotRepository) Plan(ctx context.Context, slots []*models.DeliverySlot) error {
+	args := m.Called(ctx, slots)
+	return args.Error(0)
+}
+
+func (m *MockDeliverySlotRepository) UpdateCapacity(ctx context.Context, id string, capacity int, isActive bool) (bool, error) {
+	args := m.Called(ctx, id, capacity, isActive)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDeliverySlotRepository) List(ctx context.Context, filter repository.DeliverySlotFilter) ([]*models.DeliverySlot, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DeliverySlot), args.Error(1)
+}
+
+// MockRefundRepository is a mock implementation of RefundRepository
+type MockRefundRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefundRepository) Record(ctx context.Context, refund *models.Refund, refundedBefore float64) (*models.Payment, error) {
+	args := m.Called(ctx, refund, refundedBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Payment), args.Error(1)
+}
+
+func (m *MockRefundRepository) ListByPaymentID(ctx context.Context, paymentID string) ([]*models.Refund, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Refund), args.Error(1)
+}
+
+// MockIdempotencyRepository is a mock implementation of IdempotencyRepository
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Claim(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	args := m.Called(ctx, record)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Update(ctx context.Context, record *models.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) CountExpired(ctx context.Context, before time.Time) (int64, int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+// MockPaymentReconciliationRepository is a mock implementation of PaymentReconciliationRepository
+type MockPaymentReconciliationRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentReconciliationRepository) CreateBatch(ctx context.Context, records []*models.PaymentReconciliation) error {
+	args := m.Called(ctx, records)
+	return args.Error(0)
+}
+
+func (m *MockPaymentReconciliationRepository) ListByRunID(ctx context.Context, runID string) ([]*models.PaymentReconciliation, error) {
+	args := m.Called(ctx, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PaymentReconciliation), args.Error(1)
+}
+
+func (m *MockPaymentReconciliationRepository) ListBetween(ctx context.Context, start, end time.Time) ([]*models.PaymentReconciliation, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PaymentReconciliation), args.Error(1)
+}
+
+// MockPaymentRoutingRuleRepository is a mock implementation of PaymentRoutingRuleRepository
+type MockPaymentRoutingRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRoutingRuleRepository) Create(ctx context.Context, rule *models.PaymentRoutingRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRoutingRuleRepository) GetByID(ctx context.Context, id string) (*models.PaymentRoutingRule, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentRoutingRule), args.Error(1)
+}
+
+func (m *MockPaymentRoutingRuleRepository) GetByName(ctx context.Context, name string) (*models.PaymentRoutingRule, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentRoutingRule), args.Error(1)
+}
+
+func (m *MockPaymentRoutingRuleRepository) Update(ctx context.Context, rule *models.PaymentRoutingRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRoutingRuleRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentRoutingRuleRepository) List(ctx context.Context, activeOnly bool) ([]*models.PaymentRoutingRule, error) {
+	args := m.Called(ctx, activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PaymentRoutingRule), args.Error(1)
+}
+
+// MockFulfillmentRepository is a mock implementation of repository.FulfillmentRepository
+type MockFulfillmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockFulfillmentRepository) GetCursor(ctx context.Context, consumer string) (int64, bool, error) {
+	args := m.Called(ctx, consumer)
+	return args.Get(0).(int64), args.Bool(1), args.Error(2)
+}
+
+func (m *MockFulfillmentRepository) Apply(ctx context.Context, consumer string, sequence int64, batch repository.FulfillmentBatch, at time.Time) ([]string, error) {
+	args := m.Called(ctx, consumer, sequence, batch, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockFulfillmentRepository) Confirm(ctx context.Context, orderID string, at time.Time) (bool, error) {
+	args := m.Called(ctx, orderID, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFulfillmentRepository) ListTasks(ctx context.Context, status models.FulfillmentTaskStatus, offset, limit int) ([]*models.FulfillmentTask, error) {
+	args := m.Called(ctx, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FulfillmentTask), args.Error(1)
+}
+
+func (m *MockFulfillmentRepository) CountTasks(ctx context.Context, status models.FulfillmentTaskStatus) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockFulfillmentRepository) ListAwaitingConfirmation(ctx context.Context, offset, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockFulfillmentRepository) CountAwaitingConfirmation(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockBackgroundJobRepository is a mock implementation of repository.BackgroundJobRepository
+type MockBackgroundJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockBackgroundJobRepository) Enqueue(ctx context.Context, job *workers.StoredJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) Lease(ctx context.Context, pool, owner string, limit int, visibility time.Duration) ([]*workers.StoredJob, error) {
+	args := m.Called(ctx, pool, owner, limit, visibility)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*workers.StoredJob), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) ExtendLease(ctx context.Context, id, owner string, visibility time.Duration) (bool, error) {
+	args := m.Called(ctx, id, owner, visibility)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) Release(ctx context.Context, id, owner string) error {
+	args := m.Called(ctx, id, owner)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) Complete(ctx context.Context, id, owner string) error {
+	args := m.Called(ctx, id, owner)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) Retry(ctx context.Context, id, owner string, runAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, owner, runAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) Fail(ctx context.Context, id, owner string, lastError string) error {
+	args := m.Called(ctx, id, owner, lastError)
+	return args.Error(0)
+}
+
+func (m *MockBackgroundJobRepository) ListDeadJobs(ctx context.Context, filter repository.DeadJobFilter, offset, limit int) ([]*models.DeadJob, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DeadJob), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) CountQueuedJobs(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) CountDeadJobs(ctx context.Context, filter repository.DeadJobFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) GetDeadJob(ctx context.Context, id string) (*models.DeadJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeadJob), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) RequeueDeadJob(ctx context.Context, id string, runAt time.Time) (*models.BackgroundJob, error) {
+	args := m.Called(ctx, id, runAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BackgroundJob), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) DeleteDeadJob(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBackgroundJobRepository) PurgeDeadJobs(ctx context.Context, filter repository.DeadJobFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockSupportSearchRepository is a mock implementation of repository.SupportSearchRepository
+type MockSupportSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSupportSearchRepository) FindUsers(ctx context.Context, filter repository.SupportSearchFilter, limit int) ([]*models.User, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockSupportSearchRepository) FindOrders(ctx context.Context, filter repository.SupportSearchFilter, limit int) ([]*models.Order, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Order), args.Error(1)
+}
+
+func (m *MockSupportSearchRepository) ListPaymentsByOrders(ctx context.Context, orderIDs []string) ([]*models.Payment, error) {
+	args := m.Called(ctx, orderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Payment), args.Error(1)
+}
+
+// MockCloseReportRepository is a mock implementation of repository.CloseReportRepository
+type MockCloseReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockCloseReportRepository) Claim(ctx context.Context, store, date string, at time.Time) (bool, error) {
+	args := m.Called(ctx, store, date, at)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCloseReportRepository) Release(ctx context.Context, store, date string) error {
+	args := m.Called(ctx, store, date)
+	return args.Error(0)
+}
+
+// MockCheckoutSagaRepository is a mock implementation of repository.CheckoutSagaRepository
+type MockCheckoutSagaRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckoutSagaRepository) Create(ctx context.Context, saga *models.CheckoutSaga) error {
+	args := m.Called(ctx, saga)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutSagaRepository) Update(ctx context.Context, saga *models.CheckoutSaga) error {
+	args := m.Called(ctx, saga)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutSagaRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]*models.CheckoutSaga, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CheckoutSaga), args.Error(1)
+}
+
+func (m *MockCheckoutSagaRepository) Claim(ctx context.Context, saga *models.CheckoutSaga, at time.Time) (bool, error) {
+	args := m.Called(ctx, saga, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockInventoryShardRepository is a mock implementation of repository.InventoryShardRepository
+type MockInventoryShardRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryShardRepository) Configure(ctx context.Context, productID string, shards int, auditLog *models.AuditLog) (*models.Inventory, error) {
+	args := m.Called(ctx, productID, shards, auditLog)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Inventory), args.Error(1)
+}
+
+func (m *MockInventoryShardRepository) List(ctx context.Context, productID string) ([]*models.InventoryShard, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.InventoryShard), args.Error(1)
+}
+
+func (m *MockInventoryShardRepository) Rebalance(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// MockPaymentIntentRepository is a mock implementation of repository.PaymentIntentRepository
+type MockPaymentIntentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentIntentRepository) Create(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error) {
+	args := m.Called(ctx, intent, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) GetByID(ctx context.Context, id string) (*models.PaymentIntent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) Claim(ctx context.Context, intent *models.PaymentIntent, now time.Time) (bool, error) {
+	args := m.Called(ctx, intent, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) Transition(ctx context.Context, intent *models.PaymentIntent, from models.PaymentIntentStatus) (bool, error) {
+	args := m.Called(ctx, intent, from)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) ListProcessing(ctx context.Context, limit int) ([]*models.PaymentIntent, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PaymentIntent), args.Error(1)
+}
+
+func (m *MockPaymentIntentRepository) ExpireLapsed(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+// MockProductSerialRepository is a mock implementation of repository.ProductSerialRepository
+type MockProductSerialRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductSerialRepository) Receive(ctx context.Context, serials []*models.ProductSerial) ([]*models.ProductSerial, error) {
+	args := m.Called(ctx, serials)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProductSerial), args.Error(1)
+}
+
+func (m *MockProductSerialRepository) ListByProduct(ctx context.Context, productID, lotNumber string, offset, limit int) ([]*models.ProductSerial, error) {
+	args := m.Called(ctx, productID, lotNumber, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ProductSerial), args.Error(1)
+}
+
+func (m *MockProductSerialRepository) CountByProduct(ctx context.Context, productID, lotNumber string) (int64, error) {
+	args := m.Called(ctx, productID, lotNumber)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProductSerialRepository) Capture(ctx context.Context, item *models.OrderItem, serialNumbers []string, at time.Time) ([]string, error) {
+	args := m.Called(ctx, item, serialNumbers, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockWarrantyClaimRepository is a mock implementation of repository.WarrantyClaimRepository
+type MockWarrantyClaimRepository struct {
+	mock.Mock
+}
+
+func (m *MockWarrantyClaimRepository) Create(ctx context.Context, claim *models.WarrantyClaim) (bool, error) {
+	args := m.Called(ctx, claim)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWarrantyClaimRepository) GetByID(ctx context.Context, id string) (*models.WarrantyClaim, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarrantyClaim), args.Error(1)
+}
+
+func (m *MockWarrantyClaimRepository) List(ctx context.Context, filter repository.WarrantyClaimFilter, offset, limit int) ([]*models.WarrantyClaim, error) {
+	args := m.Called(ctx, filter, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WarrantyClaim), args.Error(1)
+}
+
+func (m *MockWarrantyClaimRepository) Count(ctx context.Context, filter repository.WarrantyClaimFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWarrantyClaimRepository) Resolve(ctx context.Context, claim *models.WarrantyClaim, replacement *models.Order) (bool, error) {
+	args := m.Called(ctx, claim, replacement)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWarrantyClaimRepository) Reopen(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWarrantyClaimRepository) SummarizeByProduct(ctx context.Context, start, end time.Time) ([]repository.WarrantyClaimSummary, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.WarrantyClaimSummary), args.Error(1)
+}
+
+// MockNotificationExperimentRepository is a mock implementation of repository.NotificationExperimentRepository
+type MockNotificationExperimentRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationExperimentRepository) Create(ctx context.Context, experiment *models.NotificationExperiment) (bool, error) {
+	args := m.Called(ctx, experiment)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationExperimentRepository) GetByID(ctx context.Context, id string) (*models.NotificationExperiment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationExperiment), args.Error(1)
+}
+
+func (m *MockNotificationExperimentRepository) List(ctx context.Context, notificationType models.NotificationType, status models.NotificationExperimentStatus) ([]*models.NotificationExperiment, error) {
+	args := m.Called(ctx, notificationType, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.NotificationExperiment), args.Error(1)
+}
+
+func (m *MockNotificationExperimentRepository) GetActive(ctx context.Context, notificationType models.NotificationType, locale string) (*models.NotificationExperiment, error) {
+	args := m.Called(ctx, notificationType, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationExperiment), args.Error(1)
+}
+
+func (m *MockNotificationExperimentRepository) End(ctx context.Context, id string, from []models.NotificationExperimentStatus, status models.NotificationExperimentStatus, winner string, endedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, from, status, winner, endedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockNotificationExperimentRepository) SummarizeVariants(ctx context.Context, experiment *models.NotificationExperiment) ([]repository.NotificationVariantSummary, error) {
+	args := m.Called(ctx, experiment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.NotificationVariantSummary), args.Error(1)
+}
+
+// MockStockImportRepository is a mock implementation of repository.StockImportRepository
+type MockStockImportRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockImportRepository) Create(ctx context.Context, stockImport *models.StockImport, rows []*models.StockImportRow) error {
+	args := m.Called(ctx, stockImport, rows)
+	return args.Error(0)
+}
+
+func (m *MockStockImportRepository) GetByID(ctx context.Context, id string) (*models.StockImport, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockImport), args.Error(1)
+}
+
+func (m *MockStockImportRepository) ListRows(ctx context.Context, importID string, status models.StockImportRowStatus, offset, limit int) ([]*models.StockImportRow, error) {
+	args := m.Called(ctx, importID, status, offset, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockImportRow), args.Error(1)
+}
+
+func (m *MockStockImportRepository) CountRows(ctx context.Context, importID string, status models.StockImportRowStatus) (int64, error) {
+	args := m.Called(ctx, importID, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStockImportRepository) Fail(ctx context.Context, id string, message string) error {
+	args := m.Called(ctx, id, message)
+	return args.Error(0)
+}
+
+func (m *MockStockImportRepository) ApplyChunk(ctx context.Context, importID string, firstRow, lastRow int) ([]string, error) {
+	args := m.Called(ctx, importID, firstRow, lastRow)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
@@ -0,0 +1,269 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrderService is a mock implementation of services.OrderService
+type MockOrderService struct {
+	mock.Mock
+}
+
+func (m *MockOrderService) CreateOrder(ctx context.Context, req services.CreateOrderRequest) (*services.OrderResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) GetOrder(ctx context.Context, id string) (*services.OrderResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (*services.OrderResponse, error) {
+	args := m.Called(ctx, id, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) CancelOrder(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) CancelOrderItems(ctx context.Context, id string, req services.CancelOrderItemsRequest) (*services.OrderResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrders(ctx context.Context, req services.ListOrdersRequest) (*services.ListOrdersResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ListOrdersResponse), args.Error(1)
+}
+
+func (m *MockOrderService) GetUserOrders(ctx context.Context, userID string, req services.ListUserOrdersRequest) (*services.ListOrdersResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ListOrdersResponse), args.Error(1)
+}
+
+func (m *MockOrderService) StreamOrders(ctx context.Context, req services.ListOrdersRequest, fn func(*services.OrderResponse) error) error {
+	args := m.Called(ctx, req, fn)
+	return args.Error(0)
+}
+
+func (m *MockOrderService) UpdateOrderMetadata(ctx context.Context, id string, req services.UpdateMetadataRequest) (*services.OrderResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderResponse), args.Error(1)
+}
+
+func (m *MockOrderService) ImportOrders(ctx context.Context, r io.Reader) (*services.OrderImportResponse, error) {
+	args := m.Called(ctx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderImportResponse), args.Error(1)
+}
+
+// MockPaymentService is a mock implementation of services.PaymentService
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) ProcessPayment(ctx context.Context, req services.ProcessPaymentRequest) (*services.PaymentResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) GetPayment(ctx context.Context, id string) (*services.PaymentResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) GetOrderPayments(ctx context.Context, orderID string) ([]*services.PaymentResponse, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*services.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ResumePayment(ctx context.Context, id string) (*services.PaymentResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) RetryHeldPayments(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPaymentService) RefundPayment(ctx context.Context, id string, req services.RefundRequest) (*services.PaymentResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PaymentResponse), args.Error(1)
+}
+
+func (m *MockPaymentService) ListRefunds(ctx context.Context, id string) (*services.PaymentRefundsResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PaymentRefundsResponse), args.Error(1)
+}
+
+// MockProductService is a mock implementation of services.ProductService
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) CreateProduct(ctx context.Context, req services.CreateProductRequest) (*services.ProductResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) GetProduct(ctx context.Context, id string) (*services.ProductResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, id string, req services.UpdateProductRequest) (*services.ProductResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) ListProducts(ctx context.Context, req services.ListProductsRequest) (*services.ListProductsResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ListProductsResponse), args.Error(1)
+}
+
+// MockCartHoldService is a mock implementation of services.CartHoldService
+type MockCartHoldService struct {
+	mock.Mock
+}
+
+func (m *MockCartHoldService) HoldItem(ctx context.Context, userID string, req services.HoldCartItemRequest) (*services.CartHoldResponse, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.CartHoldResponse), args.Error(1)
+}
+
+func (m *MockCartHoldService) ReleaseItem(ctx context.Context, userID, productID string) error {
+	args := m.Called(ctx, userID, productID)
+	return args.Error(0)
+}
+
+func (m *MockCartHoldService) GetCartHolds(ctx context.Context, userID string) ([]*services.CartHoldResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*services.CartHoldResponse), args.Error(1)
+}
+
+func (m *MockCartHoldService) ReleaseExpiredHolds(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCartHoldService) GetConversionStats(ctx context.Context, req services.CartHoldStatsRequest) (*services.CartHoldStatsResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.CartHoldStatsResponse), args.Error(1)
+}
+
+// MockAdminOrderService is a mock implementation of services.AdminOrderService
+type MockAdminOrderService struct {
+	mock.Mock
+}
+
+func (m *MockAdminOrderService) GetOrderFullView(ctx context.Context, id string) (*services.OrderFullViewResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderFullViewResponse), args.Error(1)
+}
+
+func (m *MockAdminOrderService) OverrideItemPrice(ctx context.Context, orderID, itemID, userID string, req services.OverrideItemPriceRequest) (*services.OrderItemPriceOverrideResponse, error) {
+	args := m.Called(ctx, orderID, itemID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderItemPriceOverrideResponse), args.Error(1)
+}
+
+func (m *MockAdminOrderService) GetPackingSlip(ctx context.Context, id string) (*services.PackingSlipResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PackingSlipResponse), args.Error(1)
+}
+
+func (m *MockAdminOrderService) GetPickList(ctx context.Context, req services.PickListRequest) (*services.PickListResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PickListResponse), args.Error(1)
+}
+
+func (m *MockAdminOrderService) RescoreOrder(ctx context.Context, id string) (*services.OrderRisk, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.OrderRisk), args.Error(1)
+}
@@ -0,0 +1,167 @@
+package notifications_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records the notifications dispatched through it
+type recordingSink struct {
+	mutex      sync.Mutex
+	dispatched []*notifications.Notification
+}
+
+func (s *recordingSink) Dispatch(notification *notifications.Notification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dispatched = append(s.dispatched, notification)
+	return nil
+}
+
+func (s *recordingSink) notifications() []*notifications.Notification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*notifications.Notification(nil), s.dispatched...)
+}
+
+// startScheduler starts a digest scheduler whose windows only close when flushed by
+// the test, stopping it when the test ends
+func startScheduler(t *testing.T, maxItems int) (*notifications.DigestScheduler, *recordingSink) {
+	sink := &recordingSink{}
+	config := notifications.DefaultDigestConfig()
+	config.CheckInterval = time.Hour
+	config.MaxItemsPerDigest = maxItems
+
+	scheduler := notifications.NewDigestScheduler(config, sink, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	require.NoError(t, scheduler.Start(context.Background()))
+	t.Cleanup(func() { _ = scheduler.Stop() })
+	return scheduler, sink
+}
+
+// notification returns a notification of the given type for a recipient
+func notification(notificationType notifications.NotificationType, recipient, subject string) *notifications.Notification {
+	n := notifications.NewNotification(notificationType, "email", recipient)
+	n.Subject = subject
+	return n
+}
+
+// Test Classify - Informational types wait for a digest unless sent with high priority
+func TestDigestPolicy_Classify(t *testing.T) {
+	policy := notifications.DefaultDigestPolicy()
+
+	assert.Equal(t, notifications.UrgencyHourly, policy.Classify(notification(notifications.NotificationTypeLowStock, "user-1", "")))
+	assert.Equal(t, notifications.UrgencyHourly, policy.Classify(notification(notifications.NotificationTypeReportCompleted, "user-1", "")))
+	assert.Equal(t, notifications.UrgencyDaily, policy.Classify(notification(notifications.NotificationTypePromotion, "user-1", "")))
+	assert.Equal(t, notifications.UrgencyImmediate, policy.Classify(notification(notifications.NotificationTypePaymentFailed, "user-1", "")))
+
+	urgent := notification(notifications.NotificationTypeLowStock, "user-1", "")
+	urgent.SetPriority(notifications.PriorityHigh)
+	assert.Equal(t, notifications.UrgencyImmediate, policy.Classify(urgent))
+}
+
+// Test Submit - Urgent notifications are dispatched as they are submitted
+func TestSubmit_ImmediateDispatched(t *testing.T) {
+	scheduler, sink := startScheduler(t, 50)
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypePaymentFailed, "user-1", "Payment failed")))
+
+	require.Len(t, sink.notifications(), 1)
+	assert.Equal(t, notifications.NotificationTypePaymentFailed, sink.notifications()[0].Type)
+	assert.Equal(t, 0, scheduler.GetPendingCount())
+}
+
+// Test FlushDue - Buffered notifications are sent as one digest per recipient once
+// their window has ended
+func TestFlushDue_WindowExpiry(t *testing.T) {
+	scheduler, sink := startScheduler(t, 50)
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-1", "Widget is low on stock")))
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeReportCompleted, "user-1", "Sales report ready")))
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-2", "Gadget is low on stock")))
+	assert.Equal(t, 3, scheduler.GetPendingCount())
+
+	// The hourly window is still open
+	scheduler.FlushDue(time.Now())
+	assert.Empty(t, sink.notifications())
+
+	scheduler.FlushDue(time.Now().Add(time.Hour))
+
+	digests := sink.notifications()
+	require.Len(t, digests, 2)
+	assert.Equal(t, 0, scheduler.GetPendingCount())
+	for _, digest := range digests {
+		assert.Equal(t, notifications.NotificationTypeDigest, digest.Type)
+		assert.Equal(t, "email", digest.Channel)
+		if digest.Recipient == "user-1" {
+			assert.Equal(t, "Your hourly summary (2 updates)", digest.Subject)
+			assert.Contains(t, digest.Body, "- Widget is low on stock\n")
+			assert.Contains(t, digest.Body, "- Sales report ready\n")
+			assert.Len(t, digest.Data["notification_ids"], 2)
+		} else {
+			assert.Equal(t, "user-2", digest.Recipient)
+			assert.Equal(t, "Your hourly summary (1 updates)", digest.Subject)
+		}
+	}
+}
+
+// Test FlushDue - Daily digests outlast an hourly window
+func TestFlushDue_DailyWindow(t *testing.T) {
+	scheduler, sink := startScheduler(t, 50)
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypePromotion, "user-1", "Weekend sale")))
+
+	scheduler.FlushDue(time.Now().UTC().Truncate(time.Hour).Add(time.Hour))
+	if time.Now().UTC().Hour() < 23 {
+		assert.Empty(t, sink.notifications())
+	}
+
+	scheduler.FlushDue(time.Now().Add(24 * time.Hour))
+	require.Len(t, sink.notifications(), 1)
+	assert.Equal(t, "daily", sink.notifications()[0].Metadata["urgency"])
+}
+
+// Test Submit - A digest reaching the item limit is sent before its window ends
+func TestSubmit_FlushesAtMaxItems(t *testing.T) {
+	scheduler, sink := startScheduler(t, 2)
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-1", "Widget is low on stock")))
+	assert.Empty(t, sink.notifications())
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-1", "Gadget is low on stock")))
+
+	require.Len(t, sink.notifications(), 1)
+	assert.Equal(t, "Your hourly summary (2 updates)", sink.notifications()[0].Subject)
+	assert.Equal(t, 0, scheduler.GetPendingCount())
+}
+
+// Test Stop - Pending digests are sent on shutdown, and later notifications are not
+// left buffered
+func TestStop_FlushesPending(t *testing.T) {
+	sink := &recordingSink{}
+	config := notifications.DefaultDigestConfig()
+	config.CheckInterval = time.Hour
+	scheduler := notifications.NewDigestScheduler(config, sink, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	require.NoError(t, scheduler.Start(context.Background()))
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-1", "Widget is low on stock")))
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypePromotion, "user-1", "Weekend sale")))
+	assert.Empty(t, sink.notifications())
+
+	require.NoError(t, scheduler.Stop())
+
+	assert.Len(t, sink.notifications(), 2)
+	assert.Equal(t, 0, scheduler.GetPendingCount())
+
+	require.NoError(t, scheduler.Submit(notification(notifications.NotificationTypeLowStock, "user-1", "Gadget is low on stock")))
+	require.Len(t, sink.notifications(), 3)
+	assert.Equal(t, notifications.NotificationTypeLowStock, sink.notifications()[2].Type)
+}
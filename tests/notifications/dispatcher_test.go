@@ -0,0 +1,81 @@
+package notifications_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyChannel fails the first sends it is given, then delivers the rest
+type flakyChannel struct {
+	mutex     sync.Mutex
+	failures  int
+	attempts  []time.Time
+	delivered chan *notifications.Notification
+}
+
+func (c *flakyChannel) Send(ctx context.Context, notification *notifications.Notification) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.attempts = append(c.attempts, time.Now())
+	if len(c.attempts) <= c.failures {
+		return errors.New("provider unavailable")
+	}
+	c.delivered <- notification
+	return nil
+}
+
+func (c *flakyChannel) GetName() string        { return "email" }
+func (c *flakyChannel) IsEnabled() bool        { return true }
+func (c *flakyChannel) GetRateLimit() int      { return 1000 }
+func (c *flakyChannel) SupportsTemplate() bool { return false }
+
+func (c *flakyChannel) sendTimes() []time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]time.Time(nil), c.attempts...)
+}
+
+// Test retryProcessor - A failed notification is queued again once its backoff has
+// passed, without waiting for the retry check interval
+func TestDispatcher_RetriesFailedNotificationWhenDue(t *testing.T) {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	channel := &flakyChannel{failures: 1, delivered: make(chan *notifications.Notification, 1)}
+	provider := notifications.NewNotificationProvider(log)
+	provider.RegisterChannel(channel)
+
+	config := notifications.DefaultDispatcherConfig()
+	config.WorkerCount = 1
+	config.BatchSize = 1
+	config.RetryCheckInterval = time.Hour
+	dispatcher := notifications.NewNotificationDispatcher(config, provider, log)
+	require.NoError(t, dispatcher.Start(context.Background()))
+	t.Cleanup(func() { _ = dispatcher.Stop() })
+
+	n := notification(notifications.NotificationTypeOrderConfirmation, "user-1", "Order confirmed")
+	n.RetryDelay = 25 * time.Millisecond
+	require.NoError(t, dispatcher.Dispatch(n))
+
+	select {
+	case delivered := <-channel.delivered:
+		assert.Equal(t, n.ID, delivered.ID)
+		assert.Equal(t, 1, delivered.RetryCount)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed notification was not retried")
+	}
+
+	// The first retry backs off twice the retry delay
+	attempts := channel.sendTimes()
+	require.Len(t, attempts, 2)
+	assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 50*time.Millisecond)
+	assert.Equal(t, int64(1), dispatcher.GetMetrics().NotificationsRetried)
+}
@@ -0,0 +1,427 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// scriptedGateway returns the given failure types in order, then succeeds, or leaves
+// the charge processing when pending
+type scriptedGateway struct {
+	gatewayType payments.PaymentGatewayType
+	failures    []payments.PaymentFailureType
+	healthy     bool
+	pending     bool
+	requests    []*payments.GatewayPaymentRequest
+}
+
+func (g *scriptedGateway) ProcessPayment(ctx context.Context, req *payments.GatewayPaymentRequest) (*payments.GatewayPaymentResponse, error) {
+	g.requests = append(g.requests, req)
+	if len(g.failures) > 0 {
+		failureType := g.failures[0]
+		g.failures = g.failures[1:]
+		return &payments.GatewayPaymentResponse{Status: "failed", FailureType: failureType, FailureMessage: string(failureType)}, nil
+	}
+	if g.pending {
+		return &payments.GatewayPaymentResponse{Status: "processing", TransactionID: string(g.gatewayType) + "-txn", Amount: req.Amount}, nil
+	}
+	return &payments.GatewayPaymentResponse{Status: "completed", TransactionID: string(g.gatewayType) + "-txn", Amount: req.Amount, ProcessingFee: 1.75}, nil
+}
+
+func (g *scriptedGateway) RefundPayment(ctx context.Context, req *payments.GatewayRefundRequest) (*payments.GatewayRefundResponse, error) {
+	return nil, nil
+}
+
+func (g *scriptedGateway) GetPaymentStatus(ctx context.Context, gatewayTransactionID string) (*payments.GatewayPaymentStatus, error) {
+	return nil, nil
+}
+
+func (g *scriptedGateway) GetGatewayType() payments.PaymentGatewayType {
+	return g.gatewayType
+}
+
+func (g *scriptedGateway) IsHealthy(ctx context.Context) bool {
+	return g.healthy
+}
+
+// PaymentProcessorTestSuite defines the test suite for PaymentProcessor
+type PaymentProcessorTestSuite struct {
+	suite.Suite
+	gateways    *payments.PaymentGatewayManager
+	idempotency *payments.IdempotencyManager
+	processor   *payments.PaymentProcessor
+	stripe      *scriptedGateway
+	paypal      *scriptedGateway
+	square      *scriptedGateway
+	ctx         context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentProcessorTestSuite) SetupTest() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.stripe = &scriptedGateway{gatewayType: payments.GatewayTypeStripe, healthy: true}
+	suite.paypal = &scriptedGateway{gatewayType: payments.GatewayTypePayPal, healthy: true}
+	suite.square = &scriptedGateway{gatewayType: payments.GatewayTypeSquare, healthy: true}
+
+	suite.gateways = payments.NewPaymentGatewayManager(log)
+	suite.gateways.RegisterGateway(suite.stripe)
+	suite.gateways.RegisterGateway(suite.paypal)
+	suite.gateways.RegisterGateway(suite.square)
+
+	suite.idempotency = payments.NewIdempotencyManager(time.Hour, nil, log) // Records kept in memory
+	suite.processor = payments.NewPaymentProcessor(suite.gateways, payments.NewCircuitBreakerManager(log), suite.idempotency, log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PaymentProcessorTestSuite) TearDownTest() {
+	suite.idempotency.Stop()
+}
+
+// newRequest builds a stripe payment request with retries that don't wait
+func (suite *PaymentProcessorTestSuite) newRequest(key string) *payments.PaymentRequest {
+	retryPolicy := payments.DefaultRetryPolicy()
+	retryPolicy.InitialDelay = time.Millisecond
+	retryPolicy.MaxDelay = time.Millisecond
+
+	return &payments.PaymentRequest{
+		IdempotencyKey: key,
+		OrderID:        "order-1",
+		Amount:         50.00,
+		Currency:       "USD",
+		PaymentMethod:  "credit_card",
+		Gateway:        payments.GatewayTypeStripe,
+		RetryPolicy:    retryPolicy,
+	}
+}
+
+// Test ProcessPayment - Retriable failures below the threshold are retried on the same gateway
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_RetriesSameGatewayBelowThreshold() {
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeNetworkError}
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-1"))
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Equal(2, result.AttemptCount)
+	suite.Len(suite.stripe.requests, 2)
+	suite.Empty(suite.paypal.requests)
+	suite.Equal("key-1:stripe", suite.stripe.requests[1].IdempotencyKey) // Same gateway keeps its key
+}
+
+// Test ProcessPayment - Repeated timeouts fail over to a healthy gateway with its own key
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_FailsOverAfterThreshold() {
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeNetworkError, payments.FailureTypeGatewayTimeout}
+	suite.paypal.healthy = false
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-2"))
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Equal(3, result.AttemptCount)
+	suite.Len(suite.stripe.requests, 2)
+	suite.Empty(suite.paypal.requests) // Unhealthy gateways are skipped
+	suite.Len(suite.square.requests, 1)
+	suite.Equal("key-2:square", suite.square.requests[0].IdempotencyKey)
+	suite.Equal(payments.GatewayTypeSquare, result.Attempts[2].Gateway)
+	suite.Equal("key-2", result.IdempotencyKey)
+}
+
+// Test ProcessPayment - Attempts keep the transaction ID and fee of the gateway charge,
+// so the payment can be reconciled against the gateway that took it
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_AttemptsKeepGatewayTransaction() {
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeNetworkError, payments.FailureTypeGatewayTimeout}
+	suite.paypal.healthy = false
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-txn"))
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(result.Attempts, 3)
+	suite.Empty(result.Attempts[0].TransactionID)
+	suite.Equal("square-txn", result.Attempts[2].TransactionID)
+	suite.Equal(1.75, result.Attempts[2].ProcessingFee)
+}
+
+// Test ProcessPayment - A charge the gateway confirms later is taken without a retry and
+// left processing under its transaction ID
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_PendingCharge() {
+	suite.stripe.pending = true
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-pending"))
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Equal("processing", result.Status)
+	suite.Equal(1, result.AttemptCount)
+	suite.True(result.Attempts[0].Pending)
+	suite.Equal("stripe-txn", result.Attempts[0].TransactionID)
+}
+
+// Test ProcessPayment - Declines don't count towards failover
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_DeclinesDoNotFailOver() {
+	suite.stripe.failures = []payments.PaymentFailureType{
+		payments.FailureTypeTemporaryDecline,
+		payments.FailureTypeTemporaryDecline,
+		payments.FailureTypeTemporaryDecline,
+	}
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-3"))
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Len(suite.stripe.requests, 4)
+	suite.Empty(suite.paypal.requests)
+	suite.Empty(suite.square.requests)
+}
+
+// Test ProcessPayment - Non-retriable failures stop processing
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_NonRetriableFailureStops() {
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeInsufficientFunds}
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-4"))
+
+	// Assert
+	suite.NoError(err)
+	suite.False(result.Success)
+	suite.Equal("failed", result.Status)
+	suite.Equal(payments.FailureTypeInsufficientFunds, result.FinalFailureType)
+	suite.Equal(1, result.AttemptCount)
+}
+
+// Test ProcessPayment - Failover disabled keeps retrying the original gateway
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_FailoverDisabled() {
+	suite.stripe.failures = []payments.PaymentFailureType{
+		payments.FailureTypeNetworkError,
+		payments.FailureTypeNetworkError,
+		payments.FailureTypeNetworkError,
+	}
+	req := suite.newRequest("key-5")
+	req.FailoverPolicy = &payments.FailoverPolicy{}
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Len(suite.stripe.requests, 4)
+	suite.Empty(suite.paypal.requests)
+	suite.Empty(suite.square.requests)
+}
+
+// Test ProcessPayment - A repeated request returns the stored result without charging again
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_IdempotentAcrossFailover() {
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeGatewayTimeout, payments.FailureTypeGatewayTimeout}
+
+	// Execute
+	first, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-6"))
+	suite.NoError(err)
+	second, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-6"))
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(first.PaymentID, second.PaymentID)
+	suite.Len(suite.stripe.requests, 2)
+	suite.Len(suite.paypal.requests, 1) // Alternates are tried in name order
+}
+
+// Test ProcessPayment - A request without a gateway is routed by the selector
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_RoutesWithSelector() {
+	suite.gateways.SetSelector(payments.NewCurrencyAffinitySelector(
+		map[string]payments.PaymentGatewayType{"eur": payments.GatewayTypeSquare},
+		payments.NewOrderedSelector(),
+	))
+	req := suite.newRequest("key-7")
+	req.Gateway = ""
+	req.Currency = "EUR"
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Len(suite.square.requests, 1)
+	suite.Equal("key-7:square", suite.square.requests[0].IdempotencyKey)
+	suite.Empty(suite.stripe.requests)
+}
+
+// Test ProcessPayment - Failover goes to the gateway the selector picks
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_FailsOverWithSelector() {
+	suite.gateways.SetSelector(payments.NewWeightedSelector(map[payments.PaymentGatewayType]int{payments.GatewayTypeSquare: 1}))
+	suite.stripe.failures = []payments.PaymentFailureType{payments.FailureTypeGatewayTimeout, payments.FailureTypeGatewayTimeout}
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-8"))
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Empty(suite.paypal.requests)
+	suite.Len(suite.square.requests, 1)
+}
+
+// Test ProcessPayment - Without a healthy gateway, a request without one is refused
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_NoGatewayToRoute() {
+	suite.stripe.healthy = false
+	suite.paypal.healthy = false
+	suite.square.healthy = false
+	req := suite.newRequest("key-9")
+	req.Gateway = ""
+
+	// Execute
+	_, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.ErrorContains(err, "no healthy payment gateway")
+}
+
+// Test ProcessPayment - A routing rule sends matching payments to its gateway before the selector
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_RoutesByRule() {
+	minAmount := 5000.00
+	suite.gateways.SetRoutingRules([]payments.RoutingRule{
+		{Name: "large", MinAmount: &minAmount, Action: payments.RoutingActionRoute, Gateway: payments.GatewayTypePayPal},
+	})
+	req := suite.newRequest("key-10")
+	req.Gateway = ""
+	req.Amount = 7500.00
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Len(suite.paypal.requests, 1)
+	suite.Empty(suite.stripe.requests) // The selector alone picks the first gateway by name
+}
+
+// Test ProcessPayment - A payment routed to an unavailable gateway is left to the selector
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_RuleGatewayUnavailable() {
+	suite.gateways.SetRoutingRules([]payments.RoutingRule{
+		{Name: "euro", Currencies: []string{"EUR"}, Action: payments.RoutingActionRoute, Gateway: payments.GatewayTypeSquare},
+	})
+	suite.square.healthy = false
+	req := suite.newRequest("key-11")
+	req.Gateway = ""
+	req.Currency = "EUR"
+
+	// Execute
+	result, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(result.Success)
+	suite.Empty(suite.square.requests)
+	suite.Len(suite.paypal.requests, 1)
+}
+
+// Test ProcessPayment - A payment a rule bypasses is refused without reaching a gateway
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_BypassedByRule() {
+	suite.gateways.SetRoutingRules([]payments.RoutingRule{
+		{Name: "transfers", PaymentMethods: []string{"bank_transfer"}, Action: payments.RoutingActionBypass},
+	})
+	req := suite.newRequest("key-12")
+	req.Gateway = ""
+	req.PaymentMethod = "bank_transfer"
+
+	// Execute
+	_, err := suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.ErrorIs(err, payments.ErrPaymentBypassesGateways)
+	suite.Empty(suite.stripe.requests)
+	suite.Empty(suite.paypal.requests)
+	suite.Empty(suite.square.requests)
+}
+
+// Test ProcessPayment - Reusing a key for a different payment is rejected without charging
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_RejectsKeyReuseWithDifferentRequest() {
+	_, err := suite.processor.ProcessPayment(suite.ctx, suite.newRequest("key-10"))
+	suite.NoError(err)
+
+	req := suite.newRequest("key-10")
+	req.Amount = 75.00
+
+	// Execute
+	_, err = suite.processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.ErrorIs(err, payments.ErrIdempotencyKeyReused)
+	suite.Len(suite.stripe.requests, 1)
+}
+
+// Test ProcessPayment - A result stored in the database by another replica is returned
+// without charging again
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_ReturnsResultStoredInDatabase() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	records := new(mocks.MockIdempotencyRepository)
+	idempotency := payments.NewIdempotencyManager(time.Hour, records, log)
+	defer idempotency.Stop()
+	processor := payments.NewPaymentProcessor(suite.gateways, payments.NewCircuitBreakerManager(log), idempotency, log)
+
+	req := suite.newRequest("key-11")
+	records.On("GetByKey", suite.ctx, "key-11").Return(&models.IdempotencyRecord{
+		Key:         "key-11",
+		PaymentID:   "payment-1",
+		RequestHash: idempotency.GenerateRequestHash(req),
+		Status:      "completed",
+		Result:      json.RawMessage(`{"payment_id":"payment-1","success":true}`),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}, nil)
+
+	// Execute
+	result, err := processor.ProcessPayment(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal("payment-1", result.PaymentID)
+	suite.Empty(suite.stripe.requests)
+	records.AssertNotCalled(suite.T(), "Claim", mock.Anything, mock.Anything)
+}
+
+// Test ProcessPayment - A key another replica claimed first is reported as in progress
+func (suite *PaymentProcessorTestSuite) TestProcessPayment_KeyClaimedByAnotherReplica() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	records := new(mocks.MockIdempotencyRepository)
+	idempotency := payments.NewIdempotencyManager(time.Hour, records, log)
+	defer idempotency.Stop()
+	processor := payments.NewPaymentProcessor(suite.gateways, payments.NewCircuitBreakerManager(log), idempotency, log)
+
+	records.On("GetByKey", suite.ctx, "key-12").Return(nil, nil)
+	records.On("Claim", suite.ctx, mock.AnythingOfType("*models.IdempotencyRecord")).Return(false, nil)
+
+	// Execute
+	_, err := processor.ProcessPayment(suite.ctx, suite.newRequest("key-12"))
+
+	// Assert
+	suite.ErrorContains(err, "already being processed")
+	suite.Empty(suite.stripe.requests)
+}
+
+// TestPaymentProcessorTestSuite runs the test suite
+func TestPaymentProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentProcessorTestSuite))
+}
@@ -0,0 +1,131 @@
+package payments_test
+
+import (
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var routingCandidates = []payments.GatewayCandidate{
+	{Type: payments.GatewayTypePayPal, AverageLatency: 300 * time.Millisecond, Samples: 5},
+	{Type: payments.GatewayTypeSquare, AverageLatency: 100 * time.Millisecond, Samples: 5},
+	{Type: payments.GatewayTypeStripe, AverageLatency: 200 * time.Millisecond, Samples: 5},
+}
+
+func routingRequest(currency string) *payments.PaymentRequest {
+	return &payments.PaymentRequest{IdempotencyKey: "key-1", Currency: currency}
+}
+
+func TestWeightedSelector(t *testing.T) {
+	weights := map[payments.PaymentGatewayType]int{
+		payments.GatewayTypeStripe: 70,
+		payments.GatewayTypePayPal: 30,
+	}
+	tests := []struct {
+		draw     int
+		expected payments.PaymentGatewayType
+	}{
+		{0, payments.GatewayTypePayPal}, // Candidates are drawn from in name order
+		{29, payments.GatewayTypePayPal},
+		{30, payments.GatewayTypeStripe},
+		{99, payments.GatewayTypeStripe},
+	}
+
+	for _, tt := range tests {
+		var total int
+		selector := payments.NewWeightedSelectorWithRandom(weights, func(n int) int {
+			total = n
+			return tt.draw
+		})
+
+		gateway, ok := selector.SelectGateway(routingRequest("USD"), routingCandidates)
+		assert.True(t, ok)
+		assert.Equal(t, tt.expected, gateway, "draw %d", tt.draw)
+		assert.Equal(t, 100, total) // Square has no weight
+	}
+}
+
+func TestWeightedSelector_NoWeightedCandidate(t *testing.T) {
+	selector := payments.NewWeightedSelector(map[payments.PaymentGatewayType]int{payments.GatewayTypeStripe: 1})
+
+	gateway, ok := selector.SelectGateway(routingRequest("USD"), routingCandidates[:2])
+	assert.True(t, ok)
+	assert.Equal(t, payments.GatewayTypePayPal, gateway)
+
+	_, ok = selector.SelectGateway(routingRequest("USD"), nil)
+	assert.False(t, ok)
+}
+
+func TestLeastLatencySelector(t *testing.T) {
+	selector := payments.NewLeastLatencySelector()
+
+	gateway, ok := selector.SelectGateway(routingRequest("USD"), routingCandidates)
+	assert.True(t, ok)
+	assert.Equal(t, payments.GatewayTypeSquare, gateway)
+
+	// A gateway not measured yet is tried first
+	unmeasured := append([]payments.GatewayCandidate(nil), routingCandidates...)
+	unmeasured[2] = payments.GatewayCandidate{Type: payments.GatewayTypeStripe}
+	gateway, _ = selector.SelectGateway(routingRequest("USD"), unmeasured)
+	assert.Equal(t, payments.GatewayTypeStripe, gateway)
+}
+
+func TestCurrencyAffinitySelector(t *testing.T) {
+	selector := payments.NewCurrencyAffinitySelector(
+		map[string]payments.PaymentGatewayType{"EUR": payments.GatewayTypeStripe},
+		payments.NewLeastLatencySelector(),
+	)
+
+	gateway, _ := selector.SelectGateway(routingRequest("eur"), routingCandidates)
+	assert.Equal(t, payments.GatewayTypeStripe, gateway)
+
+	gateway, _ = selector.SelectGateway(routingRequest("USD"), routingCandidates)
+	assert.Equal(t, payments.GatewayTypeSquare, gateway)
+
+	// A pinned gateway that is not a candidate falls back
+	gateway, _ = selector.SelectGateway(routingRequest("EUR"), routingCandidates[:2])
+	assert.Equal(t, payments.GatewayTypeSquare, gateway)
+}
+
+func TestEvaluateRoutingRules(t *testing.T) {
+	minAmount := 5000.00
+	rules := []payments.RoutingRule{
+		{Name: "euro", Priority: 10, Currencies: []string{"EUR"}, Action: payments.RoutingActionRoute, Gateway: payments.GatewayTypeSquare},
+		{Name: "large", Priority: 20, MinAmount: &minAmount, Action: payments.RoutingActionRoute, Gateway: payments.GatewayTypePayPal},
+		{Name: "transfers", Priority: 30, PaymentMethods: []string{"bank_transfer"}, Action: payments.RoutingActionBypass},
+	}
+	payments.SortRoutingRules(rules)
+	tests := []struct {
+		amount   float64
+		currency string
+		method   string
+		rule     string
+		bypass   bool
+		gateway  payments.PaymentGatewayType
+	}{
+		{6000, "EUR", "credit_card", "large", false, payments.GatewayTypePayPal}, // Higher priority wins
+		{100, "eur", "credit_card", "euro", false, payments.GatewayTypeSquare},
+		{6000, "USD", "bank_transfer", "transfers", true, ""},
+		{100, "USD", "credit_card", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		req := &payments.PaymentRequest{Amount: tt.amount, Currency: tt.currency, PaymentMethod: tt.method}
+		decision := payments.EvaluateRoutingRules(rules, req)
+
+		if tt.rule == "" {
+			assert.Nil(t, decision.Rule)
+			assert.Len(t, decision.Checks, 3)
+			assert.Contains(t, decision.Checks[1].Reason, "below 5000.00")
+			continue
+		}
+		if assert.NotNil(t, decision.Rule) {
+			assert.Equal(t, tt.rule, decision.Rule.Name)
+		}
+		assert.Equal(t, tt.bypass, decision.Bypass)
+		assert.Equal(t, tt.gateway, decision.Gateway)
+	}
+}
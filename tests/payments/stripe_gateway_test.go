@@ -0,0 +1,253 @@
+package payments_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripeServer answers Stripe API requests with handler, recording the last request and its form
+type stripeServer struct {
+	*httptest.Server
+	request *http.Request
+	form    url.Values
+}
+
+func newStripeServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *stripeServer {
+	server := &stripeServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		server.request = r
+		server.form = r.PostForm
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newStripeGateway(server *stripeServer) *payments.StripeGateway {
+	return payments.NewStripeGateway(&payments.StripeConfig{
+		SecretKey:  "sk_test_123",
+		BaseURL:    server.URL,
+		APIVersion: "2024-06-20",
+	}, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+}
+
+func respondJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestStripeGateway_ProcessPayment(t *testing.T) {
+	server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"id":       "pi_123",
+			"status":   "succeeded",
+			"amount":   1999,
+			"currency": "usd",
+			"latest_charge": map[string]interface{}{
+				"id":                 "ch_123",
+				"authorization_code": "A1B2C3",
+			},
+		})
+	})
+
+	response, err := newStripeGateway(server).ProcessPayment(context.Background(), &payments.GatewayPaymentRequest{
+		Amount:         19.99,
+		Currency:       "USD",
+		PaymentMethod:  "pm_card_visa",
+		IdempotencyKey: "payment-1:stripe",
+		OrderReference: "order-1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", response.Status)
+	assert.Equal(t, "pi_123", response.TransactionID)
+	assert.Equal(t, "A1B2C3", response.AuthorizationCode)
+	assert.Empty(t, response.FailureType)
+
+	assert.Equal(t, http.MethodPost, server.request.Method)
+	assert.Equal(t, "/v1/payment_intents", server.request.URL.Path)
+	assert.Equal(t, "Bearer sk_test_123", server.request.Header.Get("Authorization"))
+	assert.Equal(t, "payment-1:stripe", server.request.Header.Get("Idempotency-Key"))
+	assert.Equal(t, "2024-06-20", server.request.Header.Get("Stripe-Version"))
+	assert.Equal(t, "1999", server.form.Get("amount"))
+	assert.Equal(t, "usd", server.form.Get("currency"))
+	assert.Equal(t, "pm_card_visa", server.form.Get("payment_method"))
+	assert.Equal(t, "true", server.form.Get("confirm"))
+	assert.Equal(t, "order-1", server.form.Get("metadata[order_reference]"))
+}
+
+func TestStripeGateway_ProcessPayment_ZeroDecimalCurrency(t *testing.T) {
+	server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{"id": "pi_123", "status": "succeeded", "latest_charge": "ch_123"})
+	})
+
+	response, err := newStripeGateway(server).ProcessPayment(context.Background(), &payments.GatewayPaymentRequest{
+		Amount:        1500,
+		Currency:      "JPY",
+		PaymentMethod: "pm_card_visa",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", response.Status)
+	assert.Equal(t, "1500", server.form.Get("amount"))
+	assert.Equal(t, "ch_123", response.GatewayResponse["charge"])
+}
+
+func TestStripeGateway_ProcessPayment_Failures(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		error    map[string]interface{}
+		expected payments.PaymentFailureType
+	}{
+		{"insufficient funds", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "card_declined", "decline_code": "insufficient_funds"},
+			payments.FailureTypeInsufficientFunds},
+		{"expired card", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "expired_card"},
+			payments.FailureTypeExpiredCard},
+		{"incorrect cvc", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "incorrect_cvc"},
+			payments.FailureTypeInvalidCard},
+		{"fraudulent", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "card_declined", "decline_code": "fraudulent"},
+			payments.FailureTypeFraudSuspected},
+		{"stolen card", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "card_declined", "decline_code": "stolen_card"},
+			payments.FailureTypeCardBlocked},
+		{"try again later", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "card_declined", "decline_code": "try_again_later"},
+			payments.FailureTypeTemporaryDecline},
+		{"unknown decline", http.StatusPaymentRequired,
+			map[string]interface{}{"type": "card_error", "code": "card_declined", "decline_code": "something_new"},
+			payments.FailureTypeCardBlocked},
+		{"amount too small", http.StatusBadRequest,
+			map[string]interface{}{"type": "invalid_request_error", "code": "amount_too_small", "param": "amount"},
+			payments.FailureTypeInvalidAmount},
+		{"unknown payment method", http.StatusBadRequest,
+			map[string]interface{}{"type": "invalid_request_error", "code": "resource_missing", "param": "payment_method"},
+			payments.FailureTypeInvalidCard},
+		{"invalid api key", http.StatusUnauthorized,
+			map[string]interface{}{"type": "authentication_error"},
+			payments.FailureTypeAuthenticationError},
+		{"rate limited", http.StatusTooManyRequests,
+			map[string]interface{}{"type": "rate_limit_error"},
+			payments.FailureTypeRateLimited},
+		{"stripe error", http.StatusInternalServerError,
+			map[string]interface{}{"type": "api_error"},
+			payments.FailureTypeGatewayError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+				tt.error["message"] = "Your card was declined."
+				tt.error["payment_intent"] = map[string]interface{}{"id": "pi_declined"}
+				respondJSON(w, tt.status, map[string]interface{}{"error": tt.error})
+			})
+
+			response, err := newStripeGateway(server).ProcessPayment(context.Background(), &payments.GatewayPaymentRequest{
+				Amount:        10,
+				Currency:      "USD",
+				PaymentMethod: "pm_card_visa",
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, "failed", response.Status)
+			assert.Equal(t, tt.expected, response.FailureType)
+			assert.Equal(t, "Your card was declined.", response.FailureMessage)
+			assert.Equal(t, "pi_declined", response.TransactionID)
+		})
+	}
+}
+
+func TestStripeGateway_ProcessPayment_RequiresAuthentication(t *testing.T) {
+	server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{"id": "pi_123", "status": "requires_action"})
+	})
+
+	response, err := newStripeGateway(server).ProcessPayment(context.Background(), &payments.GatewayPaymentRequest{
+		Amount:        10,
+		Currency:      "USD",
+		PaymentMethod: "pm_card_authenticationRequired",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "failed", response.Status)
+	assert.Equal(t, payments.FailureTypeInvalidCard, response.FailureType)
+}
+
+func TestStripeGateway_RefundPayment(t *testing.T) {
+	server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{"id": "re_123", "status": "succeeded", "amount": 500, "currency": "usd"})
+	})
+
+	response, err := newStripeGateway(server).RefundPayment(context.Background(), &payments.GatewayRefundRequest{
+		OriginalTransactionID: "pi_123",
+		Amount:                5,
+		Currency:              "USD",
+		Reason:                "items cancelled",
+		IdempotencyKey:        "refund-1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", response.Status)
+	assert.Equal(t, "re_123", response.RefundID)
+	assert.Equal(t, 5.0, response.Amount)
+	assert.Equal(t, "/v1/refunds", server.request.URL.Path)
+	assert.Equal(t, "pi_123", server.form.Get("payment_intent"))
+	assert.Equal(t, "500", server.form.Get("amount"))
+	assert.Empty(t, server.form.Get("reason"))
+	assert.Equal(t, "items cancelled", server.form.Get("metadata[reason]"))
+}
+
+func TestStripeGateway_GetPaymentStatus(t *testing.T) {
+	server := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"id":       "pi_123",
+			"status":   "requires_payment_method",
+			"amount":   1999,
+			"currency": "usd",
+			"created":  1718000000,
+			"last_payment_error": map[string]interface{}{
+				"type": "card_error", "code": "card_declined", "decline_code": "insufficient_funds", "message": "Insufficient funds",
+			},
+		})
+	})
+
+	status, err := newStripeGateway(server).GetPaymentStatus(context.Background(), "pi_123")
+
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodGet, server.request.Method)
+	assert.Equal(t, "/v1/payment_intents/pi_123", server.request.URL.Path)
+	assert.Equal(t, "failed", status.Status)
+	assert.Equal(t, payments.FailureTypeInsufficientFunds, status.FailureType)
+	assert.Equal(t, 19.99, status.Amount)
+	assert.Equal(t, "USD", status.Currency)
+}
+
+func TestStripeGateway_IsHealthy(t *testing.T) {
+	healthy := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, map[string]interface{}{"object": "balance"})
+	})
+	unauthorized := newStripeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": map[string]interface{}{"type": "authentication_error"}})
+	})
+
+	assert.True(t, newStripeGateway(healthy).IsHealthy(context.Background()))
+	assert.Equal(t, "/v1/balance", healthy.request.URL.Path)
+	assert.False(t, newStripeGateway(unauthorized).IsHealthy(context.Background()))
+}
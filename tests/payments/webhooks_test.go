@@ -0,0 +1,107 @@
+package payments_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/payments"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stripeSignature builds a Stripe-Signature header for body signed at signedAt
+func stripeSignature(secret string, signedAt time.Time, body []byte) http.Header {
+	timestamp := fmt.Sprintf("%d", signedAt.Unix())
+	signature := payments.SignWebhookPayload([]byte(secret), append([]byte(timestamp+"."), body...))
+
+	header := http.Header{}
+	header.Set("Stripe-Signature", "t="+timestamp+",v1="+hex.EncodeToString(signature))
+	return header
+}
+
+func TestHMACWebhookVerifier_VerifyWebhook(t *testing.T) {
+	verifier := payments.NewHMACWebhookVerifier("whsec_paypal")
+	body := []byte(`{"id":"evt_1","type":"payment.failed","data":{"transaction_id":"PP-1","failure_type":"insufficient_funds","failure_message":"declined"}}`)
+
+	header := http.Header{}
+	header.Set(payments.WebhookSignatureHeader, "sha256="+hex.EncodeToString(payments.SignWebhookPayload([]byte("whsec_paypal"), body)))
+	event, err := verifier.VerifyWebhook(header, body)
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, payments.WebhookEventPaymentFailed, event.Type)
+	assert.Equal(t, "PP-1", event.TransactionID)
+	assert.Equal(t, payments.FailureTypeInsufficientFunds, event.FailureType)
+	assert.Equal(t, "declined", event.FailureMessage)
+
+	// A body changed after signing no longer verifies
+	_, err = verifier.VerifyWebhook(header, append(body, ' '))
+	assert.ErrorIs(t, err, payments.ErrInvalidWebhookSignature)
+
+	// Neither does an unsigned callback
+	_, err = verifier.VerifyWebhook(http.Header{}, body)
+	assert.ErrorIs(t, err, payments.ErrInvalidWebhookSignature)
+}
+
+func TestStripeWebhookVerifier_PaymentIntentEvents(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	verifier := payments.NewStripeWebhookVerifierWithClock("whsec_stripe", payments.DefaultStripeWebhookTolerance, func() time.Time { return now })
+
+	succeeded := []byte(`{"id":"evt_s","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1","status":"succeeded"}}}`)
+	event, err := verifier.VerifyWebhook(stripeSignature("whsec_stripe", now.Add(-time.Minute), succeeded), succeeded)
+	require.NoError(t, err)
+	assert.Equal(t, payments.WebhookEventPaymentSucceeded, event.Type)
+	assert.Equal(t, "pi_1", event.TransactionID)
+
+	failed := []byte(`{"id":"evt_f","type":"payment_intent.payment_failed","data":{"object":{"id":"pi_2","status":"requires_payment_method",` +
+		`"last_payment_error":{"type":"card_error","code":"card_declined","decline_code":"insufficient_funds","message":"Your card has insufficient funds."}}}}`)
+	event, err = verifier.VerifyWebhook(stripeSignature("whsec_stripe", now, failed), failed)
+	require.NoError(t, err)
+	assert.Equal(t, payments.WebhookEventPaymentFailed, event.Type)
+	assert.Equal(t, "pi_2", event.TransactionID)
+	assert.Equal(t, payments.FailureTypeInsufficientFunds, event.FailureType)
+	assert.Equal(t, "Your card has insufficient funds.", event.FailureMessage)
+}
+
+func TestStripeWebhookVerifier_ChargeRefunded(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	verifier := payments.NewStripeWebhookVerifierWithClock("whsec_stripe", payments.DefaultStripeWebhookTolerance, func() time.Time { return now })
+
+	refunded := []byte(`{"id":"evt_r","type":"charge.refunded","data":{"object":{"id":"ch_1","payment_intent":"pi_3","refunded":true}}}`)
+	event, err := verifier.VerifyWebhook(stripeSignature("whsec_stripe", now, refunded), refunded)
+	require.NoError(t, err)
+	assert.Equal(t, payments.WebhookEventPaymentRefunded, event.Type)
+	assert.Equal(t, "pi_3", event.TransactionID)
+
+	// A partial refund keeps Stripe's event type, which the platform does not act on
+	partial := []byte(`{"id":"evt_p","type":"charge.refunded","data":{"object":{"id":"ch_2","payment_intent":"pi_4","refunded":false}}}`)
+	event, err = verifier.VerifyWebhook(stripeSignature("whsec_stripe", now, partial), partial)
+	require.NoError(t, err)
+	assert.Equal(t, "charge.refunded", event.Type)
+}
+
+func TestStripeWebhookVerifier_RejectsInvalidSignatures(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	verifier := payments.NewStripeWebhookVerifierWithClock("whsec_stripe", payments.DefaultStripeWebhookTolerance, func() time.Time { return now })
+	body := []byte(`{"id":"evt_s","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1"}}}`)
+
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{"wrong secret", stripeSignature("whsec_other", now, body)},
+		{"timestamp too old", stripeSignature("whsec_stripe", now.Add(-10*time.Minute), body)},
+		{"timestamp in the future", stripeSignature("whsec_stripe", now.Add(10*time.Minute), body)},
+		{"missing header", http.Header{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.VerifyWebhook(tt.header, body)
+			assert.ErrorIs(t, err, payments.ErrInvalidWebhookSignature)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package reports_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemoryCache(t *testing.T, maxSize int) reports.Cache {
+	cache := reports.NewMemoryCache(maxSize, time.Hour, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache
+}
+
+func cacheEntry(key string, expiresAt time.Time) *reports.ReportCache {
+	return &reports.ReportCache{
+		Key:          key,
+		ReportType:   reports.ReportTypeDailySales,
+		Data:         map[string]int{"orders": 3},
+		GeneratedAt:  time.Now(),
+		ExpiresAt:    expiresAt,
+		LastAccessed: time.Now(),
+	}
+}
+
+func TestMemoryCache_CountsHitsUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemoryCache(t, 10)
+
+	require.NoError(t, cache.Set(ctx, cacheEntry("fresh", time.Now().Add(time.Hour))))
+	require.NoError(t, cache.Set(ctx, cacheEntry("stale", time.Now().Add(-time.Second))))
+
+	first, err := cache.Get(ctx, "fresh")
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	second, err := cache.Get(ctx, "fresh")
+	require.NoError(t, err)
+	require.NotNil(t, second)
+	assert.Equal(t, 1, first.HitCount)
+	assert.Equal(t, 2, second.HitCount)
+	assert.Equal(t, map[string]int{"orders": 3}, second.Data)
+
+	stale, err := cache.Get(ctx, "stale")
+	require.NoError(t, err)
+	assert.Nil(t, stale)
+
+	missing, err := cache.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// The expired report was removed when it was looked up
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, reports.CacheStats{Entries: 1, TotalHits: 2}, stats)
+}
+
+func TestMemoryCache_EvictsLeastRecentlyAccessed(t *testing.T) {
+	ctx := context.Background()
+	cache := newMemoryCache(t, 2)
+	expiresAt := time.Now().Add(time.Hour)
+
+	require.NoError(t, cache.Set(ctx, cacheEntry("first", expiresAt)))
+	require.NoError(t, cache.Set(ctx, cacheEntry("second", expiresAt)))
+	_, err := cache.Get(ctx, "first")
+	require.NoError(t, err)
+	require.NoError(t, cache.Set(ctx, cacheEntry("third", expiresAt)))
+
+	for key, cached := range map[string]bool{"first": true, "second": false, "third": true} {
+		entry, err := cache.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, cached, entry != nil, key)
+	}
+
+	flushed, err := cache.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+	stats, err := cache.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+}
+
+func TestNewReportManager_CacheBackend(t *testing.T) {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+
+	// Without a Redis server to use, reports are cached in memory
+	config := reports.DefaultReportManagerConfig()
+	config.CacheBackend = reports.CacheBackendRedis
+	manager := reports.NewReportManager(config, nil, log)
+	defer manager.Close()
+
+	stats, err := manager.GetCacheStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, reports.CacheBackendMemory, stats["backend"])
+	assert.Equal(t, 0, stats["total_entries"])
+}
@@ -0,0 +1,118 @@
+package reports_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/pkg/storage"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// signingStorage wraps local storage with signed URLs, like S3
+type signingStorage struct {
+	*storage.LocalStorage
+}
+
+func (s signingStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return "https://files.example.com/" + key + "?expires=" + expiry.String(), nil
+}
+
+func newStoringReportManager(t *testing.T, fileStorage storage.Storage, signedURLThreshold int64) *reports.ReportManager {
+	orderRepo := new(mocks.MockOrderRepository)
+	orderRepo.On("AggregateSalesByDay", mock.Anything, mock.Anything, mock.Anything).Return([]repository.SalesDay{
+		{Day: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), Revenue: 300, Orders: 3, Customers: 2},
+	}, nil)
+	orderRepo.On("AggregateSalesByProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByPaymentMethod", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByCustomerType", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	config := reports.DefaultReportManagerConfig()
+	config.Storage = fileStorage
+	config.SignedURLThreshold = signedURLThreshold
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	manager := reports.NewReportManager(config, nil, log)
+	manager.RegisterGenerator(reports.NewSalesReportGenerator(orderRepo, nil, nil, nil, log))
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+func generatePDF(t *testing.T, manager *reports.ReportManager) *reports.ReportResult {
+	result, err := manager.GenerateReportSync(context.Background(), &reports.ReportRequest{
+		ID:         "daily-pdf",
+		Type:       reports.ReportTypeDailySales,
+		Format:     reports.ReportFormatPDF,
+		Parameters: map[string]interface{}{"date": "2025-06-10"},
+	})
+	require.NoError(t, err)
+	return result
+}
+
+// Test GenerateReportSync - With storage, the rendered file is stored instead of kept in the result
+func TestGenerateReportSync_StoresFile(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	manager := newStoringReportManager(t, local, 0)
+
+	result := generatePDF(t, manager)
+	assert.Empty(t, result.Content)
+	assert.Equal(t, "reports/"+result.ID+".pdf", result.FilePath)
+
+	file, err := manager.OpenFile(context.Background(), result)
+	require.NoError(t, err)
+	defer file.Content.Close()
+	content, err := io.ReadAll(file.Content)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("%PDF-1.4\n")))
+	assert.Equal(t, int64(len(content)), file.Size)
+	assert.Equal(t, "application/pdf", file.ContentType)
+	assert.Empty(t, file.URL)
+
+	// A cached report shares the stored file
+	cached := generatePDF(t, manager)
+	assert.Equal(t, true, cached.Metadata["cached"])
+	assert.Equal(t, result.FilePath, cached.FilePath)
+	assert.Equal(t, result.FileSize, cached.FileSize)
+}
+
+// Test OpenFile - Large files are downloaded from a signed URL when the storage signs them
+func TestOpenFile_SignedURL(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	large := newStoringReportManager(t, signingStorage{local}, 1)
+	result := generatePDF(t, large)
+	file, err := large.OpenFile(context.Background(), result)
+	require.NoError(t, err)
+	assert.Nil(t, file.Content)
+	assert.Equal(t, "https://files.example.com/"+result.FilePath+"?expires=15m0s", file.URL)
+
+	// Storage that cannot sign URLs serves the file itself
+	unsigned := newStoringReportManager(t, local, 1)
+	file, err = unsigned.OpenFile(context.Background(), generatePDF(t, unsigned))
+	require.NoError(t, err)
+	require.NotNil(t, file.Content)
+	file.Content.Close()
+}
+
+// Test OpenFile - Reports without a file, or whose file is gone, have nothing to open
+func TestOpenFile_NoFile(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	manager := newStoringReportManager(t, local, 0)
+
+	_, err = manager.OpenFile(context.Background(), &reports.ReportResult{Format: reports.ReportFormatJSON, Data: map[string]interface{}{}})
+	assert.ErrorIs(t, err, reports.ErrNoReportFile)
+
+	_, err = manager.OpenFile(context.Background(), &reports.ReportResult{Format: reports.ReportFormatPDF, FilePath: "reports/missing.pdf"})
+	assert.ErrorIs(t, err, reports.ErrNoReportFile)
+}
@@ -0,0 +1,82 @@
+package reports_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ReconciliationReportTestSuite covers payment reconciliation reports
+type ReconciliationReportTestSuite struct {
+	suite.Suite
+	generator          *reports.ReconciliationReportGenerator
+	reconciliationRepo *mocks.MockPaymentReconciliationRepository
+	ctx                context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ReconciliationReportTestSuite) SetupTest() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.reconciliationRepo = new(mocks.MockPaymentReconciliationRepository)
+	suite.generator = reports.NewReconciliationReportGenerator(suite.reconciliationRepo, log)
+	suite.ctx = context.Background()
+}
+
+// Test run report - Outcomes of the run are counted and listed
+func (suite *ReconciliationReportTestSuite) TestRunReport_CountsOutcomes() {
+	suite.reconciliationRepo.On("ListByRunID", suite.ctx, "run-1").Return([]*models.PaymentReconciliation{
+		{RunID: "run-1", PaymentID: "payment-1", Outcome: models.ReconciliationOutcomeMismatch, LocalStatus: models.PaymentStatusFailed, GatewayStatus: "completed"},
+		{RunID: "run-1", PaymentID: "payment-2", Outcome: models.ReconciliationOutcomeCorrected, LocalStatus: models.PaymentStatusProcessed, ResolvedStatus: models.PaymentStatusCompleted},
+		{RunID: "run-1", PaymentID: "payment-3", Outcome: models.ReconciliationOutcomeInSync, LocalStatus: models.PaymentStatusFailed},
+	}, nil)
+
+	result, err := suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:         "request-1",
+		Type:       reports.ReportTypePaymentReconciliation,
+		Format:     reports.ReportFormatJSON,
+		Parameters: map[string]interface{}{"run_id": "run-1"},
+		CreatedAt:  time.Now(),
+	})
+
+	suite.Require().NoError(err)
+	data, ok := result.Data.(*reports.PaymentReconciliationReportData)
+	suite.Require().True(ok)
+	suite.Equal("run-1", data.RunID)
+	suite.Equal(3, data.Checked)
+	suite.Equal(1, data.Mismatched)
+	suite.Equal(1, data.Corrected)
+	suite.Equal(1, data.InSync)
+	suite.Equal(3, result.RowCount)
+	suite.Equal("completed", data.Entries[0].GatewayStatus)
+}
+
+// Test period report - Dates are inclusive days in the report timezone
+func (suite *ReconciliationReportTestSuite) TestPeriodReport_UsesDates() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	suite.reconciliationRepo.On("ListBetween", suite.ctx, at(start), at(end)).Return([]*models.PaymentReconciliation{}, nil)
+
+	result, err := suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:         "request-1",
+		Type:       reports.ReportTypePaymentReconciliation,
+		Format:     reports.ReportFormatJSON,
+		Parameters: map[string]interface{}{"start_date": "2025-03-01", "end_date": "2025-03-07"},
+	})
+
+	suite.Require().NoError(err)
+	data := result.Data.(*reports.PaymentReconciliationReportData)
+	suite.Equal(0, data.Checked)
+	suite.NotNil(data.Entries)
+}
+
+// TestReconciliationReportTestSuite runs the test suite
+func TestReconciliationReportTestSuite(t *testing.T) {
+	suite.Run(t, new(ReconciliationReportTestSuite))
+}
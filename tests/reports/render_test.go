@@ -0,0 +1,112 @@
+package reports_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/documents"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newReportManager returns a report manager generating sales reports from orderRepo
+func newReportManager(orderRepo *mocks.MockOrderRepository) *reports.ReportManager {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	manager := reports.NewReportManager(nil, nil, log)
+	manager.RegisterGenerator(reports.NewSalesReportGenerator(orderRepo, nil, nil, nil, log))
+	return manager
+}
+
+// salesResult is a completed daily sales report of one product
+func salesResult(format reports.ReportFormat) *reports.ReportResult {
+	day := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	return &reports.ReportResult{
+		Type:   reports.ReportTypeDailySales,
+		Format: format,
+		Data: &reports.SalesReportData{
+			StartDate:    day,
+			EndDate:      day.AddDate(0, 0, 1),
+			Timezone:     "UTC",
+			TotalOrders:  2,
+			GrossRevenue: 150,
+			NetRevenue:   150,
+			ReturnRate:   0.125,
+			TopProducts: []reports.ProductSalesData{
+				{Rank: 1, ProductID: "p1", ProductName: "Widget (blue)", Quantity: 3, Revenue: 150, NetRevenue: 150, MarginPercent: 10, LowMargin: true},
+			},
+			LowMarginProducts: []string{"p1"},
+		},
+	}
+}
+
+// Test RenderReport - Sales reports are laid out with their summary and a section per breakdown
+func TestRenderReport_SalesPDF(t *testing.T) {
+	pdf, err := newReportManager(new(mocks.MockOrderRepository)).RenderReport(salesResult(reports.ReportFormatPDF))
+
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	assert.Contains(t, string(pdf), "(Daily Sales Report) Tj")
+	assert.Contains(t, string(pdf), "(Return rate: 12.5%) Tj")
+	assert.Contains(t, string(pdf), "(Sales by Day) Tj")
+	assert.Contains(t, string(pdf), "(Top Products) Tj")
+	assert.Contains(t, string(pdf), `Widget \(blue\) *`)
+	assert.Contains(t, string(pdf), "(* Margin below the low margin threshold) Tj")
+}
+
+// Test RenderReport - A registered layout replaces the built-in one for its report type
+func TestRenderReport_RegisteredLayout(t *testing.T) {
+	manager := newReportManager(new(mocks.MockOrderRepository))
+	manager.RegisterLayout(reports.ReportTypeDailySales, func(result *reports.ReportResult) (*documents.Document, error) {
+		return &documents.Document{Title: "Store Takings"}, nil
+	})
+
+	pdf, err := manager.RenderReport(salesResult(reports.ReportFormatPDF))
+
+	require.NoError(t, err)
+	assert.Contains(t, string(pdf), "(Store Takings) Tj")
+	assert.NotContains(t, string(pdf), "(Sales by Day) Tj")
+}
+
+// Test RenderReport - Only PDF is rendered, and data of another report type is rejected
+func TestRenderReport_Errors(t *testing.T) {
+	manager := newReportManager(new(mocks.MockOrderRepository))
+
+	_, err := manager.RenderReport(salesResult(reports.ReportFormatCSV))
+	assert.ErrorContains(t, err, "unsupported report format")
+
+	result := salesResult(reports.ReportFormatPDF)
+	result.Type = reports.ReportTypeLowStock
+	_, err = manager.RenderReport(result)
+	assert.ErrorContains(t, err, "unexpected")
+}
+
+// Test GenerateReportSync - PDF reports carry their rendered content
+func TestGenerateReportSync_PDF(t *testing.T) {
+	orderRepo := new(mocks.MockOrderRepository)
+	orderRepo.On("AggregateSalesByDay", mock.Anything, mock.Anything, mock.Anything).Return([]repository.SalesDay{
+		{Day: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), Revenue: 300, Orders: 3, Customers: 2},
+	}, nil)
+	orderRepo.On("AggregateSalesByProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByPaymentMethod", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByCustomerType", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	result, err := newReportManager(orderRepo).GenerateReportSync(context.Background(), &reports.ReportRequest{
+		ID:         "daily-pdf",
+		Type:       reports.ReportTypeDailySales,
+		Format:     reports.ReportFormatPDF,
+		Parameters: map[string]interface{}{"date": "2025-06-10"},
+	})
+
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(result.Content, []byte("%PDF-1.4\n")))
+	assert.Equal(t, int64(len(result.Content)), result.FileSize)
+	assert.Contains(t, string(result.Content), "(Orders: 3) Tj")
+}
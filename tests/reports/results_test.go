@@ -0,0 +1,164 @@
+package reports_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// withStatus matches a stored result moving to the status
+func withStatus(status models.ReportResultStatus) interface{} {
+	return mock.MatchedBy(func(result *models.ReportResult) bool { return result.Status == status })
+}
+
+// Test GenerateReportAsync - The result is stored pending, then moves to generating and completed
+func TestGenerateReportAsync_StoresStatusTransitions(t *testing.T) {
+	orderRepo := new(mocks.MockOrderRepository)
+	orderRepo.On("AggregateSalesByDay", mock.Anything, mock.Anything, mock.Anything).Return([]repository.SalesDay{
+		{Day: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC), Revenue: 300, Orders: 3, Customers: 2},
+	}, nil)
+	orderRepo.On("AggregateSalesByProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByPaymentMethod", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	orderRepo.On("AggregateSalesByCustomerType", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	results := new(mocks.MockReportResultRepository)
+	var queued, completed *models.ReportResult
+	done := make(chan struct{})
+	results.On("Create", mock.Anything, withStatus(models.ReportResultStatusPending)).
+		Run(func(args mock.Arguments) { queued = args.Get(1).(*models.ReportResult) }).
+		Return(nil).Once()
+	results.On("Transition", mock.Anything, withStatus(models.ReportResultStatusGenerating), []models.ReportResultStatus{models.ReportResultStatusPending}).
+		Return(true, nil).Once()
+	results.On("Transition", mock.Anything, withStatus(models.ReportResultStatusCompleted), []models.ReportResultStatus{models.ReportResultStatusGenerating}).
+		Run(func(args mock.Arguments) {
+			completed = args.Get(1).(*models.ReportResult)
+			close(done)
+		}).
+		Return(true, nil).Once()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	manager := reports.NewReportManager(nil, results, log)
+	manager.RegisterGenerator(reports.NewSalesReportGenerator(orderRepo, nil, nil, nil, log))
+
+	result, err := manager.GenerateReportAsync(context.Background(), &reports.ReportRequest{
+		ID:         "daily-async",
+		Type:       reports.ReportTypeDailySales,
+		Format:     reports.ReportFormatPDF,
+		Parameters: map[string]interface{}{"date": "2025-06-10"},
+		UserID:     "admin-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, reports.ReportStatusPending, result.Status)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("report was not completed")
+	}
+
+	require.NotNil(t, queued)
+	assert.Equal(t, result.ID, queued.ID)
+	assert.Equal(t, "daily_sales", queued.Type)
+	assert.Equal(t, "pdf", queued.Format)
+	assert.Equal(t, "admin-1", queued.RequestedBy)
+	assert.JSONEq(t, `{"date": "2025-06-10"}`, string(queued.Parameters))
+
+	assert.Equal(t, result.ID, completed.ID)
+	assert.NotEmpty(t, completed.Content)
+	assert.Equal(t, int64(len(completed.Content)), completed.FileSize)
+	assert.NotNil(t, completed.GeneratedAt)
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(completed.Data, &data))
+	assert.Equal(t, 3.0, data["total_orders"])
+	results.AssertExpectations(t)
+}
+
+// Test GenerateReportAsync - A report that could not be stored is not queued
+func TestGenerateReportAsync_StoreError(t *testing.T) {
+	results := new(mocks.MockReportResultRepository)
+	results.On("Create", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	_, err := reports.NewReportManager(nil, results, log).GenerateReportAsync(context.Background(), &reports.ReportRequest{
+		ID:   "daily-async",
+		Type: reports.ReportTypeDailySales,
+	})
+
+	assert.ErrorContains(t, err, "failed to store report result")
+}
+
+// Test OnCompletion - The completion handler is told about a failed async report with
+// the request it answers
+func TestGenerateReportAsync_NotifiesCompletion(t *testing.T) {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	manager := reports.NewReportManager(nil, nil, log)
+
+	finished := make(chan *reports.ReportResult, 1)
+	var requestedBy string
+	manager.OnCompletion(func(req *reports.ReportRequest, result *reports.ReportResult) {
+		requestedBy = req.UserID
+		finished <- result
+	})
+
+	// No generator is registered, so the report fails
+	queued, err := manager.GenerateReportAsync(context.Background(), &reports.ReportRequest{
+		ID:     "daily-async",
+		Type:   reports.ReportTypeDailySales,
+		UserID: "admin-1",
+	})
+	require.NoError(t, err)
+
+	select {
+	case result := <-finished:
+		assert.Equal(t, queued.ID, result.ID)
+		assert.Equal(t, reports.ReportStatusFailed, result.Status)
+		assert.Contains(t, result.Error, "no generator found")
+		assert.Equal(t, "admin-1", requestedBy)
+	case <-time.After(5 * time.Second):
+		t.Fatal("report completion was not notified")
+	}
+}
+
+// Test GetResult - Stored results are returned with their data as JSON
+func TestGetResult(t *testing.T) {
+	generatedAt := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+	results := new(mocks.MockReportResultRepository)
+	results.On("GetByID", mock.Anything, "result-1").Return(&models.ReportResult{
+		ID:           "result-1",
+		Type:         "daily_sales",
+		Format:       "json",
+		Status:       models.ReportResultStatusCompleted,
+		Data:         json.RawMessage(`{"total_orders":3}`),
+		ProcessingMs: 1500,
+		GeneratedAt:  &generatedAt,
+	}, nil)
+	results.On("GetByID", mock.Anything, "missing").Return(nil, nil)
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	manager := reports.NewReportManager(nil, results, log)
+
+	result, err := manager.GetResult(context.Background(), "result-1")
+	require.NoError(t, err)
+	assert.Equal(t, reports.ReportStatusCompleted, result.Status)
+	assert.Equal(t, reports.ReportTypeDailySales, result.Type)
+	assert.Equal(t, 1500*time.Millisecond, result.ProcessingTime)
+	assert.Equal(t, json.RawMessage(`{"total_orders":3}`), result.Data)
+
+	result, err = manager.GetResult(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	_, err = reports.NewReportManager(nil, nil, log).GetResult(context.Background(), "result-1")
+	assert.ErrorIs(t, err, reports.ErrResultsNotStored)
+}
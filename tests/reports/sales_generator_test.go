@@ -0,0 +1,333 @@
+package reports_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// SalesReportLocalizationTestSuite covers sales report aggregation and the timezone
+// and locale handling of sales reports
+type SalesReportLocalizationTestSuite struct {
+	suite.Suite
+	generator *reports.SalesReportGenerator
+	orderRepo *mocks.MockOrderRepository
+	ctx       context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *SalesReportLocalizationTestSuite) SetupTest() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.generator = reports.NewSalesReportGenerator(suite.orderRepo, nil, nil, nil, log)
+	suite.ctx = context.Background()
+}
+
+// at matches a time argument equal to t, whatever its location
+func at(t time.Time) interface{} {
+	return mock.MatchedBy(func(arg time.Time) bool { return arg.Equal(t) })
+}
+
+// noSales stubs every aggregation not stubbed yet with an empty result
+func (suite *SalesReportLocalizationTestSuite) noSales() {
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	suite.orderRepo.On("AggregateSalesByProduct", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	suite.orderRepo.On("AggregateSalesByPaymentMethod", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	suite.orderRepo.On("AggregateSalesByCustomerType", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+}
+
+// generate runs a sales report and returns its data
+func (suite *SalesReportLocalizationTestSuite) generate(req *reports.ReportRequest) *reports.SalesReportData {
+	suite.noSales()
+	result, err := suite.generator.GenerateReport(suite.ctx, req)
+	suite.Require().NoError(err)
+
+	data, ok := result.Data.(*reports.SalesReportData)
+	suite.Require().True(ok)
+	return data
+}
+
+// Test daily report - Spring-forward day is 23 hours long in the requester's timezone
+func (suite *SalesReportLocalizationTestSuite) TestDailyReport_SpringForward() {
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "daily-dst-start",
+		Type:       reports.ReportTypeDailySales,
+		Parameters: map[string]interface{}{"date": "2025-03-09"},
+		Timezone:   "America/New_York",
+	})
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	suite.True(data.StartDate.Equal(time.Date(2025, 3, 9, 0, 0, 0, 0, newYork)))
+	suite.True(data.EndDate.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, newYork)))
+	suite.Equal(23*time.Hour, data.EndDate.Sub(data.StartDate))
+	suite.Equal(time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC), data.StartDate.UTC())
+	suite.Equal("America/New_York", data.Timezone)
+}
+
+// Test daily report - Fall-back day is 25 hours long in the requester's timezone
+func (suite *SalesReportLocalizationTestSuite) TestDailyReport_FallBack() {
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "daily-dst-end",
+		Type:       reports.ReportTypeDailySales,
+		Parameters: map[string]interface{}{"date": "2025-11-02"},
+		Timezone:   "America/New_York",
+	})
+
+	suite.Equal(25*time.Hour, data.EndDate.Sub(data.StartDate))
+	suite.Equal(time.Date(2025, 11, 2, 4, 0, 0, 0, time.UTC), data.StartDate.UTC())
+	suite.Equal(time.Date(2025, 11, 3, 5, 0, 0, 0, time.UTC), data.EndDate.UTC())
+}
+
+// Test weekly report - Every day in a week spanning DST starts at local midnight
+func (suite *SalesReportLocalizationTestSuite) TestWeeklyReport_DaysStartAtLocalMidnightAcrossDST() {
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "weekly-dst",
+		Type:       reports.ReportTypeWeeklySales,
+		Parameters: map[string]interface{}{"week_start": "2025-03-27"},
+		Timezone:   "Europe/Berlin",
+		Locale:     "de-DE",
+	})
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	suite.Require().Len(data.SalesByDay, 7)
+	for i, day := range data.SalesByDay {
+		suite.True(day.Date.Equal(time.Date(2025, 3, 27+i, 0, 0, 0, 0, berlin)), "day %d", i)
+	}
+	suite.Equal(7*24*time.Hour-time.Hour, data.EndDate.Sub(data.StartDate))
+	suite.Equal("Weekly - 27.03.2025 to 02.04.2025", data.Period)
+	suite.Equal("30.03.2025", data.SalesByDay[3].Label)
+}
+
+// Test monthly report - A month with a DST transition keeps every calendar day
+func (suite *SalesReportLocalizationTestSuite) TestMonthlyReport_IncludesEveryDayAcrossDST() {
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "monthly-dst",
+		Type:       reports.ReportTypeMonthlySales,
+		Parameters: map[string]interface{}{"year": float64(2025), "month": float64(3)},
+		Timezone:   "Europe/Berlin",
+	})
+
+	suite.Len(data.SalesByDay, 31)
+	suite.Equal(31, data.SalesByDay[30].Date.Day())
+	suite.Equal("Monthly - March 2025", data.Period)
+	suite.Equal("03/31/2025", data.Display["end_date"])
+}
+
+// Test report defaults - Requests without timezone or locale use UTC and en-US
+func (suite *SalesReportLocalizationTestSuite) TestDailyReport_Defaults() {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, at(day), at(day.AddDate(0, 0, 1))).
+		Return([]repository.SalesDay{{Day: day, Revenue: 15750.50, Orders: 45, Customers: 38}}, nil)
+
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "daily-default",
+		Type:       reports.ReportTypeDailySales,
+		Parameters: map[string]interface{}{"date": "2025-01-15"},
+	})
+
+	suite.Equal(time.UTC, data.StartDate.Location())
+	suite.Equal("UTC", data.Timezone)
+	suite.Equal("en-US", data.Locale)
+	suite.Equal("Daily - 01/15/2025", data.Period)
+	suite.Equal("$15,750.50", data.Display["total_revenue"])
+}
+
+// Test daily report - Totals, refunds, products, payment methods and customers come from the orders
+func (suite *SalesReportLocalizationTestSuite) TestDailyReport_AggregatesSales() {
+	day := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	next, previous := day.AddDate(0, 0, 1), day.AddDate(0, 0, -1)
+	category := "cat-widgets"
+
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, at(day), at(next)).Return([]repository.SalesDay{
+		{Day: day, Revenue: 1000, RefundedAmount: 100, Orders: 8, Customers: 5, ReturnedOrders: 1},
+	}, nil)
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, at(previous), at(day)).Return([]repository.SalesDay{
+		{Day: previous, Revenue: 800, Orders: 6, Customers: 6},
+	}, nil)
+	suite.orderRepo.On("AggregateSalesByProduct", mock.Anything, at(day), at(next)).Return([]repository.ProductSales{
+		{ProductID: "p1", ProductName: "Widget", SKU: "W-1", CategoryID: &category, UnitCost: 30, Quantity: 10, Revenue: 600, RefundedAmount: 100, ReturnedQuantity: 1.6, Orders: 6},
+		{ProductID: "p2", ProductName: "Gadget", SKU: "G-1", UnitCost: 45, Quantity: 8, Revenue: 400, Orders: 3},
+	}, nil)
+	suite.orderRepo.On("AggregateSalesByPaymentMethod", mock.Anything, at(day), at(next)).Return([]repository.PaymentMethodSales{
+		{Method: models.PaymentMethodCreditCard, Orders: 6, Revenue: 750},
+		{Method: "", Orders: 2, Revenue: 250},
+	}, nil)
+	suite.orderRepo.On("AggregateSalesByCustomerType", mock.Anything, at(day), at(next)).Return([]repository.CustomerTypeSales{
+		{New: true, Customers: 2, Orders: 2, Revenue: 200},
+		{New: false, Customers: 3, Orders: 6, Revenue: 800},
+	}, nil)
+
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "daily-aggregates",
+		Type:       reports.ReportTypeDailySales,
+		Parameters: map[string]interface{}{"date": "2025-06-10"},
+	})
+
+	suite.Equal(1000.0, data.TotalRevenue)
+	suite.Equal(8, data.TotalOrders)
+	suite.Equal(125.0, data.AverageOrderValue)
+	suite.Equal(100.0, data.RefundedAmount)
+	suite.Equal(900.0, data.NetRevenue)
+	suite.Equal(1, data.ReturnedOrders)
+	suite.InDelta(0.125, data.ReturnRate, 0.0001)
+	suite.Require().Len(data.SalesByDay, 1)
+	suite.Equal(5, data.SalesByDay[0].CustomerCount)
+	suite.Equal(900.0, data.SalesByDay[0].NetRevenue)
+
+	// Kept units carry their cost: 8 widgets at 30 and 8 gadgets at 45
+	suite.Require().Len(data.TopProducts, 2)
+	widget := data.TopProducts[0]
+	suite.Equal(1, widget.Rank)
+	suite.Equal(2, widget.ReturnedQuantity)
+	suite.Equal(500.0, widget.NetRevenue)
+	suite.Equal(60.0, widget.AvgPrice)
+	suite.Equal(240.0, widget.CostOfGoods)
+	suite.Equal(600.0, data.CostOfGoods)
+	suite.Equal(300.0, data.GrossMargin)
+	suite.Equal([]string{"p2"}, data.LowMarginProducts)
+
+	suite.Require().Len(data.PaymentMethods, 2)
+	suite.Equal("credit_card", data.PaymentMethods[0].Method)
+	suite.Equal(75.0, data.PaymentMethods[0].Percentage)
+	suite.Equal("unspecified", data.PaymentMethods[1].Method)
+
+	suite.Require().Len(data.CustomerSegments, 2)
+	suite.Equal("new", data.CustomerSegments[0].Segment)
+	suite.Equal("returning", data.CustomerSegments[1].Segment)
+	suite.Equal(80.0, data.CustomerSegments[1].Percentage)
+
+	suite.Equal("Widget", data.Summary["best_selling_product"])
+	suite.InDelta(0.6, data.Summary["repeat_customer_rate"], 0.0001)
+	suite.InDelta(0.25, data.Summary["growth_rate"], 0.0001)
+}
+
+// Test monthly report - Days without sales are reported as zero, and the best weekday is summed across weeks
+func (suite *SalesReportLocalizationTestSuite) TestMonthlyReport_FillsDaysWithoutSales() {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, berlin)
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, at(start), at(start.AddDate(0, 1, 0))).Return([]repository.SalesDay{
+		{Day: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), Revenue: 100, Orders: 1, Customers: 1},  // Monday
+		{Day: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), Revenue: 100, Orders: 1, Customers: 1}, // Monday
+		{Day: time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC), Revenue: 150, Orders: 2, Customers: 2}, // Sunday, DST starts
+	}, nil)
+
+	data := suite.generate(&reports.ReportRequest{
+		ID:         "monthly-sparse",
+		Type:       reports.ReportTypeMonthlySales,
+		Parameters: map[string]interface{}{"year": float64(2025), "month": float64(3)},
+		Timezone:   "Europe/Berlin",
+	})
+
+	suite.Require().Len(data.SalesByDay, 31)
+	suite.Equal(100.0, data.SalesByDay[2].Revenue)
+	suite.Equal(0.0, data.SalesByDay[3].Revenue)
+	suite.Equal(0, data.SalesByDay[3].OrderCount)
+	suite.Equal(150.0, data.SalesByDay[29].Revenue)
+	suite.Equal(350.0, data.TotalRevenue)
+	suite.Equal(4, data.TotalOrders)
+	suite.Equal("Monday", data.Summary["best_day_of_week"])
+	suite.NotContains(data.Summary, "growth_rate")
+}
+
+// Test top products report - Products are limited and categories cover every product sold
+func (suite *SalesReportLocalizationTestSuite) TestTopProductsReport() {
+	category := "cat-widgets"
+	suite.orderRepo.On("AggregateSalesByProduct", mock.Anything, mock.Anything, mock.Anything).Return([]repository.ProductSales{
+		{ProductID: "p1", ProductName: "Widget", CategoryID: &category, UnitCost: 30, Quantity: 10, Revenue: 600, Orders: 6},
+		{ProductID: "p2", ProductName: "Gadget", UnitCost: 45, Quantity: 8, Revenue: 400, RefundedAmount: 50, ReturnedQuantity: 1, Orders: 3},
+		{ProductID: "p3", ProductName: "Widget Mini", CategoryID: &category, UnitCost: 5, Quantity: 20, Revenue: 200, Orders: 4},
+	}, nil)
+
+	result, err := suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:         "top-products",
+		Type:       reports.ReportTypeTopProducts,
+		Parameters: map[string]interface{}{"limit": float64(2), "period": "last_7_days"},
+	})
+	suite.Require().NoError(err)
+	data, ok := result.Data.(*reports.TopProductsReportData)
+	suite.Require().True(ok)
+
+	suite.Require().Len(data.TopProducts, 2)
+	suite.Equal("Gadget", data.Summary["highest_return_rate"])
+	suite.Equal(3, data.Summary["total_products_analyzed"])
+	suite.Equal("$50.00 - $60.00", data.Summary["avg_price_range"])
+
+	suite.Require().Len(data.Categories, 2)
+	suite.Equal("cat-widgets", data.Categories[0].CategoryName)
+	suite.Equal(2, data.Categories[0].ProductCount)
+	suite.Equal(800.0, data.Categories[0].Revenue)
+	suite.Equal(400.0, data.Categories[0].CostOfGoods)
+	suite.Equal("uncategorized", data.Categories[1].CategoryName)
+	suite.Equal("cat-widgets", data.Summary["top_category"])
+}
+
+// Test sales report - Aggregation errors fail the report
+func (suite *SalesReportLocalizationTestSuite) TestDailyReport_AggregationError() {
+	suite.orderRepo.On("AggregateSalesByDay", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection refused"))
+
+	_, err := suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:   "daily-error",
+		Type: reports.ReportTypeDailySales,
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "connection refused")
+}
+
+// Test report request - Unknown timezones and locales are rejected
+func (suite *SalesReportLocalizationTestSuite) TestGenerateReport_InvalidTimezoneOrLocale() {
+	_, err := suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:       "bad-timezone",
+		Type:     reports.ReportTypeDailySales,
+		Timezone: "Mars/Olympus_Mons",
+	})
+	suite.Error(err)
+	suite.Contains(err.Error(), "unsupported timezone")
+
+	_, err = suite.generator.GenerateReport(suite.ctx, &reports.ReportRequest{
+		ID:     "bad-locale",
+		Type:   reports.ReportTypeDailySales,
+		Locale: "xx-XX",
+	})
+	suite.Error(err)
+	suite.Contains(err.Error(), "unsupported locale")
+}
+
+// Test currency formatting - Separators and symbol placement follow the locale
+func (suite *SalesReportLocalizationTestSuite) TestFormatCurrency() {
+	cases := []struct {
+		locale   string
+		amount   float64
+		currency string
+		expected string
+	}{
+		{"en-US", 1234567.891, "USD", "$1,234,567.89"},
+		{"en-GB", 0.5, "GBP", "£0.50"},
+		{"de-DE", 1234.5, "EUR", "1.234,50 €"},
+		{"fr-FR", -987654.32, "EUR", "-987 654,32 €"},
+		{"ja-JP", 150000, "JPY", "¥150,000"},
+		{"en-US", 12, "CHF", "CHF12.00"},
+	}
+
+	for _, tc := range cases {
+		localizer, err := reports.NewLocalizer("UTC", tc.locale)
+		suite.Require().NoError(err)
+		suite.Equal(tc.expected, localizer.FormatCurrency(tc.amount, tc.currency), tc.locale)
+	}
+}
+
+// Run the test suite
+func TestSalesReportLocalizationTestSuite(t *testing.T) {
+	suite.Run(t, new(SalesReportLocalizationTestSuite))
+}
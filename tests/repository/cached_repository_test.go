@@ -0,0 +1,179 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// CachedRepositoryTestSuite defines the test suite for the cached repositories
+type CachedRepositoryTestSuite struct {
+	suite.Suite
+	productRepo   *mocks.MockProductRepository
+	inventoryRepo *mocks.MockInventoryRepository
+	cachedProduct repository.ProductRepository
+	cachedStock   repository.InventoryRepository
+	queryCache    *cache.QueryCache
+	now           time.Time
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *CachedRepositoryTestSuite) SetupTest() {
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.inventoryRepo = new(mocks.MockInventoryRepository)
+	suite.now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.ctx = context.Background()
+
+	suite.queryCache = cache.NewQueryCache(time.Minute, 100)
+	suite.queryCache.SetClock(func() time.Time { return suite.now })
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.cachedProduct = repository.NewCachedProductRepository(suite.productRepo, suite.queryCache, log)
+	suite.cachedStock = repository.NewCachedInventoryRepository(suite.inventoryRepo, suite.queryCache, log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *CachedRepositoryTestSuite) TearDownTest() {
+	suite.productRepo.AssertExpectations(suite.T())
+	suite.inventoryRepo.AssertExpectations(suite.T())
+}
+
+// Test GetByID - Second read is served from the cache
+func (suite *CachedRepositoryTestSuite) TestProductGetByID_CachesResult() {
+	product := &models.Product{ID: "product-1", Name: "Widget", Price: 10}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil).Once()
+
+	// Execute
+	first, err := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	assert.NoError(suite.T(), err)
+	second, err := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), first.Name, second.Name)
+
+	stats := suite.queryCache.Stats()[repository.ProductCacheNamespace]
+	assert.Equal(suite.T(), int64(1), stats.Hits)
+	assert.Equal(suite.T(), int64(1), stats.Misses)
+	assert.Equal(suite.T(), 0.5, stats.HitRate)
+}
+
+// Test GetByID - Callers can't mutate the cached product
+func (suite *CachedRepositoryTestSuite) TestProductGetByID_ReturnsCopies() {
+	product := &models.Product{ID: "product-1", Name: "Widget", Inventory: &models.Inventory{Available: 5}}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil).Once()
+
+	// Execute
+	first, _ := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	first.Name = "Changed"
+	first.Inventory.Available = 0
+	second, err := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Widget", second.Name)
+	assert.Equal(suite.T(), 5, second.Inventory.Available)
+}
+
+// Test GetByID - Missing products aren't cached
+func (suite *CachedRepositoryTestSuite) TestProductGetByID_NotFoundIsNotCached() {
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil).Twice()
+
+	// Execute
+	first, _ := suite.cachedProduct.GetByID(suite.ctx, "missing")
+	second, err := suite.cachedProduct.GetByID(suite.ctx, "missing")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), first)
+	assert.Nil(suite.T(), second)
+}
+
+// Test GetByID - Entries expire after the TTL
+func (suite *CachedRepositoryTestSuite) TestProductGetByID_ExpiresAfterTTL() {
+	product := &models.Product{ID: "product-1", Name: "Widget"}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil).Twice()
+
+	// Execute
+	_, _ = suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	suite.now = suite.now.Add(2 * time.Minute)
+	_, err := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), suite.queryCache.Stats()[repository.ProductCacheNamespace].Misses)
+}
+
+// Test Update - Writes invalidate the product and active product pages
+func (suite *CachedRepositoryTestSuite) TestProductUpdate_InvalidatesCache() {
+	product := &models.Product{ID: "product-1", Name: "Widget", IsActive: true}
+	updated := &models.Product{ID: "product-1", Name: "Widget v2", IsActive: true}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil).Once()
+	suite.productRepo.On("GetActive", suite.ctx, 0, 10).Return([]*models.Product{product}, nil).Once()
+	suite.productRepo.On("Update", suite.ctx, updated).Return(nil).Once()
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(updated, nil).Once()
+	suite.productRepo.On("GetActive", suite.ctx, 0, 10).Return([]*models.Product{updated}, nil).Once()
+
+	// Execute
+	_, _ = suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	_, _ = suite.cachedProduct.GetActive(suite.ctx, 0, 10)
+	err := suite.cachedProduct.Update(suite.ctx, updated)
+	assert.NoError(suite.T(), err)
+	byID, _ := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	active, _ := suite.cachedProduct.GetActive(suite.ctx, 0, 10)
+
+	// Assert
+	assert.Equal(suite.T(), "Widget v2", byID.Name)
+	assert.Equal(suite.T(), "Widget v2", active[0].Name)
+}
+
+// Test ReserveStock - Stock changes invalidate inventory and product entries
+func (suite *CachedRepositoryTestSuite) TestInventoryReserveStock_InvalidatesCache() {
+	inventory := &models.Inventory{ProductID: "product-1", Quantity: 10, Available: 10}
+	reserved := &models.Inventory{ProductID: "product-1", Quantity: 10, Reserved: 2, Available: 8}
+	product := &models.Product{ID: "product-1", Inventory: inventory}
+
+	// Mock expectations
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil).Once()
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil).Once()
+	suite.inventoryRepo.On("ReserveStock", suite.ctx, "product-1", 2).Return(nil).Once()
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(reserved, nil).Once()
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(&models.Product{ID: "product-1", Inventory: reserved}, nil).Once()
+
+	// Execute
+	_, _ = suite.cachedStock.GetByProductID(suite.ctx, "product-1")
+	_, _ = suite.cachedProduct.GetByID(suite.ctx, "product-1")
+	err := suite.cachedStock.ReserveStock(suite.ctx, "product-1", 2)
+	assert.NoError(suite.T(), err)
+	stock, _ := suite.cachedStock.GetByProductID(suite.ctx, "product-1")
+	cachedProduct, _ := suite.cachedProduct.GetByID(suite.ctx, "product-1")
+
+	// Assert
+	assert.Equal(suite.T(), 8, stock.Available)
+	assert.Equal(suite.T(), 8, cachedProduct.Inventory.Available)
+	assert.Equal(suite.T(), int64(1), suite.queryCache.Stats()[repository.InventoryCacheNamespace].Invalidations)
+}
+
+// TestCachedRepositoryTestSuite runs the test suite
+func TestCachedRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(CachedRepositoryTestSuite))
+}
@@ -0,0 +1,479 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// AdminOrderServiceTestSuite defines the test suite for AdminOrderService
+type AdminOrderServiceTestSuite struct {
+	suite.Suite
+	adminOrderService services.AdminOrderService
+	orderRepo         *mocks.MockOrderRepository
+	orderItemRepo     *mocks.MockOrderItemRepository
+	paymentRepo       *mocks.MockPaymentRepository
+	notificationRepo  *mocks.MockNotificationRepository
+	auditRepo         *mocks.MockAuditLogRepository
+	userRepo          *mocks.MockUserRepository
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *AdminOrderServiceTestSuite) SetupTest() {
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.orderItemRepo = new(mocks.MockOrderItemRepository)
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.auditRepo = new(mocks.MockAuditLogRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.ctx = context.Background()
+
+	suite.adminOrderService = services.NewAdminOrderService(
+		services.PriceOverrideSettings{MaxDiscountPercent: 20},
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.paymentRepo,
+		suite.notificationRepo,
+		suite.auditRepo,
+		suite.userRepo,
+		nil, // No risk scoring
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *AdminOrderServiceTestSuite) TearDownTest() {
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.orderItemRepo.AssertExpectations(suite.T())
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test GetOrderFullView - Assembles every related record
+func (suite *AdminOrderServiceTestSuite) TestGetOrderFullView_Success() {
+	created := time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusDelivered, TotalAmount: 60, Currency: "USD", CreatedAt: created}
+	user := &models.User{ID: "user-1", Email: "buyer@example.com", Name: "Buyer", Role: models.UserRoleCustomer, IsActive: true}
+	items := []*models.OrderItem{{
+		ID: "item-1", ProductID: "product-1", Quantity: 2, UnitPrice: 30, TotalPrice: 60,
+		Product: &models.Product{ID: "product-1", Name: "Widget", SKU: "W-1", Price: 35, CostPrice: 12, IsActive: true, Inventory: &models.Inventory{Available: 4}},
+	}}
+	payments := []*models.Payment{
+		{ID: "payment-1", Amount: 60, Status: models.PaymentStatusFailed, AttemptCount: 3, MaxRetries: 3, FailureReason: "card declined"},
+		{ID: "payment-2", Amount: 60, Status: models.PaymentStatusRefunded, AttemptCount: 1, UpdatedAt: created.Add(48 * time.Hour)},
+	}
+	notifications := []*models.Notification{{ID: "notification-1", UserID: "user-1", Type: models.NotificationTypeOrderShipped, Data: `{"order_id":"order-1"}`}}
+	auditLogs := []*models.AuditLog{
+		{Action: models.AuditActionUpdate, NewValues: `{"status":"delivered"}`, CreatedAt: created.Add(72 * time.Hour)},
+		{Action: models.AuditActionCreate, NewValues: `{"status":"pending"}`, CreatedAt: created},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+	suite.orderItemRepo.On("GetByOrderID", suite.ctx, "order-1").Return(items, nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return(payments, nil)
+	suite.notificationRepo.On("GetByOrderID", suite.ctx, "order-1").Return(notifications, nil)
+	suite.auditRepo.On("GetByEntityID", suite.ctx, "order", "order-1", 0, 200).Return(auditLogs, nil)
+
+	// Execute
+	view, err := suite.adminOrderService.GetOrderFullView(suite.ctx, "order-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "order-1", view.Order.ID)
+	assert.Equal(suite.T(), "buyer@example.com", view.Customer.Email)
+
+	assert.Len(suite.T(), view.Items, 1)
+	assert.Equal(suite.T(), 30.0, view.Items[0].UnitPrice)
+	assert.Equal(suite.T(), 35.0, view.Items[0].Product.CurrentPrice)
+	assert.Equal(suite.T(), 4, *view.Items[0].Product.Available)
+
+	assert.Len(suite.T(), view.Payments, 2)
+	assert.Equal(suite.T(), 3, view.Payments[0].AttemptCount)
+	assert.Equal(suite.T(), "card declined", view.Payments[0].FailureReason)
+	assert.Len(suite.T(), view.Refunds, 1)
+	assert.Equal(suite.T(), "payment-2", view.Refunds[0].PaymentID)
+	assert.Equal(suite.T(), 60.0, view.RefundedAmount)
+
+	assert.Len(suite.T(), view.Notifications, 1)
+	assert.Equal(suite.T(), "order_shipped", view.Notifications[0].Type)
+
+	// History is returned oldest first
+	assert.Len(suite.T(), view.StatusHistory, 2)
+	assert.Equal(suite.T(), models.AuditActionCreate, view.StatusHistory[0].Action)
+	assert.JSONEq(suite.T(), `{"status":"delivered"}`, string(view.StatusHistory[1].NewValues))
+}
+
+// Test GetOrderFullView - Unknown order
+func (suite *AdminOrderServiceTestSuite) TestGetOrderFullView_NotFound() {
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+
+	// Execute
+	view, err := suite.adminOrderService.GetOrderFullView(suite.ctx, "missing")
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), view)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+// Test GetOrderFullView - A failing lookup fails the whole view
+func (suite *AdminOrderServiceTestSuite) TestGetOrderFullView_LookupError() {
+	order := &models.Order{ID: "order-1", UserID: "user-1"}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.orderItemRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.OrderItem{}, nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return(nil, errors.New("database error"))
+	suite.notificationRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Notification{}, nil)
+	suite.auditRepo.On("GetByEntityID", suite.ctx, "order", "order-1", 0, 200).Return([]*models.AuditLog{}, nil)
+
+	// Execute
+	view, err := suite.adminOrderService.GetOrderFullView(suite.ctx, "order-1")
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), view)
+	assert.Equal(suite.T(), "database error", err.Error())
+}
+
+// pendingOrderWithItem returns a pending order with one item listed at 50
+func pendingOrderWithItem() *models.Order {
+	return &models.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Status:      models.OrderStatusPending,
+		TotalAmount: 100,
+		Items: []models.OrderItem{
+			{ID: "item-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2, UnitPrice: 50, TotalPrice: 100, ListPrice: 50},
+		},
+	}
+}
+
+// Test OverrideItemPrice - A discount within the policy reprices the item and order
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_Success() {
+	overriddenAt := time.Now()
+	adminID := "admin-1"
+	repriced := pendingOrderWithItem()
+	repriced.TotalAmount = 90
+	repriced.Items[0].UnitPrice = 45
+	repriced.Items[0].TotalPrice = 90
+	repriced.Items[0].PriceOverrideReason = models.PriceOverrideReasonNegotiated
+	repriced.Items[0].PriceOverriddenBy = &adminID
+	repriced.Items[0].PriceOverriddenAt = &overriddenAt
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(pendingOrderWithItem(), nil)
+	suite.orderRepo.On("OverrideItemPrice", suite.ctx, "order-1", "item-1", repository.ItemPriceOverride{
+		UnitPrice:    45,
+		Reason:       models.PriceOverrideReasonNegotiated,
+		Note:         "annual contract",
+		OverriddenBy: adminID,
+	}).Return(repriced, nil)
+
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", adminID, services.OverrideItemPriceRequest{
+		UnitPrice:  45,
+		ReasonCode: "negotiated",
+		Note:       "annual contract",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 90.0, response.Total)
+	assert.Equal(suite.T(), 45.0, response.Item.UnitPrice)
+	assert.Equal(suite.T(), 50.0, response.Item.ListPrice)
+	assert.Equal(suite.T(), 10.0, response.Item.DiscountPercent)
+	suite.Require().NotNil(response.Item.PriceOverride)
+	assert.Equal(suite.T(), "negotiated", response.Item.PriceOverride.ReasonCode)
+	assert.Equal(suite.T(), &adminID, response.Item.PriceOverride.OverriddenBy)
+}
+
+// Test OverrideItemPrice - Discounts over the maximum are refused
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_ExceedsMaxDiscount() {
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(pendingOrderWithItem(), nil)
+
+	// Execute: 30% below list, the policy allows 20%
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice:  35,
+		ReasonCode: "bulk_discount",
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "FORBIDDEN: discount of 30.00% exceeds the maximum of 20.00%")
+	suite.orderRepo.AssertNotCalled(suite.T(), "OverrideItemPrice")
+}
+
+// Test OverrideItemPrice - Prices above list are not overrides
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_AboveListPrice() {
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(pendingOrderWithItem(), nil)
+
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice:  55,
+		ReasonCode: "price_match",
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR: unit price 55.00 is above the list price 50.00")
+}
+
+// Test OverrideItemPrice - A reason code is required
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_InvalidReason() {
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice: 45,
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "invalid price override reason code")
+}
+
+// Test OverrideItemPrice - Only pending orders can be repriced
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_NotPending() {
+	order := pendingOrderWithItem()
+	order.Status = models.OrderStatusPaid
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice:  45,
+		ReasonCode: "negotiated",
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT: cannot override prices of a paid order")
+}
+
+// Test OverrideItemPrice - An order paid after it was read is not repriced
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_NoLongerPending() {
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(pendingOrderWithItem(), nil)
+	suite.orderRepo.On("OverrideItemPrice", suite.ctx, "order-1", "item-1", mock.Anything).Return(nil, nil)
+
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-1", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice:  45,
+		ReasonCode: "negotiated",
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "no longer pending")
+}
+
+// Test OverrideItemPrice - Unknown item
+func (suite *AdminOrderServiceTestSuite) TestOverrideItemPrice_ItemNotFound() {
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(pendingOrderWithItem(), nil)
+
+	// Execute
+	response, err := suite.adminOrderService.OverrideItemPrice(suite.ctx, "order-1", "item-2", "admin-1", services.OverrideItemPriceRequest{
+		UnitPrice:  45,
+		ReasonCode: "negotiated",
+	})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// giftOrder returns a delivered gift order of two units of a product
+func giftOrder(hidePrices bool) *models.Order {
+	return &models.Order{
+		ID: "order-1", UserID: "user-1", Status: models.OrderStatusPaid, TotalAmount: 40, Currency: "USD",
+		IsGift: true, GiftMessage: "Happy birthday!", GiftHidePrices: hidePrices,
+		ShippingAddress: &models.ShippingAddress{Recipient: "Friend", Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"},
+		Items: []models.OrderItem{{
+			ID: "item-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2, UnitPrice: 20, TotalPrice: 40,
+			Product: &models.Product{ID: "product-1", Name: "Mug", SKU: "MUG-1"},
+		}},
+	}
+}
+
+// Test GetPackingSlip - A gift order hiding prices ships with its message and no prices
+func (suite *AdminOrderServiceTestSuite) TestGetPackingSlip_GiftHidesPrices() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(giftOrder(true), nil)
+
+	slip, err := suite.adminOrderService.GetPackingSlip(suite.ctx, "order-1")
+
+	suite.Require().NoError(err)
+	suite.True(slip.Gift)
+	suite.True(slip.PricesHidden)
+	suite.Equal("Happy birthday!", slip.GiftMessage)
+	suite.Equal("Friend", slip.ShippingAddress.Recipient)
+	suite.Nil(slip.Total)
+	suite.Require().Len(slip.Items, 1)
+	suite.Equal("MUG-1", slip.Items[0].SKU)
+	suite.Equal(2, slip.Items[0].Quantity)
+	suite.Nil(slip.Items[0].UnitPrice)
+	suite.Nil(slip.Items[0].TotalPrice)
+}
+
+// Test GetPackingSlip - Other orders list their prices
+func (suite *AdminOrderServiceTestSuite) TestGetPackingSlip_ShowsPrices() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(giftOrder(false), nil)
+
+	slip, err := suite.adminOrderService.GetPackingSlip(suite.ctx, "order-1")
+
+	suite.Require().NoError(err)
+	suite.False(slip.PricesHidden)
+	suite.Require().NotNil(slip.Total)
+	suite.Equal(40.0, *slip.Total)
+	suite.Require().NotNil(slip.Items[0].UnitPrice)
+	suite.Equal(20.0, *slip.Items[0].UnitPrice)
+	suite.Equal(40.0, *slip.Items[0].TotalPrice)
+}
+
+// Test GetPackingSlip - Order not found
+func (suite *AdminOrderServiceTestSuite) TestGetPackingSlip_NotFound() {
+	suite.orderRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+
+	slip, err := suite.adminOrderService.GetPackingSlip(suite.ctx, "missing")
+
+	suite.Nil(slip)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test PackingSlipResponse.Document - A printed gift slip hiding prices has no price columns and closes with the message
+func (suite *AdminOrderServiceTestSuite) TestPackingSlipDocument_GiftHidesPrices() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(giftOrder(true), nil)
+
+	slip, err := suite.adminOrderService.GetPackingSlip(suite.ctx, "order-1")
+	suite.Require().NoError(err)
+	doc := slip.Document()
+
+	suite.Equal([]string{"SKU", "Product", "Qty"}, doc.Table.Columns)
+	suite.Require().Len(doc.Table.Rows, 1)
+	suite.Equal("MUG-1", doc.Table.Rows[0][0])
+	suite.Nil(doc.Table.Footer)
+	suite.Equal([]string{"Gift message:\nHappy birthday!"}, doc.Notes)
+}
+
+// pickOrder is an order ready to pick with an item per product ID, of the given quantity
+func pickOrder(id string, quantities map[string]int) *models.Order {
+	locations := map[string]string{"product-a": "B-02-1", "product-b": "A-10-3", "product-c": "", "product-d": "A-9-1"}
+	order := &models.Order{ID: id, Status: models.OrderStatusPaid}
+	for productID, quantity := range quantities {
+		order.Items = append(order.Items, models.OrderItem{
+			OrderID:   id,
+			ProductID: productID,
+			Quantity:  quantity,
+			Product: &models.Product{
+				ID:        productID,
+				SKU:       "SKU-" + productID,
+				Name:      "Product " + productID,
+				Inventory: &models.Inventory{ProductID: productID, Location: locations[productID]},
+			},
+		})
+	}
+	return order
+}
+
+// Test GetPickList - Items are summed per product across orders, in bin location order
+func (suite *AdminOrderServiceTestSuite) TestGetPickList_AggregatesInLocationOrder() {
+	orders := []*models.Order{
+		pickOrder("order-1", map[string]int{"product-a": 2, "product-c": 1}),
+		pickOrder("order-2", map[string]int{"product-a": 1, "product-b": 4}),
+	}
+	suite.orderRepo.On("ListByIDsWithItems", suite.ctx, []string{"order-1", "order-2"}).Return(orders, nil)
+
+	pickList, err := suite.adminOrderService.GetPickList(suite.ctx, services.PickListRequest{
+		OrderIDs: []string{"order-1", "order-2", "order-1"},
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal([]string{"order-1", "order-2"}, pickList.OrderIDs)
+	suite.Equal(8, pickList.TotalUnits)
+	suite.Require().Len(pickList.Lines, 3)
+	suite.Equal("A-10-3", pickList.Lines[0].Location)
+	suite.Equal(4, pickList.Lines[0].Quantity)
+	suite.Equal("B-02-1", pickList.Lines[1].Location)
+	suite.Equal(3, pickList.Lines[1].Quantity)
+	suite.Equal([]services.PickListOrder{{OrderID: "order-1", Quantity: 2}, {OrderID: "order-2", Quantity: 1}}, pickList.Lines[1].Orders)
+	suite.Equal("", pickList.Lines[2].Location)
+	suite.Equal("SKU-product-c", pickList.Lines[2].SKU)
+}
+
+// Test GetPickList - Aisle and shelf numbers are walked in numeric order, padded or not
+func (suite *AdminOrderServiceTestSuite) TestGetPickList_PickPathOrder() {
+	orders := []*models.Order{pickOrder("order-1", map[string]int{"product-a": 1, "product-b": 1, "product-d": 1})}
+	suite.orderRepo.On("ListByIDsWithItems", suite.ctx, []string{"order-1"}).Return(orders, nil)
+
+	pickList, err := suite.adminOrderService.GetPickList(suite.ctx, services.PickListRequest{OrderIDs: []string{"order-1"}})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(pickList.Lines, 3)
+	suite.Equal("A-9-1", pickList.Lines[0].Location)
+	suite.Equal("A-10-3", pickList.Lines[1].Location)
+	suite.Equal("B-02-1", pickList.Lines[2].Location)
+}
+
+// Test GetPickList - Orders that are not confirmed or paid, or are on hold, cannot be picked
+func (suite *AdminOrderServiceTestSuite) TestGetPickList_OrderNotReady() {
+	held := pickOrder("order-1", map[string]int{"product-a": 1})
+	held.OnHold = true
+	pending := pickOrder("order-2", map[string]int{"product-a": 1})
+	pending.Status = models.OrderStatusPending
+	suite.orderRepo.On("ListByIDsWithItems", suite.ctx, []string{"order-1"}).Return([]*models.Order{held}, nil)
+	suite.orderRepo.On("ListByIDsWithItems", suite.ctx, []string{"order-2"}).Return([]*models.Order{pending}, nil)
+
+	for _, orderID := range []string{"order-1", "order-2"} {
+		pickList, err := suite.adminOrderService.GetPickList(suite.ctx, services.PickListRequest{OrderIDs: []string{orderID}})
+
+		suite.Nil(pickList)
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "CONFLICT")
+	}
+}
+
+// Test GetPickList - Order not found
+func (suite *AdminOrderServiceTestSuite) TestGetPickList_OrderNotFound() {
+	suite.orderRepo.On("ListByIDsWithItems", suite.ctx, []string{"order-1", "missing"}).
+		Return([]*models.Order{pickOrder("order-1", map[string]int{"product-a": 1})}, nil)
+
+	pickList, err := suite.adminOrderService.GetPickList(suite.ctx, services.PickListRequest{
+		OrderIDs: []string{"order-1", "missing"},
+	})
+
+	suite.Nil(pickList)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// TestAdminOrderServiceTestSuite runs the test suite
+func TestAdminOrderServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminOrderServiceTestSuite))
+}
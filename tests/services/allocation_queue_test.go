@@ -0,0 +1,208 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// AllocationQueueTestSuite defines the test suite for AllocationQueue
+type AllocationQueueTestSuite struct {
+	suite.Suite
+	logger *logger.Logger
+	ctx    context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *AllocationQueueTestSuite) SetupTest() {
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+}
+
+// newQueue builds an enabled queue with the given wait and ticket TTL
+func (suite *AllocationQueueTestSuite) newQueue(wait, ttl time.Duration) *services.AllocationQueue {
+	return services.NewAllocationQueue(services.AllocationQueueSettings{Enabled: true, Wait: wait, TicketTTL: ttl}, suite.logger)
+}
+
+// queued returns the queue position an order was refused with
+func (suite *AllocationQueueTestSuite) queued(err error) *services.AllocationQueuePosition {
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "ALLOCATION_QUEUED")
+	position, ok := services.QueuePositionFromError(err)
+	suite.Require().True(ok)
+	return position
+}
+
+// Test Acquire - Orders for a product held by an earlier order are queued in arrival order
+func (suite *AllocationQueueTestSuite) TestAcquire_QueuesInArrivalOrder() {
+	queue := suite.newQueue(10*time.Millisecond, time.Minute)
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", items)
+	suite.Require().NoError(err)
+
+	_, err = queue.Acquire(suite.ctx, "", "user-2", items)
+	second := suite.queued(err)
+	suite.Equal(1, second.Position)
+	suite.NotEmpty(second.TicketID)
+
+	_, err = queue.Acquire(suite.ctx, "", "user-3", items)
+	third := suite.queued(err)
+	suite.Equal(2, third.Position)
+
+	// The third order cannot jump ahead while the second still holds its place
+	release()
+	_, err = queue.Acquire(suite.ctx, third.TicketID, "user-3", items)
+	suite.Equal(2, suite.queued(err).Position)
+
+	release, err = queue.Acquire(suite.ctx, second.TicketID, "user-2", items)
+	suite.Require().NoError(err)
+	release()
+
+	release, err = queue.Acquire(suite.ctx, third.TicketID, "user-3", items)
+	suite.Require().NoError(err)
+	release()
+}
+
+// Test Acquire - A waiting order is granted as soon as the order ahead releases
+func (suite *AllocationQueueTestSuite) TestAcquire_WaitsForRelease() {
+	queue := suite.newQueue(time.Second, time.Minute)
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", items)
+	suite.Require().NoError(err)
+
+	granted := make(chan error, 1)
+	go func() {
+		next, err := queue.Acquire(suite.ctx, "", "user-2", items)
+		if err == nil {
+			next()
+		}
+		granted <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	release()
+
+	select {
+	case err := <-granted:
+		suite.NoError(err)
+	case <-time.After(500 * time.Millisecond):
+		suite.Fail("waiting order was not granted after release")
+	}
+}
+
+// Test Acquire - Orders of other products are not held up
+func (suite *AllocationQueueTestSuite) TestAcquire_OtherProductsProceed() {
+	queue := suite.newQueue(10*time.Millisecond, time.Minute)
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 1}})
+	suite.Require().NoError(err)
+	defer release()
+
+	other, err := queue.Acquire(suite.ctx, "", "user-2", []services.OrderItem{{ProductID: "product-2", Quantity: 1}})
+	suite.Require().NoError(err)
+	other()
+}
+
+// Test Acquire - A stale ticket ahead loses its place to the order waiting behind it
+func (suite *AllocationQueueTestSuite) TestAcquire_ExpiresStaleTickets() {
+	queue := suite.newQueue(200*time.Millisecond, 30*time.Millisecond)
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", items)
+	suite.Require().NoError(err)
+	_, err = queue.Acquire(suite.ctx, "", "user-2", items)
+	abandoned := suite.queued(err)
+	release()
+
+	// The abandoned ticket heads the queue, but is never retried, so the waiting order
+	// is granted once it expires rather than after the whole wait
+	started := time.Now()
+	release, err = queue.Acquire(suite.ctx, "", "user-3", items)
+	suite.Require().NoError(err)
+	suite.Less(time.Since(started), 150*time.Millisecond)
+	release()
+
+	_, err = queue.Position(abandoned.TicketID, "user-2")
+	suite.Error(err)
+}
+
+// Test Acquire - A ticket only serves its user and the products it was issued for
+func (suite *AllocationQueueTestSuite) TestAcquire_TicketChecks() {
+	queue := suite.newQueue(10*time.Millisecond, time.Minute)
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", items)
+	suite.Require().NoError(err)
+	defer release()
+	_, err = queue.Acquire(suite.ctx, "", "user-2", items)
+	ticket := suite.queued(err)
+
+	_, err = queue.Acquire(suite.ctx, ticket.TicketID, "user-3", items)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+
+	_, err = queue.Acquire(suite.ctx, ticket.TicketID, "user-2", []services.OrderItem{{ProductID: "product-2", Quantity: 1}})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+
+	_, err = queue.Acquire(suite.ctx, "missing", "user-2", items)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test Position - Polling reports the place in line and keeps the ticket alive
+func (suite *AllocationQueueTestSuite) TestPosition() {
+	queue := suite.newQueue(10*time.Millisecond, 50*time.Millisecond)
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	release, err := queue.Acquire(suite.ctx, "", "user-1", items)
+	suite.Require().NoError(err)
+	defer release()
+	_, err = queue.Acquire(suite.ctx, "", "user-2", items)
+	ticket := suite.queued(err)
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(30 * time.Millisecond)
+		position, err := queue.Position(ticket.TicketID, "user-2")
+		suite.Require().NoError(err)
+		suite.Equal(1, position.Position)
+		suite.True(position.ExpiresAt.After(time.Now()))
+	}
+
+	_, err = queue.Position(ticket.TicketID, "user-1")
+	suite.Error(err)
+}
+
+// TestAllocationQueueTestSuite runs the test suite
+func TestAllocationQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(AllocationQueueTestSuite))
+}
+
+// Test Acquire - A disabled or nil queue grants every order at once
+func TestAllocationQueue_Disabled(t *testing.T) {
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	queue := services.NewAllocationQueue(services.AllocationQueueSettings{Wait: time.Second}, nil)
+	first, err := queue.Acquire(context.Background(), "", "user-1", items)
+	assert.NoError(t, err)
+	second, err := queue.Acquire(context.Background(), "", "user-2", items)
+	assert.NoError(t, err)
+	first()
+	second()
+
+	var nilQueue *services.AllocationQueue
+	release, err := nilQueue.Acquire(context.Background(), "", "user-1", items)
+	assert.NoError(t, err)
+	release()
+	_, err = nilQueue.Position("ticket", "user-1")
+	assert.Error(t, err)
+}
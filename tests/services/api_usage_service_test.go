@@ -0,0 +1,266 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// APIUsageServiceTestSuite defines the test suite for APIUsageService
+type APIUsageServiceTestSuite struct {
+	suite.Suite
+	apiUsageService services.APIUsageService
+	keyRepo         *mocks.MockAPIKeyRepository
+	usageRepo       *mocks.MockAPIUsageRepository
+	ctx             context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *APIUsageServiceTestSuite) SetupTest() {
+	suite.keyRepo = new(mocks.MockAPIKeyRepository)
+	suite.usageRepo = new(mocks.MockAPIUsageRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.apiUsageService = services.NewAPIUsageService(
+		services.APIUsageSettings{DefaultMonthlyQuota: 1000},
+		suite.keyRepo,
+		suite.usageRepo,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *APIUsageServiceTestSuite) TearDownTest() {
+	suite.keyRepo.AssertExpectations(suite.T())
+	suite.usageRepo.AssertExpectations(suite.T())
+}
+
+// sha256Hex returns the hex SHA-256 hash API keys are stored by
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// Test CreateAPIKey - Only the hash of the key is stored, with the default quota, and the key authenticates
+func (suite *APIUsageServiceTestSuite) TestCreateAPIKey_Success() {
+	var stored *models.APIKey
+	suite.keyRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.APIKey) }).
+		Return(nil)
+
+	// Execute
+	response, err := suite.apiUsageService.CreateAPIKey(suite.ctx, "user-1", services.CreateAPIKeyRequest{Name: " Warehouse sync "})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.True(strings.HasPrefix(response.Key, "eo_"))
+	suite.Equal("Warehouse sync", stored.Name)
+	suite.Equal("user-1", stored.UserID)
+	suite.Equal(sha256Hex(response.Key), stored.KeyHash)
+	suite.Equal(response.Key[:len(stored.Prefix)], stored.Prefix)
+	suite.NotContains(stored.KeyHash, response.Key)
+	suite.Equal(1000, *response.MonthlyQuota)
+	suite.Equal(int64(1000), *response.Remaining)
+
+	suite.keyRepo.On("GetByHash", suite.ctx, stored.KeyHash).Return(&models.APIKey{
+		ID:     "key-1",
+		UserID: "user-1",
+		User:   &models.User{ID: "user-1", IsActive: true},
+	}, nil)
+
+	key, err := suite.apiUsageService.AuthenticateAPIKey(suite.ctx, response.Key)
+	suite.Require().NoError(err)
+	suite.Equal("key-1", key.ID)
+}
+
+// Test AuthenticateAPIKey - Unknown, revoked and deactivated users' keys are refused, other values without a lookup
+func (suite *APIUsageServiceTestSuite) TestAuthenticateAPIKey_Refused() {
+	revokedAt := time.Now()
+	suite.keyRepo.On("GetByHash", suite.ctx, sha256Hex("eo_unknown")).Return(nil, nil)
+	suite.keyRepo.On("GetByHash", suite.ctx, sha256Hex("eo_revoked")).Return(&models.APIKey{
+		ID: "key-1", RevokedAt: &revokedAt, User: &models.User{IsActive: true},
+	}, nil)
+	suite.keyRepo.On("GetByHash", suite.ctx, sha256Hex("eo_inactive")).Return(&models.APIKey{
+		ID: "key-2", User: &models.User{IsActive: false},
+	}, nil)
+
+	for _, rawKey := range []string{"eo_unknown", "eo_revoked", "eo_inactive", "Bearer token"} {
+		key, err := suite.apiUsageService.AuthenticateAPIKey(suite.ctx, rawKey)
+		suite.NoError(err)
+		suite.Nil(key, rawKey)
+	}
+}
+
+// Test ReserveQuota - The count starts from the stored and buffered usage of the month and stops at the quota
+func (suite *APIUsageServiceTestSuite) TestReserveQuota_Exhausted() {
+	at := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	monthStart := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	suite.usageRepo.On("CountRequests", suite.ctx, "key-1", monthStart, monthStart.AddDate(0, 1, 0)).
+		Return(int64(7), nil).Once()
+
+	// One request served but not yet flushed, and one from last month
+	suite.apiUsageService.RecordRequest(models.APIUsage{
+		Day: time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC), APIKeyID: "key-1", Method: "GET", Route: "/api/v1/orders", Requests: 1,
+	})
+	suite.apiUsageService.RecordRequest(models.APIUsage{
+		Day: time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC), APIKeyID: "key-1", Method: "GET", Route: "/api/v1/orders", Requests: 1,
+	})
+
+	quota := 10
+	key := &models.APIKey{ID: "key-1", MonthlyQuota: &quota}
+
+	// Execute
+	var allowed []bool
+	for i := 0; i < 3; i++ {
+		ok, err := suite.apiUsageService.ReserveQuota(suite.ctx, key, at)
+		suite.Require().NoError(err)
+		allowed = append(allowed, ok)
+	}
+
+	// Assert
+	suite.Equal([]bool{true, true, false}, allowed)
+}
+
+// Test ReserveQuota - Keys without a quota are allowed without counting
+func (suite *APIUsageServiceTestSuite) TestReserveQuota_Unlimited() {
+	allowed, err := suite.apiUsageService.ReserveQuota(suite.ctx, &models.APIKey{ID: "key-1"}, time.Now())
+
+	suite.NoError(err)
+	suite.True(allowed)
+}
+
+// Test FlushUsage - Requests are aggregated by day, caller and endpoint, and kept when the write fails
+func (suite *APIUsageServiceTestSuite) TestFlushUsage_Aggregates() {
+	day := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	record := func(route string, latency int64, errors int64) {
+		suite.apiUsageService.RecordRequest(models.APIUsage{
+			Day: day, UserID: "user-1", Method: "GET", Route: route,
+			Requests: 1, Errors: errors, TotalLatencyMs: latency, MaxLatencyMs: latency,
+		})
+	}
+	record("/api/v1/orders", 20, 0)
+	record("/api/v1/orders", 80, 1)
+	record("/api/v1/products", 5, 0)
+	suite.apiUsageService.RecordRequest(models.APIUsage{
+		Day: day, UserID: "user-1", Method: "GET", Route: "/api/v1/orders", Rejected: 1,
+	})
+
+	var flushed []models.APIUsage
+	suite.usageRepo.On("Increment", suite.ctx, mock.Anything).Return(assert.AnError).Once()
+	suite.usageRepo.On("Increment", suite.ctx, mock.Anything).
+		Run(func(args mock.Arguments) { flushed = args.Get(1).([]models.APIUsage) }).
+		Return(nil).Once()
+
+	// Execute
+	err := suite.apiUsageService.FlushUsage(suite.ctx)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "failed to flush API usage")
+	suite.Require().NoError(suite.apiUsageService.FlushUsage(suite.ctx))
+
+	// Assert
+	suite.Require().Len(flushed, 2)
+	suite.Equal("/api/v1/orders", flushed[0].Route)
+	suite.Equal(int64(2), flushed[0].Requests)
+	suite.Equal(int64(1), flushed[0].Errors)
+	suite.Equal(int64(1), flushed[0].Rejected)
+	suite.Equal(int64(100), flushed[0].TotalLatencyMs)
+	suite.Equal(int64(80), flushed[0].MaxLatencyMs)
+	suite.Equal("/api/v1/products", flushed[1].Route)
+	suite.Equal(int64(1), flushed[1].Requests)
+
+	// Nothing is left to flush
+	suite.NoError(suite.apiUsageService.FlushUsage(suite.ctx))
+}
+
+// Test GetAPIUsage - Usage is summed by endpoint and caller with error rates and average latencies
+func (suite *APIUsageServiceTestSuite) TestGetAPIUsage_Success() {
+	filter := repository.APIUsageFilter{
+		From:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC),
+		UserID: "user-1",
+	}
+	suite.usageRepo.On("SummarizeByEndpoint", suite.ctx, filter).Return([]repository.APIUsageTotals{
+		{Method: "GET", Route: "/api/v1/orders", Requests: 8, Errors: 2, Rejected: 3, TotalLatencyMs: 400, MaxLatencyMs: 120},
+		{Method: "POST", Route: "/api/v1/orders", Requests: 2, TotalLatencyMs: 300, MaxLatencyMs: 200},
+	}, nil)
+	suite.usageRepo.On("SummarizeByCaller", suite.ctx, filter).Return([]repository.APIUsageTotals{
+		{APIKeyID: "key-1", UserID: "user-1", Requests: 10, Errors: 2, Rejected: 3, TotalLatencyMs: 700, MaxLatencyMs: 200},
+	}, nil)
+
+	// Execute
+	report, err := suite.apiUsageService.GetAPIUsage(suite.ctx, services.APIUsageRequest{
+		StartDate: "2025-06-01",
+		EndDate:   "2025-06-07",
+		UserID:    "user-1",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(int64(10), report.Totals.Requests)
+	suite.Equal(int64(3), report.Totals.Rejected)
+	suite.Equal(20.0, report.Totals.ErrorRate)
+	suite.Equal(70.0, report.Totals.AvgLatencyMs)
+	suite.Equal(int64(200), report.Totals.MaxLatencyMs)
+	suite.Require().Len(report.ByEndpoint, 2)
+	suite.Equal(25.0, report.ByEndpoint[0].ErrorRate)
+	suite.Equal(150.0, report.ByEndpoint[1].AvgLatencyMs)
+	suite.Require().Len(report.ByCaller, 1)
+	suite.Equal("key-1", report.ByCaller[0].APIKeyID)
+}
+
+// Test GetAPIUsage - An invalid date range is a validation error
+func (suite *APIUsageServiceTestSuite) TestGetAPIUsage_InvalidRange() {
+	_, err := suite.apiUsageService.GetAPIUsage(suite.ctx, services.APIUsageRequest{
+		StartDate: "2025-06-07",
+		EndDate:   "2025-06-01",
+	})
+
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// Test RevokeAPIKey - Another user's key is reported missing
+func (suite *APIUsageServiceTestSuite) TestRevokeAPIKey_OtherUsersKey() {
+	suite.keyRepo.On("GetByID", suite.ctx, "key-1").Return(&models.APIKey{ID: "key-1", UserID: "user-2"}, nil)
+
+	err := suite.apiUsageService.RevokeAPIKey(suite.ctx, "user-1", "key-1")
+
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+	suite.keyRepo.AssertNotCalled(suite.T(), "Revoke", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test SetAPIKeyQuota - The quota is set and the response shows what remains of it this month
+func (suite *APIUsageServiceTestSuite) TestSetAPIKeyQuota_Success() {
+	quota := 50
+	suite.keyRepo.On("SetQuota", suite.ctx, "key-1", &quota).Return(true, nil)
+	suite.keyRepo.On("GetByID", suite.ctx, "key-1").Return(&models.APIKey{ID: "key-1", MonthlyQuota: &quota}, nil)
+	suite.usageRepo.On("CountRequests", suite.ctx, "key-1", mock.Anything, mock.Anything).Return(int64(60), nil)
+
+	// Execute
+	response, err := suite.apiUsageService.SetAPIKeyQuota(suite.ctx, "key-1", services.SetAPIKeyQuotaRequest{MonthlyQuota: &quota})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(int64(60), response.UsedThisMonth)
+	suite.Equal(int64(0), *response.Remaining)
+}
+
+// TestAPIUsageServiceTestSuite runs the test suite
+func TestAPIUsageServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(APIUsageServiceTestSuite))
+}
@@ -0,0 +1,179 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/cache"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// AvailabilityServiceTestSuite defines the test suite for AvailabilityService
+type AvailabilityServiceTestSuite struct {
+	suite.Suite
+	availabilityService services.AvailabilityService
+	availabilityRepo    *mocks.MockProductAvailabilityRepository
+	logger              *logger.Logger
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *AvailabilityServiceTestSuite) SetupTest() {
+	suite.availabilityRepo = new(mocks.MockProductAvailabilityRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.availabilityService = services.NewAvailabilityService(
+		services.AvailabilitySettings{MaxStaleness: 5 * time.Minute},
+		suite.availabilityRepo,
+		cache.NewQueryCache(time.Minute, 100),
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *AvailabilityServiceTestSuite) TearDownTest() {
+	suite.availabilityRepo.AssertExpectations(suite.T())
+}
+
+// Test GetAvailability - Fresh entries are served from the read model, then from the cache
+func (suite *AvailabilityServiceTestSuite) TestGetAvailability_FreshEntryIsCached() {
+	availability := &models.ProductAvailability{ProductID: "product-1", Available: 7, UpdatedAt: time.Now().Add(-time.Minute)}
+
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(availability, nil).Once()
+
+	// Execute
+	first, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+	suite.NoError(err)
+	second, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(7, first.Available)
+	suite.True(first.InStock)
+	suite.False(first.Stale)
+	suite.Equal(first, second)
+	suite.availabilityRepo.AssertNotCalled(suite.T(), "Refresh", mock.Anything, mock.Anything)
+}
+
+// Test GetAvailability - Entries past the staleness bound are re-materialized
+func (suite *AvailabilityServiceTestSuite) TestGetAvailability_StaleEntryIsRefreshed() {
+	stale := &models.ProductAvailability{ProductID: "product-1", Available: 7, UpdatedAt: time.Now().Add(-time.Hour)}
+	refreshed := &models.ProductAvailability{ProductID: "product-1", Available: 3, UpdatedAt: time.Now()}
+
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(stale, nil)
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1"}).Return([]*models.ProductAvailability{refreshed}, nil)
+
+	// Execute
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(3, response.Available)
+	suite.False(response.Stale)
+}
+
+// Test GetAvailability - Missing entries are materialized on first read
+func (suite *AvailabilityServiceTestSuite) TestGetAvailability_MissingEntryIsMaterialized() {
+	refreshed := &models.ProductAvailability{ProductID: "product-1", Available: 0, UpdatedAt: time.Now()}
+
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(nil, nil)
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1"}).Return([]*models.ProductAvailability{refreshed}, nil)
+
+	// Execute
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(0, response.Available)
+	suite.False(response.InStock)
+}
+
+// Test GetAvailability - A stale entry is served when inventory can't be re-read
+func (suite *AvailabilityServiceTestSuite) TestGetAvailability_FallsBackToStaleEntry() {
+	stale := &models.ProductAvailability{ProductID: "product-1", Available: 7, UpdatedAt: time.Now().Add(-time.Hour)}
+
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(stale, nil)
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1"}).Return(nil, errors.New("database error"))
+
+	// Execute
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(7, response.Available)
+	suite.True(response.Stale)
+}
+
+// Test GetAvailability - Products without inventory are not found
+func (suite *AvailabilityServiceTestSuite) TestGetAvailability_NoInventory() {
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(nil, nil)
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1"}).Return([]*models.ProductAvailability{}, nil)
+
+	// Execute
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "not found")
+}
+
+// Test NotifyStockChanges - Changed products are refreshed once and served from the cache
+func (suite *AvailabilityServiceTestSuite) TestNotifyStockChanges_RefreshesAndCaches() {
+	refreshed := []*models.ProductAvailability{
+		{ProductID: "product-1", Available: 4, UpdatedAt: time.Now()},
+		{ProductID: "product-2", Available: 9, UpdatedAt: time.Now()},
+	}
+
+	// Mock expectations
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1", "product-2"}).Return(refreshed, nil)
+
+	// Execute
+	suite.availabilityService.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1", "product-2", "product-1"})
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-2")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(9, response.Available)
+	suite.availabilityRepo.AssertNotCalled(suite.T(), "GetByProductID", mock.Anything, mock.Anything)
+}
+
+// Test NotifyStockChanges - A failed refresh drops the cached entry
+func (suite *AvailabilityServiceTestSuite) TestNotifyStockChanges_FailedRefreshInvalidatesCache() {
+	cached := &models.ProductAvailability{ProductID: "product-1", Available: 7, UpdatedAt: time.Now()}
+	stored := &models.ProductAvailability{ProductID: "product-1", Available: 2, UpdatedAt: time.Now()}
+
+	// Mock expectations
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(cached, nil).Once()
+	suite.availabilityRepo.On("Refresh", suite.ctx, []string{"product-1"}).Return(nil, errors.New("database error"))
+	suite.availabilityRepo.On("GetByProductID", suite.ctx, "product-1").Return(stored, nil).Once()
+
+	// Execute
+	_, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+	suite.NoError(err)
+	suite.availabilityService.NotifyStockChanges(suite.ctx, services.StockChangeReasonAdjustment, []string{"product-1"})
+	response, err := suite.availabilityService.GetAvailability(suite.ctx, "product-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(2, response.Available)
+}
+
+// TestAvailabilityServiceTestSuite runs the test suite
+func TestAvailabilityServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AvailabilityServiceTestSuite))
+}
@@ -0,0 +1,252 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/metrics"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// BlocklistServiceTestSuite defines the test suite for BlocklistService and the fraud screener
+type BlocklistServiceTestSuite struct {
+	suite.Suite
+	blocklistService services.BlocklistService
+	screener         *services.FraudScreener
+	stages           *metrics.StageRecorder
+	blocklistRepo    *mocks.MockBlocklistRepository
+	userRepo         *mocks.MockUserRepository
+	actor            services.AuditActor
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *BlocklistServiceTestSuite) SetupTest() {
+	suite.blocklistRepo = new(mocks.MockBlocklistRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.stages = metrics.NewStageRecorder(10)
+	suite.actor = services.AuditActor{UserID: "admin-1", IPAddress: "10.0.0.1", UserAgent: "test"}
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.screener = services.NewFraudScreener(suite.blocklistRepo, suite.userRepo, suite.stages, log)
+	suite.blocklistService = services.NewBlocklistService(suite.blocklistRepo, suite.screener, log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *BlocklistServiceTestSuite) TearDownTest() {
+	suite.blocklistRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test AddEntry - Values are normalized and the addition is audit-logged
+func (suite *BlocklistServiceTestSuite) TestAddEntry_NormalizesValues() {
+	cases := []struct {
+		entryType string
+		value     string
+		expected  string
+	}{
+		{"email", " Fraudster@Example.COM ", "fraudster@example.com"},
+		{"domain", "@Mailinator.com", "mailinator.com"},
+		{"domain", "*.temp-mail.org", "temp-mail.org"},
+		{"card_fingerprint", " fp_8Hk2 ", "fp_8Hk2"},
+		{"ip_range", "203.0.113.7", "203.0.113.7/32"},
+		{"ip_range", "198.51.100.77/24", "198.51.100.0/24"},
+		{"ip_range", "2001:db8::1/32", "2001:db8::/32"},
+	}
+
+	for _, tc := range cases {
+		var auditLog *models.AuditLog
+		suite.blocklistRepo.On("Create", suite.ctx, mock.MatchedBy(func(entry *models.BlocklistEntry) bool {
+			return entry.Value == tc.expected
+		}), mock.AnythingOfType("*models.AuditLog")).
+			Run(func(args mock.Arguments) { auditLog = args.Get(2).(*models.AuditLog) }).
+			Return(true, nil).Once()
+
+		// Execute
+		entry, err := suite.blocklistService.AddEntry(suite.ctx, suite.actor, services.CreateBlocklistEntryRequest{
+			Type:   tc.entryType,
+			Value:  tc.value,
+			Reason: "chargebacks",
+		})
+
+		// Assert
+		suite.Require().NoError(err, tc.value)
+		suite.Equal(models.BlocklistType(tc.entryType), entry.Type)
+		suite.Equal(tc.expected, entry.Value)
+		suite.Equal("admin-1", *entry.CreatedBy)
+
+		suite.Require().NotNil(auditLog)
+		suite.Equal("blocklist_entry", auditLog.EntityType)
+		suite.Equal(models.AuditActionCreate, auditLog.Action)
+		suite.Equal("admin-1", *auditLog.UserID)
+		suite.Equal("10.0.0.1", auditLog.IPAddress)
+		var values map[string]interface{}
+		suite.Require().NoError(auditLog.GetNewValues(&values))
+		suite.Equal(tc.expected, values["value"])
+	}
+}
+
+// Test AddEntry - Malformed values and past expiries are refused
+func (suite *BlocklistServiceTestSuite) TestAddEntry_InvalidValues() {
+	past := time.Now().Add(-time.Hour)
+	requests := []services.CreateBlocklistEntryRequest{
+		{Type: "email", Value: "not-an-email"},
+		{Type: "domain", Value: "localhost"},
+		{Type: "domain", Value: "user@example.com"},
+		{Type: "ip_range", Value: "300.1.2.3"},
+		{Type: "phone", Value: "555-0100"},
+		{Type: "email", Value: "fraudster@example.com", ExpiresAt: &past},
+	}
+
+	for _, req := range requests {
+		// Execute
+		_, err := suite.blocklistService.AddEntry(suite.ctx, suite.actor, req)
+
+		// Assert
+		suite.Require().Error(err, req.Value)
+		suite.Contains(err.Error(), "VALIDATION_ERROR", req.Value)
+	}
+	suite.blocklistRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test AddEntry - Values already listed are a conflict
+func (suite *BlocklistServiceTestSuite) TestAddEntry_AlreadyListed() {
+	suite.blocklistRepo.On("Create", suite.ctx, mock.Anything, mock.Anything).Return(false, nil)
+
+	// Execute
+	_, err := suite.blocklistService.AddEntry(suite.ctx, suite.actor, services.CreateBlocklistEntryRequest{
+		Type:  "email",
+		Value: "fraudster@example.com",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test RemoveEntry - The removal is audit-logged with the removed values
+func (suite *BlocklistServiceTestSuite) TestRemoveEntry_Success() {
+	entry := &models.BlocklistEntry{ID: "entry-1", Type: models.BlocklistTypeDomain, Value: "mailinator.com"}
+	suite.blocklistRepo.On("GetByID", suite.ctx, "entry-1").Return(entry, nil)
+	suite.blocklistRepo.On("Delete", suite.ctx, "entry-1", mock.MatchedBy(func(auditLog *models.AuditLog) bool {
+		var values map[string]interface{}
+		return auditLog.EntityID == "entry-1" && auditLog.Action == models.AuditActionDelete &&
+			auditLog.GetOldValues(&values) == nil && values["value"] == "mailinator.com" && auditLog.NewValues == ""
+	})).Return(true, nil)
+
+	// Execute
+	err := suite.blocklistService.RemoveEntry(suite.ctx, suite.actor, "entry-1")
+
+	// Assert
+	suite.NoError(err)
+}
+
+// Test RemoveEntry - Unknown entries are not found
+func (suite *BlocklistServiceTestSuite) TestRemoveEntry_NotFound() {
+	suite.blocklistRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+
+	// Execute
+	err := suite.blocklistService.RemoveEntry(suite.ctx, suite.actor, "missing")
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test Screen - The user's email, its parent domains, the card and the IP are matched
+func (suite *BlocklistServiceTestSuite) TestScreen_BlocksMatches() {
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1", Email: "Buyer@Mail.Example.com"}, nil)
+	suite.blocklistRepo.On("FindMatches", suite.ctx, repository.BlocklistMatch{
+		Emails:           []string{"buyer@mail.example.com"},
+		Domains:          []string{"mail.example.com", "example.com"},
+		CardFingerprints: []string{"fp_1"},
+		IPAddress:        "203.0.113.7",
+	}, mock.AnythingOfType("time.Time")).Return([]*models.BlocklistEntry{
+		{ID: "entry-1", Type: models.BlocklistTypeDomain, Value: "example.com"},
+		{ID: "entry-2", Type: models.BlocklistTypeIPRange, Value: "203.0.113.0/24"},
+	}, nil)
+	suite.blocklistRepo.On("RecordHits", suite.ctx, []string{"entry-1", "entry-2"}, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	err := suite.screener.Screen(suite.ctx, services.FraudCheckpointPayment, services.FraudSubject{
+		UserID:          "user-1",
+		IPAddress:       "::ffff:203.0.113.7",
+		CardFingerprint: "fp_1",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "FORBIDDEN")
+	suite.NotContains(err.Error(), "example.com")
+}
+
+// Test Screen - Clean attempts pass, and attempts are counted by checkpoint and type
+func (suite *BlocklistServiceTestSuite) TestScreen_Metrics() {
+	suite.blocklistRepo.On("FindMatches", suite.ctx, mock.MatchedBy(func(match repository.BlocklistMatch) bool {
+		return match.Emails[0] == "clean@example.com"
+	}), mock.Anything).Return(nil, nil)
+	suite.blocklistRepo.On("FindMatches", suite.ctx, mock.MatchedBy(func(match repository.BlocklistMatch) bool {
+		return match.Emails[0] == "fraud@example.com"
+	}), mock.Anything).Return([]*models.BlocklistEntry{
+		{ID: "entry-1", Type: models.BlocklistTypeEmail},
+		{ID: "entry-2", Type: models.BlocklistTypeEmail},
+	}, nil)
+	suite.blocklistRepo.On("RecordHits", suite.ctx, mock.Anything, mock.Anything).Return(nil)
+	suite.blocklistRepo.On("ListTopHits", suite.ctx, mock.AnythingOfType("int")).Return(nil, nil)
+
+	// Execute
+	suite.NoError(suite.screener.Screen(suite.ctx, services.FraudCheckpointOrder, services.FraudSubject{Email: "clean@example.com"}))
+	suite.NoError(suite.screener.Screen(suite.ctx, services.FraudCheckpointOrder, services.FraudSubject{Email: "clean@example.com"}))
+	suite.Error(suite.screener.Screen(suite.ctx, services.FraudCheckpointOrder, services.FraudSubject{Email: "fraud@example.com"}))
+	suite.Error(suite.screener.Screen(suite.ctx, services.FraudCheckpointPayment, services.FraudSubject{Email: "fraud@example.com"}))
+	response, err := suite.blocklistService.GetMetrics(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.NotNil(response.TopEntries)
+	suite.Require().Len(response.Checkpoints, 2)
+
+	order := response.Checkpoints[0]
+	suite.Equal(services.FraudCheckpointOrder, order.Checkpoint)
+	suite.Equal(int64(3), order.Screened)
+	suite.Equal(int64(1), order.Blocked)
+	suite.Equal(33.33, order.BlockRate)
+	suite.Equal(int64(1), order.ByType[models.BlocklistTypeEmail])
+
+	payment := response.Checkpoints[1]
+	suite.Equal(services.FraudCheckpointPayment, payment.Checkpoint)
+	suite.Equal(int64(1), payment.Blocked)
+	suite.Equal(100.0, payment.BlockRate)
+
+	// Screening is timed as a pipeline stage, blocked attempts counting as failures
+	summaries := suite.stages.Summary()
+	suite.Require().Len(summaries, 1)
+	suite.Equal(services.StageFraudScreening, summaries[0].Stage)
+	suite.Equal(int64(4), summaries[0].Executions)
+	suite.Equal(int64(2), summaries[0].Failures)
+}
+
+// Test Screen - A nil screener lets every attempt through
+func (suite *BlocklistServiceTestSuite) TestScreen_NilScreener() {
+	var screener *services.FraudScreener
+
+	// Execute
+	err := screener.Screen(suite.ctx, services.FraudCheckpointOrder, services.FraudSubject{Email: "fraud@example.com"})
+
+	// Assert
+	suite.NoError(err)
+}
+
+// TestBlocklistServiceTestSuite runs the test suite
+func TestBlocklistServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(BlocklistServiceTestSuite))
+}
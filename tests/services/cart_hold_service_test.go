@@ -0,0 +1,230 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// CartHoldServiceTestSuite defines the test suite for CartHoldService
+type CartHoldServiceTestSuite struct {
+	suite.Suite
+	cartHoldService services.CartHoldService
+	holdRepo        *mocks.MockStockHoldRepository
+	productRepo     *mocks.MockProductRepository
+	logger          *logger.Logger
+	ctx             context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *CartHoldServiceTestSuite) SetupTest() {
+	suite.holdRepo = new(mocks.MockStockHoldRepository)
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.cartHoldService = suite.newService(services.CartHoldSettings{Enabled: true, Window: 15 * time.Minute})
+}
+
+func (suite *CartHoldServiceTestSuite) newService(settings services.CartHoldSettings) services.CartHoldService {
+	return services.NewCartHoldService(
+		settings,
+		suite.holdRepo,
+		suite.productRepo,
+		nil, // No stock webhook subscribers
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *CartHoldServiceTestSuite) TearDownTest() {
+	suite.holdRepo.AssertExpectations(suite.T())
+	suite.productRepo.AssertExpectations(suite.T())
+}
+
+// Test HoldItem - Holds expire after the store window
+func (suite *CartHoldServiceTestSuite) TestHoldItem_UsesStoreWindow() {
+	product := &models.Product{ID: "product-1", IsActive: true}
+	start := time.Now()
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil)
+	suite.holdRepo.On("Hold", suite.ctx, mock.MatchedBy(func(hold *models.StockHold) bool {
+		return hold.UserID == "user-1" && hold.ProductID == "product-1" && hold.Quantity == 2 &&
+			!hold.ExpiresAt.Before(start.Add(15*time.Minute))
+	})).Run(func(args mock.Arguments) {
+		hold := args.Get(1).(*models.StockHold)
+		hold.ID = "hold-1"
+		hold.Status = models.StockHoldStatusActive
+	}).Return(nil)
+
+	// Execute
+	response, err := suite.cartHoldService.HoldItem(suite.ctx, "user-1", services.HoldCartItemRequest{ProductID: "product-1", Quantity: 2})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "hold-1", response.ID)
+	assert.Equal(suite.T(), models.StockHoldStatusActive, response.Status)
+	assert.WithinDuration(suite.T(), start.Add(15*time.Minute), response.ExpiresAt, time.Second)
+}
+
+// Test HoldItem - A product's hold window overrides the store window
+func (suite *CartHoldServiceTestSuite) TestHoldItem_ProductWindowOverride() {
+	product := &models.Product{ID: "product-1", IsActive: true, CartHoldMinutes: 60}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil)
+	suite.holdRepo.On("Hold", suite.ctx, mock.AnythingOfType("*models.StockHold")).Return(nil)
+
+	// Execute
+	response, err := suite.cartHoldService.HoldItem(suite.ctx, "user-1", services.HoldCartItemRequest{ProductID: "product-1", Quantity: 1})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.WithinDuration(suite.T(), time.Now().Add(time.Hour), response.ExpiresAt, time.Second)
+}
+
+// Test HoldItem - Nothing is held while holds are disabled
+func (suite *CartHoldServiceTestSuite) TestHoldItem_Disabled() {
+	cartHoldService := suite.newService(services.CartHoldSettings{Enabled: false, Window: 15 * time.Minute})
+
+	// Execute
+	response, err := cartHoldService.HoldItem(suite.ctx, "user-1", services.HoldCartItemRequest{ProductID: "product-1", Quantity: 1})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "disabled")
+	suite.holdRepo.AssertNotCalled(suite.T(), "Hold", mock.Anything, mock.Anything)
+}
+
+// Test HoldItem - Inactive products cannot be held
+func (suite *CartHoldServiceTestSuite) TestHoldItem_ProductInactive() {
+	product := &models.Product{ID: "product-1", IsActive: false}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil)
+
+	// Execute
+	response, err := suite.cartHoldService.HoldItem(suite.ctx, "user-1", services.HoldCartItemRequest{ProductID: "product-1", Quantity: 1})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "not available")
+}
+
+// Test HoldItem - Insufficient stock from the repository is returned
+func (suite *CartHoldServiceTestSuite) TestHoldItem_InsufficientStock() {
+	product := &models.Product{ID: "product-1", IsActive: true}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").Return(product, nil)
+	suite.holdRepo.On("Hold", suite.ctx, mock.AnythingOfType("*models.StockHold")).
+		Return(apperrors.NewInsufficientStockError("product-1", 5, 3))
+
+	// Execute
+	response, err := suite.cartHoldService.HoldItem(suite.ctx, "user-1", services.HoldCartItemRequest{ProductID: "product-1", Quantity: 5})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "INSUFFICIENT_STOCK")
+}
+
+// Test ReleaseItem - Releases the user's active hold
+func (suite *CartHoldServiceTestSuite) TestReleaseItem_Success() {
+	hold := &models.StockHold{ID: "hold-1", UserID: "user-1", ProductID: "product-1", Quantity: 2, Status: models.StockHoldStatusActive}
+
+	// Mock expectations
+	suite.holdRepo.On("GetActive", suite.ctx, "user-1", "product-1").Return(hold, nil)
+	suite.holdRepo.On("Release", suite.ctx, "hold-1", models.StockHoldStatusReleased).Return(true, nil)
+
+	// Execute
+	err := suite.cartHoldService.ReleaseItem(suite.ctx, "user-1", "product-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+}
+
+// Test ReleaseItem - No active hold
+func (suite *CartHoldServiceTestSuite) TestReleaseItem_NotFound() {
+	// Mock expectations
+	suite.holdRepo.On("GetActive", suite.ctx, "user-1", "product-1").Return(nil, nil)
+
+	// Execute
+	err := suite.cartHoldService.ReleaseItem(suite.ctx, "user-1", "product-1")
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+// Test ReleaseExpiredHolds - Expired holds are released; holds converted meanwhile are skipped
+func (suite *CartHoldServiceTestSuite) TestReleaseExpiredHolds_ReleasesExpired() {
+	expired := []*models.StockHold{
+		{ID: "hold-1", ProductID: "product-1", Quantity: 1},
+		{ID: "hold-2", ProductID: "product-2", Quantity: 3},
+		{ID: "hold-3", ProductID: "product-3", Quantity: 2},
+	}
+
+	// Mock expectations
+	suite.holdRepo.On("ListExpired", suite.ctx, mock.AnythingOfType("time.Time"), 100).Return(expired, nil)
+	suite.holdRepo.On("Release", suite.ctx, "hold-1", models.StockHoldStatusExpired).Return(true, nil)
+	suite.holdRepo.On("Release", suite.ctx, "hold-2", models.StockHoldStatusExpired).Return(false, nil)
+	suite.holdRepo.On("Release", suite.ctx, "hold-3", models.StockHoldStatusExpired).Return(false, errors.New("database error"))
+
+	// Execute
+	released, err := suite.cartHoldService.ReleaseExpiredHolds(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, released)
+}
+
+// Test GetConversionStats - Conversion rate counts only holds that have ended
+func (suite *CartHoldServiceTestSuite) TestGetConversionStats_ConversionRate() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC) // Day after the inclusive end date
+
+	// Mock expectations
+	suite.holdRepo.On("CountByStatus", suite.ctx, start, end).Return([]repository.StockHoldStatusCount{
+		{Status: models.StockHoldStatusActive, Holds: 2, Units: 2},
+		{Status: models.StockHoldStatusConverted, Holds: 3, Units: 7},
+		{Status: models.StockHoldStatusExpired, Holds: 4, Units: 5},
+		{Status: models.StockHoldStatusReleased, Holds: 1, Units: 1},
+	}, nil)
+
+	// Execute
+	stats, err := suite.cartHoldService.GetConversionStats(suite.ctx, services.CartHoldStatsRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-02",
+	})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 10, stats.Holds)
+	assert.Equal(suite.T(), 15, stats.HeldUnits)
+	assert.Equal(suite.T(), 3, stats.Converted)
+	assert.Equal(suite.T(), 7, stats.ConvertedUnits)
+	assert.Equal(suite.T(), 4, stats.Expired)
+	assert.Equal(suite.T(), 1, stats.Released)
+	assert.Equal(suite.T(), 37.5, stats.ConversionRate)
+}
+
+// TestCartHoldServiceTestSuite runs the test suite
+func TestCartHoldServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CartHoldServiceTestSuite))
+}
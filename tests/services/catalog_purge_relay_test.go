@@ -0,0 +1,180 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/webhooks"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const catalogPurgeSecret = "purge_0123456789abcdef"
+
+// CatalogPurgeRelayTestSuite defines the test suite for CatalogPurgeRelay
+type CatalogPurgeRelayTestSuite struct {
+	suite.Suite
+	catalogEventRepo *mocks.MockCatalogEventRepository
+	server           *httptest.Server
+	status           int
+	deliveries       []webhooks.Event
+	relay            services.CatalogPurgeRelay
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *CatalogPurgeRelayTestSuite) SetupTest() {
+	suite.catalogEventRepo = new(mocks.MockCatalogEventRepository)
+	suite.status = http.StatusOK
+	suite.deliveries = nil
+	suite.ctx = context.Background()
+
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(catalogPurgeSecret, r.Header.Get(webhooks.TimestampHeader),
+			r.Header.Get(webhooks.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event webhooks.Event
+		_ = json.Unmarshal(body, &event)
+		suite.deliveries = append(suite.deliveries, event)
+		w.WriteHeader(suite.status)
+	}))
+
+	suite.relay = services.NewCatalogPurgeRelay(
+		services.CatalogPurgeRelaySettings{URL: suite.server.URL, Secret: catalogPurgeSecret},
+		suite.catalogEventRepo,
+		webhooks.NewClient(5*time.Second, "easy-orders-test"),
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *CatalogPurgeRelayTestSuite) TearDownTest() {
+	suite.server.Close()
+	suite.catalogEventRepo.AssertExpectations(suite.T())
+}
+
+// catalogEvents returns price change events with the given sequences
+func catalogEvents(sequences ...int64) []*models.CatalogEvent {
+	events := make([]*models.CatalogEvent, len(sequences))
+	for i, sequence := range sequences {
+		events[i] = &models.CatalogEvent{
+			Sequence:  sequence,
+			ProductID: "product-1",
+			SKU:       "SKU-1",
+			Type:      models.CatalogEventPriceChanged,
+		}
+	}
+	return events
+}
+
+// Test RelayCatalogEvents - A batch is posted signed and the cursor moves past it
+func (suite *CatalogPurgeRelayTestSuite) TestRelayCatalogEvents_PostsBatch() {
+	// Mock expectations
+	suite.catalogEventRepo.On("GetCursor", suite.ctx, "catalog_purge").Return(int64(10), true, nil)
+	suite.catalogEventRepo.On("ListSince", suite.ctx, int64(10), mock.Anything, mock.Anything).Return(catalogEvents(11, 13), nil)
+	suite.catalogEventRepo.On("SaveCursor", suite.ctx, "catalog_purge", int64(13)).Return(nil)
+
+	// Execute
+	relayed, err := suite.relay.RelayCatalogEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, relayed)
+	require.Len(suite.T(), suite.deliveries, 1)
+	assert.Equal(suite.T(), "catalog.changed", suite.deliveries[0].Type)
+	assert.Equal(suite.T(), "catalog-11-13", suite.deliveries[0].ID)
+
+	var page services.CatalogChangesResponse
+	require.NoError(suite.T(), json.Unmarshal(suite.deliveries[0].Data, &page))
+	assert.Len(suite.T(), page.Events, 2)
+	assert.Equal(suite.T(), "product.price_changed", page.Events[0].Type)
+	assert.Equal(suite.T(), "13", page.NextCursor)
+}
+
+// Test RelayCatalogEvents - The cursor stays put when the webhook fails, so the batch
+// is posted again on the next run
+func (suite *CatalogPurgeRelayTestSuite) TestRelayCatalogEvents_ServerErrorKeepsCursor() {
+	suite.status = http.StatusServiceUnavailable
+
+	// Mock expectations
+	suite.catalogEventRepo.On("GetCursor", suite.ctx, "catalog_purge").Return(int64(10), true, nil)
+	suite.catalogEventRepo.On("ListSince", suite.ctx, int64(10), mock.Anything, mock.Anything).Return(catalogEvents(11), nil)
+
+	// Execute
+	relayed, err := suite.relay.RelayCatalogEvents(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 0, relayed)
+	suite.catalogEventRepo.AssertNotCalled(suite.T(), "SaveCursor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RelayCatalogEvents - A batch the webhook rejects is skipped rather than holding
+// back later purges
+func (suite *CatalogPurgeRelayTestSuite) TestRelayCatalogEvents_RejectedBatchSkipped() {
+	suite.status = http.StatusBadRequest
+
+	// Mock expectations
+	suite.catalogEventRepo.On("GetCursor", suite.ctx, "catalog_purge").Return(int64(10), true, nil)
+	suite.catalogEventRepo.On("ListSince", suite.ctx, int64(10), mock.Anything, mock.Anything).Return(catalogEvents(11, 12), nil)
+	suite.catalogEventRepo.On("SaveCursor", suite.ctx, "catalog_purge", int64(12)).Return(nil)
+
+	// Execute
+	relayed, err := suite.relay.RelayCatalogEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, relayed)
+}
+
+// Test RelayCatalogEvents - The first run starts from the latest event without posting
+func (suite *CatalogPurgeRelayTestSuite) TestRelayCatalogEvents_FirstRunStartsAtLatest() {
+	// Mock expectations
+	suite.catalogEventRepo.On("GetCursor", suite.ctx, "catalog_purge").Return(int64(0), false, nil)
+	suite.catalogEventRepo.On("LatestSequence", suite.ctx).Return(int64(42), nil)
+	suite.catalogEventRepo.On("SaveCursor", suite.ctx, "catalog_purge", int64(42)).Return(nil)
+
+	// Execute
+	relayed, err := suite.relay.RelayCatalogEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, relayed)
+	assert.Empty(suite.T(), suite.deliveries)
+}
+
+// Test RelayCatalogEvents - The cursor stays put when the events cannot be read
+func (suite *CatalogPurgeRelayTestSuite) TestRelayCatalogEvents_ListFailureKeepsCursor() {
+	// Mock expectations
+	suite.catalogEventRepo.On("GetCursor", suite.ctx, "catalog_purge").Return(int64(10), true, nil)
+	suite.catalogEventRepo.On("ListSince", suite.ctx, int64(10), mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	// Execute
+	_, err := suite.relay.RelayCatalogEvents(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.deliveries)
+	suite.catalogEventRepo.AssertNotCalled(suite.T(), "SaveCursor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCatalogPurgeRelayTestSuite runs the test suite
+func TestCatalogPurgeRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(CatalogPurgeRelayTestSuite))
+}
@@ -0,0 +1,234 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ChangeFeedServiceTestSuite defines the test suite for ChangeFeedService
+type ChangeFeedServiceTestSuite struct {
+	suite.Suite
+	changeFeedService  services.ChangeFeedService
+	inventoryEventRepo *mocks.MockInventoryEventRepository
+	orderEventRepo     *mocks.MockOrderEventRepository
+	catalogEventRepo   *mocks.MockCatalogEventRepository
+	ctx                context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ChangeFeedServiceTestSuite) SetupTest() {
+	suite.inventoryEventRepo = new(mocks.MockInventoryEventRepository)
+	suite.orderEventRepo = new(mocks.MockOrderEventRepository)
+	suite.catalogEventRepo = new(mocks.MockCatalogEventRepository)
+	suite.ctx = context.Background()
+
+	suite.changeFeedService = services.NewChangeFeedService(
+		services.ChangeFeedSettings{SettleDelay: 5 * time.Second},
+		suite.inventoryEventRepo,
+		suite.orderEventRepo,
+		suite.catalogEventRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ChangeFeedServiceTestSuite) TearDownTest() {
+	suite.inventoryEventRepo.AssertExpectations(suite.T())
+	suite.orderEventRepo.AssertExpectations(suite.T())
+	suite.catalogEventRepo.AssertExpectations(suite.T())
+}
+
+// inventoryEvents returns reservation events with the given sequences
+func inventoryEvents(sequences ...int64) []*models.InventoryEvent {
+	events := make([]*models.InventoryEvent, len(sequences))
+	for i, sequence := range sequences {
+		events[i] = &models.InventoryEvent{
+			Sequence:  sequence,
+			ProductID: "product-1",
+			Type:      models.InventoryEventReserved,
+			Quantity:  2,
+			OnHand:    10,
+			Reserved:  2,
+			Available: 8,
+			Version:   int(sequence),
+		}
+	}
+	return events
+}
+
+// Test InventoryChanges - First page starts from the oldest event and reports more
+func (suite *ChangeFeedServiceTestSuite) TestInventoryChanges_FirstPage() {
+	// One event past the limit tells that more are ready
+	suite.inventoryEventRepo.On("ListSince", suite.ctx, int64(0), 5*time.Second, 3).
+		Return(inventoryEvents(1, 2, 4), nil)
+
+	response, err := suite.changeFeedService.InventoryChanges(suite.ctx, services.ChangeFeedRequest{Limit: 2})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.HasMore)
+	assert.Len(suite.T(), response.Events, 2)
+	assert.Equal(suite.T(), "1", response.Events[0].Cursor)
+	assert.Equal(suite.T(), "reserved", response.Events[0].Type)
+	assert.Equal(suite.T(), 8, response.Events[0].Available)
+	assert.Equal(suite.T(), "2", response.NextCursor)
+}
+
+// Test InventoryChanges - Resuming from a cursor with the default page size
+func (suite *ChangeFeedServiceTestSuite) TestInventoryChanges_Resume() {
+	suite.inventoryEventRepo.On("ListSince", suite.ctx, int64(2), 5*time.Second, 101).
+		Return(inventoryEvents(4), nil)
+
+	response, err := suite.changeFeedService.InventoryChanges(suite.ctx, services.ChangeFeedRequest{Since: "2"})
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), response.HasMore)
+	assert.Len(suite.T(), response.Events, 1)
+	assert.Equal(suite.T(), "4", response.NextCursor)
+}
+
+// Test InventoryChanges - No new events keeps the cursor
+func (suite *ChangeFeedServiceTestSuite) TestInventoryChanges_NoEvents() {
+	suite.inventoryEventRepo.On("ListSince", suite.ctx, int64(7), 5*time.Second, 101).
+		Return([]*models.InventoryEvent{}, nil)
+
+	response, err := suite.changeFeedService.InventoryChanges(suite.ctx, services.ChangeFeedRequest{Since: "7"})
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), response.Events)
+	assert.NotNil(suite.T(), response.Events)
+	assert.Equal(suite.T(), "7", response.NextCursor)
+}
+
+// Test InventoryChanges - Invalid cursor
+func (suite *ChangeFeedServiceTestSuite) TestInventoryChanges_InvalidCursor() {
+	response, err := suite.changeFeedService.InventoryChanges(suite.ctx, services.ChangeFeedRequest{Since: "abc"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+	suite.inventoryEventRepo.AssertNotCalled(suite.T(), "ListSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test InventoryChanges - Repository Error
+func (suite *ChangeFeedServiceTestSuite) TestInventoryChanges_RepositoryError() {
+	suite.inventoryEventRepo.On("ListSince", suite.ctx, int64(0), 5*time.Second, 101).
+		Return(nil, errors.New("database error"))
+
+	response, err := suite.changeFeedService.InventoryChanges(suite.ctx, services.ChangeFeedRequest{})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+}
+
+// Test OrderChanges - Payloads are returned as written with their version
+func (suite *ChangeFeedServiceTestSuite) TestOrderChanges_Success() {
+	events := []*models.OrderEvent{
+		{
+			Sequence:       3,
+			OrderID:        "order-1",
+			Type:           models.OrderEventCreated,
+			PayloadVersion: 1,
+			Payload:        json.RawMessage(`{"order_id":"order-1","status":"pending"}`),
+		},
+		{
+			Sequence:       5,
+			OrderID:        "order-1",
+			Type:           models.OrderEventStatusChanged,
+			PayloadVersion: 1,
+			Payload:        json.RawMessage(`{"order_id":"order-1","status":"paid","previous_status":"confirmed"}`),
+		},
+	}
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(2), 5*time.Second, 101).Return(events, nil)
+
+	response, err := suite.changeFeedService.OrderChanges(suite.ctx, services.ChangeFeedRequest{Since: "2"})
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), response.HasMore)
+	assert.Len(suite.T(), response.Events, 2)
+	assert.Equal(suite.T(), "order.created", response.Events[0].Type)
+	assert.Equal(suite.T(), 1, response.Events[1].PayloadVersion)
+	assert.JSONEq(suite.T(), `{"order_id":"order-1","status":"paid","previous_status":"confirmed"}`, string(response.Events[1].Payload))
+	assert.Equal(suite.T(), "5", response.NextCursor)
+}
+
+// Test OrderChanges - Invalid cursor
+func (suite *ChangeFeedServiceTestSuite) TestOrderChanges_InvalidCursor() {
+	response, err := suite.changeFeedService.OrderChanges(suite.ctx, services.ChangeFeedRequest{Since: "-1"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// Test CatalogChanges - Events name the product and what changed about it
+func (suite *ChangeFeedServiceTestSuite) TestCatalogChanges_Success() {
+	events := []*models.CatalogEvent{
+		{Sequence: 3, ProductID: "product-1", SKU: "SKU-1", Type: models.CatalogEventPriceChanged},
+		{Sequence: 4, ProductID: "product-2", SKU: "SKU-2", Type: models.CatalogEventAvailabilityChanged},
+		{Sequence: 6, ProductID: "product-1", SKU: "SKU-1", Type: models.CatalogEventContentChanged},
+	}
+	suite.catalogEventRepo.On("ListSince", suite.ctx, int64(2), 5*time.Second, 3).Return(events, nil)
+
+	response, err := suite.changeFeedService.CatalogChanges(suite.ctx, services.ChangeFeedRequest{Since: "2", Limit: 2})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.HasMore)
+	assert.Len(suite.T(), response.Events, 2)
+	assert.Equal(suite.T(), "product.price_changed", response.Events[0].Type)
+	assert.Equal(suite.T(), "SKU-2", response.Events[1].SKU)
+	assert.Equal(suite.T(), "4", response.NextCursor)
+}
+
+// Test CatalogChanges - Invalid cursor
+func (suite *ChangeFeedServiceTestSuite) TestCatalogChanges_InvalidCursor() {
+	response, err := suite.changeFeedService.CatalogChanges(suite.ctx, services.ChangeFeedRequest{Since: "next"})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// Test CatalogChanges - Only storefront fields of a product update record changes
+func (suite *ChangeFeedServiceTestSuite) TestCatalogChanges_ProductUpdates() {
+	previous := &models.Product{
+		Name:     "Lamp",
+		Price:    20,
+		IsActive: true,
+		Metadata: models.Metadata{"color": "red"},
+	}
+
+	stockOnly := *previous
+	stockOnly.CostPrice = 12
+	stockOnly.LeadTimeDays = 5
+	assert.Empty(suite.T(), models.CatalogChanges(previous, &stockOnly))
+
+	repriced := *previous
+	repriced.Price = 18
+	repriced.Metadata = models.Metadata{"color": "blue"}
+	assert.Equal(suite.T(), []models.CatalogEventType{
+		models.CatalogEventPriceChanged,
+		models.CatalogEventContentChanged,
+	}, models.CatalogChanges(previous, &repriced))
+
+	deactivated := *previous
+	deactivated.IsActive = false
+	assert.Equal(suite.T(), []models.CatalogEventType{models.CatalogEventAvailabilityChanged},
+		models.CatalogChanges(previous, &deactivated))
+}
+
+// TestChangeFeedServiceTestSuite runs the test suite
+func TestChangeFeedServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ChangeFeedServiceTestSuite))
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/channels"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ChannelServiceTestSuite defines the test suite for ChannelService
+type ChannelServiceTestSuite struct {
+	suite.Suite
+	channelService services.ChannelService
+	mockConnector  *channels.MockConnector
+	listingRepo    *mocks.MockChannelListingRepository
+	orderRepo      *mocks.MockOrderRepository
+	productRepo    *mocks.MockProductRepository
+	userRepo       *mocks.MockUserRepository
+	logger         *logger.Logger
+	ctx            context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ChannelServiceTestSuite) SetupTest() {
+	suite.listingRepo = new(mocks.MockChannelListingRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	channelManager := channels.NewChannelManager(suite.logger)
+	suite.mockConnector = channels.NewMockConnector(suite.logger)
+	channelManager.RegisterConnector(suite.mockConnector)
+
+	// Imports that reach order creation need a database, so only the
+	// mapping and idempotency paths are covered here
+	orderService := services.NewOrderService(
+		nil,
+		suite.orderRepo,
+		new(mocks.MockOrderItemRepository),
+		suite.productRepo,
+		new(mocks.MockInventoryRepository),
+		suite.userRepo,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil,
+		suite.logger,
+	)
+
+	suite.channelService = services.NewChannelService(
+		channelManager,
+		suite.listingRepo,
+		suite.orderRepo,
+		suite.productRepo,
+		suite.userRepo,
+		orderService,
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ChannelServiceTestSuite) TearDownTest() {
+	suite.listingRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.productRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test ImportOrder - Redelivered channel order returns the existing order
+func (suite *ChannelServiceTestSuite) TestImportOrder_DuplicateReturnsExistingOrder() {
+	payload := []byte(`{"order_id":"MK-1","customer_email":"buyer@example.com","items":[{"sku":"CH-SKU-1","quantity":1}]}`)
+	existing := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusPending, Channel: "mock", ExternalOrderID: "MK-1"}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByExternalID", suite.ctx, "mock", "MK-1").Return(existing, nil)
+	suite.orderRepo.On("GetByIDWithItems", suite.ctx, "order-1").Return(existing, nil)
+
+	// Execute
+	response, err := suite.channelService.ImportOrder(suite.ctx, "mock", payload)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.Duplicate)
+	assert.Equal(suite.T(), "order-1", response.Order.ID)
+	assert.Equal(suite.T(), "mock", response.Order.Channel)
+	suite.userRepo.AssertNotCalled(suite.T(), "GetByEmail", mock.Anything, mock.Anything)
+}
+
+// Test ImportOrder - Channel SKU without a listing is rejected
+func (suite *ChannelServiceTestSuite) TestImportOrder_UnmappedSKU() {
+	payload := []byte(`{"order_id":"MK-2","customer_email":"buyer@example.com","items":[{"sku":"CH-SKU-1","quantity":1},{"sku":"CH-SKU-2","quantity":2}]}`)
+	user := &models.User{ID: "user-1", Email: "buyer@example.com"}
+	listings := []*models.ChannelListing{{Channel: "mock", ChannelSKU: "CH-SKU-1", ProductID: "product-1"}}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByExternalID", suite.ctx, "mock", "MK-2").Return(nil, nil)
+	suite.userRepo.On("GetByEmail", suite.ctx, "buyer@example.com").Return(user, nil)
+	suite.listingRepo.On("GetByChannelSKUs", suite.ctx, "mock", []string{"CH-SKU-1", "CH-SKU-2"}).Return(listings, nil)
+
+	// Execute
+	response, err := suite.channelService.ImportOrder(suite.ctx, "mock", payload)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "CH-SKU-2 is not mapped")
+}
+
+// Test ImportOrder - Unknown channel
+func (suite *ChannelServiceTestSuite) TestImportOrder_UnknownChannel() {
+	// Execute
+	response, err := suite.channelService.ImportOrder(suite.ctx, "unknown", []byte(`{}`))
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+// Test SyncChannel - Pushes availability and prices for every listing
+func (suite *ChannelServiceTestSuite) TestSyncChannel_PushesInventoryAndPrices() {
+	listings := []*models.ChannelListing{
+		{
+			Channel: "mock", ChannelSKU: "CH-SKU-1", ProductID: "product-1",
+			Product: &models.Product{ID: "product-1", Price: 19.99, IsActive: true, Inventory: &models.Inventory{Available: 7}},
+		},
+		{
+			Channel: "mock", ChannelSKU: "CH-SKU-2", ProductID: "product-2",
+			Product: &models.Product{ID: "product-2", Price: 5.00, IsActive: false, Inventory: &models.Inventory{Available: 3}},
+		},
+	}
+
+	// Mock expectations
+	suite.listingRepo.On("ListByChannel", suite.ctx, "mock").Return(listings, nil)
+
+	// Execute
+	response, err := suite.channelService.SyncChannel(suite.ctx, "mock")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, response.InventoryUpdates)
+	assert.Equal(suite.T(), 2, response.PriceUpdates)
+
+	inventoryUpdates := suite.mockConnector.GetInventoryUpdates()
+	assert.Equal(suite.T(), 7, inventoryUpdates[0].Available)
+	assert.Equal(suite.T(), 0, inventoryUpdates[1].Available) // Inactive products are listed as out of stock
+	assert.Equal(suite.T(), 19.99, suite.mockConnector.GetPriceUpdates()[0].Price)
+}
+
+// TestChannelServiceTestSuite runs the test suite
+func TestChannelServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ChannelServiceTestSuite))
+}
@@ -0,0 +1,481 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// CheckoutServiceTestSuite defines the test suite for CheckoutService
+type CheckoutServiceTestSuite struct {
+	suite.Suite
+	checkoutService   services.CheckoutService
+	addressRepo       *mocks.MockAddressRepository
+	paymentMethodRepo *mocks.MockSavedPaymentMethodRepository
+	orderRepo         *mocks.MockOrderRepository
+	orderService      *mocks.MockOrderService
+	paymentService    *mocks.MockPaymentService
+	sagaRepo          *mocks.MockCheckoutSagaRepository
+	logger            *logger.Logger
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *CheckoutServiceTestSuite) SetupTest() {
+	suite.addressRepo = new(mocks.MockAddressRepository)
+	suite.paymentMethodRepo = new(mocks.MockSavedPaymentMethodRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.orderService = new(mocks.MockOrderService)
+	suite.paymentService = new(mocks.MockPaymentService)
+	suite.sagaRepo = new(mocks.MockCheckoutSagaRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.checkoutService = services.NewCheckoutService(
+		suite.addressRepo,
+		suite.paymentMethodRepo,
+		suite.orderRepo,
+		suite.orderService,
+		suite.paymentService,
+		suite.sagaRepo,
+		services.CheckoutSagaSettings{StaleAfter: 5 * time.Minute},
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *CheckoutServiceTestSuite) TearDownTest() {
+	suite.addressRepo.AssertExpectations(suite.T())
+	suite.paymentMethodRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.orderService.AssertExpectations(suite.T())
+	suite.paymentService.AssertExpectations(suite.T())
+	suite.sagaRepo.AssertExpectations(suite.T())
+}
+
+// expectDefaults sets up the user's default address and payment method
+func (suite *CheckoutServiceTestSuite) expectDefaults() (*models.Address, *models.SavedPaymentMethod) {
+	address := &models.Address{
+		ID:     "address-1",
+		UserID: "user-1",
+		ShippingAddress: models.ShippingAddress{
+			Recipient:  "Jane Doe",
+			Line1:      "1 Main St",
+			City:       "Cairo",
+			PostalCode: "11511",
+			Country:    "EG",
+		},
+		IsDefault: true,
+	}
+	method := &models.SavedPaymentMethod{
+		ID:           "method-1",
+		UserID:       "user-1",
+		Method:       models.PaymentMethodCreditCard,
+		GatewayToken: "tok_123",
+		IsDefault:    true,
+	}
+	suite.addressRepo.On("GetDefault", suite.ctx, "user-1").Return(address, nil)
+	suite.paymentMethodRepo.On("GetDefault", suite.ctx, "user-1").Return(method, nil)
+	return address, method
+}
+
+// expectSaga records the checkout saga as it is started and saved, returning it
+func (suite *CheckoutServiceTestSuite) expectSaga() *models.CheckoutSaga {
+	saga := &models.CheckoutSaga{}
+	suite.sagaRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.CheckoutSaga")).
+		Run(func(args mock.Arguments) {
+			created := args.Get(1).(*models.CheckoutSaga)
+			created.ID = "saga-1"
+			*saga = *created
+		}).Return(nil)
+	suite.sagaRepo.On("Update", suite.ctx, mock.AnythingOfType("*models.CheckoutSaga")).
+		Run(func(args mock.Arguments) {
+			*saga = *args.Get(1).(*models.CheckoutSaga)
+		}).Return(nil)
+	return saga
+}
+
+func expressCheckoutRequest() services.ExpressCheckoutRequest {
+	return services.ExpressCheckoutRequest{ProductID: "product-1", Quantity: 2, IdempotencyKey: "key-1"}
+}
+
+// Test AddAddress - The first address becomes the default
+func (suite *CheckoutServiceTestSuite) TestAddAddress_FirstBecomesDefault() {
+	suite.addressRepo.On("GetDefault", suite.ctx, "user-1").Return(nil, nil)
+	suite.addressRepo.On("Create", suite.ctx, mock.MatchedBy(func(a *models.Address) bool {
+		return a.UserID == "user-1" && a.IsDefault && a.ShippingAddress.Country == "EG"
+	})).Return(nil)
+
+	// Execute
+	address, err := suite.checkoutService.AddAddress(suite.ctx, "user-1", services.CreateAddressRequest{
+		Recipient: "Jane Doe", Line1: "1 Main St", City: "Cairo", PostalCode: "11511", Country: "eg",
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.True(address.IsDefault)
+	suite.Equal("EG", address.Address.Country)
+}
+
+// Test SetDefaultPaymentMethod - Payment methods of other users are not found
+func (suite *CheckoutServiceTestSuite) TestSetDefaultPaymentMethod_OtherUser() {
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-2").
+		Return(&models.SavedPaymentMethod{ID: "method-2", UserID: "user-2"}, nil)
+
+	// Execute
+	method, err := suite.checkoutService.SetDefaultPaymentMethod(suite.ctx, "user-1", "method-2")
+
+	// Assert
+	suite.Nil(method)
+	suite.ErrorContains(err, "NOT_FOUND")
+	suite.paymentMethodRepo.AssertNotCalled(suite.T(), "SetDefault", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ExpressCheckout - The order ships to the default address and is paid with the default method
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_Paid() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+	suite.expectDefaults()
+	saga := suite.expectSaga()
+
+	suite.orderService.On("CreateOrder", suite.ctx, mock.MatchedBy(func(req services.CreateOrderRequest) bool {
+		return req.UserID == "user-1" &&
+			len(req.Items) == 1 && req.Items[0].ProductID == "product-1" && req.Items[0].Quantity == 2 &&
+			req.PaymentMethod == models.PaymentMethodCreditCard &&
+			req.ShippingAddress != nil && req.ShippingAddress.City == "Cairo" &&
+			req.IdempotencyKey == "key-1"
+	})).Return(&services.OrderResponse{ID: "order-1", UserID: "user-1", Status: models.OrderStatusPending, Total: 50}, nil)
+
+	suite.paymentService.On("ProcessPayment", suite.ctx, services.ProcessPaymentRequest{
+		OrderID:           "order-1",
+		Amount:            50,
+		PaymentType:       "credit_card",
+		ExternalReference: "saved_payment_method:method-1",
+	}).Return(&services.PaymentResponse{ID: "payment-1", OrderID: "order-1", Amount: 50, Status: models.PaymentStatusCompleted}, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(services.ExpressCheckoutPaid, checkout.Outcome)
+	suite.Equal(models.OrderStatusPaid, checkout.Order.Status)
+	suite.Equal("payment-1", checkout.Payment.ID)
+	suite.False(checkout.Replayed)
+	suite.Equal(models.CheckoutSagaStatusCompleted, saga.Status)
+	suite.Equal(services.CheckoutStepChargePayment, saga.Step)
+	suite.Equal("order-1", *saga.OrderID)
+	suite.Equal("method-1", saga.PaymentMethodID)
+}
+
+// Test ExpressCheckout - A failed payment keeps the placed order and reports the failure
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_PaymentFailedKeepsOrder() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+	suite.expectDefaults()
+	saga := suite.expectSaga()
+	suite.orderService.On("CreateOrder", suite.ctx, mock.Anything).
+		Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 50}, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, mock.Anything).
+		Return(nil, errors.New("payment processing failed: card declined"))
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(services.ExpressCheckoutPaymentFailed, checkout.Outcome)
+	suite.Equal("order-1", checkout.Order.ID)
+	suite.Equal(models.OrderStatusPending, checkout.Order.Status)
+	suite.Nil(checkout.Payment)
+	suite.Contains(checkout.PaymentError, "card declined")
+	suite.Equal(models.CheckoutSagaStatusCompleted, saga.Status)
+}
+
+// Test ExpressCheckout - A held payment is reported as held
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_PaymentHeld() {
+	retryAt := time.Now().Add(time.Minute)
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+	suite.expectDefaults()
+	suite.expectSaga()
+	suite.orderService.On("CreateOrder", suite.ctx, mock.Anything).
+		Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 50}, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, mock.Anything).
+		Return(&services.PaymentResponse{ID: "payment-1", Status: models.PaymentStatusHeld, RetryAt: &retryAt}, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(services.ExpressCheckoutPaymentHeld, checkout.Outcome)
+	suite.Equal(models.OrderStatusPending, checkout.Order.Status)
+}
+
+// Test ExpressCheckout - A retry with the same key returns the order without placing or charging anything
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_ReplaysSameKey() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(&models.Order{
+		ID:          "order-1",
+		UserID:      "user-1",
+		Status:      models.OrderStatusPaid,
+		TotalAmount: 50,
+		Items:       []models.OrderItem{{ProductID: "product-1", Quantity: 2, UnitPrice: 25}},
+		Payments: []models.Payment{
+			{ID: "payment-1", OrderID: "order-1", Amount: 50, Status: models.PaymentStatusFailed, FailureReason: "card declined"},
+			{ID: "payment-2", OrderID: "order-1", Amount: 50, Status: models.PaymentStatusCompleted},
+		},
+	}, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.NoError(err)
+	suite.True(checkout.Replayed)
+	suite.Equal(services.ExpressCheckoutPaid, checkout.Outcome)
+	suite.Equal("payment-2", checkout.Payment.ID)
+	suite.Empty(checkout.PaymentError)
+	suite.orderService.AssertNotCalled(suite.T(), "CreateOrder", mock.Anything, mock.Anything)
+	suite.paymentService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+}
+
+// Test ExpressCheckout - Reusing a key for a different checkout is a conflict
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_KeyReusedForDifferentCheckout() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(&models.Order{
+		ID:    "order-1",
+		Items: []models.OrderItem{{ProductID: "product-1", Quantity: 1}},
+	}, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.Nil(checkout)
+	suite.ErrorContains(err, "CONFLICT")
+}
+
+// Test ExpressCheckout - A concurrent request that placed the order first is replayed
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_ConcurrentRequestReplayed() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil).Once()
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(&models.Order{
+		ID:     "order-1",
+		Status: models.OrderStatusPending,
+		Items:  []models.OrderItem{{ProductID: "product-1", Quantity: 2}},
+	}, nil).Once()
+	suite.expectDefaults()
+	saga := suite.expectSaga()
+	suite.orderService.On("CreateOrder", suite.ctx, mock.Anything).
+		Return(nil, errors.New("duplicate key value violates unique constraint \"idx_orders_user_idempotency_key\""))
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.NoError(err)
+	suite.True(checkout.Replayed)
+	suite.Equal(services.ExpressCheckoutPaymentPending, checkout.Outcome)
+	suite.paymentService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+	suite.Equal(models.CheckoutSagaStatusFailed, saga.Status)
+	suite.Contains(saga.LastError, "duplicate key")
+}
+
+// Test ExpressCheckout - Nothing is placed without a default address
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_NoDefaultAddress() {
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+	suite.addressRepo.On("GetDefault", suite.ctx, "user-1").Return(nil, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.Nil(checkout)
+	suite.ErrorContains(err, "no default shipping address")
+	suite.orderService.AssertNotCalled(suite.T(), "CreateOrder", mock.Anything, mock.Anything)
+}
+
+// Test ExpressCheckout - An expired default payment method is refused before placing the order
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_ExpiredPaymentMethod() {
+	expired := time.Now().Add(-time.Hour)
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+	suite.addressRepo.On("GetDefault", suite.ctx, "user-1").Return(&models.Address{ID: "address-1", UserID: "user-1"}, nil)
+	suite.paymentMethodRepo.On("GetDefault", suite.ctx, "user-1").
+		Return(&models.SavedPaymentMethod{ID: "method-1", UserID: "user-1", Method: models.PaymentMethodCreditCard, ExpiresAt: &expired}, nil)
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", expressCheckoutRequest())
+
+	// Assert
+	suite.Nil(checkout)
+	suite.ErrorContains(err, "has expired")
+	suite.orderService.AssertNotCalled(suite.T(), "CreateOrder", mock.Anything, mock.Anything)
+}
+
+// Test ExpressCheckout - The idempotency key is required
+func (suite *CheckoutServiceTestSuite) TestExpressCheckout_RequiresIdempotencyKey() {
+	req := expressCheckoutRequest()
+	req.IdempotencyKey = ""
+
+	// Execute
+	checkout, err := suite.checkoutService.ExpressCheckout(suite.ctx, "user-1", req)
+
+	// Assert
+	suite.Nil(checkout)
+	suite.ErrorContains(err, "idempotency key is required")
+}
+
+// staleSaga returns a checkout saga stopped at the given step
+func staleSaga(step string, orderID *string) *models.CheckoutSaga {
+	return &models.CheckoutSaga{
+		ID:              "saga-1",
+		UserID:          "user-1",
+		IdempotencyKey:  "key-1",
+		ProductID:       "product-1",
+		Quantity:        2,
+		PaymentMethodID: "method-1",
+		OrderID:         orderID,
+		Step:            step,
+		Status:          models.CheckoutSagaStatusRunning,
+		UpdatedAt:       time.Now().Add(-time.Hour),
+	}
+}
+
+// expectRecovery lists the saga as stale and lets this instance claim it
+func (suite *CheckoutServiceTestSuite) expectRecovery(saga *models.CheckoutSaga) {
+	suite.sagaRepo.On("ListStale", suite.ctx, mock.AnythingOfType("time.Time"), 50).Return([]*models.CheckoutSaga{saga}, nil)
+	suite.sagaRepo.On("Claim", suite.ctx, saga, mock.AnythingOfType("time.Time")).Return(true, nil)
+	suite.sagaRepo.On("Update", suite.ctx, saga).Return(nil)
+}
+
+// Test RecoverCheckouts - A checkout stopped after placing its order charges it
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_ResumesCharge() {
+	orderID := "order-1"
+	saga := staleSaga(services.CheckoutStepPlaceOrder, nil)
+	suite.expectRecovery(saga)
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(&models.Order{ID: orderID}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).
+		Return(&models.Order{ID: orderID, UserID: "user-1", Status: models.OrderStatusPending, TotalAmount: 50}, nil)
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-1").
+		Return(&models.SavedPaymentMethod{ID: "method-1", UserID: "user-1", Method: models.PaymentMethodCreditCard}, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, services.ProcessPaymentRequest{
+		OrderID:           orderID,
+		Amount:            50,
+		PaymentType:       "credit_card",
+		ExternalReference: "saved_payment_method:method-1",
+	}).Return(&services.PaymentResponse{ID: "payment-1", Status: models.PaymentStatusCompleted}, nil)
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, recovered)
+	suite.Equal(models.CheckoutSagaStatusCompleted, saga.Status)
+	suite.Equal(services.CheckoutStepChargePayment, saga.Step)
+	suite.Equal(orderID, *saga.OrderID)
+}
+
+// Test RecoverCheckouts - An order paid since the checkout stopped is not charged again
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_OrderAlreadyPaid() {
+	orderID := "order-1"
+	saga := staleSaga(services.CheckoutStepChargePayment, &orderID)
+	suite.expectRecovery(saga)
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(&models.Order{
+		ID:       orderID,
+		Status:   models.OrderStatusPending,
+		Payments: []models.Payment{{ID: "payment-1", Status: models.PaymentStatusPending}},
+	}, nil)
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, recovered)
+	suite.Equal(models.CheckoutSagaStatusCompleted, saga.Status)
+	suite.paymentService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+}
+
+// Test RecoverCheckouts - An order that can no longer be charged is cancelled
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_CompensatesOrder() {
+	orderID := "order-1"
+	expired := time.Now().Add(-time.Hour)
+	saga := staleSaga(services.CheckoutStepChargePayment, &orderID)
+	suite.expectRecovery(saga)
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).
+		Return(&models.Order{ID: orderID, Status: models.OrderStatusPending, TotalAmount: 50}, nil)
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-1").
+		Return(&models.SavedPaymentMethod{ID: "method-1", UserID: "user-1", ExpiresAt: &expired}, nil)
+	suite.orderService.On("CancelOrder", suite.ctx, orderID).Return(nil)
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, recovered)
+	suite.Equal(models.CheckoutSagaStatusCompensated, saga.Status)
+	suite.Contains(saga.LastError, "has expired")
+}
+
+// Test RecoverCheckouts - A failed compensation leaves the checkout to be recovered again
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_CompensationFails() {
+	orderID := "order-1"
+	saga := staleSaga(services.CheckoutStepChargePayment, &orderID)
+	suite.expectRecovery(saga)
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).
+		Return(&models.Order{ID: orderID, Status: models.OrderStatusPending, TotalAmount: 50}, nil)
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-1").Return(nil, nil)
+	suite.orderService.On("CancelOrder", suite.ctx, orderID).Return(errors.New("database error"))
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(0, recovered)
+	suite.Equal(models.CheckoutSagaStatusRunning, saga.Status)
+	suite.Contains(saga.LastError, "compensating place_order")
+}
+
+// Test RecoverCheckouts - A checkout stopped before placing its order fails without placing it
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_OrderNotPlaced() {
+	saga := staleSaga(services.CheckoutStepPlaceOrder, nil)
+	suite.expectRecovery(saga)
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, "user-1", "key-1").Return(nil, nil)
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, recovered)
+	suite.Equal(models.CheckoutSagaStatusFailed, saga.Status)
+	suite.orderService.AssertNotCalled(suite.T(), "CreateOrder", mock.Anything, mock.Anything)
+}
+
+// Test RecoverCheckouts - A checkout claimed by another instance is left to it
+func (suite *CheckoutServiceTestSuite) TestRecoverCheckouts_ClaimedElsewhere() {
+	saga := staleSaga(services.CheckoutStepPlaceOrder, nil)
+	suite.sagaRepo.On("ListStale", suite.ctx, mock.AnythingOfType("time.Time"), 50).Return([]*models.CheckoutSaga{saga}, nil)
+	suite.sagaRepo.On("Claim", suite.ctx, saga, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// Execute
+	recovered, err := suite.checkoutService.RecoverCheckouts(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(0, recovered)
+	suite.Equal(models.CheckoutSagaStatusRunning, saga.Status)
+}
+
+// Run the test suite
+func TestCheckoutServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CheckoutServiceTestSuite))
+}
@@ -0,0 +1,176 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// closeReports serves canned reports for close reports
+type closeReports struct {
+	services.ReportService
+	salesErr error
+}
+
+func (r *closeReports) GenerateDailySalesReport(ctx context.Context, date string, lowMarginThreshold float64) (*services.SalesReportResponse, error) {
+	if r.salesErr != nil {
+		return nil, r.salesErr
+	}
+	return &services.SalesReportResponse{
+		Date:            date,
+		TotalSales:      500,
+		TotalOrders:     10,
+		OrdersByStatus:  map[string]int{"paid": 6, "shipped": 3, "cancelled": 1},
+		OrdersByChannel: map[string]int{"shopify": 4},
+		SalesByChannel:  map[string]float64{"shopify": 120},
+	}, nil
+}
+
+func (r *closeReports) GeneratePaymentFailureReport(ctx context.Context, req services.PaymentFailureReportRequest) (*services.PaymentFailureReportResponse, error) {
+	return &services.PaymentFailureReportResponse{
+		Totals: services.PaymentFailureStats{Attempts: 20, Failures: 2, FailureRate: 10},
+	}, nil
+}
+
+func (r *closeReports) GenerateShippingSLAReport(ctx context.Context) (*services.ShippingSLAReportResponse, error) {
+	return &services.ShippingSLAReportResponse{
+		Totals: services.ShippingSLATotals{Overdue: 5, DueSoon: 2},
+		Stores: []services.ShippingSLAStore{{Channel: "shopify", Overdue: 1, DueSoon: 1}},
+	}, nil
+}
+
+// closeReportInventory serves a canned low-stock alert for close reports
+type closeReportInventory struct {
+	services.InventoryService
+}
+
+func (i *closeReportInventory) GetProductLowStockAlert(ctx context.Context) (*services.LowStockResponse, error) {
+	return &services.LowStockResponse{
+		PerProduct: true,
+		Products:   []services.ProductLowStock{{ProductID: "product-1", ProductName: "Widget"}},
+		Count:      1,
+	}, nil
+}
+
+// CloseReportServiceTestSuite defines the test suite for CloseReportService
+type CloseReportServiceTestSuite struct {
+	suite.Suite
+	reports       *closeReports
+	closeRepo     *mocks.MockCloseReportRepository
+	userRepo      *mocks.MockUserRepository
+	notifications *sentNotifications
+	service       services.CloseReportService
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *CloseReportServiceTestSuite) SetupTest() {
+	suite.reports = &closeReports{}
+	suite.closeRepo = new(mocks.MockCloseReportRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.notifications = &sentNotifications{}
+	suite.ctx = context.Background()
+
+	// The default store is always due, shopify never is
+	settings, err := services.NewCloseReportSettings("UTC", map[string]string{"*": "00:00", "shopify": "24:00"},
+		[]string{"manager@example.com", "gone@example.com"})
+	suite.Require().NoError(err)
+
+	suite.service = services.NewCloseReportService(settings, suite.reports, &closeReportInventory{},
+		suite.closeRepo, suite.userRepo, suite.notifications, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *CloseReportServiceTestSuite) TearDownTest() {
+	suite.closeRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test SendDue - Due reports are claimed and emailed to the recipients with an account
+func (suite *CloseReportServiceTestSuite) TestSendDue_SendsDueReports() {
+	// Mock expectations
+	suite.closeRepo.On("Claim", suite.ctx, "*", mock.Anything, mock.Anything).Return(true, nil)
+	suite.userRepo.On("GetByEmail", suite.ctx, "manager@example.com").Return(&models.User{ID: "manager-1"}, nil)
+	suite.userRepo.On("GetByEmail", suite.ctx, "gone@example.com").Return(nil, nil)
+
+	// Execute
+	sent, err := suite.service.SendDue(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, sent)
+	suite.Require().Len(suite.notifications.sent, 1)
+	email := suite.notifications.sent[0]
+	assert.Equal(suite.T(), "manager-1", email.UserID)
+	assert.Equal(suite.T(), string(models.NotificationTypeCloseReport), email.Type)
+	assert.Equal(suite.T(), string(models.NotificationChannelEmail), email.Channel)
+	assert.Contains(suite.T(), email.Title, "all stores")
+	assert.Contains(suite.T(), email.Body, "Sales: 500.00 from 10 orders (average 50.00)")
+	assert.Contains(suite.T(), email.Body, "cancelled 1 paid 6 shipped 3")
+	assert.Contains(suite.T(), email.Body, "Payment failures: 2 of 20 attempts (10.0%)")
+	assert.Contains(suite.T(), email.Body, "Low stock: 1 products (Widget)")
+	assert.Contains(suite.T(), email.Body, "5 orders overdue")
+}
+
+// Test SendDue - Reports already sent today are not sent again
+func (suite *CloseReportServiceTestSuite) TestSendDue_AlreadySent() {
+	suite.closeRepo.On("Claim", suite.ctx, "*", mock.Anything, mock.Anything).Return(false, nil)
+
+	sent, err := suite.service.SendDue(suite.ctx)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, sent)
+	assert.Empty(suite.T(), suite.notifications.sent)
+}
+
+// Test SendDue - A report that cannot be composed is released to be retried
+func (suite *CloseReportServiceTestSuite) TestSendDue_ComposeFailureReleasesClaim() {
+	suite.reports.salesErr = errors.New("database error")
+
+	// Mock expectations
+	suite.closeRepo.On("Claim", suite.ctx, "*", mock.Anything, mock.Anything).Return(true, nil)
+	suite.closeRepo.On("Release", suite.ctx, "*", mock.Anything).Return(nil)
+
+	// Execute
+	sent, err := suite.service.SendDue(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), 0, sent)
+	assert.Empty(suite.T(), suite.notifications.sent)
+}
+
+// Test Compose - A store's report has its own sales and SLA breaches
+func (suite *CloseReportServiceTestSuite) TestCompose_Store() {
+	report, err := suite.service.Compose(suite.ctx, "shopify", "2026-03-14")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, report.Orders)
+	assert.Equal(suite.T(), 120.0, report.Sales)
+	assert.Equal(suite.T(), 30.0, report.AverageOrderValue)
+	assert.Equal(suite.T(), 1, report.SLAOverdue)
+	assert.Equal(suite.T(), 10, report.OrdersByStatus["paid"]+report.OrdersByStatus["shipped"]+report.OrdersByStatus["cancelled"])
+}
+
+// Test NewCloseReportSettings - Invalid times and timezones are rejected
+func (suite *CloseReportServiceTestSuite) TestNewCloseReportSettings_Invalid() {
+	_, err := services.NewCloseReportSettings("UTC", map[string]string{"*": "9pm"}, nil)
+	assert.Error(suite.T(), err)
+
+	_, err = services.NewCloseReportSettings("Mars/Olympus", map[string]string{"*": "21:00"}, nil)
+	assert.Error(suite.T(), err)
+}
+
+// TestCloseReportServiceTestSuite runs the test suite
+func TestCloseReportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CloseReportServiceTestSuite))
+}
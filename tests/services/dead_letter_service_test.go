@@ -0,0 +1,172 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// DeadLetterServiceTestSuite defines the test suite for DeadLetterService
+type DeadLetterServiceTestSuite struct {
+	suite.Suite
+	deadLetterService services.DeadLetterService
+	jobRepo           *mocks.MockBackgroundJobRepository
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *DeadLetterServiceTestSuite) SetupTest() {
+	suite.jobRepo = new(mocks.MockBackgroundJobRepository)
+	suite.ctx = context.Background()
+
+	suite.deadLetterService = services.NewDeadLetterService(
+		suite.jobRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *DeadLetterServiceTestSuite) TearDownTest() {
+	suite.jobRepo.AssertExpectations(suite.T())
+}
+
+// Test ListDeadJobs - Filters by type and pool, and pages with the default limit
+func (suite *DeadLetterServiceTestSuite) TestListDeadJobs_FiltersAndPages() {
+	filter := repository.DeadJobFilter{Type: "payment_retry", Pool: "payments"}
+	jobs := []*models.DeadJob{{ID: "job-1", Type: "payment_retry", Pool: "payments", LastError: "gateway timeout"}}
+	suite.jobRepo.On("ListDeadJobs", suite.ctx, filter, 20, 20).Return(jobs, nil)
+	suite.jobRepo.On("CountDeadJobs", suite.ctx, filter).Return(int64(21), nil)
+
+	// Execute
+	response, err := suite.deadLetterService.ListDeadJobs(suite.ctx, services.ListDeadJobsRequest{
+		Page: 2, Type: "payment_retry", Pool: "payments",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), jobs, response.Jobs)
+	assert.Equal(suite.T(), 2, response.Page)
+	assert.Equal(suite.T(), 20, response.Limit)
+	assert.Equal(suite.T(), 21, response.Total)
+}
+
+// Test GetDeadJob - Returns the job with its payload
+func (suite *DeadLetterServiceTestSuite) TestGetDeadJob_Success() {
+	job := &models.DeadJob{ID: "job-1", Type: "notification", Payload: json.RawMessage(`{"recipient":"a@example.com"}`)}
+	suite.jobRepo.On("GetDeadJob", suite.ctx, "job-1").Return(job, nil)
+
+	// Execute
+	result, err := suite.deadLetterService.GetDeadJob(suite.ctx, "job-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.JSONEq(suite.T(), `{"recipient":"a@example.com"}`, string(result.Payload))
+}
+
+// Test GetDeadJob - A missing dead job is not found
+func (suite *DeadLetterServiceTestSuite) TestGetDeadJob_NotFound() {
+	suite.jobRepo.On("GetDeadJob", suite.ctx, "missing").Return(nil, nil)
+
+	// Execute
+	result, err := suite.deadLetterService.GetDeadJob(suite.ctx, "missing")
+
+	// Assert
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// Test RequeueDeadJob - The job is queued to run right away
+func (suite *DeadLetterServiceTestSuite) TestRequeueDeadJob_Success() {
+	before := time.Now()
+	suite.jobRepo.On("RequeueDeadJob", suite.ctx, "job-1", mock.MatchedBy(func(runAt time.Time) bool {
+		return !runAt.Before(before)
+	})).Return(&models.BackgroundJob{ID: "job-1", Type: "payment_retry", Pool: "payments", RunAt: before}, nil)
+
+	// Execute
+	response, err := suite.deadLetterService.RequeueDeadJob(suite.ctx, "job-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "job-1", response.ID)
+	assert.Equal(suite.T(), "payments", response.Pool)
+	assert.Equal(suite.T(), before, response.RunAt)
+}
+
+// Test RequeueDeadJob - A missing dead job is not found
+func (suite *DeadLetterServiceTestSuite) TestRequeueDeadJob_NotFound() {
+	suite.jobRepo.On("RequeueDeadJob", suite.ctx, "missing", mock.AnythingOfType("time.Time")).Return(nil, nil)
+
+	// Execute
+	response, err := suite.deadLetterService.RequeueDeadJob(suite.ctx, "missing")
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// Test DeleteDeadJob - A missing dead job is not found
+func (suite *DeadLetterServiceTestSuite) TestDeleteDeadJob_NotFound() {
+	suite.jobRepo.On("DeleteDeadJob", suite.ctx, "missing").Return(false, nil)
+
+	// Execute
+	err := suite.deadLetterService.DeleteDeadJob(suite.ctx, "missing")
+
+	// Assert
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// Test PurgeDeadJobs - Purges the jobs of a type that failed before a time
+func (suite *DeadLetterServiceTestSuite) TestPurgeDeadJobs_ByTypeAndTime() {
+	diedBefore := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	suite.jobRepo.On("PurgeDeadJobs", suite.ctx, mock.MatchedBy(func(filter repository.DeadJobFilter) bool {
+		return filter.Type == "payment_retry" && filter.DiedBefore != nil && filter.DiedBefore.Equal(diedBefore)
+	})).Return(int64(3), nil)
+
+	// Execute
+	response, err := suite.deadLetterService.PurgeDeadJobs(suite.ctx, services.PurgeDeadJobsRequest{
+		Type: "payment_retry", DiedBefore: "2025-03-01T00:00:00Z",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(3), response.Purged)
+}
+
+// Test PurgeDeadJobs - Purging without criteria is refused unless all is set
+func (suite *DeadLetterServiceTestSuite) TestPurgeDeadJobs_RequiresCriteria() {
+	// Execute
+	response, err := suite.deadLetterService.PurgeDeadJobs(suite.ctx, services.PurgeDeadJobsRequest{})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+	suite.jobRepo.AssertNotCalled(suite.T(), "PurgeDeadJobs", mock.Anything, mock.Anything)
+}
+
+// Test PurgeDeadJobs - all purges the whole queue
+func (suite *DeadLetterServiceTestSuite) TestPurgeDeadJobs_All() {
+	suite.jobRepo.On("PurgeDeadJobs", suite.ctx, repository.DeadJobFilter{}).Return(int64(7), nil)
+
+	// Execute
+	response, err := suite.deadLetterService.PurgeDeadJobs(suite.ctx, services.PurgeDeadJobsRequest{All: true})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(7), response.Purged)
+}
+
+// TestDeadLetterServiceTestSuite runs the test suite
+func TestDeadLetterServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadLetterServiceTestSuite))
+}
@@ -0,0 +1,227 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// DeliverySlotServiceTestSuite defines the test suite for DeliverySlotService
+type DeliverySlotServiceTestSuite struct {
+	suite.Suite
+	slotService services.DeliverySlotService
+	slotRepo    *mocks.MockDeliverySlotRepository
+	logger      *logger.Logger
+	ctx         context.Context
+	today       time.Time
+}
+
+// SetupTest runs before each test in the suite
+func (suite *DeliverySlotServiceTestSuite) SetupTest() {
+	suite.slotRepo = new(mocks.MockDeliverySlotRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+	suite.today = time.Now().UTC().Truncate(24 * time.Hour)
+
+	suite.slotService = services.NewDeliverySlotService(suite.slotRepo, suite.logger)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *DeliverySlotServiceTestSuite) TearDownTest() {
+	suite.slotRepo.AssertExpectations(suite.T())
+}
+
+func (suite *DeliverySlotServiceTestSuite) day(offset int) string {
+	return suite.today.AddDate(0, 0, offset).Format("2006-01-02")
+}
+
+// Test PlanSlots - Every window is planned on every day of the range
+func (suite *DeliverySlotServiceTestSuite) TestPlanSlots_PlansWindowsForEachDay() {
+	req := services.PlanDeliverySlotsRequest{
+		Region:    " Cairo ",
+		StartDate: suite.day(1),
+		EndDate:   suite.day(3),
+		Windows: []services.DeliveryWindow{
+			{Start: "14:00", End: "18:00", Capacity: 10},
+			{Start: "09:00", End: "13:00", Capacity: 20},
+		},
+	}
+	planned := []*models.DeliverySlot{
+		{ID: "slot-1", Region: "Cairo", Date: suite.today.AddDate(0, 0, 1), StartTime: "09:00", EndTime: "13:00", Capacity: 20, Reserved: 5, IsActive: true},
+		{ID: "slot-2", Region: "Cairo", Date: suite.today.AddDate(0, 0, 1), StartTime: "14:00", EndTime: "18:00", Capacity: 10, IsActive: true},
+	}
+
+	suite.slotRepo.On("Plan", suite.ctx, mock.MatchedBy(func(slots []*models.DeliverySlot) bool {
+		if len(slots) != 6 {
+			return false
+		}
+		for _, slot := range slots {
+			if slot.Region != "Cairo" || !slot.IsActive || slot.Date.Before(suite.today.AddDate(0, 0, 1)) ||
+				slot.Date.After(suite.today.AddDate(0, 0, 3)) {
+				return false
+			}
+		}
+		return true
+	})).Return(nil).Once()
+	suite.slotRepo.On("List", suite.ctx, repository.DeliverySlotFilter{
+		Region: "Cairo",
+		From:   suite.today.AddDate(0, 0, 1),
+		To:     suite.today.AddDate(0, 0, 3),
+	}).Return(planned, nil).Once()
+
+	response, err := suite.slotService.PlanSlots(suite.ctx, req)
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Cairo", response.Region)
+	assert.Equal(suite.T(), 30, response.Capacity)
+	assert.Equal(suite.T(), 5, response.Reserved)
+	require.Len(suite.T(), response.Slots, 2)
+	assert.Equal(suite.T(), 15, response.Slots[0].Remaining)
+	assert.Equal(suite.T(), suite.day(1), response.Slots[0].Date)
+}
+
+// Test PlanSlots - Overlapping windows are rejected
+func (suite *DeliverySlotServiceTestSuite) TestPlanSlots_OverlappingWindows() {
+	req := services.PlanDeliverySlotsRequest{
+		Region:    "Cairo",
+		StartDate: suite.day(1),
+		EndDate:   suite.day(1),
+		Windows: []services.DeliveryWindow{
+			{Start: "09:00", End: "13:00", Capacity: 20},
+			{Start: "12:00", End: "16:00", Capacity: 10},
+		},
+	}
+
+	response, err := suite.slotService.PlanSlots(suite.ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "overlap")
+	suite.slotRepo.AssertNotCalled(suite.T(), "Plan", mock.Anything, mock.Anything)
+}
+
+// Test PlanSlots - A window must end after it starts
+func (suite *DeliverySlotServiceTestSuite) TestPlanSlots_InvalidWindow() {
+	req := services.PlanDeliverySlotsRequest{
+		Region:    "Cairo",
+		StartDate: suite.day(1),
+		EndDate:   suite.day(1),
+		Windows:   []services.DeliveryWindow{{Start: "18:00", End: "09:00", Capacity: 20}},
+	}
+
+	response, err := suite.slotService.PlanSlots(suite.ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "must end after it starts")
+}
+
+// Test PlanSlots - Past days cannot be planned
+func (suite *DeliverySlotServiceTestSuite) TestPlanSlots_PastDays() {
+	req := services.PlanDeliverySlotsRequest{
+		Region:    "Cairo",
+		StartDate: suite.day(-1),
+		EndDate:   suite.day(1),
+		Windows:   []services.DeliveryWindow{{Start: "09:00", End: "13:00", Capacity: 20}},
+	}
+
+	response, err := suite.slotService.PlanSlots(suite.ctx, req)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "past days")
+}
+
+// Test UpdateSlot - The capacity is changed
+func (suite *DeliverySlotServiceTestSuite) TestUpdateSlot_Success() {
+	slot := &models.DeliverySlot{ID: "slot-1", Region: "Cairo", Date: suite.today, StartTime: "09:00", EndTime: "13:00", Capacity: 20, Reserved: 5, IsActive: true}
+	updated := *slot
+	updated.Capacity = 8
+	capacity := 8
+
+	suite.slotRepo.On("GetByID", suite.ctx, "slot-1").Return(slot, nil).Once()
+	suite.slotRepo.On("UpdateCapacity", suite.ctx, "slot-1", 8, true).Return(true, nil).Once()
+	suite.slotRepo.On("GetByID", suite.ctx, "slot-1").Return(&updated, nil).Once()
+
+	response, err := suite.slotService.UpdateSlot(suite.ctx, "slot-1", services.UpdateDeliverySlotRequest{Capacity: &capacity})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 8, response.Capacity)
+	assert.Equal(suite.T(), 3, response.Remaining)
+}
+
+// Test UpdateSlot - The capacity cannot go below the orders booked
+func (suite *DeliverySlotServiceTestSuite) TestUpdateSlot_BelowReserved() {
+	slot := &models.DeliverySlot{ID: "slot-1", Region: "Cairo", Date: suite.today, Capacity: 20, Reserved: 5, IsActive: true}
+	capacity := 4
+
+	suite.slotRepo.On("GetByID", suite.ctx, "slot-1").Return(slot, nil).Once()
+	suite.slotRepo.On("UpdateCapacity", suite.ctx, "slot-1", 4, true).Return(false, nil).Once()
+
+	response, err := suite.slotService.UpdateSlot(suite.ctx, "slot-1", services.UpdateDeliverySlotRequest{Capacity: &capacity})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+}
+
+// Test UpdateSlot - Slot not found
+func (suite *DeliverySlotServiceTestSuite) TestUpdateSlot_NotFound() {
+	inactive := false
+	suite.slotRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil).Once()
+
+	response, err := suite.slotService.UpdateSlot(suite.ctx, "missing", services.UpdateDeliverySlotRequest{IsActive: &inactive})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "not found")
+}
+
+// Test ListAvailableSlots - Only bookable slots from today on are listed
+func (suite *DeliverySlotServiceTestSuite) TestListAvailableSlots_FromToday() {
+	suite.slotRepo.On("List", suite.ctx, repository.DeliverySlotFilter{
+		Region:   "Cairo",
+		From:     suite.today,
+		To:       suite.today.AddDate(0, 0, 2),
+		Bookable: true,
+	}).Return([]*models.DeliverySlot{}, nil).Once()
+
+	response, err := suite.slotService.ListAvailableSlots(suite.ctx, services.ListDeliverySlotsRequest{
+		Region:    "Cairo",
+		StartDate: suite.day(-3),
+		EndDate:   suite.day(2),
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.day(0), response.StartDate)
+	assert.Empty(suite.T(), response.Slots)
+}
+
+// Test ListSlots - Defaults to the next 14 days
+func (suite *DeliverySlotServiceTestSuite) TestListSlots_DefaultRange() {
+	suite.slotRepo.On("List", suite.ctx, repository.DeliverySlotFilter{
+		From: suite.today,
+		To:   suite.today.AddDate(0, 0, 13),
+	}).Return([]*models.DeliverySlot{}, nil).Once()
+
+	response, err := suite.slotService.ListSlots(suite.ctx, services.ListDeliverySlotsRequest{})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.day(13), response.EndDate)
+}
+
+// Run the test suite
+func TestDeliverySlotServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(DeliverySlotServiceTestSuite))
+}
@@ -0,0 +1,172 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// DuplicateOrderGuardTestSuite defines the test suite for DuplicateOrderGuard
+type DuplicateOrderGuardTestSuite struct {
+	suite.Suite
+	orderRepo *mocks.MockOrderRepository
+	logger    *logger.Logger
+	ctx       context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *DuplicateOrderGuardTestSuite) SetupTest() {
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *DuplicateOrderGuardTestSuite) TearDownTest() {
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// recentOrder returns an order placed a minute ago with the given items
+func recentOrder(id string, items ...models.OrderItem) *models.Order {
+	order := &models.Order{ID: id, UserID: "user-1", Status: models.OrderStatusPending, Items: items}
+	order.CreatedAt = time.Now().Add(-time.Minute)
+	return order
+}
+
+// withinWindow matches a since time the given window before now
+func withinWindow(window time.Duration) interface{} {
+	return mock.MatchedBy(func(since time.Time) bool {
+		expected := time.Now().Add(-window)
+		return since.After(expected.Add(-time.Minute)) && !since.After(expected)
+	})
+}
+
+// Test Check - An order of the same items is placed with a warning
+func (suite *DuplicateOrderGuardTestSuite) TestCheck_WarnsOnDuplicate() {
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{Window: 10 * time.Minute}, suite.orderRepo, suite.logger)
+
+	// The same quantities split across lines in a different order still match
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, "user-1", withinWindow(10*time.Minute)).Return([]*models.Order{
+		recentOrder("order-2", models.OrderItem{ProductID: "product-1", Quantity: 1}),
+		recentOrder("order-1",
+			models.OrderItem{ProductID: "product-2", Quantity: 1},
+			models.OrderItem{ProductID: "product-1", Quantity: 2},
+		),
+	}, nil)
+
+	warning, err := guard.Check(suite.ctx, "user-1", []services.OrderItem{
+		{ProductID: "product-1", Quantity: 1},
+		{ProductID: "product-2", Quantity: 1},
+		{ProductID: "product-1", Quantity: 1},
+	}, false)
+
+	suite.NoError(err)
+	suite.Require().NotNil(warning)
+	suite.Equal("order-1", warning.OrderID)
+
+	metrics := guard.Metrics()
+	suite.Equal(int64(1), metrics.Checked)
+	suite.Equal(int64(1), metrics.Detected)
+	suite.Equal(int64(1), metrics.Warned)
+	suite.Equal(int64(0), metrics.Prevented)
+}
+
+// Test Check - Different quantities are not a duplicate
+func (suite *DuplicateOrderGuardTestSuite) TestCheck_DifferentItems() {
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{Window: 10 * time.Minute, RequireConfirmation: true}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, "user-1", mock.Anything).Return([]*models.Order{
+		recentOrder("order-1", models.OrderItem{ProductID: "product-1", Quantity: 2}),
+	}, nil)
+
+	warning, err := guard.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 3}}, false)
+
+	suite.NoError(err)
+	suite.Nil(warning)
+
+	metrics := guard.Metrics()
+	suite.Equal(int64(1), metrics.Checked)
+	suite.Equal(int64(0), metrics.Detected)
+}
+
+// Test Check - With confirmation required, an unconfirmed duplicate is prevented
+func (suite *DuplicateOrderGuardTestSuite) TestCheck_PreventsUnconfirmedDuplicate() {
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{Window: 10 * time.Minute, RequireConfirmation: true}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, "user-1", mock.Anything).Return([]*models.Order{
+		recentOrder("order-1", models.OrderItem{ProductID: "product-1", Quantity: 2}),
+	}, nil)
+
+	warning, err := guard.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 2}}, false)
+
+	suite.Nil(warning)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT: possible duplicate order")
+	suite.Contains(err.Error(), "order-1")
+
+	metrics := guard.Metrics()
+	suite.Equal(int64(1), metrics.Prevented)
+	suite.Equal(int64(1), metrics.Detected)
+	suite.True(metrics.RequireConfirmation)
+	suite.Equal(int64(600), metrics.WindowSeconds)
+}
+
+// Test Check - A confirmed duplicate is placed with a warning
+func (suite *DuplicateOrderGuardTestSuite) TestCheck_ConfirmedDuplicate() {
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{Window: 10 * time.Minute, RequireConfirmation: true}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, "user-1", mock.Anything).Return([]*models.Order{
+		recentOrder("order-1", models.OrderItem{ProductID: "product-1", Quantity: 2}),
+	}, nil)
+
+	warning, err := guard.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 2}}, true)
+
+	suite.NoError(err)
+	suite.Require().NotNil(warning)
+	suite.Equal("order-1", warning.OrderID)
+	suite.Equal(int64(1), guard.Metrics().Confirmed)
+}
+
+// Test Check - Repository errors are reported as database errors
+func (suite *DuplicateOrderGuardTestSuite) TestCheck_RepositoryError() {
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{Window: 10 * time.Minute}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, "user-1", mock.Anything).Return(nil, errors.New("connection reset"))
+
+	warning, err := guard.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 2}}, false)
+
+	suite.Nil(warning)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "DATABASE_ERROR")
+}
+
+// TestDuplicateOrderGuardTestSuite runs the test suite
+func TestDuplicateOrderGuardTestSuite(t *testing.T) {
+	suite.Run(t, new(DuplicateOrderGuardTestSuite))
+}
+
+// Test Check - A zero window or nil guard checks nothing
+func TestDuplicateOrderGuard_Disabled(t *testing.T) {
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1}}
+
+	guard := services.NewDuplicateOrderGuard(services.DuplicateOrderSettings{}, new(mocks.MockOrderRepository), nil)
+	warning, err := guard.Check(context.Background(), "user-1", items, false)
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+
+	var nilGuard *services.DuplicateOrderGuard
+	warning, err = nilGuard.Check(context.Background(), "user-1", items, false)
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+	assert.Equal(t, services.DuplicateOrderMetrics{}, nilGuard.Metrics())
+}
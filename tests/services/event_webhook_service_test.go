@@ -0,0 +1,308 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/webhooks"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+const testWebhookSecret = "whsec_0123456789abcdef"
+
+// EventWebhookServiceTestSuite defines the test suite for EventWebhookService
+type EventWebhookServiceTestSuite struct {
+	suite.Suite
+	eventWebhookService services.EventWebhookService
+	endpointRepo        *mocks.MockWebhookEndpointRepository
+	deliveryRepo        *mocks.MockWebhookDeliveryRepository
+	orderEventRepo      *mocks.MockOrderEventRepository
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *EventWebhookServiceTestSuite) SetupTest() {
+	suite.endpointRepo = new(mocks.MockWebhookEndpointRepository)
+	suite.deliveryRepo = new(mocks.MockWebhookDeliveryRepository)
+	suite.orderEventRepo = new(mocks.MockOrderEventRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.eventWebhookService = services.NewEventWebhookService(
+		services.EventWebhookSettings{
+			Retry:       webhooks.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour},
+			SettleDelay: 2 * time.Second,
+			Lease:       time.Minute,
+		},
+		suite.endpointRepo,
+		suite.deliveryRepo,
+		suite.orderEventRepo,
+		webhooks.NewClient(time.Second, "test"),
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *EventWebhookServiceTestSuite) TearDownTest() {
+	suite.endpointRepo.AssertExpectations(suite.T())
+	suite.deliveryRepo.AssertExpectations(suite.T())
+	suite.orderEventRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateEndpoint - Endpoints are created active with their topics deduplicated
+func (suite *EventWebhookServiceTestSuite) TestCreateEndpoint_Success() {
+	var endpoint *models.WebhookEndpoint
+	suite.endpointRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.WebhookEndpoint")).
+		Run(func(args mock.Arguments) { endpoint = args.Get(1).(*models.WebhookEndpoint) }).
+		Return(nil)
+
+	// Execute
+	response, err := suite.eventWebhookService.CreateEndpoint(suite.ctx, services.CreateWebhookEndpointRequest{
+		Name:   "ERP",
+		URL:    "https://erp.example.com/hooks",
+		Secret: testWebhookSecret,
+		Topics: []models.WebhookTopic{models.WebhookTopicOrderPaid, models.WebhookTopicOrderShipped, models.WebhookTopicOrderPaid},
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.True(endpoint.IsActive)
+	suite.Equal(models.StringList{"order.paid", "order.shipped"}, endpoint.Topics)
+	suite.Equal([]models.WebhookTopic{models.WebhookTopicOrderPaid, models.WebhookTopicOrderShipped}, response.Topics)
+}
+
+// Test CreateEndpoint - Short secrets and unknown topics are rejected
+func (suite *EventWebhookServiceTestSuite) TestCreateEndpoint_Invalid() {
+	requests := []services.CreateWebhookEndpointRequest{
+		{Name: "ERP", URL: "https://erp.example.com/hooks", Secret: "short", Topics: []models.WebhookTopic{models.WebhookTopicOrderPaid}},
+		{Name: "ERP", URL: "https://erp.example.com/hooks", Secret: testWebhookSecret, Topics: []models.WebhookTopic{"order.refunded"}},
+	}
+
+	for _, req := range requests {
+		_, err := suite.eventWebhookService.CreateEndpoint(suite.ctx, req)
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "VALIDATION_ERROR")
+	}
+
+	suite.endpointRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test RelayOrderEvents - Order events are queued under their topic for endpoints registered before them
+func (suite *EventWebhookServiceTestSuite) TestRelayOrderEvents() {
+	recordedAt := time.Now().Add(-time.Minute)
+	paid, _ := json.Marshal(models.OrderEventPayload{OrderID: "order-1", Status: models.OrderStatusPaid})
+	created, _ := json.Marshal(models.OrderEventPayload{OrderID: "order-1", Status: models.OrderStatusPending})
+	updated, _ := json.Marshal(models.OrderEventPayload{OrderID: "order-1", Status: models.OrderStatusPaid})
+	events := []*models.OrderEvent{
+		{Sequence: 11, OrderID: "order-1", Type: models.OrderEventCreated, Payload: created, RecordedAt: recordedAt},
+		{Sequence: 12, OrderID: "order-1", Type: models.OrderEventStatusChanged, Payload: paid, RecordedAt: recordedAt},
+		{Sequence: 13, OrderID: "order-1", Type: models.OrderEventUpdated, Payload: updated, RecordedAt: recordedAt},
+	}
+
+	suite.deliveryRepo.On("GetRelayCursor", suite.ctx, "order_events").Return(int64(10), nil)
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(10), 2*time.Second, 500).Return(events, nil)
+	suite.endpointRepo.On("ListSubscribed", suite.ctx, models.WebhookTopicOrderCreated).
+		Return([]*models.WebhookEndpoint{{ID: "endpoint-1", CreatedAt: recordedAt.Add(-time.Hour)}}, nil)
+	suite.endpointRepo.On("ListSubscribed", suite.ctx, models.WebhookTopicOrderPaid).
+		Return([]*models.WebhookEndpoint{
+			{ID: "endpoint-1", CreatedAt: recordedAt.Add(-time.Hour)},
+			{ID: "endpoint-2", CreatedAt: recordedAt.Add(time.Second)},
+		}, nil)
+
+	var deliveries []*models.WebhookDelivery
+	suite.deliveryRepo.On("EnqueueRelayed", suite.ctx, "order_events", int64(13), mock.Anything).
+		Run(func(args mock.Arguments) { deliveries = args.Get(3).([]*models.WebhookDelivery) }).
+		Return(nil)
+
+	// Execute
+	relayed, err := suite.eventWebhookService.RelayOrderEvents(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(3, relayed)
+	suite.Require().Len(deliveries, 2)
+	suite.Equal(models.WebhookTopicOrderCreated, deliveries[0].Topic)
+	suite.Equal(models.WebhookTopicOrderPaid, deliveries[1].Topic)
+	suite.Equal("endpoint-1", deliveries[1].EndpointID)
+	suite.NotEqual(deliveries[0].EventID, deliveries[1].EventID)
+	suite.Equal(recordedAt, deliveries[1].OccurredAt)
+	suite.Equal(models.WebhookDeliveryStatusPending, deliveries[1].Status)
+}
+
+// Test DeliverDue - Accepted deliveries are delivered, server errors retried with backoff
+// and client errors failed, each signed with the endpoint secret
+func (suite *EventWebhookServiceTestSuite) TestDeliverDue() {
+	var mu sync.Mutex
+	verified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if webhooks.Verify(testWebhookSecret, r.Header.Get(webhooks.TimestampHeader), r.Header.Get(webhooks.SignatureHeader), body, time.Now(), time.Minute) == nil {
+			mu.Lock()
+			verified++
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	newDelivery := func(id, path string) *models.WebhookDelivery {
+		return &models.WebhookDelivery{
+			ID:         id,
+			EndpointID: "endpoint-" + id,
+			EventID:    "event-" + id,
+			Topic:      models.WebhookTopicOrderShipped,
+			Payload:    json.RawMessage(`{"order_id":"order-1"}`),
+			Status:     models.WebhookDeliveryStatusPending,
+			Endpoint:   &models.WebhookEndpoint{ID: "endpoint-" + id, URL: server.URL + path, Secret: testWebhookSecret},
+		}
+	}
+	ok, down, rejected := newDelivery("1", "/ok"), newDelivery("2", "/down"), newDelivery("3", "/rejected")
+	down.Attempts = 1
+
+	suite.deliveryRepo.On("ClaimDue", suite.ctx, mock.AnythingOfType("time.Time"), time.Minute, 20).
+		Return([]*models.WebhookDelivery{ok, down, rejected}, nil)
+	suite.deliveryRepo.On("RecordAttempt", suite.ctx, mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+
+	// Execute
+	before := time.Now()
+	sent, err := suite.eventWebhookService.DeliverDue(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(3, sent)
+	suite.Equal(3, verified)
+
+	suite.Equal(models.WebhookDeliveryStatusDelivered, ok.Status)
+	suite.Equal(http.StatusNoContent, ok.LastStatusCode)
+	suite.NotNil(ok.DeliveredAt)
+
+	suite.Equal(models.WebhookDeliveryStatusPending, down.Status)
+	suite.Equal(2, down.Attempts)
+	suite.Require().NotNil(down.NextAttemptAt)
+	suite.WithinDuration(before.Add(2*time.Minute), *down.NextAttemptAt, 5*time.Second)
+	suite.NotEmpty(down.LastError)
+
+	suite.Equal(models.WebhookDeliveryStatusFailed, rejected.Status)
+	suite.Equal(http.StatusBadRequest, rejected.LastStatusCode)
+	suite.Nil(rejected.NextAttemptAt)
+}
+
+// Test DeliverDue - A delivery failing its last attempt is given up
+func (suite *EventWebhookServiceTestSuite) TestDeliverDue_Exhausted() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	delivery := &models.WebhookDelivery{
+		ID:       "delivery-1",
+		Topic:    models.WebhookTopicOrderPaid,
+		Payload:  json.RawMessage(`{}`),
+		Status:   models.WebhookDeliveryStatusPending,
+		Attempts: 2,
+		Endpoint: &models.WebhookEndpoint{ID: "endpoint-1", URL: server.URL, Secret: testWebhookSecret},
+	}
+	suite.deliveryRepo.On("ClaimDue", suite.ctx, mock.AnythingOfType("time.Time"), time.Minute, 20).
+		Return([]*models.WebhookDelivery{delivery}, nil)
+	suite.deliveryRepo.On("RecordAttempt", suite.ctx, delivery).Return(nil)
+
+	// Execute
+	_, err := suite.eventWebhookService.DeliverDue(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(3, delivery.Attempts)
+	suite.Equal(models.WebhookDeliveryStatusFailed, delivery.Status)
+}
+
+// Test Redeliver - A failed delivery is queued again with a fresh set of retries
+func (suite *EventWebhookServiceTestSuite) TestRedeliver_Success() {
+	suite.deliveryRepo.On("GetByID", suite.ctx, "delivery-1").Return(&models.WebhookDelivery{
+		ID:         "delivery-1",
+		EndpointID: "endpoint-1",
+		Status:     models.WebhookDeliveryStatusFailed,
+		Attempts:   3,
+	}, nil)
+	suite.endpointRepo.On("GetByID", suite.ctx, "endpoint-1").Return(&models.WebhookEndpoint{ID: "endpoint-1"}, nil)
+	suite.deliveryRepo.On("Requeue", suite.ctx, "delivery-1", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	// Execute
+	response, err := suite.eventWebhookService.Redeliver(suite.ctx, "delivery-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.WebhookDeliveryStatusPending, response.Status)
+	suite.Equal(0, response.Attempts)
+	suite.NotNil(response.NextAttemptAt)
+}
+
+// Test Redeliver - Only failed deliveries to existing endpoints can be redelivered
+func (suite *EventWebhookServiceTestSuite) TestRedeliver_Conflict() {
+	suite.deliveryRepo.On("GetByID", suite.ctx, "delivery-1").
+		Return(&models.WebhookDelivery{ID: "delivery-1", EndpointID: "endpoint-1", Status: models.WebhookDeliveryStatusDelivered}, nil)
+	suite.deliveryRepo.On("GetByID", suite.ctx, "delivery-2").
+		Return(&models.WebhookDelivery{ID: "delivery-2", EndpointID: "endpoint-2", Status: models.WebhookDeliveryStatusFailed}, nil)
+	suite.endpointRepo.On("GetByID", suite.ctx, "endpoint-2").Return(nil, nil)
+
+	for _, id := range []string{"delivery-1", "delivery-2"} {
+		_, err := suite.eventWebhookService.Redeliver(suite.ctx, id)
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "CONFLICT")
+	}
+
+	suite.deliveryRepo.AssertNotCalled(suite.T(), "Requeue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test PublishPaymentFailed - Failed payments are queued for endpoints subscribed to payment.failed
+func (suite *EventWebhookServiceTestSuite) TestPublishPaymentFailed() {
+	suite.endpointRepo.On("ListSubscribed", suite.ctx, models.WebhookTopicPaymentFailed).
+		Return([]*models.WebhookEndpoint{{ID: "endpoint-1"}}, nil)
+
+	var deliveries []*models.WebhookDelivery
+	suite.deliveryRepo.On("Enqueue", suite.ctx, mock.Anything).
+		Run(func(args mock.Arguments) { deliveries = args.Get(1).([]*models.WebhookDelivery) }).
+		Return(nil)
+
+	// Execute
+	suite.eventWebhookService.PublishPaymentFailed(suite.ctx, &models.Payment{
+		ID:            "payment-1",
+		OrderID:       "order-1",
+		Amount:        42.5,
+		FailureReason: "card declined",
+		AttemptCount:  2,
+	})
+
+	// Assert
+	suite.Require().Len(deliveries, 1)
+	suite.Equal("endpoint-1", deliveries[0].EndpointID)
+	suite.Equal(models.WebhookTopicPaymentFailed, deliveries[0].Topic)
+
+	var data services.PaymentFailedWebhookData
+	suite.Require().NoError(json.Unmarshal(deliveries[0].Payload, &data))
+	suite.Equal("order-1", data.OrderID)
+	suite.Equal("card declined", data.FailureReason)
+}
+
+// TestEventWebhookServiceTestSuite runs the test suite
+func TestEventWebhookServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(EventWebhookServiceTestSuite))
+}
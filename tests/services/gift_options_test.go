@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"easy-orders-backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Offered - Only the listed stores offer gift options, or every store with "*"
+func TestGiftOptions_Offered(t *testing.T) {
+	options := services.NewGiftOptions([]string{"direct"})
+	assert.True(t, options.Offered("direct"))
+	assert.False(t, options.Offered("shopify"))
+
+	everywhere := services.NewGiftOptions([]string{services.DefaultStore})
+	assert.True(t, everywhere.Offered("shopify"))
+
+	var none *services.GiftOptions
+	assert.False(t, none.Offered("direct"))
+}
+
+// Test Check - Gift options are refused where not offered and with too long a message
+func TestGiftOptions_Check(t *testing.T) {
+	options := services.NewGiftOptions([]string{"direct"})
+
+	assert.NoError(t, options.Check("shopify", nil))
+	assert.NoError(t, options.Check("direct", &services.OrderGiftOptions{Message: "Enjoy!", HidePrices: true}))
+
+	err := options.Check("shopify", &services.OrderGiftOptions{Message: "Enjoy!"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "BUSINESS_ERROR: store shopify does not offer gift options")
+
+	err = options.Check("direct", &services.OrderGiftOptions{Message: strings.Repeat("é", 501)})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "VALIDATION_ERROR")
+}
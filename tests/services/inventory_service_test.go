@@ -3,10 +3,14 @@ package services_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/internal/services"
+	apperrors "easy-orders-backend/pkg/errors"
 	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/tests/mocks"
 	"easy-orders-backend/tests/testutil"
@@ -22,6 +26,8 @@ type InventoryServiceTestSuite struct {
 	inventoryService services.InventoryService
 	inventoryRepo    *mocks.MockInventoryRepository
 	productRepo      *mocks.MockProductRepository
+	auditRepo        *mocks.MockAuditLogRepository
+	eventRepo        *mocks.MockInventoryEventRepository
 	logger           *logger.Logger
 	ctx              context.Context
 }
@@ -30,12 +36,18 @@ type InventoryServiceTestSuite struct {
 func (suite *InventoryServiceTestSuite) SetupTest() {
 	suite.inventoryRepo = new(mocks.MockInventoryRepository)
 	suite.productRepo = new(mocks.MockProductRepository)
+	suite.auditRepo = new(mocks.MockAuditLogRepository)
+	suite.eventRepo = new(mocks.MockInventoryEventRepository)
 	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
 	suite.ctx = context.Background()
 
 	suite.inventoryService = services.NewInventoryService(
+		services.LowStockSettings{}, // Static low-stock thresholds
 		suite.inventoryRepo,
 		suite.productRepo,
+		suite.auditRepo,
+		suite.eventRepo,
+		nil, // No stock webhook subscribers
 		suite.logger,
 	)
 }
@@ -44,6 +56,8 @@ func (suite *InventoryServiceTestSuite) SetupTest() {
 func (suite *InventoryServiceTestSuite) TearDownTest() {
 	suite.inventoryRepo.AssertExpectations(suite.T())
 	suite.productRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+	suite.eventRepo.AssertExpectations(suite.T())
 }
 
 // Test CheckAvailability - Happy Path (Sufficient Stock)
@@ -442,6 +456,496 @@ func (suite *InventoryServiceTestSuite) TestGetLowStockAlert_MissingProductInfo(
 	assert.Equal(suite.T(), "", response.Products[0].SKU)
 }
 
+// dynamicLowStockService returns an inventory service using thresholds from 30 days of
+// sales, a 7 day default lead time and 3 safety days
+func (suite *InventoryServiceTestSuite) dynamicLowStockService() services.InventoryService {
+	return services.NewInventoryService(
+		services.LowStockSettings{
+			Dynamic:        true,
+			VelocityWindow: 30 * 24 * time.Hour,
+			LeadTimeDays:   7,
+			SafetyDays:     3,
+		},
+		suite.inventoryRepo,
+		suite.productRepo,
+		suite.auditRepo,
+		suite.eventRepo,
+		nil, // No stock webhook subscribers
+		suite.logger,
+	)
+}
+
+// Test GetProductLowStockAlert - Static thresholds use min stock and overrides
+func (suite *InventoryServiceTestSuite) TestGetProductLowStockAlert_StaticThresholds() {
+	override := 50
+	belowMin := testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Below Min" })
+	aboveMin := testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Above Min" })
+	overridden := testutil.CreateTestProduct(func(p *models.Product) {
+		p.Name = "Overridden"
+		p.LowStockThreshold = &override
+	})
+	inventories := []*models.Inventory{
+		testutil.CreateTestInventory(belowMin.ID, func(i *models.Inventory) {
+			i.Available = 5
+			i.MinStock = 10
+			i.Product = belowMin
+		}),
+		testutil.CreateTestInventory(aboveMin.ID, func(i *models.Inventory) {
+			i.Available = 40
+			i.MinStock = 10
+			i.Product = aboveMin
+		}),
+		testutil.CreateTestInventory(overridden.ID, func(i *models.Inventory) {
+			i.Available = 40
+			i.MinStock = 10
+			i.Product = overridden
+		}),
+	}
+
+	// Sales are not looked up while dynamic thresholds are disabled
+	suite.inventoryRepo.On("ListWithProducts", suite.ctx).Return(inventories, nil)
+
+	// Execute
+	response, err := suite.inventoryService.GetProductLowStockAlert(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.PerProduct)
+	assert.Equal(suite.T(), 2, response.Count)
+	assert.Equal(suite.T(), "Below Min", response.Products[0].ProductName)
+	assert.Equal(suite.T(), 10, response.Products[0].Threshold)
+	assert.Equal(suite.T(), services.LowStockThresholdMinStock, response.Products[0].ThresholdSource)
+	assert.Equal(suite.T(), "Overridden", response.Products[1].ProductName)
+	assert.Equal(suite.T(), 50, response.Products[1].Threshold)
+	assert.Equal(suite.T(), services.LowStockThresholdOverride, response.Products[1].ThresholdSource)
+	suite.inventoryRepo.AssertNotCalled(suite.T(), "GetUnitsSoldSince", mock.Anything, mock.Anything)
+}
+
+// Test GetProductLowStockAlert - Dynamic thresholds cover lead time plus safety days
+func (suite *InventoryServiceTestSuite) TestGetProductLowStockAlert_DynamicThresholds() {
+	fastMover := testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Fast Mover" })
+	longLead := testutil.CreateTestProduct(func(p *models.Product) {
+		p.Name = "Long Lead"
+		p.LeadTimeDays = 27
+	})
+	unsold := testutil.CreateTestProduct(func(p *models.Product) { p.Name = "Unsold" })
+	inventories := []*models.Inventory{
+		testutil.CreateTestInventory(fastMover.ID, func(i *models.Inventory) {
+			i.Available = 25
+			i.MinStock = 5
+			i.Product = fastMover
+		}),
+		testutil.CreateTestInventory(longLead.ID, func(i *models.Inventory) {
+			i.Available = 25
+			i.MinStock = 5
+			i.Product = longLead
+		}),
+		testutil.CreateTestInventory(unsold.ID, func(i *models.Inventory) {
+			i.Available = 25
+			i.MinStock = 5
+			i.Product = unsold
+		}),
+	}
+
+	// 3 units a day: 30 units over 7+3 days for the fast mover, 90 over 27+3 for the long lead
+	suite.inventoryRepo.On("ListWithProducts", suite.ctx).Return(inventories, nil)
+	suite.inventoryRepo.On("GetUnitsSoldSince", suite.ctx, mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since) > 29*24*time.Hour && time.Since(since) < 31*24*time.Hour
+	})).Return(map[string]int{fastMover.ID: 90, longLead.ID: 90}, nil)
+
+	// Execute
+	response, err := suite.dynamicLowStockService().GetProductLowStockAlert(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, response.Count)
+
+	assert.Equal(suite.T(), "Fast Mover", response.Products[0].ProductName)
+	assert.Equal(suite.T(), 30, response.Products[0].Threshold)
+	assert.Equal(suite.T(), services.LowStockThresholdSalesVelocity, response.Products[0].ThresholdSource)
+	assert.InDelta(suite.T(), 3.0, response.Products[0].DailyVelocity, 0.001)
+	assert.NotNil(suite.T(), response.Products[0].DaysOfCover)
+	assert.InDelta(suite.T(), 25.0/3.0, *response.Products[0].DaysOfCover, 0.001)
+
+	assert.Equal(suite.T(), "Long Lead", response.Products[1].ProductName)
+	assert.Equal(suite.T(), 90, response.Products[1].Threshold)
+}
+
+// Test GetProductLowStockAlert - Repository Error
+func (suite *InventoryServiceTestSuite) TestGetProductLowStockAlert_SalesError() {
+	suite.inventoryRepo.On("ListWithProducts", suite.ctx).Return([]*models.Inventory{}, nil)
+	suite.inventoryRepo.On("GetUnitsSoldSince", suite.ctx, mock.Anything).Return(nil, errors.New("database error"))
+
+	// Execute
+	response, err := suite.dynamicLowStockService().GetProductLowStockAlert(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+}
+
+// Test ImportRecount - Happy Path with mixed rows
+func (suite *InventoryServiceTestSuite) TestImportRecount_Success() {
+	product := testutil.CreateTestProduct(func(p *models.Product) { p.SKU = "SKU-A" })
+	product.Inventory = testutil.CreateTestInventory(product.ID, func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Reserved = 10
+		i.Version = 3
+	})
+	unchanged := testutil.CreateTestProduct(func(p *models.Product) { p.SKU = "SKU-B" })
+	unchanged.Inventory = testutil.CreateTestInventory(unchanged.ID)
+
+	csv := "sku,quantity\nSKU-A,90\nSKU-B,100\nSKU-X,5\nSKU-C,abc\n"
+
+	// Mock expectations
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-A", "SKU-B", "SKU-X"}).
+		Return([]*models.Product{product, unchanged}, nil)
+	suite.inventoryRepo.On("BulkAdjustStock", recountContext(), []repository.InventoryStockAdjustment{
+		{ProductID: product.ID, Quantity: 90, ExpectedVersion: 3},
+	}).Return(nil)
+
+	// Execute
+	response, err := suite.inventoryService.ImportRecount(suite.ctx, strings.NewReader(csv), suite.actor())
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), response.RecountID)
+	assert.Equal(suite.T(), 4, response.TotalRows)
+	assert.Equal(suite.T(), 1, response.Adjusted)
+	assert.Equal(suite.T(), 1, response.Unchanged)
+	assert.Equal(suite.T(), 2, response.Failed)
+	assert.Equal(suite.T(), -10, response.NetVariance)
+	assert.Equal(suite.T(), services.RecountStatusAdjusted, response.Lines[0].Status)
+	assert.Equal(suite.T(), "product not found", response.Lines[2].Error)
+}
+
+// Test ImportRecount - Version conflict is retried against fresh stock
+func (suite *InventoryServiceTestSuite) TestImportRecount_RetriesOnConflict() {
+	stale := testutil.CreateTestProduct(func(p *models.Product) { p.SKU = "SKU-A" })
+	stale.Inventory = testutil.CreateTestInventory(stale.ID, func(i *models.Inventory) { i.Version = 1 })
+	fresh := testutil.CreateTestProduct(func(p *models.Product) {
+		p.ID = stale.ID
+		p.SKU = "SKU-A"
+	})
+	fresh.Inventory = testutil.CreateTestInventory(stale.ID, func(i *models.Inventory) {
+		i.Quantity = 95
+		i.Version = 2
+	})
+
+	// Mock expectations
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-A"}).Return([]*models.Product{stale}, nil).Once()
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-A"}).Return([]*models.Product{fresh}, nil).Once()
+	suite.inventoryRepo.On("BulkAdjustStock", recountContext(), []repository.InventoryStockAdjustment{
+		{ProductID: stale.ID, Quantity: 80, ExpectedVersion: 1},
+	}).Return(apperrors.NewOptimisticLockError("inventory", stale.ID))
+	suite.inventoryRepo.On("BulkAdjustStock", recountContext(), []repository.InventoryStockAdjustment{
+		{ProductID: stale.ID, Quantity: 80, ExpectedVersion: 2},
+	}).Return(nil)
+
+	// Execute
+	response, err := suite.inventoryService.ImportRecount(suite.ctx, strings.NewReader("SKU-A,80\n"), suite.actor())
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Adjusted)
+	assert.Equal(suite.T(), 95, response.Lines[0].PreviousQuantity)
+	assert.Equal(suite.T(), -15, response.Lines[0].Variance)
+}
+
+// Test ImportRecount - Empty file
+func (suite *InventoryServiceTestSuite) TestImportRecount_EmptyFile() {
+	// Execute
+	response, err := suite.inventoryService.ImportRecount(suite.ctx, strings.NewReader("sku,quantity\n"), suite.actor())
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "empty")
+}
+
+// actor is the admin making audited inventory changes
+func (suite *InventoryServiceTestSuite) actor() services.AuditActor {
+	return services.AuditActor{UserID: "admin-1", IPAddress: "10.0.0.1", UserAgent: "test"}
+}
+
+// recountContext matches the context of stock adjustments recorded against a recount
+// by the admin
+func recountContext() interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		source, ok := repository.InventoryEventSourceFrom(ctx)
+		return ok && source.ReferenceType == models.InventoryReferenceRecount &&
+			source.ReferenceID != "" && source.ActorID == "admin-1"
+	})
+}
+
+// relocation matches a relocation of a product's stock, audit-logged with the admin
+func relocation(productID, from, to, reason string) interface{} {
+	return mock.MatchedBy(func(r repository.InventoryRelocation) bool {
+		return r.ProductID == productID && r.From == from && r.To == to &&
+			r.AuditLog != nil && r.AuditLog.Action == models.AuditActionRelocate &&
+			r.AuditLog.EntityType == "inventory" && r.AuditLog.EntityID == productID &&
+			r.AuditLog.UserID != nil && *r.AuditLog.UserID == "admin-1" &&
+			r.AuditLog.OldValues == `{"location":"`+from+`"}` &&
+			strings.Contains(r.AuditLog.NewValues, `"location":"`+to+`"`) &&
+			strings.Contains(r.AuditLog.NewValues, reason)
+	})
+}
+
+// Test Relocate - The location is trimmed and upper-cased, and the move is audit-logged
+func (suite *InventoryServiceTestSuite) TestRelocate_Success() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) { i.Location = "B-1-1" })
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.inventoryRepo.On("Relocate", suite.ctx, relocation("product-1", "B-1-1", "A-3-2", "overflow")).Return(true, nil)
+
+	location, err := suite.inventoryService.Relocate(suite.ctx, "product-1", suite.actor(), services.RelocateInventoryRequest{
+		Location: " a-3-2 ",
+		Reason:   "overflow",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal("product-1", location.ProductID)
+	suite.Equal("A-3-2", location.Location)
+	suite.Equal("B-1-1", location.PreviousLocation)
+	suite.True(location.Relocated)
+}
+
+// Test Relocate - Stock already at the location is left alone, without an audit log
+func (suite *InventoryServiceTestSuite) TestRelocate_AlreadyThere() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) { i.Location = "A-3-2" })
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+
+	location, err := suite.inventoryService.Relocate(suite.ctx, "product-1", suite.actor(), services.RelocateInventoryRequest{
+		Location: "a-3-2",
+	})
+
+	suite.Require().NoError(err)
+	suite.False(location.Relocated)
+	suite.inventoryRepo.AssertNotCalled(suite.T(), "Relocate", mock.Anything, mock.Anything)
+}
+
+// Test Relocate - A move that lost a race with another relocation is a conflict
+func (suite *InventoryServiceTestSuite) TestRelocate_Conflict() {
+	inventory := testutil.CreateTestInventory("product-1")
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.inventoryRepo.On("Relocate", suite.ctx, relocation("product-1", "", "A-3-2", "")).Return(false, nil)
+
+	location, err := suite.inventoryService.Relocate(suite.ctx, "product-1", suite.actor(), services.RelocateInventoryRequest{
+		Location: "A-3-2",
+	})
+
+	suite.Nil(location)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test Relocate - Products without inventory have no location to set
+func (suite *InventoryServiceTestSuite) TestRelocate_NoInventory() {
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(nil, nil)
+
+	location, err := suite.inventoryService.Relocate(suite.ctx, "product-1", suite.actor(), services.RelocateInventoryRequest{
+		Location: "A-3-2",
+	})
+
+	suite.Nil(location)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test GetRelocations - Relocations are read back from the audit log
+func (suite *InventoryServiceTestSuite) TestGetRelocations() {
+	admin := "admin-1"
+	inventory := testutil.CreateTestInventory("product-1")
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.auditRepo.On("GetByEntityID", suite.ctx, "inventory", "product-1", 0, 100).Return([]*models.AuditLog{
+		{UserID: &admin, Action: models.AuditActionRelocate, OldValues: `{"location":"B-1-1"}`, NewValues: `{"location":"A-3-2","reason":"overflow"}`},
+		{Action: models.AuditActionUpdate, OldValues: `{}`, NewValues: `{}`},
+		{Action: models.AuditActionRelocate, OldValues: `{"location":""}`, NewValues: `{"location":"B-1-1","reason":"inventory recount"}`},
+	}, nil)
+
+	relocations, err := suite.inventoryService.GetRelocations(suite.ctx, "product-1")
+
+	suite.Require().NoError(err)
+	suite.Require().Len(relocations, 2)
+	suite.Equal("B-1-1", relocations[0].From)
+	suite.Equal("A-3-2", relocations[0].To)
+	suite.Equal("overflow", relocations[0].Reason)
+	suite.Equal(&admin, relocations[0].UserID)
+	suite.Equal("", relocations[1].From)
+	suite.Nil(relocations[1].UserID)
+}
+
+// Test GetHistory - Events and transfers are returned as merged by the repository, a page at a time
+func (suite *InventoryServiceTestSuite) TestGetHistory() {
+	admin := "admin-1"
+	sequence := int64(42)
+	onHand, reserved, available := 100, 3, 97
+	from, to := "B-1-1", "A-3-2"
+	occurredAt := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	inventory := testutil.CreateTestInventory("product-1")
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.eventRepo.On("ListHistory", suite.ctx, "product-1", 10, 10).Return([]repository.InventoryHistoryEntry{
+		{Type: "transferred", FromLocation: &from, ToLocation: &to, Reason: "overflow", ActorID: &admin, OccurredAt: occurredAt},
+		{Type: string(models.InventoryEventReserved), Sequence: &sequence, Quantity: 3, OnHand: &onHand, Reserved: &reserved,
+			Available: &available, ReferenceType: string(models.InventoryReferenceOrder), ReferenceID: "order-1", OccurredAt: occurredAt},
+	}, nil)
+	suite.eventRepo.On("CountHistory", suite.ctx, "product-1").Return(int64(12), nil)
+
+	history, err := suite.inventoryService.GetHistory(suite.ctx, "product-1", services.InventoryHistoryRequest{Page: 2, Limit: 10})
+
+	suite.Require().NoError(err)
+	suite.Equal("product-1", history.ProductID)
+	suite.Equal(2, history.Page)
+	suite.Equal(12, history.Total)
+	suite.Require().Len(history.Entries, 2)
+	suite.Equal("transferred", history.Entries[0].Type)
+	suite.Equal(&to, history.Entries[0].ToLocation)
+	suite.Equal("overflow", history.Entries[0].Reason)
+	suite.Equal(&admin, history.Entries[0].ActorID)
+	suite.Nil(history.Entries[0].OnHand)
+	suite.Equal(&sequence, history.Entries[1].Sequence)
+	suite.Equal(&available, history.Entries[1].Available)
+	suite.Equal("order", history.Entries[1].ReferenceType)
+	suite.Equal("order-1", history.Entries[1].ReferenceID)
+}
+
+// Test GetHistory - Products without inventory have no history
+func (suite *InventoryServiceTestSuite) TestGetHistory_NotFound() {
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "missing").Return(nil, nil)
+
+	history, err := suite.inventoryService.GetHistory(suite.ctx, "missing", services.InventoryHistoryRequest{})
+
+	suite.Nil(history)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+	suite.eventRepo.AssertNotCalled(suite.T(), "ListHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ImportRecount - A counted location relocates the stock, even when its quantity is unchanged
+func (suite *InventoryServiceTestSuite) TestImportRecount_Relocates() {
+	product := testutil.CreateTestProduct(func(p *models.Product) { p.SKU = "SKU-A" })
+	product.Inventory = testutil.CreateTestInventory(product.ID, func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Location = "B-1-1"
+	})
+	same := testutil.CreateTestProduct(func(p *models.Product) { p.SKU = "SKU-B" })
+	same.Inventory = testutil.CreateTestInventory(same.ID, func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Location = "C-2-1"
+	})
+
+	csv := "sku,quantity,location\nSKU-A,100,a-3-2\nSKU-B,100,C-2-1\nSKU-C,5," + strings.Repeat("X", 51) + "\n"
+
+	// Mock expectations
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-A", "SKU-B"}).
+		Return([]*models.Product{product, same}, nil)
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, product.ID).Return(product.Inventory, nil)
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, same.ID).Return(same.Inventory, nil)
+	suite.inventoryRepo.On("Relocate", suite.ctx, relocation(product.ID, "B-1-1", "A-3-2", "inventory recount")).Return(true, nil)
+
+	// Execute
+	response, err := suite.inventoryService.ImportRecount(suite.ctx, strings.NewReader(csv), suite.actor())
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(2, response.Unchanged)
+	suite.Equal(1, response.Failed)
+	suite.Equal(1, response.Relocated)
+	suite.True(response.Lines[0].Relocated)
+	suite.Equal("B-1-1", response.Lines[0].PreviousLocation)
+	suite.Equal("A-3-2", response.Lines[0].Location)
+	suite.False(response.Lines[1].Relocated)
+	suite.Contains(response.Lines[2].Error, "location cannot exceed 50 characters")
+}
+
+// Test SetSafetyStock - Held-back units leave physical stock alone and shrink sellable stock
+func (suite *InventoryServiceTestSuite) TestSetSafetyStock_Success() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Reserved = 10
+	})
+	updated := *inventory
+	updated.SafetyStock = 20
+	updated.RefreshAvailable()
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.inventoryRepo.On("SetSafetyStock", mock.Anything, "product-1", 20, mock.MatchedBy(func(log *models.AuditLog) bool {
+		return log.EntityID == "product-1" && log.Action == models.AuditActionUpdate
+	})).Return(&updated, nil)
+
+	safetyStock := 20
+	levels, err := suite.inventoryService.SetSafetyStock(suite.ctx, "product-1", suite.actor(), services.SetSafetyStockRequest{
+		SafetyStock: &safetyStock,
+		Reason:      "peak season",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(100, levels.PhysicalStock)
+	suite.Equal(10, levels.Reserved)
+	suite.Equal(20, levels.SafetyStock)
+	suite.Equal(70, levels.Sellable)
+}
+
+// Test SetSafetyStock - An unchanged safety stock is not written again
+func (suite *InventoryServiceTestSuite) TestSetSafetyStock_Unchanged() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) {
+		i.SafetyStock = 5
+		i.RefreshAvailable()
+	})
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+
+	safetyStock := 5
+	levels, err := suite.inventoryService.SetSafetyStock(suite.ctx, "product-1", suite.actor(), services.SetSafetyStockRequest{
+		SafetyStock: &safetyStock,
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(5, levels.SafetyStock)
+	suite.inventoryRepo.AssertNotCalled(suite.T(), "SetSafetyStock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test SetSafetyStock - Negative or missing safety stock is rejected
+func (suite *InventoryServiceTestSuite) TestSetSafetyStock_Invalid() {
+	negative := -1
+	for _, req := range []services.SetSafetyStockRequest{{}, {SafetyStock: &negative}} {
+		levels, err := suite.inventoryService.SetSafetyStock(suite.ctx, "product-1", suite.actor(), req)
+
+		suite.Nil(levels)
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "VALIDATION_ERROR")
+	}
+}
+
+// Test SetSafetyStock - Products without inventory have no safety stock to set
+func (suite *InventoryServiceTestSuite) TestSetSafetyStock_NoInventory() {
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(nil, nil)
+
+	safetyStock := 5
+	levels, err := suite.inventoryService.SetSafetyStock(suite.ctx, "product-1", suite.actor(), services.SetSafetyStockRequest{
+		SafetyStock: &safetyStock,
+	})
+
+	suite.Nil(levels)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test GetStockLevels - Sellable stock never drops below zero when safety stock exceeds what is free
+func (suite *InventoryServiceTestSuite) TestGetStockLevels_SafetyStockExceedsFree() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) {
+		i.Quantity = 10
+		i.Reserved = 4
+		i.SafetyStock = 8
+		i.RefreshAvailable()
+	})
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+
+	levels, err := suite.inventoryService.GetStockLevels(suite.ctx, "product-1")
+
+	suite.Require().NoError(err)
+	suite.Equal(10, levels.PhysicalStock)
+	suite.Equal(8, levels.SafetyStock)
+	suite.Equal(0, levels.Sellable)
+}
+
 // TestInventoryServiceTestSuite runs the test suite
 func TestInventoryServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(InventoryServiceTestSuite))
@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// InventoryShardServiceTestSuite defines the test suite for InventoryShardService
+type InventoryShardServiceTestSuite struct {
+	suite.Suite
+	shardService  services.InventoryShardService
+	inventoryRepo *mocks.MockInventoryRepository
+	shardRepo     *mocks.MockInventoryShardRepository
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *InventoryShardServiceTestSuite) SetupTest() {
+	suite.inventoryRepo = new(mocks.MockInventoryRepository)
+	suite.shardRepo = new(mocks.MockInventoryShardRepository)
+	suite.ctx = context.Background()
+
+	suite.shardService = services.NewInventoryShardService(
+		suite.inventoryRepo,
+		suite.shardRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *InventoryShardServiceTestSuite) TearDownTest() {
+	suite.inventoryRepo.AssertExpectations(suite.T())
+	suite.shardRepo.AssertExpectations(suite.T())
+}
+
+// testInventoryShards returns count shards of a product holding the given units each
+func testInventoryShards(productID string, count, units int) []*models.InventoryShard {
+	result := make([]*models.InventoryShard, count)
+	for i := range result {
+		result[i] = &models.InventoryShard{ProductID: productID, Shard: i, Available: units}
+	}
+	return result
+}
+
+// Test SetShards - The stock is split and the change audit-logged with the old count
+func (suite *InventoryShardServiceTestSuite) TestSetShards_Success() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) {
+		i.Quantity = 100
+		i.Reserved = 20
+		i.Available = 80
+	})
+	count := 4
+
+	suite.shardRepo.On("List", suite.ctx, "product-1").Return([]*models.InventoryShard{}, nil).Once()
+	suite.shardRepo.On("Configure", suite.ctx, "product-1", 4, mock.MatchedBy(func(log *models.AuditLog) bool {
+		return log.EntityType == "inventory" && log.EntityID == "product-1" && *log.UserID == "admin-1" &&
+			strings.Contains(log.OldValues, `"shards":0`) &&
+			strings.Contains(log.NewValues, `"shards":4`) && strings.Contains(log.NewValues, "flash sale")
+	})).Return(inventory, nil)
+	suite.shardRepo.On("List", suite.ctx, "product-1").Return(testInventoryShards("product-1", 4, 20), nil).Once()
+
+	response, err := suite.shardService.SetShards(suite.ctx, "product-1", services.AuditActor{UserID: "admin-1"},
+		services.SetInventoryShardsRequest{Shards: &count, Reason: " flash sale "})
+
+	suite.Require().NoError(err)
+	suite.Equal(80, response.Sellable)
+	suite.Len(response.Shards, 4)
+	suite.Equal(services.InventoryShardResponse{Shard: 3, Available: 20}, response.Shards[3])
+}
+
+// Test SetShards - Shard counts out of range are rejected before anything is read
+func (suite *InventoryShardServiceTestSuite) TestSetShards_InvalidCount() {
+	for _, count := range []int{-1, models.MaxInventoryShards + 1} {
+		_, err := suite.shardService.SetShards(suite.ctx, "product-1", services.AuditActor{UserID: "admin-1"},
+			services.SetInventoryShardsRequest{Shards: &count})
+		suite.Error(err)
+		suite.Contains(err.Error(), "VALIDATION_ERROR")
+	}
+
+	_, err := suite.shardService.SetShards(suite.ctx, "product-1", services.AuditActor{UserID: "admin-1"},
+		services.SetInventoryShardsRequest{})
+	suite.Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// Test SetShards - A product without inventory cannot be sharded
+func (suite *InventoryShardServiceTestSuite) TestSetShards_NotFound() {
+	count := 0
+	suite.shardRepo.On("List", suite.ctx, "missing").Return([]*models.InventoryShard{}, nil)
+	suite.shardRepo.On("Configure", suite.ctx, "missing", 0, mock.Anything).Return(nil, nil)
+
+	_, err := suite.shardService.SetShards(suite.ctx, "missing", services.AuditActor{UserID: "admin-1"},
+		services.SetInventoryShardsRequest{Shards: &count})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test GetShards - The sellable stock is shown with its shards
+func (suite *InventoryShardServiceTestSuite) TestGetShards_Success() {
+	inventory := testutil.CreateTestInventory("product-1", func(i *models.Inventory) {
+		i.Available = 9
+	})
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "product-1").Return(inventory, nil)
+	suite.shardRepo.On("List", suite.ctx, "product-1").Return(testInventoryShards("product-1", 3, 3), nil)
+
+	response, err := suite.shardService.GetShards(suite.ctx, "product-1")
+
+	suite.Require().NoError(err)
+	suite.Equal("product-1", response.ProductID)
+	suite.Equal(9, response.Sellable)
+	suite.Len(response.Shards, 3)
+}
+
+// Test GetShards - A product without inventory is not found
+func (suite *InventoryShardServiceTestSuite) TestGetShards_NotFound() {
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, "missing").Return(nil, nil)
+
+	_, err := suite.shardService.GetShards(suite.ctx, "missing")
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test RebalanceShards - Repository failures are reported as database errors
+func (suite *InventoryShardServiceTestSuite) TestRebalanceShards_Error() {
+	suite.shardRepo.On("Rebalance", suite.ctx).Return(2, errors.New("connection reset"))
+
+	rebalanced, err := suite.shardService.RebalanceShards(suite.ctx)
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "DATABASE_ERROR")
+	suite.Equal(2, rebalanced)
+}
+
+// TestInventoryShardServiceTestSuite runs the test suite
+func TestInventoryShardServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(InventoryShardServiceTestSuite))
+}
@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// LedgerServiceTestSuite defines the test suite for LedgerService
+type LedgerServiceTestSuite struct {
+	suite.Suite
+	ledgerService services.LedgerService
+	ledgerRepo    *mocks.MockLedgerRepository
+	posted        [][]*models.LedgerEntry
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *LedgerServiceTestSuite) SetupTest() {
+	suite.ledgerRepo = new(mocks.MockLedgerRepository)
+	suite.posted = nil
+	suite.ctx = context.Background()
+
+	suite.ledgerService = services.NewLedgerService(
+		suite.ledgerRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *LedgerServiceTestSuite) TearDownTest() {
+	suite.ledgerRepo.AssertExpectations(suite.T())
+}
+
+// expectAppend records the transactions posted to the ledger
+func (suite *LedgerServiceTestSuite) expectAppend() {
+	suite.ledgerRepo.On("Append", suite.ctx, mock.AnythingOfType("[]*models.LedgerEntry")).
+		Run(func(args mock.Arguments) {
+			suite.posted = append(suite.posted, args.Get(1).([]*models.LedgerEntry))
+		}).
+		Return(true, nil)
+}
+
+// Test RecordPayment - A payment posts its capture and its fee as balanced transactions on the gateway's clearing account
+func (suite *LedgerServiceTestSuite) TestRecordPayment_PostsCaptureAndFee() {
+	suite.expectAppend()
+	payment := &models.Payment{ID: "payment-1", OrderID: "order-1", Amount: 100, ProcessingFee: 2.9, Currency: "EUR", Gateway: "stripe"}
+
+	// Execute
+	err := suite.ledgerService.RecordPayment(suite.ctx, payment)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(suite.posted, 2)
+
+	capture, fee := suite.posted[0], suite.posted[1]
+	suite.Equal([]string{"payment:payment-1", "payment:payment-1"}, []string{capture[0].TransactionKey, capture[1].TransactionKey})
+	suite.Equal([]int{1, 2}, []int{capture[0].Line, capture[1].Line})
+	suite.Equal("gateway_clearing:stripe", capture[0].Account)
+	suite.Equal(models.LedgerDebit, capture[0].Direction)
+	suite.Equal(models.LedgerAccountRevenue, capture[1].Account)
+	suite.Equal(models.LedgerCredit, capture[1].Direction)
+	for _, entry := range capture {
+		suite.Equal(100.0, entry.Amount)
+		suite.Equal("EUR", entry.Currency)
+		suite.Equal("payment-1", entry.PaymentID)
+		suite.Equal("order-1", entry.OrderID)
+	}
+
+	suite.Equal("fee:payment-1", fee[0].TransactionKey)
+	suite.Equal(models.LedgerAccountProcessingFees, fee[0].Account)
+	suite.Equal(models.LedgerDebit, fee[0].Direction)
+	suite.Equal("gateway_clearing:stripe", fee[1].Account)
+	suite.Equal(models.LedgerCredit, fee[1].Direction)
+	suite.Equal(2.9, fee[1].Amount)
+}
+
+// Test RecordPayment - A transaction that was already posted is not an error
+func (suite *LedgerServiceTestSuite) TestRecordPayment_AlreadyPosted() {
+	suite.ledgerRepo.On("Append", suite.ctx, mock.AnythingOfType("[]*models.LedgerEntry")).Return(false, nil).Once()
+
+	// Execute
+	err := suite.ledgerService.RecordPayment(suite.ctx, &models.Payment{ID: "payment-1", OrderID: "order-1", Amount: 10})
+
+	// Assert
+	suite.NoError(err)
+}
+
+// Test RecordStoreCredit - Issued credit is owed to the customer and redeemed credit settles it against revenue
+func (suite *LedgerServiceTestSuite) TestRecordStoreCredit_IssuedAndRedeemed() {
+	suite.expectAppend()
+
+	// Execute
+	suite.Require().NoError(suite.ledgerService.RecordStoreCredit(suite.ctx, services.StoreCreditMovement{
+		MovementID: "credit-1", UserID: "user-1", Amount: 15, Reason: "late delivery",
+	}))
+	suite.Require().NoError(suite.ledgerService.RecordStoreCredit(suite.ctx, services.StoreCreditMovement{
+		MovementID: "credit-2", UserID: "user-1", OrderID: "order-2", Amount: 5, Redeemed: true,
+	}))
+
+	// Assert
+	suite.Require().Len(suite.posted, 2)
+	issued, redeemed := suite.posted[0], suite.posted[1]
+	suite.Equal("store_credit_issued:credit-1", issued[0].TransactionKey)
+	suite.Equal([]string{"revenue", "customer:user-1"}, []string{issued[0].Account, issued[1].Account})
+	suite.Equal("USD", issued[0].Currency)
+	suite.Equal("late delivery", issued[0].Reference)
+
+	suite.Equal("store_credit_redeemed:credit-2", redeemed[0].TransactionKey)
+	suite.Equal([]string{"customer:user-1", "revenue"}, []string{redeemed[0].Account, redeemed[1].Account})
+	suite.Equal(models.LedgerDebit, redeemed[0].Direction)
+	suite.Equal("order-2", redeemed[1].OrderID)
+}
+
+// Test RecordGiftCardRedemption - Movements without an amount or an identity are rejected before posting
+func (suite *LedgerServiceTestSuite) TestRecordGiftCardRedemption_Validation() {
+	err := suite.ledgerService.RecordGiftCardRedemption(suite.ctx, services.GiftCardRedemption{
+		RedemptionID: "redemption-1", GiftCardID: "card-1", OrderID: "order-1",
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+
+	err = suite.ledgerService.RecordGiftCardRedemption(suite.ctx, services.GiftCardRedemption{OrderID: "order-1", Amount: 20})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "Append", mock.Anything, mock.Anything)
+}
+
+// Test GetBalances - Balances are reported on each account's normal side and summed across accounts of a kind
+func (suite *LedgerServiceTestSuite) TestGetBalances_NormalSides() {
+	suite.ledgerRepo.On("SumByAccount", suite.ctx, "gateway_clearing").Return([]repository.LedgerAccountTotals{
+		{Account: "gateway_clearing:paypal", Debits: 50, Credits: 1.75, Entries: 3},
+		{Account: "gateway_clearing:stripe", Debits: 100, Credits: 32.9, Entries: 4},
+	}, nil)
+	suite.ledgerRepo.On("SumByAccount", suite.ctx, "customer:user-1").Return([]repository.LedgerAccountTotals{
+		{Account: "customer:user-1", Debits: 5, Credits: 15, Entries: 2},
+	}, nil)
+
+	// Execute
+	clearing, err := suite.ledgerService.GetBalances(suite.ctx, services.LedgerBalanceRequest{Account: "gateway_clearing"})
+	suite.Require().NoError(err)
+	customer, err := suite.ledgerService.GetBalances(suite.ctx, services.LedgerBalanceRequest{Account: "customer:user-1"})
+	suite.Require().NoError(err)
+
+	// Assert
+	suite.Require().Len(clearing.Accounts, 2)
+	suite.Equal(models.LedgerDebit, clearing.Accounts[0].NormalBalance)
+	suite.Equal(48.25, clearing.Accounts[0].Balance)
+	suite.Equal(67.1, clearing.Accounts[1].Balance)
+	suite.Equal(115.35, clearing.Balance)
+
+	suite.Equal(models.LedgerCredit, customer.Accounts[0].NormalBalance)
+	suite.Equal(10.0, customer.Balance)
+}
+
+// Test CheckConsistency - Unbalanced transactions and payments or refunds missing from the ledger are reported
+func (suite *LedgerServiceTestSuite) TestCheckConsistency_ReportsFindings() {
+	suite.ledgerRepo.On("ListUnbalanced", suite.ctx, mock.AnythingOfType("int")).Return([]repository.LedgerTransactionTotals{
+		{TransactionKey: "payment:payment-9", Debits: 10, Credits: 0},
+	}, nil)
+	suite.ledgerRepo.On("ListPaymentMismatches", suite.ctx, models.LedgerTransactionPayment,
+		[]models.PaymentStatus{models.PaymentStatusCompleted, models.PaymentStatusRefunded}, mock.AnythingOfType("int")).
+		Return([]repository.LedgerPaymentMismatch{}, nil)
+	suite.ledgerRepo.On("ListPaymentMismatches", suite.ctx, models.LedgerTransactionRefund,
+		[]models.PaymentStatus{models.PaymentStatusRefunded}, mock.AnythingOfType("int")).
+		Return([]repository.LedgerPaymentMismatch{
+			{PaymentID: "payment-2", OrderID: "order-2", Status: models.PaymentStatusRefunded, Amount: 30},
+		}, nil)
+
+	// Execute
+	report, err := suite.ledgerService.CheckConsistency(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.False(report.Consistent)
+	suite.Equal([]services.LedgerTransactionImbalance{{TransactionKey: "payment:payment-9", Debits: 10}}, report.UnbalancedTransactions)
+	suite.Empty(report.PaymentMismatches)
+	suite.Equal([]services.LedgerPaymentDiscrepancy{
+		{PaymentID: "payment-2", OrderID: "order-2", Status: models.PaymentStatusRefunded, Amount: 30},
+	}, report.RefundMismatches)
+}
+
+// TestLedgerServiceTestSuite runs the test suite
+func TestLedgerServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(LedgerServiceTestSuite))
+}
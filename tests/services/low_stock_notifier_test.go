@@ -0,0 +1,165 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingDigester passes the notifications submitted to it on to the test
+type recordingDigester struct {
+	submitted chan *notifications.Notification
+}
+
+func newRecordingDigester() *recordingDigester {
+	return &recordingDigester{submitted: make(chan *notifications.Notification, 10)}
+}
+
+func (d *recordingDigester) Submit(notification *notifications.Notification) error {
+	d.submitted <- notification
+	return nil
+}
+
+// next returns the next submitted notification, failing the test if none arrives
+func (d *recordingDigester) next(t *testing.T) *notifications.Notification {
+	select {
+	case notification := <-d.submitted:
+		return notification
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was submitted")
+		return nil
+	}
+}
+
+// LowStockNotifierTestSuite defines the test suite for the low-stock notifier
+type LowStockNotifierTestSuite struct {
+	suite.Suite
+	notifier      services.StockChangeNotifier
+	inventoryRepo *mocks.MockInventoryRepository
+	userRepo      *mocks.MockUserRepository
+	digests       *recordingDigester
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *LowStockNotifierTestSuite) SetupTest() {
+	suite.inventoryRepo = new(mocks.MockInventoryRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.digests = newRecordingDigester()
+	suite.ctx = context.Background()
+
+	suite.notifier = services.NewLowStockNotifier(
+		services.LowStockSettings{},
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.digests,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *LowStockNotifierTestSuite) TearDownTest() {
+	suite.inventoryRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// lowStockInventory returns the inventory of a product with the available units and min stock
+func lowStockInventory(productID, name string, available, minStock int) *models.Inventory {
+	return &models.Inventory{
+		ProductID: productID,
+		Quantity:  available,
+		Available: available,
+		MinStock:  minStock,
+		Product:   &models.Product{ID: productID, Name: name, SKU: "SKU-" + productID},
+	}
+}
+
+// Test NotifyStockChanges - Every admin is sent a complete alert for the products at or
+// below their threshold
+func (suite *LowStockNotifierTestSuite) TestNotifyStockChanges_AlertsAdmins() {
+	// Mock expectations
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(lowStockInventory("product-1", "Widget", 2, 5), nil)
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-2").
+		Return(lowStockInventory("product-2", "Gadget", 10, 5), nil)
+	suite.userRepo.On("ListByRole", mock.Anything, models.UserRoleAdmin).
+		Return([]*models.User{{ID: "admin-1"}, {ID: "admin-2"}}, nil)
+
+	// Execute
+	suite.notifier.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1", "product-2"})
+
+	// Assert
+	for _, admin := range []string{"admin-1", "admin-2"} {
+		alert := suite.digests.next(suite.T())
+		assert.Equal(suite.T(), admin, alert.Recipient)
+		assert.Equal(suite.T(), notifications.NotificationTypeLowStock, alert.Type)
+		assert.Equal(suite.T(), string(models.NotificationChannelInApp), alert.Channel)
+		assert.Equal(suite.T(), "Widget is low on stock", alert.Subject)
+		assert.Equal(suite.T(), "Widget (SKU SKU-product-1) has 2 units available, at or below its low-stock threshold of 5.", alert.Body)
+
+		var data services.ProductLowStock
+		require.NoError(suite.T(), json.Unmarshal([]byte(alert.Data["data"].(string)), &data))
+		assert.Equal(suite.T(), "product-1", data.ProductID)
+		assert.Equal(suite.T(), services.LowStockThresholdMinStock, data.ThresholdSource)
+	}
+	assert.Empty(suite.T(), suite.digests.submitted)
+}
+
+// Test NotifyStockChanges - A product is alerted on once each time it runs low, not on
+// every change while it stays low
+func (suite *LowStockNotifierTestSuite) TestNotifyStockChanges_AlertsOncePerRunLow() {
+	override := 3
+
+	// Mock expectations
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(lowStockInventory("product-1", "Widget", 2, 5), nil).Once()
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(lowStockInventory("product-1", "Widget", 1, 5), nil).Once()
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(lowStockInventory("product-1", "Widget", 20, 5), nil).Once()
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(lowStockInventory("product-1", "Widget", 4, 5), nil).Once()
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-2").
+		Return(lowStockInventory("product-2", "Gadget", 0, 5), nil).Once()
+	gizmo := lowStockInventory("product-3", "Gizmo", 3, 0)
+	gizmo.Product.LowStockThreshold = &override
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-3").Return(gizmo, nil).Once()
+	suite.userRepo.On("ListByRole", mock.Anything, models.UserRoleAdmin).
+		Return([]*models.User{{ID: "admin-1"}}, nil)
+
+	// Execute and assert: alerts are submitted in the order of the changed products, so
+	// a wrong alert would arrive before the expected one
+	suite.notifier.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1"})
+	assert.Equal(suite.T(), "Widget is low on stock", suite.digests.next(suite.T()).Subject)
+
+	// Still low
+	suite.notifier.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1", "product-2"})
+	assert.Equal(suite.T(), "Gadget is low on stock", suite.digests.next(suite.T()).Subject)
+
+	// Restocked
+	suite.notifier.NotifyStockChanges(suite.ctx, services.StockChangeReasonAdjustment, []string{"product-1", "product-3"})
+	assert.Equal(suite.T(), "Gizmo is low on stock", suite.digests.next(suite.T()).Subject)
+
+	// Low again
+	suite.notifier.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1"})
+	alert := suite.digests.next(suite.T())
+	assert.Equal(suite.T(), "Widget is low on stock", alert.Subject)
+	assert.Contains(suite.T(), alert.Body, "has 4 units available")
+}
+
+// TestLowStockNotifierTestSuite runs the test suite
+func TestLowStockNotifierTestSuite(t *testing.T) {
+	suite.Run(t, new(LowStockNotifierTestSuite))
+}
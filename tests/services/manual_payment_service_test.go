@@ -0,0 +1,256 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ManualPaymentServiceTestSuite defines the test suite for ManualPaymentService
+type ManualPaymentServiceTestSuite struct {
+	suite.Suite
+	manualPaymentService services.ManualPaymentService
+	paymentRepo          *mocks.MockPaymentRepository
+	orderRepo            *mocks.MockOrderRepository
+	ledgerRepo           *mocks.MockLedgerRepository
+	actor                services.AuditActor
+	ctx                  context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ManualPaymentServiceTestSuite) SetupTest() {
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.ledgerRepo = new(mocks.MockLedgerRepository)
+	suite.actor = services.AuditActor{UserID: "admin-1", IPAddress: "10.0.0.7", UserAgent: "backoffice"}
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.manualPaymentService = services.NewManualPaymentService(
+		suite.paymentRepo,
+		suite.orderRepo,
+		nil, // No activity recorder
+		services.NewLedgerService(suite.ledgerRepo, log),
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ManualPaymentServiceTestSuite) TearDownTest() {
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.ledgerRepo.AssertExpectations(suite.T())
+}
+
+// pendingOrder returns a pending bank transfer order
+func (suite *ManualPaymentServiceTestSuite) pendingOrder() *models.Order {
+	return &models.Order{
+		ID:            "order-1",
+		UserID:        "user-1",
+		Status:        models.OrderStatusPending,
+		TotalAmount:   120.5,
+		Currency:      "USD",
+		PaymentMethod: models.PaymentMethodBankTransfer,
+	}
+}
+
+// Test RecordPayment - A bank transfer is recorded as completed with its proof, marks the order paid and is audit-logged
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_Success() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.pendingOrder(), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{
+		{ID: "payment-0", Status: models.PaymentStatusFailed},
+	}, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(0.00, nil)
+	suite.paymentRepo.On("GetByIdempotencyKey", suite.ctx, "manual:bank_transfer:TRF-2291").Return(nil, nil)
+
+	var record repository.ManualPaymentRecord
+	suite.paymentRepo.On("RecordManual", suite.ctx, mock.AnythingOfType("repository.ManualPaymentRecord")).
+		Run(func(args mock.Arguments) { record = args.Get(1).(repository.ManualPaymentRecord) }).
+		Return(true, nil)
+	suite.ledgerRepo.On("Append", suite.ctx, mock.AnythingOfType("[]*models.LedgerEntry")).Return(true, nil).Once()
+
+	// Execute
+	response, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method:    models.PaymentMethodBankTransfer,
+		Amount:    120.5,
+		Reference: " TRF-2291 ",
+		Note:      "Received on the main account",
+		Proof:     &services.PaymentProofUpload{FileName: "receipt.pdf", Content: []byte("%PDF-1.7\n% receipt")},
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.OrderStatusPaid, response.OrderStatus)
+	suite.Equal(models.PaymentStatusCompleted, response.Payment.Status)
+	suite.Equal("TRF-2291", response.Reference)
+
+	payment := record.Payment
+	suite.Equal(models.OrderStatusPending, record.OrderStatus)
+	suite.Equal(0.00, record.PaidAmount)
+	suite.Equal(120.5, payment.Amount)
+	suite.Equal(models.PaymentStatusCompleted, payment.Status)
+	suite.Equal("manual", payment.Gateway)
+	suite.Equal("TRF-2291", payment.ExternalReference)
+	suite.Equal("admin-1", *payment.RecordedBy)
+	suite.NotNil(payment.ProcessedAt)
+	suite.JSONEq(`{"note":"Received on the main account"}`, *payment.Metadata)
+
+	suite.Require().NotNil(record.Proof)
+	suite.Equal("application/pdf", record.Proof.ContentType)
+	suite.Equal("receipt.pdf", record.Proof.FileName)
+	suite.Equal("admin-1", record.Proof.UploadedBy)
+	suite.Len(record.Proof.SHA256, 64)
+
+	audit := record.AuditLog
+	suite.Equal("order", audit.EntityType)
+	suite.Equal("order-1", audit.EntityID)
+	suite.Equal(models.AuditActionManualPayment, audit.Action)
+	suite.Equal("admin-1", *audit.UserID)
+	suite.Equal("10.0.0.7", audit.IPAddress)
+	suite.Equal("backoffice", audit.UserAgent)
+	suite.JSONEq(`{"status":"pending"}`, audit.OldValues)
+
+	var values map[string]interface{}
+	suite.Require().NoError(json.Unmarshal([]byte(audit.NewValues), &values))
+	suite.Equal("paid", values["status"])
+	suite.Equal(payment.ID, values["payment_id"])
+	suite.Equal(record.Proof.ID, values["proof_id"])
+}
+
+// Test RecordPayment - Gateway methods and amounts over what is left to pay are rejected
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_Validation() {
+	_, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodCreditCard, Amount: 120.5, Reference: "TRF-1",
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.pendingOrder(), nil).Once()
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{}, nil).Once()
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(20.5, nil).Once()
+	_, err = suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodBankTransfer, Amount: 120.5, Reference: "TRF-1",
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "exceeds the 100.00 left to pay of order total 120.50")
+
+	suite.paymentRepo.AssertNotCalled(suite.T(), "RecordManual", mock.Anything, mock.Anything)
+}
+
+// Test RecordPayment - A payment of part of what is left is recorded against the paid amount it
+// was validated with, and leaves the order pending
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_PartialKeepsOrderPending() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.pendingOrder(), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{
+		{ID: "payment-0", Amount: 20.5, Status: models.PaymentStatusCompleted},
+	}, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(20.5, nil)
+	suite.paymentRepo.On("GetByIdempotencyKey", suite.ctx, "manual:bank_transfer:TRF-2292").Return(nil, nil)
+
+	var record repository.ManualPaymentRecord
+	suite.paymentRepo.On("RecordManual", suite.ctx, mock.AnythingOfType("repository.ManualPaymentRecord")).
+		Run(func(args mock.Arguments) { record = args.Get(1).(repository.ManualPaymentRecord) }).
+		Return(true, nil)
+	suite.ledgerRepo.On("Append", suite.ctx, mock.AnythingOfType("[]*models.LedgerEntry")).Return(true, nil).Once()
+
+	// Execute
+	response, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodBankTransfer, Amount: 50, Reference: "TRF-2292",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.OrderStatusPending, response.OrderStatus)
+	suite.Equal(50.00, record.Payment.Amount)
+	suite.Equal(20.5, record.PaidAmount)
+
+	var values map[string]interface{}
+	suite.Require().NoError(json.Unmarshal([]byte(record.AuditLog.NewValues), &values))
+	suite.Equal("pending", values["status"])
+}
+
+// Test RecordPayment - A reference already recorded for the method is a conflict
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_DuplicateReference() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.pendingOrder(), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{}, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(0.00, nil)
+	suite.paymentRepo.On("GetByIdempotencyKey", suite.ctx, "manual:bank_transfer:TRF-2291").
+		Return(&models.Payment{ID: "payment-9"}, nil)
+
+	// Execute
+	_, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodBankTransfer, Amount: 120.5, Reference: "TRF-2291",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.Contains(err.Error(), "payment-9")
+}
+
+// Test RecordPayment - Proofs of an unsupported type are rejected before anything is recorded
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_UnsupportedProof() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.pendingOrder(), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{}, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(0.00, nil)
+	suite.paymentRepo.On("GetByIdempotencyKey", suite.ctx, "manual:bank_transfer:TRF-2291").Return(nil, nil)
+
+	// Execute
+	_, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodBankTransfer, Amount: 120.5, Reference: "TRF-2291",
+		Proof: &services.PaymentProofUpload{FileName: "receipt.html", Content: []byte("<html><body>paid</body></html>")},
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+	suite.paymentRepo.AssertNotCalled(suite.T(), "RecordManual", mock.Anything, mock.Anything)
+}
+
+// Test RecordPayment - An order paid through the gateway while the payment was recorded is a conflict
+func (suite *ManualPaymentServiceTestSuite) TestRecordPayment_OrderChanged() {
+	order := suite.pendingOrder()
+	order.PaymentMethod = models.PaymentMethodCash
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{}, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(0.00, nil)
+	suite.paymentRepo.On("GetByIdempotencyKey", suite.ctx, "manual:cash:COD-77").Return(nil, nil)
+	suite.paymentRepo.On("RecordManual", suite.ctx, mock.AnythingOfType("repository.ManualPaymentRecord")).Return(false, nil)
+
+	// Execute
+	_, err := suite.manualPaymentService.RecordPayment(suite.ctx, "order-1", suite.actor, services.RecordManualPaymentRequest{
+		Method: models.PaymentMethodCash, Amount: 120.5, Reference: "COD-77",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "Append", mock.Anything, mock.Anything)
+}
+
+// Test GetProof - A payment without a proof is not found
+func (suite *ManualPaymentServiceTestSuite) TestGetProof_NotFound() {
+	suite.paymentRepo.On("GetProof", suite.ctx, "payment-1").Return(nil, nil)
+
+	// Execute
+	proof, err := suite.manualPaymentService.GetProof(suite.ctx, "payment-1")
+
+	// Assert
+	suite.Nil(proof)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// TestManualPaymentServiceTestSuite runs the test suite
+func TestManualPaymentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ManualPaymentServiceTestSuite))
+}
@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// NotificationDigestSenderTestSuite defines the test suite for NotificationDigestSender
+type NotificationDigestSenderTestSuite struct {
+	suite.Suite
+	notifications *sentNotifications
+	scheduler     *notifications.DigestScheduler
+}
+
+// SetupTest runs before each test in the suite
+func (suite *NotificationDigestSenderTestSuite) SetupTest() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.notifications = &sentNotifications{}
+
+	config := notifications.DefaultDigestConfig()
+	config.CheckInterval = time.Hour
+	suite.scheduler = notifications.NewDigestScheduler(config, services.NewNotificationDigestSender(suite.notifications, log), log)
+	suite.Require().NoError(suite.scheduler.Start(context.Background()))
+}
+
+// Test Dispatch - Buffered low-stock alerts reach the notification service as one
+// stored digest
+func (suite *NotificationDigestSenderTestSuite) TestDispatch_Digest() {
+	for _, title := range []string{"Widget is low on stock", "Gadget is low on stock"} {
+		alert := notifications.NewNotification(notifications.NotificationTypeLowStock, "", "user-1")
+		alert.Subject = title
+		suite.Require().NoError(suite.scheduler.Submit(alert))
+	}
+	assert.Empty(suite.T(), suite.notifications.sent)
+
+	// Execute
+	suite.Require().NoError(suite.scheduler.Stop())
+
+	// Assert
+	require.Len(suite.T(), suite.notifications.sent, 1)
+	digest := suite.notifications.sent[0]
+	assert.Equal(suite.T(), "user-1", digest.UserID)
+	assert.Equal(suite.T(), string(models.NotificationTypeDigest), digest.Type)
+	assert.Equal(suite.T(), "Your hourly summary (2 updates)", digest.Title)
+	assert.Contains(suite.T(), digest.Body, "- Widget is low on stock\n")
+
+	var data struct {
+		NotificationIDs []string       `json:"notification_ids"`
+		CountsByType    map[string]int `json:"counts_by_type"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(digest.Data), &data))
+	assert.Len(suite.T(), data.NotificationIDs, 2)
+	assert.Equal(suite.T(), 2, data.CountsByType[string(models.NotificationTypeLowStock)])
+}
+
+// Test Dispatch - Urgent notifications are sent as they were requested
+func (suite *NotificationDigestSenderTestSuite) TestDispatch_Immediate() {
+	alert := notifications.NewNotification(notifications.NotificationType(models.NotificationTypePaymentFailed), "email", "user-1")
+	alert.Subject = "Payment failed"
+	alert.Body = "Your payment could not be processed"
+	alert.Data["data"] = `{"order_id":"order-1"}`
+
+	// Execute
+	suite.Require().NoError(suite.scheduler.Submit(alert))
+
+	// Assert
+	require.Len(suite.T(), suite.notifications.sent, 1)
+	assert.Equal(suite.T(), services.SendNotificationRequest{
+		UserID:  "user-1",
+		Type:    string(models.NotificationTypePaymentFailed),
+		Channel: "email",
+		Title:   "Payment failed",
+		Body:    "Your payment could not be processed",
+		Data:    `{"order_id":"order-1"}`,
+	}, suite.notifications.sent[0])
+	suite.Require().NoError(suite.scheduler.Stop())
+}
+
+// TestNotificationDigestSenderTestSuite runs the test suite
+func TestNotificationDigestSenderTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationDigestSenderTestSuite))
+}
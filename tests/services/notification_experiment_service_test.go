@@ -0,0 +1,295 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// NotificationExperimentServiceTestSuite defines the test suite for NotificationExperimentService
+type NotificationExperimentServiceTestSuite struct {
+	suite.Suite
+	experimentService services.NotificationExperimentService
+	experimentRepo    *mocks.MockNotificationExperimentRepository
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *NotificationExperimentServiceTestSuite) SetupTest() {
+	suite.experimentRepo = new(mocks.MockNotificationExperimentRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.experimentService = services.NewNotificationExperimentService(
+		suite.experimentRepo,
+		i18n.NewTranslator(),
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *NotificationExperimentServiceTestSuite) TearDownTest() {
+	suite.experimentRepo.AssertExpectations(suite.T())
+}
+
+// runningExperiment returns a running experiment of shipped order notifications
+func (suite *NotificationExperimentServiceTestSuite) runningExperiment() *models.NotificationExperiment {
+	return &models.NotificationExperiment{
+		ID:                    "experiment-1",
+		Name:                  "Friendlier shipping",
+		Type:                  models.NotificationTypeOrderShipped,
+		Locale:                "en",
+		VariantATitle:         "Order shipped",
+		VariantABody:          "Your order {order_id} is on its way.",
+		VariantBTitle:         "Good news!",
+		VariantBBody:          "Order {order_id} just left our warehouse.",
+		SplitPercent:          50,
+		ConversionGoal:        models.NotificationConversionOrder,
+		ConversionWindowHours: 72,
+		Status:                models.NotificationExperimentStatusRunning,
+	}
+}
+
+// Test CreateExperiment - The split, conversion window and locale default
+func (suite *NotificationExperimentServiceTestSuite) TestCreateExperiment_Defaults() {
+	var saved *models.NotificationExperiment
+	suite.experimentRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.NotificationExperiment")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*models.NotificationExperiment) }).
+		Return(true, nil)
+
+	// Execute
+	experiment, err := suite.experimentService.CreateExperiment(suite.ctx, "admin-1", services.CreateNotificationExperimentRequest{
+		Name:           "Friendlier shipping",
+		Type:           string(models.NotificationTypeOrderShipped),
+		VariantA:       services.NotificationTemplateVariant{Title: "Order shipped", Body: "Your order {order_id} is on its way."},
+		VariantB:       services.NotificationTemplateVariant{Title: "Good news!", Body: "Order {order_id} just left our warehouse."},
+		ConversionGoal: string(models.NotificationConversionOrder),
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Same(saved, experiment)
+	suite.Equal(50, experiment.SplitPercent)
+	suite.Equal(72, experiment.ConversionWindowHours)
+	suite.Equal("en", experiment.Locale)
+	suite.Equal(models.NotificationExperimentStatusRunning, experiment.Status)
+	suite.Equal("admin-1", experiment.CreatedBy)
+	suite.False(experiment.StartedAt.IsZero())
+}
+
+// Test CreateExperiment - A type and locale run one experiment at a time
+func (suite *NotificationExperimentServiceTestSuite) TestCreateExperiment_AlreadyRunning() {
+	suite.experimentRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.NotificationExperiment")).Return(false, nil)
+
+	// Execute
+	experiment, err := suite.experimentService.CreateExperiment(suite.ctx, "admin-1", services.CreateNotificationExperimentRequest{
+		Name:           "Friendlier shipping",
+		Type:           string(models.NotificationTypeOrderShipped),
+		Locale:         "fr",
+		VariantA:       services.NotificationTemplateVariant{Title: "A", Body: "A"},
+		VariantB:       services.NotificationTemplateVariant{Title: "B", Body: "B"},
+		ConversionGoal: string(models.NotificationConversionPayment),
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(experiment)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test CreateExperiment - Experiments are only run in supported locales
+func (suite *NotificationExperimentServiceTestSuite) TestCreateExperiment_UnsupportedLocale() {
+	// Execute
+	experiment, err := suite.experimentService.CreateExperiment(suite.ctx, "admin-1", services.CreateNotificationExperimentRequest{
+		Name:           "Friendlier shipping",
+		Type:           string(models.NotificationTypeOrderShipped),
+		Locale:         "de",
+		VariantA:       services.NotificationTemplateVariant{Title: "A", Body: "A"},
+		VariantB:       services.NotificationTemplateVariant{Title: "B", Body: "B"},
+		ConversionGoal: string(models.NotificationConversionPayment),
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(experiment)
+	suite.Contains(err.Error(), "unsupported locale")
+	suite.experimentRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test ApplyVariant - A running experiment words the notification by the user's variant and tags it
+func (suite *NotificationExperimentServiceTestSuite) TestApplyVariant_Running() {
+	experiment := suite.runningExperiment()
+	suite.experimentRepo.On("GetActive", suite.ctx, models.NotificationTypeOrderShipped, "en").Return(experiment, nil)
+
+	notification := &models.Notification{
+		UserID: "user-1",
+		Type:   models.NotificationTypeOrderShipped,
+		Title:  "Order shipped",
+		Body:   "Your order order-1 is on its way.",
+		Data:   `{"order_id":"order-1","status":"shipped"}`,
+	}
+
+	// Execute
+	suite.experimentService.ApplyVariant(suite.ctx, notification, "en")
+
+	// Assert
+	variant := experiment.AssignVariant("user-1")
+	suite.Require().NotNil(notification.ExperimentID)
+	suite.Equal("experiment-1", *notification.ExperimentID)
+	suite.Equal(variant, notification.Variant)
+	if variant == models.NotificationVariantB {
+		suite.Equal("Good news!", notification.Title)
+		suite.Equal("Order order-1 just left our warehouse.", notification.Body)
+	} else {
+		suite.Equal("Order shipped", notification.Title)
+		suite.Equal("Your order order-1 is on its way.", notification.Body)
+	}
+}
+
+// Test ApplyVariant - A concluded experiment sends its winner to everyone, outside the experiment
+func (suite *NotificationExperimentServiceTestSuite) TestApplyVariant_Concluded() {
+	experiment := suite.runningExperiment()
+	experiment.Status = models.NotificationExperimentStatusConcluded
+	experiment.Winner = models.NotificationVariantB
+	suite.experimentRepo.On("GetActive", suite.ctx, models.NotificationTypeOrderShipped, "en").Return(experiment, nil)
+
+	notification := &models.Notification{
+		UserID: "user-1",
+		Type:   models.NotificationTypeOrderShipped,
+		Data:   `{"order_id":"order-1"}`,
+	}
+
+	// Execute
+	suite.experimentService.ApplyVariant(suite.ctx, notification, "en")
+
+	// Assert
+	suite.Nil(notification.ExperimentID)
+	suite.Empty(notification.Variant)
+	suite.Equal("Good news!", notification.Title)
+	suite.Equal("Order order-1 just left our warehouse.", notification.Body)
+}
+
+// Test AssignVariant - Users keep their variant, and the split follows the split percent
+func (suite *NotificationExperimentServiceTestSuite) TestAssignVariant_Split() {
+	experiment := suite.runningExperiment()
+	experiment.SplitPercent = 30
+
+	variantB := 0
+	for i := 0; i < 2000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		variant := experiment.AssignVariant(userID)
+		suite.Equal(variant, experiment.AssignVariant(userID))
+		if variant == models.NotificationVariantB {
+			variantB++
+		}
+	}
+
+	suite.InDelta(600, variantB, 100)
+}
+
+// Test StopExperiment - Naming a winner concludes a running experiment
+func (suite *NotificationExperimentServiceTestSuite) TestStopExperiment_Winner() {
+	suite.experimentRepo.On("GetByID", suite.ctx, "experiment-1").Return(suite.runningExperiment(), nil)
+	suite.experimentRepo.On("End", suite.ctx, "experiment-1",
+		[]models.NotificationExperimentStatus{models.NotificationExperimentStatusRunning},
+		models.NotificationExperimentStatusConcluded, models.NotificationVariantB, mock.AnythingOfType("time.Time")).
+		Return(true, nil)
+
+	// Execute
+	experiment, err := suite.experimentService.StopExperiment(suite.ctx, "experiment-1", services.StopNotificationExperimentRequest{
+		Winner: models.NotificationVariantB,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.NotificationExperimentStatusConcluded, experiment.Status)
+	suite.Equal(models.NotificationVariantB, experiment.Winner)
+	suite.NotNil(experiment.EndedAt)
+}
+
+// Test StopExperiment - A stopped experiment cannot be stopped again
+func (suite *NotificationExperimentServiceTestSuite) TestStopExperiment_AlreadyStopped() {
+	stopped := suite.runningExperiment()
+	stopped.Status = models.NotificationExperimentStatusStopped
+	suite.experimentRepo.On("GetByID", suite.ctx, "experiment-1").Return(stopped, nil)
+	suite.experimentRepo.On("End", suite.ctx, "experiment-1", mock.Anything,
+		models.NotificationExperimentStatusStopped, "", mock.AnythingOfType("time.Time")).
+		Return(false, nil)
+
+	// Execute
+	experiment, err := suite.experimentService.StopExperiment(suite.ctx, "experiment-1", services.StopNotificationExperimentRequest{})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(experiment)
+	suite.Contains(err.Error(), "already stopped")
+}
+
+// Test GetResults - Variants are compared on conversion rate, with lift and significance
+func (suite *NotificationExperimentServiceTestSuite) TestGetResults() {
+	experiment := suite.runningExperiment()
+	suite.experimentRepo.On("GetByID", suite.ctx, "experiment-1").Return(experiment, nil)
+	suite.experimentRepo.On("SummarizeVariants", suite.ctx, experiment).Return([]repository.NotificationVariantSummary{
+		{
+			Variant:                     models.NotificationVariantA,
+			NotificationDeliverySummary: repository.NotificationDeliverySummary{Notifications: 1000, Sent: 1000, Delivered: 950, Read: 400},
+			Conversions:                 100,
+		},
+		{
+			Variant:                     models.NotificationVariantB,
+			NotificationDeliverySummary: repository.NotificationDeliverySummary{Notifications: 1000, Sent: 1000, Delivered: 960, Read: 480},
+			Conversions:                 150,
+		},
+	}, nil)
+
+	// Execute
+	results, err := suite.experimentService.GetResults(suite.ctx, "experiment-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(results.Variants, 2)
+	suite.Equal(10.0, results.Variants[0].ConversionRate)
+	suite.Equal(15.0, results.Variants[1].ConversionRate)
+	suite.Equal(50.0, results.Variants[1].ReadRate)
+	suite.Equal(models.NotificationVariantB, results.Leader)
+	suite.Equal(50.0, results.Lift)
+	suite.True(results.Significant)
+}
+
+// Test GetResults - Small samples are not significant, and unsent variants are still reported
+func (suite *NotificationExperimentServiceTestSuite) TestGetResults_NotSignificant() {
+	experiment := suite.runningExperiment()
+	suite.experimentRepo.On("GetByID", suite.ctx, "experiment-1").Return(experiment, nil)
+	suite.experimentRepo.On("SummarizeVariants", suite.ctx, experiment).Return([]repository.NotificationVariantSummary{
+		{
+			Variant:                     models.NotificationVariantA,
+			NotificationDeliverySummary: repository.NotificationDeliverySummary{Notifications: 10, Sent: 10},
+			Conversions:                 2,
+		},
+	}, nil)
+
+	// Execute
+	results, err := suite.experimentService.GetResults(suite.ctx, "experiment-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(results.Variants, 2)
+	suite.Equal(0, results.Variants[1].Notifications)
+	suite.Equal(models.NotificationVariantA, results.Leader)
+	suite.False(results.Significant)
+}
+
+// TestNotificationExperimentServiceTestSuite runs the test suite
+func TestNotificationExperimentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationExperimentServiceTestSuite))
+}
@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// stubNotificationSender fails the channels it is told to and records every attempt
+type stubNotificationSender struct {
+	mutex    sync.Mutex
+	failures map[models.NotificationChannel]error
+	attempts []models.NotificationChannel
+}
+
+func (s *stubNotificationSender) Send(ctx context.Context, channel models.NotificationChannel, notification *models.Notification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.attempts = append(s.attempts, channel)
+	return s.failures[channel]
+}
+
+// NotificationFailoverTestSuite defines the test suite for notification channel failover
+type NotificationFailoverTestSuite struct {
+	suite.Suite
+	notificationService services.NotificationService
+	notificationRepo    *mocks.MockNotificationRepository
+	userRepo            *mocks.MockUserRepository
+	sender              *stubNotificationSender
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *NotificationFailoverTestSuite) SetupTest() {
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.sender = &stubNotificationSender{failures: make(map[models.NotificationChannel]error)}
+	suite.ctx = context.Background()
+
+	settings, err := services.NewNotificationFailoverSettings(map[string][]string{
+		"order_shipped": {"sms", "in_app"},
+		"*":             {"in_app"},
+	}, 2, time.Minute)
+	suite.Require().NoError(err)
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.notificationService = services.NewNotificationService(
+		suite.notificationRepo,
+		suite.userRepo,
+		suite.sender,
+		services.NewNotificationFailover(settings, log),
+		nil,
+		log,
+	)
+
+	suite.userRepo.On("GetByID", mock.Anything, "user-1").Return(&models.User{ID: "user-1"}, nil).Maybe()
+	suite.notificationRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			notification := args.Get(1).(*models.Notification)
+			notification.ID = "notification-1"
+		}).
+		Return(nil).Maybe()
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *NotificationFailoverTestSuite) TearDownTest() {
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// send sends one notification of the given type on the given channel to user-1
+func (suite *NotificationFailoverTestSuite) send(notificationType string, channel models.NotificationChannel) error {
+	return suite.notificationService.SendNotification(suite.ctx, services.SendNotificationRequest{
+		UserID:  "user-1",
+		Type:    notificationType,
+		Channel: string(channel),
+		Title:   "Order update",
+		Body:    "Your order has shipped",
+	})
+}
+
+// Test SendNotification - A failing channel falls back along its type's policy, recording the delivering channel
+func (suite *NotificationFailoverTestSuite) TestSendNotification_FallsBackPerTypePolicy() {
+	suite.sender.failures[models.NotificationChannelEmail] = fmt.Errorf("mailbox full: %w", services.ErrNotificationBounced)
+
+	// Mock expectations
+	suite.notificationRepo.On("MarkSent", suite.ctx, "notification-1", models.NotificationChannelSMS, mock.AnythingOfType("time.Time")).Return(nil).Once()
+	suite.notificationRepo.On("MarkSent", suite.ctx, "notification-1", models.NotificationChannelInApp, mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	// Execute
+	suite.NoError(suite.send("order_shipped", models.NotificationChannelEmail))
+	suite.NoError(suite.send("promotion", models.NotificationChannelEmail))
+
+	// Assert
+	suite.Equal([]models.NotificationChannel{
+		models.NotificationChannelEmail, models.NotificationChannelSMS,
+		models.NotificationChannelEmail, models.NotificationChannelInApp,
+	}, suite.sender.attempts)
+}
+
+// Test SendNotification - A channel whose provider keeps failing is skipped, while bounces never trip it
+func (suite *NotificationFailoverTestSuite) TestSendNotification_SkipsChannelWithOpenCircuit() {
+	// Mock expectations
+	suite.notificationRepo.On("MarkSent", suite.ctx, "notification-1", models.NotificationChannelInApp, mock.AnythingOfType("time.Time")).Return(nil).Times(6)
+
+	// Bounces fall back without counting against the email provider
+	suite.sender.failures[models.NotificationChannelEmail] = fmt.Errorf("no such mailbox: %w", services.ErrNotificationBounced)
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.send("promotion", models.NotificationChannelEmail))
+	}
+	suite.Len(suite.sender.attempts, 6)
+
+	// Two provider failures open the circuit, after which email is not attempted
+	suite.sender.attempts = nil
+	suite.sender.failures[models.NotificationChannelEmail] = errors.New("smtp: connection refused")
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.send("promotion", models.NotificationChannelEmail))
+	}
+	suite.Equal([]models.NotificationChannel{
+		models.NotificationChannelEmail, models.NotificationChannelInApp,
+		models.NotificationChannelEmail, models.NotificationChannelInApp,
+		models.NotificationChannelInApp,
+	}, suite.sender.attempts)
+}
+
+// Test SendBatch - A notification no channel delivers is reported failed and not marked sent
+func (suite *NotificationFailoverTestSuite) TestSendBatch_AllChannelsFail() {
+	suite.sender.failures[models.NotificationChannelSMS] = errors.New("sms provider unavailable")
+	suite.sender.failures[models.NotificationChannelInApp] = errors.New("database unavailable")
+
+	// Execute
+	response, err := suite.notificationService.SendBatch(suite.ctx, services.SendBatchNotificationsRequest{
+		Notifications: []services.SendNotificationRequest{
+			{UserID: "user-1", Type: "order_shipped", Channel: "sms", Title: "Shipped", Body: "On its way"},
+		},
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, response.Failed)
+	suite.Equal("database unavailable", response.Results[0].Error)
+	suite.Equal([]models.NotificationChannel{models.NotificationChannelSMS, models.NotificationChannelInApp}, suite.sender.attempts)
+	suite.notificationRepo.AssertNotCalled(suite.T(), "MarkSent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestNotificationFailoverTestSuite runs the test suite
+func TestNotificationFailoverTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationFailoverTestSuite))
+}
+
+// Test NewNotificationFailoverSettings - Unknown notification types and channels are rejected
+func TestNewNotificationFailoverSettings_RejectsUnknownNames(t *testing.T) {
+	_, err := services.NewNotificationFailoverSettings(map[string][]string{"order_shiped": {"sms"}}, 5, time.Minute)
+	assert.EqualError(t, err, `unknown notification type "order_shiped"`)
+
+	_, err = services.NewNotificationFailoverSettings(map[string][]string{"*": {"pigeon"}}, 5, time.Minute)
+	assert.EqualError(t, err, `unknown notification channel "pigeon"`)
+}
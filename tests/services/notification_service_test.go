@@ -0,0 +1,264 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// NotificationServiceTestSuite defines the test suite for NotificationService
+type NotificationServiceTestSuite struct {
+	suite.Suite
+	notificationService services.NotificationService
+	notificationRepo    *mocks.MockNotificationRepository
+	userRepo            *mocks.MockUserRepository
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *NotificationServiceTestSuite) SetupTest() {
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.ctx = context.Background()
+
+	suite.notificationService = services.NewNotificationService(
+		suite.notificationRepo,
+		suite.userRepo,
+		nil, // Simulated channels
+		nil, // No channel failover
+		nil, // No wording experiments
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *NotificationServiceTestSuite) TearDownTest() {
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test SendBatch - Every recipient gets a result in request order
+func (suite *NotificationServiceTestSuite) TestSendBatch_PerRecipientResults() {
+	req := services.SendBatchNotificationsRequest{}
+	for i := 0; i < 25; i++ {
+		req.Notifications = append(req.Notifications, services.SendNotificationRequest{
+			UserID: fmt.Sprintf("user-%d", i), Type: "low_stock", Title: "Low stock", Body: "Widget is running low",
+		})
+	}
+	req.Notifications[3].UserID = "missing"
+	req.Notifications[7].Channel = "pigeon"
+
+	// Mock expectations
+	for _, notification := range req.Notifications {
+		if notification.UserID == "missing" {
+			suite.userRepo.On("GetByID", mock.Anything, "missing").Return(nil, nil).Once()
+			continue
+		}
+		suite.userRepo.On("GetByID", mock.Anything, notification.UserID).Return(&models.User{ID: notification.UserID}, nil).Once()
+	}
+	suite.notificationRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			notification := args.Get(1).(*models.Notification)
+			notification.ID = "notification-" + notification.UserID
+		}).
+		Return(nil).Times(24)
+	suite.notificationRepo.On("MarkSent", mock.Anything, mock.AnythingOfType("string"), models.NotificationChannelInApp, mock.AnythingOfType("time.Time")).
+		Return(nil).Times(23)
+
+	// Execute
+	response, err := suite.notificationService.SendBatch(suite.ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 25, response.Total)
+	assert.Equal(suite.T(), 23, response.Sent)
+	assert.Equal(suite.T(), 2, response.Failed)
+	assert.Len(suite.T(), response.Results, 25)
+
+	for i, result := range response.Results {
+		assert.Equal(suite.T(), i, result.Index)
+		assert.Equal(suite.T(), req.Notifications[i].UserID, result.UserID)
+	}
+	assert.Equal(suite.T(), services.BatchNotificationStatusSent, response.Results[0].Status)
+	assert.Equal(suite.T(), "notification-user-0", response.Results[0].NotificationID)
+
+	assert.Equal(suite.T(), services.BatchNotificationStatusFailed, response.Results[3].Status)
+	assert.Equal(suite.T(), "user not found", response.Results[3].Error)
+	assert.Empty(suite.T(), response.Results[3].NotificationID)
+
+	assert.Equal(suite.T(), services.BatchNotificationStatusFailed, response.Results[7].Status)
+	assert.Contains(suite.T(), response.Results[7].Error, "unsupported notification channel")
+	assert.Equal(suite.T(), "notification-user-7", response.Results[7].NotificationID)
+}
+
+// Test SendBatch - Repository errors fail only the affected recipient
+func (suite *NotificationServiceTestSuite) TestSendBatch_RepositoryErrorIsPerRecipient() {
+	req := services.SendBatchNotificationsRequest{Notifications: []services.SendNotificationRequest{
+		{UserID: "user-1", Title: "Hello", Body: "One"},
+		{UserID: "user-2", Title: "Hello", Body: "Two"},
+	}}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", mock.Anything, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.userRepo.On("GetByID", mock.Anything, "user-2").Return(nil, errors.New("connection reset"))
+	suite.notificationRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Notification")).Return(nil).Once()
+	suite.notificationRepo.On("MarkSent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	// Execute
+	response, err := suite.notificationService.SendBatch(suite.ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Sent)
+	assert.Equal(suite.T(), 1, response.Failed)
+	assert.Equal(suite.T(), "connection reset", response.Results[1].Error)
+}
+
+// Test SendBatch - Empty and oversized batches are rejected
+func (suite *NotificationServiceTestSuite) TestSendBatch_InvalidBatchSize() {
+	// Execute
+	response, err := suite.notificationService.SendBatch(suite.ctx, services.SendBatchNotificationsRequest{})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "batch must contain")
+
+	// Execute
+	oversized := services.SendBatchNotificationsRequest{Notifications: make([]services.SendNotificationRequest, 1001)}
+	response, err = suite.notificationService.SendBatch(suite.ctx, oversized)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+}
+
+// Test SendBatch - A cancelled context fails the remaining recipients
+func (suite *NotificationServiceTestSuite) TestSendBatch_CancelledContext() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+
+	req := services.SendBatchNotificationsRequest{Notifications: []services.SendNotificationRequest{
+		{UserID: "user-1", Title: "Hello", Body: "One"},
+	}}
+
+	// Execute
+	response, err := suite.notificationService.SendBatch(ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Failed)
+	assert.Equal(suite.T(), context.Canceled.Error(), response.Results[0].Error)
+}
+
+// Test MarkRead - Reading a notification also records its delivery
+func (suite *NotificationServiceTestSuite) TestMarkRead_RecordsDelivery() {
+	notification := &models.Notification{ID: "notification-1", UserID: "user-1", Channel: models.NotificationChannelEmail}
+
+	// Mock expectations
+	suite.notificationRepo.On("GetByID", suite.ctx, "notification-1").Return(notification, nil).Once()
+	suite.notificationRepo.On("MarkRead", suite.ctx, "notification-1", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	// Execute
+	response, err := suite.notificationService.MarkRead(suite.ctx, "user-1", "notification-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.True(response.Read)
+	suite.Require().NotNil(response.ReadAt)
+	suite.Equal(response.ReadAt, response.DeliveredAt)
+}
+
+// Test MarkRead - Other users' notifications are not found, and read ones are left alone
+func (suite *NotificationServiceTestSuite) TestMarkRead_OtherUserOrAlreadyRead() {
+	readAt := time.Now().Add(-time.Hour)
+
+	// Mock expectations
+	suite.notificationRepo.On("GetByID", suite.ctx, "notification-1").
+		Return(&models.Notification{ID: "notification-1", UserID: "user-2"}, nil).Once()
+	suite.notificationRepo.On("GetByID", suite.ctx, "notification-2").
+		Return(&models.Notification{ID: "notification-2", UserID: "user-1", Read: true, ReadAt: &readAt}, nil).Once()
+
+	// Execute
+	response, err := suite.notificationService.MarkRead(suite.ctx, "user-1", "notification-1")
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "NOT_FOUND")
+
+	// Execute
+	response, err = suite.notificationService.MarkRead(suite.ctx, "user-1", "notification-2")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(&readAt, response.ReadAt)
+	suite.notificationRepo.AssertNotCalled(suite.T(), "MarkRead", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RecordReceipt - Receipts are recorded at the time the provider reports
+func (suite *NotificationServiceTestSuite) TestRecordReceipt_Delivered() {
+	createdAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	deliveredAt := createdAt.Add(30 * time.Second)
+
+	// Mock expectations
+	suite.notificationRepo.On("GetByID", suite.ctx, "notification-1").
+		Return(&models.Notification{ID: "notification-1", CreatedAt: createdAt}, nil).Once()
+	suite.notificationRepo.On("MarkDelivered", suite.ctx, "notification-1", deliveredAt).Return(true, nil).Once()
+
+	// Execute
+	response, err := suite.notificationService.RecordReceipt(suite.ctx, "notification-1", services.NotificationReceiptRequest{
+		Status:     services.NotificationReceiptDelivered,
+		OccurredAt: &deliveredAt,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(&deliveredAt, response.DeliveredAt)
+	suite.False(response.Read)
+}
+
+// Test RecordReceipt - Receipts timed before the notification or in the future are rejected
+func (suite *NotificationServiceTestSuite) TestRecordReceipt_InvalidTime() {
+	createdAt := time.Now().Add(-time.Hour)
+	before := createdAt.Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	// Mock expectations
+	suite.notificationRepo.On("GetByID", suite.ctx, "notification-1").
+		Return(&models.Notification{ID: "notification-1", CreatedAt: createdAt}, nil).Once()
+
+	// Execute
+	_, err := suite.notificationService.RecordReceipt(suite.ctx, "notification-1", services.NotificationReceiptRequest{
+		Status: services.NotificationReceiptRead, OccurredAt: &before,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+
+	// Execute
+	_, err = suite.notificationService.RecordReceipt(suite.ctx, "notification-1", services.NotificationReceiptRequest{
+		Status: services.NotificationReceiptRead, OccurredAt: &future,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// TestNotificationServiceTestSuite runs the test suite
+func TestNotificationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationServiceTestSuite))
+}
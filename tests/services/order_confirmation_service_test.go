@@ -0,0 +1,267 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// confirmationNotifier records the orders customers are told were confirmed
+type confirmationNotifier struct {
+	confirmed []string
+}
+
+func (n *confirmationNotifier) NotifyOrderStatusChange(ctx context.Context, order *models.Order, status models.OrderStatus) {
+}
+
+func (n *confirmationNotifier) NotifyOrderConfirmed(ctx context.Context, order *models.Order) {
+	n.confirmed = append(n.confirmed, order.ID)
+}
+
+func (n *confirmationNotifier) NotifyOrderItemsCancelled(ctx context.Context, order *models.Order) {
+}
+
+func (n *confirmationNotifier) NotifyOrderRefunded(ctx context.Context, order *models.Order, amount float64) {
+}
+
+// OrderConfirmationServiceTestSuite defines the test suite for OrderConfirmationService
+type OrderConfirmationServiceTestSuite struct {
+	suite.Suite
+	confirmationService services.OrderConfirmationService
+	fulfillmentRepo     *mocks.MockFulfillmentRepository
+	orderEventRepo      *mocks.MockOrderEventRepository
+	orderRepo           *mocks.MockOrderRepository
+	notifier            *confirmationNotifier
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrderConfirmationServiceTestSuite) SetupTest() {
+	suite.fulfillmentRepo = new(mocks.MockFulfillmentRepository)
+	suite.orderEventRepo = new(mocks.MockOrderEventRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.notifier = &confirmationNotifier{}
+	suite.ctx = context.Background()
+
+	suite.confirmationService = services.NewOrderConfirmationService(
+		services.OrderConfirmationSettings{ManualStores: []string{"wholesale"}, SettleDelay: 5 * time.Second},
+		suite.fulfillmentRepo,
+		suite.orderEventRepo,
+		suite.orderRepo,
+		suite.notifier,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrderConfirmationServiceTestSuite) TearDownTest() {
+	suite.fulfillmentRepo.AssertExpectations(suite.T())
+	suite.orderEventRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// orderEvent returns an order event of an order of a store moving from previous to status
+func orderEvent(sequence int64, eventType models.OrderEventType, orderID, channel string, previous, status models.OrderStatus) *models.OrderEvent {
+	payload, _ := json.Marshal(models.OrderEventPayload{
+		OrderID:        orderID,
+		UserID:         "user-1",
+		Status:         status,
+		PreviousStatus: previous,
+		Channel:        channel,
+	})
+	return &models.OrderEvent{Sequence: sequence, OrderID: orderID, Type: eventType, Payload: payload}
+}
+
+// Test ProcessOrderEvents - Paid orders are confirmed, queued and their customers notified
+func (suite *OrderConfirmationServiceTestSuite) TestProcessOrderEvents_ConfirmsPaidOrders() {
+	events := []*models.OrderEvent{
+		orderEvent(11, models.OrderEventStatusChanged, "order-1", "web", models.OrderStatusPending, models.OrderStatusPaid),
+		orderEvent(12, models.OrderEventCreated, "order-2", "web", "", models.OrderStatusPaid),
+		// Placed but not paid yet
+		orderEvent(13, models.OrderEventCreated, "order-3", "web", "", models.OrderStatusPending),
+	}
+
+	// Mock expectations
+	suite.fulfillmentRepo.On("GetCursor", suite.ctx, "order_confirmation").Return(int64(10), true, nil)
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(10), 5*time.Second, 200).Return(events, nil)
+	suite.fulfillmentRepo.On("Apply", suite.ctx, "order_confirmation", int64(13),
+		repository.FulfillmentBatch{Confirm: []string{"order-1", "order-2"}}, mock.Anything).
+		Return([]string{"order-1", "order-2"}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(&models.Order{ID: "order-1", Status: models.OrderStatusPaid}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-2").Return(&models.Order{ID: "order-2", Status: models.OrderStatusPaid}, nil)
+
+	// Execute
+	confirmed, err := suite.confirmationService.ProcessOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, confirmed)
+	assert.Equal(suite.T(), []string{"order-1", "order-2"}, suite.notifier.confirmed)
+}
+
+// Test ProcessOrderEvents - Paid orders of manual stores wait for an admin, and orders
+// confirmed before payment are queued without telling the customer again
+func (suite *OrderConfirmationServiceTestSuite) TestProcessOrderEvents_ManualAndPreconfirmedOrders() {
+	events := []*models.OrderEvent{
+		orderEvent(11, models.OrderEventStatusChanged, "order-1", "wholesale", models.OrderStatusPending, models.OrderStatusPaid),
+		orderEvent(12, models.OrderEventStatusChanged, "order-2", "wholesale", models.OrderStatusConfirmed, models.OrderStatusPaid),
+	}
+
+	// Mock expectations
+	suite.fulfillmentRepo.On("GetCursor", suite.ctx, "order_confirmation").Return(int64(10), true, nil)
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(10), 5*time.Second, 200).Return(events, nil)
+	suite.fulfillmentRepo.On("Apply", suite.ctx, "order_confirmation", int64(12),
+		repository.FulfillmentBatch{Confirm: []string{"order-2"}}, mock.Anything).
+		Return([]string{"order-2"}, nil)
+
+	// Execute
+	confirmed, err := suite.confirmationService.ProcessOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, confirmed)
+	assert.Empty(suite.T(), suite.notifier.confirmed)
+}
+
+// Test ProcessOrderEvents - Shipped orders complete their task and cancelled or failed
+// orders cancel it
+func (suite *OrderConfirmationServiceTestSuite) TestProcessOrderEvents_ClosesTasks() {
+	events := []*models.OrderEvent{
+		orderEvent(11, models.OrderEventStatusChanged, "order-1", "web", models.OrderStatusPaid, models.OrderStatusShipped),
+		orderEvent(12, models.OrderEventStatusChanged, "order-2", "web", models.OrderStatusPaid, models.OrderStatusCancelled),
+		orderEvent(13, models.OrderEventStatusChanged, "order-3", "web", models.OrderStatusPending, models.OrderStatusFailed),
+	}
+
+	// Mock expectations
+	suite.fulfillmentRepo.On("GetCursor", suite.ctx, "order_confirmation").Return(int64(10), true, nil)
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(10), 5*time.Second, 200).Return(events, nil)
+	suite.fulfillmentRepo.On("Apply", suite.ctx, "order_confirmation", int64(13),
+		repository.FulfillmentBatch{Complete: []string{"order-1"}, Cancel: []string{"order-2", "order-3"}}, mock.Anything).
+		Return([]string{}, nil)
+
+	// Execute
+	confirmed, err := suite.confirmationService.ProcessOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, confirmed)
+}
+
+// Test ProcessOrderEvents - The first run starts from the latest event without
+// confirming orders paid before
+func (suite *OrderConfirmationServiceTestSuite) TestProcessOrderEvents_FirstRunStartsAtLatest() {
+	// Mock expectations
+	suite.fulfillmentRepo.On("GetCursor", suite.ctx, "order_confirmation").Return(int64(0), false, nil)
+	suite.orderEventRepo.On("LatestSequence", suite.ctx).Return(int64(42), nil)
+	suite.fulfillmentRepo.On("Apply", suite.ctx, "order_confirmation", int64(42),
+		repository.FulfillmentBatch{}, mock.Anything).Return([]string{}, nil)
+
+	// Execute
+	confirmed, err := suite.confirmationService.ProcessOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, confirmed)
+	suite.orderEventRepo.AssertNotCalled(suite.T(), "ListSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ProcessOrderEvents - A failed batch leaves the cursor where it was
+func (suite *OrderConfirmationServiceTestSuite) TestProcessOrderEvents_ApplyFails() {
+	events := []*models.OrderEvent{
+		orderEvent(11, models.OrderEventStatusChanged, "order-1", "web", models.OrderStatusPending, models.OrderStatusPaid),
+	}
+
+	// Mock expectations
+	suite.fulfillmentRepo.On("GetCursor", suite.ctx, "order_confirmation").Return(int64(10), true, nil)
+	suite.orderEventRepo.On("ListSince", suite.ctx, int64(10), 5*time.Second, 200).Return(events, nil)
+	suite.fulfillmentRepo.On("Apply", suite.ctx, "order_confirmation", int64(11), mock.Anything, mock.Anything).
+		Return(nil, errors.New("database error"))
+
+	// Execute
+	_, err := suite.confirmationService.ProcessOrderEvents(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), suite.notifier.confirmed)
+}
+
+// Test ConfirmOrder - An admin confirms a paid order and the customer is notified
+func (suite *OrderConfirmationServiceTestSuite) TestConfirmOrder_Success() {
+	order := &models.Order{ID: "order-1", Status: models.OrderStatusPaid}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.fulfillmentRepo.On("Confirm", suite.ctx, "order-1", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	// Execute
+	response, err := suite.confirmationService.ConfirmOrder(suite.ctx, "order-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "order-1", response.OrderID)
+	assert.Equal(suite.T(), models.OrderStatusPaid, response.Status)
+	assert.False(suite.T(), response.ConfirmedAt.IsZero())
+	assert.Equal(suite.T(), []string{"order-1"}, suite.notifier.confirmed)
+}
+
+// Test ConfirmOrder - Only paid orders not confirmed yet are confirmed
+func (suite *OrderConfirmationServiceTestSuite) TestConfirmOrder_Rejected() {
+	confirmedAt := time.Now()
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "pending").Return(&models.Order{ID: "pending", Status: models.OrderStatusPending}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "confirmed").
+		Return(&models.Order{ID: "confirmed", Status: models.OrderStatusPaid, ConfirmedAt: &confirmedAt}, nil)
+
+	// Execute & Assert
+	_, err := suite.confirmationService.ConfirmOrder(suite.ctx, "missing")
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+
+	_, err = suite.confirmationService.ConfirmOrder(suite.ctx, "pending")
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+
+	_, err = suite.confirmationService.ConfirmOrder(suite.ctx, "confirmed")
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+
+	suite.fulfillmentRepo.AssertNotCalled(suite.T(), "Confirm", mock.Anything, mock.Anything, mock.Anything)
+	assert.Empty(suite.T(), suite.notifier.confirmed)
+}
+
+// Test ListFulfillmentQueue - Queued tasks by default, and unknown statuses are rejected
+func (suite *OrderConfirmationServiceTestSuite) TestListFulfillmentQueue() {
+	tasks := []*models.FulfillmentTask{{ID: "task-1", OrderID: "order-1", Status: models.FulfillmentTaskStatusQueued}}
+
+	// Mock expectations
+	suite.fulfillmentRepo.On("ListTasks", suite.ctx, models.FulfillmentTaskStatusQueued, 0, 20).Return(tasks, nil)
+	suite.fulfillmentRepo.On("CountTasks", suite.ctx, models.FulfillmentTaskStatusQueued).Return(int64(1), nil)
+
+	// Execute
+	response, err := suite.confirmationService.ListFulfillmentQueue(suite.ctx, services.ListFulfillmentQueueRequest{})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.FulfillmentTaskStatusQueued, response.Status)
+	assert.Len(suite.T(), response.Tasks, 1)
+	assert.Equal(suite.T(), 1, response.Total)
+
+	_, err = suite.confirmationService.ListFulfillmentQueue(suite.ctx, services.ListFulfillmentQueueRequest{Status: "lost"})
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// TestOrderConfirmationServiceTestSuite runs the test suite
+func TestOrderConfirmationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderConfirmationServiceTestSuite))
+}
@@ -0,0 +1,218 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// OrderHoldServiceTestSuite defines the test suite for OrderHoldService
+type OrderHoldServiceTestSuite struct {
+	suite.Suite
+	orderHoldService services.OrderHoldService
+	holdRepo         *mocks.MockOrderHoldRepository
+	orderRepo        *mocks.MockOrderRepository
+	userRepo         *mocks.MockUserRepository
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrderHoldServiceTestSuite) SetupTest() {
+	suite.holdRepo = new(mocks.MockOrderHoldRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.orderHoldService = services.NewOrderHoldService(
+		services.OrderHoldSettings{SLA: 48 * time.Hour},
+		suite.holdRepo,
+		suite.orderRepo,
+		suite.userRepo,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrderHoldServiceTestSuite) TearDownTest() {
+	suite.holdRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test PlaceHold - A paid order is held for the reason, assigned to the reviewer and due at the end of the SLA
+func (suite *OrderHoldServiceTestSuite) TestPlaceHold_Success() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").
+		Return(&models.Order{ID: "order-1", Status: models.OrderStatusPaid}, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "admin-2").
+		Return(&models.User{ID: "admin-2", Role: models.UserRoleAdmin, IsActive: true}, nil)
+
+	var hold *models.OrderHold
+	suite.holdRepo.On("Place", suite.ctx, mock.AnythingOfType("*models.OrderHold")).
+		Run(func(args mock.Arguments) { hold = args.Get(1).(*models.OrderHold) }).
+		Return(true, nil)
+
+	// Execute
+	before := time.Now()
+	response, err := suite.orderHoldService.PlaceHold(suite.ctx, "order-1", "admin-1", services.PlaceOrderHoldRequest{
+		Reason:     models.OrderHoldReasonExportControl,
+		Note:       " Destination needs screening ",
+		AssignedTo: "admin-2",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.OrderHoldReasonExportControl, response.Reason)
+	suite.Equal("Destination needs screening", hold.Note)
+	suite.Equal("admin-1", hold.PlacedBy)
+	suite.Equal("admin-2", *hold.AssignedTo)
+	suite.NotNil(hold.AssignedAt)
+	suite.WithinDuration(before.Add(48*time.Hour), hold.DueAt, time.Minute)
+	suite.False(response.Overdue)
+}
+
+// Test PlaceHold - Orders already on hold or already shipped cannot be held
+func (suite *OrderHoldServiceTestSuite) TestPlaceHold_Conflict() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").
+		Return(&models.Order{ID: "order-1", Status: models.OrderStatusConfirmed, OnHold: true}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-2").
+		Return(&models.Order{ID: "order-2", Status: models.OrderStatusShipped}, nil)
+
+	for _, orderID := range []string{"order-1", "order-2"} {
+		_, err := suite.orderHoldService.PlaceHold(suite.ctx, orderID, "admin-1", services.PlaceOrderHoldRequest{
+			Reason: models.OrderHoldReasonFraud,
+		})
+		suite.Require().Error(err)
+		suite.Contains(err.Error(), "CONFLICT")
+	}
+
+	suite.holdRepo.AssertNotCalled(suite.T(), "Place", mock.Anything, mock.Anything)
+}
+
+// Test PlaceHold - Holds can only be assigned to active admins
+func (suite *OrderHoldServiceTestSuite) TestPlaceHold_InvalidReviewer() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").
+		Return(&models.Order{ID: "order-1", Status: models.OrderStatusPending}, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").
+		Return(&models.User{ID: "user-1", Role: models.UserRoleCustomer, IsActive: true}, nil)
+
+	// Execute
+	_, err := suite.orderHoldService.PlaceHold(suite.ctx, "order-1", "admin-1", services.PlaceOrderHoldRequest{
+		Reason:     models.OrderHoldReasonAddressIssue,
+		AssignedTo: "user-1",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+	suite.holdRepo.AssertNotCalled(suite.T(), "Place", mock.Anything, mock.Anything)
+}
+
+// Test ReleaseHold - An active hold is released by the admin with the note
+func (suite *OrderHoldServiceTestSuite) TestReleaseHold_Success() {
+	suite.holdRepo.On("GetByID", suite.ctx, "hold-1").Return(&models.OrderHold{
+		ID:        "hold-1",
+		OrderID:   "order-1",
+		Reason:    models.OrderHoldReasonFraud,
+		DueAt:     time.Now().Add(time.Hour),
+		CreatedAt: time.Now().Add(-47 * time.Hour),
+	}, nil)
+	suite.holdRepo.On("Release", suite.ctx, "hold-1", "admin-1", "Customer verified", mock.AnythingOfType("time.Time")).
+		Return(true, nil)
+
+	// Execute
+	response, err := suite.orderHoldService.ReleaseHold(suite.ctx, "hold-1", "admin-1", services.ReleaseOrderHoldRequest{
+		Note: "Customer verified ",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("admin-1", *response.ReleasedBy)
+	suite.NotNil(response.ReleasedAt)
+	suite.Equal("Customer verified", response.ReleaseNote)
+	suite.False(response.Overdue)
+}
+
+// Test ReleaseHold - Released holds cannot be released again
+func (suite *OrderHoldServiceTestSuite) TestReleaseHold_AlreadyReleased() {
+	releasedAt := time.Now()
+	suite.holdRepo.On("GetByID", suite.ctx, "hold-1").
+		Return(&models.OrderHold{ID: "hold-1", ReleasedAt: &releasedAt}, nil)
+
+	// Execute
+	_, err := suite.orderHoldService.ReleaseHold(suite.ctx, "hold-1", "admin-1", services.ReleaseOrderHoldRequest{})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.holdRepo.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ListHolds - The overdue filter only matches active holds past their due date
+func (suite *OrderHoldServiceTestSuite) TestListHolds_Overdue() {
+	overdue := &models.OrderHold{ID: "hold-1", DueAt: time.Now().Add(-time.Hour)}
+	matchFilter := mock.MatchedBy(func(filter repository.OrderHoldFilter) bool {
+		return filter.OverdueAt != nil && filter.Active != nil && *filter.Active &&
+			filter.Reason == models.OrderHoldReasonFraud
+	})
+	suite.holdRepo.On("List", suite.ctx, matchFilter, 0, 20).Return([]*models.OrderHold{overdue}, nil)
+	suite.holdRepo.On("Count", suite.ctx, matchFilter).Return(int64(1), nil)
+
+	// Execute
+	response, err := suite.orderHoldService.ListHolds(suite.ctx, services.ListOrderHoldsRequest{
+		Status:  "active",
+		Reason:  "fraud",
+		Overdue: true,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(1, response.Total)
+	suite.Require().Len(response.Holds, 1)
+	suite.True(response.Holds[0].Overdue)
+}
+
+// Test GetHoldMetrics - Hold rate, average hold time and SLA compliance are derived per reason and in total
+func (suite *OrderHoldServiceTestSuite) TestGetHoldMetrics() {
+	suite.holdRepo.On("CountHeldOrders", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(int64(200), int64(5), nil)
+	suite.holdRepo.On("SummarizeByReason", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return([]repository.OrderHoldSummary{
+			{Reason: models.OrderHoldReasonAddressIssue, Placed: 2, Released: 2, WithinSLA: 2, HoldSeconds: 4 * 3600},
+			{Reason: models.OrderHoldReasonFraud, Placed: 3, Released: 2, Active: 1, Overdue: 1, WithinSLA: 1, HoldSeconds: 100 * 3600},
+		}, nil)
+
+	// Execute
+	metrics, err := suite.orderHoldService.GetHoldMetrics(suite.ctx, services.OrderHoldMetricsRequest{
+		StartDate: "2026-01-01",
+		EndDate:   "2026-01-31",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(48.0, metrics.SLAHours)
+	suite.Equal(2.5, metrics.HoldRate)
+	suite.Require().Len(metrics.Reasons, 2)
+	suite.Equal(2.0, metrics.Reasons[0].AverageHoldHours)
+	suite.Equal(100.0, metrics.Reasons[0].SLACompliance)
+	suite.Equal(50.0, metrics.Reasons[1].AverageHoldHours)
+	suite.Equal(50.0, metrics.Reasons[1].SLACompliance)
+	suite.Equal(int64(5), metrics.Totals.Placed)
+	suite.Equal(int64(1), metrics.Totals.Overdue)
+	suite.Equal(26.0, metrics.Totals.AverageHoldHours)
+	suite.Equal(75.0, metrics.Totals.SLACompliance)
+}
+
+// TestOrderHoldServiceTestSuite runs the test suite
+func TestOrderHoldServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderHoldServiceTestSuite))
+}
@@ -0,0 +1,176 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/services"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// OrderLimiterTestSuite defines the test suite for OrderLimiter
+type OrderLimiterTestSuite struct {
+	suite.Suite
+	orderRepo *mocks.MockOrderRepository
+	logger    *logger.Logger
+	ctx       context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrderLimiterTestSuite) SetupTest() {
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrderLimiterTestSuite) TearDownTest() {
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// lastHour matches a since time an hour before now
+func lastHour() interface{} {
+	return mock.MatchedBy(func(since time.Time) bool {
+		expected := time.Now().Add(-time.Hour)
+		return since.After(expected.Add(-time.Minute)) && !since.After(expected)
+	})
+}
+
+// Test Check - Orders within every limit pass
+func (suite *OrderLimiterTestSuite) TestCheck_WithinLimits() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{
+		MaxItemsPerOrder:  2,
+		MaxQuantityPerSKU: 5,
+		MaxOrdersPerHour:  3,
+	}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("CountByUserSince", suite.ctx, "user-1", lastHour()).Return(int64(2), nil).Once()
+
+	// Repeated lines of a product count as one product
+	err := limiter.Check(suite.ctx, "user-1", []services.OrderItem{
+		{ProductID: "product-1", Quantity: 2},
+		{ProductID: "product-2", Quantity: 5},
+		{ProductID: "product-1", Quantity: 3},
+	})
+
+	suite.NoError(err)
+	suite.Equal(services.OrderLimitRejections{}, limiter.Limits().Rejected)
+}
+
+// Test Check - An order of too many different products is refused
+func (suite *OrderLimiterTestSuite) TestCheck_TooManyItems() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxItemsPerOrder: 2, MaxOrdersPerHour: 3}, suite.orderRepo, suite.logger)
+
+	err := limiter.Check(suite.ctx, "user-1", []services.OrderItem{
+		{ProductID: "product-1", Quantity: 1},
+		{ProductID: "product-2", Quantity: 1},
+		{ProductID: "product-3", Quantity: 1},
+	})
+
+	suite.Require().Error(err)
+	suite.True(apperrors.IsErrorType(err, apperrors.ErrorTypeOrderItemLimit))
+	suite.Equal(http.StatusBadRequest, apperrors.GetStatusCode(err))
+	suite.Contains(err.Error(), "order has 3 different products, more than the limit of 2")
+	suite.Equal(int64(1), limiter.Limits().Rejected.Items)
+}
+
+// Test Check - Too many units of a product across its lines are refused
+func (suite *OrderLimiterTestSuite) TestCheck_TooManyUnitsOfProduct() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxQuantityPerSKU: 5}, suite.orderRepo, suite.logger)
+
+	err := limiter.Check(suite.ctx, "user-1", []services.OrderItem{
+		{ProductID: "product-1", Quantity: 5},
+		{ProductID: "product-2", Quantity: 4},
+		{ProductID: "product-2", Quantity: 2},
+	})
+
+	suite.Require().Error(err)
+	suite.True(apperrors.IsErrorType(err, apperrors.ErrorTypeOrderQuantityLimit))
+	suite.Contains(err.Error(), "order has 6 units of product product-2, more than the limit of 5")
+	suite.Equal(int64(1), limiter.Limits().Rejected.Quantity)
+}
+
+// Test Check - A user who placed the hourly limit of orders is refused
+func (suite *OrderLimiterTestSuite) TestCheck_OrderRateLimit() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxOrdersPerHour: 3}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("CountByUserSince", suite.ctx, "user-1", lastHour()).Return(int64(3), nil).Once()
+
+	err := limiter.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 1}})
+
+	suite.Require().Error(err)
+	suite.True(apperrors.IsErrorType(err, apperrors.ErrorTypeOrderRateLimit))
+	suite.Equal(http.StatusTooManyRequests, apperrors.GetStatusCode(err))
+	suite.Equal(int64(1), limiter.Limits().Rejected.Rate)
+}
+
+// Test Check - Repository errors are reported as database errors
+func (suite *OrderLimiterTestSuite) TestCheck_RepositoryError() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxOrdersPerHour: 3}, suite.orderRepo, suite.logger)
+
+	suite.orderRepo.On("CountByUserSince", suite.ctx, "user-1", mock.Anything).Return(int64(0), errors.New("connection reset")).Once()
+
+	err := limiter.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 1}})
+
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "DATABASE_ERROR")
+}
+
+// Test Update - Changed limits apply to the next check; limits left out keep their value
+func (suite *OrderLimiterTestSuite) TestUpdate_ChangesLimits() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxItemsPerOrder: 10, MaxOrdersPerHour: 3}, suite.orderRepo, suite.logger)
+
+	quantity, orders := 2, 0
+	response, err := limiter.Update(services.UpdateOrderLimitsRequest{
+		MaxQuantityPerSKU: &quantity,
+		MaxOrdersPerHour:  &orders,
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(10, response.MaxItemsPerOrder)
+	suite.Equal(2, response.MaxQuantityPerSKU)
+	suite.Equal(0, response.MaxOrdersPerHour)
+
+	// The hourly limit is off, so no orders are counted
+	err = limiter.Check(suite.ctx, "user-1", []services.OrderItem{{ProductID: "product-1", Quantity: 3}})
+	suite.True(apperrors.IsErrorType(err, apperrors.ErrorTypeOrderQuantityLimit))
+}
+
+// Test Update - Negative limits are refused
+func (suite *OrderLimiterTestSuite) TestUpdate_NegativeLimit() {
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{MaxItemsPerOrder: 10}, suite.orderRepo, suite.logger)
+
+	items := -1
+	response, err := limiter.Update(services.UpdateOrderLimitsRequest{MaxItemsPerOrder: &items})
+
+	suite.Nil(response)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+	suite.Equal(10, limiter.Settings().MaxItemsPerOrder)
+}
+
+// TestOrderLimiterTestSuite runs the test suite
+func TestOrderLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderLimiterTestSuite))
+}
+
+// Test Check - No limits or a nil limiter refuse nothing
+func TestOrderLimiter_Disabled(t *testing.T) {
+	items := []services.OrderItem{{ProductID: "product-1", Quantity: 1000}}
+
+	limiter := services.NewOrderLimiter(services.OrderLimitSettings{}, new(mocks.MockOrderRepository), nil)
+	assert.NoError(t, limiter.Check(context.Background(), "user-1", items))
+
+	var nilLimiter *services.OrderLimiter
+	assert.NoError(t, nilLimiter.Check(context.Background(), "user-1", items))
+	assert.Equal(t, &services.OrderLimitsResponse{}, nilLimiter.Limits())
+}
@@ -3,9 +3,12 @@ package services_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
 	"easy-orders-backend/internal/services"
 	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/tests/mocks"
@@ -26,6 +29,7 @@ type OrderServiceTestSuite struct {
 	productRepo      *mocks.MockProductRepository
 	inventoryRepo    *mocks.MockInventoryRepository
 	userRepo         *mocks.MockUserRepository
+	paymentService   *mocks.MockPaymentService
 	logger           *logger.Logger
 	ctx              context.Context
 }
@@ -37,13 +41,18 @@ func (suite *OrderServiceTestSuite) SetupTest() {
 	suite.productRepo = new(mocks.MockProductRepository)
 	suite.inventoryRepo = new(mocks.MockInventoryRepository)
 	suite.userRepo = new(mocks.MockUserRepository)
+	suite.paymentService = new(mocks.MockPaymentService)
 	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
 	suite.ctx = context.Background()
 
 	// Create inventory service
 	suite.inventoryService = services.NewInventoryService(
+		services.LowStockSettings{}, // Static low-stock thresholds
 		suite.inventoryRepo,
 		suite.productRepo,
+		nil, // Relocations are not audited
+		nil, // No inventory history
+		nil, // No stock webhook subscribers
 		suite.logger,
 	)
 
@@ -57,6 +66,21 @@ func (suite *OrderServiceTestSuite) SetupTest() {
 		suite.inventoryRepo,
 		suite.userRepo,
 		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		suite.paymentService,
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
 		suite.logger,
 	)
 }
@@ -153,6 +177,23 @@ func (suite *OrderServiceTestSuite) TestCreateOrder_ValidationError_UserIDRequir
 	assert.Contains(suite.T(), err.Error(), "user ID is required")
 }
 
+// Test CreateOrder - Validation Error: Unknown Payment Method
+func (suite *OrderServiceTestSuite) TestCreateOrder_ValidationError_InvalidPaymentMethod() {
+	req := services.CreateOrderRequest{
+		UserID:        "user-id-123",
+		Items:         []services.OrderItem{{ProductID: "product-id-1", Quantity: 1}},
+		PaymentMethod: "crypto",
+	}
+
+	// Execute
+	response, err := suite.orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "invalid payment method")
+}
+
 // Test CreateOrder - Validation Error: No Items
 func (suite *OrderServiceTestSuite) TestCreateOrder_ValidationError_NoItems() {
 	req := services.CreateOrderRequest{
@@ -213,6 +254,416 @@ func (suite *OrderServiceTestSuite) TestCreateOrder_RepositoryError_GetUser() {
 	assert.Contains(suite.T(), err.Error(), "database error")
 }
 
+// Test CreateOrder - Stores that reject orders outside hours refuse them while closed
+func (suite *OrderServiceTestSuite) TestCreateOrder_StoreClosed() {
+	userID := "user-id-123"
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = userID })
+
+	// The store only opens three days from now
+	openDay := strings.ToLower(time.Now().UTC().AddDate(0, 0, 3).Weekday().String()[:3])
+	stores, err := services.NewStoreSchedules("UTC",
+		map[string]string{services.DefaultStore: openDay + " 09:00-17:00"}, nil, []string{models.OrderChannelDirect})
+	suite.Require().NoError(err)
+
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is refused before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		stores,
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id", Quantity: 1},
+		},
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(user, nil)
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "BUSINESS_ERROR: store direct is closed")
+}
+
+// Test CreateOrder - A failed price list lookup refuses the order before the transaction
+func (suite *OrderServiceTestSuite) TestCreateOrder_PriceListLookupFails() {
+	userID := "user-id-123"
+	priceListID := "price-list-id"
+	user := testutil.CreateTestUser(func(u *models.User) {
+		u.ID = userID
+		u.PriceListID = &priceListID
+	})
+
+	priceListRepo := new(mocks.MockPriceListRepository)
+	priceLists := services.NewPriceListResolver(priceListRepo, new(mocks.MockOrganizationRepository), suite.logger)
+
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is refused before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		priceLists,
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id", Quantity: 1},
+		},
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(user, nil)
+	priceListRepo.On("GetByID", suite.ctx, priceListID).Return(nil, errors.New("database error"))
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "database error")
+	priceListRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateOrder - An unconfirmed repeat of a recent order is refused before the transaction
+func (suite *OrderServiceTestSuite) TestCreateOrder_DuplicateRequiresConfirmation() {
+	userID := "user-id-123"
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = userID })
+
+	duplicates := services.NewDuplicateOrderGuard(
+		services.DuplicateOrderSettings{Window: 10 * time.Minute, RequireConfirmation: true},
+		suite.orderRepo,
+		suite.logger,
+	)
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is refused before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		duplicates,
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	previous := &models.Order{
+		ID:     "order-id-1",
+		UserID: userID,
+		Status: models.OrderStatusPending,
+		Items:  []models.OrderItem{{ProductID: "product-id", Quantity: 1}},
+	}
+	previous.CreatedAt = time.Now().Add(-2 * time.Minute)
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id", Quantity: 1},
+		},
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(user, nil)
+	suite.orderRepo.On("ListRecentByUser", suite.ctx, userID, mock.AnythingOfType("time.Time")).Return([]*models.Order{previous}, nil)
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT: possible duplicate order")
+	assert.Equal(suite.T(), int64(1), duplicates.Metrics().Prevented)
+}
+
+// Test CreateOrder - An order past the hourly order limit is refused before the user is loaded,
+// while channel orders are exempt
+func (suite *OrderServiceTestSuite) TestCreateOrder_OrderRateLimit() {
+	userID := "user-id-123"
+
+	limits := services.NewOrderLimiter(services.OrderLimitSettings{MaxOrdersPerHour: 3}, suite.orderRepo, suite.logger)
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is refused before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		limits,
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id", Quantity: 1},
+		},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("CountByUserSince", suite.ctx, userID, mock.AnythingOfType("time.Time")).Return(int64(3), nil).Once()
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "ORDER_RATE_LIMIT_EXCEEDED: at most 3 orders may be placed per hour")
+
+	// A channel order is not counted; it fails later, on the missing user
+	req.ExternalOrderID = "ext-1"
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(nil, nil).Once()
+
+	response, err = orderService.CreateOrder(suite.ctx, req)
+
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// Test CreateOrder - While an earlier order holds a product's turn in the allocation queue,
+// the next order is queued with a ticket before the transaction
+func (suite *OrderServiceTestSuite) TestCreateOrder_AllocationQueued() {
+	userID := "user-id-123"
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = userID })
+
+	queue := services.NewAllocationQueue(
+		services.AllocationQueueSettings{Enabled: true, Wait: 10 * time.Millisecond, TicketTTL: time.Minute},
+		suite.logger,
+	)
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is queued before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		queue,
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	items := []services.OrderItem{{ProductID: "product-id", Quantity: 1}}
+	release, err := queue.Acquire(suite.ctx, "", "other-user", items)
+	suite.Require().NoError(err)
+	defer release()
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(user, nil)
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, services.CreateOrderRequest{UserID: userID, Items: items})
+
+	// Assert
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "ALLOCATION_QUEUED")
+	position, queued := services.QueuePositionFromError(err)
+	suite.Require().True(queued)
+	assert.Equal(suite.T(), 1, position.Position)
+	assert.NotEmpty(suite.T(), position.TicketID)
+}
+
+// Test CreateOrder - Gift options are refused by stores that do not offer them
+func (suite *OrderServiceTestSuite) TestCreateOrder_GiftOptionsNotOffered() {
+	userID := "user-id-123"
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = userID })
+
+	orderService := services.NewOrderService(
+		nil, // DB not needed, the order is refused before the transaction
+		suite.orderRepo,
+		suite.orderItemRepo,
+		suite.productRepo,
+		suite.inventoryRepo,
+		suite.userRepo,
+		suite.inventoryService,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		services.NewGiftOptions([]string{"shopify"}),
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		nil, // No order status notifications
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id", Quantity: 1},
+		},
+		Gift: &services.OrderGiftOptions{Message: "Happy birthday!", HidePrices: true},
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(user, nil)
+
+	// Execute
+	response, err := orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "BUSINESS_ERROR: store direct does not offer gift options")
+}
+
+// Test CreateOrder - Resubmitted Request Replays the Placed Order
+func (suite *OrderServiceTestSuite) TestCreateOrder_RequestIDReplaysOrder() {
+	userID := "user-id-123"
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.IdempotencyKey = "request:req-42"
+		o.Status = models.OrderStatusPaid
+		o.Items = []models.OrderItem{
+			{ProductID: "product-id-1", Quantity: 2, UnitPrice: 10},
+			{ProductID: "product-id-2", Quantity: 1, UnitPrice: 5},
+		}
+	})
+
+	req := services.CreateOrderRequest{
+		UserID: userID,
+		Items: []services.OrderItem{
+			{ProductID: "product-id-2", Quantity: 1},
+			{ProductID: "product-id-1", Quantity: 2},
+		},
+		RequestID: "req-42",
+	}
+
+	// Mock expectations: the order is not placed again
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, userID, "request:req-42").Return(order, nil)
+
+	// Execute
+	response, err := suite.orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), response)
+	assert.True(suite.T(), response.Replayed)
+	assert.Equal(suite.T(), order.ID, response.ID)
+	assert.Equal(suite.T(), models.OrderStatusPaid, response.Status)
+	assert.Len(suite.T(), response.Items, 2)
+	suite.userRepo.AssertNotCalled(suite.T(), "GetByID", mock.Anything, mock.Anything)
+}
+
+// Test CreateOrder - Request ID Reused for Different Items
+func (suite *OrderServiceTestSuite) TestCreateOrder_RequestIDReusedForDifferentOrder() {
+	userID := "user-id-123"
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.IdempotencyKey = "request:req-42"
+		o.Items = []models.OrderItem{{ProductID: "product-id-1", Quantity: 2, UnitPrice: 10}}
+	})
+
+	req := services.CreateOrderRequest{
+		UserID:    userID,
+		Items:     []services.OrderItem{{ProductID: "product-id-1", Quantity: 3}},
+		RequestID: "req-42",
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByIdempotencyKey", suite.ctx, userID, "request:req-42").Return(order, nil)
+
+	// Execute
+	response, err := suite.orderService.CreateOrder(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT: request ID was already used for a different order")
+}
+
 // Test CreateOrder - Validation Error: Product ID Required
 func (suite *OrderServiceTestSuite) TestCreateOrder_ValidationError_ProductIDRequired() {
 	userID := "user-id-123"
@@ -473,6 +924,36 @@ func (suite *OrderServiceTestSuite) TestGetOrder_Success() {
 	assert.Equal(suite.T(), userID, response.UserID)
 	assert.Equal(suite.T(), 100.00, response.Total)
 	assert.Equal(suite.T(), 1, len(response.Items))
+	assert.Nil(suite.T(), response.PaymentAdjustment)
+}
+
+// Test GetOrder - Checkout payment method surcharge is itemized
+func (suite *OrderServiceTestSuite) TestGetOrder_ItemizesPaymentAdjustment() {
+	orderID := "order-id-123"
+
+	order := testutil.CreateTestOrder("user-id-456", func(o *models.Order) {
+		o.ID = orderID
+		o.TotalAmount = 102.00
+		o.PaymentMethod = models.PaymentMethodCreditCard
+		o.PaymentAdjustmentRate = 2
+		o.PaymentAdjustment = 2.00
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByIDWithItems", suite.ctx, orderID).Return(order, nil)
+
+	// Execute
+	response, err := suite.orderService.GetOrder(suite.ctx, orderID)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 102.00, response.Total)
+	assert.Equal(suite.T(), &services.OrderPaymentAdjustment{
+		PaymentMethod: models.PaymentMethodCreditCard,
+		Subtotal:      100.00,
+		Rate:          2,
+		Amount:        2.00,
+	}, response.PaymentAdjustment)
 }
 
 // Test GetOrder - Validation Error: ID Required
@@ -536,7 +1017,7 @@ func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_Success() {
 
 	// Mock expectations
 	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
-	suite.orderRepo.On("UpdateStatus", suite.ctx, orderID, models.OrderStatusConfirmed).Return(nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, orderID, models.OrderStatusConfirmed).Return(true, nil)
 	suite.orderRepo.On("GetByIDWithItems", suite.ctx, orderID).Return(updatedOrder, nil)
 
 	// Execute
@@ -597,7 +1078,61 @@ func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_InvalidTransition() {
 	assert.Contains(suite.T(), err.Error(), "cannot transition")
 }
 
-// Test UpdateOrderStatus - Repository Error on UpdateStatus
+// Test UpdateOrderStatus - Orders with serialized items ship only once every unit's serial number is captured
+func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_SerialNumbersNotCaptured() {
+	orderID := "order-id-123"
+	userID := "user-id-456"
+
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.ID = orderID
+		o.Status = models.OrderStatusPaid
+		o.Items = []models.OrderItem{{
+			ID:            "item-1",
+			ProductID:     "product-1",
+			Quantity:      2,
+			SerialNumbers: models.StringList{"SN-1"},
+			Product:       &models.Product{ID: "product-1", Serialized: true},
+		}}
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+
+	// Execute
+	response, err := suite.orderService.UpdateOrderStatus(suite.ctx, orderID, models.OrderStatusShipped)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "serial numbers")
+	suite.orderRepo.AssertNotCalled(suite.T(), "TransitionStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateOrderStatus - Orders on credit hold cannot be confirmed until reviewed
+func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_CreditHold() {
+	orderID := "order-id-123"
+	userID := "user-id-456"
+
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.ID = orderID
+		o.OnAccount = true
+		o.CreditHold = true
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+
+	// Execute
+	response, err := suite.orderService.UpdateOrderStatus(suite.ctx, orderID, models.OrderStatusConfirmed)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "INVALID_TRANSITION")
+	suite.orderRepo.AssertNotCalled(suite.T(), "TransitionStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateOrderStatus - Repository Error on TransitionStatus
 func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_RepositoryError() {
 	orderID := "order-id-123"
 	userID := "user-id-456"
@@ -609,7 +1144,7 @@ func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_RepositoryError() {
 
 	// Mock expectations
 	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
-	suite.orderRepo.On("UpdateStatus", suite.ctx, orderID, models.OrderStatusConfirmed).Return(errors.New("database error"))
+	suite.orderRepo.On("TransitionStatus", suite.ctx, orderID, models.OrderStatusConfirmed).Return(false, errors.New("database error"))
 
 	// Execute
 	response, err := suite.orderService.UpdateOrderStatus(suite.ctx, orderID, models.OrderStatusConfirmed)
@@ -620,6 +1155,31 @@ func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_RepositoryError() {
 	assert.Contains(suite.T(), err.Error(), "database error")
 }
 
+// Test UpdateOrderStatus - A status changed by someone else since the order was read is
+// not overwritten
+func (suite *OrderServiceTestSuite) TestUpdateOrderStatus_ChangedConcurrently() {
+	orderID := "order-id-123"
+	userID := "user-id-456"
+
+	order := testutil.CreateTestOrder(userID, func(o *models.Order) {
+		o.ID = orderID
+		o.Status = models.OrderStatusPaid
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, orderID, models.OrderStatusShipped).Return(false, nil)
+
+	// Execute
+	response, err := suite.orderService.UpdateOrderStatus(suite.ctx, orderID, models.OrderStatusShipped)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+	suite.orderRepo.AssertNotCalled(suite.T(), "GetByIDWithItems", mock.Anything, mock.Anything)
+}
+
 // Test CancelOrder - Happy Path
 func (suite *OrderServiceTestSuite) TestCancelOrder_Success() {
 	orderID := "order-id-123"
@@ -632,7 +1192,7 @@ func (suite *OrderServiceTestSuite) TestCancelOrder_Success() {
 
 	// Mock expectations
 	suite.orderRepo.On("GetByID", suite.ctx, orderID).Return(order, nil)
-	suite.orderRepo.On("UpdateStatus", suite.ctx, orderID, models.OrderStatusCancelled).Return(nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, orderID, models.OrderStatusCancelled).Return(true, nil)
 
 	// Execute
 	err := suite.orderService.CancelOrder(suite.ctx, orderID)
@@ -687,6 +1247,258 @@ func (suite *OrderServiceTestSuite) TestCancelOrder_NotCancellable() {
 	assert.Contains(suite.T(), err.Error(), "cannot be cancelled")
 }
 
+// Test CancelOrder - A paid order is cancelled, its reserved stock is returned to
+// inventory and it is refunded in full
+func (suite *OrderServiceTestSuite) TestCancelOrder_PaidOrderRefundedAndRestocked() {
+	order := &models.Order{
+		ID:          "order-id-123",
+		UserID:      "user-id-456",
+		Status:      models.OrderStatusPaid,
+		TotalAmount: 40.00,
+		Items: []models.OrderItem{
+			{ProductID: "product-1", Quantity: 2, UnitPrice: 15.00, TotalPrice: 30.00},
+			{ProductID: "product-2", Quantity: 1, UnitPrice: 10.00, TotalPrice: 10.00},
+		},
+		Payments: []models.Payment{
+			{ID: "payment-failed", Amount: 40.00, Status: models.PaymentStatusFailed},
+			{ID: "payment-id-789", Amount: 40.00, Status: models.PaymentStatusCompleted},
+		},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, order.ID, models.OrderStatusCancelled).Return(true, nil)
+	suite.paymentService.On("RefundPayment", suite.ctx, "payment-id-789", services.RefundRequest{Amount: 40.00, Reason: "order cancellation"}).
+		Return(&services.PaymentResponse{ID: "payment-id-789", Status: models.PaymentStatusRefunded, RefundedAmount: 40.00}, nil)
+	suite.inventoryRepo.On("BulkRelease", orderContext("order-id-123"), []repository.InventoryReservation{
+		{ProductID: "product-1", Quantity: 2},
+		{ProductID: "product-2", Quantity: 1},
+	}).Return(nil)
+
+	// Execute
+	err := suite.orderService.CancelOrder(suite.ctx, order.ID)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	suite.paymentService.AssertExpectations(suite.T())
+}
+
+// Test CancelOrder - A failed refund is reported, the order staying cancelled for its
+// payments to be refunded separately
+func (suite *OrderServiceTestSuite) TestCancelOrder_RefundFailed() {
+	order := &models.Order{
+		ID:          "order-id-123",
+		Status:      models.OrderStatusPaid,
+		TotalAmount: 40.00,
+		Items:       []models.OrderItem{{ProductID: "product-1", Quantity: 2, TotalPrice: 40.00}},
+		Payments:    []models.Payment{{ID: "payment-id-789", Amount: 40.00, Status: models.PaymentStatusCompleted}},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, order.ID, models.OrderStatusCancelled).Return(true, nil)
+	suite.inventoryRepo.On("BulkRelease", orderContext("order-id-123"), []repository.InventoryReservation{{ProductID: "product-1", Quantity: 2}}).
+		Return(nil)
+	suite.paymentService.On("RefundPayment", suite.ctx, "payment-id-789", mock.Anything).
+		Return(nil, errors.New("refund declined: card closed"))
+
+	// Execute
+	err := suite.orderService.CancelOrder(suite.ctx, order.ID)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "refund declined")
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// Test CancelOrder - An order that shipped or was cancelled since it was read is neither
+// refunded nor restocked
+func (suite *OrderServiceTestSuite) TestCancelOrder_ChangedConcurrently() {
+	order := &models.Order{
+		ID:          "order-id-123",
+		Status:      models.OrderStatusPaid,
+		TotalAmount: 40.00,
+		Items:       []models.OrderItem{{ProductID: "product-1", Quantity: 2, TotalPrice: 40.00}},
+		Payments:    []models.Payment{{ID: "payment-id-789", Amount: 40.00, Status: models.PaymentStatusCompleted}},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, order.ID, models.OrderStatusCancelled).Return(false, nil)
+
+	// Execute
+	err := suite.orderService.CancelOrder(suite.ctx, order.ID)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "cannot be cancelled")
+	suite.paymentService.AssertNotCalled(suite.T(), "RefundPayment", mock.Anything, mock.Anything, mock.Anything)
+	suite.inventoryRepo.AssertNotCalled(suite.T(), "BulkRelease", mock.Anything, mock.Anything)
+}
+
+// Test CancelOrderItems - Cancelled items and their share of the payment method surcharge
+// are refunded, and their stock is returned to inventory
+func (suite *OrderServiceTestSuite) TestCancelOrderItems_PartialRefund() {
+	order := &models.Order{
+		ID:                    "order-id-123",
+		UserID:                "user-id-456",
+		Status:                models.OrderStatusPaid,
+		TotalAmount:           35.70,
+		PaymentAdjustmentRate: 2,
+		PaymentAdjustment:     0.70,
+		Items: []models.OrderItem{
+			{ProductID: "product-1", Quantity: 3, UnitPrice: 10.00, TotalPrice: 30.00},
+			{ProductID: "product-2", Quantity: 1, UnitPrice: 5.00, TotalPrice: 5.00},
+		},
+		Payments: []models.Payment{{ID: "payment-id-789", Amount: 35.70, Status: models.PaymentStatusCompleted}},
+	}
+	reduced := &models.Order{
+		ID:                    order.ID,
+		UserID:                order.UserID,
+		Status:                models.OrderStatusPaid,
+		TotalAmount:           25.50,
+		PaymentAdjustmentRate: 2,
+		PaymentAdjustment:     0.50,
+		Items: []models.OrderItem{
+			{ProductID: "product-1", Quantity: 2, UnitPrice: 10.00, TotalPrice: 20.00},
+			{ProductID: "product-2", Quantity: 1, UnitPrice: 5.00, TotalPrice: 5.00},
+		},
+		Payments: order.Payments,
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.orderRepo.On("CancelItems", suite.ctx, order.ID, map[string]int{"product-1": 1}).Return(reduced, 10.20, nil)
+	suite.paymentService.On("RefundPayment", suite.ctx, "payment-id-789", services.RefundRequest{Amount: 10.20, Reason: "order cancellation"}).
+		Return(&services.PaymentResponse{ID: "payment-id-789", Status: models.PaymentStatusCompleted, RefundedAmount: 10.20}, nil)
+	suite.inventoryRepo.On("BulkRelease", orderContext("order-id-123"), []repository.InventoryReservation{{ProductID: "product-1", Quantity: 1}}).
+		Return(nil)
+
+	// Execute
+	response, err := suite.orderService.CancelOrderItems(suite.ctx, order.ID, services.CancelOrderItemsRequest{
+		Items: []services.CancelOrderItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 25.50, response.Total)
+	assert.Equal(suite.T(), 10.20, response.Refunded)
+	assert.Equal(suite.T(), 2, response.Items[0].Quantity)
+	suite.paymentService.AssertExpectations(suite.T())
+}
+
+// Test CancelOrderItems - Cancelling more than was ordered, or everything, is rejected
+func (suite *OrderServiceTestSuite) TestCancelOrderItems_ValidationError() {
+	order := &models.Order{
+		ID:          "order-id-123",
+		Status:      models.OrderStatusPending,
+		TotalAmount: 30.00,
+		Items:       []models.OrderItem{{ProductID: "product-1", Quantity: 3, UnitPrice: 10.00, TotalPrice: 30.00}},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+
+	for _, quantity := range []int{4, 3} {
+		_, err := suite.orderService.CancelOrderItems(suite.ctx, order.ID, services.CancelOrderItemsRequest{
+			Items: []services.CancelOrderItem{{ProductID: "product-1", Quantity: quantity}},
+		})
+
+		assert.Error(suite.T(), err)
+		assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+	}
+	suite.orderRepo.AssertNotCalled(suite.T(), "CancelItems", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test CancelOrderItems - Items whose order changed since it was read are not refunded
+func (suite *OrderServiceTestSuite) TestCancelOrderItems_ChangedConcurrently() {
+	order := &models.Order{
+		ID:          "order-id-123",
+		Status:      models.OrderStatusPaid,
+		TotalAmount: 30.00,
+		Items:       []models.OrderItem{{ProductID: "product-1", Quantity: 3, UnitPrice: 10.00, TotalPrice: 30.00}},
+		Payments:    []models.Payment{{ID: "payment-id-789", Amount: 30.00, Status: models.PaymentStatusCompleted}},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, order.ID).Return(order, nil)
+	suite.orderRepo.On("CancelItems", suite.ctx, order.ID, map[string]int{"product-1": 1}).Return(nil, 0.0, nil)
+
+	// Execute
+	_, err := suite.orderService.CancelOrderItems(suite.ctx, order.ID, services.CancelOrderItemsRequest{
+		Items: []services.CancelOrderItem{{ProductID: "product-1", Quantity: 1}},
+	})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+	suite.paymentService.AssertNotCalled(suite.T(), "RefundPayment", mock.Anything, mock.Anything, mock.Anything)
+	suite.inventoryRepo.AssertNotCalled(suite.T(), "BulkRelease", mock.Anything, mock.Anything)
+}
+
+// Test GetUserOrders - The user's orders in the status are paged, newest first
+func (suite *OrderServiceTestSuite) TestGetUserOrders_FiltersByStatus() {
+	userID := "user-id-123"
+	orders := []*models.Order{
+		testutil.CreateTestOrder(userID, func(o *models.Order) { o.Status = models.OrderStatusPaid }),
+		testutil.CreateTestOrder(userID, func(o *models.Order) { o.Status = models.OrderStatusPaid }),
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(&models.User{ID: userID}, nil)
+	suite.orderRepo.On("ListByUserID", suite.ctx, userID, models.OrderStatusPaid, 10, 10).Return(orders, nil)
+	suite.orderRepo.On("CountByUserIDAndStatus", suite.ctx, userID, models.OrderStatusPaid).Return(int64(12), nil)
+
+	// Execute
+	response, err := suite.orderService.GetUserOrders(suite.ctx, userID, services.ListUserOrdersRequest{
+		Page:   2,
+		Limit:  10,
+		Status: models.OrderStatusPaid,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), response.Orders, 2)
+	assert.Equal(suite.T(), 2, response.Page)
+	assert.Equal(suite.T(), 10, response.Limit)
+	assert.Equal(suite.T(), 12, response.Total)
+	assert.Equal(suite.T(), models.OrderStatusPaid, response.Orders[0].Status)
+}
+
+// Test GetUserOrders - Without a page or status the first page of every order is listed
+func (suite *OrderServiceTestSuite) TestGetUserOrders_Defaults() {
+	userID := "user-id-123"
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, userID).Return(&models.User{ID: userID}, nil)
+	suite.orderRepo.On("ListByUserID", suite.ctx, userID, models.OrderStatus(""), 0, 20).Return([]*models.Order{}, nil)
+	suite.orderRepo.On("CountByUserIDAndStatus", suite.ctx, userID, models.OrderStatus("")).Return(int64(0), nil)
+
+	// Execute
+	response, err := suite.orderService.GetUserOrders(suite.ctx, userID, services.ListUserOrdersRequest{})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), response.Orders)
+	assert.Equal(suite.T(), 1, response.Page)
+	assert.Equal(suite.T(), 20, response.Limit)
+}
+
+// Test GetUserOrders - User Not Found
+func (suite *OrderServiceTestSuite) TestGetUserOrders_UserNotFound() {
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "missing-user").Return(nil, nil)
+
+	// Execute
+	response, err := suite.orderService.GetUserOrders(suite.ctx, "missing-user", services.ListUserOrdersRequest{})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+	suite.orderRepo.AssertNotCalled(suite.T(), "ListByUserID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 // Test ListOrders - Happy Path
 func (suite *OrderServiceTestSuite) TestListOrders_Success() {
 	userID := "user-id-123"
@@ -754,6 +1566,42 @@ func (suite *OrderServiceTestSuite) TestListOrders_FilterByStatus() {
 	assert.Equal(suite.T(), 1, len(response.Orders))
 }
 
+// Test ListOrders - Filter By Risk Band, with each order's risk score
+func (suite *OrderServiceTestSuite) TestListOrders_FilterByRiskBand() {
+	score := 70
+	orders := []*models.Order{
+		testutil.CreateTestOrder("user-id-123", func(o *models.Order) {
+			o.ID = "order-1"
+			o.Items = []models.OrderItem{}
+			o.RiskScore = &score
+			o.RiskSignals = []models.RiskSignal{{Code: services.RiskSignalHighValue, Points: 70}}
+		}),
+	}
+
+	req := services.ListOrdersRequest{
+		Page:        1,
+		Limit:       20,
+		RiskBand:    models.RiskBandHigh,
+		IncludeRisk: true,
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("ListByRiskBand", suite.ctx, models.RiskBandHigh, 0, 20).Return(orders, nil)
+	suite.orderRepo.On("CountByRiskBand", suite.ctx, models.RiskBandHigh).Return(int64(1), nil)
+
+	// Execute
+	response, err := suite.orderService.ListOrders(suite.ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Total)
+	assert.Equal(suite.T(), &services.OrderRisk{
+		Score:   70,
+		Band:    models.RiskBandHigh,
+		Signals: orders[0].RiskSignals,
+	}, response.Orders[0].Risk)
+}
+
 // Test ListOrders - Default Pagination
 func (suite *OrderServiceTestSuite) TestListOrders_DefaultPagination() {
 	orders := []*models.Order{}
@@ -817,6 +1665,141 @@ func (suite *OrderServiceTestSuite) TestListOrders_RepositoryError_Count() {
 	assert.Contains(suite.T(), err.Error(), "database error")
 }
 
+// Test StreamOrders - Every batch is passed through in order
+func (suite *OrderServiceTestSuite) TestStreamOrders_Success() {
+	iterator := mocks.NewOrderSliceIterator(
+		[]*models.Order{testutil.CreateTestOrder("user-1"), testutil.CreateTestOrder("user-2")},
+		[]*models.Order{testutil.CreateTestOrder("user-3")},
+	)
+	req := services.ListOrdersRequest{Status: models.OrderStatusPending}
+
+	// Mock expectations
+	suite.orderRepo.On("Iterate", repository.OrderFilter{Status: models.OrderStatusPending}, mock.AnythingOfType("int")).
+		Return(iterator)
+
+	// Execute
+	var userIDs []string
+	err := suite.orderService.StreamOrders(suite.ctx, req, func(order *services.OrderResponse) error {
+		userIDs = append(userIDs, order.UserID)
+		return nil
+	})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"user-1", "user-2", "user-3"}, userIDs)
+}
+
+// Test StreamOrders - Fetch errors stop the stream
+func (suite *OrderServiceTestSuite) TestStreamOrders_RepositoryError() {
+	iterator := mocks.NewOrderSliceIterator([]*models.Order{testutil.CreateTestOrder("user-1")})
+	iterator.Err = errors.New("database error")
+
+	// Mock expectations
+	suite.orderRepo.On("Iterate", repository.OrderFilter{}, mock.AnythingOfType("int")).Return(iterator)
+
+	// Execute
+	streamed := 0
+	err := suite.orderService.StreamOrders(suite.ctx, services.ListOrdersRequest{}, func(order *services.OrderResponse) error {
+		streamed++
+		return nil
+	})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "database error")
+	assert.Equal(suite.T(), 1, streamed)
+}
+
+// Test StreamOrders - Invalid metadata keys are rejected before querying
+func (suite *OrderServiceTestSuite) TestStreamOrders_InvalidMetadataKey() {
+	req := services.ListOrdersRequest{MetadataKey: "bad key!"}
+
+	// Execute
+	err := suite.orderService.StreamOrders(suite.ctx, req, func(order *services.OrderResponse) error {
+		return nil
+	})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// Test ImportOrders - Migrated orders are skipped and invalid orders are reported per order
+func (suite *OrderServiceTestSuite) TestImportOrders_ReportsSkippedAndFailedOrders() {
+	csv := strings.Join([]string{
+		"order_ref,customer_email,status,ordered_at,sku,quantity,unit_price",
+		"1001,buyer@example.com,delivered,2024-03-01,SKU-A,2,10.00",
+		"1002,buyer@example.com,shipped,2024-03-02,SKU-GONE,1,5.00",
+		"1001,buyer@example.com,delivered,2024-03-01,SKU-B,1,4.50",
+		"1003,other@example.com,delivered,2024-03-03,SKU-A,zero,1.00",
+		"1004,other@example.com,delivered,2024-03-04,SKU-A,1,1.00",
+		"1004,other@example.com,cancelled,2024-03-04,SKU-B,1,1.00",
+	}, "\n")
+	existing := &models.Order{ID: "order-1001", Channel: models.OrderChannelMigration, ExternalOrderID: "1001"}
+
+	// Mock expectations
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-A", "SKU-B", "SKU-GONE"}).Return([]*models.Product{
+		{ID: "product-a", SKU: "SKU-A"},
+		{ID: "product-b", SKU: "SKU-B"},
+	}, nil)
+	suite.orderRepo.On("GetByExternalID", suite.ctx, models.OrderChannelMigration, "1001").Return(existing, nil)
+	suite.orderRepo.On("GetByExternalID", suite.ctx, models.OrderChannelMigration, "1002").Return(nil, nil)
+
+	// Execute
+	response, err := suite.orderService.ImportOrders(suite.ctx, strings.NewReader(csv))
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 6, response.TotalRows)
+	assert.Equal(suite.T(), 4, response.TotalOrders)
+	assert.Equal(suite.T(), 0, response.Imported)
+	assert.Equal(suite.T(), 1, response.Skipped)
+	assert.Equal(suite.T(), 3, response.Failed)
+
+	assert.Equal(suite.T(), services.OrderImportStatusSkipped, response.Orders[0].Status)
+	assert.Equal(suite.T(), "order-1001", response.Orders[0].OrderID)
+	assert.Equal(suite.T(), []int{2, 4}, response.Orders[0].Rows)
+	assert.Contains(suite.T(), response.Orders[1].Error, "SKU-GONE not found")
+	assert.Contains(suite.T(), response.Orders[2].Error, "row 5: quantity")
+	assert.Contains(suite.T(), response.Orders[3].Error, "row 7: customer_email and status must match")
+}
+
+// Test ImportOrders - Validation Error: required column missing
+func (suite *OrderServiceTestSuite) TestImportOrders_ValidationError_MissingColumn() {
+	csv := "order_ref,customer_email,status,sku,quantity,unit_price\n1001,buyer@example.com,delivered,SKU-A,1,10.00"
+
+	// Execute
+	response, err := suite.orderService.ImportOrders(suite.ctx, strings.NewReader(csv))
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "missing the ordered_at column")
+}
+
+// Test ImportOrders - Validation Error: invalid payment columns fail the order
+func (suite *OrderServiceTestSuite) TestImportOrders_InvalidPaymentMethod() {
+	csv := "order_ref,customer_email,status,ordered_at,sku,quantity,unit_price,payment_method\n" +
+		"1001,buyer@example.com,paid,2024-03-01T10:00:00Z,SKU-A,1,10.00,barter"
+
+	// Execute
+	response, err := suite.orderService.ImportOrders(suite.ctx, strings.NewReader(csv))
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Failed)
+	assert.Contains(suite.T(), response.Orders[0].Error, `invalid payment_method "barter"`)
+	suite.productRepo.AssertNotCalled(suite.T(), "GetBySKUs", mock.Anything, mock.Anything)
+}
+
+// orderContext matches the context of inventory changes recorded against an order
+func orderContext(orderID string) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		source, ok := repository.InventoryEventSourceFrom(ctx)
+		return ok && source.ReferenceType == models.InventoryReferenceOrder && source.ReferenceID == orderID
+	})
+}
+
 // TestOrderServiceTestSuite runs the test suite
 func TestOrderServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(OrderServiceTestSuite))
@@ -0,0 +1,350 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/i18n"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// OrderStatusNotifierTestSuite defines the test suite for OrderStatusNotifier
+type OrderStatusNotifierTestSuite struct {
+	suite.Suite
+	notifier         services.OrderStatusNotifier
+	notificationRepo *mocks.MockNotificationRepository
+	userRepo         *mocks.MockUserRepository
+	orderRepo        *mocks.MockOrderRepository
+	logger           *logger.Logger
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrderStatusNotifierTestSuite) SetupTest() {
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	settings, err := services.NewOrderStatusNotificationSettings(
+		[]string{"shipped", "cancelled"},
+		[]string{"email", "in_app"},
+	)
+	suite.Require().NoError(err)
+
+	notificationService := services.NewNotificationService(suite.notificationRepo, suite.userRepo, nil, nil, nil, suite.logger)
+	suite.notifier = services.NewOrderStatusNotifier(settings, notificationService, suite.userRepo, i18n.NewTranslator(), suite.logger)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrderStatusNotifierTestSuite) TearDownTest() {
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// Test NotifyOrderStatusChange - One notification per configured channel
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderStatusChange_SendsOnEachChannel() {
+	order := &models.Order{ID: "order-1", UserID: "user-1"}
+	var sent []*models.Notification
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*models.Notification))
+		}).Return(nil)
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Execute
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusShipped)
+
+	// Assert
+	suite.Require().Len(sent, 2)
+	assert.Equal(suite.T(), models.NotificationChannelEmail, sent[0].Channel)
+	assert.Equal(suite.T(), models.NotificationChannelInApp, sent[1].Channel)
+	for _, notification := range sent {
+		assert.Equal(suite.T(), "user-1", notification.UserID)
+		assert.Equal(suite.T(), models.NotificationTypeOrderShipped, notification.Type)
+		assert.Contains(suite.T(), notification.Body, "order-1")
+		assert.Equal(suite.T(), "Order shipped", notification.Title)
+		assert.JSONEq(suite.T(), `{"order_id":"order-1","status":"shipped"}`, notification.Data)
+	}
+}
+
+// Test NotifyOrderStatusChange - Notifications use the customer's locale
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderStatusChange_CustomerLocale() {
+	order := &models.Order{ID: "order-1", UserID: "user-1"}
+	var sent []*models.Notification
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1", Locale: "es"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*models.Notification))
+		}).Return(nil)
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Execute
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusShipped)
+
+	// Assert
+	suite.Require().Len(sent, 2)
+	assert.Equal(suite.T(), "Pedido enviado", sent[0].Title)
+	assert.Equal(suite.T(), "Su pedido order-1 está en camino.", sent[0].Body)
+}
+
+// Test NotifyOrderStatusChange - Gift orders are flagged and worded as gifts where the
+// catalogs have a gift wording for the status
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderStatusChange_GiftOrder() {
+	order := &models.Order{ID: "order-1", UserID: "user-1", IsGift: true}
+	var sent []*models.Notification
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1", Locale: "fr"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*models.Notification))
+		}).Return(nil)
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Execute
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusShipped)
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusCancelled)
+
+	// Assert
+	suite.Require().Len(sent, 4)
+	assert.Equal(suite.T(), "Votre commande cadeau order-1 est en route vers son destinataire.", sent[0].Body)
+	assert.JSONEq(suite.T(), `{"order_id":"order-1","status":"shipped","gift":"true"}`, sent[0].Data)
+	// Cancellations have no gift wording
+	assert.Equal(suite.T(), "Votre commande order-1 a été annulée.", sent[2].Body)
+}
+
+// Test NotifyOrderRefunded - The refunded amount is in the message and the notification data
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderRefunded() {
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusCancelled}
+	var sent []*models.Notification
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*models.Notification))
+		}).Return(nil)
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Execute
+	suite.notifier.NotifyOrderRefunded(suite.ctx, order, 12.5)
+
+	// Assert
+	suite.Require().Len(sent, 2)
+	assert.Equal(suite.T(), models.NotificationTypeOrderRefunded, sent[0].Type)
+	assert.Equal(suite.T(), "A refund of 12.50 for your order order-1 is on its way.", sent[0].Body)
+	assert.Contains(suite.T(), sent[0].Data, `"amount":"12.50"`)
+}
+
+// Test NotifyOrderStatusChange - Statuses that are not configured send nothing
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderStatusChange_StatusNotConfigured() {
+	order := &models.Order{ID: "order-1", UserID: "user-1"}
+
+	// Execute
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusConfirmed)
+
+	// Assert
+	suite.notificationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test NotifyOrderConfirmed - A confirmed paid order is notified as confirmed when
+// confirmations are configured
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderConfirmed() {
+	settings, err := services.NewOrderStatusNotificationSettings([]string{"confirmed"}, []string{"email"})
+	suite.Require().NoError(err)
+	notifier := services.NewOrderStatusNotifier(settings,
+		services.NewNotificationService(suite.notificationRepo, suite.userRepo, nil, nil, nil, suite.logger),
+		suite.userRepo, i18n.NewTranslator(), suite.logger)
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusPaid}
+	var sent []*models.Notification
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Notification")).
+		Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(1).(*models.Notification))
+		}).Return(nil)
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// Execute
+	notifier.NotifyOrderConfirmed(suite.ctx, order)
+
+	// Assert
+	suite.Require().Len(sent, 1)
+	assert.Equal(suite.T(), models.NotificationTypeOrderConfirmed, sent[0].Type)
+	assert.Equal(suite.T(), "Order confirmed", sent[0].Title)
+	assert.JSONEq(suite.T(), `{"order_id":"order-1","status":"paid"}`, sent[0].Data)
+}
+
+// Test NotifyOrderConfirmed - Nothing is sent unless confirmations are configured
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderConfirmed_NotConfigured() {
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusPaid}
+
+	// Execute
+	suite.notifier.NotifyOrderConfirmed(suite.ctx, order)
+
+	// Assert
+	suite.notificationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test NotifyOrderStatusChange - A failed channel does not stop the others
+func (suite *OrderStatusNotifierTestSuite) TestNotifyOrderStatusChange_ContinuesAfterFailure() {
+	order := &models.Order{ID: "order-1", UserID: "user-1"}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Channel == models.NotificationChannelEmail
+	})).Return(errors.New("database error")).Once()
+	suite.notificationRepo.On("Create", suite.ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Channel == models.NotificationChannelInApp
+	})).Return(nil).Once()
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, models.NotificationChannelInApp, mock.Anything).Return(nil).Once()
+
+	// Execute
+	suite.notifier.NotifyOrderStatusChange(suite.ctx, order, models.OrderStatusCancelled)
+}
+
+// Test RelayOrderEvents - The customer is notified of status changes in the outbox, and
+// the cursor moves past the events relayed
+func (suite *OrderStatusNotifierTestSuite) TestRelayOrderEvents_NotifiesStatusChanges() {
+	orderEventRepo := new(mocks.MockOrderEventRepository)
+	relay := services.NewOrderNotificationRelay(services.OrderNotificationRelaySettings{}, orderEventRepo, suite.orderRepo, suite.notifier, suite.logger)
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusCancelled}
+	events := []*models.OrderEvent{
+		orderEvent(6, models.OrderEventCreated, "order-1", "web", "", models.OrderStatusPending),
+		orderEvent(7, models.OrderEventPaymentCompleted, "order-1", "web", "", models.OrderStatusPending),
+		orderEvent(8, models.OrderEventStatusChanged, "order-1", "web", models.OrderStatusPending, models.OrderStatusCancelled),
+	}
+
+	// Mock expectations
+	orderEventRepo.On("GetCursor", suite.ctx, "order_notifications").Return(int64(5), true, nil)
+	orderEventRepo.On("ListSince", suite.ctx, int64(5), mock.Anything, mock.Anything).Return(events, nil)
+	orderEventRepo.On("SaveCursor", suite.ctx, "order_notifications", int64(8)).Return(nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil).Once()
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1"}, nil)
+	suite.notificationRepo.On("Create", suite.ctx, mock.MatchedBy(func(n *models.Notification) bool {
+		return n.Type == models.NotificationTypeOrderCancelled
+	})).Return(nil).Twice()
+	suite.notificationRepo.On("MarkSent", suite.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+
+	// Execute
+	notified, err := relay.RelayOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, notified)
+	orderEventRepo.AssertExpectations(suite.T())
+}
+
+// Test RelayOrderEvents - The first run starts from the latest event without notifying
+func (suite *OrderStatusNotifierTestSuite) TestRelayOrderEvents_FirstRunStartsAtLatest() {
+	orderEventRepo := new(mocks.MockOrderEventRepository)
+	relay := services.NewOrderNotificationRelay(services.OrderNotificationRelaySettings{}, orderEventRepo, suite.orderRepo, suite.notifier, suite.logger)
+
+	// Mock expectations
+	orderEventRepo.On("GetCursor", suite.ctx, "order_notifications").Return(int64(0), false, nil)
+	orderEventRepo.On("LatestSequence", suite.ctx).Return(int64(42), nil)
+	orderEventRepo.On("SaveCursor", suite.ctx, "order_notifications", int64(42)).Return(nil)
+
+	// Execute
+	notified, err := relay.RelayOrderEvents(suite.ctx)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, notified)
+	orderEventRepo.AssertExpectations(suite.T())
+	orderEventRepo.AssertNotCalled(suite.T(), "ListSince", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test RelayOrderEvents - The cursor stays put when the events cannot be read, so they
+// are notified on the next run
+func (suite *OrderStatusNotifierTestSuite) TestRelayOrderEvents_ListFailureKeepsCursor() {
+	orderEventRepo := new(mocks.MockOrderEventRepository)
+	relay := services.NewOrderNotificationRelay(services.OrderNotificationRelaySettings{}, orderEventRepo, suite.orderRepo, suite.notifier, suite.logger)
+
+	// Mock expectations
+	orderEventRepo.On("GetCursor", suite.ctx, "order_notifications").Return(int64(5), true, nil)
+	orderEventRepo.On("ListSince", suite.ctx, int64(5), mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	// Execute
+	_, err := relay.RelayOrderEvents(suite.ctx)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	orderEventRepo.AssertNotCalled(suite.T(), "SaveCursor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test UpdateOrderStatus - No notification when the status update fails
+func (suite *OrderStatusNotifierTestSuite) TestUpdateOrderStatus_NoNotificationOnFailure() {
+	orderService := services.NewOrderService(
+		nil, // DB not needed for status updates
+		suite.orderRepo,
+		new(mocks.MockOrderItemRepository),
+		new(mocks.MockProductRepository),
+		new(mocks.MockInventoryRepository),
+		suite.userRepo,
+		nil,
+		nil, // No stock webhook subscribers
+		nil, // No activity tracking
+		nil, // No payment method adjustments
+		nil, // No store hours
+		nil, // No gift options
+		nil, // No duplicate order check
+		nil, // No order limits
+		nil, // No allocation queue
+		suite.notifier,
+		nil, // No refunds
+		nil, // No fraud screening
+		nil, // No risk scoring
+		nil, // No price lists
+		nil, // No promotions
+		nil, // No stage metrics
+		suite.logger,
+	)
+	order := &models.Order{ID: "order-1", UserID: "user-1", Status: models.OrderStatusPaid}
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("TransitionStatus", suite.ctx, "order-1", models.OrderStatusShipped).Return(false, errors.New("database error"))
+
+	// Execute
+	response, err := orderService.UpdateOrderStatus(suite.ctx, "order-1", models.OrderStatusShipped)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	suite.notificationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test NewOrderStatusNotificationSettings - Unknown statuses and channels are rejected
+func (suite *OrderStatusNotifierTestSuite) TestNewOrderStatusNotificationSettings_Invalid() {
+	_, err := services.NewOrderStatusNotificationSettings([]string{"paid"}, []string{"email"})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "no customer notification")
+
+	_, err = services.NewOrderStatusNotificationSettings([]string{"shipped"}, []string{"pigeon"})
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "unknown notification channel")
+}
+
+// TestOrderStatusNotifierTestSuite runs the test suite
+func TestOrderStatusNotifierTestSuite(t *testing.T) {
+	suite.Run(t, new(OrderStatusNotifierTestSuite))
+}
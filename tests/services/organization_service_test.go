@@ -0,0 +1,361 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// OrganizationServiceTestSuite defines the test suite for OrganizationService
+type OrganizationServiceTestSuite struct {
+	suite.Suite
+	organizationService services.OrganizationService
+	organizationRepo    *mocks.MockOrganizationRepository
+	userRepo            *mocks.MockUserRepository
+	orderRepo           *mocks.MockOrderRepository
+	logger              *logger.Logger
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *OrganizationServiceTestSuite) SetupTest() {
+	suite.organizationRepo = new(mocks.MockOrganizationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.organizationService = services.NewOrganizationService(
+		suite.organizationRepo,
+		suite.userRepo,
+		suite.orderRepo,
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *OrganizationServiceTestSuite) TearDownTest() {
+	suite.organizationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateOrganization - Names are unique
+func (suite *OrganizationServiceTestSuite) TestCreateOrganization_DuplicateName() {
+	existing := &models.Organization{ID: "org-1", Name: "Acme"}
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByName", suite.ctx, "Acme").Return(existing, nil)
+
+	// Execute
+	response, err := suite.organizationService.CreateOrganization(suite.ctx, services.CreateOrganizationRequest{
+		Name: " Acme ",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.organizationRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test CreateOrganization - Happy Path
+func (suite *OrganizationServiceTestSuite) TestCreateOrganization_Success() {
+	// Mock expectations
+	suite.organizationRepo.On("GetByName", suite.ctx, "Acme").Return(nil, nil)
+	suite.organizationRepo.On("Create", suite.ctx, mock.MatchedBy(func(organization *models.Organization) bool {
+		return organization.Name == "Acme" && organization.CreditLimit == 5000 &&
+			organization.PaymentTermsDays == 30 && organization.IsActive
+	})).Return(nil)
+
+	// Execute
+	response, err := suite.organizationService.CreateOrganization(suite.ctx, services.CreateOrganizationRequest{
+		Name:             "Acme",
+		CreditLimit:      5000,
+		PaymentTermsDays: 30,
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal("Acme", response.Name)
+	suite.Equal(30, response.PaymentTermsDays)
+}
+
+// Test AddMember - Users belong to at most one organization
+func (suite *OrganizationServiceTestSuite) TestAddMember_UserInAnotherOrganization() {
+	otherOrganizationID := "org-2"
+	organization := &models.Organization{ID: "org-1", Name: "Acme"}
+	user := testutil.CreateTestUser(func(u *models.User) {
+		u.ID = "user-1"
+		u.OrganizationID = &otherOrganizationID
+	})
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByID", suite.ctx, "org-1").Return(organization, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+
+	// Execute
+	response, err := suite.organizationService.AddMember(suite.ctx, "org-1", "user-1")
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "another organization")
+	suite.userRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+// Test AddMember - Happy Path
+func (suite *OrganizationServiceTestSuite) TestAddMember_Success() {
+	organization := &models.Organization{ID: "org-1", Name: "Acme"}
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = "user-1" })
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByID", suite.ctx, "org-1").Return(organization, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+	suite.userRepo.On("Update", suite.ctx, mock.MatchedBy(func(u *models.User) bool {
+		return u.OrganizationID != nil && *u.OrganizationID == "org-1"
+	})).Return(nil)
+	suite.userRepo.On("ListByOrganization", suite.ctx, "org-1").Return([]*models.User{user}, nil)
+	suite.orderRepo.On("GetOutstandingBalance", suite.ctx, "org-1").Return(0.0, nil)
+
+	// Execute
+	response, err := suite.organizationService.AddMember(suite.ctx, "org-1", "user-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(response.Members, 1)
+	suite.Equal("user-1", response.Members[0].ID)
+}
+
+// Test RemoveMember - Users can only be removed from their own organization
+func (suite *OrganizationServiceTestSuite) TestRemoveMember_NotAMember() {
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = "user-1" })
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+
+	// Execute
+	err := suite.organizationService.RemoveMember(suite.ctx, "org-1", "user-1")
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "not found")
+}
+
+// Test ListOrganizationOrders - Members see every order of their organization
+func (suite *OrganizationServiceTestSuite) TestListOrganizationOrders_SharedVisibility() {
+	organizationID := "org-1"
+	user := testutil.CreateTestUser(func(u *models.User) {
+		u.ID = "user-1"
+		u.OrganizationID = &organizationID
+	})
+	orders := []*models.Order{
+		testutil.CreateTestOrder("user-1", func(o *models.Order) { o.OrganizationID = &organizationID }),
+		testutil.CreateTestOrder("user-2", func(o *models.Order) { o.OrganizationID = &organizationID; o.OnAccount = true }),
+	}
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+	suite.orderRepo.On("ListByOrganization", suite.ctx, organizationID, 0, 20).Return(orders, nil)
+	suite.orderRepo.On("CountByOrganization", suite.ctx, organizationID).Return(int64(2), nil)
+
+	// Execute
+	response, err := suite.organizationService.ListOrganizationOrders(suite.ctx, "user-1", services.ListOrdersRequest{})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(2, response.Total)
+	suite.Require().Len(response.Orders, 2)
+	suite.Equal("user-2", response.Orders[1].UserID)
+	suite.True(response.Orders[1].OnAccount)
+}
+
+// Test ListOrganizationOrders - Users without an organization have no organization orders
+func (suite *OrganizationServiceTestSuite) TestListOrganizationOrders_NoOrganization() {
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = "user-1" })
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+
+	// Execute
+	response, err := suite.organizationService.ListOrganizationOrders(suite.ctx, "user-1", services.ListOrdersRequest{})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "organization not found")
+}
+
+// Test ImportUsers - Rows create users and organizations, move unassigned users and report invalid rows
+func (suite *OrganizationServiceTestSuite) TestImportUsers_CreatesUpdatesAndFails() {
+	existing := testutil.CreateTestUser(func(u *models.User) {
+		u.ID = "user-existing"
+		u.Email = "existing@acme.test"
+	})
+	csv := strings.Join([]string{
+		"email,name,organization,password",
+		"new@acme.test,New Buyer,Acme,",
+		"EXISTING@acme.test,Existing Buyer,Acme,",
+		"solo@example.test,Solo Buyer,,supersecret",
+		"not-an-email,Broken,Acme,",
+		"short@acme.test,Short Password,Acme,short",
+	}, "\n")
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByName", suite.ctx, "Acme").Return(nil, nil).Once()
+	suite.organizationRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.Organization")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Organization).ID = "org-1"
+	}).Return(nil).Once()
+	suite.userRepo.On("GetByEmail", suite.ctx, "new@acme.test").Return(nil, nil)
+	suite.userRepo.On("Create", suite.ctx, mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "new@acme.test" && u.OrganizationID != nil && *u.OrganizationID == "org-1" &&
+			u.Role == models.UserRoleCustomer && u.Password != ""
+	})).Return(nil)
+	suite.userRepo.On("GetByEmail", suite.ctx, "existing@acme.test").Return(existing, nil)
+	suite.userRepo.On("Update", suite.ctx, mock.MatchedBy(func(u *models.User) bool {
+		return u.ID == "user-existing" && u.OrganizationID != nil && *u.OrganizationID == "org-1"
+	})).Return(nil)
+	suite.userRepo.On("GetByEmail", suite.ctx, "solo@example.test").Return(nil, nil)
+	suite.userRepo.On("Create", suite.ctx, mock.MatchedBy(func(u *models.User) bool {
+		return u.Email == "solo@example.test" && u.OrganizationID == nil
+	})).Return(nil)
+
+	// Execute
+	response, err := suite.organizationService.ImportUsers(suite.ctx, strings.NewReader(csv))
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(5, response.TotalRows)
+	suite.Equal(2, response.Created)
+	suite.Equal(1, response.Updated)
+	suite.Equal(2, response.Failed)
+	suite.Equal(1, response.OrganizationsCreated)
+	suite.Require().Len(response.Users, 5)
+	suite.Equal(services.UserImportStatusUpdated, response.Users[1].Status)
+	suite.Equal("org-1", response.Users[1].OrganizationID)
+	suite.Equal(5, response.Users[3].Row) // The header is row 1
+	suite.Contains(response.Users[3].Error, "email")
+	suite.Contains(response.Users[4].Error, "password")
+}
+
+// Test ImportUsers - The header must name the required columns
+func (suite *OrganizationServiceTestSuite) TestImportUsers_MissingColumn() {
+	// Execute
+	response, err := suite.organizationService.ImportUsers(suite.ctx, strings.NewReader("email,organization\nnew@acme.test,Acme\n"))
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "missing the name column")
+}
+
+// Test GetOrganization - The outstanding balance is reported against the credit limit
+func (suite *OrganizationServiceTestSuite) TestGetOrganization_CreditPosition() {
+	organization := &models.Organization{ID: "org-1", Name: "Acme", CreditLimit: 1000, PaymentTermsDays: 30}
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByID", suite.ctx, "org-1").Return(organization, nil)
+	suite.userRepo.On("ListByOrganization", suite.ctx, "org-1").Return([]*models.User{}, nil)
+	suite.orderRepo.On("GetOutstandingBalance", suite.ctx, "org-1").Return(1250.4, nil)
+
+	// Execute
+	response, err := suite.organizationService.GetOrganization(suite.ctx, "org-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().NotNil(response.OutstandingBalance)
+	suite.Equal(1250.4, *response.OutstandingBalance)
+	suite.Equal(-250.4, *response.AvailableCredit)
+}
+
+// Test ReviewCreditHold - Approving overrides the limit and keeps the order pending
+func (suite *OrganizationServiceTestSuite) TestReviewCreditHold_Approve() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) {
+		o.ID = "order-1"
+		o.OnAccount = true
+		o.CreditHold = true
+	})
+	reviewed := testutil.CreateTestOrder("user-1", func(o *models.Order) {
+		o.ID = "order-1"
+		o.OnAccount = true
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("ReviewCreditHold", suite.ctx, "order-1", "admin-1", models.OrderStatusPending).Return(true, nil)
+	suite.orderRepo.On("GetByIDWithItems", suite.ctx, "order-1").Return(reviewed, nil)
+
+	// Execute
+	response, err := suite.organizationService.ReviewCreditHold(suite.ctx, "order-1", "admin-1", services.ReviewCreditHoldRequest{
+		Decision: services.CreditReviewApprove,
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(models.OrderStatusPending, response.Status)
+	suite.False(response.CreditHold)
+}
+
+// Test ReviewCreditHold - Rejecting cancels the order
+func (suite *OrganizationServiceTestSuite) TestReviewCreditHold_Reject() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) {
+		o.ID = "order-1"
+		o.OnAccount = true
+		o.CreditHold = true
+	})
+	reviewed := testutil.CreateTestOrder("user-1", func(o *models.Order) {
+		o.ID = "order-1"
+		o.Status = models.OrderStatusCancelled
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("ReviewCreditHold", suite.ctx, "order-1", "admin-1", models.OrderStatusCancelled).Return(true, nil)
+	suite.orderRepo.On("GetByIDWithItems", suite.ctx, "order-1").Return(reviewed, nil)
+
+	// Execute
+	response, err := suite.organizationService.ReviewCreditHold(suite.ctx, "order-1", "admin-1", services.ReviewCreditHoldRequest{
+		Decision: services.CreditReviewReject,
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(models.OrderStatusCancelled, response.Status)
+}
+
+// Test ReviewCreditHold - Orders that are not held, or were reviewed concurrently, conflict
+func (suite *OrganizationServiceTestSuite) TestReviewCreditHold_NotOnHold() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) {
+		o.ID = "order-1"
+		o.CreditHold = true
+	})
+
+	// Mock expectations
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("ReviewCreditHold", suite.ctx, "order-1", "admin-1", models.OrderStatusPending).Return(false, nil)
+
+	// Execute
+	response, err := suite.organizationService.ReviewCreditHold(suite.ctx, "order-1", "admin-1", services.ReviewCreditHoldRequest{
+		Decision: services.CreditReviewApprove,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.orderRepo.AssertNotCalled(suite.T(), "GetByIDWithItems", mock.Anything, mock.Anything)
+}
+
+// TestOrganizationServiceTestSuite runs the test suite
+func TestOrganizationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(OrganizationServiceTestSuite))
+}
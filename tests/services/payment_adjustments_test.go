@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// PaymentMethodAdjustmentsTestSuite defines the test suite for checkout payment method adjustments
+type PaymentMethodAdjustmentsTestSuite struct {
+	suite.Suite
+	adjustments services.PaymentMethodAdjustments
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentMethodAdjustmentsTestSuite) SetupTest() {
+	adjustments, err := services.NewPaymentMethodAdjustments(map[string]float64{
+		"credit_card":   2,
+		"bank_transfer": -1,
+	})
+	suite.Require().NoError(err)
+	suite.adjustments = adjustments
+}
+
+// Test Apply - Surcharges are rounded to cents
+func (suite *PaymentMethodAdjustmentsTestSuite) TestApply_Surcharge() {
+	rate, amount := suite.adjustments.Apply(models.PaymentMethodCreditCard, 19.99)
+
+	assert.Equal(suite.T(), 2.0, rate)
+	assert.Equal(suite.T(), 0.40, amount)
+}
+
+// Test Apply - Negative rates are discounts
+func (suite *PaymentMethodAdjustmentsTestSuite) TestApply_Discount() {
+	rate, amount := suite.adjustments.Apply(models.PaymentMethodBankTransfer, 250.00)
+
+	assert.Equal(suite.T(), -1.0, rate)
+	assert.Equal(suite.T(), -2.50, amount)
+}
+
+// Test Apply - Unconfigured methods, and no adjustments at all, leave the total unchanged
+func (suite *PaymentMethodAdjustmentsTestSuite) TestApply_NotConfigured() {
+	rate, amount := suite.adjustments.Apply(models.PaymentMethodPayPal, 100.00)
+	assert.Zero(suite.T(), rate)
+	assert.Zero(suite.T(), amount)
+
+	rate, amount = services.PaymentMethodAdjustments(nil).Apply(models.PaymentMethodCreditCard, 100.00)
+	assert.Zero(suite.T(), rate)
+	assert.Zero(suite.T(), amount)
+}
+
+// Test NewPaymentMethodAdjustments - Unknown payment methods are rejected
+func (suite *PaymentMethodAdjustmentsTestSuite) TestNew_UnknownMethod() {
+	adjustments, err := services.NewPaymentMethodAdjustments(map[string]float64{"crypto": 1})
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), adjustments)
+	assert.Contains(suite.T(), err.Error(), "unknown payment method")
+}
+
+// TestPaymentMethodAdjustmentsTestSuite runs the test suite
+func TestPaymentMethodAdjustmentsTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentMethodAdjustmentsTestSuite))
+}
@@ -0,0 +1,297 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// PaymentIntentServiceTestSuite defines the test suite for PaymentIntentService
+type PaymentIntentServiceTestSuite struct {
+	suite.Suite
+	intentService  services.PaymentIntentService
+	intentRepo     *mocks.MockPaymentIntentRepository
+	orderRepo      *mocks.MockOrderRepository
+	paymentRepo    *mocks.MockPaymentRepository
+	paymentService *mocks.MockPaymentService
+	ctx            context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentIntentServiceTestSuite) SetupTest() {
+	suite.intentRepo = new(mocks.MockPaymentIntentRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.paymentService = new(mocks.MockPaymentService)
+	suite.ctx = context.Background()
+
+	suite.intentService = services.NewPaymentIntentService(
+		services.PaymentIntentSettings{TTL: 30 * time.Minute},
+		suite.intentRepo,
+		suite.orderRepo,
+		suite.paymentRepo,
+		suite.paymentService,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PaymentIntentServiceTestSuite) TearDownTest() {
+	suite.intentRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.paymentService.AssertExpectations(suite.T())
+}
+
+// testPaymentIntent returns an intent of user-1 awaiting confirmation
+func testPaymentIntent(overrides ...func(*models.PaymentIntent)) *models.PaymentIntent {
+	intent := &models.PaymentIntent{
+		ID:        "intent-1",
+		OrderID:   "order-1",
+		UserID:    "user-1",
+		Amount:    199.99,
+		Currency:  "USD",
+		Method:    models.PaymentMethodCreditCard,
+		Status:    models.PaymentIntentStatusRequiresConfirmation,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	for _, override := range overrides {
+		override(intent)
+	}
+	return intent
+}
+
+// Test CreateIntent - The intent is for what is left to pay of the order by default
+func (suite *PaymentIntentServiceTestSuite) TestCreateIntent_DefaultsToBalance() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) { o.ID = "order-1" })
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(50.0, nil)
+	suite.intentRepo.On("Create", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.OrderID == "order-1" && intent.UserID == "user-1" && intent.Amount == 149.99 &&
+			intent.Status == models.PaymentIntentStatusRequiresConfirmation &&
+			time.Until(intent.ExpiresAt) > 29*time.Minute
+	}), mock.Anything).Return(true, nil)
+
+	response, err := suite.intentService.CreateIntent(suite.ctx, "user-1", services.CreatePaymentIntentRequest{
+		OrderID:     "order-1",
+		PaymentType: "credit_card",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(149.99, response.Amount)
+	suite.Equal(models.PaymentIntentStatusRequiresConfirmation, response.Status)
+	suite.Nil(response.Payment)
+}
+
+// Test CreateIntent - Other users' orders are not found
+func (suite *PaymentIntentServiceTestSuite) TestCreateIntent_OtherUsersOrder() {
+	order := testutil.CreateTestOrder("user-2", func(o *models.Order) { o.ID = "order-1" })
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+
+	_, err := suite.intentService.CreateIntent(suite.ctx, "user-1", services.CreatePaymentIntentRequest{
+		OrderID:     "order-1",
+		PaymentType: "credit_card",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test CreateIntent - An intent cannot ask for more than is left to pay
+func (suite *PaymentIntentServiceTestSuite) TestCreateIntent_AmountExceedsBalance() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) { o.ID = "order-1" })
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(150.0, nil)
+
+	_, err := suite.intentService.CreateIntent(suite.ctx, "user-1", services.CreatePaymentIntentRequest{
+		OrderID:     "order-1",
+		Amount:      100,
+		PaymentType: "credit_card",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// Test CreateIntent - An order has at most one open intent
+func (suite *PaymentIntentServiceTestSuite) TestCreateIntent_OpenIntentExists() {
+	order := testutil.CreateTestOrder("user-1", func(o *models.Order) { o.ID = "order-1" })
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.paymentRepo.On("SumCompletedByOrderID", suite.ctx, "order-1").Return(0.0, nil)
+	suite.intentRepo.On("Create", suite.ctx, mock.Anything, mock.Anything).Return(false, nil)
+
+	_, err := suite.intentService.CreateIntent(suite.ctx, "user-1", services.CreatePaymentIntentRequest{
+		OrderID:     "order-1",
+		PaymentType: "debit_card",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test ConfirmIntent - A completed payment succeeds the intent
+func (suite *PaymentIntentServiceTestSuite) TestConfirmIntent_Succeeded() {
+	intent := testPaymentIntent()
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+	suite.intentRepo.On("Claim", suite.ctx, intent, mock.Anything).Return(true, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, services.ProcessPaymentRequest{
+		OrderID:           "order-1",
+		Amount:            199.99,
+		PaymentType:       "credit_card",
+		ExternalReference: "tok_visa",
+		ClientIP:          "203.0.113.7",
+	}).Return(&services.PaymentResponse{ID: "payment-1", OrderID: "order-1", Amount: 199.99, Status: models.PaymentStatusCompleted}, nil)
+	suite.intentRepo.On("Transition", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.Status == models.PaymentIntentStatusSucceeded && *intent.PaymentID == "payment-1"
+	}), models.PaymentIntentStatusProcessing).Return(true, nil)
+
+	response, err := suite.intentService.ConfirmIntent(suite.ctx, "user-1", "intent-1", services.ConfirmPaymentIntentRequest{
+		ExternalReference: " tok_visa ",
+		ClientIP:          "203.0.113.7",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentIntentStatusSucceeded, response.Status)
+	suite.Equal("payment-1", response.Payment.ID)
+}
+
+// Test ConfirmIntent - A payment held for retry leaves the intent processing
+func (suite *PaymentIntentServiceTestSuite) TestConfirmIntent_PaymentHeld() {
+	intent := testPaymentIntent()
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+	suite.intentRepo.On("Claim", suite.ctx, intent, mock.Anything).Return(true, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, mock.Anything).
+		Return(&services.PaymentResponse{ID: "payment-1", Status: models.PaymentStatusHeld}, nil)
+	suite.intentRepo.On("Transition", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.Status == models.PaymentIntentStatusProcessing && *intent.PaymentID == "payment-1"
+	}), models.PaymentIntentStatusProcessing).Return(true, nil)
+
+	response, err := suite.intentService.ConfirmIntent(suite.ctx, "user-1", "intent-1", services.ConfirmPaymentIntentRequest{
+		ExternalReference: "tok_visa",
+	})
+
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentIntentStatusProcessing, response.Status)
+}
+
+// Test ConfirmIntent - A declined payment returns the intent to awaiting confirmation
+func (suite *PaymentIntentServiceTestSuite) TestConfirmIntent_Declined() {
+	intent := testPaymentIntent()
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+	suite.intentRepo.On("Claim", suite.ctx, intent, mock.Anything).Return(true, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, mock.Anything).
+		Return(nil, errors.New("payment processing failed: card declined"))
+	suite.intentRepo.On("Transition", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.Status == models.PaymentIntentStatusRequiresConfirmation &&
+			intent.LastError == "payment processing failed: card declined"
+	}), models.PaymentIntentStatusProcessing).Return(true, nil)
+
+	_, err := suite.intentService.ConfirmIntent(suite.ctx, "user-1", "intent-1", services.ConfirmPaymentIntentRequest{
+		ExternalReference: "tok_declined",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "PAYMENT_FAILED")
+	suite.Contains(err.Error(), "card declined")
+}
+
+// Test ConfirmIntent - A lapsed intent expires instead of taking a payment
+func (suite *PaymentIntentServiceTestSuite) TestConfirmIntent_Lapsed() {
+	intent := testPaymentIntent(func(i *models.PaymentIntent) { i.ExpiresAt = time.Now().Add(-time.Minute) })
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+	suite.intentRepo.On("Transition", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.Status == models.PaymentIntentStatusExpired
+	}), models.PaymentIntentStatusRequiresConfirmation).Return(true, nil)
+
+	_, err := suite.intentService.ConfirmIntent(suite.ctx, "user-1", "intent-1", services.ConfirmPaymentIntentRequest{
+		ExternalReference: "tok_visa",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "INVALID_TRANSITION")
+	suite.paymentService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+}
+
+// Test ConfirmIntent - An intent confirmed concurrently is not charged twice
+func (suite *PaymentIntentServiceTestSuite) TestConfirmIntent_ClaimedConcurrently() {
+	intent := testPaymentIntent()
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+	suite.intentRepo.On("Claim", suite.ctx, intent, mock.Anything).Return(false, nil)
+
+	_, err := suite.intentService.ConfirmIntent(suite.ctx, "user-1", "intent-1", services.ConfirmPaymentIntentRequest{
+		ExternalReference: "tok_visa",
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test CancelIntent - A processing intent cannot be cancelled
+func (suite *PaymentIntentServiceTestSuite) TestCancelIntent_Processing() {
+	intent := testPaymentIntent(func(i *models.PaymentIntent) { i.Status = models.PaymentIntentStatusProcessing })
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(intent, nil)
+
+	_, err := suite.intentService.CancelIntent(suite.ctx, "user-1", "intent-1")
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "INVALID_TRANSITION")
+}
+
+// Test CancelIntent - Other users' intents are not found
+func (suite *PaymentIntentServiceTestSuite) TestCancelIntent_OtherUsersIntent() {
+	suite.intentRepo.On("GetByID", suite.ctx, "intent-1").Return(testPaymentIntent(), nil)
+
+	_, err := suite.intentService.CancelIntent(suite.ctx, "user-2", "intent-1")
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test SweepIntents - Processing intents follow their settled payments and lapsed ones expire
+func (suite *PaymentIntentServiceTestSuite) TestSweepIntents() {
+	completedID, heldID := "payment-1", "payment-2"
+	completed := testPaymentIntent(func(i *models.PaymentIntent) {
+		i.Status = models.PaymentIntentStatusProcessing
+		i.PaymentID = &completedID
+	})
+	held := testPaymentIntent(func(i *models.PaymentIntent) {
+		i.ID = "intent-2"
+		i.Status = models.PaymentIntentStatusProcessing
+		i.PaymentID = &heldID
+	})
+	suite.intentRepo.On("ListProcessing", suite.ctx, 50).Return([]*models.PaymentIntent{completed, held}, nil)
+	suite.paymentRepo.On("GetByID", suite.ctx, completedID).Return(testutil.CreateTestPayment("order-1", func(p *models.Payment) {
+		p.ID = completedID
+		p.Status = models.PaymentStatusCompleted
+	}), nil)
+	suite.paymentRepo.On("GetByID", suite.ctx, heldID).Return(testutil.CreateTestPayment("order-1", func(p *models.Payment) {
+		p.ID = heldID
+		p.Status = models.PaymentStatusHeld
+	}), nil)
+	suite.intentRepo.On("Transition", suite.ctx, mock.MatchedBy(func(intent *models.PaymentIntent) bool {
+		return intent.ID == "intent-1" && intent.Status == models.PaymentIntentStatusSucceeded
+	}), models.PaymentIntentStatusProcessing).Return(true, nil)
+	suite.intentRepo.On("ExpireLapsed", suite.ctx, mock.Anything).Return(2, nil)
+
+	swept, err := suite.intentService.SweepIntents(suite.ctx)
+
+	suite.Require().NoError(err)
+	suite.Equal(3, swept)
+	suite.Equal(models.PaymentIntentStatusSucceeded, completed.Status)
+	suite.Equal(models.PaymentIntentStatusProcessing, held.Status)
+}
+
+// TestPaymentIntentServiceTestSuite runs the test suite
+func TestPaymentIntentServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentIntentServiceTestSuite))
+}
@@ -0,0 +1,365 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// statusGateway answers payment status lookups from a fixed table of statuses by
+// gateway transaction ID
+type statusGateway struct {
+	statuses map[string]*payments.GatewayPaymentStatus
+}
+
+func (g *statusGateway) ProcessPayment(ctx context.Context, req *payments.GatewayPaymentRequest) (*payments.GatewayPaymentResponse, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (g *statusGateway) RefundPayment(ctx context.Context, req *payments.GatewayRefundRequest) (*payments.GatewayRefundResponse, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (g *statusGateway) GetPaymentStatus(ctx context.Context, gatewayTransactionID string) (*payments.GatewayPaymentStatus, error) {
+	status, ok := g.statuses[gatewayTransactionID]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s not found", gatewayTransactionID)
+	}
+	return status, nil
+}
+
+func (g *statusGateway) GetGatewayType() payments.PaymentGatewayType {
+	return payments.GatewayTypeStripe
+}
+
+func (g *statusGateway) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+// sentNotifications records the notifications sent through it
+type sentNotifications struct {
+	services.NotificationService
+	sent []services.SendNotificationRequest
+}
+
+func (n *sentNotifications) SendNotification(ctx context.Context, req services.SendNotificationRequest) error {
+	n.sent = append(n.sent, req)
+	return nil
+}
+
+// PaymentReconciliationServiceTestSuite defines the test suite for PaymentReconciliationService
+type PaymentReconciliationServiceTestSuite struct {
+	suite.Suite
+	paymentRepo        *mocks.MockPaymentRepository
+	orderRepo          *mocks.MockOrderRepository
+	reconciliationRepo *mocks.MockPaymentReconciliationRepository
+	userRepo           *mocks.MockUserRepository
+	notifications      *sentNotifications
+	gateway            *statusGateway
+	service            services.PaymentReconciliationService
+	ctx                context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentReconciliationServiceTestSuite) SetupTest() {
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.reconciliationRepo = new(mocks.MockPaymentReconciliationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.notifications = &sentNotifications{}
+	suite.gateway = &statusGateway{statuses: make(map[string]*payments.GatewayPaymentStatus)}
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	gateways := payments.NewPaymentGatewayManager(log)
+	gateways.RegisterGateway(suite.gateway)
+
+	suite.service = services.NewPaymentReconciliationService(
+		services.PaymentReconciliationSettings{
+			Threshold:     30 * time.Minute,
+			Lookback:      72 * time.Hour,
+			PollThreshold: 2 * time.Minute,
+			EscalateAfter: time.Hour,
+		},
+		suite.paymentRepo,
+		suite.orderRepo,
+		suite.reconciliationRepo,
+		gateways,
+		nil, // No ledger
+		nil, // No webhooks
+		nil, // No reports
+		nil, // No gateway sends webhooks, so all are polled
+		suite.userRepo,
+		suite.notifications,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PaymentReconciliationServiceTestSuite) TearDownTest() {
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.reconciliationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// gatewayPayment returns a stripe payment of 50.00 for order-1 in the status
+func gatewayPayment(id string, status models.PaymentStatus) *models.Payment {
+	return &models.Payment{
+		ID:           id,
+		OrderID:      "order-1",
+		Amount:       50.00,
+		Status:       status,
+		Gateway:      string(payments.GatewayTypeStripe),
+		GatewayTxnID: "pi_" + id,
+	}
+}
+
+// expectRun expects the payments to be listed for reconciliation and captures the
+// records of the run
+func (suite *PaymentReconciliationServiceTestSuite) expectRun(due ...*models.Payment) *[]*models.PaymentReconciliation {
+	suite.paymentRepo.On("ListForReconciliation", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).Return(due, nil)
+
+	var records []*models.PaymentReconciliation
+	suite.reconciliationRepo.On("CreateBatch", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		records = args.Get(1).([]*models.PaymentReconciliation)
+	}).Return(nil)
+	return &records
+}
+
+// Test Reconcile - A processed payment the gateway completed is completed and its order paid
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_CompletesSettledPayment() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusProcessed)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "completed", Amount: 50.00}
+	records := suite.expectRun(payment)
+
+	order := &models.Order{ID: "order-1", Status: models.OrderStatusConfirmed, TotalAmount: 50.00}
+	suite.paymentRepo.On("Reconcile", suite.ctx, payment, models.PaymentStatusProcessed).Return(true, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("RefreshPaidAmount", suite.ctx, "order-1").Return(50.00, nil)
+	suite.orderRepo.On("UpdateStatus", suite.ctx, "order-1", models.OrderStatusPaid).Return(nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Checked)
+	suite.Equal(1, run.Corrected)
+	suite.Equal(models.PaymentStatusCompleted, payment.Status)
+	suite.NotNil(payment.ReconciledAt)
+	suite.Equal(models.OrderStatusPaid, order.Status)
+	suite.Require().Len(*records, 1)
+	suite.Equal(models.ReconciliationOutcomeCorrected, (*records)[0].Outcome)
+	suite.Equal(models.PaymentStatusCompleted, (*records)[0].ResolvedStatus)
+	suite.Equal(run.RunID, (*records)[0].RunID)
+}
+
+// Test Reconcile - A pending payment the gateway declined is failed
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_FailsDeclinedPayment() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusPending)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "failed", FailureMessage: "card declined"}
+	records := suite.expectRun(payment)
+
+	suite.paymentRepo.On("Reconcile", suite.ctx, payment, models.PaymentStatusPending).Return(true, nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Corrected)
+	suite.Equal(models.PaymentStatusFailed, payment.Status)
+	suite.Contains(payment.FailureReason, "card declined")
+	suite.Equal(models.PaymentStatusFailed, (*records)[0].ResolvedStatus)
+}
+
+// Test Reconcile - A payment that failed locally but was captured is flagged, not changed
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_FlagsCapturedFailedPayment() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusFailed)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "completed", Amount: 50.00}
+	records := suite.expectRun(payment)
+
+	suite.paymentRepo.On("MarkReconciled", suite.ctx, "payment-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Mismatched)
+	suite.Equal(models.PaymentStatusFailed, payment.Status)
+	suite.Equal(models.ReconciliationOutcomeMismatch, (*records)[0].Outcome)
+	suite.Equal("completed", (*records)[0].GatewayStatus)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "Reconcile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test Reconcile - A captured amount that differs from the payment is flagged, not completed
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_FlagsAmountMismatch() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusProcessed)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "completed", Amount: 45.00}
+	records := suite.expectRun(payment)
+
+	suite.paymentRepo.On("MarkReconciled", suite.ctx, "payment-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Mismatched)
+	suite.Equal(models.PaymentStatusProcessed, payment.Status)
+	suite.Contains((*records)[0].Detail, "45.00")
+}
+
+// Test Reconcile - Payments still processing and failed lookups are reported, the
+// latter left to the next run
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_PendingAndErrors() {
+	processing := gatewayPayment("payment-1", models.PaymentStatusProcessed)
+	unknown := gatewayPayment("payment-2", models.PaymentStatusPending)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "processing", Amount: 50.00}
+	records := suite.expectRun(processing, unknown)
+
+	suite.paymentRepo.On("MarkReconciled", suite.ctx, "payment-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(2, run.Checked)
+	suite.Equal(1, run.Pending)
+	suite.Equal(1, run.Errors)
+	suite.Len(*records, 2)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "MarkReconciled", mock.Anything, "payment-2", mock.Anything)
+}
+
+// Test Reconcile - A payment that moved on while it was checked is left to the next run
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_SkipsPaymentThatMovedOn() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusProcessed)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "completed", Amount: 50.00}
+	records := suite.expectRun(payment)
+
+	suite.paymentRepo.On("Reconcile", suite.ctx, payment, models.PaymentStatusProcessed).Return(false, nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(0, run.Checked)
+	suite.Empty(*records)
+}
+
+// Test Reconcile - Nothing is recorded when no payment is due
+func (suite *PaymentReconciliationServiceTestSuite) TestReconcile_NothingDue() {
+	suite.paymentRepo.On("ListForReconciliation", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).Return([]*models.Payment{}, nil)
+
+	// Execute
+	run, err := suite.service.Reconcile(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.NotEmpty(run.RunID)
+	suite.Equal(0, run.Checked)
+	suite.reconciliationRepo.AssertNotCalled(suite.T(), "CreateBatch", mock.Anything, mock.Anything)
+}
+
+// expectPoll expects the payments of the stripe gateway to be listed for polling and
+// captures the records of the poll
+func (suite *PaymentReconciliationServiceTestSuite) expectPoll(due ...*models.Payment) *[]*models.PaymentReconciliation {
+	suite.paymentRepo.On("ListForPolling", suite.ctx, []string{string(payments.GatewayTypeStripe)}, mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).Return(due, nil)
+
+	var records []*models.PaymentReconciliation
+	suite.reconciliationRepo.On("CreateBatch", suite.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		records = args.Get(1).([]*models.PaymentReconciliation)
+	}).Return(nil)
+	return &records
+}
+
+// Test Poll - A pending payment the gateway completed is settled without escalation
+func (suite *PaymentReconciliationServiceTestSuite) TestPoll_SettlesCompletedPayment() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusPending)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "succeeded", Amount: 50.00}
+	records := suite.expectPoll(payment)
+
+	order := &models.Order{ID: "order-1", Status: models.OrderStatusPending, TotalAmount: 50.00}
+	suite.paymentRepo.On("Reconcile", suite.ctx, payment, models.PaymentStatusPending).Return(true, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+	suite.orderRepo.On("RefreshPaidAmount", suite.ctx, "order-1").Return(50.00, nil)
+	suite.orderRepo.On("UpdateStatus", suite.ctx, "order-1", models.OrderStatusPaid).Return(nil)
+
+	// Execute
+	run, err := suite.service.Poll(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Corrected)
+	suite.Equal(0, run.Escalated)
+	suite.Equal(models.PaymentStatusCompleted, payment.Status)
+	suite.Require().Len(*records, 1)
+	suite.Empty(suite.notifications.sent)
+}
+
+// Test Poll - A payment still pending after the escalation threshold is escalated to every admin once
+func (suite *PaymentReconciliationServiceTestSuite) TestPoll_EscalatesStuckPayment() {
+	payment := gatewayPayment("payment-1", models.PaymentStatusProcessed)
+	payment.UpdatedAt = time.Now().Add(-2 * time.Hour)
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "processing"}
+	suite.expectPoll(payment)
+
+	suite.paymentRepo.On("MarkReconciled", suite.ctx, "payment-1", mock.AnythingOfType("time.Time")).Return(nil)
+	suite.userRepo.On("ListByRole", suite.ctx, models.UserRoleAdmin).Return([]*models.User{{ID: "admin-1"}, {ID: "admin-2"}}, nil)
+	suite.paymentRepo.On("MarkEscalated", suite.ctx, "payment-1", mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	run, err := suite.service.Poll(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(1, run.Pending)
+	suite.Equal(1, run.Escalated)
+	suite.Require().Len(suite.notifications.sent, 2)
+	suite.Equal("admin-1", suite.notifications.sent[0].UserID)
+	suite.Equal(string(models.NotificationTypeSystem), suite.notifications.sent[0].Type)
+	suite.Contains(suite.notifications.sent[0].Data, "payment-1")
+}
+
+// Test Poll - Payments pending for less than the escalation threshold, or already escalated, are not escalated
+func (suite *PaymentReconciliationServiceTestSuite) TestPoll_DoesNotEscalateTwiceOrEarly() {
+	recent := gatewayPayment("payment-1", models.PaymentStatusPending)
+	recent.UpdatedAt = time.Now().Add(-10 * time.Minute)
+	escalatedAt := time.Now().Add(-time.Hour)
+	escalated := gatewayPayment("payment-2", models.PaymentStatusPending)
+	escalated.EscalatedAt = &escalatedAt
+	suite.gateway.statuses["pi_payment-1"] = &payments.GatewayPaymentStatus{Status: "pending"}
+	suite.gateway.statuses["pi_payment-2"] = &payments.GatewayPaymentStatus{Status: "pending"}
+	suite.expectPoll(recent, escalated)
+
+	suite.paymentRepo.On("MarkReconciled", suite.ctx, mock.Anything, mock.AnythingOfType("time.Time")).Return(nil)
+
+	// Execute
+	run, err := suite.service.Poll(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(2, run.Pending)
+	suite.Equal(0, run.Escalated)
+	suite.Empty(suite.notifications.sent)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "MarkEscalated", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestPaymentReconciliationServiceTestSuite runs the test suite
+func TestPaymentReconciliationServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentReconciliationServiceTestSuite))
+}
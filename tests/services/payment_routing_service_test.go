@@ -0,0 +1,179 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// PaymentRoutingServiceTestSuite defines the test suite for PaymentRoutingService
+type PaymentRoutingServiceTestSuite struct {
+	suite.Suite
+	ruleRepo *mocks.MockPaymentRoutingRuleRepository
+	gateways *payments.PaymentGatewayManager
+	service  services.PaymentRoutingService
+	ctx      context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PaymentRoutingServiceTestSuite) SetupTest() {
+	suite.ruleRepo = new(mocks.MockPaymentRoutingRuleRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.gateways = payments.NewPaymentGatewayManager(log)
+	suite.gateways.RegisterGateway(&statusGateway{}) // Stripe
+
+	suite.service = services.NewPaymentRoutingService(suite.ruleRepo, suite.gateways, log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PaymentRoutingServiceTestSuite) TearDownTest() {
+	suite.ruleRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateRule - A valid rule is saved with its currencies upper-cased and loaded
+// into the gateway manager
+func (suite *PaymentRoutingServiceTestSuite) TestCreateRule_SavesAndLoadsRules() {
+	minAmount := 5000.00
+	req := services.PaymentRoutingRuleRequest{
+		Name:       "large-euro",
+		Priority:   10,
+		MinAmount:  &minAmount,
+		Currencies: []string{"eur"},
+		Action:     "route",
+		Gateway:    "stripe",
+	}
+	saved := &models.PaymentRoutingRule{ID: "rule-1", Name: "large-euro", Priority: 10, MinAmount: &minAmount,
+		Currencies: models.StringList{"EUR"}, Action: models.PaymentRoutingActionRoute, Gateway: "stripe", IsActive: true}
+
+	suite.ruleRepo.On("GetByName", suite.ctx, "large-euro").Return(nil, nil)
+	suite.ruleRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.PaymentRoutingRule")).Return(nil)
+	suite.ruleRepo.On("List", suite.ctx, true).Return([]*models.PaymentRoutingRule{saved}, nil)
+
+	// Execute
+	rule, err := suite.service.CreateRule(suite.ctx, req)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(models.StringList{"EUR"}, rule.Currencies)
+	suite.True(rule.IsActive)
+	loaded := suite.gateways.RoutingRules()
+	suite.Require().Len(loaded, 1)
+	suite.Equal(payments.GatewayTypeStripe, loaded[0].Gateway)
+}
+
+// Test CreateRule - Invalid rules are rejected before anything is saved
+func (suite *PaymentRoutingServiceTestSuite) TestCreateRule_RejectsInvalidRules() {
+	minAmount, maxAmount := 100.00, 50.00
+	tests := []struct {
+		name string
+		req  services.PaymentRoutingRuleRequest
+		msg  string
+	}{
+		{"unregistered gateway", services.PaymentRoutingRuleRequest{Name: "r", Action: "route", Gateway: "adyen"}, "not registered"},
+		{"route without gateway", services.PaymentRoutingRuleRequest{Name: "r", Action: "route"}, "must name a gateway"},
+		{"inverted bounds", services.PaymentRoutingRuleRequest{Name: "r", Action: "route", Gateway: "stripe", MinAmount: &minAmount, MaxAmount: &maxAmount}, "min_amount"},
+		{"bad currency", services.PaymentRoutingRuleRequest{Name: "r", Action: "route", Gateway: "stripe", Currencies: []string{"E1R"}}, "currency code"},
+		{"bad method", services.PaymentRoutingRuleRequest{Name: "r", Action: "route", Gateway: "stripe", PaymentMethods: []string{"crypto"}}, "unsupported payment method"},
+		{"bypass with gateway", services.PaymentRoutingRuleRequest{Name: "r", Action: "bypass", Gateway: "stripe", PaymentMethods: []string{"cash"}}, "must not name a gateway"},
+		{"bypass card payments", services.PaymentRoutingRuleRequest{Name: "r", Action: "bypass", PaymentMethods: []string{"cash", "credit_card"}}, "only offline"},
+		{"bypass everything", services.PaymentRoutingRuleRequest{Name: "r", Action: "bypass"}, "offline payment methods"},
+	}
+
+	for _, tt := range tests {
+		// Execute
+		_, err := suite.service.CreateRule(suite.ctx, tt.req)
+
+		// Assert
+		suite.Require().Error(err, tt.name)
+		suite.Contains(err.Error(), "VALIDATION_ERROR", tt.name)
+		suite.Contains(err.Error(), tt.msg, tt.name)
+	}
+	suite.ruleRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test CreateRule - A taken name is a conflict
+func (suite *PaymentRoutingServiceTestSuite) TestCreateRule_NameTaken() {
+	suite.ruleRepo.On("GetByName", suite.ctx, "transfers").Return(&models.PaymentRoutingRule{ID: "rule-1"}, nil)
+
+	// Execute
+	_, err := suite.service.CreateRule(suite.ctx, services.PaymentRoutingRuleRequest{
+		Name: "transfers", Action: "bypass", PaymentMethods: []string{"bank_transfer"},
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test DeleteRule - Deleting a rule takes it out of the gateway manager
+func (suite *PaymentRoutingServiceTestSuite) TestDeleteRule_ReloadsRules() {
+	suite.gateways.SetRoutingRules([]payments.RoutingRule{{ID: "rule-1", Name: "euro", Action: payments.RoutingActionRoute, Gateway: payments.GatewayTypeStripe}})
+	suite.ruleRepo.On("GetByID", suite.ctx, "rule-1").Return(&models.PaymentRoutingRule{ID: "rule-1"}, nil)
+	suite.ruleRepo.On("Delete", suite.ctx, "rule-1").Return(nil)
+	suite.ruleRepo.On("List", suite.ctx, true).Return([]*models.PaymentRoutingRule{}, nil)
+
+	// Execute
+	err := suite.service.DeleteRule(suite.ctx, "rule-1")
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(suite.gateways.RoutingRules())
+}
+
+// Test EvaluateRules - The active rules route a matching payment, showing each check
+func (suite *PaymentRoutingServiceTestSuite) TestEvaluateRules_ActiveRules() {
+	maxAmount := 100.00
+	suite.ruleRepo.On("List", suite.ctx, true).Return([]*models.PaymentRoutingRule{
+		{ID: "rule-1", Name: "transfers", Priority: 20, PaymentMethods: models.StringList{"bank_transfer"}, Action: models.PaymentRoutingActionBypass},
+		{ID: "rule-2", Name: "small", Priority: 10, MaxAmount: &maxAmount, Action: models.PaymentRoutingActionRoute, Gateway: "stripe"},
+	}, nil)
+
+	// Execute
+	evaluation, err := suite.service.EvaluateRules(suite.ctx, services.EvaluatePaymentRoutingRequest{
+		Amount: 40, Currency: "usd", PaymentMethod: "credit_card",
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal("route", evaluation.Decision)
+	suite.Equal("small", evaluation.MatchedRule)
+	suite.Equal("stripe", evaluation.Gateway)
+	suite.Require().Len(evaluation.Checks, 2)
+	suite.False(evaluation.Checks[0].Matched)
+	suite.Contains(evaluation.Checks[0].Reason, "credit_card")
+	suite.True(evaluation.Checks[1].Matched)
+}
+
+// Test EvaluateRules - Rules of the request are evaluated in place of the active ones,
+// without being saved
+func (suite *PaymentRoutingServiceTestSuite) TestEvaluateRules_CandidateRules() {
+	// Execute
+	evaluation, err := suite.service.EvaluateRules(suite.ctx, services.EvaluatePaymentRoutingRequest{
+		Amount: 250, Currency: "EUR", PaymentMethod: "bank_transfer",
+		Rules: []services.PaymentRoutingRuleRequest{
+			{Name: "transfers", Action: "bypass", PaymentMethods: []string{"bank_transfer"}},
+		},
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal("bypass", evaluation.Decision)
+	suite.Equal("transfers", evaluation.MatchedRule)
+	suite.Empty(evaluation.Gateway)
+	suite.ruleRepo.AssertNotCalled(suite.T(), "List", mock.Anything, mock.Anything)
+}
+
+// TestPaymentRoutingServiceTestSuite runs the test suite
+func TestPaymentRoutingServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentRoutingServiceTestSuite))
+}
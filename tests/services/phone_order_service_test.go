@@ -0,0 +1,327 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	apperrors "easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// PhoneOrderServiceTestSuite defines the test suite for PhoneOrderService
+type PhoneOrderServiceTestSuite struct {
+	suite.Suite
+	phoneOrderService services.PhoneOrderService
+	userRepo          *mocks.MockUserRepository
+	orderRepo         *mocks.MockOrderRepository
+	paymentMethodRepo *mocks.MockSavedPaymentMethodRepository
+	productService    *mocks.MockProductService
+	cartHolds         *mocks.MockCartHoldService
+	orderService      *mocks.MockOrderService
+	adminOrderService *mocks.MockAdminOrderService
+	paymentService    *mocks.MockPaymentService
+	logger            *logger.Logger
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PhoneOrderServiceTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.paymentMethodRepo = new(mocks.MockSavedPaymentMethodRepository)
+	suite.productService = new(mocks.MockProductService)
+	suite.cartHolds = new(mocks.MockCartHoldService)
+	suite.orderService = new(mocks.MockOrderService)
+	suite.adminOrderService = new(mocks.MockAdminOrderService)
+	suite.paymentService = new(mocks.MockPaymentService)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.phoneOrderService = services.NewPhoneOrderService(
+		suite.userRepo,
+		suite.orderRepo,
+		suite.paymentMethodRepo,
+		suite.productService,
+		nil, // Customers order at list prices
+		suite.cartHolds,
+		suite.orderService,
+		suite.adminOrderService,
+		suite.paymentService,
+		services.PriceOverrideSettings{MaxDiscountPercent: 15},
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PhoneOrderServiceTestSuite) TearDownTest() {
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.paymentMethodRepo.AssertExpectations(suite.T())
+	suite.productService.AssertExpectations(suite.T())
+	suite.cartHolds.AssertExpectations(suite.T())
+	suite.orderService.AssertExpectations(suite.T())
+	suite.adminOrderService.AssertExpectations(suite.T())
+	suite.paymentService.AssertExpectations(suite.T())
+}
+
+// expectCustomer sets up the active customer the agent orders for
+func (suite *PhoneOrderServiceTestSuite) expectCustomer() *models.User {
+	customer := &models.User{ID: "customer-1", Email: "jane@example.com", Role: models.UserRoleCustomer, IsActive: true}
+	suite.userRepo.On("GetByID", suite.ctx, "customer-1").Return(customer, nil)
+	return customer
+}
+
+// Test SearchProducts - Products are listed at the customer's price with the override floor
+func (suite *PhoneOrderServiceTestSuite) TestSearchProducts_CustomerPricesAndOverrideFloor() {
+	suite.expectCustomer()
+	suite.productService.On("ListProducts", suite.ctx, services.ListProductsRequest{
+		Page: 1, Limit: 20, ActiveOnly: true, Query: "mug",
+	}).Return(&services.ListProductsResponse{Products: []*services.ProductResponse{
+		{ID: "product-1", Name: "Mug", SKU: "MUG-1", Price: 19.99, Stock: 12},
+	}}, nil)
+
+	response, err := suite.phoneOrderService.SearchProducts(suite.ctx, "customer-1", services.PhoneOrderProductSearchRequest{Query: "mug"})
+
+	suite.NoError(err)
+	suite.Len(response.Products, 1)
+	product := response.Products[0]
+	suite.Equal(19.99, product.ListPrice)
+	suite.Equal(19.99, product.CustomerPrice)
+	suite.Equal(17.0, product.MinOverridePrice) // 15% off, rounded up to the cent
+	suite.Equal(12, product.Stock)
+}
+
+// Test SearchProducts - Unknown customers are rejected before searching
+func (suite *PhoneOrderServiceTestSuite) TestSearchProducts_CustomerNotFound() {
+	suite.userRepo.On("GetByID", suite.ctx, "customer-2").Return(nil, nil)
+
+	response, err := suite.phoneOrderService.SearchProducts(suite.ctx, "customer-2", services.PhoneOrderProductSearchRequest{Query: "mug"})
+
+	suite.Nil(response)
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test HoldCartItem - Agents build the customer's own cart
+func (suite *PhoneOrderServiceTestSuite) TestHoldCartItem_HoldsForCustomer() {
+	suite.expectCustomer()
+	req := services.HoldCartItemRequest{ProductID: "product-1", Quantity: 2}
+	suite.cartHolds.On("HoldItem", suite.ctx, "customer-1", req).
+		Return(&services.CartHoldResponse{ID: "hold-1", ProductID: "product-1", Quantity: 2}, nil)
+
+	hold, err := suite.phoneOrderService.HoldCartItem(suite.ctx, "customer-1", req)
+
+	suite.NoError(err)
+	suite.Equal("hold-1", hold.ID)
+}
+
+// Test PlaceOrder - The cart is ordered, attributed to the agent and charged to the card on file
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_CardOnFileFromCart() {
+	suite.expectCustomer()
+	suite.cartHolds.On("GetCartHolds", suite.ctx, "customer-1").Return([]*services.CartHoldResponse{
+		{ID: "hold-1", ProductID: "product-1", Quantity: 2},
+	}, nil)
+	method := &models.SavedPaymentMethod{
+		ID: "method-1", UserID: "customer-1", Method: models.PaymentMethodCreditCard, Fingerprint: "fp-1", IsDefault: true,
+	}
+	suite.paymentMethodRepo.On("GetDefault", suite.ctx, "customer-1").Return(method, nil)
+	suite.orderService.On("CreateOrder", suite.ctx, services.CreateOrderRequest{
+		UserID:        "customer-1",
+		Items:         []services.OrderItem{{ProductID: "product-1", Quantity: 2}},
+		PaymentMethod: models.PaymentMethodCreditCard,
+		Channel:       models.OrderChannelPhone,
+		PlacedBy:      "agent-1",
+	}).Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 40}, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, services.ProcessPaymentRequest{
+		OrderID:           "order-1",
+		Amount:            40,
+		PaymentType:       "credit_card",
+		ExternalReference: "saved_payment_method:method-1",
+		CardFingerprint:   "fp-1",
+	}).Return(&services.PaymentResponse{ID: "payment-1", Status: models.PaymentStatusCompleted, Amount: 40}, nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		PaymentType: services.PhoneOrderPaymentCardOnFile,
+	})
+
+	suite.NoError(err)
+	suite.Equal(models.OrderStatusPaid, response.Order.Status)
+	suite.Equal("payment-1", response.Payment.ID)
+	suite.Empty(response.PaymentError)
+}
+
+// Test PlaceOrder - A declined card on file leaves the order pending with the reason
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_CardDeclinedLeavesOrderPending() {
+	suite.expectCustomer()
+	method := &models.SavedPaymentMethod{ID: "method-2", UserID: "customer-1", Method: models.PaymentMethodDebitCard}
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-2").Return(method, nil)
+	suite.orderService.On("CreateOrder", suite.ctx, mock.AnythingOfType("services.CreateOrderRequest")).
+		Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 25}, nil)
+	suite.paymentService.On("ProcessPayment", suite.ctx, mock.AnythingOfType("services.ProcessPaymentRequest")).
+		Return(nil, apperrors.NewPaymentFailedError("card declined"))
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		Items:           []services.PhoneOrderItem{{ProductID: "product-1", Quantity: 1}},
+		PaymentType:     services.PhoneOrderPaymentCardOnFile,
+		PaymentMethodID: "method-2",
+	})
+
+	suite.NoError(err)
+	suite.Equal(models.OrderStatusPending, response.Order.Status)
+	suite.Nil(response.Payment)
+	suite.Contains(response.PaymentError, "card declined")
+}
+
+// Test PlaceOrder - Another customer's card is never charged
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_CardOfAnotherCustomer() {
+	suite.expectCustomer()
+	method := &models.SavedPaymentMethod{ID: "method-3", UserID: "customer-9", Method: models.PaymentMethodCreditCard}
+	suite.paymentMethodRepo.On("GetByID", suite.ctx, "method-3").Return(method, nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		Items:           []services.PhoneOrderItem{{ProductID: "product-1", Quantity: 1}},
+		PaymentType:     services.PhoneOrderPaymentCardOnFile,
+		PaymentMethodID: "method-3",
+	})
+
+	suite.Nil(response)
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+	suite.orderService.AssertNotCalled(suite.T(), "CreateOrder", mock.Anything, mock.Anything)
+}
+
+// Test PlaceOrder - Expired cards on file are refused before the order is placed
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_ExpiredCardOnFile() {
+	suite.expectCustomer()
+	expired := time.Now().Add(-24 * time.Hour)
+	method := &models.SavedPaymentMethod{ID: "method-1", UserID: "customer-1", Method: models.PaymentMethodCreditCard, ExpiresAt: &expired}
+	suite.paymentMethodRepo.On("GetDefault", suite.ctx, "customer-1").Return(method, nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		Items:       []services.PhoneOrderItem{{ProductID: "product-1", Quantity: 1}},
+		PaymentType: services.PhoneOrderPaymentCardOnFile,
+	})
+
+	suite.Nil(response)
+	suite.Error(err)
+	suite.Contains(err.Error(), "expired")
+}
+
+// Test PlaceOrder - Price overrides are applied as the agent, on an invoiced order
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_InvoiceWithPriceOverride() {
+	suite.expectCustomer()
+	override := &services.OverrideItemPriceRequest{UnitPrice: 9, ReasonCode: "price_match"}
+	suite.orderService.On("CreateOrder", suite.ctx, services.CreateOrderRequest{
+		UserID: "customer-1",
+		Items: []services.OrderItem{
+			{ProductID: "product-1", Quantity: 1},
+			{ProductID: "product-2", Quantity: 3},
+		},
+		OnAccount: true,
+		Channel:   models.OrderChannelPhone,
+		PlacedBy:  "agent-1",
+	}).Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 40}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(&models.Order{
+		ID: "order-1",
+		Items: []models.OrderItem{
+			{ID: "item-1", ProductID: "product-1", Quantity: 1, UnitPrice: 10},
+			{ID: "item-2", ProductID: "product-2", Quantity: 3, UnitPrice: 10},
+		},
+	}, nil)
+	suite.adminOrderService.On("OverrideItemPrice", suite.ctx, "order-1", "item-1", "agent-1", *override).
+		Return(&services.OrderItemPriceOverrideResponse{OrderID: "order-1", Total: 39}, nil)
+	suite.orderService.On("GetOrder", suite.ctx, "order-1").
+		Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 39}, nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		Items: []services.PhoneOrderItem{
+			{ProductID: "product-1", Quantity: 1, PriceOverride: override},
+			{ProductID: "product-2", Quantity: 3},
+		},
+		PaymentType: services.PhoneOrderPaymentInvoice,
+	})
+
+	suite.NoError(err)
+	suite.Equal(39.0, response.Order.Total)
+	suite.Nil(response.Payment)
+	suite.paymentService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+}
+
+// Test PlaceOrder - An override beyond the policy cancels the order it was placed for
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_OverrideBeyondPolicyCancelsOrder() {
+	suite.expectCustomer()
+	override := &services.OverrideItemPriceRequest{UnitPrice: 5, ReasonCode: "negotiated"}
+	suite.orderService.On("CreateOrder", suite.ctx, mock.AnythingOfType("services.CreateOrderRequest")).
+		Return(&services.OrderResponse{ID: "order-1", Status: models.OrderStatusPending, Total: 10}, nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(&models.Order{
+		ID:    "order-1",
+		Items: []models.OrderItem{{ID: "item-1", ProductID: "product-1", Quantity: 1, UnitPrice: 10}},
+	}, nil)
+	suite.adminOrderService.On("OverrideItemPrice", suite.ctx, "order-1", "item-1", "agent-1", *override).
+		Return(nil, apperrors.NewForbiddenError("discount of 50.00% exceeds the maximum of 15.00%"))
+	suite.orderService.On("CancelOrder", suite.ctx, "order-1").Return(nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		Items:         []services.PhoneOrderItem{{ProductID: "product-1", Quantity: 1, PriceOverride: override}},
+		PaymentType:   services.PhoneOrderPaymentOffline,
+		OfflineMethod: models.PaymentMethodBankTransfer,
+	})
+
+	suite.Nil(response)
+	suite.Error(err)
+	suite.Contains(err.Error(), "FORBIDDEN")
+}
+
+// Test PlaceOrder - Nothing is placed for an empty cart
+func (suite *PhoneOrderServiceTestSuite) TestPlaceOrder_EmptyCart() {
+	suite.expectCustomer()
+	suite.cartHolds.On("GetCartHolds", suite.ctx, "customer-1").Return([]*services.CartHoldResponse{}, nil)
+
+	response, err := suite.phoneOrderService.PlaceOrder(suite.ctx, "customer-1", "agent-1", services.PlacePhoneOrderRequest{
+		PaymentType:   services.PhoneOrderPaymentOffline,
+		OfflineMethod: models.PaymentMethodCash,
+	})
+
+	suite.Nil(response)
+	suite.Error(err)
+	suite.Contains(err.Error(), "cart is empty")
+}
+
+// Test GenerateAgentSalesReport - Phone order sales are summed per agent, net of refunds
+func (suite *PhoneOrderServiceTestSuite) TestGenerateAgentSalesReport_SumsAgents() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC)
+	suite.orderRepo.On("AggregateSalesByAgent", suite.ctx, start, end).Return([]repository.AgentSales{
+		{AgentID: "agent-1", Orders: 3, Revenue: 300.5, RefundedAmount: 20.25},
+		{AgentID: "agent-2", Orders: 1, Revenue: 50},
+	}, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "agent-1").Return(&models.User{ID: "agent-1", Email: "agent1@example.com"}, nil)
+	suite.userRepo.On("GetByID", suite.ctx, "agent-2").Return(nil, nil)
+
+	report, err := suite.phoneOrderService.GenerateAgentSalesReport(suite.ctx, services.AgentSalesReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-07",
+	})
+
+	suite.NoError(err)
+	suite.Len(report.Agents, 2)
+	suite.Equal("agent1@example.com", report.Agents[0].AgentEmail)
+	suite.Equal(280.25, report.Agents[0].NetRevenue)
+	suite.Empty(report.Agents[1].AgentEmail)
+	suite.Equal(4, report.Totals.Orders)
+	suite.Equal(350.5, report.Totals.Revenue)
+	suite.Equal(330.25, report.Totals.NetRevenue)
+}
+
+// TestPhoneOrderServiceTestSuite runs the test suite
+func TestPhoneOrderServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PhoneOrderServiceTestSuite))
+}
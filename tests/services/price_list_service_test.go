@@ -0,0 +1,272 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+	"easy-orders-backend/tests/testutil"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// PriceListServiceTestSuite defines the test suite for PriceListService and the
+// price list resolver
+type PriceListServiceTestSuite struct {
+	suite.Suite
+	priceListService services.PriceListService
+	resolver         *services.PriceListResolver
+	priceListRepo    *mocks.MockPriceListRepository
+	productRepo      *mocks.MockProductRepository
+	organizationRepo *mocks.MockOrganizationRepository
+	userRepo         *mocks.MockUserRepository
+	logger           *logger.Logger
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PriceListServiceTestSuite) SetupTest() {
+	suite.priceListRepo = new(mocks.MockPriceListRepository)
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.organizationRepo = new(mocks.MockOrganizationRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.priceListService = services.NewPriceListService(
+		suite.priceListRepo,
+		suite.productRepo,
+		suite.organizationRepo,
+		suite.userRepo,
+		suite.logger,
+	)
+	suite.resolver = services.NewPriceListResolver(suite.priceListRepo, suite.organizationRepo, suite.logger)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PriceListServiceTestSuite) TearDownTest() {
+	suite.priceListRepo.AssertExpectations(suite.T())
+	suite.productRepo.AssertExpectations(suite.T())
+	suite.organizationRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+// Test CreatePriceList - Names are unique
+func (suite *PriceListServiceTestSuite) TestCreatePriceList_DuplicateName() {
+	existing := &models.PriceList{ID: "list-1", Name: "Wholesale"}
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByName", suite.ctx, "Wholesale").Return(existing, nil)
+
+	// Execute
+	response, err := suite.priceListService.CreatePriceList(suite.ctx, services.CreatePriceListRequest{
+		Name: " Wholesale ",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "CONFLICT")
+	suite.priceListRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test ImportItems - Rows are imported with their effective ranges
+func (suite *PriceListServiceTestSuite) TestImportItems_Success() {
+	priceList := &models.PriceList{ID: "list-1", Name: "Wholesale", IsActive: true}
+	products := []*models.Product{
+		{ID: "product-1", SKU: "SKU-1"},
+		{ID: "product-2", SKU: "SKU-2"},
+	}
+	csv := "sku,price,effective_from,effective_to\n" +
+		"SKU-1,8.50,,\n" +
+		"SKU-1,7.25,2026-01-01,2026-02-01T12:00:00Z\n" +
+		"SKU-2,19.99\n"
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByID", suite.ctx, "list-1").Return(priceList, nil)
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-1", "SKU-1", "SKU-2"}).Return(products, nil)
+	suite.priceListRepo.On("ImportItems", suite.ctx, "list-1", mock.MatchedBy(func(items []*models.PriceListItem) bool {
+		if len(items) != 3 {
+			return false
+		}
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+		return items[0].ProductID == "product-1" && items[0].Price == 8.50 &&
+			items[0].EffectiveFrom == nil && items[0].EffectiveTo == nil &&
+			items[1].Price == 7.25 && items[1].EffectiveFrom.Equal(from) && items[1].EffectiveTo.Equal(to) &&
+			items[2].ProductID == "product-2" && items[2].Price == 19.99
+	}), true).Return(nil)
+
+	// Execute
+	response, err := suite.priceListService.ImportItems(suite.ctx, "list-1", strings.NewReader(csv), true)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(3, response.TotalRows)
+	suite.Equal(3, response.Imported)
+	suite.Equal(0, response.Failed)
+	suite.True(response.Replaced)
+	for _, row := range response.Rows {
+		suite.Equal(services.PriceListImportStatusImported, row.Status)
+	}
+}
+
+// Test ImportItems - A failed row blocks the whole import
+func (suite *PriceListServiceTestSuite) TestImportItems_FailedRowsBlockImport() {
+	priceList := &models.PriceList{ID: "list-1", Name: "Wholesale", IsActive: true}
+	products := []*models.Product{{ID: "product-1", SKU: "SKU-1"}}
+	csv := "SKU-1,8.50\n" +
+		"SKU-404,3.00\n" +
+		"SKU-1,-1\n" +
+		"SKU-1,5.00,2026-03-01,2026-02-01\n" +
+		"SKU-1,6.00,,\n"
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByID", suite.ctx, "list-1").Return(priceList, nil)
+	suite.productRepo.On("GetBySKUs", suite.ctx, []string{"SKU-1", "SKU-404"}).Return(products, nil)
+
+	// Execute
+	response, err := suite.priceListService.ImportItems(suite.ctx, "list-1", strings.NewReader(csv), false)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(5, response.TotalRows)
+	suite.Equal(0, response.Imported)
+	suite.Equal(4, response.Failed)
+	suite.Equal(services.PriceListImportStatusValid, response.Rows[0].Status)
+	suite.Equal("product not found", response.Rows[1].Error)
+	suite.Equal("price must be a positive number", response.Rows[2].Error)
+	suite.Equal("effective_to must be after effective_from", response.Rows[3].Error)
+	suite.Equal("duplicate sku and effective_from in import", response.Rows[4].Error)
+	suite.priceListRepo.AssertNotCalled(suite.T(), "ImportItems", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test AssignToUser - Only existing price lists can be assigned
+func (suite *PriceListServiceTestSuite) TestAssignToUser_PriceListNotFound() {
+	user := testutil.CreateTestUser(func(u *models.User) { u.ID = "user-1" })
+	priceListID := "list-404"
+
+	// Mock expectations
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(user, nil)
+	suite.priceListRepo.On("GetByID", suite.ctx, priceListID).Return(nil, nil)
+
+	// Execute
+	err := suite.priceListService.AssignToUser(suite.ctx, "user-1", &priceListID)
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "NOT_FOUND")
+	suite.userRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
+// Test AssignToOrganization - An empty price list ID clears the assignment
+func (suite *PriceListServiceTestSuite) TestAssignToOrganization_Clears() {
+	priceListID := "list-1"
+	organization := &models.Organization{ID: "org-1", Name: "Acme", PriceListID: &priceListID}
+	empty := ""
+
+	// Mock expectations
+	suite.organizationRepo.On("GetByID", suite.ctx, "org-1").Return(organization, nil)
+	suite.organizationRepo.On("Update", suite.ctx, mock.MatchedBy(func(organization *models.Organization) bool {
+		return organization.PriceListID == nil
+	})).Return(nil)
+
+	// Execute
+	err := suite.priceListService.AssignToOrganization(suite.ctx, "org-1", &empty)
+
+	// Assert
+	suite.NoError(err)
+}
+
+// Test Resolve - The user's own list applies ahead of their organization's
+func (suite *PriceListServiceTestSuite) TestResolve_UserPriceList() {
+	userListID := "list-user"
+	organizationID := "org-1"
+	user := testutil.CreateTestUser(func(u *models.User) {
+		u.PriceListID = &userListID
+		u.OrganizationID = &organizationID
+	})
+	at := time.Now()
+	productIDs := []string{"product-1", "product-2"}
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByID", suite.ctx, userListID).Return(&models.PriceList{ID: userListID, IsActive: true}, nil)
+	suite.priceListRepo.On("FindEffectivePrices", suite.ctx, userListID, productIDs, at).
+		Return(map[string]float64{"product-1": 8.50}, nil)
+
+	// Execute
+	prices, err := suite.resolver.Resolve(suite.ctx, user, productIDs, at)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(map[string]float64{"product-1": 8.50}, prices)
+	suite.organizationRepo.AssertNotCalled(suite.T(), "GetByID", mock.Anything, mock.Anything)
+}
+
+// Test Resolve - An inactive user list falls back to the organization's list
+func (suite *PriceListServiceTestSuite) TestResolve_InactiveUserListFallsBackToOrganization() {
+	userListID := "list-user"
+	organizationListID := "list-org"
+	organizationID := "org-1"
+	user := testutil.CreateTestUser(func(u *models.User) {
+		u.PriceListID = &userListID
+		u.OrganizationID = &organizationID
+	})
+	organization := &models.Organization{ID: organizationID, PriceListID: &organizationListID}
+	at := time.Now()
+	productIDs := []string{"product-1"}
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByID", suite.ctx, userListID).Return(&models.PriceList{ID: userListID, IsActive: false}, nil)
+	suite.organizationRepo.On("GetByID", suite.ctx, organizationID).Return(organization, nil)
+	suite.priceListRepo.On("GetByID", suite.ctx, organizationListID).Return(&models.PriceList{ID: organizationListID, IsActive: true}, nil)
+	suite.priceListRepo.On("FindEffectivePrices", suite.ctx, organizationListID, productIDs, at).
+		Return(map[string]float64{"product-1": 9.00}, nil)
+
+	// Execute
+	prices, err := suite.resolver.Resolve(suite.ctx, user, productIDs, at)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(map[string]float64{"product-1": 9.00}, prices)
+}
+
+// Test Resolve - Customers without a price list order at list prices
+func (suite *PriceListServiceTestSuite) TestResolve_NoPriceList() {
+	user := testutil.CreateTestUser()
+
+	// Execute
+	prices, err := suite.resolver.Resolve(suite.ctx, user, []string{"product-1"}, time.Now())
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(prices)
+}
+
+// Test Resolve - Lookup errors are returned
+func (suite *PriceListServiceTestSuite) TestResolve_RepositoryError() {
+	userListID := "list-user"
+	user := testutil.CreateTestUser(func(u *models.User) { u.PriceListID = &userListID })
+
+	// Mock expectations
+	suite.priceListRepo.On("GetByID", suite.ctx, userListID).Return(nil, errors.New("database error"))
+
+	// Execute
+	prices, err := suite.resolver.Resolve(suite.ctx, user, []string{"product-1"}, time.Now())
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(prices)
+}
+
+// TestPriceListServiceTestSuite runs the test suite
+func TestPriceListServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PriceListServiceTestSuite))
+}
@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ProductSerialServiceTestSuite defines the test suite for ProductSerialService
+type ProductSerialServiceTestSuite struct {
+	suite.Suite
+	serialService services.ProductSerialService
+	serialRepo    *mocks.MockProductSerialRepository
+	productRepo   *mocks.MockProductRepository
+	orderRepo     *mocks.MockOrderRepository
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ProductSerialServiceTestSuite) SetupTest() {
+	suite.serialRepo = new(mocks.MockProductSerialRepository)
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.serialService = services.NewProductSerialService(
+		suite.serialRepo,
+		suite.productRepo,
+		suite.orderRepo,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ProductSerialServiceTestSuite) TearDownTest() {
+	suite.serialRepo.AssertExpectations(suite.T())
+	suite.productRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// serializedOrder returns a paid order with one item of two units of a serialized product
+func (suite *ProductSerialServiceTestSuite) serializedOrder() *models.Order {
+	return &models.Order{
+		ID:     "order-1",
+		Status: models.OrderStatusPaid,
+		Items: []models.OrderItem{{
+			ID:        "item-1",
+			OrderID:   "order-1",
+			ProductID: "product-1",
+			Quantity:  2,
+			UnitPrice: 100,
+			Product:   &models.Product{ID: "product-1", Serialized: true},
+		}},
+	}
+}
+
+// Test ReceiveSerials - New serial numbers are recorded in the lot, and those the product already has are duplicates
+func (suite *ProductSerialServiceTestSuite) TestReceiveSerials_ReportsDuplicates() {
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").
+		Return(&models.Product{ID: "product-1", Serialized: true}, nil)
+
+	var sent []*models.ProductSerial
+	suite.serialRepo.On("Receive", suite.ctx, mock.AnythingOfType("[]*models.ProductSerial")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).([]*models.ProductSerial) }).
+		Return([]*models.ProductSerial{{SerialNumber: "SN-2"}, {SerialNumber: "SN-3"}}, nil)
+
+	// Execute
+	response, err := suite.serialService.ReceiveSerials(suite.ctx, "product-1", services.ReceiveProductSerialsRequest{
+		LotNumber:     " LOT-7 ",
+		SerialNumbers: []string{"SN-1", " SN-2 ", "SN-3"},
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(sent, 3)
+	suite.Equal("SN-2", sent[1].SerialNumber)
+	suite.Equal("LOT-7", sent[1].LotNumber)
+	suite.False(sent[1].ReceivedAt.IsZero())
+	suite.Equal("LOT-7", response.LotNumber)
+	suite.Len(response.Received, 2)
+	suite.Equal([]string{"SN-1"}, response.Duplicates)
+}
+
+// Test ReceiveSerials - Serial numbers are only received for serialized products
+func (suite *ProductSerialServiceTestSuite) TestReceiveSerials_NotSerialized() {
+	suite.productRepo.On("GetByID", suite.ctx, "product-1").
+		Return(&models.Product{ID: "product-1"}, nil)
+
+	// Execute
+	response, err := suite.serialService.ReceiveSerials(suite.ctx, "product-1", services.ReceiveProductSerialsRequest{
+		LotNumber:     "LOT-7",
+		SerialNumbers: []string{"SN-1"},
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "not serialized")
+}
+
+// Test ReceiveSerials - A serial number repeated in the request is rejected
+func (suite *ProductSerialServiceTestSuite) TestReceiveSerials_RepeatedSerialNumber() {
+	// Execute
+	response, err := suite.serialService.ReceiveSerials(suite.ctx, "product-1", services.ReceiveProductSerialsRequest{
+		LotNumber:     "LOT-7",
+		SerialNumbers: []string{"SN-1", "SN-1 "},
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "repeated")
+}
+
+// Test CaptureSerials - A serial number per unit is captured on the order item
+func (suite *ProductSerialServiceTestSuite) TestCaptureSerials_Success() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.serializedOrder(), nil)
+	suite.serialRepo.On("Capture", suite.ctx, mock.AnythingOfType("*models.OrderItem"), []string{"SN-1", "SN-2"}, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.OrderItem).SerialNumbers = models.StringList(args.Get(2).([]string))
+		}).
+		Return([]string(nil), nil)
+
+	// Execute
+	item, err := suite.serialService.CaptureSerials(suite.ctx, "order-1", "item-1", services.CaptureOrderItemSerialsRequest{
+		SerialNumbers: []string{"SN-1", "SN-2"},
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("item-1", item.ID)
+	suite.Equal([]string{"SN-1", "SN-2"}, item.SerialNumbers)
+}
+
+// Test CaptureSerials - The order item needs exactly one serial number per unit
+func (suite *ProductSerialServiceTestSuite) TestCaptureSerials_WrongCount() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.serializedOrder(), nil)
+
+	// Execute
+	item, err := suite.serialService.CaptureSerials(suite.ctx, "order-1", "item-1", services.CaptureOrderItemSerialsRequest{
+		SerialNumbers: []string{"SN-1"},
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(item)
+	suite.Contains(err.Error(), "has 2 units")
+	suite.serialRepo.AssertNotCalled(suite.T(), "Capture", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test CaptureSerials - Serial numbers not received or allocated to another order are rejected
+func (suite *ProductSerialServiceTestSuite) TestCaptureSerials_Rejected() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.serializedOrder(), nil)
+	suite.serialRepo.On("Capture", suite.ctx, mock.AnythingOfType("*models.OrderItem"), []string{"SN-1", "SN-9"}, mock.AnythingOfType("time.Time")).
+		Return([]string{"SN-9"}, nil)
+
+	// Execute
+	item, err := suite.serialService.CaptureSerials(suite.ctx, "order-1", "item-1", services.CaptureOrderItemSerialsRequest{
+		SerialNumbers: []string{"SN-1", "SN-9"},
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(item)
+	suite.Contains(err.Error(), "SN-9")
+}
+
+// Test CaptureSerials - Serial numbers are not captured once the order has shipped
+func (suite *ProductSerialServiceTestSuite) TestCaptureSerials_Shipped() {
+	order := suite.serializedOrder()
+	order.Status = models.OrderStatusShipped
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(order, nil)
+
+	// Execute
+	item, err := suite.serialService.CaptureSerials(suite.ctx, "order-1", "item-1", services.CaptureOrderItemSerialsRequest{
+		SerialNumbers: []string{"SN-1", "SN-2"},
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(item)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// TestProductSerialServiceTestSuite runs the test suite
+func TestProductSerialServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductSerialServiceTestSuite))
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"easy-orders-backend/internal/models"
 	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/i18n"
 	"easy-orders-backend/pkg/logger"
 	"easy-orders-backend/tests/mocks"
 	"easy-orders-backend/tests/testutil"
@@ -36,6 +38,8 @@ func (suite *ProductServiceTestSuite) SetupTest() {
 	suite.productService = services.NewProductService(
 		suite.productRepo,
 		suite.inventoryRepo,
+		nil, // Stock is read from inventory
+		i18n.NewTranslator(),
 		suite.logger,
 	)
 }
@@ -158,6 +162,24 @@ func (suite *ProductServiceTestSuite) TestCreateProduct_ValidationError_InvalidP
 	assert.Contains(suite.T(), err.Error(), "price must be greater than 0")
 }
 
+// Test CreateProduct - Validation Error: Invalid Metadata Key
+func (suite *ProductServiceTestSuite) TestCreateProduct_ValidationError_InvalidMetadata() {
+	req := services.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		SKU:      "TEST-SKU",
+		Metadata: map[string]string{"bad key!": "value"},
+	}
+
+	// Execute
+	response, err := suite.productService.CreateProduct(suite.ctx, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "invalid metadata")
+}
+
 // Test CreateProduct - Duplicate SKU
 func (suite *ProductServiceTestSuite) TestCreateProduct_DuplicateSKU() {
 	req := services.CreateProductRequest{
@@ -247,6 +269,38 @@ func (suite *ProductServiceTestSuite) TestGetProduct_Success() {
 	assert.Equal(suite.T(), 50, response.Stock)
 }
 
+// Test GetProduct - The name and description are resolved in the request locale,
+// falling back to the default locale for fields not translated
+func (suite *ProductServiceTestSuite) TestGetProduct_Localized() {
+	productID := "product-id-123"
+	product := testutil.CreateTestProduct(func(p *models.Product) {
+		p.ID = productID
+		p.Name = "T-shirt"
+		p.Description = "Cotton"
+		p.Translations = models.ProductTranslations{"es": {Name: "Camiseta"}}
+	})
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", mock.Anything, productID).Return(product, nil)
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, productID).Return(nil, nil)
+
+	// Execute
+	response, err := suite.productService.GetProduct(i18n.WithLocale(suite.ctx, "es"), productID)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Camiseta", response.Name)
+	assert.Equal(suite.T(), "Cotton", response.Description)
+	assert.Equal(suite.T(), "es", response.Locale)
+
+	// Locales the product is not translated into fall back to the default locale
+	response, err = suite.productService.GetProduct(i18n.WithLocale(suite.ctx, "fr"), productID)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "T-shirt", response.Name)
+	assert.Equal(suite.T(), i18n.DefaultLocale, response.Locale)
+}
+
 // Test GetProduct - Without Inventory
 func (suite *ProductServiceTestSuite) TestGetProduct_WithoutInventory() {
 	productID := "product-id-456"
@@ -345,6 +399,113 @@ func (suite *ProductServiceTestSuite) TestUpdateProduct_Success() {
 	assert.False(suite.T(), response.IsActive)
 }
 
+// Test UpdateProduct - Purchase limit for a limited release
+func (suite *ProductServiceTestSuite) TestUpdateProduct_PurchaseLimit() {
+	productID := "product-id-123"
+	product := testutil.CreateTestProduct(func(p *models.Product) {
+		p.ID = productID
+	})
+	inventory := testutil.CreateTestInventory(productID)
+
+	limit, days := 2, 30
+	suite.productRepo.On("GetByID", suite.ctx, productID).Return(product, nil)
+	suite.productRepo.On("Update", suite.ctx, mock.AnythingOfType("*models.Product")).Return(nil)
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, productID).Return(inventory, nil)
+
+	// Execute
+	response, err := suite.productService.UpdateProduct(suite.ctx, productID, services.UpdateProductRequest{
+		PurchaseLimit:     &limit,
+		PurchaseLimitDays: &days,
+	})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, response.PurchaseLimit)
+	assert.Equal(suite.T(), 30, response.PurchaseLimitDays)
+
+	now := time.Now()
+	since := product.PurchaseLimitSince(now)
+	assert.NotNil(suite.T(), since)
+	assert.Equal(suite.T(), now.AddDate(0, 0, -30), *since)
+
+	// A negative limit is rejected
+	negative := -1
+	_, err = suite.productService.UpdateProduct(suite.ctx, productID, services.UpdateProductRequest{PurchaseLimit: &negative})
+	assert.Error(suite.T(), err)
+}
+
+// Test UpdateProduct - Metadata Merge
+func (suite *ProductServiceTestSuite) TestUpdateProduct_MergesMetadata() {
+	productID := "product-id-123"
+	product := testutil.CreateTestProduct(func(p *models.Product) {
+		p.ID = productID
+		p.Metadata = models.Metadata{"erp_id": "ERP-1", "legacy_ref": "L-9"}
+	})
+
+	req := services.UpdateProductRequest{
+		Metadata: map[string]string{"erp_id": "ERP-2", "legacy_ref": ""},
+	}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, productID).Return(product, nil)
+	suite.productRepo.On("Update", suite.ctx, mock.AnythingOfType("*models.Product")).Return(nil)
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, productID).Return(nil, nil)
+
+	// Execute
+	response, err := suite.productService.UpdateProduct(suite.ctx, productID, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"erp_id": "ERP-2"}, response.Metadata)
+}
+
+// Test UpdateProduct - Translations are merged; an empty translation removes its locale
+func (suite *ProductServiceTestSuite) TestUpdateProduct_MergesTranslations() {
+	productID := "product-id-123"
+	product := testutil.CreateTestProduct(func(p *models.Product) {
+		p.ID = productID
+		p.Translations = models.ProductTranslations{"es": {Name: "Camiseta"}}
+	})
+
+	req := services.UpdateProductRequest{
+		Translations: map[string]models.ProductTranslation{"FR": {Name: "T-shirt", Description: "En coton"}, "es": {}},
+	}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, productID).Return(product, nil)
+	suite.productRepo.On("Update", suite.ctx, mock.AnythingOfType("*models.Product")).Return(nil)
+	suite.inventoryRepo.On("GetByProductID", suite.ctx, productID).Return(nil, nil)
+
+	// Execute
+	response, err := suite.productService.UpdateProduct(suite.ctx, productID, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]models.ProductTranslation{"fr": {Name: "T-shirt", Description: "En coton"}}, response.Translations)
+}
+
+// Test UpdateProduct - Products are only translated into supported locales
+func (suite *ProductServiceTestSuite) TestUpdateProduct_UnsupportedLocale() {
+	productID := "product-id-123"
+	product := testutil.CreateTestProduct(func(p *models.Product) { p.ID = productID })
+
+	req := services.UpdateProductRequest{
+		Translations: map[string]models.ProductTranslation{"xx": {Name: "Unknown"}},
+	}
+
+	// Mock expectations
+	suite.productRepo.On("GetByID", suite.ctx, productID).Return(product, nil)
+
+	// Execute
+	response, err := suite.productService.UpdateProduct(suite.ctx, productID, req)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "invalid translations")
+	suite.productRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+}
+
 // Test UpdateProduct - Validation Error: ID Required
 func (suite *ProductServiceTestSuite) TestUpdateProduct_ValidationError_IDRequired() {
 	req := services.UpdateProductRequest{
@@ -456,6 +617,31 @@ func (suite *ProductServiceTestSuite) TestListProducts_ActiveOnly() {
 	assert.Equal(suite.T(), 1, len(response.Products))
 }
 
+// Test ListProducts - Search matches the text translated in the request locale
+func (suite *ProductServiceTestSuite) TestListProducts_SearchInRequestLocale() {
+	ctx := i18n.WithLocale(suite.ctx, "fr")
+	products := []*models.Product{
+		testutil.CreateTestProduct(func(p *models.Product) {
+			p.ID = "1"
+			p.Name = "T-shirt"
+			p.Translations = models.ProductTranslations{"fr": {Name: "Tee-shirt"}}
+		}),
+	}
+
+	// Mock expectations
+	suite.productRepo.On("Search", ctx, "tee", "fr", 0, 20).Return(products, nil)
+	suite.productRepo.On("CountSearch", ctx, "tee", "fr").Return(int64(1), nil)
+
+	// Execute
+	response, err := suite.productService.ListProducts(ctx, services.ListProductsRequest{Query: " tee "})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, response.Total)
+	assert.Equal(suite.T(), "Tee-shirt", response.Products[0].Name)
+	assert.Equal(suite.T(), "fr", response.Products[0].Locale)
+}
+
 // Test ListProducts - Default Pagination
 func (suite *ProductServiceTestSuite) TestListProducts_DefaultPagination() {
 	products := []*models.Product{}
@@ -0,0 +1,244 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// PromotionServiceTestSuite defines the test suite for PromotionService and the
+// promotion engine
+type PromotionServiceTestSuite struct {
+	suite.Suite
+	promotionService services.PromotionService
+	engine           *services.PromotionEngine
+	promotionRepo    *mocks.MockPromotionRepository
+	orderRepo        *mocks.MockOrderRepository
+	logger           *logger.Logger
+	ctx              context.Context
+	now              time.Time
+	user             *models.User
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PromotionServiceTestSuite) SetupTest() {
+	suite.promotionRepo = new(mocks.MockPromotionRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+	suite.now = time.Date(2025, 11, 28, 12, 0, 0, 0, time.UTC)
+	suite.user = &models.User{ID: "user-1"}
+
+	suite.promotionService = services.NewPromotionService(suite.promotionRepo, suite.logger)
+	suite.engine = services.NewPromotionEngine(suite.promotionRepo, suite.orderRepo,
+		services.PromotionSettings{ShippingFee: 7.50}, suite.logger)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PromotionServiceTestSuite) TearDownTest() {
+	suite.promotionRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// apply applies the running promotions to lines of an order on the direct channel
+func (suite *PromotionServiceTestSuite) apply(promotions []*models.Promotion, lines []services.PromotionLine) *services.PromotionOutcome {
+	suite.promotionRepo.On("ListRunning", suite.ctx, suite.now).Return(promotions, nil).Once()
+
+	eligible, err := suite.engine.Eligible(suite.ctx, suite.user, models.OrderChannelDirect, suite.now)
+	require.NoError(suite.T(), err)
+	return eligible.Apply(lines)
+}
+
+func appliedNames(outcome *services.PromotionOutcome) []string {
+	names := make([]string, len(outcome.Applied))
+	for i, applied := range outcome.Applied {
+		names[i] = applied.Name
+	}
+	return names
+}
+
+// Test Apply - Promotions stack in evaluation order, each discounting what is left
+func (suite *PromotionServiceTestSuite) TestApply_StacksInOrder() {
+	shoes := "category-shoes"
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "Shoes BOGO", Type: models.PromotionTypeCategoryBOGO, CategoryID: &shoes, Priority: 10},
+		{ID: "p2", Name: "Black Friday", Type: models.PromotionTypePercentOff, PercentOff: 10, Priority: 5},
+		{ID: "p3", Name: "Free shipping over 100", Type: models.PromotionTypeFreeShipping, MinSubtotal: 100},
+	}
+	lines := []services.PromotionLine{
+		{ProductID: "sneaker", CategoryID: &shoes, UnitPrice: 60, Quantity: 1},
+		{ProductID: "sandal", CategoryID: &shoes, UnitPrice: 30, Quantity: 1},
+		{ProductID: "hat", UnitPrice: 20, Quantity: 1},
+	}
+
+	outcome := suite.apply(promotions, lines)
+
+	// BOGO frees the cheaper shoe (30), then 10% of the remaining 80
+	assert.Equal(suite.T(), []string{"Shoes BOGO", "Black Friday", "Free shipping over 100"}, appliedNames(outcome))
+	assert.InDelta(suite.T(), 38.0, outcome.Discount, 0.001)
+	assert.InDelta(suite.T(), 30.0, outcome.Applied[0].Discount, 0.001)
+	assert.InDelta(suite.T(), 8.0, outcome.Applied[1].Discount, 0.001)
+	assert.InDelta(suite.T(), 7.50, outcome.Applied[2].Discount, 0.001)
+	assert.Zero(suite.T(), outcome.ShippingFee)
+}
+
+// Test Apply - An exclusive promotion applied first keeps the others from applying
+func (suite *PromotionServiceTestSuite) TestApply_ExclusiveAppliesAlone() {
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "VIP 25%", Type: models.PromotionTypePercentOff, PercentOff: 25, Priority: 10, Exclusive: true},
+		{ID: "p2", Name: "Sitewide 10%", Type: models.PromotionTypePercentOff, PercentOff: 10},
+		{ID: "p3", Name: "Free shipping", Type: models.PromotionTypeFreeShipping},
+	}
+
+	outcome := suite.apply(promotions, []services.PromotionLine{{ProductID: "hat", UnitPrice: 40, Quantity: 1}})
+
+	assert.Equal(suite.T(), []string{"VIP 25%"}, appliedNames(outcome))
+	assert.InDelta(suite.T(), 10.0, outcome.Discount, 0.001)
+	assert.InDelta(suite.T(), 7.50, outcome.ShippingFee, 0.001)
+}
+
+// Test Apply - An exclusive promotion is skipped once another promotion applied
+func (suite *PromotionServiceTestSuite) TestApply_ExclusiveSkippedAfterOthers() {
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "Sitewide 10%", Type: models.PromotionTypePercentOff, PercentOff: 10, Priority: 10},
+		{ID: "p2", Name: "VIP 25%", Type: models.PromotionTypePercentOff, PercentOff: 25, Exclusive: true},
+		{ID: "p3", Name: "Free shipping", Type: models.PromotionTypeFreeShipping},
+	}
+
+	outcome := suite.apply(promotions, []services.PromotionLine{{ProductID: "hat", UnitPrice: 40, Quantity: 1}})
+
+	assert.Equal(suite.T(), []string{"Sitewide 10%", "Free shipping"}, appliedNames(outcome))
+	assert.InDelta(suite.T(), 4.0, outcome.Discount, 0.001)
+}
+
+// Test Apply - Only the first promotion of a stack group applies, and promotions that
+// would take nothing off do not use up the group
+func (suite *PromotionServiceTestSuite) TestApply_StackGroup() {
+	toys := "category-toys"
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "Toys BOGO", Type: models.PromotionTypeCategoryBOGO, CategoryID: &toys, Priority: 10, StackGroup: "seasonal"},
+		{ID: "p2", Name: "Winter 15%", Type: models.PromotionTypePercentOff, PercentOff: 15, Priority: 5, StackGroup: "seasonal"},
+		{ID: "p3", Name: "Holiday 5%", Type: models.PromotionTypePercentOff, PercentOff: 5, StackGroup: "seasonal"},
+	}
+
+	outcome := suite.apply(promotions, []services.PromotionLine{{ProductID: "hat", UnitPrice: 100, Quantity: 1}})
+
+	assert.Equal(suite.T(), []string{"Winter 15%"}, appliedNames(outcome))
+	assert.InDelta(suite.T(), 15.0, outcome.Discount, 0.001)
+}
+
+// Test Apply - Orders below a promotion's minimum subtotal do not get it
+func (suite *PromotionServiceTestSuite) TestApply_MinSubtotal() {
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "Free shipping over 50", Type: models.PromotionTypeFreeShipping, MinSubtotal: 50},
+	}
+
+	outcome := suite.apply(promotions, []services.PromotionLine{{ProductID: "hat", UnitPrice: 49.99, Quantity: 1}})
+
+	assert.Empty(suite.T(), outcome.Applied)
+	assert.InDelta(suite.T(), 7.50, outcome.ShippingFee, 0.001)
+}
+
+// Test Eligible - First-order and channel promotions only go to the orders they target
+func (suite *PromotionServiceTestSuite) TestEligible_Conditions() {
+	promotions := []*models.Promotion{
+		{ID: "p1", Name: "Welcome 20%", Type: models.PromotionTypePercentOff, PercentOff: 20, Priority: 10, FirstOrderOnly: true},
+		{ID: "p2", Name: "App only 5%", Type: models.PromotionTypePercentOff, PercentOff: 5, Channel: "mobile_app"},
+		{ID: "p3", Name: "Sitewide 10%", Type: models.PromotionTypePercentOff, PercentOff: 10},
+	}
+	suite.orderRepo.On("CountByUserID", suite.ctx, "user-1").Return(int64(3), nil)
+
+	outcome := suite.apply(promotions, []services.PromotionLine{{ProductID: "hat", UnitPrice: 100, Quantity: 1}})
+
+	assert.Equal(suite.T(), []string{"Sitewide 10%"}, appliedNames(outcome))
+}
+
+// Test Apply - A nil engine applies nothing and charges no shipping
+func (suite *PromotionServiceTestSuite) TestApply_NilEngine() {
+	var engine *services.PromotionEngine
+
+	eligible, err := engine.Eligible(suite.ctx, suite.user, models.OrderChannelDirect, suite.now)
+	require.NoError(suite.T(), err)
+	outcome := eligible.Apply([]services.PromotionLine{{ProductID: "hat", UnitPrice: 100, Quantity: 1}})
+
+	assert.Empty(suite.T(), outcome.Applied)
+	assert.Zero(suite.T(), outcome.Discount)
+	assert.Zero(suite.T(), outcome.ShippingFee)
+}
+
+// Test CreatePromotion - Percent off promotions need a percent, and windows must end after they start
+func (suite *PromotionServiceTestSuite) TestCreatePromotion_Validation() {
+	_, err := suite.promotionService.CreatePromotion(suite.ctx, services.CreatePromotionRequest{
+		Name:     "No percent",
+		Type:     models.PromotionTypePercentOff,
+		StartsAt: suite.now,
+		EndsAt:   suite.now.Add(24 * time.Hour),
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+
+	_, err = suite.promotionService.CreatePromotion(suite.ctx, services.CreatePromotionRequest{
+		Name:       "Backwards",
+		Type:       models.PromotionTypePercentOff,
+		PercentOff: 10,
+		StartsAt:   suite.now,
+		EndsAt:     suite.now.Add(-time.Hour),
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "end after it starts")
+}
+
+// Test CreatePromotion - Names are unique
+func (suite *PromotionServiceTestSuite) TestCreatePromotion_DuplicateName() {
+	suite.promotionRepo.On("GetByName", suite.ctx, "Black Friday").Return(&models.Promotion{ID: "p1", Name: "Black Friday"}, nil)
+
+	_, err := suite.promotionService.CreatePromotion(suite.ctx, services.CreatePromotionRequest{
+		Name:       "Black Friday",
+		Type:       models.PromotionTypePercentOff,
+		PercentOff: 20,
+		StartsAt:   suite.now,
+		EndsAt:     suite.now.Add(72 * time.Hour),
+	})
+
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "CONFLICT")
+}
+
+// Test GeneratePromotionReport - Totals add up the promotions and average order value is of redeemed orders
+func (suite *PromotionServiceTestSuite) TestGeneratePromotionReport() {
+	start := time.Date(2025, 11, 24, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	suite.promotionRepo.On("SummarizeRedemptions", suite.ctx, start, end).Return([]repository.PromotionSummary{
+		{PromotionID: "p1", Name: "Black Friday", Type: models.PromotionTypePercentOff, Orders: 5, Redeemed: 4, Discount: 42.5, Revenue: 380},
+		{PromotionID: "p2", Name: "Free shipping", Type: models.PromotionTypeFreeShipping, Orders: 2, Redeemed: 2, Discount: 15, Revenue: 210},
+	}, nil)
+
+	report, err := suite.promotionService.GeneratePromotionReport(suite.ctx, services.PromotionReportRequest{
+		StartDate: "2025-11-24",
+		EndDate:   "2025-11-30",
+	})
+
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(7), report.Orders)
+	assert.Equal(suite.T(), int64(6), report.Redeemed)
+	assert.InDelta(suite.T(), 57.5, report.Discount, 0.001)
+	require.Len(suite.T(), report.Promotions, 2)
+	assert.InDelta(suite.T(), 95.0, report.Promotions[0].AverageOrderValue, 0.001)
+	suite.promotionRepo.AssertNotCalled(suite.T(), "ListRunning", mock.Anything, mock.Anything)
+}
+
+// TestPromotionServiceTestSuite runs the test suite
+func TestPromotionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(PromotionServiceTestSuite))
+}
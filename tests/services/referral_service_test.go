@@ -0,0 +1,245 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ReferralServiceTestSuite defines the test suite for ReferralService
+type ReferralServiceTestSuite struct {
+	suite.Suite
+	referralService services.ReferralService
+	referralRepo    *mocks.MockReferralRepository
+	creditRepo      *mocks.MockStoreCreditRepository
+	ctx             context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ReferralServiceTestSuite) SetupTest() {
+	suite.referralRepo = new(mocks.MockReferralRepository)
+	suite.creditRepo = new(mocks.MockStoreCreditRepository)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.referralService = services.NewReferralService(
+		services.ReferralSettings{RewardAmount: 10, ReturnWindow: 14 * 24 * time.Hour},
+		suite.referralRepo,
+		suite.creditRepo,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ReferralServiceTestSuite) TearDownTest() {
+	suite.referralRepo.AssertExpectations(suite.T())
+	suite.creditRepo.AssertExpectations(suite.T())
+}
+
+// Test GetReferralCode - An existing code is returned as is
+func (suite *ReferralServiceTestSuite) TestGetReferralCode_Existing() {
+	suite.referralRepo.On("GetCodeByUserID", suite.ctx, "user-1").
+		Return(&models.ReferralCode{UserID: "user-1", Code: "ABCD2345"}, nil)
+
+	// Execute
+	code, err := suite.referralService.GetReferralCode(suite.ctx, "user-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("ABCD2345", code.Code)
+	suite.referralRepo.AssertNotCalled(suite.T(), "CreateCode", mock.Anything, mock.Anything)
+}
+
+// Test GetReferralCode - A code taken by another user is retried with a new one
+func (suite *ReferralServiceTestSuite) TestGetReferralCode_RetriesTakenCode() {
+	var codes []string
+	suite.referralRepo.On("GetCodeByUserID", suite.ctx, "user-1").Return(nil, nil)
+	suite.referralRepo.On("CreateCode", suite.ctx, mock.AnythingOfType("*models.ReferralCode")).
+		Run(func(args mock.Arguments) { codes = append(codes, args.Get(1).(*models.ReferralCode).Code) }).
+		Return(false, nil).Once()
+	suite.referralRepo.On("CreateCode", suite.ctx, mock.AnythingOfType("*models.ReferralCode")).
+		Run(func(args mock.Arguments) { codes = append(codes, args.Get(1).(*models.ReferralCode).Code) }).
+		Return(true, nil).Once()
+
+	// Execute
+	code, err := suite.referralService.GetReferralCode(suite.ctx, "user-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(codes, 2)
+	suite.Equal(codes[1], code.Code)
+	suite.Len(code.Code, 8)
+	suite.Regexp("^[A-HJ-NP-Z2-9]+$", code.Code)
+}
+
+// Test ResolveReferralCode - Codes match regardless of case and spaces
+func (suite *ReferralServiceTestSuite) TestResolveReferralCode_Success() {
+	suite.referralRepo.On("GetCodeByCode", suite.ctx, "ABCD2345").
+		Return(&models.ReferralCode{UserID: "referrer-1", Code: "ABCD2345"}, nil)
+
+	// Execute
+	referrerID, err := suite.referralService.ResolveReferralCode(suite.ctx, " abcd2345 ")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("referrer-1", referrerID)
+}
+
+// Test ResolveReferralCode - Unknown codes are a validation error
+func (suite *ReferralServiceTestSuite) TestResolveReferralCode_Unknown() {
+	suite.referralRepo.On("GetCodeByCode", suite.ctx, "NOPE2345").Return(nil, nil)
+
+	// Execute
+	_, err := suite.referralService.ResolveReferralCode(suite.ctx, "NOPE2345")
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+	suite.Contains(err.Error(), "invalid referral code")
+}
+
+// Test AttributeSignup - Users can't refer themselves
+func (suite *ReferralServiceTestSuite) TestAttributeSignup() {
+	suite.referralRepo.On("Create", suite.ctx, mock.MatchedBy(func(referral *models.Referral) bool {
+		return referral.ReferrerID == "referrer-1" && referral.RefereeID == "user-1" &&
+			referral.Code == "ABCD2345" && referral.Status == models.ReferralStatusSignedUp
+	})).Return(true, nil).Once()
+
+	// Execute
+	suite.referralService.AttributeSignup(suite.ctx, "referrer-1", "user-1", "abcd2345")
+	suite.referralService.AttributeSignup(suite.ctx, "user-1", "user-1", "ABCD2345")
+}
+
+// Test RecordActivity - Only placed orders are attributed to referrals
+func (suite *ReferralServiceTestSuite) TestRecordActivity_AttributesPlacedOrders() {
+	suite.referralRepo.On("AttributeOrder", suite.ctx, "user-1", "order-1", mock.AnythingOfType("time.Time")).
+		Return(true, nil).Once()
+
+	// Execute
+	suite.referralService.RecordActivity(suite.ctx, "user-1", models.ActivityEventOrderPlaced, "order-1")
+	suite.referralService.RecordActivity(suite.ctx, "user-1", models.ActivityEventRegistered, "")
+}
+
+// Test ProcessRewards - Delivered orders are rewarded, returned ones voided and cancelled ones released
+func (suite *ReferralServiceTestSuite) TestProcessRewards() {
+	suite.referralRepo.On("ListSettleable", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).
+		Return([]repository.ReferralOrderOutcome{
+			{ID: "ref-1", ReferrerID: "referrer-1", RefereeID: "user-1", OrderID: "order-1", OrderStatus: models.OrderStatusDelivered, OrderTotal: 80},
+			{ID: "ref-2", ReferrerID: "referrer-1", RefereeID: "user-2", OrderID: "order-2", OrderStatus: models.OrderStatusDelivered, OrderTotal: 50, Refunded: 50},
+			{ID: "ref-3", ReferrerID: "referrer-2", RefereeID: "user-3", OrderID: "order-3", OrderStatus: models.OrderStatusCancelled},
+			{ID: "ref-4", ReferrerID: "referrer-2", RefereeID: "user-4", OrderID: "order-4", OrderStatus: models.OrderStatusDelivered, OrderDeleted: true},
+		}, nil)
+
+	var credit *models.StoreCreditTransaction
+	suite.referralRepo.On("Reward", suite.ctx, mock.MatchedBy(func(referral *models.Referral) bool {
+		return referral.ID == "ref-1" && referral.RewardAmount == 10 && referral.RewardedAt != nil
+	}), mock.AnythingOfType("*models.StoreCreditTransaction")).
+		Run(func(args mock.Arguments) { credit = args.Get(2).(*models.StoreCreditTransaction) }).
+		Return(true, nil)
+	suite.referralRepo.On("Void", suite.ctx, "ref-2", "order order-2 was returned").Return(true, nil)
+	suite.referralRepo.On("ReleaseOrder", suite.ctx, "ref-3").Return(true, nil)
+	suite.referralRepo.On("ReleaseOrder", suite.ctx, "ref-4").Return(true, nil)
+
+	// Execute
+	run, err := suite.referralService.ProcessRewards(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(1, run.Rewarded)
+	suite.Equal(1, run.Voided)
+	suite.Equal(2, run.Released)
+	suite.Equal(10.0, run.Credited)
+
+	suite.Require().NotNil(credit)
+	suite.Equal("referrer-1", credit.UserID)
+	suite.Equal(10.0, credit.Amount)
+	suite.Equal(models.StoreCreditReasonReferralReward, credit.Reason)
+	suite.Equal("ref-1", credit.ReferenceID)
+}
+
+// Test ProcessRewards - Referrals rewarded concurrently aren't counted twice
+func (suite *ReferralServiceTestSuite) TestProcessRewards_AlreadySettled() {
+	suite.referralRepo.On("ListSettleable", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("int")).
+		Return([]repository.ReferralOrderOutcome{
+			{ID: "ref-1", ReferrerID: "referrer-1", OrderID: "order-1", OrderStatus: models.OrderStatusDelivered},
+		}, nil)
+	suite.referralRepo.On("Reward", suite.ctx, mock.Anything, mock.Anything).Return(false, nil)
+
+	// Execute
+	run, err := suite.referralService.ProcessRewards(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(0, run.Rewarded)
+	suite.Equal(0.0, run.Credited)
+}
+
+// Test GenerateReferralReport - Totals and conversion rates are computed from the per-referrer summaries
+func (suite *ReferralServiceTestSuite) TestGenerateReferralReport() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	suite.referralRepo.On("SummarizeByReferrer", suite.ctx, start, start.AddDate(0, 0, 31)).
+		Return([]repository.ReferrerSummary{
+			{ReferrerID: "referrer-1", Name: "Ann", Email: "ann@example.com", Signups: 4, Ordered: 3, Rewarded: 2, Voided: 1, Rewards: 20, Revenue: 240.5},
+			{ReferrerID: "referrer-2", Name: "Bo", Email: "bo@example.com", Signups: 2, Ordered: 0},
+		}, nil)
+
+	// Execute
+	report, err := suite.referralService.GenerateReferralReport(suite.ctx, services.ReferralReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-31",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("2025-03-01", report.StartDate)
+	suite.Equal("2025-03-31", report.EndDate)
+	suite.Equal(int64(6), report.Totals.Signups)
+	suite.Equal(int64(3), report.Totals.Ordered)
+	suite.Equal(50.0, report.Totals.ConversionRate)
+	suite.Equal(20.0, report.Totals.Rewards)
+	suite.Equal(240.5, report.Totals.Revenue)
+	suite.Require().Len(report.ByReferrer, 2)
+	suite.Equal(75.0, report.ByReferrer[0].ConversionRate)
+	suite.Equal(0.0, report.ByReferrer[1].ConversionRate)
+}
+
+// Test GenerateReferralReport - Invalid date ranges are refused
+func (suite *ReferralServiceTestSuite) TestGenerateReferralReport_InvalidRange() {
+	// Execute
+	_, err := suite.referralService.GenerateReferralReport(suite.ctx, services.ReferralReportRequest{
+		StartDate: "2025-03-31",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// Test GetStoreCredit - The balance is returned with the latest transactions
+func (suite *ReferralServiceTestSuite) TestGetStoreCredit() {
+	suite.creditRepo.On("GetBalance", suite.ctx, "user-1").Return(30.0, nil)
+	suite.creditRepo.On("ListByUserID", suite.ctx, "user-1", mock.AnythingOfType("int")).Return(nil, nil)
+
+	// Execute
+	credit, err := suite.referralService.GetStoreCredit(suite.ctx, "user-1")
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(30.0, credit.Balance)
+	suite.NotNil(credit.Transactions)
+}
+
+// TestReferralServiceTestSuite runs the test suite
+func TestReferralServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ReferralServiceTestSuite))
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/notifications"
+	"easy-orders-backend/pkg/reports"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReportCompletionNotifier returns a report completion notifier submitting to a
+// recording digester
+func newReportCompletionNotifier() (*services.ReportCompletionNotifier, *recordingDigester) {
+	digests := newRecordingDigester()
+	return services.NewReportCompletionNotifier(digests, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}), digests
+}
+
+// Test NotifyReportCompleted - The user who queued a report is told it is ready to download
+func TestNotifyReportCompleted_Ready(t *testing.T) {
+	notifier, digests := newReportCompletionNotifier()
+
+	// Execute
+	notifier.NotifyReportCompleted(
+		&reports.ReportRequest{ID: "request-1", UserID: "admin-1"},
+		&reports.ReportResult{ID: "result-1", Type: reports.ReportTypeDailySales, Format: reports.ReportFormatPDF, Status: reports.ReportStatusCompleted},
+	)
+
+	// Assert
+	notification := digests.next(t)
+	assert.Equal(t, "admin-1", notification.Recipient)
+	assert.Equal(t, notifications.NotificationTypeReportCompleted, notification.Type)
+	assert.Equal(t, string(models.NotificationChannelInApp), notification.Channel)
+	assert.Equal(t, "Your daily sales report is ready", notification.Subject)
+	assert.Equal(t, "The daily sales report you queued is ready to download from report result result-1.", notification.Body)
+
+	var data map[string]string
+	require.NoError(t, json.Unmarshal([]byte(notification.Data["data"].(string)), &data))
+	assert.Equal(t, "result-1", data["result_id"])
+	assert.Equal(t, "completed", data["status"])
+}
+
+// Test NotifyReportCompleted - A failed report is notified with its error, and reports
+// nobody queued are not notified
+func TestNotifyReportCompleted_Failed(t *testing.T) {
+	notifier, digests := newReportCompletionNotifier()
+	result := &reports.ReportResult{ID: "result-1", Type: reports.ReportTypeDailySales, Status: reports.ReportStatusFailed, Error: "database unavailable"}
+
+	// Execute
+	notifier.NotifyReportCompleted(&reports.ReportRequest{ID: "request-1"}, result)
+	notifier.NotifyReportCompleted(&reports.ReportRequest{ID: "request-1", UserID: "admin-1"}, result)
+
+	// Assert
+	notification := digests.next(t)
+	assert.Equal(t, "admin-1", notification.Recipient)
+	assert.Equal(t, "Your daily sales report failed", notification.Subject)
+	assert.Contains(t, notification.Body, "could not be generated: database unavailable")
+	assert.Empty(t, digests.submitted)
+}
@@ -0,0 +1,751 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// ReportServiceTestSuite defines the test suite for ReportService
+type ReportServiceTestSuite struct {
+	suite.Suite
+	reportService    services.ReportService
+	orderRepo        *mocks.MockOrderRepository
+	paymentRepo      *mocks.MockPaymentRepository
+	activityRepo     *mocks.MockActivityEventRepository
+	attemptRepo      *mocks.MockPaymentAttemptRepository
+	notificationRepo *mocks.MockNotificationRepository
+	logger           *logger.Logger
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *ReportServiceTestSuite) SetupTest() {
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.activityRepo = new(mocks.MockActivityEventRepository)
+	suite.attemptRepo = new(mocks.MockPaymentAttemptRepository)
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.reportService = services.NewReportService(
+		suite.orderRepo,
+		suite.paymentRepo,
+		new(mocks.MockInventoryRepository),
+		new(mocks.MockProductRepository),
+		suite.activityRepo,
+		suite.attemptRepo,
+		suite.notificationRepo,
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *ReportServiceTestSuite) TearDownTest() {
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.activityRepo.AssertExpectations(suite.T())
+	suite.attemptRepo.AssertExpectations(suite.T())
+	suite.notificationRepo.AssertExpectations(suite.T())
+}
+
+// Test GenerateDailySalesReport - Refunded orders reduce net sales and count as returns
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_SubtractsRefunds() {
+	widget := &models.Product{ID: "product-1", Name: "Widget", SKU: "WID-1"}
+	gadget := &models.Product{ID: "product-2", Name: "Gadget", SKU: "GAD-1"}
+
+	orders := []*models.Order{
+		{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 200,
+			Items: []models.OrderItem{
+				{ProductID: widget.ID, Product: widget, Quantity: 2, TotalPrice: 200},
+			},
+			Payments: []models.Payment{{Amount: 200, Status: models.PaymentStatusCompleted}},
+		},
+		{
+			ID:          "order-2",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 150,
+			Items: []models.OrderItem{
+				{ProductID: widget.ID, Product: widget, Quantity: 1, TotalPrice: 100},
+				{ProductID: gadget.ID, Product: gadget, Quantity: 1, TotalPrice: 50},
+			},
+			Payments: []models.Payment{{Amount: 150, Status: models.PaymentStatusRefunded}},
+		},
+		{
+			// Refunds on orders that never counted as sales don't reduce revenue
+			ID:          "order-3",
+			Status:      models.OrderStatusCancelled,
+			TotalAmount: 80,
+			Items: []models.OrderItem{
+				{ProductID: gadget.ID, Product: gadget, Quantity: 1, TotalPrice: 80},
+			},
+			Payments: []models.Payment{{Amount: 80, Status: models.PaymentStatusRefunded}},
+		},
+	}
+
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(orders, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 0)
+
+	suite.Require().NoError(err)
+	suite.Equal(350.0, report.TotalSales)
+	suite.Equal(350.0, report.GrossSales)
+	suite.Equal(150.0, report.RefundedAmount)
+	suite.Equal(200.0, report.NetSales)
+	suite.Equal(2, report.CompletedOrders)
+	suite.Equal(1, report.ReturnedOrders)
+	suite.InDelta(0.5, report.ReturnRate, 0.0001)
+
+	suite.Require().Len(report.ProductSales, 2)
+
+	widgetSales := report.ProductSales[0]
+	suite.Equal(widget.ID, widgetSales.ProductID)
+	suite.Equal("WID-1", widgetSales.SKU)
+	suite.Equal(3, widgetSales.QuantitySold)
+	suite.Equal(1, widgetSales.QuantityReturned)
+	suite.Equal(300.0, widgetSales.GrossRevenue)
+	suite.Equal(100.0, widgetSales.RefundedAmount)
+	suite.Equal(200.0, widgetSales.NetRevenue)
+	suite.InDelta(1.0/3.0, widgetSales.ReturnRate, 0.0001)
+
+	gadgetSales := report.ProductSales[1]
+	suite.Equal(gadget.ID, gadgetSales.ProductID)
+	suite.Equal(1, gadgetSales.QuantitySold)
+	suite.Equal(1, gadgetSales.QuantityReturned)
+	suite.Equal(0.0, gadgetSales.NetRevenue)
+	suite.InDelta(1.0, gadgetSales.ReturnRate, 0.0001)
+}
+
+// Test GenerateDailySalesReport - Partial refunds are attributed to items proportionally
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_PartialRefund() {
+	product := &models.Product{ID: "product-1", Name: "Widget", SKU: "WID-1"}
+
+	orders := []*models.Order{
+		{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 400,
+			Items: []models.OrderItem{
+				{ProductID: product.ID, Product: product, Quantity: 4, TotalPrice: 400},
+			},
+			Payments: []models.Payment{
+				{Amount: 300, Status: models.PaymentStatusCompleted},
+				{Amount: 100, Status: models.PaymentStatusRefunded},
+			},
+		},
+	}
+
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(orders, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 0)
+
+	suite.Require().NoError(err)
+	suite.Equal(400.0, report.GrossSales)
+	suite.Equal(300.0, report.NetSales)
+	suite.Equal(1, report.ReturnedOrders)
+
+	suite.Require().Len(report.ProductSales, 1)
+	suite.Equal(1, report.ProductSales[0].QuantityReturned)
+	suite.InDelta(0.25, report.ProductSales[0].ReturnRate, 0.0001)
+}
+
+// Test GenerateDailySalesReport - Margins use product cost and flag low-margin products
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_ProfitMargins() {
+	electronics := "category-electronics"
+	premium := &models.Product{ID: "product-1", Name: "Premium", SKU: "PRE-1", CostPrice: 60, CategoryID: &electronics}
+	budget := &models.Product{ID: "product-2", Name: "Budget", SKU: "BUD-1", CostPrice: 45}
+
+	orders := []*models.Order{
+		{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 250,
+			Items: []models.OrderItem{
+				{ProductID: premium.ID, Product: premium, Quantity: 2, TotalPrice: 200},
+				{ProductID: budget.ID, Product: budget, Quantity: 1, TotalPrice: 50},
+			},
+			Payments: []models.Payment{{Amount: 250, Status: models.PaymentStatusCompleted}},
+		},
+	}
+
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(orders, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 25)
+
+	suite.Require().NoError(err)
+	suite.Equal(165.0, report.CostOfGoods)
+	suite.Equal(85.0, report.GrossMargin)
+	suite.InDelta(34.0, report.MarginPercent, 0.0001)
+	suite.Equal(25.0, report.LowMarginThreshold)
+	suite.Equal([]string{budget.ID}, report.LowMarginProducts)
+
+	suite.Require().Len(report.ProductSales, 2)
+	suite.InDelta(40.0, report.ProductSales[0].MarginPercent, 0.0001)
+	suite.False(report.ProductSales[0].LowMargin)
+	suite.InDelta(10.0, report.ProductSales[1].MarginPercent, 0.0001)
+	suite.True(report.ProductSales[1].LowMargin)
+
+	suite.Require().Len(report.CategoryMargins, 2)
+	suite.Equal(electronics, report.CategoryMargins[0].CategoryID)
+	suite.Equal(80.0, report.CategoryMargins[0].GrossMargin)
+	suite.Equal("uncategorized", report.CategoryMargins[1].CategoryID)
+	suite.Equal(5.0, report.CategoryMargins[1].GrossMargin)
+}
+
+// Test SalesReportResponse.Document - The printed report stars low-margin products and explains the star
+func (suite *ReportServiceTestSuite) TestSalesReportDocument_LowMarginProducts() {
+	product := &models.Product{ID: "product-1", Name: "Budget", SKU: "BUD-1", CostPrice: 45}
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return([]*models.Order{{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 50,
+			Items:       []models.OrderItem{{ProductID: product.ID, Product: product, Quantity: 1, TotalPrice: 50}},
+			Payments:    []models.Payment{{Amount: 50, Status: models.PaymentStatusCompleted}},
+		}}, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 25)
+	suite.Require().NoError(err)
+	doc := report.Document()
+
+	suite.Equal("Daily Sales Report", doc.Title)
+	suite.Require().Len(doc.Sections, 4)
+	products := doc.Sections[2]
+	suite.Equal("Product Sales", products.Title)
+	suite.Require().Len(products.Table.Rows, 1)
+	suite.Equal([]string{"BUD-1", "Budget *", "1", "0", "50.00", "0.00", "50.00", "10.0%"}, products.Table.Rows[0])
+	suite.Equal([]string{"* Margin below the low margin threshold of 25.0%"}, doc.Notes)
+}
+
+// Test GenerateDailySalesReport - Price overrides report the list price given away
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_PriceOverrides() {
+	product := &models.Product{ID: "product-1", Name: "Widget", SKU: "WID-1", CostPrice: 30}
+	overriddenAt := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	orders := []*models.Order{
+		{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 180,
+			Items: []models.OrderItem{
+				{
+					ProductID: product.ID, Product: product, Quantity: 2, UnitPrice: 40, TotalPrice: 80, ListPrice: 50,
+					PriceOverrideReason: models.PriceOverrideReasonNegotiated, PriceOverriddenAt: &overriddenAt,
+				},
+				{ProductID: product.ID, Product: product, Quantity: 2, UnitPrice: 50, TotalPrice: 100, ListPrice: 50},
+			},
+			Payments: []models.Payment{{Amount: 180, Status: models.PaymentStatusCompleted}},
+		},
+	}
+
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(orders, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 0)
+
+	suite.Require().NoError(err)
+	suite.Equal(180.0, report.GrossSales)
+	suite.Equal(200.0, report.ListSales)
+	suite.Equal(20.0, report.PriceOverrides)
+	suite.Equal(60.0, report.GrossMargin)
+
+	suite.Require().Len(report.ProductSales, 1)
+	suite.Equal(200.0, report.ProductSales[0].ListRevenue)
+	suite.Equal(20.0, report.ProductSales[0].PriceOverrideDiscount)
+}
+
+// Test GenerateDailySalesReport - Returned units carry no cost of goods
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_MarginExcludesReturnedUnits() {
+	product := &models.Product{ID: "product-1", Name: "Widget", SKU: "WID-1", CostPrice: 50}
+
+	orders := []*models.Order{
+		{
+			ID:          "order-1",
+			Status:      models.OrderStatusDelivered,
+			TotalAmount: 400,
+			Items: []models.OrderItem{
+				{ProductID: product.ID, Product: product, Quantity: 4, TotalPrice: 400},
+			},
+			Payments: []models.Payment{
+				{Amount: 300, Status: models.PaymentStatusCompleted},
+				{Amount: 100, Status: models.PaymentStatusRefunded},
+			},
+		},
+	}
+
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(orders, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 0)
+
+	suite.Require().NoError(err)
+	suite.Equal(150.0, report.CostOfGoods)
+	suite.Equal(150.0, report.GrossMargin)
+	suite.InDelta(50.0, report.MarginPercent, 0.0001)
+	suite.Equal(services.DefaultLowMarginThreshold, report.LowMarginThreshold)
+	suite.Empty(report.LowMarginProducts)
+}
+
+// Test GenerateDailySalesReport - Periods without orders report zero rates
+func (suite *ReportServiceTestSuite) TestGenerateDailySalesReport_NoOrders() {
+	suite.orderRepo.On("GetByDateRange", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return([]*models.Order{}, nil)
+
+	report, err := suite.reportService.GenerateDailySalesReport(suite.ctx, "2025-01-15", 0)
+
+	suite.Require().NoError(err)
+	suite.Equal(0.0, report.NetSales)
+	suite.Equal(0.0, report.ReturnRate)
+	suite.Empty(report.ProductSales)
+}
+
+// Test GenerateUserActivityReport - Active/new users and engagement come from activity events
+func (suite *ReportServiceTestSuite) TestGenerateUserActivityReport_FromActivityEvents() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC) // Day after the inclusive end date
+
+	// Mock expectations
+	suite.activityRepo.On("CountActiveUsers", suite.ctx, start, end).Return(int64(8), nil)
+	suite.activityRepo.On("CountByType", suite.ctx, start, end).Return([]repository.ActivityTypeCount{
+		{Type: models.ActivityEventLogin, Events: 20, Users: 7},
+		{Type: models.ActivityEventOrderPlaced, Events: 5, Users: 3},
+		{Type: models.ActivityEventPayment, Events: 4, Users: 3},
+		{Type: models.ActivityEventRegistered, Events: 2, Users: 2},
+	}, nil)
+	suite.activityRepo.On("DailyActiveUsers", suite.ctx, start, end).Return([]repository.DailyActiveUserCount{
+		{Day: start, ActiveUsers: 5},
+		{Day: start.AddDate(0, 0, 2), ActiveUsers: 4},
+	}, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateUserActivityReport(suite.ctx, services.UserActivityReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-03",
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(8, report.ActiveUsers)
+	suite.Equal(2, report.NewUsers)
+	suite.Equal(3, report.PurchasingUsers)
+	suite.Equal(37.5, report.ConversionRate)
+	suite.Equal(31, report.TotalEvents)
+	suite.Equal(3.88, report.EventsPerUser)
+	suite.Len(report.Engagement, 4)
+	suite.Equal(services.ActivityEngagement{Type: "login", Events: 20, Users: 7}, report.Engagement[0])
+
+	// Days without activity are reported as zero
+	suite.Equal([]services.DailyActiveUsersItem{
+		{Date: "2025-03-01", ActiveUsers: 5},
+		{Date: "2025-03-02", ActiveUsers: 0},
+		{Date: "2025-03-03", ActiveUsers: 4},
+	}, report.DailyActiveUsers)
+}
+
+// Test GenerateUserActivityReport - Invalid ranges are rejected before querying
+func (suite *ReportServiceTestSuite) TestGenerateUserActivityReport_InvalidRange() {
+	// Execute
+	_, err := suite.reportService.GenerateUserActivityReport(suite.ctx, services.UserActivityReportRequest{
+		StartDate: "2025-03-05",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "invalid date range")
+
+	// Execute
+	_, err = suite.reportService.GenerateUserActivityReport(suite.ctx, services.UserActivityReportRequest{StartDate: "03/01/2025"})
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "invalid date format")
+
+	// Execute
+	_, err = suite.reportService.GenerateUserActivityReport(suite.ctx, services.UserActivityReportRequest{
+		StartDate: "2024-01-01",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "invalid date range")
+}
+
+// Test GenerateUserActivityReport - Repository errors are returned
+func (suite *ReportServiceTestSuite) TestGenerateUserActivityReport_RepositoryError() {
+	// Mock expectations
+	suite.activityRepo.On("CountActiveUsers", suite.ctx, mock.Anything, mock.Anything).Return(int64(0), errors.New("database error"))
+
+	// Execute
+	report, err := suite.reportService.GenerateUserActivityReport(suite.ctx, services.UserActivityReportRequest{})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(report)
+}
+
+// Test GenerateSettlementReport - Surcharges and discounts are reconciled against gateway fees
+func (suite *ReportServiceTestSuite) TestGenerateSettlementReport_ReconcilesFees() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC) // Day after the inclusive end date
+
+	// Mock expectations
+	suite.paymentRepo.On("SummarizeSettlement", suite.ctx, start, end).Return([]repository.PaymentSettlementSummary{
+		{Method: models.PaymentMethodBankTransfer, Payments: 2, GrossAmount: 198.00, Discounts: 2.00, ProcessingFees: 5.74},
+		{Method: models.PaymentMethodCreditCard, Payments: 3, GrossAmount: 306.00, Surcharges: 6.00, ProcessingFees: 8.87},
+	}, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateSettlementReport(suite.ctx, services.SettlementReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(report.Methods, 2)
+
+	bankTransfer := report.Methods[0]
+	suite.Equal(models.PaymentMethodBankTransfer, bankTransfer.Method)
+	suite.Equal(-2.00, bankTransfer.NetAdjustment)
+	suite.Equal(192.26, bankTransfer.NetSettlement)
+	suite.Equal(-7.74, bankTransfer.FeeVariance)
+
+	creditCard := report.Methods[1]
+	suite.Equal(6.00, creditCard.NetAdjustment)
+	suite.Equal(-2.87, creditCard.FeeVariance)
+
+	suite.Equal(5, report.Totals.Payments)
+	suite.Equal(504.00, report.Totals.GrossAmount)
+	suite.Equal(4.00, report.Totals.NetAdjustment)
+	suite.Equal(14.61, report.Totals.ProcessingFees)
+	suite.Equal(489.39, report.Totals.NetSettlement)
+	suite.Equal(-10.61, report.Totals.FeeVariance)
+}
+
+// Test GenerateSettlementReport - Invalid ranges are rejected before querying
+func (suite *ReportServiceTestSuite) TestGenerateSettlementReport_InvalidRange() {
+	// Execute
+	report, err := suite.reportService.GenerateSettlementReport(suite.ctx, services.SettlementReportRequest{
+		StartDate: "2025-03-05",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(report)
+	suite.Contains(err.Error(), "invalid date range")
+}
+
+// Test GeneratePaymentFailureReport - Failures are broken down by reason, gateway, method and hour
+func (suite *ReportServiceTestSuite) TestGeneratePaymentFailureReport_Breakdowns() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC) // Day after the inclusive end date
+
+	// Mock expectations
+	suite.attemptRepo.On("SummarizeAttempts", suite.ctx, start, end).Return([]repository.PaymentAttemptSummary{
+		{Gateway: "paypal", Method: models.PaymentMethodPayPal, FailureType: "network_error", Hour: 22, Retry: true, Attempts: 1},
+		{Gateway: "stripe", Method: models.PaymentMethodCreditCard, Hour: 9, Attempts: 6, Successes: 6},
+		{Gateway: "stripe", Method: models.PaymentMethodCreditCard, FailureType: "insufficient_funds", Hour: 9, Attempts: 3},
+		{Gateway: "stripe", Method: models.PaymentMethodCreditCard, FailureType: "network_error", Hour: 22, Attempts: 1},
+		{Gateway: "stripe", Method: models.PaymentMethodCreditCard, Hour: 22, Retry: true, Attempts: 3, Successes: 3},
+	}, nil)
+
+	// Execute
+	report, err := suite.reportService.GeneratePaymentFailureReport(suite.ctx, services.PaymentFailureReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(14, report.Totals.Attempts)
+	suite.Equal(5, report.Totals.Failures)
+	suite.Equal(35.71, report.Totals.FailureRate)
+	suite.Equal(4, report.Totals.Retries)
+	suite.Equal(3, report.Totals.RetrySuccesses)
+	suite.Equal(75.00, report.Totals.RetrySuccessRate)
+
+	suite.Len(report.ByFailureType, 2)
+	suite.Equal(services.PaymentFailureTypeSummary{
+		FailureType: "insufficient_funds", Category: payments.FailureCategoryHardDecline, Retriable: false, Failures: 3, Share: 60.00,
+	}, report.ByFailureType[0])
+	suite.Equal(services.PaymentFailureTypeSummary{
+		FailureType: "network_error", Category: payments.FailureCategoryTechnical, Retriable: true, Failures: 2, Share: 40.00,
+	}, report.ByFailureType[1])
+	suite.Equal([]services.PaymentFailureCategorySummary{
+		{Category: payments.FailureCategoryHardDecline, Failures: 3, Share: 60.00},
+		{Category: payments.FailureCategoryTechnical, Failures: 2, Share: 40.00},
+	}, report.ByCategory)
+
+	suite.Len(report.ByGateway, 2)
+	suite.Equal("paypal", report.ByGateway[0].Key)
+	suite.Equal(100.00, report.ByGateway[0].FailureRate)
+	suite.Equal(0.00, report.ByGateway[0].RetrySuccessRate)
+	suite.Equal("stripe", report.ByGateway[1].Key)
+	suite.Equal(30.77, report.ByGateway[1].FailureRate)
+	suite.Equal(100.00, report.ByGateway[1].RetrySuccessRate)
+
+	suite.Len(report.ByMethod, 2)
+	suite.Equal("credit_card", report.ByMethod[0].Key)
+	suite.Equal(13, report.ByMethod[0].Attempts)
+
+	suite.Len(report.ByHour, 24) // Hours without attempts are listed with zero counts
+	suite.Equal(0, report.ByHour[0].Attempts)
+	suite.Equal(9, report.ByHour[9].Attempts)
+	suite.Equal(33.33, report.ByHour[9].FailureRate)
+	suite.Equal(5, report.ByHour[22].Attempts)
+	suite.Equal(40.00, report.ByHour[22].FailureRate)
+	suite.Equal(75.00, report.ByHour[22].RetrySuccessRate)
+}
+
+// Test GeneratePaymentFailureReport - Repository errors are returned
+func (suite *ReportServiceTestSuite) TestGeneratePaymentFailureReport_RepositoryError() {
+	// Mock expectations
+	suite.attemptRepo.On("SummarizeAttempts", suite.ctx, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	// Execute
+	report, err := suite.reportService.GeneratePaymentFailureReport(suite.ctx, services.PaymentFailureReportRequest{})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(report)
+}
+
+// Test GenerateNotificationDeliveryReport - Rates and average times by type, channel and both
+func (suite *ReportServiceTestSuite) TestGenerateNotificationDeliveryReport_Rates() {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC) // Day after the inclusive end date
+
+	// Mock expectations
+	suite.notificationRepo.On("SummarizeDelivery", suite.ctx, start, end).Return([]repository.NotificationDeliverySummary{
+		{Type: models.NotificationTypeOrderShipped, Channel: models.NotificationChannelEmail,
+			Notifications: 10, Sent: 10, Delivered: 8, Read: 2, DeliverySeconds: 480, ReadSeconds: 7200},
+		{Type: models.NotificationTypeOrderShipped, Channel: models.NotificationChannelInApp,
+			Notifications: 5, Sent: 4, Delivered: 4, Read: 4, ReadSeconds: 960},
+		{Type: models.NotificationTypePromotion, Channel: models.NotificationChannelEmail,
+			Notifications: 20, Sent: 20, Delivered: 12, Read: 0, DeliverySeconds: 1440},
+	}, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateNotificationDeliveryReport(suite.ctx, services.NotificationDeliveryReportRequest{
+		StartDate: "2025-03-01",
+		EndDate:   "2025-03-07",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(services.NotificationDeliveryStats{
+		Notifications: 35, Sent: 34, Delivered: 24, Read: 6,
+		DeliveryRate: 70.59, ReadRate: 25.00, AvgMinutesToDeliver: 1.33, AvgMinutesToRead: 22.67,
+	}, report.Totals)
+
+	suite.Require().Len(report.ByType, 2)
+	suite.Equal("order_shipped", report.ByType[0].Key)
+	suite.Equal(12, report.ByType[0].Delivered)
+	suite.Equal(50.00, report.ByType[0].ReadRate)
+	suite.Equal("promotion", report.ByType[1].Key)
+	suite.Equal(60.00, report.ByType[1].DeliveryRate)
+	suite.Equal(0.00, report.ByType[1].ReadRate)
+
+	suite.Require().Len(report.ByChannel, 2)
+	suite.Equal("email", report.ByChannel[0].Key)
+	suite.Equal(66.67, report.ByChannel[0].DeliveryRate)
+	suite.Equal("in_app", report.ByChannel[1].Key)
+	suite.Equal(4.00, report.ByChannel[1].AvgMinutesToRead)
+
+	suite.Require().Len(report.ByTypeAndChannel, 3)
+	suite.Equal("order_shipped", report.ByTypeAndChannel[0].Type)
+	suite.Equal("email", report.ByTypeAndChannel[0].Channel)
+	suite.Equal(80.00, report.ByTypeAndChannel[0].DeliveryRate)
+	suite.Equal(1.00, report.ByTypeAndChannel[0].AvgMinutesToDeliver)
+	suite.Equal(60.00, report.ByTypeAndChannel[0].AvgMinutesToRead)
+}
+
+// Test GenerateNotificationDeliveryReport - Invalid ranges are rejected before querying
+func (suite *ReportServiceTestSuite) TestGenerateNotificationDeliveryReport_InvalidRange() {
+	// Execute
+	report, err := suite.reportService.GenerateNotificationDeliveryReport(suite.ctx, services.NotificationDeliveryReportRequest{
+		StartDate: "2025-03-07",
+		EndDate:   "2025-03-01",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(report)
+	suite.Contains(err.Error(), "invalid date range")
+}
+
+// Test GenerateARAgingReport - Open invoices are bucketed by days past due per organization
+func (suite *ReportServiceTestSuite) TestGenerateARAgingReport_BucketsByDaysPastDue() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	invoices := []repository.OpenInvoice{
+		{OrderID: "order-1", OrganizationID: "org-2", OrganizationName: "Beta", Amount: 500, InvoiceDueAt: today.AddDate(0, 0, -120)},
+		{OrderID: "order-2", OrganizationID: "org-1", OrganizationName: "Acme", Amount: 100.10, InvoiceDueAt: today.AddDate(0, 0, -45)},
+		{OrderID: "order-3", OrganizationID: "org-1", OrganizationName: "Acme", Amount: 200.20, InvoiceDueAt: today.AddDate(0, 0, -1)},
+		{OrderID: "order-4", OrganizationID: "org-1", OrganizationName: "Acme", Amount: 50, InvoiceDueAt: today},
+		{OrderID: "order-5", OrganizationID: "org-2", OrganizationName: "Beta", Amount: 75, InvoiceDueAt: today.AddDate(0, 0, -61)},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("ListOpenInvoices", suite.ctx).Return(invoices, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateARAgingReport(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(today.Format("2006-01-02"), report.AsOf)
+	suite.Require().Len(report.Organizations, 2)
+
+	acme := report.Organizations[0]
+	suite.Equal("Acme", acme.OrganizationName) // Sorted by name
+	suite.Equal(3, acme.OpenInvoices)
+	suite.Equal(50.0, acme.Current)
+	suite.Equal(200.2, acme.Days1To30)
+	suite.Equal(100.1, acme.Days31To60)
+	suite.Equal(350.3, acme.Total)
+	suite.True(acme.OldestDueAt.Equal(today.AddDate(0, 0, -45)))
+
+	beta := report.Organizations[1]
+	suite.Equal(75.0, beta.Days61To90)
+	suite.Equal(500.0, beta.Over90)
+
+	suite.Equal(925.3, report.Totals.Total)
+	suite.Equal(500.0, report.Totals.Over90)
+}
+
+// Test GenerateARAgingReport - No open invoices yields an empty report
+func (suite *ReportServiceTestSuite) TestGenerateARAgingReport_NoOpenInvoices() {
+	// Mock expectations
+	suite.orderRepo.On("ListOpenInvoices", suite.ctx).Return([]repository.OpenInvoice{}, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateARAgingReport(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(report.Organizations)
+	suite.Zero(report.Totals.Total)
+}
+
+// Test GenerateCreditExposureReport - Accounts over their limit come first, then the most utilized
+func (suite *ReportServiceTestSuite) TestGenerateCreditExposureReport_RanksByUtilization() {
+	exposures := []repository.CreditExposure{
+		{OrganizationID: "org-a", OrganizationName: "Acme", CreditLimit: 1000, CreditLimitAction: models.CreditLimitActionReject, Outstanding: 250},
+		{OrganizationID: "org-b", OrganizationName: "Bolt", CreditLimit: 500, CreditLimitAction: models.CreditLimitActionHold,
+			Outstanding: 600, HeldOrders: 2, HeldAmount: 150.5},
+		{OrganizationID: "org-c", OrganizationName: "Cog", CreditLimit: 2000, CreditLimitAction: models.CreditLimitActionReject, Outstanding: 1500},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("ListCreditExposure", suite.ctx).Return(exposures, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateCreditExposureReport(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(report.Organizations, 3)
+	suite.Equal("org-b", report.Organizations[0].OrganizationID)
+	suite.True(report.Organizations[0].OverLimit)
+	suite.Equal(-100.0, report.Organizations[0].AvailableCredit)
+	suite.Equal(120.0, report.Organizations[0].UtilizationPercent)
+	suite.Equal("org-c", report.Organizations[1].OrganizationID)
+	suite.Equal(75.0, report.Organizations[1].UtilizationPercent)
+	suite.Equal("org-a", report.Organizations[2].OrganizationID)
+	suite.Equal(750.0, report.Organizations[2].AvailableCredit)
+
+	suite.Equal(3500.0, report.Totals.CreditLimit)
+	suite.Equal(2350.0, report.Totals.Outstanding)
+	suite.Equal(2, report.Totals.HeldOrders)
+	suite.Equal(150.5, report.Totals.HeldAmount)
+	suite.Equal(1, report.Totals.OverLimitAccounts)
+	suite.Equal(67.14, report.Totals.UtilizationPercent)
+}
+
+// Test GenerateCreditExposureReport - Any balance is over a zero credit limit
+func (suite *ReportServiceTestSuite) TestGenerateCreditExposureReport_NoCreditLimit() {
+	exposures := []repository.CreditExposure{
+		{OrganizationID: "org-a", OrganizationName: "Acme", Outstanding: 40},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("ListCreditExposure", suite.ctx).Return(exposures, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateCreditExposureReport(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Require().Len(report.Organizations, 1)
+	suite.True(report.Organizations[0].OverLimit)
+	suite.Zero(report.Organizations[0].UtilizationPercent)
+	suite.Equal(-40.0, report.Organizations[0].AvailableCredit)
+}
+
+// Test GenerateShippingSLAReport - Overdue orders count per store, most overdue store first
+func (suite *ReportServiceTestSuite) TestGenerateShippingSLAReport_OverdueByStore() {
+	now := time.Now()
+	promised := func(offset time.Duration) *time.Time {
+		t := now.Add(offset)
+		return &t
+	}
+	orders := []*models.Order{
+		{ID: "order-1", Channel: "shopify", Status: models.OrderStatusPaid, PromisedShipBy: promised(-30 * time.Hour)},
+		{ID: "order-2", Channel: "direct", Status: models.OrderStatusPending, PromisedShipBy: promised(-2 * time.Hour)},
+		{ID: "order-3", Channel: "shopify", Status: models.OrderStatusConfirmed, PromisedShipBy: promised(-90 * time.Minute)},
+		{ID: "order-4", Channel: "direct", Status: models.OrderStatusPaid, PromisedShipBy: promised(6 * time.Hour)},
+	}
+
+	// Mock expectations
+	suite.orderRepo.On("ListAwaitingShipment", suite.ctx, mock.MatchedBy(func(dueBy time.Time) bool {
+		return dueBy.Sub(now) >= 24*time.Hour && dueBy.Sub(now) < 25*time.Hour
+	})).Return(orders, nil)
+
+	// Execute
+	report, err := suite.reportService.GenerateShippingSLAReport(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(3, report.Totals.Overdue)
+	suite.Equal(1, report.Totals.DueSoon)
+
+	suite.Require().Len(report.Stores, 2)
+	suite.Equal("shopify", report.Stores[0].Channel)
+	suite.Equal(2, report.Stores[0].Overdue)
+	suite.InDelta(30.0, report.Stores[0].OldestOverdueHours, 0.1)
+	suite.Equal("direct", report.Stores[1].Channel)
+	suite.Equal(1, report.Stores[1].Overdue)
+	suite.Equal(1, report.Stores[1].DueSoon)
+
+	suite.Require().Len(report.Orders, 4)
+	suite.True(report.Orders[1].Overdue)
+	suite.InDelta(2.0, report.Orders[1].HoursOverdue, 0.1)
+	suite.False(report.Orders[3].Overdue)
+	suite.Zero(report.Orders[3].HoursOverdue)
+}
+
+// Run the test suite
+func TestReportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportServiceTestSuite))
+}
@@ -0,0 +1,209 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// RetentionServiceTestSuite defines the test suite for RetentionService
+type RetentionServiceTestSuite struct {
+	suite.Suite
+	notificationRepo *mocks.MockNotificationRepository
+	orderRepo        *mocks.MockOrderRepository
+	webhookEventRepo *mocks.MockWebhookEventRepository
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *RetentionServiceTestSuite) SetupTest() {
+	suite.notificationRepo = new(mocks.MockNotificationRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.webhookEventRepo = new(mocks.MockWebhookEventRepository)
+	suite.ctx = context.Background()
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *RetentionServiceTestSuite) TearDownTest() {
+	suite.notificationRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.webhookEventRepo.AssertExpectations(suite.T())
+}
+
+// newService builds the service under test. Report artifacts are left disabled so the
+// tests do not touch files.
+func (suite *RetentionServiceTestSuite) newService(settings services.RetentionSettings) services.RetentionService {
+	return services.NewRetentionService(
+		settings,
+		suite.notificationRepo,
+		suite.orderRepo,
+		suite.webhookEventRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// cutoffAround matches a cutoff the given retention before now
+func cutoffAround(retention time.Duration) interface{} {
+	return mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().Add(-retention)
+		return cutoff.After(expected.Add(-time.Minute)) && !cutoff.After(expected)
+	})
+}
+
+// Test Purge - Every enabled policy is purged, in batches until a batch comes back short
+func (suite *RetentionServiceTestSuite) TestPurge_DeletesInBatches() {
+	service := suite.newService(services.RetentionSettings{
+		Notifications:   180 * 24 * time.Hour,
+		IdempotencyKeys: 30 * 24 * time.Hour,
+		WebhookEvents:   90 * 24 * time.Hour,
+	})
+
+	suite.notificationRepo.On("DeleteCreatedBefore", suite.ctx, cutoffAround(180*24*time.Hour), 1000).Return(int64(1000), nil).Once()
+	suite.notificationRepo.On("DeleteCreatedBefore", suite.ctx, cutoffAround(180*24*time.Hour), 1000).Return(int64(250), nil).Once()
+	suite.orderRepo.On("ClearIdempotencyKeysBefore", suite.ctx, cutoffAround(30*24*time.Hour), 1000).Return(int64(12), nil).Once()
+	suite.webhookEventRepo.On("DeleteCreatedBefore", suite.ctx, cutoffAround(90*24*time.Hour), 1000).Return(int64(0), nil).Once()
+
+	response, err := service.Purge(suite.ctx, false)
+
+	suite.NoError(err)
+	suite.False(response.DryRun)
+	suite.Require().Len(response.Results, 3)
+	suite.Equal(services.RetentionPolicyNotifications, response.Results[0].Policy)
+	suite.Equal(int64(1250), response.Results[0].Records)
+	suite.Equal(services.RetentionPolicyIdempotencyKeys, response.Results[1].Policy)
+	suite.Equal(int64(12), response.Results[1].Records)
+	suite.Equal(services.RetentionPolicyWebhookEvents, response.Results[2].Policy)
+	suite.Equal(int64(0), response.Results[2].Records)
+}
+
+// Test Purge - A dry run only counts what each policy would purge
+func (suite *RetentionServiceTestSuite) TestPurge_DryRunCounts() {
+	service := suite.newService(services.RetentionSettings{
+		Notifications: 180 * 24 * time.Hour,
+		WebhookEvents: 90 * 24 * time.Hour,
+	})
+
+	suite.notificationRepo.On("CountCreatedBefore", suite.ctx, cutoffAround(180*24*time.Hour)).Return(int64(40), nil).Once()
+	suite.webhookEventRepo.On("CountCreatedBefore", suite.ctx, cutoffAround(90*24*time.Hour)).Return(int64(7), nil).Once()
+
+	response, err := service.Purge(suite.ctx, true)
+
+	suite.NoError(err)
+	suite.True(response.DryRun)
+	suite.Require().Len(response.Results, 2)
+	suite.Equal(int64(40), response.Results[0].Records)
+	suite.Equal(int64(7), response.Results[1].Records)
+
+	// Dry runs are recorded but not counted as purged
+	metrics := service.GetMetrics()
+	suite.Equal(int64(1), metrics[0].Runs)
+	suite.True(metrics[0].LastDryRun)
+	suite.Equal(int64(40), metrics[0].LastRecords)
+	suite.Equal(int64(0), metrics[0].PurgedTotal)
+}
+
+// Test Purge - Configured dry-run mode overrides a request to delete
+func (suite *RetentionServiceTestSuite) TestPurge_ConfiguredDryRun() {
+	service := suite.newService(services.RetentionSettings{
+		IdempotencyKeys: 30 * 24 * time.Hour,
+		DryRun:          true,
+	})
+
+	suite.orderRepo.On("CountIdempotencyKeysBefore", suite.ctx, cutoffAround(30*24*time.Hour)).Return(int64(3), nil).Once()
+
+	response, err := service.Purge(suite.ctx, false)
+
+	suite.NoError(err)
+	suite.True(response.DryRun)
+	suite.Require().Len(response.Results, 1)
+	suite.Equal(services.RetentionPolicyIdempotencyKeys, response.Results[0].Policy)
+	suite.Equal(int64(3), response.Results[0].Records)
+}
+
+// Test Purge - A failing policy is reported without stopping the others
+func (suite *RetentionServiceTestSuite) TestPurge_PolicyFailure() {
+	service := suite.newService(services.RetentionSettings{
+		Notifications: 180 * 24 * time.Hour,
+		WebhookEvents: 90 * 24 * time.Hour,
+	})
+
+	suite.notificationRepo.On("DeleteCreatedBefore", suite.ctx, mock.Anything, 1000).Return(int64(0), errors.New("connection reset")).Once()
+	suite.webhookEventRepo.On("DeleteCreatedBefore", suite.ctx, mock.Anything, 1000).Return(int64(5), nil).Once()
+
+	response, err := service.Purge(suite.ctx, false)
+
+	suite.NoError(err)
+	suite.Require().Len(response.Results, 2)
+	suite.Equal("connection reset", response.Results[0].Error)
+	suite.Equal(int64(5), response.Results[1].Records)
+
+	metrics := service.GetMetrics()
+	suite.Equal(int64(1), metrics[0].Failures)
+	suite.Equal("connection reset", metrics[0].LastError)
+	suite.Equal(int64(0), metrics[2].Failures)
+	suite.Equal(int64(5), metrics[2].PurgedTotal)
+}
+
+// Test Purge - An error is returned when every enabled policy fails
+func (suite *RetentionServiceTestSuite) TestPurge_AllPoliciesFail() {
+	service := suite.newService(services.RetentionSettings{
+		Notifications: 180 * 24 * time.Hour,
+	})
+
+	suite.notificationRepo.On("DeleteCreatedBefore", suite.ctx, mock.Anything, 1000).Return(int64(0), errors.New("connection reset")).Once()
+
+	response, err := service.Purge(suite.ctx, false)
+
+	suite.Error(err)
+	suite.Require().NotNil(response)
+	suite.Len(response.Results, 1)
+}
+
+// Test GetMetrics - Every policy is listed with its retention, including disabled ones
+func (suite *RetentionServiceTestSuite) TestGetMetrics_ListsPolicies() {
+	service := suite.newService(services.RetentionSettings{
+		Notifications:   180 * 24 * time.Hour,
+		IdempotencyKeys: 30 * 24 * time.Hour,
+	})
+
+	metrics := service.GetMetrics()
+
+	suite.Require().Len(metrics, 4)
+	suite.Equal(services.RetentionPolicyNotifications, metrics[0].Policy)
+	suite.Equal(180, metrics[0].RetentionDays)
+	suite.True(metrics[0].Enabled)
+	suite.Equal(30, metrics[1].RetentionDays)
+	suite.False(metrics[2].Enabled)
+	suite.Equal(services.RetentionPolicyReportArtifacts, metrics[3].Policy)
+	suite.Nil(metrics[3].LastRunAt)
+}
+
+// TestRetentionServiceTestSuite runs the test suite
+func TestRetentionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RetentionServiceTestSuite))
+}
+
+// Test Purge - Nothing is purged while every policy is disabled
+func TestRetentionService_AllPoliciesDisabled(t *testing.T) {
+	service := services.NewRetentionService(
+		services.RetentionSettings{},
+		new(mocks.MockNotificationRepository),
+		new(mocks.MockOrderRepository),
+		new(mocks.MockWebhookEventRepository),
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+
+	response, err := service.Purge(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Empty(t, response.Results)
+}
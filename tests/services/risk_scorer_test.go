@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// RiskScorerTestSuite defines the test suite for RiskScorer
+type RiskScorerTestSuite struct {
+	suite.Suite
+	orderRepo   *mocks.MockOrderRepository
+	userRepo    *mocks.MockUserRepository
+	paymentRepo *mocks.MockPaymentRepository
+	addressRepo *mocks.MockAddressRepository
+	scorer      *services.RiskScorer
+	ctx         context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *RiskScorerTestSuite) SetupTest() {
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.addressRepo = new(mocks.MockAddressRepository)
+	suite.ctx = context.Background()
+
+	suite.scorer = services.NewRiskScorer(
+		suite.orderRepo,
+		suite.userRepo,
+		suite.paymentRepo,
+		suite.addressRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *RiskScorerTestSuite) TearDownTest() {
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.addressRepo.AssertExpectations(suite.T())
+}
+
+// riskOrder returns an order placed now by user-1 shipping to the country
+func riskOrder(total float64, country string, items ...models.OrderItem) *models.Order {
+	return &models.Order{
+		ID:              "order-1",
+		UserID:          "user-1",
+		TotalAmount:     total,
+		Items:           items,
+		ShippingAddress: &models.ShippingAddress{Country: country},
+		CreatedAt:       time.Now(),
+	}
+}
+
+// Test Score - Signals add up to the score, which is saved with them
+func (suite *RiskScorerTestSuite) TestScore_AddsUpSignals() {
+	order := riskOrder(1200, "FR", models.OrderItem{ProductID: "product-1", Quantity: 12})
+
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1", CreatedAt: time.Now().Add(-time.Hour)}, nil)
+	suite.orderRepo.On("CountByUserSince", suite.ctx, "user-1", mock.AnythingOfType("time.Time")).Return(int64(4), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{
+		{Status: models.PaymentStatusFailed},
+		{Status: models.PaymentStatusCompleted},
+	}, nil)
+	suite.addressRepo.On("ListByUserID", suite.ctx, "user-1").Return([]*models.Address{
+		{ShippingAddress: models.ShippingAddress{Country: "US"}},
+	}, nil)
+	suite.orderRepo.On("UpdateRiskScore", suite.ctx, order).Return(nil)
+
+	// Execute
+	err := suite.scorer.Score(suite.ctx, order)
+
+	// Assert: 25 new account + 25 high value + 15 velocity + 10 bulk + 10 failed payment + 15 new country
+	suite.NoError(err)
+	suite.Require().NotNil(order.RiskScore)
+	suite.Equal(100, *order.RiskScore)
+	suite.Equal(models.RiskBandHigh, models.RiskBandOf(*order.RiskScore))
+	suite.Len(order.RiskSignals, 6)
+	suite.NotNil(order.RiskScoredAt)
+}
+
+// Test Score - An established customer's usual order scores low
+func (suite *RiskScorerTestSuite) TestScore_LowRisk() {
+	order := riskOrder(80, "US", models.OrderItem{ProductID: "product-1", Quantity: 2})
+
+	suite.userRepo.On("GetByID", suite.ctx, "user-1").Return(&models.User{ID: "user-1", CreatedAt: time.Now().AddDate(-1, 0, 0)}, nil)
+	suite.orderRepo.On("CountByUserSince", suite.ctx, "user-1", mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+	suite.paymentRepo.On("GetByOrderID", suite.ctx, "order-1").Return([]*models.Payment{}, nil)
+	suite.addressRepo.On("ListByUserID", suite.ctx, "user-1").Return([]*models.Address{
+		{ShippingAddress: models.ShippingAddress{Country: "us"}},
+	}, nil)
+	suite.orderRepo.On("UpdateRiskScore", suite.ctx, order).Return(nil)
+
+	// Execute
+	err := suite.scorer.Score(suite.ctx, order)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(0, *order.RiskScore)
+	suite.Empty(order.RiskSignals)
+}
+
+// Test RiskBand - Bands cover every score without overlapping
+func (suite *RiskScorerTestSuite) TestRiskBand_ScoreRanges() {
+	for _, band := range []models.RiskBand{models.RiskBandLow, models.RiskBandMedium, models.RiskBandHigh} {
+		min, max, ok := band.ScoreRange()
+		suite.True(ok)
+		suite.Equal(band, models.RiskBandOf(min))
+		suite.Equal(band, models.RiskBandOf(max))
+	}
+
+	_, _, ok := models.RiskBand("extreme").ScoreRange()
+	suite.False(ok)
+}
+
+// TestRiskScorerTestSuite runs the test suite
+func TestRiskScorerTestSuite(t *testing.T) {
+	suite.Run(t, new(RiskScorerTestSuite))
+}
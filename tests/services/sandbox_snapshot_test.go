@@ -0,0 +1,223 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// SandboxSnapshotServiceTestSuite defines the test suite for SandboxSnapshotService
+type SandboxSnapshotServiceTestSuite struct {
+	suite.Suite
+	snapshotService  services.SandboxSnapshotService
+	userRepo         *mocks.MockUserRepository
+	organizationRepo *mocks.MockOrganizationRepository
+	productRepo      *mocks.MockProductRepository
+	orderRepo        *mocks.MockOrderRepository
+	ctx              context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *SandboxSnapshotServiceTestSuite) SetupTest() {
+	suite.userRepo = new(mocks.MockUserRepository)
+	suite.organizationRepo = new(mocks.MockOrganizationRepository)
+	suite.productRepo = new(mocks.MockProductRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.ctx = context.Background()
+
+	suite.snapshotService = services.NewSandboxSnapshotService(
+		suite.userRepo,
+		suite.organizationRepo,
+		suite.productRepo,
+		suite.orderRepo,
+		&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()},
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *SandboxSnapshotServiceTestSuite) TearDownTest() {
+	suite.userRepo.AssertExpectations(suite.T())
+	suite.organizationRepo.AssertExpectations(suite.T())
+	suite.productRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+}
+
+// expectStore sets up a small store for one export: an organization, two users, two
+// products and one order of both with a payment. Users are listed twice, once for the
+// name pools. Each export gets fresh records, as it anonymizes them in place.
+func (suite *SandboxSnapshotServiceTestSuite) expectStore(filter repository.OrderFilter) {
+	organizationID := "org-1"
+	users := func() []*models.User {
+		return []*models.User{
+			{ID: "user-1", Email: "alice.smith@acme.com", Name: "Alice Smith", Password: "hash-1", OrganizationID: &organizationID},
+			{ID: "user-2", Email: "bob@example.org", Name: "Bob Jones", Password: "hash-2"},
+		}
+	}
+
+	suite.userRepo.On("ListAfterID", suite.ctx, "", mock.AnythingOfType("int")).Return(users(), nil).Once()
+	suite.organizationRepo.On("ListAfterID", suite.ctx, "", mock.AnythingOfType("int")).
+		Return([]*models.Organization{{ID: organizationID, Name: "Acme Trading", CreditLimit: 5000}}, nil).Once()
+	suite.userRepo.On("ListAfterID", suite.ctx, "", mock.AnythingOfType("int")).Return(users(), nil).Once()
+	suite.productRepo.On("ListAfterID", suite.ctx, "", mock.AnythingOfType("int")).Return([]*models.Product{
+		{ID: "product-1", Name: "Widget", Price: 20, CostPrice: 12, Inventory: &models.Inventory{ProductID: "product-1", Quantity: 40, Available: 40}},
+		{ID: "product-2", Name: "Gadget", Price: 5, CostPrice: 2},
+	}, nil).Once()
+	suite.orderRepo.On("Iterate", filter, mock.AnythingOfType("int")).Return(mocks.NewOrderSliceIterator([]*models.Order{{
+		ID:              "order-1",
+		UserID:          "user-1",
+		TotalAmount:     45,
+		Notes:           "Leave with the neighbour at 12 Elm Street",
+		ExternalOrderID: "SHOP-1001",
+		Metadata:        models.Metadata{"customer_ref": "A-17"},
+		ShippingAddress: &models.ShippingAddress{
+			Recipient: "Alice Smith", Line1: "12 Elm Street", City: "Springfield", PostalCode: "12345", Country: "US", Phone: "+15551234567",
+		},
+		Items: []models.OrderItem{
+			{ID: "item-1", OrderID: "order-1", ProductID: "product-1", Quantity: 2, UnitPrice: 20, TotalPrice: 40, Product: &models.Product{ID: "product-1"}},
+			{ID: "item-2", OrderID: "order-1", ProductID: "product-2", Quantity: 1, UnitPrice: 5, TotalPrice: 5},
+		},
+		Payments: []models.Payment{
+			{ID: "payment-1", OrderID: "order-1", Amount: 45, ProcessingFee: 1.5, TransactionID: "txn_live_1", IdempotencyKey: "key-1"},
+		},
+	}})).Once()
+}
+
+// export runs an export and returns its records
+func (suite *SandboxSnapshotServiceTestSuite) export(req services.SandboxSnapshotRequest) []*services.SandboxSnapshotRecord {
+	var records []*services.SandboxSnapshotRecord
+	err := suite.snapshotService.Export(suite.ctx, req, func(record *services.SandboxSnapshotRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	suite.Require().NoError(err)
+	return records
+}
+
+// Test Export - Records come in load order with personal data replaced and references kept
+func (suite *SandboxSnapshotServiceTestSuite) TestExport_AnonymizesInLoadOrder() {
+	suite.expectStore(repository.OrderFilter{WithPayments: true})
+
+	// Execute
+	records := suite.export(services.SandboxSnapshotRequest{Seed: "staging"})
+
+	// Assert
+	var tables []string
+	for _, record := range records {
+		tables = append(tables, record.Table)
+	}
+	suite.Equal([]string{"organizations", "users", "users", "products", "inventory", "products", "orders", "order_items", "order_items", "payments"}, tables)
+
+	organization := records[0].Record.(*models.Organization)
+	suite.NotContains(organization.Name, "Acme")
+
+	for _, record := range records[1:3] {
+		user := record.Record.(*models.User)
+		suite.True(strings.HasPrefix(user.Email, "user-") && strings.HasSuffix(user.Email, "@example.com"), user.Email)
+		suite.Empty(user.Password)
+
+		names := strings.Fields(user.Name)
+		suite.Require().Len(names, 2)
+		suite.Contains([]string{"Alice", "Bob"}, names[0])
+		suite.Contains([]string{"Smith", "Jones"}, names[1])
+	}
+	user := records[1].Record.(*models.User)
+	suite.Equal("user-1", user.ID)
+	suite.Equal("org-1", *user.OrganizationID)
+
+	order := records[6].Record.(*models.Order)
+	suite.Equal("order-1", order.ID)
+	suite.Equal("user-1", order.UserID)
+	suite.Empty(order.Notes)
+	suite.Empty(order.Metadata)
+	suite.NotEqual("SHOP-1001", order.ExternalOrderID)
+	suite.Nil(order.Items)
+	suite.Nil(order.Payments)
+
+	address := order.ShippingAddress
+	suite.Equal(user.Name, address.Recipient)
+	suite.NotEqual("12 Elm Street", address.Line1)
+	suite.NotEqual("12345", address.PostalCode)
+	suite.NotEqual("+15551234567", address.Phone)
+	suite.Equal("Springfield", address.City)
+	suite.Equal("US", address.Country)
+
+	payment := records[9].Record.(*models.Payment)
+	suite.Equal("order-1", payment.OrderID)
+	suite.NotEqual("txn_live_1", payment.TransactionID)
+	suite.NotEqual("key-1", payment.IdempotencyKey)
+}
+
+// Test Export - Jittered prices stay within the jitter, and orders and payments still add up
+func (suite *SandboxSnapshotServiceTestSuite) TestExport_JittersAmountsConsistently() {
+	suite.expectStore(repository.OrderFilter{WithPayments: true})
+
+	// Execute
+	records := suite.export(services.SandboxSnapshotRequest{Seed: "staging", JitterPercent: 20})
+
+	// Assert
+	widget := records[3].Record.(*models.Product)
+	suite.InDelta(20, widget.Price, 4.01)
+	suite.InDelta(widget.CostPrice/widget.Price, 0.6, 0.01)
+
+	var subtotal float64
+	for _, record := range records[7:9] {
+		item := record.Record.(*models.OrderItem)
+		suite.InDelta(item.UnitPrice*float64(item.Quantity), item.TotalPrice, 0.001)
+		subtotal += item.TotalPrice
+	}
+	item := records[7].Record.(*models.OrderItem)
+	suite.Equal(widget.Price, item.UnitPrice)
+	suite.Nil(item.Product)
+
+	order := records[6].Record.(*models.Order)
+	suite.InDelta(subtotal, order.TotalAmount, 0.011)
+	payment := records[9].Record.(*models.Payment)
+	suite.Equal(order.TotalAmount, payment.Amount)
+	suite.InDelta(1.5*order.TotalAmount/45, payment.ProcessingFee, 0.011)
+}
+
+// Test Export - Snapshots with the same seed are identical, and differ from those of another seed
+func (suite *SandboxSnapshotServiceTestSuite) TestExport_SeedMakesSnapshotsRepeatable() {
+	filter := repository.OrderFilter{WithPayments: true}
+
+	suite.expectStore(filter)
+	first := suite.export(services.SandboxSnapshotRequest{Seed: "staging"})
+	suite.expectStore(filter)
+	second := suite.export(services.SandboxSnapshotRequest{Seed: "staging"})
+	suite.expectStore(filter)
+	other := suite.export(services.SandboxSnapshotRequest{Seed: "load-test"})
+
+	suite.Equal(first, second)
+	suite.NotEqual(first[1].Record.(*models.User).Email, other[1].Record.(*models.User).Email)
+	suite.NotEqual(first[3].Record.(*models.Product).Price, other[3].Record.(*models.Product).Price)
+}
+
+// Test Export - Only orders placed since the given date are exported, and a malformed date is rejected
+func (suite *SandboxSnapshotServiceTestSuite) TestExport_OrdersSince() {
+	since := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	suite.expectStore(repository.OrderFilter{CreatedFrom: since, WithPayments: true})
+
+	records := suite.export(services.SandboxSnapshotRequest{OrdersSince: "2025-03-01"})
+	suite.Len(records, 10)
+
+	err := suite.snapshotService.Export(suite.ctx, services.SandboxSnapshotRequest{OrdersSince: "March"}, func(*services.SandboxSnapshotRecord) error {
+		return nil
+	})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+}
+
+// TestSandboxSnapshotServiceTestSuite runs the test suite
+func TestSandboxSnapshotServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SandboxSnapshotServiceTestSuite))
+}
@@ -0,0 +1,309 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// enqueuedJobStore is a workers.JobStore recording the jobs enqueued, which never
+// leases them
+type enqueuedJobStore struct {
+	mu   sync.Mutex
+	jobs []*workers.StoredJob
+}
+
+func (s *enqueuedJobStore) Enqueue(ctx context.Context, job *workers.StoredJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+func (s *enqueuedJobStore) Lease(ctx context.Context, pool, owner string, limit int, visibility time.Duration) ([]*workers.StoredJob, error) {
+	return nil, nil
+}
+
+func (s *enqueuedJobStore) ExtendLease(ctx context.Context, id, owner string, visibility time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (s *enqueuedJobStore) Release(ctx context.Context, id, owner string) error { return nil }
+
+func (s *enqueuedJobStore) Complete(ctx context.Context, id, owner string) error { return nil }
+
+func (s *enqueuedJobStore) Retry(ctx context.Context, id, owner string, runAt time.Time, lastError string) error {
+	return nil
+}
+
+func (s *enqueuedJobStore) Fail(ctx context.Context, id, owner string, lastError string) error {
+	return nil
+}
+
+// recordingStockNotifier is a services.StockChangeNotifier recording the changes
+type recordingStockNotifier struct {
+	reasons    []services.StockChangeReason
+	productIDs [][]string
+}
+
+func (n *recordingStockNotifier) NotifyStockChanges(ctx context.Context, reason services.StockChangeReason, productIDs []string) {
+	n.reasons = append(n.reasons, reason)
+	n.productIDs = append(n.productIDs, productIDs)
+}
+
+// StockImportServiceTestSuite defines the test suite for StockImportService
+type StockImportServiceTestSuite struct {
+	suite.Suite
+	importService services.StockImportService
+	importRepo    *mocks.MockStockImportRepository
+	jobStore      *enqueuedJobStore
+	notifier      *recordingStockNotifier
+	log           *logger.Logger
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *StockImportServiceTestSuite) SetupTest() {
+	suite.importRepo = new(mocks.MockStockImportRepository)
+	suite.jobStore = &enqueuedJobStore{}
+	suite.notifier = &recordingStockNotifier{}
+	suite.log = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	// The pools are not started, so queued chunks stay in the job store
+	poolManager := workers.NewPoolManager(suite.log)
+	poolManager.SetJobStore(suite.jobStore, workers.DurableQueueConfig{})
+	suite.Require().NoError(poolManager.InitializeDefaultPools())
+	poolManager.RegisterJobType(workers.JobTypeStockImport, func(payload []byte) (workers.Job, error) {
+		return workers.DecodeJob(payload, workers.NewStockImportJob("", 0, 0, nil))
+	})
+
+	suite.importService = services.NewStockImportService(suite.importRepo, poolManager, suite.notifier, suite.log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *StockImportServiceTestSuite) TearDownTest() {
+	suite.importRepo.AssertExpectations(suite.T())
+}
+
+// expectCreate saves the import under an ID and captures it with its rows
+func (suite *StockImportServiceTestSuite) expectCreate(saved **models.StockImport, rows *[]*models.StockImportRow) {
+	suite.importRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.StockImport"), mock.AnythingOfType("[]*models.StockImportRow")).
+		Run(func(args mock.Arguments) {
+			stockImport := args.Get(1).(*models.StockImport)
+			stockImport.ID = "import-1"
+			*saved = stockImport
+			*rows = args.Get(2).([]*models.StockImportRow)
+		}).
+		Return(nil)
+}
+
+// queuedChunks decodes the chunk jobs enqueued on the bulk pool
+func (suite *StockImportServiceTestSuite) queuedChunks() []*workers.StockImportJob {
+	chunks := make([]*workers.StockImportJob, 0, len(suite.jobStore.jobs))
+	for _, stored := range suite.jobStore.jobs {
+		suite.Equal(workers.JobTypeStockImport, stored.Type)
+		suite.Equal("bulk", stored.Pool)
+		var job workers.StockImportJob
+		suite.Require().NoError(json.Unmarshal(stored.Payload, &job))
+		chunks = append(chunks, &job)
+	}
+	return chunks
+}
+
+// Test ImportStock - Valid rows are queued and invalid ones rejected right away
+func (suite *StockImportServiceTestSuite) TestImportStock_QueuesRows() {
+	var saved *models.StockImport
+	var rows []*models.StockImportRow
+	suite.expectCreate(&saved, &rows)
+
+	csv := "sku,quantity\nSKU-A,40\nSKU-B,-3\nSKU-A,5\nSKU-C,abc\nSKU-D,0\n,4\n"
+
+	// Execute
+	stockImport, err := suite.importService.ImportStock(suite.ctx, strings.NewReader(csv), "delivery.csv", services.AuditActor{UserID: "admin-1"})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Same(saved, stockImport)
+	suite.Equal("delivery.csv", stockImport.Filename)
+	suite.Equal("admin-1", stockImport.CreatedBy)
+	suite.Equal(models.StockImportStatusProcessing, stockImport.Status)
+	suite.Equal(6, stockImport.TotalRows)
+	suite.Equal(3, stockImport.Rejected)
+	suite.Equal(1, stockImport.Chunks)
+
+	suite.Require().Len(rows, 6)
+	suite.Equal(2, rows[0].Row)
+	suite.Equal(40, rows[0].Quantity)
+	suite.Equal(-3, rows[1].Quantity)
+	suite.Equal(models.StockImportRowPending, rows[2].Status)
+	suite.Equal(models.StockImportRowRejected, rows[3].Status)
+	suite.Equal("quantity must be a non-zero integer", rows[3].Error)
+	suite.Equal("quantity must be a non-zero integer", rows[4].Error)
+	suite.Equal("sku is required", rows[5].Error)
+
+	chunks := suite.queuedChunks()
+	suite.Require().Len(chunks, 1)
+	suite.Equal("import-1", chunks[0].ImportID)
+	suite.Equal(2, chunks[0].FirstRow)
+	suite.Equal(4, chunks[0].LastRow)
+}
+
+// Test ImportStock - Large files are queued in chunks of rows
+func (suite *StockImportServiceTestSuite) TestImportStock_Chunks() {
+	var saved *models.StockImport
+	var rows []*models.StockImportRow
+	suite.expectCreate(&saved, &rows)
+
+	var csv strings.Builder
+	for i := 1; i <= 1001; i++ {
+		fmt.Fprintf(&csv, "SKU-%d,%d\n", i, i)
+	}
+
+	// Execute
+	stockImport, err := suite.importService.ImportStock(suite.ctx, strings.NewReader(csv.String()), "", services.AuditActor{UserID: "admin-1"})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(1001, stockImport.TotalRows)
+	suite.Equal(3, stockImport.Chunks)
+
+	chunks := suite.queuedChunks()
+	suite.Require().Len(chunks, 3)
+	ranges := make([][2]int, len(chunks))
+	for i, chunk := range chunks {
+		ranges[i] = [2]int{chunk.FirstRow, chunk.LastRow}
+	}
+	suite.Equal([][2]int{{1, 500}, {501, 1000}, {1001, 1001}}, ranges)
+}
+
+// Test ImportStock - An import of rejected rows only is completed without being queued
+func (suite *StockImportServiceTestSuite) TestImportStock_AllRowsRejected() {
+	var saved *models.StockImport
+	var rows []*models.StockImportRow
+	suite.expectCreate(&saved, &rows)
+
+	// Execute
+	stockImport, err := suite.importService.ImportStock(suite.ctx, strings.NewReader("SKU-A,zero\nSKU-B\n"), "", services.AuditActor{UserID: "admin-1"})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.StockImportStatusCompleted, stockImport.Status)
+	suite.NotNil(stockImport.CompletedAt)
+	suite.Equal(2, stockImport.Rejected)
+	suite.Zero(stockImport.Chunks)
+	suite.Empty(suite.jobStore.jobs)
+}
+
+// Test ImportStock - Empty file
+func (suite *StockImportServiceTestSuite) TestImportStock_EmptyFile() {
+	// Execute
+	stockImport, err := suite.importService.ImportStock(suite.ctx, strings.NewReader("sku,quantity\n"), "", services.AuditActor{UserID: "admin-1"})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(stockImport)
+	suite.Contains(err.Error(), "VALIDATION_ERROR")
+	suite.importRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ImportStock - An import whose chunks cannot be queued is failed
+func (suite *StockImportServiceTestSuite) TestImportStock_QueueFailure() {
+	// A pool manager without pools cannot queue the chunks
+	suite.importService = services.NewStockImportService(suite.importRepo, workers.NewPoolManager(suite.log), suite.notifier, suite.log)
+
+	var saved *models.StockImport
+	var rows []*models.StockImportRow
+	suite.expectCreate(&saved, &rows)
+	suite.importRepo.On("Fail", suite.ctx, "import-1", mock.AnythingOfType("string")).Return(nil)
+
+	// Execute
+	stockImport, err := suite.importService.ImportStock(suite.ctx, strings.NewReader("SKU-A,10\n"), "", services.AuditActor{UserID: "admin-1"})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(stockImport)
+	suite.Contains(err.Error(), "failed to queue stock import")
+}
+
+// Test ApplyStockImportChunk - Adjusted products are announced as stock changes
+func (suite *StockImportServiceTestSuite) TestApplyStockImportChunk_NotifiesStockChanges() {
+	suite.importRepo.On("ApplyChunk", suite.ctx, "import-1", 1, 500).Return([]string{"product-1", "product-2"}, nil)
+
+	// Execute
+	err := suite.importService.ApplyStockImportChunk(suite.ctx, "import-1", 1, 500)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal([]services.StockChangeReason{services.StockChangeReasonAdjustment}, suite.notifier.reasons)
+	suite.Equal([][]string{{"product-1", "product-2"}}, suite.notifier.productIDs)
+}
+
+// Test ApplyStockImportChunk - A failed chunk is returned to the worker pool to retry
+func (suite *StockImportServiceTestSuite) TestApplyStockImportChunk_Failure() {
+	suite.importRepo.On("ApplyChunk", suite.ctx, "import-1", 1, 500).Return(nil, errors.New("deadlock detected"))
+
+	// Execute
+	err := suite.importService.ApplyStockImportChunk(suite.ctx, "import-1", 1, 500)
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "deadlock detected")
+	suite.Empty(suite.notifier.reasons)
+}
+
+// Test GetImport - Rows are paged, of the status asked for
+func (suite *StockImportServiceTestSuite) TestGetImport_PagesRows() {
+	stockImport := &models.StockImport{ID: "import-1", TotalRows: 30, Accepted: 18, Rejected: 12, Status: models.StockImportStatusCompleted}
+	rejected := []*models.StockImportRow{{ImportID: "import-1", Row: 21, SKU: "SKU-X", Quantity: 5, Status: models.StockImportRowRejected, Error: "product not found"}}
+	suite.importRepo.On("GetByID", suite.ctx, "import-1").Return(stockImport, nil)
+	suite.importRepo.On("ListRows", suite.ctx, "import-1", models.StockImportRowRejected, 10, 10).Return(rejected, nil)
+	suite.importRepo.On("CountRows", suite.ctx, "import-1", models.StockImportRowRejected).Return(int64(12), nil)
+
+	// Execute
+	response, err := suite.importService.GetImport(suite.ctx, "import-1", services.StockImportRowsRequest{
+		Status: "rejected",
+		Page:   2,
+		Limit:  10,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Same(stockImport, response.Import)
+	suite.Equal(rejected, response.Rows)
+	suite.Equal(2, response.Page)
+	suite.Equal(10, response.Limit)
+	suite.Equal(12, response.Total)
+}
+
+// Test GetImport - Unknown import
+func (suite *StockImportServiceTestSuite) TestGetImport_NotFound() {
+	suite.importRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+
+	// Execute
+	response, err := suite.importService.GetImport(suite.ctx, "missing", services.StockImportRowsRequest{})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(response)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// TestStockImportServiceTestSuite runs the test suite
+func TestStockImportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(StockImportServiceTestSuite))
+}
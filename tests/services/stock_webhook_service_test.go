@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// StockWebhookServiceTestSuite defines the test suite for StockWebhookService
+type StockWebhookServiceTestSuite struct {
+	suite.Suite
+	stockWebhookService services.StockWebhookService
+	subscriptionRepo    *mocks.MockStockWebhookSubscriptionRepository
+	inventoryRepo       *mocks.MockInventoryRepository
+	logger              *logger.Logger
+	ctx                 context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *StockWebhookServiceTestSuite) SetupTest() {
+	suite.subscriptionRepo = new(mocks.MockStockWebhookSubscriptionRepository)
+	suite.inventoryRepo = new(mocks.MockInventoryRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.stockWebhookService = services.NewStockWebhookService(
+		suite.subscriptionRepo,
+		suite.inventoryRepo,
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *StockWebhookServiceTestSuite) TearDownTest() {
+	suite.subscriptionRepo.AssertExpectations(suite.T())
+	suite.inventoryRepo.AssertExpectations(suite.T())
+}
+
+// Test CreateSubscription - Successful creation with a product filter
+func (suite *StockWebhookServiceTestSuite) TestCreateSubscription_Success() {
+	req := services.CreateStockWebhookSubscriptionRequest{
+		Name:       "Amazon connector",
+		URL:        "https://connector.example.com/stock",
+		Secret:     "0123456789abcdef",
+		ProductIDs: []string{"product-1"},
+	}
+
+	// Mock expectations
+	suite.subscriptionRepo.On("Create", suite.ctx, mock.MatchedBy(func(s *models.StockWebhookSubscription) bool {
+		return s.URL == req.URL && s.IsActive && s.ProductIDs.Contains("product-1")
+	})).Return(nil)
+
+	// Execute
+	response, err := suite.stockWebhookService.CreateSubscription(suite.ctx, req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"product-1"}, response.ProductIDs)
+	assert.True(suite.T(), response.IsActive)
+}
+
+// Test DeleteSubscription - Unknown subscription
+func (suite *StockWebhookServiceTestSuite) TestDeleteSubscription_NotFound() {
+	// Mock expectations
+	suite.subscriptionRepo.On("GetByID", suite.ctx, "missing").Return(nil, nil)
+
+	// Execute
+	err := suite.stockWebhookService.DeleteSubscription(suite.ctx, "missing")
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "not found")
+	suite.subscriptionRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything, mock.Anything)
+}
+
+// Test NotifyStockChanges - Subscribers only receive the products they follow
+func (suite *StockWebhookServiceTestSuite) TestNotifyStockChanges_FiltersBySubscriberProducts() {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriptions := []*models.StockWebhookSubscription{
+		{ID: "sub-1", URL: server.URL, Secret: "0123456789abcdef", ProductIDs: models.StringList{"product-1"}, IsActive: true},
+		{ID: "sub-2", URL: server.URL, Secret: "0123456789abcdef", ProductIDs: models.StringList{"product-3"}, IsActive: true},
+	}
+	recorded := make(chan struct{})
+
+	// Mock expectations
+	suite.subscriptionRepo.On("ListActive", mock.Anything).Return(subscriptions, nil)
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-1").
+		Return(&models.Inventory{ProductID: "product-1", Quantity: 10, Reserved: 3, Available: 7}, nil)
+	suite.inventoryRepo.On("GetByProductID", mock.Anything, "product-2").
+		Return(&models.Inventory{ProductID: "product-2", Quantity: 5, Reserved: 0, Available: 5}, nil)
+	suite.subscriptionRepo.On("RecordDelivery", mock.Anything, "sub-1", "").
+		Run(func(args mock.Arguments) { close(recorded) }).
+		Return(nil)
+
+	// Execute
+	suite.stockWebhookService.NotifyStockChanges(suite.ctx, services.StockChangeReasonReservation, []string{"product-1", "product-2"})
+
+	// Assert
+	select {
+	case <-recorded:
+	case <-time.After(5 * time.Second):
+		suite.T().Fatal("stock webhook was not delivered")
+	}
+
+	req := <-received
+	body := <-bodies
+	assert.Equal(suite.T(), services.SignStockWebhookPayload("0123456789abcdef", body), req.Header.Get(services.StockWebhookSignatureHeader))
+
+	var payload services.StockChangeWebhookPayload
+	assert.NoError(suite.T(), json.Unmarshal(body, &payload))
+	assert.Equal(suite.T(), services.StockChangedEvent, payload.Event)
+	assert.Equal(suite.T(), services.StockChangeReasonReservation, payload.Reason)
+	assert.Len(suite.T(), payload.Changes, 1)
+	assert.Equal(suite.T(), "product-1", payload.Changes[0].ProductID)
+	assert.Equal(suite.T(), 7, payload.Changes[0].Available)
+	suite.subscriptionRepo.AssertNotCalled(suite.T(), "RecordDelivery", mock.Anything, "sub-2", mock.Anything)
+}
+
+// TestStockWebhookServiceTestSuite runs the test suite
+func TestStockWebhookServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(StockWebhookServiceTestSuite))
+}
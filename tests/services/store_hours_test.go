@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/services"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// StoreSchedulesTestSuite defines the test suite for store hours and shipping cutoffs
+type StoreSchedulesTestSuite struct {
+	suite.Suite
+	schedules *services.StoreSchedules
+	location  *time.Location
+}
+
+// SetupTest runs before each test in the suite
+func (suite *StoreSchedulesTestSuite) SetupTest() {
+	schedules, err := services.NewStoreSchedules("America/New_York",
+		map[string]string{
+			services.DefaultStore: "mon-fri 09:00-17:00,sat 10:00-14:00",
+			"shopify":             "mon-sun 00:00-24:00",
+		},
+		map[string]string{
+			services.DefaultStore: "14:00",
+			"shopify":             "16:00",
+		},
+		[]string{"direct"},
+	)
+	suite.Require().NoError(err)
+	suite.schedules = schedules
+
+	suite.location, err = time.LoadLocation("America/New_York")
+	suite.Require().NoError(err)
+}
+
+// at returns a time on October 2026 in the store timezone; the 16th is a Friday
+func (suite *StoreSchedulesTestSuite) at(day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, suite.location)
+}
+
+// Test Accept - Orders before the cutoff ship by closing time the same day
+func (suite *StoreSchedulesTestSuite) TestAccept_BeforeCutoff() {
+	shipBy, err := suite.schedules.Accept("wholesale", suite.at(16, 13, 59))
+
+	suite.NoError(err)
+	suite.Require().NotNil(shipBy)
+	suite.True(suite.at(16, 17, 0).Equal(*shipBy))
+}
+
+// Test Accept - Orders after the cutoff, or while closed, ship on the next open day
+func (suite *StoreSchedulesTestSuite) TestAccept_AfterCutoff() {
+	shipBy, err := suite.schedules.Accept("wholesale", suite.at(16, 14, 0))
+	suite.NoError(err)
+	suite.Require().NotNil(shipBy)
+	suite.True(suite.at(17, 14, 0).Equal(*shipBy), "Saturday closes at 14:00")
+
+	// Sunday is closed, so Saturday evening orders ship on Monday
+	shipBy, err = suite.schedules.Accept("wholesale", suite.at(17, 20, 0))
+	suite.NoError(err)
+	suite.Require().NotNil(shipBy)
+	suite.True(suite.at(19, 17, 0).Equal(*shipBy))
+}
+
+// Test Accept - Stores with their own hours use their own cutoff, in the store timezone
+func (suite *StoreSchedulesTestSuite) TestAccept_OwnHours() {
+	// 19:30 UTC is 15:30 in New York, before the 16:00 shopify cutoff
+	shipBy, err := suite.schedules.Accept("shopify", time.Date(2026, time.October, 18, 19, 30, 0, 0, time.UTC))
+
+	suite.NoError(err)
+	suite.Require().NotNil(shipBy)
+	suite.True(suite.at(19, 0, 0).Equal(*shipBy), "open until midnight on Sunday")
+}
+
+// Test Accept - Stores rejecting orders outside hours say when they open
+func (suite *StoreSchedulesTestSuite) TestAccept_ClosedStoreRejects() {
+	shipBy, err := suite.schedules.Accept("direct", suite.at(18, 12, 0))
+
+	suite.Error(err)
+	suite.Nil(shipBy)
+	suite.Contains(err.Error(), "BUSINESS_ERROR: store direct is closed, orders are accepted from 2026-10-19T09:00:00-04:00")
+
+	// Open stores take the order as usual
+	shipBy, err = suite.schedules.Accept("direct", suite.at(19, 9, 0))
+	suite.NoError(err)
+	suite.Require().NotNil(shipBy)
+	suite.True(suite.at(19, 17, 0).Equal(*shipBy))
+}
+
+// Test Accept - Without hours stores are always open and promise no ship date
+func (suite *StoreSchedulesTestSuite) TestAccept_NoHours() {
+	schedules, err := services.NewStoreSchedules("UTC", nil, nil, nil)
+	suite.Require().NoError(err)
+
+	shipBy, err := schedules.Accept("direct", suite.at(18, 3, 0))
+	suite.NoError(err)
+	suite.Nil(shipBy)
+
+	shipBy, err = (*services.StoreSchedules)(nil).Accept("direct", suite.at(18, 3, 0))
+	suite.NoError(err)
+	suite.Nil(shipBy)
+}
+
+// Test NewStoreSchedules - Invalid configuration fails at startup
+func (suite *StoreSchedulesTestSuite) TestNew_InvalidConfiguration() {
+	_, err := services.NewStoreSchedules("UTC", map[string]string{"*": "mon-fri 17:00-09:00"}, nil, nil)
+	suite.ErrorContains(err, "must close after they open")
+
+	_, err = services.NewStoreSchedules("UTC", map[string]string{"*": "weekdays 09:00-17:00"}, nil, nil)
+	suite.ErrorContains(err, "unknown day")
+
+	_, err = services.NewStoreSchedules("UTC", nil, map[string]string{"shopify": "14:00"}, nil)
+	suite.ErrorContains(err, "shipping cutoff for store shopify, which has no hours")
+
+	_, err = services.NewStoreSchedules("Mars/Olympus", nil, nil, nil)
+	suite.ErrorContains(err, "invalid store timezone")
+}
+
+// Run the test suite
+func TestStoreSchedulesTestSuite(t *testing.T) {
+	suite.Run(t, new(StoreSchedulesTestSuite))
+}
@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// SupportSearchServiceTestSuite defines the test suite for SupportSearchService
+type SupportSearchServiceTestSuite struct {
+	suite.Suite
+	searchService services.SupportSearchService
+	searchRepo    *mocks.MockSupportSearchRepository
+	ctx           context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *SupportSearchServiceTestSuite) SetupTest() {
+	suite.searchRepo = new(mocks.MockSupportSearchRepository)
+	suite.ctx = context.Background()
+	suite.searchService = services.NewSupportSearchService(suite.searchRepo, &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *SupportSearchServiceTestSuite) TearDownTest() {
+	suite.searchRepo.AssertExpectations(suite.T())
+}
+
+// Test Search - Users, orders and their payments are found by email and card
+func (suite *SupportSearchServiceTestSuite) TestSearch_ByEmailAndCard() {
+	filter := repository.SupportSearchFilter{Email: "jane@", CardLast4: "4242"}
+	orders := []*models.Order{
+		{ID: "order-1", UserID: "user-1", Status: models.OrderStatusShipped, TotalAmount: 49.99,
+			Metadata: models.Metadata{"tracking_number": "1Z999"}},
+	}
+
+	// Mock expectations
+	suite.searchRepo.On("FindUsers", suite.ctx, filter, 20).Return([]*models.User{
+		{ID: "user-1", Email: "jane@example.com", Name: "Jane", Role: models.UserRoleCustomer, IsActive: true},
+	}, nil)
+	suite.searchRepo.On("FindOrders", suite.ctx, filter, 20).Return(orders, nil)
+	suite.searchRepo.On("ListPaymentsByOrders", suite.ctx, []string{"order-1"}).Return([]*models.Payment{
+		{ID: "payment-1", OrderID: "order-1", Amount: 49.99, Status: models.PaymentStatusCompleted},
+	}, nil)
+
+	// Execute
+	response, err := suite.searchService.Search(suite.ctx, services.SupportSearchRequest{Email: " jane@ ", CardLast4: "4242"})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), response.Users, 1)
+	assert.Equal(suite.T(), "jane@example.com", response.Users[0].Email)
+	assert.Len(suite.T(), response.Orders, 1)
+	assert.Equal(suite.T(), "1Z999", response.Orders[0].TrackingNumber)
+	assert.Len(suite.T(), response.Payments, 1)
+	assert.Equal(suite.T(), "payment-1", response.Payments[0].ID)
+}
+
+// Test Search - Orders found by total and day do not look up users
+func (suite *SupportSearchServiceTestSuite) TestSearch_ByTotalAndDate() {
+	total := 49.99
+	day := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	filter := repository.SupportSearchFilter{Total: &total, Date: &day}
+
+	// Mock expectations
+	suite.searchRepo.On("FindOrders", suite.ctx, filter, 5).Return([]*models.Order{}, nil)
+	suite.searchRepo.On("ListPaymentsByOrders", suite.ctx, []string{}).Return([]*models.Payment{}, nil)
+
+	// Execute
+	response, err := suite.searchService.Search(suite.ctx, services.SupportSearchRequest{Total: 49.99, Date: "2026-03-14", Limit: 5})
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), response.Users)
+	assert.Empty(suite.T(), response.Orders)
+	suite.searchRepo.AssertNotCalled(suite.T(), "FindUsers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test Search - A search needs a detail to match, a date alone is too broad
+func (suite *SupportSearchServiceTestSuite) TestSearch_NoCriteria() {
+	_, err := suite.searchService.Search(suite.ctx, services.SupportSearchRequest{Date: "2026-03-14"})
+
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// Test Search - Dates must be days
+func (suite *SupportSearchServiceTestSuite) TestSearch_InvalidDate() {
+	_, err := suite.searchService.Search(suite.ctx, services.SupportSearchRequest{Total: 10, Date: "14/03/2026"})
+
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+}
+
+// Test Search - Repository failures are reported as database errors
+func (suite *SupportSearchServiceTestSuite) TestSearch_RepositoryError() {
+	filter := repository.SupportSearchFilter{TrackingNumber: "1Z999"}
+
+	// Mock expectations
+	suite.searchRepo.On("FindOrders", suite.ctx, filter, 20).Return(nil, errors.New("connection refused"))
+
+	// Execute
+	_, err := suite.searchService.Search(suite.ctx, services.SupportSearchRequest{TrackingNumber: "1Z999"})
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "DATABASE_ERROR")
+}
+
+// TestSupportSearchServiceTestSuite runs the test suite
+func TestSupportSearchServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(SupportSearchServiceTestSuite))
+}
@@ -0,0 +1,311 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/errors"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// WarrantyClaimServiceTestSuite defines the test suite for WarrantyClaimService
+type WarrantyClaimServiceTestSuite struct {
+	suite.Suite
+	claimService services.WarrantyClaimService
+	claimRepo    *mocks.MockWarrantyClaimRepository
+	orderRepo    *mocks.MockOrderRepository
+	payments     *mocks.MockPaymentService
+	ctx          context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *WarrantyClaimServiceTestSuite) SetupTest() {
+	suite.claimRepo = new(mocks.MockWarrantyClaimRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.payments = new(mocks.MockPaymentService)
+	suite.ctx = context.Background()
+
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.claimService = services.NewWarrantyClaimService(
+		suite.claimRepo,
+		suite.orderRepo,
+		suite.payments,
+		log,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *WarrantyClaimServiceTestSuite) TearDownTest() {
+	suite.claimRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.payments.AssertExpectations(suite.T())
+}
+
+// deliveredOrder returns a delivered order of user-1 with one item of two serialized units
+func (suite *WarrantyClaimServiceTestSuite) deliveredOrder() *models.Order {
+	return &models.Order{
+		ID:              "order-1",
+		UserID:          "user-1",
+		Status:          models.OrderStatusDelivered,
+		TotalAmount:     200,
+		Currency:        "USD",
+		ShippingAddress: &models.ShippingAddress{Recipient: "Jane Doe", Line1: "1 Main St", City: "Cairo", PostalCode: "11511", Country: "EG"},
+		Items: []models.OrderItem{{
+			ID:            "item-1",
+			OrderID:       "order-1",
+			ProductID:     "product-1",
+			Quantity:      2,
+			UnitPrice:     100,
+			ListPrice:     120,
+			SerialNumbers: models.StringList{"SN-1", "SN-2"},
+		}},
+		Payments: []models.Payment{{
+			ID:     "payment-1",
+			Amount: 200,
+			Status: models.PaymentStatusCompleted,
+		}},
+	}
+}
+
+// openClaim returns an open claim on the unit SN-2 of the delivered order
+func (suite *WarrantyClaimServiceTestSuite) openClaim() *models.WarrantyClaim {
+	return &models.WarrantyClaim{
+		ID:           "claim-1",
+		OrderID:      "order-1",
+		OrderItemID:  "item-1",
+		ProductID:    "product-1",
+		UserID:       "user-1",
+		SerialNumber: "SN-2",
+		Description:  "Does not power on",
+		Status:       models.WarrantyClaimStatusOpen,
+	}
+}
+
+// Test CreateClaim - A unit that shipped on the order item is claimed
+func (suite *WarrantyClaimServiceTestSuite) TestCreateClaim_Success() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+	suite.claimRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim")).Return(true, nil)
+
+	// Execute
+	claim, err := suite.claimService.CreateClaim(suite.ctx, "user-1", "order-1", "item-1", services.CreateWarrantyClaimRequest{
+		SerialNumber: " SN-2 ",
+		Description:  "Does not power on",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal("SN-2", claim.SerialNumber)
+	suite.Equal("product-1", claim.ProductID)
+	suite.Equal(models.WarrantyClaimStatusOpen, claim.Status)
+}
+
+// Test CreateClaim - Only serial numbers captured on the order item can be claimed
+func (suite *WarrantyClaimServiceTestSuite) TestCreateClaim_SerialNotOnItem() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+
+	// Execute
+	claim, err := suite.claimService.CreateClaim(suite.ctx, "user-1", "order-1", "item-1", services.CreateWarrantyClaimRequest{
+		SerialNumber: "SN-9",
+		Description:  "Does not power on",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "SN-9")
+	suite.claimRepo.AssertNotCalled(suite.T(), "Create", mock.Anything, mock.Anything)
+}
+
+// Test CreateClaim - Another user's order is not found
+func (suite *WarrantyClaimServiceTestSuite) TestCreateClaim_OtherUsersOrder() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+
+	// Execute
+	claim, err := suite.claimService.CreateClaim(suite.ctx, "user-2", "order-1", "item-1", services.CreateWarrantyClaimRequest{
+		SerialNumber: "SN-2",
+		Description:  "Does not power on",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "NOT_FOUND")
+}
+
+// Test CreateClaim - A unit with an open claim cannot be claimed again
+func (suite *WarrantyClaimServiceTestSuite) TestCreateClaim_AlreadyOpen() {
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+	suite.claimRepo.On("Create", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim")).Return(false, nil)
+
+	// Execute
+	claim, err := suite.claimService.CreateClaim(suite.ctx, "user-1", "order-1", "item-1", services.CreateWarrantyClaimRequest{
+		SerialNumber: "SN-2",
+		Description:  "Does not power on",
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test ResolveClaim - A replacement is placed as a zero-priced paid order to the original address
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_Replace() {
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(suite.openClaim(), nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+
+	var replacement *models.Order
+	suite.claimRepo.On("Resolve", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim"), mock.AnythingOfType("*models.Order")).
+		Run(func(args mock.Arguments) { replacement = args.Get(2).(*models.Order) }).
+		Return(true, nil)
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionReplace,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.WarrantyClaimStatusResolved, claim.Status)
+	suite.Equal("admin-1", *claim.ResolvedBy)
+	suite.Require().NotNil(replacement)
+	suite.Equal(models.OrderStatusPaid, replacement.Status)
+	suite.Equal(models.OrderChannelWarranty, replacement.Channel)
+	suite.Equal(0.0, replacement.TotalAmount)
+	suite.Require().NotNil(replacement.ShippingAddress)
+	suite.Equal("1 Main St", replacement.ShippingAddress.Line1)
+	suite.Require().Len(replacement.Items, 1)
+	suite.Equal(1, replacement.Items[0].Quantity)
+	suite.Equal(0.0, replacement.Items[0].UnitPrice)
+	suite.Equal(120.0, replacement.Items[0].ListPrice)
+}
+
+// Test ResolveClaim - Out of stock replacements are reported as such
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_ReplaceOutOfStock() {
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(suite.openClaim(), nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+	suite.claimRepo.On("Resolve", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim"), mock.AnythingOfType("*models.Order")).
+		Return(false, errors.NewInsufficientStockError("product-1", 1, 0))
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionReplace,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "INSUFFICIENT_STOCK")
+}
+
+// Test ResolveClaim - A refund returns the unit's price to the order's payment
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_Refund() {
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(suite.openClaim(), nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+	suite.claimRepo.On("Resolve", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim"), (*models.Order)(nil)).Return(true, nil)
+	suite.payments.On("RefundPayment", suite.ctx, "payment-1", services.RefundRequest{
+		Amount:      100,
+		Reason:      "warranty claim claim-1",
+		RequestedBy: "admin-1",
+	}).Return(&services.PaymentResponse{}, nil)
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionRefund,
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(models.WarrantyClaimResolutionRefund, claim.Resolution)
+	suite.Equal(100.0, claim.RefundedAmount)
+}
+
+// Test ResolveClaim - A claim whose refund fails is reopened
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_RefundFailedReopens() {
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(suite.openClaim(), nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-1").Return(suite.deliveredOrder(), nil)
+	suite.claimRepo.On("Resolve", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim"), (*models.Order)(nil)).Return(true, nil)
+	suite.payments.On("RefundPayment", suite.ctx, "payment-1", mock.AnythingOfType("services.RefundRequest")).
+		Return(nil, errors.NewExternalServiceError("gateway", "refund declined", nil))
+	suite.claimRepo.On("Reopen", suite.ctx, "claim-1").Return(nil)
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionRefund,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+}
+
+// Test ResolveClaim - A claim closed by another admin in the meantime is not resolved again
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_ClosedConcurrently() {
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(suite.openClaim(), nil)
+	suite.claimRepo.On("Resolve", suite.ctx, mock.AnythingOfType("*models.WarrantyClaim"), (*models.Order)(nil)).Return(false, nil)
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionRepair,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "CONFLICT")
+}
+
+// Test ResolveClaim - A rejected claim cannot be resolved
+func (suite *WarrantyClaimServiceTestSuite) TestResolveClaim_AlreadyRejected() {
+	rejected := suite.openClaim()
+	rejected.Status = models.WarrantyClaimStatusRejected
+	suite.claimRepo.On("GetByID", suite.ctx, "claim-1").Return(rejected, nil)
+
+	// Execute
+	claim, err := suite.claimService.ResolveClaim(suite.ctx, "claim-1", "admin-1", services.ResolveWarrantyClaimRequest{
+		Resolution: models.WarrantyClaimResolutionRepair,
+	})
+
+	// Assert
+	suite.Error(err)
+	suite.Nil(claim)
+	suite.Contains(err.Error(), "already rejected")
+}
+
+// Test GetClaimRates - Claim rates are claims per hundred units shipped, highest first
+func (suite *WarrantyClaimServiceTestSuite) TestGetClaimRates() {
+	suite.claimRepo.On("SummarizeByProduct", suite.ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return([]repository.WarrantyClaimSummary{
+			{ProductID: "product-1", Name: "Phone", UnitsShipped: 100, Claims: 2, Repairs: 1, Open: 1},
+			{ProductID: "product-2", Name: "Tablet", UnitsShipped: 50, Claims: 5, Replacements: 3, Rejected: 2},
+		}, nil)
+
+	// Execute
+	report, err := suite.claimService.GetClaimRates(suite.ctx, services.WarrantyClaimRateReportRequest{
+		StartDate: "2026-01-01",
+		EndDate:   "2026-01-31",
+	})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(report.Products, 2)
+	suite.Equal("product-2", report.Products[0].ProductID)
+	suite.Equal(10.0, report.Products[0].ClaimRate)
+	suite.Equal(2.0, report.Products[1].ClaimRate)
+	suite.Equal(int64(150), report.Totals.UnitsShipped)
+	suite.Equal(int64(7), report.Totals.Claims)
+	suite.InDelta(4.67, report.Totals.ClaimRate, 0.01)
+	suite.Equal(int64(3), report.Totals.Replacements)
+}
+
+// TestWarrantyClaimServiceTestSuite runs the test suite
+func TestWarrantyClaimServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WarrantyClaimServiceTestSuite))
+}
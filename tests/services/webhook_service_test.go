@@ -0,0 +1,382 @@
+package services_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/payments"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// WebhookServiceTestSuite defines the test suite for WebhookService
+type WebhookServiceTestSuite struct {
+	suite.Suite
+	webhookService services.WebhookService
+	webhookRepo    *mocks.MockWebhookEventRepository
+	paymentRepo    *mocks.MockPaymentRepository
+	orderRepo      *mocks.MockOrderRepository
+	ledgerRepo     *mocks.MockLedgerRepository
+	logger         *logger.Logger
+	ctx            context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *WebhookServiceTestSuite) SetupTest() {
+	suite.webhookRepo = new(mocks.MockWebhookEventRepository)
+	suite.paymentRepo = new(mocks.MockPaymentRepository)
+	suite.orderRepo = new(mocks.MockOrderRepository)
+	suite.ledgerRepo = new(mocks.MockLedgerRepository)
+	suite.logger = &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.ctx = context.Background()
+
+	suite.webhookService = services.NewWebhookService(
+		suite.webhookRepo,
+		suite.paymentRepo,
+		suite.orderRepo,
+		services.PaymentWebhookVerifiers{"paypal": payments.NewHMACWebhookVerifier("whsec_test")},
+		services.PartnerWebhookSecrets{"marketplace": "partner_secret", "paypal": "partner_secret"},
+		services.NewLedgerService(suite.ledgerRepo, suite.logger),
+		nil, // No outbound webhooks
+		suite.logger,
+	)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *WebhookServiceTestSuite) TearDownTest() {
+	suite.webhookRepo.AssertExpectations(suite.T())
+	suite.paymentRepo.AssertExpectations(suite.T())
+	suite.orderRepo.AssertExpectations(suite.T())
+	suite.ledgerRepo.AssertExpectations(suite.T())
+}
+
+// Test ReceiveEvent - Events without a valid signature of the partner's secret are
+// refused before they are recorded
+func (suite *WebhookServiceTestSuite) TestReceiveEvent_RejectsInvalidSignature() {
+	body := []byte(`{"id":"evt_1","type":"listing.synced"}`)
+
+	for name, header := range map[string]http.Header{
+		"unsigned":       {},
+		"wrong secret":   signedWebhookHeader("whsec_test", body),
+		"other body":     signedWebhookHeader("partner_secret", []byte(`{"id":"evt_2","type":"listing.synced"}`)),
+		"not hex":        {payments.WebhookSignatureHeader: []string{"sha256=not-hex"}},
+		"missing prefix": {payments.WebhookSignatureHeader: []string{hex.EncodeToString(payments.SignWebhookPayload([]byte("partner_secret"), body))}},
+	} {
+		// Execute
+		response, err := suite.webhookService.ReceiveEvent(suite.ctx, "marketplace", header, body)
+
+		// Assert
+		assert.Error(suite.T(), err, name)
+		assert.Nil(suite.T(), response, name)
+		assert.Contains(suite.T(), err.Error(), "UNAUTHORIZED", name)
+	}
+	suite.webhookRepo.AssertNotCalled(suite.T(), "CreateIfNotExists", mock.Anything, mock.Anything)
+}
+
+// Test ReceiveEvent - Providers without a secret are not accepted
+func (suite *WebhookServiceTestSuite) TestReceiveEvent_UnknownProvider() {
+	body := []byte(`{"id":"evt_1","type":"listing.synced"}`)
+
+	// Execute
+	response, err := suite.webhookService.ReceiveEvent(suite.ctx, "stripe", signedWebhookHeader("partner_secret", body), body)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+	suite.webhookRepo.AssertNotCalled(suite.T(), "CreateIfNotExists", mock.Anything, mock.Anything)
+}
+
+// Test ReceiveEvent - Payment events from partners are refused before they are recorded
+func (suite *WebhookServiceTestSuite) TestReceiveEvent_RejectsPaymentEvent() {
+	for _, eventType := range []string{"payment.succeeded", "payment.failed", "payment.refunded"} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"id":   "evt_1",
+			"type": eventType,
+			"data": map[string]string{"transaction_id": "TXN_1"},
+		})
+
+		// Execute
+		response, err := suite.webhookService.ReceiveEvent(suite.ctx, "paypal", signedWebhookHeader("partner_secret", body), body)
+
+		// Assert
+		assert.Error(suite.T(), err, eventType)
+		assert.Nil(suite.T(), response)
+		assert.Contains(suite.T(), err.Error(), "UNAUTHORIZED")
+	}
+	suite.webhookRepo.AssertNotCalled(suite.T(), "CreateIfNotExists", mock.Anything, mock.Anything)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "GetByTransactionID", mock.Anything, mock.Anything)
+}
+
+// Test ReceiveEvent - Signed partner events without a handler are recorded as partner
+// events and acknowledged
+func (suite *WebhookServiceTestSuite) TestReceiveEvent_RecordsPartnerEvent() {
+	body := []byte(`{"id":"evt_1","type":"listing.synced","data":{"listing_id":"L-1"}}`)
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.MatchedBy(func(event *models.WebhookEvent) bool {
+		return event.Source == models.WebhookEventSourcePartner && event.Provider == "marketplace" &&
+			event.EventID == "evt_1" && event.EventType == "listing.synced"
+	})).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.WebhookEvent).ID = "event-1"
+		}).
+		Return(true, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-1", []models.WebhookEventStatus{models.WebhookEventStatusReceived}).Return(true, nil)
+	suite.webhookRepo.On("MarkProcessed", suite.ctx, "event-1").Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceiveEvent(suite.ctx, "marketplace", signedWebhookHeader("partner_secret", body), body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+	assert.False(suite.T(), response.Duplicate)
+}
+
+// Test ReplayEvent - Replaying a refund whose ledger posting failed posts it, without updating the payment again
+func (suite *WebhookServiceTestSuite) TestReplayEvent_PostsRefundToLedger() {
+	payload, _ := json.Marshal(services.ReceiveWebhookRequest{
+		Provider:  "stripe",
+		EventID:   "evt_3",
+		EventType: "payment.refunded",
+		Data:      json.RawMessage(`{"transaction_id":"TXN_3"}`),
+	})
+	event := &models.WebhookEvent{ID: "event-3", Provider: "stripe", EventID: "evt_3", EventType: "payment.refunded",
+		Payload: string(payload), Status: models.WebhookEventStatusFailed}
+	payment := &models.Payment{ID: "payment-3", OrderID: "order-3", TransactionID: "TXN_3", Amount: 25, Gateway: "stripe",
+		Status: models.PaymentStatusRefunded}
+
+	// Mock expectations
+	suite.webhookRepo.On("GetByID", suite.ctx, "event-3").Return(event, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-3", []models.WebhookEventStatus{models.WebhookEventStatusFailed}).Return(true, nil)
+	suite.paymentRepo.On("GetByTransactionID", suite.ctx, "TXN_3").Return(payment, nil)
+	suite.orderRepo.On("RefreshPaidAmount", suite.ctx, "order-3").Return(0.00, nil)
+	suite.ledgerRepo.On("Append", suite.ctx, mock.MatchedBy(func(entries []*models.LedgerEntry) bool {
+		return len(entries) == 2 && entries[0].TransactionKey == "refund:payment-3" &&
+			entries[0].Account == "revenue" && entries[0].Direction == models.LedgerDebit &&
+			entries[1].Account == "gateway_clearing:stripe" && entries[1].Direction == models.LedgerCredit &&
+			entries[1].Amount == 25 && entries[1].OrderID == "order-3"
+	})).Return(true, nil)
+	suite.webhookRepo.On("MarkProcessed", suite.ctx, "event-3").Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReplayEvent(suite.ctx, "event-3")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ReceiveEvent - Redelivery of a processed event is not processed again
+func (suite *WebhookServiceTestSuite) TestReceiveEvent_DuplicateIgnored() {
+	body := []byte(`{"id":"evt_1","type":"listing.synced"}`)
+	existing := &models.WebhookEvent{ID: "event-1", Source: models.WebhookEventSourcePartner, Provider: "marketplace",
+		EventID: "evt_1", Status: models.WebhookEventStatusProcessed}
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.AnythingOfType("*models.WebhookEvent")).Return(false, nil)
+	suite.webhookRepo.On("GetByProviderEventID", suite.ctx, models.WebhookEventSourcePartner, "marketplace", "evt_1").Return(existing, nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceiveEvent(suite.ctx, "marketplace", signedWebhookHeader("partner_secret", body), body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.Duplicate)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+}
+
+// Test ReceivePaymentWebhook - Handler failure marks the event failed
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_HandlerFailure() {
+	body := []byte(`{"id":"evt_2","type":"payment.failed","data":{}}`)
+	header := signedWebhookHeader("whsec_test", body)
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.AnythingOfType("*models.WebhookEvent")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.WebhookEvent).ID = "event-2"
+		}).
+		Return(true, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-2", []models.WebhookEventStatus{models.WebhookEventStatusReceived}).Return(true, nil)
+	suite.webhookRepo.On("MarkFailed", suite.ctx, "event-2", mock.AnythingOfType("string")).Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "paypal", header, body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusFailed, response.Status)
+	assert.Contains(suite.T(), response.LastError, "transaction_id")
+}
+
+// Test ReplayEvent - Only failed events can be replayed
+func (suite *WebhookServiceTestSuite) TestReplayEvent_NotFailed() {
+	event := &models.WebhookEvent{ID: "event-1", Status: models.WebhookEventStatusProcessed}
+
+	// Mock expectations
+	suite.webhookRepo.On("GetByID", suite.ctx, "event-1").Return(event, nil)
+
+	// Execute
+	response, err := suite.webhookService.ReplayEvent(suite.ctx, "event-1")
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "cannot be replayed")
+}
+
+// Test ReplayEvent - Failed event loses the claim race
+func (suite *WebhookServiceTestSuite) TestReplayEvent_AlreadyClaimed() {
+	event := &models.WebhookEvent{ID: "event-1", EventType: "payment.failed", Status: models.WebhookEventStatusFailed}
+
+	// Mock expectations
+	suite.webhookRepo.On("GetByID", suite.ctx, "event-1").Return(event, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-1", []models.WebhookEventStatus{models.WebhookEventStatusFailed}).Return(false, nil)
+
+	// Execute
+	response, err := suite.webhookService.ReplayEvent(suite.ctx, "event-1")
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.Duplicate)
+}
+
+// Test ReceivePaymentWebhook - A signed gateway confirmation settles the payment found by its gateway transaction ID
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_SucceededPaysOrder() {
+	body := []byte(`{"id":"evt_pp_1","type":"payment.succeeded","data":{"transaction_id":"PP-123"}}`)
+	header := signedWebhookHeader("whsec_test", body)
+	payment := &models.Payment{ID: "payment-4", OrderID: "order-4", Amount: 60, Gateway: "paypal", GatewayTxnID: "PP-123",
+		Status: models.PaymentStatusProcessed}
+	order := &models.Order{ID: "order-4", Status: models.OrderStatusConfirmed, TotalAmount: 60}
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.MatchedBy(func(event *models.WebhookEvent) bool {
+		return event.Source == models.WebhookEventSourceGateway && event.Provider == "paypal" &&
+			event.EventID == "evt_pp_1" && event.EventType == "payment.succeeded"
+	})).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.WebhookEvent).ID = "event-4"
+		}).
+		Return(true, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-4", []models.WebhookEventStatus{models.WebhookEventStatusReceived}).Return(true, nil)
+	suite.paymentRepo.On("GetByGatewayTransactionID", suite.ctx, "paypal", "PP-123").Return(payment, nil)
+	suite.paymentRepo.On("UpdateStatus", suite.ctx, "payment-4", models.PaymentStatusCompleted).Return(nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-4").Return(order, nil)
+	suite.orderRepo.On("RefreshPaidAmount", suite.ctx, "order-4").Return(60.00, nil)
+	suite.orderRepo.On("UpdateStatus", suite.ctx, "order-4", models.OrderStatusPaid).Return(nil)
+	suite.ledgerRepo.On("Append", suite.ctx, mock.Anything).Return(true, nil)
+	suite.webhookRepo.On("MarkProcessed", suite.ctx, "event-4").Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "paypal", header, body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+}
+
+// Test ReceivePaymentWebhook - A confirmed payment covering part of the total leaves the order pending
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_PartialPaymentKeepsOrderPending() {
+	body := []byte(`{"id":"evt_pp_3","type":"payment.succeeded","data":{"transaction_id":"PP-789"}}`)
+	header := signedWebhookHeader("whsec_test", body)
+	payment := &models.Payment{ID: "payment-6", OrderID: "order-6", Amount: 25, Gateway: "paypal", GatewayTxnID: "PP-789",
+		Status: models.PaymentStatusProcessed}
+	order := &models.Order{ID: "order-6", Status: models.OrderStatusPending, TotalAmount: 100}
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.AnythingOfType("*models.WebhookEvent")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.WebhookEvent).ID = "event-6"
+		}).
+		Return(true, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-6", []models.WebhookEventStatus{models.WebhookEventStatusReceived}).Return(true, nil)
+	suite.paymentRepo.On("GetByGatewayTransactionID", suite.ctx, "paypal", "PP-789").Return(payment, nil)
+	suite.paymentRepo.On("UpdateStatus", suite.ctx, "payment-6", models.PaymentStatusCompleted).Return(nil)
+	suite.orderRepo.On("GetByID", suite.ctx, "order-6").Return(order, nil)
+	suite.orderRepo.On("RefreshPaidAmount", suite.ctx, "order-6").Return(75.00, nil)
+	suite.ledgerRepo.On("Append", suite.ctx, mock.Anything).Return(true, nil)
+	suite.webhookRepo.On("MarkProcessed", suite.ctx, "event-6").Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "paypal", header, body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+	suite.orderRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ReceivePaymentWebhook - A late failure does not undo a refunded payment
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_StaleFailureIgnored() {
+	body := []byte(`{"id":"evt_pp_2","type":"payment.failed","data":{"transaction_id":"PP-456"}}`)
+	header := signedWebhookHeader("whsec_test", body)
+	payment := &models.Payment{ID: "payment-5", OrderID: "order-5", Gateway: "paypal", GatewayTxnID: "PP-456",
+		Status: models.PaymentStatusRefunded}
+
+	// Mock expectations
+	suite.webhookRepo.On("CreateIfNotExists", suite.ctx, mock.AnythingOfType("*models.WebhookEvent")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.WebhookEvent).ID = "event-5"
+		}).
+		Return(true, nil)
+	suite.webhookRepo.On("Claim", suite.ctx, "event-5", []models.WebhookEventStatus{models.WebhookEventStatusReceived}).Return(true, nil)
+	suite.paymentRepo.On("GetByGatewayTransactionID", suite.ctx, "paypal", "PP-456").Return(payment, nil)
+	suite.webhookRepo.On("MarkProcessed", suite.ctx, "event-5").Return(nil)
+
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "paypal", header, body)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.WebhookEventStatusProcessed, response.Status)
+	suite.paymentRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Test ReceivePaymentWebhook - An invalid signature is rejected before the event is recorded
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_InvalidSignature() {
+	body := []byte(`{"id":"evt_pp_3","type":"payment.succeeded","data":{"transaction_id":"PP-789"}}`)
+	header := signedWebhookHeader("wrong_secret", body)
+
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "paypal", header, body)
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "UNAUTHORIZED")
+	suite.webhookRepo.AssertNotCalled(suite.T(), "CreateIfNotExists", mock.Anything, mock.Anything)
+}
+
+// Test ReceivePaymentWebhook - Gateways without a configured verifier are not found
+func (suite *WebhookServiceTestSuite) TestReceivePaymentWebhook_UnknownGateway() {
+	// Execute
+	response, err := suite.webhookService.ReceivePaymentWebhook(suite.ctx, "square", http.Header{}, []byte(`{}`))
+
+	// Assert
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// signedWebhookHeader signs a gateway callback body the way HMACWebhookVerifier expects
+func signedWebhookHeader(secret string, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(payments.WebhookSignatureHeader, "sha256="+hex.EncodeToString(payments.SignWebhookPayload([]byte(secret), body)))
+	return header
+}
+
+// TestWebhookServiceTestSuite runs the test suite
+func TestWebhookServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookServiceTestSuite))
+}
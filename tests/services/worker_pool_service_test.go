@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/internal/services"
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// WorkerPoolServiceTestSuite defines the test suite for WorkerPoolService
+type WorkerPoolServiceTestSuite struct {
+	suite.Suite
+	workerPoolService services.WorkerPoolService
+	poolManager       *workers.PoolManager
+	jobRepo           *mocks.MockBackgroundJobRepository
+	ctx               context.Context
+}
+
+// SetupTest runs before each test in the suite
+func (suite *WorkerPoolServiceTestSuite) SetupTest() {
+	log := &logger.Logger{SugaredLogger: mocks.NewNoOpLogger()}
+	suite.jobRepo = new(mocks.MockBackgroundJobRepository)
+	suite.ctx = context.Background()
+
+	suite.poolManager = workers.NewPoolManager(log)
+	for _, name := range []string{"reports", "notifications"} {
+		suite.Require().NoError(suite.poolManager.CreatePool(&workers.WorkerPoolConfig{
+			Name:            name,
+			WorkerCount:     2,
+			QueueSize:       10,
+			ShutdownTimeout: time.Second,
+			EnableMetrics:   true,
+		}))
+	}
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+
+	suite.workerPoolService = services.NewWorkerPoolService(suite.poolManager, suite.jobRepo, log)
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *WorkerPoolServiceTestSuite) TearDownTest() {
+	_ = suite.poolManager.Shutdown()
+	suite.jobRepo.AssertExpectations(suite.T())
+}
+
+// Test GetStats - Pools are reported by name with the stored jobs waiting for them
+func (suite *WorkerPoolServiceTestSuite) TestGetStats_ReportsPools() {
+	suite.jobRepo.On("CountQueuedJobs", suite.ctx).Return(map[string]int64{"reports": 7}, nil)
+
+	// Execute
+	stats, err := suite.workerPoolService.GetStats(suite.ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Require().Len(stats.Pools, 2)
+	assert.Equal(suite.T(), "notifications", stats.Pools[0].Name)
+	assert.Equal(suite.T(), "reports", stats.Pools[1].Name)
+	assert.Equal(suite.T(), int64(7), stats.Pools[1].StoredJobs)
+	assert.Equal(suite.T(), int64(0), stats.Pools[0].StoredJobs)
+	assert.Equal(suite.T(), 2, stats.Pools[0].Workers)
+	assert.Equal(suite.T(), 10, stats.Pools[0].QueueCapacity)
+	assert.NotNil(suite.T(), stats.Pools[0].StartedAt)
+	assert.Zero(suite.T(), stats.Pools[0].FailureRate)
+}
+
+// Test GetStats - A failure to count stored jobs is a database error
+func (suite *WorkerPoolServiceTestSuite) TestGetStats_RepositoryError() {
+	suite.jobRepo.On("CountQueuedJobs", suite.ctx).Return(nil, errors.New("connection refused"))
+
+	// Execute
+	stats, err := suite.workerPoolService.GetStats(suite.ctx)
+
+	// Assert
+	assert.Nil(suite.T(), stats)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "DATABASE_ERROR")
+}
+
+// Test Resize - The pool runs with the new number of workers
+func (suite *WorkerPoolServiceTestSuite) TestResize_Success() {
+	suite.jobRepo.On("CountQueuedJobs", suite.ctx).Return(map[string]int64{}, nil)
+
+	// Execute
+	stats, err := suite.workerPoolService.Resize(suite.ctx, services.ResizeWorkerPoolRequest{Pool: "reports", Workers: 5})
+
+	// Assert
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "reports", stats.Name)
+	assert.Equal(suite.T(), 5, stats.Workers)
+	metrics, err := suite.poolManager.GetPoolMetrics("reports")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 5, metrics.WorkerCount)
+}
+
+// Test Resize - Unknown pools are not found
+func (suite *WorkerPoolServiceTestSuite) TestResize_UnknownPool() {
+	// Execute
+	stats, err := suite.workerPoolService.Resize(suite.ctx, services.ResizeWorkerPoolRequest{Pool: "missing", Workers: 5})
+
+	// Assert
+	assert.Nil(suite.T(), stats)
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "NOT_FOUND")
+}
+
+// Test Resize - Worker counts outside 1 to 100 are rejected
+func (suite *WorkerPoolServiceTestSuite) TestResize_InvalidWorkerCount() {
+	for _, count := range []int{0, 101} {
+		// Execute
+		stats, err := suite.workerPoolService.Resize(suite.ctx, services.ResizeWorkerPoolRequest{Pool: "reports", Workers: count})
+
+		// Assert
+		assert.Nil(suite.T(), stats)
+		suite.Require().Error(err)
+		assert.Contains(suite.T(), err.Error(), "VALIDATION_ERROR")
+	}
+}
+
+// TestWorkerPoolServiceTestSuite runs the test suite
+func TestWorkerPoolServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkerPoolServiceTestSuite))
+}
@@ -0,0 +1,70 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, content io.ReadCloser) string {
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// Test LocalStorage - Files are stored, replaced, opened and deleted under their keys
+func TestLocalStorage_PutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := storage.NewLocalStorage(filepath.Join(dir, "files"))
+	require.NoError(t, err)
+
+	require.NoError(t, local.Put(ctx, "reports/a.pdf", []byte("first"), "application/pdf"))
+	require.NoError(t, local.Put(ctx, "reports/a.pdf", []byte("second"), "application/pdf"))
+
+	content, err := local.Open(ctx, "reports/a.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "second", readAll(t, content))
+
+	require.NoError(t, local.Delete(ctx, "reports/a.pdf"))
+	_, err = local.Open(ctx, "reports/a.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Deleting a missing file is not an error
+	assert.NoError(t, local.Delete(ctx, "reports/a.pdf"))
+}
+
+// Test LocalStorage - Keys cannot reach outside the directory
+func TestLocalStorage_KeysStayInDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := storage.NewLocalStorage(filepath.Join(dir, "files"))
+	require.NoError(t, err)
+
+	require.NoError(t, local.Put(ctx, "../../escaped.txt", []byte("data"), "text/plain"))
+
+	_, err = os.Stat(filepath.Join(dir, "files", "escaped.txt"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "escaped.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, local.Put(ctx, "..", []byte("data"), "text/plain"))
+}
+
+// Test LocalStorage - Local files cannot be downloaded from signed URLs
+func TestLocalStorage_SignedURLUnsupported(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = local.SignedURL(context.Background(), "reports/a.pdf", time.Minute)
+	assert.ErrorIs(t, err, storage.ErrSignedURLsUnsupported)
+}
@@ -218,7 +218,8 @@ func TeardownTestDatabase(db *database.DB) {
 // RunMigrations runs all database migrations
 func RunMigrations(db *database.DB) error {
 	// Auto-migrate all models
-	return db.AutoMigrate(
+	if err := db.AutoMigrate(
+		&models.Organization{},
 		&models.User{},
 		&models.Product{},
 		&models.Inventory{},
@@ -227,12 +228,114 @@ func RunMigrations(db *database.DB) error {
 		&models.Payment{},
 		&models.Notification{},
 		&models.AuditLog{},
-	)
+		&models.WebhookEvent{},
+		&models.StockWebhookSubscription{},
+		&models.ChannelListing{},
+		&models.ActivityEvent{},
+		&models.StockHold{},
+		&models.PaymentAttempt{},
+		&models.ProductAvailability{},
+		&models.Address{},
+		&models.SavedPaymentMethod{},
+		&models.InventoryEvent{},
+		&models.OrderEvent{},
+		&models.LedgerEntry{},
+		&models.PaymentProof{},
+		&models.OrderHold{},
+		&models.WebhookEndpoint{},
+		&models.WebhookDelivery{},
+		&models.WebhookRelayCursor{},
+		&models.ReportResult{},
+		&models.APIKey{},
+		&models.APIUsage{},
+		&models.ReferralCode{},
+		&models.Referral{},
+		&models.StoreCreditTransaction{},
+		&models.BlocklistEntry{},
+		&models.PriceList{},
+		&models.PriceListItem{},
+		&models.Promotion{},
+		&models.OrderPromotion{},
+		&models.DeliverySlot{},
+		&models.Refund{},
+		&models.IdempotencyRecord{},
+		&models.PaymentReconciliation{},
+		&models.PaymentRoutingRule{},
+		&models.FulfillmentTask{},
+		&models.OrderEventCursor{},
+		&models.BackgroundJob{},
+		&models.DeadJob{},
+		&models.CloseReportRun{},
+		&models.CheckoutSaga{},
+		&models.InventoryShard{},
+		&models.PaymentIntent{},
+		&models.ProductSerial{},
+		&models.WarrantyClaim{},
+		&models.NotificationExperiment{},
+		&models.StockImport{},
+		&models.StockImportRow{},
+		&models.CatalogEvent{},
+		&models.CatalogEventCursor{},
+	); err != nil {
+		return err
+	}
+
+	// Columns added by online migrations, left out of auto-migration
+	return db.Exec("ALTER TABLE orders ADD COLUMN IF NOT EXISTS paid_amount decimal(10,2) NOT NULL DEFAULT 0, " +
+		"ADD COLUMN IF NOT EXISTS delivery_slot_id uuid").Error
 }
 
 // CleanDatabase truncates all tables for a clean test state
 func CleanDatabase(db *database.DB) {
 	// Delete in reverse order to respect foreign key constraints
+	db.Exec("TRUNCATE TABLE catalog_event_cursors CASCADE")
+	db.Exec("TRUNCATE TABLE catalog_events CASCADE")
+	db.Exec("TRUNCATE TABLE stock_import_rows CASCADE")
+	db.Exec("TRUNCATE TABLE stock_imports CASCADE")
+	db.Exec("TRUNCATE TABLE notification_experiments CASCADE")
+	db.Exec("TRUNCATE TABLE warranty_claims CASCADE")
+	db.Exec("TRUNCATE TABLE payment_intents CASCADE")
+	db.Exec("TRUNCATE TABLE product_serials CASCADE")
+	db.Exec("TRUNCATE TABLE inventory_shards CASCADE")
+	db.Exec("TRUNCATE TABLE checkout_sagas CASCADE")
+	db.Exec("TRUNCATE TABLE close_report_runs CASCADE")
+	db.Exec("TRUNCATE TABLE dead_jobs CASCADE")
+	db.Exec("TRUNCATE TABLE background_jobs CASCADE")
+	db.Exec("TRUNCATE TABLE order_event_cursors CASCADE")
+	db.Exec("TRUNCATE TABLE fulfillment_tasks CASCADE")
+	db.Exec("TRUNCATE TABLE payment_routing_rules CASCADE")
+	db.Exec("TRUNCATE TABLE payment_reconciliations CASCADE")
+	db.Exec("TRUNCATE TABLE idempotency_records CASCADE")
+	db.Exec("TRUNCATE TABLE refunds CASCADE")
+	db.Exec("TRUNCATE TABLE delivery_slots CASCADE")
+	db.Exec("TRUNCATE TABLE order_promotions CASCADE")
+	db.Exec("TRUNCATE TABLE promotions CASCADE")
+	db.Exec("TRUNCATE TABLE price_list_items CASCADE")
+	db.Exec("TRUNCATE TABLE price_lists CASCADE")
+	db.Exec("TRUNCATE TABLE blocklist_entries CASCADE")
+	db.Exec("TRUNCATE TABLE store_credit_transactions CASCADE")
+	db.Exec("TRUNCATE TABLE referrals CASCADE")
+	db.Exec("TRUNCATE TABLE referral_codes CASCADE")
+	db.Exec("TRUNCATE TABLE api_usage CASCADE")
+	db.Exec("TRUNCATE TABLE api_keys CASCADE")
+	db.Exec("TRUNCATE TABLE report_results CASCADE")
+	db.Exec("TRUNCATE TABLE webhook_relay_cursors CASCADE")
+	db.Exec("TRUNCATE TABLE webhook_deliveries CASCADE")
+	db.Exec("TRUNCATE TABLE webhook_endpoints CASCADE")
+	db.Exec("TRUNCATE TABLE order_holds CASCADE")
+	db.Exec("TRUNCATE TABLE payment_proofs CASCADE")
+	db.Exec("TRUNCATE TABLE ledger_entries CASCADE")
+	db.Exec("TRUNCATE TABLE order_events CASCADE")
+	db.Exec("TRUNCATE TABLE inventory_events CASCADE")
+	db.Exec("TRUNCATE TABLE saved_payment_methods CASCADE")
+	db.Exec("TRUNCATE TABLE addresses CASCADE")
+	db.Exec("TRUNCATE TABLE product_availability CASCADE")
+	db.Exec("TRUNCATE TABLE payment_attempts CASCADE")
+	db.Exec("TRUNCATE TABLE stock_holds CASCADE")
+	db.Exec("TRUNCATE TABLE activity_events CASCADE")
+	db.Exec("TRUNCATE TABLE channel_listings CASCADE")
+	db.Exec("TRUNCATE TABLE stock_webhook_subscriptions CASCADE")
+	db.Exec("TRUNCATE TABLE webhook_events CASCADE")
 	db.Exec("TRUNCATE TABLE audit_logs CASCADE")
 	db.Exec("TRUNCATE TABLE notifications CASCADE")
 	db.Exec("TRUNCATE TABLE payments CASCADE")
@@ -241,6 +344,7 @@ func CleanDatabase(db *database.DB) {
 	db.Exec("TRUNCATE TABLE inventory CASCADE")
 	db.Exec("TRUNCATE TABLE products CASCADE")
 	db.Exec("TRUNCATE TABLE users CASCADE")
+	db.Exec("TRUNCATE TABLE organizations CASCADE")
 }
 
 // NewTestLogger creates a no-op logger for testing
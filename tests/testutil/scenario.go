@@ -0,0 +1,335 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"easy-orders-backend/internal/models"
+	"easy-orders-backend/internal/repository"
+	"easy-orders-backend/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScenarioItem is an order line of a scenario, referring to a product by the order it
+// was added to the scenario in, from 0
+type ScenarioItem struct {
+	Product  int
+	Quantity int
+}
+
+// Item returns an order line of quantity units of the scenario's product at index
+func Item(product, quantity int) ScenarioItem {
+	return ScenarioItem{Product: product, Quantity: quantity}
+}
+
+// Scenario is a consistent graph of records seeded for a test: every order belongs to
+// a user of the scenario, its items and total match its products' prices, the stock of
+// its open orders is reserved, and its payments cover what it was paid.
+type Scenario struct {
+	Users          []*models.User
+	Addresses      []*models.Address
+	PaymentMethods []*models.SavedPaymentMethod
+	Products       []*models.Product
+	Inventories    []*models.Inventory // Inventories[i] is the stock of Products[i]
+	Orders         []*models.Order     // With their items and payments
+}
+
+// User returns the user added last, whom orders added after it belong to
+func (s *Scenario) User() *models.User {
+	return s.Users[len(s.Users)-1]
+}
+
+// Product returns the product at index, in the order products were added
+func (s *Scenario) Product(index int) *models.Product {
+	return s.Products[index]
+}
+
+// Inventory returns the stock of the product at index
+func (s *Scenario) Inventory(index int) *models.Inventory {
+	return s.Inventories[index]
+}
+
+// Order returns the order added last
+func (s *Scenario) Order() *models.Order {
+	return s.Orders[len(s.Orders)-1]
+}
+
+// ScenarioBuilder builds a Scenario record by record, then seeds it in one
+// transaction. Records are built as they are added, with their IDs, so tests can
+// refer to them before seeding; the first mistake in the chain, such as an order of a
+// product not added yet, is reported by Seed.
+//
+//	scenario, err := testutil.NewScenario().
+//		WithUser().
+//		WithProduct(10).
+//		WithPendingOrder(testutil.Item(0, 2)).
+//		Seed(ctx, db)
+type ScenarioBuilder struct {
+	scenario *Scenario
+	err      error
+}
+
+// NewScenario starts an empty scenario
+func NewScenario() *ScenarioBuilder {
+	return &ScenarioBuilder{scenario: &Scenario{}}
+}
+
+// WithUser adds a customer with an email of their own, as users' emails are unique
+func (b *ScenarioBuilder) WithUser(overrides ...func(*models.User)) *ScenarioBuilder {
+	email := fmt.Sprintf("user-%d-%s@example.com", len(b.scenario.Users)+1, uuid.New().String()[:8])
+	user := CreateTestUser(append([]func(*models.User){func(u *models.User) { u.Email = email }}, overrides...)...)
+	b.scenario.Users = append(b.scenario.Users, user)
+	return b
+}
+
+// WithAddress adds a default shipping address for the last user
+func (b *ScenarioBuilder) WithAddress(overrides ...func(*models.Address)) *ScenarioBuilder {
+	user := b.lastUser("an address")
+	if user == nil {
+		return b
+	}
+
+	address := &models.Address{
+		ID:     uuid.New().String(),
+		UserID: user.ID,
+		Label:  "Home",
+		ShippingAddress: models.ShippingAddress{
+			Recipient:  user.Name,
+			Line1:      "1 Test Street",
+			City:       "Cairo",
+			PostalCode: "11511",
+			Country:    "EG",
+		},
+		IsDefault: true,
+	}
+	for _, override := range overrides {
+		if override != nil {
+			override(address)
+		}
+	}
+
+	b.scenario.Addresses = append(b.scenario.Addresses, address)
+	return b
+}
+
+// WithPaymentMethod adds a default saved card for the last user
+func (b *ScenarioBuilder) WithPaymentMethod(overrides ...func(*models.SavedPaymentMethod)) *ScenarioBuilder {
+	user := b.lastUser("a payment method")
+	if user == nil {
+		return b
+	}
+
+	method := &models.SavedPaymentMethod{
+		ID:           uuid.New().String(),
+		UserID:       user.ID,
+		Method:       models.PaymentMethodCreditCard,
+		Label:        "Test card",
+		Last4:        "4242",
+		GatewayToken: "tok_" + uuid.New().String()[:8],
+		IsDefault:    true,
+	}
+	for _, override := range overrides {
+		if override != nil {
+			override(method)
+		}
+	}
+
+	b.scenario.PaymentMethods = append(b.scenario.PaymentMethods, method)
+	return b
+}
+
+// WithProduct adds an active product with stock units on hand and none reserved
+func (b *ScenarioBuilder) WithProduct(stock int, overrides ...func(*models.Product)) *ScenarioBuilder {
+	product := CreateTestProduct(overrides...)
+	inventory := CreateTestInventory(product.ID, func(i *models.Inventory) {
+		i.Quantity = stock
+		i.Reserved = 0
+		i.Available = stock
+	})
+
+	b.scenario.Products = append(b.scenario.Products, product)
+	b.scenario.Inventories = append(b.scenario.Inventories, inventory)
+	return b
+}
+
+// WithPendingOrder adds an unpaid order of the last user, reserving its stock
+func (b *ScenarioBuilder) WithPendingOrder(items ...ScenarioItem) *ScenarioBuilder {
+	return b.WithOrder(models.OrderStatusPending, items)
+}
+
+// WithPaidOrder adds an order of the last user paid in full by a completed card
+// payment, its stock still reserved for fulfillment
+func (b *ScenarioBuilder) WithPaidOrder(items ...ScenarioItem) *ScenarioBuilder {
+	b.WithOrder(models.OrderStatusPaid, items)
+	if b.err != nil {
+		return b
+	}
+
+	order := b.scenario.Order()
+	payment := CreateTestPayment(order.ID, func(p *models.Payment) {
+		p.Amount = order.TotalAmount
+		p.Method = order.PaymentMethod
+		p.Status = models.PaymentStatusCompleted
+	})
+	order.Payments = append(order.Payments, *payment)
+	order.PaidAmount = order.TotalAmount
+	return b
+}
+
+// WithOrder adds an order of the last user in the given status, its items charged at
+// their products' prices. The stock of orders still to ship is reserved; shipped and
+// delivered orders take it off hand, and cancelled and failed ones leave it alone.
+func (b *ScenarioBuilder) WithOrder(status models.OrderStatus, items []ScenarioItem, overrides ...func(*models.Order)) *ScenarioBuilder {
+	user := b.lastUser("an order")
+	if user == nil {
+		return b
+	}
+	if len(items) == 0 {
+		return b.fail(fmt.Errorf("order %d has no items", len(b.scenario.Orders)+1))
+	}
+
+	order := CreateTestOrder(user.ID, func(o *models.Order) {
+		o.Status = status
+		o.PaymentMethod = models.PaymentMethodCreditCard
+	})
+
+	var total float64
+	for _, item := range items {
+		if item.Product < 0 || item.Product >= len(b.scenario.Products) {
+			return b.fail(fmt.Errorf("order %d refers to product %d, but only %d were added",
+				len(b.scenario.Orders)+1, item.Product, len(b.scenario.Products)))
+		}
+		if item.Quantity <= 0 {
+			return b.fail(fmt.Errorf("order %d has %d units of product %d", len(b.scenario.Orders)+1, item.Quantity, item.Product))
+		}
+
+		product := b.scenario.Products[item.Product]
+		line := CreateTestOrderItem(order.ID, product.ID, func(i *models.OrderItem) {
+			i.Quantity = item.Quantity
+			i.UnitPrice = product.Price
+			i.ListPrice = product.Price
+			i.TotalPrice = roundCents(product.Price * float64(item.Quantity))
+		})
+		order.Items = append(order.Items, *line)
+		total += line.TotalPrice
+
+		inventory := b.scenario.Inventories[item.Product]
+		switch status {
+		case models.OrderStatusShipped, models.OrderStatusDelivered:
+			inventory.Quantity -= item.Quantity
+		case models.OrderStatusCancelled, models.OrderStatusFailed:
+		default:
+			inventory.Reserved += item.Quantity
+		}
+		inventory.RefreshAvailable()
+		if inventory.Quantity < 0 || inventory.Reserved > inventory.Quantity {
+			return b.fail(fmt.Errorf("order %d needs more of product %d than its stock", len(b.scenario.Orders)+1, item.Product))
+		}
+	}
+	order.TotalAmount = roundCents(total)
+
+	for _, override := range overrides {
+		if override != nil {
+			override(order)
+		}
+	}
+
+	b.scenario.Orders = append(b.scenario.Orders, order)
+	return b
+}
+
+// Build returns the scenario without seeding it, for tests that only need the records
+func (b *ScenarioBuilder) Build() (*Scenario, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.scenario, nil
+}
+
+// Seed inserts the scenario in one transaction, in dependency order, and returns it.
+// Stock is recorded in the inventory event log like products created through the
+// product repository, and what was paid for each order is refreshed from its payments.
+func (b *ScenarioBuilder) Seed(ctx context.Context, db *database.DB) (*Scenario, error) {
+	scenario, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, user := range scenario.Users {
+			if err := tx.Create(user).Error; err != nil {
+				return fmt.Errorf("seeding user %s: %w", user.Email, err)
+			}
+		}
+		for _, address := range scenario.Addresses {
+			if err := tx.Create(address).Error; err != nil {
+				return fmt.Errorf("seeding address: %w", err)
+			}
+		}
+		for _, method := range scenario.PaymentMethods {
+			if err := tx.Create(method).Error; err != nil {
+				return fmt.Errorf("seeding payment method: %w", err)
+			}
+		}
+
+		for i, product := range scenario.Products {
+			if err := tx.Create(product).Error; err != nil {
+				return fmt.Errorf("seeding product %s: %w", product.SKU, err)
+			}
+			inventory := scenario.Inventories[i]
+			if err := tx.Create(inventory).Error; err != nil {
+				return fmt.Errorf("seeding inventory of product %s: %w", product.SKU, err)
+			}
+			if err := repository.AppendInventoryEvent(tx, inventory, models.InventoryEventAdjusted, inventory.Quantity); err != nil {
+				return fmt.Errorf("recording stock of product %s: %w", product.SKU, err)
+			}
+		}
+
+		for _, order := range scenario.Orders {
+			// Items and payments are inserted with the order
+			if err := tx.Create(order).Error; err != nil {
+				return fmt.Errorf("seeding order: %w", err)
+			}
+			if order.PaidAmount > 0 {
+				if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).
+					UpdateColumn("paid_amount", order.PaidAmount).Error; err != nil {
+					return fmt.Errorf("seeding paid amount of order: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+// lastUser returns the user the next record belongs to, failing the scenario when no
+// user was added yet
+func (b *ScenarioBuilder) lastUser(record string) *models.User {
+	if b.err != nil {
+		return nil
+	}
+	if len(b.scenario.Users) == 0 {
+		b.fail(fmt.Errorf("add a user before %s", record))
+		return nil
+	}
+	return b.scenario.User()
+}
+
+// fail records the first mistake in the chain
+func (b *ScenarioBuilder) fail(err error) *ScenarioBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// roundCents rounds an amount to the cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
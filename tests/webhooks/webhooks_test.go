@@ -0,0 +1,103 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/webhooks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "whsec_0123456789abcdef"
+
+// Test Sign and Verify - A signature verifies for its body and secret only
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":"evt-1"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := webhooks.Sign(secret, now, body)
+
+	assert.NoError(t, webhooks.Verify(secret, timestamp, signature, body, now, 5*time.Minute))
+	assert.ErrorIs(t, webhooks.Verify(secret, timestamp, signature, []byte(`{"id":"evt-2"}`), now, 5*time.Minute), webhooks.ErrInvalidSignature)
+	assert.ErrorIs(t, webhooks.Verify("another-secret-value", timestamp, signature, body, now, 5*time.Minute), webhooks.ErrInvalidSignature)
+	assert.ErrorIs(t, webhooks.Verify(secret, "not-a-time", signature, body, now, 5*time.Minute), webhooks.ErrInvalidSignature)
+}
+
+// Test Verify - Deliveries signed outside the tolerance are rejected as replays
+func TestVerify_StaleTimestamp(t *testing.T) {
+	signedAt := time.Now().Add(-10 * time.Minute)
+	body := []byte(`{"id":"evt-1"}`)
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	signature := webhooks.Sign(secret, signedAt, body)
+
+	assert.ErrorIs(t, webhooks.Verify(secret, timestamp, signature, body, time.Now(), 5*time.Minute), webhooks.ErrStaleTimestamp)
+	assert.NoError(t, webhooks.Verify(secret, timestamp, signature, body, time.Now(), 0))
+}
+
+// Test RetryPolicy - The backoff doubles with every attempt up to the cap
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := webhooks.RetryPolicy{MaxAttempts: 5, InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	assert.Equal(t, 30*time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Minute, policy.Backoff(2))
+	assert.Equal(t, 4*time.Minute, policy.Backoff(4))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(5))
+	assert.Equal(t, 5*time.Minute, policy.Backoff(60))
+	assert.False(t, policy.Exhausted(4))
+	assert.True(t, policy.Exhausted(5))
+}
+
+// Test Result - Only transport failures, timeouts, rate limits and server errors are retried
+func TestResult_Retryable(t *testing.T) {
+	failed := errors.New("failed")
+
+	assert.False(t, webhooks.Result{StatusCode: http.StatusOK}.Retryable())
+	assert.True(t, webhooks.Result{Err: failed}.Retryable())
+	assert.True(t, webhooks.Result{StatusCode: http.StatusTooManyRequests, Err: failed}.Retryable())
+	assert.True(t, webhooks.Result{StatusCode: http.StatusBadGateway, Err: failed}.Retryable())
+	assert.False(t, webhooks.Result{StatusCode: http.StatusBadRequest, Err: failed}.Retryable())
+	assert.False(t, webhooks.Result{StatusCode: http.StatusGone, Err: failed}.Retryable())
+}
+
+// Test Client.Send - Events are POSTed as JSON with their ID, type and a verifiable signature
+func TestClient_Send(t *testing.T) {
+	var received webhooks.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhooks.Verify(secret, r.Header.Get(webhooks.TimestampHeader), r.Header.Get(webhooks.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "evt-1", r.Header.Get(webhooks.IDHeader))
+		assert.Equal(t, "order.paid", r.Header.Get(webhooks.EventHeader))
+		_ = json.Unmarshal(body, &received)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := webhooks.NewClient(time.Second, "test")
+	result := client.Send(context.Background(), server.URL, secret, webhooks.Event{
+		ID:         "evt-1",
+		Type:       "order.paid",
+		OccurredAt: time.Now(),
+		Data:       json.RawMessage(`{"order_id":"order-1"}`),
+	})
+
+	require.True(t, result.Delivered(), result.Err)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "ok", result.Response)
+	assert.JSONEq(t, `{"order_id":"order-1"}`, string(received.Data))
+
+	result = client.Send(context.Background(), server.URL, "wrong-secret-value", webhooks.Event{ID: "evt-1", Type: "order.paid"})
+	assert.False(t, result.Delivered())
+	assert.Equal(t, http.StatusUnauthorized, result.StatusCode)
+}
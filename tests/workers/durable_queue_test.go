@@ -0,0 +1,341 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const testJobType = "test_job"
+
+// memoryJobStore is a workers.JobStore kept in memory
+type memoryJobStore struct {
+	mu       sync.Mutex
+	jobs     map[string]*storedJobState
+	released int
+}
+
+type storedJobState struct {
+	job         workers.StoredJob
+	status      string
+	owner       string
+	leasedUntil time.Time
+	lastError   string
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string]*storedJobState)}
+}
+
+func (s *memoryJobStore) Enqueue(ctx context.Context, job *workers.StoredJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = &storedJobState{job: *job, status: "queued"}
+	return nil
+}
+
+func (s *memoryJobStore) Lease(ctx context.Context, pool, owner string, limit int, visibility time.Duration) ([]*workers.StoredJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []*storedJobState
+	for _, state := range s.jobs {
+		if state.job.Pool != pool {
+			continue
+		}
+		if (state.status == "queued" && !state.job.RunAt.After(now)) ||
+			(state.status == "leased" && state.leasedUntil.Before(now)) {
+			due = append(due, state)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].job.Priority > due[j].job.Priority })
+
+	var leased []*workers.StoredJob
+	for _, state := range due {
+		if len(leased) == limit {
+			break
+		}
+		state.status = "leased"
+		state.owner = owner
+		state.leasedUntil = now.Add(visibility)
+		state.job.Attempts++
+		job := state.job
+		leased = append(leased, &job)
+	}
+	return leased, nil
+}
+
+func (s *memoryJobStore) ExtendLease(ctx context.Context, id, owner string, visibility time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.held(id, owner)
+	if ok {
+		state.leasedUntil = time.Now().Add(visibility)
+	}
+	return ok, nil
+}
+
+func (s *memoryJobStore) Release(ctx context.Context, id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.held(id, owner); ok {
+		state.status = "queued"
+		state.job.Attempts--
+		s.released++
+	}
+	return nil
+}
+
+func (s *memoryJobStore) Complete(ctx context.Context, id, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.held(id, owner); ok {
+		delete(s.jobs, id)
+	}
+	return nil
+}
+
+func (s *memoryJobStore) Retry(ctx context.Context, id, owner string, runAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.held(id, owner); ok {
+		state.status = "queued"
+		state.job.RunAt = runAt
+		state.lastError = lastError
+	}
+	return nil
+}
+
+func (s *memoryJobStore) Fail(ctx context.Context, id, owner string, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.held(id, owner); ok {
+		state.status = "failed"
+		state.lastError = lastError
+	}
+	return nil
+}
+
+// held returns a job while owner holds its lease; the store must be locked
+func (s *memoryJobStore) held(id, owner string) (*storedJobState, bool) {
+	state, ok := s.jobs[id]
+	if !ok || state.status != "leased" || state.owner != owner {
+		return nil, false
+	}
+	return state, true
+}
+
+// state returns a copy of a stored job's state, or false once it was completed
+func (s *memoryJobStore) state(id string) (storedJobState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.jobs[id]
+	if !ok {
+		return storedJobState{}, false
+	}
+	return *state, true
+}
+
+// testJob counts its runs and fails its first failures runs
+type testJob struct {
+	*workers.BaseJob
+	Name     string `json:"name"`
+	failures int32
+	runs     *int32
+}
+
+func (j *testJob) Execute(ctx context.Context) error {
+	run := atomic.AddInt32(j.runs, 1)
+	if run <= j.failures {
+		return errors.New("temporary failure")
+	}
+	return nil
+}
+
+// DurableQueueTestSuite defines the test suite for pools backed by a job store
+type DurableQueueTestSuite struct {
+	suite.Suite
+	store       *memoryJobStore
+	poolManager *workers.PoolManager
+	runs        int32
+	failures    int32
+	names       chan string
+}
+
+// SetupTest runs before each test in the suite
+func (suite *DurableQueueTestSuite) SetupTest() {
+	suite.store = newMemoryJobStore()
+	suite.runs = 0
+	suite.failures = 0
+	suite.names = make(chan string, 10)
+
+	suite.poolManager = workers.NewPoolManager(&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	suite.poolManager.SetJobStore(suite.store, workers.DurableQueueConfig{
+		PollInterval:      5 * time.Millisecond,
+		VisibilityTimeout: time.Minute,
+	})
+	suite.Require().NoError(suite.poolManager.CreatePool(&workers.WorkerPoolConfig{
+		Name:            "general",
+		WorkerCount:     1,
+		QueueSize:       10,
+		ShutdownTimeout: time.Second,
+		RetryDelay:      time.Millisecond,
+	}))
+	suite.poolManager.RegisterJobType(testJobType, func(payload []byte) (workers.Job, error) {
+		job, err := workers.DecodeJob(payload, suite.newJob("", 0))
+		if err == nil {
+			suite.names <- job.(*testJob).Name
+		}
+		return job, err
+	})
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *DurableQueueTestSuite) TearDownTest() {
+	_ = suite.poolManager.Shutdown()
+}
+
+// newJob returns a test job sharing the suite's run counter
+func (suite *DurableQueueTestSuite) newJob(name string, maxRetries int) *testJob {
+	return &testJob{
+		BaseJob:  workers.NewBaseJob(testJobType, workers.PriorityNormal, maxRetries),
+		Name:     name,
+		failures: atomic.LoadInt32(&suite.failures),
+		runs:     &suite.runs,
+	}
+}
+
+// Test SubmitJob - A durable job is stored, leased by its pool, rebuilt and completed
+func (suite *DurableQueueTestSuite) TestSubmitJob_StoresAndCompletes() {
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+	job := suite.newJob("stored", 3)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.SubmitJob(job))
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		_, stored := suite.store.state(job.GetID())
+		return !stored
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(suite.T(), int32(1), atomic.LoadInt32(&suite.runs))
+	assert.Equal(suite.T(), "stored", <-suite.names)
+}
+
+// Test StartAllPools - Jobs stored before a restart are resumed, including those whose
+// lease expired with the process running them
+func (suite *DurableQueueTestSuite) TestStartAllPools_ResumesStoredJobs() {
+	suite.Require().NoError(suite.store.Enqueue(context.Background(), &workers.StoredJob{
+		ID: "queued-job", Type: testJobType, Pool: "general", Payload: []byte(`{"name":"queued"}`),
+		MaxRetries: 3, RunAt: time.Now(),
+	}))
+	suite.Require().NoError(suite.store.Enqueue(context.Background(), &workers.StoredJob{
+		ID: "crashed-job", Type: testJobType, Pool: "general", Payload: []byte(`{"name":"crashed"}`),
+		MaxRetries: 3, RunAt: time.Now(),
+	}))
+	// Leased by a process that crashed, with its lease expired
+	_, err := suite.store.Lease(context.Background(), "general", "crashed-process", 10, -time.Second)
+	suite.Require().NoError(err)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.runs) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Eventually(suite.T(), func() bool {
+		_, queued := suite.store.state("queued-job")
+		_, crashed := suite.store.state("crashed-job")
+		return !queued && !crashed
+	}, time.Second, 5*time.Millisecond)
+}
+
+// Test SubmitJob - A failing job is retried after a backoff until it succeeds
+func (suite *DurableQueueTestSuite) TestSubmitJob_RetriesFailures() {
+	atomic.StoreInt32(&suite.failures, 2)
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+	job := suite.newJob("flaky", 3)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.SubmitJob(job))
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		_, stored := suite.store.state(job.GetID())
+		return !stored
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(suite.T(), int32(3), atomic.LoadInt32(&suite.runs))
+}
+
+// Test SubmitJob - A job failing on its last retry is failed and kept with its error
+func (suite *DurableQueueTestSuite) TestSubmitJob_FailsAfterLastRetry() {
+	atomic.StoreInt32(&suite.failures, 10)
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+	job := suite.newJob("broken", 1)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.SubmitJob(job))
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		state, _ := suite.store.state(job.GetID())
+		return state.status == "failed"
+	}, time.Second, 5*time.Millisecond)
+	state, _ := suite.store.state(job.GetID())
+	assert.Equal(suite.T(), 2, state.job.Attempts)
+	assert.Equal(suite.T(), "temporary failure", state.lastError)
+	assert.Equal(suite.T(), int32(2), atomic.LoadInt32(&suite.runs))
+}
+
+// Test StartAllPools - A stored job of a type without a factory is failed
+func (suite *DurableQueueTestSuite) TestStartAllPools_UnknownJobType() {
+	suite.Require().NoError(suite.store.Enqueue(context.Background(), &workers.StoredJob{
+		ID: "unknown-job", Type: "retired_type", Pool: "general", Payload: []byte(`{}`), RunAt: time.Now(),
+	}))
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		state, _ := suite.store.state("unknown-job")
+		return state.status == "failed"
+	}, time.Second, 5*time.Millisecond)
+	state, _ := suite.store.state("unknown-job")
+	assert.Contains(suite.T(), state.lastError, "no factory registered")
+}
+
+// Test SubmitJob - Jobs of types without a factory stay in memory
+func (suite *DurableQueueTestSuite) TestSubmitJob_InMemoryJobType() {
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+	job := suite.newJob("memory", 0)
+	job.Type = "memory_job"
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.SubmitJob(job))
+
+	// Assert
+	assert.Eventually(suite.T(), func() bool {
+		return atomic.LoadInt32(&suite.runs) == 1
+	}, time.Second, 5*time.Millisecond)
+	_, stored := suite.store.state(job.GetID())
+	assert.False(suite.T(), stored)
+}
+
+// TestDurableQueueTestSuite runs the test suite
+func TestDurableQueueTestSuite(t *testing.T) {
+	suite.Run(t, new(DurableQueueTestSuite))
+}
@@ -0,0 +1,164 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// blockingJob runs until released, failing if told to
+type blockingJob struct {
+	*workers.BaseJob
+	started chan struct{}
+	release chan struct{}
+	fail    bool
+}
+
+func (j *blockingJob) Execute(ctx context.Context) error {
+	j.started <- struct{}{}
+	<-j.release
+	if j.fail {
+		return errors.New("job failed")
+	}
+	return nil
+}
+
+// PoolResizeTestSuite defines the test suite for resizing worker pools at runtime
+type PoolResizeTestSuite struct {
+	suite.Suite
+	poolManager *workers.PoolManager
+	started     chan struct{}
+	release     chan struct{}
+}
+
+// SetupTest runs before each test in the suite
+func (suite *PoolResizeTestSuite) SetupTest() {
+	suite.started = make(chan struct{}, 10)
+	suite.release = make(chan struct{})
+	suite.poolManager = workers.NewPoolManager(&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	suite.Require().NoError(suite.poolManager.CreatePool(&workers.WorkerPoolConfig{
+		Name:            "general",
+		WorkerCount:     1,
+		QueueSize:       10,
+		ShutdownTimeout: time.Second,
+		RetryDelay:      time.Millisecond,
+		EnableMetrics:   true,
+	}))
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *PoolResizeTestSuite) TearDownTest() {
+	close(suite.release)
+	_ = suite.poolManager.Shutdown()
+}
+
+func (suite *PoolResizeTestSuite) submit(jobType string, fail bool) {
+	job := &blockingJob{BaseJob: workers.NewBaseJob(jobType, workers.PriorityNormal, 0), started: suite.started, release: suite.release, fail: fail}
+	suite.Require().NoError(suite.poolManager.SubmitJobToPool("general", job))
+}
+
+// waitStarted waits for count jobs to start, failing the test if they do not
+func (suite *PoolResizeTestSuite) waitStarted(count int) {
+	for i := 0; i < count; i++ {
+		select {
+		case <-suite.started:
+		case <-time.After(time.Second):
+			suite.T().Fatalf("only %d of %d jobs started", i, count)
+		}
+	}
+}
+
+// Test ResizePool - Added workers pick up queued jobs right away
+func (suite *PoolResizeTestSuite) TestResizePool_AddsWorkers() {
+	for i := 0; i < 3; i++ {
+		suite.submit("blocking_job", false)
+	}
+	suite.waitStarted(1)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.ResizePool("general", 3))
+
+	// Assert
+	suite.waitStarted(2)
+	metrics, err := suite.poolManager.GetPoolMetrics("general")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 3, metrics.WorkerCount)
+	assert.Equal(suite.T(), 3, metrics.ActiveWorkers)
+	assert.Equal(suite.T(), 0, metrics.QueueDepth)
+}
+
+// Test ResizePool - Removed workers finish their job, and the pool then runs fewer jobs at once
+func (suite *PoolResizeTestSuite) TestResizePool_RemovesWorkersAfterTheirJob() {
+	suite.Require().NoError(suite.poolManager.ResizePool("general", 2))
+	suite.submit("blocking_job", false)
+	suite.submit("blocking_job", false)
+	suite.waitStarted(2)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.ResizePool("general", 1))
+	for i := 0; i < 2; i++ {
+		suite.release <- struct{}{}
+	}
+
+	// Assert
+	suite.submit("blocking_job", false)
+	suite.submit("blocking_job", false)
+	suite.waitStarted(1)
+	select {
+	case <-suite.started:
+		suite.T().Fatal("a removed worker ran a job")
+	case <-time.After(100 * time.Millisecond):
+	}
+	metrics, err := suite.poolManager.GetPoolMetrics("general")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, metrics.WorkerCount)
+	assert.Equal(suite.T(), 1, metrics.QueueDepth)
+}
+
+// Test ResizePool - Pools need a worker, and unknown pools cannot be resized
+func (suite *PoolResizeTestSuite) TestResizePool_Invalid() {
+	assert.Error(suite.T(), suite.poolManager.ResizePool("general", 0))
+	assert.Error(suite.T(), suite.poolManager.ResizePool("missing", 2))
+
+	metrics, err := suite.poolManager.GetPoolMetrics("general")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 1, metrics.WorkerCount)
+}
+
+// Test GetPoolMetrics - Finished jobs are counted per job type
+func (suite *PoolResizeTestSuite) TestGetPoolMetrics_PerJobType() {
+	suite.submit("report_generation", false)
+	suite.submit("report_generation", true)
+	suite.submit("notification", false)
+	for i := 0; i < 3; i++ {
+		suite.waitStarted(1)
+		suite.release <- struct{}{}
+	}
+
+	// Assert
+	var metrics *workers.PoolMetrics
+	suite.Require().Eventually(func() bool {
+		var err error
+		metrics, err = suite.poolManager.GetPoolMetrics("general")
+		return err == nil && metrics.JobsProcessed == 3
+	}, time.Second, 10*time.Millisecond)
+	suite.Require().Contains(metrics.JobTypes, "report_generation")
+	assert.Equal(suite.T(), int64(2), metrics.JobTypes["report_generation"].JobsProcessed)
+	assert.Equal(suite.T(), int64(1), metrics.JobTypes["report_generation"].JobsFailed)
+	assert.Equal(suite.T(), int64(1), metrics.JobTypes["notification"].JobsSucceeded)
+	assert.False(suite.T(), metrics.StartedAt.IsZero())
+}
+
+// TestPoolResizeTestSuite runs the test suite
+func TestPoolResizeTestSuite(t *testing.T) {
+	suite.Run(t, new(PoolResizeTestSuite))
+}
@@ -0,0 +1,167 @@
+package workers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"easy-orders-backend/pkg/logger"
+	"easy-orders-backend/pkg/workers"
+	"easy-orders-backend/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingJob records when it ran
+type recordingJob struct {
+	*workers.BaseJob
+	ran chan time.Time
+}
+
+func (j *recordingJob) Execute(ctx context.Context) error {
+	j.ran <- time.Now()
+	return nil
+}
+
+// SchedulerTestSuite defines the test suite for jobs scheduled to run later
+type SchedulerTestSuite struct {
+	suite.Suite
+	poolManager *workers.PoolManager
+	ran         chan time.Time
+}
+
+// SetupTest runs before each test in the suite
+func (suite *SchedulerTestSuite) SetupTest() {
+	suite.ran = make(chan time.Time, 10)
+	suite.poolManager = workers.NewPoolManager(&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	suite.Require().NoError(suite.poolManager.CreatePool(&workers.WorkerPoolConfig{
+		Name:            "general",
+		WorkerCount:     1,
+		QueueSize:       10,
+		ShutdownTimeout: time.Second,
+		RetryDelay:      time.Millisecond,
+	}))
+	suite.Require().NoError(suite.poolManager.StartAllPools())
+}
+
+// TearDownTest runs after each test in the suite
+func (suite *SchedulerTestSuite) TearDownTest() {
+	_ = suite.poolManager.Shutdown()
+}
+
+func (suite *SchedulerTestSuite) newJob(priority int) *recordingJob {
+	return &recordingJob{BaseJob: workers.NewBaseJob("scheduled_job", priority, 0), ran: suite.ran}
+}
+
+// Test ScheduleJob - A job is held until it is due, without tying up a worker
+func (suite *SchedulerTestSuite) TestScheduleJob_RunsWhenDue() {
+	runAt := time.Now().Add(50 * time.Millisecond)
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.ScheduleJob(suite.newJob(workers.PriorityNormal), runAt))
+
+	// Assert
+	assert.Equal(suite.T(), 1, suite.poolManager.GetScheduledJobCount())
+	metrics, err := suite.poolManager.GetPoolMetrics("general")
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 0, metrics.QueueDepth)
+	assert.Equal(suite.T(), 0, metrics.ActiveWorkers)
+
+	select {
+	case ranAt := <-suite.ran:
+		assert.False(suite.T(), ranAt.Before(runAt))
+	case <-time.After(time.Second):
+		suite.T().Fatal("scheduled job did not run")
+	}
+	assert.Equal(suite.T(), 0, suite.poolManager.GetScheduledJobCount())
+}
+
+// Test ScheduleJob - A job scheduled sooner runs before one scheduled earlier for later
+func (suite *SchedulerTestSuite) TestScheduleJob_RunsInDueOrder() {
+	later := suite.newJob(workers.PriorityNormal)
+	sooner := suite.newJob(workers.PriorityNormal)
+	var order []string
+	var mu sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2; i++ {
+			<-suite.ran
+		}
+		close(done)
+	}()
+	record := func(job *recordingJob) *orderedJob {
+		return &orderedJob{recordingJob: job, record: func() {
+			mu.Lock()
+			order = append(order, job.GetID())
+			mu.Unlock()
+		}}
+	}
+
+	// Execute
+	suite.Require().NoError(suite.poolManager.ScheduleJob(record(later), time.Now().Add(80*time.Millisecond)))
+	suite.Require().NoError(suite.poolManager.ScheduleJob(record(sooner), time.Now().Add(20*time.Millisecond)))
+
+	// Assert
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		suite.T().Fatal("scheduled jobs did not run")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(suite.T(), []string{sooner.GetID(), later.GetID()}, order)
+}
+
+// Test ScheduleJob - A job whose time already passed runs right away
+func (suite *SchedulerTestSuite) TestScheduleJob_PastTimeRunsNow() {
+	// Execute
+	suite.Require().NoError(suite.poolManager.ScheduleJob(suite.newJob(workers.PriorityNormal), time.Now().Add(-time.Minute)))
+
+	// Assert
+	assert.Equal(suite.T(), 0, suite.poolManager.GetScheduledJobCount())
+	select {
+	case <-suite.ran:
+	case <-time.After(time.Second):
+		suite.T().Fatal("job did not run")
+	}
+}
+
+// Test ScheduleJob - A stored job is stored with its due time, for its pool to lease then
+func (suite *SchedulerTestSuite) TestScheduleJob_StoresDueTime() {
+	store := newMemoryJobStore()
+	poolManager := workers.NewPoolManager(&logger.Logger{SugaredLogger: mocks.NewNoOpLogger()})
+	poolManager.SetJobStore(store, workers.DurableQueueConfig{PollInterval: time.Hour, VisibilityTimeout: time.Minute})
+	suite.Require().NoError(poolManager.CreatePool(workers.DefaultWorkerPoolConfig("general")))
+	poolManager.RegisterJobType(testJobType, func(payload []byte) (workers.Job, error) {
+		return nil, nil
+	})
+	job := &testJob{BaseJob: workers.NewBaseJob(testJobType, workers.PriorityNormal, 0)}
+	runAt := time.Now().Add(time.Hour)
+
+	// Execute
+	suite.Require().NoError(poolManager.ScheduleJob(job, runAt))
+
+	// Assert
+	state, stored := store.state(job.GetID())
+	suite.Require().True(stored)
+	assert.True(suite.T(), state.job.RunAt.Equal(runAt))
+	assert.Equal(suite.T(), 0, poolManager.GetScheduledJobCount())
+}
+
+// orderedJob records the order jobs ran in
+type orderedJob struct {
+	*recordingJob
+	record func()
+}
+
+func (j *orderedJob) Execute(ctx context.Context) error {
+	j.record()
+	return j.recordingJob.Execute(ctx)
+}
+
+// TestSchedulerTestSuite runs the test suite
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}